@@ -48,6 +48,12 @@ func (f *SWFactory) Get(config *FactoryOpts) (bccsp.BCCSP, error) {
 	var ks bccsp.KeyStore
 	if swOpts.Ephemeral == true {
 		ks = sw.NewDummyKeyStore()
+	} else if swOpts.VaultKeystore != nil {
+		vks, err := newVaultKeyStore(swOpts.VaultKeystore)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to initialize software vault key store: %s", err)
+		}
+		ks = vks
 	} else if swOpts.FileKeystore != nil {
 		fks, err := sw.NewFileBasedKeyStore(nil, swOpts.FileKeystore.KeyStorePath, false)
 		if err != nil {
@@ -71,6 +77,7 @@ type SwOpts struct {
 	// Keystore Options
 	Ephemeral     bool               `mapstructure:"tempkeys,omitempty" json:"tempkeys,omitempty"`
 	FileKeystore  *FileKeystoreOpts  `mapstructure:"filekeystore,omitempty" json:"filekeystore,omitempty" yaml:"FileKeyStore"`
+	VaultKeystore *VaultKeystoreOpts `mapstructure:"vaultkeystore,omitempty" json:"vaultkeystore,omitempty" yaml:"VaultKeyStore"`
 	DummyKeystore *DummyKeystoreOpts `mapstructure:"dummykeystore,omitempty" json:"dummykeystore,omitempty"`
 }
 
@@ -0,0 +1,92 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package factory
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"sync"
+
+	"github.com/hyperledger/fabric/bccsp"
+	"github.com/hyperledger/fabric/bccsp/sw"
+)
+
+const (
+	// PasswordKeyWrapperName is the name of the key wrapper deriving the key
+	// protecting a vault keystore from a password
+	PasswordKeyWrapperName = "password"
+)
+
+// VaultKeystoreOpts contains the options of a keystore whose keys are
+// encrypted at rest under a data encryption key, which is in turn protected
+// by a key wrapper
+type VaultKeystoreOpts struct {
+	KeyStorePath string `mapstructure:"keystore" yaml:"KeyStore"`
+	// KeyWrapper is the name of the key wrapper protecting the data
+	// encryption key, "password" unless another one has been registered
+	KeyWrapper string `mapstructure:"keywrapper" yaml:"KeyWrapper"`
+	// Password or PasswordFile provide the password of the "password" key wrapper
+	Password     string `mapstructure:"password" yaml:"Password"`
+	PasswordFile string `mapstructure:"passwordfile" yaml:"PasswordFile"`
+}
+
+// KeyWrapperFactory creates the key wrapper of a vault keystore, e.g. one
+// calling out to a key management service
+type KeyWrapperFactory func(opts *VaultKeystoreOpts) (sw.KeyWrapper, error)
+
+var (
+	keyWrappersLock sync.RWMutex
+	keyWrappers     = map[string]KeyWrapperFactory{
+		PasswordKeyWrapperName: newPasswordKeyWrapper,
+	}
+)
+
+// RegisterKeyWrapper makes a key wrapper available under the given name
+// to the vault keystores
+func RegisterKeyWrapper(name string, factory KeyWrapperFactory) {
+	keyWrappersLock.Lock()
+	defer keyWrappersLock.Unlock()
+
+	keyWrappers[name] = factory
+}
+
+func newVaultKeyStore(opts *VaultKeystoreOpts) (bccsp.KeyStore, error) {
+	name := opts.KeyWrapper
+	if name == "" {
+		name = PasswordKeyWrapperName
+	}
+
+	keyWrappersLock.RLock()
+	factory, ok := keyWrappers[name]
+	keyWrappersLock.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("Key wrapper %s not registered", name)
+	}
+
+	wrapper, err := factory(opts)
+	if err != nil {
+		return nil, err
+	}
+	return sw.NewVaultKeyStore(wrapper, opts.KeyStorePath, false)
+}
+
+func newPasswordKeyWrapper(opts *VaultKeystoreOpts) (sw.KeyWrapper, error) {
+	pwd := opts.Password
+	if opts.PasswordFile != "" {
+		raw, err := ioutil.ReadFile(opts.PasswordFile)
+		if err != nil {
+			return nil, fmt.Errorf("Failed reading password file: %s", err)
+		}
+		pwd = strings.TrimRight(string(raw), "\r\n")
+	}
+	if pwd == "" {
+		return nil, errors.New("Either a password or a password file must be provided")
+	}
+	return sw.NewPasswordKeyWrapper([]byte(pwd))
+}
@@ -0,0 +1,92 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package factory
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hyperledger/fabric/bccsp/sw"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSWFactoryGetVaultKeystore(t *testing.T) {
+	path, err := ioutil.TempDir("", "vaultks")
+	assert.NoError(t, err)
+	defer os.RemoveAll(path)
+
+	f := &SWFactory{}
+	opts := &FactoryOpts{
+		SwOpts: &SwOpts{
+			SecLevel:      256,
+			HashFamily:    "SHA2",
+			VaultKeystore: &VaultKeystoreOpts{KeyStorePath: path, Password: "secret"},
+		},
+	}
+	csp, err := f.Get(opts)
+	assert.NoError(t, err)
+	assert.NotNil(t, csp)
+	_, err = os.Stat(filepath.Join(path, sw.VaultDataKeyFile))
+	assert.NoError(t, err)
+
+	// the password can be read from a file too
+	pwdFile := filepath.Join(path, "password")
+	assert.NoError(t, ioutil.WriteFile(pwdFile, []byte("secret\n"), 0600))
+	opts.SwOpts.VaultKeystore = &VaultKeystoreOpts{KeyStorePath: path, PasswordFile: pwdFile}
+	_, err = f.Get(opts)
+	assert.NoError(t, err)
+
+	opts.SwOpts.VaultKeystore = &VaultKeystoreOpts{KeyStorePath: path, Password: "wrong"}
+	_, err = f.Get(opts)
+	assert.Error(t, err)
+
+	opts.SwOpts.VaultKeystore = &VaultKeystoreOpts{KeyStorePath: path}
+	_, err = f.Get(opts)
+	assert.Error(t, err)
+
+	opts.SwOpts.VaultKeystore = &VaultKeystoreOpts{KeyStorePath: path, PasswordFile: filepath.Join(path, "missing")}
+	_, err = f.Get(opts)
+	assert.Error(t, err)
+
+	opts.SwOpts.VaultKeystore = &VaultKeystoreOpts{KeyStorePath: path, KeyWrapper: "unknown"}
+	_, err = f.Get(opts)
+	assert.Error(t, err)
+}
+
+type mockKeyWrapper struct{}
+
+func (*mockKeyWrapper) Wrap(dek []byte) ([]byte, error) {
+	return dek, nil
+}
+
+func (*mockKeyWrapper) Unwrap(wrapped []byte) ([]byte, error) {
+	return wrapped, nil
+}
+
+func TestRegisterKeyWrapper(t *testing.T) {
+	path, err := ioutil.TempDir("", "vaultks")
+	assert.NoError(t, err)
+	defer os.RemoveAll(path)
+
+	RegisterKeyWrapper("mock", func(opts *VaultKeystoreOpts) (sw.KeyWrapper, error) {
+		return &mockKeyWrapper{}, nil
+	})
+	RegisterKeyWrapper("failing", func(opts *VaultKeystoreOpts) (sw.KeyWrapper, error) {
+		return nil, errors.New("kms unreachable")
+	})
+
+	ks, err := newVaultKeyStore(&VaultKeystoreOpts{KeyStorePath: path, KeyWrapper: "mock"})
+	assert.NoError(t, err)
+	assert.NotNil(t, ks)
+
+	_, err = newVaultKeyStore(&VaultKeystoreOpts{KeyStorePath: path, KeyWrapper: "failing"})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "kms unreachable")
+}
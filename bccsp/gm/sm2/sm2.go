@@ -0,0 +1,211 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package sm2 implements the SM2 elliptic curve digital signature algorithm
+// as defined in GB/T 32918-2016, over the curve recommended by the standard.
+package sm2
+
+import (
+	"crypto"
+	"crypto/elliptic"
+	"encoding/asn1"
+	"errors"
+	"io"
+	"math/big"
+	"sync"
+
+	"github.com/hyperledger/fabric/bccsp/gm/sm3"
+)
+
+// DefaultUID is the user identifier used to compute the digest of a message
+// when none is agreed on, as recommended by GM/T 0009-2012
+var DefaultUID = []byte("1234567812345678")
+
+var (
+	initOnce sync.Once
+	p256Sm2  *elliptic.CurveParams
+)
+
+func initP256Sm2() {
+	p256Sm2 = &elliptic.CurveParams{Name: "SM2-P-256"}
+	p256Sm2.P, _ = new(big.Int).SetString("FFFFFFFEFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFF00000000FFFFFFFFFFFFFFFF", 16)
+	p256Sm2.N, _ = new(big.Int).SetString("FFFFFFFEFFFFFFFFFFFFFFFFFFFFFFFF7203DF6B21C6052B53BBF40939D54123", 16)
+	p256Sm2.B, _ = new(big.Int).SetString("28E9FA9E9D9F5E344D5A9E4BCF6509A7F39789F515AB8F92DDBCBD414D940E93", 16)
+	p256Sm2.Gx, _ = new(big.Int).SetString("32C4AE2C1F1981195F9904466A39C9948FE30BBFF2660BE1715A4589334C74C7", 16)
+	p256Sm2.Gy, _ = new(big.Int).SetString("BC3736A2F4F6779C59BDCEE36B692153D0A9877CC62A474002DF32E52139F0A0", 16)
+	p256Sm2.BitSize = 256
+}
+
+// P256Sm2 returns the curve recommended by GB/T 32918.5-2017. As for the
+// NIST curves, its a coefficient is -3, so the generic implementation of
+// elliptic.CurveParams applies.
+func P256Sm2() elliptic.Curve {
+	initOnce.Do(initP256Sm2)
+	return p256Sm2
+}
+
+// PublicKey represents an SM2 public key
+type PublicKey struct {
+	elliptic.Curve
+	X, Y *big.Int
+}
+
+// PrivateKey represents an SM2 private key
+type PrivateKey struct {
+	PublicKey
+	D *big.Int
+}
+
+// Public returns the public key corresponding to priv
+func (priv *PrivateKey) Public() crypto.PublicKey {
+	return &priv.PublicKey
+}
+
+// Sign signs a digest computed with Digest and returns the
+// ASN.1 encoded signature, so that a PrivateKey is a crypto.Signer
+func (priv *PrivateKey) Sign(rand io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	r, s, err := Sign(rand, priv, digest)
+	if err != nil {
+		return nil, err
+	}
+	return asn1.Marshal(signature{r, s})
+}
+
+type signature struct {
+	R, S *big.Int
+}
+
+var one = big.NewInt(1)
+
+// GenerateKey generates a public and private key pair
+func GenerateKey(rand io.Reader) (*PrivateKey, error) {
+	c := P256Sm2()
+	params := c.Params()
+
+	// d is chosen in [1, n-2] for 1+d to be invertible
+	b := make([]byte, params.BitSize/8+8)
+	if _, err := io.ReadFull(rand, b); err != nil {
+		return nil, err
+	}
+	d := new(big.Int).SetBytes(b)
+	n := new(big.Int).Sub(params.N, big.NewInt(2))
+	d.Mod(d, n)
+	d.Add(d, one)
+
+	priv := &PrivateKey{D: d}
+	priv.PublicKey.Curve = c
+	priv.PublicKey.X, priv.PublicKey.Y = c.ScalarBaseMult(d.Bytes())
+	return priv, nil
+}
+
+// ZA returns the hash of the distinguishing identifier of the user and of
+// the parameters of the curve and of the public key
+func ZA(pub *PublicKey, uid []byte) ([]byte, error) {
+	if len(uid) >= 8192 {
+		return nil, errors.New("sm2: user identifier too long")
+	}
+	params := pub.Curve.Params()
+	a := new(big.Int).Sub(params.P, big.NewInt(3))
+
+	h := sm3.New()
+	entl := len(uid) * 8
+	h.Write([]byte{byte(entl >> 8), byte(entl)})
+	h.Write(uid)
+	for _, v := range []*big.Int{a, params.B, params.Gx, params.Gy, pub.X, pub.Y} {
+		h.Write(padded(v, (params.BitSize+7)/8))
+	}
+	return h.Sum(nil), nil
+}
+
+// Digest returns the digest of a message signed by SM2, that is
+// SM3(ZA || msg). The digest is what Sign and Verify operate on.
+func Digest(pub *PublicKey, uid, msg []byte) ([]byte, error) {
+	za, err := ZA(pub, uid)
+	if err != nil {
+		return nil, err
+	}
+	h := sm3.New()
+	h.Write(za)
+	h.Write(msg)
+	return h.Sum(nil), nil
+}
+
+// Sign signs a digest computed with Digest using the private key
+func Sign(rand io.Reader, priv *PrivateKey, digest []byte) (r, s *big.Int, err error) {
+	c := priv.PublicKey.Curve
+	n := c.Params().N
+	if priv.D.Sign() <= 0 || new(big.Int).Add(priv.D, one).Cmp(n) >= 0 {
+		return nil, nil, errors.New("sm2: invalid private key")
+	}
+	e := new(big.Int).SetBytes(digest)
+
+	// (1+d)^-1
+	dInv := new(big.Int).Add(priv.D, one)
+	dInv.ModInverse(dInv, n)
+
+	b := make([]byte, c.Params().BitSize/8+8)
+	for {
+		if _, err = io.ReadFull(rand, b); err != nil {
+			return nil, nil, err
+		}
+		k := new(big.Int).SetBytes(b)
+		k.Mod(k, new(big.Int).Sub(n, one))
+		k.Add(k, one)
+
+		x1, _ := c.ScalarBaseMult(k.Bytes())
+
+		// r = (e + x1) mod n, retried if r = 0 or r + k = n
+		r = new(big.Int).Add(e, x1)
+		r.Mod(r, n)
+		if r.Sign() == 0 || new(big.Int).Add(r, k).Cmp(n) == 0 {
+			continue
+		}
+
+		// s = (1+d)^-1 * (k - r*d) mod n
+		s = new(big.Int).Mul(r, priv.D)
+		s.Sub(k, s)
+		s.Mul(s, dInv)
+		s.Mod(s, n)
+		if s.Sign() != 0 {
+			return r, s, nil
+		}
+	}
+}
+
+// Verify verifies the signature in r, s of a digest computed
+// with Digest using the public key
+func Verify(pub *PublicKey, digest []byte, r, s *big.Int) bool {
+	c := pub.Curve
+	n := c.Params().N
+	if r.Sign() <= 0 || s.Sign() <= 0 || r.Cmp(n) >= 0 || s.Cmp(n) >= 0 {
+		return false
+	}
+
+	t := new(big.Int).Add(r, s)
+	t.Mod(t, n)
+	if t.Sign() == 0 {
+		return false
+	}
+
+	x1, y1 := c.ScalarBaseMult(s.Bytes())
+	x2, y2 := c.ScalarMult(pub.X, pub.Y, t.Bytes())
+	x, _ := c.Add(x1, y1, x2, y2)
+
+	e := new(big.Int).SetBytes(digest)
+	x.Add(x, e)
+	x.Mod(x, n)
+	return x.Cmp(r) == 0
+}
+
+func padded(v *big.Int, size int) []byte {
+	b := v.Bytes()
+	if len(b) >= size {
+		return b
+	}
+	out := make([]byte, size)
+	copy(out[size-len(b):], b)
+	return out
+}
@@ -0,0 +1,113 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package sm2
+
+import (
+	"crypto/rand"
+	"encoding/asn1"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestP256Sm2(t *testing.T) {
+	c := P256Sm2()
+	params := c.Params()
+
+	// the generator is on the curve and has order n
+	assert.True(t, c.IsOnCurve(params.Gx, params.Gy))
+	x, y := c.ScalarBaseMult(params.N.Bytes())
+	assert.Equal(t, 0, x.Sign())
+	assert.Equal(t, 0, y.Sign())
+}
+
+func TestSignVerify(t *testing.T) {
+	priv, err := GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	assert.True(t, priv.Curve.IsOnCurve(priv.X, priv.Y))
+
+	msg := []byte("message digest")
+	digest, err := Digest(&priv.PublicKey, DefaultUID, msg)
+	assert.NoError(t, err)
+
+	r, s, err := Sign(rand.Reader, priv, digest)
+	assert.NoError(t, err)
+	assert.True(t, Verify(&priv.PublicKey, digest, r, s))
+
+	// the digest depends on the user identifier
+	other, err := Digest(&priv.PublicKey, []byte("ALICE123@YAHOO.COM"), msg)
+	assert.NoError(t, err)
+	assert.False(t, Verify(&priv.PublicKey, other, r, s))
+
+	// tampered signatures are rejected
+	assert.False(t, Verify(&priv.PublicKey, digest, s, r))
+	assert.False(t, Verify(&priv.PublicKey, digest, new(big.Int).Add(r, one), s))
+	assert.False(t, Verify(&priv.PublicKey, digest, big.NewInt(0), s))
+	assert.False(t, Verify(&priv.PublicKey, digest, r, priv.Curve.Params().N))
+
+	// as well as signatures from another key
+	priv2, err := GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	assert.False(t, Verify(&priv2.PublicKey, digest, r, s))
+}
+
+func TestSigner(t *testing.T) {
+	priv, err := GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	assert.Equal(t, &priv.PublicKey, priv.Public())
+
+	digest, err := Digest(&priv.PublicKey, DefaultUID, []byte("hello"))
+	assert.NoError(t, err)
+	raw, err := priv.Sign(rand.Reader, digest, nil)
+	assert.NoError(t, err)
+
+	sig := &signature{}
+	_, err = asn1.Unmarshal(raw, sig)
+	assert.NoError(t, err)
+	assert.True(t, Verify(&priv.PublicKey, digest, sig.R, sig.S))
+}
+
+func TestInvalidArgs(t *testing.T) {
+	priv, err := GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+
+	_, err = ZA(&priv.PublicKey, make([]byte, 8192))
+	assert.Error(t, err)
+
+	bad := &PrivateKey{PublicKey: priv.PublicKey, D: big.NewInt(0)}
+	_, _, err = Sign(rand.Reader, bad, []byte("digest"))
+	assert.Error(t, err)
+}
+
+func TestMarshal(t *testing.T) {
+	priv, err := GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+
+	der, err := MarshalPKIXPublicKey(&priv.PublicKey)
+	assert.NoError(t, err)
+	pub, err := ParsePKIXPublicKey(der)
+	assert.NoError(t, err)
+	assert.Equal(t, priv.X, pub.X)
+	assert.Equal(t, priv.Y, pub.Y)
+
+	der, err = MarshalECPrivateKey(priv)
+	assert.NoError(t, err)
+	priv2, err := ParseECPrivateKey(der)
+	assert.NoError(t, err)
+	assert.Equal(t, priv.D, priv2.D)
+	assert.Equal(t, priv.X, priv2.X)
+
+	_, err = MarshalPKIXPublicKey(nil)
+	assert.Error(t, err)
+	_, err = MarshalECPrivateKey(nil)
+	assert.Error(t, err)
+	_, err = ParsePKIXPublicKey([]byte("garbage"))
+	assert.Error(t, err)
+	_, err = ParseECPrivateKey([]byte("garbage"))
+	assert.Error(t, err)
+}
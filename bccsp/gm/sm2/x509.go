@@ -0,0 +1,121 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package sm2
+
+import (
+	"crypto/elliptic"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+var (
+	// OIDNamedCurveP256Sm2 identifies the curve of SM2 keys
+	OIDNamedCurveP256Sm2 = asn1.ObjectIdentifier{1, 2, 156, 10197, 1, 301}
+
+	oidPublicKeyECDSA = asn1.ObjectIdentifier{1, 2, 840, 10045, 2, 1}
+)
+
+type pkixPublicKey struct {
+	Algo      pkix.AlgorithmIdentifier
+	BitString asn1.BitString
+}
+
+// ecPrivateKey is the SEC 1 structure of an elliptic curve private key
+type ecPrivateKey struct {
+	Version       int
+	PrivateKey    []byte
+	NamedCurveOID asn1.ObjectIdentifier `asn1:"optional,explicit,tag:0"`
+	PublicKey     asn1.BitString        `asn1:"optional,explicit,tag:1"`
+}
+
+// MarshalPKIXPublicKey serialises a public key to the DER-encoded PKIX format
+func MarshalPKIXPublicKey(pub *PublicKey) ([]byte, error) {
+	if pub == nil || pub.X == nil || pub.Y == nil {
+		return nil, errors.New("sm2: invalid public key")
+	}
+	params, err := asn1.Marshal(OIDNamedCurveP256Sm2)
+	if err != nil {
+		return nil, err
+	}
+	point := elliptic.Marshal(pub.Curve, pub.X, pub.Y)
+	return asn1.Marshal(pkixPublicKey{
+		Algo: pkix.AlgorithmIdentifier{
+			Algorithm:  oidPublicKeyECDSA,
+			Parameters: asn1.RawValue{FullBytes: params},
+		},
+		BitString: asn1.BitString{Bytes: point, BitLength: 8 * len(point)},
+	})
+}
+
+// ParsePKIXPublicKey parses a DER-encoded SM2 public key in PKIX format
+func ParsePKIXPublicKey(der []byte) (*PublicKey, error) {
+	var pki pkixPublicKey
+	rest, err := asn1.Unmarshal(der, &pki)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) != 0 {
+		return nil, errors.New("sm2: trailing data after public key")
+	}
+	if !pki.Algo.Algorithm.Equal(oidPublicKeyECDSA) {
+		return nil, errors.New("sm2: not an elliptic curve public key")
+	}
+	var curveOID asn1.ObjectIdentifier
+	if _, err := asn1.Unmarshal(pki.Algo.Parameters.FullBytes, &curveOID); err != nil {
+		return nil, fmt.Errorf("sm2: failed parsing curve [%s]", err)
+	}
+	if !curveOID.Equal(OIDNamedCurveP256Sm2) {
+		return nil, errors.New("sm2: not an SM2 public key")
+	}
+
+	c := P256Sm2()
+	x, y := elliptic.Unmarshal(c, pki.BitString.RightAlign())
+	if x == nil {
+		return nil, errors.New("sm2: invalid public key point")
+	}
+	return &PublicKey{Curve: c, X: x, Y: y}, nil
+}
+
+// MarshalECPrivateKey serialises a private key to the SEC 1, ASN.1 DER form
+func MarshalECPrivateKey(priv *PrivateKey) ([]byte, error) {
+	if priv == nil || priv.D == nil {
+		return nil, errors.New("sm2: invalid private key")
+	}
+	return asn1.Marshal(ecPrivateKey{
+		Version:       1,
+		PrivateKey:    padded(priv.D, (priv.Curve.Params().N.BitLen()+7)/8),
+		NamedCurveOID: OIDNamedCurveP256Sm2,
+		PublicKey:     asn1.BitString{Bytes: elliptic.Marshal(priv.Curve, priv.X, priv.Y)},
+	})
+}
+
+// ParseECPrivateKey parses an SM2 private key in SEC 1, ASN.1 DER form
+func ParseECPrivateKey(der []byte) (*PrivateKey, error) {
+	var privKey ecPrivateKey
+	if _, err := asn1.Unmarshal(der, &privKey); err != nil {
+		return nil, fmt.Errorf("sm2: failed parsing private key [%s]", err)
+	}
+	if privKey.Version != 1 {
+		return nil, fmt.Errorf("sm2: unknown private key version %d", privKey.Version)
+	}
+	if !privKey.NamedCurveOID.Equal(OIDNamedCurveP256Sm2) {
+		return nil, errors.New("sm2: not an SM2 private key")
+	}
+
+	c := P256Sm2()
+	d := new(big.Int).SetBytes(privKey.PrivateKey)
+	if d.Sign() <= 0 || d.Cmp(c.Params().N) >= 0 {
+		return nil, errors.New("sm2: invalid private key value")
+	}
+	priv := &PrivateKey{D: d}
+	priv.Curve = c
+	priv.X, priv.Y = c.ScalarBaseMult(d.Bytes())
+	return priv, nil
+}
@@ -0,0 +1,53 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package sm3
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSM3(t *testing.T) {
+	// examples from GB/T 32905-2016, appendix A
+	sum := Sum([]byte("abc"))
+	assert.Equal(t, "66c7f0f462eeedd9d1f2d46bdc10e4e24167c4875cf2f7a2297da02b8f4ba8e0", hex.EncodeToString(sum[:]))
+
+	sum = Sum([]byte(strings.Repeat("abcd", 16)))
+	assert.Equal(t, "debe9ff92275b8a138604889c18e5a4d6fdb70e5387e5765293dcba39c0c5732", hex.EncodeToString(sum[:]))
+}
+
+func TestSM3Streaming(t *testing.T) {
+	msg := []byte(strings.Repeat("0123456789", 30))
+	expected := Sum(msg)
+
+	// writing in chunks of any size gives the same checksum
+	for _, chunk := range []int{1, 7, 63, 64, 65, 128} {
+		h := New()
+		for i := 0; i < len(msg); i += chunk {
+			end := i + chunk
+			if end > len(msg) {
+				end = len(msg)
+			}
+			h.Write(msg[i:end])
+		}
+		assert.Equal(t, expected[:], h.Sum(nil), "chunk size %d", chunk)
+	}
+
+	// Sum does not change the state of the hash
+	h := New()
+	h.Write(msg[:10])
+	h.Sum(nil)
+	h.Write(msg[10:])
+	assert.Equal(t, expected[:], h.Sum(nil))
+
+	h.Reset()
+	assert.Equal(t, Size, h.Size())
+	assert.Equal(t, BlockSize, h.BlockSize())
+}
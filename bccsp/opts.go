@@ -80,6 +80,15 @@ const (
 	// SHA3_384
 	SHA3_384 = "SHA3_384"
 
+	// SM2 Elliptic Curve Digital Signature Algorithm of GB/T 32918
+	SM2 = "SM2"
+	// SM3 is an identifier for both the SM3 hash function of GB/T 32905
+	// and the hash family made of it
+	SM3 = "SM3"
+
+	// ED25519 Edwards-curve Digital Signature Algorithm of RFC 8032
+	ED25519 = "ED25519"
+
 	// X509Certificate Label for X509 certificate related operation
 	X509Certificate = "X509Certificate"
 )
@@ -25,6 +25,7 @@ import (
 	"fmt"
 	"math/big"
 	"os"
+	"sync"
 
 	"github.com/hyperledger/fabric/bccsp"
 	"github.com/hyperledger/fabric/bccsp/sw"
@@ -68,7 +69,20 @@ func New(opts PKCS11Opts, keyStore bccsp.KeyStore) (bccsp.BCCSP, error) {
 	}
 
 	sessions := make(chan pkcs11.SessionHandle, sessionCacheSize)
-	csp := &impl{swCSP, conf, keyStore, ctx, sessions, slot, lib, opts.Sensitive, opts.SoftVerify}
+	csp := &impl{
+		BCCSP:        swCSP,
+		conf:         conf,
+		ks:           keyStore,
+		ctx:          ctx,
+		sessions:     sessions,
+		slot:         slot,
+		lib:          lib,
+		label:        label,
+		pin:          pin,
+		noPrivImport: opts.Sensitive,
+		softVerify:   opts.SoftVerify,
+		metrics:      newSessionMetrics(label),
+	}
 	csp.returnSession(*session)
 	return csp, nil
 }
@@ -83,6 +97,13 @@ type impl struct {
 	sessions chan pkcs11.SessionHandle
 	slot     uint
 
+	// label and pin are kept to reconnect and log in again
+	// after the token has been restarted
+	label         string
+	pin           string
+	reconnectLock sync.Mutex
+	metrics       *sessionMetrics
+
 	lib          string
 	noPrivImport bool
 	softVerify   bool
@@ -25,6 +25,7 @@ import (
 	"math/big"
 	"sync"
 
+	"github.com/hyperledger/fabric/common/metrics"
 	"github.com/miekg/pkcs11"
 	"github.com/op/go-logging"
 )
@@ -42,25 +43,9 @@ func loadLib(lib, pin, label string) (*pkcs11.Ctx, uint, *pkcs11.SessionHandle,
 	}
 
 	ctx.Initialize()
-	slots, err := ctx.GetSlotList(true)
+	slot, err := findSlot(ctx, label)
 	if err != nil {
-		return nil, slot, nil, fmt.Errorf("Could not get Slot List [%s]", err)
-	}
-	found := false
-	for _, s := range slots {
-		info, err := ctx.GetTokenInfo(s)
-		if err != nil {
-			continue
-		}
-		logger.Debugf("Looking for %s, found label %s\n", label, info.Label)
-		if label == info.Label {
-			found = true
-			slot = s
-			break
-		}
-	}
-	if !found {
-		return nil, slot, nil, fmt.Errorf("Could not find token with label %s", label)
+		return nil, slot, nil, err
 	}
 
 	var session pkcs11.SessionHandle
@@ -90,48 +75,213 @@ func loadLib(lib, pin, label string) (*pkcs11.Ctx, uint, *pkcs11.SessionHandle,
 	return ctx, slot, &session, nil
 }
 
-func (csp *impl) getSession() (session pkcs11.SessionHandle) {
-	select {
-	case session = <-csp.sessions:
-		logger.Debugf("Reusing existing pkcs11 session %+v on slot %d\n", session, csp.slot)
+// findSlot returns the slot of the token with the given label
+func findSlot(ctx *pkcs11.Ctx, label string) (uint, error) {
+	slots, err := ctx.GetSlotList(true)
+	if err != nil {
+		return 0, fmt.Errorf("Could not get Slot List [%s]", err)
+	}
+	for _, s := range slots {
+		info, err := ctx.GetTokenInfo(s)
+		if err != nil {
+			continue
+		}
+		logger.Debugf("Looking for %s, found label %s\n", label, info.Label)
+		if label == info.Label {
+			return s, nil
+		}
+	}
+	return 0, fmt.Errorf("Could not find token with label %s", label)
+}
 
-	default:
-		// cache is empty (or completely in use), create a new session
-		var s pkcs11.SessionHandle
-		var err error = nil
-		for i := 0; i < 10; i++ {
-			s, err = csp.ctx.OpenSession(csp.slot, pkcs11.CKF_SERIAL_SESSION|pkcs11.CKF_RW_SESSION)
-			if err != nil {
-				logger.Warningf("OpenSession failed, retrying [%s]\n", err)
-			} else {
-				break
+// sessionErrors are the errors after which a session, and usually all the
+// others opened on the same token, cannot be used anymore, e.g. because the
+// HSM has been restarted or the connection to a network HSM has been lost
+var sessionErrors = map[pkcs11.Error]bool{
+	pkcs11.Error(pkcs11.CKR_SESSION_HANDLE_INVALID): true,
+	pkcs11.Error(pkcs11.CKR_SESSION_CLOSED):         true,
+	pkcs11.Error(pkcs11.CKR_USER_NOT_LOGGED_IN):     true,
+	pkcs11.Error(pkcs11.CKR_DEVICE_ERROR):           true,
+	pkcs11.Error(pkcs11.CKR_DEVICE_REMOVED):         true,
+	pkcs11.Error(pkcs11.CKR_TOKEN_NOT_PRESENT):      true,
+}
+
+// reconnectErrors are the errors after which the library has to be
+// initialized again before a new session can be opened
+var reconnectErrors = map[pkcs11.Error]bool{
+	pkcs11.Error(pkcs11.CKR_CRYPTOKI_NOT_INITIALIZED): true,
+	pkcs11.Error(pkcs11.CKR_DEVICE_ERROR):             true,
+	pkcs11.Error(pkcs11.CKR_DEVICE_REMOVED):           true,
+	pkcs11.Error(pkcs11.CKR_TOKEN_NOT_PRESENT):        true,
+}
+
+func isSessionError(err error) bool {
+	p11err, ok := err.(pkcs11.Error)
+	return ok && sessionErrors[p11err]
+}
+
+func isReconnectError(err error) bool {
+	p11err, ok := err.(pkcs11.Error)
+	return ok && reconnectErrors[p11err]
+}
+
+// sessionMetrics surfaces the health of the session pool
+type sessionMetrics struct {
+	pooled      metrics.Gauge
+	opened      metrics.Counter
+	invalidated metrics.Counter
+	openFailed  metrics.Counter
+	logins      metrics.Counter
+	reconnects  metrics.Counter
+}
+
+func newSessionMetrics(label string) *sessionMetrics {
+	scope := metrics.NewRootScope().SubScope("bccsp_p11").Tagged(map[string]string{"label": label})
+	return &sessionMetrics{
+		pooled:      scope.Gauge("sessions_pooled"),
+		opened:      scope.Counter("sessions_opened"),
+		invalidated: scope.Counter("sessions_invalidated"),
+		openFailed:  scope.Counter("session_open_failures"),
+		logins:      scope.Counter("logins"),
+		reconnects:  scope.Counter("reconnects"),
+	}
+}
+
+// getSession returns a session from the pool, discarding the pooled sessions
+// which have been invalidated in the meantime, or opens and logs in a new one
+func (csp *impl) getSession() (session pkcs11.SessionHandle, err error) {
+	for {
+		select {
+		case session = <-csp.sessions:
+			csp.metrics.pooled.Update(float64(len(csp.sessions)))
+			if _, err := csp.ctx.GetSessionInfo(session); err != nil {
+				logger.Warningf("Discarding invalid pkcs11 session %+v on slot %d [%s]\n", session, csp.slot, err)
+				csp.metrics.invalidated.Inc(1)
+				csp.ctx.CloseSession(session)
+				if isSessionError(err) {
+					csp.closePooledSessions()
+				}
+				continue
 			}
+			logger.Debugf("Reusing existing pkcs11 session %+v on slot %d\n", session, csp.slot)
+			return session, nil
+
+		default:
+			// cache is empty (or completely in use), create a new session
+			return csp.openSession()
 		}
-		if err != nil {
-			panic(fmt.Errorf("OpenSession failed [%s]\n", err))
+	}
+}
+
+func (csp *impl) openSession() (pkcs11.SessionHandle, error) {
+	var s pkcs11.SessionHandle
+	var err error = nil
+	for i := 0; i < 10; i++ {
+		s, err = csp.ctx.OpenSession(csp.slot, pkcs11.CKF_SERIAL_SESSION|pkcs11.CKF_RW_SESSION)
+		if err == nil {
+			break
+		}
+		logger.Warningf("OpenSession failed, retrying [%s]\n", err)
+		if isReconnectError(err) {
+			if rerr := csp.reconnect(); rerr != nil {
+				logger.Warningf("Reconnecting to the pkcs11 token failed [%s]\n", rerr)
+			}
+		}
+	}
+	if err != nil {
+		csp.metrics.openFailed.Inc(1)
+		return 0, fmt.Errorf("OpenSession failed [%s]\n", err)
+	}
+	csp.metrics.opened.Inc(1)
+	logger.Debugf("Created new pkcs11 session %+v on slot %d\n", s, csp.slot)
+
+	// The login state is lost when the token is restarted,
+	// so a new session might have to log in again
+	err = csp.ctx.Login(s, pkcs11.CKU_USER, csp.pin)
+	if err != nil && err != pkcs11.Error(pkcs11.CKR_USER_ALREADY_LOGGED_IN) {
+		csp.ctx.CloseSession(s)
+		return 0, fmt.Errorf("Login failed [%s]\n", err)
+	}
+	if err == nil {
+		logger.Infof("Logged in to pkcs11 token %s on slot %d\n", csp.label, csp.slot)
+		csp.metrics.logins.Inc(1)
+	}
+	return s, nil
+}
+
+// reconnect initializes the library again and looks the token up,
+// as its slot might have changed
+func (csp *impl) reconnect() error {
+	csp.reconnectLock.Lock()
+	defer csp.reconnectLock.Unlock()
+
+	csp.closePooledSessions()
+	csp.ctx.Finalize()
+	if err := csp.ctx.Initialize(); err != nil && err != pkcs11.Error(pkcs11.CKR_CRYPTOKI_ALREADY_INITIALIZED) {
+		return fmt.Errorf("Initialize failed [%s]", err)
+	}
+	slot, err := findSlot(csp.ctx, csp.label)
+	if err != nil {
+		return err
+	}
+	csp.slot = slot
+	csp.metrics.reconnects.Inc(1)
+	logger.Infof("Reconnected to pkcs11 token %s on slot %d\n", csp.label, slot)
+	return nil
+}
+
+// closePooledSessions drops all the pooled sessions, which are likely
+// unusable once one of them has been invalidated
+func (csp *impl) closePooledSessions() {
+	for {
+		select {
+		case session := <-csp.sessions:
+			csp.metrics.invalidated.Inc(1)
+			csp.ctx.CloseSession(session)
+		default:
+			csp.metrics.pooled.Update(0)
+			return
 		}
-		logger.Debugf("Created new pkcs11 session %+v on slot %d\n", s, csp.slot)
-		session = s
 	}
-	return session
 }
 
 func (csp *impl) returnSession(session pkcs11.SessionHandle) {
 	select {
 	case csp.sessions <- session:
 		// returned session back to session cache
+		csp.metrics.pooled.Update(float64(len(csp.sessions)))
 	default:
 		// have plenty of sessions in cache, dropping
 		csp.ctx.CloseSession(session)
 	}
 }
 
+// handleSessionReturn returns the session to the pool unless the operation
+// it was used for failed because it has been invalidated
+func (csp *impl) handleSessionReturn(err error, session pkcs11.SessionHandle) {
+	if err != nil {
+		if _, infoErr := csp.ctx.GetSessionInfo(session); infoErr != nil {
+			logger.Warningf("Discarding invalid pkcs11 session %+v on slot %d [%s]\n", session, csp.slot, infoErr)
+			csp.metrics.invalidated.Inc(1)
+			csp.ctx.CloseSession(session)
+			if isSessionError(infoErr) {
+				csp.closePooledSessions()
+			}
+			return
+		}
+	}
+	csp.returnSession(session)
+}
+
 // Look for an EC key by SKI, stored in CKA_ID
 // This function can probably be adapted for both EC and RSA keys.
 func (csp *impl) getECKey(ski []byte) (pubKey *ecdsa.PublicKey, isPriv bool, err error) {
 	p11lib := csp.ctx
-	session := csp.getSession()
-	defer csp.returnSession(session)
+	session, err := csp.getSession()
+	if err != nil {
+		return nil, false, err
+	}
+	defer func() { csp.handleSessionReturn(err, session) }()
 	isPriv = true
 	_, err = findKeyPairFromSKI(p11lib, session, ski, privateKeyFlag)
 	if err != nil {
@@ -221,8 +371,11 @@ func oidFromNamedCurve(curve elliptic.Curve) (asn1.ObjectIdentifier, bool) {
 
 func (csp *impl) generateECKey(curve asn1.ObjectIdentifier, ephemeral bool) (ski []byte, pubKey *ecdsa.PublicKey, err error) {
 	p11lib := csp.ctx
-	session := csp.getSession()
-	defer csp.returnSession(session)
+	session, err := csp.getSession()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() { csp.handleSessionReturn(err, session) }()
 
 	id := nextIDCtr()
 	publabel := fmt.Sprintf("BCPUB%s", id.Text(16))
@@ -307,8 +460,11 @@ func (csp *impl) generateECKey(curve asn1.ObjectIdentifier, ephemeral bool) (ski
 
 func (csp *impl) signP11ECDSA(ski []byte, msg []byte) (R, S *big.Int, err error) {
 	p11lib := csp.ctx
-	session := csp.getSession()
-	defer csp.returnSession(session)
+	session, err := csp.getSession()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() { csp.handleSessionReturn(err, session) }()
 
 	privateKey, err := findKeyPairFromSKI(p11lib, session, ski, privateKeyFlag)
 	if err != nil {
@@ -337,8 +493,11 @@ func (csp *impl) signP11ECDSA(ski []byte, msg []byte) (R, S *big.Int, err error)
 
 func (csp *impl) verifyP11ECDSA(ski []byte, msg []byte, R, S *big.Int, byteSize int) (valid bool, err error) {
 	p11lib := csp.ctx
-	session := csp.getSession()
-	defer csp.returnSession(session)
+	session, err := csp.getSession()
+	if err != nil {
+		return false, err
+	}
+	defer func() { csp.handleSessionReturn(err, session) }()
 
 	logger.Debugf("Verify ECDSA\n")
 
@@ -373,8 +532,11 @@ func (csp *impl) verifyP11ECDSA(ski []byte, msg []byte, R, S *big.Int, byteSize
 
 func (csp *impl) importECKey(curve asn1.ObjectIdentifier, privKey, ecPt []byte, ephemeral bool, keyType bool) (ski []byte, err error) {
 	p11lib := csp.ctx
-	session := csp.getSession()
-	defer csp.returnSession(session)
+	session, err := csp.getSession()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { csp.handleSessionReturn(err, session) }()
 
 	id := nextIDCtr()
 
@@ -587,7 +749,11 @@ func listAttrs(p11lib *pkcs11.Ctx, session pkcs11.SessionHandle, obj pkcs11.Obje
 
 func (csp *impl) getSecretValue(ski []byte) []byte {
 	p11lib := csp.ctx
-	session := csp.getSession()
+	session, err := csp.getSession()
+	if err != nil {
+		logger.Warningf("P11: get session [%s]\n", err)
+		return nil
+	}
 	defer csp.returnSession(session)
 
 	keyHandle, err := findKeyPairFromSKI(p11lib, session, ski, privateKeyFlag)
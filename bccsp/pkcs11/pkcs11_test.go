@@ -21,6 +21,7 @@ import (
 	"crypto/rand"
 	"encoding/asn1"
 	"encoding/hex"
+	"errors"
 	"testing"
 
 	"github.com/hyperledger/fabric/bccsp"
@@ -116,7 +117,9 @@ func TestNamedCurveFromOID(t *testing.T) {
 func TestPKCS11GetSession(t *testing.T) {
 	var sessions []pkcs11.SessionHandle
 	for i := 0; i < 3*sessionCacheSize; i++ {
-		sessions = append(sessions, currentBCCSP.(*impl).getSession())
+		session, err := currentBCCSP.(*impl).getSession()
+		assert.NoError(t, err)
+		sessions = append(sessions, session)
 	}
 
 	// Return all sessions, should leave sessionCacheSize cached
@@ -131,13 +134,14 @@ func TestPKCS11GetSession(t *testing.T) {
 
 	// Should be able to get sessionCacheSize cached sessions
 	for i := 0; i < sessionCacheSize; i++ {
-		sessions = append(sessions, currentBCCSP.(*impl).getSession())
+		session, err := currentBCCSP.(*impl).getSession()
+		assert.NoError(t, err)
+		sessions = append(sessions, session)
 	}
 
 	// This one should fail
-	assert.Panics(t, func() {
-		currentBCCSP.(*impl).getSession()
-	}, "Should not been able to create another session")
+	_, err := currentBCCSP.(*impl).getSession()
+	assert.Error(t, err, "Should not been able to create another session")
 
 	// Cleanup
 	for _, session := range sessions {
@@ -146,6 +150,70 @@ func TestPKCS11GetSession(t *testing.T) {
 	currentBCCSP.(*impl).slot = oldSlot
 }
 
+func TestPKCS11GetSessionInvalidated(t *testing.T) {
+	csp := currentBCCSP.(*impl)
+
+	// Fill the pool, then close its sessions behind its back
+	// as an HSM restart would
+	var sessions []pkcs11.SessionHandle
+	for i := 0; i < sessionCacheSize; i++ {
+		session, err := csp.getSession()
+		assert.NoError(t, err)
+		sessions = append(sessions, session)
+	}
+	for _, session := range sessions {
+		csp.returnSession(session)
+	}
+	csp.ctx.CloseAllSessions(csp.slot)
+
+	// A fresh session replaces the invalidated ones
+	session, err := csp.getSession()
+	assert.NoError(t, err)
+	_, err = csp.ctx.GetSessionInfo(session)
+	assert.NoError(t, err)
+	assert.Len(t, csp.sessions, 0)
+	csp.returnSession(session)
+
+	// Signing logs in again transparently
+	k, err := csp.KeyGen(&bccsp.ECDSAKeyGenOpts{Temporary: true})
+	assert.NoError(t, err)
+	csp.ctx.CloseAllSessions(csp.slot)
+	_, err = csp.Sign(k, []byte("0123456789abcdef0123456789abcdef"), nil)
+	assert.NoError(t, err)
+}
+
+func TestPKCS11HandleSessionReturn(t *testing.T) {
+	csp := currentBCCSP.(*impl)
+
+	session, err := csp.getSession()
+	assert.NoError(t, err)
+	other, err := csp.getSession()
+	assert.NoError(t, err)
+	csp.returnSession(other)
+	pooled := len(csp.sessions)
+
+	// A failed operation on a valid session gives it back to the pool
+	csp.handleSessionReturn(errors.New("sign failed"), session)
+	assert.Len(t, csp.sessions, pooled+1)
+
+	// An invalidated session is dropped along with the pooled ones
+	session, err = csp.getSession()
+	assert.NoError(t, err)
+	csp.ctx.CloseSession(session)
+	csp.handleSessionReturn(errors.New("sign failed"), session)
+	assert.Len(t, csp.sessions, 0)
+}
+
+func TestSessionErrors(t *testing.T) {
+	assert.True(t, isSessionError(pkcs11.Error(pkcs11.CKR_SESSION_HANDLE_INVALID)))
+	assert.True(t, isSessionError(pkcs11.Error(pkcs11.CKR_DEVICE_REMOVED)))
+	assert.False(t, isSessionError(pkcs11.Error(pkcs11.CKR_SIGNATURE_INVALID)))
+	assert.False(t, isSessionError(errors.New("not a pkcs11 error")))
+
+	assert.True(t, isReconnectError(pkcs11.Error(pkcs11.CKR_CRYPTOKI_NOT_INITIALIZED)))
+	assert.False(t, isReconnectError(pkcs11.Error(pkcs11.CKR_SESSION_HANDLE_INVALID)))
+}
+
 func TestPKCS11ECKeySignVerify(t *testing.T) {
 	if currentBCCSP.(*impl).noPrivImport {
 		t.Skip("Key import turned off. Skipping Derivation tests as they currently require Key Import.")
@@ -0,0 +1,156 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package sw
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric/bccsp"
+	"github.com/hyperledger/fabric/bccsp/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestED25519SignVerify(t *testing.T) {
+	path, err := ioutil.TempDir("", "ed25519ks")
+	assert.NoError(t, err)
+	defer os.RemoveAll(path)
+	ks, err := NewFileBasedKeyStore(nil, path, false)
+	assert.NoError(t, err)
+	csp, err := New(256, "SHA2", ks)
+	assert.NoError(t, err)
+
+	k, err := csp.KeyGen(&bccsp.ED25519KeyGenOpts{Temporary: false})
+	assert.NoError(t, err)
+	assert.True(t, k.Private())
+	assert.False(t, k.Symmetric())
+	_, err = k.Bytes()
+	assert.Error(t, err)
+
+	pk, err := k.PublicKey()
+	assert.NoError(t, err)
+	assert.Equal(t, k.SKI(), pk.SKI())
+
+	// Ed25519 signs the message itself
+	msg := []byte("Hello World")
+	signature, err := csp.Sign(k, msg, nil)
+	assert.NoError(t, err)
+	assert.True(t, ed25519.Verify(pk.(*ed25519PublicKey).pubKey, msg, signature))
+
+	valid, err := csp.Verify(k, signature, msg, nil)
+	assert.NoError(t, err)
+	assert.True(t, valid)
+
+	valid, err = csp.Verify(pk, signature, msg, nil)
+	assert.NoError(t, err)
+	assert.True(t, valid)
+
+	valid, err = csp.Verify(pk, signature, []byte("Hello World!"), nil)
+	assert.NoError(t, err)
+	assert.False(t, valid)
+
+	_, err = csp.Verify(pk, []byte("garbage"), msg, nil)
+	assert.Error(t, err)
+
+	// the key is found in the keystore
+	k2, err := csp.GetKey(k.SKI())
+	assert.NoError(t, err)
+	valid, err = csp.Verify(k2, signature, msg, nil)
+	assert.NoError(t, err)
+	assert.True(t, valid)
+}
+
+func TestED25519KeyImport(t *testing.T) {
+	csp, err := New(256, "SHA2", NewDummyKeyStore())
+	assert.NoError(t, err)
+
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+
+	der, err := x509.MarshalPKCS8PrivateKey(priv)
+	assert.NoError(t, err)
+	sk, err := csp.KeyImport(der, &bccsp.ED25519PrivateKeyImportOpts{Temporary: true})
+	assert.NoError(t, err)
+	assert.True(t, sk.Private())
+
+	der, err = x509.MarshalPKIXPublicKey(pub)
+	assert.NoError(t, err)
+	pk, err := csp.KeyImport(der, &bccsp.ED25519PKIXPublicKeyImportOpts{Temporary: true})
+	assert.NoError(t, err)
+	assert.Equal(t, sk.SKI(), pk.SKI())
+	raw, err := pk.Bytes()
+	assert.NoError(t, err)
+	assert.Equal(t, der, raw)
+
+	pk, err = csp.KeyImport(pub, &bccsp.ED25519GoPublicKeyImportOpts{Temporary: true})
+	assert.NoError(t, err)
+	assert.Equal(t, sk.SKI(), pk.SKI())
+
+	// Ed25519 certificates are accepted
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "ed25519"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	certRaw, err := x509.CreateCertificate(rand.Reader, template, template, pub, priv)
+	assert.NoError(t, err)
+	cert, err := x509.ParseCertificate(certRaw)
+	assert.NoError(t, err)
+	pk, err = csp.KeyImport(cert, &bccsp.X509PublicKeyImportOpts{Temporary: true})
+	assert.NoError(t, err)
+	assert.Equal(t, sk.SKI(), pk.SKI())
+
+	_, err = csp.KeyImport([]byte("garbage"), &bccsp.ED25519PKIXPublicKeyImportOpts{Temporary: true})
+	assert.Error(t, err)
+	_, err = csp.KeyImport([]byte("garbage"), &bccsp.ED25519PrivateKeyImportOpts{Temporary: true})
+	assert.Error(t, err)
+	_, err = csp.KeyImport(pub[1:], &bccsp.ED25519GoPublicKeyImportOpts{Temporary: true})
+	assert.Error(t, err)
+	_, err = csp.KeyImport("not a key", &bccsp.ED25519GoPublicKeyImportOpts{Temporary: true})
+	assert.Error(t, err)
+}
+
+func TestED25519KeyStore(t *testing.T) {
+	path, err := ioutil.TempDir("", "ed25519ks")
+	assert.NoError(t, err)
+	defer os.RemoveAll(path)
+
+	for _, pwd := range [][]byte{nil, []byte("secret")} {
+		ks, err := NewFileBasedKeyStore(pwd, path, false)
+		assert.NoError(t, err)
+
+		_, priv, err := ed25519.GenerateKey(rand.Reader)
+		assert.NoError(t, err)
+		sk := &ed25519PrivateKey{priv}
+		assert.NoError(t, ks.StoreKey(sk))
+		loaded, err := ks.GetKey(sk.SKI())
+		assert.NoError(t, err)
+		assert.Equal(t, priv, loaded.(*ed25519PrivateKey).privKey)
+
+		otherPub, _, err := ed25519.GenerateKey(rand.Reader)
+		assert.NoError(t, err)
+		pk := &ed25519PublicKey{otherPub}
+		assert.NoError(t, ks.StoreKey(pk))
+		loaded, err = ks.GetKey(pk.SKI())
+		assert.NoError(t, err)
+		assert.Equal(t, otherPub, loaded.(*ed25519PublicKey).pubKey)
+
+		raw, err := utils.PublicKeyToPEM(priv.Public(), pwd)
+		assert.NoError(t, err)
+		pub, err := utils.PEMtoPublicKey(raw, pwd)
+		assert.NoError(t, err)
+		assert.Equal(t, priv.Public(), pub)
+	}
+}
@@ -0,0 +1,84 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package sw
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"hash"
+
+	"github.com/hyperledger/fabric/bccsp"
+	"github.com/hyperledger/fabric/bccsp/gm/sm2"
+)
+
+// SM2 signatures share the ASN.1 encoding of ECDSA ones, but are
+// not malleable the same way, so no low-S normalization applies.
+func signSM2(k *sm2.PrivateKey, digest []byte, opts bccsp.SignerOpts) (signature []byte, err error) {
+	r, s, err := sm2.Sign(rand.Reader, k, digest)
+	if err != nil {
+		return nil, err
+	}
+
+	return MarshalECDSASignature(r, s)
+}
+
+func verifySM2(k *sm2.PublicKey, signature, digest []byte, opts bccsp.SignerOpts) (valid bool, err error) {
+	r, s, err := UnmarshalECDSASignature(signature)
+	if err != nil {
+		return false, fmt.Errorf("Failed unmashalling signature [%s]", err)
+	}
+
+	return sm2.Verify(k, digest, r, s), nil
+}
+
+type sm2Signer struct{}
+
+func (s *sm2Signer) Sign(k bccsp.Key, digest []byte, opts bccsp.SignerOpts) (signature []byte, err error) {
+	return signSM2(k.(*sm2PrivateKey).privKey, digest, opts)
+}
+
+type sm2PrivateKeyVerifier struct{}
+
+func (v *sm2PrivateKeyVerifier) Verify(k bccsp.Key, signature, digest []byte, opts bccsp.SignerOpts) (valid bool, err error) {
+	return verifySM2(&(k.(*sm2PrivateKey).privKey.PublicKey), signature, digest, opts)
+}
+
+type sm2PublicKeyKeyVerifier struct{}
+
+func (v *sm2PublicKeyKeyVerifier) Verify(k bccsp.Key, signature, digest []byte, opts bccsp.SignerOpts) (valid bool, err error) {
+	return verifySM2(k.(*sm2PublicKey).pubKey, signature, digest, opts)
+}
+
+// sm2Digester computes the digest signed by SM2, which binds
+// the user identifier and the public key of the signer
+type sm2Digester struct{}
+
+func (c *sm2Digester) Hash(msg []byte, opts bccsp.HashOpts) (hash []byte, err error) {
+	digestOpts := opts.(*bccsp.SM2DigestOpts)
+	if digestOpts.Key == nil {
+		return nil, errors.New("Invalid key. It must not be nil.")
+	}
+	pk, err := digestOpts.Key.PublicKey()
+	if err != nil {
+		return nil, err
+	}
+	sm2PK, ok := pk.(*sm2PublicKey)
+	if !ok {
+		return nil, errors.New("Invalid key. It must be an SM2 key.")
+	}
+
+	uid := digestOpts.UID
+	if len(uid) == 0 {
+		uid = sm2.DefaultUID
+	}
+	return sm2.Digest(sm2PK.pubKey, uid, msg)
+}
+
+func (c *sm2Digester) GetHash(opts bccsp.HashOpts) (h hash.Hash, err error) {
+	return nil, errors.New("Not supported. The SM2 digest must be computed at once.")
+}
@@ -0,0 +1,189 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package sw
+
+import (
+	"crypto/rand"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/hyperledger/fabric/bccsp"
+	"github.com/hyperledger/fabric/bccsp/gm/sm2"
+	"github.com/hyperledger/fabric/bccsp/gm/sm3"
+	"github.com/hyperledger/fabric/bccsp/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func newSM3BCCSP(t *testing.T) (bccsp.BCCSP, string) {
+	path, err := ioutil.TempDir("", "sm2ks")
+	assert.NoError(t, err)
+	ks, err := NewFileBasedKeyStore(nil, path, false)
+	assert.NoError(t, err)
+	csp, err := New(256, "SM3", ks)
+	assert.NoError(t, err)
+	return csp, path
+}
+
+func TestSM3HashFamily(t *testing.T) {
+	csp, path := newSM3BCCSP(t)
+	defer os.RemoveAll(path)
+
+	msg := []byte("Hello World")
+	expected := sm3.Sum(msg)
+
+	// SM3 is the default hash function of the SM3 family
+	digest, err := csp.Hash(msg, &bccsp.SHAOpts{})
+	assert.NoError(t, err)
+	assert.Equal(t, expected[:], digest)
+
+	digest, err = csp.Hash(msg, &bccsp.SM3Opts{})
+	assert.NoError(t, err)
+	assert.Equal(t, expected[:], digest)
+
+	h, err := csp.GetHash(&bccsp.SM3Opts{})
+	assert.NoError(t, err)
+	h.Write(msg)
+	assert.Equal(t, expected[:], h.Sum(nil))
+
+	_, err = New(384, "SM3", NewDummyKeyStore())
+	assert.Error(t, err)
+}
+
+func TestSM2SignVerify(t *testing.T) {
+	csp, path := newSM3BCCSP(t)
+	defer os.RemoveAll(path)
+
+	k, err := csp.KeyGen(&bccsp.SM2KeyGenOpts{Temporary: false})
+	assert.NoError(t, err)
+	assert.True(t, k.Private())
+	assert.False(t, k.Symmetric())
+	_, err = k.Bytes()
+	assert.Error(t, err)
+
+	pk, err := k.PublicKey()
+	assert.NoError(t, err)
+	assert.Equal(t, k.SKI(), pk.SKI())
+
+	msg := []byte("Hello World")
+	digest, err := csp.Hash(msg, &bccsp.SM2DigestOpts{Key: k})
+	assert.NoError(t, err)
+	expected, err := sm2.Digest(pk.(*sm2PublicKey).pubKey, sm2.DefaultUID, msg)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, digest)
+
+	signature, err := csp.Sign(k, digest, nil)
+	assert.NoError(t, err)
+
+	valid, err := csp.Verify(k, signature, digest, nil)
+	assert.NoError(t, err)
+	assert.True(t, valid)
+
+	valid, err = csp.Verify(pk, signature, digest, nil)
+	assert.NoError(t, err)
+	assert.True(t, valid)
+
+	// a digest bound to another user identifier does not verify
+	other, err := csp.Hash(msg, &bccsp.SM2DigestOpts{Key: pk, UID: []byte("another user")})
+	assert.NoError(t, err)
+	valid, err = csp.Verify(pk, signature, other, nil)
+	assert.NoError(t, err)
+	assert.False(t, valid)
+
+	_, err = csp.Verify(pk, []byte("garbage"), digest, nil)
+	assert.Error(t, err)
+
+	// the key is found in the keystore
+	k2, err := csp.GetKey(k.SKI())
+	assert.NoError(t, err)
+	valid, err = csp.Verify(k2, signature, digest, nil)
+	assert.NoError(t, err)
+	assert.True(t, valid)
+}
+
+func TestSM2DigestInvalidKey(t *testing.T) {
+	csp, path := newSM3BCCSP(t)
+	defer os.RemoveAll(path)
+
+	_, err := csp.Hash([]byte("msg"), &bccsp.SM2DigestOpts{})
+	assert.Error(t, err)
+
+	k, err := csp.KeyGen(&bccsp.ECDSAP256KeyGenOpts{Temporary: true})
+	assert.NoError(t, err)
+	_, err = csp.Hash([]byte("msg"), &bccsp.SM2DigestOpts{Key: k})
+	assert.Error(t, err)
+
+	_, err = csp.GetHash(&bccsp.SM2DigestOpts{})
+	assert.Error(t, err)
+}
+
+func TestSM2KeyImport(t *testing.T) {
+	csp, path := newSM3BCCSP(t)
+	defer os.RemoveAll(path)
+
+	lowLevelKey, err := sm2.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+
+	der, err := sm2.MarshalECPrivateKey(lowLevelKey)
+	assert.NoError(t, err)
+	sk, err := csp.KeyImport(der, &bccsp.SM2PrivateKeyImportOpts{Temporary: true})
+	assert.NoError(t, err)
+	assert.True(t, sk.Private())
+
+	der, err = sm2.MarshalPKIXPublicKey(&lowLevelKey.PublicKey)
+	assert.NoError(t, err)
+	pk, err := csp.KeyImport(der, &bccsp.SM2PKIXPublicKeyImportOpts{Temporary: true})
+	assert.NoError(t, err)
+	assert.Equal(t, sk.SKI(), pk.SKI())
+	raw, err := pk.Bytes()
+	assert.NoError(t, err)
+	assert.Equal(t, der, raw)
+
+	pk, err = csp.KeyImport(&lowLevelKey.PublicKey, &bccsp.SM2GoPublicKeyImportOpts{Temporary: true})
+	assert.NoError(t, err)
+	assert.Equal(t, sk.SKI(), pk.SKI())
+
+	_, err = csp.KeyImport([]byte("garbage"), &bccsp.SM2PKIXPublicKeyImportOpts{Temporary: true})
+	assert.Error(t, err)
+	_, err = csp.KeyImport([]byte("garbage"), &bccsp.SM2PrivateKeyImportOpts{Temporary: true})
+	assert.Error(t, err)
+	_, err = csp.KeyImport("not a key", &bccsp.SM2GoPublicKeyImportOpts{Temporary: true})
+	assert.Error(t, err)
+}
+
+func TestSM2KeyStore(t *testing.T) {
+	path, err := ioutil.TempDir("", "sm2ks")
+	assert.NoError(t, err)
+	defer os.RemoveAll(path)
+
+	for _, pwd := range [][]byte{nil, []byte("secret")} {
+		ks, err := NewFileBasedKeyStore(pwd, path, false)
+		assert.NoError(t, err)
+
+		lowLevelKey, err := sm2.GenerateKey(rand.Reader)
+		assert.NoError(t, err)
+		sk := &sm2PrivateKey{lowLevelKey}
+		assert.NoError(t, ks.StoreKey(sk))
+		loaded, err := ks.GetKey(sk.SKI())
+		assert.NoError(t, err)
+		assert.Equal(t, lowLevelKey.D, loaded.(*sm2PrivateKey).privKey.D)
+
+		otherKey, err := sm2.GenerateKey(rand.Reader)
+		assert.NoError(t, err)
+		pk := &sm2PublicKey{&otherKey.PublicKey}
+		assert.NoError(t, ks.StoreKey(pk))
+		loaded, err = ks.GetKey(pk.SKI())
+		assert.NoError(t, err)
+		assert.Equal(t, otherKey.X, loaded.(*sm2PublicKey).pubKey.X)
+
+		raw, err := utils.PublicKeyToPEM(&lowLevelKey.PublicKey, pwd)
+		assert.NoError(t, err)
+		pub, err := utils.PEMtoPublicKey(raw, pwd)
+		assert.NoError(t, err)
+		assert.Equal(t, lowLevelKey.X, pub.(*sm2.PublicKey).X)
+	}
+}
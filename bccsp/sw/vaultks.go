@@ -0,0 +1,209 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package sw
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"hash"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/hyperledger/fabric/bccsp"
+)
+
+const (
+	// VaultDataKeyFile is the name of the file holding the wrapped
+	// data encryption key of a vault keystore
+	VaultDataKeyFile = "vault.dek"
+
+	vaultDataKeyPEMType = "WRAPPED DATA KEY"
+	vaultDataKeyLength  = 32
+)
+
+// KeyWrapper protects the data encryption key of a vault keystore, e.g. by
+// encrypting it under a key derived from a password or by handing it to an
+// external key management service.
+type KeyWrapper interface {
+	// Wrap encrypts the given data encryption key
+	Wrap(dek []byte) ([]byte, error)
+
+	// Unwrap decrypts a data encryption key previously encrypted by Wrap
+	Unwrap(wrapped []byte) ([]byte, error)
+}
+
+// NewVaultKeyStore instantiates a file-based key store at a given position,
+// whose keys are encrypted at rest under a random data encryption key. The
+// data encryption key is generated the first time the key store is opened
+// and is stored next to the keys, wrapped by the given KeyWrapper.
+// Keys stored unencrypted in the folder, e.g. before switching to a vault
+// keystore, can still be loaded.
+func NewVaultKeyStore(wrapper KeyWrapper, path string, readOnly bool) (bccsp.KeyStore, error) {
+	if wrapper == nil {
+		return nil, errors.New("Invalid KeyWrapper. It must not be nil.")
+	}
+	if len(path) == 0 {
+		return nil, errors.New("An invalid KeyStore path provided. Path cannot be an empty string.")
+	}
+
+	dek, err := loadVaultDataKey(wrapper, path, readOnly)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewFileBasedKeyStore(dek, path, readOnly)
+}
+
+func loadVaultDataKey(wrapper KeyWrapper, path string, readOnly bool) ([]byte, error) {
+	dekPath := filepath.Join(path, VaultDataKeyFile)
+
+	raw, err := ioutil.ReadFile(dekPath)
+	if os.IsNotExist(err) {
+		if readOnly {
+			return nil, fmt.Errorf("Vault data key not found at [%s]", dekPath)
+		}
+		return createVaultDataKey(wrapper, path, dekPath)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Failed reading vault data key at [%s]: [%s]", dekPath, err)
+	}
+
+	block, _ := pem.Decode(raw)
+	if block == nil || block.Type != vaultDataKeyPEMType {
+		return nil, fmt.Errorf("Invalid vault data key at [%s]", dekPath)
+	}
+	dek, err := wrapper.Unwrap(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("Failed unwrapping vault data key at [%s]: [%s]", dekPath, err)
+	}
+	return dek, nil
+}
+
+func createVaultDataKey(wrapper KeyWrapper, path, dekPath string) ([]byte, error) {
+	dek := make([]byte, vaultDataKeyLength)
+	if _, err := rand.Read(dek); err != nil {
+		return nil, fmt.Errorf("Failed generating vault data key: [%s]", err)
+	}
+	wrapped, err := wrapper.Wrap(dek)
+	if err != nil {
+		return nil, fmt.Errorf("Failed wrapping vault data key: [%s]", err)
+	}
+
+	if err := os.MkdirAll(path, 0755); err != nil {
+		return nil, err
+	}
+	raw := pem.EncodeToMemory(&pem.Block{Type: vaultDataKeyPEMType, Bytes: wrapped})
+	if err := ioutil.WriteFile(dekPath, raw, 0600); err != nil {
+		return nil, fmt.Errorf("Failed storing vault data key at [%s]: [%s]", dekPath, err)
+	}
+	return dek, nil
+}
+
+const (
+	passwordSaltLength = 16
+	passwordIterations = 100000
+)
+
+// NewPasswordKeyWrapper returns a KeyWrapper encrypting data encryption keys
+// with AES-GCM under a key derived from the given password with PBKDF2
+func NewPasswordKeyWrapper(pwd []byte) (KeyWrapper, error) {
+	if len(pwd) == 0 {
+		return nil, errors.New("Invalid password. It must not be empty.")
+	}
+	return &passwordKeyWrapper{pwd: pwd}, nil
+}
+
+type passwordKeyWrapper struct {
+	pwd []byte
+}
+
+// Wrap returns the salt, the nonce and the sealed data encryption key
+func (w *passwordKeyWrapper) Wrap(dek []byte) ([]byte, error) {
+	salt := make([]byte, passwordSaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	aead, err := w.aead(salt)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	wrapped := append(salt, nonce...)
+	return aead.Seal(wrapped, nonce, dek, nil), nil
+}
+
+func (w *passwordKeyWrapper) Unwrap(wrapped []byte) ([]byte, error) {
+	if len(wrapped) < passwordSaltLength {
+		return nil, errors.New("Invalid wrapped key. It is too short.")
+	}
+	aead, err := w.aead(wrapped[:passwordSaltLength])
+	if err != nil {
+		return nil, err
+	}
+	wrapped = wrapped[passwordSaltLength:]
+	if len(wrapped) < aead.NonceSize() {
+		return nil, errors.New("Invalid wrapped key. It is too short.")
+	}
+	dek, err := aead.Open(nil, wrapped[:aead.NonceSize()], wrapped[aead.NonceSize():], nil)
+	if err != nil {
+		return nil, errors.New("Failed decrypting wrapped key. Wrong password?")
+	}
+	return dek, nil
+}
+
+func (w *passwordKeyWrapper) aead(salt []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(pbkdf2(w.pwd, salt, passwordIterations, 32, sha256.New))
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// pbkdf2 derives a key from a password as specified by PKCS #5 v2.0 (RFC 8018)
+func pbkdf2(pwd, salt []byte, iter, keyLen int, h func() hash.Hash) []byte {
+	prf := hmac.New(h, pwd)
+	hashLen := prf.Size()
+	numBlocks := (keyLen + hashLen - 1) / hashLen
+
+	var buf [4]byte
+	dk := make([]byte, 0, numBlocks*hashLen)
+	u := make([]byte, hashLen)
+	for block := 1; block <= numBlocks; block++ {
+		// U_1 = PRF(password, salt || INT(block))
+		prf.Reset()
+		prf.Write(salt)
+		binary.BigEndian.PutUint32(buf[:], uint32(block))
+		prf.Write(buf[:4])
+		dk = prf.Sum(dk)
+		t := dk[len(dk)-hashLen:]
+		copy(u, t)
+
+		// U_n = PRF(password, U_(n-1)), T = U_1 xor U_2 xor ... xor U_iter
+		for n := 2; n <= iter; n++ {
+			prf.Reset()
+			prf.Write(u)
+			u = u[:0]
+			u = prf.Sum(u)
+			for x := range u {
+				t[x] ^= u[x]
+			}
+		}
+	}
+	return dk[:keyLen]
+}
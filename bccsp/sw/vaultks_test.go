@@ -0,0 +1,131 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package sw
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestVaultKeyStore(t *testing.T, path, pwd string) (*fileBasedKeyStore, error) {
+	wrapper, err := NewPasswordKeyWrapper([]byte(pwd))
+	assert.NoError(t, err)
+	ks, err := NewVaultKeyStore(wrapper, path, false)
+	if err != nil {
+		return nil, err
+	}
+	return ks.(*fileBasedKeyStore), nil
+}
+
+func TestVaultKeyStore(t *testing.T) {
+	path, err := ioutil.TempDir("", "vaultks")
+	assert.NoError(t, err)
+	defer os.RemoveAll(path)
+
+	ks, err := newTestVaultKeyStore(t, path, "secret")
+	assert.NoError(t, err)
+	assert.False(t, ks.ReadOnly())
+
+	privKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	key := &ecdsaPrivateKey{privKey}
+	assert.NoError(t, ks.StoreKey(key))
+
+	// the private key must be encrypted at rest
+	raw, err := ioutil.ReadFile(ks.getPathForAlias(hex.EncodeToString(key.SKI()), "sk"))
+	assert.NoError(t, err)
+	block, _ := pem.Decode(raw)
+	assert.NotNil(t, block)
+	assert.True(t, x509.IsEncryptedPEMBlock(block))
+
+	// reopening the keystore with the same password gives the key back
+	ks, err = newTestVaultKeyStore(t, path, "secret")
+	assert.NoError(t, err)
+	loaded, err := ks.GetKey(key.SKI())
+	assert.NoError(t, err)
+	assert.Equal(t, key.SKI(), loaded.SKI())
+	assert.True(t, loaded.Private())
+
+	// a wrong password cannot unwrap the data encryption key
+	_, err = newTestVaultKeyStore(t, path, "wrong")
+	assert.Error(t, err)
+}
+
+func TestVaultKeyStorePlaintextKeys(t *testing.T) {
+	path, err := ioutil.TempDir("", "vaultks")
+	assert.NoError(t, err)
+	defer os.RemoveAll(path)
+
+	// keys stored before switching to a vault keystore remain readable
+	plainKS, err := NewFileBasedKeyStore(nil, path, false)
+	assert.NoError(t, err)
+	privKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	key := &ecdsaPrivateKey{privKey}
+	assert.NoError(t, plainKS.StoreKey(key))
+
+	ks, err := newTestVaultKeyStore(t, path, "secret")
+	assert.NoError(t, err)
+	loaded, err := ks.GetKey(key.SKI())
+	assert.NoError(t, err)
+	assert.Equal(t, key.SKI(), loaded.SKI())
+}
+
+func TestVaultKeyStoreInvalidArgs(t *testing.T) {
+	wrapper, err := NewPasswordKeyWrapper([]byte("secret"))
+	assert.NoError(t, err)
+
+	_, err = NewVaultKeyStore(nil, os.TempDir(), false)
+	assert.Error(t, err)
+
+	_, err = NewVaultKeyStore(wrapper, "", false)
+	assert.Error(t, err)
+
+	_, err = NewPasswordKeyWrapper(nil)
+	assert.Error(t, err)
+
+	path, err := ioutil.TempDir("", "vaultks")
+	assert.NoError(t, err)
+	defer os.RemoveAll(path)
+
+	// a read only keystore cannot create its data encryption key
+	_, err = NewVaultKeyStore(wrapper, path, true)
+	assert.Error(t, err)
+
+	// the data encryption key file must be valid
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(path, VaultDataKeyFile), []byte("garbage"), 0600))
+	_, err = NewVaultKeyStore(wrapper, path, false)
+	assert.Error(t, err)
+
+	raw := pem.EncodeToMemory(&pem.Block{Type: vaultDataKeyPEMType, Bytes: []byte{1, 2, 3}})
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(path, VaultDataKeyFile), raw, 0600))
+	_, err = NewVaultKeyStore(wrapper, path, false)
+	assert.Error(t, err)
+}
+
+func TestPBKDF2(t *testing.T) {
+	// test vectors from RFC 6070
+	dk := pbkdf2([]byte("password"), []byte("salt"), 1, 20, sha1.New)
+	assert.Equal(t, "0c60c80f961f0e71f3a9b524af6012062fe037a6", hex.EncodeToString(dk))
+
+	dk = pbkdf2([]byte("password"), []byte("salt"), 4096, 20, sha1.New)
+	assert.Equal(t, "4b007901b765489abead49d926f721d065a429c1", hex.EncodeToString(dk))
+
+	dk = pbkdf2([]byte("passwordPASSWORDpassword"), []byte("saltSALTsaltSALTsaltSALTsaltSALTsalt"), 4096, 25, sha1.New)
+	assert.Equal(t, "3d2eec4fe41c849b80c8d83662c0e44a8b291a964cf2f07038", hex.EncodeToString(dk))
+}
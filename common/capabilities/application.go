@@ -0,0 +1,102 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package capabilities
+
+import (
+	cb "github.com/hyperledger/fabric/protos/common"
+)
+
+const (
+	applicationTypeName = "Application"
+
+	// ApplicationV1_1 is the capabilities string for the standard new non-backwards
+	// compatible application capabilities.
+	ApplicationV1_1 = "V1_1"
+
+	// ApplicationV1_2 is the capabilities string for the application capabilities which
+	// make the reads of the chaincodes within a simulation observe the writes made earlier
+	// in the same simulation, it implies ApplicationV1_1.
+	ApplicationV1_2 = "V1_2"
+
+	// ApplicationV1_3 is the capabilities string for the application capabilities which
+	// enable the key level endorsement policies, the collection configs of the chaincodes
+	// deployed or upgraded through lscc and the _lifecycle system chaincode, it implies
+	// ApplicationV1_2.
+	ApplicationV1_3 = "V1_3"
+)
+
+// ApplicationProvider provides capabilities information for application level config.
+type ApplicationProvider struct {
+	*registry
+	v11 bool
+	v12 bool
+	v13 bool
+}
+
+// NewApplicationProvider creates an application capabilities provider.
+func NewApplicationProvider(capabilities map[string]*cb.Capability) *ApplicationProvider {
+	ap := &ApplicationProvider{}
+	ap.registry = newRegistry(ap, capabilities)
+	ap.v11 = ap.required(ApplicationV1_1)
+	ap.v12 = ap.required(ApplicationV1_2)
+	ap.v13 = ap.required(ApplicationV1_3)
+	return ap
+}
+
+// Type returns a descriptive string for logging purposes.
+func (ap *ApplicationProvider) Type() string {
+	return applicationTypeName
+}
+
+// HasCapability returns true if the capability is supported by this binary.
+func (ap *ApplicationProvider) HasCapability(capability string) bool {
+	switch capability {
+	// Add new capability names here
+	case ApplicationV1_1:
+		return true
+	case ApplicationV1_2:
+		return true
+	case ApplicationV1_3:
+		return true
+	default:
+		return false
+	}
+}
+
+// ForbidDuplicateTXIdInBlock specifies whether two transactions with the same
+// TXId are permitted in the same block, or whether the latter is marked invalid.
+func (ap *ApplicationProvider) ForbidDuplicateTXIdInBlock() bool {
+	return ap.v11 || ap.v12 || ap.v13
+}
+
+// ReadYourWrites specifies whether the reads of a chaincode within a simulation observe
+// the writes the chaincode made earlier in the same simulation, rather than the committed state.
+func (ap *ApplicationProvider) ReadYourWrites() bool {
+	return ap.v12 || ap.v13
+}
+
+// KeyLevelEndorsement specifies whether the writes to the keys carrying a validation
+// parameter in their metadata are validated against the endorsement policy of the key,
+// and whether the writes to the keys whose metadata a preceding transaction of the block
+// updated are invalidated.
+func (ap *ApplicationProvider) KeyLevelEndorsement() bool {
+	return ap.v13
+}
+
+// CollectionUpgrade specifies whether the chaincodes deployed or upgraded through lscc
+// may carry collection configs, and whether the updates of the collection configs
+// upon chaincode upgrade are validated.
+func (ap *ApplicationProvider) CollectionUpgrade() bool {
+	return ap.v13
+}
+
+// Lifecycle specifies whether the chaincode definitions may be approved and committed
+// through the _lifecycle system chaincode, and whether the committed ones take
+// precedence over the ones of lscc when validating the transactions.
+func (ap *ApplicationProvider) Lifecycle() bool {
+	return ap.v13
+}
@@ -0,0 +1,84 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package capabilities
+
+import (
+	"testing"
+
+	cb "github.com/hyperledger/fabric/protos/common"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChannelProvider(t *testing.T) {
+	cp := NewChannelProvider(nil)
+	assert.NoError(t, cp.Supported())
+	assert.Equal(t, "Channel", cp.Type())
+
+	assert.False(t, cp.MerkleDataHash())
+
+	cp = NewChannelProvider(map[string]*cb.Capability{ChannelV1_1: {}})
+	assert.NoError(t, cp.Supported())
+	assert.False(t, cp.MerkleDataHash())
+
+	cp = NewChannelProvider(map[string]*cb.Capability{ChannelV1_2: {}})
+	assert.NoError(t, cp.Supported())
+	assert.True(t, cp.MerkleDataHash())
+
+	cp = NewChannelProvider(map[string]*cb.Capability{ChannelV1_1: {}, "V9_9": {}})
+	assert.EqualError(t, cp.Supported(), "Channel capability V9_9 is required but not supported")
+}
+
+func TestOrdererProvider(t *testing.T) {
+	op := NewOrdererProvider(nil)
+	assert.NoError(t, op.Supported())
+	assert.False(t, op.ExpirationCheck())
+
+	op = NewOrdererProvider(map[string]*cb.Capability{OrdererV1_1: {}})
+	assert.NoError(t, op.Supported())
+	assert.True(t, op.ExpirationCheck())
+	assert.False(t, op.ConsensusTypeMigration())
+
+	op = NewOrdererProvider(map[string]*cb.Capability{OrdererV1_2: {}})
+	assert.NoError(t, op.Supported())
+	assert.True(t, op.ExpirationCheck())
+	assert.True(t, op.ConsensusTypeMigration())
+
+	op = NewOrdererProvider(map[string]*cb.Capability{"V9_9": {}})
+	assert.EqualError(t, op.Supported(), "Orderer capability V9_9 is required but not supported")
+	assert.False(t, op.ExpirationCheck())
+}
+
+func TestApplicationProvider(t *testing.T) {
+	ap := NewApplicationProvider(map[string]*cb.Capability{})
+	assert.NoError(t, ap.Supported())
+	assert.False(t, ap.ForbidDuplicateTXIdInBlock())
+
+	ap = NewApplicationProvider(map[string]*cb.Capability{ApplicationV1_1: {}})
+	assert.NoError(t, ap.Supported())
+	assert.True(t, ap.ForbidDuplicateTXIdInBlock())
+	assert.False(t, ap.ReadYourWrites())
+
+	ap = NewApplicationProvider(map[string]*cb.Capability{ApplicationV1_2: {}})
+	assert.NoError(t, ap.Supported())
+	assert.True(t, ap.ForbidDuplicateTXIdInBlock())
+	assert.True(t, ap.ReadYourWrites())
+	assert.False(t, ap.KeyLevelEndorsement())
+	assert.False(t, ap.CollectionUpgrade())
+	assert.False(t, ap.Lifecycle())
+
+	ap = NewApplicationProvider(map[string]*cb.Capability{ApplicationV1_3: {}})
+	assert.NoError(t, ap.Supported())
+	assert.True(t, ap.ForbidDuplicateTXIdInBlock())
+	assert.True(t, ap.ReadYourWrites())
+	assert.True(t, ap.KeyLevelEndorsement())
+	assert.True(t, ap.CollectionUpgrade())
+	assert.True(t, ap.Lifecycle())
+
+	ap = NewApplicationProvider(map[string]*cb.Capability{"V9_9": {}})
+	assert.EqualError(t, ap.Supported(), "Application capability V9_9 is required but not supported")
+}
@@ -0,0 +1,62 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package capabilities
+
+import (
+	cb "github.com/hyperledger/fabric/protos/common"
+)
+
+const (
+	channelTypeName = "Channel"
+
+	// ChannelV1_1 is the capabilities string for the standard new non-backwards
+	// compatible channel capabilities, required of both the orderers and the peers.
+	ChannelV1_1 = "V1_1"
+
+	// ChannelV1_2 is the capabilities string for the channel capabilities which
+	// commit the header of the blocks to the Merkle root of their data, it implies
+	// ChannelV1_1.
+	ChannelV1_2 = "V1_2"
+)
+
+// ChannelProvider provides capabilities information for channel level config.
+type ChannelProvider struct {
+	*registry
+	v12 bool
+}
+
+// NewChannelProvider creates a channel capabilities provider.
+func NewChannelProvider(capabilities map[string]*cb.Capability) *ChannelProvider {
+	cp := &ChannelProvider{}
+	cp.registry = newRegistry(cp, capabilities)
+	cp.v12 = cp.required(ChannelV1_2)
+	return cp
+}
+
+// Type returns a descriptive string for logging purposes.
+func (cp *ChannelProvider) Type() string {
+	return channelTypeName
+}
+
+// HasCapability returns true if the capability is supported by this binary.
+func (cp *ChannelProvider) HasCapability(capability string) bool {
+	switch capability {
+	// Add new capability names here
+	case ChannelV1_1:
+		return true
+	case ChannelV1_2:
+		return true
+	default:
+		return false
+	}
+}
+
+// MerkleDataHash specifies whether the data hash of the header of the blocks
+// is the Merkle root of the block data, rather than the hash of the whole data.
+func (cp *ChannelProvider) MerkleDataHash() bool {
+	return cp.v12
+}
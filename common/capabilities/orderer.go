@@ -0,0 +1,69 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package capabilities
+
+import (
+	cb "github.com/hyperledger/fabric/protos/common"
+)
+
+const (
+	ordererTypeName = "Orderer"
+
+	// OrdererV1_1 is the capabilities string for the standard new non-backwards
+	// compatible orderer capabilities.
+	OrdererV1_1 = "V1_1"
+
+	// OrdererV1_2 is the capabilities string for the orderer capabilities which
+	// allow migrating the consensus type of a channel, it implies OrdererV1_1.
+	OrdererV1_2 = "V1_2"
+)
+
+// OrdererProvider provides capabilities information for orderer level config.
+type OrdererProvider struct {
+	*registry
+	v11 bool
+	v12 bool
+}
+
+// NewOrdererProvider creates an orderer capabilities provider.
+func NewOrdererProvider(capabilities map[string]*cb.Capability) *OrdererProvider {
+	op := &OrdererProvider{}
+	op.registry = newRegistry(op, capabilities)
+	op.v12 = op.required(OrdererV1_2)
+	op.v11 = op.v12 || op.required(OrdererV1_1)
+	return op
+}
+
+// Type returns a descriptive string for logging purposes.
+func (op *OrdererProvider) Type() string {
+	return ordererTypeName
+}
+
+// HasCapability returns true if the capability is supported by this binary.
+func (op *OrdererProvider) HasCapability(capability string) bool {
+	switch capability {
+	// Add new capability names here
+	case OrdererV1_1:
+		return true
+	case OrdererV1_2:
+		return true
+	default:
+		return false
+	}
+}
+
+// ExpirationCheck specifies whether the orderer checks for identity
+// expiration when validating the messages broadcast to the channel.
+func (op *OrdererProvider) ExpirationCheck() bool {
+	return op.v11
+}
+
+// ConsensusTypeMigration specifies whether the channel may be put in maintenance
+// mode, in which its consensus type may be changed.
+func (op *OrdererProvider) ConsensusTypeMigration() bool {
+	return op.v12
+}
@@ -18,9 +18,12 @@ package cauthdsl
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/common/policies"
 	"github.com/hyperledger/fabric/msp"
 	cb "github.com/hyperledger/fabric/protos/common"
 	mb "github.com/hyperledger/fabric/protos/msp"
@@ -32,29 +35,42 @@ var cauthdslLogger = flogging.MustGetLogger("cauthdsl")
 
 // compile recursively builds a go evaluatable function corresponding to the policy specified
 func compile(policy *cb.SignaturePolicy, identities []*mb.MSPPrincipal, deserializer msp.IdentityDeserializer) (func([]*cb.SignedData, []bool) bool, error) {
+	evaluator, err := compileWithTrace(policy, identities, deserializer)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(signedData []*cb.SignedData, used []bool) bool {
+		return evaluator(signedData, used, nil)
+	}, nil
+}
+
+// compileWithTrace behaves as compile, but the function it builds also
+// records how the evaluation went in the trace it is passed, if not nil
+func compileWithTrace(policy *cb.SignaturePolicy, identities []*mb.MSPPrincipal, deserializer msp.IdentityDeserializer) (func([]*cb.SignedData, []bool, *policies.Trace) bool, error) {
 	if policy == nil {
 		return nil, fmt.Errorf("Empty policy element")
 	}
 
 	switch t := policy.Type.(type) {
 	case *cb.SignaturePolicy_NOutOf_:
-		policies := make([]func([]*cb.SignedData, []bool) bool, len(t.NOutOf.Rules))
+		rules := make([]func([]*cb.SignedData, []bool, *policies.Trace) bool, len(t.NOutOf.Rules))
 		for i, policy := range t.NOutOf.Rules {
-			compiledPolicy, err := compile(policy, identities, deserializer)
+			compiledPolicy, err := compileWithTrace(policy, identities, deserializer)
 			if err != nil {
 				return nil, err
 			}
-			policies[i] = compiledPolicy
+			rules[i] = compiledPolicy
 
 		}
-		return func(signedData []*cb.SignedData, used []bool) bool {
+		return func(signedData []*cb.SignedData, used []bool, trace *policies.Trace) bool {
 			grepKey := time.Now().UnixNano()
 			cauthdslLogger.Debugf("%p gate %d evaluation starts", signedData, grepKey)
 			verified := int32(0)
 			_used := make([]bool, len(used))
-			for _, policy := range policies {
+			for _, rule := range rules {
 				copy(_used, used)
-				if policy(signedData, _used) {
+				if rule(signedData, _used, subTrace(trace)) {
 					verified++
 					copy(used, _used)
 				}
@@ -66,6 +82,11 @@ func compile(policy *cb.SignaturePolicy, identities []*mb.MSPPrincipal, deserial
 				cauthdslLogger.Debugf("%p gate %d evaluation fails", signedData, grepKey)
 			}
 
+			if trace != nil {
+				trace.Policy = fmt.Sprintf("%d out of %d", t.NOutOf.N, len(rules))
+				trace.Satisfied = verified >= t.NOutOf.N
+			}
+
 			return verified >= t.NOutOf.N
 		}, nil
 	case *cb.SignaturePolicy_SignedBy:
@@ -73,7 +94,10 @@ func compile(policy *cb.SignaturePolicy, identities []*mb.MSPPrincipal, deserial
 			return nil, fmt.Errorf("identity index out of range, requested %v, but identies length is %d", t.SignedBy, len(identities))
 		}
 		signedByID := identities[t.SignedBy]
-		return func(signedData []*cb.SignedData, used []bool) bool {
+		return func(signedData []*cb.SignedData, used []bool, trace *policies.Trace) bool {
+			if trace != nil {
+				trace.Policy = "signed by " + principalString(signedByID)
+			}
 			cauthdslLogger.Debugf("%p signed by %d principal evaluation starts (used %v)", signedData, t.SignedBy, used)
 			for i, sd := range signedData {
 				if used[i] {
@@ -87,21 +111,27 @@ func compile(policy *cb.SignaturePolicy, identities []*mb.MSPPrincipal, deserial
 				identity, err := deserializer.DeserializeIdentity(sd.Identity)
 				if err != nil {
 					cauthdslLogger.Errorf("Principal deserialization failure (%s) for identity %x", err, sd.Identity)
+					traceFailure(trace, "identity %d cannot be deserialized: %s", i, err)
 					continue
 				}
 				err = identity.SatisfiesPrincipal(signedByID)
 				if err != nil {
 					cauthdslLogger.Debugf("%p identity %d does not satisfy principal: %s", signedData, i, err)
+					traceFailure(trace, "identity %d does not satisfy principal: %s", i, err)
 					continue
 				}
 				cauthdslLogger.Debugf("%p principal matched by identity %d", signedData, i)
 				err = identity.Verify(sd.Data, sd.Signature)
 				if err != nil {
 					cauthdslLogger.Debugf("%p signature for identity %d is invalid: %s", signedData, i, err)
+					traceFailure(trace, "signature for identity %d is invalid: %s", i, err)
 					continue
 				}
 				cauthdslLogger.Debugf("%p principal evaluation succeeds for identity %d", signedData, i)
 				used[i] = true
+				if trace != nil {
+					trace.Satisfied = true
+				}
 				return true
 			}
 			cauthdslLogger.Debugf("%p principal evaluation fails", signedData)
@@ -111,3 +141,55 @@ func compile(policy *cb.SignaturePolicy, identities []*mb.MSPPrincipal, deserial
 		return nil, fmt.Errorf("Unknown type: %T:%v", t, t)
 	}
 }
+
+// subTrace returns a new trace appended to the sub-traces of trace, or nil if trace is nil
+func subTrace(trace *policies.Trace) *policies.Trace {
+	if trace == nil {
+		return nil
+	}
+	sub := &policies.Trace{}
+	trace.SubTraces = append(trace.SubTraces, sub)
+	return sub
+}
+
+func traceFailure(trace *policies.Trace, format string, args ...interface{}) {
+	if trace != nil {
+		trace.Failures = append(trace.Failures, fmt.Sprintf(format, args...))
+	}
+}
+
+// principalString returns a representation of the principal
+// in the policy language understood by FromString
+func principalString(principal *mb.MSPPrincipal) string {
+	switch principal.PrincipalClassification {
+	case mb.MSPPrincipal_ROLE:
+		role := &mb.MSPRole{}
+		if err := proto.Unmarshal(principal.Principal, role); err != nil {
+			break
+		}
+		return role.MspIdentifier + "." + strings.ToLower(role.Role.String())
+	case mb.MSPPrincipal_ORGANIZATION_UNIT:
+		ou := &mb.OrganizationUnit{}
+		if err := proto.Unmarshal(principal.Principal, ou); err != nil {
+			break
+		}
+		return ou.MspIdentifier + ".ou:" + ou.OrganizationalUnitIdentifier
+	case mb.MSPPrincipal_ANONYMITY:
+		anon := &mb.MSPIdentityAnonymity{}
+		if err := proto.Unmarshal(principal.Principal, anon); err != nil {
+			break
+		}
+		return strings.ToLower(anon.AnonymityType.String())
+	case mb.MSPPrincipal_COMBINED:
+		combined := &mb.CombinedPrincipal{}
+		if err := proto.Unmarshal(principal.Principal, combined); err != nil {
+			break
+		}
+		principals := make([]string, len(combined.Principals))
+		for i, p := range combined.Principals {
+			principals[i] = principalString(p)
+		}
+		return strings.Join(principals, " & ")
+	}
+	return strings.ToLower(principal.PrincipalClassification.String())
+}
@@ -77,6 +77,91 @@ func SignedBy(index int32) *cb.SignaturePolicy {
 	}
 }
 
+// RolePrincipal returns a principal satisfied by the identities
+// having the given role in the MSP with the given identifier
+func RolePrincipal(mspId string, role msp.MSPRole_MSPRoleType) *msp.MSPPrincipal {
+	return &msp.MSPPrincipal{
+		PrincipalClassification: msp.MSPPrincipal_ROLE,
+		Principal:               utils.MarshalOrPanic(&msp.MSPRole{Role: role, MspIdentifier: mspId})}
+}
+
+// OUPrincipal returns a principal satisfied by the identities of the MSP
+// with the given identifier that belong to the given organizational unit.
+// certifiersId is the identifier of the certification chain of the
+// organizational unit, as found in the OU configuration of the MSP, and
+// can be nil if the organizational unit is not bound to a chain.
+func OUPrincipal(mspId string, ou string, certifiersId []byte) *msp.MSPPrincipal {
+	return &msp.MSPPrincipal{
+		PrincipalClassification: msp.MSPPrincipal_ORGANIZATION_UNIT,
+		Principal: utils.MarshalOrPanic(&msp.OrganizationUnit{
+			MspIdentifier:                mspId,
+			OrganizationalUnitIdentifier: ou,
+			CertifiersIdentifier:         certifiersId})}
+}
+
+// AnonymityPrincipal returns a principal satisfied by the
+// identities that are anonymous, or nominal, as requested
+func AnonymityPrincipal(anonymity msp.MSPIdentityAnonymity_MSPIdentityAnonymityType) *msp.MSPPrincipal {
+	return &msp.MSPPrincipal{
+		PrincipalClassification: msp.MSPPrincipal_ANONYMITY,
+		Principal:               utils.MarshalOrPanic(&msp.MSPIdentityAnonymity{AnonymityType: anonymity})}
+}
+
+// CombinedPrincipal returns a principal satisfied by
+// the identities satisfying all the given principals
+func CombinedPrincipal(principals ...*msp.MSPPrincipal) *msp.MSPPrincipal {
+	return &msp.MSPPrincipal{
+		PrincipalClassification: msp.MSPPrincipal_COMBINED,
+		Principal:               utils.MarshalOrPanic(&msp.CombinedPrincipal{Principals: principals})}
+}
+
+// PolicyBuilder builds a SignaturePolicyEnvelope out of rules that refer
+// to principals directly, and takes care of collecting the principals
+// in the identities of the envelope. For instance, a policy requiring
+// signatures from 2 out of a peer of OrgA, a peer of OrgB and an admin
+// of OrgC is built as follows
+//
+//	pb := NewPolicyBuilder()
+//	policy := pb.Envelope(NOutOf(2, []*cb.SignaturePolicy{
+//		pb.SignedBy(RolePrincipal("OrgA", msp.MSPRole_PEER)),
+//		pb.SignedBy(RolePrincipal("OrgB", msp.MSPRole_PEER)),
+//		pb.SignedBy(RolePrincipal("OrgC", msp.MSPRole_ADMIN)),
+//	}))
+type PolicyBuilder struct {
+	principals []*msp.MSPPrincipal
+}
+
+// NewPolicyBuilder returns a new PolicyBuilder
+func NewPolicyBuilder() *PolicyBuilder {
+	return &PolicyBuilder{}
+}
+
+// SignedBy returns a SignaturePolicy requiring a signature from an identity
+// satisfying the given principal. Equal principals share the same index in
+// the identities of the envelope.
+func (pb *PolicyBuilder) SignedBy(principal *msp.MSPPrincipal) *cb.SignaturePolicy {
+	for i, p := range pb.principals {
+		if proto.Equal(p, principal) {
+			return SignedBy(int32(i))
+		}
+	}
+	pb.principals = append(pb.principals, principal)
+	return SignedBy(int32(len(pb.principals) - 1))
+}
+
+// Envelope returns a SignaturePolicyEnvelope embedding the given rule,
+// which is expected to be built out of the SignedBy rules of this builder
+func (pb *PolicyBuilder) Envelope(rule *cb.SignaturePolicy) *cb.SignaturePolicyEnvelope {
+	identities := make([]*msp.MSPPrincipal, len(pb.principals))
+	copy(identities, pb.principals)
+
+	return &cb.SignaturePolicyEnvelope{
+		Version:    0,
+		Rule:       rule,
+		Identities: identities,
+	}
+}
+
 // SignedByMspMember creates a SignaturePolicyEnvelope
 // requiring 1 signature from any member of the specified MSP
 func SignedByMspMember(mspId string) *cb.SignaturePolicyEnvelope {
@@ -151,6 +236,20 @@ func SignedByAnyAdmin(ids []string) *cb.SignaturePolicyEnvelope {
 	return signedByAnyOfGivenRole(msp.MSPRole_ADMIN, ids)
 }
 
+// SignedByAnyPeer returns a policy that requires one valid
+// signature from a peer of any of the orgs whose ids are
+// listed in the supplied string array
+func SignedByAnyPeer(ids []string) *cb.SignaturePolicyEnvelope {
+	return signedByAnyOfGivenRole(msp.MSPRole_PEER, ids)
+}
+
+// SignedByAnyClient returns a policy that requires one valid
+// signature from a client of any of the orgs whose ids are
+// listed in the supplied string array
+func SignedByAnyClient(ids []string) *cb.SignaturePolicyEnvelope {
+	return signedByAnyOfGivenRole(msp.MSPRole_CLIENT, ids)
+}
+
 // And is a convenience method which utilizes NOutOf to produce And equivalent behavior
 func And(lhs, rhs *cb.SignaturePolicy) *cb.SignaturePolicy {
 	return NOutOf(2, []*cb.SignaturePolicy{lhs, rhs})
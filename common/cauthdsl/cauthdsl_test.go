@@ -21,9 +21,11 @@ import (
 	"errors"
 	"testing"
 
+	"github.com/hyperledger/fabric/common/policies"
 	"github.com/hyperledger/fabric/msp"
 	cb "github.com/hyperledger/fabric/protos/common"
 	mb "github.com/hyperledger/fabric/protos/msp"
+	"github.com/hyperledger/fabric/protos/utils"
 
 	"github.com/golang/protobuf/proto"
 	logging "github.com/op/go-logging"
@@ -179,3 +181,77 @@ func TestNilSignaturePolicyEnvelope(t *testing.T) {
 	_, err := compile(nil, nil, &mockDeserializer{})
 	assert.Error(t, err, "Fail to compile")
 }
+
+func TestPolicyBuilder(t *testing.T) {
+	peerA := RolePrincipal("OrgA", mb.MSPRole_PEER)
+	peerB := RolePrincipal("OrgB", mb.MSPRole_PEER)
+	adminC := RolePrincipal("OrgC", mb.MSPRole_ADMIN)
+
+	// 2 out of a peer of OrgA, a peer of OrgB and an admin of OrgC,
+	// or a peer of OrgA together with a peer of OrgB
+	pb := NewPolicyBuilder()
+	policy := pb.Envelope(Or(
+		NOutOf(2, []*cb.SignaturePolicy{pb.SignedBy(peerA), pb.SignedBy(peerB), pb.SignedBy(adminC)}),
+		And(pb.SignedBy(RolePrincipal("OrgA", mb.MSPRole_PEER)), pb.SignedBy(peerB)),
+	))
+
+	// equal principals are collected once
+	assert.Equal(t, []*mb.MSPPrincipal{peerA, peerB, adminC}, policy.Identities)
+
+	spe, err := compile(policy.Rule, policy.Identities, &mockDeserializer{})
+	assert.NoError(t, err)
+
+	// the mock identities satisfy the principal they are serialized as
+	ids := [][]byte{peerA.Principal, peerB.Principal, adminC.Principal}
+	sigs := [][]byte{validSignature, validSignature, validSignature}
+	assert.True(t, spe(toSignedData(moreMsgs, ids, sigs)))
+	assert.True(t, spe(toSignedData(msgs, [][]byte{ids[0], ids[2]}, sigs[:2])))
+	assert.True(t, spe(toSignedData(msgs, [][]byte{ids[2], ids[1]}, sigs[:2])))
+	assert.False(t, spe(toSignedData([][]byte{nil}, [][]byte{ids[2]}, sigs[:1])))
+	assert.False(t, spe(toSignedData(msgs, [][]byte{ids[2], ids[2]}, sigs[:2])))
+	assert.False(t, spe(toSignedData(msgs, [][]byte{ids[0], ids[2]}, [][]byte{validSignature, invalidSignature})))
+}
+
+func TestPrincipals(t *testing.T) {
+	ou := &mb.OrganizationUnit{}
+	p := OUPrincipal("OrgA", "Dept1", []byte{1, 2, 3})
+	assert.Equal(t, mb.MSPPrincipal_ORGANIZATION_UNIT, p.PrincipalClassification)
+	assert.NoError(t, proto.Unmarshal(p.Principal, ou))
+	assert.Equal(t, &mb.OrganizationUnit{MspIdentifier: "OrgA", OrganizationalUnitIdentifier: "Dept1", CertifiersIdentifier: []byte{1, 2, 3}}, ou)
+
+	anon := &mb.MSPIdentityAnonymity{}
+	p = AnonymityPrincipal(mb.MSPIdentityAnonymity_ANONYMOUS)
+	assert.Equal(t, mb.MSPPrincipal_ANONYMITY, p.PrincipalClassification)
+	assert.NoError(t, proto.Unmarshal(p.Principal, anon))
+	assert.Equal(t, mb.MSPIdentityAnonymity_ANONYMOUS, anon.AnonymityType)
+
+	combined := &mb.CombinedPrincipal{}
+	p = CombinedPrincipal(RolePrincipal("OrgA", mb.MSPRole_PEER), OUPrincipal("OrgA", "Dept1", nil))
+	assert.Equal(t, mb.MSPPrincipal_COMBINED, p.PrincipalClassification)
+	assert.NoError(t, proto.Unmarshal(p.Principal, combined))
+	assert.Len(t, combined.Principals, 2)
+	assert.True(t, proto.Equal(RolePrincipal("OrgA", mb.MSPRole_PEER), combined.Principals[0]))
+}
+
+func TestEvaluateWithTrace(t *testing.T) {
+	peerA := RolePrincipal("OrgA", mb.MSPRole_PEER)
+	peerB := RolePrincipal("OrgB", mb.MSPRole_PEER)
+	pb := NewPolicyBuilder()
+	policy := pb.Envelope(Or(pb.SignedBy(peerA), pb.SignedBy(CombinedPrincipal(peerB, OUPrincipal("OrgB", "Dept1", nil)))))
+
+	p, _, err := NewPolicyProvider(&mockDeserializer{}).NewPolicy(utils.MarshalOrPanic(policy))
+	assert.NoError(t, err)
+
+	trace, err := policies.EvaluateWithTrace(p, []*cb.SignedData{{Identity: peerA.Principal, Signature: invalidSignature}})
+	assert.Error(t, err)
+	assert.Equal(t, "1 out of 2: not satisfied ("+
+		"signed by OrgA.peer: not satisfied [signature for identity 0 is invalid: Invalid signature], "+
+		"signed by OrgB.peer & OrgB.ou:Dept1: not satisfied [identity 0 does not satisfy principal: Principals do not match])", trace.String())
+
+	trace, err = policies.EvaluateWithTrace(p, []*cb.SignedData{{Identity: peerB.Principal, Signature: validSignature}, {Identity: peerA.Principal, Signature: validSignature}})
+	assert.NoError(t, err)
+	assert.True(t, trace.Satisfied)
+	assert.Equal(t, "1 out of 2: satisfied ("+
+		"signed by OrgA.peer: satisfied [identity 0 does not satisfy principal: Principals do not match], "+
+		"signed by OrgB.peer & OrgB.ou:Dept1: not satisfied [identity 0 does not satisfy principal: Principals do not match])", trace.String())
+}
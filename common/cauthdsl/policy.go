@@ -49,7 +49,7 @@ func (pr *provider) NewPolicy(data []byte) (policies.Policy, proto.Message, erro
 		return nil, nil, fmt.Errorf("This evaluator only understands messages of version 0, but version was %d", sigPolicy.Version)
 	}
 
-	compiled, err := compile(sigPolicy.Rule, sigPolicy.Identities, pr.deserializer)
+	compiled, err := compileWithTrace(sigPolicy.Rule, sigPolicy.Identities, pr.deserializer)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -61,7 +61,7 @@ func (pr *provider) NewPolicy(data []byte) (policies.Policy, proto.Message, erro
 }
 
 type policy struct {
-	evaluator func([]*cb.SignedData, []bool) bool
+	evaluator func([]*cb.SignedData, []bool, *policies.Trace) bool
 }
 
 // Evaluate takes a set of SignedData and evaluates whether this set of signatures satisfies the policy
@@ -70,9 +70,23 @@ func (p *policy) Evaluate(signatureSet []*cb.SignedData) error {
 		return fmt.Errorf("No such policy")
 	}
 
-	ok := p.evaluator(signatureSet, make([]bool, len(signatureSet)))
+	ok := p.evaluator(signatureSet, make([]bool, len(signatureSet)), nil)
 	if !ok {
 		return errors.New("Failed to authenticate policy")
 	}
 	return nil
 }
+
+// EvaluateWithTrace behaves as Evaluate, and additionally returns the trace of the evaluation
+func (p *policy) EvaluateWithTrace(signatureSet []*cb.SignedData) (*policies.Trace, error) {
+	if p == nil {
+		return nil, fmt.Errorf("No such policy")
+	}
+
+	trace := &policies.Trace{}
+	ok := p.evaluator(signatureSet, make([]bool, len(signatureSet)), trace)
+	if !ok {
+		return trace, errors.New("Failed to authenticate policy")
+	}
+	return trace, nil
+}
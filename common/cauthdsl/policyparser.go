@@ -21,42 +21,86 @@ import (
 	"reflect"
 	"regexp"
 	"strconv"
+	"strings"
 
 	"github.com/Knetic/govaluate"
 	"github.com/hyperledger/fabric/protos/common"
 	"github.com/hyperledger/fabric/protos/msp"
-	"github.com/hyperledger/fabric/protos/utils"
 )
 
-var regex *regexp.Regexp = regexp.MustCompile("^([[:alnum:]]+)([.])(member|admin)$")
+var regex *regexp.Regexp = regexp.MustCompile("^([[:alnum:]]+)([.])(member|admin|client|peer)$")
+var regexOU *regexp.Regexp = regexp.MustCompile("^([[:alnum:]]+)([.])ou:([[:alnum:]._-]+)$")
 var regexErr *regexp.Regexp = regexp.MustCompile("^No parameter '([^']+)' found[.]$")
 
-func and(args ...interface{}) (interface{}, error) {
-	toret := "outof(" + strconv.Itoa(len(args))
-	for _, arg := range args {
-		toret += ", "
-		switch t := arg.(type) {
-		case string:
-			if regex.MatchString(t) {
-				toret += "'" + t + "'"
-			} else {
-				toret += t
+// principalSeparator separates the principals of a combined principal
+const principalSeparator = "&"
+
+func isPrincipal(s string) bool {
+	_, err := principalFromString(s)
+	return err == nil
+}
+
+// principalFromString parses a principal, possibly combined, of the policy language
+func principalFromString(s string) (*msp.MSPPrincipal, error) {
+	if strings.Contains(s, principalSeparator) {
+		parts := strings.Split(s, principalSeparator)
+		principals := make([]*msp.MSPPrincipal, len(parts))
+		for i, part := range parts {
+			p, err := singlePrincipalFromString(strings.TrimSpace(part))
+			if err != nil {
+				return nil, err
 			}
-		default:
-			return nil, fmt.Errorf("Unexpected type %s", reflect.TypeOf(arg))
+			principals[i] = p
 		}
+		return CombinedPrincipal(principals...), nil
 	}
 
-	return toret + ")", nil
+	return singlePrincipalFromString(s)
 }
 
-func or(args ...interface{}) (interface{}, error) {
-	toret := "outof(" + strconv.Itoa(1)
+func singlePrincipalFromString(s string) (*msp.MSPPrincipal, error) {
+	switch s {
+	case "nominal":
+		return AnonymityPrincipal(msp.MSPIdentityAnonymity_NOMINAL), nil
+	case "anonymous":
+		return AnonymityPrincipal(msp.MSPIdentityAnonymity_ANONYMOUS), nil
+	}
+
+	if subm := regexOU.FindStringSubmatch(s); len(subm) == 4 {
+		return OUPrincipal(subm[1], subm[3], nil), nil
+	}
+
+	/* split the string */
+	subm := regex.FindAllStringSubmatch(s, -1)
+	if subm == nil || len(subm) != 1 || len(subm[0]) != 4 {
+		return nil, fmt.Errorf("Error parsing principal %s", s)
+	}
+
+	/* get the right role */
+	var r msp.MSPRole_MSPRoleType
+	switch subm[0][3] {
+	case "member":
+		r = msp.MSPRole_MEMBER
+	case "admin":
+		r = msp.MSPRole_ADMIN
+	case "client":
+		r = msp.MSPRole_CLIENT
+	case "peer":
+		r = msp.MSPRole_PEER
+	}
+
+	return RolePrincipal(subm[0][1], r), nil
+}
+
+// gate translates a gate of the policy language into
+// an outof gate requiring n of the given arguments
+func gate(n int, args []interface{}) (interface{}, error) {
+	toret := "outof(" + strconv.Itoa(n)
 	for _, arg := range args {
 		toret += ", "
 		switch t := arg.(type) {
 		case string:
-			if regex.MatchString(t) {
+			if isPrincipal(t) {
 				toret += "'" + t + "'"
 			} else {
 				toret += t
@@ -69,13 +113,34 @@ func or(args ...interface{}) (interface{}, error) {
 	return toret + ")", nil
 }
 
+func and(args ...interface{}) (interface{}, error) {
+	return gate(len(args), args)
+}
+
+func or(args ...interface{}) (interface{}, error) {
+	return gate(1, args)
+}
+
+func outof(args ...interface{}) (interface{}, error) {
+	if len(args) < 2 {
+		return nil, fmt.Errorf("Expected at least two arguments to OutOf. Given %d", len(args))
+	}
+
+	n, ok := args[0].(float64)
+	if !ok || n != float64(int(n)) {
+		return nil, fmt.Errorf("Unexpected type %s, expected an integer as first argument of OutOf", reflect.TypeOf(args[0]))
+	}
+
+	return gate(int(n), args[1:])
+}
+
 func firstPass(args ...interface{}) (interface{}, error) {
 	toret := "outof(ID"
 	for _, arg := range args {
 		toret += ", "
 		switch t := arg.(type) {
 		case string:
-			if regex.MatchString(t) {
+			if isPrincipal(t) {
 				toret += "'" + t + "'"
 			} else {
 				toret += t
@@ -117,10 +182,10 @@ func secondPass(args ...interface{}) (interface{}, error) {
 	}
 
 	/* get the n in the t out of n */
-	var n int = len(args) - 1
+	var n int = len(args) - 2
 
-	/* sanity check - t better be <= n */
-	if t > n {
+	/* sanity check - t better be between 0 and n */
+	if t < 0 || t > n {
 		return nil, fmt.Errorf("Invalid t-out-of-n predicate, t %d, n %d", t, n)
 	}
 
@@ -129,28 +194,13 @@ func secondPass(args ...interface{}) (interface{}, error) {
 	/* handle the rest of the arguments */
 	for _, principal := range args[2:] {
 		switch t := principal.(type) {
-		/* if it's a string, we expect it to be formed as
-		   <MSP_ID> . <ROLE>, where MSP_ID is the MSP identifier
-		   and ROLE is either a member of an admin*/
+		/* if it's a string, we expect it to be a principal
+		   of the policy language, see FromString */
 		case string:
-			/* split the string */
-			subm := regex.FindAllStringSubmatch(t, -1)
-			if subm == nil || len(subm) != 1 || len(subm[0]) != 4 {
-				return nil, fmt.Errorf("Error parsing principal %s", t)
-			}
-
-			/* get the right role */
-			var r msp.MSPRole_MSPRoleType
-			if subm[0][3] == "member" {
-				r = msp.MSPRole_MEMBER
-			} else {
-				r = msp.MSPRole_ADMIN
+			p, err := principalFromString(t)
+			if err != nil {
+				return nil, err
 			}
-
-			/* build the principal we've been told */
-			p := &msp.MSPPrincipal{
-				PrincipalClassification: msp.MSPPrincipal_ROLE,
-				Principal:               utils.MarshalOrPanic(&msp.MSPRole{MspIdentifier: subm[0][1], Role: r})}
 			ctx.principals = append(ctx.principals, p)
 
 			/* create a SignaturePolicy that requires a signature from
@@ -191,21 +241,36 @@ func newContext() *context {
 // implements that policy. The supported language is as follows
 //
 // GATE(P[, P])
+// OutOf(N, P[, P])
 //
 // where
 //	- GATE is either "and" or "or"
-//	- P is either a principal or another nested call to GATE
+//	- OutOf requires N of the following arguments to be satisfied
+//	- P is either a quoted principal or another nested call to GATE or OutOf
 //
-// a principal is defined as
+// a principal is one of
 //
 // ORG.ROLE
+// ORG.ou:OU
+// nominal
+// anonymous
 //
 // where
 //	- ORG is a string (representing the MSP identifier)
-//	- ROLE is either the string "member" or the string "admin" representing the required role
+//	- ROLE is one of the strings "member", "admin", "client" or "peer" representing the required role;
+//	  the "client" and "peer" roles require the MSP to have NodeOUs enabled
+//	- OU is the identifier of an organizational unit of the MSP; the principal is not bound
+//	  to a certification chain, use OUPrincipal and PolicyBuilder for that
+//	- "nominal" and "anonymous" require the identity to be nominal or anonymous, respectively
+//
+// or a combined principal, satisfied by the identities satisfying all of its
+// principals, made of principals separated by "&", such as 'A.peer & A.ou:Dept1'.
+//
+// For instance, OutOf(2, 'A.peer', 'B.peer', 'C.admin') requires signatures from
+// 2 out of a peer of A, a peer of B and an admin of C.
 func FromString(policy string) (*common.SignaturePolicyEnvelope, error) {
 	// first we translate the and/or business into outof gates
-	intermediate, err := govaluate.NewEvaluableExpressionWithFunctions(policy, map[string]govaluate.ExpressionFunction{"AND": and, "and": and, "OR": or, "or": or})
+	intermediate, err := govaluate.NewEvaluableExpressionWithFunctions(policy, map[string]govaluate.ExpressionFunction{"AND": and, "and": and, "OR": or, "or": or, "OutOf": outof, "outof": outof, "OUTOF": outof})
 	if err != nil {
 		return nil, err
 	}
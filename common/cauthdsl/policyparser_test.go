@@ -130,9 +130,132 @@ func TestComplex2(t *testing.T) {
 	assert.True(t, reflect.DeepEqual(p1, p2))
 }
 
+func TestNodeOURoles(t *testing.T) {
+	p1, err := FromString("AND('A.peer', 'B.client')")
+	assert.NoError(t, err)
+
+	principals := make([]*msp.MSPPrincipal, 0)
+
+	principals = append(principals, &msp.MSPPrincipal{
+		PrincipalClassification: msp.MSPPrincipal_ROLE,
+		Principal:               utils.MarshalOrPanic(&msp.MSPRole{Role: msp.MSPRole_PEER, MspIdentifier: "A"})})
+
+	principals = append(principals, &msp.MSPPrincipal{
+		PrincipalClassification: msp.MSPPrincipal_ROLE,
+		Principal:               utils.MarshalOrPanic(&msp.MSPRole{Role: msp.MSPRole_CLIENT, MspIdentifier: "B"})})
+
+	p2 := &common.SignaturePolicyEnvelope{
+		Version:    0,
+		Rule:       And(SignedBy(0), SignedBy(1)),
+		Identities: principals,
+	}
+
+	assert.True(t, reflect.DeepEqual(p1, p2))
+}
+
 func TestBadStringsNoPanic(t *testing.T) {
 	_, err := FromString("OR('A.member', 'Bmember')")
 	assert.Error(t, err)
 	_, err = FromString("OR('A.member', Bmember)")
 	assert.Error(t, err)
+	_, err = FromString("OR('A.member', 'B.orderer')")
+	assert.Error(t, err)
+}
+
+func TestOutOf(t *testing.T) {
+	p1, err := FromString("OutOf(2, 'A.peer', 'B.peer', 'C.admin')")
+	assert.NoError(t, err)
+
+	p2 := &common.SignaturePolicyEnvelope{
+		Version: 0,
+		Rule:    NOutOf(2, []*common.SignaturePolicy{SignedBy(0), SignedBy(1), SignedBy(2)}),
+		Identities: []*msp.MSPPrincipal{
+			RolePrincipal("A", msp.MSPRole_PEER),
+			RolePrincipal("B", msp.MSPRole_PEER),
+			RolePrincipal("C", msp.MSPRole_ADMIN),
+		},
+	}
+	assert.True(t, reflect.DeepEqual(p1, p2))
+
+	// OutOf gates nest with the other gates
+	p1, err = FromString("OR('A.admin', outof(1, AND('B.member', 'C.member'), 'D.member'))")
+	assert.NoError(t, err)
+
+	p2 = &common.SignaturePolicyEnvelope{
+		Version: 0,
+		Rule:    Or(SignedBy(3), NOutOf(1, []*common.SignaturePolicy{And(SignedBy(0), SignedBy(1)), SignedBy(2)})),
+		Identities: []*msp.MSPPrincipal{
+			RolePrincipal("B", msp.MSPRole_MEMBER),
+			RolePrincipal("C", msp.MSPRole_MEMBER),
+			RolePrincipal("D", msp.MSPRole_MEMBER),
+			RolePrincipal("A", msp.MSPRole_ADMIN),
+		},
+	}
+	assert.True(t, reflect.DeepEqual(p1, p2))
+}
+
+func TestBadOutOf(t *testing.T) {
+	for _, policy := range []string{
+		"OutOf(3, 'A.member', 'B.member')",
+		"OutOf(-1, 'A.member', 'B.member')",
+		"OutOf(1.5, 'A.member', 'B.member')",
+		"OutOf('A.member', 'B.member')",
+		"OutOf(1)",
+	} {
+		_, err := FromString(policy)
+		assert.Error(t, err, policy)
+	}
+}
+
+func TestOUPrincipal(t *testing.T) {
+	p1, err := FromString("AND('A.ou:Dept1', 'B.ou:dept_2.eu-west')")
+	assert.NoError(t, err)
+
+	p2 := &common.SignaturePolicyEnvelope{
+		Version: 0,
+		Rule:    And(SignedBy(0), SignedBy(1)),
+		Identities: []*msp.MSPPrincipal{
+			{
+				PrincipalClassification: msp.MSPPrincipal_ORGANIZATION_UNIT,
+				Principal:               utils.MarshalOrPanic(&msp.OrganizationUnit{MspIdentifier: "A", OrganizationalUnitIdentifier: "Dept1"})},
+			{
+				PrincipalClassification: msp.MSPPrincipal_ORGANIZATION_UNIT,
+				Principal:               utils.MarshalOrPanic(&msp.OrganizationUnit{MspIdentifier: "B", OrganizationalUnitIdentifier: "dept_2.eu-west"})},
+		},
+	}
+	assert.True(t, reflect.DeepEqual(p1, p2))
+
+	_, err = FromString("AND('A.ou:', 'B.member')")
+	assert.Error(t, err)
+}
+
+func TestCombinedAndAnonymityPrincipals(t *testing.T) {
+	p1, err := FromString("OR('A.peer & A.ou:Dept1 & nominal', 'anonymous')")
+	assert.NoError(t, err)
+
+	p2 := &common.SignaturePolicyEnvelope{
+		Version: 0,
+		Rule:    Or(SignedBy(0), SignedBy(1)),
+		Identities: []*msp.MSPPrincipal{
+			{
+				PrincipalClassification: msp.MSPPrincipal_COMBINED,
+				Principal: utils.MarshalOrPanic(&msp.CombinedPrincipal{Principals: []*msp.MSPPrincipal{
+					RolePrincipal("A", msp.MSPRole_PEER),
+					OUPrincipal("A", "Dept1", nil),
+					{
+						PrincipalClassification: msp.MSPPrincipal_ANONYMITY,
+						Principal:               utils.MarshalOrPanic(&msp.MSPIdentityAnonymity{AnonymityType: msp.MSPIdentityAnonymity_NOMINAL})},
+				}})},
+			{
+				PrincipalClassification: msp.MSPPrincipal_ANONYMITY,
+				Principal:               utils.MarshalOrPanic(&msp.MSPIdentityAnonymity{AnonymityType: msp.MSPIdentityAnonymity_ANONYMOUS})},
+		},
+	}
+	assert.True(t, reflect.DeepEqual(p1, p2))
+
+	// all the principals of a combined principal must be valid
+	_, err = FromString("OR('A.peer & A.orderer', 'B.member')")
+	assert.Error(t, err)
+	_, err = FromString("OR('A.peer &', 'B.member')")
+	assert.Error(t, err)
 }
@@ -38,6 +38,12 @@ type ApplicationOrg interface {
 type Application interface {
 	// Organizations returns a map of org ID to ApplicationOrg
 	Organizations() map[string]ApplicationOrg
+
+	// GossipConfig returns the gossip parameters of the channel
+	GossipConfig() *pb.GossipConfig
+
+	// Capabilities defines the capabilities for the application portion of a channel
+	Capabilities() ApplicationCapabilities
 }
 
 // Channel gives read only access to the channel configuration
@@ -52,6 +58,9 @@ type Channel interface {
 
 	// OrdererAddresses returns the list of valid orderer addresses to connect to to invoke Broadcast/Deliver
 	OrdererAddresses() []string
+
+	// Capabilities defines the capabilities for a channel
+	Capabilities() ChannelCapabilities
 }
 
 // Consortiums represents the set of consortiums serviced by an ordering service
@@ -71,6 +80,12 @@ type Orderer interface {
 	// ConsensusType returns the configured consensus type
 	ConsensusType() string
 
+	// ConsensusMetadata returns the metadata associated with the consensus type
+	ConsensusMetadata() []byte
+
+	// ConsensusState returns the state of the channel with respect to consensus-type migration
+	ConsensusState() ab.ConsensusType_State
+
 	// BatchSize returns the maximum number of messages to include in a block
 	BatchSize() *ab.BatchSize
 
@@ -85,8 +100,66 @@ type Orderer interface {
 	// used for ordering
 	KafkaBrokers() []string
 
+	// MessageFilterRules returns the names of the additional rules the messages
+	// broadcast to the channel must pass, in the order they are applied
+	MessageFilterRules() []string
+
 	// Organizations returns the organizations for the ordering service
 	Organizations() map[string]Org
+
+	// Capabilities defines the capabilities for the orderer portion of a channel
+	Capabilities() OrdererCapabilities
+}
+
+// ChannelCapabilities defines the capabilities for a channel
+type ChannelCapabilities interface {
+	// Supported returns an error if there are unknown capabilities in this channel which are required
+	Supported() error
+
+	// MerkleDataHash specifies whether the data hash of the header of the blocks is the
+	// Merkle root of the block data, rather than the hash of the whole data
+	MerkleDataHash() bool
+}
+
+// OrdererCapabilities defines the capabilities for the orderer portion of a channel
+type OrdererCapabilities interface {
+	// Supported returns an error if there are unknown capabilities in this channel which are required
+	Supported() error
+
+	// ExpirationCheck specifies whether the orderer checks for identity expiration when
+	// validating the messages broadcast to the channel
+	ExpirationCheck() bool
+
+	// ConsensusTypeMigration specifies whether the channel may be put in maintenance mode,
+	// in which its consensus type may be changed
+	ConsensusTypeMigration() bool
+}
+
+// ApplicationCapabilities defines the capabilities for the application portion of a channel
+type ApplicationCapabilities interface {
+	// Supported returns an error if there are unknown capabilities in this channel which are required
+	Supported() error
+
+	// ForbidDuplicateTXIdInBlock specifies whether two transactions with the same TXId are permitted
+	// in the same block, or whether the latter is marked invalid
+	ForbidDuplicateTXIdInBlock() bool
+
+	// ReadYourWrites specifies whether the reads of a chaincode within a simulation observe
+	// the writes the chaincode made earlier in the same simulation
+	ReadYourWrites() bool
+
+	// KeyLevelEndorsement specifies whether the writes to the keys carrying a validation parameter
+	// are validated against the endorsement policy of the key, and whether the writes to the keys
+	// whose metadata a preceding transaction of the block updated are invalidated
+	KeyLevelEndorsement() bool
+
+	// CollectionUpgrade specifies whether the chaincodes deployed or upgraded through lscc may carry
+	// collection configs, and whether the updates of the collection configs upon upgrade are validated
+	CollectionUpgrade() bool
+
+	// Lifecycle specifies whether the chaincode definitions may be approved and committed through
+	// the _lifecycle system chaincode, and whether the committed ones take precedence over lscc
+	Lifecycle() bool
 }
 
 // Resources is the common set of config resources for all channels
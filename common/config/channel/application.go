@@ -19,15 +19,27 @@ package config
 import (
 	"fmt"
 
+	"github.com/hyperledger/fabric/common/capabilities"
 	"github.com/hyperledger/fabric/common/config"
 	"github.com/hyperledger/fabric/common/config/channel/msp"
+	cb "github.com/hyperledger/fabric/protos/common"
+	pb "github.com/hyperledger/fabric/protos/peer"
 )
 
 const (
 	// ApplicationGroupKey is the group name for the Application config
 	ApplicationGroupKey = "Application"
+
+	// GossipConfigKey is the key name for the GossipConfig ConfigValue
+	GossipConfigKey = "GossipConfig"
 )
 
+// ApplicationProtos is used as the source of the ApplicationConfig
+type ApplicationProtos struct {
+	GossipConfig *pb.GossipConfig
+	Capabilities *cb.Capabilities
+}
+
 // ApplicationGroup represents the application config group
 type ApplicationGroup struct {
 	*config.Proposer
@@ -37,9 +49,11 @@ type ApplicationGroup struct {
 
 type ApplicationConfig struct {
 	*config.StandardValues
+	protos *ApplicationProtos
 
 	applicationGroup *ApplicationGroup
 	applicationOrgs  map[string]ApplicationOrg
+	capabilities     *capabilities.ApplicationProvider
 }
 
 // NewSharedConfigImpl creates a new SharedConfigImpl with the given CryptoHelper
@@ -62,20 +76,22 @@ func (ag *ApplicationGroup) Allocate() config.Values {
 }
 
 func NewApplicationConfig(ag *ApplicationGroup) *ApplicationConfig {
-	sv, err := config.NewStandardValues(&(struct{}{}))
+	protos := &ApplicationProtos{}
+	sv, err := config.NewStandardValues(protos)
 	if err != nil {
 		logger.Panicf("Programming error: %s", err)
 	}
 
 	return &ApplicationConfig{
 		applicationGroup: ag,
-
-		// Currently there are no config values
-		StandardValues: sv,
+		protos:           protos,
+		StandardValues:   sv,
 	}
 }
 
 func (ac *ApplicationConfig) Validate(tx interface{}, groups map[string]config.ValueProposer) error {
+	ac.capabilities = capabilities.NewApplicationProvider(ac.protos.Capabilities.GetCapabilities())
+
 	ac.applicationOrgs = make(map[string]ApplicationOrg)
 	var ok bool
 	for key, value := range groups {
@@ -95,3 +111,13 @@ func (ac *ApplicationConfig) Commit() {
 func (ac *ApplicationConfig) Organizations() map[string]ApplicationOrg {
 	return ac.applicationOrgs
 }
+
+// GossipConfig returns the channel's gossip parameters
+func (ac *ApplicationConfig) GossipConfig() *pb.GossipConfig {
+	return ac.protos.GossipConfig
+}
+
+// Capabilities returns a map of capability name to Capability
+func (ac *ApplicationConfig) Capabilities() ApplicationCapabilities {
+	return ac.capabilities
+}
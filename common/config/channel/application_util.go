@@ -32,7 +32,27 @@ func applicationConfigGroup(orgID string, key string, value []byte) *cb.ConfigGr
 	return result
 }
 
+// TemplateGossipConfig creates a headerless config item representing the channel's gossip parameters
+func TemplateGossipConfig(gossipConfig *pb.GossipConfig) *cb.ConfigGroup {
+	result := cb.NewConfigGroup()
+	result.Groups[ApplicationGroupKey] = cb.NewConfigGroup()
+	result.Groups[ApplicationGroupKey].Values[GossipConfigKey] = &cb.ConfigValue{
+		Value: utils.MarshalOrPanic(gossipConfig),
+	}
+	return result
+}
+
 // TemplateAnchorPeers creates a headerless config item representing the anchor peers
 func TemplateAnchorPeers(orgID string, anchorPeers []*pb.AnchorPeer) *cb.ConfigGroup {
 	return applicationConfigGroup(orgID, AnchorPeersKey, utils.MarshalOrPanic(&pb.AnchorPeers{AnchorPeers: anchorPeers}))
 }
+
+// TemplateApplicationCapabilities creates a headerless config item representing the application capabilities
+func TemplateApplicationCapabilities(capabilities map[string]bool) *cb.ConfigGroup {
+	result := cb.NewConfigGroup()
+	result.Groups[ApplicationGroupKey] = cb.NewConfigGroup()
+	result.Groups[ApplicationGroupKey].Values[CapabilitiesKey] = &cb.ConfigValue{
+		Value: utils.MarshalOrPanic(capabilitiesFromBoolMap(capabilities)),
+	}
+	return result
+}
@@ -21,6 +21,7 @@ import (
 	"math"
 
 	"github.com/hyperledger/fabric/bccsp"
+	"github.com/hyperledger/fabric/common/capabilities"
 	"github.com/hyperledger/fabric/common/config"
 	"github.com/hyperledger/fabric/common/config/channel/msp"
 	"github.com/hyperledger/fabric/common/util"
@@ -41,6 +42,10 @@ const (
 	// OrdererAddressesKey is the cb.ConfigItem type key name for the OrdererAddresses message
 	OrdererAddressesKey = "OrdererAddresses"
 
+	// CapabilitiesKey is the name of the key which refers to capabilities, it appears at the channel,
+	// application, and orderer levels and this constant is used for all three.
+	CapabilitiesKey = "Capabilities"
+
 	// GroupKey is the name of the channel group
 	ChannelGroupKey = "Channel"
 )
@@ -57,6 +62,9 @@ type ChannelValues interface {
 
 	// OrdererAddresses returns the list of valid orderer addresses to connect to to invoke Broadcast/Deliver
 	OrdererAddresses() []string
+
+	// Capabilities defines the capabilities for a channel
+	Capabilities() ChannelCapabilities
 }
 
 // ChannelProtos is where the proposed configuration is unmarshaled into
@@ -65,6 +73,7 @@ type ChannelProtos struct {
 	BlockDataHashingStructure *cb.BlockDataHashingStructure
 	OrdererAddresses          *cb.OrdererAddresses
 	Consortium                *cb.Consortium
+	Capabilities              *cb.Capabilities
 }
 
 type channelConfigSetter struct {
@@ -135,6 +144,7 @@ type ChannelConfig struct {
 	protos *ChannelProtos
 
 	hashingAlgorithm func(input []byte) []byte
+	capabilities     *capabilities.ChannelProvider
 
 	appConfig         *ApplicationGroup
 	ordererConfig     *OrdererGroup
@@ -170,6 +180,11 @@ func (cc *ChannelConfig) OrdererAddresses() []string {
 	return cc.protos.OrdererAddresses.Addresses
 }
 
+// Capabilities returns information about the available capabilities for this channel
+func (cc *ChannelConfig) Capabilities() ChannelCapabilities {
+	return cc.capabilities
+}
+
 // ConsortiumName returns the name of the consortium this channel was created under
 func (cc *ChannelConfig) ConsortiumName() string {
 	return cc.protos.Consortium.Name
@@ -182,6 +197,7 @@ func (cc *ChannelConfig) Validate(tx interface{}, groups map[string]config.Value
 		cc.validateHashingAlgorithm,
 		cc.validateBlockDataHashingStructure,
 		cc.validateOrdererAddresses,
+		cc.validateCapabilities,
 	} {
 		if err := validator(); err != nil {
 			return err
@@ -234,6 +250,11 @@ func (cc *ChannelConfig) validateBlockDataHashingStructure() error {
 	return nil
 }
 
+func (cc *ChannelConfig) validateCapabilities() error {
+	cc.capabilities = capabilities.NewChannelProvider(cc.protos.Capabilities.GetCapabilities())
+	return nil
+}
+
 func (cc *ChannelConfig) validateOrdererAddresses() error {
 	if len(cc.protos.OrdererAddresses.Addresses) == 0 {
 		return fmt.Errorf("Must set some OrdererAddresses")
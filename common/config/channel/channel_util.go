@@ -72,3 +72,22 @@ func TemplateOrdererAddresses(addresses []string) *cb.ConfigGroup {
 func DefaultOrdererAddresses() *cb.ConfigGroup {
 	return TemplateOrdererAddresses(defaultOrdererAddresses)
 }
+
+// capabilitiesFromBoolMap builds the Capabilities message requiring the capabilities set to true in the map
+func capabilitiesFromBoolMap(capabilities map[string]bool) *cb.Capabilities {
+	value := &cb.Capabilities{
+		Capabilities: make(map[string]*cb.Capability),
+	}
+	for capability, required := range capabilities {
+		if !required {
+			continue
+		}
+		value.Capabilities[capability] = &cb.Capability{}
+	}
+	return value
+}
+
+// TemplateChannelCapabilities creates a headerless config item representing the channel capabilities
+func TemplateChannelCapabilities(capabilities map[string]bool) *cb.ConfigGroup {
+	return configGroup(CapabilitiesKey, utils.MarshalOrPanic(capabilitiesFromBoolMap(capabilities)))
+}
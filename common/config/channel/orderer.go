@@ -23,8 +23,10 @@ import (
 	"strings"
 	"time"
 
+	"github.com/hyperledger/fabric/common/capabilities"
 	"github.com/hyperledger/fabric/common/config"
 	"github.com/hyperledger/fabric/common/config/channel/msp"
+	cb "github.com/hyperledger/fabric/protos/common"
 	ab "github.com/hyperledger/fabric/protos/orderer"
 )
 
@@ -48,6 +50,9 @@ const (
 
 	// KafkaBrokersKey is the cb.ConfigItem type key name for the KafkaBrokers message
 	KafkaBrokersKey = "KafkaBrokers"
+
+	// MessageFilterRulesKey is the cb.ConfigItem type key name for the MessageFilterRules message
+	MessageFilterRulesKey = "MessageFilterRules"
 )
 
 // OrdererProtos is used as the source of the OrdererConfig
@@ -57,6 +62,8 @@ type OrdererProtos struct {
 	BatchTimeout        *ab.BatchTimeout
 	KafkaBrokers        *ab.KafkaBrokers
 	ChannelRestrictions *ab.ChannelRestrictions
+	MessageFilterRules  *ab.MessageFilterRules
+	Capabilities        *cb.Capabilities
 }
 
 // Config is stores the orderer component configuration
@@ -93,6 +100,7 @@ type OrdererConfig struct {
 	orgs         map[string]Org
 
 	batchTimeout time.Duration
+	capabilities *capabilities.OrdererProvider
 }
 
 // NewOrdererConfig creates a new instance of the orderer config
@@ -120,6 +128,16 @@ func (oc *OrdererConfig) ConsensusType() string {
 	return oc.protos.ConsensusType.Type
 }
 
+// ConsensusMetadata returns the metadata associated with the consensus type
+func (oc *OrdererConfig) ConsensusMetadata() []byte {
+	return oc.protos.ConsensusType.Metadata
+}
+
+// ConsensusState returns the state of the channel with respect to consensus-type migration
+func (oc *OrdererConfig) ConsensusState() ab.ConsensusType_State {
+	return oc.protos.ConsensusType.State
+}
+
 // BatchSize returns the maximum number of messages to include in a block
 func (oc *OrdererConfig) BatchSize() *ab.BatchSize {
 	return oc.protos.BatchSize
@@ -142,6 +160,17 @@ func (oc *OrdererConfig) MaxChannelsCount() uint64 {
 	return oc.protos.ChannelRestrictions.MaxCount
 }
 
+// MessageFilterRules returns the names of the additional rules the messages
+// broadcast to the channel must pass, in the order they are applied
+func (oc *OrdererConfig) MessageFilterRules() []string {
+	return oc.protos.MessageFilterRules.Rules
+}
+
+// Capabilities returns the capabilities the ordering network has for this channel
+func (oc *OrdererConfig) Capabilities() OrdererCapabilities {
+	return oc.capabilities
+}
+
 // Organizations returns a map of the orgs in the channel
 func (oc *OrdererConfig) Organizations() map[string]Org {
 	return oc.orgs
@@ -149,6 +178,7 @@ func (oc *OrdererConfig) Organizations() map[string]Org {
 
 func (oc *OrdererConfig) Validate(tx interface{}, groups map[string]config.ValueProposer) error {
 	for _, validator := range []func() error{
+		oc.validateCapabilities,
 		oc.validateConsensusType,
 		oc.validateBatchSize,
 		oc.validateBatchTimeout,
@@ -171,10 +201,26 @@ func (oc *OrdererConfig) Validate(tx interface{}, groups map[string]config.Value
 	return nil
 }
 
+func (oc *OrdererConfig) validateCapabilities() error {
+	oc.capabilities = capabilities.NewOrdererProvider(oc.protos.Capabilities.GetCapabilities())
+	return nil
+}
+
 func (oc *OrdererConfig) validateConsensusType() error {
-	if oc.ordererGroup.OrdererConfig != nil && oc.ordererGroup.ConsensusType() != oc.protos.ConsensusType.Type {
+	state := oc.protos.ConsensusType.State
+	if _, ok := ab.ConsensusType_State_name[int32(state)]; !ok {
+		return fmt.Errorf("Attempted to set the consensus state to an unknown value: %d", state)
+	}
+	if state != ab.ConsensusType_STATE_NORMAL && !oc.capabilities.ConsensusTypeMigration() {
+		return fmt.Errorf("Attempted to set the consensus state to %s without the %s orderer capability", state, capabilities.OrdererV1_2)
+	}
+	if oc.ordererGroup.OrdererConfig == nil || oc.ordererGroup.ConsensusType() == oc.protos.ConsensusType.Type {
 		// The first config we accept the consensus type regardless
-		return fmt.Errorf("Attempted to change the consensus type from %s to %s after init", oc.ordererGroup.ConsensusType(), oc.protos.ConsensusType.Type)
+		return nil
+	}
+	if oc.ordererGroup.ConsensusState() != ab.ConsensusType_STATE_MAINTENANCE || state != ab.ConsensusType_STATE_MAINTENANCE {
+		// Only a channel in maintenance mode may migrate to another consensus type, and must remain in it
+		return fmt.Errorf("Attempted to change the consensus type from %s to %s outside of maintenance mode", oc.ordererGroup.ConsensusType(), oc.protos.ConsensusType.Type)
 	}
 	return nil
 }
@@ -19,6 +19,8 @@ package config
 import (
 	"testing"
 
+	"github.com/hyperledger/fabric/common/capabilities"
+	cb "github.com/hyperledger/fabric/protos/common"
 	ab "github.com/hyperledger/fabric/protos/orderer"
 
 	logging "github.com/op/go-logging"
@@ -46,6 +48,51 @@ func TestConsensusType(t *testing.T) {
 	assert.Error(t, oc.validateConsensusType(), "Should have failed to change consensus type")
 }
 
+func TestConsensusTypeMigration(t *testing.T) {
+	migration := capabilities.NewOrdererProvider(map[string]*cb.Capability{capabilities.OrdererV1_2: {}})
+	previous := func(consensusType string, state ab.ConsensusType_State) *OrdererGroup {
+		return &OrdererGroup{OrdererConfig: &OrdererConfig{protos: &OrdererProtos{ConsensusType: &ab.ConsensusType{Type: consensusType, State: state}}}}
+	}
+
+	oc := &OrdererConfig{
+		ordererGroup: previous("kafka", ab.ConsensusType_STATE_NORMAL),
+		protos:       &OrdererProtos{ConsensusType: &ab.ConsensusType{Type: "kafka", State: ab.ConsensusType_STATE_MAINTENANCE}},
+		capabilities: capabilities.NewOrdererProvider(nil),
+	}
+	assert.EqualError(t, oc.validateConsensusType(), "Attempted to set the consensus state to STATE_MAINTENANCE without the V1_2 orderer capability")
+
+	oc.capabilities = migration
+	assert.NoError(t, oc.validateConsensusType(), "Should have entered maintenance mode")
+
+	oc = &OrdererConfig{
+		ordererGroup: previous("kafka", ab.ConsensusType_STATE_MAINTENANCE),
+		protos:       &OrdererProtos{ConsensusType: &ab.ConsensusType{Type: "etcdraft", State: ab.ConsensusType_STATE_MAINTENANCE}},
+		capabilities: migration,
+	}
+	assert.NoError(t, oc.validateConsensusType(), "Should have changed consensus type in maintenance mode")
+
+	oc = &OrdererConfig{
+		ordererGroup: previous("kafka", ab.ConsensusType_STATE_MAINTENANCE),
+		protos:       &OrdererProtos{ConsensusType: &ab.ConsensusType{Type: "etcdraft", State: ab.ConsensusType_STATE_NORMAL}},
+		capabilities: migration,
+	}
+	assert.EqualError(t, oc.validateConsensusType(), "Attempted to change the consensus type from kafka to etcdraft outside of maintenance mode")
+
+	oc = &OrdererConfig{
+		ordererGroup: previous("kafka", ab.ConsensusType_STATE_NORMAL),
+		protos:       &OrdererProtos{ConsensusType: &ab.ConsensusType{Type: "etcdraft", State: ab.ConsensusType_STATE_MAINTENANCE}},
+		capabilities: migration,
+	}
+	assert.Error(t, oc.validateConsensusType(), "Should have failed to change consensus type while entering maintenance mode")
+
+	oc = &OrdererConfig{
+		ordererGroup: previous("kafka", ab.ConsensusType_STATE_NORMAL),
+		protos:       &OrdererProtos{ConsensusType: &ab.ConsensusType{Type: "kafka", State: ab.ConsensusType_State(5)}},
+		capabilities: migration,
+	}
+	assert.EqualError(t, oc.validateConsensusType(), "Attempted to set the consensus state to an unknown value: 5")
+}
+
 func TestBatchSize(t *testing.T) {
 
 	validMaxMessageCount := uint32(10)
@@ -17,6 +17,9 @@ limitations under the License.
 package config
 
 import (
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
 	cb "github.com/hyperledger/fabric/protos/common"
 	ab "github.com/hyperledger/fabric/protos/orderer"
 	"github.com/hyperledger/fabric/protos/utils"
@@ -36,6 +39,30 @@ func TemplateConsensusType(typeValue string) *cb.ConfigGroup {
 	return ordererConfigGroup(ConsensusTypeKey, utils.MarshalOrPanic(&ab.ConsensusType{Type: typeValue}))
 }
 
+// TemplateConsensusTypeAndMetadata creates a headerless config item representing the consensus type
+// along with the metadata the consensus type requires
+func TemplateConsensusTypeAndMetadata(typeValue string, metadata []byte) *cb.ConfigGroup {
+	return ordererConfigGroup(ConsensusTypeKey, utils.MarshalOrPanic(&ab.ConsensusType{Type: typeValue, Metadata: metadata}))
+}
+
+// ConsensusTypeOf returns the consensus type of the orderer group of the config,
+// or nil if the config has none
+func ConsensusTypeOf(config *cb.Config) (*ab.ConsensusType, error) {
+	ordererGroup, ok := config.GetChannelGroup().GetGroups()[OrdererGroupKey]
+	if !ok {
+		return nil, nil
+	}
+	value, ok := ordererGroup.Values[ConsensusTypeKey]
+	if !ok {
+		return nil, nil
+	}
+	consensusType := &ab.ConsensusType{}
+	if err := proto.Unmarshal(value.Value, consensusType); err != nil {
+		return nil, fmt.Errorf("could not unmarshal consensus type: %s", err)
+	}
+	return consensusType, nil
+}
+
 // TemplateBatchSize creates a headerless config item representing the batch size
 func TemplateBatchSize(batchSize *ab.BatchSize) *cb.ConfigGroup {
 	return ordererConfigGroup(BatchSizeKey, utils.MarshalOrPanic(batchSize))
@@ -55,3 +82,13 @@ func TemplateChannelRestrictions(maxChannels uint64) *cb.ConfigGroup {
 func TemplateKafkaBrokers(brokers []string) *cb.ConfigGroup {
 	return ordererConfigGroup(KafkaBrokersKey, utils.MarshalOrPanic(&ab.KafkaBrokers{Brokers: brokers}))
 }
+
+// TemplateMessageFilterRules creates a headerless config item representing the message filter rules
+func TemplateMessageFilterRules(rules []string) *cb.ConfigGroup {
+	return ordererConfigGroup(MessageFilterRulesKey, utils.MarshalOrPanic(&ab.MessageFilterRules{Rules: rules}))
+}
+
+// TemplateOrdererCapabilities creates a headerless config item representing the orderer capabilities
+func TemplateOrdererCapabilities(capabilities map[string]bool) *cb.ConfigGroup {
+	return ordererConfigGroup(CapabilitiesKey, utils.MarshalOrPanic(capabilitiesFromBoolMap(capabilities)))
+}
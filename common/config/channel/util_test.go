@@ -11,6 +11,7 @@ import (
 
 	cb "github.com/hyperledger/fabric/protos/common"
 	ab "github.com/hyperledger/fabric/protos/orderer"
+	pb "github.com/hyperledger/fabric/protos/peer"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -37,8 +38,28 @@ func TestOrdererUtils(t *testing.T) {
 	assert.NotNil(t, TemplateKafkaBrokers([]string{"foo"}))
 }
 
+func TestConsensusTypeOf(t *testing.T) {
+	consensusType, err := ConsensusTypeOf(&cb.Config{ChannelGroup: TemplateConsensusTypeAndMetadata("foo", []byte("bar"))})
+	assert.NoError(t, err)
+	assert.Equal(t, &ab.ConsensusType{Type: "foo", Metadata: []byte("bar")}, consensusType)
+
+	consensusType, err = ConsensusTypeOf(&cb.Config{ChannelGroup: TemplateBatchTimeout("3s")})
+	assert.NoError(t, err)
+	assert.Nil(t, consensusType)
+
+	consensusType, err = ConsensusTypeOf(nil)
+	assert.NoError(t, err)
+	assert.Nil(t, consensusType)
+
+	group := TemplateConsensusType("foo")
+	group.Groups[OrdererGroupKey].Values[ConsensusTypeKey].Value = []byte("garbage")
+	_, err = ConsensusTypeOf(&cb.Config{ChannelGroup: group})
+	assert.Error(t, err)
+}
+
 func TestApplicationUtils(t *testing.T) {
 	assert.NotNil(t, TemplateAnchorPeers("foo", nil))
+	assert.NotNil(t, TemplateGossipConfig(&pb.GossipConfig{}))
 }
 
 func TestConsortiumsUtils(t *testing.T) {
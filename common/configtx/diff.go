@@ -0,0 +1,313 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package configtx
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	cb "github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/utils"
+)
+
+// ChangeType is the kind of difference found for a config element
+type ChangeType int
+
+const (
+	// Added means the element exists only in the updated config
+	Added ChangeType = iota
+	// Removed means the element exists only in the original config
+	Removed
+	// Modified means the element exists in both configs, but differs
+	Modified
+)
+
+func (ct ChangeType) String() string {
+	switch ct {
+	case Added:
+		return "Added"
+	case Removed:
+		return "Removed"
+	case Modified:
+		return "Modified"
+	}
+	return fmt.Sprintf("ChangeType(%d)", int(ct))
+}
+
+// Change describes how a single group, value or policy differs between two configs
+type Change struct {
+	// Type is the kind of difference
+	Type ChangeType
+
+	// Key is the fully qualified path of the element, as produced by MapConfig,
+	// for instance "[Values] /Channel/Orderer/BatchSize"
+	Key string
+
+	// Details lists in a human readable form what differs in a modified element
+	Details []string
+}
+
+// Path returns the path of the element, without the prefix telling its kind
+func (c *Change) Path() string {
+	return keyPath(c.Key)
+}
+
+// String returns a human readable representation of the change
+func (c *Change) String() string {
+	if len(c.Details) == 0 {
+		return fmt.Sprintf("%s %s", c.Type, c.Key)
+	}
+	return fmt.Sprintf("%s %s: %s", c.Type, c.Key, strings.Join(c.Details, ", "))
+}
+
+// ComputeConfigDiff computes the changes needed to go from the original channel group to the
+// updated one. The changes are sorted by key, so that groups precede their values and policies.
+func ComputeConfigDiff(original, updated *cb.ConfigGroup, rootGroupKey string) ([]*Change, error) {
+	originalMap, err := MapConfig(original, rootGroupKey)
+	if err != nil {
+		return nil, fmt.Errorf("error mapping original config: %s", err)
+	}
+
+	updatedMap, err := MapConfig(updated, rootGroupKey)
+	if err != nil {
+		return nil, fmt.Errorf("error mapping updated config: %s", err)
+	}
+
+	var changes []*Change
+	for key, originalElement := range originalMap {
+		updatedElement, ok := updatedMap[key]
+		if !ok {
+			changes = append(changes, &Change{Type: Removed, Key: key})
+			continue
+		}
+
+		if details := diffComparables(originalElement, updatedElement); len(details) > 0 {
+			changes = append(changes, &Change{Type: Modified, Key: key, Details: details})
+		}
+	}
+
+	for key := range updatedMap {
+		if _, ok := originalMap[key]; !ok {
+			changes = append(changes, &Change{Type: Added, Key: key})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Key < changes[j].Key
+	})
+
+	return changes, nil
+}
+
+// ComputeConfigEnvelopeDiff computes the changes between the configs carried by two config envelopes
+func ComputeConfigEnvelopeDiff(original, updated *cb.ConfigEnvelope, rootGroupKey string) ([]*Change, error) {
+	if original == nil || original.Config == nil || updated == nil || updated.Config == nil {
+		return nil, fmt.Errorf("config envelope has no config")
+	}
+
+	return ComputeConfigDiff(original.Config.ChannelGroup, updated.Config.ChannelGroup, rootGroupKey)
+}
+
+// ComputeConfigBlockDiff computes the changes between the configs carried by two config blocks
+func ComputeConfigBlockDiff(original, updated *cb.Block, rootGroupKey string) ([]*Change, error) {
+	originalEnv, err := configEnvelopeFromBlock(original)
+	if err != nil {
+		return nil, fmt.Errorf("error extracting original config: %s", err)
+	}
+
+	updatedEnv, err := configEnvelopeFromBlock(updated)
+	if err != nil {
+		return nil, fmt.Errorf("error extracting updated config: %s", err)
+	}
+
+	return ComputeConfigEnvelopeDiff(originalEnv, updatedEnv, rootGroupKey)
+}
+
+// ValidateChangeScope checks that all the changes are made at or below one of the permitted
+// subtrees, given as paths such as "/Channel/Application/Org1MSP". Note that adding or removing
+// an element modifies the group containing it, which must then be permitted too.
+func ValidateChangeScope(changes []*Change, permittedSubtrees ...string) error {
+	var outOfScope []string
+	for _, change := range changes {
+		if !withinSubtrees(change.Path(), permittedSubtrees) {
+			outOfScope = append(outOfScope, change.Key)
+		}
+	}
+
+	if len(outOfScope) > 0 {
+		return fmt.Errorf("changes to %s are not within the permitted subtrees [%s]", strings.Join(outOfScope, ", "), strings.Join(permittedSubtrees, ", "))
+	}
+	return nil
+}
+
+// ValidateConfigUpdateScope checks that the elements a config update writes at a new version,
+// that is the elements it modifies, are all at or below one of the permitted subtrees
+func ValidateConfigUpdateScope(configUpdate *cb.ConfigUpdate, rootGroupKey string, permittedSubtrees ...string) error {
+	readSet, err := MapConfig(configUpdate.ReadSet, rootGroupKey)
+	if err != nil {
+		return fmt.Errorf("error mapping read set: %s", err)
+	}
+
+	writeSet, err := MapConfig(configUpdate.WriteSet, rootGroupKey)
+	if err != nil {
+		return fmt.Errorf("error mapping write set: %s", err)
+	}
+
+	var changes []*Change
+	for key := range ComputeDeltaSet(readSet, writeSet) {
+		changes = append(changes, &Change{Type: Modified, Key: key})
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Key < changes[j].Key
+	})
+
+	return ValidateChangeScope(changes, permittedSubtrees...)
+}
+
+func configEnvelopeFromBlock(block *cb.Block) (*cb.ConfigEnvelope, error) {
+	if block == nil || block.Data == nil || len(block.Data.Data) == 0 {
+		return nil, fmt.Errorf("block contains no data")
+	}
+
+	env, err := utils.ExtractEnvelope(block, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := utils.UnmarshalPayload(env.Payload)
+	if err != nil {
+		return nil, err
+	}
+
+	return UnmarshalConfigEnvelope(payload.Data)
+}
+
+func diffComparables(original, updated comparable) []string {
+	var details []string
+
+	if original.version() != updated.version() {
+		details = append(details, fmt.Sprintf("version %d -> %d", original.version(), updated.version()))
+	}
+
+	if original.modPolicy() != updated.modPolicy() {
+		details = append(details, fmt.Sprintf("mod_policy %q -> %q", original.modPolicy(), updated.modPolicy()))
+	}
+
+	switch {
+	case original.ConfigGroup != nil:
+		details = append(details, diffMembers("groups", groupKeys(original.ConfigGroup), groupKeys(updated.ConfigGroup))...)
+		details = append(details, diffMembers("values", valueKeys(original.ConfigGroup), valueKeys(updated.ConfigGroup))...)
+		details = append(details, diffMembers("policies", policyKeys(original.ConfigGroup), policyKeys(updated.ConfigGroup))...)
+	case original.ConfigValue != nil:
+		if !bytes.Equal(original.ConfigValue.Value, updated.ConfigValue.Value) {
+			details = append(details, fmt.Sprintf("value changed (%d bytes -> %d bytes)", len(original.ConfigValue.Value), len(updated.ConfigValue.Value)))
+		}
+	case original.ConfigPolicy != nil:
+		originalPolicy, updatedPolicy := original.ConfigPolicy.Policy, updated.ConfigPolicy.Policy
+		switch {
+		case originalPolicy == nil && updatedPolicy == nil:
+		case originalPolicy == nil || updatedPolicy == nil:
+			details = append(details, "policy changed")
+		case originalPolicy.Type != updatedPolicy.Type:
+			details = append(details, fmt.Sprintf("policy type %s -> %s", policyTypeName(originalPolicy.Type), policyTypeName(updatedPolicy.Type)))
+		case !bytes.Equal(originalPolicy.Value, updatedPolicy.Value):
+			details = append(details, "policy changed")
+		}
+	}
+
+	return details
+}
+
+// diffMembers describes the members added to and removed from a group
+func diffMembers(kind string, original, updated []string) []string {
+	var added, removed []string
+	for _, key := range updated {
+		if !containsString(original, key) {
+			added = append(added, key)
+		}
+	}
+	for _, key := range original {
+		if !containsString(updated, key) {
+			removed = append(removed, key)
+		}
+	}
+
+	var details []string
+	if len(added) > 0 {
+		details = append(details, fmt.Sprintf("%s added [%s]", kind, strings.Join(added, ", ")))
+	}
+	if len(removed) > 0 {
+		details = append(details, fmt.Sprintf("%s removed [%s]", kind, strings.Join(removed, ", ")))
+	}
+	return details
+}
+
+func groupKeys(group *cb.ConfigGroup) []string {
+	var keys []string
+	for key := range group.Groups {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func valueKeys(group *cb.ConfigGroup) []string {
+	var keys []string
+	for key := range group.Values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func policyKeys(group *cb.ConfigGroup) []string {
+	var keys []string
+	for key := range group.Policies {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+func policyTypeName(policyType int32) string {
+	if name, ok := cb.Policy_PolicyType_name[policyType]; ok {
+		return name
+	}
+	return fmt.Sprintf("%d", policyType)
+}
+
+// keyPath strips the prefix telling the kind of element from a config map key
+func keyPath(key string) string {
+	for _, prefix := range []string{GroupPrefix, ValuePrefix, PolicyPrefix} {
+		if strings.HasPrefix(key, prefix) {
+			return strings.TrimPrefix(key, prefix)
+		}
+	}
+	return key
+}
+
+func withinSubtrees(path string, subtrees []string) bool {
+	for _, subtree := range subtrees {
+		subtree = strings.TrimSuffix(subtree, PathSeparator)
+		if path == subtree || strings.HasPrefix(path, subtree+PathSeparator) {
+			return true
+		}
+	}
+	return false
+}
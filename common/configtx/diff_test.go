@@ -0,0 +1,131 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package configtx
+
+import (
+	"testing"
+
+	cb "github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/utils"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+func makeDiffConfig() *cb.ConfigGroup {
+	config := cb.NewConfigGroup()
+	config.ModPolicy = "Admins"
+	config.Values["Consortium"] = &cb.ConfigValue{Value: []byte("SampleConsortium"), ModPolicy: "Admins"}
+	config.Policies["Admins"] = &cb.ConfigPolicy{Policy: &cb.Policy{Type: int32(cb.Policy_IMPLICIT_META), Value: []byte("MAJORITY Admins")}, ModPolicy: "Admins"}
+
+	application := cb.NewConfigGroup()
+	application.ModPolicy = "Admins"
+	application.Groups["Org1"] = cb.NewConfigGroup()
+	application.Groups["Org1"].Values["MSP"] = &cb.ConfigValue{Value: []byte("org1 msp"), ModPolicy: "Admins"}
+	application.Groups["Org2"] = cb.NewConfigGroup()
+	config.Groups["Application"] = application
+
+	return config
+}
+
+func TestComputeConfigDiff(t *testing.T) {
+	original := makeDiffConfig()
+	updated := proto.Clone(original).(*cb.ConfigGroup)
+
+	changes, err := ComputeConfigDiff(original, updated, "Channel")
+	assert.NoError(t, err)
+	assert.Empty(t, changes)
+
+	application := updated.Groups["Application"]
+	application.Version = 1
+	delete(application.Groups, "Org2")
+	application.Groups["Org3"] = cb.NewConfigGroup()
+	application.Groups["Org1"].Values["MSP"] = &cb.ConfigValue{Value: []byte("new org1 msp"), ModPolicy: "Admins", Version: 1}
+	updated.Values["Consortium"].ModPolicy = "Writers"
+	updated.Policies["Admins"].Policy = &cb.Policy{Type: int32(cb.Policy_SIGNATURE)}
+
+	changes, err = ComputeConfigDiff(original, updated, "Channel")
+	assert.NoError(t, err)
+
+	var descriptions []string
+	for _, change := range changes {
+		descriptions = append(descriptions, change.String())
+	}
+	assert.Equal(t, []string{
+		"Modified [Groups] /Channel/Application: version 0 -> 1, groups added [Org3], groups removed [Org2]",
+		"Removed [Groups] /Channel/Application/Org2",
+		"Added [Groups] /Channel/Application/Org3",
+		"Modified [Policy] /Channel/Admins: policy type IMPLICIT_META -> SIGNATURE",
+		"Modified [Values] /Channel/Application/Org1/MSP: version 0 -> 1, value changed (8 bytes -> 12 bytes)",
+		"Modified [Values] /Channel/Consortium: mod_policy \"Admins\" -> \"Writers\"",
+	}, descriptions)
+	assert.Equal(t, "/Channel/Application/Org2", changes[1].Path())
+
+	_, err = ComputeConfigDiff(original, &cb.ConfigGroup{Groups: map[string]*cb.ConfigGroup{"bad key!": cb.NewConfigGroup()}}, "Channel")
+	assert.Error(t, err)
+}
+
+func TestComputeConfigBlockDiff(t *testing.T) {
+	makeBlock := func(group *cb.ConfigGroup) *cb.Block {
+		return &cb.Block{
+			Data: &cb.BlockData{
+				Data: [][]byte{utils.MarshalOrPanic(&cb.Envelope{
+					Payload: utils.MarshalOrPanic(&cb.Payload{
+						Data: utils.MarshalOrPanic(&cb.ConfigEnvelope{
+							Config: &cb.Config{ChannelGroup: group},
+						}),
+					}),
+				})},
+			},
+		}
+	}
+
+	original := makeDiffConfig()
+	updated := makeDiffConfig()
+	updated.Values["BatchSize"] = &cb.ConfigValue{}
+
+	changes, err := ComputeConfigBlockDiff(makeBlock(original), makeBlock(updated), "Channel")
+	assert.NoError(t, err)
+	assert.Equal(t, []*Change{
+		{Type: Modified, Key: "[Groups] /Channel", Details: []string{"values added [BatchSize]"}},
+		{Type: Added, Key: "[Values] /Channel/BatchSize"},
+	}, changes)
+
+	_, err = ComputeConfigBlockDiff(&cb.Block{}, makeBlock(updated), "Channel")
+	assert.Error(t, err)
+
+	_, err = ComputeConfigEnvelopeDiff(&cb.ConfigEnvelope{}, &cb.ConfigEnvelope{}, "Channel")
+	assert.Error(t, err)
+}
+
+func TestValidateChangeScope(t *testing.T) {
+	changes := []*Change{
+		{Type: Modified, Key: "[Values] /Channel/Application/Org1/MSP"},
+		{Type: Added, Key: "[Policy] /Channel/Application/Org1/Admins"},
+	}
+	assert.NoError(t, ValidateChangeScope(changes, "/Channel/Application/Org1"))
+	assert.NoError(t, ValidateChangeScope(changes, "/Channel/Application/"))
+	assert.NoError(t, ValidateChangeScope(nil))
+
+	changes = append(changes, &Change{Type: Modified, Key: "[Groups] /Channel/Application/Org10"})
+	err := ValidateChangeScope(changes, "/Channel/Application/Org1")
+	assert.EqualError(t, err, "changes to [Groups] /Channel/Application/Org10 are not within the permitted subtrees [/Channel/Application/Org1]")
+}
+
+func TestValidateConfigUpdateScope(t *testing.T) {
+	readSet := makeDiffConfig()
+	writeSet := proto.Clone(readSet).(*cb.ConfigGroup)
+	writeSet.Groups["Application"].Groups["Org1"].Values["MSP"].Version = 1
+
+	configUpdate := &cb.ConfigUpdate{ReadSet: readSet, WriteSet: writeSet}
+	assert.NoError(t, ValidateConfigUpdateScope(configUpdate, "Channel", "/Channel/Application/Org1"))
+
+	writeSet.Values["Consortium"].Version = 1
+	err := ValidateConfigUpdateScope(configUpdate, "Channel", "/Channel/Application/Org1")
+	assert.EqualError(t, err, "changes to [Values] /Channel/Consortium are not within the permitted subtrees [/Channel/Application/Org1]")
+	assert.NoError(t, ValidateConfigUpdateScope(configUpdate, "Channel", "/Channel"))
+}
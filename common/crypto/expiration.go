@@ -0,0 +1,19 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package crypto
+
+import (
+	"time"
+
+	"github.com/hyperledger/fabric/msp"
+)
+
+// ExpiresAt returns when the given identity expires, or a zero time.Time
+// in case we cannot determine that
+func ExpiresAt(identityBytes []byte) time.Time {
+	return msp.ExpiresAt(identityBytes)
+}
@@ -0,0 +1,122 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package flogging
+
+import (
+	"fmt"
+
+	"github.com/op/go-logging"
+)
+
+// shortTxIDLength is the length the transaction ids are shortened to in the console format
+const shortTxIDLength = 8
+
+// ContextLogger is a logger whose records carry the channel they are logged for, and the
+// transaction id when available. The console format prefixes the messages with them, while
+// the JSON format emits them as the ChannelField and TxIDField fields of the records.
+type ContextLogger struct {
+	logger  *logging.Logger
+	channel string
+	txID    string
+}
+
+// MustGetContextLogger returns a ContextLogger for the module, which carries no context until
+// one is attached with WithChannel or WithTxID. Like MustGetLogger, it registers the module
+// in the map of modules whose levels are managed by this package.
+func MustGetContextLogger(module string) *ContextLogger {
+	MustGetLogger(module)
+	logger := logging.MustGetLogger(module)
+	// skip the frame of the ContextLogger method when locating the caller
+	logger.ExtraCalldepth = 1
+	return &ContextLogger{logger: logger}
+}
+
+// WithChannel returns a logger whose records carry the given channel, and the context of this logger
+func (l *ContextLogger) WithChannel(channel string) *ContextLogger {
+	ctxLogger := *l
+	ctxLogger.channel = channel
+	return &ctxLogger
+}
+
+// WithTxID returns a logger whose records carry the given transaction id, and the context of this logger
+func (l *ContextLogger) WithTxID(txID string) *ContextLogger {
+	ctxLogger := *l
+	ctxLogger.txID = txID
+	return &ctxLogger
+}
+
+// IsEnabledFor returns true if the logger is enabled for the given level
+func (l *ContextLogger) IsEnabledFor(level logging.Level) bool {
+	return l.logger.IsEnabledFor(level)
+}
+
+// Debugf logs a message at the DEBUG level
+func (l *ContextLogger) Debugf(format string, args ...interface{}) {
+	if l.logger.IsEnabledFor(logging.DEBUG) {
+		l.logger.Debug(l.entry(format, args))
+	}
+}
+
+// Infof logs a message at the INFO level
+func (l *ContextLogger) Infof(format string, args ...interface{}) {
+	if l.logger.IsEnabledFor(logging.INFO) {
+		l.logger.Info(l.entry(format, args))
+	}
+}
+
+// Warningf logs a message at the WARNING level
+func (l *ContextLogger) Warningf(format string, args ...interface{}) {
+	if l.logger.IsEnabledFor(logging.WARNING) {
+		l.logger.Warning(l.entry(format, args))
+	}
+}
+
+// Errorf logs a message at the ERROR level
+func (l *ContextLogger) Errorf(format string, args ...interface{}) {
+	if l.logger.IsEnabledFor(logging.ERROR) {
+		l.logger.Error(l.entry(format, args))
+	}
+}
+
+// Criticalf logs a message at the CRITICAL level
+func (l *ContextLogger) Criticalf(format string, args ...interface{}) {
+	l.logger.Critical(l.entry(format, args))
+}
+
+// Panicf logs a message at the CRITICAL level and panics with it
+func (l *ContextLogger) Panicf(format string, args ...interface{}) {
+	entry := l.entry(format, args)
+	l.logger.Critical(entry)
+	panic(entry.String())
+}
+
+func (l *ContextLogger) entry(format string, args []interface{}) *contextEntry {
+	return &contextEntry{channel: l.channel, txID: l.txID, message: fmt.Sprintf(format, args...)}
+}
+
+// contextEntry is the single argument of the records logged by a ContextLogger, which the
+// console format prints with String, and the JSON format decomposes into fields
+type contextEntry struct {
+	channel string
+	txID    string
+	message string
+}
+
+func (e *contextEntry) String() string {
+	prefix := ""
+	if e.channel != "" {
+		prefix = "[" + e.channel + "] "
+	}
+	if e.txID != "" {
+		txID := e.txID
+		if len(txID) > shortTxIDLength {
+			txID = txID[:shortTxIDLength]
+		}
+		prefix += "[" + txID + "] "
+	}
+	return prefix + e.message
+}
@@ -0,0 +1,79 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package flogging_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/stretchr/testify/assert"
+)
+
+const testTxID = "2f1b5e9a0c7d4e8f9a6b3c2d1e0f4a5b6c7d8e9f0a1b2c3d4e5f6a7b8c9d0e1f"
+
+func TestContextLoggerConsole(t *testing.T) {
+	defer flogging.Reset()
+	buf := &bytes.Buffer{}
+	flogging.InitBackend(flogging.SetFormat("[%{module}] %{shortfile} %{level:.4s} %{message}"), buf)
+
+	logger := flogging.MustGetContextLogger("context/console")
+	logger.Infof("no context %d", 1)
+	logger.WithChannel("testchannel").Infof("channel only")
+	logger.WithChannel("testchannel").WithTxID(testTxID).Warningf("channel and %s", "txID")
+	logger.Debugf("below the level")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, lines, 3)
+	assert.Equal(t, "[context/console] context_test.go:27 INFO no context 1", lines[0])
+	assert.Equal(t, "[context/console] context_test.go:28 INFO [testchannel] channel only", lines[1])
+	assert.Equal(t, "[context/console] context_test.go:29 WARN [testchannel] [2f1b5e9a] channel and txID", lines[2])
+	assert.Equal(t, flogging.DefaultLevel(), flogging.GetModuleLevel("context/console"))
+}
+
+func TestJSONFormat(t *testing.T) {
+	defer flogging.Reset()
+	buf := &bytes.Buffer{}
+	flogging.InitBackend(flogging.SetFormat(flogging.JSONFormat), buf)
+
+	flogging.MustGetLogger("json/plain").Errorf("plain %s", "record")
+	logger := flogging.MustGetContextLogger("json/context")
+	logger.WithChannel("testchannel").WithTxID(testTxID).Infof("context %s", "record")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, lines, 2)
+	var plain, context map[string]string
+	assert.NoError(t, json.Unmarshal([]byte(lines[0]), &plain))
+	assert.NoError(t, json.Unmarshal([]byte(lines[1]), &context))
+
+	assert.Equal(t, "ERROR", plain[flogging.LevelField])
+	assert.Equal(t, "json/plain", plain[flogging.ModuleField])
+	assert.Equal(t, "context_test.go:45", plain[flogging.CallerField])
+	assert.Equal(t, "plain record", plain[flogging.MessageField])
+	assert.NotEmpty(t, plain[flogging.TimeField])
+	assert.NotContains(t, plain, flogging.ChannelField)
+	assert.NotContains(t, plain, flogging.TxIDField)
+
+	assert.Equal(t, "INFO", context[flogging.LevelField])
+	assert.Equal(t, "json/context", context[flogging.ModuleField])
+	assert.Equal(t, "context_test.go:47", context[flogging.CallerField])
+	assert.Equal(t, "testchannel", context[flogging.ChannelField])
+	assert.Equal(t, testTxID, context[flogging.TxIDField])
+	assert.Equal(t, "context record", context[flogging.MessageField])
+}
+
+func TestContextLoggerPanicf(t *testing.T) {
+	defer flogging.Reset()
+	flogging.InitBackend(flogging.SetFormat(""), &bytes.Buffer{})
+	logger := flogging.MustGetContextLogger("context/panic").WithChannel("testchannel")
+	defer func() {
+		assert.Equal(t, "[testchannel] failed 1", recover())
+	}()
+	logger.Panicf("failed %d", 1)
+}
@@ -0,0 +1,76 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package flogging
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"github.com/op/go-logging"
+)
+
+// JSONFormat is the logging format which emits each record as a single line JSON object,
+// for logs ingested by machines
+const JSONFormat = "json"
+
+// Field names of the JSON records
+const (
+	TimeField    = "ts"
+	LevelField   = "level"
+	ModuleField  = "module"
+	CallerField  = "caller"
+	ChannelField = "channel"
+	TxIDField    = "txID"
+	MessageField = "msg"
+)
+
+// jsonFormatter formats the records as JSON objects. The channel and the transaction id
+// of the records logged by a ContextLogger are emitted as fields of their own, and omitted
+// from the records which do not carry them
+type jsonFormatter struct{}
+
+func (f *jsonFormatter) Format(calldepth int, r *logging.Record, w io.Writer) error {
+	fields := map[string]string{
+		TimeField:   r.Time.UTC().Format(time.RFC3339Nano),
+		LevelField:  r.Level.String(),
+		ModuleField: r.Module,
+	}
+	if _, file, line, ok := runtime.Caller(calldepth + 1); ok {
+		fields[CallerField] = fmt.Sprintf("%s:%d", filepath.Base(file), line)
+	}
+	if entry, isContextEntry := contextEntryOf(r); isContextEntry {
+		if entry.channel != "" {
+			fields[ChannelField] = entry.channel
+		}
+		if entry.txID != "" {
+			fields[TxIDField] = entry.txID
+		}
+		fields[MessageField] = entry.message
+	} else {
+		fields[MessageField] = r.Message()
+	}
+
+	// maps are marshaled with their keys sorted, which keeps the order of the fields stable
+	b, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+func contextEntryOf(r *logging.Record) (*contextEntry, bool) {
+	if len(r.Args) != 1 {
+		return nil, false
+	}
+	entry, isContextEntry := r.Args[0].(*contextEntry)
+	return entry, isContextEntry
+}
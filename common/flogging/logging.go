@@ -17,6 +17,7 @@ limitations under the License.
 package flogging
 
 import (
+	"fmt"
 	"io"
 	"os"
 	"regexp"
@@ -40,6 +41,10 @@ var (
 	modules          map[string]string // Holds the map of all modules and their respective log level
 	peerStartModules map[string]string
 
+	activeSpec     string                   // The logging specification last activated with ActivateSpec or InitFromSpec
+	specOverrides  map[string]logging.Level // The module levels set by the active specification
+	activationLock sync.Mutex               // Serializes the activations of logging specifications
+
 	lock sync.RWMutex
 	once sync.Once
 )
@@ -60,8 +65,12 @@ func Reset() {
 	InitFromSpec("")
 }
 
-// SetFormat sets the logging format.
+// SetFormat sets the logging format. JSONFormat selects the JSON format, which emits each
+// record as a JSON object; any other value is a go-logging format pattern for the console.
 func SetFormat(formatSpec string) logging.Formatter {
+	if formatSpec == JSONFormat {
+		return &jsonFormatter{}
+	}
 	if formatSpec == "" {
 		formatSpec = defaultFormat
 	}
@@ -187,9 +196,120 @@ func InitFromSpec(spec string) string {
 	// register flogging logger in the modules map
 	MustGetLogger(pkgLogID)
 
+	activationLock.Lock()
+	activeSpec = spec
+	if _, overrides, err := ParseSpec(spec); err == nil {
+		specOverrides = overrides
+	}
+	activationLock.Unlock()
+
 	return levelAll.String()
 }
 
+// ParseSpec parses a logging specification of the form accepted by InitFromSpec,
+// returning the default level and the levels of the modules it overrides. Unlike
+// InitFromSpec, which ignores the invalid parts of a specification, ParseSpec
+// returns an error for a specification that is not entirely valid.
+func ParseSpec(spec string) (logging.Level, map[string]logging.Level, error) {
+	levelAll := defaultLevel
+	overrides := make(map[string]logging.Level)
+	if spec == "" {
+		return levelAll, overrides, nil
+	}
+	for _, field := range strings.Split(spec, ":") {
+		split := strings.Split(field, "=")
+		switch len(split) {
+		case 1:
+			level, err := logging.LogLevel(field)
+			if err != nil {
+				return 0, nil, fmt.Errorf("invalid logging level '%s' in spec '%s'", field, spec)
+			}
+			levelAll = level
+		case 2:
+			level, err := logging.LogLevel(split[1])
+			if err != nil {
+				return 0, nil, fmt.Errorf("invalid logging level '%s' in spec '%s'", split[1], spec)
+			}
+			if split[0] == "" {
+				return 0, nil, fmt.Errorf("invalid logging override '%s' in spec '%s' - no module specified", field, spec)
+			}
+			for _, module := range strings.Split(split[0], ",") {
+				if module == "" {
+					return 0, nil, fmt.Errorf("invalid logging override '%s' in spec '%s' - empty module name", field, spec)
+				}
+				overrides[module] = level
+			}
+		default:
+			return 0, nil, fmt.Errorf("invalid logging override '%s' in spec '%s' - missing ':'?", field, spec)
+		}
+	}
+	return levelAll, overrides, nil
+}
+
+// ActivateSpec replaces the log levels of all the modules with the levels of the
+// supplied logging specification, at runtime. The modules the specification does
+// not name, including those whose loggers are created later, log at its default
+// level, and the module overrides of the previously active specification are
+// dropped. An invalid specification is rejected as a whole, leaving the levels
+// unchanged. Concurrent activations are applied one at a time.
+func ActivateSpec(spec string) error {
+	levelAll, overrides, err := ParseSpec(spec)
+	if err != nil {
+		return err
+	}
+
+	activationLock.Lock()
+	defer activationLock.Unlock()
+	lock.Lock()
+	defer lock.Unlock()
+
+	for module := range specOverrides {
+		if _, overridden := overrides[module]; !overridden {
+			logging.SetLevel(levelAll, module)
+		}
+	}
+	for module := range modules {
+		if _, overridden := overrides[module]; !overridden {
+			logging.SetLevel(levelAll, module)
+		}
+	}
+	for module, level := range overrides {
+		logging.SetLevel(level, module)
+	}
+	logging.SetLevel(levelAll, "")
+
+	for module := range modules {
+		modules[module] = GetModuleLevel(module)
+	}
+	activeSpec = spec
+	specOverrides = overrides
+	logger.Infof("Activated logging spec '%s'", spec)
+	return nil
+}
+
+// Spec returns the logging specification last activated with ActivateSpec or
+// InitFromSpec, or the default level if it was empty.
+func Spec() string {
+	activationLock.Lock()
+	defer activationLock.Unlock()
+	if activeSpec == "" {
+		return DefaultLevel()
+	}
+	return activeSpec
+}
+
+// ModuleLevels returns the current log levels of all the modules that have
+// loggers, keyed by module.
+func ModuleLevels() map[string]string {
+	lock.RLock()
+	defer lock.RUnlock()
+	levels := make(map[string]string, len(modules))
+	for module := range modules {
+		levels[module] = GetModuleLevel(module)
+	}
+	return levels
+}
+
 // SetPeerStartupModulesMap saves the modules and their log levels.
 // this function should only be called at the end of peer startup.
 func SetPeerStartupModulesMap() {
@@ -18,6 +18,7 @@ package flogging_test
 
 import (
 	"os"
+	"sync"
 	"testing"
 
 	"github.com/hyperledger/fabric/common/flogging"
@@ -159,6 +160,75 @@ func TestInitFromSpec(t *testing.T) {
 
 }
 
+func TestParseSpec(t *testing.T) {
+	levelAll, overrides, err := flogging.ParseSpec("warning:gossip,gossip/state=debug:ledger=error")
+	assert.NoError(t, err)
+	assert.Equal(t, logging.WARNING, levelAll)
+	assert.Equal(t, map[string]logging.Level{
+		"gossip":       logging.DEBUG,
+		"gossip/state": logging.DEBUG,
+		"ledger":       logging.ERROR,
+	}, overrides)
+
+	levelAll, overrides, err = flogging.ParseSpec("")
+	assert.NoError(t, err)
+	assert.Equal(t, flogging.DefaultLevel(), levelAll.String())
+	assert.Empty(t, overrides)
+
+	for _, spec := range []string{"foo", "a=foo", "=warning", "a=b=c", "a,=debug"} {
+		_, _, err := flogging.ParseSpec(spec)
+		assert.Error(t, err, "Expected spec '%s' to be rejected", spec)
+	}
+}
+
+func TestActivateSpec(t *testing.T) {
+	defer flogging.Reset()
+	flogging.MustGetLogger("gossip")
+	flogging.MustGetLogger("gossip/state")
+	flogging.MustGetLogger("ledger")
+
+	assert.Equal(t, flogging.DefaultLevel(), flogging.Spec())
+	assert.NoError(t, flogging.ActivateSpec("warning:gossip/state=debug"))
+	assert.Equal(t, "warning:gossip/state=debug", flogging.Spec())
+	assert.Equal(t, "DEBUG", flogging.GetModuleLevel("gossip/state"))
+	assert.Equal(t, "WARNING", flogging.GetModuleLevel("gossip"))
+	assert.Equal(t, "WARNING", flogging.GetModuleLevel("ledger"))
+	assert.Equal(t, "WARNING", flogging.GetModuleLevel("notyetcreated"))
+	assert.Equal(t, "DEBUG", flogging.ModuleLevels()["gossip/state"])
+
+	// an invalid spec leaves the levels unchanged
+	assert.Error(t, flogging.ActivateSpec("gossip=foo"))
+	assert.Equal(t, "warning:gossip/state=debug", flogging.Spec())
+	assert.Equal(t, "DEBUG", flogging.GetModuleLevel("gossip/state"))
+
+	// the overrides of the previous spec are replaced
+	assert.NoError(t, flogging.ActivateSpec("error:ledger=info"))
+	assert.Equal(t, "ERROR", flogging.GetModuleLevel("gossip/state"))
+	assert.Equal(t, "ERROR", flogging.GetModuleLevel("gossip"))
+	assert.Equal(t, "INFO", flogging.GetModuleLevel("ledger"))
+	assert.Equal(t, "INFO", flogging.ModuleLevels()["ledger"])
+
+	// concurrent activations do not interleave
+	var wg sync.WaitGroup
+	for _, spec := range []string{"debug:ledger=error", "error:ledger=debug"} {
+		wg.Add(1)
+		go func(spec string) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				assert.NoError(t, flogging.ActivateSpec(spec))
+			}
+		}(spec)
+	}
+	wg.Wait()
+	if flogging.Spec() == "debug:ledger=error" {
+		assert.Equal(t, "DEBUG", flogging.GetModuleLevel("gossip"))
+		assert.Equal(t, "ERROR", flogging.GetModuleLevel("ledger"))
+	} else {
+		assert.Equal(t, "ERROR", flogging.GetModuleLevel("gossip"))
+		assert.Equal(t, "DEBUG", flogging.GetModuleLevel("ledger"))
+	}
+}
+
 func ExampleInitBackend() {
 	level, _ := logging.LogLevel(flogging.DefaultLevel())
 	// initializes logging backend for testing and sets time to 1970-01-01 00:00:00.000 UTC
@@ -18,6 +18,7 @@ package blkstorage
 
 import (
 	"errors"
+	"fmt"
 
 	"github.com/hyperledger/fabric/common/ledger"
 	"github.com/hyperledger/fabric/protos/common"
@@ -35,6 +36,8 @@ const (
 	IndexableAttrBlockNumTranNum  = IndexableAttr("BlockNumTranNum")
 	IndexableAttrBlockTxID        = IndexableAttr("BlockTxID")
 	IndexableAttrTxValidationCode = IndexableAttr("TxValidationCode")
+	IndexableAttrTxCreator        = IndexableAttr("TxCreator")
+	IndexableAttrTxMSPIDChaincode = IndexableAttr("TxMSPIDChaincode")
 )
 
 // IndexConfig - a configuration that includes a list of attributes that should be indexed
@@ -55,6 +58,11 @@ type BlockStoreProvider interface {
 	OpenBlockStore(ledgerid string) (BlockStore, error)
 	Exists(ledgerid string) (bool, error)
 	List() ([]string, error)
+	// Remove removes the BlockStore with given id, which must have been shut down
+	Remove(ledgerid string) error
+	// Rollback removes the blocks at and above the given height from the BlockStore
+	// with given id, which must have been shut down
+	Rollback(ledgerid string, height uint64) error
 	Close()
 }
 
@@ -71,5 +79,64 @@ type BlockStore interface {
 	RetrieveTxByBlockNumTranNum(blockNum uint64, tranNum uint64) (*common.Envelope, error)
 	RetrieveBlockByTxID(txID string) (*common.Block, error)
 	RetrieveTxValidationCodeByTxID(txID string) (peer.TxValidationCode, error)
+	// RetrieveTxValidationCodesByTxIDs returns the validation codes of the given transactions,
+	// leaving the transactions that are not found out of the returned map
+	RetrieveTxValidationCodesByTxIDs(txIDs []string) (map[string]peer.TxValidationCode, error)
+	// RetrieveTxsByCreator returns, in the order of the chain, up to limit transactions created by
+	// the given serialized identity at or after the given position, along with the position of the
+	// next one, which is nil if there are no more
+	RetrieveTxsByCreator(creator []byte, start TxPosition, limit int) ([]*peer.ProcessedTransaction, *TxPosition, error)
+	// RetrieveTxsByMSPIDAndChaincode returns, in the order of the chain, up to limit transactions submitted
+	// by the org of the given MSP ID to the given chaincode at or after the given position, along with the
+	// position of the next one, which is nil if there are no more
+	RetrieveTxsByMSPIDAndChaincode(mspID string, chaincodeName string, start TxPosition, limit int) ([]*peer.ProcessedTransaction, *TxPosition, error)
+	VerifyChain(startHeight uint64, endHeight uint64) (*CorruptionReport, error)
 	Shutdown()
 }
+
+// TxPosition locates a transaction by the number of its block and its number in the block
+type TxPosition struct {
+	BlockNum uint64
+	TranNum  uint64
+}
+
+// CorruptionKind classifies an inconsistency found while verifying the chain
+type CorruptionKind string
+
+// constants for the kinds of corruption
+const (
+	CorruptionUnreadableBlock   = CorruptionKind("UnreadableBlock")
+	CorruptionBlockNumMismatch  = CorruptionKind("BlockNumMismatch")
+	CorruptionDataHashMismatch  = CorruptionKind("DataHashMismatch")
+	CorruptionPrevHashMismatch  = CorruptionKind("PreviousHashMismatch")
+	CorruptionIndexInconsistent = CorruptionKind("IndexInconsistent")
+)
+
+// BlockCorruption describes an inconsistency found in a block
+type BlockCorruption struct {
+	BlockNum uint64
+	Kind     CorruptionKind
+	Detail   string
+}
+
+// CorruptionReport lists the inconsistencies found while verifying
+// the blocks in the range [StartHeight, EndHeight)
+type CorruptionReport struct {
+	StartHeight uint64
+	EndHeight   uint64
+	Corruptions []*BlockCorruption
+}
+
+// IsCorrupted returns whether any inconsistency was found
+func (r *CorruptionReport) IsCorrupted() bool {
+	return len(r.Corruptions) != 0
+}
+
+// Add records an inconsistency found in the given block
+func (r *CorruptionReport) Add(blockNum uint64, kind CorruptionKind, format string, args ...interface{}) {
+	r.Corruptions = append(r.Corruptions, &BlockCorruption{
+		BlockNum: blockNum,
+		Kind:     kind,
+		Detail:   fmt.Sprintf(format, args...),
+	})
+}
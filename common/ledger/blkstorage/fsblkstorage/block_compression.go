@@ -0,0 +1,92 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package fsblkstorage
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/golang/snappy"
+)
+
+// blockCompression identifies the codec a block is compressed with in the block file
+type blockCompression byte
+
+const (
+	compressionNone   blockCompression = 0
+	compressionSnappy blockCompression = 1
+	// compressionZstd is reserved for zstd compressed blocks,
+	// no zstd implementation is vendored yet
+	compressionZstd blockCompression = 2
+)
+
+// compressedBlockMarker precedes the codec and the compressed bytes of a compressed block.
+// A serialized block starts with the varint encoded block number, and a canonical varint
+// never starts with these bytes, so compressed and plain blocks can share a block file
+var compressedBlockMarker = []byte{0x80, 0x00}
+
+func parseBlockCompression(name string) (blockCompression, error) {
+	switch name {
+	case "", "none":
+		return compressionNone, nil
+	case "snappy":
+		return compressionSnappy, nil
+	case "zstd":
+		return compressionNone, fmt.Errorf("block compression [zstd] is not available in this build")
+	default:
+		return compressionNone, fmt.Errorf("unknown block compression [%s]", name)
+	}
+}
+
+func (c blockCompression) String() string {
+	switch c {
+	case compressionNone:
+		return "none"
+	case compressionSnappy:
+		return "snappy"
+	case compressionZstd:
+		return "zstd"
+	default:
+		return fmt.Sprintf("unknown(%d)", byte(c))
+	}
+}
+
+// encodeBlockBytes returns the bytes to be stored in the block file for the given serialized block
+func encodeBlockBytes(blockBytes []byte, compression blockCompression) ([]byte, error) {
+	switch compression {
+	case compressionNone:
+		return blockBytes, nil
+	case compressionSnappy:
+		encoded := append(append([]byte{}, compressedBlockMarker...), byte(compression))
+		return append(encoded, snappy.Encode(nil, blockBytes)...), nil
+	default:
+		return nil, fmt.Errorf("cannot compress block with codec [%s]", compression)
+	}
+}
+
+// decodeBlockBytes returns the serialized block for the given bytes stored in the block file,
+// along with a flag that indicates whether the block was stored compressed
+func decodeBlockBytes(storedBytes []byte) ([]byte, bool, error) {
+	if !bytes.HasPrefix(storedBytes, compressedBlockMarker) {
+		return storedBytes, false, nil
+	}
+	if len(storedBytes) <= len(compressedBlockMarker) {
+		return nil, true, fmt.Errorf("compressed block has no codec")
+	}
+	compression := blockCompression(storedBytes[len(compressedBlockMarker)])
+	compressed := storedBytes[len(compressedBlockMarker)+1:]
+	switch compression {
+	case compressionSnappy:
+		blockBytes, err := snappy.Decode(nil, compressed)
+		if err != nil {
+			return nil, true, fmt.Errorf("error while decompressing block: %s", err)
+		}
+		return blockBytes, true, nil
+	default:
+		return nil, true, fmt.Errorf("cannot decompress block with codec [%s]", compression)
+	}
+}
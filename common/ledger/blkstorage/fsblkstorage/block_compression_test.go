@@ -0,0 +1,138 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package fsblkstorage
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric/common/ledger/blkstorage"
+	"github.com/hyperledger/fabric/common/ledger/testutil"
+	putil "github.com/hyperledger/fabric/protos/utils"
+)
+
+func TestParseBlockCompression(t *testing.T) {
+	for name, expected := range map[string]blockCompression{"": compressionNone, "none": compressionNone, "snappy": compressionSnappy} {
+		c, err := parseBlockCompression(name)
+		testutil.AssertNoError(t, err, "")
+		testutil.AssertEquals(t, c, expected)
+	}
+	_, err := parseBlockCompression("zstd")
+	testutil.AssertError(t, err, "zstd is not available in this build")
+	_, err = parseBlockCompression("lz4")
+	testutil.AssertError(t, err, "Unknown codec should be rejected")
+
+	_, err = NewConfWithCompression(testPath(), 0, "lz4")
+	testutil.AssertError(t, err, "Unknown codec should be rejected")
+	conf, err := NewConfWithCompression(testPath(), 0, "snappy")
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, conf.compression, compressionSnappy)
+	testutil.AssertEquals(t, conf.maxBlockfileSize, defaultMaxBlockfileSize)
+}
+
+func TestEncodeDecodeBlockBytes(t *testing.T) {
+	block := testutil.ConstructTestBlocks(t, 1)[0]
+	blockBytes, _, err := serializeBlock(block)
+	testutil.AssertNoError(t, err, "")
+
+	stored, err := encodeBlockBytes(blockBytes, compressionNone)
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, stored, blockBytes)
+	decoded, compressed, err := decodeBlockBytes(stored)
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, compressed, false)
+	testutil.AssertEquals(t, decoded, blockBytes)
+
+	stored, err = encodeBlockBytes(blockBytes, compressionSnappy)
+	testutil.AssertNoError(t, err, "")
+	decoded, compressed, err = decodeBlockBytes(stored)
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, compressed, true)
+	testutil.AssertEquals(t, decoded, blockBytes)
+
+	_, err = encodeBlockBytes(blockBytes, compressionZstd)
+	testutil.AssertError(t, err, "zstd is not available in this build")
+	_, _, err = decodeBlockBytes(append(append([]byte{}, compressedBlockMarker...), byte(compressionZstd), 1, 2, 3))
+	testutil.AssertError(t, err, "zstd is not available in this build")
+	_, _, err = decodeBlockBytes(compressedBlockMarker)
+	testutil.AssertError(t, err, "Compressed block without codec should be rejected")
+}
+
+func TestFileLocPointerMarshalCompressed(t *testing.T) {
+	flp := newTxLocationPointer(&fileLocPointer{fileSuffixNum: 2, locPointer: locPointer{offset: 100}},
+		&locPointer{offset: 35, bytesLength: 20}, true)
+	b, err := flp.marshal()
+	testutil.AssertNoError(t, err, "")
+	unmarshalled := &fileLocPointer{}
+	testutil.AssertNoError(t, unmarshalled.unmarshal(b), "")
+	testutil.AssertEquals(t, unmarshalled, &fileLocPointer{2, locPointer{100, 20}, 35})
+
+	flp = newTxLocationPointer(&fileLocPointer{fileSuffixNum: 2, locPointer: locPointer{offset: 100}},
+		&locPointer{offset: 35, bytesLength: 20}, false)
+	b, err = flp.marshal()
+	testutil.AssertNoError(t, err, "")
+	unmarshalled = &fileLocPointer{}
+	testutil.AssertNoError(t, unmarshalled.unmarshal(b), "")
+	testutil.AssertEquals(t, unmarshalled, &fileLocPointer{2, locPointer{135, 20}, 0})
+}
+
+func TestBlockfileMgrCompressedBlocks(t *testing.T) {
+	env := newTestEnv(t, NewConf(testPath(), 0))
+	defer env.Cleanup()
+	ledgerid := "testLedger"
+	blkfileMgrWrapper := newTestBlockfileWrapper(env, ledgerid)
+	blkfileMgr := blkfileMgrWrapper.blockfileMgr
+	blocks := testutil.ConstructTestBlocks(t, 10)
+
+	// The first blocks are stored plain, the others compressed, in the same block file
+	blkfileMgrWrapper.addBlocks(blocks[:4])
+	blkfileMgr.conf.compression = compressionSnappy
+	blkfileMgrWrapper.addBlocks(blocks[4:7])
+	// Leave the last blocks out of the index, so that they get indexed by the index sync
+	origIndex := blkfileMgr.index
+	blkfileMgr.index = &noopIndex{}
+	blkfileMgrWrapper.addBlocks(blocks[7:])
+	blkfileMgr.index = origIndex
+	blkfileMgrWrapper.close()
+
+	blkfileMgrWrapper = newTestBlockfileWrapper(env, ledgerid)
+	defer blkfileMgrWrapper.close()
+	blkfileMgr = blkfileMgrWrapper.blockfileMgr
+	blkfileMgrWrapper.testGetBlockByHash(blocks)
+	blkfileMgrWrapper.testGetBlockByNumber(blocks, 0)
+
+	for blockIndex, blk := range blocks {
+		for tranIndex, txEnvelopeBytes := range blk.Data.Data {
+			txEnvelope, err := putil.GetEnvelopeFromBlock(txEnvelopeBytes)
+			testutil.AssertNoError(t, err, "Error while unmarshalling tx")
+			txEnvelopeFromFileMgr, err := blkfileMgr.retrieveTransactionByBlockNumTranNum(uint64(blockIndex), uint64(tranIndex))
+			testutil.AssertNoError(t, err, "Error while retrieving tx from blkfileMgr")
+			testutil.AssertEquals(t, txEnvelopeFromFileMgr, txEnvelope)
+
+			txID, err := extractTxID(txEnvelopeBytes)
+			testutil.AssertNoError(t, err, "")
+			txEnvelopeFromFileMgr, err = blkfileMgr.retrieveTransactionByID(txID)
+			testutil.AssertNoError(t, err, "Error while retrieving tx from blkfileMgr")
+			testutil.AssertEquals(t, txEnvelopeFromFileMgr, txEnvelope)
+			blockFromFileMgr, err := blkfileMgr.retrieveBlockByTxID(txID)
+			testutil.AssertNoError(t, err, "Error while retrieving block from blkfileMgr")
+			testutil.AssertEquals(t, blockFromFileMgr, blk)
+		}
+	}
+
+	itr, err := blkfileMgr.retrieveBlocks(0)
+	testutil.AssertNoError(t, err, "")
+	defer itr.Close()
+	for _, blk := range blocks {
+		result, err := itr.Next()
+		testutil.AssertNoError(t, err, "")
+		testutil.AssertEquals(t, result, blk)
+	}
+
+	report, err := blkfileMgr.verifyChain(0, 10)
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, report.Corruptions, []*blkstorage.BlockCorruption(nil))
+}
@@ -20,6 +20,8 @@ import (
 	"github.com/golang/protobuf/proto"
 	ledgerutil "github.com/hyperledger/fabric/common/ledger/util"
 	"github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/msp"
+	"github.com/hyperledger/fabric/protos/peer"
 	"github.com/hyperledger/fabric/protos/utils"
 )
 
@@ -32,7 +34,13 @@ type serializedBlockInfo struct {
 //The order of the transactions must be maintained for history
 type txindexInfo struct {
 	txID string
-	loc  *locPointer
+	// creator is the serialized identity of the submitter of the transaction, mspID is
+	// its MSP ID and chaincodeName is the name of the chaincode the transaction invokes.
+	// They are left blank when they cannot be extracted from the header of the transaction
+	creator       []byte
+	mspID         string
+	chaincodeName string
+	loc           *locPointer
 }
 
 func serializeBlock(block *common.Block) ([]byte, *serializedBlockInfo, error) {
@@ -111,14 +119,14 @@ func addDataBytes(blockData *common.BlockData, buf *proto.Buffer) ([]*txindexInf
 	}
 	for _, txEnvelopeBytes := range blockData.Data {
 		offset := len(buf.Bytes())
-		txid, err := extractTxID(txEnvelopeBytes)
+		idxInfo, err := extractTxIndexInfo(txEnvelopeBytes)
 		if err != nil {
 			return nil, err
 		}
 		if err := buf.EncodeRawBytes(txEnvelopeBytes); err != nil {
 			return nil, err
 		}
-		idxInfo := &txindexInfo{txid, &locPointer{offset, len(buf.Bytes()) - offset}}
+		idxInfo.loc = &locPointer{offset, len(buf.Bytes()) - offset}
 		txOffsets = append(txOffsets, idxInfo)
 	}
 	return txOffsets, nil
@@ -169,16 +177,16 @@ func extractData(buf *ledgerutil.Buffer) (*common.BlockData, []*txindexInfo, err
 	}
 	for i := uint64(0); i < numItems; i++ {
 		var txEnvBytes []byte
-		var txid string
+		var idxInfo *txindexInfo
 		txOffset := buf.GetBytesConsumed()
 		if txEnvBytes, err = buf.DecodeRawBytes(false); err != nil {
 			return nil, nil, err
 		}
-		if txid, err = extractTxID(txEnvBytes); err != nil {
+		if idxInfo, err = extractTxIndexInfo(txEnvBytes); err != nil {
 			return nil, nil, err
 		}
 		data.Data = append(data.Data, txEnvBytes)
-		idxInfo := &txindexInfo{txid, &locPointer{txOffset, buf.GetBytesConsumed() - txOffset}}
+		idxInfo.loc = &locPointer{txOffset, buf.GetBytesConsumed() - txOffset}
 		txOffsets = append(txOffsets, idxInfo)
 	}
 	return data, txOffsets, nil
@@ -202,17 +210,46 @@ func extractMetadata(buf *ledgerutil.Buffer) (*common.BlockMetadata, error) {
 }
 
 func extractTxID(txEnvelopBytes []byte) (string, error) {
-	txEnvelope, err := utils.GetEnvelopeFromBlock(txEnvelopBytes)
+	idxInfo, err := extractTxIndexInfo(txEnvelopBytes)
 	if err != nil {
 		return "", err
 	}
+	return idxInfo.txID, nil
+}
+
+// extractTxIndexInfo returns the attributes of the transaction the block index is keyed by, without its location.
+// The blocks may contain invalid transactions, so the submitter and the chaincode of a transaction whose headers
+// are not well formed are left blank rather than failing the block
+func extractTxIndexInfo(txEnvelopBytes []byte) (*txindexInfo, error) {
+	txEnvelope, err := utils.GetEnvelopeFromBlock(txEnvelopBytes)
+	if err != nil {
+		return nil, err
+	}
 	txPayload, err := utils.GetPayload(txEnvelope)
 	if err != nil {
-		return "", nil
+		return &txindexInfo{}, nil
 	}
 	chdr, err := utils.UnmarshalChannelHeader(txPayload.Header.ChannelHeader)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+	idxInfo := &txindexInfo{txID: chdr.TxId}
+
+	shdr, err := utils.GetSignatureHeader(txPayload.Header.SignatureHeader)
+	if err != nil {
+		return idxInfo, nil
+	}
+	idxInfo.creator = shdr.Creator
+	sid := &msp.SerializedIdentity{}
+	if err = proto.Unmarshal(shdr.Creator, sid); err == nil {
+		idxInfo.mspID = sid.Mspid
+	}
+
+	if common.HeaderType(chdr.Type) == common.HeaderType_ENDORSER_TRANSACTION {
+		hdrExt := &peer.ChaincodeHeaderExtension{}
+		if err = proto.Unmarshal(chdr.Extension, hdrExt); err == nil && hdrExt.ChaincodeId != nil {
+			idxInfo.chaincodeName = hdrExt.ChaincodeId.Name
+		}
 	}
-	return chdr.TxId, nil
+	return idxInfo, nil
 }
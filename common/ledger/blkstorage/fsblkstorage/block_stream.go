@@ -56,6 +56,9 @@ type blockPlacementInfo struct {
 	fileNum          int
 	blockStartOffset int64
 	blockBytesOffset int64
+	// compressed is true if the block is stored compressed, in which case the returned
+	// block bytes are decompressed and blockBytesOffset points at the compressed bytes
+	compressed bool
 }
 
 ///////////////////////////////////
@@ -134,15 +137,20 @@ func (s *blockfileStream) nextBlockBytesAndPlacementInfo() ([]byte, *blockPlacem
 	if _, err = s.reader.Discard(n); err != nil {
 		return nil, nil, err
 	}
-	blockBytes := make([]byte, length)
-	if _, err = io.ReadAtLeast(s.reader, blockBytes, int(length)); err != nil {
+	storedBytes := make([]byte, length)
+	if _, err = io.ReadAtLeast(s.reader, storedBytes, int(length)); err != nil {
 		logger.Debugf("Error while trying to read [%d] bytes from fileNum [%d]: %s", length, s.fileNum, err)
 		return nil, nil, err
 	}
+	blockBytes, compressed, err := decodeBlockBytes(storedBytes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Error while decoding block at offset [%d] of fileNum [%d]: %s", s.currentOffset, s.fileNum, err)
+	}
 	blockPlacementInfo := &blockPlacementInfo{
 		fileNum:          s.fileNum,
 		blockStartOffset: s.currentOffset,
-		blockBytesOffset: s.currentOffset + int64(n)}
+		blockBytesOffset: s.currentOffset + int64(n),
+		compressed:       compressed}
 	s.currentOffset += int64(n) + int64(length)
 	logger.Debugf("Returning blockbytes - length=[%d], placementInfo={%s}", len(blockBytes), blockPlacementInfo)
 	return blockBytes, blockPlacementInfo, nil
@@ -204,6 +212,6 @@ func (s *blockStream) close() error {
 }
 
 func (i *blockPlacementInfo) String() string {
-	return fmt.Sprintf("fileNum=[%d], startOffset=[%d], bytesOffset=[%d]",
-		i.fileNum, i.blockStartOffset, i.blockBytesOffset)
+	return fmt.Sprintf("fileNum=[%d], startOffset=[%d], bytesOffset=[%d], compressed=[%t]",
+		i.fileNum, i.blockStartOffset, i.blockBytesOffset, i.compressed)
 }
@@ -256,6 +256,11 @@ func (mgr *blockfileMgr) addBlock(block *common.Block) error {
 	if err != nil {
 		return fmt.Errorf("Error while serializing block: %s", err)
 	}
+	//Compress the block bytes if configured, the transaction offsets then remain relative to the decompressed bytes
+	compressed := mgr.conf.compression != compressionNone
+	if blockBytes, err = encodeBlockBytes(blockBytes, mgr.conf.compression); err != nil {
+		return fmt.Errorf("Error while compressing block: %s", err)
+	}
 	blockBytesLen := len(blockBytes)
 	blockBytesEncodedLen := proto.EncodeVarint(uint64(blockBytesLen))
 	totalBytesToAppend := blockBytesLen + len(blockBytesEncodedLen)
@@ -300,13 +305,15 @@ func (mgr *blockfileMgr) addBlock(block *common.Block) error {
 	blockFLP := &fileLocPointer{fileSuffixNum: newCPInfo.latestFileChunkSuffixNum}
 	blockFLP.offset = currentOffset
 	// shift the txoffset because we prepend length of bytes before block bytes
-	for _, txOffset := range txOffsets {
-		txOffset.loc.offset += len(blockBytesEncodedLen)
+	if !compressed {
+		for _, txOffset := range txOffsets {
+			txOffset.loc.offset += len(blockBytesEncodedLen)
+		}
 	}
 	//save the index in the database
 	mgr.index.indexBlock(&blockIdxInfo{
 		blockNum: block.Header.Number, blockHash: blockHash,
-		flp: blockFLP, txOffsets: txOffsets, metadata: block.Metadata})
+		flp: blockFLP, txOffsets: txOffsets, metadata: block.Metadata, compressed: compressed})
 
 	//update the checkpoint info (for storage) and the blockchain info (for APIs) in the manager
 	mgr.updateCheckpoint(newCPInfo)
@@ -378,10 +385,13 @@ func (mgr *blockfileMgr) syncIndex() error {
 		}
 
 		//The blockStartOffset will get applied to the txOffsets prior to indexing within indexBlock(),
-		//therefore just shift by the difference between blockBytesOffset and blockStartOffset
-		numBytesToShift := int(blockPlacementInfo.blockBytesOffset - blockPlacementInfo.blockStartOffset)
-		for _, offset := range info.txOffsets {
-			offset.loc.offset += numBytesToShift
+		//therefore just shift by the difference between blockBytesOffset and blockStartOffset.
+		//The txOffsets of a compressed block remain relative to the decompressed block bytes
+		if !blockPlacementInfo.compressed {
+			numBytesToShift := int(blockPlacementInfo.blockBytesOffset - blockPlacementInfo.blockStartOffset)
+			for _, offset := range info.txOffsets {
+				offset.loc.offset += numBytesToShift
+			}
 		}
 
 		//Update the blockIndexInfo with what was actually stored in file system
@@ -392,6 +402,7 @@ func (mgr *blockfileMgr) syncIndex() error {
 			locPointer: locPointer{offset: int(blockPlacementInfo.blockStartOffset)}}
 		blockIdxInfo.txOffsets = info.txOffsets
 		blockIdxInfo.metadata = info.metadata
+		blockIdxInfo.compressed = blockPlacementInfo.compressed
 
 		logger.Debugf("syncIndex() indexing block [%d]", blockIdxInfo.blockNum)
 		if err = mgr.index.indexBlock(blockIdxInfo); err != nil {
@@ -464,6 +475,51 @@ func (mgr *blockfileMgr) retrieveTxValidationCodeByTxID(txID string) (peer.TxVal
 	return mgr.index.getTxValidationCodeByTxID(txID)
 }
 
+func (mgr *blockfileMgr) retrieveTxValidationCodesByTxIDs(txIDs []string) (map[string]peer.TxValidationCode, error) {
+	logger.Debugf("retrieveTxValidationCodesByTxIDs() - %d txIDs", len(txIDs))
+	return mgr.index.getTxValidationCodesByTxIDs(txIDs)
+}
+
+func (mgr *blockfileMgr) retrieveTxsByCreator(creator []byte, start blkstorage.TxPosition, limit int) ([]*peer.ProcessedTransaction, *blkstorage.TxPosition, error) {
+	logger.Debugf("retrieveTxsByCreator() - start = [%+v], limit = [%d]", start, limit)
+	return mgr.retrievePageOfTxs(limit, func(n int) ([]*indexedTxLoc, error) {
+		return mgr.index.getTxLocsByCreator(creator, start, n)
+	})
+}
+
+func (mgr *blockfileMgr) retrieveTxsByMSPIDAndChaincode(mspID string, chaincodeName string, start blkstorage.TxPosition, limit int) ([]*peer.ProcessedTransaction, *blkstorage.TxPosition, error) {
+	logger.Debugf("retrieveTxsByMSPIDAndChaincode() - mspID = [%s], chaincodeName = [%s], start = [%+v], limit = [%d]", mspID, chaincodeName, start, limit)
+	return mgr.retrievePageOfTxs(limit, func(n int) ([]*indexedTxLoc, error) {
+		return mgr.index.getTxLocsByMSPIDAndChaincode(mspID, chaincodeName, start, n)
+	})
+}
+
+// retrievePageOfTxs returns up to limit transactions located by the given lookup of an index,
+// along with the position of the next one. It looks up one more entry to find that position
+func (mgr *blockfileMgr) retrievePageOfTxs(limit int, lookup func(n int) ([]*indexedTxLoc, error)) ([]*peer.ProcessedTransaction, *blkstorage.TxPosition, error) {
+	if limit <= 0 {
+		return nil, nil, fmt.Errorf("invalid limit %d", limit)
+	}
+	locs, err := lookup(limit + 1)
+	if err != nil {
+		return nil, nil, err
+	}
+	var next *blkstorage.TxPosition
+	if len(locs) > limit {
+		next = &locs[limit].position
+		locs = locs[:limit]
+	}
+	txs := make([]*peer.ProcessedTransaction, 0, len(locs))
+	for _, loc := range locs {
+		env, err := mgr.fetchTransactionEnvelope(loc.flp)
+		if err != nil {
+			return nil, nil, err
+		}
+		txs = append(txs, &peer.ProcessedTransaction{TransactionEnvelope: env, ValidationCode: int32(loc.validationCode)})
+	}
+	return txs, next, nil
+}
+
 func (mgr *blockfileMgr) retrieveBlockHeaderByNumber(blockNum uint64) (*common.BlockHeader, error) {
 	logger.Debugf("retrieveBlockHeaderByNumber() - blockNum = [%d]", blockNum)
 	loc, err := mgr.index.getBlockLocByBlockNum(blockNum)
@@ -519,7 +575,12 @@ func (mgr *blockfileMgr) fetchTransactionEnvelope(lp *fileLocPointer) (*common.E
 	logger.Debugf("Entering fetchTransactionEnvelope() %v\n", lp)
 	var err error
 	var txEnvelopeBytes []byte
-	if txEnvelopeBytes, err = mgr.fetchRawBytes(lp); err != nil {
+	if lp.compressedBlockTxOffset != 0 {
+		txEnvelopeBytes, err = mgr.fetchTxBytesFromCompressedBlock(lp)
+	} else {
+		txEnvelopeBytes, err = mgr.fetchRawBytes(lp)
+	}
+	if err != nil {
 		return nil, err
 	}
 	_, n := proto.DecodeVarint(txEnvelopeBytes)
@@ -527,16 +588,40 @@ func (mgr *blockfileMgr) fetchTransactionEnvelope(lp *fileLocPointer) (*common.E
 }
 
 func (mgr *blockfileMgr) fetchBlockBytes(lp *fileLocPointer) ([]byte, error) {
+	b, _, err := mgr.fetchBlockBytesAndPlacementInfo(lp)
+	return b, err
+}
+
+// fetchBlockBytesAndPlacementInfo returns the (decompressed) bytes of the block at
+// the given location, along with the information on how the block is stored
+func (mgr *blockfileMgr) fetchBlockBytesAndPlacementInfo(lp *fileLocPointer) ([]byte, *blockPlacementInfo, error) {
 	stream, err := newBlockfileStream(mgr.rootDir, lp.fileSuffixNum, int64(lp.offset))
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	defer stream.close()
-	b, err := stream.nextBlockBytes()
+	b, placementInfo, err := stream.nextBlockBytesAndPlacementInfo()
+	if err != nil {
+		return nil, nil, err
+	}
+	if b == nil {
+		return nil, nil, fmt.Errorf("No block found at location [%s]", lp)
+	}
+	return b, placementInfo, nil
+}
+
+// fetchTxBytesFromCompressedBlock decompresses the block the given transaction location
+// points at, and returns the bytes of the transaction, including their length prefix
+func (mgr *blockfileMgr) fetchTxBytesFromCompressedBlock(lp *fileLocPointer) ([]byte, error) {
+	blockBytes, err := mgr.fetchBlockBytes(lp)
 	if err != nil {
 		return nil, err
 	}
-	return b, nil
+	end := lp.compressedBlockTxOffset + lp.bytesLength
+	if end > len(blockBytes) {
+		return nil, fmt.Errorf("Transaction location [%s] is beyond the [%d] bytes of the decompressed block", lp, len(blockBytes))
+	}
+	return blockBytes[lp.compressedBlockTxOffset:end], nil
 }
 
 func (mgr *blockfileMgr) fetchRawBytes(lp *fileLocPointer) ([]byte, error) {
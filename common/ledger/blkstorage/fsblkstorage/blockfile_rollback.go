@@ -0,0 +1,125 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package fsblkstorage
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/hyperledger/fabric/common/ledger/blkstorage"
+	"github.com/hyperledger/fabric/common/ledger/util/leveldbhelper"
+)
+
+// rollback removes the blocks at and above the given height from the block files under the given
+// root directory. The checkpoint info and the block index kept in the index store are replaced, in a
+// single batch, by a checkpoint info that ends with the remaining blocks, before the block files are
+// truncated; so a rollback interrupted by a crash can be run again, and the index is rebuilt from the
+// remaining block files when the block files are opened next
+func rollback(rootDir string, height uint64, indexStore *leveldbhelper.DBHandle) error {
+	mgr := &blockfileMgr{rootDir: rootDir, db: indexStore}
+	cpInfo, err := mgr.loadCurrentInfo()
+	if err != nil {
+		return err
+	}
+	if cpInfo == nil {
+		return fmt.Errorf("No block store found under [%s]", rootDir)
+	}
+	syncCPInfoFromFS(rootDir, cpInfo)
+	currentHeight := uint64(0)
+	if !cpInfo.isChainEmpty {
+		currentHeight = cpInfo.lastBlockNumber + 1
+	}
+	if height > currentHeight {
+		return fmt.Errorf("Cannot roll back the block store to height [%d], which is above its current height [%d]",
+			height, currentHeight)
+	}
+	if height == currentHeight {
+		logger.Infof("Block store under [%s] is already at height [%d]", rootDir, height)
+		return nil
+	}
+
+	index := newBlockIndex(&blkstorage.IndexConfig{
+		AttrsToIndex: []blkstorage.IndexableAttr{blkstorage.IndexableAttrBlockNum}}, indexStore)
+	flp, err := locateBlock(rootDir, cpInfo.latestFileChunkSuffixNum, index, height)
+	if err != nil {
+		return err
+	}
+	logger.Infof("Rolling back block store under [%s] from height [%d] to height [%d], truncating block file [%d] at offset [%d]",
+		rootDir, currentHeight, height, flp.fileSuffixNum, flp.offset)
+
+	newCPInfo := &checkpointInfo{
+		latestFileChunkSuffixNum: flp.fileSuffixNum,
+		latestFileChunksize:      flp.offset,
+		isChainEmpty:             height == 0,
+	}
+	if height > 0 {
+		newCPInfo.lastBlockNumber = height - 1
+	}
+	cpInfoBytes, err := newCPInfo.marshal()
+	if err != nil {
+		return err
+	}
+	batch := leveldbhelper.NewUpdateBatch()
+	itr := indexStore.GetIterator(nil, nil)
+	for itr.Next() {
+		batch.Delete(itr.Key())
+	}
+	itr.Release()
+	if err = itr.Error(); err != nil {
+		return err
+	}
+	batch.Put(blkMgrInfoKey, cpInfoBytes)
+	if err = indexStore.WriteBatch(batch, true); err != nil {
+		return err
+	}
+
+	// remove the block files following the one the first removed block is stored in, the last one first
+	for fileNum := cpInfo.latestFileChunkSuffixNum; fileNum > flp.fileSuffixNum; fileNum-- {
+		if err = os.Remove(deriveBlockfilePath(rootDir, fileNum)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	writer, err := newBlockfileWriter(deriveBlockfilePath(rootDir, flp.fileSuffixNum))
+	if err != nil {
+		return err
+	}
+	defer writer.close()
+	return writer.truncateFile(flp.offset)
+}
+
+// locateBlock returns the location of the block with the given number. The block is looked up in the
+// index, or else the block files are scanned from the last indexed block, or from the first block file
+// if there is no usable index, up to the block file with the given suffix number
+func locateBlock(rootDir string, endFileNum int, index *blockIndex, blockNum uint64) (*fileLocPointer, error) {
+	if flp, err := index.getBlockLocByBlockNum(blockNum); err == nil {
+		return flp, nil
+	}
+	startFileNum, startOffset, startBlockNum := 0, int64(0), uint64(0)
+	if lastBlockIndexed, err := index.getLastBlockIndexed(); err == nil && lastBlockIndexed < blockNum {
+		if flp, err := index.getBlockLocByBlockNum(lastBlockIndexed); err == nil {
+			startFileNum, startOffset, startBlockNum = flp.fileSuffixNum, int64(flp.offset), lastBlockIndexed
+		}
+	}
+	stream, err := newBlockStream(rootDir, startFileNum, startOffset, endFileNum)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.close()
+	for num := startBlockNum; ; num++ {
+		blockBytes, placementInfo, err := stream.nextBlockBytesAndPlacementInfo()
+		if err != nil {
+			return nil, err
+		}
+		if blockBytes == nil {
+			return nil, fmt.Errorf("Block [%d] not found in the block files under [%s]", blockNum, rootDir)
+		}
+		if num == blockNum {
+			return &fileLocPointer{fileSuffixNum: placementInfo.fileNum,
+				locPointer: locPointer{offset: int(placementInfo.blockStartOffset)}}, nil
+		}
+	}
+}
@@ -18,8 +18,10 @@ package fsblkstorage
 
 import (
 	"bytes"
+	"crypto/sha256"
 	"errors"
 	"fmt"
+	"sort"
 
 	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric/common/ledger/blkstorage"
@@ -37,6 +39,8 @@ const (
 	blockNumTranNumIdxKeyPrefix    = 'a'
 	blockTxIDIdxKeyPrefix          = 'b'
 	txValidationResultIdxKeyPrefix = 'v'
+	txCreatorIdxKeyPrefix          = 'c'
+	txMSPIDChaincodeIdxKeyPrefix   = 'm'
 	indexCheckpointKeyStr          = "indexCheckpointKey"
 )
 
@@ -52,6 +56,16 @@ type index interface {
 	getTXLocByBlockNumTranNum(blockNum uint64, tranNum uint64) (*fileLocPointer, error)
 	getBlockLocByTxID(txID string) (*fileLocPointer, error)
 	getTxValidationCodeByTxID(txID string) (peer.TxValidationCode, error)
+	getTxValidationCodesByTxIDs(txIDs []string) (map[string]peer.TxValidationCode, error)
+	getTxLocsByCreator(creator []byte, start blkstorage.TxPosition, limit int) ([]*indexedTxLoc, error)
+	getTxLocsByMSPIDAndChaincode(mspID string, chaincodeName string, start blkstorage.TxPosition, limit int) ([]*indexedTxLoc, error)
+}
+
+// indexedTxLoc is an entry of the indexes of the transactions by creator and by MSP ID and chaincode
+type indexedTxLoc struct {
+	position       blkstorage.TxPosition
+	validationCode peer.TxValidationCode
+	flp            *fileLocPointer
 }
 
 type blockIdxInfo struct {
@@ -60,6 +74,9 @@ type blockIdxInfo struct {
 	flp       *fileLocPointer
 	txOffsets []*txindexInfo
 	metadata  *common.BlockMetadata
+	// compressed is true if the block is stored compressed, in which case
+	// the txOffsets are relative to the decompressed block bytes
+	compressed bool
 }
 
 type blockIndex struct {
@@ -118,7 +135,7 @@ func (index *blockIndex) indexBlock(blockIdxInfo *blockIdxInfo) error {
 	//Index3 Used to find a transaction by it's transaction id
 	if _, ok := index.indexItemsMap[blkstorage.IndexableAttrTxID]; ok {
 		for _, txoffset := range txOffsets {
-			txFlp := newFileLocationPointer(flp.fileSuffixNum, flp.offset, txoffset.loc)
+			txFlp := newTxLocationPointer(flp, txoffset.loc, blockIdxInfo.compressed)
 			logger.Debugf("Adding txLoc [%s] for tx ID: [%s] to index", txFlp, txoffset.txID)
 			txFlpBytes, marshalErr := txFlp.marshal()
 			if marshalErr != nil {
@@ -131,7 +148,7 @@ func (index *blockIndex) indexBlock(blockIdxInfo *blockIdxInfo) error {
 	//Index4 - Store BlockNumTranNum will be used to query history data
 	if _, ok := index.indexItemsMap[blkstorage.IndexableAttrBlockNumTranNum]; ok {
 		for txIterator, txoffset := range txOffsets {
-			txFlp := newFileLocationPointer(flp.fileSuffixNum, flp.offset, txoffset.loc)
+			txFlp := newTxLocationPointer(flp, txoffset.loc, blockIdxInfo.compressed)
 			logger.Debugf("Adding txLoc [%s] for tx number:[%d] ID: [%s] to blockNumTranNum index", txFlp, txIterator, txoffset.txID)
 			txFlpBytes, marshalErr := txFlp.marshal()
 			if marshalErr != nil {
@@ -155,6 +172,39 @@ func (index *blockIndex) indexBlock(blockIdxInfo *blockIdxInfo) error {
 		}
 	}
 
+	// Index7 - Store the location and validation result of a transaction by its creator, its block number and tran number
+	if _, ok := index.indexItemsMap[blkstorage.IndexableAttrTxCreator]; ok {
+		for idx, txoffset := range txOffsets {
+			if txoffset.creator == nil {
+				continue
+			}
+			txFlp := newTxLocationPointer(flp, txoffset.loc, blockIdxInfo.compressed)
+			txFlpBytes, marshalErr := txFlp.marshal()
+			if marshalErr != nil {
+				return marshalErr
+			}
+			batch.Put(constructTxCreatorKey(txoffset.creator, blockIdxInfo.blockNum, uint64(idx)),
+				append([]byte{byte(txsfltr.Flag(idx))}, txFlpBytes...))
+		}
+	}
+
+	// Index8 - Store the location and validation result of a transaction by the MSP ID of its creator, the chaincode
+	// it invokes, its block number and tran number
+	if _, ok := index.indexItemsMap[blkstorage.IndexableAttrTxMSPIDChaincode]; ok {
+		for idx, txoffset := range txOffsets {
+			if txoffset.mspID == "" || txoffset.chaincodeName == "" {
+				continue
+			}
+			txFlp := newTxLocationPointer(flp, txoffset.loc, blockIdxInfo.compressed)
+			txFlpBytes, marshalErr := txFlp.marshal()
+			if marshalErr != nil {
+				return marshalErr
+			}
+			batch.Put(constructTxMSPIDChaincodeKey(txoffset.mspID, txoffset.chaincodeName, blockIdxInfo.blockNum, uint64(idx)),
+				append([]byte{byte(txsfltr.Flag(idx))}, txFlpBytes...))
+		}
+	}
+
 	batch.Put(indexCheckpointKey, encodeBlockNum(blockIdxInfo.blockNum))
 	if err := index.db.WriteBatch(batch, false); err != nil {
 		return err
@@ -262,6 +312,85 @@ func (index *blockIndex) getTxValidationCodeByTxID(txID string) (peer.TxValidati
 	return result, nil
 }
 
+// getTxValidationCodesByTxIDs returns the validation codes of the given transactions.
+// The transactions that are not found in the index are left out of the returned map
+func (index *blockIndex) getTxValidationCodesByTxIDs(txIDs []string) (map[string]peer.TxValidationCode, error) {
+	if _, ok := index.indexItemsMap[blkstorage.IndexableAttrTxValidationCode]; !ok {
+		return nil, blkstorage.ErrAttrNotIndexed
+	}
+
+	// Look up the keys in order, so that neighbouring keys are read from the same blocks of the db
+	sortedTxIDs := make([]string, len(txIDs))
+	copy(sortedTxIDs, txIDs)
+	sort.Strings(sortedTxIDs)
+
+	results := make(map[string]peer.TxValidationCode, len(txIDs))
+	for _, txID := range sortedTxIDs {
+		if _, ok := results[txID]; ok {
+			continue
+		}
+		raw, err := index.db.Get(constructTxValidationCodeIDKey(txID))
+		if err != nil {
+			return nil, err
+		} else if raw == nil {
+			continue
+		} else if len(raw) != 1 {
+			return nil, errors.New("Invalid value in indexItems")
+		}
+		results[txID] = peer.TxValidationCode(int32(raw[0]))
+	}
+	return results, nil
+}
+
+// getTxLocsByCreator returns, in the order of the chain, up to limit entries of the creator index
+// for the transactions of the given creator at or after the given position
+func (index *blockIndex) getTxLocsByCreator(creator []byte, start blkstorage.TxPosition, limit int) ([]*indexedTxLoc, error) {
+	if _, ok := index.indexItemsMap[blkstorage.IndexableAttrTxCreator]; !ok {
+		return nil, blkstorage.ErrAttrNotIndexed
+	}
+	return index.getIndexedTxLocs(constructTxCreatorKeyPrefix(creator), start, limit)
+}
+
+// getTxLocsByMSPIDAndChaincode returns, in the order of the chain, up to limit entries of the MSP ID and chaincode
+// index for the transactions submitted by the given org to the given chaincode at or after the given position
+func (index *blockIndex) getTxLocsByMSPIDAndChaincode(mspID string, chaincodeName string, start blkstorage.TxPosition, limit int) ([]*indexedTxLoc, error) {
+	if _, ok := index.indexItemsMap[blkstorage.IndexableAttrTxMSPIDChaincode]; !ok {
+		return nil, blkstorage.ErrAttrNotIndexed
+	}
+	return index.getIndexedTxLocs(constructTxMSPIDChaincodeKeyPrefix(mspID, chaincodeName), start, limit)
+}
+
+// getIndexedTxLocs returns up to limit entries of an index of transactions, whose keys are made of the given
+// prefix followed by the block number and the tran number of the transaction, starting at the given position
+func (index *blockIndex) getIndexedTxLocs(prefix []byte, start blkstorage.TxPosition, limit int) ([]*indexedTxLoc, error) {
+	startKey := constructIndexedTxKey(prefix, start.BlockNum, start.TranNum)
+	// the encoded block numbers start with their length, which never reaches 0xff
+	itr := index.db.GetIterator(startKey, append(prefix, 0xff))
+	defer itr.Release()
+
+	var locs []*indexedTxLoc
+	for len(locs) < limit && itr.Next() {
+		blockNum, tranNum := decodeIndexedTxKey(itr.Key(), len(prefix))
+		value := itr.Value()
+		if len(value) < 2 {
+			return nil, errors.New("Invalid value in indexItems")
+		}
+		txFLP := &fileLocPointer{}
+		if err := txFLP.unmarshal(value[1:]); err != nil {
+			return nil, err
+		}
+		locs = append(locs, &indexedTxLoc{
+			position:       blkstorage.TxPosition{BlockNum: blockNum, TranNum: tranNum},
+			validationCode: peer.TxValidationCode(int32(value[0])),
+			flp:            txFLP,
+		})
+	}
+	if err := itr.Error(); err != nil {
+		return nil, err
+	}
+	return locs, nil
+}
+
 func constructBlockNumKey(blockNum uint64) []byte {
 	blkNumBytes := util.EncodeOrderPreservingVarUint64(blockNum)
 	return append([]byte{blockNumIdxKeyPrefix}, blkNumBytes...)
@@ -290,6 +419,42 @@ func constructBlockNumTranNumKey(blockNum uint64, txNum uint64) []byte {
 	return append([]byte{blockNumTranNumIdxKeyPrefix}, key...)
 }
 
+// constructTxCreatorKeyPrefix returns the prefix of the keys of the transactions of the creator,
+// which are keyed by the hash of the creator so that the prefixes of two creators never overlap
+func constructTxCreatorKeyPrefix(creator []byte) []byte {
+	creatorHash := sha256.Sum256(creator)
+	return append([]byte{txCreatorIdxKeyPrefix}, creatorHash[:]...)
+}
+
+func constructTxCreatorKey(creator []byte, blockNum uint64, txNum uint64) []byte {
+	return constructIndexedTxKey(constructTxCreatorKeyPrefix(creator), blockNum, txNum)
+}
+
+// constructTxMSPIDChaincodeKeyPrefix returns the prefix of the keys of the transactions submitted
+// by the org to the chaincode, the names are terminated so that the prefixes never overlap
+func constructTxMSPIDChaincodeKeyPrefix(mspID string, chaincodeName string) []byte {
+	prefix := append([]byte{txMSPIDChaincodeIdxKeyPrefix}, []byte(mspID)...)
+	prefix = append(prefix, 0x00)
+	prefix = append(prefix, []byte(chaincodeName)...)
+	return append(prefix, 0x00)
+}
+
+func constructTxMSPIDChaincodeKey(mspID string, chaincodeName string, blockNum uint64, txNum uint64) []byte {
+	return constructIndexedTxKey(constructTxMSPIDChaincodeKeyPrefix(mspID, chaincodeName), blockNum, txNum)
+}
+
+func constructIndexedTxKey(prefix []byte, blockNum uint64, txNum uint64) []byte {
+	key := append([]byte{}, prefix...)
+	key = append(key, util.EncodeOrderPreservingVarUint64(blockNum)...)
+	return append(key, util.EncodeOrderPreservingVarUint64(txNum)...)
+}
+
+func decodeIndexedTxKey(key []byte, prefixLen int) (uint64, uint64) {
+	blockNum, n := util.DecodeOrderPreservingVarUint64(key[prefixLen:])
+	tranNum, _ := util.DecodeOrderPreservingVarUint64(key[prefixLen+n:])
+	return blockNum, tranNum
+}
+
 func encodeBlockNum(blockNum uint64) []byte {
 	return proto.EncodeVarint(blockNum)
 }
@@ -313,6 +478,10 @@ func (lp *locPointer) String() string {
 type fileLocPointer struct {
 	fileSuffixNum int
 	locPointer
+	// compressedBlockTxOffset is set only for a transaction of a compressed block. In that case,
+	// offset points at the block, and this is the offset of the transaction in the decompressed
+	// block bytes, which is never zero as the block header precedes the transactions
+	compressedBlockTxOffset int
 }
 
 func newFileLocationPointer(fileSuffixNum int, beginningOffset int, relativeLP *locPointer) *fileLocPointer {
@@ -322,6 +491,19 @@ func newFileLocationPointer(fileSuffixNum int, beginningOffset int, relativeLP *
 	return flp
 }
 
+// newTxLocationPointer returns the location of a transaction given the location of its block
+// and its location relative to the block
+func newTxLocationPointer(blockFLP *fileLocPointer, txLP *locPointer, compressed bool) *fileLocPointer {
+	if !compressed {
+		return newFileLocationPointer(blockFLP.fileSuffixNum, blockFLP.offset, txLP)
+	}
+	return &fileLocPointer{
+		fileSuffixNum:           blockFLP.fileSuffixNum,
+		locPointer:              locPointer{offset: blockFLP.offset, bytesLength: txLP.bytesLength},
+		compressedBlockTxOffset: txLP.offset,
+	}
+}
+
 func (flp *fileLocPointer) marshal() ([]byte, error) {
 	buffer := proto.NewBuffer([]byte{})
 	e := buffer.EncodeVarint(uint64(flp.fileSuffixNum))
@@ -336,6 +518,12 @@ func (flp *fileLocPointer) marshal() ([]byte, error) {
 	if e != nil {
 		return nil, e
 	}
+	if flp.compressedBlockTxOffset != 0 {
+		e = buffer.EncodeVarint(uint64(flp.compressedBlockTxOffset))
+		if e != nil {
+			return nil, e
+		}
+	}
 	return buffer.Bytes(), nil
 }
 
@@ -357,10 +545,18 @@ func (flp *fileLocPointer) unmarshal(b []byte) error {
 		return e
 	}
 	flp.bytesLength = int(i)
+	//only the location of a transaction of a compressed block has a fourth field
+	if i, e = buffer.DecodeVarint(); e == nil {
+		flp.compressedBlockTxOffset = int(i)
+	}
 	return nil
 }
 
 func (flp *fileLocPointer) String() string {
+	if flp.compressedBlockTxOffset != 0 {
+		return fmt.Sprintf("fileSuffixNum=%d, %s, compressedBlockTxOffset=%d",
+			flp.fileSuffixNum, flp.locPointer.String(), flp.compressedBlockTxOffset)
+	}
 	return fmt.Sprintf("fileSuffixNum=%d, %s", flp.fileSuffixNum, flp.locPointer.String())
 }
 
@@ -24,6 +24,7 @@ import (
 	"github.com/hyperledger/fabric/common/ledger/testutil"
 	"github.com/hyperledger/fabric/core/ledger/util"
 	"github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/msp"
 	"github.com/hyperledger/fabric/protos/peer"
 	putil "github.com/hyperledger/fabric/protos/utils"
 )
@@ -58,6 +59,18 @@ func (i *noopIndex) getTxValidationCodeByTxID(txID string) (peer.TxValidationCod
 	return peer.TxValidationCode(-1), nil
 }
 
+func (i *noopIndex) getTxValidationCodesByTxIDs(txIDs []string) (map[string]peer.TxValidationCode, error) {
+	return nil, nil
+}
+
+func (i *noopIndex) getTxLocsByCreator(creator []byte, start blkstorage.TxPosition, limit int) ([]*indexedTxLoc, error) {
+	return nil, nil
+}
+
+func (i *noopIndex) getTxLocsByMSPIDAndChaincode(mspID string, chaincodeName string, start blkstorage.TxPosition, limit int) ([]*indexedTxLoc, error) {
+	return nil, nil
+}
+
 func TestBlockIndexSync(t *testing.T) {
 	testBlockIndexSync(t, 10, 5, false)
 	testBlockIndexSync(t, 10, 5, true)
@@ -128,6 +141,8 @@ func TestBlockIndexSelectiveIndexing(t *testing.T) {
 	testBlockIndexSelectiveIndexing(t, []blkstorage.IndexableAttr{blkstorage.IndexableAttrTxID, blkstorage.IndexableAttrBlockNumTranNum})
 	testBlockIndexSelectiveIndexing(t, []blkstorage.IndexableAttr{blkstorage.IndexableAttrBlockTxID})
 	testBlockIndexSelectiveIndexing(t, []blkstorage.IndexableAttr{blkstorage.IndexableAttrTxValidationCode})
+	testBlockIndexSelectiveIndexing(t, []blkstorage.IndexableAttr{blkstorage.IndexableAttrTxCreator})
+	testBlockIndexSelectiveIndexing(t, []blkstorage.IndexableAttr{blkstorage.IndexableAttrTxMSPIDChaincode})
 }
 
 func testBlockIndexSelectiveIndexing(t *testing.T, indexItems []blkstorage.IndexableAttr) {
@@ -202,12 +217,16 @@ func testBlockIndexSelectiveIndexing(t *testing.T, indexItems []blkstorage.Index
 			testutil.AssertSame(t, err, blkstorage.ErrAttrNotIndexed)
 		}
 
+		txids := []string{"non-existent-txid"}
+		expectedReasons := make(map[string]peer.TxValidationCode)
 		for _, block := range blocks {
 			flags := util.TxValidationFlags(block.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER])
 
 			for idx, d := range block.Data.Data {
 				txid, err = extractTxID(d)
 				testutil.AssertNoError(t, err, "")
+				txids = append(txids, txid)
+				expectedReasons[txid] = flags.Flag(idx)
 
 				reason, err := blockfileMgr.retrieveTxValidationCodeByTxID(txid)
 
@@ -222,5 +241,152 @@ func testBlockIndexSelectiveIndexing(t *testing.T, indexItems []blkstorage.Index
 				}
 			}
 		}
+
+		// The batched lookup leaves out the transactions that are not found
+		reasons, err := blockfileMgr.retrieveTxValidationCodesByTxIDs(append(txids, txids[1]))
+		if testutil.Contains(indexItems, blkstorage.IndexableAttrTxValidationCode) {
+			testutil.AssertNoError(t, err, "Error while retrieving tx validation codes by txIDs")
+			testutil.AssertEquals(t, reasons, expectedReasons)
+		} else {
+			testutil.AssertSame(t, err, blkstorage.ErrAttrNotIndexed)
+		}
+
+		// test 'retrieveTxsByCreator'
+		idxInfo, err := extractTxIndexInfo(blocks[1].Data.Data[0])
+		testutil.AssertNoError(t, err, "")
+		txs, next, err := blockfileMgr.retrieveTxsByCreator(idxInfo.creator, blkstorage.TxPosition{BlockNum: 1}, 100)
+		if testutil.Contains(indexItems, blkstorage.IndexableAttrTxCreator) {
+			testutil.AssertNoError(t, err, "Error while retrieving txs by creator")
+			testutil.AssertEquals(t, len(txs), len(blocks[1].Data.Data)+len(blocks[2].Data.Data))
+			testutil.AssertNil(t, next)
+		} else {
+			testutil.AssertSame(t, err, blkstorage.ErrAttrNotIndexed)
+		}
+
+		// test 'retrieveTxsByMSPIDAndChaincode'
+		_, _, err = blockfileMgr.retrieveTxsByMSPIDAndChaincode("Org1MSP", "foo", blkstorage.TxPosition{}, 100)
+		if testutil.Contains(indexItems, blkstorage.IndexableAttrTxMSPIDChaincode) {
+			testutil.AssertNoError(t, err, "Error while retrieving txs by MSP ID and chaincode")
+		} else {
+			testutil.AssertSame(t, err, blkstorage.ErrAttrNotIndexed)
+		}
 	})
 }
+
+var (
+	alice = putil.MarshalOrPanic(&msp.SerializedIdentity{Mspid: "Org1MSP", IdBytes: []byte("alice")})
+	bob   = putil.MarshalOrPanic(&msp.SerializedIdentity{Mspid: "Org2MSP", IdBytes: []byte("bob")})
+)
+
+// constructTxBySubmitter returns a transaction created by the given identity, invoking the given chaincode
+func constructTxBySubmitter(t *testing.T, txID string, creator []byte, chaincodeName string) []byte {
+	chdr := putil.MakeChannelHeader(common.HeaderType_ENDORSER_TRANSACTION, 0, "testchain", 0)
+	chdr.TxId = txID
+	chdr.Extension = putil.MarshalOrPanic(&peer.ChaincodeHeaderExtension{ChaincodeId: &peer.ChaincodeID{Name: chaincodeName}})
+	payload := &common.Payload{Header: putil.MakePayloadHeader(chdr, putil.MakeSignatureHeader(creator, []byte("nonce")))}
+	env := &common.Envelope{Payload: putil.MarshalOrPanic(payload)}
+	return putil.MarshalOrPanic(env)
+}
+
+// addTestBlocksBySubmitters adds a genesis block followed by 3 blocks, the transactions tx<blockNum>-0 and tx<blockNum>-1
+// of which are submitted to mycc by alice of Org1MSP and bob of Org2MSP, while tx<blockNum>-2 is an invalid transaction
+// submitted to othercc by alice
+func addTestBlocksBySubmitters(t *testing.T, blkfileMgrWrapper *testBlockfileMgrWrapper) {
+	blocks := testutil.ConstructTestBlocks(t, 1)
+	for blockNum := uint64(1); blockNum <= 3; blockNum++ {
+		block := common.NewBlock(blockNum, blocks[blockNum-1].Header.Hash())
+		block.Data.Data = [][]byte{
+			constructTxBySubmitter(t, fmt.Sprintf("tx%d-0", blockNum), alice, "mycc"),
+			constructTxBySubmitter(t, fmt.Sprintf("tx%d-1", blockNum), bob, "mycc"),
+			constructTxBySubmitter(t, fmt.Sprintf("tx%d-2", blockNum), alice, "othercc"),
+		}
+		block.Header.DataHash = block.Data.Hash()
+		putil.InitBlockMetadata(block)
+		flags := util.NewTxValidationFlags(3)
+		flags.SetFlag(2, peer.TxValidationCode_MVCC_READ_CONFLICT)
+		block.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER] = flags
+		blocks = append(blocks, block)
+	}
+	blkfileMgrWrapper.addBlocks(blocks)
+}
+
+// txIDsAndCodes returns the ids of the transactions along with their validation codes
+func txIDsAndCodes(t *testing.T, txs []*peer.ProcessedTransaction) []string {
+	var ids []string
+	for _, tx := range txs {
+		txID, err := extractTxID(putil.MarshalOrPanic(tx.TransactionEnvelope))
+		testutil.AssertNoError(t, err, "")
+		ids = append(ids, fmt.Sprintf("%s:%s", txID, peer.TxValidationCode(tx.ValidationCode)))
+	}
+	return ids
+}
+
+func TestRetrieveTxsByCreator(t *testing.T) {
+	env := newTestEnv(t, NewConf(testPath(), 0))
+	defer env.Cleanup()
+	blkfileMgrWrapper := newTestBlockfileWrapper(env, "testledger")
+	defer blkfileMgrWrapper.close()
+	addTestBlocksBySubmitters(t, blkfileMgrWrapper)
+	blockfileMgr := blkfileMgrWrapper.blockfileMgr
+
+	// the transactions of alice are paged in the order of the chain
+	txs, next, err := blockfileMgr.retrieveTxsByCreator(alice, blkstorage.TxPosition{}, 4)
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, txIDsAndCodes(t, txs), []string{"tx1-0:VALID", "tx1-2:MVCC_READ_CONFLICT", "tx2-0:VALID", "tx2-2:MVCC_READ_CONFLICT"})
+	testutil.AssertEquals(t, next, &blkstorage.TxPosition{BlockNum: 3, TranNum: 0})
+	txs, next, err = blockfileMgr.retrieveTxsByCreator(alice, *next, 4)
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, txIDsAndCodes(t, txs), []string{"tx3-0:VALID", "tx3-2:MVCC_READ_CONFLICT"})
+	testutil.AssertNil(t, next)
+
+	// the pages may start in the middle of a block
+	txs, next, err = blockfileMgr.retrieveTxsByCreator(bob, blkstorage.TxPosition{BlockNum: 2, TranNum: 1}, 1)
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, txIDsAndCodes(t, txs), []string{"tx2-1:VALID"})
+	testutil.AssertEquals(t, next, &blkstorage.TxPosition{BlockNum: 3, TranNum: 1})
+
+	// an unknown creator has no transactions
+	txs, next, err = blockfileMgr.retrieveTxsByCreator([]byte("carol"), blkstorage.TxPosition{}, 4)
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, len(txs), 0)
+	testutil.AssertNil(t, next)
+
+	_, _, err = blockfileMgr.retrieveTxsByCreator(alice, blkstorage.TxPosition{}, 0)
+	testutil.AssertError(t, err, "A page of no transaction should be rejected")
+}
+
+func TestRetrieveTxsByMSPIDAndChaincode(t *testing.T) {
+	env := newTestEnv(t, NewConf(testPath(), 0))
+	defer env.Cleanup()
+	blkfileMgrWrapper := newTestBlockfileWrapper(env, "testledger")
+	defer blkfileMgrWrapper.close()
+	addTestBlocksBySubmitters(t, blkfileMgrWrapper)
+	blockfileMgr := blkfileMgrWrapper.blockfileMgr
+
+	// the transactions of Org1MSP against mycc since block 2
+	txs, next, err := blockfileMgr.retrieveTxsByMSPIDAndChaincode("Org1MSP", "mycc", blkstorage.TxPosition{BlockNum: 2}, 10)
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, txIDsAndCodes(t, txs), []string{"tx2-0:VALID", "tx3-0:VALID"})
+	testutil.AssertNil(t, next)
+
+	txs, next, err = blockfileMgr.retrieveTxsByMSPIDAndChaincode("Org1MSP", "othercc", blkstorage.TxPosition{}, 2)
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, txIDsAndCodes(t, txs), []string{"tx1-2:MVCC_READ_CONFLICT", "tx2-2:MVCC_READ_CONFLICT"})
+	testutil.AssertEquals(t, next, &blkstorage.TxPosition{BlockNum: 3, TranNum: 2})
+
+	txs, next, err = blockfileMgr.retrieveTxsByMSPIDAndChaincode("Org2MSP", "mycc", blkstorage.TxPosition{}, 10)
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, txIDsAndCodes(t, txs), []string{"tx1-1:VALID", "tx2-1:VALID", "tx3-1:VALID"})
+	testutil.AssertNil(t, next)
+
+	// neither the org nor the chaincode match a prefix of the names
+	txs, _, err = blockfileMgr.retrieveTxsByMSPIDAndChaincode("Org2MSP", "othercc", blkstorage.TxPosition{}, 10)
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, len(txs), 0)
+	txs, _, err = blockfileMgr.retrieveTxsByMSPIDAndChaincode("Org1", "mycc", blkstorage.TxPosition{}, 10)
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, len(txs), 0)
+	txs, _, err = blockfileMgr.retrieveTxsByMSPIDAndChaincode("Org1MSP", "my", blkstorage.TxPosition{}, 10)
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, len(txs), 0)
+}
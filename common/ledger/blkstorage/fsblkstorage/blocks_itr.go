@@ -92,10 +92,15 @@ func (itr *blocksItr) Next() (ledger.QueryResult, error) {
 // Close releases any resources held by the iterator
 func (itr *blocksItr) Close() {
 	itr.closeMarkerLock.Lock()
-	defer itr.closeMarkerLock.Unlock()
 	itr.closeMarker = true
+	// the stream is only opened by the first call to Next
+	if itr.stream != nil {
+		itr.stream.close()
+	}
+	itr.closeMarkerLock.Unlock()
+	// wake up a Next waiting for a new block, the close marker lock must not be held
+	// while waiting for the cond lock, as waitForBlock acquires them the other way round
 	itr.mgr.cpInfoCond.L.Lock()
 	defer itr.mgr.cpInfoCond.L.Unlock()
 	itr.mgr.cpInfoCond.Broadcast()
-	itr.stream.close()
 }
@@ -74,6 +74,35 @@ func TestBlockItrClose(t *testing.T) {
 	testutil.AssertNil(t, bh)
 }
 
+func TestBlockItrCloseWaitingNext(t *testing.T) {
+	env := newTestEnv(t, NewConf(testPath(), 0))
+	defer env.Cleanup()
+	blkfileMgrWrapper := newTestBlockfileWrapper(env, "testLedger")
+	defer blkfileMgrWrapper.close()
+	blkfileMgr := blkfileMgrWrapper.blockfileMgr
+
+	blocks := testutil.ConstructTestBlocks(t, 2)
+	blkfileMgrWrapper.addBlocks(blocks)
+
+	// closing an iterator whose Next waits for a block not yet added unblocks it
+	itr, err := blkfileMgr.retrieveBlocks(2)
+	testutil.AssertNoError(t, err, "")
+	doneChan := make(chan bool)
+	go func() {
+		bh, err := itr.Next()
+		testutil.AssertNoError(t, err, "")
+		testutil.AssertNil(t, bh)
+		doneChan <- true
+	}()
+	time.Sleep(time.Millisecond * 10)
+	itr.Close()
+	select {
+	case <-doneChan:
+	case <-time.After(time.Second):
+		t.Fatalf("Next was not unblocked by Close")
+	}
+}
+
 func testIterateAndVerify(t *testing.T, itr *blocksItr, blocks []*common.Block, doneChan chan bool) {
 	blocksIterated := 0
 	for {
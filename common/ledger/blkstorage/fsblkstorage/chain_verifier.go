@@ -0,0 +1,130 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package fsblkstorage
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/ledger/blkstorage"
+	"github.com/hyperledger/fabric/protos/common"
+)
+
+// verifyChain re-reads the blocks in the range [startHeight, endHeight) from the block files,
+// recomputes their data hashes and the previous-hash links between them, and cross-checks
+// the block and transaction locations recorded in the index
+func (mgr *blockfileMgr) verifyChain(startHeight uint64, endHeight uint64) (*blkstorage.CorruptionReport, error) {
+	height := mgr.getBlockchainInfo().Height
+	if startHeight >= endHeight || endHeight > height {
+		return nil, fmt.Errorf("Invalid range [%d, %d) for a chain of height %d", startHeight, endHeight, height)
+	}
+	logger.Infof("Verifying blocks [%d, %d) of chain [%s]", startHeight, endHeight, mgr.rootDir)
+	report := &blkstorage.CorruptionReport{StartHeight: startHeight, EndHeight: endHeight}
+
+	var prevHeader *common.BlockHeader
+	if startHeight > 0 {
+		var err error
+		if prevHeader, err = mgr.retrieveBlockHeaderByNumber(startHeight - 1); err != nil {
+			report.Add(startHeight-1, blkstorage.CorruptionUnreadableBlock, "cannot read header: %s", err)
+		}
+	}
+	for blockNum := startHeight; blockNum < endHeight; blockNum++ {
+		header := mgr.verifyBlock(blockNum, prevHeader, report)
+		prevHeader = header
+	}
+	if report.IsCorrupted() {
+		logger.Warningf("Found %d inconsistencies in blocks [%d, %d) of chain [%s]",
+			len(report.Corruptions), startHeight, endHeight, mgr.rootDir)
+	}
+	return report, nil
+}
+
+// verifyBlock verifies a single block against its predecessor header and the index,
+// records the inconsistencies found in the report, and returns the header
+// of the block or nil if the block cannot be read
+func (mgr *blockfileMgr) verifyBlock(blockNum uint64, prevHeader *common.BlockHeader, report *blkstorage.CorruptionReport) *common.BlockHeader {
+	loc, err := mgr.index.getBlockLocByBlockNum(blockNum)
+	if err != nil {
+		report.Add(blockNum, blkstorage.CorruptionIndexInconsistent, "cannot find block location in index: %s", err)
+		return nil
+	}
+	blockBytes, placementInfo, err := mgr.fetchBlockBytesAndPlacementInfo(loc)
+	if err != nil {
+		report.Add(blockNum, blkstorage.CorruptionUnreadableBlock, "cannot read block at %s: %s", loc, err)
+		return nil
+	}
+	block, err := deserializeBlock(blockBytes)
+	if err != nil {
+		report.Add(blockNum, blkstorage.CorruptionUnreadableBlock, "cannot deserialize block at %s: %s", loc, err)
+		return nil
+	}
+	if block.Header.Number != blockNum {
+		report.Add(blockNum, blkstorage.CorruptionBlockNumMismatch,
+			"block at indexed location %s has number %d", loc, block.Header.Number)
+	}
+	if !block.Data.MatchesDataHash(block.Header.DataHash) {
+		report.Add(blockNum, blkstorage.CorruptionDataHashMismatch,
+			"header data hash is %x but the data hashes to %x with Merkle root %x",
+			block.Header.DataHash, block.Data.Hash(), block.Data.MerkleRoot())
+	}
+	if prevHeader != nil {
+		if prevHash := prevHeader.Hash(); !bytes.Equal(prevHash, block.Header.PreviousHash) {
+			report.Add(blockNum, blkstorage.CorruptionPrevHashMismatch,
+				"header previous hash is %x but the previous block hashes to %x", block.Header.PreviousHash, prevHash)
+		}
+	}
+	mgr.verifyBlockIndex(blockNum, block, loc, blockBytes, placementInfo.compressed, report)
+	return block.Header
+}
+
+// verifyBlockIndex checks that the index entries of the given block number
+// point to the location the block was actually read from
+func (mgr *blockfileMgr) verifyBlockIndex(blockNum uint64, block *common.Block, loc *fileLocPointer, blockBytes []byte, compressed bool, report *blkstorage.CorruptionReport) {
+	hashLoc, err := mgr.index.getBlockLocByHash(block.Header.Hash())
+	switch {
+	case err == blkstorage.ErrAttrNotIndexed:
+	case err != nil:
+		report.Add(blockNum, blkstorage.CorruptionIndexInconsistent, "cannot find block hash in index: %s", err)
+	case hashLoc.fileSuffixNum != loc.fileSuffixNum || hashLoc.offset != loc.offset:
+		report.Add(blockNum, blkstorage.CorruptionIndexInconsistent,
+			"block hash is indexed at %s while block number is indexed at %s", hashLoc, loc)
+	}
+
+	info, err := extractSerializedBlockInfo(blockBytes)
+	if err != nil {
+		report.Add(blockNum, blkstorage.CorruptionUnreadableBlock, "cannot extract transaction offsets: %s", err)
+		return
+	}
+	// Transaction offsets are indexed relative to the start of the
+	// length prefix that precedes the block bytes in the block file.
+	// The transactions of a compressed block are indexed at the block
+	// location, with their offset within the decompressed block bytes
+	prefixLen := len(proto.EncodeVarint(uint64(len(blockBytes))))
+	for tranNum, txOffset := range info.txOffsets {
+		txLoc, err := mgr.index.getTXLocByBlockNumTranNum(blockNum, uint64(tranNum))
+		if err == blkstorage.ErrAttrNotIndexed {
+			return
+		}
+		if err != nil {
+			report.Add(blockNum, blkstorage.CorruptionIndexInconsistent,
+				"cannot find transaction %d in index: %s", tranNum, err)
+			continue
+		}
+		expectedOffset := loc.offset + prefixLen + txOffset.loc.offset
+		expectedCompressedOffset := 0
+		if compressed {
+			expectedOffset = loc.offset
+			expectedCompressedOffset = txOffset.loc.offset
+		}
+		if txLoc.fileSuffixNum != loc.fileSuffixNum || txLoc.offset != expectedOffset ||
+			txLoc.compressedBlockTxOffset != expectedCompressedOffset || txLoc.bytesLength != txOffset.loc.bytesLength {
+			report.Add(blockNum, blkstorage.CorruptionIndexInconsistent,
+				"transaction %d is indexed at %s instead of file %d offset %d", tranNum, txLoc, loc.fileSuffixNum, expectedOffset)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package fsblkstorage
+
+import (
+	"os"
+	"testing"
+
+	"github.com/hyperledger/fabric/common/ledger/blkstorage"
+	"github.com/hyperledger/fabric/common/ledger/testutil"
+)
+
+func corruptionKinds(report *blkstorage.CorruptionReport) map[blkstorage.CorruptionKind][]uint64 {
+	kinds := make(map[blkstorage.CorruptionKind][]uint64)
+	for _, c := range report.Corruptions {
+		kinds[c.Kind] = append(kinds[c.Kind], c.BlockNum)
+	}
+	return kinds
+}
+
+func TestVerifyChain(t *testing.T) {
+	env := newTestEnv(t, NewConf(testPath(), 0))
+	defer env.Cleanup()
+	blkfileMgrWrapper := newTestBlockfileWrapper(env, "testLedger")
+	defer blkfileMgrWrapper.close()
+	blkfileMgr := blkfileMgrWrapper.blockfileMgr
+	blkfileMgrWrapper.addBlocks(testutil.ConstructTestBlocks(t, 10))
+
+	report, err := blkfileMgr.verifyChain(0, 10)
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, report.IsCorrupted(), false)
+
+	report, err = blkfileMgr.verifyChain(4, 7)
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, report.IsCorrupted(), false)
+
+	_, err = blkfileMgr.verifyChain(5, 5)
+	testutil.AssertError(t, err, "Empty range should be rejected")
+	_, err = blkfileMgr.verifyChain(0, 11)
+	testutil.AssertError(t, err, "Range beyond the chain height should be rejected")
+}
+
+func TestVerifyChainDetectsTamperedBlockFile(t *testing.T) {
+	env := newTestEnv(t, NewConf(testPath(), 0))
+	defer env.Cleanup()
+	blkfileMgrWrapper := newTestBlockfileWrapper(env, "testLedger")
+	defer blkfileMgrWrapper.close()
+	blkfileMgr := blkfileMgrWrapper.blockfileMgr
+	blkfileMgrWrapper.addBlocks(testutil.ConstructTestBlocks(t, 10))
+
+	// Flip the last byte of the first transaction of block 6, which is part of the envelope signature
+	txLoc, err := blkfileMgr.index.getTXLocByBlockNumTranNum(6, 0)
+	testutil.AssertNoError(t, err, "")
+	file, err := os.OpenFile(deriveBlockfilePath(blkfileMgr.rootDir, txLoc.fileSuffixNum), os.O_RDWR, 0)
+	testutil.AssertNoError(t, err, "")
+	b := make([]byte, 1)
+	_, err = file.ReadAt(b, int64(txLoc.offset+txLoc.bytesLength-1))
+	testutil.AssertNoError(t, err, "")
+	b[0] ^= 0xff
+	_, err = file.WriteAt(b, int64(txLoc.offset+txLoc.bytesLength-1))
+	testutil.AssertNoError(t, err, "")
+	file.Close()
+
+	report, err := blkfileMgr.verifyChain(0, 10)
+	testutil.AssertNoError(t, err, "")
+	kinds := corruptionKinds(report)
+	testutil.AssertEquals(t, len(report.Corruptions), 1)
+	testutil.AssertEquals(t, kinds[blkstorage.CorruptionDataHashMismatch], []uint64{6})
+	// The header of block 6 is intact, so block 7 still links to it
+	testutil.AssertEquals(t, kinds[blkstorage.CorruptionIndexInconsistent], []uint64(nil))
+	testutil.AssertEquals(t, kinds[blkstorage.CorruptionPrevHashMismatch], []uint64(nil))
+
+	// Verifying a range that excludes block 6 reports no corruption
+	report, err = blkfileMgr.verifyChain(7, 10)
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, report.IsCorrupted(), false)
+}
+
+func TestVerifyChainDetectsInconsistentIndex(t *testing.T) {
+	env := newTestEnv(t, NewConf(testPath(), 0))
+	defer env.Cleanup()
+	blkfileMgrWrapper := newTestBlockfileWrapper(env, "testLedger")
+	defer blkfileMgrWrapper.close()
+	blkfileMgr := blkfileMgrWrapper.blockfileMgr
+	blkfileMgrWrapper.addBlocks(testutil.ConstructTestBlocks(t, 10))
+
+	// Make the index entry of block 3 point to block 4
+	loc, err := blkfileMgr.index.getBlockLocByBlockNum(4)
+	testutil.AssertNoError(t, err, "")
+	locBytes, err := loc.marshal()
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertNoError(t, blkfileMgr.db.Put(constructBlockNumKey(3), locBytes, true), "")
+
+	report, err := blkfileMgr.verifyChain(0, 10)
+	testutil.AssertNoError(t, err, "")
+	kinds := corruptionKinds(report)
+	testutil.AssertEquals(t, kinds[blkstorage.CorruptionBlockNumMismatch], []uint64{3})
+	testutil.AssertEquals(t, kinds[blkstorage.CorruptionPrevHashMismatch], []uint64{3, 4})
+	testutil.AssertContains(t, kinds[blkstorage.CorruptionIndexInconsistent], uint64(3))
+	testutil.AssertEquals(t, kinds[blkstorage.CorruptionDataHashMismatch], []uint64(nil))
+}
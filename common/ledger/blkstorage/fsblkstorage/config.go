@@ -30,6 +30,7 @@ const (
 type Conf struct {
 	blockStorageDir  string
 	maxBlockfileSize int
+	compression      blockCompression
 }
 
 // NewConf constructs new `Conf`.
@@ -38,7 +39,20 @@ func NewConf(blockStorageDir string, maxBlockfileSize int) *Conf {
 	if maxBlockfileSize <= 0 {
 		maxBlockfileSize = defaultMaxBlockfileSize
 	}
-	return &Conf{blockStorageDir, maxBlockfileSize}
+	return &Conf{blockStorageDir: blockStorageDir, maxBlockfileSize: maxBlockfileSize}
+}
+
+// NewConfWithCompression constructs new `Conf` that compresses the blocks it adds to the block files
+// with the given codec ("none" or "snappy"). The blocks already in the block files are retrieved
+// regardless of the compression they were stored with
+func NewConfWithCompression(blockStorageDir string, maxBlockfileSize int, compression string) (*Conf, error) {
+	c, err := parseBlockCompression(compression)
+	if err != nil {
+		return nil, err
+	}
+	conf := NewConf(blockStorageDir, maxBlockfileSize)
+	conf.compression = c
+	return conf, nil
 }
 
 func (conf *Conf) getIndexDir() string {
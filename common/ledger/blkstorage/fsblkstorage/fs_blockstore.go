@@ -86,6 +86,27 @@ func (store *fsBlockStore) RetrieveTxValidationCodeByTxID(txID string) (peer.TxV
 	return store.fileMgr.retrieveTxValidationCodeByTxID(txID)
 }
 
+// RetrieveTxValidationCodesByTxIDs returns the validation codes of the given transactions
+func (store *fsBlockStore) RetrieveTxValidationCodesByTxIDs(txIDs []string) (map[string]peer.TxValidationCode, error) {
+	return store.fileMgr.retrieveTxValidationCodesByTxIDs(txIDs)
+}
+
+// RetrieveTxsByCreator returns the transactions of the given creator, starting at the given position
+func (store *fsBlockStore) RetrieveTxsByCreator(creator []byte, start blkstorage.TxPosition, limit int) ([]*peer.ProcessedTransaction, *blkstorage.TxPosition, error) {
+	return store.fileMgr.retrieveTxsByCreator(creator, start, limit)
+}
+
+// RetrieveTxsByMSPIDAndChaincode returns the transactions submitted by the given org to the given chaincode,
+// starting at the given position
+func (store *fsBlockStore) RetrieveTxsByMSPIDAndChaincode(mspID string, chaincodeName string, start blkstorage.TxPosition, limit int) ([]*peer.ProcessedTransaction, *blkstorage.TxPosition, error) {
+	return store.fileMgr.retrieveTxsByMSPIDAndChaincode(mspID, chaincodeName, start, limit)
+}
+
+// VerifyChain verifies the integrity of the blocks in the range [startHeight, endHeight)
+func (store *fsBlockStore) VerifyChain(startHeight uint64, endHeight uint64) (*blkstorage.CorruptionReport, error) {
+	return store.fileMgr.verifyChain(startHeight, endHeight)
+}
+
 // Shutdown shuts down the block store
 func (store *fsBlockStore) Shutdown() {
 	logger.Debugf("closing fs blockStore:%s", store.id)
@@ -17,6 +17,8 @@ limitations under the License.
 package fsblkstorage
 
 import (
+	"os"
+
 	"github.com/hyperledger/fabric/common/ledger/blkstorage"
 	"github.com/hyperledger/fabric/common/ledger/util"
 	"github.com/hyperledger/fabric/common/ledger/util/leveldbhelper"
@@ -59,6 +61,23 @@ func (p *FsBlockstoreProvider) List() ([]string, error) {
 	return util.ListSubdirs(p.conf.getChainsDir())
 }
 
+// Remove removes the BlockStore with given id, along with its index entries.
+// The BlockStore should be shut down before it is removed
+func (p *FsBlockstoreProvider) Remove(ledgerid string) error {
+	if err := p.leveldbProvider.GetDBHandle(ledgerid).DeleteAll(true); err != nil {
+		return err
+	}
+	return os.RemoveAll(p.conf.getLedgerBlockDir(ledgerid))
+}
+
+// Rollback removes the blocks at and above the given height from the BlockStore with given id.
+// The block files are truncated right before the first removed block, and the index entries of
+// the BlockStore are dropped so that the index of the remaining blocks is rebuilt from the block
+// files when the BlockStore is opened next. The BlockStore should be shut down before it is rolled back
+func (p *FsBlockstoreProvider) Rollback(ledgerid string, height uint64) error {
+	return rollback(p.conf.getLedgerBlockDir(ledgerid), height, p.leveldbProvider.GetDBHandle(ledgerid))
+}
+
 // Close closes the FsBlockstoreProvider
 func (p *FsBlockstoreProvider) Close() {
 	p.leveldbProvider.Close()
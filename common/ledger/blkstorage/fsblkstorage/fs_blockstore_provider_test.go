@@ -147,6 +147,88 @@ func TestBlockStoreProvider(t *testing.T) {
 
 }
 
+func TestBlockStoreProviderRemove(t *testing.T) {
+	env := newTestEnv(t, NewConf(testPath(), 0))
+	defer env.Cleanup()
+
+	provider := env.provider
+	blocks := testutil.ConstructTestBlocks(t, 5)
+	for _, id := range []string{constructLedgerid(1), constructLedgerid(10)} {
+		store, _ := provider.OpenBlockStore(id)
+		for _, block := range blocks {
+			testutil.AssertNoError(t, store.AddBlock(block), "")
+		}
+		store.Shutdown()
+	}
+
+	testutil.AssertNoError(t, provider.Remove(constructLedgerid(1)), "")
+	exists, err := provider.Exists(constructLedgerid(1))
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, exists, false)
+	storeNames, _ := provider.List()
+	testutil.AssertEquals(t, storeNames, []string{constructLedgerid(10)})
+
+	// a block store created again with the same id starts empty
+	store, _ := provider.OpenBlockStore(constructLedgerid(1))
+	defer store.Shutdown()
+	bcInfo, err := store.GetBlockchainInfo()
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, bcInfo.Height, uint64(0))
+
+	// the other block store is untouched
+	other, _ := provider.OpenBlockStore(constructLedgerid(10))
+	defer other.Shutdown()
+	checkBlocks(t, blocks, other)
+}
+
+func TestBlockStoreProviderRollback(t *testing.T) {
+	blocks := testutil.ConstructTestBlocks(t, 30)
+	by, _, err := serializeBlock(blocks[1])
+	testutil.AssertNoError(t, err, "")
+	// a block file holds about five blocks
+	env := newTestEnv(t, NewConf(testPath(), 5*(len(by)+8)))
+	defer env.Cleanup()
+	provider := env.provider
+
+	store, _ := provider.OpenBlockStore("ledger1")
+	for _, block := range blocks {
+		testutil.AssertNoError(t, store.AddBlock(block), "")
+	}
+	txid, err := extractTxID(blocks[20].Data.Data[0])
+	testutil.AssertNoError(t, err, "")
+	store.Shutdown()
+
+	testutil.AssertError(t, provider.Rollback("ledger1", 31), "Expected an error when rolling back above the current height")
+	testutil.AssertNoError(t, provider.Rollback("ledger1", 30), "")
+	testutil.AssertNoError(t, provider.Rollback("ledger1", 12), "")
+
+	store, _ = provider.OpenBlockStore("ledger1")
+	checkBlocks(t, blocks[:12], store)
+	_, err = store.RetrieveTxByID(txid)
+	testutil.AssertEquals(t, err, blkstorage.ErrNotFoundInIndex)
+
+	// the removed blocks can be committed again
+	for _, block := range blocks[12:] {
+		testutil.AssertNoError(t, store.AddBlock(block), "")
+	}
+	checkBlocks(t, blocks, store)
+	store.Shutdown()
+
+	// a block store can be rolled back to empty, and its blocks committed again
+	testutil.AssertNoError(t, provider.Rollback("ledger1", 0), "")
+	store, _ = provider.OpenBlockStore("ledger1")
+	defer store.Shutdown()
+	bcInfo, err := store.GetBlockchainInfo()
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, bcInfo.Height, uint64(0))
+	for _, block := range blocks {
+		testutil.AssertNoError(t, store.AddBlock(block), "")
+	}
+	checkBlocks(t, blocks, store)
+
+	testutil.AssertError(t, provider.Rollback("ledger2", 0), "Expected an error when rolling back a missing block store")
+}
+
 func constructLedgerid(id int) string {
 	return fmt.Sprintf("ledger_%d", id)
 }
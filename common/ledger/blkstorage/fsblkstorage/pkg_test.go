@@ -56,6 +56,8 @@ func newTestEnv(t testing.TB, conf *Conf) *testEnv {
 		blkstorage.IndexableAttrBlockNumTranNum,
 		blkstorage.IndexableAttrBlockTxID,
 		blkstorage.IndexableAttrTxValidationCode,
+		blkstorage.IndexableAttrTxCreator,
+		blkstorage.IndexableAttrTxMSPIDChaincode,
 	}
 	return newTestEnvSelectiveIndexing(t, conf, attrsToIndex)
 }
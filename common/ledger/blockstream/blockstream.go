@@ -0,0 +1,81 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package blockstream
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/protos/common"
+	"github.com/pkg/errors"
+)
+
+// maxBlockSize bounds the length of a block read from a stream,
+// so that a corrupted length prefix does not exhaust the memory
+const maxBlockSize = 1 << 30
+
+// Writer writes blocks into a block stream, in which every
+// marshaled block is preceded by its varint encoded length
+type Writer struct {
+	w io.Writer
+}
+
+// NewWriter returns a Writer writing a block stream into the given writer
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: w}
+}
+
+// Write appends the given block to the stream
+func (w *Writer) Write(block *common.Block) error {
+	blockBytes, err := proto.Marshal(block)
+	if err != nil {
+		return errors.Wrap(err, "error marshaling block")
+	}
+	if _, err = w.w.Write(proto.EncodeVarint(uint64(len(blockBytes)))); err != nil {
+		return errors.Wrap(err, "error writing block length")
+	}
+	if _, err = w.w.Write(blockBytes); err != nil {
+		return errors.Wrap(err, "error writing block")
+	}
+	return nil
+}
+
+// Reader reads the blocks of a block stream written by a Writer
+type Reader struct {
+	r *bufio.Reader
+}
+
+// NewReader returns a Reader reading a block stream from the given reader
+func NewReader(r io.Reader) *Reader {
+	return &Reader{r: bufio.NewReader(r)}
+}
+
+// Read returns the next block of the stream, or io.EOF
+// once all the blocks of the stream have been read
+func (r *Reader) Read() (*common.Block, error) {
+	length, err := binary.ReadUvarint(r.r)
+	if err == io.EOF {
+		return nil, io.EOF
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "error reading block length")
+	}
+	if length > maxBlockSize {
+		return nil, errors.Errorf("block length %d exceeds the maximum of %d bytes", length, maxBlockSize)
+	}
+	blockBytes := make([]byte, length)
+	if _, err = io.ReadFull(r.r, blockBytes); err != nil {
+		return nil, errors.Wrap(err, "error reading block")
+	}
+	block := &common.Block{}
+	if err = proto.Unmarshal(blockBytes, block); err != nil {
+		return nil, errors.Wrap(err, "error unmarshaling block")
+	}
+	return block, nil
+}
@@ -0,0 +1,61 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package blockstream
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/protos/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteAndRead(t *testing.T) {
+	blocks := []*common.Block{
+		{Header: &common.BlockHeader{Number: 0}, Data: &common.BlockData{Data: [][]byte{[]byte("tx0")}}},
+		{Header: &common.BlockHeader{Number: 1}, Data: &common.BlockData{Data: [][]byte{[]byte("tx1"), []byte("tx2")}}},
+		{Header: &common.BlockHeader{Number: 2}},
+	}
+
+	buf := &bytes.Buffer{}
+	w := NewWriter(buf)
+	for _, block := range blocks {
+		assert.NoError(t, w.Write(block))
+	}
+
+	r := NewReader(buf)
+	for _, expected := range blocks {
+		block, err := r.Read()
+		assert.NoError(t, err)
+		assert.True(t, proto.Equal(expected, block))
+	}
+	_, err := r.Read()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestReadCorruptedStream(t *testing.T) {
+	buf := &bytes.Buffer{}
+	assert.NoError(t, NewWriter(buf).Write(&common.Block{Header: &common.BlockHeader{Number: 5}}))
+
+	// a truncated block
+	_, err := NewReader(bytes.NewReader(buf.Bytes()[:buf.Len()-1])).Read()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "error reading block")
+
+	// a length prefix exceeding the maximum block size
+	_, err = NewReader(bytes.NewReader(proto.EncodeVarint(maxBlockSize + 1))).Read()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "exceeds the maximum")
+
+	// bytes which are not a block
+	garbage := append(proto.EncodeVarint(3), 0xff, 0xff, 0xff)
+	_, err = NewReader(bytes.NewReader(garbage)).Read()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "error unmarshaling block")
+}
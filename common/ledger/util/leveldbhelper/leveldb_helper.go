@@ -40,6 +40,8 @@ const (
 // Conf configuration for `DB`
 type Conf struct {
 	DBPath string
+	// BlockCacheCapacity is the size in bytes of the block cache of the db, the leveldb default (8MB) is used if zero
+	BlockCacheCapacity int
 }
 
 // DB - a wrapper on an actual store
@@ -71,23 +73,32 @@ func CreateDB(conf *Conf) *DB {
 
 // Open opens the underlying db
 func (dbInst *DB) Open() {
+	if err := dbInst.TryOpen(); err != nil {
+		panic(err.Error())
+	}
+}
+
+// TryOpen opens the underlying db like Open, but returns an error instead of panicking if the db
+// cannot be opened, such as when the db is locked by another process that has it open
+func (dbInst *DB) TryOpen() error {
 	dbInst.mux.Lock()
 	defer dbInst.mux.Unlock()
 	if dbInst.dbState == opened {
-		return
+		return nil
 	}
-	dbOpts := &opt.Options{}
+	dbOpts := &opt.Options{BlockCacheCapacity: dbInst.conf.BlockCacheCapacity}
 	dbPath := dbInst.conf.DBPath
 	var err error
 	var dirEmpty bool
 	if dirEmpty, err = util.CreateDirIfMissing(dbPath); err != nil {
-		panic(fmt.Sprintf("Error while trying to create dir if missing: %s", err))
+		return fmt.Errorf("Error while trying to create dir if missing: %s", err)
 	}
 	dbOpts.ErrorIfMissing = !dirEmpty
 	if dbInst.db, err = leveldb.OpenFile(dbPath, dbOpts); err != nil {
-		panic(fmt.Sprintf("Error while trying to open DB: %s", err))
+		return fmt.Errorf("Error while trying to open DB: %s", err)
 	}
 	dbInst.dbState = opened
+	return nil
 }
 
 // Close closes the underlying db
@@ -112,7 +112,7 @@ func TestLevelDBHelper(t *testing.T) {
 func TestCreateDBInEmptyDir(t *testing.T) {
 	testutil.AssertNoError(t, os.RemoveAll(testDBPath), "")
 	testutil.AssertNoError(t, os.MkdirAll(testDBPath, 0775), "")
-	db := CreateDB(&Conf{testDBPath})
+	db := CreateDB(&Conf{DBPath: testDBPath})
 	defer db.Close()
 	defer func() {
 		if r := recover(); r != nil {
@@ -128,7 +128,7 @@ func TestCreateDBInNonEmptyDir(t *testing.T) {
 	file, err := os.Create(filepath.Join(testDBPath, "dummyfile.txt"))
 	testutil.AssertNoError(t, err, "")
 	file.Close()
-	db := CreateDB(&Conf{testDBPath})
+	db := CreateDB(&Conf{DBPath: testDBPath})
 	defer db.Close()
 	defer func() {
 		if r := recover(); r == nil {
@@ -137,3 +137,17 @@ func TestCreateDBInNonEmptyDir(t *testing.T) {
 	}()
 	db.Open()
 }
+
+func TestTryOpenLockedDB(t *testing.T) {
+	testutil.AssertNoError(t, os.RemoveAll(testDBPath), "")
+	db := CreateDB(&Conf{DBPath: testDBPath})
+	testutil.AssertNoError(t, db.TryOpen(), "")
+	defer db.Close()
+
+	// the db is locked while it is open, so another instance fails to open it
+	otherDB := CreateDB(&Conf{DBPath: testDBPath})
+	testutil.AssertError(t, otherDB.TryOpen(), "Error expected when opening a locked db")
+	db.Close()
+	testutil.AssertNoError(t, otherDB.TryOpen(), "")
+	otherDB.Close()
+}
@@ -90,6 +90,24 @@ func TestBatchedUpdates(t *testing.T) {
 	}
 }
 
+func TestDeleteAll(t *testing.T) {
+	env := newTestProviderEnv(t, testDBPath)
+	defer env.cleanup()
+	p := env.provider
+
+	db1 := p.GetDBHandle("db1")
+	db10 := p.GetDBHandle("db10")
+	for i := 0; i < 10; i++ {
+		db1.Put([]byte(createTestKey(i)), []byte(createTestValue("db1", i)), false)
+		db10.Put([]byte(createTestKey(i)), []byte(createTestValue("db10", i)), false)
+	}
+
+	testutil.AssertNoError(t, db1.DeleteAll(true), "")
+	checkItrResults(t, db1.GetIterator(nil, nil), nil, nil)
+	// the keys of the other dbs are untouched
+	checkItrResults(t, db10.GetIterator(nil, nil), createTestKeys(0, 9), createTestValues("db10", 0, 9))
+}
+
 func testDBBasicWriteAndReads(t *testing.T, dbNames ...string) {
 	env := newTestProviderEnv(t, testDBPath)
 	defer env.cleanup()
@@ -18,8 +18,11 @@ package localmsp
 
 import (
 	"fmt"
+	"path/filepath"
 
+	"github.com/hyperledger/fabric/bccsp/factory"
 	"github.com/hyperledger/fabric/common/crypto"
+	"github.com/hyperledger/fabric/msp"
 	mspmgmt "github.com/hyperledger/fabric/msp/mgmt"
 	cb "github.com/hyperledger/fabric/protos/common"
 )
@@ -72,3 +75,64 @@ func (s *mspSigner) Sign(message []byte) ([]byte, error) {
 
 	return signature, nil
 }
+
+type identitySigner struct {
+	identity msp.SigningIdentity
+}
+
+// NewSignerFromMSPDir returns a LocalSigner which signs with the default signing
+// identity of the MSP in the given directory, rather than with the local MSP.
+// The private key of the identity is retrieved through a crypto provider of its
+// own, configured by bccspConfig, which may be backed by a hardware security module.
+// If bccspConfig is nil, the software based provider with the key store of the
+// directory is used.
+func NewSignerFromMSPDir(dir string, bccspConfig *factory.FactoryOpts, mspID string) (crypto.LocalSigner, error) {
+	bccspConfig = msp.SetupBCCSPKeystoreConfig(bccspConfig, filepath.Join(dir, "keystore"))
+	csp, err := factory.GetBCCSPFromOpts(bccspConfig)
+	if err != nil {
+		return nil, fmt.Errorf("Failed creating crypto provider [%s]", err)
+	}
+
+	conf, err := msp.GetLocalMspConfig(dir, bccspConfig, mspID)
+	if err != nil {
+		return nil, fmt.Errorf("Failed loading MSP configuration from %s [%s]", dir, err)
+	}
+	theMsp, err := msp.NewBccspMspWithCSP(csp)
+	if err != nil {
+		return nil, err
+	}
+	if err := theMsp.Setup(conf); err != nil {
+		return nil, fmt.Errorf("Failed setting up MSP from %s [%s]", dir, err)
+	}
+	identity, err := theMsp.GetDefaultSigningIdentity()
+	if err != nil {
+		return nil, fmt.Errorf("Failed getting MSP-based signer [%s]", err)
+	}
+
+	return &identitySigner{identity: identity}, nil
+}
+
+// NewSignatureHeader creates a SignatureHeader with the signing identity and a valid nonce
+func (s *identitySigner) NewSignatureHeader() (*cb.SignatureHeader, error) {
+	creatorIdentityRaw, err := s.identity.Serialize()
+	if err != nil {
+		return nil, fmt.Errorf("Failed serializing creator public identity [%s]", err)
+	}
+
+	nonce, err := crypto.GetRandomNonce()
+	if err != nil {
+		return nil, fmt.Errorf("Failed creating nonce [%s]", err)
+	}
+
+	return &cb.SignatureHeader{Creator: creatorIdentityRaw, Nonce: nonce}, nil
+}
+
+// Sign a message which should embed a signature header created by NewSignatureHeader
+func (s *identitySigner) Sign(message []byte) ([]byte, error) {
+	signature, err := s.identity.Sign(message)
+	if err != nil {
+		return nil, fmt.Errorf("Failed generating signature [%s]", err)
+	}
+
+	return signature, nil
+}
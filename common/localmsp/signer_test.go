@@ -21,6 +21,7 @@ import (
 	"testing"
 
 	"github.com/hyperledger/fabric/common/crypto"
+	"github.com/hyperledger/fabric/core/config"
 	mspmgmt "github.com/hyperledger/fabric/msp/mgmt"
 	"github.com/stretchr/testify/assert"
 )
@@ -71,3 +72,26 @@ func TestMspSigner_Sign(t *testing.T) {
 	err = mspIdentity.Verify(msg, sigma)
 	assert.NoError(t, err, "Failed verifiing signature")
 }
+
+func TestNewSignerFromMSPDir(t *testing.T) {
+	mspDir, err := config.GetDevMspDir()
+	assert.NoError(t, err)
+
+	signer, err := NewSignerFromMSPDir(mspDir, nil, "DEFAULT")
+	assert.NoError(t, err)
+
+	sh, err := signer.NewSignatureHeader()
+	assert.NoError(t, err)
+	assert.Len(t, sh.Nonce, crypto.NonceSize)
+
+	msg := []byte("Hello World")
+	sigma, err := signer.Sign(msg)
+	assert.NoError(t, err)
+
+	identity, err := mspmgmt.GetLocalMSP().DeserializeIdentity(sh.Creator)
+	assert.NoError(t, err, "Expected the identity of the MSP directory")
+	assert.NoError(t, identity.Verify(msg, sigma))
+
+	_, err = NewSignerFromMSPDir("/nonexistent", nil, "DEFAULT")
+	assert.Error(t, err)
+}
@@ -0,0 +1,260 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package metrics
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/uber-go/tally"
+)
+
+const (
+	// NullReporterName is the name of the reporter discarding the metrics, which is used
+	// unless another one is set
+	NullReporterName = "nullstatreporter"
+	// LogReporterName is the name of the reporter logging a summary of the metrics at
+	// every interval
+	LogReporterName = "log"
+)
+
+var reporterLogger = flogging.MustGetLogger("metrics")
+
+// Reporter is a backend the metrics of the root scope are reported to. Counters are
+// reported as the increments since the last report
+type Reporter interface {
+	// ReportCounter reports a counter value
+	ReportCounter(name string, tags map[string]string, value int64)
+
+	// ReportGauge reports a gauge value
+	ReportGauge(name string, tags map[string]string, value float64)
+
+	// ReportTimer reports a timer value
+	ReportTimer(name string, tags map[string]string, interval time.Duration)
+
+	// Flush is called once the values of a reporting round are reported
+	Flush()
+}
+
+// Opts contains the configuration of the reporter the metrics are reported to
+type Opts struct {
+	// Reporter is the name of the registered reporter
+	Reporter string
+	// Interval is the interval at which the reporter publishes the metrics
+	Interval time.Duration
+}
+
+// ReporterFactory creates a reporter with the given options
+type ReporterFactory func(opts Opts) (Reporter, error)
+
+var reporterRegistry = struct {
+	lock      sync.RWMutex
+	factories map[string]ReporterFactory
+}{
+	factories: map[string]ReporterFactory{
+		NullReporterName: func(Opts) (Reporter, error) {
+			return nil, nil
+		},
+		LogReporterName: func(opts Opts) (Reporter, error) {
+			return newLogReporter(opts.Interval), nil
+		},
+	},
+}
+
+// RegisterReporter makes the reporter created by the given factory available under the
+// given name. It returns an error if a reporter is already registered under the name
+func RegisterReporter(name string, factory ReporterFactory) error {
+	reporterRegistry.lock.Lock()
+	defer reporterRegistry.lock.Unlock()
+	if _, ok := reporterRegistry.factories[name]; ok {
+		return fmt.Errorf("metrics reporter %s is already registered", name)
+	}
+	reporterRegistry.factories[name] = factory
+	return nil
+}
+
+// SetReporter reports the metrics of the root scope to the reporter registered under the
+// name given in the options, in place of the reporter they were reported to so far
+func SetReporter(opts Opts) error {
+	reporterRegistry.lock.RLock()
+	factory, ok := reporterRegistry.factories[opts.Reporter]
+	reporterRegistry.lock.RUnlock()
+	if !ok {
+		return fmt.Errorf("metrics reporter %s is not registered", opts.Reporter)
+	}
+	reporter, err := factory(opts)
+	if err != nil {
+		return fmt.Errorf("could not create metrics reporter %s: %s", opts.Reporter, err)
+	}
+	rootReporter.set(reporter)
+	return nil
+}
+
+// rootReporter forwards the metrics of the root scope to the reporter set last
+var rootReporter = &switchReporter{}
+
+type switchReporter struct {
+	lock     sync.RWMutex
+	reporter Reporter
+}
+
+func (r *switchReporter) set(reporter Reporter) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.reporter = reporter
+}
+
+func (r *switchReporter) current() Reporter {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	return r.reporter
+}
+
+func (r *switchReporter) Capabilities() tally.Capabilities {
+	return r
+}
+
+func (r *switchReporter) Reporting() bool {
+	return r.current() != nil
+}
+
+func (r *switchReporter) Tagging() bool {
+	return true
+}
+
+func (r *switchReporter) Flush() {
+	if reporter := r.current(); reporter != nil {
+		reporter.Flush()
+	}
+}
+
+func (r *switchReporter) ReportCounter(name string, tags map[string]string, value int64) {
+	if reporter := r.current(); reporter != nil {
+		reporter.ReportCounter(name, tags, value)
+	}
+}
+
+func (r *switchReporter) ReportGauge(name string, tags map[string]string, value float64) {
+	if reporter := r.current(); reporter != nil {
+		reporter.ReportGauge(name, tags, value)
+	}
+}
+
+func (r *switchReporter) ReportTimer(name string, tags map[string]string, interval time.Duration) {
+	if reporter := r.current(); reporter != nil {
+		reporter.ReportTimer(name, tags, interval)
+	}
+}
+
+func (r *switchReporter) ReportHistogramValueSamples(name string, tags map[string]string, buckets tally.Buckets,
+	bucketLowerBound, bucketUpperBound float64, samples int64) {
+}
+
+func (r *switchReporter) ReportHistogramDurationSamples(name string, tags map[string]string, buckets tally.Buckets,
+	bucketLowerBound, bucketUpperBound time.Duration, samples int64) {
+}
+
+// logReporter sums up the metrics reported over an interval, and logs them once it elapses
+type logReporter struct {
+	interval time.Duration
+	lastLog  time.Time
+
+	lock     sync.Mutex
+	counters map[string]int64
+	gauges   map[string]float64
+	timers   map[string]*timerSummary
+}
+
+type timerSummary struct {
+	count int
+	total time.Duration
+	max   time.Duration
+}
+
+func newLogReporter(interval time.Duration) *logReporter {
+	return &logReporter{
+		interval: interval,
+		lastLog:  time.Now(),
+		counters: make(map[string]int64),
+		gauges:   make(map[string]float64),
+		timers:   make(map[string]*timerSummary),
+	}
+}
+
+func (r *logReporter) ReportCounter(name string, tags map[string]string, value int64) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.counters[metricKey(name, tags)] += value
+}
+
+func (r *logReporter) ReportGauge(name string, tags map[string]string, value float64) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.gauges[metricKey(name, tags)] = value
+}
+
+func (r *logReporter) ReportTimer(name string, tags map[string]string, interval time.Duration) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	key := metricKey(name, tags)
+	summary, ok := r.timers[key]
+	if !ok {
+		summary = &timerSummary{}
+		r.timers[key] = summary
+	}
+	summary.count++
+	summary.total += interval
+	if interval > summary.max {
+		summary.max = interval
+	}
+}
+
+func (r *logReporter) Flush() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	elapsed := time.Since(r.lastLog)
+	if elapsed < r.interval {
+		return
+	}
+	r.lastLog = time.Now()
+
+	var lines []string
+	for key, value := range r.counters {
+		lines = append(lines, fmt.Sprintf("%s %d (%.2f/s)", key, value, float64(value)/elapsed.Seconds()))
+	}
+	for key, value := range r.gauges {
+		lines = append(lines, fmt.Sprintf("%s %g", key, value))
+	}
+	for key, summary := range r.timers {
+		lines = append(lines, fmt.Sprintf("%s count %d mean %s max %s", key, summary.count, summary.total/time.Duration(summary.count), summary.max))
+	}
+	if len(lines) == 0 {
+		return
+	}
+	sort.Strings(lines)
+	reporterLogger.Infof("Metrics over the last %s:\n%s", elapsed, strings.Join(lines, "\n"))
+
+	r.counters = make(map[string]int64)
+	r.timers = make(map[string]*timerSummary)
+}
+
+// metricKey formats a metric name with its tags, as in name{key1=value1,key2=value2}
+func metricKey(name string, tags map[string]string) string {
+	if len(tags) == 0 {
+		return name
+	}
+	pairs := make([]string, 0, len(tags))
+	for key, value := range tags {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+	return name + "{" + strings.Join(pairs, ",") + "}"
+}
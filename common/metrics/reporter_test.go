@@ -0,0 +1,75 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type channelReporter struct {
+	counters chan int64
+}
+
+func (r *channelReporter) ReportCounter(name string, tags map[string]string, value int64) {
+	if name == namespace+".reporter_test.counter" && tags["channel"] == "foo" {
+		r.counters <- value
+	}
+}
+
+func (r *channelReporter) ReportGauge(name string, tags map[string]string, value float64) {}
+
+func (r *channelReporter) ReportTimer(name string, tags map[string]string, interval time.Duration) {}
+
+func (r *channelReporter) Flush() {}
+
+func TestSetReporter(t *testing.T) {
+	assert.Error(t, SetReporter(Opts{Reporter: "Missing"}))
+	assert.Error(t, RegisterReporter(LogReporterName, func(Opts) (Reporter, error) { return nil, nil }))
+
+	reporter := &channelReporter{counters: make(chan int64, 10)}
+	assert.NoError(t, RegisterReporter("TestSetReporter", func(Opts) (Reporter, error) { return reporter, nil }))
+	assert.NoError(t, SetReporter(Opts{Reporter: "TestSetReporter"}))
+	defer SetReporter(Opts{Reporter: NullReporterName})
+
+	NewRootScope().SubScope("reporter_test").Tagged(map[string]string{"channel": "foo"}).Counter("counter").Inc(3)
+	select {
+	case value := <-reporter.counters:
+		assert.Equal(t, int64(3), value)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the counter to be reported")
+	}
+}
+
+func TestLogReporter(t *testing.T) {
+	r := newLogReporter(time.Hour)
+	r.ReportCounter("counter", map[string]string{"channel": "foo"}, 2)
+	r.ReportCounter("counter", map[string]string{"channel": "foo"}, 3)
+	r.ReportGauge("gauge", nil, 0.5)
+	r.ReportTimer("timer", nil, time.Second)
+	r.ReportTimer("timer", nil, 3*time.Second)
+	assert.Equal(t, int64(5), r.counters["counter{channel=foo}"])
+	assert.Equal(t, 0.5, r.gauges["gauge"])
+	assert.Equal(t, &timerSummary{count: 2, total: 4 * time.Second, max: 3 * time.Second}, r.timers["timer"])
+
+	// the interval did not elapse yet
+	r.Flush()
+	assert.Len(t, r.counters, 1)
+
+	r.lastLog = time.Now().Add(-2 * time.Hour)
+	r.Flush()
+	assert.Empty(t, r.counters)
+	assert.Empty(t, r.timers)
+	assert.Len(t, r.gauges, 1, "Expected the gauges to keep their values")
+}
+
+func TestMetricKey(t *testing.T) {
+	assert.Equal(t, "name", metricKey("name", nil))
+	assert.Equal(t, "name{a=1,b=2}", metricKey("name", map[string]string{"b": "2", "a": "1"}))
+}
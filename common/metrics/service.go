@@ -27,15 +27,15 @@ var started uint32
 //NewRootScope creates a global root metrics scope instance, all callers can only use it to extend sub scope
 func NewRootScope() Scope {
 	once.Do(func() {
-		//TODO:Use config yaml
+		// the metrics are discarded until SetReporter sets another reporter
 		conf := config{
 			interval: 1 * time.Second,
-			reporter: "nullstatreporter",
+			reporter: NullReporterName,
 		}
 		rootScope, closer = newRootScope(
 			tally.ScopeOptions{
 				Prefix:   namespace,
-				Reporter: tally.NullStatsReporter}, conf.interval)
+				Reporter: rootReporter}, conf.interval)
 		atomic.StoreUint32(&started, 1)
 	})
 	return rootScope
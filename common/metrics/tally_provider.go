@@ -41,6 +41,18 @@ func (g *gauge) Update(v float64) {
 	g.tallyGauge.Update(v)
 }
 
+type timer struct {
+	tallyTimer tally.Timer
+}
+
+func newTimer(tallyTimer tally.Timer) *timer {
+	return &timer{tallyTimer: tallyTimer}
+}
+
+func (t *timer) Record(v time.Duration) {
+	t.tallyTimer.Record(v)
+}
+
 type scopeRegistry struct {
 	sync.RWMutex
 	subScopes map[string]*scope
@@ -55,9 +67,11 @@ type scope struct {
 
 	cm sync.RWMutex
 	gm sync.RWMutex
+	tm sync.RWMutex
 
 	counters map[string]*counter
 	gauges   map[string]*gauge
+	timers   map[string]*timer
 }
 
 func newRootScope(opts tally.ScopeOptions, interval time.Duration) (Scope, io.Closer) {
@@ -70,7 +84,8 @@ func newRootScope(opts tally.ScopeOptions, interval time.Duration) (Scope, io.Cl
 			subScopes: make(map[string]*scope),
 		},
 		counters: make(map[string]*counter),
-		gauges:   make(map[string]*gauge)}, closer
+		gauges:   make(map[string]*gauge),
+		timers:   make(map[string]*timer)}, closer
 }
 
 func (s *scope) Counter(name string) Counter {
@@ -107,6 +122,23 @@ func (s *scope) Gauge(name string) Gauge {
 	return val
 }
 
+func (s *scope) Timer(name string) Timer {
+	s.tm.RLock()
+	val, ok := s.timers[name]
+	s.tm.RUnlock()
+	if !ok {
+		s.tm.Lock()
+		val, ok = s.timers[name]
+		if !ok {
+			timer := s.tallyScope.Timer(name)
+			val = newTimer(timer)
+			s.timers[name] = val
+		}
+		s.tm.Unlock()
+	}
+	return val
+}
+
 func (s *scope) Tagged(tags map[string]string) Scope {
 	originTags := tags
 	tags = mergeRightTags(s.tags, tags)
@@ -139,6 +171,7 @@ func (s *scope) Tagged(tags map[string]string) Scope {
 
 		counters: make(map[string]*counter),
 		gauges:   make(map[string]*gauge),
+		timers:   make(map[string]*timer),
 	}
 
 	s.registry.subScopes[key] = subScope
@@ -175,6 +208,7 @@ func (s *scope) SubScope(prefix string) Scope {
 
 		counters: make(map[string]*counter),
 		gauges:   make(map[string]*gauge),
+		timers:   make(map[string]*timer),
 	}
 
 	s.registry.subScopes[key] = subScope
@@ -46,6 +46,7 @@ type testStatsReporter struct {
 
 	counters map[string]*testIntValue
 	gauges   map[string]*testFloatValue
+	timers   map[string]time.Duration
 
 	flushes int32
 }
@@ -54,7 +55,8 @@ type testStatsReporter struct {
 func newTestStatsReporter() *testStatsReporter {
 	return &testStatsReporter{
 		counters: make(map[string]*testIntValue),
-		gauges:   make(map[string]*testFloatValue)}
+		gauges:   make(map[string]*testFloatValue),
+		timers:   make(map[string]time.Duration)}
 }
 
 func (r *testStatsReporter) WaitAll() {
@@ -109,7 +111,7 @@ func (r *testStatsReporter) AllocateTimer(
 }
 
 func (r *testStatsReporter) ReportTimer(name string, tags map[string]string, interval time.Duration) {
-
+	r.timers[name] = interval
 }
 
 func (r *testStatsReporter) AllocateHistogram(
@@ -174,6 +176,21 @@ func TestCounter(t *testing.T) {
 	assert.Equal(t, int64(1), r.counters[namespace+".foo1"].val)
 }
 
+func TestTimer(t *testing.T) {
+	t.Parallel()
+	r := newTestStatsReporter()
+	opts := tally.ScopeOptions{
+		Prefix:    namespace,
+		Separator: tally.DefaultSeparator,
+		Reporter:  r}
+
+	s, c := newRootScope(opts, 1*time.Second)
+	defer c.Close()
+	s.Timer("foo").Record(2 * time.Second)
+
+	assert.Equal(t, 2*time.Second, r.timers[namespace+".foo"])
+}
+
 func TestMultiCounterReport(t *testing.T) {
 	t.Parallel()
 	r := newTestStatsReporter()
@@ -6,6 +6,8 @@ SPDX-License-Identifier: Apache-2.0
 
 package metrics
 
+import "time"
+
 // Counter is the interface for emitting Counter type metrics.
 type Counter interface {
 	// Inc increments the Counter by a delta.
@@ -18,6 +20,12 @@ type Gauge interface {
 	Update(value float64)
 }
 
+// Timer is the interface for emitting Timer metrics.
+type Timer interface {
+	// Record records the duration of an operation.
+	Record(value time.Duration)
+}
+
 // Scope is a namespace wrapper around a stats reporter, ensuring that
 // all emitted values have a given prefix or set of tags.
 type Scope interface {
@@ -27,6 +35,9 @@ type Scope interface {
 	// Gauge returns the Gauge object corresponding to the name.
 	Gauge(name string) Gauge
 
+	// Timer returns the Timer object corresponding to the name.
+	Timer(name string) Timer
+
 	// Tagged returns a new child Scope with the given tags and current tags.
 	Tagged(tags map[string]string) Scope
 
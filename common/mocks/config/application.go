@@ -0,0 +1,86 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package config
+
+import (
+	"github.com/hyperledger/fabric/common/config/channel"
+	pb "github.com/hyperledger/fabric/protos/peer"
+)
+
+// Application is a mock implementation of config.Application
+type Application struct {
+	// OrganizationsVal is returned as the result of Organizations()
+	OrganizationsVal map[string]config.ApplicationOrg
+	// GossipConfigVal is returned as the result of GossipConfig()
+	GossipConfigVal *pb.GossipConfig
+	// CapabilitiesVal is returned as the result of Capabilities() if set
+	CapabilitiesVal config.ApplicationCapabilities
+}
+
+// Organizations returns OrganizationsVal
+func (a *Application) Organizations() map[string]config.ApplicationOrg {
+	return a.OrganizationsVal
+}
+
+// GossipConfig returns GossipConfigVal
+func (a *Application) GossipConfig() *pb.GossipConfig {
+	return a.GossipConfigVal
+}
+
+// Capabilities returns the CapabilitiesVal if set, otherwise capabilities requiring nothing
+func (a *Application) Capabilities() config.ApplicationCapabilities {
+	if a.CapabilitiesVal == nil {
+		return &ApplicationCapabilities{}
+	}
+	return a.CapabilitiesVal
+}
+
+// ApplicationCapabilities mocks the config.ApplicationCapabilities interface
+type ApplicationCapabilities struct {
+	// SupportedErr is returned by Supported()
+	SupportedErr error
+	// ForbidDuplicateTXIdInBlockVal is returned by ForbidDuplicateTXIdInBlock()
+	ForbidDuplicateTXIdInBlockVal bool
+	// ReadYourWritesVal is returned by ReadYourWrites()
+	ReadYourWritesVal bool
+	// KeyLevelEndorsementVal is returned by KeyLevelEndorsement()
+	KeyLevelEndorsementVal bool
+	// CollectionUpgradeVal is returned by CollectionUpgrade()
+	CollectionUpgradeVal bool
+	// LifecycleVal is returned by Lifecycle()
+	LifecycleVal bool
+}
+
+// Supported returns SupportedErr
+func (ac *ApplicationCapabilities) Supported() error {
+	return ac.SupportedErr
+}
+
+// ForbidDuplicateTXIdInBlock returns ForbidDuplicateTXIdInBlockVal
+func (ac *ApplicationCapabilities) ForbidDuplicateTXIdInBlock() bool {
+	return ac.ForbidDuplicateTXIdInBlockVal
+}
+
+// ReadYourWrites returns ReadYourWritesVal
+func (ac *ApplicationCapabilities) ReadYourWrites() bool {
+	return ac.ReadYourWritesVal
+}
+
+// KeyLevelEndorsement returns KeyLevelEndorsementVal
+func (ac *ApplicationCapabilities) KeyLevelEndorsement() bool {
+	return ac.KeyLevelEndorsementVal
+}
+
+// CollectionUpgrade returns CollectionUpgradeVal
+func (ac *ApplicationCapabilities) CollectionUpgrade() bool {
+	return ac.CollectionUpgradeVal
+}
+
+// Lifecycle returns LifecycleVal
+func (ac *ApplicationCapabilities) Lifecycle() bool {
+	return ac.LifecycleVal
+}
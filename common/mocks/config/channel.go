@@ -16,7 +16,10 @@ limitations under the License.
 
 package config
 
-import "github.com/hyperledger/fabric/common/util"
+import (
+	"github.com/hyperledger/fabric/common/config/channel"
+	"github.com/hyperledger/fabric/common/util"
+)
 
 func nearIdentityHash(input []byte) []byte {
 	return util.ConcatenateBytes([]byte("FakeHash("), input, []byte(""))
@@ -30,6 +33,8 @@ type Channel struct {
 	BlockDataHashingStructureWidthVal uint32
 	// OrdererAddressesVal is returned as the result of OrdererAddresses()
 	OrdererAddressesVal []string
+	// CapabilitiesVal is returned as the result of Capabilities() if set
+	CapabilitiesVal config.ChannelCapabilities
 }
 
 // HashingAlgorithm returns the HashingAlgorithmVal if set, otherwise a fake simple hash function
@@ -49,3 +54,30 @@ func (scm *Channel) BlockDataHashingStructureWidth() uint32 {
 func (scm *Channel) OrdererAddresses() []string {
 	return scm.OrdererAddressesVal
 }
+
+// Capabilities returns the CapabilitiesVal if set, otherwise capabilities requiring nothing
+func (scm *Channel) Capabilities() config.ChannelCapabilities {
+	if scm.CapabilitiesVal == nil {
+		return &ChannelCapabilities{}
+	}
+	return scm.CapabilitiesVal
+}
+
+// ChannelCapabilities mocks the config.ChannelCapabilities interface
+type ChannelCapabilities struct {
+	// SupportedErr is returned by Supported()
+	SupportedErr error
+
+	// MerkleDataHashVal is returned by MerkleDataHash()
+	MerkleDataHashVal bool
+}
+
+// Supported returns SupportedErr
+func (cc *ChannelCapabilities) Supported() error {
+	return cc.SupportedErr
+}
+
+// MerkleDataHash returns MerkleDataHashVal
+func (cc *ChannelCapabilities) MerkleDataHash() bool {
+	return cc.MerkleDataHashVal
+}
@@ -27,6 +27,10 @@ import (
 type Orderer struct {
 	// ConsensusTypeVal is returned as the result of ConsensusType()
 	ConsensusTypeVal string
+	// ConsensusMetadataVal is returned as the result of ConsensusMetadata()
+	ConsensusMetadataVal []byte
+	// ConsensusStateVal is returned as the result of ConsensusState()
+	ConsensusStateVal ab.ConsensusType_State
 	// BatchSizeVal is returned as the result of BatchSize()
 	BatchSizeVal *ab.BatchSize
 	// BatchTimeoutVal is returned as the result of BatchTimeout()
@@ -35,8 +39,12 @@ type Orderer struct {
 	KafkaBrokersVal []string
 	// MaxChannelsCountVal is returns as the result of MaxChannelsCount()
 	MaxChannelsCountVal uint64
+	// MessageFilterRulesVal is returned as the result of MessageFilterRules()
+	MessageFilterRulesVal []string
 	// OrganizationsVal is returned as the result of Organizations()
 	OrganizationsVal map[string]config.Org
+	// CapabilitiesVal is returned as the result of Capabilities() if set
+	CapabilitiesVal config.OrdererCapabilities
 }
 
 // ConsensusType returns the ConsensusTypeVal
@@ -44,6 +52,16 @@ func (scm *Orderer) ConsensusType() string {
 	return scm.ConsensusTypeVal
 }
 
+// ConsensusMetadata returns the ConsensusMetadataVal
+func (scm *Orderer) ConsensusMetadata() []byte {
+	return scm.ConsensusMetadataVal
+}
+
+// ConsensusState returns the ConsensusStateVal
+func (scm *Orderer) ConsensusState() ab.ConsensusType_State {
+	return scm.ConsensusStateVal
+}
+
 // BatchSize returns the BatchSizeVal
 func (scm *Orderer) BatchSize() *ab.BatchSize {
 	return scm.BatchSizeVal
@@ -64,7 +82,45 @@ func (scm *Orderer) MaxChannelsCount() uint64 {
 	return scm.MaxChannelsCountVal
 }
 
+// MessageFilterRules returns the MessageFilterRulesVal
+func (scm *Orderer) MessageFilterRules() []string {
+	return scm.MessageFilterRulesVal
+}
+
 // Organizations returns OrganizationsVal
 func (scm *Orderer) Organizations() map[string]config.Org {
 	return scm.OrganizationsVal
 }
+
+// Capabilities returns the CapabilitiesVal if set, otherwise capabilities requiring nothing
+func (scm *Orderer) Capabilities() config.OrdererCapabilities {
+	if scm.CapabilitiesVal == nil {
+		return &OrdererCapabilities{}
+	}
+	return scm.CapabilitiesVal
+}
+
+// OrdererCapabilities mocks the config.OrdererCapabilities interface
+type OrdererCapabilities struct {
+	// SupportedErr is returned by Supported()
+	SupportedErr error
+	// ExpirationCheckVal is returned by ExpirationCheck()
+	ExpirationCheckVal bool
+	// ConsensusTypeMigrationVal is returned by ConsensusTypeMigration()
+	ConsensusTypeMigrationVal bool
+}
+
+// Supported returns SupportedErr
+func (oc *OrdererCapabilities) Supported() error {
+	return oc.SupportedErr
+}
+
+// ExpirationCheck returns ExpirationCheckVal
+func (oc *OrdererCapabilities) ExpirationCheck() bool {
+	return oc.ExpirationCheckVal
+}
+
+// ConsensusTypeMigration returns ConsensusTypeMigrationVal
+func (oc *OrdererCapabilities) ConsensusTypeMigration() bool {
+	return oc.ConsensusTypeMigrationVal
+}
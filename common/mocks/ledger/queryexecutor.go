@@ -20,11 +20,14 @@ import (
 	"fmt"
 
 	"github.com/hyperledger/fabric/common/ledger"
+	coreledger "github.com/hyperledger/fabric/core/ledger"
 )
 
 type MockQueryExecutor struct {
 	// State keeps all namespaces
 	State map[string]map[string][]byte
+	// Metadata keeps the metadata of the keys, by namespace and key
+	Metadata map[string]map[string]map[string][]byte
 }
 
 func NewMockQueryExecutor(state map[string]map[string][]byte) *MockQueryExecutor {
@@ -47,6 +50,10 @@ func (m *MockQueryExecutor) GetStateMultipleKeys(namespace string, keys []string
 
 }
 
+func (m *MockQueryExecutor) GetStateMetadata(namespace string, key string) (map[string][]byte, error) {
+	return m.Metadata[namespace][key], nil
+}
+
 func (m *MockQueryExecutor) GetStateRangeScanIterator(namespace string, startKey string, endKey string) (ledger.ResultsIterator, error) {
 	return nil, nil
 
@@ -72,5 +79,10 @@ func (m *MockQueryExecutor) ExecuteQueryOnPrivateData(namespace, collection, que
 	return nil, nil
 }
 
+func (m *MockQueryExecutor) GetStateByPartialCompositeKeyWithPagination(namespace, objectType string, attributes []string,
+	pageSize int32, bookmark string) (coreledger.QueryResultsIterator, error) {
+	return nil, nil
+}
+
 func (m *MockQueryExecutor) Done() {
 }
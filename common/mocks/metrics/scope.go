@@ -0,0 +1,181 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package metrics
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric/common/metrics"
+)
+
+// Scope is a mock implementation of metrics.Scope which records the values of its metrics
+type Scope struct {
+	prefix   string
+	tags     map[string]string
+	registry *registry
+}
+
+type registry struct {
+	lock     sync.Mutex
+	counters map[string]*Counter
+	gauges   map[string]*Gauge
+	timers   map[string]*Timer
+}
+
+// NewScope creates a root scope recording the values of its metrics and of the metrics of its sub-scopes
+func NewScope() *Scope {
+	return &Scope{
+		registry: &registry{
+			counters: make(map[string]*Counter),
+			gauges:   make(map[string]*Gauge),
+			timers:   make(map[string]*Timer),
+		},
+	}
+}
+
+// Counter returns the Counter object corresponding to the name.
+func (s *Scope) Counter(name string) metrics.Counter {
+	return s.CounterOf(s.prefix+name, s.tags)
+}
+
+// Gauge returns the Gauge object corresponding to the name.
+func (s *Scope) Gauge(name string) metrics.Gauge {
+	return s.GaugeOf(s.prefix+name, s.tags)
+}
+
+// Timer returns the Timer object corresponding to the name.
+func (s *Scope) Timer(name string) metrics.Timer {
+	return s.TimerOf(s.prefix+name, s.tags)
+}
+
+// Tagged returns a new child Scope with the given tags and current tags.
+func (s *Scope) Tagged(tags map[string]string) metrics.Scope {
+	merged := make(map[string]string, len(s.tags)+len(tags))
+	for k, v := range s.tags {
+		merged[k] = v
+	}
+	for k, v := range tags {
+		merged[k] = v
+	}
+	return &Scope{prefix: s.prefix, tags: merged, registry: s.registry}
+}
+
+// SubScope returns a new child Scope appending a further name prefix.
+func (s *Scope) SubScope(name string) metrics.Scope {
+	return &Scope{prefix: s.prefix + name + ".", tags: s.tags, registry: s.registry}
+}
+
+// CounterOf returns the counter with the given name, relative to the root scope, and tags
+func (s *Scope) CounterOf(name string, tags map[string]string) *Counter {
+	s.registry.lock.Lock()
+	defer s.registry.lock.Unlock()
+	key := metricKey(name, tags)
+	c, ok := s.registry.counters[key]
+	if !ok {
+		c = &Counter{}
+		s.registry.counters[key] = c
+	}
+	return c
+}
+
+// GaugeOf returns the gauge with the given name, relative to the root scope, and tags
+func (s *Scope) GaugeOf(name string, tags map[string]string) *Gauge {
+	s.registry.lock.Lock()
+	defer s.registry.lock.Unlock()
+	key := metricKey(name, tags)
+	g, ok := s.registry.gauges[key]
+	if !ok {
+		g = &Gauge{}
+		s.registry.gauges[key] = g
+	}
+	return g
+}
+
+// TimerOf returns the timer with the given name, relative to the root scope, and tags
+func (s *Scope) TimerOf(name string, tags map[string]string) *Timer {
+	s.registry.lock.Lock()
+	defer s.registry.lock.Unlock()
+	key := metricKey(name, tags)
+	t, ok := s.registry.timers[key]
+	if !ok {
+		t = &Timer{}
+		s.registry.timers[key] = t
+	}
+	return t
+}
+
+// Counter is a mock implementation of metrics.Counter
+type Counter struct {
+	lock  sync.Mutex
+	value int64
+}
+
+// Inc increments the Counter by a delta.
+func (c *Counter) Inc(delta int64) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.value += delta
+}
+
+// Value returns the sum of the increments of the counter
+func (c *Counter) Value() int64 {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.value
+}
+
+// Gauge is a mock implementation of metrics.Gauge
+type Gauge struct {
+	lock  sync.Mutex
+	value float64
+}
+
+// Update sets the gauges absolute value.
+func (g *Gauge) Update(value float64) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	g.value = value
+}
+
+// Value returns the last value of the gauge
+func (g *Gauge) Value() float64 {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	return g.value
+}
+
+// Timer is a mock implementation of metrics.Timer
+type Timer struct {
+	lock   sync.Mutex
+	values []time.Duration
+}
+
+// Record records the duration of an operation.
+func (t *Timer) Record(value time.Duration) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.values = append(t.values, value)
+}
+
+// Values returns the durations recorded by the timer
+func (t *Timer) Values() []time.Duration {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return append([]time.Duration(nil), t.values...)
+}
+
+func metricKey(name string, tags map[string]string) string {
+	pairs := make([]string, 0, len(tags))
+	for key, value := range tags {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+	return name + "{" + strings.Join(pairs, ",") + "}"
+}
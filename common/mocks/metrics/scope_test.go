@@ -0,0 +1,33 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric/common/metrics"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScopeInterface(t *testing.T) {
+	_ = metrics.Scope(NewScope())
+}
+
+func TestScope(t *testing.T) {
+	root := NewScope()
+	scope := root.SubScope("foo").Tagged(map[string]string{"channel": "bar"})
+	scope.Counter("counter").Inc(2)
+	scope.Counter("counter").Inc(3)
+	scope.Gauge("gauge").Update(1.5)
+	scope.Timer("timer").Record(time.Second)
+
+	assert.Equal(t, int64(5), root.CounterOf("foo.counter", map[string]string{"channel": "bar"}).Value())
+	assert.Equal(t, 1.5, root.GaugeOf("foo.gauge", map[string]string{"channel": "bar"}).Value())
+	assert.Equal(t, []time.Duration{time.Second}, root.TimerOf("foo.timer", map[string]string{"channel": "bar"}).Values())
+	assert.Equal(t, int64(0), root.CounterOf("foo.counter", nil).Value())
+}
@@ -17,31 +17,39 @@ limitations under the License.
 package scc
 
 import (
+	channelconfig "github.com/hyperledger/fabric/common/config/channel"
 	lm "github.com/hyperledger/fabric/common/mocks/ledger"
+	"github.com/hyperledger/fabric/common/policies"
 	"github.com/hyperledger/fabric/core/common/sysccprovider"
 	"github.com/hyperledger/fabric/core/ledger"
 )
 
 type MocksccProviderFactory struct {
-	Qe   *lm.MockQueryExecutor
-	QErr error
+	Qe                      *lm.MockQueryExecutor
+	QErr                    error
+	PolicyManager           policies.Manager
+	ApplicationCapabilities channelconfig.ApplicationCapabilities
+	MSPIDs                  []string
 }
 
 func (c *MocksccProviderFactory) NewSystemChaincodeProvider() sysccprovider.SystemChaincodeProvider {
-	return &mocksccProviderImpl{Qe: c.Qe, QErr: c.QErr}
+	return &mocksccProviderImpl{Qe: c.Qe, QErr: c.QErr, Pm: c.PolicyManager, Ac: c.ApplicationCapabilities, MspIDs: c.MSPIDs}
 }
 
 type mocksccProviderImpl struct {
-	Qe   *lm.MockQueryExecutor
-	QErr error
+	Qe     *lm.MockQueryExecutor
+	QErr   error
+	Pm     policies.Manager
+	Ac     channelconfig.ApplicationCapabilities
+	MspIDs []string
 }
 
 func (c *mocksccProviderImpl) IsSysCC(name string) bool {
-	return (name == "lscc") || (name == "escc") || (name == "vscc") || (name == "notext")
+	return (name == "lscc") || (name == "escc") || (name == "vscc") || (name == "notext") || (name == "_lifecycle")
 }
 
 func (c *mocksccProviderImpl) IsSysCCAndNotInvokableCC2CC(name string) bool {
-	return (name == "escc") || (name == "vscc")
+	return (name == "escc") || (name == "vscc") || (name == "_lifecycle")
 }
 
 func (c *mocksccProviderImpl) IsSysCCAndNotInvokableExternal(name string) bool {
@@ -51,3 +59,15 @@ func (c *mocksccProviderImpl) IsSysCCAndNotInvokableExternal(name string) bool {
 func (c *mocksccProviderImpl) GetQueryExecutorForLedger(cid string) (ledger.QueryExecutor, error) {
 	return c.Qe, c.QErr
 }
+
+func (c *mocksccProviderImpl) PolicyManager(cid string) (policies.Manager, bool) {
+	return c.Pm, c.Pm != nil
+}
+
+func (c *mocksccProviderImpl) ApplicationCapabilities(cid string) (channelconfig.ApplicationCapabilities, bool) {
+	return c.Ac, c.Ac != nil
+}
+
+func (c *mocksccProviderImpl) MSPIDs(cid string) []string {
+	return c.MspIDs
+}
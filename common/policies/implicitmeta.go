@@ -18,6 +18,7 @@ package policies
 
 import (
 	"fmt"
+	"sort"
 
 	cb "github.com/hyperledger/fabric/protos/common"
 
@@ -25,9 +26,10 @@ import (
 )
 
 type implicitMetaPolicy struct {
-	conf        *cb.ImplicitMetaPolicy
-	threshold   int
-	subPolicies []Policy
+	conf            *cb.ImplicitMetaPolicy
+	threshold       int
+	subPolicies     []Policy
+	subPolicyGroups []string
 }
 
 // NewPolicy creates a new policy based on the policy bytes
@@ -43,11 +45,15 @@ func newImplicitMetaPolicy(data []byte) (*implicitMetaPolicy, error) {
 }
 
 func (imp *implicitMetaPolicy) initialize(config *policyConfig) {
-	imp.subPolicies = make([]Policy, len(config.managers))
-	i := 0
-	for _, manager := range config.managers {
-		imp.subPolicies[i], _ = manager.GetPolicy(imp.conf.SubPolicy)
-		i++
+	imp.subPolicyGroups = make([]string, 0, len(config.managers))
+	for group := range config.managers {
+		imp.subPolicyGroups = append(imp.subPolicyGroups, group)
+	}
+	sort.Strings(imp.subPolicyGroups)
+
+	imp.subPolicies = make([]Policy, len(imp.subPolicyGroups))
+	for i, group := range imp.subPolicyGroups {
+		imp.subPolicies[i], _ = config.managers[group].GetPolicy(imp.conf.SubPolicy)
 	}
 
 	switch imp.conf.Rule {
@@ -81,3 +87,31 @@ func (imp *implicitMetaPolicy) Evaluate(signatureSet []*cb.SignedData) error {
 	}
 	return fmt.Errorf("Failed to reach implicit threshold of %d sub-policies, required %d remaining", imp.threshold, remaining)
 }
+
+// EvaluateWithTrace behaves as Evaluate, and additionally returns the trace of the evaluation.
+// Unlike Evaluate, all the sub-policies are evaluated, for the trace to be complete.
+func (imp *implicitMetaPolicy) EvaluateWithTrace(signatureSet []*cb.SignedData) (*Trace, error) {
+	trace := &Trace{
+		Policy:    fmt.Sprintf("%s %s", imp.conf.Rule, imp.conf.SubPolicy),
+		SubTraces: make([]*Trace, len(imp.subPolicies)),
+	}
+
+	remaining := imp.threshold
+	for i, policy := range imp.subPolicies {
+		subTrace, err := EvaluateWithTrace(policy, signatureSet)
+		trace.SubTraces[i] = &Trace{
+			Policy:    imp.subPolicyGroups[i] + PathSeparator + imp.conf.SubPolicy,
+			Satisfied: err == nil,
+			SubTraces: []*Trace{subTrace},
+		}
+		if err == nil {
+			remaining--
+		}
+	}
+
+	if remaining <= 0 {
+		trace.Satisfied = true
+		return trace, nil
+	}
+	return trace, fmt.Errorf("Failed to reach implicit threshold of %d sub-policies, required %d remaining", imp.threshold, remaining)
+}
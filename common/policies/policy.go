@@ -145,6 +145,12 @@ func (rp rejectPolicy) Evaluate(signedData []*cb.SignedData) error {
 	return fmt.Errorf("No such policy type: %s", rp)
 }
 
+// EvaluateWithTrace behaves as Evaluate, and additionally returns the trace of the evaluation
+func (rp rejectPolicy) EvaluateWithTrace(signedData []*cb.SignedData) (*Trace, error) {
+	err := rp.Evaluate(signedData)
+	return &Trace{Policy: "reject " + string(rp), Failures: []string{err.Error()}}, err
+}
+
 // Basepath returns the basePath the manager was instantiated with
 func (pm *ManagerImpl) BasePath() string {
 	return pm.basePath
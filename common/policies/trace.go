@@ -0,0 +1,80 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package policies
+
+import (
+	"strings"
+
+	cb "github.com/hyperledger/fabric/protos/common"
+)
+
+// Trace records how the evaluation of a policy against a set of
+// SignedData went, down to the sub-policies and principals it is made of
+type Trace struct {
+	// Policy describes the policy, sub-policy or principal evaluated
+	Policy string
+
+	// Satisfied tells whether the signatures satisfied the policy
+	Satisfied bool
+
+	// Failures lists why the signatures did not satisfy a principal
+	Failures []string
+
+	// SubTraces are the traces of the sub-policies or principals evaluated
+	SubTraces []*Trace
+}
+
+// String returns a one line representation of the trace, suitable
+// for inclusion in error messages and logs
+func (t *Trace) String() string {
+	if t == nil {
+		return ""
+	}
+
+	s := t.Policy + ": "
+	if t.Satisfied {
+		s += "satisfied"
+	} else {
+		s += "not satisfied"
+	}
+	if len(t.Failures) > 0 {
+		s += " [" + strings.Join(t.Failures, "; ") + "]"
+	}
+	if len(t.SubTraces) > 0 {
+		subTraces := make([]string, len(t.SubTraces))
+		for i, subTrace := range t.SubTraces {
+			subTraces[i] = subTrace.String()
+		}
+		s += " (" + strings.Join(subTraces, ", ") + ")"
+	}
+	return s
+}
+
+// TracingPolicy is a Policy able to record how its evaluation went
+type TracingPolicy interface {
+	Policy
+
+	// EvaluateWithTrace behaves as Evaluate, and additionally
+	// returns the trace of the evaluation
+	EvaluateWithTrace(signatureSet []*cb.SignedData) (*Trace, error)
+}
+
+// EvaluateWithTrace evaluates the policy against the set of SignedData and
+// returns the trace of the evaluation. Policies that do not implement
+// TracingPolicy are traced as a whole, without details.
+func EvaluateWithTrace(policy Policy, signatureSet []*cb.SignedData) (*Trace, error) {
+	if tracingPolicy, ok := policy.(TracingPolicy); ok {
+		return tracingPolicy.EvaluateWithTrace(signatureSet)
+	}
+
+	err := policy.Evaluate(signatureSet)
+	trace := &Trace{Policy: "policy", Satisfied: err == nil}
+	if err != nil {
+		trace.Failures = []string{err.Error()}
+	}
+	return trace, err
+}
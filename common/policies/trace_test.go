@@ -0,0 +1,84 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package policies
+
+import (
+	"fmt"
+	"testing"
+
+	cb "github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/utils"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type errorPolicy struct{}
+
+func (ep errorPolicy) Evaluate(signedData []*cb.SignedData) error {
+	return fmt.Errorf("bad signature")
+}
+
+func TestTraceString(t *testing.T) {
+	trace := &Trace{
+		Policy: "1 out of 2",
+		SubTraces: []*Trace{
+			{Policy: "signed by Org1.member", Failures: []string{"identity 0 is invalid", "identity 1 is invalid"}},
+			{Policy: "signed by Org2.member"},
+		},
+	}
+	assert.Equal(t, "1 out of 2: not satisfied (signed by Org1.member: not satisfied [identity 0 is invalid; identity 1 is invalid], signed by Org2.member: not satisfied)", trace.String())
+
+	trace.SubTraces[1].Satisfied = true
+	trace.Satisfied = true
+	assert.Equal(t, "1 out of 2: satisfied (signed by Org1.member: not satisfied [identity 0 is invalid; identity 1 is invalid], signed by Org2.member: satisfied)", trace.String())
+
+	var nilTrace *Trace
+	assert.Equal(t, "", nilTrace.String())
+}
+
+func TestEvaluateWithTraceFallback(t *testing.T) {
+	trace, err := EvaluateWithTrace(acceptPolicy{}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, &Trace{Policy: "policy", Satisfied: true}, trace)
+
+	trace, err = EvaluateWithTrace(errorPolicy{}, nil)
+	assert.EqualError(t, err, "bad signature")
+	assert.Equal(t, &Trace{Policy: "policy", Failures: []string{"bad signature"}}, trace)
+
+	trace, err = EvaluateWithTrace(rejectPolicy("missing"), nil)
+	assert.Error(t, err)
+	assert.False(t, trace.Satisfied)
+	assert.Equal(t, "reject missing", trace.Policy)
+}
+
+func TestImplicitMetaTrace(t *testing.T) {
+	imp, err := newImplicitMetaPolicy(utils.MarshalOrPanic(&cb.ImplicitMetaPolicy{
+		Rule:      cb.ImplicitMetaPolicy_MAJORITY,
+		SubPolicy: TestPolicyName,
+	}))
+	assert.NoError(t, err)
+
+	managers := makeManagers(3, 1)
+	managers["2"].config.policies[TestPolicyName] = errorPolicy{}
+	imp.initialize(&policyConfig{managers: managers})
+
+	trace, err := EvaluateWithTrace(imp, nil)
+	assert.Error(t, err)
+	assert.Equal(t, imp.Evaluate(nil), err)
+	assert.Equal(t, "MAJORITY TestPolicyName: not satisfied ("+
+		"0/TestPolicyName: satisfied (policy: satisfied), "+
+		"1/TestPolicyName: not satisfied (reject TestPolicyName: not satisfied [No such policy type: TestPolicyName]), "+
+		"2/TestPolicyName: not satisfied (policy: not satisfied [bad signature]))", trace.String())
+
+	managers["1"].config.policies[TestPolicyName] = acceptPolicy{}
+	imp.initialize(&policyConfig{managers: managers})
+
+	trace, err = EvaluateWithTrace(imp, nil)
+	assert.NoError(t, err)
+	assert.True(t, trace.Satisfied)
+	assert.Len(t, trace.SubTraces, 3)
+}
@@ -133,6 +133,7 @@ type Orderer struct {
 	BatchTimeout  time.Duration   `yaml:"BatchTimeout"`
 	BatchSize     BatchSize       `yaml:"BatchSize"`
 	Kafka         Kafka           `yaml:"Kafka"`
+	EtcdRaft      EtcdRaft        `yaml:"EtcdRaft"`
 	Organizations []*Organization `yaml:"Organizations"`
 	MaxChannels   uint64          `yaml:"MaxChannels"`
 }
@@ -149,6 +150,30 @@ type Kafka struct {
 	Brokers []string `yaml:"Brokers"`
 }
 
+// EtcdRaft contains configuration for the etcd/raft-based orderer.
+type EtcdRaft struct {
+	Consenters []*Consenter    `yaml:"Consenters"`
+	Options    EtcdRaftOptions `yaml:"Options"`
+}
+
+// Consenter identifies a consenting node of the etcd/raft-based orderer.
+// The TLS certificates are given as paths to PEM files.
+type Consenter struct {
+	Host          string `yaml:"Host"`
+	Port          uint32 `yaml:"Port"`
+	ClientTLSCert string `yaml:"ClientTLSCert"`
+	ServerTLSCert string `yaml:"ServerTLSCert"`
+}
+
+// EtcdRaftOptions contains the options shared by all the etcd/raft nodes of a channel.
+type EtcdRaftOptions struct {
+	TickInterval         string `yaml:"TickInterval"`
+	ElectionTick         uint32 `yaml:"ElectionTick"`
+	HeartbeatTick        uint32 `yaml:"HeartbeatTick"`
+	MaxInflightBlocks    uint32 `yaml:"MaxInflightBlocks"`
+	SnapshotIntervalSize uint32 `yaml:"SnapshotIntervalSize"`
+}
+
 var genesisDefaults = TopLevel{
 	Orderer: &Orderer{
 		OrdererType:  "solo",
@@ -162,6 +187,15 @@ var genesisDefaults = TopLevel{
 		Kafka: Kafka{
 			Brokers: []string{"127.0.0.1:9092"},
 		},
+		EtcdRaft: EtcdRaft{
+			Options: EtcdRaftOptions{
+				TickInterval:         "500ms",
+				ElectionTick:         10,
+				HeartbeatTick:        1,
+				MaxInflightBlocks:    5,
+				SnapshotIntervalSize: 20 * 1024 * 1024,
+			},
+		},
 	},
 }
 
@@ -236,6 +270,11 @@ func (p *Profile) completeInitialization(configDir string) {
 		return
 	}
 
+	for _, consenter := range p.Orderer.EtcdRaft.Consenters {
+		cf.TranslatePathInPlace(configDir, &consenter.ClientTLSCert)
+		cf.TranslatePathInPlace(configDir, &consenter.ServerTLSCert)
+	}
+
 	for {
 		switch {
 		case p.Orderer.OrdererType == "":
@@ -259,6 +298,21 @@ func (p *Profile) completeInitialization(configDir string) {
 		case p.Orderer.Kafka.Brokers == nil:
 			logger.Infof("Orderer.Kafka.Brokers unset, setting to %v", genesisDefaults.Orderer.Kafka.Brokers)
 			p.Orderer.Kafka.Brokers = genesisDefaults.Orderer.Kafka.Brokers
+		case p.Orderer.EtcdRaft.Options.TickInterval == "":
+			logger.Infof("Orderer.EtcdRaft.Options.TickInterval unset, setting to %s", genesisDefaults.Orderer.EtcdRaft.Options.TickInterval)
+			p.Orderer.EtcdRaft.Options.TickInterval = genesisDefaults.Orderer.EtcdRaft.Options.TickInterval
+		case p.Orderer.EtcdRaft.Options.ElectionTick == 0:
+			logger.Infof("Orderer.EtcdRaft.Options.ElectionTick unset, setting to %v", genesisDefaults.Orderer.EtcdRaft.Options.ElectionTick)
+			p.Orderer.EtcdRaft.Options.ElectionTick = genesisDefaults.Orderer.EtcdRaft.Options.ElectionTick
+		case p.Orderer.EtcdRaft.Options.HeartbeatTick == 0:
+			logger.Infof("Orderer.EtcdRaft.Options.HeartbeatTick unset, setting to %v", genesisDefaults.Orderer.EtcdRaft.Options.HeartbeatTick)
+			p.Orderer.EtcdRaft.Options.HeartbeatTick = genesisDefaults.Orderer.EtcdRaft.Options.HeartbeatTick
+		case p.Orderer.EtcdRaft.Options.MaxInflightBlocks == 0:
+			logger.Infof("Orderer.EtcdRaft.Options.MaxInflightBlocks unset, setting to %v", genesisDefaults.Orderer.EtcdRaft.Options.MaxInflightBlocks)
+			p.Orderer.EtcdRaft.Options.MaxInflightBlocks = genesisDefaults.Orderer.EtcdRaft.Options.MaxInflightBlocks
+		case p.Orderer.EtcdRaft.Options.SnapshotIntervalSize == 0:
+			logger.Infof("Orderer.EtcdRaft.Options.SnapshotIntervalSize unset, setting to %v", genesisDefaults.Orderer.EtcdRaft.Options.SnapshotIntervalSize)
+			p.Orderer.EtcdRaft.Options.SnapshotIntervalSize = genesisDefaults.Orderer.EtcdRaft.Options.SnapshotIntervalSize
 		default:
 			return
 		}
@@ -18,6 +18,7 @@ package provisional
 
 import (
 	"fmt"
+	"io/ioutil"
 
 	"github.com/hyperledger/fabric/common/cauthdsl"
 	"github.com/hyperledger/fabric/common/config/channel"
@@ -31,6 +32,7 @@ import (
 	"github.com/hyperledger/fabric/orderer/common/bootstrap"
 	cb "github.com/hyperledger/fabric/protos/common"
 	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/orderer/etcdraft"
 	pb "github.com/hyperledger/fabric/protos/peer"
 	"github.com/hyperledger/fabric/protos/utils"
 	logging "github.com/op/go-logging"
@@ -65,6 +67,8 @@ const (
 	ConsensusTypeSolo = "solo"
 	// ConsensusTypeKafka identifies the Kafka-based consensus implementation.
 	ConsensusTypeKafka = "kafka"
+	// ConsensusTypeEtcdRaft identifies the etcd/raft-based consensus implementation.
+	ConsensusTypeEtcdRaft = "etcdraft"
 
 	// TestChainID is the default value of ChainID. It is used by all testing
 	// networks. It it necessary to set and export this variable so that test
@@ -106,11 +110,18 @@ func New(conf *genesisconfig.Profile) Generator {
 		oa := config.TemplateOrdererAddresses(conf.Orderer.Addresses)
 		oa.Values[config.OrdererAddressesKey].ModPolicy = OrdererAdminsPolicy
 
+		// The etcd/raft-based orderer keeps its consenters and options in the consensus type metadata
+		consensusType := config.TemplateConsensusType(conf.Orderer.OrdererType)
+		if conf.Orderer.OrdererType == ConsensusTypeEtcdRaft {
+			consensusType = config.TemplateConsensusTypeAndMetadata(conf.Orderer.OrdererType,
+				utils.MarshalOrPanic(etcdRaftMetadata(&conf.Orderer.EtcdRaft)))
+		}
+
 		bs.ordererGroups = []*cb.ConfigGroup{
 			oa,
 
 			// Orderer Config Types
-			config.TemplateConsensusType(conf.Orderer.OrdererType),
+			consensusType,
 			config.TemplateBatchSize(&ab.BatchSize{
 				MaxMessageCount:   conf.Orderer.BatchSize.MaxMessageCount,
 				AbsoluteMaxBytes:  conf.Orderer.BatchSize.AbsoluteMaxBytes,
@@ -142,6 +153,7 @@ func New(conf *genesisconfig.Profile) Generator {
 		case ConsensusTypeSolo:
 		case ConsensusTypeKafka:
 			bs.ordererGroups = append(bs.ordererGroups, config.TemplateKafkaBrokers(conf.Orderer.Kafka.Brokers))
+		case ConsensusTypeEtcdRaft:
 		default:
 			panic(fmt.Errorf("Wrong consenter type value given: %s", conf.Orderer.OrdererType))
 		}
@@ -227,6 +239,40 @@ func New(conf *genesisconfig.Profile) Generator {
 }
 
 // ChannelTemplate TODO
+// etcdRaftMetadata builds the consensus type metadata of the etcd/raft-based orderer,
+// reading the TLS certificates of the consenters from their files
+func etcdRaftMetadata(conf *genesisconfig.EtcdRaft) *etcdraft.Metadata {
+	if len(conf.Consenters) == 0 {
+		logger.Panicf("Orderer.EtcdRaft.Consenters must list at least one consenter")
+	}
+	metadata := &etcdraft.Metadata{
+		Options: &etcdraft.Options{
+			TickInterval:         conf.Options.TickInterval,
+			ElectionTick:         conf.Options.ElectionTick,
+			HeartbeatTick:        conf.Options.HeartbeatTick,
+			MaxInflightBlocks:    conf.Options.MaxInflightBlocks,
+			SnapshotIntervalSize: conf.Options.SnapshotIntervalSize,
+		},
+	}
+	for _, consenter := range conf.Consenters {
+		clientTLSCert, err := ioutil.ReadFile(consenter.ClientTLSCert)
+		if err != nil {
+			logger.Panicf("Error loading client TLS certificate of consenter %s:%d: %s", consenter.Host, consenter.Port, err)
+		}
+		serverTLSCert, err := ioutil.ReadFile(consenter.ServerTLSCert)
+		if err != nil {
+			logger.Panicf("Error loading server TLS certificate of consenter %s:%d: %s", consenter.Host, consenter.Port, err)
+		}
+		metadata.Consenters = append(metadata.Consenters, &etcdraft.Consenter{
+			Host:          consenter.Host,
+			Port:          consenter.Port,
+			ClientTlsCert: clientTLSCert,
+			ServerTlsCert: serverTLSCert,
+		})
+	}
+	return metadata
+}
+
 func (bs *bootstrapper) ChannelTemplate() configtx.Template {
 	return configtx.NewModPolicySettingTemplate(
 		configvaluesmsp.AdminsPolicyKey,
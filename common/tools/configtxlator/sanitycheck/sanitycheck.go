@@ -77,6 +77,44 @@ func Check(config *cb.Config) (*Messages, error) {
 	return result, nil
 }
 
+// principalMSPIDs returns the MSP IDs the principal refers to,
+// looking into the principals of combined principals
+func principalMSPIDs(identity *mspprotos.MSPPrincipal) ([]string, error) {
+	switch identity.PrincipalClassification {
+	case mspprotos.MSPPrincipal_ROLE:
+		role := &mspprotos.MSPRole{}
+		err := proto.Unmarshal(identity.Principal, role)
+		if err != nil {
+			return nil, fmt.Errorf("is of type ROLE, but could not be unmarshaled to msp.MSPRole: %s", err)
+		}
+		return []string{role.MspIdentifier}, nil
+	case mspprotos.MSPPrincipal_ORGANIZATION_UNIT:
+		ou := &mspprotos.OrganizationUnit{}
+		err := proto.Unmarshal(identity.Principal, ou)
+		if err != nil {
+			return nil, fmt.Errorf("is of type ORGANIZATION_UNIT, but could not be unmarshaled to msp.OrganizationUnit: %s", err)
+		}
+		return []string{ou.MspIdentifier}, nil
+	case mspprotos.MSPPrincipal_COMBINED:
+		combined := &mspprotos.CombinedPrincipal{}
+		err := proto.Unmarshal(identity.Principal, combined)
+		if err != nil {
+			return nil, fmt.Errorf("is of type COMBINED, but could not be unmarshaled to msp.CombinedPrincipal: %s", err)
+		}
+		var mspIDs []string
+		for _, principal := range combined.Principals {
+			ids, err := principalMSPIDs(principal)
+			if err != nil {
+				return nil, err
+			}
+			mspIDs = append(mspIDs, ids...)
+		}
+		return mspIDs, nil
+	default:
+		return nil, nil
+	}
+}
+
 func checkPolicyPrincipals(group *cb.ConfigGroup, basePath string, mspMap map[string]struct{}) (warnings []*ElementMessage, errors []*ElementMessage) {
 	for policyName, configPolicy := range group.Policies {
 		appendError := func(err string) {
@@ -109,31 +147,17 @@ func checkPolicyPrincipals(group *cb.ConfigGroup, basePath string, mspMap map[st
 		}
 
 		for i, identity := range spe.Identities {
-			var mspID string
-			switch identity.PrincipalClassification {
-			case mspprotos.MSPPrincipal_ROLE:
-				role := &mspprotos.MSPRole{}
-				err = proto.Unmarshal(identity.Principal, role)
-				if err != nil {
-					appendError(fmt.Sprintf("value of identities array at index %d is of type ROLE, but could not be unmarshaled to msp.MSPRole: %s", i, err))
-					continue
-				}
-				mspID = role.MspIdentifier
-			case mspprotos.MSPPrincipal_ORGANIZATION_UNIT:
-				ou := &mspprotos.OrganizationUnit{}
-				err = proto.Unmarshal(identity.Principal, ou)
-				if err != nil {
-					appendError(fmt.Sprintf("value of identities array at index %d is of type ORGANIZATION_UNIT, but could not be unmarshaled to msp.OrganizationUnit: %s", i, err))
-					continue
-				}
-				mspID = ou.MspIdentifier
-			default:
+			mspIDs, err := principalMSPIDs(identity)
+			if err != nil {
+				appendError(fmt.Sprintf("value of identities array at index %d %s", i, err))
 				continue
 			}
 
-			_, ok := mspMap[mspID]
-			if !ok {
-				appendWarning(fmt.Sprintf("identity principal at index %d refers to MSP ID '%s', which is not an MSP in the network", i, mspID))
+			for _, mspID := range mspIDs {
+				_, ok := mspMap[mspID]
+				if !ok {
+					appendWarning(fmt.Sprintf("identity principal at index %d refers to MSP ID '%s', which is not an MSP in the network", i, mspID))
+				}
 			}
 		}
 	}
@@ -0,0 +1,448 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// stateapidesc generates a machine-readable description of the gossip state transfer
+// message exchange, for peer implementations that need to interoperate with it.
+// The messages and their fields are read from the generated protos/gossip Go code,
+// and the interfaces from the gossip/state package, along with their comments.
+// The description is kept in gossip/state/state_transfer_api.json, and is
+// regenerated with go generate in the gossip/state package.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	descriptionVersion = 1
+	gossipProtoFile    = "protos/gossip/message.pb.go"
+	stateDir           = "gossip/state"
+	gossipMessageType  = "GossipMessage"
+)
+
+// exchange describes a state transfer message exchange by the GossipMessage content fields it uses.
+// Which content fields make up an exchange, and how the opaque bytes fields are encoded, is not
+// expressed in the code, so it is recorded here
+type exchange struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Request     string            `json:"request"`
+	Response    string            `json:"response,omitempty"`
+	Encodings   map[string]string `json:"encodings,omitempty"`
+}
+
+var exchanges = []exchange{
+	{
+		Name:        "BlockPush",
+		Description: "The leader peer of an organization pushes the blocks it receives from the ordering service to the other peers of the channel.",
+		Request:     "data_msg",
+		Encodings: map[string]string{
+			"Payload.data": "marshaled common.Block",
+		},
+	},
+	{
+		Name: "BlockRange",
+		Description: "A peer that is missing blocks asks a peer of the channel for the blocks in the range [start_seq_num, end_seq_num]. " +
+			"The response copies the nonce of the request, and holds the blocks the remote peer has in that range, along with their private data.",
+		Request:  "state_request",
+		Response: "state_response",
+		Encodings: map[string]string{
+			"Payload.data":           "marshaled common.Block",
+			"Payload.private_data":   "one marshaled gossip.PvtDataPayload per transaction with private data",
+			"PvtDataPayload.payload": "marshaled rwset.TxPvtReadWriteSet",
+		},
+	},
+	{
+		Name: "StateDiff",
+		Description: "A peer asks a peer of its own organization for the state key-value pairs written after its checkpoint height, " +
+			"a page at a time. The first page is asked at height 0, the next ones at the height of the first page, from the position " +
+			"following the entries received. The response copies the nonce of the request, and every page carries the checksum over " +
+			"all the entries, signed by the responding peer.",
+		Request:  "state_diff_request",
+		Response: "state_diff_response",
+	},
+	{
+		Name: "BootstrapPush",
+		Description: "A peer in sync with the channel offers a peer joining the channel far behind it to push it the blocks up to the offered height. " +
+			"The joining peer accepts a single offer at a time, with the height the blocks are to be pushed from, and then sends a " +
+			"state_bootstrap_accept with its ledger height for every batch of blocks it commits. The offering peer pushes the blocks as " +
+			"state_response messages carrying the nonce of the offer, up to a window of blocks past the height last acknowledged.",
+		Request:  "state_bootstrap_offer",
+		Response: "state_bootstrap_accept",
+	},
+}
+
+// extraMessages are messages that are not referenced by the fields of the exchanged messages,
+// as they are carried encoded in bytes fields
+var extraMessages = []string{"PvtDataPayload"}
+
+type description struct {
+	Version     int        `json:"version"`
+	Envelope    envelope   `json:"envelope"`
+	Exchanges   []exchange `json:"exchanges"`
+	Messages    []*message `json:"messages"`
+	Interfaces  []*iface   `json:"interfaces"`
+	SourceFiles []string   `json:"source_files"`
+}
+
+type envelope struct {
+	Message string   `json:"message"`
+	Fields  []*field `json:"fields"`
+	Content []*field `json:"content"`
+}
+
+type message struct {
+	Name    string   `json:"name"`
+	Comment string   `json:"comment,omitempty"`
+	Fields  []*field `json:"fields"`
+}
+
+type field struct {
+	Name     string `json:"name"`
+	Number   int    `json:"number"`
+	Type     string `json:"type"`
+	Repeated bool   `json:"repeated,omitempty"`
+	Comment  string `json:"comment,omitempty"`
+	// goType is the Go type of the field, from which the message types it references are found
+	goType string
+}
+
+type iface struct {
+	Name    string    `json:"name"`
+	Comment string    `json:"comment,omitempty"`
+	Methods []*method `json:"methods"`
+}
+
+type method struct {
+	Name      string `json:"name"`
+	Signature string `json:"signature"`
+	Comment   string `json:"comment,omitempty"`
+}
+
+func main() {
+	root := flag.String("root", ".", "the root directory of the fabric source tree")
+	output := flag.String("output", "", "the file to write the description to, standard output if empty")
+	flag.Parse()
+
+	desc, err := describe(*root)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error generating state transfer API description: %s\n", err)
+		os.Exit(1)
+	}
+	b, err := marshalDescription(desc)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error marshaling state transfer API description: %s\n", err)
+		os.Exit(1)
+	}
+	if *output == "" {
+		os.Stdout.Write(b)
+		return
+	}
+	if err := ioutil.WriteFile(*output, b, 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing state transfer API description: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+func marshalDescription(desc *description) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	// Keep the Go signatures, like channel types, readable
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(desc); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// describe builds the description from the fabric source tree at the given root
+func describe(root string) (*description, error) {
+	fset := token.NewFileSet()
+	protoFile, err := parser.ParseFile(fset, filepath.Join(root, gossipProtoFile), nil, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	structs := structTypes(protoFile)
+
+	gossipMsg, ok := structs[gossipMessageType]
+	if !ok {
+		return nil, fmt.Errorf("message %s not found in %s", gossipMessageType, gossipProtoFile)
+	}
+	env := envelope{Message: "gossip." + gossipMessageType}
+	for _, f := range gossipMsg.Fields.List {
+		if f.Tag == nil || !strings.Contains(f.Tag.Value, "protobuf:") {
+			continue
+		}
+		fld, err := newField(fset, f)
+		if err != nil {
+			return nil, err
+		}
+		env.Fields = append(env.Fields, fld)
+	}
+
+	// The content fields of the exchanges are the single fields of the oneof wrapper types
+	contentFields := make(map[string]*field)
+	for name, st := range structs {
+		if !strings.HasPrefix(name, gossipMessageType+"_") || st.Fields.NumFields() != 1 {
+			continue
+		}
+		f := st.Fields.List[0]
+		if f.Tag == nil || !strings.Contains(f.Tag.Value, ",oneof") {
+			continue
+		}
+		fld, err := newField(fset, f)
+		if err != nil {
+			return nil, err
+		}
+		contentFields[fld.Name] = fld
+	}
+
+	var messageNames []string
+	for _, ex := range exchanges {
+		for _, name := range []string{ex.Request, ex.Response} {
+			if name == "" {
+				continue
+			}
+			fld, ok := contentFields[name]
+			if !ok {
+				return nil, fmt.Errorf("content field %s of exchange %s not found in %s", name, ex.Name, gossipMessageType)
+			}
+			env.Content = append(env.Content, fld)
+			messageNames = append(messageNames, strings.TrimPrefix(fld.goType, "*"))
+		}
+	}
+	sort.Slice(env.Content, func(i, j int) bool { return env.Content[i].Number < env.Content[j].Number })
+
+	messages, err := describeMessages(fset, structs, append(messageNames, extraMessages...))
+	if err != nil {
+		return nil, err
+	}
+
+	interfaces, stateFiles, err := describeInterfaces(filepath.Join(root, stateDir))
+	if err != nil {
+		return nil, err
+	}
+
+	return &description{
+		Version:     descriptionVersion,
+		Envelope:    env,
+		Exchanges:   exchanges,
+		Messages:    messages,
+		Interfaces:  interfaces,
+		SourceFiles: append([]string{gossipProtoFile}, stateFiles...),
+	}, nil
+}
+
+// describeMessages describes the given messages and the messages their fields reference, sorted by name
+func describeMessages(fset *token.FileSet, structs map[string]*structType, names []string) ([]*message, error) {
+	described := make(map[string]*message)
+	for len(names) > 0 {
+		name := names[0]
+		names = names[1:]
+		if _, ok := described[name]; ok {
+			continue
+		}
+		st, ok := structs[name]
+		if !ok {
+			return nil, fmt.Errorf("message %s not found in %s", name, gossipProtoFile)
+		}
+		msg := &message{Name: name, Comment: st.doc}
+		for _, f := range st.Fields.List {
+			if f.Tag == nil || !strings.Contains(f.Tag.Value, "protobuf:") {
+				continue
+			}
+			fld, err := newField(fset, f)
+			if err != nil {
+				return nil, fmt.Errorf("message %s: %s", name, err)
+			}
+			msg.Fields = append(msg.Fields, fld)
+			if strings.HasPrefix(strings.TrimPrefix(fld.goType, "[]"), "*") {
+				names = append(names, fld.Type)
+			}
+		}
+		described[name] = msg
+	}
+
+	messages := make([]*message, 0, len(described))
+	for _, msg := range described {
+		messages = append(messages, msg)
+	}
+	sort.Slice(messages, func(i, j int) bool { return messages[i].Name < messages[j].Name })
+	return messages, nil
+}
+
+// structType is a struct type declaration along with its doc comment
+type structType struct {
+	*ast.StructType
+	doc string
+}
+
+func structTypes(file *ast.File) map[string]*structType {
+	structs := make(map[string]*structType)
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			ts := spec.(*ast.TypeSpec)
+			st, ok := ts.Type.(*ast.StructType)
+			if !ok {
+				continue
+			}
+			structs[ts.Name.Name] = &structType{st, comment(gen.Doc)}
+		}
+	}
+	return structs
+}
+
+// newField describes a struct field of a generated message from its protobuf struct tag,
+// which has the form "<wire type>,<number>,<opt|rep>,name=<name>[,json=<name>][,enum=<type>][,oneof]"
+func newField(fset *token.FileSet, f *ast.Field) (*field, error) {
+	tag, err := strconv.Unquote(f.Tag.Value)
+	if err != nil {
+		return nil, err
+	}
+	protoTag := strings.Split(reflectTag(tag, "protobuf"), ",")
+	if len(protoTag) < 4 {
+		return nil, fmt.Errorf("malformed protobuf tag %s", tag)
+	}
+	number, err := strconv.Atoi(protoTag[1])
+	if err != nil {
+		return nil, fmt.Errorf("malformed field number in protobuf tag %s", tag)
+	}
+	fld := &field{
+		Number:   number,
+		Repeated: protoTag[2] == "rep",
+		Comment:  comment(f.Doc),
+		goType:   nodeString(fset, f.Type),
+	}
+	var enum string
+	for _, attr := range protoTag[3:] {
+		switch {
+		case strings.HasPrefix(attr, "name="):
+			fld.Name = strings.TrimPrefix(attr, "name=")
+		case strings.HasPrefix(attr, "enum="):
+			enum = strings.TrimPrefix(attr, "enum=")
+		}
+	}
+	fld.Type = protoType(fld.goType, enum)
+	return fld, nil
+}
+
+// protoType returns the protobuf type of a field given its Go type, or the message name
+// for message fields, which is also the name of the Go type of the message
+func protoType(goType string, enum string) string {
+	if enum != "" {
+		return enum
+	}
+	elemType := strings.TrimPrefix(goType, "[]")
+	if goType == "[]byte" || elemType == "[]byte" {
+		return "bytes"
+	}
+	switch elemType {
+	case "bool", "string", "int32", "int64", "uint32", "uint64", "float32", "float64":
+		return elemType
+	}
+	return strings.TrimPrefix(elemType, "*")
+}
+
+func reflectTag(tag string, key string) string {
+	for _, kv := range strings.Fields(tag) {
+		if strings.HasPrefix(kv, key+":") {
+			v, err := strconv.Unquote(strings.TrimPrefix(kv, key+":"))
+			if err == nil {
+				return v
+			}
+		}
+	}
+	return ""
+}
+
+// describeInterfaces describes the exported interfaces declared in the non-test
+// Go files of the given directory, and returns them sorted by name along with the files
+func describeInterfaces(dir string) ([]*iface, []string, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(fi os.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}, parser.ParseComments)
+	if err != nil {
+		return nil, nil, err
+	}
+	var interfaces []*iface
+	var files []string
+	for _, pkg := range pkgs {
+		for fileName, file := range pkg.Files {
+			files = append(files, filepath.ToSlash(filepath.Join(stateDir, filepath.Base(fileName))))
+			for _, decl := range file.Decls {
+				gen, ok := decl.(*ast.GenDecl)
+				if !ok || gen.Tok != token.TYPE {
+					continue
+				}
+				for _, spec := range gen.Specs {
+					ts := spec.(*ast.TypeSpec)
+					it, ok := ts.Type.(*ast.InterfaceType)
+					if !ok || !ts.Name.IsExported() {
+						continue
+					}
+					doc := ts.Doc
+					if doc == nil {
+						doc = gen.Doc
+					}
+					interfaces = append(interfaces, newInterface(fset, ts.Name.Name, comment(doc), it))
+				}
+			}
+		}
+	}
+	sort.Slice(interfaces, func(i, j int) bool { return interfaces[i].Name < interfaces[j].Name })
+	sort.Strings(files)
+	return interfaces, files, nil
+}
+
+func newInterface(fset *token.FileSet, name string, doc string, it *ast.InterfaceType) *iface {
+	i := &iface{Name: name, Comment: doc}
+	for _, m := range it.Methods.List {
+		signature := nodeString(fset, m.Type)
+		if len(m.Names) == 0 {
+			// An embedded interface
+			i.Methods = append(i.Methods, &method{Name: signature, Signature: "embedded", Comment: comment(m.Doc)})
+			continue
+		}
+		for _, n := range m.Names {
+			i.Methods = append(i.Methods, &method{Name: n.Name, Signature: signature, Comment: comment(m.Doc)})
+		}
+	}
+	return i
+}
+
+func nodeString(fset *token.FileSet, node ast.Node) string {
+	var buf bytes.Buffer
+	printer.Fprint(&buf, fset, node)
+	return buf.String()
+}
+
+// comment returns the text of the given comment group on a single line,
+// so that the description does not change with the wrapping of the comments
+func comment(cg *ast.CommentGroup) string {
+	if cg == nil {
+		return ""
+	}
+	return strings.Join(strings.Fields(cg.Text()), " ")
+}
@@ -0,0 +1,81 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const fabricRoot = "../../.."
+
+func TestDescriptionUpToDate(t *testing.T) {
+	desc, err := describe(fabricRoot)
+	assert.NoError(t, err)
+	b, err := marshalDescription(desc)
+	assert.NoError(t, err)
+	committed, err := ioutil.ReadFile(filepath.Join(fabricRoot, stateDir, "state_transfer_api.json"))
+	assert.NoError(t, err)
+	assert.Equal(t, string(committed), string(b),
+		"gossip/state/state_transfer_api.json is out of date, run go generate in gossip/state")
+}
+
+func TestDescribe(t *testing.T) {
+	desc, err := describe(fabricRoot)
+	assert.NoError(t, err)
+
+	var contents []string
+	for _, f := range desc.Envelope.Content {
+		contents = append(contents, f.Name)
+	}
+	assert.Equal(t, []string{"data_msg", "state_request", "state_response", "state_diff_request", "state_diff_response",
+		"state_bootstrap_offer", "state_bootstrap_accept"}, contents)
+
+	messages := make(map[string]*message)
+	for _, msg := range desc.Messages {
+		messages[msg.Name] = msg
+	}
+	// Payload is referenced by RemoteStateResponse, and PvtDataPayload is carried in its private data
+	for _, name := range []string{"DataMessage", "Payload", "PvtDataPayload", "RemoteStateRequest", "RemoteStateResponse",
+		"StateDiffRequest", "StateDiffResponse", "StateDiffEntry", "StateBootstrapOffer", "StateBootstrapAccept"} {
+		assert.Contains(t, messages, name)
+	}
+	assert.Len(t, messages, 10)
+	assert.Equal(t, &field{Name: "payloads", Number: 1, Type: "Payload", Repeated: true, goType: "[]*Payload"},
+		messages["RemoteStateResponse"].Fields[0])
+	assert.Equal(t, "checksum is the SHA256 hash over the channel, the checkpoint, the height and all the entries of the state differences, the same for all the pages",
+		messages["StateDiffResponse"].Fields[3].Comment)
+
+	var provider *iface
+	for _, i := range desc.Interfaces {
+		if i.Name == "GossipStateProvider" {
+			provider = i
+		}
+	}
+	assert.NotNil(t, provider)
+	assert.Equal(t, &method{Name: "GetBlock", Signature: "func(index uint64) *common.Block",
+		Comment: "Retrieve block with sequence number equal to index"}, provider.Methods[0])
+}
+
+func TestDescribeMissingSources(t *testing.T) {
+	_, err := describe(os.TempDir())
+	assert.Error(t, err)
+}
+
+func TestProtoType(t *testing.T) {
+	assert.Equal(t, "bytes", protoType("[]byte", ""))
+	assert.Equal(t, "bytes", protoType("[][]byte", ""))
+	assert.Equal(t, "uint64", protoType("uint64", ""))
+	assert.Equal(t, "string", protoType("[]string", ""))
+	assert.Equal(t, "Payload", protoType("*Payload", ""))
+	assert.Equal(t, "Payload", protoType("[]*Payload", ""))
+	assert.Equal(t, "gossip.GossipMessage_Tag", protoType("GossipMessage_Tag", "gossip.GossipMessage_Tag"))
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/hyperledger/fabric/common/flogging"
 	"github.com/hyperledger/fabric/core/ledger"
 	"github.com/hyperledger/fabric/core/ledger/customtx"
+	"github.com/hyperledger/fabric/core/policy"
 	"github.com/hyperledger/fabric/protos/common"
 )
 
@@ -20,7 +21,8 @@ var aclLogger = flogging.MustGetLogger("aclmgmt")
 
 //fabric resources used for ACL checks. Note that some of the checks
 //such as LSCC_INSTALL are "peer wide" (current access checks in peer are
-//based on local MSP). These are not currently covered by RSCC or defaultProvider
+//based on local MSP). These are not covered by RSCC, the defaultProvider maps
+//them to a policy of the local MSP
 const (
 	PROPOSE = "PROPOSE"
 
@@ -40,6 +42,8 @@ const (
 	QSCC_GetBlockByHash     = "QSCC.GetBlockByHash"
 	QSCC_GetTransactionByID = "QSCC.GetTransactionByID"
 	QSCC_GetBlockByTxID     = "QSCC.GetBlockByTxID"
+	QSCC_GetBlocksByRange   = "QSCC.GetBlocksByRange"
+	QSCC_GetTxsByCreator    = "QSCC.GetTransactionsByCreator"
 
 	//CSCC resources
 	CSCC_JoinChain      = "CSCC.JoinChain"
@@ -122,3 +126,9 @@ func GetACLProvider() ACLProvider {
 func NewDefaultACLProvider() ACLProvider {
 	return newDefaultACLProvider()
 }
+
+//NewDefaultACLProviderWithPolicyChecker constructs a new default provider evaluating
+//the policies of the resources with the given policy checker
+func NewDefaultACLProviderWithPolicyChecker(policyChecker policy.PolicyChecker) ACLProvider {
+	return newDefaultACLProviderWithPolicyChecker(policyChecker)
+}
@@ -12,6 +12,7 @@ import (
 	"testing"
 
 	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/msp/mgmt"
 	"github.com/hyperledger/fabric/protos/common"
 	pb "github.com/hyperledger/fabric/protos/peer"
 	"github.com/stretchr/testify/assert"
@@ -29,6 +30,27 @@ func (e *mockACLProvider) GenerateSimulationResults(txEnvelop *common.Envelope,
 	return nil
 }
 
+//mockPolicyChecker records the policies it is asked to check
+type mockPolicyChecker struct {
+	channelID  string
+	policyName string
+	noChannel  bool
+}
+
+func (m *mockPolicyChecker) CheckPolicy(channelID, policyName string, signedProp *pb.SignedProposal) error {
+	m.channelID, m.policyName, m.noChannel = channelID, policyName, false
+	return nil
+}
+
+func (m *mockPolicyChecker) CheckPolicyBySignedData(channelID, policyName string, sd []*common.SignedData) error {
+	return fmt.Errorf("unexpected call")
+}
+
+func (m *mockPolicyChecker) CheckPolicyNoChannel(policyName string, signedProp *pb.SignedProposal) error {
+	m.channelID, m.policyName, m.noChannel = "", policyName, true
+	return nil
+}
+
 //treat each test as an independent isolated one
 func reinit() {
 	aclProvider = nil
@@ -93,3 +115,31 @@ func TestBadACL(t *testing.T) {
 	err := GetACLProvider().CheckACL(PROPOSE, "somechain", &pb.SignedProposal{})
 	assert.Error(t, err, "Expected error")
 }
+
+func TestDefaultProviderPolicies(t *testing.T) {
+	pc := &mockPolicyChecker{}
+	provider := NewDefaultACLProviderWithPolicyChecker(pc)
+
+	//channel resources are checked against a policy of the channel
+	assert.NoError(t, provider.CheckACL(QSCC_GetBlockByNumber, "somechain", &pb.SignedProposal{}))
+	assert.Equal(t, &mockPolicyChecker{channelID: "somechain", policyName: CHANNELREADERS}, pc)
+
+	assert.NoError(t, provider.CheckACL(CSCC_GetConfigBlock, "somechain", &pb.SignedProposal{}))
+	assert.Equal(t, &mockPolicyChecker{channelID: "somechain", policyName: CHANNELREADERS}, pc)
+
+	assert.NoError(t, provider.CheckACL(PROPOSE, "somechain", &pb.SignedProposal{}))
+	assert.Equal(t, &mockPolicyChecker{channelID: "somechain", policyName: CHANNELWRITERS}, pc)
+
+	//peer wide resources are checked against a policy of the local MSP
+	assert.NoError(t, provider.CheckACL(LSCC_INSTALL, "", &pb.SignedProposal{}))
+	assert.Equal(t, &mockPolicyChecker{policyName: mgmt.Admins, noChannel: true}, pc)
+
+	assert.NoError(t, provider.CheckACL(CSCC_JoinChain, "", &pb.SignedProposal{}))
+	assert.Equal(t, &mockPolicyChecker{policyName: mgmt.Admins, noChannel: true}, pc)
+
+	assert.NoError(t, provider.CheckACL(CSCC_GetChannels, "", &pb.SignedProposal{}))
+	assert.Equal(t, &mockPolicyChecker{policyName: mgmt.Members, noChannel: true}, pc)
+
+	//deploy and upgrade are covered by the proposal ACL
+	assert.Error(t, provider.CheckACL(LSCC_DEPLOY, "somechain", &pb.SignedProposal{}))
+}
@@ -27,7 +27,7 @@ const (
 type defaultACLProvider struct {
 	policyChecker policy.PolicyChecker
 
-	//peer wide policy (currently not used)
+	//peer wide policy, checked against the local MSP
 	pResourcePolicyMap map[string]string
 
 	//channel specific policy
@@ -35,27 +35,29 @@ type defaultACLProvider struct {
 }
 
 func newDefaultACLProvider() ACLProvider {
-	d := &defaultACLProvider{}
+	return newDefaultACLProviderWithPolicyChecker(policy.NewPolicyChecker(
+		peer.NewChannelPolicyManagerGetter(),
+		mgmt.GetLocalMSP(),
+		mgmt.NewLocalMSPPrincipalGetter(),
+	))
+}
+
+func newDefaultACLProviderWithPolicyChecker(policyChecker policy.PolicyChecker) ACLProvider {
+	d := &defaultACLProvider{policyChecker: policyChecker}
 	d.initialize()
 
 	return d
 }
 
 func (d *defaultACLProvider) initialize() {
-	d.policyChecker = policy.NewPolicyChecker(
-		peer.NewChannelPolicyManagerGetter(),
-		mgmt.GetLocalMSP(),
-		mgmt.NewLocalMSPPrincipalGetter(),
-	)
-
 	d.pResourcePolicyMap = make(map[string]string)
 	d.cResourcePolicyMap = make(map[string]string)
 
 	//-------------- LSCC --------------
-	//p resources (implemented by the chaincode currently)
-	d.pResourcePolicyMap[LSCC_INSTALL] = ""
-	d.pResourcePolicyMap[LSCC_GETCHAINCODES] = ""
-	d.pResourcePolicyMap[LSCC_GETINSTALLEDCHAINCODES] = ""
+	//p resources
+	d.pResourcePolicyMap[LSCC_INSTALL] = mgmt.Admins
+	d.pResourcePolicyMap[LSCC_GETCHAINCODES] = mgmt.Admins
+	d.pResourcePolicyMap[LSCC_GETINSTALLEDCHAINCODES] = mgmt.Admins
 
 	//c resources
 	d.cResourcePolicyMap[LSCC_DEPLOY] = ""  //ACL check covered by PROPOSAL
@@ -73,14 +75,16 @@ func (d *defaultACLProvider) initialize() {
 	d.cResourcePolicyMap[QSCC_GetBlockByHash] = CHANNELREADERS
 	d.cResourcePolicyMap[QSCC_GetTransactionByID] = CHANNELREADERS
 	d.cResourcePolicyMap[QSCC_GetBlockByTxID] = CHANNELREADERS
+	d.cResourcePolicyMap[QSCC_GetBlocksByRange] = CHANNELREADERS
+	d.cResourcePolicyMap[QSCC_GetTxsByCreator] = CHANNELREADERS
 
 	//--------------- CSCC resources -----------
-	//p resources (implemented by the chaincode currently)
-	d.pResourcePolicyMap[CSCC_JoinChain] = ""
-	d.cResourcePolicyMap[CSCC_GetChannels] = ""
+	//p resources
+	d.pResourcePolicyMap[CSCC_JoinChain] = mgmt.Admins
+	d.pResourcePolicyMap[CSCC_GetChannels] = mgmt.Members
 
 	//c resources
-	d.pResourcePolicyMap[CSCC_GetConfigBlock] = CHANNELREADERS
+	d.cResourcePolicyMap[CSCC_GetConfigBlock] = CHANNELREADERS
 
 	//---------------- non-scc resources ------------
 	//Propose
@@ -105,9 +109,15 @@ func (d *defaultACLProvider) defaultPolicy(resName string, cprovider bool) strin
 	return pol
 }
 
-//CheckACL provides default (v 1.0) behavior by mapping resources to their ACL for a channel
+//CheckACL provides default (v 1.0) behavior by mapping resources to their ACL for a channel.
+//Peer wide resources are mapped to a policy of the local MSP, whatever the channel
 func (d *defaultACLProvider) CheckACL(resName string, channelID string, idinfo interface{}) error {
+	cprovider := true
 	policy := d.defaultPolicy(resName, true)
+	if policy == "" {
+		cprovider = false
+		policy = d.defaultPolicy(resName, false)
+	}
 	if policy == "" {
 		aclLogger.Errorf("Unmapped policy for %s", resName)
 		return fmt.Errorf("Unmapped policy for %s", resName)
@@ -115,6 +125,9 @@ func (d *defaultACLProvider) CheckACL(resName string, channelID string, idinfo i
 
 	switch idinfo.(type) {
 	case *pb.SignedProposal:
+		if !cprovider {
+			return d.policyChecker.CheckPolicyNoChannel(policy, idinfo.(*pb.SignedProposal))
+		}
 		return d.policyChecker.CheckPolicy(channelID, policy, idinfo.(*pb.SignedProposal))
 	default:
 		aclLogger.Errorf("Unmapped id on checkACL %s", resName)
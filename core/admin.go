@@ -19,6 +19,7 @@ package core
 import (
 	"github.com/golang/protobuf/ptypes/empty"
 	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/msp/mgmt"
 	pb "github.com/hyperledger/fabric/protos/peer"
 	"golang.org/x/net/context"
 )
@@ -71,3 +72,11 @@ func (*ServerAdmin) RevertLogLevels(context.Context, *empty.Empty) (*empty.Empty
 
 	return &empty.Empty{}, err
 }
+
+// RefreshLocalMsp reloads the local MSP folder so that updated CRLs and
+// intermediate certificates take effect without restarting the peer
+func (*ServerAdmin) RefreshLocalMsp(context.Context, *empty.Empty) (*empty.Empty, error) {
+	err := mgmt.RefreshLocalMsp()
+
+	return &empty.Empty{}, err
+}
@@ -23,6 +23,7 @@ import (
 	"github.com/golang/protobuf/ptypes/empty"
 	"github.com/hyperledger/fabric/common/flogging"
 	"github.com/hyperledger/fabric/core/testutil"
+	"github.com/hyperledger/fabric/msp/mgmt"
 	pb "github.com/hyperledger/fabric/protos/peer"
 	"github.com/stretchr/testify/assert"
 )
@@ -68,3 +69,14 @@ func TestLoggingCalls(t *testing.T) {
 	assert.Equal(t, flogging.DefaultLevel(), logResponse.LogLevel, "log level should have been the default")
 	assert.Nil(t, err, "Error should have been nil")
 }
+
+func TestRefreshLocalMsp(t *testing.T) {
+	response, err := adminServer.RefreshLocalMsp(context.Background(), &empty.Empty{})
+	assert.NotNil(t, response, "Response should have been set")
+	assert.Error(t, err, "Refreshing a local MSP which was not loaded from a folder should fail")
+
+	assert.NoError(t, mgmt.LoadDevMsp())
+	response, err = adminServer.RefreshLocalMsp(context.Background(), &empty.Empty{})
+	assert.NotNil(t, response, "Response should have been set")
+	assert.Nil(t, err, "Error should have been nil")
+}
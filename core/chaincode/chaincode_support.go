@@ -17,6 +17,7 @@ limitations under the License.
 package chaincode
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"path/filepath"
@@ -99,6 +100,10 @@ type runningChaincodes struct {
 	//mark the starting of launch of a chaincode so multiple requests
 	//do not attempt to start the chaincode at the same time
 	launchStarted map[string]bool
+
+	//chaincode containers launched by the peer, restarted if they terminate
+	//unexpectedly
+	launched map[string]*launchedChaincode
 }
 
 //GetChain returns the chaincode framework support object
@@ -138,7 +143,7 @@ func NewChaincodeSupport(getCCEndpoint func() (*pb.PeerEndpoint, error), userrun
 	pnid := viper.GetString("peer.networkId")
 	pid := viper.GetString("peer.id")
 
-	theChaincodeSupport = &ChaincodeSupport{runningChaincodes: &runningChaincodes{chaincodeMap: make(map[string]*chaincodeRTEnv), launchStarted: make(map[string]bool)}, peerNetworkID: pnid, peerID: pid}
+	theChaincodeSupport = &ChaincodeSupport{runningChaincodes: &runningChaincodes{chaincodeMap: make(map[string]*chaincodeRTEnv), launchStarted: make(map[string]bool), launched: make(map[string]*launchedChaincode)}, peerNetworkID: pnid, peerID: pid}
 
 	//initialize global chain
 
@@ -158,6 +163,7 @@ func NewChaincodeSupport(getCCEndpoint func() (*pb.PeerEndpoint, error), userrun
 	theChaincodeSupport.userRunsCC = userrunsCC
 
 	theChaincodeSupport.ccStartupTimeout = ccstartuptimeout
+	theChaincodeSupport.monitor = newMonitorConfig()
 
 	theChaincodeSupport.peerTLS = viper.GetBool("peer.tls.enabled")
 	if theChaincodeSupport.peerTLS {
@@ -239,6 +245,7 @@ type ChaincodeSupport struct {
 	shimLogLevel      string
 	logFormat         string
 	executetimeout    time.Duration
+	monitor           monitorConfig
 	userRunsCC        bool
 	peerTLS           bool
 }
@@ -486,6 +493,7 @@ func (chaincodeSupport *ChaincodeSupport) launchAndWaitForRegister(ctxt context.
 			err = resp.(container.VMCResp).Err
 		}
 		err = fmt.Errorf("Error starting container: %s", err)
+		emitChaincodeEvent(canName, launchFailureEvent)
 		chaincodeSupport.runningChaincodes.Lock()
 		delete(chaincodeSupport.runningChaincodes.chaincodeMap, canName)
 		chaincodeSupport.runningChaincodes.Unlock()
@@ -493,22 +501,28 @@ func (chaincodeSupport *ChaincodeSupport) launchAndWaitForRegister(ctxt context.
 	}
 
 	//wait for REGISTER state
+	timeout := chaincodeSupport.startupTimeout(cccid.Name)
 	select {
 	case ok := <-notfy:
 		if !ok {
 			err = fmt.Errorf("registration failed for %s(networkid:%s,peerid:%s,tx:%s)", canName, chaincodeSupport.peerNetworkID, chaincodeSupport.peerID, cccid.TxID)
+			emitChaincodeEvent(canName, launchFailureEvent)
 		}
-	case <-time.After(chaincodeSupport.ccStartupTimeout):
-		err = fmt.Errorf("Timeout expired while starting chaincode %s(networkid:%s,peerid:%s,tx:%s)", canName, chaincodeSupport.peerNetworkID, chaincodeSupport.peerID, cccid.TxID)
+	case <-time.After(timeout):
+		err = fmt.Errorf("Timeout expired after %s while starting chaincode %s(networkid:%s,peerid:%s,tx:%s)", timeout, canName, chaincodeSupport.peerNetworkID, chaincodeSupport.peerID, cccid.TxID)
+		emitChaincodeEvent(canName, launchTimeoutEvent)
 	}
 	if err != nil {
 		chaincodeLogger.Debugf("stopping due to error while launching %s", err)
-		errIgnore := chaincodeSupport.Stop(ctxt, cccid, cds)
+		errIgnore := chaincodeSupport.stopContainer(ctxt, cccid, cds)
 		if errIgnore != nil {
 			chaincodeLogger.Debugf("error on stop %s(%s)", errIgnore, err)
 		}
+		return err
 	}
-	return err
+
+	chaincodeSupport.chaincodeLaunched(cccid, cds, builder)
+	return nil
 }
 
 //Stop stops a chaincode if running
@@ -518,6 +532,16 @@ func (chaincodeSupport *ChaincodeSupport) Stop(context context.Context, cccid *c
 		return fmt.Errorf("chaincode name not set")
 	}
 
+	//the chaincode is not restarted once its container is stopped
+	chaincodeSupport.forgetLaunched(canName, nil)
+
+	return chaincodeSupport.stopContainer(context, cccid, cds)
+}
+
+//stopContainer stops the container of a chaincode and removes its handler
+func (chaincodeSupport *ChaincodeSupport) stopContainer(context context.Context, cccid *ccprovider.CCContext, cds *pb.ChaincodeDeploymentSpec) error {
+	canName := cccid.GetCanonicalName()
+
 	//stop the chaincode
 	sir := container.StopImageReq{CCID: ccintf.CCID{ChaincodeSpec: cds.ChaincodeSpec, NetworkID: chaincodeSupport.peerNetworkID, PeerID: chaincodeSupport.peerID, Version: cccid.Version}, Timeout: 0}
 	// The line below is left for debugging. It replaces the line above to keep
@@ -658,6 +682,10 @@ func (chaincodeSupport *ChaincodeSupport) Launch(context context.Context, cccid
 		}
 
 		builder := func() (io.Reader, error) { return platforms.GenerateDockerBuild(cds) }
+		if cds.ExecEnv == pb.ChaincodeDeploymentSpec_EXTERNAL {
+			//the code package of an external chaincode is its connection definition
+			builder = func() (io.Reader, error) { return bytes.NewReader(cds.CodePackage), nil }
+		}
 
 		cLang := cds.ChaincodeSpec.Type
 		err = chaincodeSupport.launchAndWaitForRegister(context, cccid, cds, cLang, builder)
@@ -669,7 +697,7 @@ func (chaincodeSupport *ChaincodeSupport) Launch(context context.Context, cccid
 
 	if err == nil {
 		//launch will set the chaincode in Ready state
-		err = chaincodeSupport.sendReady(context, cccid, chaincodeSupport.ccStartupTimeout)
+		err = chaincodeSupport.sendReady(context, cccid, chaincodeSupport.startupTimeout(cccid.Name))
 		if err != nil {
 			chaincodeLogger.Errorf("sending init failed(%s)", err)
 			err = fmt.Errorf("Failed to init chaincode(%s)", err)
@@ -689,8 +717,11 @@ func (chaincodeSupport *ChaincodeSupport) Launch(context context.Context, cccid
 //getVMType - just returns a string for now. Another possibility is to use a factory method to
 //return a VM executor
 func (chaincodeSupport *ChaincodeSupport) getVMType(cds *pb.ChaincodeDeploymentSpec) (string, error) {
-	if cds.ExecEnv == pb.ChaincodeDeploymentSpec_SYSTEM {
+	switch cds.ExecEnv {
+	case pb.ChaincodeDeploymentSpec_SYSTEM:
 		return container.SYSTEM, nil
+	case pb.ChaincodeDeploymentSpec_EXTERNAL:
+		return container.EXTERNAL, nil
 	}
 	return container.DOCKER, nil
 }
@@ -474,6 +474,43 @@ func getQueryStateByRange(t *testing.T, chainID, ccname string, ccSide *mockpeer
 	return nil
 }
 
+func getQueryStateByPartialCompositeKeyWithPagination(t *testing.T, chainID, ccname string, ccSide *mockpeer.MockCCComm) error {
+	done := setuperror()
+
+	errorFunc := func(ind int, err error) {
+		done <- err
+	}
+
+	chaincodeID := &pb.ChaincodeID{Name: ccname, Version: "0"}
+	ci := &pb.ChaincodeInput{[][]byte{[]byte("invoke"), []byte("A"), []byte("B"), []byte("10")}, nil}
+	cis := &pb.ChaincodeInvocationSpec{ChaincodeSpec: &pb.ChaincodeSpec{Type: pb.ChaincodeSpec_Type(pb.ChaincodeSpec_Type_value["GOLANG"]), ChaincodeId: chaincodeID, Input: ci}}
+	txid := util.GenerateUUID()
+	ctxt, txsim, sprop, prop := startTx(t, chainID, cis, txid)
+
+	//the whole page is expected in the response, along with its metadata
+	pageRequest := &pb.GetStateByPartialCompositeKeyWithPagination{ObjectType: "marble", PageSize: 2}
+	checkPageFunc := func(reqMsg *pb.ChaincodeMessage) *pb.ChaincodeMessage {
+		qr := &pb.QueryResponse{}
+		metadata := &pb.QueryResponseMetadata{}
+		if proto.Unmarshal(reqMsg.Payload, qr) != nil || qr.HasMore || proto.Unmarshal(qr.Metadata, metadata) != nil ||
+			metadata.FetchedRecordsCount != int32(len(qr.Results)) {
+			return &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_COMPLETED, Payload: putils.MarshalOrPanic(&pb.Response{Status: shim.ERROR, Message: "unexpected page"}), Txid: txid}
+		}
+		return &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_COMPLETED, Payload: putils.MarshalOrPanic(&pb.Response{Status: shim.OK, Payload: []byte("OK")}), Txid: txid}
+	}
+
+	respSet := &mockpeer.MockResponseSet{errorFunc, nil, []*mockpeer.MockResponse{
+		&mockpeer.MockResponse{&pb.ChaincodeMessage{Type: pb.ChaincodeMessage_TRANSACTION}, &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_GET_STATE_BY_PARTIAL_COMPOSITE_KEY_WITH_PAGINATION, Payload: putils.MarshalOrPanic(pageRequest), Txid: txid}},
+		&mockpeer.MockResponse{&pb.ChaincodeMessage{Type: pb.ChaincodeMessage_RESPONSE}, checkPageFunc}}}
+
+	cccid := ccprovider.NewCCContext(chainID, ccname, "0", txid, false, sprop, prop)
+	execCC(t, ctxt, ccSide, cccid, false, false, done, cis, respSet)
+
+	endTx(t, cccid, txsim, cis)
+
+	return nil
+}
+
 func cc2cc(t *testing.T, chainID, chainID2, ccname string, ccSide *mockpeer.MockCCComm) error {
 	calledCC := "calledCC"
 	//starts and registers the CC
@@ -673,6 +710,9 @@ func TestCCFramework(t *testing.T) {
 	//call's query state range
 	getQueryStateByRange(t, chainID, ccname, ccSide)
 
+	//call's paginated partial composite key query
+	getQueryStateByPartialCompositeKeyWithPagination(t, chainID, ccname, ccSide)
+
 	//call's cc2cc (variation with syscc calls)
 	cc2cc(t, chainID, chainID2, ccname, ccSide)
 
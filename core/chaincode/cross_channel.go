@@ -0,0 +1,83 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaincode
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric/core/ledger"
+)
+
+// readOnlyTxSimulator is the TxSimulator of a chaincode invoked by a chaincode of another
+// channel. The transaction is only committed to the channel of the calling chaincode, so
+// the called chaincode may read the state of its channel but any write to it is rejected
+type readOnlyTxSimulator struct {
+	ledger.QueryExecutor
+	calledChainID string
+	callerChainID string
+}
+
+func newReadOnlyTxSimulator(qe ledger.QueryExecutor, calledChainID, callerChainID string) *readOnlyTxSimulator {
+	return &readOnlyTxSimulator{QueryExecutor: qe, calledChainID: calledChainID, callerChainID: callerChainID}
+}
+
+func (s *readOnlyTxSimulator) writeError() error {
+	return fmt.Errorf("chaincodes on channel [%s] invoked from channel [%s] are read-only, writes across channels are not allowed",
+		s.calledChainID, s.callerChainID)
+}
+
+// SetState implements method in interface `ledger.TxSimulator`
+func (s *readOnlyTxSimulator) SetState(namespace string, key string, value []byte) error {
+	return s.writeError()
+}
+
+// DeleteState implements method in interface `ledger.TxSimulator`
+func (s *readOnlyTxSimulator) DeleteState(namespace string, key string) error {
+	return s.writeError()
+}
+
+// SetStateMultipleKeys implements method in interface `ledger.TxSimulator`
+func (s *readOnlyTxSimulator) SetStateMultipleKeys(namespace string, kvs map[string][]byte) error {
+	return s.writeError()
+}
+
+// SetStateMetadata implements method in interface `ledger.TxSimulator`
+func (s *readOnlyTxSimulator) SetStateMetadata(namespace string, key string, metadata map[string][]byte) error {
+	return s.writeError()
+}
+
+// DeleteStateMetadata implements method in interface `ledger.TxSimulator`
+func (s *readOnlyTxSimulator) DeleteStateMetadata(namespace string, key string) error {
+	return s.writeError()
+}
+
+// ExecuteUpdate implements method in interface `ledger.TxSimulator`
+func (s *readOnlyTxSimulator) ExecuteUpdate(query string) error {
+	return s.writeError()
+}
+
+// SetPrivateData implements method in interface `ledger.TxSimulator`
+func (s *readOnlyTxSimulator) SetPrivateData(namespace, collection, key string, value []byte) error {
+	return s.writeError()
+}
+
+// SetPrivateDataMultipleKeys implements method in interface `ledger.TxSimulator`
+func (s *readOnlyTxSimulator) SetPrivateDataMultipleKeys(namespace, collection string, kvs map[string][]byte) error {
+	return s.writeError()
+}
+
+// DeletePrivateData implements method in interface `ledger.TxSimulator`
+func (s *readOnlyTxSimulator) DeletePrivateData(namespace, collection, key string) error {
+	return s.writeError()
+}
+
+// GetTxSimulationResults implements method in interface `ledger.TxSimulator`.
+// The simulation of the called chaincode has no results of its own
+func (s *readOnlyTxSimulator) GetTxSimulationResults() (*ledger.TxSimulationResults, error) {
+	return nil, fmt.Errorf("the simulation of the chaincodes on channel [%s] invoked from channel [%s] has no results",
+		s.calledChainID, s.callerChainID)
+}
@@ -0,0 +1,38 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaincode
+
+import (
+	"testing"
+
+	mocklgr "github.com/hyperledger/fabric/common/mocks/ledger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadOnlyTxSimulator(t *testing.T) {
+	qe := mocklgr.NewMockQueryExecutor(map[string]map[string][]byte{"mycc": {"key1": []byte("value1")}})
+	txsim := newReadOnlyTxSimulator(qe, "channel1", "channel2")
+
+	// the state of the called channel can be read
+	val, err := txsim.GetState("mycc", "key1")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("value1"), val)
+
+	// but not written to
+	expectedErr := "chaincodes on channel [channel1] invoked from channel [channel2] are read-only, writes across channels are not allowed"
+	assert.EqualError(t, txsim.SetState("mycc", "key1", []byte("value2")), expectedErr)
+	assert.EqualError(t, txsim.DeleteState("mycc", "key1"), expectedErr)
+	assert.EqualError(t, txsim.SetStateMultipleKeys("mycc", map[string][]byte{"key1": nil}), expectedErr)
+	assert.EqualError(t, txsim.SetStateMetadata("mycc", "key1", nil), expectedErr)
+	assert.EqualError(t, txsim.DeleteStateMetadata("mycc", "key1"), expectedErr)
+	assert.EqualError(t, txsim.ExecuteUpdate("update"), expectedErr)
+	assert.EqualError(t, txsim.SetPrivateData("mycc", "coll1", "key1", []byte("value2")), expectedErr)
+	assert.EqualError(t, txsim.SetPrivateDataMultipleKeys("mycc", "coll1", map[string][]byte{"key1": nil}), expectedErr)
+	assert.EqualError(t, txsim.DeletePrivateData("mycc", "coll1", "key1"), expectedErr)
+	_, err = txsim.GetTxSimulationResults()
+	assert.Error(t, err)
+}
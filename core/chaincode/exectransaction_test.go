@@ -761,14 +761,37 @@ func runChaincodeInvokeChaincode(t *testing.T, channel1 string, channel2 string,
 		return nextBlockNumber1, nextBlockNumber2
 	}
 
-	// as Alice, invoke chaincode2 on channel2 so that it invokes chaincode1 on channel1
+	// as Alice, invoke chaincode2 on channel2 so that it invokes chaincode1 on channel1,
+	// which fails since chaincode1 writes to channel1
 	_, _, _, err = invoke(ctxt, channel2, chaincode2InvokeSpec, nextBlockNumber2, []byte("Alice"))
+	if err == nil {
+		// the chaincodes invoked across channels are read-only
+		stopChaincode(ctxt, cccid1)
+		stopChaincode(ctxt, cccid2)
+		stopChaincode(ctxt, cccid3)
+		nextBlockNumber2++
+		t.Fatalf("As Alice, invoking <%s/%s> via <%s/%s> should fail as it writes across channels, but it succeeded.", cccid1.Name, cccid1.ChainID, chaincode2Name, channel2)
+		return nextBlockNumber1, nextBlockNumber2
+	}
+
+	// as Alice, query chaincode1 on channel1 via chaincode2 on channel2
+	chaincode2QuerySpec := &pb.ChaincodeSpec{
+		Type: chaincode2Type,
+		ChaincodeId: &pb.ChaincodeID{
+			Name:    chaincode2Name,
+			Version: chaincode2Version,
+		},
+		Input: &pb.ChaincodeInput{
+			Args: util.ToChaincodeArgs("query", "e", cccid1.Name, "a", channel1),
+		},
+	}
+	_, _, _, err = invoke(ctxt, channel2, chaincode2QuerySpec, nextBlockNumber2, []byte("Alice"))
 	if err != nil {
 		// Alice should be able to call
 		stopChaincode(ctxt, cccid1)
 		stopChaincode(ctxt, cccid2)
 		stopChaincode(ctxt, cccid3)
-		t.Fatalf("As Alice, invoking <%s/%s> via <%s/%s> should should of succeeded, but it failed: %s", cccid1.Name, cccid1.ChainID, chaincode2Name, channel2, err)
+		t.Fatalf("As Alice, querying <%s/%s> via <%s/%s> should should of succeeded, but it failed: %s", cccid1.Name, cccid1.ChainID, chaincode2Name, channel2, err)
 		return nextBlockNumber1, nextBlockNumber2
 	}
 	nextBlockNumber2++
@@ -1268,9 +1291,9 @@ func TestQueries(t *testing.T) {
 		}
 
 		//Reset the query limit to 5
-		viper.Set("ledger.state.queryLimit", 5)
+		viper.Set("ledger.state.totalQueryLimit", 5)
 
-		//The following range query for "marble01" to "marble11" should return 5 marbles due to the queryLimit
+		//The following range query for "marble01" to "marble11" should return 5 marbles due to the totalQueryLimit
 		f = "keys"
 		args = util.ToChaincodeArgs(f, "marble001", "marble011")
 
@@ -1296,7 +1319,7 @@ func TestQueries(t *testing.T) {
 		}
 
 		//Reset the query limit to 10000
-		viper.Set("ledger.state.queryLimit", 10000)
+		viper.Set("ledger.state.totalQueryLimit", 10000)
 
 		//The following rich query for should return 50 marbles
 		f = "query"
@@ -1326,9 +1349,9 @@ func TestQueries(t *testing.T) {
 		}
 
 		//Reset the query limit to 5
-		viper.Set("ledger.state.queryLimit", 5)
+		viper.Set("ledger.state.totalQueryLimit", 5)
 
-		//The following rich query should return 5 marbles due to the queryLimit
+		//The following rich query should return 5 marbles due to the totalQueryLimit
 		f = "query"
 		args = util.ToChaincodeArgs(f, "{\"selector\":{\"owner\":\"jerry\"}}")
 
@@ -214,6 +214,20 @@ func (handler *Handler) deleteTxContext(txid string) {
 	}
 }
 
+// failPendingTransactions notifies the transactions waiting for a response of the
+// chaincode with an error, rather than leaving them wait until they time out
+func (handler *Handler) failPendingTransactions(reason string) {
+	handler.Lock()
+	defer handler.Unlock()
+	for txid, txctx := range handler.txCtxs {
+		select {
+		case txctx.responseNotifier <- &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_ERROR, Payload: []byte(reason), Txid: txid}:
+		default:
+			//a response is already pending
+		}
+	}
+}
+
 func (handler *Handler) putQueryIterator(txContext *transactionContext, txid string,
 	queryIterator commonledger.ResultsIterator) {
 	handler.Lock()
@@ -301,6 +315,9 @@ func (handler *Handler) processStream() error {
 
 	//catch send errors and bail now that sends aren't synchronous
 	errc := make(chan error, 1)
+
+	//health check of the chaincode, which answers the keepalives
+	lastReceived := time.Now()
 	for {
 		in = nil
 		err = nil
@@ -340,6 +357,7 @@ func (handler *Handler) processStream() error {
 
 			// we can spin off another Recv again
 			recv = true
+			lastReceived = time.Now()
 
 			if in.Type == pb.ChaincodeMessage_KEEPALIVE {
 				chaincodeLogger.Debug("Received KEEPALIVE Response")
@@ -361,6 +379,13 @@ func (handler *Handler) processStream() error {
 				continue
 			}
 
+			if handler.chaincodeSupport.unresponsive(lastReceived) {
+				hcErr := fmt.Errorf("no message received from chaincode %s since %s, ending chaincode support stream", handler.ChaincodeID.GetName(), lastReceived)
+				chaincodeLogger.Errorf("Health check failed: %s", hcErr)
+				emitChaincodeEvent(handler.ChaincodeID.GetName(), healthCheckFailureEvent)
+				return hcErr
+			}
+
 			//if no error message from serialSend, KEEPALIVE happy, and don't care about error
 			//(maybe it'll work later)
 			handler.serialSendAsync(&pb.ChaincodeMessage{Type: pb.ChaincodeMessage_KEEPALIVE}, nil)
@@ -396,7 +421,11 @@ func HandleChaincodeStream(chaincodeSupport *ChaincodeSupport, ctxt context.Cont
 	deadline, ok := ctxt.Deadline()
 	chaincodeLogger.Debugf("Current context deadline = %s, ok = %v", deadline, ok)
 	handler := newChaincodeSupportHandler(chaincodeSupport, stream)
-	return handler.processStream()
+	err := handler.processStream()
+	if handler.registered {
+		chaincodeSupport.chaincodeTerminated(handler, err)
+	}
+	return err
 }
 
 func newChaincodeSupportHandler(chaincodeSupport *ChaincodeSupport, peerChatStream ccintf.ChaincodeStream) *Handler {
@@ -419,6 +448,7 @@ func newChaincodeSupportHandler(chaincodeSupport *ChaincodeSupport, peerChatStre
 			{Name: pb.ChaincodeMessage_COMPLETED.String(), Src: []string{readystate}, Dst: readystate},
 			{Name: pb.ChaincodeMessage_GET_STATE.String(), Src: []string{readystate}, Dst: readystate},
 			{Name: pb.ChaincodeMessage_GET_STATE_BY_RANGE.String(), Src: []string{readystate}, Dst: readystate},
+			{Name: pb.ChaincodeMessage_GET_STATE_BY_PARTIAL_COMPOSITE_KEY_WITH_PAGINATION.String(), Src: []string{readystate}, Dst: readystate},
 			{Name: pb.ChaincodeMessage_GET_QUERY_RESULT.String(), Src: []string{readystate}, Dst: readystate},
 			{Name: pb.ChaincodeMessage_GET_HISTORY_FOR_KEY.String(), Src: []string{readystate}, Dst: readystate},
 			{Name: pb.ChaincodeMessage_QUERY_STATE_NEXT.String(), Src: []string{readystate}, Dst: readystate},
@@ -443,6 +473,9 @@ func newChaincodeSupportHandler(chaincodeSupport *ChaincodeSupport, peerChatStre
 			"enter_" + establishedstate:                                 func(e *fsm.Event) { v.enterEstablishedState(e, v.FSM.Current()) },
 			"enter_" + readystate:                                       func(e *fsm.Event) { v.enterReadyState(e, v.FSM.Current()) },
 			"enter_" + endstate:                                         func(e *fsm.Event) { v.enterEndState(e, v.FSM.Current()) },
+			"after_" + pb.ChaincodeMessage_GET_STATE_BY_PARTIAL_COMPOSITE_KEY_WITH_PAGINATION.String(): func(e *fsm.Event) {
+				v.afterGetStateByPartialCompositeKeyWithPagination(e, v.FSM.Current())
+			},
 		},
 	)
 
@@ -751,6 +784,117 @@ func (handler *Handler) handleGetStateByRange(msg *pb.ChaincodeMessage) {
 	}()
 }
 
+// afterGetStateByPartialCompositeKeyWithPagination handles a GET_STATE_BY_PARTIAL_COMPOSITE_KEY_WITH_PAGINATION
+// request from the chaincode.
+func (handler *Handler) afterGetStateByPartialCompositeKeyWithPagination(e *fsm.Event, state string) {
+	msg, ok := e.Args[0].(*pb.ChaincodeMessage)
+	if !ok {
+		e.Cancel(fmt.Errorf("Received unexpected message type"))
+		return
+	}
+	chaincodeLogger.Debugf("Received %s, invoking get state from ledger", pb.ChaincodeMessage_GET_STATE_BY_PARTIAL_COMPOSITE_KEY_WITH_PAGINATION)
+
+	// Query ledger for a page of state
+	handler.handleGetStateByPartialCompositeKeyWithPagination(msg)
+	chaincodeLogger.Debug("Exiting GET_STATE_BY_PARTIAL_COMPOSITE_KEY_WITH_PAGINATION")
+}
+
+// Handles query to ledger for a page of the keys of a partial composite key. The whole page is
+// sent back in the response, along with the bookmark of the next page in the response metadata
+func (handler *Handler) handleGetStateByPartialCompositeKeyWithPagination(msg *pb.ChaincodeMessage) {
+	// The defer followed by triggering a go routine dance is needed to ensure that the previous state transition
+	// is completed before the next one is triggered. The previous state transition is deemed complete only when
+	// the afterGetStateByPartialCompositeKeyWithPagination function is exited.
+	go func() {
+		// Check if this is the unique state request from this chaincode txid
+		uniqueReq := handler.createTXIDEntry(msg.Txid)
+		if !uniqueReq {
+			// Drop this request
+			chaincodeLogger.Error("Another state request pending for this Txid. Cannot process.")
+			return
+		}
+
+		var serialSendMsg *pb.ChaincodeMessage
+
+		defer func() {
+			handler.deleteTXIDEntry(msg.Txid)
+			chaincodeLogger.Debugf("[%s]handleGetStateByPartialCompositeKeyWithPagination serial send %s", shorttxid(serialSendMsg.Txid), serialSendMsg.Type)
+			handler.serialSendAsync(serialSendMsg, nil)
+		}()
+
+		errHandler := func(payload []byte, errFmt string, errArgs ...interface{}) {
+			chaincodeLogger.Errorf(errFmt, errArgs...)
+			serialSendMsg = &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_ERROR, Payload: payload, Txid: msg.Txid}
+		}
+
+		pageRequest := &pb.GetStateByPartialCompositeKeyWithPagination{}
+		unmarshalErr := proto.Unmarshal(msg.Payload, pageRequest)
+		if unmarshalErr != nil {
+			errHandler([]byte(unmarshalErr.Error()), "Failed to unmarshall paginated query request. Sending %s", pb.ChaincodeMessage_ERROR)
+			return
+		}
+
+		var txContext *transactionContext
+		txContext, serialSendMsg = handler.isValidTxSim(msg.Txid, "[%s]No ledger context for GetStateByPartialCompositeKeyWithPagination. Sending %s", shorttxid(msg.Txid), pb.ChaincodeMessage_ERROR)
+		if txContext == nil {
+			return
+		}
+		chaincodeID := handler.getCCRootName()
+
+		pageIter, err := txContext.txsimulator.GetStateByPartialCompositeKeyWithPagination(chaincodeID,
+			pageRequest.ObjectType, pageRequest.Attributes, pageRequest.PageSize, pageRequest.Bookmark)
+		if err != nil {
+			errHandler([]byte(err.Error()), "Failed to get ledger page iterator. Sending %s", pb.ChaincodeMessage_ERROR)
+			return
+		}
+
+		payload, err := getPageQueryResponse(pageIter)
+		if err != nil {
+			errHandler([]byte(err.Error()), "Failed to get query result. Sending %s", pb.ChaincodeMessage_ERROR)
+			return
+		}
+
+		payloadBytes, err := proto.Marshal(payload)
+		if err != nil {
+			errHandler([]byte(err.Error()), "Failed to marshal response. Sending %s", pb.ChaincodeMessage_ERROR)
+			return
+		}
+		chaincodeLogger.Debugf("Got a page of keys and values. Sending %s", pb.ChaincodeMessage_RESPONSE)
+		serialSendMsg = &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_RESPONSE, Payload: payloadBytes, Txid: msg.Txid}
+	}()
+}
+
+// getPageQueryResponse reads the whole page of the given iterator, which it closes, and constructs a
+// QueryResponse with no more results, whose metadata holds the bookmark at which the next page begins
+func getPageQueryResponse(iter ledger.QueryResultsIterator) (*pb.QueryResponse, error) {
+	var queryResultsBytes []*pb.QueryResultBytes
+	for {
+		queryResult, err := iter.Next()
+		if err != nil {
+			iter.Close()
+			return nil, err
+		}
+		if queryResult == nil {
+			break
+		}
+		resultBytes, err := proto.Marshal(queryResult.(proto.Message))
+		if err != nil {
+			iter.Close()
+			return nil, err
+		}
+		queryResultsBytes = append(queryResultsBytes, &pb.QueryResultBytes{ResultBytes: resultBytes})
+	}
+
+	metadata, err := proto.Marshal(&pb.QueryResponseMetadata{
+		FetchedRecordsCount: int32(len(queryResultsBytes)),
+		Bookmark:            iter.GetBookmarkAndClose(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &pb.QueryResponse{Results: queryResultsBytes, HasMore: false, Metadata: metadata}, nil
+}
+
 const maxResultLimit = 100
 
 //getQueryResponse takes an iterator and fetch state to construct QueryResponse
@@ -1219,7 +1363,8 @@ func (handler *Handler) enterBusyState(e *fsm.Event, state string) {
 			}
 
 			// Set up a new context for the called chaincode if on a different channel
-			// We grab the called channel's ledger simulator to hold the new state
+			// The transaction is only committed to the caller's channel, so the called
+			// chaincode reads the called channel's ledger and any write to it is rejected
 			ctxt := context.Background()
 			txsim := txContext.txsimulator
 			historyQueryExecutor := txContext.historyQueryExecutor
@@ -1231,14 +1376,23 @@ func (handler *Handler) enterBusyState(e *fsm.Event, state string) {
 						Payload: []byte(payload), Txid: msg.Txid}
 					return
 				}
-				txsim2, err2 := lgr.NewTxSimulator(msg.Txid)
+				qe, err2 := lgr.NewQueryExecutor()
 				if err2 != nil {
 					triggerNextStateMsg = &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_ERROR,
 						Payload: []byte(err2.Error()), Txid: msg.Txid}
 					return
 				}
-				defer txsim2.Done()
-				txsim = txsim2
+				defer qe.Done()
+				txsim = newReadOnlyTxSimulator(qe, calledCcIns.ChainID, txContext.chainID)
+				if historyQueryExecutor != nil {
+					// the history queries are served by the called channel's ledger as well
+					historyQueryExecutor, err2 = lgr.NewHistoryQueryExecutor()
+					if err2 != nil {
+						triggerNextStateMsg = &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_ERROR,
+							Payload: []byte(err2.Error()), Txid: msg.Txid}
+						return
+					}
+				}
 			}
 			ctxt = context.WithValue(ctxt, TXSimulatorKey, txsim)
 			ctxt = context.WithValue(ctxt, HistoryQueryExecutorKey, historyQueryExecutor)
@@ -0,0 +1,247 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaincode
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric/common/metrics"
+	"github.com/hyperledger/fabric/common/util"
+	"github.com/hyperledger/fabric/core/common/ccprovider"
+	"github.com/hyperledger/fabric/core/container/api"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	"github.com/spf13/viper"
+	"golang.org/x/net/context"
+)
+
+const (
+	restartBackoffDefault    = time.Second
+	restartMaxBackoffDefault = time.Minute
+)
+
+// the events of the lifecycle of the chaincode containers counted in the peer metrics
+const (
+	launchTimeoutEvent      = "launch_timeouts"
+	launchFailureEvent      = "launch_failures"
+	healthCheckFailureEvent = "health_check_failures"
+	terminationEvent        = "terminations"
+	restartEvent            = "restarts"
+	restartFailureEvent     = "restart_failures"
+)
+
+var chaincodeMetrics = metrics.NewRootScope().SubScope("chaincode")
+
+// emitChaincodeEvent counts an event of the lifecycle of a chaincode in the peer metrics
+func emitChaincodeEvent(canName, event string) {
+	chaincodeMetrics.Tagged(map[string]string{"chaincode": canName}).Counter(event).Inc(1)
+}
+
+// monitorConfig configures the monitoring of the chaincode containers launched by the peer
+type monitorConfig struct {
+	// missedKeepalives is the number of keepalive intervals without any message
+	// from a chaincode after which its container is considered dead
+	missedKeepalives int
+
+	// restartAttempts is the number of attempts to restart a chaincode container
+	// which terminated unexpectedly, 0 turns the automatic restart off
+	restartAttempts int
+
+	// restartBackoff is the wait before the first restart attempt, doubled after
+	// every failed attempt up to restartMaxBackoff
+	restartBackoff    time.Duration
+	restartMaxBackoff time.Duration
+
+	// startupTimeouts overrides the startup timeout of the chaincodes by name
+	startupTimeouts map[string]time.Duration
+}
+
+func newMonitorConfig() monitorConfig {
+	config := monitorConfig{
+		missedKeepalives:  viper.GetInt("chaincode.missedkeepalives"),
+		restartAttempts:   viper.GetInt("chaincode.restart.maxattempts"),
+		restartBackoff:    viper.GetDuration("chaincode.restart.backoff"),
+		restartMaxBackoff: viper.GetDuration("chaincode.restart.maxbackoff"),
+		startupTimeouts:   make(map[string]time.Duration),
+	}
+	if config.restartBackoff <= 0 {
+		config.restartBackoff = restartBackoffDefault
+	}
+	if config.restartMaxBackoff <= 0 {
+		config.restartMaxBackoff = restartMaxBackoffDefault
+	}
+
+	for name, value := range viper.GetStringMapString("chaincode.startuptimeoutoverrides") {
+		timeout, err := time.ParseDuration(value)
+		if err != nil || timeout <= 0 {
+			chaincodeLogger.Errorf("Invalid startup timeout override %s for chaincode %s, ignoring it", value, name)
+			continue
+		}
+		config.startupTimeouts[strings.ToLower(name)] = timeout
+	}
+	return config
+}
+
+// launchedChaincode records how a chaincode container was launched, so that it can
+// be launched again if it terminates unexpectedly
+type launchedChaincode struct {
+	cccid      *ccprovider.CCContext
+	cds        *pb.ChaincodeDeploymentSpec
+	builder    api.BuildSpecFactory
+	restarting bool
+}
+
+// startupTimeout returns the time the peer waits for a chaincode to start, as overridden
+// for the chaincode if configured so. The names of the chaincodes are matched case
+// insensitively, as the configuration keys are.
+func (chaincodeSupport *ChaincodeSupport) startupTimeout(ccName string) time.Duration {
+	if timeout, ok := chaincodeSupport.monitor.startupTimeouts[strings.ToLower(ccName)]; ok {
+		return timeout
+	}
+	return chaincodeSupport.ccStartupTimeout
+}
+
+// unresponsive returns true if the health check of a chaincode failed, that is, nothing
+// was received from the chaincode during the configured number of keepalive intervals
+func (chaincodeSupport *ChaincodeSupport) unresponsive(lastReceived time.Time) bool {
+	if chaincodeSupport.keepalive <= 0 || chaincodeSupport.monitor.missedKeepalives <= 0 {
+		return false
+	}
+	return time.Since(lastReceived) > time.Duration(chaincodeSupport.monitor.missedKeepalives)*chaincodeSupport.keepalive
+}
+
+// chaincodeLaunched records a chaincode container launched by the peer. System
+// chaincodes run within the peer and are not recorded.
+func (chaincodeSupport *ChaincodeSupport) chaincodeLaunched(cccid *ccprovider.CCContext, cds *pb.ChaincodeDeploymentSpec, builder api.BuildSpecFactory) {
+	if cds.ExecEnv == pb.ChaincodeDeploymentSpec_SYSTEM {
+		return
+	}
+
+	chaincodeSupport.runningChaincodes.Lock()
+	defer chaincodeSupport.runningChaincodes.Unlock()
+	chaincodeSupport.runningChaincodes.launched[cccid.GetCanonicalName()] = &launchedChaincode{cccid: cccid, cds: cds, builder: builder}
+}
+
+// forgetLaunched removes the record of a chaincode container, so that it is not
+// restarted when it terminates. If lc is not nil, the record is removed only if it
+// is still the current one.
+func (chaincodeSupport *ChaincodeSupport) forgetLaunched(canName string, lc *launchedChaincode) {
+	chaincodeSupport.runningChaincodes.Lock()
+	defer chaincodeSupport.runningChaincodes.Unlock()
+	if current, ok := chaincodeSupport.runningChaincodes.launched[canName]; ok && (lc == nil || current == lc) {
+		delete(chaincodeSupport.runningChaincodes.launched, canName)
+	}
+}
+
+// isLaunched returns true if lc is still the current record of the chaincode container
+func (chaincodeSupport *ChaincodeSupport) isLaunched(canName string, lc *launchedChaincode) bool {
+	chaincodeSupport.runningChaincodes.RLock()
+	defer chaincodeSupport.runningChaincodes.RUnlock()
+	return chaincodeSupport.runningChaincodes.launched[canName] == lc
+}
+
+// chaincodeTerminated is called once the stream of a registered chaincode ended. The
+// transactions in progress are failed right away and, unless the chaincode was stopped
+// by the peer, its container is restarted.
+func (chaincodeSupport *ChaincodeSupport) chaincodeTerminated(handler *Handler, cause error) {
+	canName := handler.ChaincodeID.Name
+	handler.failPendingTransactions(fmt.Sprintf("chaincode %s terminated: %s", canName, cause))
+
+	chaincodeSupport.runningChaincodes.Lock()
+	lc, ok := chaincodeSupport.runningChaincodes.launched[canName]
+	if !ok || lc.restarting {
+		chaincodeSupport.runningChaincodes.Unlock()
+		return
+	}
+	lc.restarting = true
+	chaincodeSupport.runningChaincodes.Unlock()
+
+	chaincodeLogger.Errorf("Chaincode %s terminated unexpectedly: %s", canName, cause)
+	emitChaincodeEvent(canName, terminationEvent)
+
+	if chaincodeSupport.monitor.restartAttempts <= 0 {
+		chaincodeSupport.forgetLaunched(canName, lc)
+		return
+	}
+	go chaincodeSupport.restartChaincode(canName, lc)
+}
+
+// restartChaincode launches again a chaincode container which terminated unexpectedly,
+// backing off between the attempts. The chaincode is sent READY by the next transaction
+// launching it, as for any chaincode which has just registered.
+func (chaincodeSupport *ChaincodeSupport) restartChaincode(canName string, lc *launchedChaincode) {
+	backoff := chaincodeSupport.monitor.restartBackoff
+	for attempt := 1; attempt <= chaincodeSupport.monitor.restartAttempts; attempt++ {
+		time.Sleep(backoff)
+
+		// the chaincode was stopped or launched again by a transaction in the meantime
+		if !chaincodeSupport.isLaunched(canName, lc) {
+			chaincodeLogger.Debugf("Chaincode %s no longer needs to be restarted", canName)
+			return
+		}
+
+		cccid := ccprovider.NewCCContext(lc.cccid.ChainID, lc.cccid.Name, lc.cccid.Version, util.GenerateUUID(), false, nil, nil)
+		err := chaincodeSupport.launchAndWaitForRegister(context.Background(), cccid, lc.cds, lc.cds.ChaincodeSpec.Type, lc.builder)
+		if err == nil {
+			chaincodeLogger.Infof("Chaincode %s restarted after %d attempt(s)", canName, attempt)
+			emitChaincodeEvent(canName, restartEvent)
+			return
+		}
+		chaincodeLogger.Warningf("Attempt %d of %d to restart chaincode %s failed: %s", attempt, chaincodeSupport.monitor.restartAttempts, canName, err)
+
+		backoff *= 2
+		if backoff > chaincodeSupport.monitor.restartMaxBackoff {
+			backoff = chaincodeSupport.monitor.restartMaxBackoff
+		}
+	}
+
+	chaincodeLogger.Errorf("Giving up restarting chaincode %s after %d attempts, it is launched again by the next transaction", canName, chaincodeSupport.monitor.restartAttempts)
+	emitChaincodeEvent(canName, restartFailureEvent)
+	chaincodeSupport.forgetLaunched(canName, lc)
+}
+
+// ContainerStatuses returns the status of the chaincodes known to the peer, sorted by
+// their canonical names
+func (chaincodeSupport *ChaincodeSupport) ContainerStatuses() []*pb.ChaincodeContainerStatus {
+	chaincodeSupport.runningChaincodes.RLock()
+	defer chaincodeSupport.runningChaincodes.RUnlock()
+
+	statuses := make(map[string]*pb.ChaincodeContainerStatus)
+	for canName, chrte := range chaincodeSupport.runningChaincodes.chaincodeMap {
+		state := pb.ChaincodeContainerStatus_LAUNCHING
+		if chrte.handler.registered {
+			state = pb.ChaincodeContainerStatus_RUNNING
+		}
+		statuses[canName] = &pb.ChaincodeContainerStatus{CanonicalName: canName, State: state}
+	}
+	for canName := range chaincodeSupport.runningChaincodes.launchStarted {
+		if _, ok := statuses[canName]; !ok {
+			statuses[canName] = &pb.ChaincodeContainerStatus{CanonicalName: canName, State: pb.ChaincodeContainerStatus_LAUNCHING}
+		}
+	}
+	for canName, lc := range chaincodeSupport.runningChaincodes.launched {
+		status, ok := statuses[canName]
+		if !ok {
+			// the container terminated and is not restarted (yet)
+			status = &pb.ChaincodeContainerStatus{CanonicalName: canName, State: pb.ChaincodeContainerStatus_UNKNOWN}
+			statuses[canName] = status
+		}
+		if lc.restarting {
+			status.State = pb.ChaincodeContainerStatus_RESTARTING
+		}
+		status.Container = true
+	}
+
+	result := make([]*pb.ChaincodeContainerStatus, 0, len(statuses))
+	for _, status := range statuses {
+		result = append(result, status)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].CanonicalName < result[j].CanonicalName })
+	return result
+}
@@ -0,0 +1,170 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaincode
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric/core/common/ccprovider"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+)
+
+func newTestChaincodeSupport(monitor monitorConfig) *ChaincodeSupport {
+	return &ChaincodeSupport{
+		runningChaincodes: &runningChaincodes{
+			chaincodeMap:  make(map[string]*chaincodeRTEnv),
+			launchStarted: make(map[string]bool),
+			launched:      make(map[string]*launchedChaincode),
+		},
+		ccStartupTimeout: 5 * time.Second,
+		monitor:          monitor,
+	}
+}
+
+func TestNewMonitorConfig(t *testing.T) {
+	config := newMonitorConfig()
+	assert.Equal(t, restartBackoffDefault, config.restartBackoff)
+	assert.Equal(t, restartMaxBackoffDefault, config.restartMaxBackoff)
+	assert.Empty(t, config.startupTimeouts)
+
+	viper.Set("chaincode.missedkeepalives", 3)
+	viper.Set("chaincode.restart.maxattempts", 5)
+	viper.Set("chaincode.restart.backoff", "2s")
+	viper.Set("chaincode.restart.maxbackoff", "30s")
+	viper.Set("chaincode.startuptimeoutoverrides", map[string]interface{}{"MyCC": "10m", "badcc": "foo"})
+	defer func() {
+		for _, key := range []string{"chaincode.missedkeepalives", "chaincode.restart.maxattempts", "chaincode.restart.backoff",
+			"chaincode.restart.maxbackoff", "chaincode.startuptimeoutoverrides"} {
+			viper.Set(key, nil)
+		}
+	}()
+
+	config = newMonitorConfig()
+	assert.Equal(t, 3, config.missedKeepalives)
+	assert.Equal(t, 5, config.restartAttempts)
+	assert.Equal(t, 2*time.Second, config.restartBackoff)
+	assert.Equal(t, 30*time.Second, config.restartMaxBackoff)
+	assert.Equal(t, map[string]time.Duration{"mycc": 10 * time.Minute}, config.startupTimeouts)
+}
+
+func TestStartupTimeout(t *testing.T) {
+	cs := newTestChaincodeSupport(monitorConfig{startupTimeouts: map[string]time.Duration{"mycc": time.Minute}})
+	assert.Equal(t, time.Minute, cs.startupTimeout("mycc"))
+	assert.Equal(t, time.Minute, cs.startupTimeout("MYCC"))
+	assert.Equal(t, 5*time.Second, cs.startupTimeout("othercc"))
+}
+
+func TestUnresponsive(t *testing.T) {
+	cs := newTestChaincodeSupport(monitorConfig{missedKeepalives: 3})
+	longAgo := time.Now().Add(-time.Hour)
+
+	// keepalive off
+	assert.False(t, cs.unresponsive(longAgo))
+
+	cs.keepalive = time.Second
+	assert.True(t, cs.unresponsive(longAgo))
+	assert.False(t, cs.unresponsive(time.Now().Add(-2*time.Second)))
+
+	// health check off
+	cs.monitor.missedKeepalives = 0
+	assert.False(t, cs.unresponsive(longAgo))
+}
+
+func TestFailPendingTransactions(t *testing.T) {
+	handler := &Handler{txCtxs: make(map[string]*transactionContext)}
+	txctx1, err := handler.createTxContext(context.Background(), "testchainid", "tx1", nil, nil)
+	assert.NoError(t, err)
+	txctx2, err := handler.createTxContext(context.Background(), "testchainid", "tx2", nil, nil)
+	assert.NoError(t, err)
+
+	// the response of tx2 is already there
+	completed := &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_COMPLETED, Txid: "tx2"}
+	txctx2.responseNotifier <- completed
+
+	handler.failPendingTransactions("chaincode mycc:0 terminated")
+	msg := <-txctx1.responseNotifier
+	assert.Equal(t, pb.ChaincodeMessage_ERROR, msg.Type)
+	assert.Equal(t, "tx1", msg.Txid)
+	assert.Equal(t, "chaincode mycc:0 terminated", string(msg.Payload))
+	assert.Equal(t, completed, <-txctx2.responseNotifier)
+}
+
+func TestChaincodeTerminated(t *testing.T) {
+	cs := newTestChaincodeSupport(monitorConfig{})
+	cccid := ccprovider.NewCCContext("testchainid", "mycc", "0", "txid", false, nil, nil)
+	cds := &pb.ChaincodeDeploymentSpec{ChaincodeSpec: &pb.ChaincodeSpec{ChaincodeId: &pb.ChaincodeID{Name: "mycc", Version: "0"}}}
+	handler := &Handler{ChaincodeID: &pb.ChaincodeID{Name: "mycc:0"}, txCtxs: make(map[string]*transactionContext)}
+
+	// system chaincodes are not recorded
+	cs.chaincodeLaunched(cccid, &pb.ChaincodeDeploymentSpec{ExecEnv: pb.ChaincodeDeploymentSpec_SYSTEM}, nil)
+	assert.Empty(t, cs.runningChaincodes.launched)
+
+	// the chaincode is forgotten when it terminates and restart is off
+	cs.chaincodeLaunched(cccid, cds, nil)
+	assert.Len(t, cs.runningChaincodes.launched, 1)
+	cs.chaincodeTerminated(handler, fmt.Errorf("EOF"))
+	assert.Empty(t, cs.runningChaincodes.launched)
+
+	// a chaincode stopped by the peer is not restarted
+	cs.monitor.restartAttempts = 1
+	cs.chaincodeLaunched(cccid, cds, nil)
+	cs.forgetLaunched("mycc:0", nil)
+	cs.chaincodeTerminated(handler, fmt.Errorf("EOF"))
+	assert.Empty(t, cs.runningChaincodes.launched)
+}
+
+func TestRestartChaincodeAbandoned(t *testing.T) {
+	cs := newTestChaincodeSupport(monitorConfig{restartAttempts: 3, restartBackoff: time.Millisecond, restartMaxBackoff: time.Millisecond})
+	cccid := ccprovider.NewCCContext("testchainid", "mycc", "0", "txid", false, nil, nil)
+	cds := &pb.ChaincodeDeploymentSpec{ChaincodeSpec: &pb.ChaincodeSpec{ChaincodeId: &pb.ChaincodeID{Name: "mycc", Version: "0"}}}
+
+	// the chaincode was launched again by a transaction in the meantime
+	cs.chaincodeLaunched(cccid, cds, nil)
+	lc := cs.runningChaincodes.launched["mycc:0"]
+	cs.chaincodeLaunched(cccid, cds, nil)
+
+	done := make(chan struct{})
+	go func() {
+		cs.restartChaincode("mycc:0", lc)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("Timed out waiting for the restart to be abandoned")
+	}
+	assert.False(t, cs.isLaunched("mycc:0", lc))
+	assert.Len(t, cs.runningChaincodes.launched, 1)
+}
+
+func TestContainerStatuses(t *testing.T) {
+	cs := newTestChaincodeSupport(monitorConfig{})
+	assert.Empty(t, cs.ContainerStatuses())
+
+	cds := &pb.ChaincodeDeploymentSpec{ChaincodeSpec: &pb.ChaincodeSpec{ChaincodeId: &pb.ChaincodeID{Name: "mycc", Version: "0"}}}
+	cs.runningChaincodes.chaincodeMap["lscc:1.1"] = &chaincodeRTEnv{handler: &Handler{registered: true}}
+	cs.runningChaincodes.chaincodeMap["mycc:0"] = &chaincodeRTEnv{handler: &Handler{registered: true}}
+	cs.chaincodeLaunched(ccprovider.NewCCContext("testchainid", "mycc", "0", "txid", false, nil, nil), cds, nil)
+	cs.runningChaincodes.launchStarted["othercc:0"] = true
+	cs.runningChaincodes.chaincodeMap["restartcc:0"] = &chaincodeRTEnv{handler: &Handler{}}
+	cs.chaincodeLaunched(ccprovider.NewCCContext("testchainid", "restartcc", "0", "txid", false, nil, nil), cds, nil)
+	cs.runningChaincodes.launched["restartcc:0"].restarting = true
+	cs.chaincodeLaunched(ccprovider.NewCCContext("testchainid", "deadcc", "0", "txid", false, nil, nil), cds, nil)
+
+	assert.Equal(t, []*pb.ChaincodeContainerStatus{
+		{CanonicalName: "deadcc:0", State: pb.ChaincodeContainerStatus_UNKNOWN, Container: true},
+		{CanonicalName: "lscc:1.1", State: pb.ChaincodeContainerStatus_RUNNING},
+		{CanonicalName: "mycc:0", State: pb.ChaincodeContainerStatus_RUNNING, Container: true},
+		{CanonicalName: "othercc:0", State: pb.ChaincodeContainerStatus_LAUNCHING},
+		{CanonicalName: "restartcc:0", State: pb.ChaincodeContainerStatus_RESTARTING, Container: true},
+	}, cs.ContainerStatuses())
+}
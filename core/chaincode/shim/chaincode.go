@@ -545,6 +545,26 @@ func (stub *ChaincodeStub) GetStateByPartialCompositeKey(objectType string, attr
 	}
 }
 
+// GetStateByPartialCompositeKeyWithPagination documentation can be found in interfaces.go
+func (stub *ChaincodeStub) GetStateByPartialCompositeKeyWithPagination(objectType string, attributes []string,
+	pageSize int32, bookmark string) (StateQueryIteratorInterface, *pb.QueryResponseMetadata, error) {
+	if pageSize <= 0 {
+		return nil, nil, errors.New("the page size must be positive")
+	}
+	if _, err := stub.CreateCompositeKey(objectType, attributes); err != nil {
+		return nil, nil, err
+	}
+	response, err := stub.handler.handleGetStateByPartialCompositeKeyWithPagination(objectType, attributes, pageSize, bookmark, stub.TxID)
+	if err != nil {
+		return nil, nil, err
+	}
+	metadata := &pb.QueryResponseMetadata{}
+	if err := proto.Unmarshal(response.Metadata, metadata); err != nil {
+		return nil, nil, fmt.Errorf("failed unmarshalling the query response metadata: %s", err)
+	}
+	return &StateQueryIterator{CommonIterator: &CommonIterator{stub.handler, stub.TxID, response, 0}}, metadata, nil
+}
+
 func (iter *StateQueryIterator) Next() (*queryresult.KV, error) {
 	if result, err := iter.nextResult(STATE_QUERY_RESULT); err == nil {
 		return result.(*queryresult.KV), err
@@ -535,6 +535,55 @@ func (handler *Handler) handleGetStateByRange(startKey, endKey string, txid stri
 	return nil, errors.New(fmt.Sprintf("Incorrect chaincode message %s received. Expecting %s or %s", responseMsg.Type, pb.ChaincodeMessage_RESPONSE, pb.ChaincodeMessage_ERROR))
 }
 
+func (handler *Handler) handleGetStateByPartialCompositeKeyWithPagination(objectType string, attributes []string,
+	pageSize int32, bookmark string, txid string) (*pb.QueryResponse, error) {
+	// Create the channel on which to communicate the response from validating peer
+	var respChan chan pb.ChaincodeMessage
+	var err error
+	if respChan, err = handler.createChannel(txid); err != nil {
+		return nil, err
+	}
+
+	defer handler.deleteChannel(txid)
+
+	// Send GET_STATE_BY_PARTIAL_COMPOSITE_KEY_WITH_PAGINATION message to validator chaincode support
+	//we constructed a valid object. No need to check for error
+	payloadBytes, _ := proto.Marshal(&pb.GetStateByPartialCompositeKeyWithPagination{
+		ObjectType: objectType,
+		Attributes: attributes,
+		PageSize:   pageSize,
+		Bookmark:   bookmark,
+	})
+
+	msg := &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_GET_STATE_BY_PARTIAL_COMPOSITE_KEY_WITH_PAGINATION, Payload: payloadBytes, Txid: txid}
+	chaincodeLogger.Debugf("[%s]Sending %s", shorttxid(msg.Txid), pb.ChaincodeMessage_GET_STATE_BY_PARTIAL_COMPOSITE_KEY_WITH_PAGINATION)
+
+	var responseMsg pb.ChaincodeMessage
+	if responseMsg, err = handler.sendReceive(msg, respChan); err != nil {
+		return nil, errors.New(fmt.Sprintf("[%s]error sending %s", shorttxid(msg.Txid), pb.ChaincodeMessage_GET_STATE_BY_PARTIAL_COMPOSITE_KEY_WITH_PAGINATION))
+	}
+
+	if responseMsg.Type.String() == pb.ChaincodeMessage_RESPONSE.String() {
+		// Success response
+		chaincodeLogger.Debugf("[%s]Received %s. Successfully got page", shorttxid(responseMsg.Txid), pb.ChaincodeMessage_RESPONSE)
+
+		pageQueryResponse := &pb.QueryResponse{}
+		if err = proto.Unmarshal(responseMsg.Payload, pageQueryResponse); err != nil {
+			return nil, errors.New(fmt.Sprintf("[%s]GetStateByPartialCompositeKeyWithPaginationResponse unmarshall error", shorttxid(responseMsg.Txid)))
+		}
+
+		return pageQueryResponse, nil
+	}
+	if responseMsg.Type.String() == pb.ChaincodeMessage_ERROR.String() {
+		// Error response
+		chaincodeLogger.Errorf("[%s]Received %s", shorttxid(responseMsg.Txid), pb.ChaincodeMessage_ERROR)
+		return nil, errors.New(string(responseMsg.Payload[:]))
+	}
+
+	// Incorrect chaincode message received
+	return nil, errors.New(fmt.Sprintf("Incorrect chaincode message %s received. Expecting %s or %s", responseMsg.Type, pb.ChaincodeMessage_RESPONSE, pb.ChaincodeMessage_ERROR))
+}
+
 func (handler *Handler) handleQueryStateNext(id, txid string) (*pb.QueryResponse, error) {
 	// Create the channel on which to communicate the response from validating peer
 	var respChan chan pb.ChaincodeMessage
@@ -123,6 +123,20 @@ type ChaincodeStubInterface interface {
 	// has not changed since transaction endorsement (phantom reads detected).
 	GetStateByPartialCompositeKey(objectType string, keys []string) (StateQueryIteratorInterface, error)
 
+	// GetStateByPartialCompositeKeyWithPagination queries a page of at most
+	// pageSize of the composite keys whose prefix matches the given partial
+	// composite key, in lexical order. The page begins at the given bookmark,
+	// an empty bookmark referring to the first matching key. The returned
+	// metadata holds the number of fetched records and the bookmark at which
+	// the next page begins, which is empty after the last page.
+	// The `objectType` and attributes are subject to the same restrictions as
+	// in GetStateByPartialCompositeKey.
+	// Call Close() on the returned StateQueryIteratorInterface object when done.
+	// The query is re-executed during validation phase to ensure result set
+	// has not changed since transaction endorsement (phantom reads detected).
+	GetStateByPartialCompositeKeyWithPagination(objectType string, keys []string,
+		pageSize int32, bookmark string) (StateQueryIteratorInterface, *pb.QueryResponseMetadata, error)
+
 	// CreateCompositeKey combines the given `attributes` to form a composite
 	// key. The objectType and attributes are expected to have only valid utf8
 	// strings and should not contain U+0000 (nil byte) and U+10FFFF
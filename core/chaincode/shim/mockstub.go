@@ -251,6 +251,45 @@ func (stub *MockStub) GetStateByPartialCompositeKey(objectType string, attribute
 	return NewMockStateRangeQueryIterator(stub, partialCompositeKey, partialCompositeKey+string(maxUnicodeRuneValue)), nil
 }
 
+// GetStateByPartialCompositeKeyWithPagination function can be invoked by a chaincode to
+// query a page of at most pageSize of the composite keys whose prefix matches the given
+// partial composite key, beginning at the given bookmark.
+func (stub *MockStub) GetStateByPartialCompositeKeyWithPagination(objectType string, attributes []string,
+	pageSize int32, bookmark string) (StateQueryIteratorInterface, *pb.QueryResponseMetadata, error) {
+	if pageSize <= 0 {
+		return nil, nil, errors.New("the page size must be positive")
+	}
+	partialCompositeKey, err := stub.CreateCompositeKey(objectType, attributes)
+	if err != nil {
+		return nil, nil, err
+	}
+	startKey := partialCompositeKey
+	if bookmark != "" {
+		if !strings.HasPrefix(bookmark, partialCompositeKey) {
+			return nil, nil, fmt.Errorf("invalid bookmark [%q] for the partial composite key [%q]", bookmark, partialCompositeKey)
+		}
+		startKey = bookmark
+	}
+	endKey := partialCompositeKey + string(maxUnicodeRuneValue)
+
+	// the range of the mock iterator includes its end key, which is therefore the last key of the page
+	lastKey := startKey
+	metadata := &pb.QueryResponseMetadata{}
+	for elem := stub.Keys.Front(); elem != nil; elem = elem.Next() {
+		key := elem.Value.(string)
+		if key < startKey || key >= endKey {
+			continue
+		}
+		if metadata.FetchedRecordsCount == pageSize {
+			metadata.Bookmark = key
+			break
+		}
+		lastKey = key
+		metadata.FetchedRecordsCount++
+	}
+	return NewMockStateRangeQueryIterator(stub, startKey, lastKey), metadata, nil
+}
+
 // CreateCompositeKey combines the list of attributes
 //to form a composite key.
 func (stub *MockStub) CreateCompositeKey(objectType string, attributes []string) (string, error) {
@@ -23,6 +23,7 @@ import (
 	"testing"
 
 	"github.com/hyperledger/fabric/common/flogging"
+	pb "github.com/hyperledger/fabric/protos/peer"
 	"github.com/spf13/viper"
 )
 
@@ -193,6 +194,53 @@ func TestGetStateByPartialCompositeKey(t *testing.T) {
 	}
 }
 
+func TestGetStateByPartialCompositeKeyWithPagination(t *testing.T) {
+	stub := NewMockStub("GetStateByPartialCompositeKeyWithPaginationTest", nil)
+	stub.MockTransactionStart("init")
+	var keys []string
+	for _, name := range []string{"a", "b", "c"} {
+		key, _ := stub.CreateCompositeKey("marble", []string{name})
+		stub.PutState(key, []byte(name))
+		keys = append(keys, key)
+	}
+	otherKey, _ := stub.CreateCompositeKey("marbleOwner", []string{"tom"})
+	stub.PutState(otherKey, []byte("tom"))
+	stub.MockTransactionEnd("init")
+
+	readPage := func(bookmark string) ([]string, *pb.QueryResponseMetadata) {
+		rqi, metadata, err := stub.GetStateByPartialCompositeKeyWithPagination("marble", []string{}, 2, bookmark)
+		if err != nil {
+			t.Fatalf("Failed reading the page at [%q]: %s", bookmark, err)
+		}
+		defer rqi.Close()
+		var page []string
+		for rqi.HasNext() {
+			response, err := rqi.Next()
+			if err != nil {
+				t.Fatalf("Failed reading the page at [%q]: %s", bookmark, err)
+			}
+			page = append(page, response.Key)
+		}
+		return page, metadata
+	}
+
+	page, metadata := readPage("")
+	if !reflect.DeepEqual(keys[:2], page) || metadata.FetchedRecordsCount != 2 || metadata.Bookmark != keys[2] {
+		t.Fatalf("Unexpected first page %q with metadata %v", page, metadata)
+	}
+	page, metadata = readPage(metadata.Bookmark)
+	if !reflect.DeepEqual(keys[2:], page) || metadata.FetchedRecordsCount != 1 || metadata.Bookmark != "" {
+		t.Fatalf("Unexpected last page %q with metadata %v", page, metadata)
+	}
+
+	if _, _, err := stub.GetStateByPartialCompositeKeyWithPagination("marble", []string{}, 2, otherKey); err == nil {
+		t.Fatal("Expected a bookmark of another object type to be rejected")
+	}
+	if _, _, err := stub.GetStateByPartialCompositeKeyWithPagination("marble", []string{}, 0, ""); err == nil {
+		t.Fatal("Expected a non positive page size to be rejected")
+	}
+}
+
 func TestGetStateByPartialCompositeKeyCollision(t *testing.T) {
 	stub := NewMockStub("GetStateByPartialCompositeKeyCollisionTest", nil)
 	stub.MockTransactionStart("init")
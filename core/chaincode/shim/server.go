@@ -0,0 +1,132 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package shim
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+
+	"github.com/hyperledger/fabric/bccsp/factory"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// TLSProperties are the TLS material of a chaincode server, PEM encoded
+type TLSProperties struct {
+	// Key and Cert are the key pair the server authenticates with
+	Key  []byte
+	Cert []byte
+
+	// ClientCACerts are the CA certificates the peers are authenticated
+	// with, if set the peers are required to present a certificate
+	ClientCACerts []byte
+}
+
+// ChaincodeServer runs a chaincode as a server the peers connect to, rather than
+// the chaincode connecting to a peer. It is used with the chaincodes installed
+// with the EXTERNAL execution environment, whose connection definition points
+// to the address of the server.
+type ChaincodeServer struct {
+	// CCID is the canonical name of the chaincode, that is name:version,
+	// the chaincode registers with
+	CCID string
+
+	// Address is the address the server listens on
+	Address string
+
+	// CC is the chaincode served
+	CC Chaincode
+
+	// TLSProps is the TLS material of the server, if nil the server does
+	// not use TLS
+	TLSProps *TLSProperties
+
+	server *grpc.Server
+}
+
+// Start serves the chaincode until Stop is called or the listener fails
+func (cs *ChaincodeServer) Start() error {
+	if cs.CCID == "" {
+		return fmt.Errorf("Error chaincode id not provided")
+	}
+	if cs.Address == "" {
+		return fmt.Errorf("Error chaincode server address not provided")
+	}
+	if cs.CC == nil {
+		return fmt.Errorf("Error chaincode not provided")
+	}
+
+	err := factory.InitFactories(factory.GetDefaultOpts())
+	if err != nil {
+		return fmt.Errorf("Internal error, BCCSP could not be initialized with default options: %s", err)
+	}
+
+	var opts []grpc.ServerOption
+	if cs.TLSProps != nil {
+		tlsConfig, err := cs.TLSProps.serverConfig()
+		if err != nil {
+			return err
+		}
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+
+	listener, err := net.Listen("tcp", cs.Address)
+	if err != nil {
+		return fmt.Errorf("Error listening on %s: %s", cs.Address, err)
+	}
+
+	cs.server = grpc.NewServer(opts...)
+	pb.RegisterChaincodeServer(cs.server, cs)
+	chaincodeLogger.Infof("Chaincode %s serving on %s", cs.CCID, cs.Address)
+	return cs.server.Serve(listener)
+}
+
+// Stop stops the server, ending the streams with the peers
+func (cs *ChaincodeServer) Stop() {
+	if cs.server != nil {
+		cs.server.Stop()
+	}
+}
+
+// Connect registers the chaincode with a peer connecting to the server and
+// serves the transactions of the peer until the stream ends
+func (cs *ChaincodeServer) Connect(stream pb.Chaincode_ConnectServer) error {
+	chaincodeLogger.Debugf("Peer connected to chaincode %s", cs.CCID)
+	return chatWithPeer(cs.CCID, &serverStream{stream}, cs.CC)
+}
+
+// serverStream adapts the stream of a peer connecting to the server to the
+// stream chatWithPeer expects
+type serverStream struct {
+	pb.Chaincode_ConnectServer
+}
+
+// CloseSend is a no-op, the stream is closed once Connect returns
+func (s *serverStream) CloseSend() error {
+	return nil
+}
+
+func (props *TLSProperties) serverConfig() (*tls.Config, error) {
+	cert, err := tls.X509KeyPair(props.Cert, props.Key)
+	if err != nil {
+		return nil, fmt.Errorf("Error loading the TLS key pair of the chaincode server: %s", err)
+	}
+
+	tlsConfig := &tls.Config{Certificates: []tls.Certificate{cert}}
+	if len(props.ClientCACerts) > 0 {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(props.ClientCACerts) {
+			return nil, fmt.Errorf("Error loading the client CA certificates of the chaincode server")
+		}
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return tlsConfig, nil
+}
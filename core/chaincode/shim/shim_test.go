@@ -94,6 +94,8 @@ func (t *shimTestCC) Invoke(stub ChaincodeStubInterface) pb.Response {
 		return t.historyq(stub, args)
 	} else if function == "richq" {
 		return t.richq(stub, args)
+	} else if function == "pageq" {
+		return t.pageq(stub, args)
 	}
 
 	return Error("Invalid invoke function name. Expecting \"invoke\" \"delete\" \"query\"")
@@ -252,6 +254,32 @@ func (t *shimTestCC) rangeq(stub ChaincodeStubInterface, args []string) pb.Respo
 	return Success(buffer.Bytes())
 }
 
+// pageq calls paginated partial composite key query and returns the bookmark of the next page
+func (t *shimTestCC) pageq(stub ChaincodeStubInterface, args []string) pb.Response {
+	if len(args) != 2 {
+		return Error("Incorrect number of arguments. Expecting object type and bookmark for paginated query")
+	}
+
+	resultsIterator, metadata, err := stub.GetStateByPartialCompositeKeyWithPagination(args[0], []string{}, 2, args[1])
+	if err != nil {
+		return Error(err.Error())
+	}
+	defer resultsIterator.Close()
+
+	var fetched int32
+	for resultsIterator.HasNext() {
+		if _, err := resultsIterator.Next(); err != nil {
+			return Error(err.Error())
+		}
+		fetched++
+	}
+	if fetched != metadata.FetchedRecordsCount {
+		return Error("the number of fetched records does not match the query response metadata")
+	}
+
+	return Success([]byte(metadata.Bookmark))
+}
+
 // richq calls tichq query
 func (t *shimTestCC) richq(stub ChaincodeStubInterface, args []string) pb.Response {
 	if len(args) != 1 {
@@ -832,6 +860,42 @@ func TestInvoke(t *testing.T) {
 	//wait for done
 	processDone(t, done, false)
 
+	//paginated query
+
+	//create the response
+	pageQResp := &pb.QueryResponse{Results: []*pb.QueryResultBytes{
+		&pb.QueryResultBytes{ResultBytes: utils.MarshalOrPanic(&lproto.KV{"getputcc", "\x00marble\x00A\x00", []byte("100")})},
+		&pb.QueryResultBytes{ResultBytes: utils.MarshalOrPanic(&lproto.KV{"getputcc", "\x00marble\x00B\x00", []byte("200")})}},
+		HasMore:  false,
+		Metadata: utils.MarshalOrPanic(&pb.QueryResponseMetadata{FetchedRecordsCount: 2, Bookmark: "\x00marble\x00C\x00"})}
+
+	respSet = &mockpeer.MockResponseSet{errorFunc, errorFunc, []*mockpeer.MockResponse{
+		&mockpeer.MockResponse{&pb.ChaincodeMessage{Type: pb.ChaincodeMessage_GET_STATE_BY_PARTIAL_COMPOSITE_KEY_WITH_PAGINATION, Txid: "9"}, &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_RESPONSE, Payload: utils.MarshalOrPanic(pageQResp), Txid: "9"}},
+		&mockpeer.MockResponse{&pb.ChaincodeMessage{Type: pb.ChaincodeMessage_QUERY_STATE_CLOSE, Txid: "9"}, &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_RESPONSE, Txid: "9"}},
+		&mockpeer.MockResponse{&pb.ChaincodeMessage{Type: pb.ChaincodeMessage_COMPLETED, Txid: "9"}, nil}}}
+	peerSide.SetResponses(respSet)
+
+	ci = &pb.ChaincodeInput{[][]byte{[]byte("pageq"), []byte("marble"), []byte("")}, nil}
+	payload = utils.MarshalOrPanic(ci)
+	peerSide.Send(&pb.ChaincodeMessage{Type: pb.ChaincodeMessage_TRANSACTION, Payload: payload, Txid: "9"})
+
+	//wait for done
+	processDone(t, done, false)
+
+	//paginated query error
+
+	respSet = &mockpeer.MockResponseSet{errorFunc, errorFunc, []*mockpeer.MockResponse{
+		&mockpeer.MockResponse{&pb.ChaincodeMessage{Type: pb.ChaincodeMessage_GET_STATE_BY_PARTIAL_COMPOSITE_KEY_WITH_PAGINATION, Txid: "9a"}, &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_ERROR, Payload: nil, Txid: "9a"}},
+		&mockpeer.MockResponse{&pb.ChaincodeMessage{Type: pb.ChaincodeMessage_COMPLETED, Txid: "9a"}, nil}}}
+	peerSide.SetResponses(respSet)
+
+	ci = &pb.ChaincodeInput{[][]byte{[]byte("pageq"), []byte("marble"), []byte("")}, nil}
+	payload = utils.MarshalOrPanic(ci)
+	peerSide.Send(&pb.ChaincodeMessage{Type: pb.ChaincodeMessage_TRANSACTION, Payload: payload, Txid: "9a"})
+
+	//wait for done
+	processDone(t, done, false)
+
 	time.Sleep(1 * time.Second)
 	peerSide.Quit()
 }
@@ -7,6 +7,7 @@ SPDX-License-Identifier: Apache-2.0
 package comm
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/spf13/viper"
@@ -28,6 +29,7 @@ var (
 		ClientKeepaliveTimeout: 20,   // 20 sec - gRPC default
 		ServerKeepaliveTime:    7200, // 2 hours - gRPC default
 		ServerKeepaliveTimeout: 20,   // 20 sec - gRPC default
+		PermitWithoutStream:    true,
 	}
 )
 
@@ -48,6 +50,20 @@ type KeepaliveOptions struct {
 	// ServerKeepaliveTimeout is the duration the server waits for a response
 	// from the client after sending a ping before closing the connection
 	ServerKeepaliveTimeout int
+	// PermitWithoutStream allows the clients to ping the servers, and the
+	// servers to accept the pings, while there are no active streams, which
+	// keeps idle connections open through load balancers and proxies
+	PermitWithoutStream bool
+}
+
+// GRPCOptions is used to set the keepalive and message size settings of the
+// gRPC clients and servers of a component
+type GRPCOptions struct {
+	Keepalive KeepaliveOptions
+	// MaxRecvMsgSize is the maximum message size in bytes that can be received
+	MaxRecvMsgSize int
+	// MaxSendMsgSize is the maximum message size in bytes that can be sent
+	MaxSendMsgSize int
 }
 
 // cacheConfiguration caches common package scoped variables
@@ -98,30 +114,121 @@ func SetKeepaliveOptions(ka KeepaliveOptions) {
 
 // ServerKeepaliveOptions returns the gRPC keepalive options for servers
 func ServerKeepaliveOptions() []grpc.ServerOption {
+	return keepaliveOptions.ServerOptions()
+}
+
+// ClientKeepaliveOptions returns the gRPC keepalive options for clients
+func ClientKeepaliveOptions() []grpc.DialOption {
+	return keepaliveOptions.DialOptions()
+}
+
+// ServerOptions returns the gRPC keepalive options for servers
+func (ka KeepaliveOptions) ServerOptions() []grpc.ServerOption {
 	var serverOpts []grpc.ServerOption
 	kap := keepalive.ServerParameters{
-		Time:    time.Duration(keepaliveOptions.ServerKeepaliveTime) * time.Second,
-		Timeout: time.Duration(keepaliveOptions.ServerKeepaliveTimeout) * time.Second,
+		Time:    time.Duration(ka.ServerKeepaliveTime) * time.Second,
+		Timeout: time.Duration(ka.ServerKeepaliveTimeout) * time.Second,
 	}
 	serverOpts = append(serverOpts, grpc.KeepaliveParams(kap))
 	kep := keepalive.EnforcementPolicy{
 		// needs to match clientKeepalive
-		MinTime: time.Duration(keepaliveOptions.ClientKeepaliveTime) * time.Second,
-		// allow keepalive w/o rpc
-		PermitWithoutStream: true,
+		MinTime:             time.Duration(ka.ClientKeepaliveTime) * time.Second,
+		PermitWithoutStream: ka.PermitWithoutStream,
 	}
 	serverOpts = append(serverOpts, grpc.KeepaliveEnforcementPolicy(kep))
 	return serverOpts
 }
 
-// ClientKeepaliveOptions returns the gRPC keepalive options for clients
-func ClientKeepaliveOptions() []grpc.DialOption {
+// DialOptions returns the gRPC keepalive options for clients
+func (ka KeepaliveOptions) DialOptions() []grpc.DialOption {
 	var dialOpts []grpc.DialOption
 	kap := keepalive.ClientParameters{
-		Time:                time.Duration(keepaliveOptions.ClientKeepaliveTime) * time.Second,
-		Timeout:             time.Duration(keepaliveOptions.ClientKeepaliveTimeout) * time.Second,
-		PermitWithoutStream: true,
+		Time:                time.Duration(ka.ClientKeepaliveTime) * time.Second,
+		Timeout:             time.Duration(ka.ClientKeepaliveTimeout) * time.Second,
+		PermitWithoutStream: ka.PermitWithoutStream,
 	}
 	dialOpts = append(dialOpts, grpc.WithKeepaliveParams(kap))
 	return dialOpts
 }
+
+// DefaultGRPCOptions returns the package wide keepalive and message size
+// settings of gRPC clients and servers
+func DefaultGRPCOptions() GRPCOptions {
+	return GRPCOptions{
+		Keepalive:      keepaliveOptions,
+		MaxRecvMsgSize: maxRecvMsgSize,
+		MaxSendMsgSize: maxSendMsgSize,
+	}
+}
+
+// SetGRPCOptions sets the package wide keepalive and message size settings
+// of gRPC clients and servers
+func SetGRPCOptions(opts GRPCOptions) {
+	SetKeepaliveOptions(opts.Keepalive)
+	SetMaxRecvMsgSize(opts.MaxRecvMsgSize)
+	SetMaxSendMsgSize(opts.MaxSendMsgSize)
+}
+
+// GRPCOptionsFromConfig reads the keepalive and message size settings under
+// the given configuration key prefix, that is <prefix>.keepalive.client and
+// <prefix>.keepalive.server interval and timeout durations,
+// <prefix>.keepalive.permitWithoutStream, <prefix>.maxRecvMsgSize and
+// <prefix>.maxSendMsgSize. The settings which are not configured are the
+// package wide ones
+func GRPCOptionsFromConfig(prefix string) (GRPCOptions, error) {
+	opts := DefaultGRPCOptions()
+	ka := &opts.Keepalive
+	for key, seconds := range map[string]*int{
+		"keepalive.client.interval": &ka.ClientKeepaliveTime,
+		"keepalive.client.timeout":  &ka.ClientKeepaliveTimeout,
+		"keepalive.server.interval": &ka.ServerKeepaliveTime,
+		"keepalive.server.timeout":  &ka.ServerKeepaliveTimeout,
+	} {
+		key = prefix + "." + key
+		if !viper.IsSet(key) {
+			continue
+		}
+		d := viper.GetDuration(key)
+		if d < time.Second {
+			return GRPCOptions{}, fmt.Errorf("%s must be at least 1s, got %v", key, d)
+		}
+		*seconds = int(d / time.Second)
+	}
+	if key := prefix + ".keepalive.permitWithoutStream"; viper.IsSet(key) {
+		ka.PermitWithoutStream = viper.GetBool(key)
+	}
+	for key, size := range map[string]*int{
+		"maxRecvMsgSize": &opts.MaxRecvMsgSize,
+		"maxSendMsgSize": &opts.MaxSendMsgSize,
+	} {
+		key = prefix + "." + key
+		if !viper.IsSet(key) {
+			continue
+		}
+		*size = viper.GetInt(key)
+		if *size <= 0 {
+			return GRPCOptions{}, fmt.Errorf("%s must be positive, got %d", key, *size)
+		}
+	}
+	return opts, nil
+}
+
+// ServerOptions returns the gRPC server options of the keepalive and message
+// size settings
+func (opts GRPCOptions) ServerOptions() []grpc.ServerOption {
+	serverOpts := []grpc.ServerOption{
+		grpc.MaxSendMsgSize(opts.MaxSendMsgSize),
+		grpc.MaxRecvMsgSize(opts.MaxRecvMsgSize),
+	}
+	return append(serverOpts, opts.Keepalive.ServerOptions()...)
+}
+
+// DialOptions returns the gRPC dial options of the keepalive and message
+// size settings
+func (opts GRPCOptions) DialOptions() []grpc.DialOption {
+	dialOpts := []grpc.DialOption{
+		grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(opts.MaxRecvMsgSize),
+			grpc.MaxCallSendMsgSize(opts.MaxSendMsgSize)),
+	}
+	return append(dialOpts, opts.Keepalive.DialOptions()...)
+}
@@ -54,3 +54,49 @@ func TestConfig(t *testing.T) {
 	configurationCached = false
 	viper.Set("peer.tls.enabled", false)
 }
+
+func TestGRPCOptionsFromConfig(t *testing.T) {
+	defaults := DefaultGRPCOptions()
+	defer SetGRPCOptions(defaults)
+
+	// the settings which aren't configured are the package wide ones
+	opts, err := GRPCOptionsFromConfig("test")
+	assert.NoError(t, err)
+	assert.Equal(t, defaults, opts)
+
+	viper.Set("test.keepalive.client.interval", "1m")
+	viper.Set("test.keepalive.server.timeout", "5s")
+	viper.Set("test.keepalive.permitWithoutStream", false)
+	viper.Set("test.maxRecvMsgSize", 1024)
+	defer viper.Set("test", nil)
+	opts, err = GRPCOptionsFromConfig("test")
+	assert.NoError(t, err)
+	assert.Equal(t, GRPCOptions{
+		Keepalive: KeepaliveOptions{
+			ClientKeepaliveTime:    60,
+			ClientKeepaliveTimeout: defaults.Keepalive.ClientKeepaliveTimeout,
+			ServerKeepaliveTime:    defaults.Keepalive.ServerKeepaliveTime,
+			ServerKeepaliveTimeout: 5,
+		},
+		MaxRecvMsgSize: 1024,
+		MaxSendMsgSize: defaults.MaxSendMsgSize,
+	}, opts)
+	assert.Len(t, opts.ServerOptions(), 4)
+	assert.Len(t, opts.DialOptions(), 2)
+
+	// a component's unset settings follow the package wide ones
+	SetGRPCOptions(opts)
+	viper.Set("test.component.maxSendMsgSize", 2048)
+	componentOpts, err := GRPCOptionsFromConfig("test.component")
+	assert.NoError(t, err)
+	opts.MaxSendMsgSize = 2048
+	assert.Equal(t, opts, componentOpts)
+
+	viper.Set("test.keepalive.server.interval", "10ms")
+	_, err = GRPCOptionsFromConfig("test")
+	assert.Error(t, err)
+	viper.Set("test.keepalive.server.interval", "1s")
+	viper.Set("test.maxSendMsgSize", 0)
+	_, err = GRPCOptionsFromConfig("test")
+	assert.Error(t, err)
+}
@@ -34,8 +34,11 @@ type CASupport struct {
 	sync.RWMutex
 	AppRootCAsByChain     map[string][][]byte
 	OrdererRootCAsByChain map[string][][]byte
-	ClientRootCAs         [][]byte
-	ServerRootCAs         [][]byte
+	// AppRootCAsByOrgByChain holds the root CAs of each application
+	// organization of the chains, indexed by chain and then by MSP ID
+	AppRootCAsByOrgByChain map[string]map[string][][]byte
+	ClientRootCAs          [][]byte
+	ServerRootCAs          [][]byte
 }
 
 // GetCASupport returns the singleton CASupport instance
@@ -43,8 +46,9 @@ func GetCASupport() *CASupport {
 
 	once.Do(func() {
 		caSupport = &CASupport{
-			AppRootCAsByChain:     make(map[string][][]byte),
-			OrdererRootCAsByChain: make(map[string][][]byte),
+			AppRootCAsByChain:      make(map[string][][]byte),
+			OrdererRootCAsByChain:  make(map[string][][]byte),
+			AppRootCAsByOrgByChain: make(map[string]map[string][][]byte),
 		}
 	})
 	return caSupport
@@ -74,6 +78,40 @@ func (cas *CASupport) GetServerRootCAs() (appRootCAs, ordererRootCAs [][]byte) {
 	return appRootCAs, ordererRootCAs
 }
 
+// GetServerRootCAsByOrg returns the PEM-encoded root certificates of each of the
+// application organizations defined for all chains, indexed by MSP ID. The statically
+// configured server root certificates are indexed by the empty MSP ID. The root
+// certificates returned should be used to set the trusted server roots of each
+// organization for TLS clients.
+func (cas *CASupport) GetServerRootCAsByOrg() map[string][][]byte {
+	return cas.appRootCAsByOrg(cas.ServerRootCAs)
+}
+
+// GetClientRootCAsByOrg returns the PEM-encoded root certificates of each of the
+// application organizations defined for all chains, indexed by MSP ID. The statically
+// configured client root certificates are indexed by the empty MSP ID. The root
+// certificates returned should be used to set the trusted client roots of each
+// organization for TLS servers.
+func (cas *CASupport) GetClientRootCAsByOrg() map[string][][]byte {
+	return cas.appRootCAsByOrg(cas.ClientRootCAs)
+}
+
+func (cas *CASupport) appRootCAsByOrg(staticRootCAs [][]byte) map[string][][]byte {
+	cas.RLock()
+	defer cas.RUnlock()
+
+	rootCAsByOrg := make(map[string][][]byte)
+	for _, orgs := range cas.AppRootCAsByOrgByChain {
+		for mspID, rootCAs := range orgs {
+			rootCAsByOrg[mspID] = append(rootCAsByOrg[mspID], rootCAs...)
+		}
+	}
+	if len(staticRootCAs) > 0 {
+		rootCAsByOrg[""] = append(rootCAsByOrg[""], staticRootCAs...)
+	}
+	return rootCAsByOrg
+}
+
 // GetDeliverServiceCredentials returns GRPC transport credentials for given channel to be used by GRPC
 // clients which communicate with ordering service endpoints.
 // If the channel isn't found, error is returned.
@@ -141,6 +141,15 @@ func TestCASupport(t *testing.T) {
 	assert.Equal(t, 4, len(appClientRoots), "Expected 4 app client root CAs")
 	assert.Equal(t, 2, len(ordererClientRoots), "Expected 4 orderer client root CAs")
 
+	cas.AppRootCAsByOrgByChain["channel1"] = map[string][][]byte{"Org1MSP": {rootCAs[0]}}
+	cas.AppRootCAsByOrgByChain["channel2"] = map[string][][]byte{"Org1MSP": {rootCAs[0]}, "Org2MSP": {rootCAs[1]}}
+	assert.Equal(t, map[string][][]byte{
+		"Org1MSP": {rootCAs[0], rootCAs[0]},
+		"Org2MSP": {rootCAs[1]},
+		"":        {rootCAs[5]},
+	}, cas.GetServerRootCAsByOrg())
+	assert.Len(t, cas.GetClientRootCAsByOrg(), 3, "Expected the client root CAs of 2 orgs and the static ones")
+
 	// make sure we really have a singleton
 	casClone := GetCASupport()
 	assert.Exactly(t, casClone, cas, "Expected GetCASupport to be a singleton")
@@ -0,0 +1,39 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package comm
+
+import (
+	"errors"
+	"fmt"
+	"net"
+)
+
+//ListenReusePort creates the given number of TCP listeners bound to the same
+//address with the SO_REUSEPORT socket option, so that the kernel distributes
+//the incoming connections among them. If the address has port 0, all the
+//listeners are bound to the port chosen for the first one
+func ListenReusePort(address string, count int) ([]net.Listener, error) {
+	if address == "" {
+		return nil, errors.New("Missing address parameter")
+	}
+	if count < 1 {
+		return nil, fmt.Errorf("Invalid number of listeners %d", count)
+	}
+	listeners := make([]net.Listener, 0, count)
+	for i := 0; i < count; i++ {
+		lis, err := listenReusePort(address)
+		if err != nil {
+			for _, l := range listeners {
+				l.Close()
+			}
+			return nil, err
+		}
+		listeners = append(listeners, lis)
+		address = lis.Addr().String()
+	}
+	return listeners, nil
+}
@@ -0,0 +1,11 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package comm
+
+//The syscall package does not define SO_REUSEPORT on linux. Its value is
+//the same on all the architectures binaries are released for
+const soReusePort = 0xf
@@ -0,0 +1,19 @@
+// +build !linux,!darwin
+
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package comm
+
+import (
+	"fmt"
+	"net"
+	"runtime"
+)
+
+func listenReusePort(address string) (net.Listener, error) {
+	return nil, fmt.Errorf("SO_REUSEPORT listeners are not supported on %s", runtime.GOOS)
+}
@@ -0,0 +1,98 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package comm_test
+
+import (
+	"net"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric/core/comm"
+	testpb "github.com/hyperledger/fabric/core/comm/testdata/grpc"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+)
+
+func TestListenReusePort(t *testing.T) {
+
+	t.Parallel()
+	if runtime.GOOS != "linux" && runtime.GOOS != "darwin" {
+		_, err := comm.ListenReusePort("localhost:0", 2)
+		assert.Error(t, err)
+		return
+	}
+
+	_, err := comm.ListenReusePort("", 2)
+	assert.Error(t, err)
+	_, err = comm.ListenReusePort("localhost:0", 0)
+	assert.Error(t, err)
+
+	listeners, err := comm.ListenReusePort("localhost:0", 3)
+	assert.NoError(t, err)
+	assert.Len(t, listeners, 3)
+	for _, lis := range listeners {
+		assert.Equal(t, listeners[0].Addr().String(), lis.Addr().String())
+		defer lis.Close()
+	}
+
+	//a regular listener cannot bind to the shared port
+	_, err = net.Listen("tcp", listeners[0].Addr().String())
+	assert.Error(t, err)
+}
+
+func TestNewGRPCServerFromListeners(t *testing.T) {
+
+	t.Parallel()
+	if runtime.GOOS != "linux" && runtime.GOOS != "darwin" {
+		t.Skip("SO_REUSEPORT listeners are not supported on " + runtime.GOOS)
+	}
+
+	_, err := comm.NewGRPCServerFromListeners(nil, comm.SecureServerConfig{UseTLS: false})
+	assert.Error(t, err)
+
+	listeners, err := comm.ListenReusePort("localhost:0", 4)
+	if err != nil {
+		t.Fatalf("Failed to create listeners: %v", err)
+	}
+	srv, err := comm.NewGRPCServerFromListeners(listeners, comm.SecureServerConfig{UseTLS: false})
+	if err != nil {
+		t.Fatalf("Failed to return new GRPC server: %v", err)
+	}
+	testAddress := listeners[0].Addr().String()
+	assert.Equal(t, testAddress, srv.Address())
+	assert.Equal(t, listeners[0], srv.Listener())
+
+	//register the GRPC test server
+	testpb.RegisterTestServiceServer(srv.Server(), &testServiceServer{})
+
+	//start the server
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- srv.Start()
+	}()
+
+	//GRPC client options
+	var dialOptions []grpc.DialOption
+	dialOptions = append(dialOptions, grpc.WithInsecure())
+
+	//the kernel spreads the connections among the listeners, which all serve the same service
+	for i := 0; i < 20; i++ {
+		_, err = invokeEmptyCall(testAddress, dialOptions)
+		if err != nil {
+			t.Fatalf("GRPC client failed to invoke the EmptyCall service on %s: %v",
+				testAddress, err)
+		}
+	}
+
+	srv.Stop()
+	select {
+	case <-serveErr:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Start should return once the server is stopped")
+	}
+}
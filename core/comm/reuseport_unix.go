@@ -0,0 +1,65 @@
+// +build linux darwin
+
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package comm
+
+import (
+	"net"
+	"os"
+	"syscall"
+)
+
+func listenReusePort(address string) (net.Listener, error) {
+	tcpAddr, err := net.ResolveTCPAddr("tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	family := syscall.AF_INET
+	var sockaddr syscall.Sockaddr
+	if ip4 := tcpAddr.IP.To4(); ip4 != nil || tcpAddr.IP == nil {
+		sa := &syscall.SockaddrInet4{Port: tcpAddr.Port}
+		copy(sa.Addr[:], ip4)
+		sockaddr = sa
+	} else {
+		family = syscall.AF_INET6
+		sa := &syscall.SockaddrInet6{Port: tcpAddr.Port}
+		copy(sa.Addr[:], tcpAddr.IP.To16())
+		sockaddr = sa
+	}
+
+	fd, err := syscall.Socket(family, syscall.SOCK_STREAM, syscall.IPPROTO_TCP)
+	if err != nil {
+		return nil, os.NewSyscallError("socket", err)
+	}
+	syscall.CloseOnExec(fd)
+	if err = bindReusePort(fd, sockaddr); err != nil {
+		syscall.Close(fd)
+		return nil, err
+	}
+
+	//net.FileListener duplicates the descriptor, so the file is closed either way
+	file := os.NewFile(uintptr(fd), "reuseport:"+address)
+	defer file.Close()
+	return net.FileListener(file)
+}
+
+func bindReusePort(fd int, sockaddr syscall.Sockaddr) error {
+	if err := syscall.SetsockoptInt(fd, syscall.SOL_SOCKET, syscall.SO_REUSEADDR, 1); err != nil {
+		return os.NewSyscallError("setsockopt", err)
+	}
+	if err := syscall.SetsockoptInt(fd, syscall.SOL_SOCKET, soReusePort, 1); err != nil {
+		return os.NewSyscallError("setsockopt", err)
+	}
+	if err := syscall.Bind(fd, sockaddr); err != nil {
+		return os.NewSyscallError("bind", err)
+	}
+	if err := syscall.Listen(fd, syscall.SOMAXCONN); err != nil {
+		return os.NewSyscallError("listen", err)
+	}
+	return nil
+}
@@ -35,6 +35,9 @@ type SecureServerConfig struct {
 	UseTLS bool
 	//Whether or not TLS client must present certificates for authentication
 	RequireClientCert bool
+	//Keepalive and message size settings of the server, the package wide
+	//settings if nil
+	GRPCOptions *GRPCOptions
 }
 
 //GRPCServer defines an interface representing a GRPC-based server
@@ -70,6 +73,9 @@ type grpcServerImpl struct {
 	address string
 	//Listener for handling network requests
 	listener net.Listener
+	//Additional listeners sharing the port of listener, each served by its
+	//own accept loop
+	reusePortListeners []net.Listener
 	//GRPC server
 	server *grpc.Server
 	//Certificate presented by the server for TLS communication
@@ -111,11 +117,23 @@ func NewGRPCServer(address string, secureConfig SecureServerConfig) (GRPCServer,
 //NewGRPCServerFromListener creates a new implementation of a GRPCServer given
 //an existing net.Listener instance.
 func NewGRPCServerFromListener(listener net.Listener, secureConfig SecureServerConfig) (GRPCServer, error) {
+	return NewGRPCServerFromListeners([]net.Listener{listener}, secureConfig)
+}
+
+//NewGRPCServerFromListeners creates a new implementation of a GRPCServer given
+//existing net.Listener instances bound to the same address (see ListenReusePort).
+//The server runs an accept loop per listener, while all the connections share
+//the registered services
+func NewGRPCServerFromListeners(listeners []net.Listener, secureConfig SecureServerConfig) (GRPCServer, error) {
 
+	if len(listeners) == 0 {
+		return nil, errors.New("Missing listeners parameter")
+	}
 	grpcServer := &grpcServerImpl{
-		address:  listener.Addr().String(),
-		listener: listener,
-		lock:     &sync.Mutex{},
+		address:            listeners[0].Addr().String(),
+		listener:           listeners[0],
+		reusePortListeners: listeners[1:],
+		lock:               &sync.Mutex{},
 	}
 
 	//set up our server options
@@ -166,11 +184,12 @@ func NewGRPCServerFromListener(listener net.Listener, secureConfig SecureServerC
 				"ServerCertificate when UseTLS is true")
 		}
 	}
-	// set max send and recv msg sizes
-	serverOpts = append(serverOpts, grpc.MaxSendMsgSize(MaxSendMsgSize()))
-	serverOpts = append(serverOpts, grpc.MaxRecvMsgSize(MaxRecvMsgSize()))
-	// set the keepalive options
-	serverOpts = append(serverOpts, ServerKeepaliveOptions()...)
+	// set max send and recv msg sizes, and the keepalive options
+	grpcOpts := DefaultGRPCOptions()
+	if secureConfig.GRPCOptions != nil {
+		grpcOpts = *secureConfig.GRPCOptions
+	}
+	serverOpts = append(serverOpts, grpcOpts.ServerOptions()...)
 
 	grpcServer.server = grpc.NewServer(serverOpts...)
 
@@ -205,7 +224,17 @@ func (gServer *grpcServerImpl) TLSEnabled() bool {
 
 //Start starts the underlying grpc.Server
 func (gServer *grpcServerImpl) Start() error {
-	return gServer.server.Serve(gServer.listener)
+	if len(gServer.reusePortListeners) == 0 {
+		return gServer.server.Serve(gServer.listener)
+	}
+	//serve every listener and return as soon as one of the accept loops stops
+	errs := make(chan error, len(gServer.reusePortListeners)+1)
+	for _, lis := range append([]net.Listener{gServer.listener}, gServer.reusePortListeners...) {
+		go func(lis net.Listener) {
+			errs <- gServer.server.Serve(lis)
+		}(lis)
+	}
+	return <-errs
 }
 
 //Stop stops the underlying grpc.Server
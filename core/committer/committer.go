@@ -16,7 +16,10 @@ limitations under the License.
 
 package committer
 
-import "github.com/hyperledger/fabric/protos/common"
+import (
+	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/protos/common"
+)
 
 // Committer is the interface supported by committers
 // The only committer is noopssinglechain committer.
@@ -30,6 +33,16 @@ type Committer interface {
 	// Commit block to the ledger
 	Commit(block *common.Block) error
 
+	// CommitWithPvtData commits block to the ledger along with the private data
+	// received for it, the private data of the block that isn't supplied is
+	// taken from the transient store of the peer
+	CommitWithPvtData(blockAndPvtData *ledger.BlockAndPvtData) error
+
+	// RecommitLostBlock completes the commit of the block, the last block of the ledger,
+	// which a failed commit added to the block store of the ledger without committing
+	// its private data or state. The block isn't validated again
+	RecommitLostBlock(block *common.Block) error
+
 	// Get recent block sequence number
 	LedgerHeight() (uint64, error)
 
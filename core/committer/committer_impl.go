@@ -20,10 +20,14 @@ import (
 	"fmt"
 
 	"github.com/hyperledger/fabric/common/flogging"
+	commonledger "github.com/hyperledger/fabric/common/ledger"
+	"github.com/hyperledger/fabric/common/ledger/blkstorage"
 	"github.com/hyperledger/fabric/core/committer/txvalidator"
 	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/events/ccevents"
 	"github.com/hyperledger/fabric/events/producer"
 	"github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/ledger/rwset"
 	"github.com/hyperledger/fabric/protos/utils"
 	"github.com/op/go-logging"
 )
@@ -35,8 +39,12 @@ import (
 
 var logger *logging.Logger // package-level logger
 
+// blockLogger attaches the channel of the blocks to the records logged while committing them
+var blockLogger *flogging.ContextLogger
+
 func init() {
 	logger = flogging.MustGetLogger("committer")
+	blockLogger = flogging.MustGetContextLogger("committer")
 }
 
 // LedgerCommitter is the implementation of  Committer interface
@@ -46,6 +54,7 @@ type LedgerCommitter struct {
 	ledger    ledger.PeerLedger
 	validator txvalidator.Validator
 	eventer   ConfigBlockEventer
+	notifier  *TxStatusNotifier
 }
 
 // ConfigBlockEventer callback function proto type to define action
@@ -62,37 +71,177 @@ func NewLedgerCommitter(ledger ledger.PeerLedger, validator txvalidator.Validato
 // same as way as NewLedgerCommitter, while also provides an option to specify callback to
 // be called upon new configuration block arrival and commit event
 func NewLedgerCommitterReactive(ledger ledger.PeerLedger, validator txvalidator.Validator, eventer ConfigBlockEventer) *LedgerCommitter {
-	return &LedgerCommitter{ledger: ledger, validator: validator, eventer: eventer}
+	return &LedgerCommitter{ledger: ledger, validator: validator, eventer: eventer, notifier: NewTxStatusNotifier()}
 }
 
 // Commit commits block to into the ledger
 // Note, it is important that this always be called serially
 func (lc *LedgerCommitter) Commit(block *common.Block) error {
+	return lc.CommitWithPvtData(&ledger.BlockAndPvtData{Block: block})
+}
+
+// CommitWithPvtData commits block to into the ledger along with the private data received for it
+// Note, it is important that this always be called serially
+func (lc *LedgerCommitter) CommitWithPvtData(blockAndPvtData *ledger.BlockAndPvtData) error {
+	block := blockAndPvtData.Block
+	// the channel is only attached to the records when the block carries it
+	chainID, _ := utils.GetChainIDFromBlock(block)
+	logger := blockLogger.WithChannel(chainID)
 
 	// Validate and mark invalid transactions
-	logger.Debug("Validating block")
+	logger.Debugf("Validating block %d", block.Header.Number)
 	if err := lc.validator.Validate(block); err != nil {
 		return err
 	}
 
 	// Updating CSCC with new configuration block
 	if utils.IsConfigBlock(block) {
-		logger.Debug("Received configuration update, calling CSCC ConfigUpdate")
+		logger.Debugf("Received configuration update, calling CSCC ConfigUpdate")
 		if err := lc.eventer(block); err != nil {
 			return fmt.Errorf("Could not update CSCC with new configuration update due to %s", err)
 		}
 	}
 
-	if err := lc.ledger.Commit(block); err != nil {
+	if err := lc.ledger.CommitWithPvtData(blockAndPvtData); err != nil {
+		return err
+	}
+
+	lc.blockCommitted(block)
+	return nil
+}
+
+// RecommitLostBlock commits the private data and state of the last block of the ledger, which
+// a failed commit left in the block store only, and publishes the block once it is committed
+func (lc *LedgerCommitter) RecommitLostBlock(block *common.Block) error {
+	height, err := lc.LedgerHeight()
+	if err != nil {
+		return err
+	}
+	if block.Header.Number+1 != height {
+		return fmt.Errorf("block %d is not the last block of the ledger, whose height is %d", block.Header.Number, height)
+	}
+	recommitter, ok := lc.ledger.(ledger.LostBlocksRecommitter)
+	if !ok {
+		return fmt.Errorf("ledger does not recommit the blocks lost by a failed commit")
+	}
+	if err := recommitter.RecommitLostBlocks(); err != nil {
 		return err
 	}
 
+	lc.blockCommitted(block)
+	return nil
+}
+
+// stateDiffSyncer returns the ledger as a `ledger.StateDiffSyncer`, if it is one
+func (lc *LedgerCommitter) stateDiffSyncer() (ledger.StateDiffSyncer, error) {
+	syncer, ok := lc.ledger.(ledger.StateDiffSyncer)
+	if !ok {
+		return nil, fmt.Errorf("ledger does not sync its state by state differences")
+	}
+	return syncer, nil
+}
+
+// StateCheckpoint returns the height the state database of the ledger reflects
+func (lc *LedgerCommitter) StateCheckpoint() (uint64, error) {
+	syncer, err := lc.stateDiffSyncer()
+	if err != nil {
+		return 0, err
+	}
+	return syncer.StateCheckpoint()
+}
+
+// StateDiffSince returns the state differences of the ledger since the given checkpoint, and the height they are up to
+func (lc *LedgerCommitter) StateDiffSince(checkpoint uint64) (uint64, []*ledger.StateDiffEntry, error) {
+	syncer, err := lc.stateDiffSyncer()
+	if err != nil {
+		return 0, nil, err
+	}
+	return syncer.StateDiffSince(checkpoint)
+}
+
+// CommitValidatedBlock adds a block validated by another peer to the ledger while the recovery of its state database
+// is deferred, and publishes the block once it is added. The block isn't validated again
+func (lc *LedgerCommitter) CommitValidatedBlock(blockAndPvtData *ledger.BlockAndPvtData) error {
+	block := blockAndPvtData.Block
+	syncer, err := lc.stateDiffSyncer()
+	if err != nil {
+		return err
+	}
+	if utils.IsConfigBlock(block) {
+		if err := lc.eventer(block); err != nil {
+			return fmt.Errorf("Could not update CSCC with new configuration update due to %s", err)
+		}
+	}
+	if err := syncer.CommitValidatedBlock(blockAndPvtData); err != nil {
+		return err
+	}
+
+	lc.blockCommitted(block)
+	return nil
+}
+
+// ApplyStateDiff writes the state differences to the deferred state database of the ledger
+func (lc *LedgerCommitter) ApplyStateDiff(checkpoint uint64, height uint64, entries []*ledger.StateDiffEntry) error {
+	syncer, err := lc.stateDiffSyncer()
+	if err != nil {
+		return err
+	}
+	return syncer.ApplyStateDiff(checkpoint, height, entries)
+}
+
+// RecoverState brings the deferred state database of the ledger up to date by recommitting the blocks
+// to it. Unlike RecommitLostBlock, nothing is published, as the blocks were published once added
+func (lc *LedgerCommitter) RecoverState() error {
+	recommitter, ok := lc.ledger.(ledger.LostBlocksRecommitter)
+	if !ok {
+		return fmt.Errorf("ledger does not recommit the blocks lost by a failed commit")
+	}
+	return recommitter.RecommitLostBlocks()
+}
+
+// blockCommitted notifies the listeners of the transactions of the committed block, and publishes it
+func (lc *LedgerCommitter) blockCommitted(block *common.Block) {
+	// the channel is only attached to the records when the block carries it
+	chainID, _ := utils.GetChainIDFromBlock(block)
+	logger := blockLogger.WithChannel(chainID)
+
+	lc.notifier.notifyBlock(block)
+
+	// persist the chaincode events of the block for their durable delivery
+	ccevents.BlockCommitted(block)
+
 	// send block event *after* the block has been committed
 	if err := producer.SendProducerBlockEvent(block); err != nil {
 		logger.Errorf("Error publishing block %d, because: %v", block.Header.Number, err)
 	}
+}
 
-	return nil
+// RegisterTxStatusListener returns a channel that receives the status of the transaction with the given ID
+// once it is committed. A transaction committed before the listener got registered is looked up with CommittedTxStatus
+func (lc *LedgerCommitter) RegisterTxStatusListener(txID string) <-chan *TxStatus {
+	return lc.notifier.RegisterTxStatusListener(txID)
+}
+
+// CommittedTxStatus returns the status the transaction with the given ID was committed with,
+// or nil if it is not committed
+func (lc *LedgerCommitter) CommittedTxStatus(txID string) (*TxStatus, error) {
+	block, err := lc.ledger.GetBlockByTxID(txID)
+	if err == blkstorage.ErrNotFoundInIndex {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	code, err := lc.ledger.GetTxValidationCodeByTxID(txID)
+	if err != nil {
+		return nil, err
+	}
+	return &TxStatus{TxID: txID, BlockNumber: block.Header.Number, ValidationCode: code}, nil
+}
+
+// UnregisterTxStatusListener removes a listener registered with RegisterTxStatusListener
+func (lc *LedgerCommitter) UnregisterTxStatusListener(txID string, listener <-chan *TxStatus) {
+	lc.notifier.UnregisterTxStatusListener(txID, listener)
 }
 
 // LedgerHeight returns recently committed block sequence number
@@ -107,6 +256,16 @@ func (lc *LedgerCommitter) LedgerHeight() (uint64, error) {
 	return info.Height, nil
 }
 
+// PersistPvtData stores the private write set of a transaction pushed by another endorser
+// into the transient store of the ledger, for the ledger to commit it along with the block
+func (lc *LedgerCommitter) PersistPvtData(txid string, endorserid string, endorsementBlkHt uint64, pvtSimResults *rwset.TxPvtReadWriteSet) error {
+	persister, ok := lc.ledger.(ledger.TransientPvtDataPersister)
+	if !ok {
+		return fmt.Errorf("ledger does not keep the private data of transactions in a transient store")
+	}
+	return persister.PersistPvtData(txid, endorserid, endorsementBlkHt, pvtSimResults)
+}
+
 // GetBlocks used to retrieve blocks with sequence numbers provided in the slice
 func (lc *LedgerCommitter) GetBlocks(blockSeqs []uint64) []*common.Block {
 	var blocks []*common.Block
@@ -124,6 +283,13 @@ func (lc *LedgerCommitter) GetBlocks(blockSeqs []uint64) []*common.Block {
 	return blocks
 }
 
+// GetBlocksIterator returns an iterator over the blocks of the ledger starting from the given
+// block, which reads them sequentially. It blocks once it reaches the height of the ledger,
+// until the next block is committed
+func (lc *LedgerCommitter) GetBlocksIterator(startSeqNum uint64) (commonledger.ResultsIterator, error) {
+	return lc.ledger.GetBlocksIterator(startSeqNum)
+}
+
 // Close the ledger
 func (lc *LedgerCommitter) Close() {
 	lc.ledger.Close()
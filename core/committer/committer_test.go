@@ -28,6 +28,7 @@ import (
 	"github.com/hyperledger/fabric/core/ledger/ledgermgmt"
 	"github.com/hyperledger/fabric/core/mocks/validator"
 	"github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/peer"
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
 )
@@ -69,10 +70,23 @@ func TestKVLedgerBlockStorage(t *testing.T) {
 	assert.Equal(t, uint64(2), height)
 	assert.NoError(t, err)
 
+	// Only the last block of the ledger is recommitted, which is a no-op once the ledger is in sync
+	assert.NoError(t, committer.RecommitLostBlock(block1))
+	assert.Error(t, committer.RecommitLostBlock(gb))
+
 	blocks := committer.GetBlocks([]uint64{0})
 	assert.Equal(t, 1, len(blocks))
 	assert.NoError(t, err)
 
+	itr, err := committer.GetBlocksIterator(0)
+	assert.NoError(t, err)
+	for _, expected := range []*common.Block{gb, block1} {
+		res, err := itr.Next()
+		assert.NoError(t, err)
+		assert.Equal(t, expected.Header.Number, res.(*common.Block).Header.Number)
+	}
+	itr.Close()
+
 	bcInfo, _ = ledger.GetBlockchainInfo()
 	block1Hash := block1.Header.Hash()
 	testutil.AssertEquals(t, bcInfo, &common.BlockchainInfo{
@@ -107,3 +121,51 @@ func TestNewLedgerCommitterReactive(t *testing.T) {
 	committer.Commit(block)
 	assert.Equal(t, int32(1), atomic.LoadInt32(&configArrived))
 }
+
+func TestTxStatusListener(t *testing.T) {
+	viper.Set("peer.fileSystemPath", "/tmp/fabric/committertest")
+	ledgermgmt.InitializeTestEnv()
+	defer ledgermgmt.CleanupTestEnv()
+	gb, _ := test.MakeGenesisBlock("TestLedger")
+	ledger, err := ledgermgmt.CreateLedger(gb)
+	assert.NoError(t, err, "Error while creating ledger: %s", err)
+	defer ledger.Close()
+	committer := NewLedgerCommitter(ledger, &validator.MockValidator{})
+
+	simulator, _ := ledger.NewTxSimulator(util.GenerateUUID())
+	simulator.SetState("ns1", "key1", []byte("value1"))
+	simulator.Done()
+	simRes, _ := simulator.GetTxSimulationResults()
+	simResBytes, _ := simRes.GetPubSimulationBytes()
+	block1 := testutil.ConstructBlock(t, 1, gb.Header.Hash(), [][]byte{simResBytes}, true)
+	txID, err := extractTxID(block1.Data.Data[0])
+	assert.NoError(t, err)
+
+	listener := committer.RegisterTxStatusListener(txID)
+	unregistered := committer.RegisterTxStatusListener(txID)
+	committer.UnregisterTxStatusListener(txID, unregistered)
+	otherTx := committer.RegisterTxStatusListener("otherTx")
+
+	assert.NoError(t, committer.Commit(block1))
+	expected := &TxStatus{TxID: txID, BlockNumber: 1, ValidationCode: peer.TxValidationCode_VALID}
+	assert.Equal(t, expected, <-listener)
+	_, open := <-listener
+	assert.False(t, open)
+	select {
+	case <-unregistered:
+		t.Fatal("Unregistered listener should not have been notified")
+	case <-otherTx:
+		t.Fatal("Listener of another transaction should not have been notified")
+	default:
+	}
+
+	// the status of a transaction committed before a listener gets registered is looked up
+	txStatus, err := committer.CommittedTxStatus(txID)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, txStatus)
+	txStatus, err = committer.CommittedTxStatus("otherTx")
+	assert.NoError(t, err)
+	assert.Nil(t, txStatus)
+	committer.UnregisterTxStatusListener("otherTx", otherTx)
+	assert.Empty(t, committer.notifier.listeners)
+}
@@ -0,0 +1,125 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package committer
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/hyperledger/fabric/core/ledger/util"
+	"github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/peer"
+	"github.com/hyperledger/fabric/protos/utils"
+)
+
+// TxStatus is the status a transaction was committed with
+type TxStatus struct {
+	TxID           string
+	BlockNumber    uint64
+	ValidationCode peer.TxValidationCode
+}
+
+// TxStatusNotifier keeps the listeners registered for the IDs of transactions that
+// are not committed yet, and notifies them once the transactions get committed.
+// The transactions of a committed block are only looked at when there are listeners
+type TxStatusNotifier struct {
+	lock      sync.Mutex
+	listeners map[string][]chan *TxStatus
+}
+
+// NewTxStatusNotifier creates a TxStatusNotifier without listeners
+func NewTxStatusNotifier() *TxStatusNotifier {
+	return &TxStatusNotifier{listeners: make(map[string][]chan *TxStatus)}
+}
+
+// RegisterTxStatusListener returns a channel that receives the status of the transaction with
+// the given ID once it is committed, after which the channel is closed. The status is buffered in
+// the channel, so the block commit is not held by a listener that does not read it
+func (n *TxStatusNotifier) RegisterTxStatusListener(txID string) <-chan *TxStatus {
+	ch := make(chan *TxStatus, 1)
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	n.listeners[txID] = append(n.listeners[txID], ch)
+	return ch
+}
+
+// UnregisterTxStatusListener removes a listener that is not interested in the status of the transaction anymore
+func (n *TxStatusNotifier) UnregisterTxStatusListener(txID string, listener <-chan *TxStatus) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	chans := n.listeners[txID]
+	for i, ch := range chans {
+		if ch == listener {
+			chans = append(chans[:i], chans[i+1:]...)
+			break
+		}
+	}
+	if len(chans) == 0 {
+		delete(n.listeners, txID)
+		return
+	}
+	n.listeners[txID] = chans
+}
+
+// notifyBlock notifies the listeners of the transactions of the given committed block
+func (n *TxStatusNotifier) notifyBlock(block *common.Block) {
+	n.lock.Lock()
+	noListeners := len(n.listeners) == 0
+	n.lock.Unlock()
+	if noListeners {
+		return
+	}
+	txsFilter := util.TxValidationFlags(block.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER])
+	if len(txsFilter) != len(block.Data.Data) {
+		logger.Warningf("Not notifying the status of the transactions of block %d, the block has no valid transactions filter", block.Header.Number)
+		return
+	}
+	for txIndex, envBytes := range block.Data.Data {
+		txID, err := extractTxID(envBytes)
+		if err != nil {
+			logger.Debugf("Not notifying the status of transaction %d of block %d, the transaction ID cannot be extracted: %s",
+				txIndex, block.Header.Number, err)
+			continue
+		}
+		// a transaction whose ID repeats one already committed is marked invalid, and must not
+		// override the status notified for the transaction committed first
+		if txsFilter.Flag(txIndex) == peer.TxValidationCode_DUPLICATE_TXID {
+			continue
+		}
+		n.notify(&TxStatus{TxID: txID, BlockNumber: block.Header.Number, ValidationCode: txsFilter.Flag(txIndex)})
+	}
+}
+
+// notify sends the given status to the listeners of the transaction
+func (n *TxStatusNotifier) notify(status *TxStatus) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	for _, ch := range n.listeners[status.TxID] {
+		ch <- status
+		close(ch)
+	}
+	delete(n.listeners, status.TxID)
+}
+
+func extractTxID(envBytes []byte) (string, error) {
+	env, err := utils.GetEnvelopeFromBlock(envBytes)
+	if err != nil {
+		return "", err
+	}
+	payload, err := utils.GetPayload(env)
+	if err != nil {
+		return "", err
+	}
+	if payload.Header == nil {
+		return "", errors.New("missing payload header")
+	}
+	chdr, err := utils.UnmarshalChannelHeader(payload.Header.ChannelHeader)
+	if err != nil {
+		return "", err
+	}
+	return chdr.TxId, nil
+}
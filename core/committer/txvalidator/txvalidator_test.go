@@ -17,11 +17,13 @@ limitations under the License.
 package txvalidator
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric/common/configtx/test"
 	"github.com/hyperledger/fabric/common/ledger/testutil"
+	mockchannelconfig "github.com/hyperledger/fabric/common/mocks/config"
 	util2 "github.com/hyperledger/fabric/common/util"
 	"github.com/hyperledger/fabric/core/common/sysccprovider"
 	"github.com/hyperledger/fabric/core/ledger/ledgermgmt"
@@ -99,6 +101,48 @@ func TestBlockValidation(t *testing.T) {
 	*/
 }
 
+func TestBlockValidationDuplicateTXIdInBlock(t *testing.T) {
+	viper.Set("peer.fileSystemPath", "/tmp/fabric/txvalidatortest")
+	ledgermgmt.InitializeTestEnv()
+	defer ledgermgmt.CleanupTestEnv()
+
+	gb, _ := test.MakeGenesisBlock("TestLedger")
+	ledger, _ := ledgermgmt.CreateLedger(gb)
+	defer ledger.Close()
+
+	env, _, err := testutil.ConstructTransaction(t, []byte("simulation results"), "", true)
+	assert.NoError(t, err)
+	envBytes := utils.MarshalOrPanic(env)
+
+	newBlock := func() *common.Block {
+		block := common.NewBlock(1, gb.Header.Hash())
+		block.Data.Data = [][]byte{envBytes, envBytes}
+		block.Header.DataHash = block.Data.Hash()
+		return block
+	}
+
+	// Without the capability, both transactions are valid
+	block := newBlock()
+	tValidator := &txValidator{&mocktxvalidator.Support{LedgerVal: ledger}, &validator.MockVsccValidator{}}
+	assert.NoError(t, tValidator.Validate(block))
+	txsfltr := util.TxValidationFlags(block.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER])
+	assert.True(t, txsfltr.IsSetTo(0, peer.TxValidationCode_VALID))
+	assert.True(t, txsfltr.IsSetTo(1, peer.TxValidationCode_VALID))
+
+	// With it, the second one is a duplicate
+	block = newBlock()
+	capabilities := &mockchannelconfig.ApplicationCapabilities{ForbidDuplicateTXIdInBlockVal: true}
+	tValidator = &txValidator{&mocktxvalidator.Support{LedgerVal: ledger, CapabilitiesVal: capabilities}, &validator.MockVsccValidator{}}
+	assert.NoError(t, tValidator.Validate(block))
+	txsfltr = util.TxValidationFlags(block.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER])
+	assert.True(t, txsfltr.IsSetTo(0, peer.TxValidationCode_VALID))
+	assert.True(t, txsfltr.IsSetTo(1, peer.TxValidationCode_DUPLICATE_TXID))
+
+	// Blocks of channels requiring unsupported capabilities are not validated
+	capabilities.SupportedErr = fmt.Errorf("Application capability V9_9 is required but not supported")
+	assert.Error(t, tValidator.Validate(newBlock()))
+}
+
 func TestNewTxValidator_DuplicateTransactions(t *testing.T) {
 	viper.Set("peer.fileSystemPath", "/tmp/fabric/txvalidatortest")
 	ledgermgmt.InitializeTestEnv()
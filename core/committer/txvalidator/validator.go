@@ -10,6 +10,7 @@ import (
 	"fmt"
 
 	"github.com/golang/protobuf/proto"
+	channelconfig "github.com/hyperledger/fabric/common/config/channel"
 	"github.com/hyperledger/fabric/common/configtx"
 	"github.com/hyperledger/fabric/common/flogging"
 	coreUtil "github.com/hyperledger/fabric/common/util"
@@ -19,10 +20,12 @@ import (
 	"github.com/hyperledger/fabric/core/common/validation"
 	"github.com/hyperledger/fabric/core/ledger"
 	ledgerUtil "github.com/hyperledger/fabric/core/ledger/util"
+	"github.com/hyperledger/fabric/core/scc/lifecycle"
 	"github.com/hyperledger/fabric/msp"
 
 	"github.com/hyperledger/fabric/protos/common"
 	"github.com/hyperledger/fabric/protos/peer"
+	lc "github.com/hyperledger/fabric/protos/peer/lifecycle"
 	"github.com/hyperledger/fabric/protos/utils"
 	"github.com/op/go-logging"
 
@@ -44,6 +47,9 @@ type Support interface {
 	// GetMSPIDs returns the IDs for the application MSPs
 	// that have been defined in the channel
 	GetMSPIDs(cid string) []string
+
+	// Capabilities defines the capabilities for the application portion of this channel
+	Capabilities() channelconfig.ApplicationCapabilities
 }
 
 //Validator interface which defines API to validate block transactions
@@ -91,6 +97,14 @@ func (e VSCCInfoLookupFailureError) Error() string {
 // failed endrosement policy check
 type VSCCEndorsementPolicyError struct {
 	reason string
+	// namespace is the chaincode whose endorsement policy was not satisfied
+	namespace string
+	// code is the validation code vscc marked the failure with, such as a violation of
+	// the instantiation policy of a deployed chaincode; it is unset for endorsement policy failures
+	code peer.TxValidationCode
+	// policy is the name of the violated policy vscc marked the failure with or, for
+	// endorsement policy failures, the path of the endorsement policy that was evaluated
+	policy string
 }
 
 // Error returns reasons which lead to the failure
@@ -98,6 +112,14 @@ func (e VSCCEndorsementPolicyError) Error() string {
 	return e.reason
 }
 
+// validationCode returns the validation code of the transaction that failed the check
+func (e VSCCEndorsementPolicyError) validationCode() peer.TxValidationCode {
+	if e.code == peer.TxValidationCode_VALID {
+		return peer.TxValidationCode_ENDORSEMENT_POLICY_FAILURE
+	}
+	return e.code
+}
+
 // VSCCExecutionFailureError error to indicate
 // failure during attempt of executing VSCC
 // endorsement policy check
@@ -110,11 +132,19 @@ func (e VSCCExecutionFailureError) Error() string {
 	return e.reason
 }
 
+// SysCCEndorsementPolicyPath is the path recorded in the validation reason of an invocation of
+// a system chaincode whose endorsements are not signed by any member of the channel
+const SysCCEndorsementPolicyPath = "SignedByAnyMember"
+
 var logger *logging.Logger // package-level logger
 
+// txLogger attaches the channel and the id of the transactions to the records logged while validating them
+var txLogger *flogging.ContextLogger
+
 func init() {
 	// Init logger with module name
 	logger = flogging.MustGetLogger("txvalidator")
+	txLogger = flogging.MustGetContextLogger("txvalidator")
 }
 
 // NewTxValidator creates new transactions validator
@@ -135,12 +165,26 @@ func (v *txValidator) chainExists(chain string) bool {
 func (v *txValidator) Validate(block *common.Block) error {
 	logger.Debug("START Block Validation")
 	defer logger.Debug("END Block Validation")
+
+	capabilities := v.support.Capabilities()
+	if err := capabilities.Supported(); err != nil {
+		err = fmt.Errorf("Channel requires application capabilities this peer does not support: %s", err)
+		logger.Critical(err)
+		return err
+	}
+
 	// Initialize trans as valid here, then set invalidation reason code upon invalidation below
 	txsfltr := ledgerUtil.NewTxValidationFlags(len(block.Data.Data))
 	// txsChaincodeNames records all the invoked chaincodes by tx in a block
 	txsChaincodeNames := make(map[int]*sysccprovider.ChaincodeInstance)
 	// upgradedChaincodes records all the chaincodes that are upgraded in a block
 	txsUpgradedChaincodes := make(map[int]*sysccprovider.ChaincodeInstance)
+	// txsReasons records the reasons of the transactions invalidated by vscc
+	var txsReasons []*peer.TxValidationReason
+	// txIDs records the TxIds of the endorser transactions in the block
+	txIDs := make(map[string]struct{})
+	// metadataUpdates records the keys whose metadata the valid transactions of the block update
+	metadataUpdates := make(keySet)
 	for tIdx, d := range block.Data.Data {
 		if d != nil {
 			if env, err := utils.GetEnvelopeFromBlock(d); err != nil {
@@ -156,6 +200,7 @@ func (v *txValidator) Validate(block *common.Block) error {
 				var payload *common.Payload
 				var err error
 				var txResult peer.TxValidationCode
+				var txRWSet *rwsetutil.TxRwSet
 
 				if payload, txResult = validation.ValidateTransaction(env); txResult != peer.TxValidationCode_VALID {
 					logger.Errorf("Invalid transaction with index %d", tIdx)
@@ -182,18 +227,26 @@ func (v *txValidator) Validate(block *common.Block) error {
 				if common.HeaderType(chdr.Type) == common.HeaderType_ENDORSER_TRANSACTION {
 					// Check duplicate transactions
 					txID := chdr.TxId
+					txLogger := txLogger.WithChannel(channel).WithTxID(txID)
 					if _, err := v.support.Ledger().GetTransactionByID(txID); err == nil {
-						logger.Error("Duplicate transaction found, ", txID, ", skipping")
+						txLogger.Errorf("Duplicate transaction found, skipping")
 						txsfltr.SetFlag(tIdx, peer.TxValidationCode_DUPLICATE_TXID)
 						continue
 					}
+					if capabilities.ForbidDuplicateTXIdInBlock() {
+						if _, ok := txIDs[txID]; ok {
+							txLogger.Errorf("Duplicate transaction found in block, skipping")
+							txsfltr.SetFlag(tIdx, peer.TxValidationCode_DUPLICATE_TXID)
+							continue
+						}
+						txIDs[txID] = struct{}{}
+					}
 
 					// Validate tx with vscc and policy
-					logger.Debug("Validating transaction vscc tx validate")
+					txLogger.Debugf("Validating transaction vscc tx validate")
 					err, cde := v.vscc.VSCCValidateTx(payload, d, env)
 					if err != nil {
-						txID := txID
-						logger.Errorf("VSCCValidateTx for transaction txId = %s returned error %s", txID, err)
+						txLogger.Errorf("VSCCValidateTx returned error %s", err)
 						switch err.(type) {
 						case *VSCCExecutionFailureError:
 							return err
@@ -201,19 +254,38 @@ func (v *txValidator) Validate(block *common.Block) error {
 							return err
 						default:
 							txsfltr.SetFlag(tIdx, cde)
+							txsReasons = append(txsReasons, newVSCCValidationReason(tIdx, cde, err))
+							continue
+						}
+					}
+
+					// vscc evaluated the key-level endorsement policies against the committed state, so
+					// the endorsements of a write to a key whose metadata a preceding transaction of the
+					// block updates were not checked against the policy in force
+					if capabilities.KeyLevelEndorsement() {
+						if txRWSet, err = getTxRWSet(d); err != nil {
+							txLogger.Errorf("Get read-write set from transaction returned error %s", err)
+							txsfltr.SetFlag(tIdx, peer.TxValidationCode_BAD_RWSET)
+							continue
+						}
+						if reason := metadataUpdates.validateWrites(txRWSet); reason != nil {
+							txLogger.Warningf("Transaction writes key %s in namespace %s whose metadata was updated in the same block", reason.Key, reason.Namespace)
+							reason.TxIndex = uint64(tIdx)
+							txsfltr.SetFlag(tIdx, reason.Code)
+							txsReasons = append(txsReasons, reason)
 							continue
 						}
 					}
 
 					invokeCC, upgradeCC, err := v.getTxCCInstance(payload)
 					if err != nil {
-						logger.Errorf("Get chaincode instance from transaction txId = %s returned error %s", txID, err)
+						txLogger.Errorf("Get chaincode instance from transaction returned error %s", err)
 						txsfltr.SetFlag(tIdx, peer.TxValidationCode_INVALID_OTHER_REASON)
 						continue
 					}
 					txsChaincodeNames[tIdx] = invokeCC
 					if upgradeCC != nil {
-						logger.Infof("Find chaincode upgrade transaction for chaincode %s on chain %s with new version %s", upgradeCC.ChaincodeName, upgradeCC.ChainID, upgradeCC.ChaincodeVersion)
+						txLogger.Infof("Find chaincode upgrade transaction for chaincode %s with new version %s", upgradeCC.ChaincodeName, upgradeCC.ChaincodeVersion)
 						txsUpgradedChaincodes[tIdx] = upgradeCC
 					}
 				} else if common.HeaderType(chdr.Type) == common.HeaderType_CONFIG {
@@ -244,6 +316,9 @@ func (v *txValidator) Validate(block *common.Block) error {
 				}
 				// Succeeded to pass down here, transaction is valid
 				txsfltr.SetFlag(tIdx, peer.TxValidationCode_VALID)
+				if txRWSet != nil {
+					metadataUpdates.addMetadataWrites(txRWSet)
+				}
 			} else {
 				logger.Warning("Nil tx from block")
 				txsfltr.SetFlag(tIdx, peer.TxValidationCode_NIL_ENVELOPE)
@@ -258,9 +333,88 @@ func (v *txValidator) Validate(block *common.Block) error {
 
 	block.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER] = txsfltr
 
+	return ledgerUtil.AddTxValidationReasons(block, txsReasons...)
+}
+
+// keySet holds keys by namespace
+type keySet map[string]map[string]bool
+
+func (s keySet) add(ns, key string) {
+	if s[ns] == nil {
+		s[ns] = make(map[string]bool)
+	}
+	s[ns][key] = true
+}
+
+func (s keySet) contains(ns, key string) bool {
+	return s[ns][key]
+}
+
+// addMetadataWrites adds the keys whose metadata the transaction updates
+func (s keySet) addMetadataWrites(txRWSet *rwsetutil.TxRwSet) {
+	for _, nsRWSet := range txRWSet.NsRwSets {
+		for _, metadataWrite := range nsRWSet.KvRwSet.MetadataWrites {
+			s.add(nsRWSet.NameSpace, metadataWrite.Key)
+		}
+	}
+}
+
+// validateWrites returns the reason invalidating a transaction that writes the value or the
+// metadata of a key of the set, or nil if the transaction writes none of them
+func (s keySet) validateWrites(txRWSet *rwsetutil.TxRwSet) *peer.TxValidationReason {
+	if len(s) == 0 {
+		return nil
+	}
+	for _, nsRWSet := range txRWSet.NsRwSets {
+		ns := nsRWSet.NameSpace
+		var writtenKeys []string
+		for _, kvWrite := range nsRWSet.KvRwSet.Writes {
+			writtenKeys = append(writtenKeys, kvWrite.Key)
+		}
+		for _, metadataWrite := range nsRWSet.KvRwSet.MetadataWrites {
+			writtenKeys = append(writtenKeys, metadataWrite.Key)
+		}
+		for _, key := range writtenKeys {
+			if s.contains(ns, key) {
+				return &peer.TxValidationReason{
+					Code:      peer.TxValidationCode_ENDORSEMENT_POLICY_FAILURE,
+					Namespace: ns,
+					Key:       key,
+					Message:   "the metadata of the key written was updated in the same block",
+				}
+			}
+		}
+	}
 	return nil
 }
 
+// getTxRWSet returns the read-write set of the endorser transaction in the envelope
+func getTxRWSet(envBytes []byte) (*rwsetutil.TxRwSet, error) {
+	respPayload, err := utils.GetActionFromEnvelope(envBytes)
+	if err != nil {
+		return nil, fmt.Errorf("GetActionFromEnvelope failed, error %s", err)
+	}
+	txRWSet := &rwsetutil.TxRwSet{}
+	if err = txRWSet.FromProtoBytes(respPayload.Results); err != nil {
+		return nil, fmt.Errorf("txRWSet.FromProtoBytes failed, error %s", err)
+	}
+	return txRWSet, nil
+}
+
+// newVSCCValidationReason returns the reason of a transaction that vscc invalidated with the given error
+func newVSCCValidationReason(tIdx int, code peer.TxValidationCode, err error) *peer.TxValidationReason {
+	reason := &peer.TxValidationReason{
+		TxIndex: uint64(tIdx),
+		Code:    code,
+		Message: err.Error(),
+	}
+	if policyErr, ok := err.(*VSCCEndorsementPolicyError); ok {
+		reason.Namespace = policyErr.namespace
+		reason.Policy = policyErr.policy
+	}
+	return reason
+}
+
 // generateCCKey generates a unique identifier for chaincode in specific chain
 func (v *txValidator) generateCCKey(ccName, chainID string) string {
 	return fmt.Sprintf("%s/%s", ccName, chainID)
@@ -387,11 +541,13 @@ func (v *txValidator) getUpgradeTxInstance(chainID string, cdsBytes []byte) (*sy
 	}, nil
 }
 
-// GetInfoForValidate gets the ChaincodeInstance(with latest version) of tx, vscc and policy from lscc
-func (v *vsccValidatorImpl) GetInfoForValidate(txid, chID, ccID string) (*sysccprovider.ChaincodeInstance, *sysccprovider.ChaincodeInstance, []byte, error) {
+// GetInfoForValidate gets the ChaincodeInstance(with latest version) of tx, vscc and policy from lscc,
+// along with the path the policy was read from
+func (v *vsccValidatorImpl) GetInfoForValidate(txid, chID, ccID string) (*sysccprovider.ChaincodeInstance, *sysccprovider.ChaincodeInstance, []byte, string, error) {
 	cc := &sysccprovider.ChaincodeInstance{ChainID: chID}
 	vscc := &sysccprovider.ChaincodeInstance{ChainID: chID}
 	var policy []byte
+	var policyPath string
 	var err error
 	if !sysccprovider.GetSystemChaincodeProvider().IsSysCC(ccID) {
 		// when we are validating a chaincode that is not a
@@ -399,16 +555,17 @@ func (v *vsccValidatorImpl) GetInfoForValidate(txid, chID, ccID string) (*sysccp
 		// of VSCC and of the policy that should be used
 
 		// obtain name of the VSCC and the policy from LSCC
-		cd, err := v.getCDataForCC(ccID)
+		cd, cdPath, err := v.getCDataForCC(ccID)
 		if err != nil {
 			msg := fmt.Sprintf("Unable to get chaincode data from ledger for txid %s, due to %s", txid, err)
 			logger.Errorf(msg)
-			return nil, nil, nil, err
+			return nil, nil, nil, "", err
 		}
 		cc.ChaincodeName = cd.Name
 		cc.ChaincodeVersion = cd.Version
 		vscc.ChaincodeName = cd.Vscc
 		policy = cd.Policy
+		policyPath = cdPath
 	} else {
 		// when we are validating a system CC, we use the default
 		// VSCC and a default policy that requires one signature
@@ -419,14 +576,15 @@ func (v *vsccValidatorImpl) GetInfoForValidate(txid, chID, ccID string) (*sysccp
 		p := cauthdsl.SignedByAnyMember(v.support.GetMSPIDs(chID))
 		policy, err = utils.Marshal(p)
 		if err != nil {
-			return nil, nil, nil, err
+			return nil, nil, nil, "", err
 		}
+		policyPath = SysCCEndorsementPolicyPath
 	}
 
 	// Get vscc version
 	vscc.ChaincodeVersion = coreUtil.GetSysCCVersion()
 
-	return cc, vscc, policy, nil
+	return cc, vscc, policy, policyPath, nil
 }
 
 func (v *vsccValidatorImpl) VSCCValidateTx(payload *common.Payload, envBytes []byte, env *common.Envelope) (error, peer.TxValidationCode) {
@@ -529,7 +687,7 @@ func (v *vsccValidatorImpl) VSCCValidateTx(payload *common.Payload, envBytes []b
 		// validate *EACH* read write set according to its chaincode's endorsement policy
 		for _, ns := range wrNamespace {
 			// Get latest chaincode version, vscc and validate policy
-			txcc, vscc, policy, err := v.GetInfoForValidate(chdr.TxId, chdr.ChannelId, ns)
+			txcc, vscc, policy, policyPath, err := v.GetInfoForValidate(chdr.TxId, chdr.ChannelId, ns)
 			if err != nil {
 				logger.Errorf("GetInfoForValidate for txId = %s returned error %s", chdr.TxId, err)
 				return err, peer.TxValidationCode_INVALID_OTHER_REASON
@@ -546,9 +704,13 @@ func (v *vsccValidatorImpl) VSCCValidateTx(payload *common.Payload, envBytes []b
 
 			// do VSCC validation
 			if err = v.VSCCValidateTxForCC(envBytes, chdr.TxId, chdr.ChannelId, vscc.ChaincodeName, vscc.ChaincodeVersion, policy); err != nil {
-				switch err.(type) {
+				switch e := err.(type) {
 				case *VSCCEndorsementPolicyError:
-					return err, peer.TxValidationCode_ENDORSEMENT_POLICY_FAILURE
+					e.namespace = ns
+					if e.policy == "" {
+						e.policy = policyPath
+					}
+					return err, e.validationCode()
 				default:
 					return err, peer.TxValidationCode_INVALID_OTHER_REASON
 				}
@@ -563,9 +725,14 @@ func (v *vsccValidatorImpl) VSCCValidateTx(payload *common.Payload, envBytes []b
 			return fmt.Errorf("Committing an invocation of cc %s is illegal", ccID),
 				peer.TxValidationCode_ILLEGAL_WRITESET
 		}
+		// _lifecycle may only be invoked once the application capabilities of the channel enable it
+		if ccID == lifecycle.Name && !v.support.Capabilities().Lifecycle() {
+			return fmt.Errorf("Committing an invocation of cc %s is illegal, the application capabilities of the channel do not enable it", ccID),
+				peer.TxValidationCode_ILLEGAL_WRITESET
+		}
 
 		// Get latest chaincode version, vscc and validate policy
-		_, vscc, policy, err := v.GetInfoForValidate(chdr.TxId, chdr.ChannelId, ccID)
+		_, vscc, policy, policyPath, err := v.GetInfoForValidate(chdr.TxId, chdr.ChannelId, ccID)
 		if err != nil {
 			logger.Errorf("GetInfoForValidate for txId = %s returned error %s", chdr.TxId, err)
 			return err, peer.TxValidationCode_INVALID_OTHER_REASON
@@ -577,9 +744,13 @@ func (v *vsccValidatorImpl) VSCCValidateTx(payload *common.Payload, envBytes []b
 		// user creates a new system chaincode which is invokable from the outside
 		// they have to modify VSCC to provide appropriate validation
 		if err = v.VSCCValidateTxForCC(envBytes, chdr.TxId, vscc.ChainID, vscc.ChaincodeName, vscc.ChaincodeVersion, policy); err != nil {
-			switch err.(type) {
+			switch e := err.(type) {
 			case *VSCCEndorsementPolicyError:
-				return err, peer.TxValidationCode_ENDORSEMENT_POLICY_FAILURE
+				e.namespace = ccID
+				if e.policy == "" {
+					e.policy = policyPath
+				}
+				return err, e.validationCode()
 			default:
 				return err, peer.TxValidationCode_INVALID_OTHER_REASON
 			}
@@ -618,46 +789,102 @@ func (v *vsccValidatorImpl) VSCCValidateTxForCC(envBytes []byte, txid, chid, vsc
 	}
 	if res.Status != shim.OK {
 		logger.Errorf("VSCC check failed for transaction txid=%s, error %s", txid, res.Message)
-		return &VSCCEndorsementPolicyError{fmt.Sprintf("%s", res.Message)}
+		policyErr := &VSCCEndorsementPolicyError{reason: fmt.Sprintf("%s", res.Message)}
+		// vscc may tell the validation code of the failure in the payload of the response
+		if len(res.Payload) > 0 {
+			vsccReason := &peer.TxValidationReason{}
+			if err := proto.Unmarshal(res.Payload, vsccReason); err == nil {
+				policyErr.code = vsccReason.Code
+				policyErr.policy = vsccReason.Policy
+			}
+		}
+		return policyErr
 	}
 
 	return nil
 }
 
-func (v *vsccValidatorImpl) getCDataForCC(ccid string) (*ccprovider.ChaincodeData, error) {
+// getCDataForCC returns the chaincode data of a chaincode, along with the path of its
+// endorsement policy, which is made of the namespace, the key and the field it is stored in
+func (v *vsccValidatorImpl) getCDataForCC(ccid string) (*ccprovider.ChaincodeData, string, error) {
 	l := v.support.Ledger()
 	if l == nil {
-		return nil, fmt.Errorf("nil ledger instance")
+		return nil, "", fmt.Errorf("nil ledger instance")
 	}
 
 	qe, err := l.NewQueryExecutor()
 	if err != nil {
-		return nil, fmt.Errorf("Could not retrieve QueryExecutor, error %s", err)
+		return nil, "", fmt.Errorf("Could not retrieve QueryExecutor, error %s", err)
 	}
 	defer qe.Done()
 
+	// a definition committed through _lifecycle takes precedence over the one of lscc,
+	// once the application capabilities of the channel enable _lifecycle
+	if v.support.Capabilities().Lifecycle() {
+		defBytes, err := qe.GetState(lifecycle.Name, lifecycle.DefinitionKey(ccid))
+		if err != nil {
+			return nil, "", &VSCCInfoLookupFailureError{fmt.Sprintf("Could not retrieve definition of chaincode %s, error %s", ccid, err)}
+		}
+		if defBytes != nil {
+			cd, err := definitionToCData(defBytes)
+			return cd, statePolicyPath(lifecycle.Name, lifecycle.DefinitionKey(ccid), "EndorsementPolicy"), err
+		}
+	}
+
 	bytes, err := qe.GetState("lscc", ccid)
 	if err != nil {
-		return nil, &VSCCInfoLookupFailureError{fmt.Sprintf("Could not retrieve state for chaincode %s, error %s", ccid, err)}
+		return nil, "", &VSCCInfoLookupFailureError{fmt.Sprintf("Could not retrieve state for chaincode %s, error %s", ccid, err)}
 	}
 
 	if bytes == nil {
-		return nil, fmt.Errorf("lscc's state for [%s] not found.", ccid)
+		return nil, "", fmt.Errorf("lscc's state for [%s] not found.", ccid)
 	}
 
 	cd := &ccprovider.ChaincodeData{}
 	err = proto.Unmarshal(bytes, cd)
 	if err != nil {
-		return nil, fmt.Errorf("Unmarshalling ChaincodeQueryResponse failed, error %s", err)
+		return nil, "", fmt.Errorf("Unmarshalling ChaincodeQueryResponse failed, error %s", err)
 	}
 
 	if cd.Vscc == "" {
-		return nil, fmt.Errorf("lscc's state for [%s] is invalid, vscc field must be set.", ccid)
+		return nil, "", fmt.Errorf("lscc's state for [%s] is invalid, vscc field must be set.", ccid)
 	}
 
 	if len(cd.Policy) == 0 {
-		return nil, fmt.Errorf("lscc's state for [%s] is invalid, policy field must be set.", ccid)
+		return nil, "", fmt.Errorf("lscc's state for [%s] is invalid, policy field must be set.", ccid)
 	}
 
-	return cd, err
+	return cd, statePolicyPath("lscc", ccid, "Policy"), err
+}
+
+// statePolicyPath returns the path of a policy stored in the given field of the value of a key of a namespace
+func statePolicyPath(namespace, key, field string) string {
+	return "/" + namespace + "/" + key + "/" + field
+}
+
+// definitionToCData returns the chaincode data of a chaincode definition committed through
+// _lifecycle, which names the default escc and vscc if the definition doesn't name them
+func definitionToCData(defBytes []byte) (*ccprovider.ChaincodeData, error) {
+	def := &lc.ChaincodeDefinition{}
+	if err := proto.Unmarshal(defBytes, def); err != nil {
+		return nil, fmt.Errorf("Unmarshalling ChaincodeDefinition failed, error %s", err)
+	}
+	cd := &ccprovider.ChaincodeData{
+		Name:    def.Name,
+		Version: def.Version,
+		Escc:    def.Escc,
+		Vscc:    def.Vscc,
+		Policy:  def.EndorsementPolicy,
+		Id:      def.Id,
+	}
+	if cd.Escc == "" {
+		cd.Escc = "escc"
+	}
+	if cd.Vscc == "" {
+		cd.Vscc = "vscc"
+	}
+	if len(cd.Policy) == 0 {
+		return nil, fmt.Errorf("definition of chaincode %s is invalid, endorsement policy must be set", def.Name)
+	}
+	return cd, nil
 }
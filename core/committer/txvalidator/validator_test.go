@@ -23,9 +23,12 @@ import (
 	"testing"
 
 	"github.com/hyperledger/fabric/common/cauthdsl"
+	channelconfig "github.com/hyperledger/fabric/common/config/channel"
 	ctxt "github.com/hyperledger/fabric/common/configtx/test"
 	ledger2 "github.com/hyperledger/fabric/common/ledger"
+	"github.com/hyperledger/fabric/common/ledger/blkstorage"
 	"github.com/hyperledger/fabric/common/ledger/testutil"
+	mockchannelconfig "github.com/hyperledger/fabric/common/mocks/config"
 	"github.com/hyperledger/fabric/common/mocks/scc"
 	"github.com/hyperledger/fabric/common/util"
 	"github.com/hyperledger/fabric/core/chaincode/shim"
@@ -33,14 +36,17 @@ import (
 	"github.com/hyperledger/fabric/core/common/sysccprovider"
 	"github.com/hyperledger/fabric/core/ledger"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/rwsetutil"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statemetadata"
 	"github.com/hyperledger/fabric/core/ledger/ledgermgmt"
 	lutils "github.com/hyperledger/fabric/core/ledger/util"
 	"github.com/hyperledger/fabric/core/mocks/ccprovider"
+	"github.com/hyperledger/fabric/core/scc/lifecycle"
 	"github.com/hyperledger/fabric/msp"
 	"github.com/hyperledger/fabric/msp/mgmt"
 	"github.com/hyperledger/fabric/msp/mgmt/testtools"
 	"github.com/hyperledger/fabric/protos/common"
 	"github.com/hyperledger/fabric/protos/peer"
+	lc "github.com/hyperledger/fabric/protos/peer/lifecycle"
 	"github.com/hyperledger/fabric/protos/utils"
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
@@ -135,8 +141,33 @@ func putCCInfo(theLedger ledger.PeerLedger, ccname string, policy []byte, t *tes
 	putCCInfoWithVSCCAndVer(theLedger, ccname, "vscc", ccVersion, policy, t)
 }
 
+func putDefinition(theLedger ledger.PeerLedger, ccname, ver string, policy []byte, t *testing.T) {
+	def := &lc.ChaincodeDefinition{
+		Name:              ccname,
+		Version:           ver,
+		Sequence:          1,
+		EndorsementPolicy: policy,
+	}
+
+	simulator, err := theLedger.NewTxSimulator(util.GenerateUUID())
+	assert.NoError(t, err)
+	simulator.SetState(lifecycle.Name, lifecycle.DefinitionKey(ccname), utils.MarshalOrPanic(def))
+	simulator.Done()
+
+	simRes, err := simulator.GetTxSimulationResults()
+	assert.NoError(t, err)
+	pubSimulationBytes, err := simRes.GetPubSimulationBytes()
+	assert.NoError(t, err)
+	bcInfo, err := theLedger.GetBlockchainInfo()
+	assert.NoError(t, err)
+	block := testutil.ConstructBlock(t, bcInfo.Height, bcInfo.CurrentBlockHash, [][]byte{pubSimulationBytes}, true)
+	err = theLedger.Commit(block)
+	assert.NoError(t, err)
+}
+
 type mockSupport struct {
-	l ledger.PeerLedger
+	l   ledger.PeerLedger
+	acs channelconfig.ApplicationCapabilities
 }
 
 func (m *mockSupport) Ledger() ledger.PeerLedger {
@@ -155,6 +186,13 @@ func (m *mockSupport) GetMSPIDs(cid string) []string {
 	return []string{"DEFAULT"}
 }
 
+func (m *mockSupport) Capabilities() channelconfig.ApplicationCapabilities {
+	if m.acs == nil {
+		return &mockchannelconfig.ApplicationCapabilities{}
+	}
+	return m.acs
+}
+
 func assertInvalid(block *common.Block, t *testing.T, code peer.TxValidationCode) {
 	txsFilter := lutils.TxValidationFlags(block.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER])
 	assert.True(t, txsFilter.IsInvalid(0))
@@ -181,6 +219,66 @@ func TestInvokeBadRWSet(t *testing.T) {
 	assertInvalid(b, t, peer.TxValidationCode_BAD_RWSET)
 }
 
+func TestInvokeWritesAfterMetadataUpdates(t *testing.T) {
+	l, _ := setupLedgerAndValidator(t)
+	defer ledgermgmt.CleanupTestEnv()
+	defer l.Close()
+
+	ccID := "mycc"
+
+	putCCInfo(l, ccID, signedByAnyMember([]string{"DEFAULT"}), t)
+
+	txWith := func(build func(*rwsetutil.RWSetBuilder)) []byte {
+		rwsetBuilder := rwsetutil.NewRWSetBuilder()
+		build(rwsetBuilder)
+		rwset, err := rwsetBuilder.GetTxSimulationResults()
+		assert.NoError(t, err)
+		rwsetBytes, err := rwset.GetPubSimulationBytes()
+		assert.NoError(t, err)
+		return utils.MarshalOrPanic(getEnv(ccID, rwsetBytes, t))
+	}
+	newBlock := func() *common.Block {
+		return &common.Block{Data: &common.BlockData{Data: [][]byte{
+			// tx0 updates the metadata of key1
+			txWith(func(b *rwsetutil.RWSetBuilder) {
+				b.AddToMetadataWriteSet(ccID, "key1", statemetadata.ToEntries(map[string][]byte{"name": []byte("value")}))
+			}),
+			// tx1 writes key1, whose metadata tx0 updates
+			txWith(func(b *rwsetutil.RWSetBuilder) { b.AddToWriteSet(ccID, "key1", []byte("value")) }),
+			// tx2 updates the metadata of key1, which tx0 updates
+			txWith(func(b *rwsetutil.RWSetBuilder) { b.AddToMetadataWriteSet(ccID, "key1", nil) }),
+			// tx3 writes key2
+			txWith(func(b *rwsetutil.RWSetBuilder) { b.AddToWriteSet(ccID, "key2", []byte("value")) }),
+		}}}
+	}
+
+	// the application capabilities enable the key level endorsement policies
+	v := NewTxValidator(&mockSupport{l: l, acs: &mockchannelconfig.ApplicationCapabilities{KeyLevelEndorsementVal: true}})
+	b := newBlock()
+	err := v.Validate(b)
+	assert.NoError(t, err)
+	txsFilter := lutils.TxValidationFlags(b.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER])
+	assert.True(t, txsFilter.IsValid(0))
+	assert.True(t, txsFilter.IsSetTo(1, peer.TxValidationCode_ENDORSEMENT_POLICY_FAILURE))
+	assert.True(t, txsFilter.IsSetTo(2, peer.TxValidationCode_ENDORSEMENT_POLICY_FAILURE))
+	assert.True(t, txsFilter.IsValid(3))
+	reasons, err := lutils.GetTxValidationReasons(b)
+	assert.NoError(t, err)
+	reason := reasons[1]
+	assert.Equal(t, ccID, reason.Namespace)
+	assert.Equal(t, "key1", reason.Key)
+
+	// they don't
+	v = NewTxValidator(&mockSupport{l: l})
+	b = newBlock()
+	err = v.Validate(b)
+	assert.NoError(t, err)
+	txsFilter = lutils.TxValidationFlags(b.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER])
+	for i := range b.Data.Data {
+		assert.True(t, txsFilter.IsValid(i))
+	}
+}
+
 func TestInvokeNoPolicy(t *testing.T) {
 	l, v := setupLedgerAndValidator(t)
 	defer ledgermgmt.CleanupTestEnv()
@@ -215,6 +313,72 @@ func TestInvokeOK(t *testing.T) {
 	assertValid(b, t)
 }
 
+func TestInvokeOKLifecycleDefinition(t *testing.T) {
+	l, v := setupLedgerAndValidator(t)
+	defer ledgermgmt.CleanupTestEnv()
+	defer l.Close()
+
+	ccID := "mycc"
+
+	// the chaincode is only defined through _lifecycle, with the default vscc
+	putDefinition(l, ccID, ccVersion, signedByAnyMember([]string{"DEFAULT"}), t)
+
+	// the definition is ignored until the application capabilities enable _lifecycle
+	tx := getEnv(ccID, createRWset(t, ccID), t)
+	b := &common.Block{Data: &common.BlockData{Data: [][]byte{utils.MarshalOrPanic(tx)}}}
+	err := v.Validate(b)
+	assert.NoError(t, err)
+	assertInvalid(b, t, peer.TxValidationCode_INVALID_OTHER_REASON)
+
+	v = NewTxValidator(&mockSupport{l: l, acs: &mockchannelconfig.ApplicationCapabilities{LifecycleVal: true}})
+	tx = getEnv(ccID, createRWset(t, ccID), t)
+	b = &common.Block{Data: &common.BlockData{Data: [][]byte{utils.MarshalOrPanic(tx)}}}
+	err = v.Validate(b)
+	assert.NoError(t, err)
+	assertValid(b, t)
+}
+
+func TestInvokeNOKLifecycleDefinitionOverridesLSCC(t *testing.T) {
+	l, v := setupLedgerAndValidator(t)
+	defer ledgermgmt.CleanupTestEnv()
+	defer l.Close()
+
+	ccID := "mycc"
+
+	// the definition committed through _lifecycle is enforced instead of the one
+	// of lscc, so that transactions of the version instantiated through lscc expire
+	putCCInfo(l, ccID, signedByAnyMember([]string{"DEFAULT"}), t)
+	putDefinition(l, ccID, "2.0", signedByAnyMember([]string{"DEFAULT"}), t)
+
+	// once the application capabilities enable _lifecycle
+	tx := getEnv(ccID, createRWset(t, ccID), t)
+	b := &common.Block{Data: &common.BlockData{Data: [][]byte{utils.MarshalOrPanic(tx)}}}
+	err := v.Validate(b)
+	assert.NoError(t, err)
+	assertValid(b, t)
+
+	v = NewTxValidator(&mockSupport{l: l, acs: &mockchannelconfig.ApplicationCapabilities{LifecycleVal: true}})
+	tx = getEnv(ccID, createRWset(t, ccID), t)
+	b = &common.Block{Data: &common.BlockData{Data: [][]byte{utils.MarshalOrPanic(tx)}}}
+	err = v.Validate(b)
+	assert.NoError(t, err)
+	assertInvalid(b, t, peer.TxValidationCode_EXPIRED_CHAINCODE)
+}
+
+func TestInvokeNOKLifecycleNotEnabled(t *testing.T) {
+	l, v := setupLedgerAndValidator(t)
+	defer ledgermgmt.CleanupTestEnv()
+	defer l.Close()
+
+	// _lifecycle cannot be invoked until the application capabilities enable it
+	tx := getEnv(lifecycle.Name, createRWset(t, lifecycle.Name), t)
+	b := &common.Block{Data: &common.BlockData{Data: [][]byte{utils.MarshalOrPanic(tx)}}}
+
+	err := v.Validate(b)
+	assert.NoError(t, err)
+	assertInvalid(b, t, peer.TxValidationCode_ILLEGAL_WRITESET)
+}
+
 func TestInvokeOKSCC(t *testing.T) {
 	l, v := setupLedgerAndValidator(t)
 	defer ledgermgmt.CleanupTestEnv()
@@ -266,6 +430,23 @@ func TestInvokeNOKWritesToESCC(t *testing.T) {
 	assertInvalid(b, t, peer.TxValidationCode_ILLEGAL_WRITESET)
 }
 
+func TestInvokeNOKWritesToLifecycle(t *testing.T) {
+	l, v := setupLedgerAndValidator(t)
+	defer ledgermgmt.CleanupTestEnv()
+	defer l.Close()
+
+	ccID := "mycc"
+
+	putCCInfo(l, ccID, signedByAnyMember([]string{"DEFAULT"}), t)
+
+	tx := getEnv(ccID, createRWset(t, ccID, lifecycle.Name), t)
+	b := &common.Block{Data: &common.BlockData{Data: [][]byte{utils.MarshalOrPanic(tx)}}}
+
+	err := v.Validate(b)
+	assert.NoError(t, err)
+	assertInvalid(b, t, peer.TxValidationCode_ILLEGAL_WRITESET)
+}
+
 func TestInvokeNOKWritesToNotExt(t *testing.T) {
 	l, v := setupLedgerAndValidator(t)
 	defer ledgermgmt.CleanupTestEnv()
@@ -424,6 +605,36 @@ func (m *mockLedger) GetTxValidationCodeByTxID(txID string) (peer.TxValidationCo
 	return args.Get(0).(peer.TxValidationCode), nil
 }
 
+// GetTransactionsByCreator returns the transactions of the given creator
+func (m *mockLedger) GetTransactionsByCreator(creator []byte, start blkstorage.TxPosition, limit int) ([]*peer.ProcessedTransaction, *blkstorage.TxPosition, error) {
+	args := m.Called(creator, start, limit)
+	return args.Get(0).([]*peer.ProcessedTransaction), args.Get(1).(*blkstorage.TxPosition), args.Error(2)
+}
+
+// GetTransactionsByMSPIDAndChaincode returns the transactions of the given org against the given chaincode
+func (m *mockLedger) GetTransactionsByMSPIDAndChaincode(mspID string, chaincodeName string, start blkstorage.TxPosition, limit int) ([]*peer.ProcessedTransaction, *blkstorage.TxPosition, error) {
+	args := m.Called(mspID, chaincodeName, start, limit)
+	return args.Get(0).([]*peer.ProcessedTransaction), args.Get(1).(*blkstorage.TxPosition), args.Error(2)
+}
+
+// GetTxValidationCodes returns validation codes of given txs
+func (m *mockLedger) GetTxValidationCodes(txIDs []string) (map[string]peer.TxValidationCode, error) {
+	args := m.Called(txIDs)
+	return args.Get(0).(map[string]peer.TxValidationCode), nil
+}
+
+// GetTxValidationReasonByTxID returns the validation reason of given tx
+func (m *mockLedger) GetTxValidationReasonByTxID(txID string) (*peer.TxValidationReason, error) {
+	args := m.Called(txID)
+	return args.Get(0).(*peer.TxValidationReason), args.Error(1)
+}
+
+// GetTransactionProof returns the inclusion proof of the transaction
+func (m *mockLedger) GetTransactionProof(txID string) (*ledger.TransactionProof, error) {
+	args := m.Called(txID)
+	return args.Get(0).(*ledger.TransactionProof), args.Error(1)
+}
+
 // NewTxSimulator creates new transaction simulator
 func (m *mockLedger) NewTxSimulator(txid string) (ledger.TxSimulator, error) {
 	args := m.Called()
@@ -436,6 +647,12 @@ func (m *mockLedger) NewQueryExecutor() (ledger.QueryExecutor, error) {
 	return args.Get(0).(ledger.QueryExecutor), nil
 }
 
+// NewQueryExecutorWithIsolation creates query executor with the given isolation level
+func (m *mockLedger) NewQueryExecutorWithIsolation(level ledger.IsolationLevel) (ledger.QueryExecutor, error) {
+	args := m.Called(level)
+	return args.Get(0).(ledger.QueryExecutor), nil
+}
+
 // NewHistoryQueryExecutor history query executor
 func (m *mockLedger) NewHistoryQueryExecutor() (ledger.HistoryQueryExecutor, error) {
 	args := m.Called()
@@ -469,11 +686,26 @@ func (m *mockLedger) PrivateDataMinBlockNum() (uint64, error) {
 	return 0, nil
 }
 
+// GetMissingPvtDataInfoForMostRecentBlocks returns the missing pvt data of the most recent blocks
+func (m *mockLedger) GetMissingPvtDataInfoForMostRecentBlocks(maxBlock int) (ledger.MissingPvtDataInfo, error) {
+	return nil, nil
+}
+
+// VerifyChain verifies the integrity of the blocks in the given range
+func (m *mockLedger) VerifyChain(startHeight uint64, endHeight uint64) (*blkstorage.CorruptionReport, error) {
+	return &blkstorage.CorruptionReport{StartHeight: startHeight, EndHeight: endHeight}, nil
+}
+
 // Prune prune using policy
 func (m *mockLedger) Prune(policy ledger2.PrunePolicy) error {
 	return nil
 }
 
+func (m *mockLedger) GetConfigHistoryRetriever() (ledger.ConfigHistoryRetriever, error) {
+	args := m.Called()
+	return args.Get(0).(ledger.ConfigHistoryRetriever), args.Error(1)
+}
+
 func (m *mockLedger) GetBlockchainInfo() (*common.BlockchainInfo, error) {
 	args := m.Called()
 	return args.Get(0).(*common.BlockchainInfo), nil
@@ -518,6 +750,11 @@ func (exec *mockQueryExecutor) GetStateMultipleKeys(namespace string, keys []str
 	return args.Get(0).([][]byte), args.Error(1)
 }
 
+func (exec *mockQueryExecutor) GetStateMetadata(namespace string, key string) (map[string][]byte, error) {
+	args := exec.Called(namespace, key)
+	return args.Get(0).(map[string][]byte), args.Error(1)
+}
+
 func (exec *mockQueryExecutor) GetStateRangeScanIterator(namespace string, startKey string, endKey string) (ledger2.ResultsIterator, error) {
 	args := exec.Called(namespace, startKey, endKey)
 	return args.Get(0).(ledger2.ResultsIterator), args.Error(1)
@@ -548,6 +785,12 @@ func (exec *mockQueryExecutor) ExecuteQueryOnPrivateData(namespace, collection,
 	return args.Get(0).(ledger2.ResultsIterator), args.Error(1)
 }
 
+func (exec *mockQueryExecutor) GetStateByPartialCompositeKeyWithPagination(namespace, objectType string, attributes []string,
+	pageSize int32, bookmark string) (ledger.QueryResultsIterator, error) {
+	args := exec.Called(namespace, objectType, attributes, pageSize, bookmark)
+	return args.Get(0).(ledger.QueryResultsIterator), args.Error(1)
+}
+
 func (exec *mockQueryExecutor) Done() {
 }
 
@@ -601,6 +844,7 @@ func TestValidationInvalidEndorsing(t *testing.T) {
 	cdbytes := utils.MarshalOrPanic(cd)
 
 	queryExecutor := new(mockQueryExecutor)
+	queryExecutor.On("GetState", lifecycle.Name, lifecycle.DefinitionKey(ccID)).Return([]byte(nil), nil)
 	queryExecutor.On("GetState", "lscc", ccID).Return(cdbytes, nil)
 	theLedger.On("NewQueryExecutor", mock.Anything).Return(queryExecutor, nil)
 
@@ -609,13 +853,108 @@ func TestValidationInvalidEndorsing(t *testing.T) {
 	// Keep default callback
 	c := executeChaincodeProvider.getCallback()
 	executeChaincodeProvider.setCallback(func() (*peer.Response, *peer.ChaincodeEvent, error) {
-		return &peer.Response{Status: shim.ERROR}, nil, nil
+		return &peer.Response{Status: shim.ERROR, Message: "policy not satisfied"}, nil, nil
+	})
+	err := validator.Validate(b)
+	// Restore default callback
+	executeChaincodeProvider.setCallback(c)
+	assert.NoError(t, err)
+	assertInvalid(b, t, peer.TxValidationCode_ENDORSEMENT_POLICY_FAILURE)
+
+	// The reason of the failure is recorded in the block metadata
+	reasons, err := lutils.GetTxValidationReasons(b)
+	assert.NoError(t, err)
+	assert.Equal(t, &peer.TxValidationReason{
+		TxIndex:   0,
+		Code:      peer.TxValidationCode_ENDORSEMENT_POLICY_FAILURE,
+		Namespace: ccID,
+		Policy:    "/lscc/" + ccID + "/Policy",
+		Message:   "policy not satisfied",
+	}, reasons[0])
+}
+
+func TestValidationInvalidEndorsingLifecycleDefinition(t *testing.T) {
+	theLedger := new(mockLedger)
+	validator := NewTxValidator(&mockSupport{l: theLedger, acs: &mockchannelconfig.ApplicationCapabilities{LifecycleVal: true}})
+
+	ccID := "mycc"
+	tx := getEnv(ccID, createRWset(t, ccID), t)
+
+	theLedger.On("GetTransactionByID", mock.Anything).Return(&peer.ProcessedTransaction{}, errors.New("Cannot find the transaction"))
+
+	def := &lc.ChaincodeDefinition{
+		Name:              ccID,
+		Version:           ccVersion,
+		Sequence:          1,
+		EndorsementPolicy: signedByAnyMember([]string{"DEFAULT"}),
+	}
+
+	queryExecutor := new(mockQueryExecutor)
+	queryExecutor.On("GetState", lifecycle.Name, lifecycle.DefinitionKey(ccID)).Return(utils.MarshalOrPanic(def), nil)
+	theLedger.On("NewQueryExecutor", mock.Anything).Return(queryExecutor, nil)
+
+	b := &common.Block{Data: &common.BlockData{Data: [][]byte{utils.MarshalOrPanic(tx)}}}
+
+	// Keep default callback
+	c := executeChaincodeProvider.getCallback()
+	executeChaincodeProvider.setCallback(func() (*peer.Response, *peer.ChaincodeEvent, error) {
+		return &peer.Response{Status: shim.ERROR, Message: "policy not satisfied"}, nil, nil
 	})
 	err := validator.Validate(b)
 	// Restore default callback
 	executeChaincodeProvider.setCallback(c)
 	assert.NoError(t, err)
 	assertInvalid(b, t, peer.TxValidationCode_ENDORSEMENT_POLICY_FAILURE)
+
+	// The path of the endorsement policy of the definition is recorded in the reason of the failure
+	reasons, err := lutils.GetTxValidationReasons(b)
+	assert.NoError(t, err)
+	assert.Equal(t, "/_lifecycle/namespaces/"+ccID+"/EndorsementPolicy", reasons[0].Policy)
+}
+
+func TestValidationCodeOfVSCC(t *testing.T) {
+	theLedger := new(mockLedger)
+	validator := NewTxValidator(&mockSupport{l: theLedger})
+
+	ccID := "mycc"
+	tx := getEnv(ccID, createRWset(t, ccID), t)
+
+	theLedger.On("GetTransactionByID", mock.Anything).Return(&peer.ProcessedTransaction{}, errors.New("Cannot find the transaction"))
+
+	cd := &ccp.ChaincodeData{
+		Name:    ccID,
+		Version: ccVersion,
+		Vscc:    "vscc",
+		Policy:  signedByAnyMember([]string{"DEFAULT"}),
+	}
+
+	queryExecutor := new(mockQueryExecutor)
+	queryExecutor.On("GetState", lifecycle.Name, lifecycle.DefinitionKey(ccID)).Return([]byte(nil), nil)
+	queryExecutor.On("GetState", "lscc", ccID).Return(utils.MarshalOrPanic(cd), nil)
+	theLedger.On("NewQueryExecutor", mock.Anything).Return(queryExecutor, nil)
+
+	b := &common.Block{Data: &common.BlockData{Data: [][]byte{utils.MarshalOrPanic(tx)}}}
+
+	// vscc tells the validation code of the failure in the payload of its response
+	c := executeChaincodeProvider.getCallback()
+	executeChaincodeProvider.setCallback(func() (*peer.Response, *peer.ChaincodeEvent, error) {
+		reason := &peer.TxValidationReason{Code: peer.TxValidationCode_INSTANTIATION_POLICY_FAILURE, Policy: "Instantiation"}
+		return &peer.Response{Status: shim.ERROR, Message: "instantiation policy violated", Payload: utils.MarshalOrPanic(reason)}, nil, nil
+	})
+	err := validator.Validate(b)
+	executeChaincodeProvider.setCallback(c)
+	assert.NoError(t, err)
+	assertInvalid(b, t, peer.TxValidationCode_INSTANTIATION_POLICY_FAILURE)
+
+	reasons, err := lutils.GetTxValidationReasons(b)
+	assert.NoError(t, err)
+	assert.Equal(t, &peer.TxValidationReason{
+		TxIndex:   0,
+		Code:      peer.TxValidationCode_INSTANTIATION_POLICY_FAILURE,
+		Namespace: ccID,
+		Policy:    "Instantiation",
+		Message:   "instantiation policy violated",
+	}, reasons[0])
 }
 
 type ccResultCallback func() (*peer.Response, *peer.ChaincodeEvent, error)
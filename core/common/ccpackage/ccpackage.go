@@ -24,6 +24,7 @@ import (
 	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric/msp"
 	"github.com/hyperledger/fabric/protos/common"
+	mspprotos "github.com/hyperledger/fabric/protos/msp"
 	"github.com/hyperledger/fabric/protos/peer"
 	"github.com/hyperledger/fabric/protos/utils"
 )
@@ -151,6 +152,7 @@ func CreateSignedCCDepSpecForInstall(pack []*common.Envelope) (*common.Envelope,
 			//if it has endorsement, all other owners should have signed too
 			if len(cip.OwnerEndorsements) > 0 {
 				endorsements = make([]*peer.Endorsement, len(pack))
+				endorsementExists = true
 			}
 
 		} else if err = ValidateCip(baseCip, cip); err != nil {
@@ -243,3 +245,49 @@ func SignExistingPackage(env *common.Envelope, owner msp.SigningIdentity) (*comm
 
 	return createSignedCCDepSpec(sdepspec.ChaincodeDeploymentSpec, sdepspec.InstantiationPolicy, endorsements)
 }
+
+// VerifyOwnerEndorsements verifies the owner endorsements of a signed package. Each
+// endorsement must be a valid signature over the package by an admin of the MSP of
+// the endorser, which is deserialized by the first of the deserializers knowing it.
+// A package without endorsements is valid, it is up to the caller to require them.
+func VerifyOwnerEndorsements(sdepspec *peer.SignedChaincodeDeploymentSpec, deserializers []msp.IdentityDeserializer) error {
+	if sdepspec == nil || sdepspec.ChaincodeDeploymentSpec == nil || sdepspec.InstantiationPolicy == nil {
+		return fmt.Errorf("invalid signed deployment spec")
+	}
+
+	for n, endorsement := range sdepspec.OwnerEndorsements {
+		if endorsement == nil || len(endorsement.Endorser) == 0 || len(endorsement.Signature) == 0 {
+			return fmt.Errorf("owner endorsement %d is empty", n)
+		}
+
+		var identity msp.Identity
+		var err error
+		for _, deserializer := range deserializers {
+			if identity, err = deserializer.DeserializeIdentity(endorsement.Endorser); err == nil {
+				break
+			}
+		}
+		if identity == nil {
+			return fmt.Errorf("could not deserialize the endorser of owner endorsement %d, err %v", n, err)
+		}
+
+		// the signed bytes are built anew, appending to the package bytes could overwrite them
+		signed := make([]byte, 0, len(sdepspec.ChaincodeDeploymentSpec)+len(sdepspec.InstantiationPolicy)+len(endorsement.Endorser))
+		signed = append(signed, sdepspec.ChaincodeDeploymentSpec...)
+		signed = append(signed, sdepspec.InstantiationPolicy...)
+		signed = append(signed, endorsement.Endorser...)
+		if err = identity.Verify(signed, endorsement.Signature); err != nil {
+			return fmt.Errorf("invalid signature of owner endorsement %d by %s, err %s", n, identity.GetMSPIdentifier(), err)
+		}
+
+		principal := &mspprotos.MSPPrincipal{
+			PrincipalClassification: mspprotos.MSPPrincipal_ROLE,
+			Principal:               utils.MarshalOrPanic(&mspprotos.MSPRole{Role: mspprotos.MSPRole_ADMIN, MspIdentifier: identity.GetMSPIdentifier()}),
+		}
+		if err = identity.SatisfiesPrincipal(principal); err != nil {
+			return fmt.Errorf("the endorser of owner endorsement %d is not an admin of %s, err %s", n, identity.GetMSPIdentifier(), err)
+		}
+	}
+
+	return nil
+}
@@ -30,6 +30,7 @@ import (
 	mspprotos "github.com/hyperledger/fabric/protos/msp"
 	"github.com/hyperledger/fabric/protos/peer"
 	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
 )
 
 func ownerCreateCCDepSpec(codepackage []byte, sigpolicy *common.SignaturePolicyEnvelope, owner msp.SigningIdentity) (*common.Envelope, error) {
@@ -163,6 +164,52 @@ func TestCreateSignedCCDepSpecForInstall(t *testing.T) {
 	}
 }
 
+func TestVerifyOwnerEndorsements(t *testing.T) {
+	mspid, _ := localmsp.GetIdentifier()
+	sigpolicy := createInstantiationPolicy(mspid, mspprotos.MSPRole_ADMIN)
+	env1, err := ownerCreateCCDepSpec([]byte("codepackage"), sigpolicy, signer)
+	assert.NoError(t, err)
+	env2, err := ownerCreateCCDepSpec([]byte("codepackage"), sigpolicy, signer)
+	assert.NoError(t, err)
+
+	env, err := CreateSignedCCDepSpecForInstall([]*common.Envelope{env1, env2})
+	assert.NoError(t, err)
+	_, sdepspec, err := ExtractSignedCCDepSpec(env)
+	assert.NoError(t, err)
+	assert.Len(t, sdepspec.OwnerEndorsements, 2)
+
+	deserializers := []msp.IdentityDeserializer{localmsp}
+	assert.NoError(t, VerifyOwnerEndorsements(sdepspec, deserializers))
+
+	// a package without endorsements is valid
+	env, err = ownerCreateCCDepSpec([]byte("codepackage"), sigpolicy, nil)
+	assert.NoError(t, err)
+	_, unsigned, err := ExtractSignedCCDepSpec(env)
+	assert.NoError(t, err)
+	assert.NoError(t, VerifyOwnerEndorsements(unsigned, deserializers))
+
+	// the endorsers are unknown
+	err = VerifyOwnerEndorsements(sdepspec, nil)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "could not deserialize the endorser of owner endorsement 0")
+
+	// the code package was tampered with
+	tampered := *sdepspec
+	cds := &peer.ChaincodeDeploymentSpec{CodePackage: []byte("tampered codepackage")}
+	tampered.ChaincodeDeploymentSpec = utils.MarshalOrPanic(cds)
+	err = VerifyOwnerEndorsements(&tampered, deserializers)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid signature of owner endorsement 0")
+
+	// the endorsement is empty
+	tampered = *sdepspec
+	tampered.OwnerEndorsements = []*peer.Endorsement{&peer.Endorsement{}}
+	err = VerifyOwnerEndorsements(&tampered, deserializers)
+	assert.EqualError(t, err, "owner endorsement 0 is empty")
+
+	assert.EqualError(t, VerifyOwnerEndorsements(nil, deserializers), "invalid signed deployment spec")
+}
+
 func TestMismatchedCodePackages(t *testing.T) {
 	mspid, _ := localmsp.GetIdentifier()
 	sigpolicy := createInstantiationPolicy(mspid, mspprotos.MSPRole_ADMIN)
@@ -108,6 +108,15 @@ func (ccpack *SignedCDSPackage) GetInstantiationPolicy() []byte {
 	return ccpack.sDepSpec.InstantiationPolicy
 }
 
+// GetSignedDepSpec gets the SignedChaincodeDeploymentSpec from the package, including
+// the owner endorsements
+func (ccpack *SignedCDSPackage) GetSignedDepSpec() *pb.SignedChaincodeDeploymentSpec {
+	if ccpack.sDepSpec == nil {
+		panic("GetSignedDepSpec called on uninitialized package")
+	}
+	return ccpack.sDepSpec
+}
+
 // GetDepSpecBytes gets the serialized ChaincodeDeploymentSpec from the package
 func (ccpack *SignedCDSPackage) GetDepSpecBytes() []byte {
 	//this has to be after creating a package and initializing it
@@ -0,0 +1,171 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package privdata
+
+import (
+	"github.com/hyperledger/fabric/protos/common"
+)
+
+// Collection defines a common interface for collections
+type Collection interface {
+	// CollectionID returns this collection's ID
+	CollectionID() string
+
+	// MemberOrgs returns the collection's members as MSP IDs. This serves as
+	// a human-readable way of quickly identifying who is part of a collection.
+	MemberOrgs() []string
+}
+
+// CollectionAccessPolicy encapsulates functions for the access policy of a collection
+type CollectionAccessPolicy interface {
+	// AccessFilter returns a member filter function for a collection
+	AccessFilter() Filter
+
+	// RequiredPeerCount returns the minimum number of peers the private data
+	// of the collection is disseminated to upon endorsement
+	RequiredPeerCount() int
+
+	// MaximumPeerCount returns the maximum number of peers the private data
+	// of the collection is disseminated to upon endorsement
+	MaximumPeerCount() int
+
+	// MemberOrgs returns the collection's members as MSP IDs
+	MemberOrgs() []string
+}
+
+// Filter defines a rule that filters peers according to data signed by them.
+// The Identity in the SignedData is a SerializedIdentity of a peer.
+// The Data is a message the peer signed, and the Signature is the corresponding
+// Signature on that Data.
+// It returns true if the policy holds for the given signed data, false otherwise.
+type Filter func(common.SignedData) bool
+
+// CollectionStore retrieves the collections of the chaincodes from the
+// collection configs committed with lscc upon their instantiation or upgrade
+type CollectionStore interface {
+	// RetrieveCollection retrieves the collection of the criteria, with the
+	// latest configuration committed into the ledger of the channel
+	RetrieveCollection(common.CollectionCriteria) (Collection, error)
+
+	// RetrieveCollectionAccessPolicy retrieves the access policy of a collection
+	RetrieveCollectionAccessPolicy(common.CollectionCriteria) (CollectionAccessPolicy, error)
+
+	// RetrieveCollectionConfigPackage retrieves the configuration of all the
+	// collections of the chaincode of the criteria
+	RetrieveCollectionConfigPackage(common.CollectionCriteria) (*common.CollectionConfigPackage, error)
+
+	// RetrieveCollectionConfigPackageAt retrieves the configuration of all the
+	// collections of the chaincode of the criteria, as it was at the given block
+	// number; the collection configs of a chaincode change upon its upgrade
+	RetrieveCollectionConfigPackageAt(cc common.CollectionCriteria, blockNum uint64) (*common.CollectionConfigPackage, error)
+
+	// RetrieveCollectionAccessPolicyAt retrieves the access policy of the
+	// collection of the criteria, as configured at the given block number
+	RetrieveCollectionAccessPolicyAt(cc common.CollectionCriteria, blockNum uint64) (CollectionAccessPolicy, error)
+}
+
+// IsMemberOrg returns whether the organization of the given MSP ID is a member
+// of the collection of the given access policy
+func IsMemberOrg(policy CollectionAccessPolicy, mspID string) bool {
+	for _, org := range policy.MemberOrgs() {
+		if org == mspID {
+			return true
+		}
+	}
+	return false
+}
+
+// IsOrgEligible returns whether the peers of the organization of the given MSP ID
+// may receive the private data of the collection of the criteria. The collections
+// of the chaincodes instantiated without collection configs are open to every
+// organization of the channel, while the collections missing from the collection
+// configs of a chaincode are open to none.
+func IsOrgEligible(store CollectionStore, cc common.CollectionCriteria, mspID string) (bool, error) {
+	collections, err := store.RetrieveCollectionConfigPackage(cc)
+	if err != nil {
+		return false, err
+	}
+	if collections == nil {
+		return true, nil
+	}
+	policy, err := store.RetrieveCollectionAccessPolicy(cc)
+	if _, isNoSuchCollection := err.(NoSuchCollectionError); isNoSuchCollection {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return IsMemberOrg(policy, mspID), nil
+}
+
+// IsOrgEligibleAt is IsOrgEligible against the collection configs as they were at the given block number,
+// the collection configs the transactions of the block were endorsed and validated against
+func IsOrgEligibleAt(store CollectionStore, cc common.CollectionCriteria, blockNum uint64, mspID string) (bool, error) {
+	collections, err := store.RetrieveCollectionConfigPackageAt(cc, blockNum)
+	if err != nil {
+		return false, err
+	}
+	if collections == nil {
+		return true, nil
+	}
+	policy, err := store.RetrieveCollectionAccessPolicyAt(cc, blockNum)
+	if _, isNoSuchCollection := err.(NoSuchCollectionError); isNoSuchCollection {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return IsMemberOrg(policy, mspID), nil
+}
+
+const (
+	// collectionSeparator is the separator used to build the KVS
+	// key storing the collections of a chaincode; note that we are
+	// using as separator a character which is illegal for either the
+	// name or the version of a chaincode so there cannot be any
+	// collisions when choosing the name
+	collectionSeparator = "~"
+	// collectionSuffix is the suffix of the KVS key storing the
+	// collections of a chaincode
+	collectionSuffix = "collection"
+)
+
+// BuildCollectionKVSKey constructs the collection config key for a given chaincode name
+func BuildCollectionKVSKey(ccname string) string {
+	return ccname + collectionSeparator + collectionSuffix
+}
+
+// IsCollectionConfigKey detects if a key is a collection key
+func IsCollectionConfigKey(key string) bool {
+	return len(key) > len(collectionSeparator+collectionSuffix) &&
+		key[len(key)-len(collectionSeparator+collectionSuffix):] == collectionSeparator+collectionSuffix
+}
+
+const (
+	// LifecycleNamespace is the namespace of the _lifecycle system chaincode,
+	// whose committed chaincode definitions carry the collections of their
+	// chaincodes, replacing the ones committed through lscc
+	LifecycleNamespace = "_lifecycle"
+	// lifecycleDefinitionPrefix is the prefix of the keys of the
+	// chaincode definitions committed through _lifecycle
+	lifecycleDefinitionPrefix = "namespaces/"
+)
+
+// BuildLifecycleDefinitionKey constructs the key of the definition of a given
+// chaincode committed through _lifecycle
+func BuildLifecycleDefinitionKey(ccname string) string {
+	return lifecycleDefinitionPrefix + ccname
+}
+
+// ParseLifecycleDefinitionKey returns the name of the chaincode whose committed definition
+// is held by a key of the _lifecycle namespace, and false if the key holds no definition
+func ParseLifecycleDefinitionKey(key string) (string, bool) {
+	if len(key) <= len(lifecycleDefinitionPrefix) || key[:len(lifecycleDefinitionPrefix)] != lifecycleDefinitionPrefix {
+		return "", false
+	}
+	return key[len(lifecycleDefinitionPrefix):], true
+}
@@ -0,0 +1,122 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package privdata
+
+import (
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/cauthdsl"
+	"github.com/hyperledger/fabric/common/policies"
+	"github.com/hyperledger/fabric/msp"
+	"github.com/hyperledger/fabric/protos/common"
+	m "github.com/hyperledger/fabric/protos/msp"
+)
+
+// SimpleCollection implements a collection with static properties
+// and a public member set
+type SimpleCollection struct {
+	name         string
+	accessPolicy policies.Policy
+	memberOrgs   []string
+	conf         common.StaticCollectionConfig
+}
+
+// CollectionID returns the collection's ID
+func (sc *SimpleCollection) CollectionID() string {
+	return sc.name
+}
+
+// MemberOrgs returns the MSP IDs that are part of this collection
+func (sc *SimpleCollection) MemberOrgs() []string {
+	return sc.memberOrgs
+}
+
+// RequiredPeerCount returns the minimum number of peers
+// required to send private data to
+func (sc *SimpleCollection) RequiredPeerCount() int {
+	return int(sc.conf.RequiredPeerCount)
+}
+
+// MaximumPeerCount returns the maximum number of peers
+// private data will be sent to
+func (sc *SimpleCollection) MaximumPeerCount() int {
+	return int(sc.conf.MaximumPeerCount)
+}
+
+// AccessFilter returns the member filter function that evaluates signed data
+// against the member access policy of this collection
+func (sc *SimpleCollection) AccessFilter() Filter {
+	return func(sd common.SignedData) bool {
+		if err := sc.accessPolicy.Evaluate([]*common.SignedData{&sd}); err != nil {
+			return false
+		}
+		return true
+	}
+}
+
+// Setup configures a simple collection object based on a given
+// StaticCollectionConfig proto that has all the necessary information
+func (sc *SimpleCollection) Setup(collectionConfig *common.StaticCollectionConfig, deserializer msp.IdentityDeserializer) error {
+	if collectionConfig == nil {
+		return fmt.Errorf("nil config passed to collection setup")
+	}
+	sc.conf = *collectionConfig
+	sc.name = collectionConfig.GetName()
+
+	// get the access signature policy envelope
+	collectionPolicyConfig := collectionConfig.GetMemberOrgsPolicy()
+	if collectionPolicyConfig == nil {
+		return fmt.Errorf("collection config policy of collection %s is nil", sc.name)
+	}
+	accessPolicyEnvelope := collectionPolicyConfig.GetSignaturePolicy()
+	if accessPolicyEnvelope == nil {
+		return fmt.Errorf("collection config access policy of collection %s is nil", sc.name)
+	}
+
+	// create access policy from the envelope
+	npp := cauthdsl.NewPolicyProvider(deserializer)
+	polBytes, err := proto.Marshal(accessPolicyEnvelope)
+	if err != nil {
+		return err
+	}
+	sc.accessPolicy, _, err = npp.NewPolicy(polBytes)
+	if err != nil {
+		return err
+	}
+
+	// get member org MSP IDs from the envelope
+	for _, principal := range accessPolicyEnvelope.Identities {
+		switch principal.PrincipalClassification {
+		case m.MSPPrincipal_ROLE:
+			// Principal contains the msp role
+			mspRole := &m.MSPRole{}
+			if err = proto.Unmarshal(principal.Principal, mspRole); err != nil {
+				return err
+			}
+			sc.memberOrgs = append(sc.memberOrgs, mspRole.MspIdentifier)
+		case m.MSPPrincipal_IDENTITY:
+			// Principal contains the serialized identity of a member
+			principalId, err := deserializer.DeserializeIdentity(principal.Principal)
+			if err != nil {
+				return err
+			}
+			sc.memberOrgs = append(sc.memberOrgs, principalId.GetMSPIdentifier())
+		case m.MSPPrincipal_ORGANIZATION_UNIT:
+			// Principal contains the organizational unit of the members
+			ou := &m.OrganizationUnit{}
+			if err = proto.Unmarshal(principal.Principal, ou); err != nil {
+				return err
+			}
+			sc.memberOrgs = append(sc.memberOrgs, ou.MspIdentifier)
+		default:
+			return fmt.Errorf("invalid principal type %d in the access policy of collection %s", int32(principal.PrincipalClassification), sc.name)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,177 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package privdata
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/cauthdsl"
+	"github.com/hyperledger/fabric/msp"
+	"github.com/hyperledger/fabric/protos/common"
+	mb "github.com/hyperledger/fabric/protos/msp"
+	"github.com/stretchr/testify/assert"
+)
+
+// mockIdentity is a member of the MSP of its serialization, of the form <mspid>:<name>
+type mockIdentity struct {
+	mspID string
+	name  string
+}
+
+func (id *mockIdentity) GetIdentifier() *msp.IdentityIdentifier {
+	return &msp.IdentityIdentifier{Mspid: id.mspID, Id: id.name}
+}
+
+func (id *mockIdentity) GetMSPIdentifier() string {
+	return id.mspID
+}
+
+func (id *mockIdentity) Validate() error {
+	return nil
+}
+
+func (id *mockIdentity) GetOrganizationalUnits() []*msp.OUIdentifier {
+	return nil
+}
+
+func (id *mockIdentity) Verify(msg []byte, sig []byte) error {
+	if !bytes.Equal(msg, sig) {
+		return errors.New("invalid signature")
+	}
+	return nil
+}
+
+func (id *mockIdentity) Serialize() ([]byte, error) {
+	return []byte(id.mspID + ":" + id.name), nil
+}
+
+func (id *mockIdentity) SatisfiesPrincipal(principal *mb.MSPPrincipal) error {
+	role := &mb.MSPRole{}
+	if err := proto.Unmarshal(principal.Principal, role); err != nil {
+		return err
+	}
+	if role.MspIdentifier != id.mspID {
+		return fmt.Errorf("identity is a member of %s, not %s", id.mspID, role.MspIdentifier)
+	}
+	return nil
+}
+
+type mockDeserializer struct{}
+
+func (d *mockDeserializer) DeserializeIdentity(serializedIdentity []byte) (msp.Identity, error) {
+	parts := bytes.SplitN(serializedIdentity, []byte(":"), 2)
+	if len(parts) != 2 {
+		return nil, errors.New("invalid identity")
+	}
+	return &mockIdentity{mspID: string(parts[0]), name: string(parts[1])}, nil
+}
+
+func createCollectionPolicyConfig(accessPolicy *common.SignaturePolicyEnvelope) *common.CollectionPolicyConfig {
+	return &common.CollectionPolicyConfig{
+		Payload: &common.CollectionPolicyConfig_SignaturePolicy{
+			SignaturePolicy: accessPolicy,
+		},
+	}
+}
+
+func TestSetupBadConfig(t *testing.T) {
+	// set up simple collection with invalid data
+	var sc SimpleCollection
+	err := sc.Setup(nil, &mockDeserializer{})
+	assert.Error(t, err)
+
+	// no policy
+	err = sc.Setup(&common.StaticCollectionConfig{Name: "test collection"}, &mockDeserializer{})
+	assert.EqualError(t, err, "collection config policy of collection test collection is nil")
+
+	// no signature policy
+	err = sc.Setup(&common.StaticCollectionConfig{
+		Name:             "test collection",
+		MemberOrgsPolicy: &common.CollectionPolicyConfig{},
+	}, &mockDeserializer{})
+	assert.EqualError(t, err, "collection config access policy of collection test collection is nil")
+}
+
+func TestSetupGoodConfigCollection(t *testing.T) {
+	// create member access policy
+	signers := []string{"signer0", "signer1"}
+	policyEnvelope := cauthdsl.SignedByAnyMember(signers)
+	accessPolicy := createCollectionPolicyConfig(policyEnvelope)
+
+	// create static collection config with the policy
+	collectionConfig := &common.StaticCollectionConfig{
+		Name:              "test collection",
+		MemberOrgsPolicy:  accessPolicy,
+		RequiredPeerCount: 1,
+		MaximumPeerCount:  2,
+	}
+
+	// set up simple collection with valid data
+	var sc SimpleCollection
+	err := sc.Setup(collectionConfig, &mockDeserializer{})
+	assert.NoError(t, err)
+
+	// check name
+	assert.Equal(t, "test collection", sc.CollectionID())
+
+	// check members
+	assert.Equal(t, signers, sc.MemberOrgs())
+
+	// check required and maximum peer counts
+	assert.Equal(t, 1, sc.RequiredPeerCount())
+	assert.Equal(t, 2, sc.MaximumPeerCount())
+
+	assert.True(t, IsMemberOrg(&sc, "signer1"))
+	assert.False(t, IsMemberOrg(&sc, "signer2"))
+}
+
+func TestSimpleCollectionFilter(t *testing.T) {
+	// create member access policy
+	signers := []string{"signer0", "signer1"}
+	policyEnvelope := cauthdsl.SignedByAnyMember(signers)
+	accessPolicy := createCollectionPolicyConfig(policyEnvelope)
+
+	// create static collection config with the policy
+	collectionConfig := &common.StaticCollectionConfig{
+		Name:             "test collection",
+		MemberOrgsPolicy: accessPolicy,
+	}
+
+	// set up simple collection
+	var sc SimpleCollection
+	err := sc.Setup(collectionConfig, &mockDeserializer{})
+	assert.NoError(t, err)
+
+	// get the collection access filter
+	cap := (CollectionAccessPolicy)(&sc)
+	accessFilter := cap.AccessFilter()
+
+	// check filter: a member of the collection
+	assert.True(t, accessFilter(common.SignedData{
+		Identity:  []byte("signer0:peer0"),
+		Data:      []byte("data"),
+		Signature: []byte("data"),
+	}))
+
+	// check filter: a member of another organization
+	assert.False(t, accessFilter(common.SignedData{
+		Identity:  []byte("signer2:peer0"),
+		Data:      []byte("data"),
+		Signature: []byte("data"),
+	}))
+
+	// check filter: an invalid signature
+	assert.False(t, accessFilter(common.SignedData{
+		Identity:  []byte("signer0:peer0"),
+		Data:      []byte("data"),
+		Signature: []byte("bad signature"),
+	}))
+}
@@ -0,0 +1,160 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package privdata
+
+import (
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/msp"
+	"github.com/hyperledger/fabric/protos/common"
+	lc "github.com/hyperledger/fabric/protos/peer/lifecycle"
+)
+
+// lsccNamespace is the namespace of the lifecycle system chaincode,
+// which commits the collection configs of the chaincodes
+const lsccNamespace = "lscc"
+
+// Support is an interface used to inject dependencies
+type Support interface {
+	// GetQueryExecutorForLedger returns a query executor for the specified channel
+	GetQueryExecutorForLedger(cid string) (ledger.QueryExecutor, error)
+
+	// GetConfigHistoryRetriever returns the retriever of the versions of
+	// the collection configs committed into the specified channel
+	GetConfigHistoryRetriever(cid string) (ledger.ConfigHistoryRetriever, error)
+
+	// GetIdentityDeserializer returns an IdentityDeserializer
+	// instance for the specified chain
+	GetIdentityDeserializer(chainID string) msp.IdentityDeserializer
+}
+
+// NoSuchCollectionError is returned when the chaincode of the criteria
+// has no collection of the requested name
+type NoSuchCollectionError common.CollectionCriteria
+
+func (f NoSuchCollectionError) Error() string {
+	return fmt.Sprintf("collection %s/%s/%s could not be found", f.Channel, f.Namespace, f.Collection)
+}
+
+type simpleCollectionStore struct {
+	s Support
+}
+
+// NewSimpleCollectionStore returns a collection store reading the collection configs
+// from the _lifecycle and lscc state of the ledgers supplied by the given support
+func NewSimpleCollectionStore(s Support) CollectionStore {
+	return &simpleCollectionStore{s}
+}
+
+func (c *simpleCollectionStore) retrieveSimpleCollection(cc common.CollectionCriteria) (*SimpleCollection, error) {
+	collections, err := c.RetrieveCollectionConfigPackage(cc)
+	if err != nil {
+		return nil, err
+	}
+	return c.simpleCollectionOf(cc, collections)
+}
+
+// simpleCollectionOf sets up the collection of the criteria out of the given collection configs
+func (c *simpleCollectionStore) simpleCollectionOf(cc common.CollectionCriteria, collections *common.CollectionConfigPackage) (*SimpleCollection, error) {
+	if collections == nil {
+		return nil, NoSuchCollectionError(cc)
+	}
+
+	for _, cconf := range collections.Config {
+		switch cconf := cconf.Payload.(type) {
+		case *common.CollectionConfig_StaticCollectionConfig:
+			if cconf.StaticCollectionConfig.Name == cc.Collection {
+				sc := &SimpleCollection{}
+				err := sc.Setup(cconf.StaticCollectionConfig, c.s.GetIdentityDeserializer(cc.Channel))
+				if err != nil {
+					return nil, fmt.Errorf("error setting up collection %s/%s/%s: %s", cc.Channel, cc.Namespace, cc.Collection, err)
+				}
+				return sc, nil
+			}
+		default:
+			return nil, fmt.Errorf("unexpected collection type in the config of chaincode %s", cc.Namespace)
+		}
+	}
+
+	return nil, NoSuchCollectionError(cc)
+}
+
+func (c *simpleCollectionStore) RetrieveCollection(cc common.CollectionCriteria) (Collection, error) {
+	return c.retrieveSimpleCollection(cc)
+}
+
+func (c *simpleCollectionStore) RetrieveCollectionAccessPolicy(cc common.CollectionCriteria) (CollectionAccessPolicy, error) {
+	return c.retrieveSimpleCollection(cc)
+}
+
+// RetrieveCollectionAccessPolicyAt returns the access policy of the collection of the criteria
+// as configured at the given block number
+func (c *simpleCollectionStore) RetrieveCollectionAccessPolicyAt(cc common.CollectionCriteria, blockNum uint64) (CollectionAccessPolicy, error) {
+	collections, err := c.RetrieveCollectionConfigPackageAt(cc, blockNum)
+	if err != nil {
+		return nil, err
+	}
+	return c.simpleCollectionOf(cc, collections)
+}
+
+// RetrieveCollectionConfigPackage returns the collection configs committed for the
+// chaincode of the criteria, nil if the chaincode has no collection. The collections
+// of a chaincode defined through _lifecycle are the ones of its committed definition
+func (c *simpleCollectionStore) RetrieveCollectionConfigPackage(cc common.CollectionCriteria) (*common.CollectionConfigPackage, error) {
+	qe, err := c.s.GetQueryExecutorForLedger(cc.Channel)
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve query executor for channel %s: %s", cc.Channel, err)
+	}
+	defer qe.Done()
+
+	defBytes, err := qe.GetState(LifecycleNamespace, BuildLifecycleDefinitionKey(cc.Namespace))
+	if err != nil {
+		return nil, fmt.Errorf("error while retrieving the definition of chaincode %s on channel %s: %s", cc.Namespace, cc.Channel, err)
+	}
+	if defBytes != nil {
+		def := &lc.ChaincodeDefinition{}
+		if err = proto.Unmarshal(defBytes, def); err != nil {
+			return nil, fmt.Errorf("invalid definition of chaincode %s on channel %s: %s", cc.Namespace, cc.Channel, err)
+		}
+		return def.Collections, nil
+	}
+
+	cb, err := qe.GetState(lsccNamespace, BuildCollectionKVSKey(cc.Namespace))
+	if err != nil {
+		return nil, fmt.Errorf("error while retrieving the collections of chaincode %s on channel %s: %s", cc.Namespace, cc.Channel, err)
+	}
+	if cb == nil {
+		return nil, nil
+	}
+
+	collections := &common.CollectionConfigPackage{}
+	if err = proto.Unmarshal(cb, collections); err != nil {
+		return nil, fmt.Errorf("invalid configuration for the collections of chaincode %s on channel %s: %s", cc.Namespace, cc.Channel, err)
+	}
+	return collections, nil
+}
+
+// RetrieveCollectionConfigPackageAt returns the collection configs of the chaincode of the
+// criteria which were in effect at the given block number, that is the ones committed last by
+// a block not above it, nil if the chaincode had no collection at that block
+func (c *simpleCollectionStore) RetrieveCollectionConfigPackageAt(cc common.CollectionCriteria, blockNum uint64) (*common.CollectionConfigPackage, error) {
+	retriever, err := c.s.GetConfigHistoryRetriever(cc.Channel)
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve config history of channel %s: %s", cc.Channel, err)
+	}
+
+	info, err := retriever.CollectionConfigAt(blockNum, cc.Namespace)
+	if err != nil {
+		return nil, fmt.Errorf("error while retrieving the collections of chaincode %s on channel %s at block %d: %s", cc.Namespace, cc.Channel, blockNum, err)
+	}
+	if info == nil {
+		return nil, nil
+	}
+	return info.CollectionConfig, nil
+}
@@ -0,0 +1,263 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package privdata
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/cauthdsl"
+	lm "github.com/hyperledger/fabric/common/mocks/ledger"
+	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/msp"
+	"github.com/hyperledger/fabric/protos/common"
+	lc "github.com/hyperledger/fabric/protos/peer/lifecycle"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockStoreSupport struct {
+	state   map[string]map[string][]byte
+	history mockConfigHistoryRetriever
+}
+
+func (c *mockStoreSupport) GetQueryExecutorForLedger(cid string) (ledger.QueryExecutor, error) {
+	if cid != "testchannel" {
+		return nil, errors.New("no ledger")
+	}
+	return lm.NewMockQueryExecutor(c.state), nil
+}
+
+func (c *mockStoreSupport) GetConfigHistoryRetriever(cid string) (ledger.ConfigHistoryRetriever, error) {
+	if cid != "testchannel" {
+		return nil, errors.New("no ledger")
+	}
+	return c.history, nil
+}
+
+func (c *mockStoreSupport) GetIdentityDeserializer(chainID string) msp.IdentityDeserializer {
+	return &mockDeserializer{}
+}
+
+func newMockStoreSupport(collections map[string][]byte) *mockStoreSupport {
+	lsccState := make(map[string][]byte)
+	for ccname, configs := range collections {
+		lsccState[BuildCollectionKVSKey(ccname)] = configs
+	}
+	return &mockStoreSupport{state: map[string]map[string][]byte{"lscc": lsccState, LifecycleNamespace: {}}}
+}
+
+// mockConfigHistoryRetriever holds the versions of the collection configs of the chaincodes,
+// sorted by the numbers of their committing blocks
+type mockConfigHistoryRetriever map[string][]*ledger.CollectionConfigInfo
+
+func (r mockConfigHistoryRetriever) CollectionConfigAt(blockNum uint64, chaincodeName string) (*ledger.CollectionConfigInfo, error) {
+	var info *ledger.CollectionConfigInfo
+	for _, i := range r[chaincodeName] {
+		if i.CommittingBlockNum <= blockNum {
+			info = i
+		}
+	}
+	return info, nil
+}
+
+func TestCollectionStore(t *testing.T) {
+	collections := utils.MarshalOrPanic(&common.CollectionConfigPackage{
+		Config: []*common.CollectionConfig{{
+			Payload: &common.CollectionConfig_StaticCollectionConfig{
+				StaticCollectionConfig: &common.StaticCollectionConfig{
+					Name:              "mycollection",
+					MemberOrgsPolicy:  createCollectionPolicyConfig(cauthdsl.SignedByAnyMember([]string{"Org1MSP", "Org2MSP"})),
+					RequiredPeerCount: 1,
+					MaximumPeerCount:  3,
+				},
+			},
+		}},
+	})
+	cs := NewSimpleCollectionStore(newMockStoreSupport(map[string][]byte{
+		"mycc":  collections,
+		"badcc": []byte("barf"),
+	}))
+
+	cc := common.CollectionCriteria{Channel: "testchannel", Namespace: "mycc", Collection: "mycollection"}
+	c, err := cs.RetrieveCollection(cc)
+	assert.NoError(t, err)
+	assert.Equal(t, "mycollection", c.CollectionID())
+	assert.Equal(t, []string{"Org1MSP", "Org2MSP"}, c.MemberOrgs())
+
+	policy, err := cs.RetrieveCollectionAccessPolicy(cc)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, policy.RequiredPeerCount())
+	assert.Equal(t, 3, policy.MaximumPeerCount())
+
+	ccp, err := cs.RetrieveCollectionConfigPackage(cc)
+	assert.NoError(t, err)
+	assert.Len(t, ccp.Config, 1)
+
+	eligible, err := IsOrgEligible(cs, cc, "Org2MSP")
+	assert.NoError(t, err)
+	assert.True(t, eligible)
+	eligible, err = IsOrgEligible(cs, cc, "Org3MSP")
+	assert.NoError(t, err)
+	assert.False(t, eligible)
+
+	// a collection missing from the collection configs of the chaincode
+	cc = common.CollectionCriteria{Channel: "testchannel", Namespace: "mycc", Collection: "othercollection"}
+	_, err = cs.RetrieveCollection(cc)
+	assert.EqualError(t, err, "collection testchannel/mycc/othercollection could not be found")
+	eligible, err = IsOrgEligible(cs, cc, "Org1MSP")
+	assert.NoError(t, err)
+	assert.False(t, eligible)
+
+	// a chaincode without collection configs
+	cc = common.CollectionCriteria{Channel: "testchannel", Namespace: "othercc", Collection: "mycollection"}
+	ccp, err = cs.RetrieveCollectionConfigPackage(cc)
+	assert.NoError(t, err)
+	assert.Nil(t, ccp)
+	_, err = cs.RetrieveCollectionAccessPolicy(cc)
+	assert.EqualError(t, err, "collection testchannel/othercc/mycollection could not be found")
+	eligible, err = IsOrgEligible(cs, cc, "Org3MSP")
+	assert.NoError(t, err)
+	assert.True(t, eligible)
+
+	// invalid collection configs
+	cc = common.CollectionCriteria{Channel: "testchannel", Namespace: "badcc", Collection: "mycollection"}
+	_, err = cs.RetrieveCollection(cc)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid configuration for the collections of chaincode badcc")
+	_, err = IsOrgEligible(cs, cc, "Org1MSP")
+	assert.Error(t, err)
+
+	// an unknown channel
+	cc = common.CollectionCriteria{Channel: "otherchannel", Namespace: "mycc", Collection: "mycollection"}
+	_, err = cs.RetrieveCollection(cc)
+	assert.EqualError(t, err, "could not retrieve query executor for channel otherchannel: no ledger")
+}
+
+func TestCollectionStoreLifecycleDefinitions(t *testing.T) {
+	lsccCollections := &common.CollectionConfigPackage{Config: []*common.CollectionConfig{{
+		Payload: &common.CollectionConfig_StaticCollectionConfig{
+			StaticCollectionConfig: &common.StaticCollectionConfig{
+				Name:             "lscccollection",
+				MemberOrgsPolicy: createCollectionPolicyConfig(cauthdsl.SignedByAnyMember([]string{"Org1MSP"})),
+			},
+		},
+	}}}
+	lifecycleCollections := &common.CollectionConfigPackage{Config: []*common.CollectionConfig{{
+		Payload: &common.CollectionConfig_StaticCollectionConfig{
+			StaticCollectionConfig: &common.StaticCollectionConfig{
+				Name:             "mycollection",
+				MemberOrgsPolicy: createCollectionPolicyConfig(cauthdsl.SignedByAnyMember([]string{"Org1MSP", "Org2MSP"})),
+			},
+		},
+	}}}
+	support := newMockStoreSupport(map[string][]byte{
+		"mycc":     utils.MarshalOrPanic(lsccCollections),
+		"lscccc":   utils.MarshalOrPanic(lsccCollections),
+		"nocollcc": utils.MarshalOrPanic(lsccCollections),
+	})
+	support.state[LifecycleNamespace][BuildLifecycleDefinitionKey("mycc")] = utils.MarshalOrPanic(&lc.ChaincodeDefinition{
+		Name: "mycc", Version: "1.0", Sequence: 1, Collections: lifecycleCollections,
+	})
+	support.state[LifecycleNamespace][BuildLifecycleDefinitionKey("nocollcc")] = utils.MarshalOrPanic(&lc.ChaincodeDefinition{
+		Name: "nocollcc", Version: "1.0", Sequence: 1,
+	})
+	support.state[LifecycleNamespace][BuildLifecycleDefinitionKey("badcc")] = []byte("barf")
+	cs := NewSimpleCollectionStore(support)
+
+	// the collections of a chaincode defined through _lifecycle are the ones of its definition
+	cc := common.CollectionCriteria{Channel: "testchannel", Namespace: "mycc", Collection: "mycollection"}
+	ccp, err := cs.RetrieveCollectionConfigPackage(cc)
+	assert.NoError(t, err)
+	assert.True(t, proto.Equal(lifecycleCollections, ccp))
+	c, err := cs.RetrieveCollection(cc)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Org1MSP", "Org2MSP"}, c.MemberOrgs())
+	cc.Collection = "lscccollection"
+	_, err = cs.RetrieveCollection(cc)
+	assert.EqualError(t, err, "collection testchannel/mycc/lscccollection could not be found")
+
+	// even if its definition has none
+	cc = common.CollectionCriteria{Channel: "testchannel", Namespace: "nocollcc", Collection: "lscccollection"}
+	ccp, err = cs.RetrieveCollectionConfigPackage(cc)
+	assert.NoError(t, err)
+	assert.Nil(t, ccp)
+
+	// the collections of the other chaincodes are the ones committed through lscc
+	cc = common.CollectionCriteria{Channel: "testchannel", Namespace: "lscccc", Collection: "lscccollection"}
+	c, err = cs.RetrieveCollection(cc)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Org1MSP"}, c.MemberOrgs())
+
+	cc = common.CollectionCriteria{Channel: "testchannel", Namespace: "badcc", Collection: "mycollection"}
+	_, err = cs.RetrieveCollection(cc)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid definition of chaincode badcc")
+}
+
+func TestLifecycleDefinitionKey(t *testing.T) {
+	key := BuildLifecycleDefinitionKey("mycc")
+	assert.Equal(t, "namespaces/mycc", key)
+	ccname, ok := ParseLifecycleDefinitionKey(key)
+	assert.True(t, ok)
+	assert.Equal(t, "mycc", ccname)
+	_, ok = ParseLifecycleDefinitionKey("namespaces/")
+	assert.False(t, ok)
+	_, ok = ParseLifecycleDefinitionKey("approvals/mycc/1/Org1MSP")
+	assert.False(t, ok)
+}
+
+func TestCollectionKVSKey(t *testing.T) {
+	key := BuildCollectionKVSKey("mycc")
+	assert.Equal(t, "mycc~collection", key)
+	assert.True(t, IsCollectionConfigKey(key))
+	assert.False(t, IsCollectionConfigKey("mycc"))
+	assert.False(t, IsCollectionConfigKey("~collection"))
+}
+
+func TestRetrieveCollectionConfigPackageAt(t *testing.T) {
+	v1 := &common.CollectionConfigPackage{Config: []*common.CollectionConfig{{
+		Payload: &common.CollectionConfig_StaticCollectionConfig{
+			StaticCollectionConfig: &common.StaticCollectionConfig{
+				Name:             "mycollection",
+				MemberOrgsPolicy: createCollectionPolicyConfig(cauthdsl.SignedByAnyMember([]string{"Org1MSP"})),
+			},
+		},
+	}}}
+	v2 := &common.CollectionConfigPackage{Config: []*common.CollectionConfig{{
+		Payload: &common.CollectionConfig_StaticCollectionConfig{
+			StaticCollectionConfig: &common.StaticCollectionConfig{
+				Name:             "mycollection",
+				MemberOrgsPolicy: createCollectionPolicyConfig(cauthdsl.SignedByAnyMember([]string{"Org1MSP", "Org2MSP"})),
+			},
+		},
+	}}}
+	support := newMockStoreSupport(map[string][]byte{"mycc": utils.MarshalOrPanic(v2)})
+	support.history = mockConfigHistoryRetriever{"mycc": {
+		{CollectionConfig: v1, CommittingBlockNum: 5},
+		{CollectionConfig: v2, CommittingBlockNum: 10},
+	}}
+	cs := NewSimpleCollectionStore(support)
+	cc := common.CollectionCriteria{Channel: "testchannel", Namespace: "mycc", Collection: "mycollection"}
+
+	// the chaincode had no collection before its instantiation
+	ccp, err := cs.RetrieveCollectionConfigPackageAt(cc, 4)
+	assert.NoError(t, err)
+	assert.Nil(t, ccp)
+
+	// the collection configs in effect are the ones committed last
+	for blockNum, expected := range map[uint64]*common.CollectionConfigPackage{5: v1, 9: v1, 10: v2, 100: v2} {
+		ccp, err = cs.RetrieveCollectionConfigPackageAt(cc, blockNum)
+		assert.NoError(t, err)
+		assert.True(t, proto.Equal(expected, ccp), "block %d", blockNum)
+	}
+
+	cc.Channel = "otherchannel"
+	_, err = cs.RetrieveCollectionConfigPackageAt(cc, 10)
+	assert.EqualError(t, err, "could not retrieve config history of channel otherchannel: no ledger")
+}
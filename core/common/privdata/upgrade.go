@@ -0,0 +1,128 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package privdata
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/protos/common"
+	m "github.com/hyperledger/fabric/protos/msp"
+)
+
+// ValidateCollectionConfigs checks that the given collection configs are uniquely
+// named static collections, each with a member orgs policy and consistent peer counts
+func ValidateCollectionConfigs(collections *common.CollectionConfigPackage) error {
+	names := make(map[string]struct{})
+	for _, c := range collections.GetConfig() {
+		sc := c.GetStaticCollectionConfig()
+		if sc == nil {
+			return fmt.Errorf("unknown collection configuration type %T", c.GetPayload())
+		}
+		if sc.Name == "" {
+			return errors.New("collection name not set")
+		}
+		if _, exists := names[sc.Name]; exists {
+			return fmt.Errorf("collection %s defined more than once", sc.Name)
+		}
+		names[sc.Name] = struct{}{}
+
+		if sc.MemberOrgsPolicy.GetSignaturePolicy() == nil {
+			return fmt.Errorf("collection %s has no member orgs policy", sc.Name)
+		}
+		if sc.RequiredPeerCount < 0 {
+			return fmt.Errorf("collection %s has a negative required peer count", sc.Name)
+		}
+		if sc.MaximumPeerCount < sc.RequiredPeerCount {
+			return fmt.Errorf("collection %s has a maximum peer count (%d) lower than its required peer count (%d)", sc.Name, sc.MaximumPeerCount, sc.RequiredPeerCount)
+		}
+	}
+	return nil
+}
+
+// ValidateCollectionConfigUpgrade checks that the collection configs supplied upon the upgrade
+// of a chaincode may replace the ones it has. The collections of the chaincode cannot be removed,
+// as the peers keep their private data until it is purged according to their block to live,
+// which cannot be modified either. The other parameters of the collections, their member
+// orgs included, may be modified, and new collections may be added
+func ValidateCollectionConfigUpgrade(existing, updated *common.CollectionConfigPackage) error {
+	updatedByName := make(map[string]*common.StaticCollectionConfig)
+	for _, c := range updated.GetConfig() {
+		if sc := c.GetStaticCollectionConfig(); sc != nil {
+			updatedByName[sc.Name] = sc
+		}
+	}
+
+	for _, c := range existing.GetConfig() {
+		sc := c.GetStaticCollectionConfig()
+		if sc == nil {
+			continue
+		}
+		updatedSC, exists := updatedByName[sc.Name]
+		if !exists {
+			return fmt.Errorf("collection %s cannot be removed upon upgrade", sc.Name)
+		}
+		if updatedSC.BlockToLive != sc.BlockToLive {
+			return fmt.Errorf("the block to live of collection %s cannot be modified upon upgrade (from %d to %d)",
+				sc.Name, sc.BlockToLive, updatedSC.BlockToLive)
+		}
+	}
+	return nil
+}
+
+// ValidateCollectionMemberOrgs checks that the principals of the member orgs policies
+// of the given collections belong to the orgs of the given MSP IDs
+func ValidateCollectionMemberOrgs(collections *common.CollectionConfigPackage, mspIDs []string) error {
+	known := make(map[string]struct{}, len(mspIDs))
+	for _, mspID := range mspIDs {
+		known[mspID] = struct{}{}
+	}
+
+	for _, c := range collections.GetConfig() {
+		sc := c.GetStaticCollectionConfig()
+		if sc == nil {
+			continue
+		}
+		for _, principal := range sc.GetMemberOrgsPolicy().GetSignaturePolicy().GetIdentities() {
+			mspID, err := principalMSPID(principal)
+			if err != nil {
+				return fmt.Errorf("invalid member orgs policy of collection %s: %s", sc.Name, err)
+			}
+			if _, exists := known[mspID]; !exists {
+				return fmt.Errorf("collection %s has member org %s which is not an org of the channel", sc.Name, mspID)
+			}
+		}
+	}
+	return nil
+}
+
+// principalMSPID returns the MSP ID of the org the given principal belongs to
+func principalMSPID(principal *m.MSPPrincipal) (string, error) {
+	switch principal.PrincipalClassification {
+	case m.MSPPrincipal_ROLE:
+		mspRole := &m.MSPRole{}
+		if err := proto.Unmarshal(principal.Principal, mspRole); err != nil {
+			return "", err
+		}
+		return mspRole.MspIdentifier, nil
+	case m.MSPPrincipal_IDENTITY:
+		id := &m.SerializedIdentity{}
+		if err := proto.Unmarshal(principal.Principal, id); err != nil {
+			return "", err
+		}
+		return id.Mspid, nil
+	case m.MSPPrincipal_ORGANIZATION_UNIT:
+		ou := &m.OrganizationUnit{}
+		if err := proto.Unmarshal(principal.Principal, ou); err != nil {
+			return "", err
+		}
+		return ou.MspIdentifier, nil
+	default:
+		return "", fmt.Errorf("invalid principal type %d", int32(principal.PrincipalClassification))
+	}
+}
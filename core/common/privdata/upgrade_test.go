@@ -0,0 +1,109 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package privdata
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric/common/cauthdsl"
+	"github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/msp"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func staticCollectionConfig(name string, blockToLive uint64, policy *common.SignaturePolicyEnvelope) *common.CollectionConfig {
+	return &common.CollectionConfig{
+		Payload: &common.CollectionConfig_StaticCollectionConfig{
+			StaticCollectionConfig: &common.StaticCollectionConfig{
+				Name:             name,
+				MemberOrgsPolicy: createCollectionPolicyConfig(policy),
+				BlockToLive:      blockToLive,
+			},
+		},
+	}
+}
+
+func TestValidateCollectionConfigs(t *testing.T) {
+	org1 := cauthdsl.SignedByAnyMember([]string{"Org1MSP"})
+	validate := func(configs ...*common.CollectionConfig) error {
+		return ValidateCollectionConfigs(&common.CollectionConfigPackage{Config: configs})
+	}
+
+	assert.NoError(t, ValidateCollectionConfigs(nil))
+	assert.NoError(t, validate(staticCollectionConfig("coll1", 0, org1), staticCollectionConfig("coll2", 10, org1)))
+	assert.EqualError(t, validate(staticCollectionConfig("", 0, org1)), "collection name not set")
+	assert.EqualError(t, validate(staticCollectionConfig("coll1", 0, org1), staticCollectionConfig("coll1", 10, org1)),
+		"collection coll1 defined more than once")
+	assert.EqualError(t, validate(&common.CollectionConfig{}), "unknown collection configuration type <nil>")
+
+	noPolicy := staticCollectionConfig("coll1", 0, org1)
+	noPolicy.GetStaticCollectionConfig().MemberOrgsPolicy = nil
+	assert.EqualError(t, validate(noPolicy), "collection coll1 has no member orgs policy")
+
+	peerCounts := staticCollectionConfig("coll1", 0, org1)
+	peerCounts.GetStaticCollectionConfig().RequiredPeerCount = -1
+	assert.EqualError(t, validate(peerCounts), "collection coll1 has a negative required peer count")
+	peerCounts.GetStaticCollectionConfig().RequiredPeerCount = 2
+	peerCounts.GetStaticCollectionConfig().MaximumPeerCount = 1
+	assert.EqualError(t, validate(peerCounts),
+		"collection coll1 has a maximum peer count (1) lower than its required peer count (2)")
+}
+
+func TestValidateCollectionConfigUpgrade(t *testing.T) {
+	org1 := cauthdsl.SignedByAnyMember([]string{"Org1MSP"})
+	org1And2 := cauthdsl.SignedByAnyMember([]string{"Org1MSP", "Org2MSP"})
+	existing := &common.CollectionConfigPackage{Config: []*common.CollectionConfig{
+		staticCollectionConfig("coll1", 0, org1And2),
+		staticCollectionConfig("coll2", 10, org1),
+	}}
+	upgrade := func(configs ...*common.CollectionConfig) error {
+		return ValidateCollectionConfigUpgrade(existing, &common.CollectionConfigPackage{Config: configs})
+	}
+
+	// the member orgs may change and collections may be added
+	assert.NoError(t, upgrade(
+		staticCollectionConfig("coll1", 0, org1),
+		staticCollectionConfig("coll2", 10, org1And2),
+		staticCollectionConfig("coll3", 5, org1),
+	))
+
+	// the collections cannot be removed
+	assert.EqualError(t, upgrade(staticCollectionConfig("coll1", 0, org1And2)),
+		"collection coll2 cannot be removed upon upgrade")
+	assert.EqualError(t, upgrade(), "collection coll1 cannot be removed upon upgrade")
+
+	// and their block to live cannot be modified
+	assert.EqualError(t, upgrade(staticCollectionConfig("coll1", 0, org1And2), staticCollectionConfig("coll2", 20, org1)),
+		"the block to live of collection coll2 cannot be modified upon upgrade (from 10 to 20)")
+
+	// a chaincode without collections may get some
+	assert.NoError(t, ValidateCollectionConfigUpgrade(nil, existing))
+}
+
+func TestValidateCollectionMemberOrgs(t *testing.T) {
+	channelOrgs := []string{"Org1MSP", "Org2MSP"}
+	validate := func(policy *common.SignaturePolicyEnvelope) error {
+		return ValidateCollectionMemberOrgs(&common.CollectionConfigPackage{Config: []*common.CollectionConfig{
+			staticCollectionConfig("coll1", 0, policy),
+		}}, channelOrgs)
+	}
+
+	assert.NoError(t, validate(cauthdsl.SignedByAnyMember([]string{"Org1MSP", "Org2MSP"})))
+	assert.EqualError(t, validate(cauthdsl.SignedByAnyMember([]string{"Org1MSP", "Org3MSP"})),
+		"collection coll1 has member org Org3MSP which is not an org of the channel")
+
+	identity := utils.MarshalOrPanic(&msp.SerializedIdentity{Mspid: "Org2MSP", IdBytes: []byte("cert")})
+	policy := cauthdsl.SignedByAnyMember([]string{"Org1MSP"})
+	policy.Identities[0] = &msp.MSPPrincipal{PrincipalClassification: msp.MSPPrincipal_IDENTITY, Principal: identity}
+	assert.NoError(t, validate(policy))
+
+	policy.Identities[0] = &msp.MSPPrincipal{PrincipalClassification: msp.MSPPrincipal_ROLE, Principal: []byte("barf")}
+	err := validate(policy)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid member orgs policy of collection coll1")
+}
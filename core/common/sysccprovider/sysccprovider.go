@@ -17,6 +17,8 @@ limitations under the License.
 package sysccprovider
 
 import (
+	channelconfig "github.com/hyperledger/fabric/common/config/channel"
+	"github.com/hyperledger/fabric/common/policies"
 	"github.com/hyperledger/fabric/core/ledger"
 )
 
@@ -41,6 +43,19 @@ type SystemChaincodeProvider interface {
 	// That's useful for system chaincodes that require unfettered
 	// access to the ledger
 	GetQueryExecutorForLedger(cid string) (ledger.QueryExecutor, error)
+
+	// PolicyManager returns the policy manager of the supplied
+	// channel, and false if the peer hasn't joined the channel
+	PolicyManager(cid string) (policies.Manager, bool)
+
+	// ApplicationCapabilities returns the application capabilities
+	// of the supplied channel, and false if the peer hasn't joined
+	// the channel or the channel carries no application config
+	ApplicationCapabilities(cid string) (channelconfig.ApplicationCapabilities, bool)
+
+	// MSPIDs returns the MSP IDs of the application orgs
+	// of the supplied channel
+	MSPIDs(cid string) []string
 }
 
 var sccFactory SystemChaincodeProviderFactory
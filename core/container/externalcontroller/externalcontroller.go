@@ -0,0 +1,248 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package externalcontroller
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/core/comm"
+	container "github.com/hyperledger/fabric/core/container/api"
+	"github.com/hyperledger/fabric/core/container/ccintf"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+const defaultDialTimeout = 3 * time.Second
+
+var (
+	externalLogger = flogging.MustGetLogger("externalcontroller")
+
+	// the connections to the chaincode servers, by instance name
+	instances = struct {
+		sync.Mutex
+		m map[string]*externalInstance
+	}{m: make(map[string]*externalInstance)}
+)
+
+// ConnectionInfo is the connection definition of a chaincode running as an external
+// server, which is the code package of the chaincodes installed with the EXTERNAL
+// execution environment. The TLS material is PEM encoded.
+type ConnectionInfo struct {
+	Address            string `json:"address"`
+	DialTimeout        string `json:"dial_timeout,omitempty"`
+	TLSRequired        bool   `json:"tls_required,omitempty"`
+	ClientAuthRequired bool   `json:"client_auth_required,omitempty"`
+	ClientKey          string `json:"client_key,omitempty"`
+	ClientCert         string `json:"client_cert,omitempty"`
+	RootCert           string `json:"root_cert,omitempty"`
+}
+
+// ParseConnectionInfo parses and validates a connection definition
+func ParseConnectionInfo(raw []byte) (*ConnectionInfo, error) {
+	info := &ConnectionInfo{}
+	if err := json.Unmarshal(raw, info); err != nil {
+		return nil, fmt.Errorf("invalid chaincode connection definition: %s", err)
+	}
+	if info.Address == "" {
+		return nil, fmt.Errorf("invalid chaincode connection definition: address not set")
+	}
+	if info.DialTimeout != "" {
+		if _, err := time.ParseDuration(info.DialTimeout); err != nil {
+			return nil, fmt.Errorf("invalid chaincode connection definition: invalid dial_timeout %s: %s", info.DialTimeout, err)
+		}
+	}
+	if info.TLSRequired {
+		if _, err := info.tlsConfig(); err != nil {
+			return nil, fmt.Errorf("invalid chaincode connection definition: %s", err)
+		}
+	}
+	return info, nil
+}
+
+func (info *ConnectionInfo) dialTimeout() time.Duration {
+	if timeout, err := time.ParseDuration(info.DialTimeout); err == nil && timeout > 0 {
+		return timeout
+	}
+	return defaultDialTimeout
+}
+
+func (info *ConnectionInfo) tlsConfig() (*tls.Config, error) {
+	if info.RootCert == "" {
+		return nil, fmt.Errorf("root_cert is required with TLS")
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM([]byte(info.RootCert)) {
+		return nil, fmt.Errorf("failed to load root_cert")
+	}
+	tlsConfig := &tls.Config{RootCAs: pool}
+
+	if info.ClientAuthRequired {
+		cert, err := tls.X509KeyPair([]byte(info.ClientCert), []byte(info.ClientKey))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client_cert and client_key: %s", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
+func (info *ConnectionInfo) dial() (*grpc.ClientConn, error) {
+	opts := []grpc.DialOption{
+		grpc.WithBlock(),
+		grpc.WithTimeout(info.dialTimeout()),
+		grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(comm.MaxRecvMsgSize()), grpc.MaxCallSendMsgSize(comm.MaxSendMsgSize())),
+	}
+	if info.TLSRequired {
+		tlsConfig, err := info.tlsConfig()
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
+	} else {
+		opts = append(opts, grpc.WithInsecure())
+	}
+	return grpc.Dial(info.Address, opts...)
+}
+
+type externalInstance struct {
+	conn   *grpc.ClientConn
+	cancel context.CancelFunc
+}
+
+// ExternalVM is a vm for the chaincodes running as servers managed outside of the
+// peer, such as in a Kubernetes deployment. Rather than launching a container, the
+// peer connects to the chaincode server and the chaincode registers over that stream.
+type ExternalVM struct {
+}
+
+// Deploy has nothing to build, the chaincode server is deployed separately
+func (vm *ExternalVM) Deploy(ctxt context.Context, ccid ccintf.CCID, args []string, env []string, reader io.Reader) error {
+	return nil
+}
+
+// Start connects to the chaincode server of the connection definition the builder
+// supplies, and handles the stream to the chaincode until it ends or Stop is called
+func (vm *ExternalVM) Start(ctxt context.Context, ccid ccintf.CCID, args []string, env []string, builder container.BuildSpecFactory, prelaunchFunc container.PrelaunchFunc) error {
+	instName, _ := vm.GetVMName(ccid, nil)
+
+	ccSupport, ok := ctxt.Value(ccintf.GetCCHandlerKey()).(ccintf.CCSupport)
+	if !ok || ccSupport == nil {
+		return fmt.Errorf("chaincode support not supplied")
+	}
+
+	if builder == nil {
+		return fmt.Errorf("connection definition of %s not supplied", instName)
+	}
+	reader, err := builder()
+	if err != nil {
+		return fmt.Errorf("failed to read the connection definition of %s: %s", instName, err)
+	}
+	raw, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return fmt.Errorf("failed to read the connection definition of %s: %s", instName, err)
+	}
+	info, err := ParseConnectionInfo(raw)
+	if err != nil {
+		return err
+	}
+
+	instances.Lock()
+	if _, running := instances.m[instName]; running {
+		instances.Unlock()
+		return fmt.Errorf("chaincode %s already connected", instName)
+	}
+	instances.Unlock()
+
+	externalLogger.Debugf("connecting to chaincode %s at %s", instName, info.Address)
+	conn, err := info.dial()
+	if err != nil {
+		return fmt.Errorf("failed to connect to chaincode %s at %s: %s", instName, info.Address, err)
+	}
+
+	// the stream outlives the transaction which launched the chaincode
+	streamCtxt, cancel := context.WithCancel(context.Background())
+	stream, err := pb.NewChaincodeClient(conn).Connect(streamCtxt)
+	if err != nil {
+		cancel()
+		conn.Close()
+		return fmt.Errorf("failed to connect to chaincode %s at %s: %s", instName, info.Address, err)
+	}
+
+	if prelaunchFunc != nil {
+		if err = prelaunchFunc(); err != nil {
+			cancel()
+			conn.Close()
+			return err
+		}
+	}
+
+	inst := &externalInstance{conn: conn, cancel: cancel}
+	instances.Lock()
+	instances.m[instName] = inst
+	instances.Unlock()
+
+	go func() {
+		err := ccSupport.HandleChaincodeStream(streamCtxt, stream)
+		externalLogger.Debugf("stream to chaincode %s at %s ended: %s", instName, info.Address, err)
+
+		instances.Lock()
+		if instances.m[instName] == inst {
+			delete(instances.m, instName)
+		}
+		instances.Unlock()
+		cancel()
+		conn.Close()
+	}()
+
+	return nil
+}
+
+// Stop disconnects from the chaincode server, which keeps running
+func (vm *ExternalVM) Stop(ctxt context.Context, ccid ccintf.CCID, timeout uint, dontkill bool, dontremove bool) error {
+	instName, _ := vm.GetVMName(ccid, nil)
+
+	instances.Lock()
+	inst, ok := instances.m[instName]
+	delete(instances.m, instName)
+	instances.Unlock()
+
+	if !ok {
+		return fmt.Errorf("%s not connected", instName)
+	}
+	inst.cancel()
+	return inst.conn.Close()
+}
+
+// Destroy has nothing to remove, the chaincode server is managed separately
+func (vm *ExternalVM) Destroy(ctxt context.Context, ccid ccintf.CCID, force bool, noprune bool) error {
+	return nil
+}
+
+// GetVMName returns the canonical name of the chaincode, as there is no container
+// to name. It accepts a format function parameter to allow different formatting
+// based on the desired use of the name.
+func (vm *ExternalVM) GetVMName(ccid ccintf.CCID, format func(string) (string, error)) (string, error) {
+	name := ccid.GetName()
+	if format != nil {
+		formattedName, err := format(name)
+		if err != nil {
+			return formattedName, err
+		}
+		name = formattedName
+	}
+	return name, nil
+}
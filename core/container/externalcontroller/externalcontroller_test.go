@@ -0,0 +1,145 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package externalcontroller
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	"github.com/hyperledger/fabric/core/container/ccintf"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+)
+
+type testChaincode struct{}
+
+func (testChaincode) Init(stub shim.ChaincodeStubInterface) pb.Response {
+	return shim.Success(nil)
+}
+
+func (testChaincode) Invoke(stub shim.ChaincodeStubInterface) pb.Response {
+	return shim.Success(nil)
+}
+
+// mockCCSupport hands the streams to the chaincodes over to the test
+type mockCCSupport struct {
+	streams chan ccintf.ChaincodeStream
+	done    chan struct{}
+}
+
+func (m *mockCCSupport) HandleChaincodeStream(ctxt context.Context, stream ccintf.ChaincodeStream) error {
+	m.streams <- stream
+	<-m.done
+	return nil
+}
+
+func TestParseConnectionInfo(t *testing.T) {
+	info, err := ParseConnectionInfo([]byte(`{"address": "mycc:9999", "dial_timeout": "10s"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, "mycc:9999", info.Address)
+	assert.Equal(t, 10*time.Second, info.dialTimeout())
+
+	info, err = ParseConnectionInfo([]byte(`{"address": "mycc:9999"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, defaultDialTimeout, info.dialTimeout())
+
+	for _, raw := range []string{
+		`garbage`,
+		`{"dial_timeout": "10s"}`,
+		`{"address": "mycc:9999", "dial_timeout": "forever"}`,
+		`{"address": "mycc:9999", "tls_required": true}`,
+		`{"address": "mycc:9999", "tls_required": true, "root_cert": "not a certificate"}`,
+	} {
+		_, err = ParseConnectionInfo([]byte(raw))
+		assert.Error(t, err, "connection definition %s should be invalid", raw)
+	}
+}
+
+func TestStartStop(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	address := listener.Addr().String()
+	listener.Close()
+
+	server := &shim.ChaincodeServer{CCID: "mycc:0", Address: address, CC: testChaincode{}}
+	go server.Start()
+	defer server.Stop()
+
+	ccSupport := &mockCCSupport{streams: make(chan ccintf.ChaincodeStream, 1), done: make(chan struct{})}
+	defer close(ccSupport.done)
+	ctxt := context.WithValue(context.Background(), ccintf.GetCCHandlerKey(), ccSupport)
+	ccid := ccintf.CCID{ChaincodeSpec: &pb.ChaincodeSpec{ChaincodeId: &pb.ChaincodeID{Name: "mycc"}}, Version: "0"}
+	builder := func() (io.Reader, error) {
+		return bytes.NewReader([]byte(fmt.Sprintf(`{"address": "%s", "dial_timeout": "5s"}`, address))), nil
+	}
+
+	prelaunched := false
+	vm := &ExternalVM{}
+	err = vm.Start(ctxt, ccid, nil, nil, builder, func() error {
+		prelaunched = true
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.True(t, prelaunched)
+
+	// the chaincode registers over the stream the peer opened
+	var stream ccintf.ChaincodeStream
+	select {
+	case stream = <-ccSupport.streams:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Timed out waiting for the stream to the chaincode")
+	}
+	msg, err := stream.Recv()
+	assert.NoError(t, err)
+	assert.Equal(t, pb.ChaincodeMessage_REGISTER, msg.Type)
+	chaincodeID := &pb.ChaincodeID{}
+	assert.NoError(t, proto.Unmarshal(msg.Payload, chaincodeID))
+	assert.Equal(t, "mycc:0", chaincodeID.Name)
+
+	// a chaincode is connected once
+	err = vm.Start(ctxt, ccid, nil, nil, builder, nil)
+	assert.Error(t, err)
+
+	assert.NoError(t, vm.Stop(ctxt, ccid, 0, false, false))
+	_, err = stream.Recv()
+	assert.Error(t, err)
+	assert.Error(t, vm.Stop(ctxt, ccid, 0, false, false))
+}
+
+func TestStartErrors(t *testing.T) {
+	vm := &ExternalVM{}
+	ccid := ccintf.CCID{ChaincodeSpec: &pb.ChaincodeSpec{ChaincodeId: &pb.ChaincodeID{Name: "mycc"}}, Version: "0"}
+	ccSupport := &mockCCSupport{streams: make(chan ccintf.ChaincodeStream, 1), done: make(chan struct{})}
+	ctxt := context.WithValue(context.Background(), ccintf.GetCCHandlerKey(), ccSupport)
+
+	err := vm.Start(context.Background(), ccid, nil, nil, nil, nil)
+	assert.EqualError(t, err, "chaincode support not supplied")
+
+	err = vm.Start(ctxt, ccid, nil, nil, nil, nil)
+	assert.EqualError(t, err, "connection definition of mycc-0 not supplied")
+
+	err = vm.Start(ctxt, ccid, nil, nil, func() (io.Reader, error) { return bytes.NewReader([]byte(`{}`)), nil }, nil)
+	assert.EqualError(t, err, "invalid chaincode connection definition: address not set")
+
+	// nothing listens on the address
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	address := listener.Addr().String()
+	listener.Close()
+	err = vm.Start(ctxt, ccid, nil, nil, func() (io.Reader, error) {
+		return bytes.NewReader([]byte(fmt.Sprintf(`{"address": "%s", "dial_timeout": "100ms"}`, address))), nil
+	}, nil)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to connect to chaincode mycc-0")
+}
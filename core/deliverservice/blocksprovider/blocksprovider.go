@@ -15,6 +15,7 @@ import (
 	"github.com/golang/protobuf/proto"
 	gossipcommon "github.com/hyperledger/fabric/gossip/common"
 	"github.com/hyperledger/fabric/gossip/discovery"
+	"github.com/hyperledger/fabric/gossip/gossip"
 
 	"github.com/hyperledger/fabric/common/flogging"
 	"github.com/hyperledger/fabric/gossip/api"
@@ -42,6 +43,24 @@ type GossipServiceAdapter interface {
 
 	// Gossip the message across the peers
 	Gossip(msg *gossip_proto.GossipMessage)
+
+	// SendByCriteria sends the message to the peers of a channel matching the
+	// criteria, and waits for the number of them requested to acknowledge it
+	SendByCriteria(msg *gossip_proto.GossipMessage, criteria gossip.SendCriteria) error
+}
+
+// AckConfig configures the push of the blocks delivered to a quorum of peers of the
+// channel, which acknowledge them. The blocks are pushed on top of being gossiped
+type AckConfig struct {
+	// MinAck is the number of peers that need to acknowledge every block,
+	// the blocks are only gossiped if not positive
+	MinAck int
+	// Timeout is the time to wait for the acknowledgements
+	// of the peers before retrying other peers
+	Timeout time.Duration
+	// MaxPeers is the max number of peers a block is pushed to, including
+	// the retries, all the peers of the channel if not positive
+	MaxPeers int
 }
 
 // BlocksProvider used to read blocks from the ordering service
@@ -90,6 +109,8 @@ type blocksProviderImpl struct {
 	done int32
 
 	wrongStatusThreshold int
+
+	ackConf AckConfig
 }
 
 const wrongStatusThreshold = 10
@@ -102,14 +123,16 @@ func init() {
 	logger = flogging.MustGetLogger("blocksProvider")
 }
 
-// NewBlocksProvider constructor function to create blocks deliverer instance
-func NewBlocksProvider(chainID string, client streamClient, gossip GossipServiceAdapter, mcs api.MessageCryptoService) BlocksProvider {
+// NewBlocksProvider constructor function to create blocks deliverer instance,
+// which pushes the blocks to a quorum of peers as configured by ackConf
+func NewBlocksProvider(chainID string, client streamClient, gossip GossipServiceAdapter, mcs api.MessageCryptoService, ackConf AckConfig) BlocksProvider {
 	return &blocksProviderImpl{
 		chainID:              chainID,
 		client:               client,
 		gossip:               gossip,
 		mcs:                  mcs,
 		wrongStatusThreshold: wrongStatusThreshold,
+		ackConf:              ackConf,
 	}
 }
 
@@ -184,6 +207,7 @@ func (b *blocksProviderImpl) DeliverBlocks() {
 			// Gossip messages with other nodes
 			logger.Debugf("[%s] Gossiping block [%d], peers number [%d]", b.chainID, seqNum, numberOfPeers)
 			b.gossip.Gossip(gossipMsg)
+			b.pushToQuorum(gossipMsg, seqNum)
 		default:
 			logger.Warningf("[%s] Received unknown: ", b.chainID, t)
 			return
@@ -191,6 +215,25 @@ func (b *blocksProviderImpl) DeliverBlocks() {
 	}
 }
 
+// pushToQuorum sends the block to the configured number of peers of the channel, and waits for them to
+// acknowledge it, so that the block reaches a quorum of peers regardless of the randomness of gossip
+func (b *blocksProviderImpl) pushToQuorum(gossipMsg *gossip_proto.GossipMessage, seqNum uint64) {
+	if b.ackConf.MinAck <= 0 {
+		return
+	}
+	criteria := gossip.SendCriteria{
+		Timeout:  b.ackConf.Timeout,
+		MinAck:   b.ackConf.MinAck,
+		MaxPeers: b.ackConf.MaxPeers,
+		Channel:  gossipcommon.ChainID(b.chainID),
+	}
+	if err := b.gossip.SendByCriteria(gossipMsg, criteria); err != nil {
+		logger.Warningf("[%s] Block [%d] wasn't acknowledged by %d peers: %s", b.chainID, seqNum, b.ackConf.MinAck, err)
+		return
+	}
+	logger.Debugf("[%s] Block [%d] was acknowledged by %d peers", b.chainID, seqNum, b.ackConf.MinAck)
+}
+
 // Stop stops blocks delivery provider
 func (b *blocksProviderImpl) Stop() {
 	atomic.StoreInt32(&b.done, 1)
@@ -67,7 +67,7 @@ func makeTestCase(ledgerHeight uint64, mcs api.MessageCryptoService, shouldSucce
 		gossipServiceAdapter := &mocks.MockGossipServiceAdapter{GossipBlockDisseminations: make(chan uint64)}
 		deliverer := &mocks.MockBlocksDeliverer{Pos: ledgerHeight}
 		deliverer.MockRecv = rcv
-		provider := NewBlocksProvider("***TEST_CHAINID***", deliverer, gossipServiceAdapter, mcs)
+		provider := NewBlocksProvider("***TEST_CHAINID***", deliverer, gossipServiceAdapter, mcs, AckConfig{})
 		defer provider.Stop()
 		ready := make(chan struct{})
 		go func() {
@@ -439,3 +439,26 @@ func TestBlockVerificationFailure(t *testing.T) {
 	mcs.On("VerifyBlock", mock.Anything).Return(errors.New("Invalid signature"))
 	makeTestCase(uint64(0), mcs, false, rcvr)(t)
 }
+
+func TestBlocksProviderPushToQuorum(t *testing.T) {
+	bd := mocks.MockBlocksDeliverer{Pos: 0, CloseCalled: make(chan struct{}, 1)}
+	bd.MockRecv = mocks.MockRecv
+	mcs := &mockMCS{}
+	mcs.On("VerifyBlock", mock.Anything).Return(nil)
+	gossipServiceAdapter := &mocks.MockGossipServiceAdapter{
+		GossipBlockDisseminations: make(chan uint64, 10),
+		AckedBlocks:               make(chan uint64, 10),
+	}
+	provider := NewBlocksProvider("***TEST_CHAINID***", &bd, gossipServiceAdapter, mcs, AckConfig{MinAck: 2, Timeout: time.Second})
+	go provider.DeliverBlocks()
+	defer provider.Stop()
+
+	// The blocks are both gossiped and pushed to a quorum of peers
+	select {
+	case seqNum := <-gossipServiceAdapter.AckedBlocks:
+		assert.Equal(t, uint64(0), seqNum)
+	case <-time.After(time.Second * 5):
+		t.Fatal("Didn't push the block to a quorum of peers")
+	}
+	assert.Equal(t, uint64(0), <-gossipServiceAdapter.GossipBlockDisseminations)
+}
@@ -46,6 +46,10 @@ type broadcastClient struct {
 	blocksprovider.BlocksDeliverer
 	conn     *connection
 	endpoint string
+	// The source of blocks when none of the endpoints of prod is reachable, nil if none
+	fallback *deliverFallback
+	// When the client connected to the fallback, zero while connected to an endpoint of prod
+	fallbackSince time.Time
 }
 
 // NewBroadcastClient returns a broadcastClient with the given params
@@ -105,6 +109,10 @@ func (bc *broadcastClient) try(action func() (interface{}, error)) (interface{},
 }
 
 func (bc *broadcastClient) doAction(action func() (interface{}, error)) (interface{}, error) {
+	if bc.conn != nil && !bc.fallbackSince.IsZero() && time.Since(bc.fallbackSince) >= bc.fallback.retryInterval {
+		logger.Info("Disconnecting from", bc.endpoint, "to try connecting to the ordering service again")
+		bc.Disconnect(false)
+	}
 	if bc.conn == nil {
 		err := bc.connect()
 		if err != nil {
@@ -129,6 +137,12 @@ func (bc *broadcastClient) sleep(duration time.Duration) {
 func (bc *broadcastClient) connect() error {
 	bc.endpoint = ""
 	conn, endpoint, err := bc.prod.NewConnection()
+	createClient, onFallback := bc.createClient, false
+	if err != nil && bc.fallback != nil {
+		logger.Warning("Failed connecting to the ordering service, falling back to the other peers of the organization:", err)
+		conn, endpoint, err = bc.fallback.prod.NewConnection()
+		createClient, onFallback = bc.fallback.createClient, true
+	}
 	logger.Debug("Connected to", endpoint)
 	if err != nil {
 		logger.Error("Failed obtaining connection:", err)
@@ -136,7 +150,7 @@ func (bc *broadcastClient) connect() error {
 	}
 	ctx, cf := context.WithCancel(context.Background())
 	logger.Debug("Establishing gRPC stream with", endpoint, "...")
-	abc, err := bc.createClient(conn).Deliver(ctx)
+	abc, err := createClient(conn).Deliver(ctx)
 	if err != nil {
 		logger.Error("Connection to ", endpoint, "established but was unable to create gRPC stream:", err)
 		conn.Close()
@@ -144,6 +158,10 @@ func (bc *broadcastClient) connect() error {
 	}
 	err = bc.afterConnect(conn, abc, cf, endpoint)
 	if err == nil {
+		bc.fallbackSince = time.Time{}
+		if onFallback {
+			bc.fallbackSince = time.Now()
+		}
 		return nil
 	}
 	logger.Warning("Failed running post-connection procedures:", err)
@@ -218,6 +236,9 @@ func (bc *broadcastClient) Disconnect(disableEndpoint bool) {
 	defer bc.Unlock()
 	if disableEndpoint && bc.endpoint != "" {
 		bc.prod.DisableEndpoint(bc.endpoint)
+		if bc.fallback != nil {
+			bc.fallback.prod.DisableEndpoint(bc.endpoint)
+		}
 	}
 	bc.endpoint = ""
 	if bc.conn == nil {
@@ -10,6 +10,7 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"sort"
 	"sync"
 	"time"
 
@@ -19,6 +20,7 @@ import (
 	"github.com/hyperledger/fabric/gossip/api"
 	"github.com/hyperledger/fabric/protos/orderer"
 	"github.com/op/go-logging"
+	"golang.org/x/net/context"
 	"google.golang.org/grpc"
 )
 
@@ -55,6 +57,10 @@ type DeliverService interface {
 
 	// Stop terminates delivery service and closes the connection
 	Stop()
+
+	// HealthCheck returns an error if the delivery service is stopped, or if the delivery
+	// of blocks to some channel terminated without being stopped
+	HealthCheck(ctx context.Context) error
 }
 
 // deliverServiceImpl the implementation of the delivery service
@@ -63,6 +69,7 @@ type DeliverService interface {
 type deliverServiceImpl struct {
 	conf           *Config
 	blockProviders map[string]blocksprovider.BlocksProvider
+	terminated     map[string]bool
 	lock           sync.RWMutex
 	stopping       bool
 }
@@ -84,6 +91,16 @@ type Config struct {
 	Gossip blocksprovider.GossipServiceAdapter
 	// Endpoints specifies the endpoints of the ordering service
 	Endpoints []string
+	// PeerConnFactory creates a connection to the Deliver service of another peer of the
+	// organization, which the blocks are fetched from while none of the endpoints of the
+	// ordering service is reachable. The fallback to the other peers is disabled if nil
+	PeerConnFactory func(endpoint string) (*grpc.ClientConn, error)
+	// PeerFallbackRetryInterval is how long the blocks are fetched from another peer before
+	// connecting to the ordering service is tried again, one minute if not positive
+	PeerFallbackRetryInterval time.Duration
+	// BlockAck configures the push of the blocks delivered to a quorum of peers of
+	// the channel, which acknowledge them. The blocks are only gossiped if zero
+	BlockAck blocksprovider.AckConfig
 }
 
 // NewDeliverService construction function to create and initialize
@@ -94,6 +111,7 @@ func NewDeliverService(conf *Config) (DeliverService, error) {
 	ds := &deliverServiceImpl{
 		conf:           conf,
 		blockProviders: make(map[string]blocksprovider.BlocksProvider),
+		terminated:     make(map[string]bool),
 	}
 	if err := ds.validateConfiguration(); err != nil {
 		return nil, err
@@ -140,9 +158,11 @@ func (d *deliverServiceImpl) StartDeliverForChannel(chainID string, ledgerInfo b
 	} else {
 		client := d.newClient(chainID, ledgerInfo)
 		logger.Debug("This peer will pass blocks from orderer service to other peers for channel", chainID)
-		d.blockProviders[chainID] = blocksprovider.NewBlocksProvider(chainID, client, d.conf.Gossip, d.conf.CryptoSvc)
+		provider := blocksprovider.NewBlocksProvider(chainID, client, d.conf.Gossip, d.conf.CryptoSvc, d.conf.BlockAck)
+		d.blockProviders[chainID] = provider
 		go func() {
-			d.blockProviders[chainID].DeliverBlocks()
+			provider.DeliverBlocks()
+			d.markTerminated(chainID, provider)
 			finalizer()
 		}()
 	}
@@ -161,6 +181,7 @@ func (d *deliverServiceImpl) StopDeliverForChannel(chainID string) error {
 	if client, exist := d.blockProviders[chainID]; exist {
 		client.Stop()
 		delete(d.blockProviders, chainID)
+		delete(d.terminated, chainID)
 		logger.Debug("This peer will stop pass blocks from orderer service to other peers")
 	} else {
 		errMsg := fmt.Sprintf("Delivery service - no block provider for %s found, can't stop delivery", chainID)
@@ -182,6 +203,36 @@ func (d *deliverServiceImpl) Stop() {
 	}
 }
 
+// markTerminated records that the delivery of blocks to the channel terminated, unless it was stopped
+func (d *deliverServiceImpl) markTerminated(chainID string, provider blocksprovider.BlocksProvider) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if d.stopping || d.blockProviders[chainID] != provider {
+		return
+	}
+	logger.Warningf("Delivery of blocks for channel %s terminated", chainID)
+	d.terminated[chainID] = true
+}
+
+// HealthCheck returns an error if the delivery service is stopped, or if the delivery
+// of blocks to some channel terminated without being stopped
+func (d *deliverServiceImpl) HealthCheck(ctx context.Context) error {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+	if d.stopping {
+		return errors.New("Delivery service is stopped")
+	}
+	if len(d.terminated) == 0 {
+		return nil
+	}
+	var chainIDs []string
+	for chainID := range d.terminated {
+		chainIDs = append(chainIDs, chainID)
+	}
+	sort.Strings(chainIDs)
+	return fmt.Errorf("Delivery of blocks terminated for channels %v", chainIDs)
+}
+
 func (d *deliverServiceImpl) newClient(chainID string, ledgerInfoProvider blocksprovider.LedgerInfo) *broadcastClient {
 	requester := &blocksRequester{
 		chainID: chainID,
@@ -199,33 +250,60 @@ func (d *deliverServiceImpl) newClient(chainID string, ledgerInfoProvider blocks
 	}
 	connProd := comm.NewConnectionProducer(d.conf.ConnFactory(chainID), d.conf.Endpoints)
 	bClient := NewBroadcastClient(connProd, d.conf.ABCFactory, broadcastSetup, backoffPolicy)
+	if d.conf.PeerConnFactory != nil {
+		retryInterval := d.conf.PeerFallbackRetryInterval
+		if retryInterval <= 0 {
+			retryInterval = defPeerFallbackRetryInterval
+		}
+		bClient.fallback = &deliverFallback{
+			prod:          newOrgPeersConnProducer(chainID, d.conf.Gossip, d.conf.PeerConnFactory),
+			createClient:  PeerABCFactory,
+			retryInterval: retryInterval,
+		}
+	}
 	requester.client = bClient
 	return bClient
 }
 
+// DefaultConnectionFactory creates the connections to the ordering service with the
+// keepalive and message size settings of peer.deliveryclient, or the peer wide ones
 func DefaultConnectionFactory(channelID string) func(endpoint string) (*grpc.ClientConn, error) {
-	return func(endpoint string) (*grpc.ClientConn, error) {
-		dialOpts := []grpc.DialOption{grpc.WithTimeout(connTimeout), grpc.WithBlock()}
-		// set max send/recv msg sizes
-		dialOpts = append(dialOpts, grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(comm.MaxRecvMsgSize()),
-			grpc.MaxCallSendMsgSize(comm.MaxSendMsgSize())))
-		// set the keepalive options
-		dialOpts = append(dialOpts, comm.ClientKeepaliveOptions()...)
-
-		if comm.TLSEnabled() {
-			creds, err := comm.GetCASupport().GetDeliverServiceCredentials(channelID)
-			if err != nil {
-				return nil, fmt.Errorf("Failed obtaining credentials for channel %s: %v", channelID, err)
-			}
-			dialOpts = append(dialOpts, grpc.WithTransportCredentials(creds))
-		} else {
-			dialOpts = append(dialOpts, grpc.WithInsecure())
+	grpcOpts, err := comm.GRPCOptionsFromConfig("peer.deliveryclient")
+	if err != nil {
+		logger.Warningf("Invalid delivery client gRPC settings, using the peer wide ones: %v", err)
+		grpcOpts = comm.DefaultGRPCOptions()
+	}
+	return NewConnectionFactory(grpcOpts)(channelID)
+}
+
+// NewConnectionFactory returns a connection factory which creates the connections
+// to the ordering service with the given keepalive and message size settings
+func NewConnectionFactory(grpcOpts comm.GRPCOptions) func(channelID string) func(endpoint string) (*grpc.ClientConn, error) {
+	return func(channelID string) func(endpoint string) (*grpc.ClientConn, error) {
+		return func(endpoint string) (*grpc.ClientConn, error) {
+			return dial(channelID, endpoint, grpcOpts)
 		}
-		grpc.EnableTracing = true
-		return grpc.Dial(endpoint, dialOpts...)
 	}
 }
 
+func dial(channelID string, endpoint string, grpcOpts comm.GRPCOptions) (*grpc.ClientConn, error) {
+	dialOpts := []grpc.DialOption{grpc.WithTimeout(connTimeout), grpc.WithBlock()}
+	// set max send/recv msg sizes, and the keepalive options
+	dialOpts = append(dialOpts, grpcOpts.DialOptions()...)
+
+	if comm.TLSEnabled() {
+		creds, err := comm.GetCASupport().GetDeliverServiceCredentials(channelID)
+		if err != nil {
+			return nil, fmt.Errorf("Failed obtaining credentials for channel %s: %v", channelID, err)
+		}
+		dialOpts = append(dialOpts, grpc.WithTransportCredentials(creds))
+	} else {
+		dialOpts = append(dialOpts, grpc.WithInsecure())
+	}
+	grpc.EnableTracing = true
+	return grpc.Dial(endpoint, dialOpts...)
+}
+
 func DefaultABCFactory(conn *grpc.ClientConn) orderer.AtomicBroadcastClient {
 	return orderer.NewAtomicBroadcastClient(conn)
 }
@@ -12,6 +12,7 @@ import (
 	"github.com/golang/protobuf/proto"
 	gossip_common "github.com/hyperledger/fabric/gossip/common"
 	"github.com/hyperledger/fabric/gossip/discovery"
+	"github.com/hyperledger/fabric/gossip/gossip"
 	"github.com/hyperledger/fabric/protos/common"
 	gossip_proto "github.com/hyperledger/fabric/protos/gossip"
 	"github.com/hyperledger/fabric/protos/orderer"
@@ -27,6 +28,8 @@ type MockGossipServiceAdapter struct {
 	AddPayloadsCnt int32
 
 	GossipBlockDisseminations chan uint64
+
+	AckedBlocks chan uint64
 }
 
 type MockAtomicBroadcastClient struct {
@@ -56,6 +59,14 @@ func (mock *MockGossipServiceAdapter) Gossip(msg *gossip_proto.GossipMessage) {
 	mock.GossipBlockDisseminations <- msg.GetDataMsg().Payload.SeqNum
 }
 
+// SendByCriteria sends the message to the peers matching the criteria, which all acknowledge it
+func (mock *MockGossipServiceAdapter) SendByCriteria(msg *gossip_proto.GossipMessage, criteria gossip.SendCriteria) error {
+	if mock.AckedBlocks != nil {
+		mock.AckedBlocks <- msg.GetDataMsg().Payload.SeqNum
+	}
+	return nil
+}
+
 // MockBlocksDeliverer mocking structure of BlocksDeliverer interface to initialize
 // the blocks provider implementation
 type MockBlocksDeliverer struct {
@@ -0,0 +1,128 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package deliverclient
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric/core/comm"
+	"github.com/hyperledger/fabric/core/deliverservice/blocksprovider"
+	"github.com/hyperledger/fabric/gossip/api"
+	gossipcommon "github.com/hyperledger/fabric/gossip/common"
+	"github.com/hyperledger/fabric/protos/orderer"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+)
+
+// defPeerFallbackRetryInterval is how long the blocks are fetched from another
+// peer before reconnecting to the ordering service is tried again
+const defPeerFallbackRetryInterval = time.Minute
+
+// deliverFallback is the source of blocks a broadcast client falls back to
+// when none of the endpoints of its connection producer is reachable
+type deliverFallback struct {
+	prod         comm.ConnectionProducer
+	createClient clientFactory
+	// How long the fallback is used before the endpoints of the
+	// connection producer of the client are tried again
+	retryInterval time.Duration
+}
+
+// orgPeersConnProducer produces connections to the other peers of the organization
+// which are members of a channel, as gossip currently sees them
+type orgPeersConnProducer struct {
+	chainID string
+	gossip  blocksprovider.GossipServiceAdapter
+	connect comm.ConnectionFactory
+
+	lock sync.Mutex
+	prod comm.ConnectionProducer
+}
+
+func newOrgPeersConnProducer(chainID string, gossip blocksprovider.GossipServiceAdapter, connect comm.ConnectionFactory) *orgPeersConnProducer {
+	return &orgPeersConnProducer{chainID: chainID, gossip: gossip, connect: connect}
+}
+
+// NewConnection creates a new connection to one of the peers of the organization
+// in the channel. Returns the connection, the endpoint selected, nil on success.
+// Returns nil, "", error on failure
+func (p *orgPeersConnProducer) NewConnection() (*grpc.ClientConn, string, error) {
+	endpoints := p.endpoints()
+	if len(endpoints) == 0 {
+		return nil, "", errors.New("no peers of the organization in the channel to deliver blocks from")
+	}
+	p.lock.Lock()
+	if p.prod == nil {
+		p.prod = comm.NewConnectionProducer(p.connect, endpoints)
+	} else {
+		p.prod.UpdateEndpoints(endpoints)
+	}
+	prod := p.prod
+	p.lock.Unlock()
+	return prod.NewConnection()
+}
+
+// UpdateEndpoints ignores the given endpoints, as the endpoints
+// of the peers are taken from the membership of the channel
+func (p *orgPeersConnProducer) UpdateEndpoints(endpoints []string) {
+}
+
+// DisableEndpoint removes the endpoint of a peer for some time
+func (p *orgPeersConnProducer) DisableEndpoint(endpoint string) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.prod != nil {
+		p.prod.DisableEndpoint(endpoint)
+	}
+}
+
+// endpoints returns the endpoints of the peers of the organization in the channel,
+// which are the peers that disseminate their internal endpoints to this peer
+func (p *orgPeersConnProducer) endpoints() []string {
+	var endpoints []string
+	for _, member := range p.gossip.PeersOfChannel(gossipcommon.ChainID(p.chainID)) {
+		if member.InternalEndpoint != "" {
+			endpoints = append(endpoints, member.InternalEndpoint)
+		}
+	}
+	return endpoints
+}
+
+// peerDeliverClient adapts the Deliver service of a peer, which follows the
+// protocol of the Deliver service of the ordering service, to an AtomicBroadcastClient
+type peerDeliverClient struct {
+	client pb.DeliverClient
+}
+
+// PeerABCFactory creates an AtomicBroadcastClient delivering the
+// blocks of the Deliver service of the peer connected to
+func PeerABCFactory(conn *grpc.ClientConn) orderer.AtomicBroadcastClient {
+	return &peerDeliverClient{client: pb.NewDeliverClient(conn)}
+}
+
+// Broadcast returns an error, as peers don't order transactions
+func (c *peerDeliverClient) Broadcast(ctx context.Context, opts ...grpc.CallOption) (orderer.AtomicBroadcast_BroadcastClient, error) {
+	return nil, errors.New("peers don't serve Broadcast")
+}
+
+// Deliver opens a stream to the Deliver service of the peer
+func (c *peerDeliverClient) Deliver(ctx context.Context, opts ...grpc.CallOption) (orderer.AtomicBroadcast_DeliverClient, error) {
+	return c.client.Deliver(ctx, opts...)
+}
+
+// PeerConnectionFactory creates the connections to the Deliver service of the
+// other peers of the organization with the dial options of gossip
+func PeerConnectionFactory(secureDialOpts api.PeerSecureDialOpts) func(endpoint string) (*grpc.ClientConn, error) {
+	return func(endpoint string) (*grpc.ClientConn, error) {
+		dialOpts := []grpc.DialOption{grpc.WithTimeout(connTimeout), grpc.WithBlock()}
+		dialOpts = append(dialOpts, secureDialOpts()...)
+		return grpc.Dial(endpoint, dialOpts...)
+	}
+}
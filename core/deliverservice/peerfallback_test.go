@@ -0,0 +1,135 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package deliverclient
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric/core/deliverservice/blocksprovider"
+	gossipcommon "github.com/hyperledger/fabric/gossip/common"
+	"github.com/hyperledger/fabric/gossip/discovery"
+	"github.com/hyperledger/fabric/protos/orderer"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+)
+
+// channelMembers is a gossip service adapter which sees the given members in the channels
+type channelMembers struct {
+	blocksprovider.GossipServiceAdapter
+	members []discovery.NetworkMember
+}
+
+func (cm *channelMembers) PeersOfChannel(gossipcommon.ChainID) []discovery.NetworkMember {
+	return cm.members
+}
+
+func TestPeerFallback(t *testing.T) {
+	// Scenario: the ordering service is unreachable, so the blocks are received from a peer
+	// until connecting to the ordering service is tried again and succeeds
+	orderers := &connProducer{shouldFail: true}
+	peers := &connProducer{}
+	ordererClient, peerClient := &abclient{}, &abclient{}
+	var connectedTo []orderer.AtomicBroadcastClient
+	clientOf := func(client *abclient) clientFactory {
+		return func(*grpc.ClientConn) orderer.AtomicBroadcastClient {
+			connectedTo = append(connectedTo, client)
+			return client
+		}
+	}
+	setupInvoked := 0
+	setup := func(blocksprovider.BlocksDeliverer) error {
+		setupInvoked++
+		return nil
+	}
+	backoffStrategy := func(attemptNum int, elapsedTime time.Duration) (time.Duration, bool) {
+		return time.Duration(0), attemptNum < 2
+	}
+	bc := NewBroadcastClient(orderers, clientOf(ordererClient), setup, backoffStrategy)
+	bc.fallback = &deliverFallback{prod: peers, createClient: clientOf(peerClient), retryInterval: time.Hour}
+	defer bc.Close()
+
+	_, err := bc.Recv()
+	assert.NoError(t, err)
+	assert.Equal(t, []orderer.AtomicBroadcastClient{peerClient}, connectedTo)
+	assert.Equal(t, 1, orderers.connAttempts)
+	assert.Equal(t, 1, peers.connAttempts)
+	assert.Equal(t, 1, setupInvoked)
+	assert.False(t, bc.fallbackSince.IsZero())
+
+	// The peer keeps delivering the blocks within the retry interval
+	_, err = bc.Recv()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, orderers.connAttempts)
+
+	// The ordering service is tried again past the retry interval
+	orderers.shouldFail = false
+	bc.fallback.retryInterval = time.Millisecond
+	time.Sleep(10 * time.Millisecond)
+	_, err = bc.Recv()
+	assert.NoError(t, err)
+	assert.Equal(t, []orderer.AtomicBroadcastClient{peerClient, ordererClient}, connectedTo)
+	assert.Equal(t, 2, orderers.connAttempts)
+	assert.Equal(t, 2, setupInvoked)
+	assert.True(t, bc.fallbackSince.IsZero())
+}
+
+func TestPeerFallbackUnavailable(t *testing.T) {
+	// Scenario: neither the ordering service nor the peers are reachable
+	orderers := &connProducer{shouldFail: true}
+	peers := &connProducer{shouldFail: true}
+	clFactory := func(*grpc.ClientConn) orderer.AtomicBroadcastClient {
+		return &abclient{}
+	}
+	setup := func(blocksprovider.BlocksDeliverer) error {
+		return nil
+	}
+	backoffStrategy := func(attemptNum int, elapsedTime time.Duration) (time.Duration, bool) {
+		return time.Duration(0), attemptNum < 2
+	}
+	bc := NewBroadcastClient(orderers, clFactory, setup, backoffStrategy)
+	bc.fallback = &deliverFallback{prod: peers, createClient: clFactory, retryInterval: time.Hour}
+	defer bc.Close()
+
+	_, err := bc.Recv()
+	assert.Error(t, err)
+	assert.Equal(t, 2, orderers.connAttempts)
+	assert.Equal(t, 2, peers.connAttempts)
+}
+
+func TestOrgPeersConnProducer(t *testing.T) {
+	gossip := &channelMembers{}
+	var dialed []string
+	prod := newOrgPeersConnProducer("testchainid", gossip, func(endpoint string) (*grpc.ClientConn, error) {
+		dialed = append(dialed, endpoint)
+		return nil, errors.New("unreachable")
+	})
+
+	// No peer of the organization is in the channel
+	gossip.members = []discovery.NetworkMember{{Endpoint: "p0.org2:7051"}}
+	_, _, err := prod.NewConnection()
+	assert.EqualError(t, err, "no peers of the organization in the channel to deliver blocks from")
+	assert.Empty(t, dialed)
+
+	// Only the peers of the organization, whose internal endpoints are known, are dialed
+	gossip.members = append(gossip.members,
+		discovery.NetworkMember{Endpoint: "p1.org1:7051", InternalEndpoint: "p1:7051"},
+		discovery.NetworkMember{InternalEndpoint: "p2:7051"})
+	_, _, err = prod.NewConnection()
+	assert.Error(t, err)
+	assert.Len(t, dialed, 2)
+	assert.Contains(t, dialed, "p1:7051")
+	assert.Contains(t, dialed, "p2:7051")
+
+	// The disabled peers aren't dialed
+	dialed = nil
+	prod.DisableEndpoint("p1:7051")
+	_, _, err = prod.NewConnection()
+	assert.Error(t, err)
+	assert.Equal(t, []string{"p2:7051"}, dialed)
+}
@@ -8,6 +8,7 @@ package endorser
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric/common/flogging"
@@ -15,8 +16,8 @@ import (
 
 	"errors"
 
-	"github.com/hyperledger/fabric/common/policies"
 	"github.com/hyperledger/fabric/common/util"
+	"github.com/hyperledger/fabric/core/aclmgmt"
 	"github.com/hyperledger/fabric/core/chaincode"
 	"github.com/hyperledger/fabric/core/chaincode/shim"
 	"github.com/hyperledger/fabric/core/common/ccprovider"
@@ -25,13 +26,14 @@ import (
 	"github.com/hyperledger/fabric/core/handlers/library"
 	"github.com/hyperledger/fabric/core/ledger"
 	"github.com/hyperledger/fabric/core/peer"
-	"github.com/hyperledger/fabric/core/policy"
 	syscc "github.com/hyperledger/fabric/core/scc"
+	"github.com/hyperledger/fabric/gossip/service"
 	"github.com/hyperledger/fabric/msp"
-	"github.com/hyperledger/fabric/msp/mgmt"
 	"github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/ledger/rwset"
 	pb "github.com/hyperledger/fabric/protos/peer"
 	putils "github.com/hyperledger/fabric/protos/utils"
+	"github.com/spf13/viper"
 )
 
 // >>>>> begin errors section >>>>>
@@ -47,32 +49,88 @@ func (ce chaincodeError) Error() string {
 
 // <<<<< end errors section <<<<<<
 
-var endorserLogger = flogging.MustGetLogger("endorser")
+// StatusReadOnlyPeer is the status of the response to a proposal refused because the
+// peer is read-only, the client should send it to an endorsing peer
+const StatusReadOnlyPeer = 405
+
+// StatusIdentityExpired is the status of the response to a proposal refused because the
+// certificate of its creator expired, the client should renew its certificate
+const StatusIdentityExpired = 401
+
+// readOnlyChaincodes are the system chaincodes whose proposals on a channel a read-only
+// peer serves. They query the ledger and the config of the channel, while the proposals
+// of the application chaincodes and lscc may produce transactions
+var readOnlyChaincodes = map[string]bool{"qscc": true, "cscc": true}
+
+// endorserLogger attaches the channel and the transaction id of the proposals to the
+// records it logs while processing them
+var endorserLogger = flogging.MustGetContextLogger("endorser")
 
 // The Jira issue that documents Endorser flow along with its relationship to
 // the lifecycle chaincode - https://jira.hyperledger.org/browse/FAB-181
 
 // Endorser provides the Endorser service ProcessProposal
 type Endorser struct {
-	policyChecker policy.PolicyChecker
+	aclProvider aclmgmt.ACLProvider
+	pool        *workerPool
+	heightGate  *heightGate
+
+	// readOnly is set for the peers that replicate and serve the ledgers of their
+	// channels without endorsing, the proposals that may produce a transaction are refused
+	readOnly bool
+
+	// expirationCheck is set for the peers that refuse the proposals of the clients whose
+	// certificate expired, it is off unless peer.endorser.expirationCheck is set.
+	// now returns the time the certificates are checked against
+	expirationCheck bool
+	now             func() time.Time
+
+	// distributePrivateData pushes the private write set of an endorsed transaction
+	// to the members of its collections, nil if the private data isn't pushed
+	distributePrivateData func(chainID string, txID string, pvtData *rwset.TxPvtReadWriteSet, blkHt uint64) error
 }
 
-// NewEndorserServer creates and returns a new Endorser server instance.
+// NewEndorserServer creates and returns a new Endorser server instance,
+// whose worker pool and ledger height gating are configured by the
+// peer.endorser section of the config, and which pushes the private data
+// of the transactions it endorses with gossip
 func NewEndorserServer() pb.EndorserServer {
+	e := newEndorser(WorkerPoolConfig{
+		Workers:              viper.GetInt("peer.endorser.workers"),
+		ChaincodeConcurrency: viper.GetInt("peer.endorser.chaincodeConcurrency"),
+		Timeout:              viper.GetDuration("peer.endorser.timeout"),
+	})
+	if maxLag := viper.GetInt("peer.endorser.maxLedgerHeightLag"); maxLag > 0 {
+		e.heightGate = newHeightGate(uint64(maxLag))
+	}
+	e.readOnly = viper.GetBool("peer.readOnly")
+	e.expirationCheck = viper.GetBool("peer.endorser.expirationCheck")
+	e.distributePrivateData = func(chainID string, txID string, pvtData *rwset.TxPvtReadWriteSet, blkHt uint64) error {
+		return service.GetGossipService().DistributePrivateData(chainID, txID, pvtData, blkHt)
+	}
+
+	return e
+}
+
+// NewEndorserServerWithConfig creates and returns a new Endorser server instance
+// processing the proposals with a worker pool of the given config
+func NewEndorserServerWithConfig(config WorkerPoolConfig) pb.EndorserServer {
+	return newEndorser(config)
+}
+
+func newEndorser(config WorkerPoolConfig) *Endorser {
 	e := new(Endorser)
-	e.policyChecker = policy.NewPolicyChecker(
-		peer.NewChannelPolicyManagerGetter(),
-		mgmt.GetLocalMSP(),
-		mgmt.NewLocalMSPPrincipalGetter(),
-	)
+	e.aclProvider = aclmgmt.GetACLProvider()
+	e.pool = newWorkerPool(config)
+	e.now = time.Now
 
 	return e
 }
 
-// checkACL checks that the supplied proposal complies
-// with the writers policy of the chain
+// checkACL checks that the supplied proposal complies with the ACL of
+// proposals on the chain, the writers policy of the chain by default
 func (e *Endorser) checkACL(signedProp *pb.SignedProposal, chdr *common.ChannelHeader, shdr *common.SignatureHeader, hdrext *pb.ChaincodeHeaderExtension) error {
-	return e.policyChecker.CheckPolicy(chdr.ChannelId, policies.ChannelApplicationWriters, signedProp)
+	return e.aclProvider.CheckACL(aclmgmt.PROPOSE, chdr.ChannelId, signedProp)
 }
 
 //TODO - check for escc and vscc
@@ -98,8 +156,9 @@ func (*Endorser) getHistoryQueryExecutor(ledgername string) (ledger.HistoryQuery
 
 //call specified chaincode (system or user)
 func (e *Endorser) callChaincode(ctxt context.Context, chainID string, version string, txid string, signedProp *pb.SignedProposal, prop *pb.Proposal, cis *pb.ChaincodeInvocationSpec, cid *pb.ChaincodeID, txsim ledger.TxSimulator) (*pb.Response, *pb.ChaincodeEvent, error) {
-	endorserLogger.Debugf("Entry - txid: %s channel id: %s version: %s", txid, chainID, version)
-	defer endorserLogger.Debugf("Exit")
+	logger := endorserLogger.WithChannel(chainID).WithTxID(txid)
+	logger.Debugf("Entry - version: %s", version)
+	defer logger.Debugf("Exit")
 	var err error
 	var res *pb.Response
 	var ccevent *pb.ChaincodeEvent
@@ -213,8 +272,9 @@ func (e *Endorser) disableJavaCCInst(cid *pb.ChaincodeID, cis *pb.ChaincodeInvoc
 
 //simulate the proposal by calling the chaincode
 func (e *Endorser) simulateProposal(ctx context.Context, chainID string, txid string, signedProp *pb.SignedProposal, prop *pb.Proposal, cid *pb.ChaincodeID, txsim ledger.TxSimulator) (*ccprovider.ChaincodeData, *pb.Response, []byte, *pb.ChaincodeEvent, error) {
-	endorserLogger.Debugf("Entry - txid: %s channel id: %s", txid, chainID)
-	defer endorserLogger.Debugf("Exit")
+	logger := endorserLogger.WithChannel(chainID).WithTxID(txid)
+	logger.Debugf("Entry")
+	defer logger.Debugf("Exit")
 	//we do expect the payload to be a ChaincodeInvocationSpec
 	//if we are supporting other payloads in future, this be glaringly point
 	//as something that should change
@@ -247,6 +307,13 @@ func (e *Endorser) simulateProposal(ctx context.Context, chainID string, txid st
 		if err != nil {
 			return nil, nil, nil, nil, err
 		}
+
+		if txsim != nil && readYourWrites(chainID) {
+			if rywSim, ok := txsim.(ledger.ReadYourWritesEnabler); ok {
+				logger.Debugf("Enabling read-your-writes simulation for chaincode %s", cid.Name)
+				rywSim.EnableReadYourWrites()
+			}
+		}
 	} else {
 		version = util.GetSysCCVersion()
 	}
@@ -258,7 +325,7 @@ func (e *Endorser) simulateProposal(ctx context.Context, chainID string, txid st
 	var ccevent *pb.ChaincodeEvent
 	res, ccevent, err = e.callChaincode(ctx, chainID, version, txid, signedProp, prop, cis, cid, txsim)
 	if err != nil {
-		endorserLogger.Errorf("failed to invoke chaincode %s on transaction %s, error: %s", cid, txid, err)
+		logger.Errorf("failed to invoke chaincode %s, error: %s", cid, err)
 		return nil, nil, nil, nil, err
 	}
 
@@ -267,6 +334,13 @@ func (e *Endorser) simulateProposal(ctx context.Context, chainID string, txid st
 			return nil, nil, nil, nil, err
 		}
 
+		if simResult.PvtSimulationResults != nil && e.distributePrivateData != nil {
+			if err = e.distributePrivateData(chainID, txid, simResult.PvtSimulationResults, simResult.SimulationBlkHt); err != nil {
+				logger.Errorf("failed to distribute the private data, error: %s", err)
+				return nil, nil, nil, nil, err
+			}
+		}
+
 		if pubSimResBytes, err = simResult.GetPubSimulationBytes(); err != nil {
 			return nil, nil, nil, nil, err
 		}
@@ -285,8 +359,9 @@ func (e *Endorser) getCDSFromLSCC(ctx context.Context, chainID string, txid stri
 
 //endorse the proposal by calling the ESCC
 func (e *Endorser) endorseProposal(ctx context.Context, chainID string, txid string, signedProp *pb.SignedProposal, proposal *pb.Proposal, response *pb.Response, simRes []byte, event *pb.ChaincodeEvent, visibility []byte, ccid *pb.ChaincodeID, txsim ledger.TxSimulator, cd *ccprovider.ChaincodeData) (*pb.ProposalResponse, error) {
-	endorserLogger.Debugf("Entry - txid: %s channel id: %s chaincode id: %s", txid, chainID, ccid)
-	defer endorserLogger.Debugf("Exit")
+	logger := endorserLogger.WithChannel(chainID).WithTxID(txid)
+	logger.Debugf("Entry - chaincode id: %s", ccid)
+	defer logger.Debugf("Exit")
 
 	isSysCC := cd == nil
 	// 1) extract the name of the escc that is requested to endorse this chaincode
@@ -303,7 +378,7 @@ func (e *Endorser) endorseProposal(ctx context.Context, chainID string, txid str
 		}
 	}
 
-	endorserLogger.Debugf("info: escc for chaincode id %s is %s", ccid, escc)
+	logger.Debugf("info: escc for chaincode id %s is %s", ccid, escc)
 
 	// marshalling event bytes
 	var err error
@@ -375,8 +450,16 @@ func (e *Endorser) endorseProposal(ctx context.Context, chainID string, txid str
 	return pResp, nil
 }
 
-// ProcessProposal process the Proposal
+// ProcessProposal process the Proposal once a worker of the pool is available
 func (e *Endorser) ProcessProposal(ctx context.Context, signedProp *pb.SignedProposal) (*pb.ProposalResponse, error) {
+	if e.pool == nil {
+		return e.processProposal(ctx, signedProp)
+	}
+	return e.pool.process(ctx, signedProp, e.processProposal)
+}
+
+// processProposal process the Proposal
+func (e *Endorser) processProposal(ctx context.Context, signedProp *pb.SignedProposal) (*pb.ProposalResponse, error) {
 	endorserLogger.Debugf("Entry")
 	defer endorserLogger.Debugf("Exit")
 	// at first, we check whether the message is valid
@@ -395,6 +478,15 @@ func (e *Endorser) ProcessProposal(ctx context.Context, signedProp *pb.SignedPro
 		return &pb.ProposalResponse{Response: &pb.Response{Status: 500, Message: err.Error()}}, err
 	}
 
+	// the MSPs validate the creator regardless of the expiration of its certificate; the
+	// refusal is a response rather than an error so that the client gets the status
+	if e.expirationCheck {
+		if err = msp.CheckExpiration(shdr.Creator, e.now()); err != nil {
+			endorserLogger.Warningf("Refusing proposal of creator with an expired certificate: %s", err)
+			return &pb.ProposalResponse{Response: &pb.Response{Status: StatusIdentityExpired, Message: err.Error()}}, nil
+		}
+	}
+
 	// block invocations to security-sensitive system chaincodes
 	if syscc.IsSysCCAndNotInvokableExternal(hdrExt.ChaincodeId.Name) {
 		endorserLogger.Errorf("Error: an attempt was made by %#v to invoke system chaincode %s",
@@ -413,7 +505,16 @@ func (e *Endorser) ProcessProposal(ctx context.Context, signedProp *pb.SignedPro
 		err = errors.New("Invalid txID. It must be different from the empty string.")
 		return &pb.ProposalResponse{Response: &pb.Response{Status: 500, Message: err.Error()}}, err
 	}
-	endorserLogger.Debugf("processing txid: %s", txid)
+	logger := endorserLogger.WithChannel(chainID).WithTxID(txid)
+	logger.Debugf("processing proposal for chaincode %s", hdrExt.ChaincodeId.Name)
+
+	// a read-only peer only serves the queries of the system chaincodes; the refusal is
+	// a response rather than an error so that the client turns to an endorsing peer
+	if e.readOnly && chainID != "" && !readOnlyChaincodes[hdrExt.ChaincodeId.Name] {
+		err = fmt.Errorf("peer is read-only, it does not endorse proposals for chaincode %s", hdrExt.ChaincodeId.Name)
+		logger.Debugf("Refusing proposal: %s", err)
+		return &pb.ProposalResponse{Response: &pb.Response{Status: StatusReadOnlyPeer, Message: err.Error()}}, nil
+	}
 	if chainID != "" {
 		// here we handle uniqueness check and ACLs for proposals targeting a chain
 		lgr := peer.GetLedger(chainID)
@@ -431,6 +532,16 @@ func (e *Endorser) ProcessProposal(ctx context.Context, signedProp *pb.SignedPro
 			if err = e.checkACL(signedProp, chdr, shdr, hdrExt); err != nil {
 				return &pb.ProposalResponse{Response: &pb.Response{Status: 500, Message: err.Error()}}, err
 			}
+
+			// refuse to simulate application chaincodes on a ledger catching up
+			// with the channel; the refusal is a response rather than an error
+			// so that the client gets the status and retries with another peer
+			if e.heightGate != nil {
+				if err = e.heightGate.check(chainID); err != nil {
+					logger.Warningf("Refusing proposal: %s", err)
+					return &pb.ProposalResponse{Response: &pb.Response{Status: StatusLedgerLagging, Message: err.Error()}}, nil
+				}
+			}
 		}
 	} else {
 		// chainless proposals do not/cannot affect ledger and cannot be submitted as transactions
@@ -472,7 +583,7 @@ func (e *Endorser) ProcessProposal(ctx context.Context, signedProp *pb.SignedPro
 	}
 	if res != nil {
 		if res.Status >= shim.ERROR {
-			endorserLogger.Errorf("simulateProposal() resulted in chaincode response status %d for txid: %s", res.Status, txid)
+			logger.Errorf("simulateProposal() resulted in chaincode response status %d", res.Status)
 			var cceventBytes []byte
 			if ccevent != nil {
 				cceventBytes, err = putils.GetBytesChaincodeEvent(ccevent)
@@ -503,7 +614,7 @@ func (e *Endorser) ProcessProposal(ctx context.Context, signedProp *pb.SignedPro
 		}
 		if pResp != nil {
 			if res.Status >= shim.ERRORTHRESHOLD {
-				endorserLogger.Debugf("endorseProposal() resulted in chaincode error for txid: %s", txid)
+				logger.Debugf("endorseProposal() resulted in chaincode error")
 				return pResp, &chaincodeError{res.Status, res.Message}
 			}
 		}
@@ -544,3 +655,11 @@ func (e *Endorser) commitTxSimulation(proposal *pb.Proposal, chainID string, sig
 
 	return nil
 }
+
+// readYourWrites returns whether the application capabilities of the channel make the reads of
+// the chaincodes within a simulation observe their earlier writes. All the peers of the channel
+// agree on the capabilities, so that the endorsements of the peers do not differ
+func readYourWrites(chainID string) bool {
+	ac, ok := peer.GetApplicationCapabilities(chainID)
+	return ok && ac.ReadYourWrites()
+}
@@ -701,6 +701,63 @@ func TestInvokeSccFail(t *testing.T) {
 	}
 }
 
+// TestReadOnlyPeer makes sure that a read-only peer refuses the proposals of
+// application chaincodes, while serving the queries of qscc
+func TestReadOnlyPeer(t *testing.T) {
+	chainID := util.GetTestChainID()
+	e := newEndorser(WorkerPoolConfig{})
+	e.readOnly = true
+	creator, _ := signer.Serialize()
+
+	process := func(spec *pb.ChaincodeSpec) *pb.ProposalResponse {
+		prop, _, err := getInvokeProposal(&pb.ChaincodeInvocationSpec{ChaincodeSpec: spec}, chainID, creator)
+		assert.NoError(t, err)
+		signedProp, err := getSignedProposal(prop, signer)
+		assert.NoError(t, err)
+		resp, _ := e.ProcessProposal(context.Background(), signedProp)
+		return resp
+	}
+
+	resp := process(&pb.ChaincodeSpec{Type: 1, ChaincodeId: &pb.ChaincodeID{Name: "ex01", Version: "0"},
+		Input: &pb.ChaincodeInput{Args: util.ToChaincodeArgs("invoke", "a", "b", "10")}})
+	assert.Equal(t, int32(StatusReadOnlyPeer), resp.Response.Status)
+
+	resp = process(&pb.ChaincodeSpec{Type: 1, ChaincodeId: &pb.ChaincodeID{Name: "lscc"},
+		Input: &pb.ChaincodeInput{Args: util.ToChaincodeArgs("getchaincodes")}})
+	assert.Equal(t, int32(StatusReadOnlyPeer), resp.Response.Status)
+
+	resp = process(&pb.ChaincodeSpec{Type: 1, ChaincodeId: &pb.ChaincodeID{Name: "qscc"},
+		Input: &pb.ChaincodeInput{Args: util.ToChaincodeArgs("GetChainInfo", chainID)}})
+	assert.NotEqual(t, int32(StatusReadOnlyPeer), resp.Response.Status)
+}
+
+// TestExpiredIdentity makes sure that the proposals of a creator whose certificate
+// expired are refused with their own status when the expiration check is enabled
+func TestExpiredIdentity(t *testing.T) {
+	chainID := util.GetTestChainID()
+	e := newEndorser(WorkerPoolConfig{})
+	e.expirationCheck = true
+	creator, _ := signer.Serialize()
+	expiresAt := msp.ExpiresAt(creator)
+	assert.False(t, expiresAt.IsZero())
+
+	spec := &pb.ChaincodeSpec{Type: 1, ChaincodeId: &pb.ChaincodeID{Name: "qscc"},
+		Input: &pb.ChaincodeInput{Args: util.ToChaincodeArgs("GetChainInfo", chainID)}}
+	prop, _, err := getInvokeProposal(&pb.ChaincodeInvocationSpec{ChaincodeSpec: spec}, chainID, creator)
+	assert.NoError(t, err)
+	signedProp, err := getSignedProposal(prop, signer)
+	assert.NoError(t, err)
+
+	e.now = func() time.Time { return expiresAt.Add(time.Second) }
+	resp, err := e.ProcessProposal(context.Background(), signedProp)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(StatusIdentityExpired), resp.Response.Status)
+
+	e.now = func() time.Time { return expiresAt.Add(-time.Second) }
+	resp, _ = e.ProcessProposal(context.Background(), signedProp)
+	assert.NotEqual(t, int32(StatusIdentityExpired), resp.GetResponse().GetStatus())
+}
+
 func newTempDir() string {
 	tempDir, err := ioutil.TempDir("", "fabric-")
 	if err != nil {
@@ -0,0 +1,95 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package endorser
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric/core/peer"
+	gcommon "github.com/hyperledger/fabric/gossip/common"
+	"github.com/hyperledger/fabric/gossip/discovery"
+	"github.com/hyperledger/fabric/gossip/service"
+	"github.com/hyperledger/fabric/gossip/state"
+)
+
+// StatusLedgerLagging is the status of the response to a proposal refused because the
+// ledger of the peer lags behind the channel, the client should retry with another peer
+const StatusLedgerLagging = 503
+
+// heightGate refuses the proposals of a channel while the ledger of the peer lags the
+// height of the channel known from the other peers by more than maxLag blocks, so that
+// the clients do not get stale reads from a peer catching up with the channel
+type heightGate struct {
+	maxLag uint64
+
+	// ledgerHeight returns the height of the ledger of the peer for a channel
+	ledgerHeight func(chainID string) (uint64, error)
+
+	// channelHeight returns the highest height of the channel advertised by
+	// the other peers of the channel, 0 if unknown
+	channelHeight func(chainID string) uint64
+}
+
+func newHeightGate(maxLag uint64) *heightGate {
+	return &heightGate{
+		maxLag:        maxLag,
+		ledgerHeight:  peerLedgerHeight,
+		channelHeight: gossipChannelHeight,
+	}
+}
+
+// check returns an error if the ledger of the peer lags behind the channel
+func (g *heightGate) check(chainID string) error {
+	channelHeight := g.channelHeight(chainID)
+	if channelHeight == 0 {
+		return nil
+	}
+
+	ledgerHeight, err := g.ledgerHeight(chainID)
+	if err != nil {
+		return err
+	}
+
+	if channelHeight > ledgerHeight && channelHeight-ledgerHeight > g.maxLag {
+		return fmt.Errorf("ledger of channel %s is at height %d, lagging the channel height %d by more than %d blocks", chainID, ledgerHeight, channelHeight, g.maxLag)
+	}
+	return nil
+}
+
+func peerLedgerHeight(chainID string) (uint64, error) {
+	lgr := peer.GetLedger(chainID)
+	if lgr == nil {
+		return 0, fmt.Errorf("failure while looking up the ledger %s", chainID)
+	}
+	info, err := lgr.GetBlockchainInfo()
+	if err != nil {
+		return 0, err
+	}
+	return info.Height, nil
+}
+
+// gossipChannelHeight returns the highest height of the channel known from gossip, which
+// is initialized before the peer serves any proposal
+func gossipChannelHeight(chainID string) uint64 {
+	return maxAdvertisedHeight(service.GetGossipService().PeersOfChannel(gcommon.ChainID(chainID)))
+}
+
+// maxAdvertisedHeight returns the highest ledger height advertised in the gossip metadata
+// of the peers. The peers advertise the number of their last committed block.
+func maxAdvertisedHeight(members []discovery.NetworkMember) uint64 {
+	var max uint64
+	for _, member := range members {
+		nodeMetastate, err := state.FromBytes(member.Metadata)
+		if err != nil {
+			continue
+		}
+		if height := nodeMetastate.LedgerHeight + 1; height > max {
+			max = height
+		}
+	}
+	return max
+}
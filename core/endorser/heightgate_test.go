@@ -0,0 +1,63 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package endorser
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/fabric/gossip/discovery"
+	"github.com/hyperledger/fabric/gossip/state"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestHeightGate(maxLag, ledgerHeight, channelHeight uint64, ledgerErr error) *heightGate {
+	return &heightGate{
+		maxLag: maxLag,
+		ledgerHeight: func(chainID string) (uint64, error) {
+			return ledgerHeight, ledgerErr
+		},
+		channelHeight: func(chainID string) uint64 {
+			return channelHeight
+		},
+	}
+}
+
+func TestHeightGateCheck(t *testing.T) {
+	// the ledger is up to date
+	assert.NoError(t, newTestHeightGate(5, 100, 100, nil).check("testchainid"))
+	// the ledger lags within the threshold
+	assert.NoError(t, newTestHeightGate(5, 100, 105, nil).check("testchainid"))
+	// the ledger is ahead of the other peers
+	assert.NoError(t, newTestHeightGate(5, 100, 50, nil).check("testchainid"))
+	// the channel height is unknown
+	assert.NoError(t, newTestHeightGate(5, 100, 0, nil).check("testchainid"))
+
+	err := newTestHeightGate(5, 100, 106, nil).check("testchainid")
+	assert.EqualError(t, err, "ledger of channel testchainid is at height 100, lagging the channel height 106 by more than 5 blocks")
+
+	err = newTestHeightGate(5, 0, 106, fmt.Errorf("no ledger")).check("testchainid")
+	assert.EqualError(t, err, "no ledger")
+}
+
+func TestMaxAdvertisedHeight(t *testing.T) {
+	metadata := func(lastBlock uint64) []byte {
+		b, err := state.NewNodeMetastate(lastBlock).Bytes()
+		assert.NoError(t, err)
+		return b
+	}
+
+	assert.Equal(t, uint64(0), maxAdvertisedHeight(nil))
+
+	members := []discovery.NetworkMember{
+		{Endpoint: "p1", Metadata: metadata(9)},
+		{Endpoint: "p2", Metadata: []byte("garbage")},
+		{Endpoint: "p3", Metadata: metadata(41)},
+		{Endpoint: "p4"},
+	}
+	assert.Equal(t, uint64(42), maxAdvertisedHeight(members))
+}
@@ -0,0 +1,247 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package endorser
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	putils "github.com/hyperledger/fabric/protos/utils"
+	"golang.org/x/net/context"
+)
+
+// proposalProcessor processes a signed proposal into a proposal response
+type proposalProcessor func(ctx context.Context, signedProp *pb.SignedProposal) (*pb.ProposalResponse, error)
+
+// WorkerPoolConfig configures the pool of workers the endorser processes the proposals with
+type WorkerPoolConfig struct {
+	// Workers is the maximum number of proposals processed concurrently,
+	// 0 means no limit
+	Workers int
+
+	// ChaincodeConcurrency is the maximum number of proposals processed
+	// concurrently for a given chaincode of a channel, 0 means no limit
+	ChaincodeConcurrency int
+
+	// Timeout is the maximum time a proposal waits for a worker and is processed,
+	// after which a failure proposal response is returned, 0 means no limit
+	Timeout time.Duration
+}
+
+// workerPool bounds the number of proposals processed concurrently, overall and per
+// chaincode, coalesces the identical proposals submitted while one is in progress, and
+// bounds the time it takes to respond to a proposal
+type workerPool struct {
+	config  WorkerPoolConfig
+	workers chan struct{}
+
+	mutex      sync.Mutex
+	chaincodes map[string]chan struct{}
+	inProgress map[string]*pendingProposal
+}
+
+// pendingProposal is a proposal in progress, whose response is shared by the identical
+// proposals submitted until it completes
+type pendingProposal struct {
+	signedProp *pb.SignedProposal
+	done       chan struct{}
+	resp       *pb.ProposalResponse
+	err        error
+}
+
+func newWorkerPool(config WorkerPoolConfig) *workerPool {
+	wp := &workerPool{
+		config:     config,
+		chaincodes: make(map[string]chan struct{}),
+		inProgress: make(map[string]*pendingProposal),
+	}
+	if config.Workers > 0 {
+		wp.workers = make(chan struct{}, config.Workers)
+	}
+	return wp
+}
+
+// process processes the signed proposal with the processor once a worker is available.
+// If the same proposal is already in progress, its response is returned instead of
+// processing it again.
+func (wp *workerPool) process(ctx context.Context, signedProp *pb.SignedProposal, processor proposalProcessor) (*pb.ProposalResponse, error) {
+	channelID, txID, ccName := proposalKeys(signedProp)
+
+	// the proposals which cannot be identified are not coalesced, they
+	// are rejected by the processor anyway
+	if txID == "" {
+		return wp.run(ctx, channelID, ccName, signedProp, processor)
+	}
+
+	key := channelID + "/" + txID
+	wp.mutex.Lock()
+	pending, ok := wp.inProgress[key]
+	if ok && sameProposal(pending.signedProp, signedProp) {
+		wp.mutex.Unlock()
+		endorserLogger.WithChannel(channelID).WithTxID(txID).Debugf("Proposal is already in progress, waiting for its response")
+		return wp.wait(ctx, pending)
+	}
+	if ok {
+		// a different proposal reusing the transaction ID is processed on its own,
+		// the processor rejects it if the transaction is already on the ledger
+		wp.mutex.Unlock()
+		return wp.run(ctx, channelID, ccName, signedProp, processor)
+	}
+	pending = &pendingProposal{signedProp: signedProp, done: make(chan struct{})}
+	wp.inProgress[key] = pending
+	wp.mutex.Unlock()
+
+	pending.resp, pending.err = wp.run(ctx, channelID, ccName, signedProp, processor)
+
+	wp.mutex.Lock()
+	delete(wp.inProgress, key)
+	wp.mutex.Unlock()
+	close(pending.done)
+
+	return pending.resp, pending.err
+}
+
+// run processes the proposal once a worker and a slot for its chaincode are available,
+// or returns a failure response if the timeout expires or the client goes away first
+func (wp *workerPool) run(ctx context.Context, channelID, ccName string, signedProp *pb.SignedProposal, processor proposalProcessor) (*pb.ProposalResponse, error) {
+	var timeout <-chan time.Time
+	if wp.config.Timeout > 0 {
+		timer := time.NewTimer(wp.config.Timeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	if wp.workers != nil {
+		select {
+		case wp.workers <- struct{}{}:
+		case <-timeout:
+			return timeoutResponse("while waiting for an endorser worker", wp.config.Timeout), nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	ccSlots := wp.chaincodeSlots(channelID, ccName)
+	if ccSlots != nil {
+		select {
+		case ccSlots <- struct{}{}:
+		case <-timeout:
+			wp.release(nil)
+			return timeoutResponse(fmt.Sprintf("while waiting for the proposals to chaincode %s to complete", ccName), wp.config.Timeout), nil
+		case <-ctx.Done():
+			wp.release(nil)
+			return nil, ctx.Err()
+		}
+	}
+
+	type result struct {
+		resp *pb.ProposalResponse
+		err  error
+	}
+	results := make(chan result, 1)
+	go func() {
+		// the worker is only released once the processing completes, even
+		// when the response has already been returned because of the timeout
+		defer wp.release(ccSlots)
+		resp, err := processor(ctx, signedProp)
+		results <- result{resp, err}
+	}()
+
+	select {
+	case r := <-results:
+		return r.resp, r.err
+	case <-timeout:
+		return timeoutResponse("while being processed", wp.config.Timeout), nil
+	}
+}
+
+// wait waits for the response to a pending proposal
+func (wp *workerPool) wait(ctx context.Context, pending *pendingProposal) (*pb.ProposalResponse, error) {
+	var timeout <-chan time.Time
+	if wp.config.Timeout > 0 {
+		timer := time.NewTimer(wp.config.Timeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	select {
+	case <-pending.done:
+		return pending.resp, pending.err
+	case <-timeout:
+		return timeoutResponse("while waiting for the identical proposal in progress", wp.config.Timeout), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// chaincodeSlots returns the slots bounding the proposals processed concurrently for
+// a chaincode of a channel, or nil if there is no limit
+func (wp *workerPool) chaincodeSlots(channelID, ccName string) chan struct{} {
+	if wp.config.ChaincodeConcurrency <= 0 {
+		return nil
+	}
+
+	key := channelID + "/" + ccName
+	wp.mutex.Lock()
+	defer wp.mutex.Unlock()
+	slots, ok := wp.chaincodes[key]
+	if !ok {
+		slots = make(chan struct{}, wp.config.ChaincodeConcurrency)
+		wp.chaincodes[key] = slots
+	}
+	return slots
+}
+
+func (wp *workerPool) release(ccSlots chan struct{}) {
+	if ccSlots != nil {
+		<-ccSlots
+	}
+	if wp.workers != nil {
+		<-wp.workers
+	}
+}
+
+// proposalKeys returns the channel, transaction ID and chaincode name of a signed proposal,
+// or empty strings if they cannot be extracted. The proposal is validated by the processor.
+func proposalKeys(signedProp *pb.SignedProposal) (channelID, txID, ccName string) {
+	prop, err := putils.GetProposal(signedProp.GetProposalBytes())
+	if err != nil {
+		return
+	}
+	hdr, err := putils.GetHeader(prop.Header)
+	if err != nil {
+		return
+	}
+	chdr, err := putils.UnmarshalChannelHeader(hdr.ChannelHeader)
+	if err != nil {
+		return
+	}
+	hdrExt, err := putils.GetChaincodeHeaderExtension(hdr)
+	if err != nil {
+		return chdr.ChannelId, chdr.TxId, ""
+	}
+	return chdr.ChannelId, chdr.TxId, hdrExt.GetChaincodeId().GetName()
+}
+
+func sameProposal(p1, p2 *pb.SignedProposal) bool {
+	return bytes.Equal(p1.ProposalBytes, p2.ProposalBytes) && bytes.Equal(p1.Signature, p2.Signature)
+}
+
+// timeoutResponse is the failure response returned to the client when a proposal times out.
+// It is returned without an error, so that the client receives it rather than a gRPC error.
+func timeoutResponse(stage string, timeout time.Duration) *pb.ProposalResponse {
+	return &pb.ProposalResponse{
+		Response: &pb.Response{
+			Status:  shim.ERROR,
+			Message: fmt.Sprintf("proposal timed out after %s %s", timeout, stage),
+		},
+	}
+}
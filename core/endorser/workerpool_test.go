@@ -0,0 +1,175 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package endorser
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	putils "github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+)
+
+func mockSignedProposal(chainID, ccName string) *pb.SignedProposal {
+	sProp, _ := putils.MockSignedEndorserProposalOrPanic(chainID, &pb.ChaincodeSpec{ChaincodeId: &pb.ChaincodeID{Name: ccName}}, []byte("Alice"), []byte("msg1"))
+	return sProp
+}
+
+// blockingProcessor processes the proposals once released, recording how many are processed
+// concurrently at most
+type blockingProcessor struct {
+	release   chan struct{}
+	calls     int32
+	running   int32
+	maxActive int32
+}
+
+func newBlockingProcessor() *blockingProcessor {
+	return &blockingProcessor{release: make(chan struct{})}
+}
+
+func (bp *blockingProcessor) process(ctx context.Context, signedProp *pb.SignedProposal) (*pb.ProposalResponse, error) {
+	atomic.AddInt32(&bp.calls, 1)
+	running := atomic.AddInt32(&bp.running, 1)
+	for {
+		maxActive := atomic.LoadInt32(&bp.maxActive)
+		if running <= maxActive || atomic.CompareAndSwapInt32(&bp.maxActive, maxActive, running) {
+			break
+		}
+	}
+	<-bp.release
+	atomic.AddInt32(&bp.running, -1)
+	return &pb.ProposalResponse{Response: &pb.Response{Status: shim.OK}}, nil
+}
+
+func processAll(wp *workerPool, proposals []*pb.SignedProposal, processor proposalProcessor) []*pb.ProposalResponse {
+	responses := make([]*pb.ProposalResponse, len(proposals))
+	var wg sync.WaitGroup
+	for i, sProp := range proposals {
+		wg.Add(1)
+		go func(i int, sProp *pb.SignedProposal) {
+			defer wg.Done()
+			responses[i], _ = wp.process(context.Background(), sProp, processor)
+		}(i, sProp)
+	}
+	wg.Wait()
+	return responses
+}
+
+func TestWorkerPoolWorkers(t *testing.T) {
+	wp := newWorkerPool(WorkerPoolConfig{Workers: 2})
+	bp := newBlockingProcessor()
+
+	var proposals []*pb.SignedProposal
+	for i := 0; i < 6; i++ {
+		proposals = append(proposals, mockSignedProposal("testchainid", "mycc"))
+	}
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		close(bp.release)
+	}()
+	for _, resp := range processAll(wp, proposals, bp.process) {
+		assert.Equal(t, int32(shim.OK), resp.Response.Status)
+	}
+	assert.Equal(t, int32(6), atomic.LoadInt32(&bp.calls))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&bp.maxActive))
+}
+
+func TestWorkerPoolChaincodeConcurrency(t *testing.T) {
+	wp := newWorkerPool(WorkerPoolConfig{ChaincodeConcurrency: 1})
+	bp := newBlockingProcessor()
+
+	// the proposals to a chaincode are processed one at a time, but
+	// do not hold up the proposals to another chaincode
+	proposals := []*pb.SignedProposal{
+		mockSignedProposal("testchainid", "cc1"),
+		mockSignedProposal("testchainid", "cc1"),
+		mockSignedProposal("testchainid", "cc2"),
+		mockSignedProposal("testchainid", "cc2"),
+	}
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		close(bp.release)
+	}()
+	processAll(wp, proposals, bp.process)
+	assert.Equal(t, int32(4), atomic.LoadInt32(&bp.calls))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&bp.maxActive))
+}
+
+func TestWorkerPoolDeduplication(t *testing.T) {
+	wp := newWorkerPool(WorkerPoolConfig{})
+	bp := newBlockingProcessor()
+
+	sProp := mockSignedProposal("testchainid", "mycc")
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		close(bp.release)
+	}()
+	responses := processAll(wp, []*pb.SignedProposal{sProp, sProp, sProp}, bp.process)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&bp.calls))
+	assert.True(t, responses[0] == responses[1] && responses[1] == responses[2])
+
+	// once completed, the proposal is processed again
+	wp.process(context.Background(), sProp, bp.process)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&bp.calls))
+	assert.Empty(t, wp.inProgress)
+}
+
+func TestWorkerPoolTimeout(t *testing.T) {
+	wp := newWorkerPool(WorkerPoolConfig{Workers: 1, Timeout: 50 * time.Millisecond})
+	bp := newBlockingProcessor()
+	defer close(bp.release)
+
+	responses := processAll(wp, []*pb.SignedProposal{
+		mockSignedProposal("testchainid", "mycc"),
+		mockSignedProposal("testchainid", "mycc"),
+	}, bp.process)
+
+	// one proposal times out while processed, the other one while waiting for the worker
+	var messages []string
+	for _, resp := range responses {
+		assert.Equal(t, int32(shim.ERROR), resp.Response.Status)
+		messages = append(messages, resp.Response.Message)
+	}
+	joined := strings.Join(messages, "\n")
+	assert.Contains(t, joined, "proposal timed out after 50ms while being processed")
+	assert.Contains(t, joined, "proposal timed out after 50ms while waiting for an endorser worker")
+	assert.Equal(t, int32(1), atomic.LoadInt32(&bp.calls))
+}
+
+func TestWorkerPoolCanceledContext(t *testing.T) {
+	wp := newWorkerPool(WorkerPoolConfig{Workers: 1})
+	bp := newBlockingProcessor()
+
+	go wp.process(context.Background(), mockSignedProposal("testchainid", "mycc"), bp.process)
+	for atomic.LoadInt32(&bp.calls) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := wp.process(ctx, mockSignedProposal("testchainid", "mycc"), bp.process)
+	assert.Equal(t, context.Canceled, err)
+	close(bp.release)
+}
+
+func TestWorkerPoolMalformedProposal(t *testing.T) {
+	wp := newWorkerPool(WorkerPoolConfig{Workers: 1, ChaincodeConcurrency: 1})
+	bp := newBlockingProcessor()
+	close(bp.release)
+
+	resp, err := wp.process(context.Background(), &pb.SignedProposal{ProposalBytes: []byte("garbage")}, bp.process)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(shim.OK), resp.Response.Status)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&bp.calls))
+}
@@ -0,0 +1,200 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package core
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric/msp"
+	"github.com/hyperledger/fabric/msp/mgmt"
+	"github.com/hyperledger/fabric/protos/common"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	"github.com/hyperledger/fabric/protos/utils"
+	"golang.org/x/net/context"
+)
+
+// IntrospectionSupport provides the state of the peer exposed by the Introspection service
+type IntrospectionSupport interface {
+	// GetChannels returns the channels joined by the peer and the heights of their ledgers
+	GetChannels() ([]*pb.ChannelLedgerInfo, error)
+
+	// GetGossipMembership returns the peers known alive by the gossip of the peer
+	GetGossipMembership() (*pb.GossipMembership, error)
+
+	// GetChaincodeContainers returns the status of the chaincode containers of the peer
+	GetChaincodeContainers() []*pb.ChaincodeContainerStatus
+}
+
+// IntrospectionServer implements the Introspection service of the peer. Its requests
+// must be signed by an admin of the local MSP
+type IntrospectionServer struct {
+	support IntrospectionSupport
+	access  *localMSPAccess
+}
+
+// NewIntrospectionServer creates and returns an Introspection service instance. The requests
+// must be timestamped within timeWindow of the time of the peer
+func NewIntrospectionServer(support IntrospectionSupport, localMSP msp.IdentityDeserializer, principalGetter mgmt.MSPPrincipalGetter, timeWindow time.Duration) *IntrospectionServer {
+	return &IntrospectionServer{support: support, access: newLocalMSPAccess(localMSP, principalGetter, timeWindow)}
+}
+
+// defaultTimeWindow is the time window of the requests when none is configured
+const defaultTimeWindow = 15 * time.Minute
+
+// localMSPAccess restricts the requests of the peer services to the identities of the local MSP.
+// The requests are MESSAGE envelopes timestamped within the time window of the peer, whose nonces
+// are remembered for the time window so that they can't be replayed
+type localMSPAccess struct {
+	localMSP        msp.IdentityDeserializer
+	principalGetter mgmt.MSPPrincipalGetter
+	timeWindow      time.Duration
+	now             func() time.Time
+
+	lock sync.Mutex
+	// nonces maps the nonces of the accepted requests to the time they expire at
+	nonces map[string]time.Time
+}
+
+func newLocalMSPAccess(localMSP msp.IdentityDeserializer, principalGetter mgmt.MSPPrincipalGetter, timeWindow time.Duration) *localMSPAccess {
+	if timeWindow <= 0 {
+		timeWindow = defaultTimeWindow
+	}
+	return &localMSPAccess{
+		localMSP:        localMSP,
+		principalGetter: principalGetter,
+		timeWindow:      timeWindow,
+		now:             time.Now,
+		nonces:          make(map[string]time.Time),
+	}
+}
+
+// roleDescriptions names the roles of the local MSP in the errors
+var roleDescriptions = map[string]string{
+	mgmt.Admins:  "an admin",
+	mgmt.Members: "a member",
+}
+
+// check returns the payload of the envelope, or an error unless the envelope is signed by
+// an identity of the local MSP having the role (mgmt.Admins or mgmt.Members)
+func (a *localMSPAccess) check(env *common.Envelope, role string) (*common.Payload, error) {
+	if env == nil {
+		return nil, errors.New("nil envelope")
+	}
+	payload, err := utils.UnmarshalPayload(env.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed unmarshalling the payload: %s", err)
+	}
+	if payload.Header == nil {
+		return nil, errors.New("missing header in the payload")
+	}
+	chdr, err := utils.UnmarshalChannelHeader(payload.Header.ChannelHeader)
+	if err != nil {
+		return nil, fmt.Errorf("failed unmarshalling the channel header: %s", err)
+	}
+	if common.HeaderType(chdr.Type) != common.HeaderType_MESSAGE {
+		return nil, fmt.Errorf("invalid header type %s", common.HeaderType(chdr.Type))
+	}
+	if chdr.Timestamp == nil {
+		return nil, errors.New("missing timestamp in the channel header")
+	}
+	now := a.now()
+	timestamp := time.Unix(chdr.Timestamp.Seconds, int64(chdr.Timestamp.Nanos))
+	if timestamp.Before(now.Add(-a.timeWindow)) || timestamp.After(now.Add(a.timeWindow)) {
+		return nil, fmt.Errorf("timestamp %s is more than %s apart from the time of the peer %s", timestamp.UTC(), a.timeWindow, now.UTC())
+	}
+	shdr, err := utils.GetSignatureHeader(payload.Header.SignatureHeader)
+	if err != nil {
+		return nil, fmt.Errorf("failed unmarshalling the signature header: %s", err)
+	}
+	if len(shdr.Nonce) == 0 {
+		return nil, errors.New("missing nonce in the signature header")
+	}
+
+	// Deserialize the creator with the local MSP
+	id, err := a.localMSP.DeserializeIdentity(shdr.Creator)
+	if err != nil {
+		return nil, fmt.Errorf("failed deserializing the creator: %s", err)
+	}
+
+	// Verify that the creator has the role in the local MSP
+	principal, err := a.principalGetter.Get(role)
+	if err != nil {
+		return nil, fmt.Errorf("failed getting the principal of the local MSP %s: %s", role, err)
+	}
+	if err = id.SatisfiesPrincipal(principal); err != nil {
+		return nil, fmt.Errorf("the creator is not %s of the local MSP: %s", roleDescriptions[role], err)
+	}
+
+	// Verify the signature
+	if err = id.Verify(env.Payload, env.Signature); err != nil {
+		return nil, fmt.Errorf("invalid signature: %s", err)
+	}
+
+	// Only the signed requests are remembered, lest forged ones evict or shadow the nonces
+	if err = a.useNonce(shdr.Nonce, timestamp, now); err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
+// useNonce records the nonce of a request timestamped at timestamp, or returns an error if a
+// request with the same nonce was already accepted. The nonces are forgotten once the requests
+// they belong to are out of the time window
+func (a *localMSPAccess) useNonce(nonce []byte, timestamp, now time.Time) error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	for n, expiry := range a.nonces {
+		if !expiry.After(now) {
+			delete(a.nonces, n)
+		}
+	}
+	if _, exists := a.nonces[string(nonce)]; exists {
+		return errors.New("replayed request: its nonce was already used")
+	}
+	a.nonces[string(nonce)] = timestamp.Add(a.timeWindow)
+	return nil
+}
+
+// checkAdmin returns an error unless the envelope is signed by an admin of the local MSP
+func (s *IntrospectionServer) checkAdmin(env *common.Envelope) error {
+	_, err := s.access.check(env, mgmt.Admins)
+	return err
+}
+
+// GetChannels returns the channels joined by the peer and the heights of their ledgers
+func (s *IntrospectionServer) GetChannels(ctx context.Context, env *common.Envelope) (*pb.ChannelsIntrospection, error) {
+	if err := s.checkAdmin(env); err != nil {
+		log.Warningf("Introspection request for the channels denied: %s", err)
+		return nil, fmt.Errorf("access denied: %s", err)
+	}
+	channels, err := s.support.GetChannels()
+	if err != nil {
+		return nil, err
+	}
+	return &pb.ChannelsIntrospection{Channels: channels}, nil
+}
+
+// GetGossipMembership returns the peers known alive by the gossip of the peer
+func (s *IntrospectionServer) GetGossipMembership(ctx context.Context, env *common.Envelope) (*pb.GossipMembership, error) {
+	if err := s.checkAdmin(env); err != nil {
+		log.Warningf("Introspection request for the gossip membership denied: %s", err)
+		return nil, fmt.Errorf("access denied: %s", err)
+	}
+	return s.support.GetGossipMembership()
+}
+
+// GetChaincodeContainers returns the status of the chaincode containers of the peer
+func (s *IntrospectionServer) GetChaincodeContainers(ctx context.Context, env *common.Envelope) (*pb.ChaincodeContainers, error) {
+	if err := s.checkAdmin(env); err != nil {
+		log.Warningf("Introspection request for the chaincode containers denied: %s", err)
+		return nil, fmt.Errorf("access denied: %s", err)
+	}
+	return &pb.ChaincodeContainers{Containers: s.support.GetChaincodeContainers()}, nil
+}
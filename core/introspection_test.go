@@ -0,0 +1,184 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package core
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric/common/crypto"
+	"github.com/hyperledger/fabric/core/policy/mocks"
+	"github.com/hyperledger/fabric/protos/common"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+)
+
+type mockIntrospectionSupport struct {
+	channels   []*pb.ChannelLedgerInfo
+	membership *pb.GossipMembership
+	containers []*pb.ChaincodeContainerStatus
+	err        error
+}
+
+func (m *mockIntrospectionSupport) GetChannels() ([]*pb.ChannelLedgerInfo, error) {
+	return m.channels, m.err
+}
+
+func (m *mockIntrospectionSupport) GetGossipMembership() (*pb.GossipMembership, error) {
+	return m.membership, m.err
+}
+
+func (m *mockIntrospectionSupport) GetChaincodeContainers() []*pb.ChaincodeContainerStatus {
+	return m.containers
+}
+
+// signedEnvelope returns a MESSAGE envelope created now by creator, with a fresh nonce,
+// whose signature is its payload as the mock identities expect it
+func signedEnvelope(t *testing.T, creator []byte) *common.Envelope {
+	nonce, err := crypto.GetRandomNonce()
+	assert.NoError(t, err)
+	return signedEnvelopeOf(creator, utils.MakeChannelHeader(common.HeaderType_MESSAGE, 0, "", 0), nonce)
+}
+
+func signedEnvelopeOf(creator []byte, chdr *common.ChannelHeader, nonce []byte) *common.Envelope {
+	payload := &common.Payload{
+		Header: &common.Header{
+			ChannelHeader:   utils.MarshalOrPanic(chdr),
+			SignatureHeader: utils.MarshalOrPanic(&common.SignatureHeader{Creator: creator, Nonce: nonce}),
+		},
+	}
+	payloadBytes := utils.MarshalOrPanic(payload)
+	return &common.Envelope{Payload: payloadBytes, Signature: payloadBytes}
+}
+
+func TestIntrospectionServer(t *testing.T) {
+	support := &mockIntrospectionSupport{
+		channels:   []*pb.ChannelLedgerInfo{{ChannelId: "mychannel", Height: 5}},
+		membership: &pb.GossipMembership{Peers: []*pb.GossipMember{{Endpoint: "peer1:7051"}}},
+		containers: []*pb.ChaincodeContainerStatus{{CanonicalName: "mycc:0", State: pb.ChaincodeContainerStatus_RUNNING, Container: true}},
+	}
+	deserializer := &mocks.MockIdentityDeserializer{Identity: []byte("Admin")}
+	server := NewIntrospectionServer(support, deserializer, &mocks.MockMSPPrincipalGetter{Principal: []byte("Admin")}, time.Minute)
+
+	request := func() *common.Envelope {
+		env := signedEnvelope(t, []byte("Admin"))
+		deserializer.Msg = env.Payload
+		return env
+	}
+
+	channels, err := server.GetChannels(context.Background(), request())
+	assert.NoError(t, err)
+	assert.Equal(t, support.channels, channels.Channels)
+
+	membership, err := server.GetGossipMembership(context.Background(), request())
+	assert.NoError(t, err)
+	assert.Equal(t, support.membership, membership)
+
+	containers, err := server.GetChaincodeContainers(context.Background(), request())
+	assert.NoError(t, err)
+	assert.Equal(t, support.containers, containers.Containers)
+
+	// the errors of the support are returned
+	support.err = errors.New("ledger failure")
+	_, err = server.GetChannels(context.Background(), request())
+	assert.EqualError(t, err, "ledger failure")
+	_, err = server.GetGossipMembership(context.Background(), request())
+	assert.EqualError(t, err, "ledger failure")
+}
+
+func TestIntrospectionServerAccessDenied(t *testing.T) {
+	deserializer := &mocks.MockIdentityDeserializer{Identity: []byte("Admin")}
+	server := NewIntrospectionServer(&mockIntrospectionSupport{}, deserializer, &mocks.MockMSPPrincipalGetter{Principal: []byte("Admin")}, time.Minute)
+
+	// nil envelope
+	_, err := server.GetChannels(context.Background(), nil)
+	assert.EqualError(t, err, "access denied: nil envelope")
+
+	// invalid payload
+	_, err = server.GetChannels(context.Background(), &common.Envelope{Payload: []byte("garbage")})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "access denied: failed unmarshalling the payload")
+
+	// missing header
+	_, err = server.GetChannels(context.Background(), &common.Envelope{Payload: utils.MarshalOrPanic(&common.Payload{})})
+	assert.EqualError(t, err, "access denied: missing header in the payload")
+
+	// creator unknown to the local MSP
+	env := signedEnvelope(t, []byte("Stranger"))
+	_, err = server.GetGossipMembership(context.Background(), env)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "access denied: failed deserializing the creator")
+
+	// creator not an admin of the local MSP
+	deserializer.Identity = []byte("Member")
+	env = signedEnvelope(t, []byte("Member"))
+	_, err = server.GetChaincodeContainers(context.Background(), env)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "access denied: the creator is not an admin of the local MSP")
+
+	// invalid signature
+	deserializer.Identity = []byte("Admin")
+	env = signedEnvelope(t, []byte("Admin"))
+	deserializer.Msg = env.Payload
+	env.Signature = []byte("forged")
+	_, err = server.GetChannels(context.Background(), env)
+	assert.EqualError(t, err, "access denied: invalid signature: Invalid Signature")
+}
+
+func TestIntrospectionServerRequestFreshness(t *testing.T) {
+	deserializer := &mocks.MockIdentityDeserializer{Identity: []byte("Admin")}
+	server := NewIntrospectionServer(&mockIntrospectionSupport{}, deserializer, &mocks.MockMSPPrincipalGetter{Principal: []byte("Admin")}, time.Minute)
+	now := time.Now()
+	server.access.now = func() time.Time { return now }
+
+	request := func(headerType common.HeaderType, timestamp time.Time, nonce string) error {
+		chdr := utils.MakeChannelHeader(headerType, 0, "", 0)
+		chdr.Timestamp.Seconds = timestamp.Unix()
+		env := signedEnvelopeOf([]byte("Admin"), chdr, []byte(nonce))
+		deserializer.Msg = env.Payload
+		_, err := server.GetChannels(context.Background(), env)
+		return err
+	}
+
+	// other header types are rejected
+	err := request(common.HeaderType_ENDORSER_TRANSACTION, now, "nonce1")
+	assert.EqualError(t, err, "access denied: invalid header type ENDORSER_TRANSACTION")
+
+	// the timestamp must be within the time window
+	err = request(common.HeaderType_MESSAGE, now.Add(-2*time.Minute), "nonce1")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "is more than 1m0s apart from the time of the peer")
+	err = request(common.HeaderType_MESSAGE, now.Add(2*time.Minute), "nonce1")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "is more than 1m0s apart from the time of the peer")
+
+	// a missing nonce is rejected
+	err = request(common.HeaderType_MESSAGE, now, "")
+	assert.EqualError(t, err, "access denied: missing nonce in the signature header")
+
+	// a request can't be replayed
+	assert.NoError(t, request(common.HeaderType_MESSAGE, now, "nonce1"))
+	err = request(common.HeaderType_MESSAGE, now, "nonce1")
+	assert.EqualError(t, err, "access denied: replayed request: its nonce was already used")
+
+	// a rejected request doesn't use its nonce
+	deserializer.Msg = []byte("something else")
+	chdr := utils.MakeChannelHeader(common.HeaderType_MESSAGE, 0, "", 0)
+	chdr.Timestamp.Seconds = now.Unix()
+	_, err = server.GetChannels(context.Background(), signedEnvelopeOf([]byte("Admin"), chdr, []byte("nonce2")))
+	assert.Error(t, err)
+	assert.NoError(t, request(common.HeaderType_MESSAGE, now, "nonce2"))
+
+	// the nonces are forgotten once their requests are out of the time window
+	now = now.Add(2 * time.Minute)
+	assert.Len(t, server.access.nonces, 2)
+	assert.NoError(t, request(common.HeaderType_MESSAGE, now, "nonce1"))
+	assert.Len(t, server.access.nonces, 1)
+}
@@ -0,0 +1,227 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package confighistory
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/common/ledger/util/leveldbhelper"
+	"github.com/hyperledger/fabric/core/common/privdata"
+	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/rwsetutil"
+	"github.com/hyperledger/fabric/core/ledger/ledgerconfig"
+	"github.com/hyperledger/fabric/core/ledger/util"
+	"github.com/hyperledger/fabric/protos/common"
+	lc "github.com/hyperledger/fabric/protos/peer/lifecycle"
+	putils "github.com/hyperledger/fabric/protos/utils"
+)
+
+var logger = flogging.MustGetLogger("confighistory")
+
+// lsccNamespace is the namespace the collection configs of the chaincodes are committed to
+const lsccNamespace = "lscc"
+
+var savePointKey = []byte{0x00}
+var configKeyPrefix = []byte{0x01}
+
+// Provider provides the config history databases of the ledgers
+type Provider struct {
+	dbProvider *leveldbhelper.Provider
+}
+
+// NewProvider instantiates a Provider
+func NewProvider() *Provider {
+	return NewProviderWithConf(&leveldbhelper.Conf{DBPath: ledgerconfig.GetConfigHistoryPath()})
+}
+
+// NewProviderWithConf instantiates a Provider on the leveldb with the given configuration
+func NewProviderWithConf(conf *leveldbhelper.Conf) *Provider {
+	logger.Debugf("constructing config history Provider dbPath=%s", conf.DBPath)
+	return &Provider{leveldbhelper.NewProvider(conf)}
+}
+
+// GetDBHandle gets the handle to the config history database of the given ledger
+func (p *Provider) GetDBHandle(ledgerID string) *DB {
+	return &DB{p.dbProvider.GetDBHandle(ledgerID), ledgerID}
+}
+
+// Drop deletes all the keys of the config history database of the given ledger
+func (p *Provider) Drop(ledgerID string) error {
+	return p.dbProvider.GetDBHandle(ledgerID).DeleteAll(true)
+}
+
+// Close closes the underlying db
+func (p *Provider) Close() {
+	p.dbProvider.Close()
+}
+
+// DB keeps every version of the collection configs of the chaincodes of a ledger, under the number of the
+// block that committed it, be it through lscc or _lifecycle. It implements ledger.ConfigHistoryRetriever
+type DB struct {
+	db       *leveldbhelper.DBHandle
+	ledgerID string
+}
+
+// Commit records the collection configs committed by the valid transactions of the block
+func (d *DB) Commit(block *common.Block) error {
+	blockNum := block.Header.Number
+	txsFilter := util.TxValidationFlags(block.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER])
+	if len(txsFilter) == 0 {
+		txsFilter = util.NewTxValidationFlags(len(block.Data.Data))
+	}
+
+	batch := leveldbhelper.NewUpdateBatch()
+	for txNum, envBytes := range block.Data.Data {
+		if txsFilter.IsInvalid(txNum) {
+			continue
+		}
+		configs, err := collectionConfigWrites(envBytes)
+		if err != nil {
+			return err
+		}
+		// a later transaction of the block overrides the configs written by an earlier one
+		for ccName, configBytes := range configs {
+			logger.Debugf("Channel [%s]: Recording collection configs of chaincode [%s] committed by block [%d]", d.ledgerID, ccName, blockNum)
+			batch.Put(encodeConfigKey(ccName, blockNum), configBytes)
+		}
+	}
+	batch.Put(savePointKey, encodeBlockNum(blockNum))
+	return d.db.WriteBatch(batch, false)
+}
+
+// CollectionConfigAt implements method in interface ledger.ConfigHistoryRetriever
+func (d *DB) CollectionConfigAt(blockNum uint64, chaincodeName string) (*ledger.CollectionConfigInfo, error) {
+	// the end key sorts right after the key of the given block and before the key of the next one
+	itr := d.db.GetIterator(encodeConfigKey(chaincodeName, 0), append(encodeConfigKey(chaincodeName, blockNum), 0x00))
+	defer itr.Release()
+	var key, value []byte
+	for itr.Next() {
+		key = append(key[:0], itr.Key()...)
+		value = append(value[:0], itr.Value()...)
+	}
+	if err := itr.Error(); err != nil {
+		return nil, err
+	}
+	if key == nil {
+		return nil, nil
+	}
+
+	collections := &common.CollectionConfigPackage{}
+	if err := proto.Unmarshal(value, collections); err != nil {
+		return nil, fmt.Errorf("invalid collection configs of chaincode %s: %s", chaincodeName, err)
+	}
+	return &ledger.CollectionConfigInfo{
+		CollectionConfig:   collections,
+		CommittingBlockNum: binary.BigEndian.Uint64(key[len(key)-8:]),
+	}, nil
+}
+
+// ShouldRecover implements method in interface kvledger.Recoverer
+func (d *DB) ShouldRecover(lastAvailableBlock uint64) (bool, uint64, error) {
+	savepoint, err := d.db.Get(savePointKey)
+	if err != nil {
+		return false, 0, err
+	}
+	if savepoint == nil {
+		return true, 0, nil
+	}
+	blockNum := binary.BigEndian.Uint64(savepoint)
+	return blockNum != lastAvailableBlock, blockNum + 1, nil
+}
+
+// CommitLostBlock implements method in interface kvledger.Recoverer
+func (d *DB) CommitLostBlock(blockAndPvtdata *ledger.BlockAndPvtData) error {
+	return d.Commit(blockAndPvtdata.Block)
+}
+
+// collectionConfigWrites returns the collection configs written to the namespace of lscc, or
+// along with the chaincode definitions written to the one of _lifecycle, by the given
+// transaction, by the name of their chaincode
+func collectionConfigWrites(envBytes []byte) (map[string][]byte, error) {
+	env, err := putils.GetEnvelopeFromBlock(envBytes)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := putils.GetPayload(env)
+	if err != nil {
+		return nil, err
+	}
+	chdr, err := putils.UnmarshalChannelHeader(payload.Header.ChannelHeader)
+	if err != nil {
+		return nil, err
+	}
+	if common.HeaderType(chdr.Type) != common.HeaderType_ENDORSER_TRANSACTION {
+		return nil, nil
+	}
+
+	respPayload, err := putils.GetActionFromEnvelope(envBytes)
+	if err != nil {
+		return nil, err
+	}
+	txRWSet := &rwsetutil.TxRwSet{}
+	if err = txRWSet.FromProtoBytes(respPayload.Results); err != nil {
+		return nil, err
+	}
+	configs := make(map[string][]byte)
+	for _, nsRWSet := range txRWSet.NsRwSets {
+		switch nsRWSet.NameSpace {
+		case lsccNamespace:
+			for _, kvWrite := range nsRWSet.KvRwSet.Writes {
+				if kvWrite.IsDelete || !privdata.IsCollectionConfigKey(kvWrite.Key) {
+					continue
+				}
+				configs[strings.TrimSuffix(kvWrite.Key, privdata.BuildCollectionKVSKey(""))] = kvWrite.Value
+			}
+		case privdata.LifecycleNamespace:
+			for _, kvWrite := range nsRWSet.KvRwSet.Writes {
+				ccName, ok := privdata.ParseLifecycleDefinitionKey(kvWrite.Key)
+				if kvWrite.IsDelete || !ok {
+					continue
+				}
+				configBytes, err := definitionCollections(kvWrite.Value)
+				if err != nil {
+					return nil, fmt.Errorf("invalid definition of chaincode %s: %s", ccName, err)
+				}
+				configs[ccName] = configBytes
+			}
+		}
+	}
+	return configs, nil
+}
+
+// definitionCollections returns the collection configs of the given chaincode definition committed
+// through _lifecycle. They replace the ones committed through lscc, so the configs of a definition
+// without collections are an empty package
+func definitionCollections(defBytes []byte) ([]byte, error) {
+	def := &lc.ChaincodeDefinition{}
+	if err := proto.Unmarshal(defBytes, def); err != nil {
+		return nil, err
+	}
+	if def.Collections == nil {
+		return proto.Marshal(&common.CollectionConfigPackage{})
+	}
+	return proto.Marshal(def.Collections)
+}
+
+// encodeConfigKey returns the key of the collection configs of the chaincode committed by
+// the given block; the keys of a chaincode are sorted by the numbers of their blocks
+func encodeConfigKey(chaincodeName string, blockNum uint64) []byte {
+	key := append([]byte{}, configKeyPrefix...)
+	key = append(key, []byte(chaincodeName)...)
+	key = append(key, 0x00)
+	return append(key, encodeBlockNum(blockNum)...)
+}
+
+func encodeBlockNum(blockNum uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, blockNum)
+	return b
+}
@@ -0,0 +1,195 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package confighistory
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/ledger/testutil"
+	"github.com/hyperledger/fabric/common/ledger/util/leveldbhelper"
+	"github.com/hyperledger/fabric/core/common/privdata"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/rwsetutil"
+	lutil "github.com/hyperledger/fabric/core/ledger/util"
+	"github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/peer"
+	lc "github.com/hyperledger/fabric/protos/peer/lifecycle"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestProvider(t *testing.T) (*Provider, func()) {
+	dbPath, err := ioutil.TempDir("", "confighistory")
+	require.NoError(t, err)
+	p := NewProviderWithConf(&leveldbhelper.Conf{DBPath: dbPath})
+	return p, func() {
+		p.Close()
+		os.RemoveAll(dbPath)
+	}
+}
+
+func collectionConfigs(names ...string) *common.CollectionConfigPackage {
+	collections := &common.CollectionConfigPackage{}
+	for _, name := range names {
+		collections.Config = append(collections.Config, &common.CollectionConfig{
+			Payload: &common.CollectionConfig_StaticCollectionConfig{
+				StaticCollectionConfig: &common.StaticCollectionConfig{Name: name},
+			},
+		})
+	}
+	return collections
+}
+
+// simulationResults returns the results of a transaction writing the given
+// collection configs to lscc, by the name of their chaincode
+func simulationResults(t *testing.T, configs map[string]*common.CollectionConfigPackage) []byte {
+	rwsetBuilder := rwsetutil.NewRWSetBuilder()
+	for ccName, collections := range configs {
+		rwsetBuilder.AddToWriteSet(lsccNamespace, ccName, []byte("chaincode data"))
+		rwsetBuilder.AddToWriteSet(lsccNamespace, privdata.BuildCollectionKVSKey(ccName), utils.MarshalOrPanic(collections))
+	}
+	rwsetBuilder.AddToWriteSet("mycc", "key", []byte("value"))
+	sr, err := rwsetBuilder.GetTxSimulationResults()
+	require.NoError(t, err)
+	res, err := sr.GetPubSimulationBytes()
+	require.NoError(t, err)
+	return res
+}
+
+// definitionResults returns the results of a transaction committing the definition
+// of the given chaincode, with the given collection configs, through _lifecycle
+func definitionResults(t *testing.T, ccName string, collections *common.CollectionConfigPackage) []byte {
+	rwsetBuilder := rwsetutil.NewRWSetBuilder()
+	rwsetBuilder.AddToWriteSet(privdata.LifecycleNamespace, privdata.BuildLifecycleDefinitionKey(ccName),
+		utils.MarshalOrPanic(&lc.ChaincodeDefinition{Name: ccName, Version: "1.0", Sequence: 1, Collections: collections}))
+	rwsetBuilder.AddToWriteSet(privdata.LifecycleNamespace, "approvals/"+ccName+"/1/Org1MSP", []byte("approval"))
+	sr, err := rwsetBuilder.GetTxSimulationResults()
+	require.NoError(t, err)
+	res, err := sr.GetPubSimulationBytes()
+	require.NoError(t, err)
+	return res
+}
+
+func assertCollectionConfigAt(t *testing.T, db *DB, blockNum uint64, ccName string, expected *common.CollectionConfigPackage, committingBlockNum uint64) {
+	info, err := db.CollectionConfigAt(blockNum, ccName)
+	require.NoError(t, err)
+	if expected == nil {
+		assert.Nil(t, info, "block %d, chaincode %s", blockNum, ccName)
+		return
+	}
+	require.NotNil(t, info, "block %d, chaincode %s", blockNum, ccName)
+	assert.True(t, proto.Equal(expected, info.CollectionConfig), "block %d, chaincode %s", blockNum, ccName)
+	assert.Equal(t, committingBlockNum, info.CommittingBlockNum)
+}
+
+func TestCollectionConfigAt(t *testing.T) {
+	p, cleanup := newTestProvider(t)
+	defer cleanup()
+	db := p.GetDBHandle("ledger1")
+
+	v1 := collectionConfigs("coll1")
+	v2 := collectionConfigs("coll1", "coll2")
+	v3 := collectionConfigs("coll1", "coll2", "coll3")
+	otherV1 := collectionConfigs("othercoll")
+
+	blocks := []*common.Block{
+		testutil.ConstructBlock(t, 0, nil, nil, false),
+		testutil.ConstructBlock(t, 1, nil, [][]byte{simulationResults(t, map[string]*common.CollectionConfigPackage{"mycc": v1})}, false),
+		testutil.ConstructBlock(t, 2, nil, [][]byte{simulationResults(t, nil)}, false),
+		testutil.ConstructBlock(t, 3, nil, [][]byte{
+			simulationResults(t, map[string]*common.CollectionConfigPackage{"mycc": v2, "othercc": otherV1}),
+			simulationResults(t, map[string]*common.CollectionConfigPackage{"mycc": v3}),
+		}, false),
+		testutil.ConstructBlock(t, 4, nil, [][]byte{simulationResults(t, map[string]*common.CollectionConfigPackage{"mycc": v1})}, false),
+	}
+	// the upgrade of block 4 is invalid
+	blocks[4].Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER] = lutil.TxValidationFlags{uint8(peer.TxValidationCode_MVCC_READ_CONFLICT)}
+	for _, block := range blocks {
+		require.NoError(t, db.Commit(block))
+	}
+
+	assertCollectionConfigAt(t, db, 0, "mycc", nil, 0)
+	assertCollectionConfigAt(t, db, 1, "mycc", v1, 1)
+	assertCollectionConfigAt(t, db, 2, "mycc", v1, 1)
+	// the last transaction of a block wins
+	assertCollectionConfigAt(t, db, 3, "mycc", v3, 3)
+	assertCollectionConfigAt(t, db, 4, "mycc", v3, 3)
+	assertCollectionConfigAt(t, db, 2, "othercc", nil, 0)
+	assertCollectionConfigAt(t, db, 1000, "othercc", otherV1, 3)
+	assertCollectionConfigAt(t, db, 1000, "unknowncc", nil, 0)
+	// the names of the chaincodes don't overlap
+	assertCollectionConfigAt(t, db, 1000, "my", nil, 0)
+
+	// the configs of a ledger are not visible from another one
+	assertCollectionConfigAt(t, p.GetDBHandle("ledger2"), 1000, "mycc", nil, 0)
+
+	// and are dropped along with its config history
+	require.NoError(t, p.Drop("ledger1"))
+	assertCollectionConfigAt(t, db, 1000, "mycc", nil, 0)
+}
+
+func TestLifecycleCollectionConfigAt(t *testing.T) {
+	p, cleanup := newTestProvider(t)
+	defer cleanup()
+	db := p.GetDBHandle("ledger1")
+
+	lsccV1 := collectionConfigs("coll1")
+	v2 := collectionConfigs("coll1", "coll2")
+	blocks := []*common.Block{
+		testutil.ConstructBlock(t, 0, nil, nil, false),
+		testutil.ConstructBlock(t, 1, nil, [][]byte{simulationResults(t, map[string]*common.CollectionConfigPackage{"mycc": lsccV1, "othercc": lsccV1})}, false),
+		testutil.ConstructBlock(t, 2, nil, [][]byte{definitionResults(t, "mycc", v2)}, false),
+		testutil.ConstructBlock(t, 3, nil, [][]byte{definitionResults(t, "othercc", nil)}, false),
+	}
+	for _, block := range blocks {
+		require.NoError(t, db.Commit(block))
+	}
+
+	// the collections of a definition committed through _lifecycle replace the ones of lscc
+	assertCollectionConfigAt(t, db, 1, "mycc", lsccV1, 1)
+	assertCollectionConfigAt(t, db, 2, "mycc", v2, 2)
+	assertCollectionConfigAt(t, db, 2, "othercc", lsccV1, 1)
+	// even if the definition has none
+	assertCollectionConfigAt(t, db, 3, "othercc", &common.CollectionConfigPackage{}, 3)
+
+	// a definition which doesn't unmarshal is rejected
+	rwsetBuilder := rwsetutil.NewRWSetBuilder()
+	rwsetBuilder.AddToWriteSet(privdata.LifecycleNamespace, privdata.BuildLifecycleDefinitionKey("badcc"), []byte("barf"))
+	sr, err := rwsetBuilder.GetTxSimulationResults()
+	require.NoError(t, err)
+	res, err := sr.GetPubSimulationBytes()
+	require.NoError(t, err)
+	err = db.Commit(testutil.ConstructBlock(t, 4, nil, [][]byte{res}, false))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid definition of chaincode badcc")
+}
+
+func TestRecovery(t *testing.T) {
+	p, cleanup := newTestProvider(t)
+	defer cleanup()
+	db := p.GetDBHandle("ledger1")
+
+	shouldRecover, firstBlockNum, err := db.ShouldRecover(5)
+	require.NoError(t, err)
+	assert.True(t, shouldRecover)
+	assert.Equal(t, uint64(0), firstBlockNum)
+
+	for blockNum := uint64(0); blockNum < 3; blockNum++ {
+		require.NoError(t, db.Commit(testutil.ConstructBlock(t, blockNum, nil, nil, false)))
+	}
+	shouldRecover, firstBlockNum, err = db.ShouldRecover(5)
+	require.NoError(t, err)
+	assert.True(t, shouldRecover)
+	assert.Equal(t, uint64(3), firstBlockNum)
+
+	shouldRecover, _, err = db.ShouldRecover(2)
+	require.NoError(t, err)
+	assert.False(t, shouldRecover)
+}
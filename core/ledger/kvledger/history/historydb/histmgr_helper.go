@@ -23,6 +23,7 @@ import (
 )
 
 var compositeKeySep = []byte{0x00}
+var lastKeyIndicator = byte(0x01)
 
 //ConstructCompositeHistoryKey builds the History Key of namespace~key~blocknum~trannum
 // using an order preserving encoding so that history query results are ordered by height
@@ -53,6 +54,27 @@ func ConstructPartialCompositeHistoryKey(ns string, key string, endkey bool) []b
 	return compositeKey
 }
 
+//ConstructCompositeHistoryKeyAtBlock builds the History Key namespace~key~blocknum at which the
+// history records of a key written by the given block and the following ones begin
+func ConstructCompositeHistoryKeyAtBlock(ns string, key string, blocknum uint64) []byte {
+	compositeKey := ConstructPartialCompositeHistoryKey(ns, key, false)
+	return append(compositeKey, util.EncodeOrderPreservingVarUint64(blocknum)...)
+}
+
+//ConstructHistoryKeyRangeBound builds the History Key namespace~key~ bounding the history
+// records of a key range. An empty key bounds the history records of the whole namespace
+func ConstructHistoryKeyRangeBound(ns string, key string, endkey bool) []byte {
+	if key == "" {
+		var compositeKey []byte
+		compositeKey = append(compositeKey, []byte(ns)...)
+		if endkey {
+			return append(compositeKey, lastKeyIndicator)
+		}
+		return append(compositeKey, compositeKeySep...)
+	}
+	return ConstructPartialCompositeHistoryKey(ns, key, false)
+}
+
 //SplitCompositeHistoryKey splits the key bytes using a separator
 func SplitCompositeHistoryKey(bytesToSplit []byte, separator []byte) ([]byte, []byte) {
 	split := bytes.SplitN(bytesToSplit, separator, 2)
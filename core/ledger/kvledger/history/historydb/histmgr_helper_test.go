@@ -20,6 +20,7 @@ import (
 	"testing"
 
 	"github.com/hyperledger/fabric/common/ledger/testutil"
+	"github.com/hyperledger/fabric/common/ledger/util"
 )
 
 var strKeySep = string(compositeKeySep)
@@ -46,3 +47,13 @@ func TestSplitCompositeKey(t *testing.T) {
 	// second position should hold the extra bytes that were split off
 	testutil.AssertEquals(t, extraBytes, []byte("extra bytes to split"))
 }
+
+func TestConstructHistoryKeyBounds(t *testing.T) {
+	testutil.AssertEquals(t, ConstructCompositeHistoryKeyAtBlock("ns1", "key1", 5),
+		append([]byte("ns1"+strKeySep+"key1"+strKeySep), util.EncodeOrderPreservingVarUint64(5)...))
+
+	testutil.AssertEquals(t, ConstructHistoryKeyRangeBound("ns1", "key1", false), []byte("ns1"+strKeySep+"key1"+strKeySep))
+	testutil.AssertEquals(t, ConstructHistoryKeyRangeBound("ns1", "key1", true), []byte("ns1"+strKeySep+"key1"+strKeySep))
+	testutil.AssertEquals(t, ConstructHistoryKeyRangeBound("ns1", "", false), []byte("ns1"+strKeySep))
+	testutil.AssertEquals(t, ConstructHistoryKeyRangeBound("ns1", "", true), []byte("ns1"+string([]byte{0x01})))
+}
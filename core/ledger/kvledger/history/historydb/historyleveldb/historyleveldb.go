@@ -33,7 +33,6 @@ import (
 var logger = flogging.MustGetLogger("historyleveldb")
 
 var savePointKey = []byte{0x00}
-var emptyValue = []byte{}
 
 // HistoryDBProvider implements interface HistoryDBProvider
 type HistoryDBProvider struct {
@@ -42,9 +41,13 @@ type HistoryDBProvider struct {
 
 // NewHistoryDBProvider instantiates HistoryDBProvider
 func NewHistoryDBProvider() *HistoryDBProvider {
-	dbPath := ledgerconfig.GetHistoryLevelDBPath()
-	logger.Debugf("constructing HistoryDBProvider dbPath=%s", dbPath)
-	dbProvider := leveldbhelper.NewProvider(&leveldbhelper.Conf{DBPath: dbPath})
+	return NewHistoryDBProviderWithConf(&leveldbhelper.Conf{DBPath: ledgerconfig.GetHistoryLevelDBPath()})
+}
+
+// NewHistoryDBProviderWithConf instantiates HistoryDBProvider on the leveldb with the given configuration
+func NewHistoryDBProviderWithConf(conf *leveldbhelper.Conf) *HistoryDBProvider {
+	logger.Debugf("constructing HistoryDBProvider dbPath=%s", conf.DBPath)
+	dbProvider := leveldbhelper.NewProvider(conf)
 	return &HistoryDBProvider{dbProvider}
 }
 
@@ -53,6 +56,11 @@ func (provider *HistoryDBProvider) GetDBHandle(dbName string) (historydb.History
 	return newHistoryDB(provider.dbProvider.GetDBHandle(dbName), dbName), nil
 }
 
+// Drop deletes all the keys of a named database
+func (provider *HistoryDBProvider) Drop(dbName string) error {
+	return provider.dbProvider.GetDBHandle(dbName).DeleteAll(true)
+}
+
 // Close closes the underlying db
 func (provider *HistoryDBProvider) Close() {
 	provider.dbProvider.Close()
@@ -153,8 +161,10 @@ func (historyDB *historyDB) Commit(block *common.Block) error {
 					//composite key for history records is in the form ns~key~blockNo~tranNo
 					compositeHistoryKey := historydb.ConstructCompositeHistoryKey(ns, writeKey, blockNo, tranNo)
 
-					// No value is required, write an empty byte array (emptyValue) since Put() of nil is not allowed
-					dbBatch.Put(compositeHistoryKey, emptyValue)
+					// The key is written as the value, so that the history records of a key range can be
+					// attributed to their keys even when these contain the separator of the composite key.
+					// The records written before held an empty value
+					dbBatch.Put(compositeHistoryKey, []byte(writeKey))
 				}
 			}
 
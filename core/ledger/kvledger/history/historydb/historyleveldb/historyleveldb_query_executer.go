@@ -18,6 +18,8 @@ package historyleveldb
 
 import (
 	"errors"
+	"fmt"
+	"math"
 
 	commonledger "github.com/hyperledger/fabric/common/ledger"
 	"github.com/hyperledger/fabric/common/ledger/blkstorage"
@@ -51,21 +53,60 @@ func (q *LevelHistoryDBQueryExecutor) GetHistoryForKey(namespace string, key str
 
 	// range scan to find any history records starting with namespace~key
 	dbItr := q.historyDB.db.GetIterator(compositeStartKey, compositeEndKey)
-	return newHistoryScanner(compositeStartKey, namespace, key, dbItr, q.blockStore), nil
+	return newHistoryScanner(namespace, key, false, dbItr, q.blockStore), nil
+}
+
+// GetHistoryForKeyInBlockRange implements method in interface `ledger.HistoryQueryExecutor`
+func (q *LevelHistoryDBQueryExecutor) GetHistoryForKeyInBlockRange(namespace string, key string,
+	startBlockNum uint64, endBlockNum uint64) (commonledger.ResultsIterator, error) {
+
+	if ledgerconfig.IsHistoryDBEnabled() == false {
+		return nil, errors.New("History tracking not enabled - historyDatabase is false")
+	}
+	if startBlockNum > endBlockNum {
+		return nil, fmt.Errorf("invalid block range [%d, %d]", startBlockNum, endBlockNum)
+	}
+
+	// the history records of a key are ordered by height, those of the range are
+	// found from namespace~key~startBlockNum up to namespace~key~endBlockNum+1
+	compositeStartKey := historydb.ConstructCompositeHistoryKeyAtBlock(namespace, key, startBlockNum)
+	compositeEndKey := historydb.ConstructPartialCompositeHistoryKey(namespace, key, true)
+	if endBlockNum < math.MaxUint64 {
+		compositeEndKey = historydb.ConstructCompositeHistoryKeyAtBlock(namespace, key, endBlockNum+1)
+	}
+
+	dbItr := q.historyDB.db.GetIterator(compositeStartKey, compositeEndKey)
+	return newHistoryScanner(namespace, key, false, dbItr, q.blockStore), nil
+}
+
+// GetHistoryForKeyRange implements method in interface `ledger.HistoryQueryExecutor`
+func (q *LevelHistoryDBQueryExecutor) GetHistoryForKeyRange(namespace string, startKey string, endKey string) (commonledger.ResultsIterator, error) {
+
+	if ledgerconfig.IsHistoryDBEnabled() == false {
+		return nil, errors.New("History tracking not enabled - historyDatabase is false")
+	}
+
+	// range scan to find the history records from namespace~startKey~ up to namespace~endKey~
+	compositeStartKey := historydb.ConstructHistoryKeyRangeBound(namespace, startKey, false)
+	compositeEndKey := historydb.ConstructHistoryKeyRangeBound(namespace, endKey, true)
+	dbItr := q.historyDB.db.GetIterator(compositeStartKey, compositeEndKey)
+	return newHistoryScanner(namespace, "", true, dbItr, q.blockStore), nil
 }
 
 //historyScanner implements ResultsIterator for iterating through history results
 type historyScanner struct {
-	compositePartialKey []byte //compositePartialKey includes namespace~key
-	namespace           string
-	key                 string
-	dbItr               iterator.Iterator
-	blockStore          blkstorage.BlockStore
+	namespace string
+	key       string // key is the key whose history is iterated, unless keyRange is set
+	// keyRange is set when iterating through the history of a range of keys,
+	// in which case the results are of type *KeyRangeModification
+	keyRange   bool
+	dbItr      iterator.Iterator
+	blockStore blkstorage.BlockStore
 }
 
-func newHistoryScanner(compositePartialKey []byte, namespace string, key string,
+func newHistoryScanner(namespace string, key string, keyRange bool,
 	dbItr iterator.Iterator, blockStore blkstorage.BlockStore) *historyScanner {
-	return &historyScanner{compositePartialKey, namespace, key, dbItr, blockStore}
+	return &historyScanner{namespace, key, keyRange, dbItr, blockStore}
 }
 
 func (scanner *historyScanner) Next() (commonledger.QueryResult, error) {
@@ -74,12 +115,18 @@ func (scanner *historyScanner) Next() (commonledger.QueryResult, error) {
 	}
 	historyKey := scanner.dbItr.Key() // history key is in the form namespace~key~blocknum~trannum
 
+	key := scanner.key
+	if scanner.keyRange {
+		key = getKeyFromHistoryRecord(scanner.namespace, historyKey, scanner.dbItr.Value())
+	}
+	compositePartialKey := historydb.ConstructPartialCompositeHistoryKey(scanner.namespace, key, false)
+
 	// SplitCompositeKey(namespace~key~blocknum~trannum, namespace~key~) will return the blocknum~trannum in second position
-	_, blockNumTranNumBytes := historydb.SplitCompositeHistoryKey(historyKey, scanner.compositePartialKey)
+	_, blockNumTranNumBytes := historydb.SplitCompositeHistoryKey(historyKey, compositePartialKey)
 	blockNum, bytesConsumed := util.DecodeOrderPreservingVarUint64(blockNumTranNumBytes[0:])
 	tranNum, _ := util.DecodeOrderPreservingVarUint64(blockNumTranNumBytes[bytesConsumed:])
 	logger.Debugf("Found history record for namespace:%s key:%s at blockNumTranNum %v:%v\n",
-		scanner.namespace, scanner.key, blockNum, tranNum)
+		scanner.namespace, key, blockNum, tranNum)
 
 	// Get the transaction from block storage that is associated with this history record
 	tranEnvelope, err := scanner.blockStore.RetrieveTxByBlockNumTranNum(blockNum, tranNum)
@@ -88,12 +135,17 @@ func (scanner *historyScanner) Next() (commonledger.QueryResult, error) {
 	}
 
 	// Get the txid, key write value, timestamp, and delete indicator associated with this transaction
-	queryResult, err := getKeyModificationFromTran(tranEnvelope, scanner.namespace, scanner.key)
+	queryResult, err := getKeyModificationFromTran(tranEnvelope, scanner.namespace, key)
 	if err != nil {
 		return nil, err
 	}
+	keyModification := queryResult.(*queryresult.KeyModification)
 	logger.Debugf("Found historic key value for namespace:%s key:%s from transaction %s\n",
-		scanner.namespace, scanner.key, queryResult.(*queryresult.KeyModification).TxId)
+		scanner.namespace, key, keyModification.TxId)
+	if scanner.keyRange {
+		return &queryresult.KeyRangeModification{Key: key, TxId: keyModification.TxId, Value: keyModification.Value,
+			Timestamp: keyModification.Timestamp, IsDelete: keyModification.IsDelete}, nil
+	}
 	return queryResult, nil
 }
 
@@ -101,6 +153,33 @@ func (scanner *historyScanner) Close() {
 	scanner.dbItr.Release()
 }
 
+// getKeyFromHistoryRecord returns the key of a history record in the form namespace~key~blocknum~trannum,
+// which is the value of the record. The records written before held an empty value, their key is
+// taken to end at the first separator followed by a valid blocknum~trannum
+func getKeyFromHistoryRecord(namespace string, historyKey []byte, value []byte) string {
+	if len(value) > 0 {
+		return string(value)
+	}
+	keyAndHeight := historyKey[len(namespace)+1:]
+	for i, b := range keyAndHeight {
+		if b == 0x00 && isEncodedHeight(keyAndHeight[i+1:]) {
+			return string(keyAndHeight[:i])
+		}
+	}
+	return string(keyAndHeight)
+}
+
+// isEncodedHeight returns whether the bytes are exactly an order preserving encoding of blocknum~trannum
+func isEncodedHeight(bytes []byte) bool {
+	for i := 0; i < 2; i++ {
+		if len(bytes) == 0 || bytes[0] > 8 || len(bytes) < int(bytes[0])+1 {
+			return false
+		}
+		bytes = bytes[bytes[0]+1:]
+	}
+	return len(bytes) == 0
+}
+
 // getTxIDandKeyWriteValueFromTran inspects a transaction for writes to a given key
 func getKeyModificationFromTran(tranEnvelope *common.Envelope, namespace string, key string) (commonledger.QueryResult, error) {
 	logger.Debugf("Entering getKeyModificationFromTran()\n", namespace, key)
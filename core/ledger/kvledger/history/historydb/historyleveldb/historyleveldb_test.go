@@ -17,6 +17,7 @@ limitations under the License.
 package historyleveldb
 
 import (
+	"math"
 	"os"
 	"strconv"
 	"testing"
@@ -25,6 +26,7 @@ import (
 	"github.com/hyperledger/fabric/common/ledger/testutil"
 	util2 "github.com/hyperledger/fabric/common/util"
 	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/history/historydb"
 	"github.com/hyperledger/fabric/core/ledger/util"
 	"github.com/hyperledger/fabric/protos/common"
 	"github.com/hyperledger/fabric/protos/ledger/queryresult"
@@ -200,6 +202,103 @@ func TestHistory(t *testing.T) {
 	testutil.AssertEquals(t, count, 4)
 }
 
+func TestHistoryForKeyRangeAndBlockRange(t *testing.T) {
+	env := newTestHistoryEnv(t)
+	defer env.cleanup()
+	provider := env.testBlockStorageEnv.provider
+	ledger1id := "ledger1"
+	store1, err := provider.OpenBlockStore(ledger1id)
+	testutil.AssertNoError(t, err, "Error upon provider.OpenBlockStore()")
+	defer store1.Shutdown()
+
+	bg, gb := testutil.NewBlockGenerator(t, ledger1id, false)
+	testutil.AssertNoError(t, store1.AddBlock(gb), "")
+	testutil.AssertNoError(t, env.testHistoryDB.Commit(gb), "")
+
+	compositeKey := "\x00obj\x00key2\x00"
+	// each block has a single transaction with the given writes, a nil value being a delete
+	blockWrites := []map[string][]byte{
+		{"key1": []byte("b1"), compositeKey: []byte("b1"), "key3": []byte("b1")},
+		{"key1": []byte("b2"), "key3": nil},
+		{"key1": []byte("b3"), "key4": []byte("b3"), compositeKey: []byte("b3")},
+	}
+	for _, writes := range blockWrites {
+		simulator, _ := env.txmgr.NewTxSimulator(util2.GenerateUUID())
+		for key, value := range writes {
+			if value == nil {
+				simulator.DeleteState("ns1", key)
+				continue
+			}
+			simulator.SetState("ns1", key, value)
+		}
+		simulator.SetState("ns2", "key1", []byte("other"))
+		simulator.Done()
+		simRes, _ := simulator.GetTxSimulationResults()
+		pubSimResBytes, _ := simRes.GetPubSimulationBytes()
+		block := bg.NextBlock([][]byte{pubSimResBytes})
+		testutil.AssertNoError(t, store1.AddBlock(block), "")
+		testutil.AssertNoError(t, env.testHistoryDB.Commit(block), "")
+	}
+
+	qhistory, err := env.testHistoryDB.NewHistoryQueryExecutor(store1)
+	testutil.AssertNoError(t, err, "Error upon NewHistoryQueryExecutor")
+
+	// the values of a key written by a range of blocks
+	historyInBlockRange := func(key string, startBlockNum, endBlockNum uint64) []string {
+		itr, err := qhistory.GetHistoryForKeyInBlockRange("ns1", key, startBlockNum, endBlockNum)
+		testutil.AssertNoError(t, err, "Error upon GetHistoryForKeyInBlockRange()")
+		defer itr.Close()
+		values := []string{}
+		for {
+			kmod, err := itr.Next()
+			testutil.AssertNoError(t, err, "")
+			if kmod == nil {
+				return values
+			}
+			values = append(values, string(kmod.(*queryresult.KeyModification).Value))
+		}
+	}
+	testutil.AssertEquals(t, historyInBlockRange("key1", 2, 3), []string{"b2", "b3"})
+	testutil.AssertEquals(t, historyInBlockRange("key1", 0, 1), []string{"b1"})
+	testutil.AssertEquals(t, historyInBlockRange("key1", 2, 2), []string{"b2"})
+	testutil.AssertEquals(t, historyInBlockRange("key1", 4, math.MaxUint64), []string{})
+	testutil.AssertEquals(t, historyInBlockRange("key1", 3, math.MaxUint64), []string{"b3"})
+	testutil.AssertEquals(t, historyInBlockRange(compositeKey, 1, 2), []string{"b1"})
+	_, err = qhistory.GetHistoryForKeyInBlockRange("ns1", "key1", 3, 2)
+	testutil.AssertError(t, err, "Expected an error upon an invalid block range")
+
+	// the values written to a range of keys, as key=value
+	historyForKeyRange := func(startKey, endKey string) []string {
+		itr, err := qhistory.GetHistoryForKeyRange("ns1", startKey, endKey)
+		testutil.AssertNoError(t, err, "Error upon GetHistoryForKeyRange()")
+		defer itr.Close()
+		values := []string{}
+		for {
+			kmod, err := itr.Next()
+			testutil.AssertNoError(t, err, "")
+			if kmod == nil {
+				return values
+			}
+			krmod := kmod.(*queryresult.KeyRangeModification)
+			testutil.AssertNotNil(t, krmod.Timestamp)
+			testutil.AssertEquals(t, krmod.IsDelete, krmod.Value == nil)
+			values = append(values, krmod.Key+"="+string(krmod.Value))
+		}
+	}
+	testutil.AssertEquals(t, historyForKeyRange("key1", "key4"), []string{"key1=b1", "key1=b2", "key1=b3", "key3=b1", "key3="})
+	testutil.AssertEquals(t, historyForKeyRange("key3", ""), []string{"key3=b1", "key3=", "key4=b3"})
+	testutil.AssertEquals(t, historyForKeyRange("", "key1"), []string{compositeKey + "=b1", compositeKey + "=b3"})
+	testutil.AssertEquals(t, historyForKeyRange("key5", ""), []string{})
+
+	// the key of a record written before the keys were written as the values is taken
+	// from the history key, the separators of a composite key included
+	historyKey := historydb.ConstructCompositeHistoryKey("ns1", compositeKey, 256, 0)
+	testutil.AssertEquals(t, getKeyFromHistoryRecord("ns1", historyKey, []byte{}), compositeKey)
+	historyKey = historydb.ConstructCompositeHistoryKey("ns1", "key1", 0, 256)
+	testutil.AssertEquals(t, getKeyFromHistoryRecord("ns1", historyKey, []byte{}), "key1")
+	testutil.AssertEquals(t, getKeyFromHistoryRecord("ns1", historyKey, []byte("key1")), "key1")
+}
+
 func TestHistoryForInvalidTran(t *testing.T) {
 	env := newTestHistoryEnv(t)
 	defer env.cleanup()
@@ -0,0 +1,101 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package kvledger
+
+import (
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric/common/ledger/util/leveldbhelper"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/confighistory"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/history/historydb"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/history/historydb/historyleveldb"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/privacyenabledstate"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statedb/stateleveldb"
+	"github.com/hyperledger/fabric/core/ledger/ledgerconfig"
+	"github.com/hyperledger/fabric/core/ledger/ledgerstorage"
+)
+
+// storeProviders holds the providers of the stores a ledger is opened from. The shared store providers
+// serve all the ledgers that are not isolated, while each isolated ledger gets store providers of its own,
+// on databases that are kept under the root path of the ledger and have their own caches
+type storeProviders struct {
+	ledgerStoreProvider   *ledgerstorage.Provider
+	vdbProvider           privacyenabledstate.DBProvider
+	historydbProvider     historydb.HistoryDBProvider
+	configHistoryProvider *confighistory.Provider
+	// shared is true for the store providers of the ledgers that are not isolated
+	shared bool
+}
+
+// newIsolatedStoreProviders creates the store providers of the given isolated ledger. A CouchDB state
+// database already keeps a database per ledger, so the shared state database provider is used in that case
+func newIsolatedStoreProviders(conf *ledgerconfig.LedgerConfig, sharedVdbProvider privacyenabledstate.DBProvider) *storeProviders {
+	logger.Infof("Opening the stores of the isolated ledger under [%s]", conf.RootPath)
+	providers := &storeProviders{
+		ledgerStoreProvider: ledgerstorage.NewIsolatedProvider(conf),
+		vdbProvider:         sharedVdbProvider,
+		historydbProvider: historyleveldb.NewHistoryDBProviderWithConf(
+			&leveldbhelper.Conf{DBPath: conf.GetHistoryLevelDBPath(), BlockCacheCapacity: conf.BlockCacheSize}),
+		configHistoryProvider: confighistory.NewProviderWithConf(
+			&leveldbhelper.Conf{DBPath: conf.GetConfigHistoryPath(), BlockCacheCapacity: conf.BlockCacheSize}),
+	}
+	if !ledgerconfig.IsCouchDBEnabled() {
+		providers.vdbProvider = &privacyenabledstate.CommonStorageDBProvider{
+			VersionedDBProvider: stateleveldb.NewVersionedDBProviderWithConf(
+				&leveldbhelper.Conf{DBPath: conf.GetStateLevelDBPath(), BlockCacheCapacity: conf.BlockCacheSize}),
+		}
+	}
+	return providers
+}
+
+func (p *storeProviders) close() {
+	p.ledgerStoreProvider.Close()
+	p.historydbProvider.Close()
+	p.configHistoryProvider.Close()
+	if p.shared || !ledgerconfig.IsCouchDBEnabled() {
+		p.vdbProvider.Close()
+	}
+}
+
+// commitThrottle limits the rate at which blocks are committed to a ledger, so that the commits
+// of a busy ledger do not take all the I/O of the peer
+type commitThrottle struct {
+	bytesPerSecond int
+
+	mutex sync.Mutex
+	// next is the time the next commit may start at
+	next time.Time
+}
+
+// newCommitThrottle returns a throttle that allows the given number of bytes per second, or nil for no limit
+func newCommitThrottle(bytesPerSecond int) *commitThrottle {
+	if bytesPerSecond <= 0 {
+		return nil
+	}
+	return &commitThrottle{bytesPerSecond: bytesPerSecond}
+}
+
+// wait blocks until the bytes of the preceding commits are within the rate limit, and then accounts for
+// the given number of bytes of the commit, so a block larger than the limit is not held back on an idle ledger
+func (t *commitThrottle) wait(size int) {
+	if t == nil {
+		return
+	}
+	t.mutex.Lock()
+	now := time.Now()
+	if t.next.Before(now) {
+		t.next = now
+	}
+	start := t.next
+	t.next = t.next.Add(time.Duration(int64(size) * int64(time.Second) / int64(t.bytesPerSecond)))
+	t.mutex.Unlock()
+
+	if delay := start.Sub(now); delay > 0 {
+		time.Sleep(delay)
+	}
+}
@@ -0,0 +1,75 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package kvledger
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	configtxtest "github.com/hyperledger/fabric/common/configtx/test"
+	"github.com/hyperledger/fabric/common/ledger/testutil"
+	"github.com/hyperledger/fabric/core/ledger/ledgerconfig"
+	ledgertestutil "github.com/hyperledger/fabric/core/ledger/testutil"
+	"github.com/spf13/viper"
+)
+
+func TestIsolatedLedger(t *testing.T) {
+	env := newTestEnv(t)
+	defer env.cleanup()
+	defer ledgertestutil.ResetConfigToDefaultValues()
+	isolatedID := constructTestLedgerID(1)
+	viper.Set("ledger.perLedger", []map[string]interface{}{{"ledgerId": isolatedID, "isolated": true, "blockCacheSize": 1024 * 1024}})
+	conf, err := ledgerconfig.GetLedgerConfig(isolatedID)
+	testutil.AssertNoError(t, err, "")
+	rootPath := conf.RootPath
+
+	provider, _ := NewProvider()
+	for i := 0; i < 2; i++ {
+		gb, _ := configtxtest.MakeGenesisBlock(constructTestLedgerID(i))
+		_, err := provider.Create(gb)
+		testutil.AssertNoError(t, err, "")
+	}
+	provider.Close()
+
+	// the isolated ledger is kept under its own root path, the other one in the shared stores
+	_, err = os.Stat(filepath.Join(rootPath, "chains", "chains", isolatedID))
+	testutil.AssertNoError(t, err, "")
+	_, err = os.Stat(filepath.Join(ledgerconfig.GetBlockStorePath(), "chains", isolatedID))
+	testutil.AssertEquals(t, os.IsNotExist(err), true)
+	_, err = os.Stat(filepath.Join(ledgerconfig.GetBlockStorePath(), "chains", constructTestLedgerID(0)))
+	testutil.AssertNoError(t, err, "")
+
+	provider, _ = NewProvider()
+	defer provider.Close()
+	ledgerIds, _ := provider.List()
+	testutil.AssertEquals(t, len(ledgerIds), 2)
+	for i := 0; i < 2; i++ {
+		ledger, err := provider.Open(constructTestLedgerID(i))
+		testutil.AssertNoError(t, err, "")
+		bcInfo, err := ledger.GetBlockchainInfo()
+		testutil.AssertNoError(t, err, "")
+		testutil.AssertEquals(t, bcInfo.Height, uint64(1))
+		ledger.Close()
+	}
+}
+
+func TestCommitThrottle(t *testing.T) {
+	testutil.AssertNil(t, newCommitThrottle(0))
+	var noThrottle *commitThrottle
+	noThrottle.wait(1024)
+
+	throttle := newCommitThrottle(1000)
+	start := time.Now()
+	// the first commit is not held back, even though it is larger than the limit
+	throttle.wait(2000)
+	testutil.AssertEquals(t, time.Since(start) < time.Second, true)
+	// the second commit waits for the bytes of the first one
+	throttle.wait(100)
+	testutil.AssertEquals(t, time.Since(start) >= 2*time.Second, true)
+}
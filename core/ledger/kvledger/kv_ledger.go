@@ -17,20 +17,27 @@ limitations under the License.
 package kvledger
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
+	"sort"
+	"sync/atomic"
 
 	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric/common/flogging"
 	commonledger "github.com/hyperledger/fabric/common/ledger"
+	"github.com/hyperledger/fabric/common/ledger/blkstorage"
 	"github.com/hyperledger/fabric/common/util"
 	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/confighistory"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/history/historydb"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/privacyenabledstate"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/txmgr"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/txmgr/pvtdatatxmgr"
 	"github.com/hyperledger/fabric/core/ledger/ledgerconfig"
 	"github.com/hyperledger/fabric/core/ledger/ledgerstorage"
+	"github.com/hyperledger/fabric/core/ledger/pvtdatastorage"
+	ledgerUtil "github.com/hyperledger/fabric/core/ledger/util"
 	"github.com/hyperledger/fabric/core/transientstore"
 	"github.com/hyperledger/fabric/protos/common"
 	"github.com/hyperledger/fabric/protos/ledger/rwset"
@@ -47,13 +54,17 @@ type kvLedger struct {
 	blockStore     *ledgerstorage.Store
 	txtmgmt        txmgr.TxMgr
 	historyDB      historydb.HistoryDB
+	configHistory  *confighistory.DB
 	transientStore transientstore.Store
+	commitThrottle *commitThrottle
+	// stateDeferred is set to 1, atomically, while the recovery of the state database is deferred to the state differences
+	stateDeferred int32
 }
 
 // NewKVLedger constructs new `KVLedger`
 func newKVLedger(ledgerID string, blockStore *ledgerstorage.Store,
-	versionedDB privacyenabledstate.DB, historyDB historydb.HistoryDB,
-	transientStore transientstore.Store) (*kvLedger, error) {
+	versionedDB privacyenabledstate.DB, historyDB historydb.HistoryDB, configHistory *confighistory.DB,
+	transientStore transientstore.Store, commitThrottle *commitThrottle) (*kvLedger, error) {
 
 	logger.Debugf("Creating KVLedger ledgerID=%s: ", ledgerID)
 
@@ -62,20 +73,24 @@ func newKVLedger(ledgerID string, blockStore *ledgerstorage.Store,
 	txmgmt = pvtdatatxmgr.NewLockbasedTxMgr(versionedDB, transientStore)
 
 	// Create a kvLedger for this chain/ledger, which encasulates the underlying
-	// id store, blockstore, txmgr (state database), history database
-	l := &kvLedger{ledgerID, blockStore, txmgmt, historyDB, transientStore}
-
-	//Recover both state DB and history DB if they are out of sync with block storage
-	if err := l.recoverDBs(); err != nil {
+	// id store, blockstore, txmgr (state database), history database, config history database
+	l := &kvLedger{ledgerID: ledgerID, blockStore: blockStore, txtmgmt: txmgmt, historyDB: historyDB,
+		configHistory: configHistory, transientStore: transientStore, commitThrottle: commitThrottle}
+	// the pvt data of the collections is purged by their block to live, as configured at the committing block
+	blockStore.Init(&collectionBTLPolicy{configHistory})
+
+	//Recover the state DB, the history DB and the config history DB if they are out of sync with block storage
+	if err := l.recoverDBs(true); err != nil {
 		panic(fmt.Errorf(`Error during state DB recovery:%s`, err))
 	}
 
 	return l, nil
 }
 
-//Recover the state database and history database (if exist)
-//by recommitting last valid blocks
-func (l *kvLedger) recoverDBs() error {
+//Recover the state database, history database (if exist) and config history database
+//by recommitting last valid blocks. If deferState is set and the state database lags behind
+//by at least 'ledger.state.stateDiffSync.minLag' blocks, its recovery is deferred instead
+func (l *kvLedger) recoverDBs(deferState bool) error {
 	logger.Debugf("Entering recoverDB()")
 	//If there is no block in blockstorage, nothing to recover.
 	info, _ := l.blockStore.GetBlockchainInfo()
@@ -84,52 +99,62 @@ func (l *kvLedger) recoverDBs() error {
 		return nil
 	}
 	lastAvailableBlockNum := info.Height - 1
-	recoverables := []recoverable{l.txtmgmt, l.historyDB}
+	recoverables := []recoverable{l.txtmgmt, l.historyDB, l.configHistory}
 	recoverers := []*recoverer{}
 	for _, recoverable := range recoverables {
 		recoverFlag, firstBlockNum, err := recoverable.ShouldRecover(lastAvailableBlockNum)
 		if err != nil {
 			return err
 		}
-		if recoverFlag {
-			recoverers = append(recoverers, &recoverer{firstBlockNum, recoverable})
+		if !recoverFlag {
+			continue
 		}
+		if recoverable == l.txtmgmt && deferState && shouldDeferStateRecovery(firstBlockNum, info.Height) {
+			logger.Infof("Channel [%s]: Deferring the recovery of the state database from block [%d] to the state differences",
+				l.ledgerID, firstBlockNum)
+			atomic.StoreInt32(&l.stateDeferred, 1)
+			continue
+		}
+		recoverers = append(recoverers, &recoverer{firstBlockNum, recoverable})
 	}
 	if len(recoverers) == 0 {
 		return nil
 	}
-	if len(recoverers) == 1 {
-		return l.recommitLostBlocks(recoverers[0].firstBlockNum, lastAvailableBlockNum, recoverers[0].recoverable)
-	}
 
-	// both dbs need to be recovered
-	if recoverers[0].firstBlockNum > recoverers[1].firstBlockNum {
-		// swap (put the lagger db at 0 index)
-		recoverers[0], recoverers[1] = recoverers[1], recoverers[0]
-	}
-	if recoverers[0].firstBlockNum != recoverers[1].firstBlockNum {
-		// bring the lagger db equal to the other db
-		if err := l.recommitLostBlocks(recoverers[0].firstBlockNum, recoverers[1].firstBlockNum-1,
-			recoverers[0].recoverable); err != nil {
+	// put the most lagging dbs first, and bring each of them equal to the next one,
+	// so that the blocks are recommitted to all the dbs behind them at once
+	sort.SliceStable(recoverers, func(i, j int) bool {
+		return recoverers[i].firstBlockNum < recoverers[j].firstBlockNum
+	})
+	for i := 0; i < len(recoverers); i++ {
+		lastBlockNum := lastAvailableBlockNum
+		if i < len(recoverers)-1 {
+			if recoverers[i].firstBlockNum == recoverers[i+1].firstBlockNum {
+				continue
+			}
+			lastBlockNum = recoverers[i+1].firstBlockNum - 1
+		}
+		lagging := make([]recoverable, 0, i+1)
+		for _, r := range recoverers[:i+1] {
+			lagging = append(lagging, r.recoverable)
+		}
+		if err := l.recommitLostBlocks(recoverers[i].firstBlockNum, lastBlockNum, lagging...); err != nil {
 			return err
 		}
 	}
-	// get both the db upto block storage
-	return l.recommitLostBlocks(recoverers[1].firstBlockNum, lastAvailableBlockNum,
-		recoverers[0].recoverable, recoverers[1].recoverable)
+	return nil
 }
 
-//recommitLostBlocks retrieves blocks in specified range and commit the write set to either
-//state DB or history DB or both
+//recommitLostBlocks retrieves blocks in specified range, along with their pvt data, and commit
+//the write set to either state DB or history DB or both
 func (l *kvLedger) recommitLostBlocks(firstBlockNum uint64, lastBlockNum uint64, recoverables ...recoverable) error {
-	var err error
-	var block *common.Block
 	for blockNumber := firstBlockNum; blockNumber <= lastBlockNum; blockNumber++ {
-		if block, err = l.GetBlockByNumber(blockNumber); err != nil {
+		blockAndPvtdata, err := l.retrieveBlockAndPvtdata(blockNumber)
+		if err != nil {
 			return err
 		}
 		for _, r := range recoverables {
-			if err := r.CommitLostBlock(&ledger.BlockAndPvtData{Block: block}); err != nil {
+			if err := r.CommitLostBlock(blockAndPvtdata); err != nil {
 				return err
 			}
 		}
@@ -137,6 +162,19 @@ func (l *kvLedger) recommitLostBlocks(firstBlockNum uint64, lastBlockNum uint64,
 	return nil
 }
 
+// retrieveBlockAndPvtdata retrieves the block from the block storage along with its pvt data
+func (l *kvLedger) retrieveBlockAndPvtdata(blockNumber uint64) (*ledger.BlockAndPvtData, error) {
+	blockAndPvtdata, err := l.blockStore.GetPvtDataAndBlockByNum(blockNumber, nil)
+	if _, ok := err.(*pvtdatastorage.ErrOutOfRange); ok {
+		// the block was added to the block storage without its pvt data, return the block alone
+		var block *common.Block
+		if block, err = l.blockStore.RetrieveBlockByNumber(blockNumber); err == nil {
+			blockAndPvtdata = &ledger.BlockAndPvtData{Block: block}
+		}
+	}
+	return blockAndPvtdata, err
+}
+
 // GetTransactionByID retrieves a transaction by id
 func (l *kvLedger) GetTransactionByID(txID string) (*peer.ProcessedTransaction, error) {
 	tranEnv, err := l.blockStore.RetrieveTxByID(txID)
@@ -185,6 +223,92 @@ func (l *kvLedger) GetTxValidationCodeByTxID(txID string) (peer.TxValidationCode
 	return l.blockStore.RetrieveTxValidationCodeByTxID(txID)
 }
 
+// GetTxValidationCodes returns the validation codes of the given transactions, looking them up
+// in the block index in key order, and leaving the transactions that are not found out of the map
+func (l *kvLedger) GetTxValidationCodes(txIDs []string) (map[string]peer.TxValidationCode, error) {
+	return l.blockStore.RetrieveTxValidationCodesByTxIDs(txIDs)
+}
+
+// GetTransactionsByCreator returns a page of the transactions created by the given identity
+func (l *kvLedger) GetTransactionsByCreator(creator []byte, start blkstorage.TxPosition, limit int) ([]*peer.ProcessedTransaction, *blkstorage.TxPosition, error) {
+	return l.blockStore.RetrieveTxsByCreator(creator, start, limit)
+}
+
+// GetTransactionsByMSPIDAndChaincode returns a page of the transactions submitted by the given org to the given chaincode
+func (l *kvLedger) GetTransactionsByMSPIDAndChaincode(mspID string, chaincodeName string, start blkstorage.TxPosition, limit int) ([]*peer.ProcessedTransaction, *blkstorage.TxPosition, error) {
+	return l.blockStore.RetrieveTxsByMSPIDAndChaincode(mspID, chaincodeName, start, limit)
+}
+
+// GetTransactionProof returns the header of the block that includes the transaction
+// and the Merkle path from the transaction to the data hash of the header. It returns
+// an error if the data hash of the block is not the Merkle root of its data, i.e. the
+// block was created before the channel required the ChannelV1_2 capability
+func (l *kvLedger) GetTransactionProof(txID string) (*ledger.TransactionProof, error) {
+	block, err := l.blockStore.RetrieveBlockByTxID(txID)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(block.Data.MerkleRoot(), block.Header.DataHash) {
+		return nil, fmt.Errorf("block %d does not commit to the Merkle root of its data", block.Header.Number)
+	}
+	txIndex, err := txIndexInBlock(block, txID)
+	if err != nil {
+		return nil, err
+	}
+	path, err := block.Data.MerklePath(txIndex)
+	if err != nil {
+		return nil, err
+	}
+	return &ledger.TransactionProof{
+		BlockHeader: block.Header,
+		TxIndex:     txIndex,
+		TxCount:     uint64(len(block.Data.Data)),
+		Transaction: block.Data.Data[txIndex],
+		MerklePath:  path,
+	}, nil
+}
+
+// GetTxValidationReasonByTxID returns the reason recorded in the block metadata for the validation code of the transaction
+func (l *kvLedger) GetTxValidationReasonByTxID(txID string) (*peer.TxValidationReason, error) {
+	block, err := l.blockStore.RetrieveBlockByTxID(txID)
+	if err != nil {
+		return nil, err
+	}
+	txIndex, err := txIndexInBlock(block, txID)
+	if err != nil {
+		return nil, err
+	}
+	reasons, err := ledgerUtil.GetTxValidationReasons(block)
+	if err != nil {
+		return nil, err
+	}
+	return reasons[txIndex], nil
+}
+
+// txIndexInBlock returns the index of the transaction with the given id in the data of the block
+func txIndexInBlock(block *common.Block, txID string) (uint64, error) {
+	for txIndex, envBytes := range block.Data.Data {
+		env, err := utils.GetEnvelopeFromBlock(envBytes)
+		if err != nil {
+			return 0, err
+		}
+		chdr, err := utils.ChannelHeader(env)
+		if err != nil {
+			return 0, err
+		}
+		if chdr.TxId == txID {
+			return uint64(txIndex), nil
+		}
+	}
+	return 0, fmt.Errorf("transaction %s is indexed in block %d but is not part of its data", txID, block.Header.Number)
+}
+
+// VerifyChain verifies the integrity of the blocks in the range [startHeight, endHeight)
+// and reports the inconsistencies found
+func (l *kvLedger) VerifyChain(startHeight uint64, endHeight uint64) (*blkstorage.CorruptionReport, error) {
+	return l.blockStore.VerifyChain(startHeight, endHeight)
+}
+
 //Prune prunes the blocks/transactions that satisfy the given policy
 func (l *kvLedger) Prune(policy commonledger.PrunePolicy) error {
 	return errors.New("Not yet implemented")
@@ -202,6 +326,11 @@ func (l *kvLedger) NewQueryExecutor() (ledger.QueryExecutor, error) {
 	return l.txtmgmt.NewQueryExecutor(util.GenerateUUID())
 }
 
+// NewQueryExecutorWithIsolation gives handle to a query executor that reads the state with the given isolation level
+func (l *kvLedger) NewQueryExecutorWithIsolation(level ledger.IsolationLevel) (ledger.QueryExecutor, error) {
+	return l.txtmgmt.NewQueryExecutorWithIsolation(util.GenerateUUID(), level)
+}
+
 // NewHistoryQueryExecutor gives handle to a history query executor.
 // A client can obtain more than one 'HistoryQueryExecutor's for parallel execution.
 // Any synchronization should be performed at the implementation level if required
@@ -214,46 +343,96 @@ func (l *kvLedger) NewHistoryQueryExecutor() (ledger.HistoryQueryExecutor, error
 // TODO when we move the transient store outside the ledger, the commiter would invoke function `CommitWithPvtData` and this
 // function will be removed
 func (l *kvLedger) Commit(block *common.Block) error {
+	return l.CommitWithPvtData(&ledger.BlockAndPvtData{Block: block})
+}
+
+// CommitWithPvtData commits the block and the corresponding pvt data in an atomic operation.
+// The supplied pvt data is expected to have been verified against the hashes recorded in the block.
+// The pvt data of the collections that is not supplied is retrieved from the transient store, and
+// the collections whose pvt data is still not available are recorded as missing, as eligible unless
+// the supplied missing pvt data records the peer as ineligible for them
+func (l *kvLedger) CommitWithPvtData(pvtdataAndBlock *ledger.BlockAndPvtData) error {
+	block := pvtdataAndBlock.Block
+	if l.isStateDeferred() {
+		return fmt.Errorf("Channel [%s]: Cannot commit block [%d] while the recovery of the state database is deferred",
+			l.ledgerID, block.Header.Number)
+	}
 	pvtdata, err := retrievePrivateData(l.transientStore, block)
 	if err != nil {
 		return err
 	}
-	return l.CommitWithPvtData(&ledger.BlockAndPvtData{Block: block, BlockPvtData: pvtdata})
+	for seqInBlock, txPvtData := range pvtdataAndBlock.BlockPvtData {
+		pvtdata[seqInBlock] = mergeTxPvtData(seqInBlock, txPvtData, pvtdata[seqInBlock])
+	}
+	missingPvtData, err := missingPrivateData(block, pvtdata, pvtdataAndBlock.MissingPvtData)
+	if err != nil {
+		return err
+	}
+	return l.commitWithPvtData(&ledger.BlockAndPvtData{Block: block, BlockPvtData: pvtdata, MissingPvtData: missingPvtData})
 }
 
-// CommitWithPvtData commits the block and the corresponding pvt data in an atomic operation
-func (l *kvLedger) CommitWithPvtData(pvtdataAndBlock *ledger.BlockAndPvtData) error {
+func (l *kvLedger) commitWithPvtData(pvtdataAndBlock *ledger.BlockAndPvtData) error {
 	var err error
 	block := pvtdataAndBlock.Block
 	blockNo := pvtdataAndBlock.Block.Header.Number
 
+	l.commitThrottle.wait(proto.Size(block))
+
 	logger.Debugf("Channel [%s]: Validating block [%d]", l.ledgerID, blockNo)
 	err = l.txtmgmt.ValidateAndPrepare(pvtdataAndBlock, true)
 	if err != nil {
 		return err
 	}
+	if err = l.removeExpiredPvtData(blockNo); err != nil {
+		l.txtmgmt.Rollback()
+		return err
+	}
 
 	logger.Debugf("Channel [%s]: Committing block [%d] to storage", l.ledgerID, blockNo)
 	if err = l.blockStore.CommitWithPvtData(pvtdataAndBlock); err != nil {
+		l.txtmgmt.Rollback()
 		return err
 	}
 	logger.Infof("Channel [%s]: Created block [%d] with %d transaction(s)", l.ledgerID, block.Header.Number, len(block.Data.Data))
 
+	// Past the block storage, a failed commit leaves the block to be recommitted by RecommitLostBlocks
 	logger.Debugf("Channel [%s]: Committing block [%d] transactions to state database", l.ledgerID, blockNo)
 	if err = l.txtmgmt.Commit(); err != nil {
-		panic(fmt.Errorf(`Error during commit to txmgr:%s`, err))
+		return fmt.Errorf(`Error during commit to txmgr:%s`, err)
 	}
 
 	// History database could be written in parallel with state and/or async as a future optimization
 	if ledgerconfig.IsHistoryDBEnabled() {
 		logger.Debugf("Channel [%s]: Committing block [%d] transactions to history database", l.ledgerID, blockNo)
 		if err := l.historyDB.Commit(block); err != nil {
-			panic(fmt.Errorf(`Error during commit to history db:%s`, err))
+			return fmt.Errorf(`Error during commit to history db:%s`, err)
 		}
 	}
+
+	logger.Debugf("Channel [%s]: Committing block [%d] collection configs to config history database", l.ledgerID, blockNo)
+	if err := l.configHistory.Commit(block); err != nil {
+		return fmt.Errorf(`Error during commit to config history db:%s`, err)
+	}
 	return nil
 }
 
+// RecommitLostBlocks implements method in interface `ledger.LostBlocksRecommitter`
+func (l *kvLedger) RecommitLostBlocks() error {
+	if err := l.blockStore.SyncPvtdataStoreWithBlockStore(); err != nil {
+		return err
+	}
+	if err := l.recoverDBs(false); err != nil {
+		return err
+	}
+	atomic.StoreInt32(&l.stateDeferred, 0)
+	return nil
+}
+
+// GetConfigHistoryRetriever returns the retriever of the versions of the collection configs committed to the ledger
+func (l *kvLedger) GetConfigHistoryRetriever() (ledger.ConfigHistoryRetriever, error) {
+	return l.configHistory, nil
+}
+
 // GetPvtDataAndBlockByNum returns the block and the corresponding pvt data.
 // The pvt data is filtered by the list of 'collections' supplied
 func (l *kvLedger) GetPvtDataAndBlockByNum(blockNum uint64, filter ledger.PvtNsCollFilter) (*ledger.BlockAndPvtData, error) {
@@ -278,6 +457,11 @@ func (l *kvLedger) PrivateDataMinBlockNum() (uint64, error) {
 	return 0, fmt.Errorf("not yet implemented")
 }
 
+// GetMissingPvtDataInfoForMostRecentBlocks implements the corresponding method from interface ledger.PeerLedger
+func (l *kvLedger) GetMissingPvtDataInfoForMostRecentBlocks(maxBlock int) (ledger.MissingPvtDataInfo, error) {
+	return l.blockStore.GetMissingPvtDataInfoForMostRecentBlocks(maxBlock)
+}
+
 // Close closes `KVLedger`
 func (l *kvLedger) Close() {
 	l.blockStore.Shutdown()
@@ -285,8 +469,22 @@ func (l *kvLedger) Close() {
 	l.transientStore.Shutdown()
 }
 
+// PersistPvtData implements method in interface `ledger.TransientPvtDataPersister`
+func (l *kvLedger) PersistPvtData(txid string, endorserid string, endorsementBlkHt uint64, pvtSimResults *rwset.TxPvtReadWriteSet) error {
+	// the empty endorser id denotes the private write sets simulated by the peer itself
+	if endorserid == "" {
+		return errors.New("endorser id is required to persist the private data of another endorser")
+	}
+	pvtSimBytes, err := proto.Marshal(pvtSimResults)
+	if err != nil {
+		return err
+	}
+	return l.transientStore.Persist(txid, endorserid, endorsementBlkHt, pvtSimBytes)
+}
+
 // retrievePrivateData retrieves the pvt data from the transient store for committing it into the
-// pvt data store along with block commit.
+// pvt data store along with block commit. The pvt data simulated by the peer itself and the pvt data
+// pushed by other endorsers are both verified against the hashes recorded in the block.
 // KVLedger does this job temporarily for phase-1 and will be moved out to committer
 func retrievePrivateData(transientStore transientstore.Store, block *common.Block) (map[uint64]*ledger.TxPvtData, error) {
 	pvtdata := make(map[uint64]*ledger.TxPvtData)
@@ -303,19 +501,177 @@ func retrievePrivateData(transientStore transientstore.Store, block *common.Bloc
 		if err != nil {
 			return nil, err
 		}
-		pvtEndorsement, err := transientStore.GetSelfSimulatedTxPvtRWSetByTxid(chdr.TxId)
+		txPvtRWSet, err := retrieveVerifiedPrivateData(transientStore, chdr.TxId, envBytes)
 		if err != nil {
 			return nil, err
 		}
-		if pvtEndorsement == nil {
+		if txPvtRWSet == nil {
 			continue
 		}
-		txPvtRWSet := &rwset.TxPvtReadWriteSet{}
-		if err := proto.Unmarshal(pvtEndorsement.PvtSimulationResults, txPvtRWSet); err != nil {
-			return nil, err
-		}
 		seqInBlock := uint64(txIndex)
 		pvtdata[seqInBlock] = &ledger.TxPvtData{SeqInBlock: seqInBlock, WriteSet: txPvtRWSet}
 	}
 	return pvtdata, nil
 }
+
+// missingPrivateData returns the collections of the valid transactions of the block whose pvt data
+// is not part of the given pvt data. As the ledger cannot tell the collections the peer is a member
+// of, the missing pvt data is recorded as eligible unless the given missing pvt data, supplied by
+// the committer, records it as ineligible
+func missingPrivateData(block *common.Block, pvtdata map[uint64]*ledger.TxPvtData, supplied ledger.TxMissingPvtDataMap) (ledger.TxMissingPvtDataMap, error) {
+	missingPvtData := make(ledger.TxMissingPvtDataMap)
+	var txsFilter ledgerUtil.TxValidationFlags
+	if block.Metadata != nil && len(block.Metadata.Metadata) > int(common.BlockMetadataIndex_TRANSACTIONS_FILTER) {
+		txsFilter = ledgerUtil.TxValidationFlags(block.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER])
+	}
+	for txIndex, envBytes := range block.Data.Data {
+		if len(txsFilter) > txIndex && txsFilter.IsInvalid(txIndex) {
+			continue
+		}
+		hashes, err := pvtDataHashes(envBytes)
+		if err != nil {
+			return nil, err
+		}
+		seqInBlock := uint64(txIndex)
+		for ns, colls := range hashes {
+			for coll := range colls {
+				if txPvtData, ok := pvtdata[seqInBlock]; ok && txPvtData.Has(ns, coll) {
+					continue
+				}
+				missingPvtData.Add(seqInBlock, ns, coll, !isIneligible(supplied[seqInBlock], ns, coll))
+			}
+		}
+	}
+	return missingPvtData, nil
+}
+
+func isIneligible(missingPvtData []*ledger.MissingPvtData, ns, coll string) bool {
+	for _, missing := range missingPvtData {
+		if missing.Namespace == ns && missing.Collection == coll {
+			return !missing.IsEligible
+		}
+	}
+	return false
+}
+
+// retrieveVerifiedPrivateData assembles the pvt data of a transaction from the pvt data simulated by this
+// peer or pushed by other endorsers into the transient store. A collection is taken from the first pvt data
+// whose hash matches the one in the public read-write set of the transaction, the rest is ignored so that
+// pvt data simulated differently than the committed transaction, or invalid pvt data pushed by a peer,
+// does not fail the commit. It returns nil if no collection is found.
+func retrieveVerifiedPrivateData(transientStore transientstore.Store, txid string, envBytes []byte) (*rwset.TxPvtReadWriteSet, error) {
+	itr, err := transientStore.GetTxPvtRWSetByTxid(txid)
+	if err != nil {
+		return nil, err
+	}
+	defer itr.Close()
+
+	var hashes map[string]map[string][]byte
+	var assembled *rwset.TxPvtReadWriteSet
+	collected := make(map[string]map[string]bool)
+	for {
+		res, err := itr.Next()
+		if err != nil {
+			return nil, err
+		}
+		if res == nil {
+			break
+		}
+		pvtEndorsement := res.(*transientstore.EndorserPvtSimulationResults)
+		received := &rwset.TxPvtReadWriteSet{}
+		if err := proto.Unmarshal(pvtEndorsement.PvtSimulationResults, received); err != nil {
+			logger.Warningf("Ignoring invalid pvt data of txid=[%s] from endorser [%s]: %s", txid, pvtEndorsement.EndorserID, err)
+			continue
+		}
+		if hashes == nil {
+			if hashes, err = pvtDataHashes(envBytes); err != nil {
+				return nil, err
+			}
+		}
+		for _, nsPvtRWSet := range received.NsPvtRwset {
+			for _, collPvtRWSet := range nsPvtRWSet.CollectionPvtRwset {
+				ns, coll := nsPvtRWSet.Namespace, collPvtRWSet.CollectionName
+				if collected[ns][coll] {
+					continue
+				}
+				if !bytes.Equal(ledgerUtil.ComputeHash(collPvtRWSet.Rwset), hashes[ns][coll]) {
+					logger.Warningf("Ignoring pvt data of txid=[%s] for collection [%s:%s] from endorser [%s], its hash does not match",
+						txid, ns, coll, pvtEndorsement.EndorserID)
+					continue
+				}
+				if assembled == nil {
+					assembled = &rwset.TxPvtReadWriteSet{DataModel: received.DataModel}
+				}
+				addCollPvtRWSet(assembled, ns, collPvtRWSet)
+				if collected[ns] == nil {
+					collected[ns] = make(map[string]bool)
+				}
+				collected[ns][coll] = true
+			}
+		}
+	}
+	return assembled, nil
+}
+
+// mergeTxPvtData returns the pvt data of a transaction made of the collections of the supplied pvt data,
+// and of the collections of the pvt data retrieved from the transient store that are not supplied
+func mergeTxPvtData(seqInBlock uint64, supplied, retrieved *ledger.TxPvtData) *ledger.TxPvtData {
+	if retrieved == nil || retrieved.WriteSet == nil {
+		return supplied
+	}
+	if supplied == nil || supplied.WriteSet == nil {
+		return retrieved
+	}
+	merged := &rwset.TxPvtReadWriteSet{DataModel: supplied.WriteSet.DataModel}
+	for _, nsPvtRWSet := range supplied.WriteSet.NsPvtRwset {
+		for _, collPvtRWSet := range nsPvtRWSet.CollectionPvtRwset {
+			addCollPvtRWSet(merged, nsPvtRWSet.Namespace, collPvtRWSet)
+		}
+	}
+	for _, nsPvtRWSet := range retrieved.WriteSet.NsPvtRwset {
+		for _, collPvtRWSet := range nsPvtRWSet.CollectionPvtRwset {
+			if supplied.Has(nsPvtRWSet.Namespace, collPvtRWSet.CollectionName) {
+				continue
+			}
+			addCollPvtRWSet(merged, nsPvtRWSet.Namespace, collPvtRWSet)
+		}
+	}
+	return &ledger.TxPvtData{SeqInBlock: seqInBlock, WriteSet: merged}
+}
+
+// pvtDataHashes returns the hashes of the pvt write sets recorded in the public read-write set of
+// a transaction, by namespace and collection
+func pvtDataHashes(envBytes []byte) (map[string]map[string][]byte, error) {
+	hashes := make(map[string]map[string][]byte)
+	action, err := utils.GetActionFromEnvelope(envBytes)
+	if err != nil {
+		// not an endorser transaction, it has no pvt data
+		return hashes, nil
+	}
+	txRWSet := &rwset.TxReadWriteSet{}
+	if err := proto.Unmarshal(action.Results, txRWSet); err != nil {
+		return nil, err
+	}
+	for _, nsRWSet := range txRWSet.NsRwset {
+		for _, collHashedRWSet := range nsRWSet.CollectionHashedRwset {
+			if hashes[nsRWSet.Namespace] == nil {
+				hashes[nsRWSet.Namespace] = make(map[string][]byte)
+			}
+			hashes[nsRWSet.Namespace][collHashedRWSet.CollectionName] = collHashedRWSet.PvtRwsetHash
+		}
+	}
+	return hashes, nil
+}
+
+func addCollPvtRWSet(txPvtRWSet *rwset.TxPvtReadWriteSet, ns string, collPvtRWSet *rwset.CollectionPvtReadWriteSet) {
+	for _, nsPvtRWSet := range txPvtRWSet.NsPvtRwset {
+		if nsPvtRWSet.Namespace == ns {
+			nsPvtRWSet.CollectionPvtRwset = append(nsPvtRWSet.CollectionPvtRwset, collPvtRWSet)
+			return
+		}
+	}
+	txPvtRWSet.NsPvtRwset = append(txPvtRWSet.NsPvtRwset, &rwset.NsPvtReadWriteSet{
+		Namespace:          ns,
+		CollectionPvtRwset: []*rwset.CollectionPvtReadWriteSet{collPvtRWSet},
+	})
+}
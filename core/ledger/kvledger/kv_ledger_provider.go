@@ -20,10 +20,12 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"sync"
 
 	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric/common/ledger/util/leveldbhelper"
 	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/confighistory"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/history/historydb"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/history/historydb/historyleveldb"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/privacyenabledstate"
@@ -33,6 +35,7 @@ import (
 	"github.com/hyperledger/fabric/protos/common"
 	"github.com/hyperledger/fabric/protos/utils"
 	"github.com/syndtr/goleveldb/leveldb"
+	"golang.org/x/net/context"
 )
 
 var (
@@ -50,20 +53,24 @@ var (
 // Provider implements interface ledger.PeerLedgerProvider
 type Provider struct {
 	idStore                *idStore
-	ledgerStoreProvider    *ledgerstorage.Provider
-	vdbProvider            privacyenabledstate.DBProvider
-	historydbProvider      historydb.HistoryDBProvider
+	sharedStoreProviders   *storeProviders
 	transientStoreProvider transientstore.StoreProvider
+
+	isolatedLock           sync.Mutex
+	isolatedStoreProviders map[string]*storeProviders
 }
 
 // NewProvider instantiates a new Provider.
 // This is not thread-safe and assumed to be synchronized be the caller
 func NewProvider() (ledger.PeerLedgerProvider, error) {
+	// Initialize the ID store (inventory of chainIds/ledgerIds)
+	return newProvider(openIDStore(ledgerconfig.GetLedgerProviderPath()))
+}
 
-	logger.Info("Initializing ledger provider")
+// newProvider instantiates a new Provider with the given, opened, ID store
+func newProvider(idStore *idStore) (*Provider, error) {
 
-	// Initialize the ID store (inventory of chainIds/ledgerIds)
-	idStore := openIDStore(ledgerconfig.GetLedgerProviderPath())
+	logger.Info("Initializing ledger provider")
 
 	ledgerStoreProvider := ledgerstorage.NewProvider()
 
@@ -79,8 +86,16 @@ func NewProvider() (ledger.PeerLedgerProvider, error) {
 	var historydbProvider historydb.HistoryDBProvider
 	historydbProvider = historyleveldb.NewHistoryDBProvider()
 
+	// Initialize the config history database (versions of the collection configs by block number)
+	configHistoryProvider := confighistory.NewProvider()
+
 	logger.Info("ledger provider Initialized")
-	provider := &Provider{idStore, ledgerStoreProvider, vdbProvider, historydbProvider, transientStoreProvider}
+	provider := &Provider{
+		idStore:                idStore,
+		sharedStoreProviders:   &storeProviders{ledgerStoreProvider, vdbProvider, historydbProvider, configHistoryProvider, true},
+		transientStoreProvider: transientStoreProvider,
+		isolatedStoreProviders: make(map[string]*storeProviders),
+	}
 	provider.recoverUnderConstructionLedger()
 	return provider, nil
 }
@@ -135,24 +150,33 @@ func (provider *Provider) Open(ledgerID string) (ledger.PeerLedger, error) {
 }
 
 func (provider *Provider) openInternal(ledgerID string) (ledger.PeerLedger, error) {
+	conf, err := ledgerconfig.GetLedgerConfig(ledgerID)
+	if err != nil {
+		return nil, err
+	}
+	storeProviders := provider.storeProviders(ledgerID, conf)
+
 	// Get the block store for a chain/ledger
-	blockStore, err := provider.ledgerStoreProvider.Open(ledgerID)
+	blockStore, err := storeProviders.ledgerStoreProvider.Open(ledgerID)
 	if err != nil {
 		return nil, err
 	}
 
 	// Get the versioned database (state database) for a chain/ledger
-	vDB, err := provider.vdbProvider.GetDBHandle(ledgerID)
+	vDB, err := storeProviders.vdbProvider.GetDBHandle(ledgerID)
 	if err != nil {
 		return nil, err
 	}
 
 	// Get the history database (index for history of values by key) for a chain/ledger
-	historyDB, err := provider.historydbProvider.GetDBHandle(ledgerID)
+	historyDB, err := storeProviders.historydbProvider.GetDBHandle(ledgerID)
 	if err != nil {
 		return nil, err
 	}
 
+	// Get the config history database (versions of the collection configs by block number) for a chain/ledger
+	configHistory := storeProviders.configHistoryProvider.GetDBHandle(ledgerID)
+
 	// Get the transient store for a chain/ledger
 	transientStore, err := provider.transientStoreProvider.OpenStore(ledgerID)
 	if err != nil {
@@ -160,14 +184,31 @@ func (provider *Provider) openInternal(ledgerID string) (ledger.PeerLedger, erro
 	}
 
 	// Create a kvLedger for this chain/ledger, which encasulates the underlying data stores
-	// (id store, blockstore, state database, history database)
-	l, err := newKVLedger(ledgerID, blockStore, vDB, historyDB, transientStore)
+	// (id store, blockstore, state database, history database, config history database)
+	l, err := newKVLedger(ledgerID, blockStore, vDB, historyDB, configHistory, transientStore,
+		newCommitThrottle(conf.MaxCommitBytesPerSecond))
 	if err != nil {
 		return nil, err
 	}
 	return l, nil
 }
 
+// storeProviders returns the store providers of the given ledger, creating them on the first open
+// of an isolated ledger
+func (provider *Provider) storeProviders(ledgerID string, conf *ledgerconfig.LedgerConfig) *storeProviders {
+	if !conf.Isolated {
+		return provider.sharedStoreProviders
+	}
+	provider.isolatedLock.Lock()
+	defer provider.isolatedLock.Unlock()
+	p, ok := provider.isolatedStoreProviders[ledgerID]
+	if !ok {
+		p = newIsolatedStoreProviders(conf, provider.sharedStoreProviders.vdbProvider)
+		provider.isolatedStoreProviders[ledgerID] = p
+	}
+	return p
+}
+
 // Exists implements the corresponding method from interface ledger.PeerLedgerProvider
 func (provider *Provider) Exists(ledgerID string) (bool, error) {
 	return provider.idStore.ledgerIDExists(ledgerID)
@@ -178,12 +219,62 @@ func (provider *Provider) List() ([]string, error) {
 	return provider.idStore.getAllLedgerIds()
 }
 
+// HealthCheck checks that the database of the ledger ids can be read, for the operations server
+func (provider *Provider) HealthCheck(ctx context.Context) error {
+	if _, err := provider.idStore.getUnderConstructionFlag(); err != nil {
+		return fmt.Errorf("Error while reading the ledger ids: %s", err)
+	}
+	return nil
+}
+
+// Remove removes the ledger with the given id, which should not be open, along with its blocks, pvt data,
+// state and history. The ledger is marked as under construction until its blocks are removed, so that
+// a removal interrupted by a crash is completed by 'recoverUnderConstructionLedger'
+func (provider *Provider) Remove(ledgerID string) error {
+	exists, err := provider.idStore.ledgerIDExists(ledgerID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrNonExistingLedgerID
+	}
+	conf, err := ledgerconfig.GetLedgerConfig(ledgerID)
+	if err != nil {
+		return err
+	}
+	logger.Infof("Removing ledger [%s]", ledgerID)
+	if err := provider.idStore.deleteLedgerID(ledgerID); err != nil {
+		return err
+	}
+	storeProviders := provider.storeProviders(ledgerID, conf)
+	if err := storeProviders.ledgerStoreProvider.Remove(ledgerID); err != nil {
+		return err
+	}
+	if err := storeProviders.vdbProvider.Drop(ledgerID); err != nil {
+		return err
+	}
+	if err := storeProviders.historydbProvider.Drop(ledgerID); err != nil {
+		return err
+	}
+	if err := storeProviders.configHistoryProvider.Drop(ledgerID); err != nil {
+		return err
+	}
+	if err := provider.idStore.unsetUnderConstructionFlag(); err != nil {
+		return err
+	}
+	logger.Infof("Removed ledger [%s]", ledgerID)
+	return nil
+}
+
 // Close implements the corresponding method from interface ledger.PeerLedgerProvider
 func (provider *Provider) Close() {
 	provider.idStore.close()
-	provider.ledgerStoreProvider.Close()
-	provider.vdbProvider.Close()
-	provider.historydbProvider.Close()
+	provider.sharedStoreProviders.close()
+	provider.isolatedLock.Lock()
+	for _, p := range provider.isolatedStoreProviders {
+		p.close()
+	}
+	provider.isolatedLock.Unlock()
 	provider.transientStoreProvider.Close()
 }
 
@@ -291,6 +382,14 @@ func (s *idStore) createLedgerID(ledgerID string, gb *common.Block) error {
 	return s.db.WriteBatch(batch, true)
 }
 
+// deleteLedgerID removes the ledger id from the created ledgers list, and marks the ledger as under construction
+func (s *idStore) deleteLedgerID(ledgerID string) error {
+	batch := &leveldb.Batch{}
+	batch.Delete(s.encodeLedgerKey(ledgerID))
+	batch.Put(underConstructionLedgerKey, []byte(ledgerID))
+	return s.db.WriteBatch(batch, true)
+}
+
 func (s *idStore) ledgerIDExists(ledgerID string) (bool, error) {
 	key := s.encodeLedgerKey(ledgerID)
 	val := []byte{}
@@ -82,6 +82,35 @@ func TestLedgerProvider(t *testing.T) {
 	testutil.AssertEquals(t, err, ErrNonExistingLedgerID)
 }
 
+func TestLedgerProviderRemove(t *testing.T) {
+	env := newTestEnv(t)
+	defer env.cleanup()
+	provider, _ := NewProvider()
+	defer provider.Close()
+	p := provider.(*Provider)
+
+	genesisBlock, _ := configtxtest.MakeGenesisBlock(constructTestLedgerID(1))
+	ledger, err := provider.Create(genesisBlock)
+	testutil.AssertNoError(t, err, "")
+	ledger.Close()
+
+	testutil.AssertNoError(t, p.Remove(constructTestLedgerID(1)), "")
+	status, _ := provider.Exists(constructTestLedgerID(1))
+	testutil.AssertEquals(t, status, false)
+	flag, err := p.idStore.getUnderConstructionFlag()
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, flag, "")
+	testutil.AssertEquals(t, p.Remove(constructTestLedgerID(1)), ErrNonExistingLedgerID)
+
+	// the removed ledger can be created again
+	ledger, err = provider.Create(genesisBlock)
+	testutil.AssertNoError(t, err, "")
+	bcInfo, err := ledger.GetBlockchainInfo()
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, bcInfo.Height, uint64(1))
+	ledger.Close()
+}
+
 func TestRecovery(t *testing.T) {
 	env := newTestEnv(t)
 	defer env.cleanup()
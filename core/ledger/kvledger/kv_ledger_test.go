@@ -22,7 +22,9 @@ import (
 	"strconv"
 	"testing"
 
+	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/common/ledger/blkstorage"
 	"github.com/hyperledger/fabric/common/ledger/testutil"
 	"github.com/hyperledger/fabric/common/util"
 	lgr "github.com/hyperledger/fabric/core/ledger"
@@ -30,6 +32,7 @@ import (
 	ledgertestutil "github.com/hyperledger/fabric/core/ledger/testutil"
 	"github.com/hyperledger/fabric/protos/common"
 	"github.com/hyperledger/fabric/protos/ledger/queryresult"
+	"github.com/hyperledger/fabric/protos/ledger/rwset"
 	"github.com/hyperledger/fabric/protos/peer"
 	putils "github.com/hyperledger/fabric/protos/utils"
 	"github.com/spf13/viper"
@@ -125,6 +128,115 @@ func TestKVLedgerBlockStorage(t *testing.T) {
 	// get the transaction validation code for this transaction id
 	validCode, _ := ledger.GetTxValidationCodeByTxID(txID2)
 	testutil.AssertEquals(t, validCode, peer.TxValidationCode_VALID)
+
+	// get the transaction validation codes in a batch, the non-existent transaction is left out
+	validCodes, err := ledger.GetTxValidationCodes([]string{txID2, "non-existent-txid"})
+	testutil.AssertNoError(t, err, "Error upon GetTxValidationCodes")
+	testutil.AssertEquals(t, validCodes, map[string]peer.TxValidationCode{txID2: peer.TxValidationCode_VALID})
+
+	// the transactions of both blocks were created by the same identity
+	shdr, err := putils.GetSignatureHeader(payload2.Header.SignatureHeader)
+	testutil.AssertNoError(t, err, "Error upon GetSignatureHeader")
+	txs, next, err := ledger.GetTransactionsByCreator(shdr.Creator, blkstorage.TxPosition{BlockNum: 1}, 1)
+	testutil.AssertNoError(t, err, "Error upon GetTransactionsByCreator")
+	testutil.AssertEquals(t, len(txs), 1)
+	testutil.AssertEquals(t, txs[0].TransactionEnvelope, txEnv2)
+	testutil.AssertEquals(t, next, &blkstorage.TxPosition{BlockNum: 2, TranNum: 0})
+}
+
+func TestKVLedgerTransactionProof(t *testing.T) {
+	env := newTestEnv(t)
+	defer env.cleanup()
+	provider, _ := NewProvider()
+	defer provider.Close()
+
+	bg, gb := testutil.NewBlockGenerator(t, "testLedger", false)
+	ledger, _ := provider.Create(gb)
+	defer ledger.Close()
+
+	var simulationResults [][]byte
+	var txids []string
+	for i := 0; i < 5; i++ {
+		simulator, _ := ledger.NewTxSimulator(util.GenerateUUID())
+		simulator.SetState("ns1", fmt.Sprintf("key%d", i), []byte(fmt.Sprintf("value%d", i)))
+		simulator.Done()
+		simRes, _ := simulator.GetTxSimulationResults()
+		pubSimBytes, _ := simRes.GetPubSimulationBytes()
+		simulationResults = append(simulationResults, pubSimBytes)
+		txids = append(txids, util.GenerateUUID())
+	}
+	// the block generator creates blocks with the flat data hash
+	block1 := bg.NextBlockWithTxid(simulationResults[:1], txids[:1])
+	assert.NoError(t, ledger.Commit(block1))
+	_, err := ledger.GetTransactionProof(txids[0])
+	assert.Error(t, err)
+
+	block2 := bg.NextBlockWithTxid(simulationResults[1:], txids[1:])
+	block2.Header.DataHash = block2.Data.MerkleRoot()
+	assert.NoError(t, ledger.Commit(block2))
+
+	for i, txid := range txids[1:] {
+		proof, err := ledger.GetTransactionProof(txid)
+		assert.NoError(t, err)
+		assert.Equal(t, block2.Header, proof.BlockHeader)
+		assert.Equal(t, uint64(i), proof.TxIndex)
+		assert.Equal(t, uint64(4), proof.TxCount)
+		assert.Equal(t, block2.Data.Data[i], proof.Transaction)
+		assert.NoError(t, proof.Verify())
+
+		proof.Transaction = block2.Data.Data[(i+1)%4]
+		assert.Error(t, proof.Verify())
+	}
+
+	_, err = ledger.GetTransactionProof("unknownTxID")
+	assert.Error(t, err)
+}
+
+func TestKVLedgerTxValidationReason(t *testing.T) {
+	env := newTestEnv(t)
+	defer env.cleanup()
+	provider, _ := NewProvider()
+	defer provider.Close()
+
+	bg, gb := testutil.NewBlockGenerator(t, "testLedger", false)
+	ledger, _ := provider.Create(gb)
+	defer ledger.Close()
+
+	simulator, _ := ledger.NewTxSimulator(util.GenerateUUID())
+	simulator.SetState("ns1", "key1", []byte("value1"))
+	simulator.Done()
+	simRes, _ := simulator.GetTxSimulationResults()
+	pubSimBytes, _ := simRes.GetPubSimulationBytes()
+	assert.NoError(t, ledger.Commit(bg.NextBlock([][]byte{pubSimBytes})))
+
+	// both transactions read and update key1, so the second one is in conflict with the first one
+	var simulationResults [][]byte
+	var txids []string
+	for i := 0; i < 2; i++ {
+		simulator, _ := ledger.NewTxSimulator(util.GenerateUUID())
+		simulator.GetState("ns1", "key1")
+		simulator.SetState("ns1", "key1", []byte(fmt.Sprintf("value1_%d", i)))
+		simulator.Done()
+		simRes, _ := simulator.GetTxSimulationResults()
+		pubSimBytes, _ := simRes.GetPubSimulationBytes()
+		simulationResults = append(simulationResults, pubSimBytes)
+		txids = append(txids, util.GenerateUUID())
+	}
+	assert.NoError(t, ledger.Commit(bg.NextBlockWithTxid(simulationResults, txids)))
+
+	reason, err := ledger.GetTxValidationReasonByTxID(txids[0])
+	assert.NoError(t, err)
+	assert.Nil(t, reason)
+	reason, err = ledger.GetTxValidationReasonByTxID(txids[1])
+	assert.NoError(t, err)
+	assert.NotNil(t, reason)
+	assert.Equal(t, uint64(1), reason.TxIndex)
+	assert.Equal(t, peer.TxValidationCode_MVCC_READ_CONFLICT, reason.Code)
+	assert.Equal(t, "ns1", reason.Namespace)
+	assert.Equal(t, "key1", reason.Key)
+
+	_, err = ledger.GetTxValidationReasonByTxID("unknownTxID")
+	assert.Error(t, err)
 }
 
 func TestKVLedgerBlockStorageWithPvtdata(t *testing.T) {
@@ -188,6 +300,189 @@ func TestKVLedgerBlockStorageWithPvtdata(t *testing.T) {
 	testutil.AssertNil(t, pvtdataAndBlock.BlockPvtData)
 }
 
+func TestKVLedgerCommitWithReceivedPvtdata(t *testing.T) {
+	env := newTestEnv(t)
+	defer env.cleanup()
+	provider, _ := NewProvider()
+	defer provider.Close()
+
+	bg, gb := testutil.NewBlockGenerator(t, "testLedger", false)
+	ledger, _ := provider.Create(gb)
+	defer ledger.Close()
+
+	// the transaction is simulated by another endorser, which pushes its pvt data
+	simulator, _ := ledger.NewTxSimulator(util.GenerateUUID())
+	simulator.SetState("ns1", "key1", []byte("value1"))
+	simulator.SetPrivateData("ns1", "coll1", "key2", []byte("value2"))
+	simulator.SetPrivateData("ns1", "coll2", "key2", []byte("value3"))
+	simulator.Done()
+	simRes, _ := simulator.GetTxSimulationResults()
+	pubSimBytes, _ := simRes.GetPubSimulationBytes()
+	txid := util.GenerateUUID()
+
+	persister, ok := ledger.(lgr.TransientPvtDataPersister)
+	testutil.AssertEquals(t, ok, true)
+	testutil.AssertError(t, persister.PersistPvtData(txid, "", 1, simRes.PvtSimulationResults), "")
+
+	// the pvt data of coll2 is tampered with by the endorser
+	tampered := proto.Clone(simRes.PvtSimulationResults).(*rwset.TxPvtReadWriteSet)
+	tampered.NsPvtRwset[0].CollectionPvtRwset[1].Rwset = []byte("tampered")
+	testutil.AssertNoError(t, persister.PersistPvtData(txid, "endorser1", 1, tampered), "")
+
+	block1 := bg.NextBlockWithTxid([][]byte{pubSimBytes}, []string{txid})
+	testutil.AssertNoError(t, ledger.Commit(block1), "")
+
+	pvtdataAndBlock, _ := ledger.GetPvtDataAndBlockByNum(1, nil)
+	testutil.AssertNotNil(t, pvtdataAndBlock.BlockPvtData)
+	testutil.AssertEquals(t, pvtdataAndBlock.BlockPvtData[0].Has("ns1", "coll1"), true)
+	testutil.AssertEquals(t, pvtdataAndBlock.BlockPvtData[0].Has("ns1", "coll2"), false)
+
+	// the pvt data of coll2 is recorded as missing
+	expectedMissingPvtDataInfo := make(lgr.MissingPvtDataInfo)
+	expectedMissingPvtDataInfo.Add(1, 0, "ns1", "coll2")
+	missingPvtDataInfo, err := ledger.GetMissingPvtDataInfoForMostRecentBlocks(10)
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, missingPvtDataInfo, expectedMissingPvtDataInfo)
+}
+
+func TestKVLedgerCommitRecordsMissingPvtdata(t *testing.T) {
+	env := newTestEnv(t)
+	defer env.cleanup()
+	provider, _ := NewProvider()
+	defer provider.Close()
+
+	bg, gb := testutil.NewBlockGenerator(t, "testLedger", false)
+	ledger, _ := provider.Create(gb)
+	defer ledger.Close()
+
+	simulate := func(txid string, value string) *lgr.TxSimulationResults {
+		simulator, _ := ledger.NewTxSimulator(txid)
+		simulator.SetState("ns1", "key1", []byte("value1"))
+		simulator.SetPrivateData("ns1", "coll1", "key2", []byte(value))
+		simulator.SetPrivateData("ns1", "coll2", "key2", []byte("value3"))
+		simulator.Done()
+		simRes, _ := simulator.GetTxSimulationResults()
+		return simRes
+	}
+
+	// the peer simulated the transaction differently than the endorsement that made it to the block
+	txid := util.GenerateUUID()
+	simulate(txid, "value2")
+	pubSimBytes, _ := simulate(util.GenerateUUID(), "othervalue").GetPubSimulationBytes()
+	block1 := bg.NextBlockWithTxid([][]byte{pubSimBytes}, []string{txid})
+	testutil.AssertNoError(t, ledger.Commit(block1), "")
+
+	pvtdataAndBlock, _ := ledger.GetPvtDataAndBlockByNum(1, nil)
+	testutil.AssertNotNil(t, pvtdataAndBlock.BlockPvtData)
+	testutil.AssertEquals(t, pvtdataAndBlock.BlockPvtData[0].Has("ns1", "coll1"), false)
+	testutil.AssertEquals(t, pvtdataAndBlock.BlockPvtData[0].Has("ns1", "coll2"), true)
+
+	// the pvt data of a collection is not supplied along with the block, as the committer
+	// dropped the pvt data it received for it since it did not match the hash in the block
+	simRes := simulate(util.GenerateUUID(), "value2")
+	pubSimBytes, _ = simRes.GetPubSimulationBytes()
+	verified := proto.Clone(simRes.PvtSimulationResults).(*rwset.TxPvtReadWriteSet)
+	verified.NsPvtRwset[0].CollectionPvtRwset = verified.NsPvtRwset[0].CollectionPvtRwset[:1]
+	block2 := bg.NextBlock([][]byte{pubSimBytes})
+	testutil.AssertNoError(t, ledger.CommitWithPvtData(&lgr.BlockAndPvtData{
+		Block:        block2,
+		BlockPvtData: map[uint64]*lgr.TxPvtData{0: {SeqInBlock: 0, WriteSet: verified}},
+	}), "")
+
+	pvtdataAndBlock, _ = ledger.GetPvtDataAndBlockByNum(2, nil)
+	testutil.AssertNotNil(t, pvtdataAndBlock.BlockPvtData)
+	testutil.AssertEquals(t, pvtdataAndBlock.BlockPvtData[0].Has("ns1", "coll1"), true)
+	testutil.AssertEquals(t, pvtdataAndBlock.BlockPvtData[0].Has("ns1", "coll2"), false)
+
+	// the mismatching pvt data is recorded as missing
+	expectedMissingPvtDataInfo := make(lgr.MissingPvtDataInfo)
+	expectedMissingPvtDataInfo.Add(1, 0, "ns1", "coll1")
+	expectedMissingPvtDataInfo.Add(2, 0, "ns1", "coll2")
+	missingPvtDataInfo, err := ledger.GetMissingPvtDataInfoForMostRecentBlocks(10)
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, missingPvtDataInfo, expectedMissingPvtDataInfo)
+
+	// the pvt data of a collection the committer tells the peer is ineligible for is not expected
+	missingPvtData := make(lgr.TxMissingPvtDataMap)
+	missingPvtData.Add(0, "ns1", "coll2", false)
+	block3 := bg.NextBlock([][]byte{pubSimBytes})
+	testutil.AssertNoError(t, ledger.CommitWithPvtData(&lgr.BlockAndPvtData{
+		Block:          block3,
+		BlockPvtData:   map[uint64]*lgr.TxPvtData{0: {SeqInBlock: 0, WriteSet: verified}},
+		MissingPvtData: missingPvtData,
+	}), "")
+	missingPvtDataInfo, err = ledger.GetMissingPvtDataInfoForMostRecentBlocks(10)
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, missingPvtDataInfo, expectedMissingPvtDataInfo)
+}
+
+func TestKVLedgerPvtDataExpiry(t *testing.T) {
+	env := newTestEnv(t)
+	defer env.cleanup()
+	provider, _ := NewProvider()
+	defer provider.Close()
+
+	bg, gb := testutil.NewBlockGenerator(t, "testLedger", false)
+	ledger, _ := provider.Create(gb)
+	defer ledger.Close()
+
+	commitTx := func(simulate func(simulator lgr.TxSimulator)) {
+		txid := util.GenerateUUID()
+		simulator, _ := ledger.NewTxSimulator(txid)
+		simulate(simulator)
+		simulator.Done()
+		simRes, _ := simulator.GetTxSimulationResults()
+		pubSimBytes, _ := simRes.GetPubSimulationBytes()
+		testutil.AssertNoError(t, ledger.Commit(bg.NextBlockWithTxid([][]byte{pubSimBytes}, []string{txid})), "")
+	}
+	assertPvtData := func(coll, key string, expected []byte) {
+		qe, _ := ledger.NewQueryExecutor()
+		defer qe.Done()
+		value, err := qe.GetPrivateData("ns1", coll, key)
+		testutil.AssertNoError(t, err, "")
+		testutil.AssertEquals(t, value, expected)
+	}
+
+	// block 1 configures coll1 with a block to live of one block, and coll2 without
+	collections := &common.CollectionConfigPackage{}
+	for name, btl := range map[string]uint64{"coll1": 1, "coll2": 0} {
+		collections.Config = append(collections.Config, &common.CollectionConfig{
+			Payload: &common.CollectionConfig_StaticCollectionConfig{
+				StaticCollectionConfig: &common.StaticCollectionConfig{Name: name, BlockToLive: btl},
+			},
+		})
+	}
+	commitTx(func(simulator lgr.TxSimulator) {
+		simulator.SetState("lscc", "ns1~collection", putils.MarshalOrPanic(collections))
+	})
+
+	// block 2 writes the pvt data, block 3 updates key3 of coll1 again
+	commitTx(func(simulator lgr.TxSimulator) {
+		simulator.SetPrivateData("ns1", "coll1", "key1", []byte("value1"))
+		simulator.SetPrivateData("ns1", "coll1", "key3", []byte("value3"))
+		simulator.SetPrivateData("ns1", "coll2", "key2", []byte("value2"))
+	})
+	commitTx(func(simulator lgr.TxSimulator) {
+		simulator.SetPrivateData("ns1", "coll1", "key3", []byte("value3.1"))
+	})
+	assertPvtData("coll1", "key1", []byte("value1"))
+
+	// the pvt data of coll1 committed by block 2 is purged upon the commit of block 4,
+	// except for key3 which was written since
+	commitTx(func(simulator lgr.TxSimulator) {
+		simulator.SetState("ns1", "key4", []byte("value4"))
+	})
+	assertPvtData("coll1", "key1", nil)
+	assertPvtData("coll1", "key3", []byte("value3.1"))
+	assertPvtData("coll2", "key2", []byte("value2"))
+
+	pvtdataAndBlock, _ := ledger.GetPvtDataAndBlockByNum(2, nil)
+	testutil.AssertEquals(t, pvtdataAndBlock.BlockPvtData[0].Has("ns1", "coll1"), false)
+	testutil.AssertEquals(t, pvtdataAndBlock.BlockPvtData[0].Has("ns1", "coll2"), true)
+	pvtdataAndBlock, _ = ledger.GetPvtDataAndBlockByNum(3, nil)
+	testutil.AssertEquals(t, pvtdataAndBlock.BlockPvtData[0].Has("ns1", "coll1"), true)
+}
+
 func TestKVLedgerDBRecovery(t *testing.T) {
 	ledgertestutil.SetupCoreYAMLConfig()
 	env := newTestEnv(t)
@@ -532,6 +827,121 @@ func TestKVLedgerDBRecovery(t *testing.T) {
 	simulator.Done()
 }
 
+func TestKVLedgerRecommitLostBlocks(t *testing.T) {
+	ledgertestutil.SetupCoreYAMLConfig()
+	env := newTestEnv(t)
+	defer env.cleanup()
+	provider, _ := NewProvider()
+	defer provider.Close()
+
+	bg, gb := testutil.NewBlockGenerator(t, "testLedger", false)
+	ledger, _ := provider.Create(gb)
+	defer ledger.Close()
+	simulateBlock := func(value string) *common.Block {
+		simulator, _ := ledger.NewTxSimulator(util.GenerateUUID())
+		simulator.SetState("ns1", "key1", []byte(value))
+		simulator.Done()
+		simRes, _ := simulator.GetTxSimulationResults()
+		pubSimBytes, _ := simRes.GetPubSimulationBytes()
+		return bg.NextBlock([][]byte{pubSimBytes})
+	}
+	assertValue := func(expected string) {
+		qe, _ := ledger.NewQueryExecutor()
+		defer qe.Done()
+		value, err := qe.GetState("ns1", "key1")
+		assert.NoError(t, err)
+		assert.Equal(t, []byte(expected), value)
+	}
+	assert.NoError(t, ledger.Commit(simulateBlock("value1")))
+
+	// the commit of the block fails past the block storage
+	l := ledger.(*kvLedger)
+	block2 := simulateBlock("value2")
+	assert.NoError(t, l.txtmgmt.ValidateAndPrepare(&lgr.BlockAndPvtData{Block: block2}, true))
+	assert.NoError(t, l.blockStore.CommitWithPvtData(&lgr.BlockAndPvtData{Block: block2}))
+	l.txtmgmt.Rollback()
+	assertValue("value1")
+
+	// the lost block is recommitted, and the ledger keeps committing blocks
+	assert.NoError(t, l.RecommitLostBlocks())
+	assertValue("value2")
+	assert.NoError(t, l.RecommitLostBlocks())
+	assert.NoError(t, ledger.Commit(simulateBlock("value3")))
+	assertValue("value3")
+	bcInfo, err := ledger.GetBlockchainInfo()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(4), bcInfo.Height)
+}
+
+func TestKVLedgerConfigHistory(t *testing.T) {
+	env := newTestEnv(t)
+	defer env.cleanup()
+	provider, _ := NewProvider()
+	defer provider.Close()
+
+	bg, gb := testutil.NewBlockGenerator(t, "testLedger", false)
+	ledger, err := provider.Create(gb)
+	assert.NoError(t, err)
+
+	collectionConfigs := func(names ...string) *common.CollectionConfigPackage {
+		collections := &common.CollectionConfigPackage{}
+		for _, name := range names {
+			collections.Config = append(collections.Config, &common.CollectionConfig{
+				Payload: &common.CollectionConfig_StaticCollectionConfig{
+					StaticCollectionConfig: &common.StaticCollectionConfig{Name: name},
+				},
+			})
+		}
+		return collections
+	}
+	nextBlock := func(collections *common.CollectionConfigPackage) *common.Block {
+		simulator, _ := ledger.NewTxSimulator(util.GenerateUUID())
+		simulator.SetState("lscc", "mycc", []byte("chaincode data"))
+		simulator.SetState("lscc", "mycc~collection", putils.MarshalOrPanic(collections))
+		simulator.Done()
+		simRes, _ := simulator.GetTxSimulationResults()
+		pubSimBytes, _ := simRes.GetPubSimulationBytes()
+		return bg.NextBlock([][]byte{pubSimBytes})
+	}
+	assertCollectionConfigAt := func(blockNum uint64, expected *common.CollectionConfigPackage, committingBlockNum uint64) {
+		retriever, err := ledger.GetConfigHistoryRetriever()
+		assert.NoError(t, err)
+		info, err := retriever.CollectionConfigAt(blockNum, "mycc")
+		assert.NoError(t, err)
+		if expected == nil {
+			assert.Nil(t, info)
+			return
+		}
+		assert.True(t, proto.Equal(expected, info.CollectionConfig))
+		assert.Equal(t, committingBlockNum, info.CommittingBlockNum)
+	}
+
+	v1 := collectionConfigs("coll1")
+	assert.NoError(t, ledger.Commit(nextBlock(v1)))
+	assertCollectionConfigAt(0, nil, 0)
+	assertCollectionConfigAt(1, v1, 1)
+
+	// the peer fails after committing the second block to the block storage and the state DB
+	v2 := collectionConfigs("coll1", "coll2")
+	block2 := nextBlock(v2)
+	assert.NoError(t, ledger.(*kvLedger).txtmgmt.ValidateAndPrepare(&lgr.BlockAndPvtData{Block: block2}, true))
+	assert.NoError(t, ledger.(*kvLedger).blockStore.AddBlock(block2))
+	assert.NoError(t, ledger.(*kvLedger).txtmgmt.Commit())
+	assertCollectionConfigAt(2, v1, 1)
+	ledger.Close()
+	provider.Close()
+
+	// the config history DB is recovered when the ledger is opened again
+	provider, _ = NewProvider()
+	defer provider.Close()
+	ledger, err = provider.Open("testLedger")
+	assert.NoError(t, err)
+	defer ledger.Close()
+	assertCollectionConfigAt(1, v1, 1)
+	assertCollectionConfigAt(2, v2, 2)
+	assertCollectionConfigAt(100, v2, 2)
+}
+
 func TestLedgerWithCouchDbEnabledWithBinaryAndJSONData(t *testing.T) {
 
 	//call a helper method to load the core.yaml
@@ -0,0 +1,128 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package kvledger
+
+import (
+	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/rwsetutil"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/txmgr"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/version"
+	"github.com/hyperledger/fabric/protos/utils"
+)
+
+// collectionBTLPolicy implements pvtdatastorage.BTLPolicy on the collection configs
+// kept in the config history of the ledger
+type collectionBTLPolicy struct {
+	configHistory ledger.ConfigHistoryRetriever
+}
+
+// GetBTL implements method in interface pvtdatastorage.BTLPolicy. It returns the block to live
+// of the collection as configured at the given block, zero if the collection is not configured
+func (p *collectionBTLPolicy) GetBTL(ns string, coll string, committingBlk uint64) (uint64, error) {
+	info, err := p.configHistory.CollectionConfigAt(committingBlk, ns)
+	if err != nil || info == nil {
+		return 0, err
+	}
+	for _, config := range info.CollectionConfig.Config {
+		staticConfig := config.GetStaticCollectionConfig()
+		if staticConfig != nil && staticConfig.Name == coll {
+			return staticConfig.BlockToLive, nil
+		}
+	}
+	return 0, nil
+}
+
+// removeExpiredPvtData adds the removal of the pvt data expiring at the given block, and of its hashes,
+// to the updates of the state database prepared for the block. The pvt data store purges the pvt data
+// upon the commit of the block, so the keys are read from it, and the hashes of the keys from the
+// blocks that committed them, beforehand
+func (l *kvLedger) removeExpiredPvtData(blockNum uint64) error {
+	expiring, err := l.blockStore.GetExpiringPvtData(blockNum)
+	if err != nil || len(expiring) == 0 {
+		return err
+	}
+	committingBlocks := make(map[uint64]*ledger.BlockAndPvtData)
+	var expired []*txmgr.ExpiredPvtWrites
+	for _, e := range expiring {
+		blockAndPvtdata, ok := committingBlocks[e.CommittingBlk]
+		if !ok {
+			if blockAndPvtdata, err = l.blockStore.GetPvtDataAndBlockByNum(e.CommittingBlk, nil); err != nil {
+				return err
+			}
+			committingBlocks[e.CommittingBlk] = blockAndPvtdata
+		}
+		writes := &txmgr.ExpiredPvtWrites{
+			Height:     version.NewHeight(e.CommittingBlk, e.TxNum),
+			Namespace:  e.Namespace,
+			Collection: e.Collection,
+		}
+		if e.TxNum < uint64(len(blockAndPvtdata.Block.Data.Data)) {
+			if writes.KeyHashes, err = hashedWrites(blockAndPvtdata.Block.Data.Data[e.TxNum], e.Namespace, e.Collection); err != nil {
+				return err
+			}
+		}
+		if txPvtData := blockAndPvtdata.BlockPvtData[e.TxNum]; txPvtData != nil && txPvtData.WriteSet != nil {
+			if writes.Keys, err = pvtWrites(txPvtData, e.Namespace, e.Collection); err != nil {
+				return err
+			}
+		}
+		expired = append(expired, writes)
+	}
+	logger.Debugf("Channel [%s]: Removing the pvt data of %d collection write set(s) expiring at block [%d]", l.ledgerID, len(expired), blockNum)
+	return l.txtmgmt.RemoveExpiredPvtData(expired)
+}
+
+// hashedWrites returns the hashes of the keys of the collection written by the transaction
+func hashedWrites(envBytes []byte, ns, coll string) ([][]byte, error) {
+	action, err := utils.GetActionFromEnvelope(envBytes)
+	if err != nil {
+		// not an endorser transaction, it has no pvt data
+		return nil, nil
+	}
+	txRWSet := &rwsetutil.TxRwSet{}
+	if err := txRWSet.FromProtoBytes(action.Results); err != nil {
+		return nil, err
+	}
+	var keyHashes [][]byte
+	for _, nsRWSet := range txRWSet.NsRwSets {
+		if nsRWSet.NameSpace != ns {
+			continue
+		}
+		for _, collHashedRWSet := range nsRWSet.CollHashedRwSets {
+			if collHashedRWSet.CollectionName != coll || collHashedRWSet.HashedRwSet == nil {
+				continue
+			}
+			for _, hashedWrite := range collHashedRWSet.HashedRwSet.HashedWrites {
+				keyHashes = append(keyHashes, hashedWrite.KeyHash)
+			}
+		}
+	}
+	return keyHashes, nil
+}
+
+// pvtWrites returns the keys of the collection written by the pvt data of the transaction
+func pvtWrites(txPvtData *ledger.TxPvtData, ns, coll string) ([]string, error) {
+	txPvtRWSet, err := rwsetutil.TxPvtRwSetFromProtoMsg(txPvtData.WriteSet)
+	if err != nil {
+		return nil, err
+	}
+	var keys []string
+	for _, nsPvtRWSet := range txPvtRWSet.NsPvtRwSet {
+		if nsPvtRWSet.NameSpace != ns {
+			continue
+		}
+		for _, collPvtRWSet := range nsPvtRWSet.CollPvtRwSets {
+			if collPvtRWSet.CollectionName != coll || collPvtRWSet.KvRwSet == nil {
+				continue
+			}
+			for _, write := range collPvtRWSet.KvRwSet.Writes {
+				keys = append(keys, write.Key)
+			}
+		}
+	}
+	return keys, nil
+}
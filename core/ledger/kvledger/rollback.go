@@ -0,0 +1,116 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package kvledger
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric/common/ledger/util/leveldbhelper"
+	"github.com/hyperledger/fabric/core/ledger/ledgerconfig"
+)
+
+// ResetAllKVLedgers rolls back all the ledgers of the peer to their genesis blocks, and drops their state
+// and history databases, which are rebuilt from the genesis blocks when the peer is started next.
+// The peer should not be running, an error is returned if the ledgers are found in use
+func ResetAllKVLedgers() error {
+	provider, err := newProviderIfNotInUse()
+	if err != nil {
+		return err
+	}
+	defer provider.Close()
+	ledgerIDs, err := provider.List()
+	if err != nil {
+		return err
+	}
+	for _, ledgerID := range ledgerIDs {
+		if err := provider.rollback(ledgerID, 1); err != nil {
+			return err
+		}
+	}
+	logger.Infof("Reset [%d] ledgers to their genesis blocks", len(ledgerIDs))
+	return nil
+}
+
+// RollbackKVLedger rolls back the ledger with the given id to the given height, i.e. removes the blocks at
+// and above the height along with their pvt data, and drops the state and history databases of the ledger,
+// which are rebuilt from the remaining blocks when the peer is started next. The peer should not be running,
+// an error is returned if the ledgers are found in use
+func RollbackKVLedger(ledgerID string, height uint64) error {
+	provider, err := newProviderIfNotInUse()
+	if err != nil {
+		return err
+	}
+	defer provider.Close()
+	exists, err := provider.Exists(ledgerID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrNonExistingLedgerID
+	}
+	return provider.rollback(ledgerID, height)
+}
+
+// newProviderIfNotInUse returns a new Provider, unless the ledgers are in use by a running peer, which holds
+// the lock of the database of the ledger ids. The database opened to check the lock is the one the Provider
+// uses, so that a peer started after the check cannot open the ledgers before the Provider is closed
+func newProviderIfNotInUse() (*Provider, error) {
+	path := ledgerconfig.GetLedgerProviderPath()
+	db := leveldbhelper.CreateDB(&leveldbhelper.Conf{DBPath: path})
+	if err := db.TryOpen(); err != nil {
+		return nil, fmt.Errorf("Cannot open the ledgers under [%s], they may be in use by a running peer: %s", path, err)
+	}
+	provider, err := newProvider(&idStore{db})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return provider, nil
+}
+
+// rollback rolls back the ledger with the given id, which should not be open, to the given height. The state
+// and history databases of the ledger are dropped before the blocks are removed, so that a rollback interrupted
+// by a crash leaves databases that are rebuilt from the blocks of the ledger, whether or not they were removed
+func (provider *Provider) rollback(ledgerID string, height uint64) error {
+	if height == 0 {
+		return fmt.Errorf("Cannot roll back ledger [%s] below its genesis block", ledgerID)
+	}
+	conf, err := ledgerconfig.GetLedgerConfig(ledgerID)
+	if err != nil {
+		return err
+	}
+	storeProviders := provider.storeProviders(ledgerID, conf)
+	blockStore, err := storeProviders.ledgerStoreProvider.Open(ledgerID)
+	if err != nil {
+		return err
+	}
+	bcInfo, err := blockStore.GetBlockchainInfo()
+	blockStore.Shutdown()
+	if err != nil {
+		return err
+	}
+	if height > bcInfo.Height {
+		return fmt.Errorf("Cannot roll back ledger [%s] to height [%d], which is above its current height [%d]",
+			ledgerID, height, bcInfo.Height)
+	}
+
+	logger.Infof("Rolling back ledger [%s] from height [%d] to height [%d]", ledgerID, bcInfo.Height, height)
+	if err := storeProviders.vdbProvider.Drop(ledgerID); err != nil {
+		return err
+	}
+	if err := storeProviders.historydbProvider.Drop(ledgerID); err != nil {
+		return err
+	}
+	if err := storeProviders.configHistoryProvider.Drop(ledgerID); err != nil {
+		return err
+	}
+	if err := storeProviders.ledgerStoreProvider.Rollback(ledgerID, height); err != nil {
+		return err
+	}
+	logger.Infof("Rolled back ledger [%s] to height [%d]", ledgerID, height)
+	return nil
+}
@@ -0,0 +1,91 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package kvledger
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric/common/ledger/testutil"
+	"github.com/hyperledger/fabric/common/util"
+	lgr "github.com/hyperledger/fabric/core/ledger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRollbackKVLedger(t *testing.T) {
+	env := newTestEnv(t)
+	defer env.cleanup()
+	provider, _ := NewProvider()
+
+	bg, gb := testutil.NewBlockGenerator(t, "testLedger", false)
+	ledger, _ := provider.Create(gb)
+	for _, value := range []string{"value1", "value2", "value3"} {
+		txid := util.GenerateUUID()
+		simulator, _ := ledger.NewTxSimulator(txid)
+		simulator.SetState("ns1", "key1", []byte(value))
+		simulator.SetPrivateData("ns1", "coll1", "key2", []byte(value))
+		simulator.Done()
+		simRes, _ := simulator.GetTxSimulationResults()
+		pubSimBytes, _ := simRes.GetPubSimulationBytes()
+		assert.NoError(t, ledger.Commit(bg.NextBlockWithTxid([][]byte{pubSimBytes}, []string{txid})))
+	}
+
+	// the ledgers cannot be rolled back while they are in use
+	assert.Error(t, RollbackKVLedger("testLedger", 2))
+	ledger.Close()
+	provider.Close()
+
+	assert.Error(t, RollbackKVLedger("testLedger", 0))
+	assert.Error(t, RollbackKVLedger("testLedger", 5))
+	assert.Equal(t, ErrNonExistingLedgerID, RollbackKVLedger("otherLedger", 1))
+	assert.NoError(t, RollbackKVLedger("testLedger", 2))
+
+	// the state and history are rebuilt from the remaining blocks, along with their pvt data
+	provider, _ = NewProvider()
+	ledger, _ = provider.Open("testLedger")
+	checkLedger(t, ledger, 2, []byte("value1"))
+	historyQE, err := ledger.NewHistoryQueryExecutor()
+	assert.NoError(t, err)
+	itr, err := historyQE.GetHistoryForKey("ns1", "key1")
+	assert.NoError(t, err)
+	numModifications := 0
+	for result, _ := itr.Next(); result != nil; result, _ = itr.Next() {
+		numModifications++
+	}
+	itr.Close()
+	assert.Equal(t, 1, numModifications)
+	ledger.Close()
+	provider.Close()
+
+	// the ledgers stay locked from the in-use check until the provider opened by the check is closed
+	lockedProvider, err := newProviderIfNotInUse()
+	assert.NoError(t, err)
+	_, err = newProviderIfNotInUse()
+	assert.Error(t, err)
+	lockedProvider.Close()
+
+	assert.NoError(t, ResetAllKVLedgers())
+	provider, _ = NewProvider()
+	defer provider.Close()
+	ledger, _ = provider.Open("testLedger")
+	defer ledger.Close()
+	checkLedger(t, ledger, 1, nil)
+}
+
+func checkLedger(t *testing.T, ledger lgr.PeerLedger, expectedHeight uint64, expectedValue []byte) {
+	bcInfo, err := ledger.GetBlockchainInfo()
+	assert.NoError(t, err)
+	assert.Equal(t, expectedHeight, bcInfo.Height)
+	qe, err := ledger.NewQueryExecutor()
+	assert.NoError(t, err)
+	defer qe.Done()
+	value, err := qe.GetState("ns1", "key1")
+	assert.NoError(t, err)
+	assert.Equal(t, expectedValue, value)
+	value, err = qe.GetPrivateData("ns1", "coll1", "key2")
+	assert.NoError(t, err)
+	assert.Equal(t, expectedValue, value)
+}
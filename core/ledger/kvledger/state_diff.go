@@ -0,0 +1,203 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package kvledger
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/privacyenabledstate"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/version"
+	"github.com/hyperledger/fabric/core/ledger/ledgerconfig"
+)
+
+// maxStateDiffReads is the number of times the state differences are read before giving up, if blocks
+// keep being committed between the collection of the keys they update and the read of the values
+const maxStateDiffReads = 10
+
+// shouldDeferStateRecovery returns whether the recovery of the state database, which starts at the
+// given block, is deferred to the state differences rather than recommitting the blocks
+func shouldDeferStateRecovery(firstBlockNum uint64, height uint64) bool {
+	minLag := ledgerconfig.GetStateDiffSyncMinLag()
+	return minLag > 0 && height-firstBlockNum >= uint64(minLag)
+}
+
+func (l *kvLedger) isStateDeferred() bool {
+	return atomic.LoadInt32(&l.stateDeferred) == 1
+}
+
+// StateCheckpoint implements method in interface `ledger.StateDiffSyncer`
+func (l *kvLedger) StateCheckpoint() (uint64, error) {
+	savepoint, err := l.txtmgmt.GetLastSavepoint()
+	if err != nil || savepoint == nil {
+		return 0, err
+	}
+	return savepoint.BlockNum + 1, nil
+}
+
+// StateDiffSince implements method in interface `ledger.StateDiffSyncer`. The keys are those the blocks from the
+// checkpoint on update when they are recommitted, and their values are read at once. If blocks are committed in
+// between, the keys they update are added and the values are read again, so that they all are of the returned height
+func (l *kvLedger) StateDiffSince(checkpoint uint64) (uint64, []*ledger.StateDiffEntry, error) {
+	if l.isStateDeferred() {
+		return 0, nil, fmt.Errorf("Channel [%s]: Cannot serve state differences while the recovery of the state database is deferred", l.ledgerID)
+	}
+	updates := privacyenabledstate.NewUpdateBatch()
+	collected := checkpoint
+	for i := 0; i < maxStateDiffReads; i++ {
+		height, err := l.StateCheckpoint()
+		if err != nil {
+			return 0, nil, err
+		}
+		if height <= checkpoint {
+			return 0, nil, fmt.Errorf("Channel [%s]: State database at height [%d] is not beyond checkpoint [%d]", l.ledgerID, height, checkpoint)
+		}
+		if err := l.collectUpdates(updates, collected, height); err != nil {
+			return 0, nil, err
+		}
+		collected = height
+		readHeight, entries, err := l.txtmgmt.ReadStateDiff(updates)
+		if err != nil {
+			return 0, nil, err
+		}
+		if readHeight == collected {
+			logger.Debugf("Channel [%s]: Serving [%d] state differences from height [%d] to [%d]", l.ledgerID, len(entries), checkpoint, readHeight)
+			return readHeight, entries, nil
+		}
+	}
+	return 0, nil, fmt.Errorf("Channel [%s]: Blocks kept being committed while reading the state differences since checkpoint [%d]", l.ledgerID, checkpoint)
+}
+
+// collectUpdates adds the updates the blocks in the range [from, to) make to the state database to the given batch
+func (l *kvLedger) collectUpdates(updates *privacyenabledstate.UpdateBatch, from uint64, to uint64) error {
+	for blockNumber := from; blockNumber < to; blockNumber++ {
+		blockAndPvtdata, err := l.retrieveBlockAndPvtdata(blockNumber)
+		if err != nil {
+			return err
+		}
+		batch, err := l.txtmgmt.PrepareUpdates(blockAndPvtdata)
+		if err != nil {
+			return err
+		}
+		for _, ns := range batch.PubUpdates.GetUpdatedNamespaces() {
+			for key, vv := range batch.PubUpdates.GetUpdates(ns) {
+				updates.PubUpdates.Update(ns, key, vv)
+			}
+		}
+		mergeUpdateMap(updates.HashUpdates.UpdateMap, batch.HashUpdates.UpdateMap)
+		mergeUpdateMap(updates.PvtUpdates.UpdateMap, batch.PvtUpdates.UpdateMap)
+	}
+	return nil
+}
+
+func mergeUpdateMap(to privacyenabledstate.UpdateMap, from privacyenabledstate.UpdateMap) {
+	for ns, nsBatch := range from {
+		for _, coll := range nsBatch.GetCollectionNames() {
+			for key, vv := range nsBatch.GetUpdates(coll) {
+				if vv.Value == nil {
+					to.Delete(ns, coll, key, vv.Version)
+				} else {
+					to.Put(ns, coll, key, vv.Value, vv.Version)
+				}
+			}
+		}
+	}
+}
+
+// CommitValidatedBlock implements method in interface `ledger.StateDiffSyncer`. The pvt data of the collections
+// that is not supplied is retrieved from the transient store, as by `CommitWithPvtData`, and the block is committed
+// to the history and config history databases, whose recovery is not deferred
+func (l *kvLedger) CommitValidatedBlock(pvtdataAndBlock *ledger.BlockAndPvtData) error {
+	block := pvtdataAndBlock.Block
+	if !l.isStateDeferred() {
+		return fmt.Errorf("Channel [%s]: Cannot commit validated block [%d] unless the recovery of the state database is deferred",
+			l.ledgerID, block.Header.Number)
+	}
+	pvtdata, err := retrievePrivateData(l.transientStore, block)
+	if err != nil {
+		return err
+	}
+	for seqInBlock, txPvtData := range pvtdataAndBlock.BlockPvtData {
+		pvtdata[seqInBlock] = mergeTxPvtData(seqInBlock, txPvtData, pvtdata[seqInBlock])
+	}
+	missingPvtData, err := missingPrivateData(block, pvtdata, pvtdataAndBlock.MissingPvtData)
+	if err != nil {
+		return err
+	}
+	if err := l.blockStore.CommitWithPvtData(&ledger.BlockAndPvtData{Block: block, BlockPvtData: pvtdata, MissingPvtData: missingPvtData}); err != nil {
+		return err
+	}
+	logger.Infof("Channel [%s]: Created validated block [%d] with %d transaction(s)", l.ledgerID, block.Header.Number, len(block.Data.Data))
+	if ledgerconfig.IsHistoryDBEnabled() {
+		if err := l.historyDB.Commit(block); err != nil {
+			return fmt.Errorf(`Error during commit to history db:%s`, err)
+		}
+	}
+	if err := l.configHistory.Commit(block); err != nil {
+		return fmt.Errorf(`Error during commit to config history db:%s`, err)
+	}
+	return nil
+}
+
+// ApplyStateDiff implements method in interface `ledger.StateDiffSyncer`
+func (l *kvLedger) ApplyStateDiff(checkpoint uint64, height uint64, entries []*ledger.StateDiffEntry) error {
+	if !l.isStateDeferred() {
+		return fmt.Errorf("Channel [%s]: Cannot apply state differences unless the recovery of the state database is deferred", l.ledgerID)
+	}
+	stateHeight, err := l.StateCheckpoint()
+	if err != nil {
+		return err
+	}
+	if stateHeight != checkpoint {
+		return fmt.Errorf("Channel [%s]: State differences are since checkpoint [%d], the state database is at height [%d]",
+			l.ledgerID, checkpoint, stateHeight)
+	}
+	info, err := l.blockStore.GetBlockchainInfo()
+	if err != nil {
+		return err
+	}
+	if height <= checkpoint || height != info.Height {
+		return fmt.Errorf("Channel [%s]: State differences are up to height [%d], the block store is at height [%d]",
+			l.ledgerID, height, info.Height)
+	}
+	for _, entry := range entries {
+		if err := validateStateDiffEntry(entry, height); err != nil {
+			return fmt.Errorf("Channel [%s]: Invalid state difference: %s", l.ledgerID, err)
+		}
+	}
+	lastBlock, err := l.blockStore.RetrieveBlockByNumber(height - 1)
+	if err != nil {
+		return err
+	}
+	savepoint := version.NewHeight(height-1, uint64(len(lastBlock.Data.Data)-1))
+	if err := l.txtmgmt.ApplyStateDiff(entries, savepoint); err != nil {
+		return err
+	}
+	atomic.StoreInt32(&l.stateDeferred, 0)
+	logger.Infof("Channel [%s]: Applied [%d] state differences from height [%d] to [%d]", l.ledgerID, len(entries), checkpoint, height)
+	return nil
+}
+
+// validateStateDiffEntry returns an error if the entry cannot be written to the state database at the given height
+func validateStateDiffEntry(entry *ledger.StateDiffEntry, height uint64) error {
+	switch {
+	case entry.Namespace == "":
+		return fmt.Errorf("entry has no namespace")
+	case entry.Key == "" && entry.KeyHash == nil:
+		return fmt.Errorf("entry of namespace [%s] has no key", entry.Namespace)
+	case entry.Key != "" && entry.KeyHash != nil:
+		return fmt.Errorf("entry of key [%s:%s] has a key hash as well", entry.Namespace, entry.Key)
+	case entry.Collection == "" && entry.KeyHash != nil:
+		return fmt.Errorf("entry of namespace [%s] has a key hash but no collection", entry.Namespace)
+	case !entry.IsDelete && entry.Value == nil:
+		return fmt.Errorf("entry of key [%s:%s] has no value", entry.Namespace, entry.Key)
+	case entry.BlockNum >= height:
+		return fmt.Errorf("entry of key [%s:%s] is of block [%d], beyond height [%d]", entry.Namespace, entry.Key, entry.BlockNum, height)
+	}
+	return nil
+}
@@ -0,0 +1,191 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package kvledger
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric/common/ledger/testutil"
+	"github.com/hyperledger/fabric/common/util"
+	lgr "github.com/hyperledger/fabric/core/ledger"
+	ledgertestutil "github.com/hyperledger/fabric/core/ledger/testutil"
+	"github.com/hyperledger/fabric/protos/common"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+// simulateStateDiffBlock generates a block of a transaction that sets the given keys of namespace ns1,
+// and deletes those whose value is empty
+func simulateStateDiffBlock(t *testing.T, ledger lgr.PeerLedger, bg *testutil.BlockGenerator, kvs map[string]string) *common.Block {
+	simulator, err := ledger.NewTxSimulator(util.GenerateUUID())
+	assert.NoError(t, err)
+	for key, value := range kvs {
+		if value == "" {
+			assert.NoError(t, simulator.DeleteState("ns1", key))
+		} else {
+			assert.NoError(t, simulator.SetState("ns1", key, []byte(value)))
+		}
+	}
+	simulator.Done()
+	simRes, err := simulator.GetTxSimulationResults()
+	assert.NoError(t, err)
+	pubSimBytes, err := simRes.GetPubSimulationBytes()
+	assert.NoError(t, err)
+	return bg.NextBlock([][]byte{pubSimBytes})
+}
+
+func assertStateDiffValues(t *testing.T, ledger lgr.PeerLedger, expected map[string]string) {
+	qe, err := ledger.NewQueryExecutor()
+	assert.NoError(t, err)
+	defer qe.Done()
+	for key, expectedValue := range expected {
+		value, err := qe.GetState("ns1", key)
+		assert.NoError(t, err)
+		if expectedValue == "" {
+			assert.Nil(t, value, "key %s", key)
+		} else {
+			assert.Equal(t, []byte(expectedValue), value, "key %s", key)
+		}
+	}
+}
+
+// openDeferredLedger creates a ledger that commits the first given block, and only adds the second one to the
+// block storage, and reopens it so that the recovery of its state database is deferred
+func openDeferredLedger(t *testing.T, gb *common.Block, block1 *common.Block, block2 *common.Block) (*Provider, *kvLedger) {
+	provider, err := NewProvider()
+	assert.NoError(t, err)
+	ledger, err := provider.Create(gb)
+	assert.NoError(t, err)
+	assert.NoError(t, ledger.Commit(block1))
+	l := ledger.(*kvLedger)
+	assert.NoError(t, l.txtmgmt.ValidateAndPrepare(&lgr.BlockAndPvtData{Block: block2}, true))
+	assert.NoError(t, l.blockStore.CommitWithPvtData(&lgr.BlockAndPvtData{Block: block2}))
+	l.txtmgmt.Rollback()
+	ledger.Close()
+	provider.Close()
+
+	provider, err = NewProvider()
+	assert.NoError(t, err)
+	ledger, err = provider.Open("testLedger")
+	assert.NoError(t, err)
+	l = ledger.(*kvLedger)
+	assert.True(t, l.isStateDeferred())
+	checkpoint, err := l.StateCheckpoint()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), checkpoint)
+	return provider.(*Provider), l
+}
+
+func TestKVLedgerStateDiffSync(t *testing.T) {
+	ledgertestutil.SetupCoreYAMLConfig()
+	viper.Set("ledger.state.stateDiffSync.minLag", 1)
+	defer viper.Set("ledger.state.stateDiffSync.minLag", 0)
+
+	// the source ledger commits the blocks, and serves the state differences since the first one
+	sourceEnv := createTestEnv(t, "/tmp/fabric/ledgertests/kvledger/statediff/source")
+	provider, err := NewProvider()
+	assert.NoError(t, err)
+	bg, gb := testutil.NewBlockGenerator(t, "testLedger", false)
+	source, err := provider.Create(gb)
+	assert.NoError(t, err)
+	block1 := simulateStateDiffBlock(t, source, bg, map[string]string{"key1": "value1.1", "key2": "value2.1"})
+	assert.NoError(t, source.Commit(block1))
+	block2 := simulateStateDiffBlock(t, source, bg, map[string]string{"key1": "value1.2", "key3": "value3.2"})
+	assert.NoError(t, source.Commit(block2))
+	block3 := simulateStateDiffBlock(t, source, bg, map[string]string{"key2": ""})
+	assert.NoError(t, source.Commit(block3))
+
+	height, entries, err := source.(*kvLedger).StateDiffSince(2)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(4), height)
+	assert.Len(t, entries, 3)
+	for _, entry := range entries {
+		assert.Equal(t, "ns1", entry.Namespace)
+		switch entry.Key {
+		case "key1":
+			assert.Equal(t, []byte("value1.2"), entry.Value)
+			assert.Equal(t, uint64(2), entry.BlockNum)
+		case "key2":
+			assert.True(t, entry.IsDelete)
+			assert.Equal(t, uint64(3), entry.BlockNum)
+		case "key3":
+			assert.Equal(t, []byte("value3.2"), entry.Value)
+		default:
+			t.Fatalf("Unexpected state difference of key %s", entry.Key)
+		}
+	}
+	_, _, err = source.(*kvLedger).StateDiffSince(4)
+	assert.Error(t, err)
+	source.Close()
+	provider.Close()
+	sourceEnv.cleanup()
+
+	// the target ledger defers the recovery of its state database, which is at the first block
+	env := createTestEnv(t, "/tmp/fabric/ledgertests/kvledger/statediff/target")
+	defer env.cleanup()
+	targetProvider, target := openDeferredLedger(t, gb, block1, block2)
+	defer targetProvider.Close()
+	defer target.Close()
+
+	// the ledger commits no blocks, and serves no state differences, until its state database is up to date
+	assert.Error(t, target.Commit(block3))
+	_, _, err = target.StateDiffSince(1)
+	assert.Error(t, err)
+
+	// the state differences are applied once the block store is at their height
+	assert.Error(t, target.ApplyStateDiff(2, height, entries))
+	assert.NoError(t, target.CommitValidatedBlock(&lgr.BlockAndPvtData{Block: block3}))
+	assert.Error(t, target.ApplyStateDiff(3, height, entries))
+	assert.Error(t, target.ApplyStateDiff(2, height, []*lgr.StateDiffEntry{{Namespace: "ns1", Key: "key1"}}))
+	assert.Error(t, target.ApplyStateDiff(2, height, []*lgr.StateDiffEntry{{Namespace: "ns1", Key: "key1", Value: []byte("value"), BlockNum: 4}}))
+	assert.NoError(t, target.ApplyStateDiff(2, height, entries))
+	assert.False(t, target.isStateDeferred())
+	assertStateDiffValues(t, target, map[string]string{"key1": "value1.2", "key2": "", "key3": "value3.2"})
+	checkpoint, err := target.StateCheckpoint()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(4), checkpoint)
+
+	// the ledger commits blocks past the state differences
+	assert.Error(t, target.CommitValidatedBlock(&lgr.BlockAndPvtData{Block: block3}))
+	assert.Error(t, target.ApplyStateDiff(2, height, entries))
+	assert.NoError(t, target.Commit(simulateStateDiffBlock(t, target, bg, map[string]string{"key1": "value1.4"})))
+	assertStateDiffValues(t, target, map[string]string{"key1": "value1.4", "key2": "", "key3": "value3.2"})
+}
+
+func TestKVLedgerRecoverDeferredState(t *testing.T) {
+	ledgertestutil.SetupCoreYAMLConfig()
+	viper.Set("ledger.state.stateDiffSync.minLag", 1)
+	defer viper.Set("ledger.state.stateDiffSync.minLag", 0)
+
+	bg, gb := testutil.NewBlockGenerator(t, "testLedger", false)
+	// the blocks are simulated against a ledger of their own, whose state is that of the deferred one
+	simEnv := createTestEnv(t, "/tmp/fabric/ledgertests/kvledger/statediff/sim")
+	simProvider, err := NewProvider()
+	assert.NoError(t, err)
+	sim, err := simProvider.Create(gb)
+	assert.NoError(t, err)
+	block1 := simulateStateDiffBlock(t, sim, bg, map[string]string{"key1": "value1.1"})
+	assert.NoError(t, sim.Commit(block1))
+	block2 := simulateStateDiffBlock(t, sim, bg, map[string]string{"key1": "value1.2"})
+	sim.Close()
+	simProvider.Close()
+	simEnv.cleanup()
+
+	env := newTestEnv(t)
+	defer env.cleanup()
+	provider, ledger := openDeferredLedger(t, gb, block1, block2)
+	defer provider.Close()
+	defer ledger.Close()
+	assertStateDiffValues(t, ledger, map[string]string{"key1": "value1.1"})
+
+	// recommitting the lost blocks brings the deferred state database up to date
+	assert.NoError(t, ledger.RecommitLostBlocks())
+	assert.False(t, ledger.isStateDeferred())
+	assertStateDiffValues(t, ledger, map[string]string{"key1": "value1.2"})
+	assert.NoError(t, ledger.Commit(simulateStateDiffBlock(t, ledger, bg, map[string]string{"key1": "value1.3"})))
+	assertStateDiffValues(t, ledger, map[string]string{"key1": "value1.3"})
+}
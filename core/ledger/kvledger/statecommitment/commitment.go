@@ -0,0 +1,150 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package statecommitment
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"hash"
+
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/rwsetutil"
+	"github.com/hyperledger/fabric/core/ledger/util"
+	"github.com/hyperledger/fabric/protos/common"
+	putils "github.com/hyperledger/fabric/protos/utils"
+)
+
+// Compute returns the state commitment of a committed block, that is the hash of the validation
+// codes of its transactions and of the public and hashed private state updates applied by its
+// valid transactions. The peers of a channel hold the same blocks, so their commitments of a
+// block differ only if they validated its transactions differently. The commitments are computed
+// from the blocks, so the state databases are not read and may differ unnoticed
+func Compute(block *common.Block) ([]byte, error) {
+	if block == nil || block.Header == nil || block.Data == nil {
+		return nil, fmt.Errorf("the block is incomplete")
+	}
+	w := &writer{h: sha256.New()}
+	w.writeUint64(block.Header.Number)
+
+	var txsFilter util.TxValidationFlags
+	if block.Metadata != nil && len(block.Metadata.Metadata) > int(common.BlockMetadataIndex_TRANSACTIONS_FILTER) {
+		txsFilter = util.TxValidationFlags(block.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER])
+	}
+	if len(txsFilter) == 0 {
+		// the genesis block carries no validation codes
+		txsFilter = util.NewTxValidationFlags(len(block.Data.Data))
+	}
+	if len(txsFilter) != len(block.Data.Data) {
+		return nil, fmt.Errorf("block [%d] has %d validation codes for %d transactions", block.Header.Number, len(txsFilter), len(block.Data.Data))
+	}
+
+	for txNum, envBytes := range block.Data.Data {
+		w.writeUint64(uint64(txsFilter.Flag(txNum)))
+		if txsFilter.IsInvalid(txNum) {
+			continue
+		}
+		txRWSet, err := getTxRWSet(envBytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed extracting the read-write set of transaction [%d] of block [%d]: %s", txNum, block.Header.Number, err)
+		}
+		if txRWSet == nil {
+			w.writeUint64(0)
+			continue
+		}
+		w.writeUint64(uint64(len(txRWSet.NsRwSets)))
+		for _, nsRWSet := range txRWSet.NsRwSets {
+			w.writeString(nsRWSet.NameSpace)
+			kvWrites := nsRWSet.KvRwSet.GetWrites()
+			w.writeUint64(uint64(len(kvWrites)))
+			for _, kvWrite := range kvWrites {
+				w.writeString(kvWrite.Key)
+				w.writeBool(kvWrite.IsDelete)
+				w.writeBytes(kvWrite.Value)
+			}
+			metadataWrites := nsRWSet.KvRwSet.GetMetadataWrites()
+			w.writeUint64(uint64(len(metadataWrites)))
+			for _, metadataWrite := range metadataWrites {
+				w.writeString(metadataWrite.Key)
+				w.writeUint64(uint64(len(metadataWrite.Entries)))
+				for _, entry := range metadataWrite.Entries {
+					w.writeString(entry.Name)
+					w.writeBytes(entry.Value)
+				}
+			}
+			w.writeUint64(uint64(len(nsRWSet.CollHashedRwSets)))
+			for _, collRWSet := range nsRWSet.CollHashedRwSets {
+				w.writeString(collRWSet.CollectionName)
+				hashedWrites := collRWSet.HashedRwSet.GetHashedWrites()
+				w.writeUint64(uint64(len(hashedWrites)))
+				for _, hashedWrite := range hashedWrites {
+					w.writeBytes(hashedWrite.KeyHash)
+					w.writeBool(hashedWrite.IsDelete)
+					w.writeBytes(hashedWrite.ValueHash)
+				}
+			}
+		}
+	}
+	return w.h.Sum(nil), nil
+}
+
+// getTxRWSet returns the read-write set of an endorser transaction, and nil for the other transactions
+func getTxRWSet(envBytes []byte) (*rwsetutil.TxRwSet, error) {
+	env, err := putils.GetEnvelopeFromBlock(envBytes)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := putils.GetPayload(env)
+	if err != nil {
+		return nil, err
+	}
+	chdr, err := putils.UnmarshalChannelHeader(payload.Header.ChannelHeader)
+	if err != nil {
+		return nil, err
+	}
+	if common.HeaderType(chdr.Type) != common.HeaderType_ENDORSER_TRANSACTION {
+		return nil, nil
+	}
+	respPayload, err := putils.GetActionFromEnvelope(envBytes)
+	if err != nil {
+		return nil, err
+	}
+	txRWSet := &rwsetutil.TxRwSet{}
+	if err = txRWSet.FromProtoBytes(respPayload.Results); err != nil {
+		return nil, err
+	}
+	return txRWSet, nil
+}
+
+// writer feeds the fields of the state updates to the hash. The variable length fields are
+// prefixed with their length and the lists with their number of elements, so that distinct
+// updates cannot be encoded alike
+type writer struct {
+	h hash.Hash
+}
+
+func (w *writer) writeUint64(n uint64) {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, n)
+	w.h.Write(b)
+}
+
+func (w *writer) writeBytes(b []byte) {
+	w.writeUint64(uint64(len(b)))
+	w.h.Write(b)
+}
+
+func (w *writer) writeString(s string) {
+	w.writeBytes([]byte(s))
+}
+
+func (w *writer) writeBool(b bool) {
+	if b {
+		w.h.Write([]byte{1})
+		return
+	}
+	w.h.Write([]byte{0})
+}
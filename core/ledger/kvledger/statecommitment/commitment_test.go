@@ -0,0 +1,87 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package statecommitment
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric/common/ledger/testutil"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/rwsetutil"
+	"github.com/hyperledger/fabric/core/ledger/util"
+	"github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/peer"
+	"github.com/stretchr/testify/assert"
+)
+
+func simulationResults(t *testing.T, ns string, kvs ...string) []byte {
+	rwsetBuilder := rwsetutil.NewRWSetBuilder()
+	for i := 0; i+1 < len(kvs); i += 2 {
+		rwsetBuilder.AddToWriteSet(ns, kvs[i], []byte(kvs[i+1]))
+	}
+	simRes, err := rwsetBuilder.GetTxSimulationResults()
+	assert.NoError(t, err)
+	simResBytes, err := simRes.GetPubSimulationBytes()
+	assert.NoError(t, err)
+	return simResBytes
+}
+
+func TestCompute(t *testing.T) {
+	simRes := [][]byte{simulationResults(t, "ns1", "key1", "value1"), simulationResults(t, "ns2", "key2", "value2")}
+	block := testutil.ConstructBlock(t, 5, nil, simRes, false)
+	commitment, err := Compute(block)
+	assert.NoError(t, err)
+	assert.Len(t, commitment, 32)
+
+	// the commitment depends only on the state updates, not on the transaction ids and signatures
+	sameBlock := testutil.ConstructBlock(t, 5, []byte("another previous hash"), simRes, true)
+	sameCommitment, err := Compute(sameBlock)
+	assert.NoError(t, err)
+	assert.Equal(t, commitment, sameCommitment)
+
+	// a different block number
+	otherBlock := testutil.ConstructBlock(t, 6, nil, simRes, false)
+	otherCommitment, err := Compute(otherBlock)
+	assert.NoError(t, err)
+	assert.NotEqual(t, commitment, otherCommitment)
+
+	// different state updates
+	otherBlock = testutil.ConstructBlock(t, 5, nil, [][]byte{simRes[0], simulationResults(t, "ns2", "key2", "value3")}, false)
+	otherCommitment, err = Compute(otherBlock)
+	assert.NoError(t, err)
+	assert.NotEqual(t, commitment, otherCommitment)
+
+	// a transaction validated differently
+	txsFilter := util.NewTxValidationFlags(2)
+	txsFilter.SetFlag(1, peer.TxValidationCode_MVCC_READ_CONFLICT)
+	sameBlock.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER] = txsFilter
+	otherCommitment, err = Compute(sameBlock)
+	assert.NoError(t, err)
+	assert.NotEqual(t, commitment, otherCommitment)
+
+	// the updates of the invalid transactions are not applied
+	otherBlock = testutil.ConstructBlock(t, 5, nil, [][]byte{simRes[0], simulationResults(t, "ns2", "key2", "value3")}, false)
+	otherBlock.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER] = txsFilter
+	sameCommitment, err = Compute(otherBlock)
+	assert.NoError(t, err)
+	assert.Equal(t, otherCommitment, sameCommitment)
+}
+
+func TestComputeErrors(t *testing.T) {
+	_, err := Compute(nil)
+	assert.EqualError(t, err, "the block is incomplete")
+
+	block := testutil.ConstructBlock(t, 1, nil, [][]byte{simulationResults(t, "ns1", "key1", "value1")}, false)
+	block.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER] = util.NewTxValidationFlags(2)
+	_, err = Compute(block)
+	assert.EqualError(t, err, "block [1] has 2 validation codes for 1 transactions")
+
+	block = testutil.ConstructBlock(t, 1, nil, [][]byte{simulationResults(t, "ns1", "key1", "value1")}, false)
+	block.Data.Data[0] = []byte("garbage")
+	_, err = Compute(block)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed extracting the read-write set of transaction [0] of block [1]")
+}
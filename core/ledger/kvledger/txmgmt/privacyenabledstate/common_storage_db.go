@@ -10,7 +10,9 @@ import (
 	"encoding/base64"
 	"fmt"
 
+	"github.com/hyperledger/fabric/common/metrics"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statedb"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statedb/statecache"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statedb/statecouchdb"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statedb/stateleveldb"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/version"
@@ -39,6 +41,9 @@ func NewCommonStorageDBProvider() (DBProvider, error) {
 	} else {
 		vdbProvider = stateleveldb.NewVersionedDBProvider()
 	}
+	if cacheSize := ledgerconfig.GetStateCacheSize(); cacheSize > 0 {
+		vdbProvider = statecache.NewVersionedDBProvider(vdbProvider, cacheSize, metrics.NewRootScope().SubScope("statecache"))
+	}
 	return &CommonStorageDBProvider{vdbProvider}, nil
 }
 
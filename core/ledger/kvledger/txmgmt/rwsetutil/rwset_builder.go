@@ -37,6 +37,7 @@ type nsPubRwBuilder struct {
 	namespace         string
 	readMap           map[string]*kvrwset.KVRead //for mvcc validation
 	writeMap          map[string]*kvrwset.KVWrite
+	metadataWriteMap  map[string]*kvrwset.KVMetadataWrite
 	rangeQueriesMap   map[rangeQueryKey]*kvrwset.RangeQueryInfo //for phantom read validation
 	rangeQueriesKeys  []rangeQueryKey
 	collHashRwBuilder map[string]*collHashRwBuilder
@@ -82,6 +83,13 @@ func (b *RWSetBuilder) AddToWriteSet(ns string, key string, value []byte) {
 	nsPubRwBuilder.writeMap[key] = newKVWrite(key, value)
 }
 
+// AddToMetadataWriteSet adds the metadata entries of a key to the metadata write-set.
+// No entries delete the metadata of the key
+func (b *RWSetBuilder) AddToMetadataWriteSet(ns string, key string, entries []*kvrwset.KVMetadataEntry) {
+	nsPubRwBuilder := b.getOrCreateNsPubRwBuilder(ns)
+	nsPubRwBuilder.metadataWriteMap[key] = &kvrwset.KVMetadataWrite{Key: key, Entries: entries}
+}
+
 // AddToRangeQuerySet adds a range query info for performing phantom read validation
 func (b *RWSetBuilder) AddToRangeQuerySet(ns string, rqi *kvrwset.RangeQueryInfo) {
 	nsPubRwBuilder := b.getOrCreateNsPubRwBuilder(ns)
@@ -114,6 +122,38 @@ func (b *RWSetBuilder) AddToPvtAndHashedWriteSet(ns string, coll string, key str
 	return nil
 }
 
+// GetFromWriteSet returns the value of the key in the write-set and whether the key is present in the write-set.
+// The value is nil if the key is deleted
+func (b *RWSetBuilder) GetFromWriteSet(ns string, key string) ([]byte, bool) {
+	nsPubRwBuilder, ok := b.pubRwBuilderMap[ns]
+	if !ok {
+		return nil, false
+	}
+	kvWrite, ok := nsPubRwBuilder.writeMap[key]
+	if !ok {
+		return nil, false
+	}
+	return kvWrite.Value, true
+}
+
+// GetFromPvtWriteSet returns the value of the key in the private write-set of the collection and whether
+// the key is present in it. The value is nil if the key is deleted
+func (b *RWSetBuilder) GetFromPvtWriteSet(ns string, coll string, key string) ([]byte, bool) {
+	nsPvtRwBuilder, ok := b.pvtRwBuilderMap[ns]
+	if !ok {
+		return nil, false
+	}
+	collPvtRwBuilder, ok := nsPvtRwBuilder.collPvtRwBuilders[coll]
+	if !ok {
+		return nil, false
+	}
+	kvWrite, ok := collPvtRwBuilder.writeMap[key]
+	if !ok {
+		return nil, false
+	}
+	return kvWrite.Value, true
+}
+
 // GetTxSimulationResults returns the proto bytes of public rwset
 // (public data + hashes of private data) and the private rwset for the transaction
 func (b *RWSetBuilder) GetTxSimulationResults() (*ledger.TxSimulationResults, error) {
@@ -183,12 +223,15 @@ func (b *RWSetBuilder) getTxPvtReadWriteSet() *TxPvtRwSet {
 func (b *nsPubRwBuilder) build() *NsRwSet {
 	var readSet []*kvrwset.KVRead
 	var writeSet []*kvrwset.KVWrite
+	var metadataWriteSet []*kvrwset.KVMetadataWrite
 	var rangeQueriesInfo []*kvrwset.RangeQueryInfo
 	var collHashedRwSet []*CollHashedRwSet
 	//add read set
 	util.GetValuesBySortedKeys(&(b.readMap), &readSet)
 	//add write set
 	util.GetValuesBySortedKeys(&(b.writeMap), &writeSet)
+	//add metadata write set
+	util.GetValuesBySortedKeys(&(b.metadataWriteMap), &metadataWriteSet)
 	//add range query info
 	for _, key := range b.rangeQueriesKeys {
 		rangeQueriesInfo = append(rangeQueriesInfo, b.rangeQueriesMap[key])
@@ -201,7 +244,7 @@ func (b *nsPubRwBuilder) build() *NsRwSet {
 	}
 	return &NsRwSet{
 		NameSpace:        b.namespace,
-		KvRwSet:          &kvrwset.KVRWSet{Reads: readSet, Writes: writeSet, RangeQueriesInfo: rangeQueriesInfo},
+		KvRwSet:          &kvrwset.KVRWSet{Reads: readSet, Writes: writeSet, MetadataWrites: metadataWriteSet, RangeQueriesInfo: rangeQueriesInfo},
 		CollHashedRwSets: collHashedRwSet,
 	}
 }
@@ -286,6 +329,7 @@ func newNsPubRwBuilder(namespace string) *nsPubRwBuilder {
 		namespace,
 		make(map[string]*kvrwset.KVRead),
 		make(map[string]*kvrwset.KVWrite),
+		make(map[string]*kvrwset.KVMetadataWrite),
 		make(map[rangeQueryKey]*kvrwset.RangeQueryInfo),
 		nil,
 		make(map[string]*collHashRwBuilder),
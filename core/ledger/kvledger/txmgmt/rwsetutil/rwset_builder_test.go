@@ -91,6 +91,38 @@ func TestTxSimulationResultWithOnlyPubData(t *testing.T) {
 	testutil.AssertNil(t, txSimulationResults.PubSimulationResults.NsRwset[0].CollectionHashedRwset)
 }
 
+func TestTxSimulationResultWithMetadataWrites(t *testing.T) {
+	rwSetBuilder := NewRWSetBuilder()
+	rwSetBuilder.AddToWriteSet("ns1", "key1", []byte("value1"))
+	entries := []*kvrwset.KVMetadataEntry{{Name: "name1", Value: []byte("metadata1")}}
+	rwSetBuilder.AddToMetadataWriteSet("ns1", "key2", entries)
+	rwSetBuilder.AddToMetadataWriteSet("ns1", "key1", entries)
+	// no entries delete the metadata
+	rwSetBuilder.AddToMetadataWriteSet("ns2", "key3", nil)
+
+	txSimulationResults, err := rwSetBuilder.GetTxSimulationResults()
+	testutil.AssertNoError(t, err, "")
+
+	ns1KVRWSet := &kvrwset.KVRWSet{
+		Writes: []*kvrwset.KVWrite{newKVWrite("key1", []byte("value1"))},
+		MetadataWrites: []*kvrwset.KVMetadataWrite{
+			{Key: "key1", Entries: entries},
+			{Key: "key2", Entries: entries},
+		}}
+	ns2KVRWSet := &kvrwset.KVRWSet{
+		MetadataWrites: []*kvrwset.KVMetadataWrite{{Key: "key3"}}}
+
+	expectedTxRWSet := &rwset.TxReadWriteSet{NsRwset: []*rwset.NsReadWriteSet{
+		{Namespace: "ns1", Rwset: serializeTestProtoMsg(t, ns1KVRWSet)},
+		{Namespace: "ns2", Rwset: serializeTestProtoMsg(t, ns2KVRWSet)},
+	}}
+	testutil.AssertEquals(t, txSimulationResults.PubSimulationResults, expectedTxRWSet)
+
+	txRWSet := &TxRwSet{}
+	testutil.AssertNoError(t, txRWSet.FromProtoBytes(serializeTestProtoMsg(t, expectedTxRWSet)), "")
+	testutil.AssertEquals(t, txRWSet.NsRwSets[0].KvRwSet.MetadataWrites, ns1KVRWSet.MetadataWrites)
+}
+
 func TestTxSimulationResultWithPvtData(t *testing.T) {
 	rwSetBuilder := NewRWSetBuilder()
 	// public rws ns1 + ns2
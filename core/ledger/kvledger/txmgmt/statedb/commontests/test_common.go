@@ -17,12 +17,14 @@ limitations under the License.
 package commontests
 
 import (
+	"fmt"
 	"strings"
 	"testing"
 
 	"github.com/hyperledger/fabric/common/ledger/testutil"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statedb"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/version"
+	"github.com/spf13/viper"
 )
 
 // TestGetStateMultipleKeys tests read for given multiple keys
@@ -167,6 +169,42 @@ func TestDeletes(t *testing.T, dbProvider statedb.VersionedDBProvider) {
 	testutil.AssertNil(t, vv)
 }
 
+// TestValueAndMetadataWrites tests the storage of the values along with the metadata of the keys
+func TestValueAndMetadataWrites(t *testing.T, dbProvider statedb.VersionedDBProvider) {
+	db, err := dbProvider.GetDBHandle("testvalueandmetadata")
+	testutil.AssertNoError(t, err, "")
+	batch := statedb.NewUpdateBatch()
+
+	vv1 := statedb.VersionedValue{Value: []byte("value1"), Metadata: []byte("metadata1"), Version: version.NewHeight(1, 1)}
+	vv2 := statedb.VersionedValue{Value: []byte("value2"), Metadata: []byte("metadata2"), Version: version.NewHeight(1, 2)}
+	vv3 := statedb.VersionedValue{Value: []byte("value3"), Version: version.NewHeight(1, 3)}
+	vv4 := statedb.VersionedValue{Value: []byte(`{"color":"blue"}`), Metadata: []byte("metadata4"), Version: version.NewHeight(1, 4)}
+
+	batch.PutValAndMetadata("ns1", "key1", vv1.Value, vv1.Metadata, vv1.Version)
+	batch.PutValAndMetadata("ns1", "key2", vv2.Value, vv2.Metadata, vv2.Version)
+	batch.PutValAndMetadata("ns1", "key3", vv3.Value, vv3.Metadata, vv3.Version)
+	batch.PutValAndMetadata("ns1", "key4", vv4.Value, vv4.Metadata, vv4.Version)
+	db.ApplyUpdates(batch, version.NewHeight(2, 5))
+
+	vv, _ := db.GetState("ns1", "key1")
+	testutil.AssertEquals(t, vv, &vv1)
+	vv, _ = db.GetState("ns1", "key2")
+	testutil.AssertEquals(t, vv, &vv2)
+	vv, _ = db.GetState("ns1", "key3")
+	testutil.AssertEquals(t, vv, &vv3)
+	vv, _ = db.GetState("ns1", "key4")
+	testutil.AssertEquals(t, vv, &vv4)
+
+	itr, err := db.GetStateRangeScanIterator("ns1", "key1", "key3")
+	testutil.AssertNoError(t, err, "")
+	defer itr.Close()
+	for _, expected := range []statedb.VersionedValue{vv1, vv2} {
+		queryResult, err := itr.Next()
+		testutil.AssertNoError(t, err, "")
+		testutil.AssertEquals(t, queryResult.(*statedb.VersionedKV).VersionedValue, expected)
+	}
+}
+
 // TestIterator tests the iterator
 func TestIterator(t *testing.T, dbProvider statedb.VersionedDBProvider) {
 	db, err := dbProvider.GetDBHandle("testiterator")
@@ -197,6 +235,51 @@ func TestIterator(t *testing.T, dbProvider statedb.VersionedDBProvider) {
 	testItr(t, itr4, []string{"key5", "key6"})
 }
 
+// TestRangeScanTotalQueryLimit tests that the range scans with a limit stop at the limit and resume
+// from their bookmark, and that the range scans without a limit ignore the total query limit
+func TestRangeScanTotalQueryLimit(t *testing.T, dbProvider statedb.VersionedDBProvider) {
+	db, err := dbProvider.GetDBHandle("testrangescantotalquerylimit")
+	testutil.AssertNoError(t, err, "")
+	db.Open()
+	defer db.Close()
+	batch := statedb.NewUpdateBatch()
+	for i := 1; i <= 7; i++ {
+		batch.Put("ns1", fmt.Sprintf("key%d", i), []byte(fmt.Sprintf("value%d", i)), version.NewHeight(1, uint64(i)))
+	}
+	db.ApplyUpdates(batch, version.NewHeight(2, 7))
+
+	viper.Set("ledger.state.couchDBConfig.internalQueryLimit", 2)
+	viper.Set("ledger.state.totalQueryLimit", 3)
+	defer viper.Set("ledger.state.couchDBConfig.internalQueryLimit", 1000)
+	defer viper.Set("ledger.state.totalQueryLimit", 10000)
+
+	itr, _ := db.GetStateRangeScanIteratorWithLimit("ns1", "", "", 3)
+	testItr(t, itr, []string{"key1", "key2", "key3"})
+	testutil.AssertEquals(t, itr.GetBookmark(), "key4")
+
+	itr, _ = db.GetStateRangeScanIteratorWithLimit("ns1", "key4", "", 3)
+	testItr(t, itr, []string{"key4", "key5", "key6"})
+	testutil.AssertEquals(t, itr.GetBookmark(), "key7")
+
+	itr, _ = db.GetStateRangeScanIteratorWithLimit("ns1", "key7", "", 3)
+	testItr(t, itr, []string{"key7"})
+	testutil.AssertEquals(t, itr.GetBookmark(), "")
+
+	// the results which end at the limit leave no bookmark
+	itr, _ = db.GetStateRangeScanIteratorWithLimit("ns1", "key5", "", 3)
+	testItr(t, itr, []string{"key5", "key6", "key7"})
+	testutil.AssertEquals(t, itr.GetBookmark(), "")
+
+	itr, _ = db.GetStateRangeScanIteratorWithLimit("ns1", "", "", 0)
+	testItr(t, itr, []string{"key1", "key2", "key3", "key4", "key5", "key6", "key7"})
+	testutil.AssertEquals(t, itr.GetBookmark(), "")
+
+	// the range scans without a limit, like the ones validating range queries, read the whole range
+	itr, _ = db.GetStateRangeScanIterator("ns1", "", "")
+	testItr(t, itr, []string{"key1", "key2", "key3", "key4", "key5", "key6", "key7"})
+	testutil.AssertEquals(t, itr.GetBookmark(), "")
+}
+
 func testItr(t *testing.T, itr statedb.ResultsIterator, expectedKeys []string) {
 	defer itr.Close()
 	for _, expectedKey := range expectedKeys {
@@ -488,3 +571,47 @@ func TestQuery(t *testing.T, dbProvider statedb.VersionedDBProvider) {
 	testutil.AssertNil(t, queryResult2)
 
 }
+
+// TestQueryTotalQueryLimit tests that the queries stop at the total query limit and resume from their bookmark
+func TestQueryTotalQueryLimit(t *testing.T, dbProvider statedb.VersionedDBProvider) {
+	db, err := dbProvider.GetDBHandle("testquerytotalquerylimit")
+	testutil.AssertNoError(t, err, "")
+	db.Open()
+	defer db.Close()
+	batch := statedb.NewUpdateBatch()
+	for i := 1; i <= 5; i++ {
+		batch.Put("ns1", fmt.Sprintf("key%d", i), []byte(fmt.Sprintf(`{"asset_name": "marble%d", "owner": "tom"}`, i)), version.NewHeight(1, uint64(i)))
+	}
+	db.ApplyUpdates(batch, version.NewHeight(2, 5))
+
+	viper.Set("ledger.state.couchDBConfig.internalQueryLimit", 2)
+	viper.Set("ledger.state.totalQueryLimit", 3)
+	defer viper.Set("ledger.state.couchDBConfig.internalQueryLimit", 1000)
+	defer viper.Set("ledger.state.totalQueryLimit", 10000)
+
+	query := `{"selector":{"owner":"tom"}}`
+	itr, err := db.ExecuteQuery("ns1", query)
+	testutil.AssertNoError(t, err, "")
+	testItrCount(t, itr, 3)
+	testutil.AssertEquals(t, itr.GetBookmark(), "3")
+
+	itr, err = db.ExecuteQueryWithBookmark("ns1", query, "3")
+	testutil.AssertNoError(t, err, "")
+	testItrCount(t, itr, 2)
+	testutil.AssertEquals(t, itr.GetBookmark(), "")
+
+	_, err = db.ExecuteQueryWithBookmark("ns1", query, "barf")
+	testutil.AssertError(t, err, "an invalid bookmark should be rejected")
+}
+
+func testItrCount(t *testing.T, itr statedb.ResultsIterator, expectedCount int) {
+	defer itr.Close()
+	for i := 0; i < expectedCount; i++ {
+		queryResult, err := itr.Next()
+		testutil.AssertNoError(t, err, "")
+		testutil.AssertNotNil(t, queryResult)
+	}
+	last, err := itr.Next()
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertNil(t, last)
+}
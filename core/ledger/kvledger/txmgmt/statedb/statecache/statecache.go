@@ -0,0 +1,222 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package statecache
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/common/metrics"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statedb"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/version"
+)
+
+var logger = flogging.MustGetLogger("statecache")
+
+var compositeKeySep = "\x00"
+
+// VersionedDBProvider wraps a VersionedDBProvider, and caches the latest committed states of the
+// most recently read keys of each of its databases in memory. The cached keys are invalidated
+// when the updates of a block are applied to the database
+type VersionedDBProvider struct {
+	statedb.VersionedDBProvider
+	cacheSize int
+	scope     metrics.Scope
+	databases map[string]*VersionedDB
+	mux       sync.Mutex
+}
+
+// NewVersionedDBProvider wraps the given provider with caches of cacheSize keys per database,
+// whose hits and misses are counted in the given metrics scope
+func NewVersionedDBProvider(dbProvider statedb.VersionedDBProvider, cacheSize int, scope metrics.Scope) *VersionedDBProvider {
+	logger.Debugf("constructing state cache VersionedDBProvider cacheSize=%d", cacheSize)
+	return &VersionedDBProvider{
+		VersionedDBProvider: dbProvider,
+		cacheSize:           cacheSize,
+		scope:               scope,
+		databases:           make(map[string]*VersionedDB),
+	}
+}
+
+// GetDBHandle implements method in VersionedDBProvider interface
+func (provider *VersionedDBProvider) GetDBHandle(dbName string) (statedb.VersionedDB, error) {
+	provider.mux.Lock()
+	defer provider.mux.Unlock()
+	if vdb, ok := provider.databases[dbName]; ok {
+		return vdb, nil
+	}
+	db, err := provider.VersionedDBProvider.GetDBHandle(dbName)
+	if err != nil {
+		return nil, err
+	}
+	vdb := newVersionedDB(db, provider.cacheSize, provider.scope.Tagged(map[string]string{"channel": dbName}))
+	provider.databases[dbName] = vdb
+	return vdb, nil
+}
+
+// Drop implements method in VersionedDBProvider interface
+func (provider *VersionedDBProvider) Drop(dbName string) error {
+	provider.mux.Lock()
+	delete(provider.databases, dbName)
+	provider.mux.Unlock()
+	return provider.VersionedDBProvider.Drop(dbName)
+}
+
+// VersionedDB wraps a VersionedDB and serves the point reads of the cached keys from memory
+type VersionedDB struct {
+	statedb.VersionedDB
+	cache  *lruCache
+	hits   metrics.Counter
+	misses metrics.Counter
+	// generation is incremented with each commit, so that the values read
+	// from the database before a commit are not cached after it
+	generation uint64
+	mux        sync.Mutex
+}
+
+func newVersionedDB(db statedb.VersionedDB, cacheSize int, scope metrics.Scope) *VersionedDB {
+	return &VersionedDB{
+		VersionedDB: db,
+		cache:       newLRUCache(cacheSize),
+		hits:        scope.Counter("hits"),
+		misses:      scope.Counter("misses"),
+	}
+}
+
+// GetState implements method in VersionedDB interface
+func (vdb *VersionedDB) GetState(namespace string, key string) (*statedb.VersionedValue, error) {
+	vdb.mux.Lock()
+	vv, ok := vdb.cache.get(cacheKey(namespace, key))
+	generation := vdb.generation
+	vdb.mux.Unlock()
+	if ok {
+		vdb.hits.Inc(1)
+		return vv, nil
+	}
+
+	vdb.misses.Inc(1)
+	vv, err := vdb.VersionedDB.GetState(namespace, key)
+	if err != nil {
+		return nil, err
+	}
+	vdb.putIfNotCommitted(generation, namespace, []string{key}, []*statedb.VersionedValue{vv})
+	return vv, nil
+}
+
+// GetStateMultipleKeys implements method in VersionedDB interface
+func (vdb *VersionedDB) GetStateMultipleKeys(namespace string, keys []string) ([]*statedb.VersionedValue, error) {
+	vals := make([]*statedb.VersionedValue, len(keys))
+	var missedKeys []string
+	var missedIndexes []int
+	vdb.mux.Lock()
+	for i, key := range keys {
+		vv, ok := vdb.cache.get(cacheKey(namespace, key))
+		if !ok {
+			missedKeys = append(missedKeys, key)
+			missedIndexes = append(missedIndexes, i)
+			continue
+		}
+		vals[i] = vv
+	}
+	generation := vdb.generation
+	vdb.mux.Unlock()
+	vdb.hits.Inc(int64(len(keys) - len(missedKeys)))
+	if len(missedKeys) == 0 {
+		return vals, nil
+	}
+
+	vdb.misses.Inc(int64(len(missedKeys)))
+	missedVals, err := vdb.VersionedDB.GetStateMultipleKeys(namespace, missedKeys)
+	if err != nil {
+		return nil, err
+	}
+	for i, vv := range missedVals {
+		vals[missedIndexes[i]] = vv
+	}
+	vdb.putIfNotCommitted(generation, namespace, missedKeys, missedVals)
+	return vals, nil
+}
+
+// ApplyUpdates implements method in VersionedDB interface. The keys updated by the batch
+// are invalidated once the batch is applied to the database
+func (vdb *VersionedDB) ApplyUpdates(batch *statedb.UpdateBatch, height *version.Height) error {
+	err := vdb.VersionedDB.ApplyUpdates(batch, height)
+	vdb.mux.Lock()
+	defer vdb.mux.Unlock()
+	vdb.generation++
+	for _, ns := range batch.GetUpdatedNamespaces() {
+		for key := range batch.GetUpdates(ns) {
+			vdb.cache.remove(cacheKey(ns, key))
+		}
+	}
+	return err
+}
+
+// putIfNotCommitted caches the values read from the database, unless a block was committed since
+// the given generation, in which case they may be stale
+func (vdb *VersionedDB) putIfNotCommitted(generation uint64, namespace string, keys []string, vals []*statedb.VersionedValue) {
+	vdb.mux.Lock()
+	defer vdb.mux.Unlock()
+	if vdb.generation != generation {
+		return
+	}
+	for i, key := range keys {
+		vdb.cache.put(cacheKey(namespace, key), vals[i])
+	}
+}
+
+func cacheKey(namespace, key string) string {
+	return namespace + compositeKeySep + key
+}
+
+// lruCache keeps the values of up to size keys, evicting the least recently used one first.
+// The values of the keys absent from the database are cached as nil
+type lruCache struct {
+	size    int
+	entries map[string]*list.Element
+	lru     *list.List
+}
+
+type cacheEntry struct {
+	key   string
+	value *statedb.VersionedValue
+}
+
+func newLRUCache(size int) *lruCache {
+	return &lruCache{size: size, entries: make(map[string]*list.Element), lru: list.New()}
+}
+
+func (c *lruCache) get(key string) (*statedb.VersionedValue, bool) {
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.lru.MoveToFront(elem)
+	return elem.Value.(*cacheEntry).value, true
+}
+
+func (c *lruCache) put(key string, value *statedb.VersionedValue) {
+	if elem, ok := c.entries[key]; ok {
+		elem.Value.(*cacheEntry).value = value
+		c.lru.MoveToFront(elem)
+		return
+	}
+	c.entries[key] = c.lru.PushFront(&cacheEntry{key, value})
+	if c.lru.Len() > c.size {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+func (c *lruCache) remove(key string) {
+	if elem, ok := c.entries[key]; ok {
+		c.lru.Remove(elem)
+		delete(c.entries, key)
+	}
+}
@@ -0,0 +1,131 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package statecache
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/hyperledger/fabric/common/ledger/util/leveldbhelper"
+	mockmetrics "github.com/hyperledger/fabric/common/mocks/metrics"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statedb"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statedb/stateleveldb"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/version"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestProvider(t *testing.T, cacheSize int) (*VersionedDBProvider, *mockmetrics.Scope, func()) {
+	dbPath, err := ioutil.TempDir("", "statecache")
+	require.NoError(t, err)
+	scope := mockmetrics.NewScope()
+	p := NewVersionedDBProvider(stateleveldb.NewVersionedDBProviderWithConf(&leveldbhelper.Conf{DBPath: dbPath}), cacheSize, scope)
+	return p, scope, func() {
+		p.Close()
+		os.RemoveAll(dbPath)
+	}
+}
+
+func assertHitsAndMisses(t *testing.T, scope *mockmetrics.Scope, hits, misses int64) {
+	tags := map[string]string{"channel": "ledger1"}
+	assert.Equal(t, hits, scope.CounterOf("hits", tags).Value(), "hits")
+	assert.Equal(t, misses, scope.CounterOf("misses", tags).Value(), "misses")
+}
+
+func TestCachedGetState(t *testing.T) {
+	p, scope, cleanup := newTestProvider(t, 10)
+	defer cleanup()
+	db, err := p.GetDBHandle("ledger1")
+	require.NoError(t, err)
+	vv1 := &statedb.VersionedValue{Value: []byte("value1"), Version: version.NewHeight(1, 1)}
+	batch := statedb.NewUpdateBatch()
+	batch.Put("ns", "key1", vv1.Value, vv1.Version)
+	require.NoError(t, db.ApplyUpdates(batch, version.NewHeight(1, 1)))
+
+	// the second reads of the keys are served by the cache, the absent ones included
+	for i := 0; i < 2; i++ {
+		vv, err := db.GetState("ns", "key1")
+		assert.NoError(t, err)
+		assert.Equal(t, vv1, vv)
+		vv, err = db.GetState("ns", "key2")
+		assert.NoError(t, err)
+		assert.Nil(t, vv)
+	}
+	assertHitsAndMisses(t, scope, 2, 2)
+
+	// the keys updated by a block are read again from the database
+	vv2 := &statedb.VersionedValue{Value: []byte("value2"), Version: version.NewHeight(2, 1)}
+	batch = statedb.NewUpdateBatch()
+	batch.Put("ns", "key2", vv2.Value, vv2.Version)
+	batch.Delete("ns", "key1", version.NewHeight(2, 2))
+	require.NoError(t, db.ApplyUpdates(batch, version.NewHeight(2, 2)))
+	vals, err := db.GetStateMultipleKeys("ns", []string{"key1", "key2"})
+	assert.NoError(t, err)
+	assert.Equal(t, []*statedb.VersionedValue{nil, vv2}, vals)
+	assertHitsAndMisses(t, scope, 2, 4)
+	vals, err = db.GetStateMultipleKeys("ns", []string{"key2", "key3", "key1"})
+	assert.NoError(t, err)
+	assert.Equal(t, []*statedb.VersionedValue{vv2, nil, nil}, vals)
+	assertHitsAndMisses(t, scope, 4, 5)
+
+	// the keys of the other namespaces are distinct
+	vv, err := db.GetState("ns2", "key2")
+	assert.NoError(t, err)
+	assert.Nil(t, vv)
+	assertHitsAndMisses(t, scope, 4, 6)
+
+	// and the cache of a database is dropped along with it
+	require.NoError(t, p.Drop("ledger1"))
+	db, err = p.GetDBHandle("ledger1")
+	require.NoError(t, err)
+	vv, err = db.GetState("ns", "key2")
+	assert.NoError(t, err)
+	assert.Nil(t, vv)
+	assertHitsAndMisses(t, scope, 4, 7)
+}
+
+func TestCacheValuesReadBeforeCommit(t *testing.T) {
+	p, _, cleanup := newTestProvider(t, 10)
+	defer cleanup()
+	db, err := p.GetDBHandle("ledger1")
+	require.NoError(t, err)
+	vdb := db.(*VersionedDB)
+
+	// a value read before a commit is not cached after it
+	generation := vdb.generation
+	require.NoError(t, db.ApplyUpdates(statedb.NewUpdateBatch(), version.NewHeight(1, 1)))
+	vdb.putIfNotCommitted(generation, "ns", []string{"key1"}, []*statedb.VersionedValue{{Value: []byte("stale")}})
+	_, ok := vdb.cache.get(cacheKey("ns", "key1"))
+	assert.False(t, ok)
+}
+
+func TestLRUCache(t *testing.T) {
+	c := newLRUCache(2)
+	vv1 := &statedb.VersionedValue{Value: []byte("value1")}
+	vv2 := &statedb.VersionedValue{Value: []byte("value2")}
+	c.put("key1", vv1)
+	c.put("key2", vv2)
+	// key1 is used more recently than key2 and is kept when key3 is cached
+	vv, ok := c.get("key1")
+	assert.True(t, ok)
+	assert.Equal(t, vv1, vv)
+	c.put("key3", nil)
+	_, ok = c.get("key2")
+	assert.False(t, ok)
+	vv, ok = c.get("key3")
+	assert.True(t, ok)
+	assert.Nil(t, vv)
+
+	c.put("key1", vv2)
+	vv, _ = c.get("key1")
+	assert.Equal(t, vv2, vv)
+	c.remove("key1")
+	_, ok = c.get("key1")
+	assert.False(t, ok)
+	assert.Equal(t, 1, c.lru.Len())
+}
@@ -18,9 +18,11 @@ package statecouchdb
 
 import (
 	"bytes"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -40,29 +42,41 @@ var lastKeyIndicator = byte(0x01)
 
 var binaryWrapper = "valueBytes"
 
-//querySkip is implemented for future use by query paging
-//currently defaulted to 0 and is not used
-var querySkip = 0
+var metadataWrapper = "metadata"
+
+const (
+	idField      = "_id"
+	revField     = "_rev"
+	deletedField = "_deleted"
+	// updateConflictError is the error of the documents rejected by a bulk update
+	// because their revision is not the current one
+	updateConflictError = "conflict"
+	// maxCachedRevisionsPerNamespace bounds the revision cache of a namespace, which is
+	// emptied when full
+	maxCachedRevisionsPerNamespace = 10000
+)
 
 // VersionedDBProvider implements interface VersionedDBProvider
 type VersionedDBProvider struct {
-	couchInstance *couchdb.CouchInstance
-	databases     map[string]*VersionedDB
-	mux           sync.Mutex
-	openCounts    uint64
+	couchInstance      *couchdb.CouchInstance
+	databases          map[string]*VersionedDB
+	mux                sync.Mutex
+	openCounts         uint64
+	preserveValueBytes bool
+	maxBatchUpdateSize int
 }
 
 // NewVersionedDBProvider instantiates VersionedDBProvider
 func NewVersionedDBProvider() (*VersionedDBProvider, error) {
 	logger.Debugf("constructing CouchDB VersionedDBProvider")
 	couchDBDef := couchdb.GetCouchDBDefinition()
-	couchInstance, err := couchdb.CreateCouchInstance(couchDBDef.URL, couchDBDef.Username, couchDBDef.Password,
-		couchDBDef.MaxRetries, couchDBDef.MaxRetriesOnStartup, couchDBDef.RequestTimeout)
+	couchInstance, err := couchdb.CreateCouchInstanceFromDefinition(couchDBDef)
 	if err != nil {
 		return nil, err
 	}
 
-	return &VersionedDBProvider{couchInstance, make(map[string]*VersionedDB), sync.Mutex{}, 0}, nil
+	return &VersionedDBProvider{couchInstance, make(map[string]*VersionedDB), sync.Mutex{}, 0,
+		couchDBDef.PreserveValueBytes, couchDBDef.MaxBatchUpdateSize}, nil
 }
 
 // GetDBHandle gets the handle to a named database
@@ -73,7 +87,7 @@ func (provider *VersionedDBProvider) GetDBHandle(dbName string) (statedb.Version
 	vdb := provider.databases[dbName]
 	if vdb == nil {
 		var err error
-		vdb, err = newVersionedDB(provider.couchInstance, dbName)
+		vdb, err = newVersionedDB(provider.couchInstance, dbName, provider.preserveValueBytes, provider.maxBatchUpdateSize)
 		if err != nil {
 			return nil, err
 		}
@@ -82,6 +96,20 @@ func (provider *VersionedDBProvider) GetDBHandle(dbName string) (statedb.Version
 	return vdb, nil
 }
 
+// Drop drops the couch database of a named database
+func (provider *VersionedDBProvider) Drop(dbName string) error {
+	provider.mux.Lock()
+	defer provider.mux.Unlock()
+
+	db, err := couchdb.CreateCouchDatabase(*provider.couchInstance, dbName)
+	if err != nil {
+		return err
+	}
+	delete(provider.databases, dbName)
+	_, err = db.DropDatabase()
+	return err
+}
+
 // Close closes the underlying db instance
 func (provider *VersionedDBProvider) Close() {
 	// No close needed on Couch
@@ -91,16 +119,25 @@ func (provider *VersionedDBProvider) Close() {
 type VersionedDB struct {
 	db     *couchdb.CouchDatabase
 	dbName string
+	// preserveValueBytes stores JSON values as binary attachments too, so that they are
+	// read back unchanged. Such values are not matched by rich queries
+	preserveValueBytes bool
+	// maxBatchUpdateSize is the maximum number of documents saved by a bulk update request
+	maxBatchUpdateSize int
+	// revisionCache keeps the revisions of the documents saved by this instance, by namespace
+	// and key, so that they need not be retrieved when these documents are updated again.
+	// It is only accessed by ApplyUpdates, which is not invoked concurrently
+	revisionCache map[string]map[string]string
 }
 
 // newVersionedDB constructs an instance of VersionedDB
-func newVersionedDB(couchInstance *couchdb.CouchInstance, dbName string) (*VersionedDB, error) {
+func newVersionedDB(couchInstance *couchdb.CouchInstance, dbName string, preserveValueBytes bool, maxBatchUpdateSize int) (*VersionedDB, error) {
 	// CreateCouchDatabase creates a CouchDB database object, as well as the underlying database if it does not exist
 	db, err := couchdb.CreateCouchDatabase(*couchInstance, dbName)
 	if err != nil {
 		return nil, err
 	}
-	return &VersionedDB{db, dbName}, nil
+	return &VersionedDB{db, dbName, preserveValueBytes, maxBatchUpdateSize, make(map[string]map[string]string)}, nil
 }
 
 // Open implements method in VersionedDB interface
@@ -141,13 +178,13 @@ func (vdb *VersionedDB) GetState(namespace string, key string) (*statedb.Version
 		return nil, nil
 	}
 
-	//remove the data wrapper and return the value and version
-	returnValue, returnVersion := removeDataWrapper(couchDoc.JSONValue, couchDoc.Attachments)
+	//remove the data wrapper and return the value, metadata and version
+	returnValue, returnMetadata, returnVersion := removeDataWrapper(couchDoc.JSONValue, couchDoc.Attachments)
 
-	return &statedb.VersionedValue{Value: returnValue, Version: &returnVersion}, nil
+	return &statedb.VersionedValue{Value: returnValue, Metadata: returnMetadata, Version: &returnVersion}, nil
 }
 
-func removeDataWrapper(wrappedValue []byte, attachments []*couchdb.Attachment) ([]byte, version.Height) {
+func removeDataWrapper(wrappedValue []byte, attachments []*couchdb.Attachment) ([]byte, []byte, version.Height) {
 
 	//initialize the return value
 	returnValue := []byte{}
@@ -192,7 +229,13 @@ func removeDataWrapper(wrappedValue []byte, attachments []*couchdb.Attachment) (
 	//create the version based on the blockNum and txNum
 	returnVersion = version.NewHeight(blockNum, txNum)
 
-	return returnValue, *returnVersion
+	//the metadata is kept base64 encoded, as json encodes the bytes
+	var returnMetadata []byte
+	if encodedMetadata, ok := jsonResult[metadataWrapper].(string); ok {
+		returnMetadata, _ = base64.StdEncoding.DecodeString(encodedMetadata)
+	}
+
+	return returnValue, returnMetadata, *returnVersion
 
 }
 
@@ -214,92 +257,97 @@ func (vdb *VersionedDB) GetStateMultipleKeys(namespace string, keys []string) ([
 // GetStateRangeScanIterator implements method in VersionedDB interface
 // startKey is inclusive
 // endKey is exclusive
-func (vdb *VersionedDB) GetStateRangeScanIterator(namespace string, startKey string, endKey string) (statedb.ResultsIterator, error) {
+func (vdb *VersionedDB) GetStateRangeScanIterator(namespace string, startKey string, endKey string) (statedb.QueryResultsIterator, error) {
+	return vdb.GetStateRangeScanIteratorWithLimit(namespace, startKey, endKey, 0)
+}
 
-	//Get the querylimit from core.yaml
-	queryLimit := ledgerconfig.GetQueryLimit()
+// GetStateRangeScanIteratorWithLimit implements method in VersionedDB interface
+func (vdb *VersionedDB) GetStateRangeScanIteratorWithLimit(namespace string, startKey string, endKey string, limit int) (statedb.QueryResultsIterator, error) {
 
 	compositeStartKey := constructCompositeKey(namespace, startKey)
 	compositeEndKey := constructCompositeKey(namespace, endKey)
 	if endKey == "" {
 		compositeEndKey[len(compositeEndKey)-1] = lastKeyIndicator
 	}
-	queryResult, err := vdb.db.ReadDocRange(string(compositeStartKey), string(compositeEndKey), queryLimit, querySkip)
+	internalLimit, err := getInternalQueryLimit()
 	if err != nil {
+		return nil, err
+	}
+	scanner := &kvScanner{
+		db:            vdb.db,
+		namespace:     namespace,
+		nextStartKey:  string(compositeStartKey),
+		endKey:        string(compositeEndKey),
+		internalLimit: internalLimit,
+		totalLimit:    limit,
+	}
+	if err := scanner.fetchNextPage(); err != nil {
 		logger.Debugf("Error calling ReadDocRange(): %s\n", err.Error())
 		return nil, err
 	}
 	logger.Debugf("Exiting GetStateRangeScanIterator")
-	return newKVScanner(namespace, *queryResult), nil
+	return scanner, nil
 
 }
 
 // ExecuteQuery implements method in VersionedDB interface
-func (vdb *VersionedDB) ExecuteQuery(namespace, query string) (statedb.ResultsIterator, error) {
+func (vdb *VersionedDB) ExecuteQuery(namespace, query string) (statedb.QueryResultsIterator, error) {
+	return vdb.ExecuteQueryWithBookmark(namespace, query, "")
+}
 
-	//Get the querylimit from core.yaml
-	queryLimit := ledgerconfig.GetQueryLimit()
+// ExecuteQueryWithBookmark implements method in VersionedDB interface.
+// The bookmark of a query is the number of its results that precede the remaining ones
+func (vdb *VersionedDB) ExecuteQueryWithBookmark(namespace, query, bookmark string) (statedb.QueryResultsIterator, error) {
 
-	queryString, err := ApplyQueryWrapper(namespace, query, queryLimit, 0)
+	internalLimit, err := getInternalQueryLimit()
 	if err != nil {
-		logger.Debugf("Error calling ApplyQueryWrapper(): %s\n", err.Error())
 		return nil, err
 	}
-
-	queryResult, err := vdb.db.QueryDocuments(queryString)
-	if err != nil {
+	skip := 0
+	if bookmark != "" {
+		if skip, err = strconv.Atoi(bookmark); err != nil || skip < 0 {
+			return nil, fmt.Errorf("invalid bookmark [%s]", bookmark)
+		}
+	}
+	scanner := &queryScanner{
+		db:            vdb.db,
+		namespace:     namespace,
+		query:         query,
+		skip:          skip,
+		internalLimit: internalLimit,
+		totalLimit:    ledgerconfig.GetTotalQueryLimit(),
+	}
+	if err := scanner.fetchPage(); err != nil {
 		logger.Debugf("Error calling QueryDocuments(): %s\n", err.Error())
 		return nil, err
 	}
 	logger.Debugf("Exiting ExecuteQuery")
-	return newQueryScanner(*queryResult), nil
+	return scanner, nil
 }
 
-// ApplyUpdates implements method in VersionedDB interface
+// ApplyUpdates implements method in VersionedDB interface. The updates of each namespace are
+// saved by bulk update requests of up to maxBatchUpdateSize documents, with the revisions of
+// the updated documents taken from the revision cache when known, and retrieved in bulk otherwise
 func (vdb *VersionedDB) ApplyUpdates(batch *statedb.UpdateBatch, height *version.Height) error {
 
 	namespaces := batch.GetUpdatedNamespaces()
 	for _, ns := range namespaces {
 		updates := batch.GetUpdates(ns)
-		for k, vv := range updates {
-			compositeKey := constructCompositeKey(ns, k)
-			logger.Debugf("Channel [%s]: Applying key=[%#v]", vdb.dbName, compositeKey)
-
-			//convert nils to deletes
-			if vv.Value == nil {
-
-				vdb.db.DeleteDoc(string(compositeKey), "")
-
-			} else {
-				couchDoc := &couchdb.CouchDoc{}
-
-				//Check to see if the value is a valid JSON
-				//If this is not a valid JSON, then store as an attachment
-				if couchdb.IsJSON(string(vv.Value)) {
-					// Handle it as json
-					couchDoc.JSONValue = addVersionAndChainCodeID(vv.Value, ns, vv.Version)
-				} else { // if the data is not JSON, save as binary attachment in Couch
-
-					attachment := &couchdb.Attachment{}
-					attachment.AttachmentBytes = vv.Value
-					attachment.ContentType = "application/octet-stream"
-					attachment.Name = binaryWrapper
-					attachments := append([]*couchdb.Attachment{}, attachment)
-
-					couchDoc.Attachments = attachments
-					couchDoc.JSONValue = addVersionAndChainCodeID(nil, ns, vv.Version)
-				}
-
-				// SaveDoc using couchdb client and use attachment to persist the binary data
-				rev, err := vdb.db.SaveDoc(string(compositeKey), "", couchDoc)
-				if err != nil {
-					logger.Errorf("Error during Commit(): %s\n", err.Error())
-					return err
-				}
-				if rev != "" {
-					logger.Debugf("Saved document revision number: %s\n", rev)
-				}
+		keys := make([]string, 0, len(updates))
+		for k := range updates {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for len(keys) > 0 {
+			n := len(keys)
+			if vdb.maxBatchUpdateSize > 0 && n > vdb.maxBatchUpdateSize {
+				n = vdb.maxBatchUpdateSize
 			}
+			if err := vdb.applyBatchUpdates(ns, keys[:n], updates); err != nil {
+				logger.Errorf("Error during Commit(): %s\n", err.Error())
+				return err
+			}
+			keys = keys[n:]
 		}
 	}
 
@@ -313,15 +361,162 @@ func (vdb *VersionedDB) ApplyUpdates(batch *statedb.UpdateBatch, height *version
 	return nil
 }
 
-//addVersionAndChainCodeID adds keys for version and chaincodeID to the JSON value
-func addVersionAndChainCodeID(value []byte, chaincodeID string, version *version.Height) []byte {
+// applyBatchUpdates saves the updates of the given keys of a namespace by a single bulk update
+// request, and then saves the documents rejected due to a revision conflict one by one
+func (vdb *VersionedDB) applyBatchUpdates(ns string, keys []string, updates map[string]*statedb.VersionedValue) error {
+	revisions, err := vdb.getRevisions(ns, keys)
+	if err != nil {
+		return err
+	}
+
+	var docs []*couchdb.CouchDoc
+	for _, k := range keys {
+		vv := updates[k]
+		logger.Debugf("Channel [%s]: Applying key=[%#v]", vdb.dbName, constructCompositeKey(ns, k))
+		// a document that does not exist needs not be deleted
+		if vv.Value == nil && revisions[k] == "" {
+			continue
+		}
+		docs = append(docs, vdb.createCouchDoc(ns, k, revisions[k], vv))
+	}
+	if len(docs) == 0 {
+		return nil
+	}
+
+	responses, err := vdb.db.BatchUpdateDocuments(docs)
+	if err != nil {
+		return err
+	}
+	for _, resp := range responses {
+		_, k := splitCompositeKey([]byte(resp.ID))
+		if resp.Ok {
+			vdb.cacheRevision(ns, k, updates[k].Value == nil, resp.Rev)
+			continue
+		}
+		if resp.Error != updateConflictError {
+			return fmt.Errorf("error saving document [%s]: %s (%s)", resp.ID, resp.Error, resp.Reason)
+		}
+		// the cached revision is stale, or the document was updated concurrently
+		logger.Warningf("Channel [%s]: Revision conflict on bulk update of document [%#v], saving it alone", vdb.dbName, resp.ID)
+		delete(vdb.revisionCache[ns], k)
+		if err := vdb.saveDoc(ns, k, updates[k]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// saveDoc saves the update of a single key, retrieving the current revision of its document
+func (vdb *VersionedDB) saveDoc(ns, key string, vv *statedb.VersionedValue) error {
+	id := string(constructCompositeKey(ns, key))
+	if vv.Value == nil {
+		// the deletion of a document that does not exist does not fail
+		return vdb.db.DeleteDoc(id, "")
+	}
+	rev, err := vdb.db.SaveDoc(id, "", vdb.createCouchDoc(ns, key, "", vv))
+	if err != nil {
+		return err
+	}
+	logger.Debugf("Saved document revision number: %s\n", rev)
+	vdb.cacheRevision(ns, key, false, rev)
+	return nil
+}
+
+// getRevisions returns the current revisions of the documents of the given keys, by key.
+// The documents that do not exist have no revision
+func (vdb *VersionedDB) getRevisions(ns string, keys []string) (map[string]string, error) {
+	revisions := make(map[string]string, len(keys))
+	var missedIDs []string
+	cache := vdb.revisionCache[ns]
+	for _, k := range keys {
+		if rev, ok := cache[k]; ok {
+			revisions[k] = rev
+			continue
+		}
+		missedIDs = append(missedIDs, string(constructCompositeKey(ns, k)))
+	}
+	if len(missedIDs) == 0 {
+		return revisions, nil
+	}
+
+	docMetadata, err := vdb.db.BatchRetrieveIDRevision(missedIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, md := range docMetadata {
+		if md.ID == "" || md.Rev == "" {
+			continue
+		}
+		_, k := splitCompositeKey([]byte(md.ID))
+		revisions[k] = md.Rev
+	}
+	return revisions, nil
+}
+
+// cacheRevision records the revision of the document of a key that was just saved, or
+// forgets it if the document was deleted
+func (vdb *VersionedDB) cacheRevision(ns, key string, deleted bool, rev string) {
+	if deleted {
+		delete(vdb.revisionCache[ns], key)
+		return
+	}
+	cache := vdb.revisionCache[ns]
+	if cache == nil || len(cache) >= maxCachedRevisionsPerNamespace {
+		cache = make(map[string]string)
+		vdb.revisionCache[ns] = cache
+	}
+	cache[key] = rev
+}
+
+// createCouchDoc creates the document saving the value of a key at the given revision, if any.
+// A nil value is saved as the deletion of the document
+func (vdb *VersionedDB) createCouchDoc(ns, key, rev string, vv *statedb.VersionedValue) *couchdb.CouchDoc {
+	jsonMap := map[string]interface{}{idField: string(constructCompositeKey(ns, key))}
+	if rev != "" {
+		jsonMap[revField] = rev
+	}
+	if vv.Value == nil {
+		jsonMap[deletedField] = true
+		jsonValue, _ := json.Marshal(jsonMap)
+		return &couchdb.CouchDoc{JSONValue: jsonValue}
+	}
+
+	couchDoc := &couchdb.CouchDoc{}
+
+	//Check to see if the value is a valid JSON
+	//If this is not a valid JSON, or the value bytes are preserved, then store as an attachment
+	if !vdb.preserveValueBytes && couchdb.IsJSON(string(vv.Value)) {
+		// Handle it as json
+		couchDoc.JSONValue = addVersionAndChainCodeID(jsonMap, vv.Value, ns, vv.Metadata, vv.Version)
+	} else { // if the data is not JSON or is to be preserved, save as binary attachment in Couch
+
+		attachment := &couchdb.Attachment{}
+		attachment.AttachmentBytes = vv.Value
+		attachment.ContentType = "application/octet-stream"
+		attachment.Name = binaryWrapper
+		attachments := append([]*couchdb.Attachment{}, attachment)
+
+		couchDoc.Attachments = attachments
+		couchDoc.JSONValue = addVersionAndChainCodeID(jsonMap, nil, ns, vv.Metadata, vv.Version)
+	}
+	return couchDoc
+}
+
+//addVersionAndChainCodeID adds keys for version, chaincodeID and metadata, if any, to the JSON value,
+//along with the given document fields
+func addVersionAndChainCodeID(jsonMap map[string]interface{}, value []byte, chaincodeID string, metadata []byte, version *version.Height) []byte {
 
 	//create a version mapping
-	jsonMap := map[string]interface{}{"version": fmt.Sprintf("%v:%v", version.BlockNum, version.TxNum)}
+	jsonMap["version"] = fmt.Sprintf("%v:%v", version.BlockNum, version.TxNum)
 
 	//add the chaincodeID
 	jsonMap["chaincodeid"] = chaincodeID
 
+	//add the metadata, which json encodes in base64
+	if metadata != nil {
+		jsonMap[metadataWrapper] = metadata
+	}
+
 	//Add the wrapped data if the value is not null
 	if value != nil {
 
@@ -429,21 +624,66 @@ func splitCompositeKey(compositeKey []byte) (string, string) {
 	return string(split[0]), string(split[1])
 }
 
+// getInternalQueryLimit returns the number of records read from CouchDB per page of results
+func getInternalQueryLimit() (int, error) {
+	internalLimit := ledgerconfig.GetInternalQueryLimit()
+	if internalLimit <= 0 {
+		return 0, fmt.Errorf("invalid internal query limit %d, it should be positive", internalLimit)
+	}
+	return internalLimit, nil
+}
+
+// kvScanner reads the results of a range scan from CouchDB by pages of the internal query limit,
+// and returns them up to the total query limit, in which case the key following the last
+// result returned is kept as the bookmark of the scan
 type kvScanner struct {
-	cursor    int
-	namespace string
-	results   []couchdb.QueryResult
+	db            *couchdb.CouchDatabase
+	namespace     string
+	nextStartKey  string
+	endKey        string
+	internalLimit int
+	totalLimit    int
+	results       []couchdb.QueryResult
+	cursor        int
+	numReturned   int
+	bookmark      string
+	exhausted     bool
 }
 
-func newKVScanner(namespace string, queryResults []couchdb.QueryResult) *kvScanner {
-	return &kvScanner{-1, namespace, queryResults}
+// fetchNextPage reads the page of results starting at nextStartKey. One more record than
+// the internal query limit is read, whose key is the start key of the following page
+func (scanner *kvScanner) fetchNextPage() error {
+	queryResult, err := scanner.db.ReadDocRange(scanner.nextStartKey, scanner.endKey, scanner.internalLimit+1, 0)
+	if err != nil {
+		return err
+	}
+	results := *queryResult
+	scanner.nextStartKey = ""
+	if len(results) > scanner.internalLimit {
+		scanner.nextStartKey = results[scanner.internalLimit].ID
+		results = results[:scanner.internalLimit]
+	}
+	scanner.results = results
+	scanner.cursor = -1
+	return nil
 }
 
 func (scanner *kvScanner) Next() (statedb.QueryResult, error) {
 
+	if scanner.exhausted {
+		return nil, nil
+	}
+
+	if scanner.cursor+1 >= len(scanner.results) && scanner.nextStartKey != "" {
+		if err := scanner.fetchNextPage(); err != nil {
+			return nil, err
+		}
+	}
+
 	scanner.cursor++
 
 	if scanner.cursor >= len(scanner.results) {
+		scanner.exhausted = true
 		return nil, nil
 	}
 
@@ -451,47 +691,111 @@ func (scanner *kvScanner) Next() (statedb.QueryResult, error) {
 
 	_, key := splitCompositeKey([]byte(selectedKV.ID))
 
-	//remove the data wrapper and return the value and version
-	returnValue, returnVersion := removeDataWrapper(selectedKV.Value, selectedKV.Attachments)
+	if scanner.totalLimit > 0 && scanner.numReturned >= scanner.totalLimit {
+		logger.Warningf("Range scan on namespace [%s] reached the total query limit of %d results, the remaining results start from key [%s]",
+			scanner.namespace, scanner.totalLimit, key)
+		scanner.bookmark = key
+		scanner.exhausted = true
+		return nil, nil
+	}
+	scanner.numReturned++
+
+	//remove the data wrapper and return the value, metadata and version
+	returnValue, returnMetadata, returnVersion := removeDataWrapper(selectedKV.Value, selectedKV.Attachments)
 
 	return &statedb.VersionedKV{
 		CompositeKey:   statedb.CompositeKey{Namespace: scanner.namespace, Key: key},
-		VersionedValue: statedb.VersionedValue{Value: returnValue, Version: &returnVersion}}, nil
+		VersionedValue: statedb.VersionedValue{Value: returnValue, Metadata: returnMetadata, Version: &returnVersion}}, nil
 }
 
 func (scanner *kvScanner) Close() {
 	scanner = nil
 }
 
+// GetBookmark implements method in QueryResultsIterator interface
+func (scanner *kvScanner) GetBookmark() string {
+	return scanner.bookmark
+}
+
+// queryScanner reads the results of a query from CouchDB by pages of the internal query limit,
+// and returns them up to the total query limit, in which case the number of results preceding
+// the first one not returned is kept as the bookmark of the query
 type queryScanner struct {
-	cursor  int
-	results []couchdb.QueryResult
+	db            *couchdb.CouchDatabase
+	namespace     string
+	query         string
+	skip          int
+	internalLimit int
+	totalLimit    int
+	results       []couchdb.QueryResult
+	cursor        int
+	numReturned   int
+	bookmark      string
+	exhausted     bool
 }
 
-func newQueryScanner(queryResults []couchdb.QueryResult) *queryScanner {
-	return &queryScanner{-1, queryResults}
+// fetchPage reads the page of results following the first skip results of the query
+func (scanner *queryScanner) fetchPage() error {
+	queryString, err := ApplyQueryWrapper(scanner.namespace, scanner.query, scanner.internalLimit, scanner.skip)
+	if err != nil {
+		return err
+	}
+	queryResult, err := scanner.db.QueryDocuments(queryString)
+	if err != nil {
+		return err
+	}
+	scanner.results = *queryResult
+	scanner.cursor = -1
+	return nil
 }
 
 func (scanner *queryScanner) Next() (statedb.QueryResult, error) {
 
+	if scanner.exhausted {
+		return nil, nil
+	}
+
+	// a full page may be followed by another one
+	if scanner.cursor+1 >= len(scanner.results) && len(scanner.results) == scanner.internalLimit {
+		scanner.skip += len(scanner.results)
+		if err := scanner.fetchPage(); err != nil {
+			return nil, err
+		}
+	}
+
 	scanner.cursor++
 
 	if scanner.cursor >= len(scanner.results) {
+		scanner.exhausted = true
 		return nil, nil
 	}
 
+	if scanner.totalLimit > 0 && scanner.numReturned >= scanner.totalLimit {
+		scanner.bookmark = strconv.Itoa(scanner.skip + scanner.cursor)
+		logger.Warningf("Query on namespace [%s] reached the total query limit of %d results, the remaining results follow bookmark [%s]",
+			scanner.namespace, scanner.totalLimit, scanner.bookmark)
+		scanner.exhausted = true
+		return nil, nil
+	}
+	scanner.numReturned++
+
 	selectedResultRecord := scanner.results[scanner.cursor]
 
 	namespace, key := splitCompositeKey([]byte(selectedResultRecord.ID))
 
-	//remove the data wrapper and return the value and version
-	returnValue, returnVersion := removeDataWrapper(selectedResultRecord.Value, selectedResultRecord.Attachments)
+	//remove the data wrapper and return the value, metadata and version
+	returnValue, returnMetadata, returnVersion := removeDataWrapper(selectedResultRecord.Value, selectedResultRecord.Attachments)
 
 	return &statedb.VersionedKV{
 		CompositeKey:   statedb.CompositeKey{Namespace: namespace, Key: key},
-		VersionedValue: statedb.VersionedValue{Value: returnValue, Version: &returnVersion}}, nil
+		VersionedValue: statedb.VersionedValue{Value: returnValue, Metadata: returnMetadata, Version: &returnVersion}}, nil
 }
 
 func (scanner *queryScanner) Close() {
 	scanner = nil
 }
+
+// GetBookmark implements method in QueryResultsIterator interface
+func (scanner *queryScanner) GetBookmark() string {
+	return scanner.bookmark
+}
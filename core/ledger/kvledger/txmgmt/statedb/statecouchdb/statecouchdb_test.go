@@ -17,6 +17,8 @@ limitations under the License.
 package statecouchdb
 
 import (
+	"encoding/json"
+	"fmt"
 	"os"
 	"testing"
 	"time"
@@ -27,6 +29,7 @@ import (
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/version"
 	"github.com/hyperledger/fabric/core/ledger/ledgerconfig"
 	ledgertestutil "github.com/hyperledger/fabric/core/ledger/testutil"
+	"github.com/hyperledger/fabric/core/ledger/util/couchdb"
 	"github.com/spf13/viper"
 )
 
@@ -90,6 +93,15 @@ func TestDeletes(t *testing.T) {
 	}
 }
 
+func TestValueAndMetadataWrites(t *testing.T) {
+	if ledgerconfig.IsCouchDBEnabled() == true {
+		env := NewTestVDBEnv(t)
+		env.Cleanup("testvalueandmetadata")
+		defer env.Cleanup("testvalueandmetadata")
+		commontests.TestValueAndMetadataWrites(t, env.DBProvider)
+	}
+}
+
 func TestIterator(t *testing.T) {
 	if ledgerconfig.IsCouchDBEnabled() == true {
 
@@ -101,6 +113,116 @@ func TestIterator(t *testing.T) {
 	}
 }
 
+func TestPreserveValueBytes(t *testing.T) {
+	if ledgerconfig.IsCouchDBEnabled() == true {
+		viper.Set("ledger.state.couchDBConfig.preserveValueBytes", true)
+		defer viper.Set("ledger.state.couchDBConfig.preserveValueBytes", false)
+		env := NewTestVDBEnv(t)
+		env.Cleanup("testpreservevaluebytes")
+		defer env.Cleanup("testpreservevaluebytes")
+
+		db, err := env.DBProvider.GetDBHandle("testpreservevaluebytes")
+		testutil.AssertNoError(t, err, "")
+		db.Open()
+		defer db.Close()
+		// the keys of the JSON value are not in the order json marshaling would put them in
+		jsonValue := []byte(`{"b":1, "a":{"d":2,"c":3}}`)
+		batch := statedb.NewUpdateBatch()
+		batch.Put("ns1", "key1", jsonValue, version.NewHeight(1, 1))
+		batch.Put("ns1", "key2", []byte("binary"), version.NewHeight(1, 2))
+		testutil.AssertNoError(t, db.ApplyUpdates(batch, version.NewHeight(1, 2)), "")
+
+		vv, err := db.GetState("ns1", "key1")
+		testutil.AssertNoError(t, err, "")
+		testutil.AssertEquals(t, vv.Value, jsonValue)
+		testutil.AssertEquals(t, vv.Version, version.NewHeight(1, 1))
+		vv, err = db.GetState("ns1", "key2")
+		testutil.AssertNoError(t, err, "")
+		testutil.AssertEquals(t, vv.Value, []byte("binary"))
+	}
+}
+
+func TestBatchUpdatesWithRevisionCache(t *testing.T) {
+	if ledgerconfig.IsCouchDBEnabled() == true {
+		viper.Set("ledger.state.couchDBConfig.maxBatchUpdateSize", 2)
+		defer viper.Set("ledger.state.couchDBConfig.maxBatchUpdateSize", 1000)
+		env := NewTestVDBEnv(t)
+		env.Cleanup("testbatchupdates")
+		defer env.Cleanup("testbatchupdates")
+
+		db, err := env.DBProvider.GetDBHandle("testbatchupdates")
+		testutil.AssertNoError(t, err, "")
+		vdb := db.(*VersionedDB)
+		keys := []string{"key1", "key2", "key3", "key4", "key5"}
+		putAll := func(blockNum uint64) {
+			batch := statedb.NewUpdateBatch()
+			for i, key := range keys {
+				batch.Put("ns1", key, []byte(fmt.Sprintf(`{"block":%d}`, blockNum)), version.NewHeight(blockNum, uint64(i)))
+			}
+			testutil.AssertNoError(t, db.ApplyUpdates(batch, version.NewHeight(blockNum, uint64(len(keys)))), "")
+			for i, key := range keys {
+				vv, err := db.GetState("ns1", key)
+				testutil.AssertNoError(t, err, "")
+				testutil.AssertEquals(t, vv.Value, []byte(fmt.Sprintf(`{"block":%d}`, blockNum)))
+				testutil.AssertEquals(t, vv.Version, version.NewHeight(blockNum, uint64(i)))
+			}
+		}
+
+		// the documents are created by several bulk updates, and updated with the cached revisions
+		putAll(1)
+		testutil.AssertEquals(t, len(vdb.revisionCache["ns1"]), len(keys))
+		staleRev := vdb.revisionCache["ns1"]["key3"]
+		putAll(2)
+
+		// a document whose cached revision is stale is saved alone
+		vdb.revisionCache["ns1"]["key3"] = staleRev
+		putAll(3)
+		// and so are the documents whose revision is not cached
+		vdb.revisionCache = make(map[string]map[string]string)
+		putAll(4)
+
+		// the deleted documents are forgotten by the cache, and may be created again
+		batch := statedb.NewUpdateBatch()
+		batch.Delete("ns1", "key1", version.NewHeight(5, 1))
+		batch.Delete("ns1", "key6", version.NewHeight(5, 2))
+		testutil.AssertNoError(t, db.ApplyUpdates(batch, version.NewHeight(5, 2)), "")
+		_, ok := vdb.revisionCache["ns1"]["key1"]
+		testutil.AssertEquals(t, ok, false)
+		vv, err := db.GetState("ns1", "key1")
+		testutil.AssertNoError(t, err, "")
+		testutil.AssertNil(t, vv)
+		putAll(6)
+	}
+}
+
+func TestCreateCouchDoc(t *testing.T) {
+	vdb := &VersionedDB{}
+	docFields := func(doc *couchdb.CouchDoc) map[string]interface{} {
+		fields := make(map[string]interface{})
+		testutil.AssertNoError(t, json.Unmarshal(doc.JSONValue, &fields), "")
+		return fields
+	}
+
+	doc := vdb.createCouchDoc("ns1", "key1", "1-abc", &statedb.VersionedValue{Value: []byte(`{"a":1}`), Version: version.NewHeight(2, 3)})
+	testutil.AssertNil(t, doc.Attachments)
+	testutil.AssertEquals(t, docFields(doc), map[string]interface{}{
+		"_id": "ns1\x00key1", "_rev": "1-abc", "version": "2:3", "chaincodeid": "ns1", "data": map[string]interface{}{"a": float64(1)},
+	})
+
+	// the documents to be created have no revision
+	doc = vdb.createCouchDoc("ns1", "key1", "", &statedb.VersionedValue{Value: []byte("binary"), Version: version.NewHeight(2, 3)})
+	testutil.AssertEquals(t, len(doc.Attachments), 1)
+	testutil.AssertEquals(t, doc.Attachments[0].AttachmentBytes, []byte("binary"))
+	testutil.AssertEquals(t, docFields(doc), map[string]interface{}{
+		"_id": "ns1\x00key1", "version": "2:3", "chaincodeid": "ns1",
+	})
+
+	doc = vdb.createCouchDoc("ns1", "key1", "2-def", &statedb.VersionedValue{Version: version.NewHeight(2, 3)})
+	testutil.AssertEquals(t, docFields(doc), map[string]interface{}{
+		"_id": "ns1\x00key1", "_rev": "2-def", "_deleted": true,
+	})
+}
+
 func TestEncodeDecodeValueAndVersion(t *testing.T) {
 	testValueAndVersionEncoding(t, []byte("value1"), version.NewHeight(1, 2))
 	testValueAndVersionEncoding(t, []byte{}, version.NewHeight(50, 50))
@@ -143,6 +265,24 @@ func TestQuery(t *testing.T) {
 	}
 }
 
+func TestRangeScanTotalQueryLimit(t *testing.T) {
+	if ledgerconfig.IsCouchDBEnabled() == true {
+		env := NewTestVDBEnv(t)
+		env.Cleanup("testrangescantotalquerylimit")
+		defer env.Cleanup("testrangescantotalquerylimit")
+		commontests.TestRangeScanTotalQueryLimit(t, env.DBProvider)
+	}
+}
+
+func TestQueryTotalQueryLimit(t *testing.T) {
+	if ledgerconfig.IsCouchDBEnabled() == true {
+		env := NewTestVDBEnv(t)
+		env.Cleanup("testquerytotalquerylimit")
+		defer env.Cleanup("testquerytotalquerylimit")
+		commontests.TestQueryTotalQueryLimit(t, env.DBProvider)
+	}
+}
+
 func TestGetStateMultipleKeys(t *testing.T) {
 	if ledgerconfig.IsCouchDBEnabled() == true {
 		env := NewTestVDBEnv(t)
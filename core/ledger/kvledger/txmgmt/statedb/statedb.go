@@ -27,6 +27,8 @@ import (
 type VersionedDBProvider interface {
 	// GetDBHandle returns a handle to a VersionedDB
 	GetDBHandle(id string) (VersionedDB, error)
+	// Drop removes all the data of the VersionedDB with the given id, which should not be in use
+	Drop(id string) error
 	// Close closes all the VersionedDB instances and releases any resources held by VersionedDBProvider
 	Close()
 }
@@ -40,10 +42,18 @@ type VersionedDB interface {
 	// GetStateRangeScanIterator returns an iterator that contains all the key-values between given key ranges.
 	// startKey is inclusive
 	// endKey is exclusive
-	// The returned ResultsIterator contains results of type *VersionedKV
-	GetStateRangeScanIterator(namespace string, startKey string, endKey string) (ResultsIterator, error)
+	// The returned ResultsIterator contains results of type *VersionedKV, all the keys in the range
+	// regardless of the total query limit, as the commit-time validation of range queries requires
+	GetStateRangeScanIterator(namespace string, startKey string, endKey string) (QueryResultsIterator, error)
+	// GetStateRangeScanIteratorWithLimit is the same as GetStateRangeScanIterator, except that the returned
+	// iterator stops after limit results. Its bookmark is then the key from which a subsequent range scan
+	// resumes, to be supplied as its startKey. A limit of zero or less lifts the limit
+	GetStateRangeScanIteratorWithLimit(namespace string, startKey string, endKey string, limit int) (QueryResultsIterator, error)
 	// ExecuteQuery executes the given query and returns an iterator that contains results of type *VersionedKV.
-	ExecuteQuery(namespace, query string) (ResultsIterator, error)
+	ExecuteQuery(namespace, query string) (QueryResultsIterator, error)
+	// ExecuteQueryWithBookmark executes the given query from the bookmark returned by a previous
+	// iterator of the same query. An empty bookmark executes the query from its first result
+	ExecuteQueryWithBookmark(namespace, query, bookmark string) (QueryResultsIterator, error)
 	// ApplyUpdates applies the batch to the underlying db.
 	// height is the height of the highest transaction in the Batch that
 	// a state db implementation is expected to ues as a save point
@@ -69,10 +79,12 @@ type CompositeKey struct {
 	Key       string
 }
 
-// VersionedValue encloses value and corresponding version
+// VersionedValue encloses value and corresponding version.
+// Metadata holds the serialized metadata of the key, if any
 type VersionedValue struct {
-	Value   []byte
-	Version *version.Height
+	Value    []byte
+	Metadata []byte
+	Version  *version.Height
 }
 
 // VersionedKV encloses key and corresponding VersionedValue
@@ -87,6 +99,14 @@ type ResultsIterator interface {
 	Close()
 }
 
+// QueryResultsIterator iterates over the results of a range scan or a query on a VersionedDB.
+// It returns at most the limit of results of the scan or the query. Once Next returned nil, GetBookmark
+// returns the paging token from which the remaining results may be queried, or an empty string if there are none
+type QueryResultsIterator interface {
+	ResultsIterator
+	GetBookmark() string
+}
+
 // QueryResult - a general interface for supporting different types of query results. Actual types differ for different queries
 type QueryResult interface{}
 
@@ -123,15 +143,20 @@ func (batch *UpdateBatch) Get(ns string, key string) *VersionedValue {
 
 // Put adds a VersionedKV
 func (batch *UpdateBatch) Put(ns string, key string, value []byte, version *version.Height) {
+	batch.PutValAndMetadata(ns, key, value, nil, version)
+}
+
+// PutValAndMetadata adds a VersionedKV along with the serialized metadata of the key
+func (batch *UpdateBatch) PutValAndMetadata(ns string, key string, value []byte, metadata []byte, version *version.Height) {
 	if value == nil {
 		panic("Nil value not allowed")
 	}
-	batch.Update(ns, key, &VersionedValue{value, version})
+	batch.Update(ns, key, &VersionedValue{value, metadata, version})
 }
 
 // Delete deletes a Key and associated value
 func (batch *UpdateBatch) Delete(ns string, key string, version *version.Height) {
-	batch.Update(ns, key, &VersionedValue{nil, version})
+	batch.Update(ns, key, &VersionedValue{nil, nil, version})
 }
 
 // Exists checks whether the given key exists in the batch
@@ -226,7 +251,7 @@ func (itr *nsIterator) Next() (QueryResult, error) {
 	key := itr.sortedKeys[itr.nextIndex]
 	vv := itr.nsUpdates.m[key]
 	itr.nextIndex++
-	return &VersionedKV{CompositeKey{itr.ns, key}, VersionedValue{vv.Value, vv.Version}}, nil
+	return &VersionedKV{CompositeKey{itr.ns, key}, VersionedValue{vv.Value, vv.Metadata, vv.Version}}, nil
 }
 
 // Close implements the method from QueryResult interface
@@ -41,9 +41,13 @@ type VersionedDBProvider struct {
 
 // NewVersionedDBProvider instantiates VersionedDBProvider
 func NewVersionedDBProvider() *VersionedDBProvider {
-	dbPath := ledgerconfig.GetStateLevelDBPath()
-	logger.Debugf("constructing VersionedDBProvider dbPath=%s", dbPath)
-	dbProvider := leveldbhelper.NewProvider(&leveldbhelper.Conf{DBPath: dbPath})
+	return NewVersionedDBProviderWithConf(&leveldbhelper.Conf{DBPath: ledgerconfig.GetStateLevelDBPath()})
+}
+
+// NewVersionedDBProviderWithConf instantiates VersionedDBProvider on the leveldb with the given configuration
+func NewVersionedDBProviderWithConf(conf *leveldbhelper.Conf) *VersionedDBProvider {
+	logger.Debugf("constructing VersionedDBProvider dbPath=%s", conf.DBPath)
+	dbProvider := leveldbhelper.NewProvider(conf)
 	return &VersionedDBProvider{dbProvider}
 }
 
@@ -52,6 +56,11 @@ func (provider *VersionedDBProvider) GetDBHandle(dbName string) (statedb.Version
 	return newVersionedDB(provider.dbProvider.GetDBHandle(dbName), dbName), nil
 }
 
+// Drop deletes all the keys of a named database
+func (provider *VersionedDBProvider) Drop(dbName string) error {
+	return provider.dbProvider.GetDBHandle(dbName).DeleteAll(true)
+}
+
 // Close closes the underlying db
 func (provider *VersionedDBProvider) Close() {
 	provider.dbProvider.Close()
@@ -100,8 +109,8 @@ func (vdb *versionedDB) GetState(namespace string, key string) (*statedb.Version
 	if dbVal == nil {
 		return nil, nil
 	}
-	val, ver := statedb.DecodeValue(dbVal)
-	return &statedb.VersionedValue{Value: val, Version: ver}, nil
+	val, metadata, ver := statedb.DecodeValueAndMetadata(dbVal)
+	return &statedb.VersionedValue{Value: val, Metadata: metadata, Version: ver}, nil
 }
 
 // GetStateMultipleKeys implements method in VersionedDB interface
@@ -120,18 +129,28 @@ func (vdb *versionedDB) GetStateMultipleKeys(namespace string, keys []string) ([
 // GetStateRangeScanIterator implements method in VersionedDB interface
 // startKey is inclusive
 // endKey is exclusive
-func (vdb *versionedDB) GetStateRangeScanIterator(namespace string, startKey string, endKey string) (statedb.ResultsIterator, error) {
+func (vdb *versionedDB) GetStateRangeScanIterator(namespace string, startKey string, endKey string) (statedb.QueryResultsIterator, error) {
+	return vdb.GetStateRangeScanIteratorWithLimit(namespace, startKey, endKey, 0)
+}
+
+// GetStateRangeScanIteratorWithLimit implements method in VersionedDB interface
+func (vdb *versionedDB) GetStateRangeScanIteratorWithLimit(namespace string, startKey string, endKey string, limit int) (statedb.QueryResultsIterator, error) {
 	compositeStartKey := constructCompositeKey(namespace, startKey)
 	compositeEndKey := constructCompositeKey(namespace, endKey)
 	if endKey == "" {
 		compositeEndKey[len(compositeEndKey)-1] = lastKeyIndicator
 	}
 	dbItr := vdb.db.GetIterator(compositeStartKey, compositeEndKey)
-	return newKVScanner(namespace, dbItr), nil
+	return newKVScanner(namespace, dbItr, limit), nil
 }
 
 // ExecuteQuery implements method in VersionedDB interface
-func (vdb *versionedDB) ExecuteQuery(namespace, query string) (statedb.ResultsIterator, error) {
+func (vdb *versionedDB) ExecuteQuery(namespace, query string) (statedb.QueryResultsIterator, error) {
+	return nil, errors.New("ExecuteQuery not supported for leveldb")
+}
+
+// ExecuteQueryWithBookmark implements method in VersionedDB interface
+func (vdb *versionedDB) ExecuteQueryWithBookmark(namespace, query, bookmark string) (statedb.QueryResultsIterator, error) {
 	return nil, errors.New("ExecuteQuery not supported for leveldb")
 }
 
@@ -148,7 +167,7 @@ func (vdb *versionedDB) ApplyUpdates(batch *statedb.UpdateBatch, height *version
 			if vv.Value == nil {
 				dbBatch.Delete(compositeKey)
 			} else {
-				dbBatch.Put(compositeKey, statedb.EncodeValue(vv.Value, vv.Version))
+				dbBatch.Put(compositeKey, statedb.EncodeValueAndMetadata(vv.Value, vv.Metadata, vv.Version))
 			}
 		}
 	}
@@ -181,30 +200,53 @@ func splitCompositeKey(compositeKey []byte) (string, string) {
 	return string(split[0]), string(split[1])
 }
 
+// kvScanner returns the results of a range scan up to the total query limit, in which case
+// the key following the last result returned is kept as the bookmark of the scan
 type kvScanner struct {
-	namespace string
-	dbItr     iterator.Iterator
+	namespace   string
+	dbItr       iterator.Iterator
+	totalLimit  int
+	numReturned int
+	bookmark    string
+	exhausted   bool
 }
 
-func newKVScanner(namespace string, dbItr iterator.Iterator) *kvScanner {
-	return &kvScanner{namespace, dbItr}
+func newKVScanner(namespace string, dbItr iterator.Iterator, totalLimit int) *kvScanner {
+	return &kvScanner{namespace: namespace, dbItr: dbItr, totalLimit: totalLimit}
 }
 
 func (scanner *kvScanner) Next() (statedb.QueryResult, error) {
+	if scanner.exhausted {
+		return nil, nil
+	}
 	if !scanner.dbItr.Next() {
+		scanner.exhausted = true
 		return nil, nil
 	}
 	dbKey := scanner.dbItr.Key()
+	_, key := splitCompositeKey(dbKey)
+	if scanner.totalLimit > 0 && scanner.numReturned >= scanner.totalLimit {
+		logger.Warningf("Range scan on namespace [%s] reached the total query limit of %d results, the remaining results start from key [%s]",
+			scanner.namespace, scanner.totalLimit, key)
+		scanner.bookmark = key
+		scanner.exhausted = true
+		return nil, nil
+	}
+	scanner.numReturned++
 	dbVal := scanner.dbItr.Value()
 	dbValCopy := make([]byte, len(dbVal))
 	copy(dbValCopy, dbVal)
-	_, key := splitCompositeKey(dbKey)
-	value, version := statedb.DecodeValue(dbValCopy)
+	value, metadata, version := statedb.DecodeValueAndMetadata(dbValCopy)
 	return &statedb.VersionedKV{
 		CompositeKey:   statedb.CompositeKey{Namespace: scanner.namespace, Key: key},
-		VersionedValue: statedb.VersionedValue{Value: value, Version: version}}, nil
+		VersionedValue: statedb.VersionedValue{Value: value, Metadata: metadata, Version: version}}, nil
 }
 
 func (scanner *kvScanner) Close() {
 	scanner.dbItr.Release()
 }
+
+// GetBookmark implements method in QueryResultsIterator interface
+func (scanner *kvScanner) GetBookmark() string {
+	return scanner.bookmark
+}
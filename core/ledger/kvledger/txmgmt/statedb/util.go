@@ -16,7 +16,15 @@ limitations under the License.
 
 package statedb
 
-import "github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/version"
+import (
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/version"
+)
+
+// metadataEncodingMarker starts the encoded values that carry metadata. The values without
+// metadata keep the encoding of EncodeValue, whose first byte is the size of the block number
+// of the version and is never above 8
+const metadataEncodingMarker = byte(0xff)
 
 //EncodeValue appends the value to the version, allows storage of version and value in binary form
 func EncodeValue(value []byte, version *version.Height) []byte {
@@ -33,3 +41,28 @@ func DecodeValue(encodedValue []byte) ([]byte, *version.Height) {
 	value := encodedValue[n:]
 	return value, height
 }
+
+//EncodeValueAndMetadata is EncodeValue for a value that may carry metadata. Without metadata, the encoding
+//is the same as that of EncodeValue, otherwise the length prefixed metadata is put in front of it
+func EncodeValueAndMetadata(value []byte, metadata []byte, version *version.Height) []byte {
+	if metadata == nil {
+		return EncodeValue(value, version)
+	}
+	encodedValue := append([]byte{metadataEncodingMarker}, proto.EncodeVarint(uint64(len(metadata)))...)
+	encodedValue = append(encodedValue, metadata...)
+	return append(encodedValue, EncodeValue(value, version)...)
+}
+
+//DecodeValueAndMetadata separates the version, value and metadata from a binary value produced by
+//EncodeValueAndMetadata or EncodeValue
+func DecodeValueAndMetadata(encodedValue []byte) ([]byte, []byte, *version.Height) {
+	if len(encodedValue) == 0 || encodedValue[0] != metadataEncodingMarker {
+		value, height := DecodeValue(encodedValue)
+		return value, nil, height
+	}
+	metadataLen, n := proto.DecodeVarint(encodedValue[1:])
+	metadataStart := 1 + n
+	metadataEnd := metadataStart + int(metadataLen)
+	value, height := DecodeValue(encodedValue[metadataEnd:])
+	return value, encodedValue[metadataStart:metadataEnd], height
+}
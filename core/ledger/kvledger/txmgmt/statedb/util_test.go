@@ -52,3 +52,30 @@ func TestEncodeDecodeJSON(t *testing.T) {
 	testutil.AssertEquals(t, decodedVersion, version2)
 
 }
+
+// TestEncodeDecodeValueAndMetadata tests encoding and decoding a value with and without metadata
+func TestEncodeDecodeValueAndMetadata(t *testing.T) {
+	value := []byte("value1")
+	metadata := []byte("metadata1")
+	version1 := version.NewHeight(1, 1)
+
+	encodedValue := EncodeValueAndMetadata(value, metadata, version1)
+	decodedValue, decodedMetadata, decodedVersion := DecodeValueAndMetadata(encodedValue)
+	testutil.AssertEquals(t, decodedValue, value)
+	testutil.AssertEquals(t, decodedMetadata, metadata)
+	testutil.AssertEquals(t, decodedVersion, version1)
+
+	// Without metadata, the encoding is the one of EncodeValue, so that
+	// the values stored before the metadata support decode as well
+	encodedValue = EncodeValueAndMetadata(value, nil, version1)
+	testutil.AssertEquals(t, encodedValue, EncodeValue(value, version1))
+	decodedValue, decodedMetadata, decodedVersion = DecodeValueAndMetadata(encodedValue)
+	testutil.AssertEquals(t, decodedValue, value)
+	testutil.AssertNil(t, decodedMetadata)
+	testutil.AssertEquals(t, decodedVersion, version1)
+
+	decodedValue, decodedMetadata, decodedVersion = DecodeValueAndMetadata(EncodeValue(value, version.NewHeight(0, 0)))
+	testutil.AssertEquals(t, decodedValue, value)
+	testutil.AssertNil(t, decodedMetadata)
+	testutil.AssertEquals(t, decodedVersion, version.NewHeight(0, 0))
+}
@@ -0,0 +1,61 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package statemetadata
+
+import (
+	"sort"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/protos/ledger/rwset/kvrwset"
+)
+
+// ToEntries converts the metadata of a key into the entries of a metadata write, sorted by name
+func ToEntries(metadata map[string][]byte) []*kvrwset.KVMetadataEntry {
+	if len(metadata) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(metadata))
+	for name := range metadata {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	entries := make([]*kvrwset.KVMetadataEntry, len(names))
+	for i, name := range names {
+		entries[i] = &kvrwset.KVMetadataEntry{Name: name, Value: metadata[name]}
+	}
+	return entries
+}
+
+// SerializeEntries serializes the metadata entries of a key for the storage in the state db.
+// No entries serialize to nil, i.e. the key carries no metadata
+func SerializeEntries(entries []*kvrwset.KVMetadataEntry) ([]byte, error) {
+	if len(entries) == 0 {
+		return nil, nil
+	}
+	return proto.Marshal(&kvrwset.KVMetadataWrite{Entries: entries})
+}
+
+// Serialize serializes the metadata of a key for the storage in the state db
+func Serialize(metadata map[string][]byte) ([]byte, error) {
+	return SerializeEntries(ToEntries(metadata))
+}
+
+// Deserialize returns the metadata of a key from the bytes stored in the state db
+func Deserialize(metadataBytes []byte) (map[string][]byte, error) {
+	if metadataBytes == nil {
+		return nil, nil
+	}
+	metadataWrite := &kvrwset.KVMetadataWrite{}
+	if err := proto.Unmarshal(metadataBytes, metadataWrite); err != nil {
+		return nil, err
+	}
+	metadata := make(map[string][]byte, len(metadataWrite.Entries))
+	for _, entry := range metadataWrite.Entries {
+		metadata[entry.Name] = entry.Value
+	}
+	return metadata, nil
+}
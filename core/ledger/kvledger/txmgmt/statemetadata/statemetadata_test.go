@@ -0,0 +1,49 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package statemetadata
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric/protos/ledger/rwset/kvrwset"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSerializeDeserialize(t *testing.T) {
+	metadata := map[string][]byte{"name2": []byte("value2"), "name1": []byte("value1")}
+	entries := ToEntries(metadata)
+	assert.Equal(t, []*kvrwset.KVMetadataEntry{
+		{Name: "name1", Value: []byte("value1")},
+		{Name: "name2", Value: []byte("value2")},
+	}, entries)
+
+	metadataBytes, err := Serialize(metadata)
+	assert.NoError(t, err)
+	entriesBytes, err := SerializeEntries(entries)
+	assert.NoError(t, err)
+	assert.Equal(t, entriesBytes, metadataBytes)
+
+	deserialized, err := Deserialize(metadataBytes)
+	assert.NoError(t, err)
+	assert.Equal(t, metadata, deserialized)
+}
+
+func TestSerializeNoMetadata(t *testing.T) {
+	metadataBytes, err := Serialize(nil)
+	assert.NoError(t, err)
+	assert.Nil(t, metadataBytes)
+	metadataBytes, err = Serialize(map[string][]byte{})
+	assert.NoError(t, err)
+	assert.Nil(t, metadataBytes)
+
+	metadata, err := Deserialize(nil)
+	assert.NoError(t, err)
+	assert.Nil(t, metadata)
+
+	_, err = Deserialize([]byte("not a metadata write"))
+	assert.Error(t, err)
+}
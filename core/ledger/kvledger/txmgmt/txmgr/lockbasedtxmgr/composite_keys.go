@@ -0,0 +1,121 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package lockbasedtxmgr
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	commonledger "github.com/hyperledger/fabric/common/ledger"
+	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/protos/ledger/queryresult"
+)
+
+// The composite keys are built as the chaincode shim builds them, that is
+// compositeKeyNamespace + objectType + separator + (attribute + separator)*
+const (
+	compositeKeyNamespace = "\x00"
+	compositeKeySeparator = "\x00"
+	maxUnicodeRuneValue   = utf8.MaxRune
+)
+
+// validateCompositeKeyFormat returns an error if the key contains the null character without being
+// a well formed composite key, so that the keys written outside of the composite key helpers cannot
+// collide with the composite keys of an object type
+func validateCompositeKeyFormat(key string) error {
+	if !strings.Contains(key, compositeKeySeparator) {
+		return nil
+	}
+	if len(key) < 2 || !strings.HasPrefix(key, compositeKeyNamespace) || !strings.HasSuffix(key, compositeKeySeparator) {
+		return fmt.Errorf("key [%q] contains the null character, which is reserved to the separators of the composite keys", key)
+	}
+	if strings.ContainsRune(key, maxUnicodeRuneValue) {
+		return fmt.Errorf("composite key [%q] contains %#U, which is not allowed in the attributes of a composite key", key, maxUnicodeRuneValue)
+	}
+	return nil
+}
+
+// createPartialCompositeKey builds the prefix shared by the composite keys of the given object type and attributes
+func createPartialCompositeKey(objectType string, attributes []string) (string, error) {
+	key := compositeKeyNamespace
+	for _, component := range append([]string{objectType}, attributes...) {
+		if !utf8.ValidString(component) {
+			return "", fmt.Errorf("not a valid utf8 string: [%x]", component)
+		}
+		if strings.Contains(component, compositeKeySeparator) || strings.ContainsRune(component, maxUnicodeRuneValue) {
+			return "", fmt.Errorf("input [%q] contains %#U or %#U, which are not allowed in the attributes of a composite key",
+				component, 0, maxUnicodeRuneValue)
+		}
+		key += component + compositeKeySeparator
+	}
+	return key, nil
+}
+
+func (h *queryHelper) getStateByPartialCompositeKeyWithPagination(namespace, objectType string, attributes []string,
+	pageSize int32, bookmark string) (ledger.QueryResultsIterator, error) {
+	if pageSize <= 0 {
+		return nil, errors.New("the page size must be positive")
+	}
+	partialKey, err := createPartialCompositeKey(objectType, attributes)
+	if err != nil {
+		return nil, err
+	}
+	startKey := partialKey
+	if bookmark != "" {
+		if !strings.HasPrefix(bookmark, partialKey) {
+			return nil, fmt.Errorf("invalid bookmark [%q] for the partial composite key [%q]", bookmark, partialKey)
+		}
+		startKey = bookmark
+	}
+	itr, err := h.getStateRangeScanIterator(namespace, startKey, partialKey+string(maxUnicodeRuneValue))
+	if err != nil {
+		return nil, err
+	}
+	return &paginatedResultsItr{resultsItr: itr.(*resultsItr), pageSize: pageSize}, nil
+}
+
+// paginatedResultsItr returns up to pageSize results of a range scan. The bookmark of the next page
+// is the key that follows the page, which is read and recorded in the range query info, if any
+type paginatedResultsItr struct {
+	*resultsItr
+	pageSize    int32
+	numReturned int32
+	bookmark    string
+	exhausted   bool
+}
+
+// Next implements method in interface ledger.ResultsIterator
+func (itr *paginatedResultsItr) Next() (commonledger.QueryResult, error) {
+	if itr.exhausted {
+		return nil, nil
+	}
+	queryResult, err := itr.resultsItr.Next()
+	if err != nil {
+		return nil, err
+	}
+	if queryResult == nil {
+		itr.exhausted = true
+		// the scan may have stopped at the total query limit
+		itr.bookmark = itr.dbItr.GetBookmark()
+		return nil, nil
+	}
+	if itr.numReturned == itr.pageSize {
+		itr.exhausted = true
+		itr.bookmark = queryResult.(*queryresult.KV).Key
+		return nil, nil
+	}
+	itr.numReturned++
+	return queryResult, nil
+}
+
+// GetBookmarkAndClose implements method in interface ledger.QueryResultsIterator
+func (itr *paginatedResultsItr) GetBookmarkAndClose() string {
+	itr.Close()
+	return itr.bookmark
+}
@@ -18,27 +18,47 @@ package lockbasedtxmgr
 
 import (
 	"errors"
+	"fmt"
 
 	commonledger "github.com/hyperledger/fabric/common/ledger"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/rwsetutil"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statedb"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statemetadata"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/version"
 	"github.com/hyperledger/fabric/core/ledger/ledgerconfig"
 	"github.com/hyperledger/fabric/protos/ledger/queryresult"
 	"github.com/hyperledger/fabric/protos/ledger/rwset/kvrwset"
 )
 
+// stateReader serves the point reads of a queryHelper
+type stateReader interface {
+	GetState(namespace string, key string) (*statedb.VersionedValue, error)
+	GetStateMultipleKeys(namespace string, keys []string) ([]*statedb.VersionedValue, error)
+	GetPrivateData(namespace, collection, key string) (*statedb.VersionedValue, error)
+	GetPrivateDataMultipleKeys(namespace, collection string, keys []string) ([]*statedb.VersionedValue, error)
+}
+
 type queryHelper struct {
-	txmgr        *LockBasedTxMgr
-	rwsetBuilder *rwsetutil.RWSetBuilder
-	itrs         []*resultsItr
-	err          error
-	doneInvoked  bool
+	txmgr           *LockBasedTxMgr
+	txid            string
+	stateReader     stateReader
+	rwsetBuilder    *rwsetutil.RWSetBuilder
+	itrs            []*resultsItr
+	openItrs        map[commonledger.ResultsIterator]string
+	err             error
+	doneInvoked     bool
+	holdsCommitLock bool
+	readYourWrites  bool
+	// snapshot is the reader of the query executors with snapshot isolation, nil otherwise
+	snapshot *committedStateReader
 }
 
 func (h *queryHelper) getState(ns string, key string) ([]byte, error) {
 	h.checkDone()
-	versionedValue, err := h.txmgr.db.GetState(ns, key)
+	if val, ok := h.getFromWriteSet(ns, key); ok {
+		return val, nil
+	}
+	versionedValue, err := h.stateReader.GetState(ns, key)
 	if err != nil {
 		return nil, err
 	}
@@ -51,12 +71,16 @@ func (h *queryHelper) getState(ns string, key string) ([]byte, error) {
 
 func (h *queryHelper) getStateMultipleKeys(namespace string, keys []string) ([][]byte, error) {
 	h.checkDone()
-	versionedValues, err := h.txmgr.db.GetStateMultipleKeys(namespace, keys)
+	versionedValues, err := h.stateReader.GetStateMultipleKeys(namespace, keys)
 	if err != nil {
-		return nil, nil
+		return nil, err
 	}
 	values := make([][]byte, len(versionedValues))
 	for i, versionedValue := range versionedValues {
+		if val, ok := h.getFromWriteSet(namespace, keys[i]); ok {
+			values[i] = val
+			continue
+		}
 		val, ver := decomposeVersionedValue(versionedValue)
 		if h.rwsetBuilder != nil {
 			h.rwsetBuilder.AddToReadSet(namespace, keys[i], ver)
@@ -66,29 +90,70 @@ func (h *queryHelper) getStateMultipleKeys(namespace string, keys []string) ([][
 	return values, nil
 }
 
+func (h *queryHelper) getStateMetadata(ns string, key string) (map[string][]byte, error) {
+	h.checkDone()
+	versionedValue, err := h.stateReader.GetState(ns, key)
+	if err != nil {
+		return nil, err
+	}
+	_, ver := decomposeVersionedValue(versionedValue)
+	if h.rwsetBuilder != nil {
+		h.rwsetBuilder.AddToReadSet(ns, key, ver)
+	}
+	if versionedValue == nil {
+		return nil, nil
+	}
+	return statemetadata.Deserialize(versionedValue.Metadata)
+}
+
 func (h *queryHelper) getStateRangeScanIterator(namespace string, startKey string, endKey string) (commonledger.ResultsIterator, error) {
 	h.checkDone()
+	if err := h.checkItrLimit(); err != nil {
+		return nil, err
+	}
+	h.acquireCommitLock()
 	itr, err := newResultsItr(namespace, startKey, endKey, h.txmgr.db, h.rwsetBuilder,
 		ledgerconfig.IsQueryReadsHashingEnabled(), ledgerconfig.GetMaxDegreeQueryReadsHashing())
 	if err != nil {
 		return nil, err
 	}
+	if h.snapshot != nil {
+		snapshotItr, err := h.snapshot.rangeScanIterator(itr.dbItr, namespace, startKey, endKey)
+		if err != nil {
+			itr.dbItr.Close()
+			return nil, err
+		}
+		itr.dbItr = snapshotItr
+	}
 	h.itrs = append(h.itrs, itr)
+	itr.onClose = h.trackItr(itr, fmt.Sprintf("range scan iterator on namespace [%s]", namespace))
 	return itr, nil
 }
 
 func (h *queryHelper) executeQuery(namespace, query string) (commonledger.ResultsIterator, error) {
 	h.checkDone()
+	if err := h.checkItrLimit(); err != nil {
+		return nil, err
+	}
+	h.acquireCommitLock()
+	if h.snapshot != nil && h.snapshot.stale() {
+		return nil, errStaleSnapshot
+	}
 	dbItr, err := h.txmgr.db.ExecuteQuery(namespace, query)
 	if err != nil {
 		return nil, err
 	}
-	return &queryResultsItr{DBItr: dbItr, RWSetBuilder: h.rwsetBuilder}, nil
+	itr := &queryResultsItr{DBItr: dbItr, RWSetBuilder: h.rwsetBuilder}
+	itr.onClose = h.trackItr(itr, fmt.Sprintf("query results iterator on namespace [%s]", namespace))
+	return itr, nil
 }
 
 func (h *queryHelper) getPrivateData(ns, coll, key string) ([]byte, error) {
 	h.checkDone()
-	versionedValue, err := h.txmgr.db.GetPrivateData(ns, coll, key)
+	if val, ok := h.getFromPvtWriteSet(ns, coll, key); ok {
+		return val, nil
+	}
+	versionedValue, err := h.stateReader.GetPrivateData(ns, coll, key)
 	if err != nil {
 		return nil, err
 	}
@@ -101,12 +166,16 @@ func (h *queryHelper) getPrivateData(ns, coll, key string) ([]byte, error) {
 
 func (h *queryHelper) getPrivateDataMultipleKeys(ns, coll string, keys []string) ([][]byte, error) {
 	h.checkDone()
-	versionedValues, err := h.txmgr.db.GetPrivateDataMultipleKeys(ns, coll, keys)
+	versionedValues, err := h.stateReader.GetPrivateDataMultipleKeys(ns, coll, keys)
 	if err != nil {
 		return nil, nil
 	}
 	values := make([][]byte, len(versionedValues))
 	for i, versionedValue := range versionedValues {
+		if val, ok := h.getFromPvtWriteSet(ns, coll, keys[i]); ok {
+			values[i] = val
+			continue
+		}
 		val, ver := decomposeVersionedValue(versionedValue)
 		if h.rwsetBuilder != nil {
 			h.rwsetBuilder.AddToHashedReadSet(ns, coll, keys[i], ver)
@@ -116,6 +185,22 @@ func (h *queryHelper) getPrivateDataMultipleKeys(ns, coll string, keys []string)
 	return values, nil
 }
 
+// getFromWriteSet returns the value written to the key earlier in the simulation, if the read-your-writes mode is on
+func (h *queryHelper) getFromWriteSet(ns, key string) ([]byte, bool) {
+	if !h.readYourWrites || h.rwsetBuilder == nil {
+		return nil, false
+	}
+	return h.rwsetBuilder.GetFromWriteSet(ns, key)
+}
+
+// getFromPvtWriteSet returns the value written to the private key earlier in the simulation, if the read-your-writes mode is on
+func (h *queryHelper) getFromPvtWriteSet(ns, coll, key string) ([]byte, bool) {
+	if !h.readYourWrites || h.rwsetBuilder == nil {
+		return nil, false
+	}
+	return h.rwsetBuilder.GetFromPvtWriteSet(ns, coll, key)
+}
+
 func (h *queryHelper) getPrivateDataRangeScanIterator(namespace, collection, startKey, endKey string) (commonledger.ResultsIterator, error) {
 	// TODO
 	return nil, errors.New("Not Yet Supported")
@@ -132,11 +217,15 @@ func (h *queryHelper) done() {
 	}
 
 	defer func() {
-		h.txmgr.commitRWLock.RUnlock()
-		h.doneInvoked = true
-		for _, itr := range h.itrs {
-			itr.Close()
+		// the iterators left open by the caller are closed before the commits are unblocked
+		h.closeLeakedItrs()
+		if h.holdsCommitLock {
+			h.txmgr.commitRWLock.RUnlock()
+		}
+		if h.snapshot != nil {
+			h.txmgr.releaseSnapshot(h.snapshot.snapshotHeight)
 		}
+		h.doneInvoked = true
 	}()
 
 	for _, itr := range h.itrs {
@@ -157,6 +246,16 @@ func (h *queryHelper) done() {
 	}
 }
 
+// acquireCommitLock blocks the commit of the next block until done is invoked.
+// This is a no-op if the helper already holds the lock
+func (h *queryHelper) acquireCommitLock() {
+	if h.holdsCommitLock {
+		return
+	}
+	h.txmgr.commitRWLock.RLock()
+	h.holdsCommitLock = true
+}
+
 func (h *queryHelper) checkDone() {
 	if h.doneInvoked {
 		panic("This instance should not be used after calling Done()")
@@ -170,15 +269,19 @@ func (h *queryHelper) checkDone() {
 type resultsItr struct {
 	ns                      string
 	endKey                  string
-	dbItr                   statedb.ResultsIterator
+	dbItr                   statedb.QueryResultsIterator
 	rwSetBuilder            *rwsetutil.RWSetBuilder
 	rangeQueryInfo          *kvrwset.RangeQueryInfo
 	rangeQueryResultsHelper *rwsetutil.RangeQueryResultsHelper
+	onClose                 func()
+	closed                  bool
 }
 
 func newResultsItr(ns string, startKey string, endKey string,
 	db statedb.VersionedDB, rwsetBuilder *rwsetutil.RWSetBuilder, enableHashing bool, maxDegree uint32) (*resultsItr, error) {
-	dbItr, err := db.GetStateRangeScanIterator(ns, startKey, endKey)
+	// Only the range scans of the chaincodes stop at the total query limit, the validation
+	// of the range queries at commit time scans the whole range the simulation read
+	dbItr, err := db.GetStateRangeScanIteratorWithLimit(ns, startKey, endKey, ledgerconfig.GetTotalQueryLimit())
 	if err != nil {
 		return nil, err
 	}
@@ -229,6 +332,11 @@ func (itr *resultsItr) updateRangeQueryInfo(queryResult statedb.QueryResult) {
 	}
 
 	if queryResult == nil {
+		if itr.dbItr.GetBookmark() != "" {
+			// the scan stopped at the total query limit, so the keys following
+			// the last one retrieved by the caller were not read
+			return
+		}
 		// caller scanned till the iterator got exhausted.
 		// So, set the endKey to the actual endKey supplied in the query
 		itr.rangeQueryInfo.ItrExhausted = true
@@ -244,12 +352,21 @@ func (itr *resultsItr) updateRangeQueryInfo(queryResult statedb.QueryResult) {
 
 // Close implements method in interface ledger.ResultsIterator
 func (itr *resultsItr) Close() {
+	if itr.closed {
+		return
+	}
+	itr.closed = true
 	itr.dbItr.Close()
+	if itr.onClose != nil {
+		itr.onClose()
+	}
 }
 
 type queryResultsItr struct {
 	DBItr        statedb.ResultsIterator
 	RWSetBuilder *rwsetutil.RWSetBuilder
+	onClose      func()
+	closed       bool
 }
 
 // Next implements method in interface ledger.ResultsIterator
@@ -273,7 +390,14 @@ func (itr *queryResultsItr) Next() (commonledger.QueryResult, error) {
 
 // Close implements method in interface ledger.ResultsIterator
 func (itr *queryResultsItr) Close() {
+	if itr.closed {
+		return
+	}
+	itr.closed = true
 	itr.DBItr.Close()
+	if itr.onClose != nil {
+		itr.onClose()
+	}
 }
 
 func decomposeVersionedValue(versionedValue *statedb.VersionedValue) ([]byte, *version.Height) {
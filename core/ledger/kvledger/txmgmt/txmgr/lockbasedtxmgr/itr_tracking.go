@@ -0,0 +1,83 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package lockbasedtxmgr
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	commonledger "github.com/hyperledger/fabric/common/ledger"
+	"github.com/hyperledger/fabric/common/metrics"
+	"github.com/hyperledger/fabric/core/ledger/ledgerconfig"
+)
+
+var itrMetrics = newIteratorMetrics(metrics.NewRootScope().SubScope("ledger"))
+
+// iteratorMetrics counts the result iterators opened by the query executors. An iterator
+// pins a snapshot of the state database until it is closed
+type iteratorMetrics struct {
+	open     int64
+	opened   metrics.Counter
+	leaked   metrics.Counter
+	rejected metrics.Counter
+	openNow  metrics.Gauge
+}
+
+func newIteratorMetrics(scope metrics.Scope) *iteratorMetrics {
+	return &iteratorMetrics{
+		opened:   scope.Counter("iterators_opened"),
+		leaked:   scope.Counter("iterators_leaked"),
+		rejected: scope.Counter("iterators_rejected"),
+		openNow:  scope.Gauge("open_iterators"),
+	}
+}
+
+func (m *iteratorMetrics) itrOpened() {
+	m.opened.Inc(1)
+	m.openNow.Update(float64(atomic.AddInt64(&m.open, 1)))
+}
+
+func (m *iteratorMetrics) itrClosed() {
+	m.openNow.Update(float64(atomic.AddInt64(&m.open, -1)))
+}
+
+// checkItrLimit returns an error if the helper already holds the maximum number of open iterators
+// allowed per query executor, in which case no other iterator should be opened
+func (h *queryHelper) checkItrLimit() error {
+	maxOpen := ledgerconfig.GetMaxOpenIteratorsPerQueryExecutor()
+	if maxOpen <= 0 || len(h.openItrs) < maxOpen {
+		return nil
+	}
+	itrMetrics.rejected.Inc(1)
+	return fmt.Errorf("query executor [%s] has reached the maximum of %d open iterators, the iterators no longer used should be closed", h.txid, maxOpen)
+}
+
+// trackItr records the given iterator as open until the returned function, which
+// the iterator is expected to invoke upon its closure, is invoked
+func (h *queryHelper) trackItr(itr commonledger.ResultsIterator, desc string) func() {
+	if h.openItrs == nil {
+		h.openItrs = make(map[commonledger.ResultsIterator]string)
+	}
+	h.openItrs[itr] = desc
+	itrMetrics.itrOpened()
+	return func() {
+		if _, ok := h.openItrs[itr]; !ok {
+			return
+		}
+		delete(h.openItrs, itr)
+		itrMetrics.itrClosed()
+	}
+}
+
+// closeLeakedItrs closes the iterators that are still open when the helper is done with
+func (h *queryHelper) closeLeakedItrs() {
+	for itr, desc := range h.openItrs {
+		logger.Warningf("Query executor [%s] is done while its %s is still open, closing it", h.txid, desc)
+		itrMetrics.leaked.Inc(1)
+		itr.Close()
+	}
+}
@@ -18,6 +18,7 @@ package lockbasedtxmgr
 
 import (
 	"github.com/hyperledger/fabric/common/ledger"
+	coreledger "github.com/hyperledger/fabric/core/ledger"
 )
 
 // LockBasedQueryExecutor is a query executor used in `LockBasedTxMgr`
@@ -27,7 +28,7 @@ type lockBasedQueryExecutor struct {
 }
 
 func newQueryExecutor(txmgr *LockBasedTxMgr, txid string) *lockBasedQueryExecutor {
-	helper := &queryHelper{txmgr: txmgr, rwsetBuilder: nil}
+	helper := &queryHelper{txmgr: txmgr, txid: txid, stateReader: txmgr.db, rwsetBuilder: nil}
 	logger.Debugf("constructing new query executor txid = [%s]", txid)
 	return &lockBasedQueryExecutor{helper, txid}
 }
@@ -42,6 +43,11 @@ func (q *lockBasedQueryExecutor) GetStateMultipleKeys(namespace string, keys []s
 	return q.helper.getStateMultipleKeys(namespace, keys)
 }
 
+// GetStateMetadata implements method in interface `ledger.QueryExecutor`
+func (q *lockBasedQueryExecutor) GetStateMetadata(namespace string, key string) (map[string][]byte, error) {
+	return q.helper.getStateMetadata(namespace, key)
+}
+
 // GetStateRangeScanIterator implements method in interface `ledger.QueryExecutor`
 // startKey is included in the results and endKey is excluded. An empty startKey refers to the first available key
 // and an empty endKey refers to the last available key. For scanning all the keys, both the startKey and the endKey
@@ -71,6 +77,12 @@ func (q *lockBasedQueryExecutor) ExecuteQueryOnPrivateData(namespace, collection
 	return q.helper.executeQueryOnPrivateData(namespace, collection, query)
 }
 
+// GetStateByPartialCompositeKeyWithPagination implements method in interface `ledger.QueryExecutor`
+func (q *lockBasedQueryExecutor) GetStateByPartialCompositeKeyWithPagination(namespace, objectType string, attributes []string,
+	pageSize int32, bookmark string) (coreledger.QueryResultsIterator, error) {
+	return q.helper.getStateByPartialCompositeKeyWithPagination(namespace, objectType, attributes, pageSize, bookmark)
+}
+
 // Done implements method in interface `ledger.QueryExecutor`
 func (q *lockBasedQueryExecutor) Done() {
 	logger.Debugf("Done with transaction simulation / query execution [%s]", q.txid)
@@ -21,6 +21,7 @@ import (
 
 	"github.com/hyperledger/fabric/core/ledger"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/rwsetutil"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statemetadata"
 )
 
 // LockBasedTxSimulator is a transaction simulator used in `LockBasedTxMgr`
@@ -31,7 +32,7 @@ type lockBasedTxSimulator struct {
 
 func newLockBasedTxSimulator(txmgr *LockBasedTxMgr, txid string) (*lockBasedTxSimulator, error) {
 	rwsetBuilder := rwsetutil.NewRWSetBuilder()
-	helper := &queryHelper{txmgr: txmgr, rwsetBuilder: rwsetBuilder}
+	helper := &queryHelper{txmgr: txmgr, txid: txid, stateReader: txmgr.db, rwsetBuilder: rwsetBuilder}
 	logger.Debugf("constructing new tx simulator txid = [%s]", txid)
 	return &lockBasedTxSimulator{lockBasedQueryExecutor{helper, txid}, rwsetBuilder}, nil
 }
@@ -44,13 +45,22 @@ func (s *lockBasedTxSimulator) GetState(ns string, key string) ([]byte, error) {
 // SetState implements method in interface `ledger.TxSimulator`
 func (s *lockBasedTxSimulator) SetState(ns string, key string, value []byte) error {
 	s.helper.checkDone()
-	if err := s.helper.txmgr.db.ValidateKey(key); err != nil {
+	if err := s.validateKey(key); err != nil {
 		return err
 	}
 	s.rwsetBuilder.AddToWriteSet(ns, key, value)
 	return nil
 }
 
+// validateKey checks that the key of a write is supported by the state database, and that it
+// contains the null character only as the separator of a composite key
+func (s *lockBasedTxSimulator) validateKey(key string) error {
+	if err := s.helper.txmgr.db.ValidateKey(key); err != nil {
+		return err
+	}
+	return validateCompositeKeyFormat(key)
+}
+
 // DeleteState implements method in interface `ledger.TxSimulator`
 func (s *lockBasedTxSimulator) DeleteState(ns string, key string) error {
 	return s.SetState(ns, key, nil)
@@ -66,10 +76,25 @@ func (s *lockBasedTxSimulator) SetStateMultipleKeys(namespace string, kvs map[st
 	return nil
 }
 
+// SetStateMetadata implements method in interface `ledger.TxSimulator`
+func (s *lockBasedTxSimulator) SetStateMetadata(ns string, key string, metadata map[string][]byte) error {
+	s.helper.checkDone()
+	if err := s.validateKey(key); err != nil {
+		return err
+	}
+	s.rwsetBuilder.AddToMetadataWriteSet(ns, key, statemetadata.ToEntries(metadata))
+	return nil
+}
+
+// DeleteStateMetadata implements method in interface `ledger.TxSimulator`
+func (s *lockBasedTxSimulator) DeleteStateMetadata(ns string, key string) error {
+	return s.SetStateMetadata(ns, key, nil)
+}
+
 // SetPrivateData implements method in interface `ledger.TxSimulator`
 func (s *lockBasedTxSimulator) SetPrivateData(ns, coll, key string, value []byte) error {
 	s.helper.checkDone()
-	if err := s.helper.txmgr.db.ValidateKey(key); err != nil {
+	if err := s.validateKey(key); err != nil {
 		return err
 	}
 	return s.rwsetBuilder.AddToPvtAndHashedWriteSet(ns, coll, key, value)
@@ -90,6 +115,12 @@ func (s *lockBasedTxSimulator) SetPrivateDataMultipleKeys(ns, coll string, kvs m
 	return nil
 }
 
+// EnableReadYourWrites implements method in interface `ledger.ReadYourWritesEnabler`
+func (s *lockBasedTxSimulator) EnableReadYourWrites() {
+	s.helper.checkDone()
+	s.helper.readYourWrites = true
+}
+
 // GetTxSimulationResults implements method in interface `ledger.TxSimulator`
 func (s *lockBasedTxSimulator) GetTxSimulationResults() (*ledger.TxSimulationResults, error) {
 	logger.Debugf("Simulation completed, getting simulation results")
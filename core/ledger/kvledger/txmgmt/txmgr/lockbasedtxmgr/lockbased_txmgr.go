@@ -22,11 +22,14 @@ import (
 	"github.com/hyperledger/fabric/common/flogging"
 	"github.com/hyperledger/fabric/core/ledger"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/privacyenabledstate"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/txmgr"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/validator"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/validator/valimpl"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/version"
+	"github.com/hyperledger/fabric/core/ledger/ledgerconfig"
 	"github.com/hyperledger/fabric/core/transientstore"
 	"github.com/hyperledger/fabric/protos/common"
+	"github.com/pkg/errors"
 )
 
 var logger = flogging.MustGetLogger("lockbasedtxmgr")
@@ -39,12 +42,23 @@ type LockBasedTxMgr struct {
 	batch        *privacyenabledstate.UpdateBatch
 	currentBlock *common.Block
 	commitRWLock sync.RWMutex
+	// preImages hold the values that the keys updated by the block being committed and by
+	// the blocks committed since the oldest open snapshot had before these blocks, ordered
+	// by block number. committedHeight is the height fully applied to the state database
+	// since the txmgr was constructed, and snapshots count the open snapshots by height.
+	// The snapshots below expiredHeight were expired, as too many blocks were committed since.
+	// They are guarded by preImageLock rather than commitRWLock
+	preImages       []*commitPreImage
+	committedHeight uint64
+	snapshots       map[uint64]int
+	expiredHeight   uint64
+	preImageLock    sync.RWMutex
 }
 
 // NewLockBasedTxMgr constructs a new instance of NewLockBasedTxMgr
 func NewLockBasedTxMgr(db privacyenabledstate.DB, tStore transientstore.Store) *LockBasedTxMgr {
 	db.Open()
-	txmgr := &LockBasedTxMgr{db: db}
+	txmgr := &LockBasedTxMgr{db: db, snapshots: make(map[uint64]int)}
 	txmgr.validator = valimpl.NewStatebasedValidator(txmgr, db)
 	return txmgr
 }
@@ -58,10 +72,32 @@ func (txmgr *LockBasedTxMgr) GetLastSavepoint() (*version.Height, error) {
 // NewQueryExecutor implements method in interface `txmgmt.TxMgr`
 func (txmgr *LockBasedTxMgr) NewQueryExecutor(txid string) (ledger.QueryExecutor, error) {
 	qe := newQueryExecutor(txmgr, txid)
-	txmgr.commitRWLock.RLock()
+	qe.helper.acquireCommitLock()
 	return qe, nil
 }
 
+// NewQueryExecutorWithIsolation implements method in interface `txmgmt.TxMgr`
+func (txmgr *LockBasedTxMgr) NewQueryExecutorWithIsolation(txid string, level ledger.IsolationLevel) (ledger.QueryExecutor, error) {
+	switch level {
+	case ledger.IsolationSerializable:
+		return txmgr.NewQueryExecutor(txid)
+	case ledger.IsolationReadCommitted:
+		if !ledgerconfig.IsReadCommittedIsolationEnabled() {
+			logger.Debugf("Read committed isolation is disabled, constructing a serializable query executor")
+			return txmgr.NewQueryExecutor(txid)
+		}
+		return newReadCommittedQueryExecutor(txmgr, txid), nil
+	case ledger.IsolationSnapshot:
+		if !ledgerconfig.IsSnapshotIsolationEnabled() {
+			logger.Debugf("Snapshot isolation is disabled, constructing a serializable query executor")
+			return txmgr.NewQueryExecutor(txid)
+		}
+		return newSnapshotQueryExecutor(txmgr, txid), nil
+	default:
+		return nil, errors.Errorf("unknown isolation level %d", level)
+	}
+}
+
 // NewTxSimulator implements method in interface `txmgmt.TxMgr`
 func (txmgr *LockBasedTxMgr) NewTxSimulator(txid string) (ledger.TxSimulator, error) {
 	logger.Debugf("constructing new tx simulator")
@@ -69,7 +105,7 @@ func (txmgr *LockBasedTxMgr) NewTxSimulator(txid string) (ledger.TxSimulator, er
 	if err != nil {
 		return nil, err
 	}
-	txmgr.commitRWLock.RLock()
+	s.helper.acquireCommitLock()
 	return s, nil
 }
 
@@ -86,6 +122,48 @@ func (txmgr *LockBasedTxMgr) ValidateAndPrepare(blockAndPvtdata *ledger.BlockAnd
 	return err
 }
 
+// RemoveExpiredPvtData implements method in interface `txmgmt.TxMgr`. It adds to the batch prepared by
+// `ValidateAndPrepare` the removal of the expired pvt data and of its hashes, as long as the keys were
+// not written since the expired writes, neither by a committed block nor by the block being committed
+func (txmgr *LockBasedTxMgr) RemoveExpiredPvtData(expired []*txmgr.ExpiredPvtWrites) error {
+	if txmgr.batch == nil {
+		panic("validateAndPrepare() method should have been called before calling removeExpiredPvtData()")
+	}
+	ht := version.NewHeight(txmgr.currentBlock.Header.Number, uint64(len(txmgr.currentBlock.Data.Data)-1))
+	for _, writes := range expired {
+		ns, coll := writes.Namespace, writes.Collection
+		for _, keyHash := range writes.KeyHashes {
+			if txmgr.batch.HashUpdates.Contains(ns, coll, keyHash) {
+				continue
+			}
+			vv, err := txmgr.db.GetValueHash(ns, coll, keyHash)
+			if err != nil {
+				return err
+			}
+			if vv == nil || vv.Version.Compare(writes.Height) != 0 {
+				continue
+			}
+			logger.Debugf("Removing the expired hashed key [%x] of collection [%s:%s]", keyHash, ns, coll)
+			txmgr.batch.HashUpdates.Delete(ns, coll, keyHash, ht)
+		}
+		for _, key := range writes.Keys {
+			if txmgr.batch.PvtUpdates.Get(ns, coll, key) != nil {
+				continue
+			}
+			vv, err := txmgr.db.GetPrivateData(ns, coll, key)
+			if err != nil {
+				return err
+			}
+			if vv == nil || vv.Version.Compare(writes.Height) != 0 {
+				continue
+			}
+			logger.Debugf("Removing the expired key [%s] of collection [%s:%s]", key, ns, coll)
+			txmgr.batch.PvtUpdates.Delete(ns, coll, key, ht)
+		}
+	}
+	return nil
+}
+
 // Shutdown implements method in interface `txmgmt.TxMgr`
 func (txmgr *LockBasedTxMgr) Shutdown() {
 	txmgr.db.Close()
@@ -94,17 +172,39 @@ func (txmgr *LockBasedTxMgr) Shutdown() {
 // Commit implements method in interface `txmgmt.TxMgr`
 func (txmgr *LockBasedTxMgr) Commit() error {
 	logger.Debugf("Committing updates to state database")
-	txmgr.commitRWLock.Lock()
-	defer txmgr.commitRWLock.Unlock()
-	logger.Debugf("Write lock acquired for committing updates to state database")
 	if txmgr.batch == nil {
 		panic("validateAndPrepare() method should have been called before calling commit()")
 	}
 	defer func() { txmgr.batch = nil }()
-	if err := txmgr.db.ApplyPrivacyAwareUpdates(txmgr.batch,
-		version.NewHeight(txmgr.currentBlock.Header.Number, uint64(len(txmgr.currentBlock.Data.Data)-1))); err != nil {
+	return txmgr.commitUpdates(txmgr.batch,
+		version.NewHeight(txmgr.currentBlock.Header.Number, uint64(len(txmgr.currentBlock.Data.Data)-1)))
+}
+
+// commitUpdates applies the updates to the state database, with the given height as the savepoint
+func (txmgr *LockBasedTxMgr) commitUpdates(batch *privacyenabledstate.UpdateBatch, savepoint *version.Height) error {
+	blockNum := savepoint.BlockNum
+	var preImage *commitPreImage
+	if ledgerconfig.IsReadCommittedIsolationEnabled() || ledgerconfig.IsSnapshotIsolationEnabled() {
+		// Only the committer updates the state database, so the pre-image can be read without the lock
+		var err error
+		if preImage, err = txmgr.readPreImage(batch); err != nil {
+			return err
+		}
+		preImage.blockNum = blockNum
+	}
+	txmgr.commitRWLock.Lock()
+	defer txmgr.commitRWLock.Unlock()
+	logger.Debugf("Write lock acquired for committing updates to state database")
+	if preImage != nil {
+		txmgr.installPreImage(preImage)
+	}
+	if err := txmgr.db.ApplyPrivacyAwareUpdates(batch, savepoint); err != nil {
+		if preImage != nil {
+			txmgr.dropPreImage(preImage)
+		}
 		return err
 	}
+	txmgr.blockCommitted(blockNum)
 	logger.Debugf("Updates committed to state database")
 	return nil
 }
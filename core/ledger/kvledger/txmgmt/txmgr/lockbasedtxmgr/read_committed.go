@@ -0,0 +1,406 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package lockbasedtxmgr
+
+import (
+	"sort"
+
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/privacyenabledstate"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statedb"
+	"github.com/hyperledger/fabric/core/ledger/ledgerconfig"
+	"github.com/pkg/errors"
+)
+
+// commitPreImage holds the values that the keys updated by a block had before the block
+// was committed. A key that did not exist is held with a nil value
+type commitPreImage struct {
+	blockNum  uint64
+	pubValues *statedb.UpdateBatch
+	pvtValues *privacyenabledstate.PvtUpdateBatch
+}
+
+// readPreImage reads from the state database the current values of the keys updated by the given batch
+func (txmgr *LockBasedTxMgr) readPreImage(batch *privacyenabledstate.UpdateBatch) (*commitPreImage, error) {
+	preImage := &commitPreImage{pubValues: statedb.NewUpdateBatch(), pvtValues: privacyenabledstate.NewPvtUpdateBatch()}
+	for _, ns := range batch.PubUpdates.GetUpdatedNamespaces() {
+		keys := updatedKeys(batch.PubUpdates.GetUpdates(ns))
+		versionedValues, err := txmgr.db.GetStateMultipleKeys(ns, keys)
+		if err != nil {
+			return nil, err
+		}
+		for i, vv := range versionedValues {
+			if vv == nil {
+				preImage.pubValues.Delete(ns, keys[i], nil)
+				continue
+			}
+			preImage.pubValues.PutValAndMetadata(ns, keys[i], vv.Value, vv.Metadata, vv.Version)
+		}
+	}
+	for ns, nsBatch := range batch.PvtUpdates.UpdateMap {
+		for _, coll := range nsBatch.GetCollectionNames() {
+			keys := updatedKeys(nsBatch.GetUpdates(coll))
+			versionedValues, err := txmgr.db.GetPrivateDataMultipleKeys(ns, coll, keys)
+			if err != nil {
+				return nil, err
+			}
+			for i, vv := range versionedValues {
+				if vv == nil {
+					preImage.pvtValues.Delete(ns, coll, keys[i], nil)
+					continue
+				}
+				preImage.pvtValues.Put(ns, coll, keys[i], vv.Value, vv.Version)
+			}
+		}
+	}
+	return preImage, nil
+}
+
+// installPreImage makes the pre-image of the block about to be applied to the state database
+// visible to the readers, before the state database starts changing
+func (txmgr *LockBasedTxMgr) installPreImage(preImage *commitPreImage) {
+	txmgr.preImageLock.Lock()
+	defer txmgr.preImageLock.Unlock()
+	txmgr.preImages = append(txmgr.preImages, preImage)
+}
+
+// blockCommitted records the commit of a block, so that the reads at the last committed
+// height are served from the state database from now on, expires the snapshots taken too
+// many blocks ago, and releases the pre-images not needed by any snapshot
+func (txmgr *LockBasedTxMgr) blockCommitted(blockNum uint64) {
+	txmgr.preImageLock.Lock()
+	defer txmgr.preImageLock.Unlock()
+	txmgr.committedHeight = blockNum + 1
+	txmgr.expireSnapshots()
+	txmgr.releasePreImages()
+}
+
+// expireSnapshots expires the snapshots taken before the last 'ledger.state.snapshotIsolation.maxBlocks'
+// committed blocks, so that their pre-images are released and their further reads fail.
+// This is called under the pre-image lock
+func (txmgr *LockBasedTxMgr) expireSnapshots() {
+	maxBlocks := ledgerconfig.GetSnapshotIsolationMaxBlocks()
+	if maxBlocks <= 0 || txmgr.committedHeight <= uint64(maxBlocks) {
+		return
+	}
+	expiredHeight := txmgr.committedHeight - uint64(maxBlocks)
+	for height, count := range txmgr.snapshots {
+		if height < expiredHeight {
+			logger.Warningf("Expiring [%d] snapshot query executors taken at height [%d], as [%d] blocks were committed since",
+				count, height, txmgr.committedHeight-height)
+			delete(txmgr.snapshots, height)
+		}
+	}
+	if expiredHeight > txmgr.expiredHeight {
+		txmgr.expiredHeight = expiredHeight
+	}
+}
+
+// dropPreImage removes the pre-image of a block whose commit failed
+func (txmgr *LockBasedTxMgr) dropPreImage(preImage *commitPreImage) {
+	txmgr.preImageLock.Lock()
+	defer txmgr.preImageLock.Unlock()
+	for i, p := range txmgr.preImages {
+		if p == preImage {
+			txmgr.preImages = append(txmgr.preImages[:i], txmgr.preImages[i+1:]...)
+			return
+		}
+	}
+}
+
+// acquireSnapshot pins the last committed height, whose values are kept until releaseSnapshot is invoked
+func (txmgr *LockBasedTxMgr) acquireSnapshot() uint64 {
+	txmgr.preImageLock.Lock()
+	defer txmgr.preImageLock.Unlock()
+	height := txmgr.committedHeight
+	txmgr.snapshots[height]++
+	return height
+}
+
+func (txmgr *LockBasedTxMgr) releaseSnapshot(height uint64) {
+	txmgr.preImageLock.Lock()
+	defer txmgr.preImageLock.Unlock()
+	if height < txmgr.expiredHeight {
+		// the snapshot was already released when it expired
+		return
+	}
+	if txmgr.snapshots[height]--; txmgr.snapshots[height] <= 0 {
+		delete(txmgr.snapshots, height)
+	}
+	txmgr.releasePreImages()
+}
+
+// releasePreImages drops the pre-images of the committed blocks which no snapshot was taken before.
+// The pre-image of block b holds the values at height b, so it is needed by the snapshots of the heights
+// up to b and, while the block is being committed, by the reads at the last committed height.
+// This is called under the pre-image lock
+func (txmgr *LockBasedTxMgr) releasePreImages() {
+	minSnapshot := txmgr.committedHeight
+	for height := range txmgr.snapshots {
+		if height < minSnapshot {
+			minSnapshot = height
+		}
+	}
+	i := 0
+	for i < len(txmgr.preImages) && txmgr.preImages[i].blockNum < minSnapshot {
+		i++
+	}
+	txmgr.preImages = txmgr.preImages[i:]
+}
+
+// pubValueAt returns the value that the key had at the given height if a block committed since
+// updated it, and nil otherwise. This is called under the pre-image lock
+func (txmgr *LockBasedTxMgr) pubValueAt(height uint64, namespace, key string) *statedb.VersionedValue {
+	// the pre-image of the first block updating the key since the height holds its value at the height
+	for _, preImage := range txmgr.preImages {
+		if preImage.blockNum < height {
+			continue
+		}
+		if vv := preImage.pubValues.Get(namespace, key); vv != nil {
+			return vv
+		}
+	}
+	return nil
+}
+
+// pvtValueAt is the private data counterpart of pubValueAt
+func (txmgr *LockBasedTxMgr) pvtValueAt(height uint64, namespace, collection, key string) *statedb.VersionedValue {
+	for _, preImage := range txmgr.preImages {
+		if preImage.blockNum < height {
+			continue
+		}
+		if vv := preImage.pvtValues.Get(namespace, collection, key); vv != nil {
+			return vv
+		}
+	}
+	return nil
+}
+
+func updatedKeys(updates map[string]*statedb.VersionedValue) []string {
+	keys := make([]string, 0, len(updates))
+	for key := range updates {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// committedStateReader serves point reads from a committed height, either the last committed
+// height at the time of each read or the height of a snapshot. The keys updated by the blocks
+// committed since that height are read from their pre-images, and the other keys from the state
+// database. The pre-image lock is held across both reads, so the pre-images cannot be installed
+// or released in between
+type committedStateReader struct {
+	txmgr *LockBasedTxMgr
+	// snapshot is true if the reads are served from snapshotHeight
+	snapshot       bool
+	snapshotHeight uint64
+}
+
+func newReadCommittedQueryExecutor(txmgr *LockBasedTxMgr, txid string) *lockBasedQueryExecutor {
+	helper := &queryHelper{txmgr: txmgr, txid: txid, stateReader: &committedStateReader{txmgr: txmgr}}
+	logger.Debugf("constructing new read committed query executor txid = [%s]", txid)
+	return &lockBasedQueryExecutor{helper, txid}
+}
+
+func newSnapshotQueryExecutor(txmgr *LockBasedTxMgr, txid string) *lockBasedQueryExecutor {
+	reader := &committedStateReader{txmgr: txmgr, snapshot: true, snapshotHeight: txmgr.acquireSnapshot()}
+	helper := &queryHelper{txmgr: txmgr, txid: txid, stateReader: reader, snapshot: reader}
+	logger.Debugf("constructing new snapshot query executor txid = [%s] at height [%d]", txid, reader.snapshotHeight)
+	return &lockBasedQueryExecutor{helper, txid}
+}
+
+// expired returns true if the reads are served from a snapshot that expired, whose values at the height
+// of the snapshot may have been released. This is called under the pre-image lock
+func (r *committedStateReader) expired() bool {
+	return r.snapshot && r.snapshotHeight < r.txmgr.expiredHeight
+}
+
+// height returns the height the reads are served from. This is called under the pre-image lock
+func (r *committedStateReader) height() uint64 {
+	if r.snapshot {
+		return r.snapshotHeight
+	}
+	return r.txmgr.committedHeight
+}
+
+func (r *committedStateReader) GetState(namespace string, key string) (*statedb.VersionedValue, error) {
+	r.txmgr.preImageLock.RLock()
+	defer r.txmgr.preImageLock.RUnlock()
+	if r.expired() {
+		return nil, errExpiredSnapshot
+	}
+	if vv := r.txmgr.pubValueAt(r.height(), namespace, key); vv != nil {
+		return vv, nil
+	}
+	return r.txmgr.db.GetState(namespace, key)
+}
+
+func (r *committedStateReader) GetStateMultipleKeys(namespace string, keys []string) ([]*statedb.VersionedValue, error) {
+	r.txmgr.preImageLock.RLock()
+	defer r.txmgr.preImageLock.RUnlock()
+	if r.expired() {
+		return nil, errExpiredSnapshot
+	}
+	versionedValues, err := r.txmgr.db.GetStateMultipleKeys(namespace, keys)
+	if err != nil {
+		return nil, err
+	}
+	for i, key := range keys {
+		if vv := r.txmgr.pubValueAt(r.height(), namespace, key); vv != nil {
+			versionedValues[i] = vv
+		}
+	}
+	return versionedValues, nil
+}
+
+func (r *committedStateReader) GetPrivateData(namespace, collection, key string) (*statedb.VersionedValue, error) {
+	r.txmgr.preImageLock.RLock()
+	defer r.txmgr.preImageLock.RUnlock()
+	if r.expired() {
+		return nil, errExpiredSnapshot
+	}
+	if vv := r.txmgr.pvtValueAt(r.height(), namespace, collection, key); vv != nil {
+		return vv, nil
+	}
+	return r.txmgr.db.GetPrivateData(namespace, collection, key)
+}
+
+func (r *committedStateReader) GetPrivateDataMultipleKeys(namespace, collection string, keys []string) ([]*statedb.VersionedValue, error) {
+	r.txmgr.preImageLock.RLock()
+	defer r.txmgr.preImageLock.RUnlock()
+	if r.expired() {
+		return nil, errExpiredSnapshot
+	}
+	versionedValues, err := r.txmgr.db.GetPrivateDataMultipleKeys(namespace, collection, keys)
+	if err != nil {
+		return nil, err
+	}
+	for i, key := range keys {
+		if vv := r.txmgr.pvtValueAt(r.height(), namespace, collection, key); vv != nil {
+			versionedValues[i] = vv
+		}
+	}
+	return versionedValues, nil
+}
+
+// stale returns true if a block was committed since the snapshot was taken. This is called
+// with the commit lock held, so no block is being committed
+func (r *committedStateReader) stale() bool {
+	r.txmgr.preImageLock.RLock()
+	defer r.txmgr.preImageLock.RUnlock()
+	return r.txmgr.committedHeight > r.snapshotHeight
+}
+
+// rangeScanIterator wraps a range scan of the state database so that the keys of the range updated
+// since the snapshot was taken are returned with their values at the height of the snapshot.
+// This is called with the commit lock held, so the state database does not change while the
+// returned iterator is open
+func (r *committedStateReader) rangeScanIterator(dbItr statedb.QueryResultsIterator, namespace, startKey, endKey string) (statedb.QueryResultsIterator, error) {
+	r.txmgr.preImageLock.RLock()
+	defer r.txmgr.preImageLock.RUnlock()
+	if r.expired() {
+		return nil, errExpiredSnapshot
+	}
+	overridden := make(map[string]bool)
+	var overrides []*statedb.VersionedKV
+	for _, preImage := range r.txmgr.preImages {
+		if preImage.blockNum < r.snapshotHeight {
+			continue
+		}
+		for key, vv := range preImage.pubValues.GetUpdates(namespace) {
+			if key < startKey || (endKey != "" && key >= endKey) || overridden[key] {
+				continue
+			}
+			// the first pre-image of the key since the snapshot holds its value at the snapshot
+			overridden[key] = true
+			overrides = append(overrides, &statedb.VersionedKV{
+				CompositeKey:   statedb.CompositeKey{Namespace: namespace, Key: key},
+				VersionedValue: *vv,
+			})
+		}
+	}
+	if len(overrides) == 0 {
+		return dbItr, nil
+	}
+	sort.Slice(overrides, func(i, j int) bool { return overrides[i].Key < overrides[j].Key })
+	return &snapshotRangeItr{dbItr: dbItr, overrides: overrides}, nil
+}
+
+// snapshotRangeItr merges the results of a range scan of the state database with the values
+// that the keys updated since a snapshot had at the snapshot. The keys which did not exist
+// at the snapshot are skipped
+type snapshotRangeItr struct {
+	dbItr     statedb.QueryResultsIterator
+	overrides []*statedb.VersionedKV
+	next      *statedb.VersionedKV
+	dbDone    bool
+}
+
+// Next implements method in interface statedb.ResultsIterator
+func (itr *snapshotRangeItr) Next() (statedb.QueryResult, error) {
+	for {
+		if itr.next == nil && !itr.dbDone {
+			result, err := itr.dbItr.Next()
+			if err != nil {
+				return nil, err
+			}
+			if result == nil {
+				itr.dbDone = true
+				itr.dropOverridesFromBookmark()
+			} else {
+				itr.next = result.(*statedb.VersionedKV)
+			}
+		}
+		if len(itr.overrides) == 0 || (itr.next != nil && itr.next.Key < itr.overrides[0].Key) {
+			if itr.next == nil {
+				return nil, nil
+			}
+			result := itr.next
+			itr.next = nil
+			return result, nil
+		}
+		override := itr.overrides[0]
+		itr.overrides = itr.overrides[1:]
+		if itr.next != nil && itr.next.Key == override.Key {
+			itr.next = nil
+		}
+		if override.Value == nil {
+			continue
+		}
+		return override, nil
+	}
+}
+
+// dropOverridesFromBookmark drops the keys following the last key retrieved, if the scan
+// of the state database stopped at the total query limit
+func (itr *snapshotRangeItr) dropOverridesFromBookmark() {
+	bookmark := itr.dbItr.GetBookmark()
+	if bookmark == "" {
+		return
+	}
+	for i, override := range itr.overrides {
+		if override.Key >= bookmark {
+			itr.overrides = itr.overrides[:i]
+			return
+		}
+	}
+}
+
+// GetBookmark implements method in interface statedb.QueryResultsIterator
+func (itr *snapshotRangeItr) GetBookmark() string {
+	return itr.dbItr.GetBookmark()
+}
+
+// Close implements method in interface statedb.ResultsIterator
+func (itr *snapshotRangeItr) Close() {
+	itr.dbItr.Close()
+}
+
+var errStaleSnapshot = errors.New("a block was committed since the snapshot of the query executor was taken, " +
+	"rich queries cannot be evaluated at the height of the snapshot")
+
+var errExpiredSnapshot = errors.New("too many blocks were committed since the snapshot of the query executor was taken, " +
+	"the values at the height of the snapshot were released")
@@ -0,0 +1,333 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package lockbasedtxmgr
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/privacyenabledstate"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/version"
+	"github.com/hyperledger/fabric/protos/ledger/queryresult"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadCommittedDuringCommit(t *testing.T) {
+	viper.Set("ledger.state.readCommittedIsolation", true)
+	defer viper.Set("ledger.state.readCommittedIsolation", false)
+	for _, testEnv := range testEnvs {
+		t.Run(testEnv.getName(), func(t *testing.T) {
+			testEnv.init(t, "testreadcommittedduringcommit")
+			testReadCommittedDuringCommit(t, testEnv)
+			testEnv.cleanup()
+		})
+	}
+}
+
+func testReadCommittedDuringCommit(t *testing.T, env testEnv) {
+	txMgr := env.getTxMgr().(*LockBasedTxMgr)
+	db := env.getVDB()
+
+	batch1 := privacyenabledstate.NewUpdateBatch()
+	batch1.PubUpdates.Put("ns1", "key1", []byte("value1"), version.NewHeight(1, 0))
+	batch1.PubUpdates.Put("ns1", "key2", []byte("value2"), version.NewHeight(1, 0))
+	batch1.PvtUpdates.Put("ns1", "coll1", "key1", []byte("pvtValue1"), version.NewHeight(1, 0))
+	assert.NoError(t, db.ApplyPrivacyAwareUpdates(batch1, version.NewHeight(1, 0)))
+
+	batch2 := privacyenabledstate.NewUpdateBatch()
+	batch2.PubUpdates.Put("ns1", "key1", []byte("value1_1"), version.NewHeight(2, 0))
+	batch2.PubUpdates.Delete("ns1", "key2", version.NewHeight(2, 0))
+	batch2.PubUpdates.Put("ns1", "key3", []byte("value3"), version.NewHeight(2, 0))
+	batch2.PvtUpdates.Put("ns1", "coll1", "key1", []byte("pvtValue1_1"), version.NewHeight(2, 0))
+
+	qe, err := txMgr.NewQueryExecutorWithIsolation("test_tx", ledger.IsolationReadCommitted)
+	assert.NoError(t, err)
+	defer qe.Done()
+
+	// Apply block 2 the way Commit does, with the pre-image installed
+	preImage, err := txMgr.readPreImage(batch2)
+	assert.NoError(t, err)
+	preImage.blockNum = 2
+	txMgr.installPreImage(preImage)
+	assert.NoError(t, db.ApplyPrivacyAwareUpdates(batch2, version.NewHeight(2, 0)))
+
+	// The reads are served from block 1 until the commit completes
+	value, err := qe.GetState("ns1", "key1")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("value1"), value)
+	values, err := qe.GetStateMultipleKeys("ns1", []string{"key1", "key2", "key3"})
+	assert.NoError(t, err)
+	assert.Equal(t, [][]byte{[]byte("value1"), []byte("value2"), nil}, values)
+	value, err = qe.GetPrivateData("ns1", "coll1", "key1")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("pvtValue1"), value)
+	values, err = qe.GetPrivateDataMultipleKeys("ns1", "coll1", []string{"key1"})
+	assert.NoError(t, err)
+	assert.Equal(t, [][]byte{[]byte("pvtValue1")}, values)
+
+	txMgr.blockCommitted(2)
+
+	values, err = qe.GetStateMultipleKeys("ns1", []string{"key1", "key2", "key3"})
+	assert.NoError(t, err)
+	assert.Equal(t, [][]byte{[]byte("value1_1"), nil, []byte("value3")}, values)
+	value, err = qe.GetPrivateData("ns1", "coll1", "key1")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("pvtValue1_1"), value)
+}
+
+func TestReadCommittedDoesNotBlockCommit(t *testing.T) {
+	viper.Set("ledger.state.readCommittedIsolation", true)
+	defer viper.Set("ledger.state.readCommittedIsolation", false)
+	for _, testEnv := range testEnvs {
+		t.Run(testEnv.getName(), func(t *testing.T) {
+			testEnv.init(t, "testreadcommitteddoesnotblockcommit")
+			testReadCommittedDoesNotBlockCommit(t, testEnv)
+			testEnv.cleanup()
+		})
+	}
+}
+
+func testReadCommittedDoesNotBlockCommit(t *testing.T, env testEnv) {
+	txMgr := env.getTxMgr()
+	txMgrHelper := newTxMgrTestHelper(t, txMgr)
+
+	s1, _ := txMgr.NewTxSimulator("test_tx1")
+	s1.SetState("ns1", "key1", []byte("value1"))
+	s1.Done()
+	txRWSet1, _ := s1.GetTxSimulationResults()
+	txMgrHelper.validateAndCommitRWSet(txRWSet1.PubSimulationResults)
+
+	qe, err := txMgr.NewQueryExecutorWithIsolation("test_tx2", ledger.IsolationReadCommitted)
+	assert.NoError(t, err)
+	value, _ := qe.GetState("ns1", "key1")
+	assert.Equal(t, []byte("value1"), value)
+
+	// The commit proceeds while the read committed query executor is open
+	s2, _ := txMgr.NewTxSimulator("test_tx3")
+	s2.SetState("ns1", "key1", []byte("value1_1"))
+	s2.Done()
+	txRWSet2, _ := s2.GetTxSimulationResults()
+	txMgrHelper.validateAndCommitRWSet(txRWSet2.PubSimulationResults)
+	value, _ = qe.GetState("ns1", "key1")
+	assert.Equal(t, []byte("value1_1"), value)
+
+	// A range scan blocks the commits until the query executor is done
+	itr, err := qe.GetStateRangeScanIterator("ns1", "", "")
+	assert.NoError(t, err)
+	itr.Next()
+	s3, _ := txMgr.NewTxSimulator("test_tx4")
+	s3.SetState("ns1", "key2", []byte("value2"))
+	s3.Done()
+	txRWSet3, _ := s3.GetTxSimulationResults()
+	committed := make(chan struct{})
+	go func() {
+		txMgrHelper.validateAndCommitRWSet(txRWSet3.PubSimulationResults)
+		close(committed)
+	}()
+	select {
+	case <-committed:
+		assert.Fail(t, "Commit should wait for the range scan of the query executor")
+	case <-time.After(200 * time.Millisecond):
+	}
+	qe.Done()
+	select {
+	case <-committed:
+	case <-time.After(10 * time.Second):
+		assert.Fail(t, "Commit should proceed once the query executor is done")
+	}
+}
+
+func TestSnapshotIsolation(t *testing.T) {
+	viper.Set("ledger.state.readCommittedIsolation", true)
+	defer viper.Set("ledger.state.readCommittedIsolation", false)
+	viper.Set("ledger.state.snapshotIsolation.enabled", true)
+	defer viper.Set("ledger.state.snapshotIsolation.enabled", false)
+	for _, testEnv := range testEnvs {
+		t.Run(testEnv.getName(), func(t *testing.T) {
+			testEnv.init(t, "testsnapshotisolation")
+			testSnapshotIsolation(t, testEnv)
+			testEnv.cleanup()
+		})
+	}
+}
+
+func testSnapshotIsolation(t *testing.T, env testEnv) {
+	txMgr := env.getTxMgr().(*LockBasedTxMgr)
+	txMgrHelper := newTxMgrTestHelper(t, txMgr)
+	commit := func(txid string, update func(s ledger.TxSimulator)) {
+		s, _ := txMgr.NewTxSimulator(txid)
+		update(s)
+		s.Done()
+		txRWSet, _ := s.GetTxSimulationResults()
+		txMgrHelper.validateAndCommitRWSet(txRWSet.PubSimulationResults)
+	}
+
+	commit("test_tx1", func(s ledger.TxSimulator) {
+		s.SetState("ns1", "key1", []byte("value1"))
+		s.SetState("ns1", "key2", []byte("value2"))
+		s.SetState("ns1", "key4", []byte("value4"))
+	})
+
+	snapshot, err := txMgr.NewQueryExecutorWithIsolation("test_tx2", ledger.IsolationSnapshot)
+	assert.NoError(t, err)
+	assert.False(t, snapshot.(*lockBasedQueryExecutor).helper.holdsCommitLock)
+
+	// The commits proceed while the snapshot query executor is open
+	commit("test_tx3", func(s ledger.TxSimulator) {
+		s.SetState("ns1", "key1", []byte("value1_1"))
+		s.DeleteState("ns1", "key2")
+	})
+	value, err := snapshot.GetState("ns1", "key1")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("value1"), value)
+	commit("test_tx4", func(s ledger.TxSimulator) {
+		s.SetState("ns1", "key1", []byte("value1_2"))
+		s.SetState("ns1", "key3", []byte("value3"))
+		s.SetState("ns1", "key4", []byte("value4_1"))
+	})
+
+	// All the reads are served from the height at which the snapshot was taken
+	value, err = snapshot.GetState("ns1", "key1")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("value1"), value)
+	values, err := snapshot.GetStateMultipleKeys("ns1", []string{"key1", "key2", "key3", "key4"})
+	assert.NoError(t, err)
+	assert.Equal(t, [][]byte{[]byte("value1"), []byte("value2"), nil, []byte("value4")}, values)
+	itr, err := snapshot.GetStateRangeScanIterator("ns1", "key1", "key5")
+	assert.NoError(t, err)
+	var keys, rangeValues []string
+	for {
+		result, err := itr.Next()
+		assert.NoError(t, err)
+		if result == nil {
+			break
+		}
+		keys = append(keys, result.(*queryresult.KV).Key)
+		rangeValues = append(rangeValues, string(result.(*queryresult.KV).Value))
+	}
+	itr.Close()
+	assert.Equal(t, []string{"key1", "key2", "key4"}, keys)
+	assert.Equal(t, []string{"value1", "value2", "value4"}, rangeValues)
+	_, err = snapshot.ExecuteQuery("ns1", `{"selector":{}}`)
+	assert.Equal(t, errStaleSnapshot, err)
+
+	// A read committed query executor reads the last committed height
+	readCommitted, err := txMgr.NewQueryExecutorWithIsolation("test_tx5", ledger.IsolationReadCommitted)
+	assert.NoError(t, err)
+	values, err = readCommitted.GetStateMultipleKeys("ns1", []string{"key1", "key2", "key3", "key4"})
+	assert.NoError(t, err)
+	assert.Equal(t, [][]byte{[]byte("value1_2"), nil, []byte("value3"), []byte("value4_1")}, values)
+	readCommitted.Done()
+
+	// The pre-images are kept for the snapshot until it is done
+	assert.Len(t, txMgr.preImages, 2)
+	snapshot.Done()
+	assert.Empty(t, txMgr.preImages)
+	assert.Empty(t, txMgr.snapshots)
+}
+
+func TestSnapshotIsolationMaxBlocks(t *testing.T) {
+	viper.Set("ledger.state.snapshotIsolation.enabled", true)
+	defer viper.Set("ledger.state.snapshotIsolation.enabled", false)
+	viper.Set("ledger.state.snapshotIsolation.maxBlocks", 2)
+	defer viper.Set("ledger.state.snapshotIsolation.maxBlocks", 100)
+	env := testEnvs[0]
+	env.init(t, "testsnapshotisolationmaxblocks")
+	defer env.cleanup()
+
+	txMgr := env.getTxMgr().(*LockBasedTxMgr)
+	txMgrHelper := newTxMgrTestHelper(t, txMgr)
+	commit := func(txid string, value string) {
+		s, _ := txMgr.NewTxSimulator(txid)
+		s.SetState("ns1", "key1", []byte(value))
+		s.Done()
+		txRWSet, _ := s.GetTxSimulationResults()
+		txMgrHelper.validateAndCommitRWSet(txRWSet.PubSimulationResults)
+	}
+
+	commit("test_tx1", "value1")
+	snapshot, err := txMgr.NewQueryExecutorWithIsolation("test_tx2", ledger.IsolationSnapshot)
+	assert.NoError(t, err)
+
+	// The snapshot is served while no more than the maximum number of blocks were committed since it was taken
+	commit("test_tx3", "value1_1")
+	commit("test_tx4", "value1_2")
+	value, err := snapshot.GetState("ns1", "key1")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("value1"), value)
+	assert.Len(t, txMgr.preImages, 2)
+
+	// One more block expires the snapshot, whose pre-images are released and whose reads fail
+	commit("test_tx5", "value1_3")
+	assert.Empty(t, txMgr.preImages)
+	assert.Empty(t, txMgr.snapshots)
+	_, err = snapshot.GetState("ns1", "key1")
+	assert.Equal(t, errExpiredSnapshot, err)
+	_, err = snapshot.GetStateMultipleKeys("ns1", []string{"key1"})
+	assert.Equal(t, errExpiredSnapshot, err)
+	_, err = snapshot.GetStateRangeScanIterator("ns1", "", "")
+	assert.Equal(t, errExpiredSnapshot, err)
+	snapshot.Done()
+	assert.Empty(t, txMgr.snapshots)
+
+	// A snapshot taken after the expiry is served
+	snapshot, err = txMgr.NewQueryExecutorWithIsolation("test_tx6", ledger.IsolationSnapshot)
+	assert.NoError(t, err)
+	value, err = snapshot.GetState("ns1", "key1")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("value1_3"), value)
+	snapshot.Done()
+}
+
+func TestNewQueryExecutorWithIsolation(t *testing.T) {
+	env := testEnvs[0]
+	env.init(t, "testnewqueryexecutorwithisolation")
+	defer env.cleanup()
+	txMgr := env.getTxMgr().(*LockBasedTxMgr)
+
+	_, err := txMgr.NewQueryExecutorWithIsolation("test_tx1", ledger.IsolationLevel(10))
+	assert.Error(t, err)
+
+	// Read committed isolation is disabled by default, so the query executor holds the commit lock
+	qe, err := txMgr.NewQueryExecutorWithIsolation("test_tx2", ledger.IsolationReadCommitted)
+	assert.NoError(t, err)
+	assert.True(t, qe.(*lockBasedQueryExecutor).helper.holdsCommitLock)
+	qe.Done()
+	qe, err = txMgr.NewQueryExecutorWithIsolation("test_tx2", ledger.IsolationSnapshot)
+	assert.NoError(t, err)
+	assert.True(t, qe.(*lockBasedQueryExecutor).helper.holdsCommitLock)
+	assert.Nil(t, qe.(*lockBasedQueryExecutor).helper.snapshot)
+	qe.Done()
+
+	viper.Set("ledger.state.readCommittedIsolation", true)
+	defer viper.Set("ledger.state.readCommittedIsolation", false)
+	qe, err = txMgr.NewQueryExecutorWithIsolation("test_tx3", ledger.IsolationReadCommitted)
+	assert.NoError(t, err)
+	assert.False(t, qe.(*lockBasedQueryExecutor).helper.holdsCommitLock)
+	qe.Done()
+
+	// Snapshot isolation has its own setting
+	qe, err = txMgr.NewQueryExecutorWithIsolation("test_tx4", ledger.IsolationSnapshot)
+	assert.NoError(t, err)
+	assert.True(t, qe.(*lockBasedQueryExecutor).helper.holdsCommitLock)
+	qe.Done()
+
+	viper.Set("ledger.state.snapshotIsolation.enabled", true)
+	defer viper.Set("ledger.state.snapshotIsolation.enabled", false)
+	qe, err = txMgr.NewQueryExecutorWithIsolation("test_tx4", ledger.IsolationSnapshot)
+	assert.NoError(t, err)
+	assert.False(t, qe.(*lockBasedQueryExecutor).helper.holdsCommitLock)
+	assert.NotNil(t, qe.(*lockBasedQueryExecutor).helper.snapshot)
+	qe.Done()
+
+	qe, err = txMgr.NewQueryExecutorWithIsolation("test_tx5", ledger.IsolationSerializable)
+	assert.NoError(t, err)
+	assert.True(t, qe.(*lockBasedQueryExecutor).helper.holdsCommitLock)
+	qe.Done()
+}
@@ -0,0 +1,111 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package lockbasedtxmgr
+
+import (
+	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/privacyenabledstate"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statedb"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/validator/valimpl"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/version"
+)
+
+// PrepareUpdates implements method in interface `txmgmt.TxMgr`. The updates are prepared by a validator of
+// their own, so that the block being committed meanwhile, and the updates prepared for it, are left untouched
+func (txmgr *LockBasedTxMgr) PrepareUpdates(blockAndPvtdata *ledger.BlockAndPvtData) (*privacyenabledstate.UpdateBatch, error) {
+	return valimpl.NewStatebasedValidator(txmgr, txmgr.db).ValidateAndPrepareBatch(blockAndPvtdata, false)
+}
+
+// ReadStateDiff implements method in interface `txmgmt.TxMgr`. The values are read under the commit lock,
+// so that they all are those of the returned height
+func (txmgr *LockBasedTxMgr) ReadStateDiff(updates *privacyenabledstate.UpdateBatch) (uint64, []*ledger.StateDiffEntry, error) {
+	txmgr.commitRWLock.RLock()
+	defer txmgr.commitRWLock.RUnlock()
+	savepoint, err := txmgr.db.GetLatestSavePoint()
+	if err != nil || savepoint == nil {
+		return 0, nil, err
+	}
+
+	var entries []*ledger.StateDiffEntry
+	for _, ns := range updates.PubUpdates.GetUpdatedNamespaces() {
+		nsUpdates := updates.PubUpdates.GetUpdates(ns)
+		keys := updatedKeys(nsUpdates)
+		versionedValues, err := txmgr.db.GetStateMultipleKeys(ns, keys)
+		if err != nil {
+			return 0, nil, err
+		}
+		for i, vv := range versionedValues {
+			entry := &ledger.StateDiffEntry{Namespace: ns, Key: keys[i]}
+			entries = append(entries, committedValue(entry, vv, nsUpdates[keys[i]]))
+		}
+	}
+	for ns, nsBatch := range updates.HashUpdates.UpdateMap {
+		for _, coll := range nsBatch.GetCollectionNames() {
+			for keyHash, update := range nsBatch.GetUpdates(coll) {
+				vv, err := txmgr.db.GetValueHash(ns, coll, []byte(keyHash))
+				if err != nil {
+					return 0, nil, err
+				}
+				entry := &ledger.StateDiffEntry{Namespace: ns, Collection: coll, KeyHash: []byte(keyHash)}
+				entries = append(entries, committedValue(entry, vv, update))
+			}
+		}
+	}
+	for ns, nsBatch := range updates.PvtUpdates.UpdateMap {
+		for _, coll := range nsBatch.GetCollectionNames() {
+			collUpdates := nsBatch.GetUpdates(coll)
+			keys := updatedKeys(collUpdates)
+			versionedValues, err := txmgr.db.GetPrivateDataMultipleKeys(ns, coll, keys)
+			if err != nil {
+				return 0, nil, err
+			}
+			for i, vv := range versionedValues {
+				entry := &ledger.StateDiffEntry{Namespace: ns, Collection: coll, Key: keys[i]}
+				entries = append(entries, committedValue(entry, vv, collUpdates[keys[i]]))
+			}
+		}
+	}
+	return savepoint.BlockNum + 1, entries, nil
+}
+
+// committedValue sets the committed value of the key to its entry, or marks the entry deleted if the key
+// does not exist, with the height of the last update of the key, which is that of the deletion
+func committedValue(entry *ledger.StateDiffEntry, vv *statedb.VersionedValue, lastUpdate *statedb.VersionedValue) *ledger.StateDiffEntry {
+	ver := lastUpdate.Version
+	if vv == nil || vv.Value == nil {
+		entry.IsDelete = true
+	} else {
+		entry.Value, entry.Metadata = vv.Value, vv.Metadata
+		ver = vv.Version
+	}
+	entry.BlockNum, entry.TxNum = ver.BlockNum, ver.TxNum
+	return entry
+}
+
+// ApplyStateDiff implements method in interface `txmgmt.TxMgr`
+func (txmgr *LockBasedTxMgr) ApplyStateDiff(entries []*ledger.StateDiffEntry, savepoint *version.Height) error {
+	batch := privacyenabledstate.NewUpdateBatch()
+	for _, entry := range entries {
+		ver := version.NewHeight(entry.BlockNum, entry.TxNum)
+		switch {
+		case entry.Collection == "" && entry.IsDelete:
+			batch.PubUpdates.Delete(entry.Namespace, entry.Key, ver)
+		case entry.Collection == "":
+			batch.PubUpdates.PutValAndMetadata(entry.Namespace, entry.Key, entry.Value, entry.Metadata, ver)
+		case entry.KeyHash != nil && entry.IsDelete:
+			batch.HashUpdates.Delete(entry.Namespace, entry.Collection, entry.KeyHash, ver)
+		case entry.KeyHash != nil:
+			batch.HashUpdates.Put(entry.Namespace, entry.Collection, entry.KeyHash, entry.Value, ver)
+		case entry.IsDelete:
+			batch.PvtUpdates.Delete(entry.Namespace, entry.Collection, entry.Key, ver)
+		default:
+			batch.PvtUpdates.Put(entry.Namespace, entry.Collection, entry.Key, entry.Value, ver)
+		}
+	}
+	logger.Debugf("Applying [%d] state differences to state database up to block [%d]", len(entries), savepoint.BlockNum)
+	return txmgr.commitUpdates(batch, savepoint)
+}
@@ -20,13 +20,20 @@ import (
 	"encoding/json"
 	"fmt"
 	"testing"
+	"unicode/utf8"
 
 	"os"
 
 	"github.com/hyperledger/fabric/common/flogging"
 	"github.com/hyperledger/fabric/common/ledger/testutil"
+	mockmetrics "github.com/hyperledger/fabric/common/mocks/metrics"
+	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/privacyenabledstate"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/rwsetutil"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/txmgr"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/version"
 	ledgertestutil "github.com/hyperledger/fabric/core/ledger/testutil"
+	"github.com/hyperledger/fabric/core/ledger/util"
 	"github.com/hyperledger/fabric/protos/ledger/queryresult"
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
@@ -282,6 +289,79 @@ func testTxPhantomValidation(t *testing.T, env testEnv) {
 	txMgrHelper.validateAndCommitRWSet(txRWSet4.PubSimulationResults)
 }
 
+func TestTxPhantomValidationWithTotalQueryLimit(t *testing.T) {
+	for _, testEnv := range testEnvs {
+		t.Logf("Running test for TestEnv = %s", testEnv.getName())
+		testLedgerID := "testtxphantomvalidationwithtotalquerylimit"
+		testEnv.init(t, testLedgerID)
+		testTxPhantomValidationWithTotalQueryLimit(t, testEnv)
+		testEnv.cleanup()
+	}
+}
+
+func testTxPhantomValidationWithTotalQueryLimit(t *testing.T, env testEnv) {
+	txMgr := env.getTxMgr()
+	txMgrHelper := newTxMgrTestHelper(t, txMgr)
+	s1, _ := txMgr.NewTxSimulator("test_tx1")
+	for i := 1; i <= 4; i++ {
+		s1.SetState("ns", fmt.Sprintf("key%d", i), []byte(fmt.Sprintf("value%d", i)))
+	}
+	s1.Done()
+	txRWSet1, _ := s1.GetTxSimulationResults()
+	txMgrHelper.validateAndCommitRWSet(txRWSet1.PubSimulationResults)
+
+	viper.Set("ledger.state.totalQueryLimit", 2)
+	defer viper.Set("ledger.state.totalQueryLimit", 10000)
+	scan := func(txid string) *ledger.TxSimulationResults {
+		s, _ := txMgr.NewTxSimulator(txid)
+		itr, _ := s.GetStateRangeScanIterator("ns", "", "")
+		count := 0
+		for {
+			if result, _ := itr.Next(); result == nil {
+				break
+			}
+			count++
+		}
+		assert.Equal(t, 2, count)
+		s.SetState("ns", "key1", []byte("value1_"+txid))
+		s.Done()
+		results, _ := s.GetTxSimulationResults()
+		return results
+	}
+	txRWSet2 := scan("test_tx2")
+	txRWSet3 := scan("test_tx3")
+	txRWSet, err := rwsetutil.TxRwSetFromProtoMsg(txRWSet2.PubSimulationResults)
+	assert.NoError(t, err)
+	rqInfo := txRWSet.NsRwSets[0].KvRwSet.RangeQueriesInfo[0]
+	assert.Equal(t, "key2", rqInfo.EndKey)
+	assert.False(t, rqInfo.ItrExhausted)
+
+	// the keys past the total query limit were not read by the scans
+	s4, _ := txMgr.NewTxSimulator("test_tx4")
+	s4.DeleteState("ns", "key4")
+	s4.Done()
+	txRWSet4, _ := s4.GetTxSimulationResults()
+	txMgrHelper.validateAndCommitRWSet(txRWSet4.PubSimulationResults)
+	txMgrHelper.validateAndCommitRWSet(txRWSet2.PubSimulationResults)
+	// unlike the keys they returned
+	txMgrHelper.checkRWsetInvalid(txRWSet3.PubSimulationResults)
+
+	// the validation of a range query reads the whole range, whatever the total query limit
+	viper.Set("ledger.state.totalQueryLimit", 0)
+	s5, _ := txMgr.NewTxSimulator("test_tx5")
+	itr, _ := s5.GetStateRangeScanIterator("ns", "", "")
+	for {
+		if result, _ := itr.Next(); result == nil {
+			break
+		}
+	}
+	s5.SetState("ns", "key5", []byte("value5"))
+	s5.Done()
+	txRWSet5, _ := s5.GetTxSimulationResults()
+	viper.Set("ledger.state.totalQueryLimit", 1)
+	txMgrHelper.validateAndCommitRWSet(txRWSet5.PubSimulationResults)
+}
+
 func TestIterator(t *testing.T) {
 	for _, testEnv := range testEnvs {
 		t.Logf("Running test for TestEnv = %s", testEnv.getName())
@@ -371,6 +451,58 @@ func testIterator(t *testing.T, env testEnv, numKeys int, startKeyNum int, endKe
 	testutil.AssertEquals(t, count, expectedCount)
 }
 
+func TestIteratorLimitAndLeaks(t *testing.T) {
+	for _, testEnv := range testEnvs {
+		t.Logf("Running test for TestEnv = %s", testEnv.getName())
+		testLedgerID := "testiteratorlimitandleaks"
+		testEnv.init(t, testLedgerID)
+		testIteratorLimitAndLeaks(t, testEnv)
+		testEnv.cleanup()
+	}
+}
+
+func testIteratorLimitAndLeaks(t *testing.T, env testEnv) {
+	defer func(m *iteratorMetrics) { itrMetrics = m }(itrMetrics)
+	scope := mockmetrics.NewScope()
+	itrMetrics = newIteratorMetrics(scope)
+	viper.Set("ledger.state.maxOpenIteratorsPerQueryExecutor", 2)
+	defer viper.Set("ledger.state.maxOpenIteratorsPerQueryExecutor", 100)
+
+	txMgr := env.getTxMgr()
+	s, _ := txMgr.NewTxSimulator("test_tx1")
+	itr1, err := s.GetStateRangeScanIterator("ns", "", "")
+	assert.NoError(t, err)
+	_, err = s.GetStateRangeScanIterator("ns", "", "")
+	assert.NoError(t, err)
+	assert.Equal(t, float64(2), scope.GaugeOf("open_iterators", nil).Value())
+
+	// the limit is reached until an iterator is closed
+	_, err = s.GetStateRangeScanIterator("ns", "", "")
+	assert.EqualError(t, err, "query executor [test_tx1] has reached the maximum of 2 open iterators, the iterators no longer used should be closed")
+	itr1.Close()
+	itr1.Close()
+	assert.Equal(t, float64(1), scope.GaugeOf("open_iterators", nil).Value())
+	_, err = s.GetStateRangeScanIterator("ns", "", "")
+	assert.NoError(t, err)
+
+	// the iterators left open are closed when the simulation is done
+	s.Done()
+	_, err = s.GetTxSimulationResults()
+	assert.NoError(t, err)
+	assert.Equal(t, float64(0), scope.GaugeOf("open_iterators", nil).Value())
+	assert.Equal(t, int64(3), scope.CounterOf("iterators_opened", nil).Value())
+	assert.Equal(t, int64(1), scope.CounterOf("iterators_rejected", nil).Value())
+	assert.Equal(t, int64(2), scope.CounterOf("iterators_leaked", nil).Value())
+
+	// and the leaked iterators don't block the commits
+	txMgrHelper := newTxMgrTestHelper(t, txMgr)
+	s2, _ := txMgr.NewTxSimulator("test_tx2")
+	s2.SetState("ns", "key1", []byte("value1"))
+	s2.Done()
+	txRWSet, _ := s2.GetTxSimulationResults()
+	txMgrHelper.validateAndCommitRWSet(txRWSet.PubSimulationResults)
+}
+
 func TestIteratorWithDeletes(t *testing.T) {
 	for _, testEnv := range testEnvs {
 		t.Logf("Running test for TestEnv = %s", testEnv.getName())
@@ -526,6 +658,174 @@ func testGetSetMultipeKeys(t *testing.T, env testEnv) {
 	}
 }
 
+func TestTxSimulatorReadYourWrites(t *testing.T) {
+	for _, testEnv := range testEnvs {
+		t.Logf("Running test for TestEnv = %s", testEnv.getName())
+		testLedgerID := "testtxsimulatorreadyourwrites"
+		testEnv.init(t, testLedgerID)
+		testTxSimulatorReadYourWrites(t, testEnv)
+		testEnv.cleanup()
+	}
+}
+
+func testTxSimulatorReadYourWrites(t *testing.T, env testEnv) {
+	txMgr := env.getTxMgr()
+	txMgrHelper := newTxMgrTestHelper(t, txMgr)
+	// simulate tx1 and commit
+	s1, _ := txMgr.NewTxSimulator("test_tx1")
+	s1.SetState("ns1", "key1", []byte("value1"))
+	s1.SetState("ns1", "key2", []byte("value2"))
+	s1.Done()
+	txRWSet1, _ := s1.GetTxSimulationResults()
+	txMgrHelper.validateAndCommitRWSet(txRWSet1.PubSimulationResults)
+
+	// without the read-your-writes mode, the reads observe the committed state
+	s2, _ := txMgr.NewTxSimulator("test_tx2")
+	s2.SetState("ns1", "key1", []byte("value1_2"))
+	value, err := s2.GetState("ns1", "key1")
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, value, []byte("value1"))
+	s2.Done()
+
+	s3, _ := txMgr.NewTxSimulator("test_tx3")
+	defer s3.Done()
+	s3.(ledger.ReadYourWritesEnabler).EnableReadYourWrites()
+	s3.SetState("ns1", "key1", []byte("value1_3"))
+	s3.DeleteState("ns1", "key2")
+	value, err = s3.GetState("ns1", "key1")
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, value, []byte("value1_3"))
+	value, err = s3.GetState("ns1", "key2")
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertNil(t, value)
+	values, err := s3.GetStateMultipleKeys("ns1", []string{"key1", "key2", "key3"})
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, values, [][]byte{[]byte("value1_3"), nil, nil})
+
+	s3.SetPrivateData("ns1", "coll1", "key1", []byte("pvtValue1_3"))
+	value, err = s3.GetPrivateData("ns1", "coll1", "key1")
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, value, []byte("pvtValue1_3"))
+	values, err = s3.GetPrivateDataMultipleKeys("ns1", "coll1", []string{"key1"})
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, values, [][]byte{[]byte("pvtValue1_3")})
+
+	// only the read of key3, which was not written by the simulation, goes to the read-set
+	txRWSet := s3.(*lockBasedTxSimulator).rwsetBuilder.GetTxReadWriteSet()
+	testutil.AssertEquals(t, len(txRWSet.NsRwSets), 1)
+	reads := txRWSet.NsRwSets[0].KvRwSet.Reads
+	testutil.AssertEquals(t, len(reads), 1)
+	testutil.AssertEquals(t, reads[0].Key, "key3")
+	for _, collHashedRwSet := range txRWSet.NsRwSets[0].CollHashedRwSets {
+		testutil.AssertEquals(t, len(collHashedRwSet.HashedRwSet.HashedReads), 0)
+	}
+}
+
+func TestTxSimulatorStateMetadata(t *testing.T) {
+	for _, testEnv := range testEnvs {
+		t.Logf("Running test for TestEnv = %s", testEnv.getName())
+		testLedgerID := "testtxsimulatorstatemetadata"
+		testEnv.init(t, testLedgerID)
+		testTxSimulatorStateMetadata(t, testEnv)
+		testEnv.cleanup()
+	}
+}
+
+func testTxSimulatorStateMetadata(t *testing.T, env testEnv) {
+	txMgr := env.getTxMgr()
+	txMgrHelper := newTxMgrTestHelper(t, txMgr)
+	metadata := map[string][]byte{"VALIDATION_PARAMETER": []byte("policy1"), "name2": []byte("value2")}
+
+	// simulate tx1 that creates key1 with metadata, and sets metadata on the non-existing key2
+	s1, _ := txMgr.NewTxSimulator("test_tx1")
+	s1.SetState("ns1", "key1", []byte("value1"))
+	s1.SetStateMetadata("ns1", "key1", metadata)
+	s1.SetStateMetadata("ns1", "key2", metadata)
+	s1.Done()
+	txRWSet1, _ := s1.GetTxSimulationResults()
+	txMgrHelper.validateAndCommitRWSet(txRWSet1.PubSimulationResults)
+
+	qe, _ := txMgr.NewQueryExecutor("test_tx2")
+	readMetadata, err := qe.GetStateMetadata("ns1", "key1")
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, readMetadata, metadata)
+	readMetadata, err = qe.GetStateMetadata("ns1", "key2")
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertNil(t, readMetadata)
+	qe.Done()
+
+	// simulate tx3 that updates the value of key1, which keeps its metadata
+	s3, _ := txMgr.NewTxSimulator("test_tx3")
+	s3.SetState("ns1", "key1", []byte("value1_3"))
+	s3.Done()
+	txRWSet3, _ := s3.GetTxSimulationResults()
+	txMgrHelper.validateAndCommitRWSet(txRWSet3.PubSimulationResults)
+
+	// simulate tx4 that reads and then deletes the metadata of key1
+	s4, _ := txMgr.NewTxSimulator("test_tx4")
+	readMetadata, err = s4.GetStateMetadata("ns1", "key1")
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, readMetadata, metadata)
+	s4.DeleteStateMetadata("ns1", "key1")
+	s4.Done()
+	txRWSet4, _ := s4.GetTxSimulationResults()
+	txMgrHelper.validateAndCommitRWSet(txRWSet4.PubSimulationResults)
+
+	qe, _ = txMgr.NewQueryExecutor("test_tx5")
+	defer qe.Done()
+	value, err := qe.GetState("ns1", "key1")
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, value, []byte("value1_3"))
+	readMetadata, err = qe.GetStateMetadata("ns1", "key1")
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertNil(t, readMetadata)
+}
+
+func TestRemoveExpiredPvtData(t *testing.T) {
+	// the state database is read without couchdb specifics
+	testEnv := testEnvs[0]
+	testEnv.init(t, "testremoveexpiredpvtdata")
+	defer testEnv.cleanup()
+	txMgr := testEnv.getTxMgr()
+	db := testEnv.getVDB()
+
+	// key1 and key2 are written by the transaction 0 of block 1, key2 is written again by block 2
+	batch := privacyenabledstate.NewUpdateBatch()
+	for key, ht := range map[string]*version.Height{"key1": version.NewHeight(1, 0), "key2": version.NewHeight(2, 0)} {
+		batch.PvtUpdates.Put("ns1", "coll1", key, []byte("value"), ht)
+		batch.HashUpdates.Put("ns1", "coll1", util.ComputeStringHash(key), util.ComputeStringHash("value"), ht)
+	}
+	assert.NoError(t, db.ApplyPrivacyAwareUpdates(batch, version.NewHeight(2, 0)))
+
+	// the pvt data of the transaction 0 of block 1 expires at the block being committed
+	bg, _ := testutil.NewBlockGenerator(t, "testLedger", false)
+	block := bg.NextBlock([][]byte{})
+	assert.NoError(t, txMgr.ValidateAndPrepare(&ledger.BlockAndPvtData{Block: block}, true))
+	assert.NoError(t, txMgr.RemoveExpiredPvtData([]*txmgr.ExpiredPvtWrites{{
+		Height:     version.NewHeight(1, 0),
+		Namespace:  "ns1",
+		Collection: "coll1",
+		KeyHashes:  [][]byte{util.ComputeStringHash("key1"), util.ComputeStringHash("key2")},
+		Keys:       []string{"key1", "key2"},
+	}}))
+	assert.NoError(t, txMgr.Commit())
+
+	vv, err := db.GetPrivateData("ns1", "coll1", "key1")
+	assert.NoError(t, err)
+	assert.Nil(t, vv)
+	vv, err = db.GetValueHash("ns1", "coll1", util.ComputeStringHash("key1"))
+	assert.NoError(t, err)
+	assert.Nil(t, vv)
+
+	// key2 was written since, it is kept
+	vv, err = db.GetPrivateData("ns1", "coll1", "key2")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("value"), vv.Value)
+	vv, err = db.GetValueHash("ns1", "coll1", util.ComputeStringHash("key2"))
+	assert.NoError(t, err)
+	assert.NotNil(t, vv)
+}
+
 func createTestKey(i int) string {
 	if i == 0 {
 		return ""
@@ -629,3 +929,98 @@ func TestValidateKey(t *testing.T) {
 		testEnv.cleanup()
 	}
 }
+
+func TestValidateCompositeKeyFormat(t *testing.T) {
+	for _, testEnv := range testEnvs {
+		testLedgerID := "test.validate.composite.key"
+		testEnv.init(t, testLedgerID)
+		txSimulator, _ := testEnv.getTxMgr().NewTxSimulator("test_tx1")
+		assert.NoError(t, txSimulator.SetState("ns1", "key1", []byte("value")))
+		assert.NoError(t, txSimulator.SetState("ns1", "\x00color\x00blue\x001\x00", []byte("value")))
+		assert.NoError(t, txSimulator.DeleteState("ns1", "\x00color\x00"))
+		// the null character is only allowed as the separator of a composite key
+		assert.EqualError(t, txSimulator.SetState("ns1", "key\x00", []byte("value")),
+			`key ["key\x00"] contains the null character, which is reserved to the separators of the composite keys`)
+		assert.Error(t, txSimulator.SetState("ns1", "\x00color\x00blue", []byte("value")))
+		assert.Error(t, txSimulator.SetState("ns1", "\x00", []byte("value")))
+		assert.Error(t, txSimulator.SetState("ns1", "\x00color\x00"+string(utf8.MaxRune)+"\x00", []byte("value")))
+		assert.Error(t, txSimulator.SetStateMetadata("ns1", "key\x00", nil))
+		assert.Error(t, txSimulator.SetPrivateData("ns1", "coll1", "key\x00", []byte("value")))
+		testEnv.cleanup()
+	}
+}
+
+func TestGetStateByPartialCompositeKeyWithPagination(t *testing.T) {
+	for _, testEnv := range testEnvs {
+		t.Logf("Running test for TestEnv = %s", testEnv.getName())
+		testLedgerID := "testpartialcompositekeypagination"
+		testEnv.init(t, testLedgerID)
+		testGetStateByPartialCompositeKeyWithPagination(t, testEnv)
+		testEnv.cleanup()
+	}
+}
+
+func testGetStateByPartialCompositeKeyWithPagination(t *testing.T, env testEnv) {
+	txMgr := env.getTxMgr()
+	txMgrHelper := newTxMgrTestHelper(t, txMgr)
+	s, _ := txMgr.NewTxSimulator("test_tx1")
+	for _, key := range []string{"\x00color\x00blue\x001\x00", "\x00color\x00blue\x002\x00", "\x00color\x00blue\x003\x00",
+		"\x00color\x00bluegreen\x001\x00", "\x00color\x00red\x001\x00", "\x00colors\x00blue\x00", "color"} {
+		assert.NoError(t, s.SetState("ns", key, []byte(key)))
+	}
+	s.Done()
+	txRWSet, _ := s.GetTxSimulationResults()
+	txMgrHelper.validateAndCommitRWSet(txRWSet.PubSimulationResults)
+
+	// getPage returns the keys of a page and the bookmark of the next one
+	getPage := func(qe ledger.QueryExecutor, attributes []string, pageSize int32, bookmark string) ([]string, string) {
+		itr, err := qe.GetStateByPartialCompositeKeyWithPagination("ns", "color", attributes, pageSize, bookmark)
+		assert.NoError(t, err)
+		keys := []string{}
+		for {
+			res, err := itr.Next()
+			assert.NoError(t, err)
+			if res == nil {
+				return keys, itr.GetBookmarkAndClose()
+			}
+			keys = append(keys, res.(*queryresult.KV).Key)
+		}
+	}
+
+	qe, _ := txMgr.NewQueryExecutor("test_tx2")
+	defer qe.Done()
+	keys, bookmark := getPage(qe, []string{"blue"}, 2, "")
+	assert.Equal(t, []string{"\x00color\x00blue\x001\x00", "\x00color\x00blue\x002\x00"}, keys)
+	assert.Equal(t, "\x00color\x00blue\x003\x00", bookmark)
+	keys, bookmark = getPage(qe, []string{"blue"}, 2, bookmark)
+	assert.Equal(t, []string{"\x00color\x00blue\x003\x00"}, keys)
+	assert.Equal(t, "", bookmark)
+	// a page that ends with the last key has no next page
+	keys, bookmark = getPage(qe, []string{"blue"}, 3, "")
+	assert.Len(t, keys, 3)
+	assert.Equal(t, "", bookmark)
+	keys, bookmark = getPage(qe, nil, 10, "")
+	assert.Len(t, keys, 5)
+	assert.Equal(t, "", bookmark)
+
+	_, err := qe.GetStateByPartialCompositeKeyWithPagination("ns", "color", []string{"blue"}, 2, "\x00color\x00red\x001\x00")
+	assert.Error(t, err)
+	_, err = qe.GetStateByPartialCompositeKeyWithPagination("ns", "color", []string{"blue"}, 0, "")
+	assert.EqualError(t, err, "the page size must be positive")
+	_, err = qe.GetStateByPartialCompositeKeyWithPagination("ns", "color", []string{"bl\x00ue"}, 2, "")
+	assert.Error(t, err)
+
+	// a page read by a simulation is protected against phantoms up to the bookmark
+	s, _ = txMgr.NewTxSimulator("test_tx3")
+	keys, bookmark = getPage(s, []string{"blue"}, 1, "")
+	assert.Equal(t, []string{"\x00color\x00blue\x001\x00"}, keys)
+	assert.Equal(t, "\x00color\x00blue\x002\x00", bookmark)
+	s.Done()
+	txRWSet, _ = s.GetTxSimulationResults()
+	rwset, err := rwsetutil.TxRwSetFromProtoMsg(txRWSet.PubSimulationResults)
+	assert.NoError(t, err)
+	rqi := rwset.NsRwSets[0].KvRwSet.RangeQueriesInfo[0]
+	assert.Equal(t, "\x00color\x00blue\x00", rqi.StartKey)
+	assert.Equal(t, "\x00color\x00blue\x002\x00", rqi.EndKey)
+	assert.False(t, rqi.ItrExhausted)
+}
@@ -65,6 +65,14 @@ func newSimulatorWrapper(actualSim ledger.TxSimulator, tStore transientstore.Sto
 	return &transientHandlerTxSimulator{actualSim, tStore, txid, simBlkHt}
 }
 
+// EnableReadYourWrites implements method in interface `ledger.ReadYourWritesEnabler` by
+// passing it on to the wrapped txsimulator, if that supports the read-your-writes mode
+func (w *transientHandlerTxSimulator) EnableReadYourWrites() {
+	if rywSim, ok := w.TxSimulator.(ledger.ReadYourWritesEnabler); ok {
+		rywSim.EnableReadYourWrites()
+	}
+}
+
 func (w *transientHandlerTxSimulator) GetTxSimulationResults() (*ledger.TxSimulationResults, error) {
 	var txSimRes *ledger.TxSimulationResults
 	var pvtSimBytes []byte
@@ -18,18 +18,39 @@ package txmgr
 
 import (
 	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/privacyenabledstate"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/version"
 )
 
 // TxMgr - an interface that a transaction manager should implement
 type TxMgr interface {
 	NewQueryExecutor(txid string) (ledger.QueryExecutor, error)
+	NewQueryExecutorWithIsolation(txid string, level ledger.IsolationLevel) (ledger.QueryExecutor, error)
 	NewTxSimulator(txid string) (ledger.TxSimulator, error)
 	ValidateAndPrepare(blockAndPvtdata *ledger.BlockAndPvtData, doMVCCValidation bool) error
+	RemoveExpiredPvtData(expired []*ExpiredPvtWrites) error
 	GetLastSavepoint() (*version.Height, error)
 	ShouldRecover(lastAvailableBlock uint64) (bool, uint64, error)
 	CommitLostBlock(blockAndPvtdata *ledger.BlockAndPvtData) error
+	// PrepareUpdates returns the updates of the block to the state database, as recommitting the block prepares
+	// them, without keeping them for Commit
+	PrepareUpdates(blockAndPvtdata *ledger.BlockAndPvtData) (*privacyenabledstate.UpdateBatch, error)
+	// ReadStateDiff returns the committed values of the keys the given updates write or delete, as state
+	// differences, and the height of the state database they are read at
+	ReadStateDiff(updates *privacyenabledstate.UpdateBatch) (uint64, []*ledger.StateDiffEntry, error)
+	// ApplyStateDiff writes the state differences to the state database, with the given height as the savepoint
+	ApplyStateDiff(entries []*ledger.StateDiffEntry, savepoint *version.Height) error
 	Commit() error
 	Rollback()
 	Shutdown()
 }
+
+// ExpiredPvtWrites identifies the writes of a transaction to a collection whose pvt data expired,
+// by the hashes of the keys written and, if the pvt data is available, by the keys themselves
+type ExpiredPvtWrites struct {
+	Height     *version.Height
+	Namespace  string
+	Collection string
+	KeyHashes  [][]byte
+	Keys       []string
+}
@@ -17,6 +17,9 @@ limitations under the License.
 package statebasedval
 
 import (
+	"encoding/hex"
+	"fmt"
+
 	"github.com/hyperledger/fabric/common/flogging"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/privacyenabledstate"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/rwsetutil"
@@ -44,16 +47,24 @@ func (v *Validator) ValidateAndPrepareBatch(block *valinternal.Block, doMVCCVali
 	updates := valinternal.NewPubAndHashUpdates()
 	for _, tx := range block.Txs {
 		var validationCode peer.TxValidationCode
+		var validationReason *peer.TxValidationReason
 		var err error
-		if validationCode, err = v.validateEndorserTX(tx.RWSet, doMVCCValidation, updates); err != nil {
+		if validationCode, validationReason, err = v.validateEndorserTX(tx.RWSet, doMVCCValidation, updates); err != nil {
 			return nil, err
 		}
 
 		tx.ValidationCode = validationCode
+		if validationReason != nil {
+			validationReason.TxIndex = uint64(tx.IndexInBlock)
+			validationReason.Code = validationCode
+			tx.ValidationReason = validationReason
+		}
 		if validationCode == peer.TxValidationCode_VALID {
 			logger.Debugf("Block [%d] Transaction index [%d] TxId [%s] marked as valid by state validator", block.Num, tx.IndexInBlock, tx.ID)
 			committingTxHeight := version.NewHeight(block.Num, uint64(tx.IndexInBlock))
-			updates.ApplyWriteSet(tx.RWSet, committingTxHeight)
+			if err = updates.ApplyWriteSet(tx.RWSet, committingTxHeight, v.db); err != nil {
+				return nil, err
+			}
 		} else {
 			logger.Warningf("Block [%d] Transaction index [%d] TxId [%s] marked as invalid by state validator. Reason code [%s]",
 				block.Num, tx.IndexInBlock, tx.ID, validationCode.String())
@@ -66,57 +77,74 @@ func (v *Validator) ValidateAndPrepareBatch(block *valinternal.Block, doMVCCVali
 func (v *Validator) validateEndorserTX(
 	txRWSet *rwsetutil.TxRwSet,
 	doMVCCValidation bool,
-	updates *valinternal.PubAndHashUpdates) (peer.TxValidationCode, error) {
+	updates *valinternal.PubAndHashUpdates) (peer.TxValidationCode, *peer.TxValidationReason, error) {
 
 	var validationCode = peer.TxValidationCode_VALID
+	var validationReason *peer.TxValidationReason
 	var err error
 	//mvccvalidation, may invalidate transaction
 	if doMVCCValidation {
-		validationCode, err = v.validateTx(txRWSet, updates)
+		validationCode, validationReason, err = v.validateTx(txRWSet, updates)
 	}
-	return validationCode, err
+	return validationCode, validationReason, err
 }
 
-func (v *Validator) validateTx(txRWSet *rwsetutil.TxRwSet, updates *valinternal.PubAndHashUpdates) (peer.TxValidationCode, error) {
+// validateTx returns the validation code of the transaction and, for an invalid transaction,
+// the reason naming the read that is in conflict
+func (v *Validator) validateTx(txRWSet *rwsetutil.TxRwSet, updates *valinternal.PubAndHashUpdates) (peer.TxValidationCode, *peer.TxValidationReason, error) {
 	// Uncomment the following only for local debugging. Don't want to print data in the logs in production
 	//logger.Debugf("validateTx - validating txRWSet: %s", spew.Sdump(txRWSet))
 	for _, nsRWSet := range txRWSet.NsRwSets {
 		ns := nsRWSet.NameSpace
 		// Validate public reads
-		if valid, err := v.validateReadSet(ns, nsRWSet.KvRwSet.Reads, updates.PubUpdates); !valid || err != nil {
+		if conflict, err := v.validateReadSet(ns, nsRWSet.KvRwSet.Reads, updates.PubUpdates); conflict != nil || err != nil {
 			if err != nil {
-				return peer.TxValidationCode(-1), err
+				return peer.TxValidationCode(-1), nil, err
 			}
-			return peer.TxValidationCode_MVCC_READ_CONFLICT, nil
+			return peer.TxValidationCode_MVCC_READ_CONFLICT, &peer.TxValidationReason{
+				Namespace: ns,
+				Key:       conflict.Key,
+				Message:   "the version of the key read was updated",
+			}, nil
 		}
 		// Validate range queries for phantom items
-		if valid, err := v.validateRangeQueries(ns, nsRWSet.KvRwSet.RangeQueriesInfo, updates.PubUpdates); !valid || err != nil {
+		if conflict, err := v.validateRangeQueries(ns, nsRWSet.KvRwSet.RangeQueriesInfo, updates.PubUpdates); conflict != nil || err != nil {
 			if err != nil {
-				return peer.TxValidationCode(-1), err
+				return peer.TxValidationCode(-1), nil, err
 			}
-			return peer.TxValidationCode_PHANTOM_READ_CONFLICT, nil
+			return peer.TxValidationCode_PHANTOM_READ_CONFLICT, &peer.TxValidationReason{
+				Namespace: ns,
+				Key:       conflict.StartKey,
+				Message:   fmt.Sprintf("the results of the range query [%s, %s) changed", conflict.StartKey, conflict.EndKey),
+			}, nil
 		}
 		// Validate hashes for private reads
-		if valid, err := v.validateNsHashedReadSets(ns, nsRWSet.CollHashedRwSets, updates.HashUpdates); !valid || err != nil {
+		if coll, conflict, err := v.validateNsHashedReadSets(ns, nsRWSet.CollHashedRwSets, updates.HashUpdates); conflict != nil || err != nil {
 			if err != nil {
-				return peer.TxValidationCode(-1), err
+				return peer.TxValidationCode(-1), nil, err
 			}
-			return peer.TxValidationCode_MVCC_READ_CONFLICT, nil
+			return peer.TxValidationCode_MVCC_READ_CONFLICT, &peer.TxValidationReason{
+				Namespace:  ns,
+				Collection: coll,
+				Key:        hex.EncodeToString(conflict.KeyHash),
+				Message:    "the version of the private key read was updated",
+			}, nil
 		}
 	}
-	return peer.TxValidationCode_VALID, nil
+	return peer.TxValidationCode_VALID, nil, nil
 }
 
 ////////////////////////////////////////////////////////////////////////////////
 /////                 Validation of public read-set
 ////////////////////////////////////////////////////////////////////////////////
-func (v *Validator) validateReadSet(ns string, kvReads []*kvrwset.KVRead, updates *privacyenabledstate.PubUpdateBatch) (bool, error) {
+// validateReadSet returns the first read that is in conflict, or nil if all the reads are valid
+func (v *Validator) validateReadSet(ns string, kvReads []*kvrwset.KVRead, updates *privacyenabledstate.PubUpdateBatch) (*kvrwset.KVRead, error) {
 	for _, kvRead := range kvReads {
 		if valid, err := v.validateKVRead(ns, kvRead, updates); !valid || err != nil {
-			return valid, err
+			return kvRead, err
 		}
 	}
-	return true, nil
+	return nil, nil
 }
 
 // validateKVRead performs mvcc check for a key read during transaction simulation.
@@ -147,13 +175,14 @@ func (v *Validator) validateKVRead(ns string, kvRead *kvrwset.KVRead, updates *p
 ////////////////////////////////////////////////////////////////////////////////
 /////                 Validation of range queries
 ////////////////////////////////////////////////////////////////////////////////
-func (v *Validator) validateRangeQueries(ns string, rangeQueriesInfo []*kvrwset.RangeQueryInfo, updates *privacyenabledstate.PubUpdateBatch) (bool, error) {
+// validateRangeQueries returns the first range query whose results changed, or nil if all the range queries are valid
+func (v *Validator) validateRangeQueries(ns string, rangeQueriesInfo []*kvrwset.RangeQueryInfo, updates *privacyenabledstate.PubUpdateBatch) (*kvrwset.RangeQueryInfo, error) {
 	for _, rqi := range rangeQueriesInfo {
 		if valid, err := v.validateRangeQuery(ns, rqi, updates); !valid || err != nil {
-			return valid, err
+			return rqi, err
 		}
 	}
-	return true, nil
+	return nil, nil
 }
 
 // validateRangeQuery performs a phatom read check i.e., it
@@ -189,24 +218,26 @@ func (v *Validator) validateRangeQuery(ns string, rangeQueryInfo *kvrwset.RangeQ
 ////////////////////////////////////////////////////////////////////////////////
 /////                 Validation of hashed read-set
 ////////////////////////////////////////////////////////////////////////////////
+// validateNsHashedReadSets returns the collection and the first hashed read that is in conflict,
+// or a nil read if all the hashed reads are valid
 func (v *Validator) validateNsHashedReadSets(ns string, collHashedRWSets []*rwsetutil.CollHashedRwSet,
-	updates *privacyenabledstate.HashedUpdateBatch) (bool, error) {
+	updates *privacyenabledstate.HashedUpdateBatch) (string, *kvrwset.KVReadHash, error) {
 	for _, collHashedRWSet := range collHashedRWSets {
-		if valid, err := v.validateCollHashedReadSet(ns, collHashedRWSet.CollectionName, collHashedRWSet.HashedRwSet.HashedReads, updates); !valid || err != nil {
-			return valid, err
+		if conflict, err := v.validateCollHashedReadSet(ns, collHashedRWSet.CollectionName, collHashedRWSet.HashedRwSet.HashedReads, updates); conflict != nil || err != nil {
+			return collHashedRWSet.CollectionName, conflict, err
 		}
 	}
-	return true, nil
+	return "", nil, nil
 }
 
 func (v *Validator) validateCollHashedReadSet(ns, coll string, kvReadHashes []*kvrwset.KVReadHash,
-	updates *privacyenabledstate.HashedUpdateBatch) (bool, error) {
+	updates *privacyenabledstate.HashedUpdateBatch) (*kvrwset.KVReadHash, error) {
 	for _, kvReadHash := range kvReadHashes {
 		if valid, err := v.validateKVReadHash(ns, coll, kvReadHash, updates); !valid || err != nil {
-			return valid, err
+			return kvReadHash, err
 		}
 	}
-	return true, nil
+	return nil, nil
 }
 
 // validateKVReadHash performs mvcc check for a hash of a key that is present in the private data space
@@ -25,6 +25,8 @@ import (
 	"github.com/hyperledger/fabric/common/ledger/testutil"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/privacyenabledstate"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/rwsetutil"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statedb"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statemetadata"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/validator/valinternal"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/version"
 	"github.com/hyperledger/fabric/protos/ledger/rwset/kvrwset"
@@ -206,6 +208,105 @@ func TestPhantomHashBasedValidation(t *testing.T) {
 	checkValidation(t, validator, getTestPubSimulationRWSet(t, rwsetBuilder2), []int{0})
 }
 
+func TestMetadataWrites(t *testing.T) {
+	testDBEnv := privacyenabledstate.LevelDBCommonStorageTestEnv{}
+	testDBEnv.Init(t)
+	defer testDBEnv.Cleanup()
+	db := testDBEnv.GetDBHandle("TestDB")
+
+	//populate db with initial data
+	batch := privacyenabledstate.NewUpdateBatch()
+	batch.PubUpdates.PutValAndMetadata("ns1", "key1", []byte("value1"), []byte("metadata1"), version.NewHeight(1, 0))
+	batch.PubUpdates.PutValAndMetadata("ns1", "key2", []byte("value2"), []byte("metadata2"), version.NewHeight(1, 1))
+	batch.PubUpdates.Put("ns1", "key3", []byte("value3"), version.NewHeight(1, 2))
+	batch.PubUpdates.PutValAndMetadata("ns1", "key4", []byte("value4"), []byte("metadata4"), version.NewHeight(1, 3))
+	db.ApplyPrivacyAwareUpdates(batch, version.NewHeight(1, 3))
+
+	validator := NewValidator(db)
+	metadata := map[string][]byte{"name1": []byte("value1")}
+	entries := statemetadata.ToEntries(metadata)
+	metadataBytes, err := statemetadata.Serialize(metadata)
+	testutil.AssertNoError(t, err, "")
+
+	// tx0 updates the value of key1, which keeps its metadata
+	rwsetBuilder0 := rwsetutil.NewRWSetBuilder()
+	rwsetBuilder0.AddToWriteSet("ns1", "key1", []byte("value1_new"))
+	// tx1 updates the metadata of key2 and of key3, and of the non-existing key5
+	rwsetBuilder1 := rwsetutil.NewRWSetBuilder()
+	rwsetBuilder1.AddToMetadataWriteSet("ns1", "key2", entries)
+	rwsetBuilder1.AddToMetadataWriteSet("ns1", "key3", entries)
+	rwsetBuilder1.AddToMetadataWriteSet("ns1", "key5", entries)
+	// tx2 updates the value and deletes the metadata of key4, and updates the value of key3, which keeps the metadata set by tx1
+	rwsetBuilder2 := rwsetutil.NewRWSetBuilder()
+	rwsetBuilder2.AddToWriteSet("ns1", "key4", []byte("value4_new"))
+	rwsetBuilder2.AddToMetadataWriteSet("ns1", "key4", nil)
+	rwsetBuilder2.AddToWriteSet("ns1", "key3", []byte("value3_new"))
+	// tx3 deletes key2
+	rwsetBuilder3 := rwsetutil.NewRWSetBuilder()
+	rwsetBuilder3.AddToWriteSet("ns1", "key2", nil)
+
+	var txs []*valinternal.Transaction
+	for i, txRWSet := range getTestPubSimulationRWSet(t, rwsetBuilder0, rwsetBuilder1, rwsetBuilder2, rwsetBuilder3) {
+		txs = append(txs, &valinternal.Transaction{ID: fmt.Sprintf("txid-%d", i), IndexInBlock: i, RWSet: txRWSet})
+	}
+	updates, err := validator.ValidateAndPrepareBatch(&valinternal.Block{Num: 2, Txs: txs}, true)
+	testutil.AssertNoError(t, err, "")
+
+	testutil.AssertEquals(t, updates.PubUpdates.Get("ns1", "key1"),
+		&statedb.VersionedValue{Value: []byte("value1_new"), Metadata: []byte("metadata1"), Version: version.NewHeight(2, 0)})
+	testutil.AssertEquals(t, updates.PubUpdates.Get("ns1", "key2"),
+		&statedb.VersionedValue{Version: version.NewHeight(2, 3)})
+	testutil.AssertEquals(t, updates.PubUpdates.Get("ns1", "key3"),
+		&statedb.VersionedValue{Value: []byte("value3_new"), Metadata: metadataBytes, Version: version.NewHeight(2, 2)})
+	testutil.AssertEquals(t, updates.PubUpdates.Get("ns1", "key4"),
+		&statedb.VersionedValue{Value: []byte("value4_new"), Version: version.NewHeight(2, 2)})
+	testutil.AssertNil(t, updates.PubUpdates.Get("ns1", "key5"))
+}
+
+func TestValidationReasons(t *testing.T) {
+	testDBEnv := privacyenabledstate.LevelDBCommonStorageTestEnv{}
+	testDBEnv.Init(t)
+	defer testDBEnv.Cleanup()
+	db := testDBEnv.GetDBHandle("TestDB")
+
+	batch := privacyenabledstate.NewUpdateBatch()
+	batch.PubUpdates.Put("ns1", "key1", []byte("value1"), version.NewHeight(1, 0))
+	batch.PubUpdates.Put("ns1", "key2", []byte("value2"), version.NewHeight(1, 1))
+	db.ApplyPrivacyAwareUpdates(batch, version.NewHeight(1, 1))
+
+	validator := NewValidator(db)
+
+	rwsetBuilder1 := rwsetutil.NewRWSetBuilder()
+	rwsetBuilder1.AddToReadSet("ns1", "key1", version.NewHeight(1, 0))
+	rwsetBuilder2 := rwsetutil.NewRWSetBuilder()
+	rwsetBuilder2.AddToReadSet("ns1", "key1", version.NewHeight(1, 0))
+	rwsetBuilder2.AddToReadSet("ns1", "key2", version.NewHeight(1, 0))
+	rwsetBuilder3 := rwsetutil.NewRWSetBuilder()
+	rqi3 := &kvrwset.RangeQueryInfo{StartKey: "key1", EndKey: "key3", ItrExhausted: true}
+	rqi3.SetRawReads([]*kvrwset.KVRead{rwsetutil.NewKVRead("key1", version.NewHeight(1, 0))})
+	rwsetBuilder3.AddToRangeQuerySet("ns1", rqi3)
+
+	var trans []*valinternal.Transaction
+	for i, txRWSet := range getTestPubSimulationRWSet(t, rwsetBuilder1, rwsetBuilder2, rwsetBuilder3) {
+		trans = append(trans, &valinternal.Transaction{ID: fmt.Sprintf("txid-%d", i), IndexInBlock: i, RWSet: txRWSet})
+	}
+	block := &valinternal.Block{Num: 2, Txs: trans}
+	_, err := validator.ValidateAndPrepareBatch(block, true)
+	testutil.AssertNoError(t, err, "")
+
+	testutil.AssertNil(t, block.Txs[0].ValidationReason)
+	reason := block.Txs[1].ValidationReason
+	testutil.AssertNotNil(t, reason)
+	testutil.AssertEquals(t, reason.TxIndex, uint64(1))
+	testutil.AssertEquals(t, reason.Code, peer.TxValidationCode_MVCC_READ_CONFLICT)
+	testutil.AssertEquals(t, reason.Namespace, "ns1")
+	testutil.AssertEquals(t, reason.Key, "key2")
+	reason = block.Txs[2].ValidationReason
+	testutil.AssertNotNil(t, reason)
+	testutil.AssertEquals(t, reason.Code, peer.TxValidationCode_PHANTOM_READ_CONFLICT)
+	testutil.AssertEquals(t, reason.Key, "key1")
+}
+
 func checkValidation(t *testing.T, val *Validator, transRWSets []*rwsetutil.TxRwSet, expectedInvalidTxIndexes []int) {
 	var trans []*valinternal.Transaction
 	for i, tranRWSet := range transRWSets {
@@ -56,7 +56,9 @@ func (impl *DefaultImpl) ValidateAndPrepareBatch(blockAndPvtdata *ledger.BlockAn
 		return nil, err
 	}
 	logger.Debug("postprocessing ProtoBlock...")
-	postprocessProtoBlock(block, internalBlock)
+	if err = postprocessProtoBlock(block, internalBlock); err != nil {
+		return nil, err
+	}
 	logger.Debug("ValidateAndPrepareBatch() complete")
 	return &privacyenabledstate.UpdateBatch{
 		PubUpdates:  pubAndHashUpdates.PubUpdates,
@@ -175,12 +175,18 @@ func processNonEndorserTx(txEnv *common.Envelope, txid string, txType common.Hea
 }
 
 // postprocessProtoBlock updates the proto block's validation flags (in metadata) by the results of validation process
-func postprocessProtoBlock(block *common.Block, validatedBlock *valinternal.Block) {
+// and records the reasons of the transactions invalidated by the validation process alongside the flags
+func postprocessProtoBlock(block *common.Block, validatedBlock *valinternal.Block) error {
 	txsFilter := util.TxValidationFlags(block.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER])
+	var reasons []*peer.TxValidationReason
 	for _, tx := range validatedBlock.Txs {
 		txsFilter.SetFlag(tx.IndexInBlock, tx.ValidationCode)
+		if tx.ValidationReason != nil {
+			reasons = append(reasons, tx.ValidationReason)
+		}
 	}
 	block.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER] = txsFilter
+	return util.AddTxValidationReasons(block, reasons...)
 }
 
 func addPvtRWSetToPvtUpdateBatch(pvtRWSet *rwsetutil.TxPvtRwSet, pvtUpdateBatch *privacyenabledstate.PvtUpdateBatch, ver *version.Height) {
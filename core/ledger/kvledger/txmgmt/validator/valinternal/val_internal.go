@@ -7,12 +7,18 @@ SPDX-License-Identifier: Apache-2.0
 package valinternal
 
 import (
+	"github.com/hyperledger/fabric/common/flogging"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/privacyenabledstate"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/rwsetutil"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statedb"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statemetadata"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/version"
+	"github.com/hyperledger/fabric/protos/ledger/rwset/kvrwset"
 	"github.com/hyperledger/fabric/protos/peer"
 )
 
+var logger = flogging.MustGetLogger("valinternal")
+
 // InternalValidator is supposed to validate the transactions based on public data and hashes present in a block
 // and returns a batch that should be used to update the state
 type InternalValidator interface {
@@ -33,6 +39,8 @@ type Transaction struct {
 	ID             string
 	RWSet          *rwsetutil.TxRwSet
 	ValidationCode peer.TxValidationCode
+	// ValidationReason explains the validation code of an invalid transaction, when known
+	ValidationReason *peer.TxValidationReason
 }
 
 // PubAndHashUpdates encapsulates public and hash updates. The intended use of this to hold the updates
@@ -78,16 +86,56 @@ func (t *Transaction) RetrieveHash(ns string, coll string) []byte {
 	return nil
 }
 
-// ApplyWriteSet adds (or deletes) the key/values present in the write set to the PubAndHashUpdates
-func (u *PubAndHashUpdates) ApplyWriteSet(txRWSet *rwsetutil.TxRwSet, txHeight *version.Height) {
+// ApplyWriteSet adds (or deletes) the key/values present in the write set to the PubAndHashUpdates.
+// The metadata of a key is kept across the updates of its value, unless the write set also carries a
+// metadata write for the key. A metadata write alone updates the metadata (and the version) of an existing
+// key and is ignored for a key that does not exist. The db is used for looking up the existing metadata
+// and values of the keys that are not updated by the preceding transactions in the block
+func (u *PubAndHashUpdates) ApplyWriteSet(txRWSet *rwsetutil.TxRwSet, txHeight *version.Height, db privacyenabledstate.DB) error {
 	for _, nsRWSet := range txRWSet.NsRwSets {
 		ns := nsRWSet.NameSpace
+		metadataWrites := make(map[string]*kvrwset.KVMetadataWrite)
+		for _, metadataWrite := range nsRWSet.KvRwSet.MetadataWrites {
+			metadataWrites[metadataWrite.Key] = metadataWrite
+		}
+
 		for _, kvWrite := range nsRWSet.KvRwSet.Writes {
 			if kvWrite.IsDelete {
 				u.PubUpdates.Delete(ns, kvWrite.Key, txHeight)
+				delete(metadataWrites, kvWrite.Key)
+				continue
+			}
+			var metadata []byte
+			var err error
+			if metadataWrite, ok := metadataWrites[kvWrite.Key]; ok {
+				metadata, err = statemetadata.SerializeEntries(metadataWrite.Entries)
+				delete(metadataWrites, kvWrite.Key)
 			} else {
-				u.PubUpdates.Put(ns, kvWrite.Key, kvWrite.Value, txHeight)
+				metadata, err = u.latestMetadata(ns, kvWrite.Key, db)
+			}
+			if err != nil {
+				return err
+			}
+			u.PubUpdates.PutValAndMetadata(ns, kvWrite.Key, kvWrite.Value, metadata, txHeight)
+		}
+
+		for _, metadataWrite := range nsRWSet.KvRwSet.MetadataWrites {
+			if _, ok := metadataWrites[metadataWrite.Key]; !ok {
+				continue
+			}
+			vv, err := u.latestValue(ns, metadataWrite.Key, db)
+			if err != nil {
+				return err
 			}
+			if vv == nil || vv.Value == nil {
+				logger.Debugf("Ignoring the metadata write for the non-existing key [%s:%s]", ns, metadataWrite.Key)
+				continue
+			}
+			metadata, err := statemetadata.SerializeEntries(metadataWrite.Entries)
+			if err != nil {
+				return err
+			}
+			u.PubUpdates.PutValAndMetadata(ns, metadataWrite.Key, vv.Value, metadata, txHeight)
 		}
 
 		for _, collHashRWset := range nsRWSet.CollHashedRwSets {
@@ -101,4 +149,22 @@ func (u *PubAndHashUpdates) ApplyWriteSet(txRWSet *rwsetutil.TxRwSet, txHeight *
 			}
 		}
 	}
+	return nil
+}
+
+// latestValue returns the value of the key as updated by the preceding transactions in the block, if any,
+// or else the committed value. A nil VersionedValue, or one with a nil Value, means that the key does not exist
+func (u *PubAndHashUpdates) latestValue(ns string, key string, db privacyenabledstate.DB) (*statedb.VersionedValue, error) {
+	if vv := u.PubUpdates.Get(ns, key); vv != nil {
+		return vv, nil
+	}
+	return db.GetState(ns, key)
+}
+
+func (u *PubAndHashUpdates) latestMetadata(ns string, key string, db privacyenabledstate.DB) ([]byte, error) {
+	vv, err := u.latestValue(ns, key, db)
+	if err != nil || vv == nil {
+		return nil, err
+	}
+	return vv.Metadata, nil
 }
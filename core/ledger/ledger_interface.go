@@ -19,9 +19,11 @@ package ledger
 import (
 	"github.com/golang/protobuf/proto"
 	commonledger "github.com/hyperledger/fabric/common/ledger"
+	"github.com/hyperledger/fabric/common/ledger/blkstorage"
 	"github.com/hyperledger/fabric/protos/common"
 	"github.com/hyperledger/fabric/protos/ledger/rwset"
 	"github.com/hyperledger/fabric/protos/peer"
+	"github.com/pkg/errors"
 )
 
 // PeerLedgerProvider provides handle to ledger instances
@@ -52,6 +54,23 @@ type PeerLedger interface {
 	GetBlockByTxID(txID string) (*common.Block, error)
 	// GetTxValidationCodeByTxID returns reason code of transaction validation
 	GetTxValidationCodeByTxID(txID string) (peer.TxValidationCode, error)
+	// GetTxValidationCodes returns the reason codes of validation of the given transactions.
+	// The transactions that are not found are left out of the returned map
+	GetTxValidationCodes(txIDs []string) (map[string]peer.TxValidationCode, error)
+	// GetTxValidationReasonByTxID returns the reason recorded for the validation code of the transaction,
+	// which is nil for a valid transaction and for a transaction committed without a recorded reason
+	GetTxValidationReasonByTxID(txID string) (*peer.TxValidationReason, error)
+	// GetTransactionsByCreator returns, in the order of the chain, up to limit transactions created by the given
+	// serialized identity at or after the given position, along with the position of the next one, nil if there
+	// are no more. The transactions committed before the peer indexed their creators are not returned
+	GetTransactionsByCreator(creator []byte, start blkstorage.TxPosition, limit int) ([]*peer.ProcessedTransaction, *blkstorage.TxPosition, error)
+	// GetTransactionsByMSPIDAndChaincode returns, in the order of the chain, up to limit transactions submitted by the
+	// org of the given MSP ID to the given chaincode at or after the given position, along with the position of the
+	// next one, nil if there are no more. It requires the index of the transactions by MSP ID and chaincode to be enabled
+	GetTransactionsByMSPIDAndChaincode(mspID string, chaincodeName string, start blkstorage.TxPosition, limit int) ([]*peer.ProcessedTransaction, *blkstorage.TxPosition, error)
+	// GetTransactionProof returns a proof that the transaction with the given id is included
+	// in a block, which can be verified without the other transactions of the block
+	GetTransactionProof(txID string) (*TransactionProof, error)
 	// NewTxSimulator gives handle to a transaction simulator.
 	// A client can obtain more than one 'TxSimulator's for parallel execution.
 	// Any snapshoting/synchronization should be performed at the implementation level if required
@@ -60,6 +79,9 @@ type PeerLedger interface {
 	// A client can obtain more than one 'QueryExecutor's for parallel execution.
 	// Any synchronization should be performed at the implementation level if required
 	NewQueryExecutor() (QueryExecutor, error)
+	// NewQueryExecutorWithIsolation gives handle to a query executor that reads the state
+	// with the given isolation level. See IsolationLevel for the semantics of the levels
+	NewQueryExecutorWithIsolation(level IsolationLevel) (QueryExecutor, error)
 	// NewHistoryQueryExecutor gives handle to a history query executor.
 	// A client can obtain more than one 'HistoryQueryExecutor's for parallel execution.
 	// Any synchronization should be performed at the implementation level if required
@@ -80,8 +102,32 @@ type PeerLedger interface {
 	PurgePrivateData(maxBlockNumToRetain uint64) error
 	// PrivateDataMinBlockNum returns the lowest retained endorsement block height
 	PrivateDataMinBlockNum() (uint64, error)
+	// GetMissingPvtDataInfoForMostRecentBlocks returns the pvt data missing from the most recent
+	// blocks, up to maxBlock blocks, for the collections the peer is eligible for
+	GetMissingPvtDataInfoForMostRecentBlocks(maxBlock int) (MissingPvtDataInfo, error)
+	// VerifyChain recomputes the hashes and previous-hash links of the blocks in the range
+	// [startHeight, endHeight) and cross-checks the block index, reporting the inconsistencies found
+	VerifyChain(startHeight uint64, endHeight uint64) (*blkstorage.CorruptionReport, error)
 	//Prune prunes the blocks/transactions that satisfy the given policy
 	Prune(policy commonledger.PrunePolicy) error
+	// GetConfigHistoryRetriever returns the retriever of the versions of the
+	// collection configs committed to the ledger
+	GetConfigHistoryRetriever() (ConfigHistoryRetriever, error)
+}
+
+// ConfigHistoryRetriever retrieves the versions of the collection configs of the chaincodes,
+// as they are committed to the ledger by the deploy and upgrade transactions of lscc
+type ConfigHistoryRetriever interface {
+	// CollectionConfigAt returns the collection configs of the chaincode in effect at the given
+	// block number, that is the ones committed last by a block not above it, nil if there are none
+	CollectionConfigAt(blockNum uint64, chaincodeName string) (*CollectionConfigInfo, error)
+}
+
+// CollectionConfigInfo encapsulates a version of the collection configs
+// of a chaincode along with the number of the block that committed it
+type CollectionConfigInfo struct {
+	CollectionConfig   *common.CollectionConfigPackage
+	CommittingBlockNum uint64
 }
 
 // ValidatedLedger represents the 'final ledger' after filtering out invalid transactions from PeerLedger.
@@ -90,6 +136,35 @@ type ValidatedLedger interface {
 	commonledger.Ledger
 }
 
+// IsolationLevel defines how the reads of a query executor are isolated from a concurrent block commit
+type IsolationLevel int
+
+const (
+	// IsolationSerializable blocks the commit of the next block until the query executor is done,
+	// so that all the reads of the query executor are served from the same committed height.
+	// This is the isolation level of the query executors returned by NewQueryExecutor
+	IsolationSerializable IsolationLevel = iota
+	// IsolationReadCommitted does not block a block commit. While a block is being committed, the
+	// point reads (GetState, GetPrivateData and their multiple keys variants) are served from the last
+	// fully committed height, and never observe the writes of the block being committed, regardless of
+	// whether the state database applies a block atomically (LevelDB) or in several bulk requests (CouchDB).
+	// Two reads of the same key may still return the values of two different committed heights
+	// if a block commit completes between them. Range scans and rich queries are not isolated
+	// this way, and behave as under IsolationSerializable from the first range scan or query onwards.
+	// When 'ledger.state.readCommittedIsolation' is disabled, this level behaves as IsolationSerializable
+	IsolationReadCommitted
+	// IsolationSnapshot does not block a block commit either, but serves all the reads of the query
+	// executor from the height that was fully committed when the query executor was created, so that
+	// its reads always observe a complete block boundary. The last committed values of the keys updated
+	// by the blocks committed in the meantime are kept until the query executor is done, or until
+	// 'ledger.state.snapshotIsolation.maxBlocks' blocks were committed, after which its reads fail. Range
+	// scans are served from the same height and block the commits from the first range scan onwards, as
+	// under IsolationReadCommitted. Rich queries fail once a block was committed since the query executor
+	// was created, as the state database cannot evaluate them at an older height.
+	// When 'ledger.state.snapshotIsolation.enabled' is disabled, this level behaves as IsolationSerializable
+	IsolationSnapshot
+)
+
 // QueryExecutor executes the queries
 // Get* methods are for supporting KV-based data model. ExecuteQuery method is for supporting a rich datamodel and query support
 //
@@ -100,6 +175,9 @@ type QueryExecutor interface {
 	GetState(namespace string, key string) ([]byte, error)
 	// GetStateMultipleKeys gets the values for multiple keys in a single call
 	GetStateMultipleKeys(namespace string, keys []string) ([][]byte, error)
+	// GetStateMetadata returns the metadata of the given namespace and key, such as its endorsement policy.
+	// The metadata is a map from the names of the metadata entries to their values, nil if the key has no metadata
+	GetStateMetadata(namespace string, key string) (map[string][]byte, error)
 	// GetStateRangeScanIterator returns an iterator that contains all the key-values between given key ranges.
 	// startKey is included in the results and endKey is excluded. An empty startKey refers to the first available key
 	// and an empty endKey refers to the last available key. For scanning all the keys, both the startKey and the endKey
@@ -126,15 +204,39 @@ type QueryExecutor interface {
 	// For a chaincode, the namespace corresponds to the chaincodeId
 	// The returned ResultsIterator contains results of type *KV which is defined in protos/ledger/queryresult.
 	ExecuteQueryOnPrivateData(namespace, collection, query string) (commonledger.ResultsIterator, error)
+	// GetStateByPartialCompositeKeyWithPagination returns an iterator over a page of at most pageSize of the
+	// key-values whose keys are composite keys starting with the given object type and attributes, in key order.
+	// The page begins at the given bookmark, an empty bookmark referring to the first matching key.
+	// The bookmark of the next page is returned by the iterator, and is empty after the last page.
+	// The returned ResultsIterator contains results of type *KV which is defined in protos/ledger/queryresult.
+	GetStateByPartialCompositeKeyWithPagination(namespace, objectType string, attributes []string,
+		pageSize int32, bookmark string) (QueryResultsIterator, error)
 	// Done releases resources occupied by the QueryExecutor
 	Done()
 }
 
+// QueryResultsIterator is a ResultsIterator over a page of results, which
+// returns the bookmark at which the next page begins
+type QueryResultsIterator interface {
+	commonledger.ResultsIterator
+	// GetBookmarkAndClose closes the iterator and returns the bookmark of the next page, which
+	// is empty when there is no next page. It is only set once the page has been iterated through
+	GetBookmarkAndClose() string
+}
+
 // HistoryQueryExecutor executes the history queries
 type HistoryQueryExecutor interface {
 	// GetHistoryForKey retrieves the history of values for a key.
 	// The returned ResultsIterator contains results of type *KeyModification which is defined in protos/ledger/queryresult.
 	GetHistoryForKey(namespace string, key string) (commonledger.ResultsIterator, error)
+	// GetHistoryForKeyInBlockRange retrieves the history of values for a key written by the blocks
+	// from startBlockNum to endBlockNum, both included.
+	// The returned ResultsIterator contains results of type *KeyModification which is defined in protos/ledger/queryresult.
+	GetHistoryForKeyInBlockRange(namespace string, key string, startBlockNum uint64, endBlockNum uint64) (commonledger.ResultsIterator, error)
+	// GetHistoryForKeyRange retrieves the history of values for the keys from startKey (inclusive) to
+	// endKey (exclusive), ordered by key and then by height. An empty endKey ends the range at the last key.
+	// The returned ResultsIterator contains results of type *KeyRangeModification which is defined in protos/ledger/queryresult.
+	GetHistoryForKeyRange(namespace string, startKey string, endKey string) (commonledger.ResultsIterator, error)
 }
 
 // TxSimulator simulates a transaction on a consistent snapshot of the 'as recent state as possible'
@@ -147,6 +249,12 @@ type TxSimulator interface {
 	DeleteState(namespace string, key string) error
 	// SetMultipleKeys sets the values for multiple keys in a single call
 	SetStateMultipleKeys(namespace string, kvs map[string][]byte) error
+	// SetStateMetadata sets the metadata of the given namespace and key, replacing its existing metadata.
+	// The metadata is kept across the updates of the value of the key, and is removed along with the key.
+	// The metadata of a key that does not exist at commit time is ignored
+	SetStateMetadata(namespace string, key string, metadata map[string][]byte) error
+	// DeleteStateMetadata deletes the metadata of the given namespace and key
+	DeleteStateMetadata(namespace string, key string) error
 	// ExecuteUpdate for supporting rich data model (see comments on QueryExecutor above)
 	ExecuteUpdate(query string) error
 	// SetPrivateData sets the given value to a key in the private data state represented by the tuple <namespace, collection, key>
@@ -168,6 +276,99 @@ type TxSimulator interface {
 	GetTxSimulationResults() (*TxSimulationResults, error)
 }
 
+// ReadYourWritesEnabler is implemented by the TxSimulators that support the read-your-writes simulation mode.
+// Once enabled, the point reads within the simulation (GetState, GetStateMultipleKeys, GetPrivateData and
+// GetPrivateDataMultipleKeys) observe the writes made earlier in the same simulation - a deleted key reads as nil.
+// Such reads are served from the write-set and are not added to the read-set, as they do not depend on the
+// committed state. Range scans and rich queries still observe only the committed state
+type ReadYourWritesEnabler interface {
+	// EnableReadYourWrites turns on the read-your-writes mode for the rest of the simulation
+	EnableReadYourWrites()
+}
+
+// TransientPvtDataPersister is implemented by the ledgers which keep the private write sets of the endorsed
+// transactions in a transient store until the transactions are committed. Besides the private write sets
+// simulated by the peer itself, the store keeps those pushed by the other endorsers of the channel, which
+// the ledger falls back to at commit for the collections whose hashes they match in the block
+type TransientPvtDataPersister interface {
+	// PersistPvtData stores the private write set of a transaction simulated by another endorser
+	PersistPvtData(txid string, endorserid string, endorsementBlkHt uint64, pvtSimResults *rwset.TxPvtReadWriteSet) error
+}
+
+// LostBlocksRecommitter is implemented by the ledgers which can recommit the blocks added to their block
+// store by a commit that failed past it, to the stores the failed commit did not reach. A failed commit
+// leaves the ledger behind the block store until the blocks are recommitted, which happens on restart otherwise
+type LostBlocksRecommitter interface {
+	// RecommitLostBlocks commits the pending private data of the last block of the block store, and the blocks
+	// of the block store the state, history and config history databases are missing
+	RecommitLostBlocks() error
+}
+
+// StateDiffEntry is a key of the state database written or deleted by the blocks since a checkpoint, along
+// with its committed value and metadata, and the height of the transaction which last updated it. A private
+// data key is set along with its collection, and the hash of a private data key is set as the KeyHash
+type StateDiffEntry struct {
+	Namespace  string
+	Collection string
+	Key        string
+	KeyHash    []byte
+	Value      []byte
+	Metadata   []byte
+	IsDelete   bool
+	BlockNum   uint64
+	TxNum      uint64
+}
+
+// StateDiffSyncer is implemented by the ledgers whose state database can be brought up to date with their blocks
+// by writing the state differences since its checkpoint, as another peer of the channel computes them, rather
+// than by recommitting the blocks. A ledger opened with its state database at least 'ledger.state.stateDiffSync.minLag'
+// blocks behind its block store defers the recovery of the state database, and refuses to commit blocks until the state
+// differences are applied, or the blocks are recommitted to the state database by `LostBlocksRecommitter`
+type StateDiffSyncer interface {
+	// StateCheckpoint returns the height the state database reflects, which is below
+	// the height of the block store while the recovery of the state database is deferred
+	StateCheckpoint() (uint64, error)
+	// StateDiffSince returns the committed values of the keys written or deleted by the blocks from the
+	// given checkpoint height on, and the height of the state database the values are read at
+	StateDiffSince(checkpoint uint64) (uint64, []*StateDiffEntry, error)
+	// CommitValidatedBlock adds a block validated by another peer to the block store along with its private data,
+	// while the recovery of the state database is deferred. The block is neither validated again nor committed to the
+	// state database, the state differences applied afterwards cover it
+	CommitValidatedBlock(blockAndPvtData *BlockAndPvtData) error
+	// ApplyStateDiff writes the state differences from the checkpoint the deferred state database
+	// reflects up to the height of the block store, which ends the deferral of its recovery
+	ApplyStateDiff(checkpoint uint64, height uint64, entries []*StateDiffEntry) error
+}
+
+// TransactionProof proves the inclusion of a transaction in a block by a Merkle path from the
+// transaction to the DataHash of the block header, which is the Merkle root of the block data
+// (see common.BlockData.MerkleRoot) for the blocks created once the channel requires the
+// ChannelV1_2 capability. A verifier that does not trust the peer serving the proof only
+// needs to check the block header, e.g. against the orderer signatures of the block
+type TransactionProof struct {
+	BlockHeader *common.BlockHeader
+	// TxIndex is the index of the transaction in the block data
+	TxIndex uint64
+	// TxCount is the number of transactions in the block data
+	TxCount uint64
+	// Transaction is the entry of the block data, i.e. the marshaled transaction envelope
+	Transaction []byte
+	// MerklePath holds the hashes of the sibling subtrees from the transaction up to the root
+	MerklePath [][]byte
+}
+
+// Verify returns an error if the Merkle path of the proof does not lead from the transaction to the DataHash of the block header
+func (p *TransactionProof) Verify() error {
+	if p.BlockHeader == nil {
+		return errors.New("proof has no block header")
+	}
+	if !common.VerifyMerklePath(p.Transaction, p.TxIndex, p.TxCount, p.MerklePath, p.BlockHeader.DataHash) {
+		return errors.Errorf("Merkle path of transaction %d of block %d does not lead to the data hash of the block header",
+			p.TxIndex, p.BlockHeader.Number)
+	}
+	return nil
+}
+
 // TxPvtData encapsulates the transaction number and pvt write-set for a transaction
 type TxPvtData struct {
 	SeqInBlock uint64
@@ -175,10 +376,58 @@ type TxPvtData struct {
 }
 
 // BlockAndPvtData encapsultes the block and a map that contains the tuples <seqInBlock, *TxPvtData>
-// The map is expected to contain the entries only for the transactions that has associated pvt data
+// The map is expected to contain the entries only for the transactions that has associated pvt data.
+// MissingPvtData lists the collections whose pvt data is not available at the time of the commit
 type BlockAndPvtData struct {
-	Block        *common.Block
-	BlockPvtData map[uint64]*TxPvtData
+	Block          *common.Block
+	BlockPvtData   map[uint64]*TxPvtData
+	MissingPvtData TxMissingPvtDataMap
+}
+
+// MissingPvtData captures a collection of a transaction whose pvt data is not available at the
+// time of the commit. IsEligible tells whether the peer is a member of the collection, in which
+// case the pvt data is expected to be fetched from the other peers later on
+type MissingPvtData struct {
+	Namespace  string
+	Collection string
+	IsEligible bool
+}
+
+// TxMissingPvtDataMap is a map from the seqInBlock of the transactions to their missing pvt data
+type TxMissingPvtDataMap map[uint64][]*MissingPvtData
+
+// Add adds a missing collection of the transaction at the given seqInBlock to the map
+func (txMissingPvtData TxMissingPvtDataMap) Add(seqInBlock uint64, ns, coll string, isEligible bool) {
+	txMissingPvtData[seqInBlock] = append(txMissingPvtData[seqInBlock], &MissingPvtData{
+		Namespace:  ns,
+		Collection: coll,
+		IsEligible: isEligible,
+	})
+}
+
+// MissingPvtDataInfo is a map from block numbers to the missing pvt data of their transactions
+type MissingPvtDataInfo map[uint64]MissingBlockPvtdataInfo
+
+// MissingBlockPvtdataInfo is a map from the seqInBlock of the transactions of a block to their missing collections
+type MissingBlockPvtdataInfo map[uint64][]*MissingCollectionPvtDataInfo
+
+// MissingCollectionPvtDataInfo identifies a collection whose pvt data is missing
+type MissingCollectionPvtDataInfo struct {
+	Namespace  string
+	Collection string
+}
+
+// Add adds a missing collection of the given transaction of the given block
+func (missingPvtDataInfo MissingPvtDataInfo) Add(blkNum, seqInBlock uint64, ns, coll string) {
+	missingBlockPvtDataInfo, ok := missingPvtDataInfo[blkNum]
+	if !ok {
+		missingBlockPvtDataInfo = make(MissingBlockPvtdataInfo)
+		missingPvtDataInfo[blkNum] = missingBlockPvtDataInfo
+	}
+	missingBlockPvtDataInfo[seqInBlock] = append(missingBlockPvtDataInfo[seqInBlock], &MissingCollectionPvtDataInfo{
+		Namespace:  ns,
+		Collection: coll,
+	})
 }
 
 // PvtCollFilter represents the set of the collection names (as keys of the map with value 'true')
@@ -17,6 +17,7 @@ limitations under the License.
 package ledgerconfig
 
 import (
+	"fmt"
 	"path/filepath"
 
 	"github.com/hyperledger/fabric/core/config"
@@ -54,6 +55,11 @@ func GetHistoryLevelDBPath() string {
 	return filepath.Join(GetRootPath(), "historyLeveldb")
 }
 
+// GetConfigHistoryPath returns the filesystem path that is used to maintain the versions of the collection configs
+func GetConfigHistoryPath() string {
+	return filepath.Join(GetRootPath(), "configHistory")
+}
+
 // GetTransientStorePath returns the filesystem path that is used to temporarily store the private rwset
 func GetTransientStorePath() string {
 	return filepath.Join(GetRootPath(), "transientStore")
@@ -74,19 +80,143 @@ func GetPvtdataStorePath() string {
 	return filepath.Join(GetRootPath(), "pvtdataStore")
 }
 
+// GetChaincodeEventsStorePath returns the filesystem path that is used to persist the chaincode events of the committed blocks
+func GetChaincodeEventsStorePath() string {
+	return filepath.Join(GetRootPath(), "chaincodeEvents")
+}
+
 // GetMaxBlockfileSize returns maximum size of the block file
 func GetMaxBlockfileSize() int {
-	return 64 * 1024 * 1024
+	maxBlockfileSize := viper.GetInt("ledger.blockchain.maxBlockfileSize")
+	// if maxBlockfileSize was unset, default to 64MB
+	if !viper.IsSet("ledger.blockchain.maxBlockfileSize") || maxBlockfileSize <= 0 {
+		maxBlockfileSize = 64 * 1024 * 1024
+	}
+	return maxBlockfileSize
 }
 
-//GetQueryLimit exposes the queryLimit variable
-func GetQueryLimit() int {
-	queryLimit := viper.GetInt("ledger.state.couchDBConfig.queryLimit")
-	// if queryLimit was unset, default to 10000
-	if !viper.IsSet("ledger.state.couchDBConfig.queryLimit") {
-		queryLimit = 10000
+// GetBlockfileCompression returns the codec the blocks are compressed with
+// when they are added to the block files, "none" by default
+func GetBlockfileCompression() string {
+	compression := viper.GetString("ledger.blockchain.compression")
+	if compression == "" {
+		compression = "none"
 	}
-	return queryLimit
+	return compression
+}
+
+// IsTxMSPIDChaincodeIndexEnabled returns whether the block store indexes the transactions
+// by the MSP ID of their submitter and the chaincode they invoke
+func IsTxMSPIDChaincodeIndexEnabled() bool {
+	return viper.GetBool("ledger.blockchain.indexMSPIDAndChaincode")
+}
+
+const perLedgerConfigKey = "ledger.perLedger"
+
+// perLedgerConfig is an entry of the list of the settings of individual ledgers under ledger.perLedger.
+// The entries are listed rather than keyed by ledger ID, as viper splits keys at the dots ledger IDs may contain
+type perLedgerConfig struct {
+	LedgerID                string `mapstructure:"ledgerId"`
+	Isolated                bool
+	RootPath                string
+	BlockCacheSize          int
+	MaxCommitBytesPerSecond int
+}
+
+// LedgerConfig holds the settings of a single ledger, read from the entry of the ledger under ledger.perLedger
+type LedgerConfig struct {
+	// Isolated tells whether the ledger keeps its block store, private data store, state, history and config
+	// history leveldb databases in databases of its own under RootPath, instead of the ones shared by all ledgers
+	Isolated bool
+	// RootPath is the path the databases of an isolated ledger are kept under
+	RootPath string
+	// BlockCacheSize is the size in bytes of the block cache of each of the databases of an isolated ledger
+	BlockCacheSize int
+	// MaxCommitBytesPerSecond limits the rate, in bytes of blocks per second, at which blocks are committed
+	// to the ledger, zero meaning no limit
+	MaxCommitBytesPerSecond int
+}
+
+// GetLedgerConfig returns the settings of the given ledger. A ledger without an entry under ledger.perLedger
+// is not isolated and is not throttled. An error is returned if the entries cannot be read, or if the ledger
+// has several of them, as whether the ledger is isolated must not be mistaken
+func GetLedgerConfig(ledgerID string) (*LedgerConfig, error) {
+	var entries []perLedgerConfig
+	if err := viper.UnmarshalKey(perLedgerConfigKey, &entries); err != nil {
+		return nil, fmt.Errorf("failed reading the settings of the ledgers under %s: %s", perLedgerConfigKey, err)
+	}
+	var entry *perLedgerConfig
+	for i := range entries {
+		if entries[i].LedgerID != ledgerID {
+			continue
+		}
+		if entry != nil {
+			return nil, fmt.Errorf("ledger [%s] has several entries under %s", ledgerID, perLedgerConfigKey)
+		}
+		entry = &entries[i]
+	}
+	conf := &LedgerConfig{RootPath: filepath.Join(GetRootPath(), "isolated", ledgerID)}
+	if entry == nil {
+		return conf, nil
+	}
+	conf.Isolated = entry.Isolated
+	if entry.RootPath != "" {
+		conf.RootPath = entry.RootPath
+	}
+	conf.BlockCacheSize = entry.BlockCacheSize
+	conf.MaxCommitBytesPerSecond = entry.MaxCommitBytesPerSecond
+	return conf, nil
+}
+
+// GetBlockStorePath returns the filesystem path of the block store of an isolated ledger
+func (c *LedgerConfig) GetBlockStorePath() string {
+	return filepath.Join(c.RootPath, "chains")
+}
+
+// GetPvtdataStorePath returns the filesystem path of the private data store of an isolated ledger
+func (c *LedgerConfig) GetPvtdataStorePath() string {
+	return filepath.Join(c.RootPath, "pvtdataStore")
+}
+
+// GetStateLevelDBPath returns the filesystem path of the state level db of an isolated ledger
+func (c *LedgerConfig) GetStateLevelDBPath() string {
+	return filepath.Join(c.RootPath, "stateLeveldb")
+}
+
+// GetHistoryLevelDBPath returns the filesystem path of the history level db of an isolated ledger
+func (c *LedgerConfig) GetHistoryLevelDBPath() string {
+	return filepath.Join(c.RootPath, "historyLeveldb")
+}
+
+// GetConfigHistoryPath returns the filesystem path of the collection config history of an isolated ledger
+func (c *LedgerConfig) GetConfigHistoryPath() string {
+	return filepath.Join(c.RootPath, "configHistory")
+}
+
+// GetInternalQueryLimit returns the number of records requested from CouchDB at once
+// by the range scans and the queries, which page through their results
+func GetInternalQueryLimit() int {
+	internalQueryLimit := viper.GetInt("ledger.state.couchDBConfig.internalQueryLimit")
+	// if internalQueryLimit was unset, default to 1000
+	if !viper.IsSet("ledger.state.couchDBConfig.internalQueryLimit") {
+		internalQueryLimit = 1000
+	}
+	return internalQueryLimit
+}
+
+// GetTotalQueryLimit returns the maximum number of results returned by a range scan or a query
+// of a chaincode on the state database, past which a bookmark is returned instead. A value of zero
+// or less lifts the limit. The configs predating totalQueryLimit set the deprecated
+// ledger.state.couchDBConfig.queryLimit instead, which is used when totalQueryLimit is unset
+func GetTotalQueryLimit() int {
+	if viper.IsSet("ledger.state.totalQueryLimit") {
+		return viper.GetInt("ledger.state.totalQueryLimit")
+	}
+	if viper.IsSet("ledger.state.couchDBConfig.queryLimit") {
+		return viper.GetInt("ledger.state.couchDBConfig.queryLimit")
+	}
+	// if both were unset, default to 10000
+	return 10000
 }
 
 //IsHistoryDBEnabled exposes the historyDatabase variable
@@ -94,6 +224,50 @@ func IsHistoryDBEnabled() bool {
 	return viper.GetBool("ledger.history.enableHistoryDatabase")
 }
 
+// IsReadCommittedIsolationEnabled returns true if the state database should keep the last committed
+// values of the keys being updated during a block commit, for serving read committed query executors
+func IsReadCommittedIsolationEnabled() bool {
+	return viper.GetBool("ledger.state.readCommittedIsolation")
+}
+
+// IsSnapshotIsolationEnabled returns true if the state database should keep the values that the keys
+// updated by the committed blocks had at the heights of the open snapshot query executors
+func IsSnapshotIsolationEnabled() bool {
+	return viper.GetBool("ledger.state.snapshotIsolation.enabled")
+}
+
+// GetSnapshotIsolationMaxBlocks returns the number of blocks that may be committed since a snapshot
+// query executor was created before its reads fail. A value of zero or less lifts the limit
+func GetSnapshotIsolationMaxBlocks() int {
+	if viper.IsSet("ledger.state.snapshotIsolation.maxBlocks") {
+		return viper.GetInt("ledger.state.snapshotIsolation.maxBlocks")
+	}
+	return 100
+}
+
+// GetStateDiffSyncMinLag returns the number of blocks the state database must lag behind the block store, when a
+// ledger is opened, for the recovery of the state database to be deferred to the state differences served by
+// another peer of the organization, rather than recommitting the blocks. A value of zero or less never defers it
+func GetStateDiffSyncMinLag() int {
+	return viper.GetInt("ledger.state.stateDiffSync.minLag")
+}
+
+// GetStateCacheSize returns the number of keys of each state database whose latest committed
+// values are cached in memory. A value of zero or less disables the cache
+func GetStateCacheSize() int {
+	return viper.GetInt("ledger.state.cacheSize")
+}
+
+// GetMaxOpenIteratorsPerQueryExecutor returns the maximum number of result iterators a query executor
+// may hold open at once. A value of zero or less lifts the limit
+func GetMaxOpenIteratorsPerQueryExecutor() int {
+	// if maxOpenIteratorsPerQueryExecutor was unset, default to 100
+	if !viper.IsSet("ledger.state.maxOpenIteratorsPerQueryExecutor") {
+		return 100
+	}
+	return viper.GetInt("ledger.state.maxOpenIteratorsPerQueryExecutor")
+}
+
 // IsQueryReadsHashingEnabled enables or disables computing of hash
 // of range query results for phantom item validation
 func IsQueryReadsHashingEnabled() bool {
@@ -17,6 +17,8 @@ limitations under the License.
 package ledgerconfig
 
 import (
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/hyperledger/fabric/common/ledger/testutil"
@@ -60,6 +62,9 @@ func TestLedgerConfigPathDefault(t *testing.T) {
 	testutil.AssertEquals(t,
 		GetHistoryLevelDBPath(),
 		"/var/hyperledger/production/ledgersData/historyLeveldb")
+	testutil.AssertEquals(t,
+		GetConfigHistoryPath(),
+		"/var/hyperledger/production/ledgersData/configHistory")
 	testutil.AssertEquals(t,
 		GetBlockStorePath(),
 		"/var/hyperledger/production/ledgersData/chains")
@@ -81,31 +86,63 @@ func TestLedgerConfigPath(t *testing.T) {
 	testutil.AssertEquals(t,
 		GetHistoryLevelDBPath(),
 		"/tmp/hyperledger/production/ledgersData/historyLeveldb")
+	testutil.AssertEquals(t,
+		GetConfigHistoryPath(),
+		"/tmp/hyperledger/production/ledgersData/configHistory")
 	testutil.AssertEquals(t,
 		GetBlockStorePath(),
 		"/tmp/hyperledger/production/ledgersData/chains")
 }
 
-func TestGetQueryLimitDefault(t *testing.T) {
+func TestGetInternalQueryLimitDefault(t *testing.T) {
+	setUpCoreYAMLConfig()
+	defaultValue := GetInternalQueryLimit()
+	testutil.AssertEquals(t, defaultValue, 1000) //test default config is 1000
+}
+
+func TestGetInternalQueryLimitUnset(t *testing.T) {
+	viper.Reset()
+	defaultValue := GetInternalQueryLimit()
+	testutil.AssertEquals(t, defaultValue, 1000) //test default config is 1000
+}
+
+func TestGetInternalQueryLimit(t *testing.T) {
+	setUpCoreYAMLConfig()
+	defer ledgertestutil.ResetConfigToDefaultValues()
+	viper.Set("ledger.state.couchDBConfig.internalQueryLimit", 5000)
+	updatedValue := GetInternalQueryLimit()
+	testutil.AssertEquals(t, updatedValue, 5000) //test config returns 5000
+}
+
+func TestGetTotalQueryLimitDefault(t *testing.T) {
 	setUpCoreYAMLConfig()
-	defaultValue := GetQueryLimit()
+	defaultValue := GetTotalQueryLimit()
 	testutil.AssertEquals(t, defaultValue, 10000) //test default config is 10000
 }
 
-func TestGetQueryLimitUnset(t *testing.T) {
+func TestGetTotalQueryLimitUnset(t *testing.T) {
 	viper.Reset()
-	defaultValue := GetQueryLimit()
+	defaultValue := GetTotalQueryLimit()
 	testutil.AssertEquals(t, defaultValue, 10000) //test default config is 10000
 }
 
-func TestGetQueryLimit(t *testing.T) {
+func TestGetTotalQueryLimit(t *testing.T) {
 	setUpCoreYAMLConfig()
 	defer ledgertestutil.ResetConfigToDefaultValues()
-	viper.Set("ledger.state.couchDBConfig.queryLimit", 5000)
-	updatedValue := GetQueryLimit()
+	viper.Set("ledger.state.totalQueryLimit", 5000)
+	updatedValue := GetTotalQueryLimit()
 	testutil.AssertEquals(t, updatedValue, 5000) //test config returns 5000
 }
 
+func TestGetTotalQueryLimitDeprecatedKey(t *testing.T) {
+	viper.Reset()
+	defer ledgertestutil.ResetConfigToDefaultValues()
+	viper.Set("ledger.state.couchDBConfig.queryLimit", 2000)
+	testutil.AssertEquals(t, GetTotalQueryLimit(), 2000) //test the deprecated key is read when totalQueryLimit is unset
+	viper.Set("ledger.state.totalQueryLimit", 5000)
+	testutil.AssertEquals(t, GetTotalQueryLimit(), 5000) //test totalQueryLimit takes precedence
+}
+
 func TestIsHistoryDBEnabledDefault(t *testing.T) {
 	setUpCoreYAMLConfig()
 	defaultValue := IsHistoryDBEnabled()
@@ -128,6 +165,114 @@ func TestIsHistoryDBEnabledFalse(t *testing.T) {
 	testutil.AssertEquals(t, updatedValue, false) //test config returns false
 }
 
+func TestGetMaxBlockfileSize(t *testing.T) {
+	setUpCoreYAMLConfig()
+	defer ledgertestutil.ResetConfigToDefaultValues()
+	testutil.AssertEquals(t, GetMaxBlockfileSize(), 64*1024*1024) //test default config is 64MB
+	viper.Set("ledger.blockchain.maxBlockfileSize", 1024)
+	testutil.AssertEquals(t, GetMaxBlockfileSize(), 1024)
+}
+
+func TestGetBlockfileCompression(t *testing.T) {
+	setUpCoreYAMLConfig()
+	defer ledgertestutil.ResetConfigToDefaultValues()
+	testutil.AssertEquals(t, GetBlockfileCompression(), "none") //test default config is none
+	viper.Set("ledger.blockchain.compression", "snappy")
+	testutil.AssertEquals(t, GetBlockfileCompression(), "snappy")
+}
+
+func TestIsTxMSPIDChaincodeIndexEnabled(t *testing.T) {
+	setUpCoreYAMLConfig()
+	defer ledgertestutil.ResetConfigToDefaultValues()
+	testutil.AssertEquals(t, IsTxMSPIDChaincodeIndexEnabled(), false) //test default config is false
+	viper.Set("ledger.blockchain.indexMSPIDAndChaincode", true)
+	testutil.AssertEquals(t, IsTxMSPIDChaincodeIndexEnabled(), true)
+}
+
+func TestGetMaxOpenIteratorsPerQueryExecutor(t *testing.T) {
+	setUpCoreYAMLConfig()
+	defer ledgertestutil.ResetConfigToDefaultValues()
+	testutil.AssertEquals(t, GetMaxOpenIteratorsPerQueryExecutor(), 100) //test default config is 100
+	viper.Set("ledger.state.maxOpenIteratorsPerQueryExecutor", 0)
+	testutil.AssertEquals(t, GetMaxOpenIteratorsPerQueryExecutor(), 0)
+	viper.Reset()
+	testutil.AssertEquals(t, GetMaxOpenIteratorsPerQueryExecutor(), 100) //test unset config defaults to 100
+}
+
+func TestGetStateCacheSize(t *testing.T) {
+	setUpCoreYAMLConfig()
+	defer ledgertestutil.ResetConfigToDefaultValues()
+	testutil.AssertEquals(t, GetStateCacheSize(), 0) //test default config is 0
+	viper.Set("ledger.state.cacheSize", 1000)
+	testutil.AssertEquals(t, GetStateCacheSize(), 1000)
+}
+
+func TestSnapshotIsolationConfig(t *testing.T) {
+	setUpCoreYAMLConfig()
+	defer ledgertestutil.ResetConfigToDefaultValues()
+	testutil.AssertEquals(t, IsSnapshotIsolationEnabled(), false)
+	testutil.AssertEquals(t, GetSnapshotIsolationMaxBlocks(), 100) //test default config is 100
+	viper.Set("ledger.state.snapshotIsolation.enabled", true)
+	viper.Set("ledger.state.snapshotIsolation.maxBlocks", 0)
+	testutil.AssertEquals(t, IsSnapshotIsolationEnabled(), true)
+	testutil.AssertEquals(t, GetSnapshotIsolationMaxBlocks(), 0)
+	viper.Reset()
+	testutil.AssertEquals(t, GetSnapshotIsolationMaxBlocks(), 100) //test unset config defaults to 100
+}
+
+func TestGetLedgerConfig(t *testing.T) {
+	setUpCoreYAMLConfig()
+	defer ledgertestutil.ResetConfigToDefaultValues()
+	conf, err := GetLedgerConfig("ch1")
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, conf, &LedgerConfig{RootPath: filepath.Join(GetRootPath(), "isolated", "ch1")})
+
+	// the entries are matched by ledger id, which may contain dots
+	viper.Set("ledger.perLedger", []map[string]interface{}{
+		{"ledgerId": "ch1", "isolated": true, "rootPath": "/mnt/ch1", "blockCacheSize": 1024, "maxCommitBytesPerSecond": 2048},
+		{"ledgerId": "ch.2", "maxCommitBytesPerSecond": 4096},
+	})
+	conf, err = GetLedgerConfig("ch1")
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, conf, &LedgerConfig{Isolated: true, RootPath: "/mnt/ch1", BlockCacheSize: 1024, MaxCommitBytesPerSecond: 2048})
+	testutil.AssertEquals(t, conf.GetBlockStorePath(), "/mnt/ch1/chains")
+	testutil.AssertEquals(t, conf.GetStateLevelDBPath(), "/mnt/ch1/stateLeveldb")
+	conf, err = GetLedgerConfig("ch.2")
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, conf, &LedgerConfig{RootPath: filepath.Join(GetRootPath(), "isolated", "ch.2"), MaxCommitBytesPerSecond: 4096})
+	conf, err = GetLedgerConfig("ch")
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, conf.Isolated, false)
+
+	// a ledger with several entries is rejected
+	viper.Set("ledger.perLedger", []map[string]interface{}{{"ledgerId": "ch1", "isolated": true}, {"ledgerId": "ch1"}})
+	_, err = GetLedgerConfig("ch1")
+	testutil.AssertError(t, err, "")
+	viper.Set("ledger.perLedger", "ch1")
+	_, err = GetLedgerConfig("ch1")
+	testutil.AssertError(t, err, "")
+}
+
+func TestGetLedgerConfigFromYAML(t *testing.T) {
+	defer ledgertestutil.ResetConfigToDefaultValues()
+	// the entries are set as decoded from the configuration file
+	v := viper.New()
+	v.SetConfigType("yaml")
+	err := v.ReadConfig(strings.NewReader(`
+ledger:
+  perLedger:
+    - ledgerId: org.example.channel
+      isolated: true
+      blockCacheSize: 1024
+`))
+	testutil.AssertNoError(t, err, "")
+	viper.Set("ledger.perLedger", v.Get("ledger.perLedger"))
+	conf, err := GetLedgerConfig("org.example.channel")
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, conf.Isolated, true)
+	testutil.AssertEquals(t, conf.BlockCacheSize, 1024)
+}
+
 func setUpCoreYAMLConfig() {
 	//call a helper method to load the core.yaml
 	ledgertestutil.SetupCoreYAMLConfig()
@@ -28,6 +28,7 @@ import (
 	"github.com/hyperledger/fabric/core/ledger/kvledger"
 	"github.com/hyperledger/fabric/protos/common"
 	"github.com/hyperledger/fabric/protos/utils"
+	"golang.org/x/net/context"
 )
 
 var logger = flogging.MustGetLogger("ledgermgmt")
@@ -38,6 +39,9 @@ var ErrLedgerAlreadyOpened = errors.New("Ledger already opened")
 // ErrLedgerMgmtNotInitialized is thrown when ledger mgmt is used before initializing this
 var ErrLedgerMgmtNotInitialized = errors.New("ledger mgmt should be initialized before using")
 
+// ErrLedgerMgmtInUse is thrown by a ResetAllLedgers or RollbackLedger call if ledger mgmt is initialized and not closed
+var ErrLedgerMgmtInUse = errors.New("ledgers cannot be reset or rolled back while ledger mgmt is in use")
+
 var openedLedgers map[string]ledger.PeerLedger
 var ledgerProvider ledger.PeerLedgerProvider
 var lock sync.Mutex
@@ -113,6 +117,27 @@ func OpenLedger(id string) (ledger.PeerLedger, error) {
 	return l, nil
 }
 
+// RemoveLedger closes the ledger with the given id, if opened, and removes it along with its data, for
+// rolling back the ledgers created for the channels that a peer failed to join
+func RemoveLedger(id string) error {
+	lock.Lock()
+	defer lock.Unlock()
+	if !initialized {
+		return ErrLedgerMgmtNotInitialized
+	}
+	if l, ok := openedLedgers[id]; ok {
+		l.(*closableLedger).closeWithoutLock()
+	}
+	remover, ok := ledgerProvider.(interface {
+		Remove(ledgerID string) error
+	})
+	if !ok {
+		return fmt.Errorf("ledger provider cannot remove ledger [%s]", id)
+	}
+	logger.Infof("Removing ledger [%s]", id)
+	return remover.Remove(id)
+}
+
 // GetLedgerIDs returns the ids of the ledgers created
 func GetLedgerIDs() ([]string, error) {
 	lock.Lock()
@@ -123,6 +148,54 @@ func GetLedgerIDs() ([]string, error) {
 	return ledgerProvider.List()
 }
 
+// ResetAllLedgers resets all the ledgers to their genesis blocks, for recovering from bad blocks or
+// operator errors. The state and history of the ledgers are rebuilt the next time they are opened.
+// This is meant to be invoked while the peer is not running, before ledger mgmt is initialized
+func ResetAllLedgers() error {
+	lock.Lock()
+	defer lock.Unlock()
+	if openedLedgers != nil {
+		return ErrLedgerMgmtInUse
+	}
+	logger.Info("Resetting all ledgers to their genesis blocks")
+	return kvledger.ResetAllKVLedgers()
+}
+
+// RollbackLedger rolls back the ledger with the given id to the given height, removing the blocks
+// at and above the height. The state and history of the ledger are rebuilt the next time it is opened.
+// This is meant to be invoked while the peer is not running, before ledger mgmt is initialized
+func RollbackLedger(id string, height uint64) error {
+	lock.Lock()
+	defer lock.Unlock()
+	if openedLedgers != nil {
+		return ErrLedgerMgmtInUse
+	}
+	logger.Infof("Rolling back ledger [%s] to height [%d]", id, height)
+	return kvledger.RollbackKVLedger(id, height)
+}
+
+// HealthCheck checks that ledger mgmt is initialized and that its ledger provider is healthy,
+// for the operations server
+func HealthCheck(ctx context.Context) error {
+	lock.Lock()
+	if !initialized {
+		lock.Unlock()
+		return ErrLedgerMgmtNotInitialized
+	}
+	if openedLedgers == nil {
+		lock.Unlock()
+		return errors.New("ledger mgmt is closed")
+	}
+	provider := ledgerProvider
+	lock.Unlock()
+	if checker, ok := provider.(interface {
+		HealthCheck(ctx context.Context) error
+	}); ok {
+		return checker.HealthCheck(ctx)
+	}
+	return nil
+}
+
 // Close closes all the opened ledgers and any resources held for ledger management
 func Close() {
 	logger.Infof("Closing ledger mgmt")
@@ -160,3 +233,57 @@ func (l *closableLedger) closeWithoutLock() {
 	l.PeerLedger.Close()
 	delete(openedLedgers, l.id)
 }
+
+// RecommitLostBlocks implements method in interface `ledger.LostBlocksRecommitter` for the actual ledgers which do
+func (l *closableLedger) RecommitLostBlocks() error {
+	recommitter, ok := l.PeerLedger.(ledger.LostBlocksRecommitter)
+	if !ok {
+		return fmt.Errorf("ledger [%s] does not recommit the blocks lost by a failed commit", l.id)
+	}
+	return recommitter.RecommitLostBlocks()
+}
+
+// stateDiffSyncer returns the actual ledger as a `ledger.StateDiffSyncer`, if it is one
+func (l *closableLedger) stateDiffSyncer() (ledger.StateDiffSyncer, error) {
+	syncer, ok := l.PeerLedger.(ledger.StateDiffSyncer)
+	if !ok {
+		return nil, fmt.Errorf("ledger [%s] does not sync its state by state differences", l.id)
+	}
+	return syncer, nil
+}
+
+// StateCheckpoint implements method in interface `ledger.StateDiffSyncer` for the actual ledgers which do
+func (l *closableLedger) StateCheckpoint() (uint64, error) {
+	syncer, err := l.stateDiffSyncer()
+	if err != nil {
+		return 0, err
+	}
+	return syncer.StateCheckpoint()
+}
+
+// StateDiffSince implements method in interface `ledger.StateDiffSyncer` for the actual ledgers which do
+func (l *closableLedger) StateDiffSince(checkpoint uint64) (uint64, []*ledger.StateDiffEntry, error) {
+	syncer, err := l.stateDiffSyncer()
+	if err != nil {
+		return 0, nil, err
+	}
+	return syncer.StateDiffSince(checkpoint)
+}
+
+// CommitValidatedBlock implements method in interface `ledger.StateDiffSyncer` for the actual ledgers which do
+func (l *closableLedger) CommitValidatedBlock(blockAndPvtData *ledger.BlockAndPvtData) error {
+	syncer, err := l.stateDiffSyncer()
+	if err != nil {
+		return err
+	}
+	return syncer.CommitValidatedBlock(blockAndPvtData)
+}
+
+// ApplyStateDiff implements method in interface `ledger.StateDiffSyncer` for the actual ledgers which do
+func (l *closableLedger) ApplyStateDiff(checkpoint uint64, height uint64, entries []*ledger.StateDiffEntry) error {
+	syncer, err := l.stateDiffSyncer()
+	if err != nil {
+		return err
+	}
+	return syncer.ApplyStateDiff(checkpoint, height, entries)
+}
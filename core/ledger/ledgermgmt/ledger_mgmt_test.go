@@ -26,6 +26,7 @@ import (
 	"github.com/hyperledger/fabric/common/ledger/testutil"
 	"github.com/hyperledger/fabric/core/ledger"
 	"github.com/spf13/viper"
+	"golang.org/x/net/context"
 )
 
 func TestMain(m *testing.M) {
@@ -48,6 +49,7 @@ func TestLedgerMgmt(t *testing.T) {
 	ids, err := GetLedgerIDs()
 	testutil.AssertNil(t, ids)
 	testutil.AssertEquals(t, err, ErrLedgerMgmtNotInitialized)
+	testutil.AssertEquals(t, HealthCheck(context.Background()), ErrLedgerMgmtNotInitialized)
 
 	Close()
 
@@ -64,6 +66,7 @@ func TestLedgerMgmt(t *testing.T) {
 
 	ids, _ = GetLedgerIDs()
 	testutil.AssertEquals(t, len(ids), numLedgers)
+	testutil.AssertNoError(t, HealthCheck(context.Background()), "")
 	for i := 0; i < numLedgers; i++ {
 		testutil.AssertEquals(t, ids[i], constructTestLedgerID(i))
 	}
@@ -81,6 +84,14 @@ func TestLedgerMgmt(t *testing.T) {
 	l, err = OpenLedger(ledgerID)
 	testutil.AssertEquals(t, err, ErrLedgerAlreadyOpened)
 
+	// an opened ledger is closed when removed
+	removedID := constructTestLedgerID(numLedgers - 1)
+	testutil.AssertNoError(t, RemoveLedger(removedID), "")
+	ids, _ = GetLedgerIDs()
+	testutil.AssertEquals(t, len(ids), numLedgers-1)
+	_, err = OpenLedger(removedID)
+	testutil.AssertError(t, err, "")
+
 	// close all opened ledgers and ledger mgmt
 	Close()
 
@@ -88,7 +99,16 @@ func TestLedgerMgmt(t *testing.T) {
 	Initialize(nil)
 	l, err = OpenLedger(ledgerID)
 	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, RollbackLedger(ledgerID, 1), ErrLedgerMgmtInUse)
+	testutil.AssertEquals(t, ResetAllLedgers(), ErrLedgerMgmtInUse)
 	Close()
+
+	testutil.AssertError(t, HealthCheck(context.Background()), "")
+
+	// the ledgers can be rolled back once ledger mgmt is closed
+	testutil.AssertNoError(t, RollbackLedger(ledgerID, 1), "")
+	testutil.AssertNoError(t, ResetAllLedgers(), "")
+	testutil.AssertError(t, RollbackLedger(constructTestLedgerID(numLedgers), 1), "")
 }
 
 func constructTestLedgerID(i int) string {
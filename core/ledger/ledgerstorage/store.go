@@ -22,6 +22,7 @@ import (
 
 	"github.com/hyperledger/fabric/common/ledger/blkstorage"
 	"github.com/hyperledger/fabric/common/ledger/blkstorage/fsblkstorage"
+	"github.com/hyperledger/fabric/common/ledger/util/leveldbhelper"
 	"github.com/hyperledger/fabric/core/ledger"
 	"github.com/hyperledger/fabric/core/ledger/ledgerconfig"
 	"github.com/hyperledger/fabric/core/ledger/pvtdatastorage"
@@ -43,6 +44,17 @@ type Store struct {
 
 // NewProvider returns the handle to the provider
 func NewProvider() *Provider {
+	return newProvider(ledgerconfig.GetBlockStorePath(), &leveldbhelper.Conf{DBPath: ledgerconfig.GetPvtdataStorePath()})
+}
+
+// NewIsolatedProvider returns the handle to a provider whose stores are kept under the root path of the given
+// isolated ledger configuration, separate from the stores of the other ledgers
+func NewIsolatedProvider(conf *ledgerconfig.LedgerConfig) *Provider {
+	return newProvider(conf.GetBlockStorePath(),
+		&leveldbhelper.Conf{DBPath: conf.GetPvtdataStorePath(), BlockCacheCapacity: conf.BlockCacheSize})
+}
+
+func newProvider(blockStorePath string, pvtdataStoreConf *leveldbhelper.Conf) *Provider {
 	// Initialize the block storage
 	attrsToIndex := []blkstorage.IndexableAttr{
 		blkstorage.IndexableAttrBlockHash,
@@ -51,13 +63,20 @@ func NewProvider() *Provider {
 		blkstorage.IndexableAttrBlockNumTranNum,
 		blkstorage.IndexableAttrBlockTxID,
 		blkstorage.IndexableAttrTxValidationCode,
+		blkstorage.IndexableAttrTxCreator,
+	}
+	if ledgerconfig.IsTxMSPIDChaincodeIndexEnabled() {
+		attrsToIndex = append(attrsToIndex, blkstorage.IndexableAttrTxMSPIDChaincode)
 	}
 	indexConfig := &blkstorage.IndexConfig{AttrsToIndex: attrsToIndex}
-	blockStoreProvider := fsblkstorage.NewProvider(
-		fsblkstorage.NewConf(ledgerconfig.GetBlockStorePath(), ledgerconfig.GetMaxBlockfileSize()),
-		indexConfig)
+	blockStoreConf, err := fsblkstorage.NewConfWithCompression(blockStorePath,
+		ledgerconfig.GetMaxBlockfileSize(), ledgerconfig.GetBlockfileCompression())
+	if err != nil {
+		panic(fmt.Errorf("Invalid block storage configuration: %s", err))
+	}
+	blockStoreProvider := fsblkstorage.NewProvider(blockStoreConf, indexConfig)
 
-	pvtStoreProvider := pvtdatastorage.NewProvider()
+	pvtStoreProvider := pvtdatastorage.NewProviderWithConf(pvtdataStoreConf)
 	return &Provider{blockStoreProvider, pvtStoreProvider}
 }
 
@@ -79,6 +98,35 @@ func (p *Provider) Open(ledgerid string) (*Store, error) {
 	return store, nil
 }
 
+// Rollback removes the blocks at and above the given height, along with their pvt data, from the store
+// of the given ledger, which should not be open. The blocks are removed first, so that a rollback
+// interrupted by a crash leaves the pvt data store ahead of the block storage and can be run again
+func (p *Provider) Rollback(ledgerid string, height uint64) error {
+	if err := p.blkStoreProvider.Rollback(ledgerid, height); err != nil {
+		return err
+	}
+	pvtdataStore, err := p.pvtdataStoreProvider.OpenStore(ledgerid)
+	if err != nil {
+		return err
+	}
+	defer pvtdataStore.Shutdown()
+	return pvtdataStore.Truncate(height)
+}
+
+// Remove removes the blocks and the pvt data of the given ledger, which should not be open
+func (p *Provider) Remove(ledgerid string) error {
+	pvtdataStore, err := p.pvtdataStoreProvider.OpenStore(ledgerid)
+	if err != nil {
+		return err
+	}
+	err = pvtdataStore.Truncate(0)
+	pvtdataStore.Shutdown()
+	if err != nil {
+		return err
+	}
+	return p.blkStoreProvider.Remove(ledgerid)
+}
+
 // Close closes the provider
 func (p *Provider) Close() {
 	p.blkStoreProvider.Close()
@@ -93,7 +141,7 @@ func (s *Store) CommitWithPvtData(blockAndPvtdata *ledger.BlockAndPvtData) error
 	for _, v := range blockAndPvtdata.BlockPvtData {
 		pvtdata = append(pvtdata, v)
 	}
-	if err := s.pvtdataStore.Prepare(blockAndPvtdata.Block.Header.Number, pvtdata); err != nil {
+	if err := s.pvtdataStore.Prepare(blockAndPvtdata.Block.Header.Number, pvtdata, blockAndPvtdata.MissingPvtData); err != nil {
 		return err
 	}
 	if err := s.AddBlock(blockAndPvtdata.Block); err != nil {
@@ -136,6 +184,34 @@ func (s *Store) GetPvtDataByNum(blockNum uint64, filter ledger.PvtNsCollFilter)
 	return pvtdata, nil
 }
 
+// Init sets the block to live policy the pvt data is purged according to
+func (s *Store) Init(btlPolicy pvtdatastorage.BTLPolicy) {
+	s.pvtdataStore.Init(btlPolicy)
+}
+
+// GetExpiringPvtData returns the pvt data that expires at the given block
+func (s *Store) GetExpiringPvtData(blockNum uint64) ([]*pvtdatastorage.ExpiringPvtData, error) {
+	s.rwlock.RLock()
+	defer s.rwlock.RUnlock()
+	return s.pvtdataStore.GetExpiringPvtData(blockNum)
+}
+
+// GetMissingPvtDataInfoForMostRecentBlocks returns the missing pvt data the peer is eligible for,
+// recorded for the most recent blocks, up to maxBlock blocks
+func (s *Store) GetMissingPvtDataInfoForMostRecentBlocks(maxBlock int) (ledger.MissingPvtDataInfo, error) {
+	s.rwlock.RLock()
+	defer s.rwlock.RUnlock()
+	return s.pvtdataStore.GetMissingPvtDataInfoForMostRecentBlocks(maxBlock)
+}
+
+// SyncPvtdataStoreWithBlockStore commits or rolls back the pending batch of pvt data left by a commit
+// which failed past the preparation of the batch, depending on whether the block was added to the block storage
+func (s *Store) SyncPvtdataStoreWithBlockStore() error {
+	s.rwlock.Lock()
+	defer s.rwlock.Unlock()
+	return s.init()
+}
+
 // init checks whether the block storage and pvt data store are in sync
 // this is called when the store instance is constructed and handed over for the use.
 // this check whether there is a pending batch (possibly from a previous system crash)
@@ -21,6 +21,7 @@ import (
 	"testing"
 
 	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/common/ledger/blkstorage"
 	"github.com/hyperledger/fabric/common/ledger/testutil"
 	"github.com/hyperledger/fabric/core/ledger"
 	"github.com/hyperledger/fabric/protos/ledger/rwset"
@@ -96,6 +97,68 @@ func TestStore(t *testing.T) {
 	assert.Nil(t, blockAndPvtdata.BlockPvtData[2])
 }
 
+func TestStoreRollback(t *testing.T) {
+	testEnv := newTestEnv(t)
+	defer testEnv.cleanup()
+	provider := NewProvider()
+	defer provider.Close()
+	store, err := provider.Open("testLedger")
+	assert.NoError(t, err)
+	sampleData := sampleData(t)
+	for _, sampleDatum := range sampleData {
+		assert.NoError(t, store.CommitWithPvtData(sampleDatum))
+	}
+	store.Shutdown()
+
+	assert.Error(t, provider.Rollback("testLedger", 11))
+	assert.NoError(t, provider.Rollback("testLedger", 3))
+	store, err = provider.Open("testLedger")
+	assert.NoError(t, err)
+	defer store.Shutdown()
+	bcInfo, err := store.GetBlockchainInfo()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(3), bcInfo.Height)
+	blockAndPvtdata, err := store.GetPvtDataAndBlockByNum(2, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, sampleData[2], blockAndPvtdata)
+	_, err = store.GetPvtDataAndBlockByNum(3, nil)
+	assert.Error(t, err)
+
+	// the removed blocks and their pvt data can be committed again
+	for _, sampleDatum := range sampleData[3:] {
+		assert.NoError(t, store.CommitWithPvtData(sampleDatum))
+	}
+	blockAndPvtdata, err = store.GetPvtDataAndBlockByNum(3, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, sampleData[3], blockAndPvtdata)
+}
+
+func TestStoreMSPIDChaincodeIndex(t *testing.T) {
+	for _, enabled := range []bool{false, true} {
+		testEnv := newTestEnv(t)
+		viper.Set("ledger.blockchain.indexMSPIDAndChaincode", enabled)
+		provider := NewProvider()
+		store, err := provider.Open("testLedger")
+		assert.NoError(t, err)
+		for _, sampleDatum := range sampleData(t) {
+			assert.NoError(t, store.CommitWithPvtData(sampleDatum))
+		}
+
+		// the transactions are indexed by MSP ID and chaincode only if the index is enabled
+		_, _, err = store.RetrieveTxsByMSPIDAndChaincode("Org1MSP", "mycc", blkstorage.TxPosition{}, 10)
+		if enabled {
+			assert.NoError(t, err)
+		} else {
+			assert.Equal(t, blkstorage.ErrAttrNotIndexed, err)
+		}
+
+		store.Shutdown()
+		provider.Close()
+		viper.Set("ledger.blockchain.indexMSPIDAndChaincode", false)
+		testEnv.cleanup()
+	}
+}
+
 func sampleData(t *testing.T) []*ledger.BlockAndPvtData {
 	var blockAndpvtdata []*ledger.BlockAndPvtData
 	blocks := testutil.ConstructTestBlocks(t, 10)
@@ -7,9 +7,11 @@ SPDX-License-Identifier: Apache-2.0
 package pvtdatastorage
 
 import (
+	"bytes"
 	"math"
 
 	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/ledger/util"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/version"
 	"github.com/hyperledger/fabric/protos/ledger/rwset"
 )
@@ -18,7 +20,14 @@ var (
 	pendingCommitKey    = []byte{0}
 	lastCommittedBlkkey = []byte{1}
 	pvtDataKeyPrefix    = []byte{2}
+	// the keys of the missing pvt data are grouped by the eligibility of the peer,
+	// so that the eligible missing pvt data can be scanned on its own
+	eligibleMissingDataKeyPrefix   = []byte{3}
+	ineligibleMissingDataKeyPrefix = []byte{4}
+	// the keys of the expiring pvt data are sorted by the block the pvt data expires at
+	expiryKeyPrefix = []byte{5}
 
+	nilByte    = byte(0)
 	emptyValue = []byte{}
 )
 
@@ -54,3 +63,55 @@ func decodeBlockNum(blockNumBytes []byte) uint64 {
 	s, _ := proto.DecodeVarint(blockNumBytes)
 	return s
 }
+
+// encodeMissingDataKey encodes the key of a missing collection of a transaction. The block number is
+// encoded in the reverse order so that a range scan returns the most recent blocks first
+func encodeMissingDataKey(isEligible bool, blockNum uint64, tranNum uint64, ns, coll string) []byte {
+	key := append(missingDataKeyPrefix(isEligible), version.NewHeight(math.MaxUint64-blockNum, tranNum).ToBytes()...)
+	key = append(key, []byte(ns)...)
+	key = append(key, nilByte)
+	return append(key, []byte(coll)...)
+}
+
+func decodeMissingDataKey(key []byte) (blockNum uint64, tranNum uint64, ns, coll string) {
+	height, n := version.NewHeightFromBytes(key[1:])
+	splittedKey := bytes.SplitN(key[n+1:], []byte{nilByte}, 2)
+	return math.MaxUint64 - height.BlockNum, height.TxNum, string(splittedKey[0]), string(splittedKey[1])
+}
+
+func getKeysForMissingDataRangeScanByBlockNum(isEligible bool, blockNum uint64) (startKey []byte, endKey []byte) {
+	startKey = append(missingDataKeyPrefix(isEligible), version.NewHeight(math.MaxUint64-blockNum, 0).ToBytes()...)
+	endKey = append(missingDataKeyPrefix(isEligible), version.NewHeight(math.MaxUint64-blockNum, math.MaxUint64).ToBytes()...)
+	return
+}
+
+func missingDataKeyPrefix(isEligible bool) []byte {
+	if isEligible {
+		return append([]byte{}, eligibleMissingDataKeyPrefix...)
+	}
+	return append([]byte{}, ineligibleMissingDataKeyPrefix...)
+}
+
+// encodeExpiryKey encodes the key of a collection of a transaction committed by committingBlk,
+// whose pvt data expires at expiringBlk
+func encodeExpiryKey(expiringBlk uint64, committingBlk uint64, tranNum uint64, ns, coll string) []byte {
+	key := append(append([]byte{}, expiryKeyPrefix...), version.NewHeight(expiringBlk, committingBlk).ToBytes()...)
+	key = append(key, util.EncodeOrderPreservingVarUint64(tranNum)...)
+	key = append(key, []byte(ns)...)
+	key = append(key, nilByte)
+	return append(key, []byte(coll)...)
+}
+
+func decodeExpiryKey(key []byte) (expiringBlk uint64, committingBlk uint64, tranNum uint64, ns, coll string) {
+	height, n1 := version.NewHeightFromBytes(key[1:])
+	tranNum, n2 := util.DecodeOrderPreservingVarUint64(key[1+n1:])
+	splittedKey := bytes.SplitN(key[1+n1+n2:], []byte{nilByte}, 2)
+	return height.BlockNum, height.TxNum, tranNum, string(splittedKey[0]), string(splittedKey[1])
+}
+
+// getKeysForExpiryRangeScan returns the range of the keys of the pvt data expiring at or before the given block
+func getKeysForExpiryRangeScan(expiringBlk uint64) (startKey []byte, endKey []byte) {
+	startKey = append([]byte{}, expiryKeyPrefix...)
+	endKey = append(append([]byte{}, expiryKeyPrefix...), util.EncodeOrderPreservingVarUint64(expiringBlk+1)...)
+	return
+}
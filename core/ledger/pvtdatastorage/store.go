@@ -28,6 +28,9 @@ type Provider interface {
 // on whether the block was written successfully or not. The store implementation
 // is expected to survive a server crash between the call to `Prepare` and `Commit`/`Rollback`
 type Store interface {
+	// Init sets the block to live policy the pvt data committed from then on is purged according to.
+	// If it is not set, the pvt data is never purged
+	Init(btlPolicy BTLPolicy)
 	// GetPvtDataByBlockNum returns only the pvt data  corresponding to the given block number
 	// The pvt data is filtered by the list of 'ns/collections' supplied in the filter
 	// A nil filter does not filter any results
@@ -36,12 +39,21 @@ type Store interface {
 	// Subsequently, the caller is expected to call either `Commit` or `Rollback` function.
 	// Return from this should ensure that enough preparation is done such that `Commit` function invoked afterwards
 	// can commit the data and the store is capable of surviving a crash between this function call and the next
-	// invoke to the `Commit`
-	Prepare(blockNum uint64, pvtData []*ledger.TxPvtData) error
+	// invoke to the `Commit`. The missing pvt data, if any, is recorded along with the pvt data
+	Prepare(blockNum uint64, pvtData []*ledger.TxPvtData, missingPvtData ledger.TxMissingPvtDataMap) error
 	// Commit commits the pvt data passed in the previous invoke to the `Prepare` function
 	Commit() error
 	// Rollback rolls back the pvt data passed in the previous invoke to the `Prepare` function
 	Rollback() error
+	// Truncate removes the pvt data and the missing pvt data of the blocks at and above the given height,
+	// along with the pending batch if any, so that the store ends with the block preceding the given height
+	Truncate(height uint64) error
+	// GetExpiringPvtData returns the pvt data that expires at the given block, which is purged
+	// upon the commit of the block, along with the missing pvt data records of the same collections
+	GetExpiringPvtData(blockNum uint64) ([]*ExpiringPvtData, error)
+	// GetMissingPvtDataInfoForMostRecentBlocks returns the missing pvt data the peer is eligible for,
+	// recorded for the most recent committed blocks that have some, up to maxBlock blocks
+	GetMissingPvtDataInfoForMostRecentBlocks(maxBlock int) (ledger.MissingPvtDataInfo, error)
 	// IsEmpty returns true if the store does not have any block committed yet
 	IsEmpty() (bool, error)
 	// LastCommittedBlockHeight returns the height of the last committed block
@@ -52,6 +64,22 @@ type Store interface {
 	Shutdown()
 }
 
+// BTLPolicy provides the block to live of the pvt data of the collections
+type BTLPolicy interface {
+	// GetBTL returns the number of blocks the pvt data of the collection committed by the given block is
+	// kept for after that block, as configured at that block; zero means that the pvt data is never purged
+	GetBTL(ns string, coll string, committingBlk uint64) (uint64, error)
+}
+
+// ExpiringPvtData identifies the pvt data of a collection written by a transaction,
+// which is purged upon the commit of the block it expires at
+type ExpiringPvtData struct {
+	CommittingBlk uint64
+	TxNum         uint64
+	Namespace     string
+	Collection    string
+}
+
 // ErrIllegalCall is to be thrown by a store impl if the store does not expect a call to Prepare/Commit/Rollback
 type ErrIllegalCall struct {
 	msg string
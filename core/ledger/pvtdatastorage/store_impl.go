@@ -8,6 +8,7 @@ package pvtdatastorage
 
 import (
 	"fmt"
+	"math"
 
 	"github.com/hyperledger/fabric/common/flogging"
 	"github.com/hyperledger/fabric/common/ledger/util/leveldbhelper"
@@ -28,14 +29,19 @@ type store struct {
 	isEmpty            bool
 	lastCommittedBlock uint64
 	batchPending       bool
+	btlPolicy          BTLPolicy
 }
 
 type blkTranNumKey []byte
 
 // NewProvider instantiates a StoreProvider
 func NewProvider() Provider {
-	dbPath := ledgerconfig.GetPvtdataStorePath()
-	dbProvider := leveldbhelper.NewProvider(&leveldbhelper.Conf{DBPath: dbPath})
+	return NewProviderWithConf(&leveldbhelper.Conf{DBPath: ledgerconfig.GetPvtdataStorePath()})
+}
+
+// NewProviderWithConf instantiates a StoreProvider on the leveldb with the given configuration
+func NewProviderWithConf(conf *leveldbhelper.Conf) Provider {
+	dbProvider := leveldbhelper.NewProvider(conf)
 	return &provider{dbProvider: dbProvider}
 }
 
@@ -65,8 +71,13 @@ func (s *store) initState() error {
 	return nil
 }
 
+// Init implements the function in the interface `Store`
+func (s *store) Init(btlPolicy BTLPolicy) {
+	s.btlPolicy = btlPolicy
+}
+
 // Prepare implements the function in the interface `Store`
-func (s *store) Prepare(blockNum uint64, pvtData []*ledger.TxPvtData) error {
+func (s *store) Prepare(blockNum uint64, pvtData []*ledger.TxPvtData, missingPvtData ledger.TxMissingPvtDataMap) error {
 	if s.batchPending {
 		return &ErrIllegalCall{`A pending batch exists as as result of last invoke to "Prepare" call.
 			 Invoke "Commit" or "Rollback" on the pending batch before invoking "Prepare" function`}
@@ -86,6 +97,23 @@ func (s *store) Prepare(blockNum uint64, pvtData []*ledger.TxPvtData) error {
 		}
 		logger.Debugf("Adding private data to batch blockNum=%d, tranNum=%d", blockNum, txPvtData.SeqInBlock)
 		batch.Put(key, value)
+		for _, ns := range txPvtData.WriteSet.NsPvtRwset {
+			for _, coll := range ns.CollectionPvtRwset {
+				if err = s.addExpiryKey(batch, blockNum, txPvtData.SeqInBlock, ns.Namespace, coll.CollectionName); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	for txNum, missingData := range missingPvtData {
+		for _, missing := range missingData {
+			logger.Debugf("Adding missing private data to batch blockNum=%d, tranNum=%d, ns=%s, coll=%s, eligible=%t",
+				blockNum, txNum, missing.Namespace, missing.Collection, missing.IsEligible)
+			batch.Put(encodeMissingDataKey(missing.IsEligible, blockNum, txNum, missing.Namespace, missing.Collection), emptyValue)
+			if err = s.addExpiryKey(batch, blockNum, txNum, missing.Namespace, missing.Collection); err != nil {
+				return err
+			}
+		}
 	}
 	batch.Put(pendingCommitKey, emptyValue)
 	if err := s.db.WriteBatch(batch, true); err != nil {
@@ -103,6 +131,9 @@ func (s *store) Commit() error {
 	committingBlockNum := s.nextBlockNum()
 	logger.Debugf("Committing pvt data for block = %d", committingBlockNum)
 	batch := leveldbhelper.NewUpdateBatch()
+	if err := s.addPurgeOfExpiredData(batch, committingBlockNum); err != nil {
+		return err
+	}
 	batch.Delete(pendingCommitKey)
 	batch.Put(lastCommittedBlkkey, encodeBlockNum(committingBlockNum))
 	if err := s.db.WriteBatch(batch, true); err != nil {
@@ -117,7 +148,7 @@ func (s *store) Commit() error {
 
 // Rollback implements the function in the interface `Store`
 func (s *store) Rollback() error {
-	var pendingBatchKeys []blkTranNumKey
+	var pendingBatchKeys [][]byte
 	var err error
 	if !s.batchPending {
 		return &ErrIllegalCall{"No pending batch to rollback"}
@@ -141,6 +172,52 @@ func (s *store) Rollback() error {
 	return nil
 }
 
+// Truncate implements the function in the interface `Store`
+func (s *store) Truncate(height uint64) error {
+	currentHeight := s.nextBlockNum()
+	if height > currentHeight {
+		return &ErrIllegalArgs{fmt.Sprintf("Cannot truncate to height=%d, which is above the current height=%d", height, currentHeight)}
+	}
+	logger.Debugf("Truncating pvt data from height = %d to height = %d", currentHeight, height)
+	batch := leveldbhelper.NewUpdateBatch()
+	// the keys of the pvt data of the pending batch, if any, are removed along with the committed ones
+	for _, key := range retrieveKeys(s.db, encodePK(height, 0), eligibleMissingDataKeyPrefix) {
+		batch.Delete(key)
+	}
+	if height > 0 {
+		// the block numbers of the missing pvt data are encoded in the reverse order
+		for _, isEligible := range []bool{true, false} {
+			endKey, _ := getKeysForMissingDataRangeScanByBlockNum(isEligible, height-1)
+			for _, key := range retrieveKeys(s.db, missingDataKeyPrefix(isEligible), endKey) {
+				batch.Delete(key)
+			}
+		}
+		for _, key := range retrieveKeys(s.db, expiryKeyPrefix, nil) {
+			if _, committingBlk, _, _, _ := decodeExpiryKey(key); committingBlk >= height {
+				batch.Delete(key)
+			}
+		}
+		batch.Put(lastCommittedBlkkey, encodeBlockNum(height-1))
+	} else {
+		// the missing pvt data and the expiry keys
+		for _, key := range retrieveKeys(s.db, eligibleMissingDataKeyPrefix, nil) {
+			batch.Delete(key)
+		}
+		batch.Delete(lastCommittedBlkkey)
+	}
+	batch.Delete(pendingCommitKey)
+	if err := s.db.WriteBatch(batch, true); err != nil {
+		return err
+	}
+	s.batchPending = false
+	s.isEmpty = height == 0
+	if height > 0 {
+		s.lastCommittedBlock = height - 1
+	}
+	logger.Debugf("Truncated pvt data to height = %d", height)
+	return nil
+}
+
 // GetPvtDataByBlockNum implements the function in the interface `Store`.
 // If the store is empty or the last committed block number is smaller then the
 // requested block number, an 'ErrOutOfRange' is thrown
@@ -173,6 +250,41 @@ func (s *store) GetPvtDataByBlockNum(blockNum uint64, filter ledger.PvtNsCollFil
 	return pvtData, nil
 }
 
+// GetExpiringPvtData implements the function in the interface `Store`
+func (s *store) GetExpiringPvtData(blockNum uint64) ([]*ExpiringPvtData, error) {
+	var expiring []*ExpiringPvtData
+	startKey, endKey := getKeysForExpiryRangeScan(blockNum)
+	for _, key := range retrieveKeys(s.db, startKey, endKey) {
+		_, committingBlk, txNum, ns, coll := decodeExpiryKey(key)
+		expiring = append(expiring, &ExpiringPvtData{CommittingBlk: committingBlk, TxNum: txNum, Namespace: ns, Collection: coll})
+	}
+	return expiring, nil
+}
+
+// GetMissingPvtDataInfoForMostRecentBlocks implements the function in the interface `Store`
+func (s *store) GetMissingPvtDataInfoForMostRecentBlocks(maxBlock int) (ledger.MissingPvtDataInfo, error) {
+	missingPvtDataInfo := make(ledger.MissingPvtDataInfo)
+	if s.isEmpty || maxBlock <= 0 {
+		return missingPvtDataInfo, nil
+	}
+	// the block numbers are encoded in the reverse order, the most recent blocks come first
+	itr := s.db.GetIterator(eligibleMissingDataKeyPrefix, ineligibleMissingDataKeyPrefix)
+	defer itr.Release()
+
+	for itr.Next() {
+		blkNum, txNum, ns, coll := decodeMissingDataKey(itr.Key())
+		if blkNum > s.lastCommittedBlock {
+			// the missing pvt data of a pending batch
+			continue
+		}
+		if _, ok := missingPvtDataInfo[blkNum]; !ok && len(missingPvtDataInfo) == maxBlock {
+			break
+		}
+		missingPvtDataInfo.Add(blkNum, txNum, ns, coll)
+	}
+	return missingPvtDataInfo, nil
+}
+
 // LastCommittedBlockHeight implements the function in the interface `Store`
 func (s *store) LastCommittedBlockHeight() (uint64, error) {
 	if s.isEmpty {
@@ -203,15 +315,123 @@ func (s *store) nextBlockNum() uint64 {
 	return s.lastCommittedBlock + 1
 }
 
-func (s *store) retrievePendingBatchKeys() ([]blkTranNumKey, error) {
-	var pendingBatchKeys []blkTranNumKey
-	itr := s.db.GetIterator(encodePK(s.nextBlockNum(), 0), nil)
-	for itr.Next() {
-		pendingBatchKeys = append(pendingBatchKeys, itr.Key())
+func (s *store) retrievePendingBatchKeys() ([][]byte, error) {
+	var pendingBatchKeys [][]byte
+	startKey, endKey := getKeysForRangeScanByBlockNum(s.nextBlockNum())
+	pendingBatchKeys = append(pendingBatchKeys, retrieveKeys(s.db, startKey, endKey)...)
+	for _, isEligible := range []bool{true, false} {
+		startKey, endKey = getKeysForMissingDataRangeScanByBlockNum(isEligible, s.nextBlockNum())
+		pendingBatchKeys = append(pendingBatchKeys, retrieveKeys(s.db, startKey, endKey)...)
+	}
+	for _, key := range retrieveKeys(s.db, expiryKeyPrefix, nil) {
+		if _, committingBlk, _, _, _ := decodeExpiryKey(key); committingBlk == s.nextBlockNum() {
+			pendingBatchKeys = append(pendingBatchKeys, key)
+		}
 	}
 	return pendingBatchKeys, nil
 }
 
+// addExpiryKey adds to the batch the expiry key of the pvt data of the collection committed by the block,
+// if the collection has a block to live. The pvt data committed by block n with a block to live of btl
+// blocks is purged upon the commit of block n+btl+1
+func (s *store) addExpiryKey(batch *leveldbhelper.UpdateBatch, blockNum uint64, txNum uint64, ns, coll string) error {
+	if s.btlPolicy == nil {
+		return nil
+	}
+	btl, err := s.btlPolicy.GetBTL(ns, coll, blockNum)
+	if err != nil {
+		return err
+	}
+	if btl == 0 || btl >= math.MaxUint64-blockNum {
+		return nil
+	}
+	batch.Put(encodeExpiryKey(blockNum+btl+1, blockNum, txNum, ns, coll), emptyValue)
+	return nil
+}
+
+// addPurgeOfExpiredData adds to the batch the removal of the pvt data and of the missing pvt data
+// expiring at or before the given block, along with their expiry keys
+func (s *store) addPurgeOfExpiredData(batch *leveldbhelper.UpdateBatch, blockNum uint64) error {
+	startKey, endKey := getKeysForExpiryRangeScan(blockNum)
+	expiryKeys := retrieveKeys(s.db, startKey, endKey)
+	if len(expiryKeys) == 0 {
+		return nil
+	}
+	// the pvt data of a transaction is updated once for all its expired collections
+	purged := make(map[string]*rwset.TxPvtReadWriteSet)
+	for _, expiryKey := range expiryKeys {
+		_, committingBlk, txNum, ns, coll := decodeExpiryKey(expiryKey)
+		logger.Debugf("Purging expired pvt data blockNum=%d, tranNum=%d, ns=%s, coll=%s", committingBlk, txNum, ns, coll)
+		key := encodePK(committingBlk, txNum)
+		pvtWSet, ok := purged[string(key)]
+		if !ok {
+			value, err := s.db.Get(key)
+			if err != nil {
+				return err
+			}
+			if value != nil {
+				if pvtWSet, err = decodePvtRwSet(value); err != nil {
+					return err
+				}
+			}
+			purged[string(key)] = pvtWSet
+		}
+		if pvtWSet != nil {
+			removeCollPvtRWSet(pvtWSet, ns, coll)
+		}
+		for _, isEligible := range []bool{true, false} {
+			batch.Delete(encodeMissingDataKey(isEligible, committingBlk, txNum, ns, coll))
+		}
+		batch.Delete(expiryKey)
+	}
+	for key, pvtWSet := range purged {
+		if pvtWSet == nil {
+			continue
+		}
+		if len(pvtWSet.NsPvtRwset) == 0 {
+			batch.Delete([]byte(key))
+			continue
+		}
+		value, err := encodePvtRwSet(pvtWSet)
+		if err != nil {
+			return err
+		}
+		batch.Put([]byte(key), value)
+	}
+	return nil
+}
+
+// removeCollPvtRWSet removes the write set of the collection from the pvt write set of a transaction
+func removeCollPvtRWSet(pvtWSet *rwset.TxPvtReadWriteSet, ns, coll string) {
+	var nsPvtRWSets []*rwset.NsPvtReadWriteSet
+	for _, nsPvtRWSet := range pvtWSet.NsPvtRwset {
+		if nsPvtRWSet.Namespace == ns {
+			var collPvtRWSets []*rwset.CollectionPvtReadWriteSet
+			for _, collPvtRWSet := range nsPvtRWSet.CollectionPvtRwset {
+				if collPvtRWSet.CollectionName != coll {
+					collPvtRWSets = append(collPvtRWSets, collPvtRWSet)
+				}
+			}
+			if len(collPvtRWSets) == 0 {
+				continue
+			}
+			nsPvtRWSet.CollectionPvtRwset = collPvtRWSets
+		}
+		nsPvtRWSets = append(nsPvtRWSets, nsPvtRWSet)
+	}
+	pvtWSet.NsPvtRwset = nsPvtRWSets
+}
+
+func retrieveKeys(db *leveldbhelper.DBHandle, startKey, endKey []byte) [][]byte {
+	var keys [][]byte
+	itr := db.GetIterator(startKey, endKey)
+	defer itr.Release()
+	for itr.Next() {
+		keys = append(keys, append([]byte{}, itr.Key()...))
+	}
+	return keys
+}
+
 func (s *store) hasPendingCommit() (bool, error) {
 	var v []byte
 	var err error
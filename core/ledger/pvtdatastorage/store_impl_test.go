@@ -40,15 +40,15 @@ func TestStoreBasicCommitAndRetrieval(t *testing.T) {
 	testData := samplePvtData(t, []uint64{2, 4})
 
 	// no pvt data with block 0
-	assert.NoError(store.Prepare(0, nil))
+	assert.NoError(store.Prepare(0, nil, nil))
 	assert.NoError(store.Commit())
 
 	// pvt data with block 1 - commit
-	assert.NoError(store.Prepare(1, testData))
+	assert.NoError(store.Prepare(1, testData, nil))
 	assert.NoError(store.Commit())
 
 	// pvt data with block 2 - rollback
-	assert.NoError(store.Prepare(2, testData))
+	assert.NoError(store.Prepare(2, testData, nil))
 	assert.NoError(store.Rollback())
 
 	// pvt data retrieval for block 0 should return nil
@@ -86,17 +86,236 @@ func TestStoreState(t *testing.T) {
 	store := env.TestStore
 	testData := samplePvtData(t, []uint64{0})
 
-	_, ok := store.Prepare(1, testData).(*ErrIllegalArgs)
+	_, ok := store.Prepare(1, testData, nil).(*ErrIllegalArgs)
 	assert.True(ok)
 
-	assert.Nil(store.Prepare(0, testData))
+	assert.Nil(store.Prepare(0, testData, nil))
 	assert.NoError(store.Commit())
 
-	assert.Nil(store.Prepare(1, testData))
-	_, ok = store.Prepare(2, testData).(*ErrIllegalCall)
+	assert.Nil(store.Prepare(1, testData, nil))
+	_, ok = store.Prepare(2, testData, nil).(*ErrIllegalCall)
 	assert.True(ok)
 }
 
+func TestStoreMissingPvtData(t *testing.T) {
+	env := NewTestStoreEnv(t)
+	defer env.Cleanup()
+	assert := assert.New(t)
+	store := env.TestStore
+	testData := samplePvtData(t, []uint64{2})
+
+	// no missing pvt data in an empty store
+	missingPvtDataInfo, err := store.GetMissingPvtDataInfoForMostRecentBlocks(10)
+	assert.NoError(err)
+	assert.Len(missingPvtDataInfo, 0)
+
+	// block 0 has no missing pvt data
+	assert.NoError(store.Prepare(0, testData, nil))
+	assert.NoError(store.Commit())
+
+	// block 1 misses eligible and ineligible pvt data
+	missingData := make(ledger.TxMissingPvtDataMap)
+	missingData.Add(1, "ns-1", "coll-1", true)
+	missingData.Add(1, "ns-1", "coll-2", false)
+	missingData.Add(4, "ns-2", "coll-1", true)
+	assert.NoError(store.Prepare(1, testData, missingData))
+	assert.NoError(store.Commit())
+
+	// block 2 misses eligible pvt data
+	missingData = make(ledger.TxMissingPvtDataMap)
+	missingData.Add(3, "ns-1", "coll-1", true)
+	assert.NoError(store.Prepare(2, nil, missingData))
+	assert.NoError(store.Commit())
+
+	// block 3 misses eligible pvt data, but is rolled back
+	missingData = make(ledger.TxMissingPvtDataMap)
+	missingData.Add(0, "ns-3", "coll-1", true)
+	assert.NoError(store.Prepare(3, nil, missingData))
+
+	// the missing pvt data of a pending batch is not returned
+	expectedMissingPvtDataInfo := make(ledger.MissingPvtDataInfo)
+	expectedMissingPvtDataInfo.Add(2, 3, "ns-1", "coll-1")
+	expectedMissingPvtDataInfo.Add(1, 1, "ns-1", "coll-1")
+	expectedMissingPvtDataInfo.Add(1, 4, "ns-2", "coll-1")
+	missingPvtDataInfo, err = store.GetMissingPvtDataInfoForMostRecentBlocks(10)
+	assert.NoError(err)
+	assert.Equal(expectedMissingPvtDataInfo, missingPvtDataInfo)
+
+	assert.NoError(store.Rollback())
+	missingPvtDataInfo, err = store.GetMissingPvtDataInfoForMostRecentBlocks(10)
+	assert.NoError(err)
+	assert.Equal(expectedMissingPvtDataInfo, missingPvtDataInfo)
+
+	// only the most recent block is returned
+	expectedMissingPvtDataInfo = make(ledger.MissingPvtDataInfo)
+	expectedMissingPvtDataInfo.Add(2, 3, "ns-1", "coll-1")
+	missingPvtDataInfo, err = store.GetMissingPvtDataInfoForMostRecentBlocks(1)
+	assert.NoError(err)
+	assert.Equal(expectedMissingPvtDataInfo, missingPvtDataInfo)
+
+	// the pvt data of the blocks is not affected by the missing pvt data
+	retrievedData, err := store.GetPvtDataByBlockNum(1, nil)
+	assert.NoError(err)
+	assert.Equal(testData, retrievedData)
+	retrievedData, err = store.GetPvtDataByBlockNum(2, nil)
+	assert.NoError(err)
+	assert.Nil(retrievedData)
+}
+
+func TestStoreTruncate(t *testing.T) {
+	env := NewTestStoreEnv(t)
+	defer env.Cleanup()
+	assert := assert.New(t)
+	store := env.TestStore
+	testData := samplePvtData(t, []uint64{2, 4})
+
+	for blkNum := uint64(0); blkNum < 4; blkNum++ {
+		missingData := make(ledger.TxMissingPvtDataMap)
+		missingData.Add(1, "ns-1", "coll-1", true)
+		missingData.Add(3, "ns-1", "coll-2", false)
+		assert.NoError(store.Prepare(blkNum, testData, missingData))
+		assert.NoError(store.Commit())
+	}
+	assert.NoError(store.Prepare(4, testData, nil))
+
+	assert.Error(store.Truncate(6))
+	assert.NoError(store.Truncate(2))
+	testPendingBatch(false, assert, store)
+	height, err := store.LastCommittedBlockHeight()
+	assert.NoError(err)
+	assert.Equal(uint64(2), height)
+	retrievedData, err := store.GetPvtDataByBlockNum(1, nil)
+	assert.NoError(err)
+	assert.Equal(testData, retrievedData)
+	_, err = store.GetPvtDataByBlockNum(2, nil)
+	_, ok := err.(*ErrOutOfRange)
+	assert.True(ok)
+
+	expectedMissingPvtDataInfo := make(ledger.MissingPvtDataInfo)
+	expectedMissingPvtDataInfo.Add(1, 1, "ns-1", "coll-1")
+	expectedMissingPvtDataInfo.Add(0, 1, "ns-1", "coll-1")
+	missingPvtDataInfo, err := store.GetMissingPvtDataInfoForMostRecentBlocks(10)
+	assert.NoError(err)
+	assert.Equal(expectedMissingPvtDataInfo, missingPvtDataInfo)
+
+	// the truncated blocks can be committed again, without the pvt data they had
+	assert.NoError(store.Prepare(2, nil, nil))
+	assert.NoError(store.Commit())
+	retrievedData, err = store.GetPvtDataByBlockNum(2, nil)
+	assert.NoError(err)
+	assert.Nil(retrievedData)
+
+	// a reopened store keeps its height, and can be truncated to empty
+	store, err = env.TestStoreProvider.OpenStore("TestStore")
+	assert.NoError(err)
+	height, err = store.LastCommittedBlockHeight()
+	assert.NoError(err)
+	assert.Equal(uint64(3), height)
+	assert.NoError(store.Truncate(0))
+	testEmpty(true, assert, store)
+	missingPvtDataInfo, err = store.GetMissingPvtDataInfoForMostRecentBlocks(10)
+	assert.NoError(err)
+	assert.Len(missingPvtDataInfo, 0)
+	assert.Len(retrieveKeys(env.TestStoreProvider.(*provider).dbProvider.GetDBHandle("TestStore"), nil, nil), 0)
+}
+
+func TestStoreExpiry(t *testing.T) {
+	env := NewTestStoreEnv(t)
+	defer env.Cleanup()
+	assert := assert.New(t)
+	store := env.TestStore
+	store.Init(&btlPolicyMock{map[string]uint64{"ns-1/coll-1": 1, "ns-2/coll-2": 2}})
+	testData := samplePvtData(t, []uint64{2, 4})
+
+	assert.NoError(store.Prepare(0, nil, nil))
+	assert.NoError(store.Commit())
+
+	// block 1 commits the pvt data of tx 2 and 4, and misses the pvt data of tx 5
+	missingData := make(ledger.TxMissingPvtDataMap)
+	missingData.Add(5, "ns-1", "coll-1", true)
+	assert.NoError(store.Prepare(1, testData, missingData))
+	assert.NoError(store.Commit())
+
+	// a rolled back block leaves no expiring pvt data
+	assert.NoError(store.Prepare(2, testData, nil))
+	assert.NoError(store.Rollback())
+
+	expiring, err := store.GetExpiringPvtData(2)
+	assert.NoError(err)
+	assert.Len(expiring, 0)
+	expiring, err = store.GetExpiringPvtData(3)
+	assert.NoError(err)
+	assert.Equal([]*ExpiringPvtData{
+		{CommittingBlk: 1, TxNum: 2, Namespace: "ns-1", Collection: "coll-1"},
+		{CommittingBlk: 1, TxNum: 4, Namespace: "ns-1", Collection: "coll-1"},
+		{CommittingBlk: 1, TxNum: 5, Namespace: "ns-1", Collection: "coll-1"},
+	}, expiring)
+
+	// the pvt data is kept until block 2 and purged upon the commit of block 3
+	assert.NoError(store.Prepare(2, nil, nil))
+	assert.NoError(store.Commit())
+	retrievedData, err := store.GetPvtDataByBlockNum(1, nil)
+	assert.NoError(err)
+	assert.Equal(testData, retrievedData)
+
+	assert.NoError(store.Prepare(3, nil, nil))
+	assert.NoError(store.Commit())
+	retrievedData, err = store.GetPvtDataByBlockNum(1, nil)
+	assert.NoError(err)
+	assert.Len(retrievedData, 2)
+	for _, txPvtData := range retrievedData {
+		assert.False(txPvtData.Has("ns-1", "coll-1"))
+		assert.True(txPvtData.Has("ns-1", "coll-2"))
+		assert.True(txPvtData.Has("ns-2", "coll-2"))
+	}
+	missingPvtDataInfo, err := store.GetMissingPvtDataInfoForMostRecentBlocks(10)
+	assert.NoError(err)
+	assert.Len(missingPvtDataInfo, 0)
+
+	assert.NoError(store.Prepare(4, nil, nil))
+	assert.NoError(store.Commit())
+	retrievedData, err = store.GetPvtDataByBlockNum(1, nil)
+	assert.NoError(err)
+	for _, txPvtData := range retrievedData {
+		assert.False(txPvtData.Has("ns-2", "coll-2"))
+		assert.True(txPvtData.Has("ns-1", "coll-2"))
+		assert.True(txPvtData.Has("ns-2", "coll-1"))
+	}
+	expiring, err = store.GetExpiringPvtData(10)
+	assert.NoError(err)
+	assert.Len(expiring, 0)
+}
+
+func TestExpiryKeyEncoding(t *testing.T) {
+	key := encodeExpiryKey(300, 10, 3, "ns", "coll")
+	expiringBlk, committingBlk, txNum, ns, coll := decodeExpiryKey(key)
+	assert.Equal(t, uint64(300), expiringBlk)
+	assert.Equal(t, uint64(10), committingBlk)
+	assert.Equal(t, uint64(3), txNum)
+	assert.Equal(t, "ns", ns)
+	assert.Equal(t, "coll", coll)
+
+	// the keys are sorted by the block the pvt data expires at
+	startKey, endKey := getKeysForExpiryRangeScan(300)
+	assert.True(t, string(startKey) < string(key) && string(key) < string(endKey))
+	assert.True(t, string(encodeExpiryKey(301, 0, 0, "ns", "coll")) > string(endKey))
+	_, endKey = getKeysForExpiryRangeScan(299)
+	assert.True(t, string(key) > string(endKey))
+}
+
+func TestMissingDataKeyEncoding(t *testing.T) {
+	key := encodeMissingDataKey(true, 10, 3, "ns", "coll")
+	blkNum, txNum, ns, coll := decodeMissingDataKey(key)
+	assert.Equal(t, uint64(10), blkNum)
+	assert.Equal(t, uint64(3), txNum)
+	assert.Equal(t, "ns", ns)
+	assert.Equal(t, "coll", coll)
+
+	// the keys of the most recent blocks come first
+	assert.True(t, string(encodeMissingDataKey(true, 11, 0, "ns", "coll")) < string(key))
+	assert.True(t, string(encodeMissingDataKey(false, 11, 0, "ns", "coll")) > string(key))
+}
+
 // TODO Add tests for simulating a crash between calls `Prepare` and `Commit`/`Rollback`
 
 func testEmpty(expectedEmpty bool, assert *assert.Assertions, store Store) {
@@ -148,3 +367,11 @@ func samplePvtData(t *testing.T, txNums []uint64) []*ledger.TxPvtData {
 	}
 	return pvtData
 }
+
+type btlPolicyMock struct {
+	btls map[string]uint64
+}
+
+func (p *btlPolicyMock) GetBTL(ns string, coll string, committingBlk uint64) (uint64, error) {
+	return p.btls[ns+"/"+coll], nil
+}
@@ -89,9 +89,13 @@ func SetupCoreYAMLConfig() {
 // ResetConfigToDefaultValues resets configurations optins back to defaults
 func ResetConfigToDefaultValues() {
 	//reset to defaults
-	viper.Set("ledger.state.couchDBConfig.queryLimit", 10000)
+	viper.Set("ledger.state.couchDBConfig.internalQueryLimit", 1000)
+	viper.Set("ledger.state.totalQueryLimit", 10000)
 	viper.Set("ledger.state.stateDatabase", "goleveldb")
 	viper.Set("ledger.history.enableHistoryDatabase", false)
+	viper.Set("ledger.blockchain.maxBlockfileSize", 64*1024*1024)
+	viper.Set("ledger.blockchain.compression", "none")
+	viper.Set("ledger.perLedger", []interface{}{})
 	viper.Set("peer.fileSystemPath", "/var/hyperledger/production")
 }
 
@@ -30,6 +30,20 @@ type CouchDBDef struct {
 	MaxRetries          int
 	MaxRetriesOnStartup int
 	RequestTimeout      time.Duration
+	// MaxConnections limits the number of concurrent requests to CouchDB, and so the number of
+	// pooled connections, zero meaning no limit
+	MaxConnections int
+	// MaxRetryWaitTime caps the exponential backoff between the retries of a request, zero meaning no cap
+	MaxRetryWaitTime time.Duration
+	// CircuitBreakerThreshold is the number of consecutive failed requests after which requests
+	// fail fast for CircuitBreakerCooldown, zero meaning the circuit breaker is disabled
+	CircuitBreakerThreshold int
+	CircuitBreakerCooldown  time.Duration
+	// PreserveValueBytes tells the state database to store all the values as binary attachments,
+	// so that JSON values are read back byte for byte instead of re-marshaled
+	PreserveValueBytes bool
+	// MaxBatchUpdateSize is the maximum number of documents saved by a single bulk update request
+	MaxBatchUpdateSize int
 }
 
 //GetCouchDBDefinition exposes the useCouchDB variable
@@ -41,6 +55,17 @@ func GetCouchDBDefinition() *CouchDBDef {
 	maxRetries := viper.GetInt("ledger.state.couchDBConfig.maxRetries")
 	maxRetriesOnStartup := viper.GetInt("ledger.state.couchDBConfig.maxRetriesOnStartup")
 	requestTimeout := viper.GetDuration("ledger.state.couchDBConfig.requestTimeout")
+	maxConnections := viper.GetInt("ledger.state.couchDBConfig.maxConnections")
+	maxRetryWaitTime := viper.GetDuration("ledger.state.couchDBConfig.maxRetryWaitTime")
+	circuitBreakerThreshold := viper.GetInt("ledger.state.couchDBConfig.circuitBreakerThreshold")
+	circuitBreakerCooldown := viper.GetDuration("ledger.state.couchDBConfig.circuitBreakerCooldown")
+	preserveValueBytes := viper.GetBool("ledger.state.couchDBConfig.preserveValueBytes")
+	maxBatchUpdateSize := viper.GetInt("ledger.state.couchDBConfig.maxBatchUpdateSize")
+	// if maxBatchUpdateSize was unset, default to 1000
+	if !viper.IsSet("ledger.state.couchDBConfig.maxBatchUpdateSize") {
+		maxBatchUpdateSize = 1000
+	}
 
-	return &CouchDBDef{couchDBAddress, username, password, maxRetries, maxRetriesOnStartup, requestTimeout}
+	return &CouchDBDef{couchDBAddress, username, password, maxRetries, maxRetriesOnStartup, requestTimeout,
+		maxConnections, maxRetryWaitTime, circuitBreakerThreshold, circuitBreakerCooldown, preserveValueBytes, maxBatchUpdateSize}
 }
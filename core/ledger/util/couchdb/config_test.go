@@ -31,4 +31,10 @@ func TestGetCouchDBDefinition(t *testing.T) {
 	testutil.AssertEquals(t, couchDBDef.MaxRetries, 3)
 	testutil.AssertEquals(t, couchDBDef.MaxRetriesOnStartup, 10)
 	testutil.AssertEquals(t, couchDBDef.RequestTimeout, time.Second*35)
+	testutil.AssertEquals(t, couchDBDef.MaxConnections, 100)
+	testutil.AssertEquals(t, couchDBDef.MaxRetryWaitTime, time.Second*10)
+	testutil.AssertEquals(t, couchDBDef.CircuitBreakerThreshold, 0)
+	testutil.AssertEquals(t, couchDBDef.CircuitBreakerCooldown, time.Second*5)
+	testutil.AssertEquals(t, couchDBDef.PreserveValueBytes, false)
+	testutil.AssertEquals(t, couchDBDef.MaxBatchUpdateSize, 1000)
 }
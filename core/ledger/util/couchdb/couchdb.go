@@ -39,6 +39,7 @@ import (
 
 	"github.com/hyperledger/fabric/common/flogging"
 	logging "github.com/op/go-logging"
+	"golang.org/x/net/context"
 )
 
 var logger = flogging.MustGetLogger("couchdb")
@@ -131,12 +132,19 @@ type CouchConnectionDef struct {
 	MaxRetries          int
 	MaxRetriesOnStartup int
 	RequestTimeout      time.Duration
+	MaxConnections      int
+	MaxRetryWaitTime    time.Duration
+	// CircuitBreakerThreshold and CircuitBreakerCooldown configure the circuit breaker, see CouchDBDef
+	CircuitBreakerThreshold int
+	CircuitBreakerCooldown  time.Duration
 }
 
 //CouchInstance represents a CouchDB instance
 type CouchInstance struct {
-	conf   CouchConnectionDef //connection configuration
-	client *http.Client       // a client to connect to this instance
+	conf    CouchConnectionDef //connection configuration
+	client  *http.Client       // a client to connect to this instance
+	pool    *connectionPool    // limits the concurrent requests to this instance
+	breaker *circuitBreaker    // fails requests fast while this instance is failing
 }
 
 //CouchDatabase represents a database within a CouchDB instance
@@ -236,8 +244,8 @@ func CreateConnectionDefinition(couchDBAddress, username, password string, maxRe
 	logger.Debugf("Exiting CreateConnectionDefinition()")
 
 	//return an object containing the connection information
-	return &CouchConnectionDef{finalURL.String(), username, password, maxRetries,
-		maxRetriesOnStartup, requestTimeout}, nil
+	return &CouchConnectionDef{URL: finalURL.String(), Username: username, Password: password, MaxRetries: maxRetries,
+		MaxRetriesOnStartup: maxRetriesOnStartup, RequestTimeout: requestTimeout}, nil
 
 }
 
@@ -381,6 +389,22 @@ func (couchInstance *CouchInstance) VerifyCouchConfig() (*ConnectionInfo, *DBRet
 	return dbResponse, couchDBReturn, nil
 }
 
+// HealthCheck checks that the CouchDB instance is reachable with a single request, without retries,
+// so that an unreachable instance is reported promptly by the operations server
+func (couchInstance *CouchInstance) HealthCheck(ctx context.Context) error {
+	connectURL, err := url.Parse(couchInstance.conf.URL)
+	if err != nil {
+		return err
+	}
+	connectURL.Path = "/"
+	resp, _, err := couchInstance.handleRequest(http.MethodGet, connectURL.String(), nil, "", "", 1, true)
+	if err != nil {
+		return fmt.Errorf("CouchDB at [%s] is unreachable: %s", couchInstance.conf.URL, err)
+	}
+	closeResponseBody(resp)
+	return nil
+}
+
 //DropDatabase provides method to drop an existing database
 func (dbclient *CouchDatabase) DropDatabase() (*DBOperationResponse, error) {
 
@@ -1095,8 +1119,13 @@ func (dbclient *CouchDatabase) BatchUpdateDocuments(documents []*CouchDoc) ([]*B
 		//create a document map
 		var document = make(map[string]interface{})
 
-		//unmarshal the JSON component of the CouchDoc into the document
-		json.Unmarshal(jsonDocument.JSONValue, &document)
+		//unmarshal the JSON component of the CouchDoc into the document,
+		//keeping the numbers as they are written rather than as float64
+		decoder := json.NewDecoder(bytes.NewReader(jsonDocument.JSONValue))
+		decoder.UseNumber()
+		if err := decoder.Decode(&document); err != nil {
+			return nil, err
+		}
 
 		//iterate through any attachments
 		if len(jsonDocument.Attachments) > 0 {
@@ -1218,18 +1247,29 @@ func (couchInstance *CouchInstance) handleRequest(method, connectURL string, dat
 	//create the return objects for couchDB
 	var resp *http.Response
 	var errResp error
+	var waitDuration time.Duration
 	couchDBReturn := &DBReturn{}
 
-	//set initial wait duration for retries
-	waitDuration := retryWaitTime * time.Millisecond
-
 	if maxRetries < 1 {
 		return nil, nil, fmt.Errorf("Number of retries must be greater than zero.")
 	}
 
+	if err := couchInstance.breaker.allow(); err != nil {
+		couchMetrics.circuitRejections.Inc(1)
+		return nil, nil, err
+	}
+	couchMetrics.requests.Inc(1)
+
 	//attempt the http request for the max number of retries
 	for attempts := 0; attempts < maxRetries; attempts++ {
 
+		//wait for the backoff of the previous attempt, if any
+		if attempts > 0 {
+			couchMetrics.retries.Inc(1)
+			time.Sleep(waitDuration)
+		}
+		waitDuration = retryWait(attempts, couchInstance.conf.MaxRetryWaitTime)
+
 		//Set up a buffer for the payload data
 		payloadData := new(bytes.Buffer)
 
@@ -1286,8 +1326,14 @@ func (couchInstance *CouchInstance) handleRequest(method, connectURL string, dat
 			logger.Debugf("HTTP Request: %s", bytes.Replace(dump, []byte{0x0d, 0x0a}, []byte{0x20, 0x7c, 0x20}, -1))
 		}
 
-		//Execute http request
+		//Execute http request, the connection is released once the response body is closed
+		couchInstance.pool.acquire()
 		resp, errResp = couchInstance.client.Do(req)
+		if errResp != nil || resp == nil {
+			couchInstance.pool.release()
+		} else {
+			resp.Body = &pooledBody{ReadCloser: resp.Body, pool: couchInstance.pool}
+		}
 
 		//check to see if the return from CouchDB is valid
 		if invalidCouchDBReturn(resp, errResp) {
@@ -1299,6 +1345,11 @@ func (couchInstance *CouchInstance) handleRequest(method, connectURL string, dat
 			break
 		}
 
+		//if this was the last attempt, drop out of the retry and report the failure below
+		if attempts == maxRetries-1 {
+			break
+		}
+
 		//if this is an unexpected golang http error, log the error and retry
 		if errResp != nil {
 
@@ -1325,14 +1376,16 @@ func (couchInstance *CouchInstance) handleRequest(method, connectURL string, dat
 				waitDuration.String(), attempts+1, couchDBReturn.Error, resp.Status, couchDBReturn.Reason)
 
 		}
-		//sleep for specified sleep time, then retry
-		time.Sleep(waitDuration)
-
-		//backoff, doubling the retry time for next attempt
-		waitDuration *= 2
 
 	} // end retry loop
 
+	//the request failed if CouchDB could not be reached or still returned a 500 error after the retries
+	failed := errResp != nil || resp == nil || resp.StatusCode >= 500
+	couchInstance.breaker.record(failed)
+	if failed {
+		couchMetrics.failures.Inc(1)
+	}
+
 	//if a golang http error is still present after retries are exhausted, return the error
 	if errResp != nil {
 		return nil, nil, errResp
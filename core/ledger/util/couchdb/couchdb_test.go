@@ -125,7 +125,7 @@ func TestBadCouchDBInstance(t *testing.T) {
 	client := &http.Client{}
 
 	//Create a bad couchdb instance
-	badCouchDBInstance := CouchInstance{badConnectDef, client}
+	badCouchDBInstance := CouchInstance{conf: badConnectDef, client: client}
 
 	//Create a bad CouchDatabase
 	badDB := CouchDatabase{badCouchDBInstance, "baddb"}
@@ -32,12 +32,24 @@ var maxLength = 249
 func CreateCouchInstance(couchDBConnectURL, id, pw string, maxRetries,
 	maxRetriesOnStartup int, connectionTimeout time.Duration) (*CouchInstance, error) {
 
-	couchConf, err := CreateConnectionDefinition(couchDBConnectURL,
-		id, pw, maxRetries, maxRetriesOnStartup, connectionTimeout)
+	return CreateCouchInstanceFromDefinition(&CouchDBDef{URL: couchDBConnectURL, Username: id, Password: pw,
+		MaxRetries: maxRetries, MaxRetriesOnStartup: maxRetriesOnStartup, RequestTimeout: connectionTimeout})
+}
+
+//CreateCouchInstanceFromDefinition creates a CouchDB instance with the connection pooling,
+//retry backoff and circuit breaking settings of the definition
+func CreateCouchInstanceFromDefinition(couchDBDef *CouchDBDef) (*CouchInstance, error) {
+
+	couchConf, err := CreateConnectionDefinition(couchDBDef.URL, couchDBDef.Username, couchDBDef.Password,
+		couchDBDef.MaxRetries, couchDBDef.MaxRetriesOnStartup, couchDBDef.RequestTimeout)
 	if err != nil {
 		logger.Errorf("Error during CouchDB CreateConnectionDefinition(): %s\n", err.Error())
 		return nil, err
 	}
+	couchConf.MaxConnections = couchDBDef.MaxConnections
+	couchConf.MaxRetryWaitTime = couchDBDef.MaxRetryWaitTime
+	couchConf.CircuitBreakerThreshold = couchDBDef.CircuitBreakerThreshold
+	couchConf.CircuitBreakerCooldown = couchDBDef.CircuitBreakerCooldown
 
 	// Create the http client once
 	// Clients and Transports are safe for concurrent use by multiple goroutines
@@ -46,10 +58,20 @@ func CreateCouchInstance(couchDBConnectURL, id, pw string, maxRetries,
 
 	transport := &http.Transport{Proxy: http.ProxyFromEnvironment}
 	transport.DisableCompression = false
+	// keep the connections of all the concurrent requests open for reuse,
+	// instead of the two idle connections per host kept by default
+	if couchConf.MaxConnections > 0 {
+		transport.MaxIdleConnsPerHost = couchConf.MaxConnections
+	}
 	client.Transport = transport
 
 	//Create the CouchDB instance
-	couchInstance := &CouchInstance{conf: *couchConf, client: client}
+	couchInstance := &CouchInstance{
+		conf:    *couchConf,
+		client:  client,
+		pool:    newConnectionPool(couchConf.MaxConnections),
+		breaker: newCircuitBreaker(couchConf.CircuitBreakerThreshold, couchConf.CircuitBreakerCooldown),
+	}
 
 	connectInfo, retVal, verifyErr := couchInstance.VerifyCouchConfig()
 	if verifyErr != nil {
@@ -0,0 +1,153 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package couchdb
+
+import (
+	"errors"
+	"io"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/hyperledger/fabric/common/metrics"
+)
+
+// ErrCircuitOpen is returned for the requests that are not sent to CouchDB
+// because too many of the preceding requests failed
+var ErrCircuitOpen = errors.New("CouchDB circuit breaker is open, the request was not sent")
+
+// circuitBreaker fails requests fast once a number of consecutive requests have failed, so that
+// an overloaded CouchDB is given time to recover instead of receiving more retries. After the
+// cooldown the requests are sent again, and the first failure opens the circuit once more
+type circuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	lock      sync.Mutex
+	failures  int
+	openUntil time.Time
+}
+
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{threshold: threshold, cooldown: cooldown}
+}
+
+// allow returns ErrCircuitOpen if the request must not be sent
+func (cb *circuitBreaker) allow() error {
+	if cb == nil || cb.threshold <= 0 {
+		return nil
+	}
+	cb.lock.Lock()
+	defer cb.lock.Unlock()
+	if time.Now().Before(cb.openUntil) {
+		return ErrCircuitOpen
+	}
+	return nil
+}
+
+// record records the outcome of a request that was sent
+func (cb *circuitBreaker) record(failed bool) {
+	if cb == nil || cb.threshold <= 0 {
+		return
+	}
+	cb.lock.Lock()
+	defer cb.lock.Unlock()
+	if !failed {
+		cb.failures = 0
+		return
+	}
+	cb.failures++
+	if cb.failures >= cb.threshold {
+		if time.Now().After(cb.openUntil) {
+			logger.Warningf("%d consecutive CouchDB requests failed, failing requests for %s", cb.failures, cb.cooldown)
+		}
+		cb.openUntil = time.Now().Add(cb.cooldown)
+	}
+}
+
+// connectionPool limits the number of concurrent requests to CouchDB
+type connectionPool struct {
+	slots  chan struct{}
+	active int64
+}
+
+// newConnectionPool returns a pool of the given size, which is unlimited if zero
+func newConnectionPool(size int) *connectionPool {
+	p := &connectionPool{}
+	if size > 0 {
+		p.slots = make(chan struct{}, size)
+	}
+	return p
+}
+
+// acquire blocks until a connection is available
+func (p *connectionPool) acquire() {
+	if p == nil {
+		return
+	}
+	if p.slots != nil {
+		p.slots <- struct{}{}
+	}
+	couchMetrics.activeRequests.Update(float64(atomic.AddInt64(&p.active, 1)))
+}
+
+func (p *connectionPool) release() {
+	if p == nil {
+		return
+	}
+	couchMetrics.activeRequests.Update(float64(atomic.AddInt64(&p.active, -1)))
+	if p.slots != nil {
+		<-p.slots
+	}
+}
+
+// pooledBody releases the connection of a response to the pool once the body is closed
+type pooledBody struct {
+	io.ReadCloser
+	pool *connectionPool
+	once sync.Once
+}
+
+func (b *pooledBody) Close() error {
+	b.once.Do(b.pool.release)
+	return b.ReadCloser.Close()
+}
+
+// retryWait returns the time to wait before the given retry attempt, starting at zero. The wait
+// doubles with each attempt up to maxWait, if set, and a random jitter of up to half of the wait is
+// subtracted so that the requests that failed together are not retried together
+func retryWait(attempt int, maxWait time.Duration) time.Duration {
+	wait := retryWaitTime * time.Millisecond
+	for i := 0; i < attempt && (maxWait <= 0 || wait < maxWait); i++ {
+		wait *= 2
+	}
+	if maxWait > 0 && wait > maxWait {
+		wait = maxWait
+	}
+	return wait - time.Duration(rand.Int63n(int64(wait/2)+1))
+}
+
+var couchMetrics = newRequestMetrics(metrics.NewRootScope().SubScope("couchdb"))
+
+type requestMetrics struct {
+	requests          metrics.Counter
+	retries           metrics.Counter
+	failures          metrics.Counter
+	circuitRejections metrics.Counter
+	activeRequests    metrics.Gauge
+}
+
+func newRequestMetrics(scope metrics.Scope) *requestMetrics {
+	return &requestMetrics{
+		requests:          scope.Counter("requests"),
+		retries:           scope.Counter("request_retries"),
+		failures:          scope.Counter("request_failures"),
+		circuitRejections: scope.Counter("circuit_breaker_rejections"),
+		activeRequests:    scope.Gauge("active_requests"),
+	}
+}
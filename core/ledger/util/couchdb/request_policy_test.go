@@ -0,0 +1,113 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package couchdb
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric/common/ledger/testutil"
+)
+
+func TestRetryWait(t *testing.T) {
+	for attempt := 0; attempt < 6; attempt++ {
+		wait := retryWait(attempt, 0)
+		max := (retryWaitTime * time.Millisecond) << uint(attempt)
+		testutil.AssertEquals(t, wait <= max && wait >= max/2, true)
+	}
+	for attempt := 0; attempt < 100; attempt++ {
+		wait := retryWait(attempt, time.Second)
+		testutil.AssertEquals(t, wait <= time.Second, true)
+	}
+	testutil.AssertEquals(t, retryWait(20, time.Second) >= time.Second/2, true)
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	disabled := newCircuitBreaker(0, time.Minute)
+	for i := 0; i < 10; i++ {
+		disabled.record(true)
+	}
+	testutil.AssertNoError(t, disabled.allow(), "")
+
+	cb := newCircuitBreaker(2, 100*time.Millisecond)
+	cb.record(true)
+	cb.record(false)
+	cb.record(true)
+	testutil.AssertNoError(t, cb.allow(), "A success should reset the consecutive failures")
+	cb.record(true)
+	testutil.AssertEquals(t, cb.allow(), ErrCircuitOpen)
+
+	time.Sleep(150 * time.Millisecond)
+	testutil.AssertNoError(t, cb.allow(), "The circuit should be closed after the cooldown")
+	cb.record(true)
+	testutil.AssertEquals(t, cb.allow(), ErrCircuitOpen)
+}
+
+func TestHandleRequestPoolAndCircuitBreaker(t *testing.T) {
+	var requests, active, maxActive int32
+	var failing int32 = 1
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		n := atomic.AddInt32(&active, 1)
+		defer atomic.AddInt32(&active, -1)
+		for {
+			max := atomic.LoadInt32(&maxActive)
+			if n <= max || atomic.CompareAndSwapInt32(&maxActive, max, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		if atomic.LoadInt32(&failing) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"error":"overloaded","reason":"test"}`))
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	couchInstance := &CouchInstance{
+		conf:    CouchConnectionDef{URL: server.URL, MaxRetries: 2, MaxRetryWaitTime: time.Millisecond},
+		client:  &http.Client{Timeout: time.Second},
+		pool:    newConnectionPool(2),
+		breaker: newCircuitBreaker(2, time.Hour),
+	}
+
+	// the failed requests open the circuit, after which requests are not sent
+	for i := 0; i < 2; i++ {
+		_, _, err := couchInstance.handleRequest(http.MethodGet, server.URL, nil, "", "", 2, true)
+		testutil.AssertError(t, err, "")
+		testutil.AssertEquals(t, strings.Contains(err.Error(), "overloaded"), true)
+	}
+	testutil.AssertEquals(t, atomic.LoadInt32(&requests), int32(4))
+	_, _, err := couchInstance.handleRequest(http.MethodGet, server.URL, nil, "", "", 2, true)
+	testutil.AssertEquals(t, err, ErrCircuitOpen)
+	testutil.AssertEquals(t, atomic.LoadInt32(&requests), int32(4))
+
+	// the concurrent requests are limited by the pool
+	atomic.StoreInt32(&failing, 0)
+	couchInstance.breaker = newCircuitBreaker(2, time.Hour)
+	done := make(chan error)
+	for i := 0; i < 6; i++ {
+		go func() {
+			resp, _, err := couchInstance.handleRequest(http.MethodGet, server.URL, nil, "", "", 2, true)
+			if err == nil {
+				closeResponseBody(resp)
+			}
+			done <- err
+		}()
+	}
+	for i := 0; i < 6; i++ {
+		testutil.AssertNoError(t, <-done, "")
+	}
+	testutil.AssertEquals(t, atomic.LoadInt32(&maxActive) <= 2, true)
+	testutil.AssertEquals(t, atomic.LoadInt64(&couchInstance.pool.active), int64(0))
+}
@@ -0,0 +1,71 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package util
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/peer"
+)
+
+// GetTxValidationReasons returns the validation reasons recorded in the metadata of the given block,
+// keyed by the index of the transaction in the block. Blocks without recorded reasons yield an empty map
+func GetTxValidationReasons(block *common.Block) (map[uint64]*peer.TxValidationReason, error) {
+	reasons := make(map[uint64]*peer.TxValidationReason)
+	if block.Metadata == nil || len(block.Metadata.Metadata) <= int(common.BlockMetadataIndex_TRANSACTIONS_VALIDATION_REASONS) {
+		return reasons, nil
+	}
+	reasonsBytes := block.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_VALIDATION_REASONS]
+	if len(reasonsBytes) == 0 {
+		return reasons, nil
+	}
+	txReasons := &peer.TxValidationReasons{}
+	if err := proto.Unmarshal(reasonsBytes, txReasons); err != nil {
+		return nil, fmt.Errorf("error unmarshaling the validation reasons of block %d: %s", block.Header.Number, err)
+	}
+	for _, reason := range txReasons.Reasons {
+		reasons[reason.TxIndex] = reason
+	}
+	return reasons, nil
+}
+
+// AddTxValidationReasons records the given validation reasons in the metadata of the block,
+// replacing the reasons already recorded for the same transactions
+func AddTxValidationReasons(block *common.Block, reasons ...*peer.TxValidationReason) error {
+	if len(reasons) == 0 {
+		return nil
+	}
+	existing, err := GetTxValidationReasons(block)
+	if err != nil {
+		return err
+	}
+	for _, reason := range reasons {
+		existing[reason.TxIndex] = reason
+	}
+	txReasons := &peer.TxValidationReasons{}
+	for _, reason := range existing {
+		txReasons.Reasons = append(txReasons.Reasons, reason)
+	}
+	sort.Slice(txReasons.Reasons, func(i, j int) bool {
+		return txReasons.Reasons[i].TxIndex < txReasons.Reasons[j].TxIndex
+	})
+	reasonsBytes, err := proto.Marshal(txReasons)
+	if err != nil {
+		return err
+	}
+	if block.Metadata == nil {
+		block.Metadata = &common.BlockMetadata{}
+	}
+	for len(block.Metadata.Metadata) <= int(common.BlockMetadataIndex_TRANSACTIONS_VALIDATION_REASONS) {
+		block.Metadata.Metadata = append(block.Metadata.Metadata, []byte{})
+	}
+	block.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_VALIDATION_REASONS] = reasonsBytes
+	return nil
+}
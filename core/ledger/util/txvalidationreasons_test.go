@@ -0,0 +1,46 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package util
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/peer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTxValidationReasons(t *testing.T) {
+	block := &common.Block{
+		Header:   &common.BlockHeader{Number: 5},
+		Metadata: &common.BlockMetadata{Metadata: [][]byte{{}, {}, {}}},
+	}
+	reasons, err := GetTxValidationReasons(block)
+	assert.NoError(t, err)
+	assert.Empty(t, reasons)
+
+	err = AddTxValidationReasons(block,
+		&peer.TxValidationReason{TxIndex: 3, Code: peer.TxValidationCode_ENDORSEMENT_POLICY_FAILURE, Namespace: "cc"},
+		&peer.TxValidationReason{TxIndex: 1, Code: peer.TxValidationCode_MVCC_READ_CONFLICT, Namespace: "cc", Key: "key1"})
+	assert.NoError(t, err)
+	assert.Len(t, block.Metadata.Metadata, int(common.BlockMetadataIndex_TRANSACTIONS_VALIDATION_REASONS)+1)
+
+	// a reason added later replaces the one recorded for the same transaction
+	err = AddTxValidationReasons(block,
+		&peer.TxValidationReason{TxIndex: 1, Code: peer.TxValidationCode_PHANTOM_READ_CONFLICT, Namespace: "cc"})
+	assert.NoError(t, err)
+
+	reasons, err = GetTxValidationReasons(block)
+	assert.NoError(t, err)
+	assert.Len(t, reasons, 2)
+	assert.Equal(t, peer.TxValidationCode_PHANTOM_READ_CONFLICT, reasons[1].Code)
+	assert.Equal(t, peer.TxValidationCode_ENDORSEMENT_POLICY_FAILURE, reasons[3].Code)
+
+	block.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_VALIDATION_REASONS] = []byte("garbage")
+	_, err = GetTxValidationReasons(block)
+	assert.Error(t, err)
+}
@@ -17,6 +17,8 @@ limitations under the License.
 package support
 
 import (
+	channelconfig "github.com/hyperledger/fabric/common/config/channel"
+	mockchannelconfig "github.com/hyperledger/fabric/common/mocks/config"
 	mockpolicies "github.com/hyperledger/fabric/common/mocks/policies"
 	"github.com/hyperledger/fabric/common/policies"
 	"github.com/hyperledger/fabric/core/ledger"
@@ -25,9 +27,10 @@ import (
 )
 
 type Support struct {
-	LedgerVal     ledger.PeerLedger
-	MSPManagerVal msp.MSPManager
-	ApplyVal      error
+	LedgerVal       ledger.PeerLedger
+	MSPManagerVal   msp.MSPManager
+	ApplyVal        error
+	CapabilitiesVal channelconfig.ApplicationCapabilities
 }
 
 // Ledger returns LedgerVal
@@ -52,3 +55,11 @@ func (ms *Support) PolicyManager() policies.Manager {
 func (cs *Support) GetMSPIDs(cid string) []string {
 	return []string{"DEFAULT"}
 }
+
+// Capabilities returns CapabilitiesVal if set, otherwise capabilities requiring nothing
+func (cs *Support) Capabilities() channelconfig.ApplicationCapabilities {
+	if cs.CapabilitiesVal == nil {
+		return &mockchannelconfig.ApplicationCapabilities{}
+	}
+	return cs.CapabilitiesVal
+}
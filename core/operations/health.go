@@ -0,0 +1,142 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package operations
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+const (
+	// StatusOK is the status reported when all the checks pass
+	StatusOK = "OK"
+	// StatusUnavailable is the status reported when some of the checks fail
+	StatusUnavailable = "Service Unavailable"
+)
+
+// HealthChecker is implemented by the components whose health is reported by the operations server
+type HealthChecker interface {
+	// HealthCheck returns an error when the component is unhealthy. Implementations should return
+	// once the context is done
+	HealthCheck(ctx context.Context) error
+}
+
+// CheckerFunc adapts a function to the HealthChecker interface
+type CheckerFunc func(ctx context.Context) error
+
+// HealthCheck calls f(ctx)
+func (f CheckerFunc) HealthCheck(ctx context.Context) error {
+	return f(ctx)
+}
+
+// FailedCheck names a component whose health check failed, and the reason it failed
+type FailedCheck struct {
+	Component string `json:"component"`
+	Reason    string `json:"reason"`
+}
+
+// HealthStatus is the body of the responses of the health endpoints
+type HealthStatus struct {
+	Status       string        `json:"status"`
+	Time         time.Time     `json:"time"`
+	FailedChecks []FailedCheck `json:"failed_checks,omitempty"`
+}
+
+// healthHandler serves the aggregated health of the checkers registered with it
+type healthHandler struct {
+	mutex    sync.RWMutex
+	checkers map[string]HealthChecker
+	timeout  time.Duration
+	now      func() time.Time
+}
+
+func newHealthHandler(timeout time.Duration) *healthHandler {
+	return &healthHandler{
+		checkers: make(map[string]HealthChecker),
+		timeout:  timeout,
+		now:      time.Now,
+	}
+}
+
+func (h *healthHandler) register(component string, checker HealthChecker) error {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	if _, exists := h.checkers[component]; exists {
+		return fmt.Errorf("a health checker for component [%s] is already registered", component)
+	}
+	h.checkers[component] = checker
+	return nil
+}
+
+func (h *healthHandler) deregister(component string) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	delete(h.checkers, component)
+}
+
+// runChecks runs the checks concurrently, each bounded by the timeout of the handler, and returns the
+// failed checks ordered by component
+func (h *healthHandler) runChecks(ctx context.Context) []FailedCheck {
+	h.mutex.RLock()
+	checkers := make(map[string]HealthChecker, len(h.checkers))
+	for component, checker := range h.checkers {
+		checkers[component] = checker
+	}
+	h.mutex.RUnlock()
+
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	var lock sync.Mutex
+	var failedChecks []FailedCheck
+	var wg sync.WaitGroup
+	for component, checker := range checkers {
+		wg.Add(1)
+		go func(component string, checker HealthChecker) {
+			defer wg.Done()
+			errC := make(chan error, 1)
+			go func() { errC <- checker.HealthCheck(ctx) }()
+			var err error
+			select {
+			case err = <-errC:
+			case <-ctx.Done():
+				err = fmt.Errorf("health check timed out: %s", ctx.Err())
+			}
+			if err == nil {
+				return
+			}
+			lock.Lock()
+			failedChecks = append(failedChecks, FailedCheck{Component: component, Reason: err.Error()})
+			lock.Unlock()
+		}(component, checker)
+	}
+	wg.Wait()
+
+	sort.Slice(failedChecks, func(i, j int) bool { return failedChecks[i].Component < failedChecks[j].Component })
+	return failedChecks
+}
+
+func (h *healthHandler) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		resp.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	status := HealthStatus{Status: StatusOK, Time: h.now()}
+	code := http.StatusOK
+	if failedChecks := h.runChecks(req.Context()); len(failedChecks) > 0 {
+		status.Status = StatusUnavailable
+		status.FailedChecks = failedChecks
+		code = http.StatusServiceUnavailable
+		logger.Warningf("Health checks failed: %+v", failedChecks)
+	}
+	writeJSON(resp, code, status)
+}
@@ -0,0 +1,57 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package operations
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/hyperledger/fabric/common/flogging"
+)
+
+// LogSpec is the body of the requests and responses of the logging specification endpoint
+type LogSpec struct {
+	Spec    string            `json:"spec"`
+	Modules map[string]string `json:"modules,omitempty"`
+}
+
+// ErrorResponse is the body of the responses to rejected requests
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// logSpecHandler queries the active logging specification, along with the levels of the
+// modules, and activates new specifications at runtime
+type logSpecHandler struct{}
+
+func (h *logSpecHandler) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case http.MethodGet:
+		writeJSON(resp, http.StatusOK, LogSpec{Spec: flogging.Spec(), Modules: flogging.ModuleLevels()})
+	case http.MethodPut:
+		var logSpec LogSpec
+		if err := json.NewDecoder(req.Body).Decode(&logSpec); err != nil {
+			writeJSON(resp, http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+		if err := flogging.ActivateSpec(logSpec.Spec); err != nil {
+			writeJSON(resp, http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+		resp.WriteHeader(http.StatusNoContent)
+	default:
+		resp.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func writeJSON(resp http.ResponseWriter, code int, body interface{}) {
+	resp.Header().Set("Content-Type", "application/json")
+	resp.WriteHeader(code)
+	if err := json.NewEncoder(resp).Encode(body); err != nil {
+		logger.Errorf("Failed to write the response: %s", err)
+	}
+}
@@ -0,0 +1,67 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package operations
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogSpec(t *testing.T) {
+	defer flogging.Reset()
+	flogging.MustGetLogger("gossip/state")
+
+	system := NewSystem(Options{ListenAddress: "127.0.0.1:0"})
+	assert.NoError(t, system.Start())
+	defer system.Stop()
+	url := "http://" + system.Addr() + URLLogSpec
+
+	put := func(body string) *http.Response {
+		req, err := http.NewRequest(http.MethodPut, url, strings.NewReader(body))
+		assert.NoError(t, err)
+		resp, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		return resp
+	}
+	get := func() LogSpec {
+		resp, err := http.Get(url)
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		var logSpec LogSpec
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&logSpec))
+		return logSpec
+	}
+
+	resp := put(`{"spec": "warning:gossip/state=debug"}`)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	logSpec := get()
+	assert.Equal(t, "warning:gossip/state=debug", logSpec.Spec)
+	assert.Equal(t, "DEBUG", logSpec.Modules["gossip/state"])
+	assert.Equal(t, "WARNING", logSpec.Modules[pkgLogID])
+
+	for _, body := range []string{`{"spec": "gossip=foo"}`, `not json`} {
+		resp = put(body)
+		var errResp ErrorResponse
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&errResp))
+		resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		assert.NotEmpty(t, errResp.Error)
+	}
+	assert.Equal(t, "warning:gossip/state=debug", get().Spec, "Expected the rejected specs to leave the active spec unchanged")
+
+	resp, err := http.Post(url, "application/json", strings.NewReader(`{"spec": "debug"}`))
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+}
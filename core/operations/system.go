@@ -0,0 +1,193 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package operations serves the operations endpoints shared by the peer and the orderer, which
+// report the liveness and the readiness of the process from the health of its components, and
+// change the logging specification of the process at runtime.
+package operations
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric/common/flogging"
+	logging "github.com/op/go-logging"
+)
+
+const pkgLogID = "operations"
+
+var logger *logging.Logger
+
+func init() {
+	logger = flogging.MustGetLogger(pkgLogID)
+}
+
+const (
+	// URLLiveness is the path at which the liveness of the process is reported
+	URLLiveness = "/healthz/live"
+	// URLReadiness is the path at which the readiness of the process is reported
+	URLReadiness = "/healthz/ready"
+	// URLLogSpec is the path at which the logging specification is queried and changed
+	URLLogSpec = "/logspec"
+
+	// DefaultHealthCheckTimeout bounds the health checks when no timeout is configured
+	DefaultHealthCheckTimeout = 30 * time.Second
+)
+
+// TLS contains the TLS configuration of the operations server
+type TLS struct {
+	Enabled            bool
+	CertFile           string
+	KeyFile            string
+	ClientCertRequired bool
+	ClientRootCAs      []string
+}
+
+// Options contains the configuration of the operations server
+type Options struct {
+	ListenAddress      string
+	HealthCheckTimeout time.Duration
+	TLS                TLS
+}
+
+// System serves the operations endpoints. The liveness endpoint aggregates the health of the
+// components registered with RegisterLivenessChecker, which the process cannot recover from being
+// unhealthy without a restart; the readiness endpoint additionally aggregates the health of the
+// components registered with RegisterReadinessChecker, such as connections to external services
+type System struct {
+	options   Options
+	liveness  *healthHandler
+	readiness *healthHandler
+	mutex     sync.Mutex
+	server    *http.Server
+	listener  net.Listener
+}
+
+// NewSystem returns a System configured with the given options, which serves once started
+func NewSystem(options Options) *System {
+	if options.HealthCheckTimeout == 0 {
+		options.HealthCheckTimeout = DefaultHealthCheckTimeout
+	}
+	return &System{
+		options:   options,
+		liveness:  newHealthHandler(options.HealthCheckTimeout),
+		readiness: newHealthHandler(options.HealthCheckTimeout),
+	}
+}
+
+// RegisterLivenessChecker registers the health checker of a component, which is reported by both
+// the liveness and the readiness endpoints. An error is returned if the component is already registered
+func (s *System) RegisterLivenessChecker(component string, checker HealthChecker) error {
+	if err := s.readiness.register(component, checker); err != nil {
+		return err
+	}
+	if err := s.liveness.register(component, checker); err != nil {
+		s.readiness.deregister(component)
+		return err
+	}
+	return nil
+}
+
+// RegisterReadinessChecker registers the health checker of a component, which is reported by the
+// readiness endpoint only. An error is returned if the component is already registered
+func (s *System) RegisterReadinessChecker(component string, checker HealthChecker) error {
+	return s.readiness.register(component, checker)
+}
+
+// Start starts serving the operations endpoints
+func (s *System) Start() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.server != nil {
+		return fmt.Errorf("operations server already started")
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(URLLiveness, s.liveness)
+	mux.Handle(URLReadiness, s.readiness)
+	mux.Handle(URLLogSpec, &logSpecHandler{})
+	server := &http.Server{Handler: mux}
+
+	listener, err := net.Listen("tcp", s.options.ListenAddress)
+	if err != nil {
+		return err
+	}
+	if s.options.TLS.Enabled {
+		tlsConfig, err := s.options.TLS.config()
+		if err != nil {
+			listener.Close()
+			return err
+		}
+		server.TLSConfig = tlsConfig
+		listener = tls.NewListener(listener, tlsConfig)
+	}
+	s.server = server
+	s.listener = listener
+
+	go func() {
+		if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			logger.Errorf("Operations server failed: %s", err)
+		}
+	}()
+	logger.Infof("Serving operations endpoints on %s", listener.Addr())
+	return nil
+}
+
+// Stop stops serving the operations endpoints
+func (s *System) Stop() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.server == nil {
+		return nil
+	}
+	err := s.server.Close()
+	s.server = nil
+	s.listener = nil
+	return err
+}
+
+// Addr returns the address the operations endpoints are served on, once started
+func (s *System) Addr() string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.listener == nil {
+		return ""
+	}
+	return s.listener.Addr().String()
+}
+
+func (t TLS) config() (*tls.Config, error) {
+	certificate, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load TLS key pair: %s", err)
+	}
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if !t.ClientCertRequired {
+		return tlsConfig, nil
+	}
+	clientCAs := x509.NewCertPool()
+	for _, clientRoot := range t.ClientRootCAs {
+		root, err := ioutil.ReadFile(clientRoot)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client root CA file '%s': %s", clientRoot, err)
+		}
+		if !clientCAs.AppendCertsFromPEM(root) {
+			return nil, fmt.Errorf("failed to parse client root CA file '%s'", clientRoot)
+		}
+	}
+	tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	tlsConfig.ClientCAs = clientCAs
+	return tlsConfig, nil
+}
@@ -0,0 +1,139 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package operations
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+)
+
+func getHealthStatus(t *testing.T, client *http.Client, url string) (int, HealthStatus) {
+	resp, err := client.Get(url)
+	if !assert.NoError(t, err) {
+		return 0, HealthStatus{}
+	}
+	defer resp.Body.Close()
+	var status HealthStatus
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&status))
+	return resp.StatusCode, status
+}
+
+func TestSystemHealthChecks(t *testing.T) {
+	system := NewSystem(Options{ListenAddress: "127.0.0.1:0", HealthCheckTimeout: 100 * time.Millisecond})
+	assert.Equal(t, "", system.Addr())
+	assert.NoError(t, system.Start())
+	defer system.Stop()
+	assert.Error(t, system.Start(), "Expected an error starting the system twice")
+
+	liveURL := "http://" + system.Addr() + URLLiveness
+	readyURL := "http://" + system.Addr() + URLReadiness
+
+	// no checkers are registered yet
+	code, status := getHealthStatus(t, http.DefaultClient, liveURL)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, StatusOK, status.Status)
+	assert.Empty(t, status.FailedChecks)
+
+	var livenessErr, readinessErr error
+	assert.NoError(t, system.RegisterLivenessChecker("ledger", CheckerFunc(func(ctx context.Context) error { return livenessErr })))
+	assert.NoError(t, system.RegisterReadinessChecker("couchdb", CheckerFunc(func(ctx context.Context) error { return readinessErr })))
+	assert.Error(t, system.RegisterLivenessChecker("couchdb", CheckerFunc(func(ctx context.Context) error { return nil })))
+	assert.Error(t, system.RegisterReadinessChecker("ledger", CheckerFunc(func(ctx context.Context) error { return nil })))
+
+	code, _ = getHealthStatus(t, http.DefaultClient, readyURL)
+	assert.Equal(t, http.StatusOK, code)
+
+	// a failed readiness check does not affect the liveness
+	readinessErr = errors.New("unreachable")
+	code, _ = getHealthStatus(t, http.DefaultClient, liveURL)
+	assert.Equal(t, http.StatusOK, code)
+	code, status = getHealthStatus(t, http.DefaultClient, readyURL)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, StatusUnavailable, status.Status)
+	assert.Equal(t, []FailedCheck{{Component: "couchdb", Reason: "unreachable"}}, status.FailedChecks)
+
+	// a failed liveness check is reported by both endpoints
+	livenessErr = errors.New("closed")
+	code, status = getHealthStatus(t, http.DefaultClient, liveURL)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, []FailedCheck{{Component: "ledger", Reason: "closed"}}, status.FailedChecks)
+	code, status = getHealthStatus(t, http.DefaultClient, readyURL)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, []FailedCheck{{Component: "couchdb", Reason: "unreachable"}, {Component: "ledger", Reason: "closed"}}, status.FailedChecks)
+
+	resp, err := http.Post(liveURL, "application/json", nil)
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+
+	assert.NoError(t, system.Stop())
+	assert.Equal(t, "", system.Addr())
+	_, err = http.Get(liveURL)
+	assert.Error(t, err, "Expected the system to be stopped")
+}
+
+func TestSystemHealthCheckTimeout(t *testing.T) {
+	system := NewSystem(Options{ListenAddress: "127.0.0.1:0", HealthCheckTimeout: 100 * time.Millisecond})
+	assert.NoError(t, system.Start())
+	defer system.Stop()
+
+	blocked := make(chan struct{})
+	defer close(blocked)
+	assert.NoError(t, system.RegisterLivenessChecker("gossip", CheckerFunc(func(ctx context.Context) error {
+		<-blocked
+		return nil
+	})))
+	code, status := getHealthStatus(t, http.DefaultClient, "http://"+system.Addr()+URLLiveness)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	if assert.Len(t, status.FailedChecks, 1) {
+		assert.Equal(t, "gossip", status.FailedChecks[0].Component)
+		assert.Contains(t, status.FailedChecks[0].Reason, "timed out")
+	}
+}
+
+func TestSystemTLS(t *testing.T) {
+	testdir := filepath.Join("..", "comm", "testdata", "certs")
+	system := NewSystem(Options{
+		ListenAddress: "127.0.0.1:0",
+		TLS: TLS{
+			Enabled:            true,
+			CertFile:           filepath.Join(testdir, "Org1-server1-cert.pem"),
+			KeyFile:            filepath.Join(testdir, "Org1-server1-key.pem"),
+			ClientCertRequired: true,
+			ClientRootCAs:      []string{filepath.Join(testdir, "Org1-cert.pem")},
+		},
+	})
+	assert.NoError(t, system.Start())
+	defer system.Stop()
+
+	url := "https://" + system.Addr() + URLLiveness
+
+	// the server certificate predates subject alternative names, so only the client certificates are verified
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}}
+	_, err := client.Get(url)
+	assert.Error(t, err, "Expected clients without certificates to be rejected")
+
+	clientCert, err := tls.LoadX509KeyPair(filepath.Join(testdir, "Org1-client1-cert.pem"), filepath.Join(testdir, "Org1-client1-key.pem"))
+	assert.NoError(t, err)
+	client = &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+		InsecureSkipVerify: true,
+		Certificates:       []tls.Certificate{clientCert},
+	}}}
+	code, _ := getHealthStatus(t, client, url)
+	assert.Equal(t, http.StatusOK, code)
+
+	system = NewSystem(Options{ListenAddress: "127.0.0.1:0", TLS: TLS{Enabled: true, CertFile: "missing", KeyFile: "missing"}})
+	assert.Error(t, system.Start(), "Expected an error without the TLS key pair")
+}
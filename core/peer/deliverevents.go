@@ -0,0 +1,179 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package peer
+
+import (
+	"fmt"
+
+	commonledger "github.com/hyperledger/fabric/common/ledger"
+	"github.com/hyperledger/fabric/common/policies"
+	"github.com/hyperledger/fabric/orderer/common/deliver"
+	ordererledger "github.com/hyperledger/fabric/orderer/common/ledger"
+	"github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	pb "github.com/hyperledger/fabric/protos/peer"
+)
+
+// DeliverServer implements the Deliver service of the peer, which streams the blocks
+// committed to the ledgers of the chains to the clients authorized by the
+// Application Readers policy of the channel
+type DeliverServer struct {
+	dh deliver.Handler
+}
+
+// NewDeliverServer creates a server delivering the blocks of the chains created on the peer
+func NewDeliverServer() *DeliverServer {
+	return &DeliverServer{
+		dh: deliver.NewHandlerImplWithPolicy(deliverSupportManager{}, policies.ChannelApplicationReaders),
+	}
+}
+
+// Deliver sends a stream of blocks to a client after commitment
+func (s *DeliverServer) Deliver(srv pb.Deliver_DeliverServer) error {
+	peerLogger.Debugf("Starting new Deliver handler")
+	return s.dh.Handle(srv)
+}
+
+// deliverSupportManager looks up the chains created on the peer for the deliver handler
+type deliverSupportManager struct{}
+
+func (deliverSupportManager) GetChain(cid string) (deliver.Support, bool) {
+	chains.RLock()
+	defer chains.RUnlock()
+	c, ok := chains.list[cid]
+	if !ok {
+		return nil, false
+	}
+	return &deliverSupport{cs: c.cs}, true
+}
+
+// deliverSupport provides the deliver handler with the config and the ledger of a chain
+type deliverSupport struct {
+	cs *chainSupport
+}
+
+func (ds *deliverSupport) Sequence() uint64 {
+	return ds.cs.Sequence()
+}
+
+func (ds *deliverSupport) PolicyManager() policies.Manager {
+	return ds.cs.PolicyManager()
+}
+
+func (ds *deliverSupport) Reader() ordererledger.Reader {
+	return &ledgerReader{ledger: ds.cs.Ledger()}
+}
+
+// Errored returns a nil channel, as the blocks are read from the local ledger
+// and there is no consenter whose failure would stop the delivery
+func (ds *deliverSupport) Errored() <-chan struct{} {
+	return nil
+}
+
+// blocksLedger is the subset of the ledger of a chain the blocks are read from
+type blocksLedger interface {
+	GetBlockchainInfo() (*common.BlockchainInfo, error)
+	GetBlocksIterator(startBlockNumber uint64) (commonledger.ResultsIterator, error)
+}
+
+// ledgerReader adapts the ledger of a chain to the Reader the deliver handler expects
+type ledgerReader struct {
+	ledger blocksLedger
+}
+
+// Iterator returns an Iterator, as specified by a cb.SeekInfo message, and its
+// starting block number
+func (lr *ledgerReader) Iterator(startPosition *ab.SeekPosition) (ordererledger.Iterator, uint64) {
+	var startingBlockNumber uint64
+	switch start := startPosition.Type.(type) {
+	case *ab.SeekPosition_Oldest:
+		startingBlockNumber = 0
+	case *ab.SeekPosition_Newest:
+		height := lr.Height()
+		if height == 0 {
+			return &ordererledger.NotFoundErrorIterator{}, 0
+		}
+		startingBlockNumber = height - 1
+	case *ab.SeekPosition_Specified:
+		startingBlockNumber = start.Specified.Number
+		if startingBlockNumber > lr.Height() {
+			return &ordererledger.NotFoundErrorIterator{}, 0
+		}
+	default:
+		return &ordererledger.NotFoundErrorIterator{}, 0
+	}
+
+	iterator, err := lr.ledger.GetBlocksIterator(startingBlockNumber)
+	if err != nil {
+		peerLogger.Errorf("Failed to get a blocks iterator from block %d: %s", startingBlockNumber, err)
+		return &ordererledger.NotFoundErrorIterator{}, 0
+	}
+
+	return &ledgerIterator{iterator: iterator}, startingBlockNumber
+}
+
+// Height returns the number of blocks on the ledger
+func (lr *ledgerReader) Height() uint64 {
+	info, err := lr.ledger.GetBlockchainInfo()
+	if err != nil {
+		peerLogger.Errorf("Failed to read the height of the ledger: %s", err)
+		return 0
+	}
+	return info.Height
+}
+
+// ledgerIterator adapts a blocks iterator of the ledger, whose Next blocks until the block
+// is committed, to the Iterator the deliver handler expects. The next block is read in the
+// background once ReadyChan is called, and the channel closes when it is available.
+type ledgerIterator struct {
+	iterator commonledger.ResultsIterator
+	ready    chan struct{}
+	block    *common.Block
+	err      error
+}
+
+// Next blocks until there is a new block available, or returns an error if the
+// next block is no longer retrievable
+func (li *ledgerIterator) Next() (*common.Block, common.Status) {
+	<-li.ReadyChan()
+	block, err := li.block, li.err
+	li.ready, li.block, li.err = nil, nil, nil
+
+	if err != nil {
+		peerLogger.Errorf("Failed to read the next block from the ledger: %s", err)
+		return nil, common.Status_SERVICE_UNAVAILABLE
+	}
+	return block, common.Status_SUCCESS
+}
+
+// ReadyChan supplies a channel which will block until Next will not block
+func (li *ledgerIterator) ReadyChan() <-chan struct{} {
+	if li.ready == nil {
+		ready := make(chan struct{})
+		li.ready = ready
+		go func() {
+			defer close(ready)
+			result, err := li.iterator.Next()
+			if err != nil {
+				li.err = err
+				return
+			}
+			block, ok := result.(*common.Block)
+			if !ok || block == nil {
+				li.err = fmt.Errorf("blocks iterator closed or returned %T", result)
+				return
+			}
+			li.block = block
+		}()
+	}
+	return li.ready
+}
+
+// Close releases resources acquired by the Iterator
+func (li *ledgerIterator) Close() {
+	li.iterator.Close()
+}
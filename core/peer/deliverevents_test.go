@@ -0,0 +1,126 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package peer
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	commonledger "github.com/hyperledger/fabric/common/ledger"
+	"github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockBlocksIterator struct {
+	blocks chan *common.Block
+	closed chan struct{}
+}
+
+func (m *mockBlocksIterator) Next() (commonledger.QueryResult, error) {
+	select {
+	case block := <-m.blocks:
+		return block, nil
+	case <-m.closed:
+		return nil, nil
+	}
+}
+
+func (m *mockBlocksIterator) Close() {
+	close(m.closed)
+}
+
+type mockBlocksLedger struct {
+	height   uint64
+	err      error
+	iterator *mockBlocksIterator
+	start    uint64
+}
+
+func (m *mockBlocksLedger) GetBlockchainInfo() (*common.BlockchainInfo, error) {
+	return &common.BlockchainInfo{Height: m.height}, m.err
+}
+
+func (m *mockBlocksLedger) GetBlocksIterator(startBlockNumber uint64) (commonledger.ResultsIterator, error) {
+	m.start = startBlockNumber
+	return m.iterator, m.err
+}
+
+func newMockBlocksLedger(height uint64) *mockBlocksLedger {
+	return &mockBlocksLedger{
+		height:   height,
+		iterator: &mockBlocksIterator{blocks: make(chan *common.Block, 1), closed: make(chan struct{})},
+	}
+}
+
+func TestLedgerReaderIterator(t *testing.T) {
+	l := newMockBlocksLedger(5)
+	reader := &ledgerReader{ledger: l}
+	assert.Equal(t, uint64(5), reader.Height())
+
+	_, number := reader.Iterator(&ab.SeekPosition{Type: &ab.SeekPosition_Oldest{Oldest: &ab.SeekOldest{}}})
+	assert.Equal(t, uint64(0), number)
+	assert.Equal(t, uint64(0), l.start)
+
+	_, number = reader.Iterator(&ab.SeekPosition{Type: &ab.SeekPosition_Newest{Newest: &ab.SeekNewest{}}})
+	assert.Equal(t, uint64(4), number)
+	assert.Equal(t, uint64(4), l.start)
+
+	_, number = reader.Iterator(&ab.SeekPosition{Type: &ab.SeekPosition_Specified{Specified: &ab.SeekSpecified{Number: 5}}})
+	assert.Equal(t, uint64(5), number)
+	assert.Equal(t, uint64(5), l.start)
+
+	iterator, _ := reader.Iterator(&ab.SeekPosition{Type: &ab.SeekPosition_Specified{Specified: &ab.SeekSpecified{Number: 6}}})
+	_, status := iterator.Next()
+	assert.Equal(t, common.Status_NOT_FOUND, status)
+
+	l.err = fmt.Errorf("ledger error")
+	assert.Equal(t, uint64(0), reader.Height())
+	iterator, _ = reader.Iterator(&ab.SeekPosition{Type: &ab.SeekPosition_Oldest{Oldest: &ab.SeekOldest{}}})
+	_, status = iterator.Next()
+	assert.Equal(t, common.Status_NOT_FOUND, status)
+}
+
+func TestLedgerIterator(t *testing.T) {
+	l := newMockBlocksLedger(1)
+	iterator, _ := (&ledgerReader{ledger: l}).Iterator(&ab.SeekPosition{Type: &ab.SeekPosition_Specified{Specified: &ab.SeekSpecified{Number: 1}}})
+
+	// the iterator is not ready until the block is committed
+	select {
+	case <-iterator.ReadyChan():
+		t.Fatalf("Iterator should not be ready")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	block := &common.Block{Header: &common.BlockHeader{Number: 1}}
+	l.iterator.blocks <- block
+	select {
+	case <-iterator.ReadyChan():
+	case <-time.After(time.Second):
+		t.Fatalf("Timed out waiting for the iterator to be ready")
+	}
+	next, status := iterator.Next()
+	assert.Equal(t, common.Status_SUCCESS, status)
+	assert.Equal(t, block, next)
+
+	// closing the iterator unblocks a pending read
+	ready := iterator.ReadyChan()
+	iterator.Close()
+	select {
+	case <-ready:
+	case <-time.After(time.Second):
+		t.Fatalf("Timed out waiting for the iterator to be closed")
+	}
+	_, status = iterator.Next()
+	assert.Equal(t, common.Status_SERVICE_UNAVAILABLE, status)
+}
+
+func TestDeliverSupportManager(t *testing.T) {
+	_, ok := deliverSupportManager{}.GetChain("nonexistent")
+	assert.False(t, ok)
+}
@@ -0,0 +1,104 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package peer
+
+import (
+	"fmt"
+	"sort"
+
+	gcommon "github.com/hyperledger/fabric/gossip/common"
+	"github.com/hyperledger/fabric/gossip/discovery"
+	"github.com/hyperledger/fabric/gossip/service"
+	"github.com/hyperledger/fabric/gossip/state"
+	pb "github.com/hyperledger/fabric/protos/peer"
+)
+
+// gossipMembership is the view of the gossip membership of the peer
+type gossipMembership interface {
+	// Peers returns the peers considered alive
+	Peers() []discovery.NetworkMember
+	// PeersOfChannel returns the peers considered alive and subscribed to the channel
+	PeersOfChannel(gcommon.ChainID) []discovery.NetworkMember
+}
+
+// IntrospectionSupport collects the state of the peer exposed to its operators
+type IntrospectionSupport struct {
+	gossip     func() gossipMembership
+	containers func() []*pb.ChaincodeContainerStatus
+}
+
+// NewIntrospectionSupport returns the IntrospectionSupport of the peer, containers returns
+// the status of its chaincode containers
+func NewIntrospectionSupport(containers func() []*pb.ChaincodeContainerStatus) *IntrospectionSupport {
+	return &IntrospectionSupport{
+		// the gossip service is initialized after the peer services are registered
+		gossip:     func() gossipMembership { return service.GetGossipService() },
+		containers: containers,
+	}
+}
+
+// channelIDs returns the IDs of the channels joined by the peer, sorted
+func channelIDs() []string {
+	chains.RLock()
+	defer chains.RUnlock()
+	cids := make([]string, 0, len(chains.list))
+	for cid := range chains.list {
+		cids = append(cids, cid)
+	}
+	sort.Strings(cids)
+	return cids
+}
+
+// GetChannels returns the channels joined by the peer and the heights of their ledgers
+func (s *IntrospectionSupport) GetChannels() ([]*pb.ChannelLedgerInfo, error) {
+	var channels []*pb.ChannelLedgerInfo
+	for _, cid := range channelIDs() {
+		l := GetLedger(cid)
+		if l == nil {
+			// the channel was left in the meantime
+			continue
+		}
+		info, err := l.GetBlockchainInfo()
+		if err != nil {
+			return nil, fmt.Errorf("failed getting the height of the ledger of channel [%s]: %s", cid, err)
+		}
+		channels = append(channels, &pb.ChannelLedgerInfo{ChannelId: cid, Height: info.Height, CurrentBlockHash: info.CurrentBlockHash})
+	}
+	return channels, nil
+}
+
+// GetGossipMembership returns the peers known alive by the gossip of the peer, globally
+// and for every channel joined by the peer
+func (s *IntrospectionSupport) GetGossipMembership() (*pb.GossipMembership, error) {
+	g := s.gossip()
+	if g == nil {
+		return nil, fmt.Errorf("the gossip service is not initialized")
+	}
+
+	membership := &pb.GossipMembership{}
+	for _, member := range g.Peers() {
+		membership.Peers = append(membership.Peers, &pb.GossipMember{Endpoint: member.Endpoint, PkiId: member.PKIid})
+	}
+	for _, cid := range channelIDs() {
+		channel := &pb.GossipChannelMembership{ChannelId: cid}
+		for _, member := range g.PeersOfChannel(gcommon.ChainID(cid)) {
+			gm := &pb.GossipMember{Endpoint: member.Endpoint, PkiId: member.PKIid}
+			// the members of a channel advertise the height of their ledger in their metadata
+			if metastate, err := state.FromBytes(member.Metadata); err == nil {
+				gm.LedgerHeight = metastate.LedgerHeight
+			}
+			channel.Peers = append(channel.Peers, gm)
+		}
+		membership.Channels = append(membership.Channels, channel)
+	}
+	return membership, nil
+}
+
+// GetChaincodeContainers returns the status of the chaincode containers of the peer
+func (s *IntrospectionSupport) GetChaincodeContainers() []*pb.ChaincodeContainerStatus {
+	return s.containers()
+}
@@ -0,0 +1,85 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package peer
+
+import (
+	"os"
+	"testing"
+
+	gcommon "github.com/hyperledger/fabric/gossip/common"
+	"github.com/hyperledger/fabric/gossip/discovery"
+	"github.com/hyperledger/fabric/gossip/state"
+	"github.com/hyperledger/fabric/msp/mgmt/testtools"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockGossipMembership struct {
+	peers          []discovery.NetworkMember
+	peersOfChannel map[string][]discovery.NetworkMember
+}
+
+func (m *mockGossipMembership) Peers() []discovery.NetworkMember {
+	return m.peers
+}
+
+func (m *mockGossipMembership) PeersOfChannel(chainID gcommon.ChainID) []discovery.NetworkMember {
+	return m.peersOfChannel[string(chainID)]
+}
+
+func TestIntrospectionSupport(t *testing.T) {
+	viper.Set("peer.fileSystemPath", "/tmp/hyperledger/test/introspection")
+	defer os.RemoveAll("/tmp/hyperledger/test/introspection")
+	MockInitialize()
+	defer MockInitialize()
+	assert.NoError(t, msptesttools.LoadMSPSetupForTesting())
+
+	metadata, err := state.NewNodeMetastate(3).Bytes()
+	assert.NoError(t, err)
+	g := &mockGossipMembership{
+		peers: []discovery.NetworkMember{{Endpoint: "peer1:7051", PKIid: gcommon.PKIidType("peer1")}},
+		peersOfChannel: map[string][]discovery.NetworkMember{
+			"channel1": {{Endpoint: "peer1:7051", PKIid: gcommon.PKIidType("peer1"), Metadata: metadata}},
+		},
+	}
+	containers := []*pb.ChaincodeContainerStatus{{CanonicalName: "mycc:0", State: pb.ChaincodeContainerStatus_RUNNING, Container: true}}
+	support := NewIntrospectionSupport(func() []*pb.ChaincodeContainerStatus { return containers })
+	support.gossip = func() gossipMembership { return g }
+
+	channels, err := support.GetChannels()
+	assert.NoError(t, err)
+	assert.Empty(t, channels)
+
+	assert.NoError(t, MockCreateChain("channel2"))
+	assert.NoError(t, MockCreateChain("channel1"))
+	channels, err = support.GetChannels()
+	assert.NoError(t, err)
+	assert.Len(t, channels, 2)
+	for i, cid := range []string{"channel1", "channel2"} {
+		assert.Equal(t, cid, channels[i].ChannelId)
+		assert.Equal(t, uint64(1), channels[i].Height)
+		assert.NotEmpty(t, channels[i].CurrentBlockHash)
+	}
+
+	membership, err := support.GetGossipMembership()
+	assert.NoError(t, err)
+	assert.Equal(t, &pb.GossipMembership{
+		Peers: []*pb.GossipMember{{Endpoint: "peer1:7051", PkiId: []byte("peer1")}},
+		Channels: []*pb.GossipChannelMembership{
+			{ChannelId: "channel1", Peers: []*pb.GossipMember{{Endpoint: "peer1:7051", PkiId: []byte("peer1"), LedgerHeight: 3}}},
+			{ChannelId: "channel2"},
+		},
+	}, membership)
+
+	assert.Equal(t, containers, support.GetChaincodeContainers())
+
+	// the gossip service is not initialized yet
+	support.gossip = func() gossipMembership { return nil }
+	_, err = support.GetGossipMembership()
+	assert.EqualError(t, err, "the gossip service is not initialized")
+}
@@ -9,13 +9,16 @@ package peer
 import (
 	"errors"
 	"fmt"
+	"io"
 	"net"
 	"sync"
 
+	"github.com/hyperledger/fabric/common/capabilities"
 	channelconfig "github.com/hyperledger/fabric/common/config/channel"
 	configtxapi "github.com/hyperledger/fabric/common/configtx/api"
 	configtxtest "github.com/hyperledger/fabric/common/configtx/test"
 	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/common/ledger/blockstream"
 	mockchannelconfig "github.com/hyperledger/fabric/common/mocks/config"
 	mockconfigtx "github.com/hyperledger/fabric/common/mocks/configtx"
 	mockpolicies "github.com/hyperledger/fabric/common/mocks/policies"
@@ -23,8 +26,10 @@ import (
 	"github.com/hyperledger/fabric/core/comm"
 	"github.com/hyperledger/fabric/core/committer"
 	"github.com/hyperledger/fabric/core/committer/txvalidator"
+	"github.com/hyperledger/fabric/core/common/privdata"
 	"github.com/hyperledger/fabric/core/ledger"
 	"github.com/hyperledger/fabric/core/ledger/ledgermgmt"
+	"github.com/hyperledger/fabric/events/ccevents"
 	"github.com/hyperledger/fabric/gossip/api"
 	"github.com/hyperledger/fabric/gossip/service"
 	"github.com/hyperledger/fabric/msp"
@@ -58,6 +63,16 @@ func (cs *chainSupport) GetMSPIDs(cid string) []string {
 	return GetMSPIDs(cid)
 }
 
+// Capabilities returns the application capabilities of the current channel config,
+// which requires no capability if the channel has no application config
+func (cs *chainSupport) Capabilities() channelconfig.ApplicationCapabilities {
+	ac, ok := cs.ApplicationConfig()
+	if !ok {
+		return capabilities.NewApplicationProvider(nil)
+	}
+	return ac.Capabilities()
+}
+
 // chain is a local struct to manage objects in a chain
 type chain struct {
 	cs        *chainSupport
@@ -113,7 +128,7 @@ func Initialize(init func(string)) {
 			continue
 		}
 		// Create a chain if we get a valid ledger with config block
-		if err = createChain(cid, ledger, cb); err != nil {
+		if err = createChain(cid, ledger, cb, nil); err != nil {
 			peerLogger.Warningf("Failed to load chain %s(%s)", cid, err)
 			peerLogger.Debugf("Error reloading chain %s with message %s. We continue to the next chain rather than abort.", cid, err)
 			continue
@@ -162,7 +177,9 @@ func getCurrConfigBlockFromLedger(ledger ledger.PeerLedger) (*common.Block, erro
 }
 
 // createChain creates a new chain object and insert it into the chains
-func createChain(cid string, ledger ledger.PeerLedger, cb *common.Block) error {
+// createChain creates the chain of the given ledger. If bootstrap is not nil, it is invoked with
+// the committer of the chain before the chain starts replicating blocks through gossip
+func createChain(cid string, ledger ledger.PeerLedger, cb *common.Block, bootstrap func(committer.Committer) error) error {
 
 	envelopeConfig, err := utils.ExtractEnvelope(cb, 0)
 	if err != nil {
@@ -229,15 +246,25 @@ func createChain(cid string, ledger ledger.PeerLedger, cb *common.Block) error {
 	if len(ordererAddresses) == 0 {
 		return errors.New("No ordering service endpoint provided in configuration block")
 	}
-	service.GetGossipService().InitializeChannel(cs.ChainID(), c, ordererAddresses)
 
 	chains.Lock()
-	defer chains.Unlock()
 	chains.list[cid] = &chain{
 		cs:        cs,
 		cb:        cb,
 		committer: c,
 	}
+	chains.Unlock()
+
+	if bootstrap != nil {
+		if err = bootstrap(c); err != nil {
+			chains.Lock()
+			delete(chains.list, cid)
+			chains.Unlock()
+			return err
+		}
+	}
+
+	service.GetGossipService().InitializeChannel(cs.ChainID(), c, privdata.NewSimpleCollectionStore(collectionSupport{}), ordererAddresses)
 	return nil
 }
 
@@ -253,7 +280,108 @@ func CreateChainFromBlock(cb *common.Block) error {
 		return fmt.Errorf("Cannot create ledger from genesis block, due to %s", err)
 	}
 
-	return createChain(cid, l, cb)
+	return createChain(cid, l, cb, nil)
+}
+
+// CreateChainFromBlockStream creates a new chain from its genesis block, and commits the
+// blocks read from the given block stream before the chain starts replicating blocks from
+// the other peers and the ordering service. The blocks of the stream already in the ledger,
+// such as the genesis block itself, are skipped
+func CreateChainFromBlockStream(cb *common.Block, blocks *blockstream.Reader) error {
+	cid, err := utils.GetChainIDFromBlock(cb)
+	if err != nil {
+		return err
+	}
+
+	var l ledger.PeerLedger
+	if l, err = ledgermgmt.CreateLedger(cb); err != nil {
+		return fmt.Errorf("Cannot create ledger from genesis block, due to %s", err)
+	}
+
+	return createChain(cid, l, cb, func(c committer.Committer) error {
+		for {
+			block, err := blocks.Read()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("Cannot read the blocks of channel %s, due to %s", cid, err)
+			}
+			if block.Header == nil {
+				return fmt.Errorf("Cannot commit a block without header on channel %s", cid)
+			}
+			height, err := c.LedgerHeight()
+			if err != nil {
+				return err
+			}
+			if block.Header.Number < height {
+				continue
+			}
+			if block.Header.Number > height {
+				return fmt.Errorf("Block %d of channel %s is missing from the block stream", height, cid)
+			}
+			if err = c.Commit(block); err != nil {
+				return fmt.Errorf("Cannot commit block %d of channel %s, due to %s", block.Header.Number, cid, err)
+			}
+		}
+	})
+}
+
+// CreateChainsFromBlocks creates the chains of the given config blocks, either all of them or none.
+// The channels are checked to be distinct and not joined yet before any ledger is created. If the
+// ledger or the chain of any channel cannot be created, the chains already created are closed and
+// the ledgers created for the blocks are removed
+func CreateChainsFromBlocks(cbs []*common.Block) error {
+	cids := make([]string, 0, len(cbs))
+	for _, cb := range cbs {
+		cid, err := utils.GetChainIDFromBlock(cb)
+		if err != nil {
+			return err
+		}
+		for _, joined := range cids {
+			if joined == cid {
+				return fmt.Errorf("Channel %s is joined more than once", cid)
+			}
+		}
+		if GetLedger(cid) != nil {
+			return fmt.Errorf("Channel %s is already joined", cid)
+		}
+		cids = append(cids, cid)
+	}
+
+	var created, started []string
+	rollback := func() {
+		for _, cid := range started {
+			chains.Lock()
+			delete(chains.list, cid)
+			chains.Unlock()
+			service.GetGossipService().CloseChannel(cid)
+		}
+		for _, cid := range created {
+			if err := ledgermgmt.RemoveLedger(cid); err != nil {
+				peerLogger.Errorf("Cannot remove the ledger of channel %s, due to %s", cid, err)
+			}
+		}
+	}
+
+	ledgers := make([]ledger.PeerLedger, len(cbs))
+	for i, cb := range cbs {
+		l, err := ledgermgmt.CreateLedger(cb)
+		if err != nil {
+			rollback()
+			return fmt.Errorf("Cannot create ledger of channel %s from genesis block, due to %s", cids[i], err)
+		}
+		ledgers[i] = l
+		created = append(created, cids[i])
+	}
+	for i, cb := range cbs {
+		if err := createChain(cids[i], ledgers[i], cb, nil); err != nil {
+			rollback()
+			return fmt.Errorf("Cannot create chain of channel %s, due to %s", cids[i], err)
+		}
+		started = append(started, cids[i])
+	}
+	return nil
 }
 
 // MockCreateChain used for creating a ledger for a chain for tests
@@ -311,6 +439,50 @@ func GetLedger(cid string) ledger.PeerLedger {
 	return nil
 }
 
+// txStatusRegistrar is implemented by the committers that notify the status of the committed transactions
+type txStatusRegistrar interface {
+	RegisterTxStatusListener(txID string) <-chan *committer.TxStatus
+	UnregisterTxStatusListener(txID string, listener <-chan *committer.TxStatus)
+	CommittedTxStatus(txID string) (*committer.TxStatus, error)
+}
+
+// RegisterTxStatusListener returns a channel that receives the status of the given transaction once it is
+// committed to the chain with chain ID. Note that this call returns nil if chain cid has not been created.
+func RegisterTxStatusListener(cid, txID string) <-chan *committer.TxStatus {
+	chains.RLock()
+	defer chains.RUnlock()
+	if c, ok := chains.list[cid]; ok {
+		if r, ok := c.committer.(txStatusRegistrar); ok {
+			return r.RegisterTxStatusListener(txID)
+		}
+	}
+	return nil
+}
+
+// UnregisterTxStatusListener removes a listener registered with RegisterTxStatusListener
+func UnregisterTxStatusListener(cid, txID string, listener <-chan *committer.TxStatus) {
+	chains.RLock()
+	defer chains.RUnlock()
+	if c, ok := chains.list[cid]; ok {
+		if r, ok := c.committer.(txStatusRegistrar); ok {
+			r.UnregisterTxStatusListener(txID, listener)
+		}
+	}
+}
+
+// CommittedTxStatus returns the status the given transaction was committed to the chain with chain ID
+// with, or nil if it is not committed. Note that this call returns nil if chain cid has not been created.
+func CommittedTxStatus(cid, txID string) (*committer.TxStatus, error) {
+	chains.RLock()
+	defer chains.RUnlock()
+	if c, ok := chains.list[cid]; ok {
+		if r, ok := c.committer.(txStatusRegistrar); ok {
+			return r.CommittedTxStatus(txID)
+		}
+	}
+	return nil, nil
+}
+
 // GetPolicyManager returns the policy manager of the chain with chain ID. Note that this
 // call returns nil if chain cid has not been created.
 func GetPolicyManager(cid string) policies.Manager {
@@ -322,6 +494,76 @@ func GetPolicyManager(cid string) policies.Manager {
 	return nil
 }
 
+// GetChannelCapabilities returns the channel capabilities of the chain with chain ID, and
+// whether the chain has been created and carries a channel config
+func GetChannelCapabilities(cid string) (channelconfig.ChannelCapabilities, bool) {
+	chains.RLock()
+	defer chains.RUnlock()
+	if c, ok := chains.list[cid]; ok {
+		if cc := c.cs.ChannelConfig(); cc != nil {
+			return cc.Capabilities(), true
+		}
+	}
+	return nil, false
+}
+
+// GetApplicationCapabilities returns the application capabilities of the chain with chain ID, and
+// whether the chain has been created and carries an application config
+func GetApplicationCapabilities(cid string) (channelconfig.ApplicationCapabilities, bool) {
+	chains.RLock()
+	defer chains.RUnlock()
+	if c, ok := chains.list[cid]; ok {
+		if ac, ok := c.cs.ApplicationConfig(); ok {
+			return ac.Capabilities(), true
+		}
+	}
+	return nil, false
+}
+
+// chaincodeEventsSupport provides the chaincode events server with the resources of the chains
+type chaincodeEventsSupport struct{}
+
+// NewChaincodeEventsSupport returns the support of the chaincode events server, which delivers
+// the events of the chains created on the peer
+func NewChaincodeEventsSupport() ccevents.Support {
+	return chaincodeEventsSupport{}
+}
+
+func (chaincodeEventsSupport) Ledger(cid string) ccevents.Ledger {
+	if l := GetLedger(cid); l != nil {
+		return l
+	}
+	return nil
+}
+
+func (chaincodeEventsSupport) PolicyManager(cid string) policies.Manager {
+	return GetPolicyManager(cid)
+}
+
+// collectionSupport provides the collection store with the ledgers and the
+// identity deserializers of the chains
+type collectionSupport struct{}
+
+func (collectionSupport) GetQueryExecutorForLedger(cid string) (ledger.QueryExecutor, error) {
+	l := GetLedger(cid)
+	if l == nil {
+		return nil, fmt.Errorf("channel %s does not exist", cid)
+	}
+	return l.NewQueryExecutor()
+}
+
+func (collectionSupport) GetConfigHistoryRetriever(cid string) (ledger.ConfigHistoryRetriever, error) {
+	l := GetLedger(cid)
+	if l == nil {
+		return nil, fmt.Errorf("channel %s does not exist", cid)
+	}
+	return l.GetConfigHistoryRetriever()
+}
+
+func (collectionSupport) GetIdentityDeserializer(chainID string) msp.IdentityDeserializer {
+	return mspmgmt.GetIdentityDeserializer(chainID)
+}
+
 // GetCurrConfigBlock returns the cached config block of the specified chain.
 // Note that this call returns nil if chain cid has not been created.
 func GetCurrConfigBlock(cid string) *common.Block {
@@ -381,6 +623,7 @@ func buildTrustedRootsForChain(cm channelconfig.Resources) {
 
 	appRootCAs := [][]byte{}
 	ordererRootCAs := [][]byte{}
+	appRootCAsByOrg := make(map[string][][]byte)
 	appOrgMSPs := make(map[string]struct{})
 	ac, ok := cm.ApplicationConfig()
 	if ok {
@@ -405,6 +648,7 @@ func buildTrustedRootsForChain(cm channelconfig.Resources) {
 					if _, ok := appOrgMSPs[k]; ok {
 						peerLogger.Debugf("adding app root CAs for MSP [%s]", k)
 						appRootCAs = append(appRootCAs, root)
+						appRootCAsByOrg[k] = append(appRootCAsByOrg[k], root)
 					} else {
 						peerLogger.Debugf("adding orderer root CAs for MSP [%s]", k)
 						ordererRootCAs = append(ordererRootCAs, root)
@@ -415,6 +659,7 @@ func buildTrustedRootsForChain(cm channelconfig.Resources) {
 					if _, ok := appOrgMSPs[k]; ok {
 						peerLogger.Debugf("adding app root CAs for MSP [%s]", k)
 						appRootCAs = append(appRootCAs, intermediate)
+						appRootCAsByOrg[k] = append(appRootCAsByOrg[k], intermediate)
 					} else {
 						peerLogger.Debugf("adding orderer root CAs for MSP [%s]", k)
 						ordererRootCAs = append(ordererRootCAs, intermediate)
@@ -424,6 +669,7 @@ func buildTrustedRootsForChain(cm channelconfig.Resources) {
 		}
 		rootCASupport.AppRootCAsByChain[cid] = appRootCAs
 		rootCASupport.OrdererRootCAsByChain[cid] = ordererRootCAs
+		rootCASupport.AppRootCAsByOrgByChain[cid] = appRootCAsByOrg
 	}
 }
 
@@ -530,13 +776,29 @@ func (c *channelPolicyManagerGetter) Manager(channelID string) (policies.Manager
 	return policyManager, policyManager != nil
 }
 
+func (c *channelPolicyManagerGetter) ChannelCapabilities(channelID string) (channelconfig.ChannelCapabilities, bool) {
+	return GetChannelCapabilities(channelID)
+}
+
 // CreatePeerServer creates an instance of comm.GRPCServer
-// This server is used for peer communications
+// This server is used for peer communications. If peer.reusePortListeners
+// is greater than 1, the server accepts connections on that many listeners
+// sharing the listen address
 func CreatePeerServer(listenAddress string,
 	secureConfig comm.SecureServerConfig) (comm.GRPCServer, error) {
 
 	var err error
-	peerServer, err = comm.NewGRPCServer(listenAddress, secureConfig)
+	if listenerCount := viper.GetInt("peer.reusePortListeners"); listenerCount > 1 {
+		var listeners []net.Listener
+		listeners, err = comm.ListenReusePort(listenAddress, listenerCount)
+		if err != nil {
+			peerLogger.Errorf("Failed to create %d listeners on %s (%s)", listenerCount, listenAddress, err)
+			return nil, err
+		}
+		peerServer, err = comm.NewGRPCServerFromListeners(listeners, secureConfig)
+	} else {
+		peerServer, err = comm.NewGRPCServer(listenAddress, secureConfig)
+	}
 	if err != nil {
 		peerLogger.Errorf("Failed to create peer server (%s)", err)
 		return nil, err
@@ -17,6 +17,7 @@ limitations under the License.
 package peer
 
 import (
+	"bytes"
 	"fmt"
 	"net"
 	"os"
@@ -24,6 +25,7 @@ import (
 	"testing"
 
 	configtxtest "github.com/hyperledger/fabric/common/configtx/test"
+	"github.com/hyperledger/fabric/common/ledger/blockstream"
 	"github.com/hyperledger/fabric/common/localmsp"
 	mscc "github.com/hyperledger/fabric/common/mocks/scc"
 	"github.com/hyperledger/fabric/core/comm"
@@ -31,6 +33,7 @@ import (
 	"github.com/hyperledger/fabric/core/common/sysccprovider"
 	"github.com/hyperledger/fabric/core/deliverservice"
 	"github.com/hyperledger/fabric/core/deliverservice/blocksprovider"
+	"github.com/hyperledger/fabric/core/ledger/ledgermgmt"
 	"github.com/hyperledger/fabric/core/mocks/ccprovider"
 	"github.com/hyperledger/fabric/gossip/api"
 	"github.com/hyperledger/fabric/gossip/service"
@@ -38,8 +41,11 @@ import (
 	"github.com/hyperledger/fabric/msp/mgmt/testtools"
 	peergossip "github.com/hyperledger/fabric/peer/gossip"
 	"github.com/hyperledger/fabric/peer/gossip/mocks"
+	"github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/utils"
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
 	"google.golang.org/grpc"
 )
 
@@ -63,6 +69,10 @@ func (*mockDeliveryClient) Stop() {
 
 }
 
+func (*mockDeliveryClient) HealthCheck(ctx context.Context) error {
+	return nil
+}
+
 type mockDeliveryClientFactory struct {
 }
 
@@ -228,6 +238,65 @@ func TestCreateChainFromBlock(t *testing.T) {
 	if len(channels) != 1 {
 		t.Fatalf("incorrect number of channels")
 	}
+
+	// A chain created from a block stream, whose genesis block is skipped
+	streamChainID := "mystreamchainid"
+	streamBlock, err := configtxtest.MakeGenesisBlock(streamChainID)
+	assert.NoError(t, err)
+	buf := &bytes.Buffer{}
+	assert.NoError(t, blockstream.NewWriter(buf).Write(streamBlock))
+	assert.NoError(t, CreateChainFromBlockStream(streamBlock, blockstream.NewReader(buf)))
+	assert.NotNil(t, GetLedger(streamChainID))
+	height, err := GetLedger(streamChainID).GetBlockchainInfo()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), height.Height)
+
+	// A block stream with a gap
+	gapChainID := "mygapchainid"
+	gapBlock, err := configtxtest.MakeGenesisBlock(gapChainID)
+	assert.NoError(t, err)
+	buf = &bytes.Buffer{}
+	assert.NoError(t, blockstream.NewWriter(buf).Write(&common.Block{Header: &common.BlockHeader{Number: 2}}))
+	err = CreateChainFromBlockStream(gapBlock, blockstream.NewReader(buf))
+	assert.EqualError(t, err, "Block 1 of channel mygapchainid is missing from the block stream")
+	assert.Nil(t, GetLedger(gapChainID))
+
+	// Chains created from a bundle of blocks
+	bundleChainIDs := []string{"mybundlechainid1", "mybundlechainid2"}
+	var bundle []*common.Block
+	for _, cid := range bundleChainIDs {
+		block, err := configtxtest.MakeGenesisBlock(cid)
+		assert.NoError(t, err)
+		bundle = append(bundle, block)
+	}
+	assert.NoError(t, CreateChainsFromBlocks(bundle))
+	for _, cid := range bundleChainIDs {
+		assert.NotNil(t, GetLedger(cid))
+	}
+	assert.EqualError(t, CreateChainsFromBlocks(bundle[:1]), "Channel mybundlechainid1 is already joined")
+
+	// A bundle with a block whose chain cannot be created is rolled back
+	rolledBackBlock, err := configtxtest.MakeGenesisBlock("myrolledbackchainid")
+	assert.NoError(t, err)
+	env, err := utils.CreateSignedEnvelope(common.HeaderType_ENDORSER_TRANSACTION, "mybadchainid", nil, &common.ConfigEnvelope{}, 0, 0)
+	assert.NoError(t, err)
+	badBlock := common.NewBlock(0, nil)
+	badBlock.Data.Data = [][]byte{utils.MarshalOrPanic(env)}
+	badBlock.Header.DataHash = badBlock.Data.Hash()
+	err = CreateChainsFromBlocks([]*common.Block{rolledBackBlock, rolledBackBlock})
+	assert.EqualError(t, err, "Channel myrolledbackchainid is joined more than once")
+	err = CreateChainsFromBlocks([]*common.Block{rolledBackBlock, badBlock})
+	assert.Contains(t, fmt.Sprint(err), "Cannot create chain of channel mybadchainid")
+	assert.Nil(t, GetLedger("myrolledbackchainid"))
+	assert.Nil(t, GetLedger("mybadchainid"))
+	ledgerIDs, err := ledgermgmt.GetLedgerIDs()
+	assert.NoError(t, err)
+	assert.NotContains(t, ledgerIDs, "myrolledbackchainid")
+	assert.NotContains(t, ledgerIDs, "mybadchainid")
+
+	// The chains of a rolled back bundle can be joined again
+	assert.NoError(t, CreateChainsFromBlocks([]*common.Block{rolledBackBlock}))
+	assert.NotNil(t, GetLedger("myrolledbackchainid"))
 }
 
 func TestNewPeerClientConnection(t *testing.T) {
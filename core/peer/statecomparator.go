@@ -0,0 +1,112 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package peer
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/hyperledger/fabric/core/ledger/kvledger/statecommitment"
+	gcommon "github.com/hyperledger/fabric/gossip/common"
+	"github.com/hyperledger/fabric/gossip/service"
+	"github.com/hyperledger/fabric/gossip/state"
+	pb "github.com/hyperledger/fabric/protos/peer"
+)
+
+// StateComparatorSupport provides the state commitments of the channels of the peer, and
+// reaches the other peers of the channels to compare them
+type StateComparatorSupport struct {
+	gossip  func() gossipMembership
+	connect func(endpoint string) (pb.StateComparatorClient, io.Closer, error)
+}
+
+// NewStateComparatorSupport returns the StateComparatorSupport of the peer
+func NewStateComparatorSupport() *StateComparatorSupport {
+	return &StateComparatorSupport{
+		// the gossip service is initialized after the peer services are registered
+		gossip: func() gossipMembership { return service.GetGossipService() },
+		connect: func(endpoint string) (pb.StateComparatorClient, io.Closer, error) {
+			conn, err := NewPeerClientConnectionWithAddress(endpoint)
+			if err != nil {
+				return nil, nil, err
+			}
+			return pb.NewStateComparatorClient(conn), conn, nil
+		},
+	}
+}
+
+// LedgerHeight returns the height of the ledger of a channel joined by the peer
+func (s *StateComparatorSupport) LedgerHeight(cid string) (uint64, error) {
+	l := GetLedger(cid)
+	if l == nil {
+		return 0, fmt.Errorf("channel [%s] not found", cid)
+	}
+	info, err := l.GetBlockchainInfo()
+	if err != nil {
+		return 0, fmt.Errorf("failed getting the height of the ledger of channel [%s]: %s", cid, err)
+	}
+	return info.Height, nil
+}
+
+// PeerLedgerHeight returns the height of the ledger of a channel advertised in the gossip
+// metadata of the peer at endpoint. It fails if that peer is not known alive in the channel
+func (s *StateComparatorSupport) PeerLedgerHeight(cid string, endpoint string) (uint64, error) {
+	g := s.gossip()
+	if g == nil {
+		return 0, fmt.Errorf("the gossip service is not initialized")
+	}
+	for _, member := range g.PeersOfChannel(gcommon.ChainID(cid)) {
+		if member.Endpoint != endpoint {
+			continue
+		}
+		metastate, err := state.FromBytes(member.Metadata)
+		if err != nil {
+			return 0, fmt.Errorf("peer [%s] advertises invalid metadata in channel [%s]: %s", endpoint, cid, err)
+		}
+		return metastate.LedgerHeight, nil
+	}
+	return 0, fmt.Errorf("peer [%s] is not known alive in channel [%s]", endpoint, cid)
+}
+
+// GetStateCommitments returns the state commitments of the blocks start to end of a channel,
+// both included. The commitments stop at the last block committed by the peer
+func (s *StateComparatorSupport) GetStateCommitments(cid string, start, end uint64) ([]*pb.BlockStateCommitment, error) {
+	l := GetLedger(cid)
+	if l == nil {
+		return nil, fmt.Errorf("channel [%s] not found", cid)
+	}
+	info, err := l.GetBlockchainInfo()
+	if err != nil {
+		return nil, fmt.Errorf("failed getting the height of the ledger of channel [%s]: %s", cid, err)
+	}
+	if info.Height == 0 || start >= info.Height {
+		return nil, nil
+	}
+	if end >= info.Height {
+		end = info.Height - 1
+	}
+
+	var commitments []*pb.BlockStateCommitment
+	for blockNum := start; blockNum <= end; blockNum++ {
+		block, err := l.GetBlockByNumber(blockNum)
+		if err != nil {
+			return nil, fmt.Errorf("failed retrieving block [%d] of channel [%s]: %s", blockNum, cid, err)
+		}
+		commitment, err := statecommitment.Compute(block)
+		if err != nil {
+			return nil, fmt.Errorf("failed computing the state commitment of block [%d] of channel [%s]: %s", blockNum, cid, err)
+		}
+		commitments = append(commitments, &pb.BlockStateCommitment{BlockNum: blockNum, Commitment: commitment})
+	}
+	return commitments, nil
+}
+
+// Connect returns a client of the StateComparator service of the peer at endpoint, and the
+// closer of its connection
+func (s *StateComparatorSupport) Connect(endpoint string) (pb.StateComparatorClient, io.Closer, error) {
+	return s.connect(endpoint)
+}
@@ -0,0 +1,80 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package peer
+
+import (
+	"os"
+	"testing"
+
+	"github.com/hyperledger/fabric/core/ledger/kvledger/statecommitment"
+	gcommon "github.com/hyperledger/fabric/gossip/common"
+	"github.com/hyperledger/fabric/gossip/discovery"
+	"github.com/hyperledger/fabric/gossip/state"
+	"github.com/hyperledger/fabric/msp/mgmt/testtools"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStateComparatorSupport(t *testing.T) {
+	viper.Set("peer.fileSystemPath", "/tmp/hyperledger/test/statecomparator")
+	defer os.RemoveAll("/tmp/hyperledger/test/statecomparator")
+	MockInitialize()
+	defer MockInitialize()
+	assert.NoError(t, msptesttools.LoadMSPSetupForTesting())
+
+	metadata, err := state.NewNodeMetastate(4).Bytes()
+	assert.NoError(t, err)
+	g := &mockGossipMembership{
+		peersOfChannel: map[string][]discovery.NetworkMember{
+			"channel1": {
+				{Endpoint: "peer1:7051", PKIid: gcommon.PKIidType("peer1"), Metadata: metadata},
+				{Endpoint: "peer2:7051", PKIid: gcommon.PKIidType("peer2"), Metadata: []byte("garbage")},
+			},
+		},
+	}
+	support := NewStateComparatorSupport()
+	support.gossip = func() gossipMembership { return g }
+
+	_, err = support.LedgerHeight("channel1")
+	assert.EqualError(t, err, "channel [channel1] not found")
+	_, err = support.GetStateCommitments("channel1", 0, 0)
+	assert.EqualError(t, err, "channel [channel1] not found")
+
+	assert.NoError(t, MockCreateChain("channel1"))
+	height, err := support.LedgerHeight("channel1")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), height)
+
+	// the commitments stop at the last committed block
+	commitments, err := support.GetStateCommitments("channel1", 0, 10)
+	assert.NoError(t, err)
+	assert.Len(t, commitments, 1)
+	genesisBlock, err := GetLedger("channel1").GetBlockByNumber(0)
+	assert.NoError(t, err)
+	expected, err := statecommitment.Compute(genesisBlock)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), commitments[0].BlockNum)
+	assert.Equal(t, expected, commitments[0].Commitment)
+
+	commitments, err = support.GetStateCommitments("channel1", 1, 10)
+	assert.NoError(t, err)
+	assert.Empty(t, commitments)
+
+	// the heights of the other peers are advertised in their gossip metadata
+	height, err = support.PeerLedgerHeight("channel1", "peer1:7051")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(4), height)
+	_, err = support.PeerLedgerHeight("channel1", "peer2:7051")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "peer [peer2:7051] advertises invalid metadata in channel [channel1]")
+	_, err = support.PeerLedgerHeight("channel1", "peer3:7051")
+	assert.EqualError(t, err, "peer [peer3:7051] is not known alive in channel [channel1]")
+
+	support.gossip = func() gossipMembership { return nil }
+	_, err = support.PeerLedgerHeight("channel1", "peer1:7051")
+	assert.EqualError(t, err, "the gossip service is not initialized")
+}
@@ -0,0 +1,214 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package subsystem
+
+import (
+	"sync"
+
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+)
+
+var logger = flogging.MustGetLogger("subsystem")
+
+// Subsystem is a component of the peer whose lifecycle is driven by the Manager
+type Subsystem interface {
+	// Start starts the subsystem. It should not block
+	// beyond the time needed to bring the subsystem up.
+	Start() error
+
+	// Stop stops the subsystem and releases its resources
+	Stop()
+
+	// HealthCheck returns nil if the subsystem is healthy,
+	// or an error describing the problem otherwise
+	HealthCheck(ctx context.Context) error
+}
+
+// Hooks is a Subsystem assembled from optional functions,
+// a nil function is treated as a no-op
+type Hooks struct {
+	StartFunc       func() error
+	StopFunc        func()
+	HealthCheckFunc func(ctx context.Context) error
+}
+
+// Start invokes StartFunc, if set
+func (h *Hooks) Start() error {
+	if h.StartFunc == nil {
+		return nil
+	}
+	return h.StartFunc()
+}
+
+// Stop invokes StopFunc, if set
+func (h *Hooks) Stop() {
+	if h.StopFunc != nil {
+		h.StopFunc()
+	}
+}
+
+// HealthCheck invokes HealthCheckFunc, if set
+func (h *Hooks) HealthCheck(ctx context.Context) error {
+	if h.HealthCheckFunc == nil {
+		return nil
+	}
+	return h.HealthCheckFunc(ctx)
+}
+
+type registration struct {
+	name      string
+	subsystem Subsystem
+	deps      []string
+}
+
+// Manager starts registered subsystems in dependency order
+// and stops them in the reverse order
+type Manager struct {
+	sync.Mutex
+	registrations map[string]*registration
+	order         []string
+	started       []string
+}
+
+// NewManager creates a new, empty, Manager
+func NewManager() *Manager {
+	return &Manager{
+		registrations: make(map[string]*registration),
+	}
+}
+
+// Register registers a subsystem under the given name. The subsystem
+// is started only after all subsystems it depends on were started.
+// Dependencies need not be registered before their dependents,
+// but must be registered by the time Start is called.
+func (m *Manager) Register(name string, s Subsystem, dependsOn ...string) error {
+	m.Lock()
+	defer m.Unlock()
+	if s == nil {
+		return errors.Errorf("nil subsystem %s", name)
+	}
+	if _, exists := m.registrations[name]; exists {
+		return errors.Errorf("subsystem %s is already registered", name)
+	}
+	m.registrations[name] = &registration{
+		name:      name,
+		subsystem: s,
+		deps:      dependsOn,
+	}
+	m.order = append(m.order, name)
+	return nil
+}
+
+// Start starts all registered subsystems in dependency order.
+// If a subsystem fails to start, the subsystems that were already
+// started are stopped in reverse order and the error is returned.
+func (m *Manager) Start() error {
+	m.Lock()
+	defer m.Unlock()
+	if len(m.started) != 0 {
+		return errors.New("subsystems are already started")
+	}
+	order, err := m.sortedNames()
+	if err != nil {
+		return err
+	}
+	for _, name := range order {
+		logger.Debugf("Starting subsystem %s", name)
+		if err := m.registrations[name].subsystem.Start(); err != nil {
+			m.stopStarted()
+			return errors.WithMessage(err, "failed starting subsystem "+name)
+		}
+		m.started = append(m.started, name)
+	}
+	return nil
+}
+
+// Stop stops all started subsystems, in the reverse order they were started
+func (m *Manager) Stop() {
+	m.Lock()
+	defer m.Unlock()
+	m.stopStarted()
+}
+
+// Get returns the subsystem registered under the given name
+func (m *Manager) Get(name string) (Subsystem, error) {
+	m.Lock()
+	defer m.Unlock()
+	r, exists := m.registrations[name]
+	if !exists {
+		return nil, errors.Errorf("subsystem %s is not registered", name)
+	}
+	return r.subsystem, nil
+}
+
+// HealthCheck runs the health check of every registered subsystem,
+// and returns the errors of the unhealthy ones, keyed by subsystem name
+func (m *Manager) HealthCheck(ctx context.Context) map[string]error {
+	m.Lock()
+	regs := make([]*registration, 0, len(m.order))
+	for _, name := range m.order {
+		regs = append(regs, m.registrations[name])
+	}
+	m.Unlock()
+
+	failures := make(map[string]error)
+	for _, r := range regs {
+		if err := r.subsystem.HealthCheck(ctx); err != nil {
+			failures[r.name] = err
+		}
+	}
+	return failures
+}
+
+func (m *Manager) stopStarted() {
+	for i := len(m.started) - 1; i >= 0; i-- {
+		logger.Debugf("Stopping subsystem %s", m.started[i])
+		m.registrations[m.started[i]].subsystem.Stop()
+	}
+	m.started = nil
+}
+
+// sortedNames returns the registered subsystem names sorted topologically,
+// keeping registration order among subsystems that don't depend on each other
+func (m *Manager) sortedNames() ([]string, error) {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int)
+	var sorted []string
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch state[name] {
+		case visited:
+			return nil
+		case visiting:
+			return errors.Errorf("dependency cycle detected at subsystem %s", name)
+		}
+		state[name] = visiting
+		for _, dep := range m.registrations[name].deps {
+			if _, exists := m.registrations[dep]; !exists {
+				return errors.Errorf("subsystem %s depends on unregistered subsystem %s", name, dep)
+			}
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		state[name] = visited
+		sorted = append(sorted, name)
+		return nil
+	}
+	for _, name := range m.order {
+		if err := visit(name); err != nil {
+			return nil, err
+		}
+	}
+	return sorted, nil
+}
@@ -0,0 +1,108 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package subsystem
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+)
+
+type recorder struct {
+	events []string
+}
+
+func (r *recorder) subsystem(name string, startErr error) Subsystem {
+	return &Hooks{
+		StartFunc: func() error {
+			if startErr != nil {
+				return startErr
+			}
+			r.events = append(r.events, "start "+name)
+			return nil
+		},
+		StopFunc: func() {
+			r.events = append(r.events, "stop "+name)
+		},
+	}
+}
+
+func TestStartStopOrder(t *testing.T) {
+	r := &recorder{}
+	m := NewManager()
+	assert.NoError(t, m.Register("gossip", r.subsystem("gossip", nil), "ledger"))
+	assert.NoError(t, m.Register("eventhub", r.subsystem("eventhub", nil)))
+	assert.NoError(t, m.Register("ledger", r.subsystem("ledger", nil)))
+	assert.NoError(t, m.Register("deliver", r.subsystem("deliver", nil), "gossip", "ledger"))
+
+	assert.NoError(t, m.Start())
+	assert.Error(t, m.Start())
+	m.Stop()
+	assert.Equal(t, []string{
+		"start ledger", "start gossip", "start eventhub", "start deliver",
+		"stop deliver", "stop eventhub", "stop gossip", "stop ledger",
+	}, r.events)
+
+	// Stopping again is a no-op
+	m.Stop()
+	assert.Len(t, r.events, 8)
+}
+
+func TestRegisterErrors(t *testing.T) {
+	m := NewManager()
+	assert.Error(t, m.Register("nil", nil))
+	assert.NoError(t, m.Register("a", &Hooks{}))
+	assert.Error(t, m.Register("a", &Hooks{}))
+}
+
+func TestStartDependencyErrors(t *testing.T) {
+	m := NewManager()
+	m.Register("a", &Hooks{}, "b")
+	err := m.Start()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "unregistered subsystem b")
+
+	m.Register("b", &Hooks{}, "a")
+	err = m.Start()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "dependency cycle")
+}
+
+func TestStartFailureRollsBack(t *testing.T) {
+	r := &recorder{}
+	m := NewManager()
+	m.Register("ledger", r.subsystem("ledger", nil))
+	m.Register("gossip", r.subsystem("gossip", nil), "ledger")
+	m.Register("deliver", r.subsystem("deliver", errors.New("no orderers")), "gossip")
+
+	err := m.Start()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed starting subsystem deliver: no orderers")
+	assert.Equal(t, []string{"start ledger", "start gossip", "stop gossip", "stop ledger"}, r.events)
+}
+
+func TestHealthCheck(t *testing.T) {
+	m := NewManager()
+	m.Register("healthy", &Hooks{})
+	m.Register("sick", &Hooks{
+		HealthCheckFunc: func(ctx context.Context) error {
+			return errors.New("disk full")
+		},
+	})
+	failures := m.HealthCheck(context.Background())
+	assert.Len(t, failures, 1)
+	assert.EqualError(t, failures["sick"], "disk full")
+
+	// the health of a subsystem can be checked on its own
+	sick, err := m.Get("sick")
+	assert.NoError(t, err)
+	assert.EqualError(t, sick.HealthCheck(context.Background()), "disk full")
+	_, err = m.Get("unknown")
+	assert.EqualError(t, err, "subsystem unknown is not registered")
+}
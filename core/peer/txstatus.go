@@ -0,0 +1,158 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package peer
+
+import (
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/policies"
+	"github.com/hyperledger/fabric/core/committer"
+	"github.com/hyperledger/fabric/protos/common"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	"github.com/hyperledger/fabric/protos/utils"
+	"golang.org/x/net/context"
+)
+
+// TxStatusSupport provides the transaction status server with the chains created on the peer
+type TxStatusSupport interface {
+	// RegisterTxStatusListener returns a channel receiving the status of the transaction once it
+	// is committed to the chain, or nil if the chain has not been created
+	RegisterTxStatusListener(cid, txID string) <-chan *committer.TxStatus
+
+	// UnregisterTxStatusListener removes a listener registered with RegisterTxStatusListener
+	UnregisterTxStatusListener(cid, txID string, listener <-chan *committer.TxStatus)
+
+	// CommittedTxStatus returns the status the transaction was committed to the chain with,
+	// or nil if it is not committed
+	CommittedTxStatus(cid, txID string) (*committer.TxStatus, error)
+
+	// PolicyManager returns the policy manager of the chain, or nil if the chain has not been created
+	PolicyManager(cid string) policies.Manager
+}
+
+// TxStatusServer implements the TxStatus service of the peer, which lets the clients authorized
+// by the Application Readers policy of the channel wait for the commit of a transaction
+type TxStatusServer struct {
+	support TxStatusSupport
+}
+
+// NewTxStatusServer creates a server notifying the status of the transactions committed to the given chains
+func NewTxStatusServer(support TxStatusSupport) *TxStatusServer {
+	return &TxStatusServer{support: support}
+}
+
+// NewTxStatusSupport returns the support of the transaction status server, which notifies the
+// status of the transactions committed to the chains created on the peer
+func NewTxStatusSupport() TxStatusSupport {
+	return txStatusSupport{}
+}
+
+type txStatusSupport struct{}
+
+func (txStatusSupport) RegisterTxStatusListener(cid, txID string) <-chan *committer.TxStatus {
+	return RegisterTxStatusListener(cid, txID)
+}
+
+func (txStatusSupport) UnregisterTxStatusListener(cid, txID string, listener <-chan *committer.TxStatus) {
+	UnregisterTxStatusListener(cid, txID, listener)
+}
+
+func (txStatusSupport) CommittedTxStatus(cid, txID string) (*committer.TxStatus, error) {
+	return CommittedTxStatus(cid, txID)
+}
+
+func (txStatusSupport) PolicyManager(cid string) policies.Manager {
+	return GetPolicyManager(cid)
+}
+
+// WaitForCommit returns the status of the requested transaction once it is committed, or right away if
+// it is committed already. The listener of the transaction is removed if the client stops waiting first
+func (s *TxStatusServer) WaitForCommit(ctx context.Context, env *common.Envelope) (*pb.TxStatusResponse, error) {
+	payload, err := utils.UnmarshalPayload(env.Payload)
+	if err != nil {
+		peerLogger.Warningf("Received an envelope with no payload: %s", err)
+		return &pb.TxStatusResponse{Status: common.Status_BAD_REQUEST}, nil
+	}
+	if payload.Header == nil {
+		peerLogger.Warningf("Received an envelope with no header")
+		return &pb.TxStatusResponse{Status: common.Status_BAD_REQUEST}, nil
+	}
+	chdr, err := utils.UnmarshalChannelHeader(payload.Header.ChannelHeader)
+	if err != nil {
+		peerLogger.Warningf("Failed to unmarshal channel header: %s", err)
+		return &pb.TxStatusResponse{Status: common.Status_BAD_REQUEST}, nil
+	}
+	request := &pb.TxStatusRequest{}
+	if err := proto.Unmarshal(payload.Data, request); err != nil {
+		peerLogger.Warningf("[channel: %s] Received an invalid transaction status request: %s", chdr.ChannelId, err)
+		return &pb.TxStatusResponse{Status: common.Status_BAD_REQUEST}, nil
+	}
+	if request.TxId == "" {
+		peerLogger.Warningf("[channel: %s] Received a transaction status request with no transaction ID", chdr.ChannelId)
+		return &pb.TxStatusResponse{Status: common.Status_BAD_REQUEST}, nil
+	}
+	signedData, err := env.AsSignedData()
+	if err != nil {
+		peerLogger.Warningf("[channel: %s] Received a transaction status request with no signature: %s", chdr.ChannelId, err)
+		return &pb.TxStatusResponse{Status: common.Status_BAD_REQUEST}, nil
+	}
+	if status := s.checkAccess(chdr.ChannelId, signedData); status != common.Status_SUCCESS {
+		return &pb.TxStatusResponse{Status: status}, nil
+	}
+
+	listener := s.support.RegisterTxStatusListener(chdr.ChannelId, request.TxId)
+	if listener == nil {
+		return &pb.TxStatusResponse{Status: common.Status_NOT_FOUND}, nil
+	}
+	// the transaction is looked up once the listener is registered, so that it is not missed if its block
+	// got committed before, or while, the listener was registered. A transaction repeating the ID of a
+	// committed one is not notified, its client gets the status of the committed one
+	txStatus, err := s.support.CommittedTxStatus(chdr.ChannelId, request.TxId)
+	if err != nil || txStatus != nil {
+		s.support.UnregisterTxStatusListener(chdr.ChannelId, request.TxId, listener)
+	}
+	if err != nil {
+		peerLogger.Errorf("[channel: %s] Failed looking up transaction %s: %s", chdr.ChannelId, request.TxId, err)
+		return &pb.TxStatusResponse{Status: common.Status_INTERNAL_SERVER_ERROR}, nil
+	}
+	if txStatus != nil {
+		return txStatusResponse(txStatus), nil
+	}
+	peerLogger.Debugf("[channel: %s] Waiting for the commit of transaction %s", chdr.ChannelId, request.TxId)
+	select {
+	case txStatus := <-listener:
+		return txStatusResponse(txStatus), nil
+	case <-ctx.Done():
+		s.support.UnregisterTxStatusListener(chdr.ChannelId, request.TxId, listener)
+		peerLogger.Debugf("[channel: %s] Client stopped waiting for the commit of transaction %s", chdr.ChannelId, request.TxId)
+		return nil, ctx.Err()
+	}
+}
+
+func txStatusResponse(txStatus *committer.TxStatus) *pb.TxStatusResponse {
+	return &pb.TxStatusResponse{
+		Status:         common.Status_SUCCESS,
+		BlockNumber:    txStatus.BlockNumber,
+		ValidationCode: txStatus.ValidationCode,
+	}
+}
+
+func (s *TxStatusServer) checkAccess(cid string, signedData []*common.SignedData) common.Status {
+	policyManager := s.support.PolicyManager(cid)
+	if policyManager == nil {
+		return common.Status_NOT_FOUND
+	}
+	policy, ok := policyManager.GetPolicy(policies.ChannelApplicationReaders)
+	if !ok {
+		peerLogger.Errorf("[channel: %s] Could not find policy %s", cid, policies.ChannelApplicationReaders)
+		return common.Status_FORBIDDEN
+	}
+	if err := policy.Evaluate(signedData); err != nil {
+		peerLogger.Warningf("[channel: %s] Transaction status request not authorized: %s", cid, err)
+		return common.Status_FORBIDDEN
+	}
+	return common.Status_SUCCESS
+}
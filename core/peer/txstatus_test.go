@@ -0,0 +1,179 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package peer
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	mockpolicies "github.com/hyperledger/fabric/common/mocks/policies"
+	"github.com/hyperledger/fabric/common/policies"
+	"github.com/hyperledger/fabric/core/committer"
+	"github.com/hyperledger/fabric/protos/common"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+)
+
+type mockTxStatusSupport struct {
+	listener      chan *committer.TxStatus
+	committed     map[string]*committer.TxStatus
+	lookupErr     error
+	policyManager *mockpolicies.Manager
+	registered    chan string
+	unregistered  chan string
+}
+
+func (s *mockTxStatusSupport) RegisterTxStatusListener(cid, txID string) <-chan *committer.TxStatus {
+	if cid != "testchainid" {
+		return nil
+	}
+	s.registered <- txID
+	return s.listener
+}
+
+func (s *mockTxStatusSupport) UnregisterTxStatusListener(cid, txID string, listener <-chan *committer.TxStatus) {
+	s.unregistered <- txID
+}
+
+func (s *mockTxStatusSupport) CommittedTxStatus(cid, txID string) (*committer.TxStatus, error) {
+	return s.committed[txID], s.lookupErr
+}
+
+func (s *mockTxStatusSupport) PolicyManager(cid string) policies.Manager {
+	if cid != "testchainid" {
+		return nil
+	}
+	return s.policyManager
+}
+
+func newMockTxStatusSupport() *mockTxStatusSupport {
+	return &mockTxStatusSupport{
+		listener:      make(chan *committer.TxStatus, 1),
+		committed:     make(map[string]*committer.TxStatus),
+		policyManager: &mockpolicies.Manager{Policy: &mockpolicies.Policy{}},
+		registered:    make(chan string, 1),
+		unregistered:  make(chan string, 1),
+	}
+}
+
+func makeTxStatusRequest(cid, txID string) *common.Envelope {
+	return &common.Envelope{Payload: utils.MarshalOrPanic(&common.Payload{
+		Header: &common.Header{
+			ChannelHeader:   utils.MarshalOrPanic(&common.ChannelHeader{ChannelId: cid}),
+			SignatureHeader: utils.MarshalOrPanic(&common.SignatureHeader{Creator: []byte("client")}),
+		},
+		Data: utils.MarshalOrPanic(&pb.TxStatusRequest{TxId: txID}),
+	})}
+}
+
+func TestTxStatusServerWaitForCommit(t *testing.T) {
+	support := newMockTxStatusSupport()
+	server := NewTxStatusServer(support)
+
+	type result struct {
+		response *pb.TxStatusResponse
+		err      error
+	}
+	done := make(chan result)
+	go func() {
+		response, err := server.WaitForCommit(context.Background(), makeTxStatusRequest("testchainid", "tx1"))
+		done <- result{response, err}
+	}()
+
+	select {
+	case <-support.registered:
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for the listener registration")
+	}
+	select {
+	case <-done:
+		t.Fatal("The status was returned before the transaction got committed")
+	default:
+	}
+
+	support.listener <- &committer.TxStatus{TxID: "tx1", BlockNumber: 3, ValidationCode: pb.TxValidationCode_MVCC_READ_CONFLICT}
+
+	select {
+	case r := <-done:
+		assert.NoError(t, r.err)
+		assert.Equal(t, common.Status_SUCCESS, r.response.Status)
+		assert.Equal(t, uint64(3), r.response.BlockNumber)
+		assert.Equal(t, pb.TxValidationCode_MVCC_READ_CONFLICT, r.response.ValidationCode)
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for the transaction status")
+	}
+}
+
+func TestTxStatusServerAlreadyCommitted(t *testing.T) {
+	support := newMockTxStatusSupport()
+	server := NewTxStatusServer(support)
+
+	// the block of the transaction got committed before the client registered its listener
+	support.committed["tx1"] = &committer.TxStatus{TxID: "tx1", BlockNumber: 5, ValidationCode: pb.TxValidationCode_VALID}
+	response, err := server.WaitForCommit(context.Background(), makeTxStatusRequest("testchainid", "tx1"))
+	assert.NoError(t, err)
+	assert.Equal(t, common.Status_SUCCESS, response.Status)
+	assert.Equal(t, uint64(5), response.BlockNumber)
+	assert.Equal(t, pb.TxValidationCode_VALID, response.ValidationCode)
+	assert.Equal(t, "tx1", <-support.registered)
+	assert.Equal(t, "tx1", <-support.unregistered)
+
+	// the listener is removed if the transaction cannot be looked up
+	support.lookupErr = errors.New("index unavailable")
+	response, err = server.WaitForCommit(context.Background(), makeTxStatusRequest("testchainid", "tx2"))
+	assert.NoError(t, err)
+	assert.Equal(t, common.Status_INTERNAL_SERVER_ERROR, response.Status)
+	assert.Equal(t, "tx2", <-support.registered)
+	assert.Equal(t, "tx2", <-support.unregistered)
+}
+
+func TestTxStatusServerClientGone(t *testing.T) {
+	support := newMockTxStatusSupport()
+	server := NewTxStatusServer(support)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		_, err := server.WaitForCommit(ctx, makeTxStatusRequest("testchainid", "tx1"))
+		done <- err
+	}()
+	<-support.registered
+	cancel()
+
+	select {
+	case err := <-done:
+		assert.Equal(t, context.Canceled, err)
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for WaitForCommit to return")
+	}
+	assert.Equal(t, "tx1", <-support.unregistered)
+}
+
+func TestTxStatusServerBadRequests(t *testing.T) {
+	support := newMockTxStatusSupport()
+	server := NewTxStatusServer(support)
+
+	response, err := server.WaitForCommit(context.Background(), &common.Envelope{Payload: []byte("garbage")})
+	assert.NoError(t, err)
+	assert.Equal(t, common.Status_BAD_REQUEST, response.Status)
+
+	response, err = server.WaitForCommit(context.Background(), makeTxStatusRequest("testchainid", ""))
+	assert.NoError(t, err)
+	assert.Equal(t, common.Status_BAD_REQUEST, response.Status)
+
+	response, err = server.WaitForCommit(context.Background(), makeTxStatusRequest("otherchainid", "tx1"))
+	assert.NoError(t, err)
+	assert.Equal(t, common.Status_NOT_FOUND, response.Status)
+
+	support.policyManager.Policy.Err = errors.New("not a reader")
+	response, err = server.WaitForCommit(context.Background(), makeTxStatusRequest("testchainid", "tx1"))
+	assert.NoError(t, err)
+	assert.Equal(t, common.Status_FORBIDDEN, response.Status)
+}
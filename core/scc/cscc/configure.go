@@ -22,18 +22,19 @@ limitations under the License.
 package cscc
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
+	"io"
 
 	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric/common/config/channel"
 	"github.com/hyperledger/fabric/common/flogging"
-	"github.com/hyperledger/fabric/common/policies"
+	"github.com/hyperledger/fabric/common/ledger/blockstream"
+	"github.com/hyperledger/fabric/core/aclmgmt"
 	"github.com/hyperledger/fabric/core/chaincode/shim"
 	"github.com/hyperledger/fabric/core/peer"
-	"github.com/hyperledger/fabric/core/policy"
 	"github.com/hyperledger/fabric/events/producer"
-	"github.com/hyperledger/fabric/msp/mgmt"
 	"github.com/hyperledger/fabric/protos/common"
 	pb "github.com/hyperledger/fabric/protos/peer"
 	"github.com/hyperledger/fabric/protos/utils"
@@ -43,7 +44,7 @@ import (
 // configuration transaction coming in from the ordering service, the
 // committer calls this system chaincode to process the transaction.
 type PeerConfiger struct {
-	policyChecker policy.PolicyChecker
+	aclProvider aclmgmt.ACLProvider
 }
 
 var cnflogger = flogging.MustGetLogger("cscc")
@@ -51,6 +52,7 @@ var cnflogger = flogging.MustGetLogger("cscc")
 // These are function names from Invoke first parameter
 const (
 	JoinChain      string = "JoinChain"
+	JoinChains     string = "JoinChains"
 	GetConfigBlock string = "GetConfigBlock"
 	GetChannels    string = "GetChannels"
 )
@@ -61,12 +63,8 @@ const (
 func (e *PeerConfiger) Init(stub shim.ChaincodeStubInterface) pb.Response {
 	cnflogger.Info("Init CSCC")
 
-	// Init policy checker for access control
-	e.policyChecker = policy.NewPolicyChecker(
-		peer.NewChannelPolicyManagerGetter(),
-		mgmt.GetLocalMSP(),
-		mgmt.NewLocalMSPPrincipalGetter(),
-	)
+	// Init ACL provider for access control
+	e.aclProvider = aclmgmt.GetACLProvider()
 
 	return shim.Success(nil)
 }
@@ -80,6 +78,10 @@ func (e *PeerConfiger) Init(stub shim.ChaincodeStubInterface) pb.Response {
 // UpdateConfigBlock
 // # args[1] is a configuration Block if args[0] is JoinChain or
 // UpdateConfigBlock; otherwise it is the chain id
+// JoinChain accepts an optional args[2], a block stream of the blocks of the chain
+// that are committed upon the join, before the peer replicates the rest of the chain
+// JoinChains takes a block stream of the configuration blocks of several chains as
+// args[1], and joins either all of the chains or none of them
 // TODO: Improve the scc interface to avoid marshal/unmarshal args
 func (e *PeerConfiger) Invoke(stub shim.ChaincodeStubInterface) pb.Response {
 	args := stub.GetArgs()
@@ -125,22 +127,43 @@ func (e *PeerConfiger) Invoke(stub shim.ChaincodeStubInterface) pb.Response {
 				"of configuration block, because of %s", cid, err))
 		}
 
-		// 2. check local MSP Admins policy
-		if err = e.policyChecker.CheckPolicyNoChannel(mgmt.Admins, sp); err != nil {
+		// 2. check the ACL of joining a channel, the local MSP Admins policy by default
+		if err = e.aclProvider.CheckACL(aclmgmt.CSCC_JoinChain, "", sp); err != nil {
 			return shim.Error(fmt.Sprintf("\"JoinChain\" request failed authorization check "+
 				"for channel [%s]: [%s]", cid, err))
 		}
 
-		return joinChain(cid, block)
+		var blockStream []byte
+		if len(args) > 2 {
+			blockStream = args[2]
+		}
+		return joinChain(cid, block, blockStream)
+	case JoinChains:
+		if args[1] == nil {
+			return shim.Error("Cannot join the channels <nil> configuration blocks provided")
+		}
+
+		blocks, err := readConfigBlocks(args[1])
+		if err != nil {
+			return shim.Error(fmt.Sprintf("\"JoinChains\" request failed because of validation "+
+				"of configuration blocks, because of %s", err))
+		}
+
+		// 2. check the ACL of joining a channel, the local MSP Admins policy by default
+		if err = e.aclProvider.CheckACL(aclmgmt.CSCC_JoinChain, "", sp); err != nil {
+			return shim.Error(fmt.Sprintf("\"JoinChains\" request failed authorization check: [%s]", err))
+		}
+
+		return joinChains(blocks)
 	case GetConfigBlock:
-		// 2. check the channel reader policy
-		if err = e.policyChecker.CheckPolicy(string(args[1]), policies.ChannelApplicationReaders, sp); err != nil {
+		// 2. check the ACL of the config block, the channel reader policy by default
+		if err = e.aclProvider.CheckACL(aclmgmt.CSCC_GetConfigBlock, string(args[1]), sp); err != nil {
 			return shim.Error(fmt.Sprintf("\"GetConfigBlock\" request failed authorization check for channel [%s]: [%s]", args[1], err))
 		}
 		return getConfigBlock(args[1])
 	case GetChannels:
-		// 2. check local MSP Members policy
-		if err = e.policyChecker.CheckPolicyNoChannel(mgmt.Members, sp); err != nil {
+		// 2. check the ACL of listing the channels, the local MSP Members policy by default
+		if err = e.aclProvider.CheckACL(aclmgmt.CSCC_GetChannels, "", sp); err != nil {
 			return shim.Error(fmt.Sprintf("\"GetChannels\" request failed authorization check: [%s]", err))
 		}
 
@@ -187,8 +210,14 @@ func validateConfigBlock(block *common.Block) error {
 // joinChain will join the specified chain in the configuration block.
 // Since it is the first block, it is the genesis block containing configuration
 // for this chain, so we want to update the Chain object with this info
-func joinChain(chainID string, block *common.Block) pb.Response {
-	if err := peer.CreateChainFromBlock(block); err != nil {
+func joinChain(chainID string, block *common.Block, blockStream []byte) pb.Response {
+	var err error
+	if blockStream == nil {
+		err = peer.CreateChainFromBlock(block)
+	} else {
+		err = peer.CreateChainFromBlockStream(block, blockstream.NewReader(bytes.NewReader(blockStream)))
+	}
+	if err != nil {
 		return shim.Error(err.Error())
 	}
 
@@ -201,6 +230,47 @@ func joinChain(chainID string, block *common.Block) pb.Response {
 	return shim.Success(nil)
 }
 
+// readConfigBlocks reads the configuration blocks of the block stream, and validates each of them
+func readConfigBlocks(blockStream []byte) ([]*common.Block, error) {
+	var blocks []*common.Block
+	reader := blockstream.NewReader(bytes.NewReader(blockStream))
+	for {
+		block, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Failed to read the configuration blocks, %s", err)
+		}
+		if err := validateConfigBlock(block); err != nil {
+			return nil, fmt.Errorf("Invalid configuration block %d: %s", len(blocks), err)
+		}
+		blocks = append(blocks, block)
+	}
+	if len(blocks) == 0 {
+		return nil, errors.New("No configuration blocks provided")
+	}
+	return blocks, nil
+}
+
+// joinChains joins the chains of the configuration blocks, all of them or none
+func joinChains(blocks []*common.Block) pb.Response {
+	if err := peer.CreateChainsFromBlocks(blocks); err != nil {
+		return shim.Error(err.Error())
+	}
+
+	for _, block := range blocks {
+		chainID, _ := utils.GetChainIDFromBlock(block)
+		peer.InitChain(chainID)
+
+		if err := producer.SendProducerBlockEvent(block); err != nil {
+			cnflogger.Errorf("Error sending block event %s", err)
+		}
+	}
+
+	return shim.Success(nil)
+}
+
 // Return the current configuration block for the specified chainID. If the
 // peer doesn't belong to the chain, return error
 func getConfigBlock(chainID []byte) pb.Response {
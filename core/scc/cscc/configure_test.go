@@ -16,6 +16,7 @@ limitations under the License.
 package cscc
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"strings"
@@ -25,9 +26,11 @@ import (
 	"github.com/golang/protobuf/proto"
 	configtxtest "github.com/hyperledger/fabric/common/configtx/test"
 	"github.com/hyperledger/fabric/common/genesis"
+	"github.com/hyperledger/fabric/common/ledger/blockstream"
 	"github.com/hyperledger/fabric/common/localmsp"
 	"github.com/hyperledger/fabric/common/mocks/scc"
 	"github.com/hyperledger/fabric/common/policies"
+	"github.com/hyperledger/fabric/core/aclmgmt"
 	"github.com/hyperledger/fabric/core/chaincode"
 	"github.com/hyperledger/fabric/core/chaincode/shim"
 	"github.com/hyperledger/fabric/core/common/sysccprovider"
@@ -48,6 +51,7 @@ import (
 	"github.com/hyperledger/fabric/protos/utils"
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
 )
 
 type mockDeliveryClient struct {
@@ -70,6 +74,10 @@ func (*mockDeliveryClient) Stop() {
 
 }
 
+func (*mockDeliveryClient) HealthCheck(ctx context.Context) error {
+	return nil
+}
+
 type mockDeliveryClientFactory struct {
 }
 
@@ -79,6 +87,7 @@ func (*mockDeliveryClientFactory) Service(g service.GossipService, endpoints []s
 
 func TestMain(m *testing.M) {
 	msptesttools.LoadMSPSetupForTesting()
+	aclmgmt.RegisterACLProvider(nil)
 
 	os.Exit(m.Run())
 }
@@ -191,11 +200,11 @@ func TestConfigerInvokeJoinChainCorrectParams(t *testing.T) {
 
 	identityDeserializer := &policymocks.MockIdentityDeserializer{[]byte("Alice"), []byte("msg1")}
 
-	e.policyChecker = policy.NewPolicyChecker(
+	e.aclProvider = aclmgmt.NewDefaultACLProviderWithPolicyChecker(policy.NewPolicyChecker(
 		policyManagerGetter,
 		identityDeserializer,
 		&policymocks.MockMSPPrincipalGetter{Principal: []byte("Alice")},
-	)
+	))
 
 	identity, _ := mgmt.GetLocalSigningIdentityOrPanic().Serialize()
 	messageCryptoService := peergossip.NewMCS(&mocks.ChannelPolicyManagerGetter{}, localmsp.NewSigner(), mgmt.NewDeserializersManager())
@@ -274,6 +283,31 @@ func TestConfigerInvokeJoinChainCorrectParams(t *testing.T) {
 	if len(cqr.GetChannels()) != 1 {
 		t.FailNow()
 	}
+
+	// Join several channels at once
+	joinChains := func(chainIDs ...string) pb.Response {
+		buf := &bytes.Buffer{}
+		for _, chainID := range chainIDs {
+			block, err := configtxtest.MakeGenesisBlock(chainID)
+			assert.NoError(t, err)
+			assert.NoError(t, blockstream.NewWriter(buf).Write(block))
+		}
+		return stub.MockInvokeWithSignedProposal("4", [][]byte{[]byte(JoinChains), buf.Bytes()}, sProp)
+	}
+	res = joinChains("mytestchainid1", "mytestchainid2")
+	assert.Equal(t, int32(shim.OK), res.Status, res.Message)
+	assert.NotNil(t, peer.GetLedger("mytestchainid1"))
+	assert.NotNil(t, peer.GetLedger("mytestchainid2"))
+
+	// None of the channels is joined if one of them cannot be
+	res = joinChains("mytestchainid3", "mytestchainid")
+	assert.Equal(t, int32(shim.ERROR), res.Status)
+	assert.Nil(t, peer.GetLedger("mytestchainid3"))
+	res = joinChains()
+	assert.Equal(t, int32(shim.ERROR), res.Status)
+	res = stub.MockInvokeWithSignedProposal("4", [][]byte{[]byte(JoinChains), badBlockBytes}, sProp)
+	assert.Equal(t, int32(shim.ERROR), res.Status)
+	assert.Len(t, peer.GetChannelsInfo(), 3)
 }
 
 func TestPeerConfiger_SubmittingOrdererGenesis(t *testing.T) {
@@ -22,6 +22,7 @@ import (
 	//import system chain codes here
 	"github.com/hyperledger/fabric/core/scc/cscc"
 	"github.com/hyperledger/fabric/core/scc/escc"
+	"github.com/hyperledger/fabric/core/scc/lifecycle"
 	"github.com/hyperledger/fabric/core/scc/lscc"
 	"github.com/hyperledger/fabric/core/scc/qscc"
 	"github.com/hyperledger/fabric/core/scc/rscc"
@@ -79,6 +80,15 @@ var systemChaincodes = []*SystemChaincode{
 		InvokableExternal: true,  // rscc can be invoked to update policies
 		InvokableCC2CC:    false, // rscc cannot be invoked from a cc
 	},
+	{
+		Enabled:           true,
+		Name:              lifecycle.Name,
+		Path:              "github.com/hyperledger/fabric/core/scc/lifecycle",
+		InitArgs:          [][]byte{[]byte("")},
+		Chaincode:         &lifecycle.Lifecycle{},
+		InvokableExternal: true,  // _lifecycle is invoked to approve and commit chaincode definitions, once the application capabilities of the channel enable it
+		InvokableCC2CC:    false, // _lifecycle cannot be invoked from a cc
+	},
 }
 
 //RegisterSysCCs is the hook for system chaincodes where system chaincodes are registered with the fabric
@@ -0,0 +1,227 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package lifecycle
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/policies"
+	"github.com/hyperledger/fabric/core/common/privdata"
+	cb "github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/msp"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	lc "github.com/hyperledger/fabric/protos/peer/lifecycle"
+	"github.com/hyperledger/fabric/protos/utils"
+)
+
+const (
+	// Name is the name of the lifecycle system chaincode, and the namespace
+	// holding the approved and the committed chaincode definitions
+	Name = privdata.LifecycleNamespace
+
+	// ApproveFuncName approves a chaincode definition for the org of the creator
+	ApproveFuncName = "ApproveChaincodeDefinitionForMyOrg"
+	// CommitFuncName commits a chaincode definition to the channel
+	CommitFuncName = "CommitChaincodeDefinition"
+	// QueryDefinitionFuncName returns the committed definition of a chaincode
+	QueryDefinitionFuncName = "QueryChaincodeDefinition"
+	// QueryApprovalStatusFuncName returns the orgs which approved a chaincode definition
+	QueryApprovalStatusFuncName = "QueryApprovalStatus"
+
+	// LifecycleEndorsementPolicyName is the channel policy that the approvals of
+	// a chaincode definition have to satisfy for the definition to be committed.
+	// The Admins policy of the application orgs is used if the channel doesn't define it
+	LifecycleEndorsementPolicyName = policies.PathSeparator + policies.ChannelPrefix + policies.PathSeparator +
+		policies.ApplicationPrefix + policies.PathSeparator + "LifecycleEndorsement"
+
+	approvalPrefix = "approvals/"
+)
+
+var (
+	chaincodeNameRegExp    = regexp.MustCompile("^[A-Za-z0-9_-]+$")
+	chaincodeVersionRegExp = regexp.MustCompile("^[A-Za-z0-9_.-]+$")
+)
+
+// DefinitionKey returns the key of the committed definition of the chaincode
+func DefinitionKey(name string) string {
+	return privdata.BuildLifecycleDefinitionKey(name)
+}
+
+// ApprovalKey returns the key of the approval by the org of the sequence-th
+// definition of the chaincode
+func ApprovalKey(name string, sequence int64, mspID string) string {
+	return fmt.Sprintf("%s%s/%d/%s", approvalPrefix, name, sequence, mspID)
+}
+
+// ApprovalsRange returns the range of the keys of the approvals of the sequence-th
+// definition of the chaincode, as the start key and the exclusive end key
+func ApprovalsRange(name string, sequence int64) (string, string) {
+	prefix := fmt.Sprintf("%s%s/%d/", approvalPrefix, name, sequence)
+	// '0' follows '/', and cannot be followed by another digit of the sequence
+	return prefix, prefix[:len(prefix)-1] + "0"
+}
+
+// IsApprovalKey returns whether the key holds an approval
+// of the sequence-th definition of the chaincode
+func IsApprovalKey(key, name string, sequence int64) bool {
+	start, end := ApprovalsRange(name, sequence)
+	return key >= start && key < end
+}
+
+// ValidateDefinition returns an error if the chaincode definition is malformed
+func ValidateDefinition(def *lc.ChaincodeDefinition) error {
+	if def == nil {
+		return errors.New("nil chaincode definition")
+	}
+	if !chaincodeNameRegExp.MatchString(def.Name) {
+		return fmt.Errorf("invalid chaincode name '%s'", def.Name)
+	}
+	if !chaincodeVersionRegExp.MatchString(def.Version) {
+		return fmt.Errorf("invalid version '%s' of chaincode %s", def.Version, def.Name)
+	}
+	if def.Sequence < 1 {
+		return fmt.Errorf("invalid sequence %d of chaincode %s, sequences start at 1", def.Sequence, def.Name)
+	}
+	if len(def.EndorsementPolicy) == 0 {
+		return fmt.Errorf("no endorsement policy for chaincode %s", def.Name)
+	}
+	if err := proto.Unmarshal(def.EndorsementPolicy, &cb.SignaturePolicyEnvelope{}); err != nil {
+		return fmt.Errorf("invalid endorsement policy for chaincode %s: %s", def.Name, err)
+	}
+	if err := privdata.ValidateCollectionConfigs(def.Collections); err != nil {
+		return fmt.Errorf("invalid collections of chaincode %s: %s", def.Name, err)
+	}
+	return nil
+}
+
+// UnmarshalDefinition unmarshals and validates a chaincode definition
+func UnmarshalDefinition(defBytes []byte) (*lc.ChaincodeDefinition, error) {
+	def := &lc.ChaincodeDefinition{}
+	if err := proto.Unmarshal(defBytes, def); err != nil {
+		return nil, fmt.Errorf("invalid chaincode definition: %s", err)
+	}
+	if err := ValidateDefinition(def); err != nil {
+		return nil, err
+	}
+	return def, nil
+}
+
+// Approval is the approval of a chaincode definition by an org of a channel. It is
+// attested by the signed proposal invoking ApproveChaincodeDefinitionForMyOrg, which
+// is stored as the value of the approval key
+type Approval struct {
+	ChannelID  string
+	MSPID      string
+	Definition *lc.ChaincodeDefinition
+	SignedData *cb.SignedData
+}
+
+// ParseApproval returns the approval of the signed proposal. The signature
+// of the proposal is only checked when the approval is evaluated
+func ParseApproval(sp *pb.SignedProposal) (*Approval, error) {
+	if sp == nil {
+		return nil, errors.New("nil signed proposal")
+	}
+	prop, err := utils.GetProposal(sp.ProposalBytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proposal: %s", err)
+	}
+	hdr, err := utils.GetHeader(prop.Header)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proposal header: %s", err)
+	}
+	chdr, err := utils.UnmarshalChannelHeader(hdr.ChannelHeader)
+	if err != nil {
+		return nil, fmt.Errorf("invalid channel header: %s", err)
+	}
+	shdr, err := utils.GetSignatureHeader(hdr.SignatureHeader)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signature header: %s", err)
+	}
+	creator := &msp.SerializedIdentity{}
+	if err = proto.Unmarshal(shdr.Creator, creator); err != nil {
+		return nil, fmt.Errorf("invalid creator: %s", err)
+	}
+	cis, err := utils.GetChaincodeInvocationSpec(prop)
+	if err != nil {
+		return nil, fmt.Errorf("invalid chaincode invocation: %s", err)
+	}
+	if cis.ChaincodeSpec == nil || cis.ChaincodeSpec.ChaincodeId == nil || cis.ChaincodeSpec.ChaincodeId.Name != Name ||
+		cis.ChaincodeSpec.Input == nil || len(cis.ChaincodeSpec.Input.Args) != 2 ||
+		string(cis.ChaincodeSpec.Input.Args[0]) != ApproveFuncName {
+		return nil, fmt.Errorf("proposal is not an invocation of %s of %s", ApproveFuncName, Name)
+	}
+	def, err := UnmarshalDefinition(cis.ChaincodeSpec.Input.Args[1])
+	if err != nil {
+		return nil, err
+	}
+	return &Approval{
+		ChannelID:  chdr.ChannelId,
+		MSPID:      creator.Mspid,
+		Definition: def,
+		SignedData: &cb.SignedData{Data: sp.ProposalBytes, Identity: shdr.Creator, Signature: sp.Signature},
+	}, nil
+}
+
+// ApprovalPolicy returns the policy of the channel that the approvals of a
+// chaincode definition have to satisfy, along with the name of the policy
+func ApprovalPolicy(pm policies.Manager) (policies.Policy, string) {
+	if policy, ok := pm.GetPolicy(LifecycleEndorsementPolicyName); ok {
+		return policy, LifecycleEndorsementPolicyName
+	}
+	policy, _ := pm.GetPolicy(policies.ChannelApplicationAdmins)
+	return policy, policies.ChannelApplicationAdmins
+}
+
+// MatchingApprovals returns the approvals of the definition in the channel among the
+// stored approvals; the approvals of other definitions or channels are skipped.
+// Each org can only approve once, as the key of its approval
+// is derived from its MSP ID, which is verified when it is written
+func MatchingApprovals(channelID string, def *lc.ChaincodeDefinition, approvals map[string][]byte) ([]*Approval, error) {
+	var matching []*Approval
+	for key, value := range approvals {
+		sp := &pb.SignedProposal{}
+		if err := proto.Unmarshal(value, sp); err != nil {
+			return nil, fmt.Errorf("invalid approval %s: %s", key, err)
+		}
+		approval, err := ParseApproval(sp)
+		if err != nil {
+			return nil, fmt.Errorf("invalid approval %s: %s", key, err)
+		}
+		if approval.ChannelID != channelID || !proto.Equal(approval.Definition, def) ||
+			key != ApprovalKey(def.Name, def.Sequence, approval.MSPID) {
+			continue
+		}
+		matching = append(matching, approval)
+	}
+	return matching, nil
+}
+
+// EvaluateApprovals returns an error if the approvals of the definition in the
+// channel among the stored approvals don't satisfy the approval policy of the channel
+func EvaluateApprovals(channelID string, def *lc.ChaincodeDefinition, approvals map[string][]byte, pm policies.Manager) error {
+	matching, err := MatchingApprovals(channelID, def, approvals)
+	if err != nil {
+		return err
+	}
+	signatureSet := make([]*cb.SignedData, len(matching))
+	for i, approval := range matching {
+		signatureSet[i] = approval.SignedData
+	}
+	policy, policyName := ApprovalPolicy(pm)
+	if policy == nil {
+		return fmt.Errorf("no policy %s in channel %s", policyName, channelID)
+	}
+	if trace, err := policies.EvaluateWithTrace(policy, signatureSet); err != nil {
+		return fmt.Errorf("approvals of chaincode %s sequence %d don't satisfy policy %s: %s, evaluation %s",
+			def.Name, def.Sequence, policyName, err, trace)
+	}
+	return nil
+}
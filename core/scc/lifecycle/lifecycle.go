@@ -0,0 +1,252 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package lifecycle
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	"github.com/hyperledger/fabric/core/common/privdata"
+	"github.com/hyperledger/fabric/core/common/sysccprovider"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	lc "github.com/hyperledger/fabric/protos/peer/lifecycle"
+	"github.com/hyperledger/fabric/protos/utils"
+)
+
+var logger = flogging.MustGetLogger("lifecycle")
+
+// Lifecycle is the system chaincode through which the orgs of a channel agree on the
+// definitions of the chaincodes of the channel. Each org approves a definition of a
+// chaincode, and the definition is committed once the approvals of the orgs satisfy the
+// lifecycle policy of the channel. The committed definitions take precedence over the ones
+// of lscc when the committer resolves the validation plugin and the endorsement policy of a
+// chaincode, and when the peers resolve its collections; the chaincodes are still installed,
+// instantiated and launched through lscc.
+//
+// ApproveChaincodeDefinitionForMyOrg stores the signed proposal as the approval of the
+// definition by the org of the creator, CommitChaincodeDefinition commits the definition
+// if its approvals satisfy the policy, and QueryApprovalStatus returns the ApprovalStatus
+// of the definition; they take the marshaled ChaincodeDefinition as their only argument.
+// QueryChaincodeDefinition takes the name of a chaincode, and returns its committed
+// ChaincodeDefinition
+type Lifecycle struct {
+	// sccprovider is the interface with which we call
+	// methods of the system chaincode package without
+	// import cycles
+	sccprovider sysccprovider.SystemChaincodeProvider
+}
+
+// Init is called once when the chaincode started the first time
+func (l *Lifecycle) Init(stub shim.ChaincodeStubInterface) pb.Response {
+	l.sccprovider = sysccprovider.GetSystemChaincodeProvider()
+
+	return shim.Success(nil)
+}
+
+// Invoke dispatches the invocation to the function named by the first argument
+func (l *Lifecycle) Invoke(stub shim.ChaincodeStubInterface) pb.Response {
+	args := stub.GetArgs()
+	if len(args) != 2 {
+		return shim.Error(fmt.Sprintf("invalid number of arguments to %s: %d", Name, len(args)))
+	}
+	function := string(args[0])
+
+	sp, err := stub.GetSignedProposal()
+	if err != nil {
+		return shim.Error(fmt.Sprintf("failed retrieving signed proposal on executing %s: %s", function, err))
+	}
+	channelID, err := channelOf(sp)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	if ac, exists := l.sccprovider.ApplicationCapabilities(channelID); !exists || !ac.Lifecycle() {
+		return shim.Error(fmt.Sprintf("%s is not enabled on channel %s, its application capabilities do not enable it", Name, channelID))
+	}
+
+	switch function {
+	case ApproveFuncName:
+		if err := l.approve(stub, channelID, sp, args[1]); err != nil {
+			return shim.Error(err.Error())
+		}
+		return shim.Success(nil)
+	case CommitFuncName:
+		if err := l.commit(stub, channelID, args[1]); err != nil {
+			return shim.Error(err.Error())
+		}
+		return shim.Success(nil)
+	case QueryDefinitionFuncName:
+		defBytes, err := stub.GetState(DefinitionKey(string(args[1])))
+		if err != nil {
+			return shim.Error(fmt.Sprintf("failed retrieving definition of chaincode %s: %s", args[1], err))
+		}
+		if defBytes == nil {
+			return shim.Error(fmt.Sprintf("chaincode %s is not defined in channel %s", args[1], channelID))
+		}
+		return shim.Success(defBytes)
+	case QueryApprovalStatusFuncName:
+		status, err := l.approvalStatus(stub, channelID, args[1])
+		if err != nil {
+			return shim.Error(err.Error())
+		}
+		return shim.Success(status)
+	default:
+		return shim.Error(fmt.Sprintf("invalid function %s of %s", function, Name))
+	}
+}
+
+// approve stores the signed proposal as the approval of the definition by the org of its creator
+func (l *Lifecycle) approve(stub shim.ChaincodeStubInterface, channelID string, sp *pb.SignedProposal, defBytes []byte) error {
+	def, err := UnmarshalDefinition(defBytes)
+	if err != nil {
+		return err
+	}
+	approval, err := ParseApproval(sp)
+	if err != nil {
+		return err
+	}
+	if !proto.Equal(approval.Definition, def) {
+		return errors.New("approved definition differs from the one of the proposal")
+	}
+	if err = l.checkDefinition(stub, channelID, def); err != nil {
+		return err
+	}
+	spBytes, err := proto.Marshal(sp)
+	if err != nil {
+		return fmt.Errorf("failed marshaling approval: %s", err)
+	}
+	logger.Debugf("Org %s approves definition %d of chaincode %s", approval.MSPID, def.Sequence, def.Name)
+	return stub.PutState(ApprovalKey(def.Name, def.Sequence, approval.MSPID), spBytes)
+}
+
+// commit stores the definition as the definition of the chaincode
+// if its approvals satisfy the policy of the channel
+func (l *Lifecycle) commit(stub shim.ChaincodeStubInterface, channelID string, defBytes []byte) error {
+	def, err := UnmarshalDefinition(defBytes)
+	if err != nil {
+		return err
+	}
+	if err = l.checkDefinition(stub, channelID, def); err != nil {
+		return err
+	}
+	approvals, err := getApprovals(stub, def)
+	if err != nil {
+		return err
+	}
+	pm, ok := l.sccprovider.PolicyManager(channelID)
+	if !ok {
+		return fmt.Errorf("no policy manager for channel %s", channelID)
+	}
+	if err = EvaluateApprovals(channelID, def, approvals, pm); err != nil {
+		return err
+	}
+	defBytes, err = proto.Marshal(def)
+	if err != nil {
+		return fmt.Errorf("failed marshaling definition of chaincode %s: %s", def.Name, err)
+	}
+	logger.Infof("Committing definition %d of chaincode %s to channel %s", def.Sequence, def.Name, channelID)
+	return stub.PutState(DefinitionKey(def.Name), defBytes)
+}
+
+// approvalStatus returns the marshaled ApprovalStatus of the definition
+func (l *Lifecycle) approvalStatus(stub shim.ChaincodeStubInterface, channelID string, defBytes []byte) ([]byte, error) {
+	def, err := UnmarshalDefinition(defBytes)
+	if err != nil {
+		return nil, err
+	}
+	approvals, err := getApprovals(stub, def)
+	if err != nil {
+		return nil, err
+	}
+	matching, err := MatchingApprovals(channelID, def, approvals)
+	if err != nil {
+		return nil, err
+	}
+	status := &lc.ApprovalStatus{}
+	for _, approval := range matching {
+		status.Approved = append(status.Approved, approval.MSPID)
+	}
+	return proto.Marshal(status)
+}
+
+// checkDefinition returns an error if the definition doesn't follow the definition of the
+// chaincode committed last, if any, or if its collections cannot replace the committed ones
+// or have members which are not orgs of the channel
+func (l *Lifecycle) checkDefinition(stub shim.ChaincodeStubInterface, channelID string, def *lc.ChaincodeDefinition) error {
+	committedBytes, err := stub.GetState(DefinitionKey(def.Name))
+	if err != nil {
+		return fmt.Errorf("failed retrieving definition of chaincode %s: %s", def.Name, err)
+	}
+	committed := &lc.ChaincodeDefinition{}
+	if err = proto.Unmarshal(committedBytes, committed); err != nil {
+		return fmt.Errorf("invalid committed definition of chaincode %s: %s", def.Name, err)
+	}
+	if def.Sequence != committed.Sequence+1 {
+		return fmt.Errorf("expected sequence %d for chaincode %s, got %d", committed.Sequence+1, def.Name, def.Sequence)
+	}
+	if err = privdata.ValidateCollectionConfigUpgrade(committed.Collections, def.Collections); err != nil {
+		return fmt.Errorf("invalid collections of chaincode %s: %s", def.Name, err)
+	}
+	if err = privdata.ValidateCollectionMemberOrgs(def.Collections, l.sccprovider.MSPIDs(channelID)); err != nil {
+		return fmt.Errorf("invalid collections of chaincode %s: %s", def.Name, err)
+	}
+	return nil
+}
+
+// getApprovals returns the stored approvals of the definitions with the sequence of the definition,
+// by key. The approvals are read one by one after being looked up, for them to be recorded as
+// reads of the transaction, which the validators evaluate again against the policy
+func getApprovals(stub shim.ChaincodeStubInterface, def *lc.ChaincodeDefinition) (map[string][]byte, error) {
+	start, end := ApprovalsRange(def.Name, def.Sequence)
+	iter, err := stub.GetStateByRange(start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed retrieving approvals of chaincode %s: %s", def.Name, err)
+	}
+	defer iter.Close()
+	var keys []string
+	for iter.HasNext() {
+		kv, err := iter.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed retrieving approvals of chaincode %s: %s", def.Name, err)
+		}
+		keys = append(keys, kv.Key)
+	}
+	approvals := make(map[string][]byte, len(keys))
+	for _, key := range keys {
+		value, err := stub.GetState(key)
+		if err != nil {
+			return nil, fmt.Errorf("failed retrieving approval %s: %s", key, err)
+		}
+		approvals[key] = value
+	}
+	return approvals, nil
+}
+
+// channelOf returns the channel of the signed proposal
+func channelOf(sp *pb.SignedProposal) (string, error) {
+	if sp == nil {
+		return "", errors.New("nil signed proposal")
+	}
+	prop, err := utils.GetProposal(sp.ProposalBytes)
+	if err != nil {
+		return "", fmt.Errorf("invalid proposal: %s", err)
+	}
+	hdr, err := utils.GetHeader(prop.Header)
+	if err != nil {
+		return "", fmt.Errorf("invalid proposal header: %s", err)
+	}
+	chdr, err := utils.UnmarshalChannelHeader(hdr.ChannelHeader)
+	if err != nil {
+		return "", fmt.Errorf("invalid channel header: %s", err)
+	}
+	if chdr.ChannelId == "" {
+		return "", fmt.Errorf("%s can only be invoked on a channel", Name)
+	}
+	return chdr.ChannelId, nil
+}
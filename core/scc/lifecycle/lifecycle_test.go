@@ -0,0 +1,280 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package lifecycle
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/cauthdsl"
+	mockchannelconfig "github.com/hyperledger/fabric/common/mocks/config"
+	mockpolicies "github.com/hyperledger/fabric/common/mocks/policies"
+	"github.com/hyperledger/fabric/common/mocks/scc"
+	"github.com/hyperledger/fabric/common/policies"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	"github.com/hyperledger/fabric/core/common/sysccprovider"
+	cb "github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/msp"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	lc "github.com/hyperledger/fabric/protos/peer/lifecycle"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// orgsPolicy is satisfied by the signatures of at least n distinct orgs
+type orgsPolicy struct {
+	n int
+}
+
+func (p *orgsPolicy) Evaluate(signatureSet []*cb.SignedData) error {
+	orgs := make(map[string]struct{})
+	for _, sd := range signatureSet {
+		id := &msp.SerializedIdentity{}
+		if err := proto.Unmarshal(sd.Identity, id); err != nil {
+			return err
+		}
+		orgs[id.Mspid] = struct{}{}
+	}
+	if len(orgs) < p.n {
+		return fmt.Errorf("signed by %d orgs, %d required", len(orgs), p.n)
+	}
+	return nil
+}
+
+func newDefinition(name, version string, sequence int64) *lc.ChaincodeDefinition {
+	return &lc.ChaincodeDefinition{
+		Name:              name,
+		Version:           version,
+		Sequence:          sequence,
+		EndorsementPolicy: utils.MarshalOrPanic(cauthdsl.SignedByMspMember("Org1MSP")),
+	}
+}
+
+func collectionConfigs(blockToLive uint64, mspIDs []string, names ...string) *cb.CollectionConfigPackage {
+	collections := &cb.CollectionConfigPackage{}
+	for _, name := range names {
+		collections.Config = append(collections.Config, &cb.CollectionConfig{
+			Payload: &cb.CollectionConfig_StaticCollectionConfig{
+				StaticCollectionConfig: &cb.StaticCollectionConfig{
+					Name: name,
+					MemberOrgsPolicy: &cb.CollectionPolicyConfig{
+						Payload: &cb.CollectionPolicyConfig_SignaturePolicy{SignaturePolicy: cauthdsl.SignedByAnyMember(mspIDs)},
+					},
+					BlockToLive: blockToLive,
+				},
+			},
+		})
+	}
+	return collections
+}
+
+func signedProposal(t *testing.T, channelID, mspID, function string, arg []byte) *pb.SignedProposal {
+	creator := utils.MarshalOrPanic(&msp.SerializedIdentity{Mspid: mspID, IdBytes: []byte("cert of " + mspID)})
+	cis := &pb.ChaincodeInvocationSpec{ChaincodeSpec: &pb.ChaincodeSpec{
+		ChaincodeId: &pb.ChaincodeID{Name: Name},
+		Input:       &pb.ChaincodeInput{Args: [][]byte{[]byte(function), arg}},
+	}}
+	prop, _, err := utils.CreateChaincodeProposal(cb.HeaderType_ENDORSER_TRANSACTION, channelID, cis, creator)
+	require.NoError(t, err)
+	return &pb.SignedProposal{ProposalBytes: utils.MarshalOrPanic(prop), Signature: []byte("signature of " + mspID)}
+}
+
+func newLifecycleStub(pm policies.Manager) *shim.MockStub {
+	capabilities := &mockchannelconfig.ApplicationCapabilities{LifecycleVal: true}
+	sysccprovider.RegisterSystemChaincodeProviderFactory(&scc.MocksccProviderFactory{
+		PolicyManager:           pm,
+		ApplicationCapabilities: capabilities,
+		MSPIDs:                  []string{"Org1MSP", "Org2MSP"},
+	})
+	stub := shim.NewMockStub(Name, &Lifecycle{})
+	stub.MockInit("init", nil)
+	return stub
+}
+
+func invoke(t *testing.T, stub *shim.MockStub, mspID, function string, arg []byte) pb.Response {
+	sp := signedProposal(t, "mychannel", mspID, function, arg)
+	return stub.MockInvokeWithSignedProposal("tx", [][]byte{[]byte(function), arg}, sp)
+}
+
+func TestApproveAndCommit(t *testing.T) {
+	pm := &mockpolicies.Manager{PolicyMap: map[string]policies.Policy{LifecycleEndorsementPolicyName: &orgsPolicy{n: 2}}}
+	stub := newLifecycleStub(pm)
+	def := utils.MarshalOrPanic(newDefinition("mycc", "1.0", 1))
+
+	// A definition approved by a single org cannot be committed
+	res := invoke(t, stub, "Org1MSP", ApproveFuncName, def)
+	assert.Equal(t, int32(shim.OK), res.Status, res.Message)
+	res = invoke(t, stub, "Org1MSP", CommitFuncName, def)
+	assert.Equal(t, int32(shim.ERROR), res.Status)
+	assert.Contains(t, res.Message, "signed by 1 orgs, 2 required")
+
+	// The approval of another definition by the second org doesn't count
+	res = invoke(t, stub, "Org2MSP", ApproveFuncName, utils.MarshalOrPanic(newDefinition("mycc", "2.0", 1)))
+	assert.Equal(t, int32(shim.OK), res.Status, res.Message)
+	res = invoke(t, stub, "Org1MSP", QueryApprovalStatusFuncName, def)
+	assert.Equal(t, int32(shim.OK), res.Status, res.Message)
+	status := &lc.ApprovalStatus{}
+	require.NoError(t, proto.Unmarshal(res.Payload, status))
+	assert.Equal(t, []string{"Org1MSP"}, status.Approved)
+	res = invoke(t, stub, "Org1MSP", CommitFuncName, def)
+	assert.Equal(t, int32(shim.ERROR), res.Status)
+
+	// Until it approves the same definition
+	res = invoke(t, stub, "Org2MSP", ApproveFuncName, def)
+	assert.Equal(t, int32(shim.OK), res.Status, res.Message)
+	res = invoke(t, stub, "Org2MSP", CommitFuncName, def)
+	assert.Equal(t, int32(shim.OK), res.Status, res.Message)
+
+	res = invoke(t, stub, "Org1MSP", QueryDefinitionFuncName, []byte("mycc"))
+	assert.Equal(t, int32(shim.OK), res.Status, res.Message)
+	committed := &lc.ChaincodeDefinition{}
+	require.NoError(t, proto.Unmarshal(res.Payload, committed))
+	assert.True(t, proto.Equal(newDefinition("mycc", "1.0", 1), committed))
+	res = invoke(t, stub, "Org1MSP", QueryDefinitionFuncName, []byte("othercc"))
+	assert.Equal(t, int32(shim.ERROR), res.Status)
+
+	// The next definition has to follow the committed one
+	res = invoke(t, stub, "Org1MSP", ApproveFuncName, def)
+	assert.Equal(t, int32(shim.ERROR), res.Status)
+	assert.Contains(t, res.Message, "expected sequence 2 for chaincode mycc, got 1")
+	res = invoke(t, stub, "Org1MSP", CommitFuncName, def)
+	assert.Equal(t, int32(shim.ERROR), res.Status)
+	res = invoke(t, stub, "Org1MSP", ApproveFuncName, utils.MarshalOrPanic(newDefinition("mycc", "2.0", 2)))
+	assert.Equal(t, int32(shim.OK), res.Status, res.Message)
+}
+
+func TestCollections(t *testing.T) {
+	pm := &mockpolicies.Manager{PolicyMap: map[string]policies.Policy{LifecycleEndorsementPolicyName: &orgsPolicy{n: 2}}}
+	stub := newLifecycleStub(pm)
+	orgs := []string{"Org1MSP", "Org2MSP"}
+	defWithCollections := func(sequence int64, collections *cb.CollectionConfigPackage) *lc.ChaincodeDefinition {
+		def := newDefinition("mycc", "1.0", sequence)
+		def.Collections = collections
+		return def
+	}
+	approveAndCommit := func(def *lc.ChaincodeDefinition) pb.Response {
+		defBytes := utils.MarshalOrPanic(def)
+		for _, mspID := range orgs {
+			if res := invoke(t, stub, mspID, ApproveFuncName, defBytes); res.Status != shim.OK {
+				return res
+			}
+		}
+		return invoke(t, stub, "Org1MSP", CommitFuncName, defBytes)
+	}
+
+	// The approvals have to match on the collections of the definition
+	def := defWithCollections(1, collectionConfigs(10, orgs, "coll1"))
+	res := invoke(t, stub, "Org1MSP", ApproveFuncName, utils.MarshalOrPanic(def))
+	assert.Equal(t, int32(shim.OK), res.Status, res.Message)
+	res = invoke(t, stub, "Org2MSP", ApproveFuncName, utils.MarshalOrPanic(defWithCollections(1, collectionConfigs(10, orgs, "coll2"))))
+	assert.Equal(t, int32(shim.OK), res.Status, res.Message)
+	res = invoke(t, stub, "Org1MSP", QueryApprovalStatusFuncName, utils.MarshalOrPanic(def))
+	assert.Equal(t, int32(shim.OK), res.Status, res.Message)
+	status := &lc.ApprovalStatus{}
+	require.NoError(t, proto.Unmarshal(res.Payload, status))
+	assert.Equal(t, []string{"Org1MSP"}, status.Approved)
+	res = invoke(t, stub, "Org1MSP", CommitFuncName, utils.MarshalOrPanic(def))
+	assert.Equal(t, int32(shim.ERROR), res.Status)
+
+	// The committed definition carries the collections
+	res = approveAndCommit(def)
+	assert.Equal(t, int32(shim.OK), res.Status, res.Message)
+	res = invoke(t, stub, "Org1MSP", QueryDefinitionFuncName, []byte("mycc"))
+	assert.Equal(t, int32(shim.OK), res.Status, res.Message)
+	committed := &lc.ChaincodeDefinition{}
+	require.NoError(t, proto.Unmarshal(res.Payload, committed))
+	assert.True(t, proto.Equal(def, committed))
+
+	// The collections must be valid
+	res = invoke(t, stub, "Org1MSP", ApproveFuncName, utils.MarshalOrPanic(defWithCollections(2, collectionConfigs(10, orgs, "coll1", "coll1"))))
+	assert.Equal(t, int32(shim.ERROR), res.Status)
+	assert.Contains(t, res.Message, "invalid collections of chaincode mycc: collection coll1 defined more than once")
+
+	// Their members must be orgs of the channel
+	res = invoke(t, stub, "Org1MSP", ApproveFuncName, utils.MarshalOrPanic(defWithCollections(2, collectionConfigs(10, []string{"Org3MSP"}, "coll1"))))
+	assert.Equal(t, int32(shim.ERROR), res.Status)
+	assert.Contains(t, res.Message, "collection coll1 has member org Org3MSP which is not an org of the channel")
+
+	// And they cannot be removed, nor their block to live modified
+	res = invoke(t, stub, "Org1MSP", ApproveFuncName, utils.MarshalOrPanic(defWithCollections(2, nil)))
+	assert.Equal(t, int32(shim.ERROR), res.Status)
+	assert.Contains(t, res.Message, "collection coll1 cannot be removed upon upgrade")
+	res = invoke(t, stub, "Org1MSP", ApproveFuncName, utils.MarshalOrPanic(defWithCollections(2, collectionConfigs(20, orgs, "coll1"))))
+	assert.Equal(t, int32(shim.ERROR), res.Status)
+	assert.Contains(t, res.Message, "the block to live of collection coll1 cannot be modified upon upgrade")
+
+	// but collections can be added, and their members modified
+	res = approveAndCommit(defWithCollections(2, collectionConfigs(10, []string{"Org1MSP"}, "coll1", "coll2")))
+	assert.Equal(t, int32(shim.OK), res.Status, res.Message)
+}
+
+func TestCommitFallsBackToAdminsPolicy(t *testing.T) {
+	pm := &mockpolicies.Manager{PolicyMap: map[string]policies.Policy{
+		policies.ChannelApplicationAdmins: &mockpolicies.Policy{Err: errors.New("not admins")},
+	}}
+	stub := newLifecycleStub(pm)
+	def := utils.MarshalOrPanic(newDefinition("mycc", "1.0", 1))
+
+	res := invoke(t, stub, "Org1MSP", ApproveFuncName, def)
+	assert.Equal(t, int32(shim.OK), res.Status, res.Message)
+	res = invoke(t, stub, "Org1MSP", CommitFuncName, def)
+	assert.Equal(t, int32(shim.ERROR), res.Status)
+	assert.Contains(t, res.Message, policies.ChannelApplicationAdmins)
+	assert.Contains(t, res.Message, "not admins")
+}
+
+func TestInvalidInvocations(t *testing.T) {
+	stub := newLifecycleStub(&mockpolicies.Manager{})
+	def := newDefinition("mycc", "1.0", 1)
+
+	res := stub.MockInvokeWithSignedProposal("tx", [][]byte{[]byte(ApproveFuncName)}, nil)
+	assert.Equal(t, int32(shim.ERROR), res.Status)
+	res = invoke(t, stub, "Org1MSP", "foo", utils.MarshalOrPanic(def))
+	assert.Equal(t, int32(shim.ERROR), res.Status)
+	assert.Contains(t, res.Message, "invalid function foo")
+
+	// The approved definition must be the one of the proposal
+	sp := signedProposal(t, "mychannel", "Org1MSP", ApproveFuncName, utils.MarshalOrPanic(def))
+	res = stub.MockInvokeWithSignedProposal("tx", [][]byte{[]byte(ApproveFuncName), utils.MarshalOrPanic(newDefinition("mycc", "2.0", 1))}, sp)
+	assert.Equal(t, int32(shim.ERROR), res.Status)
+	assert.Contains(t, res.Message, "approved definition differs")
+
+	// The channel of the proposal must be set
+	sp = signedProposal(t, "", "Org1MSP", ApproveFuncName, utils.MarshalOrPanic(def))
+	res = stub.MockInvokeWithSignedProposal("tx", [][]byte{[]byte(ApproveFuncName), utils.MarshalOrPanic(def)}, sp)
+	assert.Equal(t, int32(shim.ERROR), res.Status)
+
+	for _, invalid := range []*lc.ChaincodeDefinition{
+		newDefinition("my cc", "1.0", 1),
+		newDefinition("mycc", "", 1),
+		newDefinition("mycc", "1.0", 0),
+		{Name: "mycc", Version: "1.0", Sequence: 1},
+		{Name: "mycc", Version: "1.0", Sequence: 1, EndorsementPolicy: []byte("garbage")},
+	} {
+		res = invoke(t, stub, "Org1MSP", ApproveFuncName, utils.MarshalOrPanic(invalid))
+		assert.Equal(t, int32(shim.ERROR), res.Status, "definition %v", invalid)
+	}
+
+	// The application capabilities of the channel must enable _lifecycle
+	sysccprovider.RegisterSystemChaincodeProviderFactory(&scc.MocksccProviderFactory{PolicyManager: &mockpolicies.Manager{}})
+	stub = shim.NewMockStub(Name, &Lifecycle{})
+	stub.MockInit("init", nil)
+	res = invoke(t, stub, "Org1MSP", ApproveFuncName, utils.MarshalOrPanic(def))
+	assert.Equal(t, int32(shim.ERROR), res.Status)
+	assert.Contains(t, res.Message, "is not enabled on channel mychannel")
+}
+
+func TestApprovalKeys(t *testing.T) {
+	assert.True(t, IsApprovalKey(ApprovalKey("mycc", 1, "Org1MSP"), "mycc", 1))
+	assert.False(t, IsApprovalKey(ApprovalKey("mycc", 10, "Org1MSP"), "mycc", 1))
+	assert.False(t, IsApprovalKey(ApprovalKey("mycc", 1, "Org1MSP"), "mycc", 10))
+	assert.False(t, IsApprovalKey(ApprovalKey("mycc2", 1, "Org1MSP"), "mycc", 1))
+	assert.False(t, IsApprovalKey(DefinitionKey("mycc"), "mycc", 1))
+}
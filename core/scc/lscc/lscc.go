@@ -17,24 +17,26 @@ limitations under the License.
 package lscc
 
 import (
+	"bytes"
 	"fmt"
 	"regexp"
 
 	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric/common/cauthdsl"
 	"github.com/hyperledger/fabric/common/flogging"
-	"github.com/hyperledger/fabric/common/policies"
+	"github.com/hyperledger/fabric/core/aclmgmt"
 	"github.com/hyperledger/fabric/core/chaincode/shim"
+	"github.com/hyperledger/fabric/core/common/ccpackage"
 	"github.com/hyperledger/fabric/core/common/ccprovider"
+	"github.com/hyperledger/fabric/core/common/privdata"
 	"github.com/hyperledger/fabric/core/common/sysccprovider"
 	"github.com/hyperledger/fabric/core/peer"
-	"github.com/hyperledger/fabric/core/policy"
-	"github.com/hyperledger/fabric/core/policyprovider"
-	"github.com/hyperledger/fabric/msp/mgmt"
+	"github.com/hyperledger/fabric/msp"
 	mspmgmt "github.com/hyperledger/fabric/msp/mgmt"
 	"github.com/hyperledger/fabric/protos/common"
 	pb "github.com/hyperledger/fabric/protos/peer"
 	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/spf13/viper"
 )
 
 //The life cycle system chaincode manages chaincodes deployed
@@ -80,6 +82,13 @@ const (
 	allowedCharsVersion       = "[A-Za-z0-9_.-]+"
 )
 
+// aclResources maps the channel query functions to the resources whose ACL is checked
+var aclResources = map[string]string{
+	GETCCINFO:  aclmgmt.LSCC_GETCCINFO,
+	GETDEPSPEC: aclmgmt.LSCC_GETDEPSPEC,
+	GETCCDATA:  aclmgmt.LSCC_GETCCDATA,
+}
+
 //---------- the LSCC -----------------
 
 // LifeCycleSysCC implements chaincode lifecycle and policies around it
@@ -89,9 +98,13 @@ type LifeCycleSysCC struct {
 	// import cycles
 	sccprovider sysccprovider.SystemChaincodeProvider
 
-	// policyChecker is the interface used to perform
+	// aclProvider is the interface used to perform
 	// access control
-	policyChecker policy.PolicyChecker
+	aclProvider aclmgmt.ACLProvider
+
+	// requireSignedPackage refuses to install the packages
+	// without owner endorsements
+	requireSignedPackage bool
 }
 
 //----------------errors---------------
@@ -124,6 +137,20 @@ func (i InvalidArgsErr) Error() string {
 	return fmt.Sprintf("invalid argument (%d) to lscc", int(i))
 }
 
+//UnsignedPackageErr package not signed error
+type UnsignedPackageErr string
+
+func (f UnsignedPackageErr) Error() string {
+	return fmt.Sprintf("chaincode package %s is not signed by its owners", string(f))
+}
+
+//InvalidPackageSignatureErr invalid package signature error
+type InvalidPackageSignatureErr string
+
+func (f InvalidPackageSignatureErr) Error() string {
+	return fmt.Sprintf("invalid owner endorsements of the chaincode package: %s", string(f))
+}
+
 //TXExistsErr transaction exists error
 type TXExistsErr string
 
@@ -229,6 +256,13 @@ func (f InstantiationPolicyViolatedErr) Error() string {
 	return fmt.Sprintf("chaincode instantiation policy violated(%s)", string(f))
 }
 
+//InvalidCollectionConfigErr invalid collection configuration error
+type InvalidCollectionConfigErr string
+
+func (f InvalidCollectionConfigErr) Error() string {
+	return string(f)
+}
+
 //InstantiationPolicyMissing when no existing instantiation policy is found when upgrading CC
 type InstantiationPolicyMissing string
 
@@ -238,13 +272,34 @@ func (f InstantiationPolicyMissing) Error() string {
 
 //-------------- helper functions ------------------
 //create the chaincode on the given chain
-func (lscc *LifeCycleSysCC) createChaincode(stub shim.ChaincodeStubInterface, cd *ccprovider.ChaincodeData) error {
-	return lscc.putChaincodeData(stub, cd)
+func (lscc *LifeCycleSysCC) createChaincode(stub shim.ChaincodeStubInterface, chainName string, cd *ccprovider.ChaincodeData, collectionConfigBytes []byte) error {
+	if err := lscc.checkCollectionConfigsSupported(chainName, collectionConfigBytes); err != nil {
+		return err
+	}
+	if err := validateCollectionConfigs(collectionConfigBytes); err != nil {
+		return err
+	}
+	if err := lscc.putChaincodeData(stub, cd); err != nil {
+		return err
+	}
+	return lscc.putChaincodeCollectionData(stub, cd, collectionConfigBytes)
 }
 
 //upgrade the chaincode on the given chain
-func (lscc *LifeCycleSysCC) upgradeChaincode(stub shim.ChaincodeStubInterface, cd *ccprovider.ChaincodeData) error {
-	return lscc.putChaincodeData(stub, cd)
+func (lscc *LifeCycleSysCC) upgradeChaincode(stub shim.ChaincodeStubInterface, chainName string, cd *ccprovider.ChaincodeData, collectionConfigBytes []byte) error {
+	if err := lscc.checkCollectionConfigsSupported(chainName, collectionConfigBytes); err != nil {
+		return err
+	}
+	if err := validateCollectionConfigs(collectionConfigBytes); err != nil {
+		return err
+	}
+	if err := lscc.validateCollectionConfigUpgrade(stub, chainName, cd.Name, collectionConfigBytes); err != nil {
+		return err
+	}
+	if err := lscc.putChaincodeData(stub, cd); err != nil {
+		return err
+	}
+	return lscc.putChaincodeCollectionData(stub, cd, collectionConfigBytes)
 }
 
 //create the chaincode on the given chain
@@ -271,6 +326,78 @@ func (lscc *LifeCycleSysCC) putChaincodeData(stub shim.ChaincodeStubInterface, c
 	return err
 }
 
+// putChaincodeCollectionData stores the collection configs of the chaincode, from which
+// the peers of the channel learn which organizations are members of its collections.
+// The chaincodes instantiated or upgraded without collection configs keep the
+// ones they already have, if any.
+func (lscc *LifeCycleSysCC) putChaincodeCollectionData(stub shim.ChaincodeStubInterface, cd *ccprovider.ChaincodeData, collectionConfigBytes []byte) error {
+	if len(collectionConfigBytes) == 0 {
+		return nil
+	}
+
+	return stub.PutState(privdata.BuildCollectionKVSKey(cd.Name), collectionConfigBytes)
+}
+
+// checkCollectionConfigsSupported returns an error if collection configs are supplied
+// before the application capabilities of the channel enable them, as the validators
+// of the channel would invalidate the transaction
+func (lscc *LifeCycleSysCC) checkCollectionConfigsSupported(chainName string, collectionConfigBytes []byte) error {
+	if len(collectionConfigBytes) == 0 {
+		return nil
+	}
+	if ac, exists := lscc.sccprovider.ApplicationCapabilities(chainName); !exists || !ac.CollectionUpgrade() {
+		return InvalidCollectionConfigErr(fmt.Sprintf("collection configs are not supported on channel %s, its application capabilities do not enable them", chainName))
+	}
+	return nil
+}
+
+// validateCollectionConfigs checks that the given bytes, if any, are a marshalled
+// CollectionConfigPackage of valid collection configs
+func validateCollectionConfigs(collectionConfigBytes []byte) error {
+	if len(collectionConfigBytes) == 0 {
+		return nil
+	}
+	collections := &common.CollectionConfigPackage{}
+	if err := proto.Unmarshal(collectionConfigBytes, collections); err != nil {
+		return InvalidCollectionConfigErr(fmt.Sprintf("invalid collection configuration supplied: %s", err))
+	}
+	if err := privdata.ValidateCollectionConfigs(collections); err != nil {
+		return InvalidCollectionConfigErr(err.Error())
+	}
+	return nil
+}
+
+// validateCollectionConfigUpgrade checks that the collection configs supplied, if any, upon the
+// upgrade of the chaincode may replace the ones it has, and that their members are orgs of the channel
+func (lscc *LifeCycleSysCC) validateCollectionConfigUpgrade(stub shim.ChaincodeStubInterface, chainName string, ccname string, collectionConfigBytes []byte) error {
+	if len(collectionConfigBytes) == 0 {
+		return nil
+	}
+	collections := &common.CollectionConfigPackage{}
+	if err := proto.Unmarshal(collectionConfigBytes, collections); err != nil {
+		return InvalidCollectionConfigErr(fmt.Sprintf("invalid collection configuration supplied: %s", err))
+	}
+	if err := privdata.ValidateCollectionMemberOrgs(collections, peer.GetMSPIDs(chainName)); err != nil {
+		return InvalidCollectionConfigErr(err.Error())
+	}
+
+	existingBytes, err := stub.GetState(privdata.BuildCollectionKVSKey(ccname))
+	if err != nil {
+		return TXNotFoundErr(err.Error())
+	}
+	if existingBytes == nil {
+		return nil
+	}
+	existing := &common.CollectionConfigPackage{}
+	if err = proto.Unmarshal(existingBytes, existing); err != nil {
+		return InvalidCollectionConfigErr(fmt.Sprintf("invalid collection configuration of chaincode %s: %s", ccname, err))
+	}
+	if err = privdata.ValidateCollectionConfigUpgrade(existing, collections); err != nil {
+		return InvalidCollectionConfigErr(err.Error())
+	}
+	return nil
+}
+
 //checks for existence of chaincode on the given channel
 func (lscc *LifeCycleSysCC) getCCInstance(stub shim.ChaincodeStubInterface, ccname string) ([]byte, error) {
 	cdbytes, err := stub.GetState(ccname)
@@ -470,6 +597,10 @@ func (lscc *LifeCycleSysCC) executeInstall(stub shim.ChaincodeStubInterface, ccb
 		return err
 	}
 
+	if err = lscc.verifyPackageSignatures(ccpack); err != nil {
+		return err
+	}
+
 	//everything checks out..lets write the package to the FS
 	if err = ccpack.PutChaincodeToFS(); err != nil {
 		return fmt.Errorf("Error installing chaincode code %s:%s(%s)", cds.ChaincodeSpec.ChaincodeId.Name, cds.ChaincodeSpec.ChaincodeId.Version, err)
@@ -478,6 +609,36 @@ func (lscc *LifeCycleSysCC) executeInstall(stub shim.ChaincodeStubInterface, ccb
 	return err
 }
 
+// verifyPackageSignatures verifies the owner endorsements of a SignedCDSPackage against
+// the admins of the MSPs known to the peer, so that a package tampered with after it was
+// signed is refused. Unsigned packages are refused if the peer requires signatures.
+func (lscc *LifeCycleSysCC) verifyPackageSignatures(ccpack ccprovider.CCPackage) error {
+	var sdepspec *pb.SignedChaincodeDeploymentSpec
+	if sccpack, isSccpack := ccpack.(*ccprovider.SignedCDSPackage); isSccpack {
+		sdepspec = sccpack.GetSignedDepSpec()
+	}
+
+	if sdepspec == nil || len(sdepspec.OwnerEndorsements) == 0 {
+		if lscc.requireSignedPackage {
+			id := ccpack.GetDepSpec().ChaincodeSpec.ChaincodeId
+			return UnsignedPackageErr(id.Name + ":" + id.Version)
+		}
+		return nil
+	}
+
+	// the package is installed on no channel, the endorsers may belong to the
+	// local MSP or to any MSP of the channels the peer joined
+	deserializers := []msp.IdentityDeserializer{mspmgmt.GetLocalMSP()}
+	for _, deserializer := range mspmgmt.GetDeserializers() {
+		deserializers = append(deserializers, deserializer)
+	}
+
+	if err := ccpackage.VerifyOwnerEndorsements(sdepspec, deserializers); err != nil {
+		return InvalidPackageSignatureErr(err.Error())
+	}
+	return nil
+}
+
 // getInstantiationPolicy retrieves the instantiation policy from a SignedCDSPackage
 func (lscc *LifeCycleSysCC) getInstantiationPolicy(channel string, ccpack ccprovider.CCPackage) ([]byte, error) {
 	var ip []byte
@@ -504,6 +665,20 @@ func (lscc *LifeCycleSysCC) getInstantiationPolicy(channel string, ccpack ccprov
 	return ip, nil
 }
 
+// checkInstalledPackage returns an error if the deployment spec carries a code package
+// which is not the one of the chaincode package installed on this peer. The chaincode data
+// is built from the installed package, so that the code endorsed is the code installed
+func (lscc *LifeCycleSysCC) checkInstalledPackage(cds *pb.ChaincodeDeploymentSpec, ccpack ccprovider.CCPackage) error {
+	if len(cds.CodePackage) == 0 {
+		return nil
+	}
+	installed := ccpack.GetDepSpec()
+	if installed == nil || !bytes.Equal(cds.CodePackage, installed.CodePackage) {
+		return InvalidCCOnFSError(fmt.Sprintf("code package of %s:%s differs from the installed package", cds.ChaincodeSpec.ChaincodeId.Name, cds.ChaincodeSpec.ChaincodeId.Version))
+	}
+	return nil
+}
+
 // checkInstantiationPolicy evaluates an instantiation policy against a signed proposal
 func (lscc *LifeCycleSysCC) checkInstantiationPolicy(stub shim.ChaincodeStubInterface, chainName string, instantiationPolicy []byte) error {
 	// create a policy object from the policy bytes
@@ -548,7 +723,7 @@ func (lscc *LifeCycleSysCC) checkInstantiationPolicy(stub shim.ChaincodeStubInte
 }
 
 // executeDeploy implements the "instantiate" Invoke transaction
-func (lscc *LifeCycleSysCC) executeDeploy(stub shim.ChaincodeStubInterface, chainname string, depSpec []byte, policy []byte, escc []byte, vscc []byte) (*ccprovider.ChaincodeData, error) {
+func (lscc *LifeCycleSysCC) executeDeploy(stub shim.ChaincodeStubInterface, chainname string, depSpec []byte, policy []byte, escc []byte, vscc []byte, collectionConfigBytes []byte) (*ccprovider.ChaincodeData, error) {
 	cds, err := utils.GetChaincodeDeploymentSpec(depSpec)
 
 	if err != nil {
@@ -579,6 +754,10 @@ func (lscc *LifeCycleSysCC) executeDeploy(stub shim.ChaincodeStubInterface, chai
 		return nil, fmt.Errorf("cannot get package for the chaincode to be instantiated (%s:%s)-%s", cds.ChaincodeSpec.ChaincodeId.Name, cds.ChaincodeSpec.ChaincodeId.Version, err)
 	}
 
+	if err = lscc.checkInstalledPackage(cds, ccpack); err != nil {
+		return nil, err
+	}
+
 	//this is guarantees to be not nil
 	cd := ccpack.GetChaincodeData()
 
@@ -597,13 +776,13 @@ func (lscc *LifeCycleSysCC) executeDeploy(stub shim.ChaincodeStubInterface, chai
 		return nil, err
 	}
 
-	err = lscc.createChaincode(stub, cd)
+	err = lscc.createChaincode(stub, chainname, cd, collectionConfigBytes)
 
 	return cd, err
 }
 
 // executeUpgrade implements the "upgrade" Invoke transaction.
-func (lscc *LifeCycleSysCC) executeUpgrade(stub shim.ChaincodeStubInterface, chainName string, depSpec []byte, policy []byte, escc []byte, vscc []byte) (*ccprovider.ChaincodeData, error) {
+func (lscc *LifeCycleSysCC) executeUpgrade(stub shim.ChaincodeStubInterface, chainName string, depSpec []byte, policy []byte, escc []byte, vscc []byte, collectionConfigBytes []byte) (*ccprovider.ChaincodeData, error) {
 	cds, err := utils.GetChaincodeDeploymentSpec(depSpec)
 	if err != nil {
 		return nil, err
@@ -655,6 +834,10 @@ func (lscc *LifeCycleSysCC) executeUpgrade(stub shim.ChaincodeStubInterface, cha
 		return nil, fmt.Errorf("cannot get package for the chaincode to be upgraded (%s:%s)-%s", chaincodeName, cds.ChaincodeSpec.ChaincodeId.Version, err)
 	}
 
+	if err = lscc.checkInstalledPackage(cds, ccpack); err != nil {
+		return nil, err
+	}
+
 	//get the new cd to upgrade to this is guaranteed to be not nil
 	cd = ccpack.GetChaincodeData()
 
@@ -673,7 +856,7 @@ func (lscc *LifeCycleSysCC) executeUpgrade(stub shim.ChaincodeStubInterface, cha
 		return nil, err
 	}
 
-	err = lscc.upgradeChaincode(stub, cd)
+	err = lscc.upgradeChaincode(stub, chainName, cd, collectionConfigBytes)
 	if err != nil {
 		return nil, err
 	}
@@ -687,8 +870,10 @@ func (lscc *LifeCycleSysCC) executeUpgrade(stub shim.ChaincodeStubInterface, cha
 func (lscc *LifeCycleSysCC) Init(stub shim.ChaincodeStubInterface) pb.Response {
 	lscc.sccprovider = sysccprovider.GetSystemChaincodeProvider()
 
-	// Init policy checker for access control
-	lscc.policyChecker = policyprovider.GetPolicyChecker()
+	// Init ACL provider for access control
+	lscc.aclProvider = aclmgmt.GetACLProvider()
+
+	lscc.requireSignedPackage = viper.GetBool("chaincode.package.requiresignature")
 
 	return shim.Success(nil)
 }
@@ -719,8 +904,8 @@ func (lscc *LifeCycleSysCC) Invoke(stub shim.ChaincodeStubInterface) pb.Response
 			return shim.Error(InvalidArgsLenErr(len(args)).Error())
 		}
 
-		// 2. check local MSP Admins policy
-		if err = lscc.policyChecker.CheckPolicyNoChannel(mgmt.Admins, sp); err != nil {
+		// 2. check the ACL of installing, the local MSP Admins policy by default
+		if err = lscc.aclProvider.CheckACL(aclmgmt.LSCC_INSTALL, "", sp); err != nil {
 			return shim.Error(fmt.Sprintf("Authorization for INSTALL has been denied (error-%s)", err))
 		}
 
@@ -732,7 +917,7 @@ func (lscc *LifeCycleSysCC) Invoke(stub shim.ChaincodeStubInterface) pb.Response
 		}
 		return shim.Success([]byte("OK"))
 	case DEPLOY:
-		if len(args) < 3 || len(args) > 6 {
+		if len(args) < 3 || len(args) > 7 {
 			return shim.Error(InvalidArgsLenErr(len(args)).Error())
 		}
 
@@ -753,6 +938,7 @@ func (lscc *LifeCycleSysCC) Invoke(stub shim.ChaincodeStubInterface) pb.Response
 		// args[3] is a marshalled SignaturePolicyEnvelope representing the endorsement policy
 		// args[4] is the name of escc
 		// args[5] is the name of vscc
+		// args[6] is a marshalled CollectionConfigPackage
 		var policy []byte
 		if len(args) > 3 && len(args[3]) > 0 {
 			policy = args[3]
@@ -778,7 +964,12 @@ func (lscc *LifeCycleSysCC) Invoke(stub shim.ChaincodeStubInterface) pb.Response
 			vscc = []byte("vscc")
 		}
 
-		cd, err := lscc.executeDeploy(stub, chainname, depSpec, policy, escc, vscc)
+		var collectionsConfig []byte
+		if len(args) > 6 {
+			collectionsConfig = args[6]
+		}
+
+		cd, err := lscc.executeDeploy(stub, chainname, depSpec, policy, escc, vscc, collectionsConfig)
 		if err != nil {
 			return shim.Error(err.Error())
 		}
@@ -788,7 +979,7 @@ func (lscc *LifeCycleSysCC) Invoke(stub shim.ChaincodeStubInterface) pb.Response
 		}
 		return shim.Success(cdbytes)
 	case UPGRADE:
-		if len(args) < 3 || len(args) > 6 {
+		if len(args) < 3 || len(args) > 7 {
 			return shim.Error(InvalidArgsLenErr(len(args)).Error())
 		}
 
@@ -806,6 +997,7 @@ func (lscc *LifeCycleSysCC) Invoke(stub shim.ChaincodeStubInterface) pb.Response
 		// args[3] is a marshalled SignaturePolicyEnvelope representing the endorsement policy
 		// args[4] is the name of escc
 		// args[5] is the name of vscc
+		// args[6] is a marshalled CollectionConfigPackage
 		var policy []byte
 		if len(args) > 3 && len(args[3]) > 0 {
 			policy = args[3]
@@ -831,7 +1023,12 @@ func (lscc *LifeCycleSysCC) Invoke(stub shim.ChaincodeStubInterface) pb.Response
 			vscc = []byte("vscc")
 		}
 
-		cd, err := lscc.executeUpgrade(stub, chainname, depSpec, policy, escc, vscc)
+		var collectionsConfig []byte
+		if len(args) > 6 {
+			collectionsConfig = args[6]
+		}
+
+		cd, err := lscc.executeUpgrade(stub, chainname, depSpec, policy, escc, vscc, collectionsConfig)
 		if err != nil {
 			return shim.Error(err.Error())
 		}
@@ -848,10 +1045,10 @@ func (lscc *LifeCycleSysCC) Invoke(stub shim.ChaincodeStubInterface) pb.Response
 		chain := string(args[1])
 		ccname := string(args[2])
 
-		// 2. check local Channel Readers policy
+		// 2. check the ACL of the function, the channel Readers policy by default
 		// Notice that this information are already available on the ledger
 		// therefore we enforce here that the caller is reader of the channel.
-		if err = lscc.policyChecker.CheckPolicy(chain, policies.ChannelApplicationReaders, sp); err != nil {
+		if err = lscc.aclProvider.CheckACL(aclResources[function], chain, sp); err != nil {
 			return shim.Error(fmt.Sprintf("Authorization for %s on channel %s has been denied with error %s", function, args[1], err))
 		}
 
@@ -882,8 +1079,8 @@ func (lscc *LifeCycleSysCC) Invoke(stub shim.ChaincodeStubInterface) pb.Response
 			return shim.Error(InvalidArgsLenErr(len(args)).Error())
 		}
 
-		// 2. check local MSP Admins policy
-		if err = lscc.policyChecker.CheckPolicyNoChannel(mgmt.Admins, sp); err != nil {
+		// 2. check the ACL of listing the chaincodes, the local MSP Admins policy by default
+		if err = lscc.aclProvider.CheckACL(aclmgmt.LSCC_GETCHAINCODES, "", sp); err != nil {
 			return shim.Error(fmt.Sprintf("Authorization for GETCHAINCODES on channel %s has been denied with error %s", args[0], err))
 		}
 
@@ -893,8 +1090,8 @@ func (lscc *LifeCycleSysCC) Invoke(stub shim.ChaincodeStubInterface) pb.Response
 			return shim.Error(InvalidArgsLenErr(len(args)).Error())
 		}
 
-		// 2. check local MSP Admins policy
-		if err = lscc.policyChecker.CheckPolicyNoChannel(mgmt.Admins, sp); err != nil {
+		// 2. check the ACL of listing the installed chaincodes, the local MSP Admins policy by default
+		if err = lscc.aclProvider.CheckACL(aclmgmt.LSCC_GETINSTALLEDCHAINCODES, "", sp); err != nil {
 			return shim.Error(fmt.Sprintf("Authorization for GETINSTALLEDCHAINCODES on channel %s has been denied with error %s", args[0], err))
 		}
 
@@ -27,12 +27,15 @@ import (
 
 	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric/common/cauthdsl"
+	mockchannelconfig "github.com/hyperledger/fabric/common/mocks/config"
 	"github.com/hyperledger/fabric/common/mocks/scc"
 	"github.com/hyperledger/fabric/common/policies"
 	"github.com/hyperledger/fabric/common/util"
+	"github.com/hyperledger/fabric/core/aclmgmt"
 	"github.com/hyperledger/fabric/core/chaincode/shim"
 	"github.com/hyperledger/fabric/core/common/ccpackage"
 	"github.com/hyperledger/fabric/core/common/ccprovider"
+	"github.com/hyperledger/fabric/core/common/privdata"
 	"github.com/hyperledger/fabric/core/common/sysccprovider"
 	cutil "github.com/hyperledger/fabric/core/container/util"
 	"github.com/hyperledger/fabric/core/peer"
@@ -105,11 +108,11 @@ func testInstall(t *testing.T, ccname string, version string, path string, expec
 			"test": &policymocks.MockChannelPolicyManager{MockPolicy: &policymocks.MockPolicy{Deserializer: identityDeserializer}},
 		},
 	}
-	scc.policyChecker = policy.NewPolicyChecker(
+	scc.aclProvider = aclmgmt.NewDefaultACLProviderWithPolicyChecker(policy.NewPolicyChecker(
 		policyManagerGetter,
 		identityDeserializer,
 		&policymocks.MockMSPPrincipalGetter{Principal: []byte("Alice")},
-	)
+	))
 
 	cds, err := constructDeploymentSpec(ccname, path, version, [][]byte{[]byte("init"), []byte("a"), []byte("100"), []byte("b"), []byte("200")}, false)
 	if err != nil {
@@ -174,6 +177,83 @@ func testInstall(t *testing.T, ccname string, version string, path string, expec
 	}
 }
 
+//TestInstallSignedPackage tests the verification of the owner endorsements of the installed packages
+func TestInstallSignedPackage(t *testing.T) {
+	scc := new(LifeCycleSysCC)
+	stub := shim.NewMockStub("lscc", scc)
+
+	if res := stub.MockInit("1", nil); res.Status != shim.OK {
+		fmt.Println("Init failed", string(res.Message))
+		t.FailNow()
+	}
+
+	identityDeserializer := &policymocks.MockIdentityDeserializer{[]byte("Alice"), []byte("msg1")}
+	policyManagerGetter := &policymocks.MockChannelPolicyManagerGetter{
+		Managers: map[string]policies.Manager{
+			"test": &policymocks.MockChannelPolicyManager{MockPolicy: &policymocks.MockPolicy{Deserializer: identityDeserializer}},
+		},
+	}
+	scc.aclProvider = aclmgmt.NewDefaultACLProviderWithPolicyChecker(policy.NewPolicyChecker(
+		policyManagerGetter,
+		identityDeserializer,
+		&policymocks.MockMSPPrincipalGetter{Principal: []byte("Alice")},
+	))
+	sProp, _ := utils.MockSignedEndorserProposalOrPanic("", &pb.ChaincodeSpec{}, []byte("Alice"), []byte("msg1"))
+	identityDeserializer.Msg = sProp.ProposalBytes
+	sProp.Signature = sProp.ProposalBytes
+
+	install := func(version string, owner msp.SigningIdentity, tamper bool) pb.Response {
+		cds, err := constructDeploymentSpec("example02", "github.com/hyperledger/fabric/examples/chaincode/go/chaincode_example02", version, [][]byte{[]byte("init")}, false)
+		assert.NoError(t, err)
+		env, err := ccpackage.OwnerCreateSignedCCDepSpec(cds, cauthdsl.SignedByMspAdmin(mspid), owner)
+		assert.NoError(t, err)
+
+		if tamper {
+			_, sdepspec, err := ccpackage.ExtractSignedCCDepSpec(env)
+			assert.NoError(t, err)
+			cds.CodePackage = append(cds.CodePackage, []byte("tampered")...)
+			env, err = ccpackage.OwnerCreateSignedCCDepSpec(cds, cauthdsl.SignedByMspAdmin(mspid), nil)
+			assert.NoError(t, err)
+			_, tampered, err := ccpackage.ExtractSignedCCDepSpec(env)
+			assert.NoError(t, err)
+			tampered.OwnerEndorsements = sdepspec.OwnerEndorsements
+			payload := &common.Payload{}
+			assert.NoError(t, proto.Unmarshal(env.Payload, payload))
+			payload.Data = utils.MarshalOrPanic(tampered)
+			env.Payload = utils.MarshalOrPanic(payload)
+		}
+
+		return stub.MockInvokeWithSignedProposal("1", [][]byte{[]byte(INSTALL), utils.MarshalOrPanic(env)}, sProp)
+	}
+
+	signer, err := mspmgmt.GetLocalMSP().GetDefaultSigningIdentity()
+	assert.NoError(t, err)
+
+	// signed by an admin of the local MSP
+	defer os.Remove(lscctestpath + "/example02.0")
+	res := install("0", signer, false)
+	assert.Equal(t, int32(shim.OK), res.Status, res.Message)
+
+	// the code package was tampered with after it was signed
+	res = install("1", signer, true)
+	assert.NotEqual(t, int32(shim.OK), res.Status)
+	assert.Contains(t, res.Message, "invalid owner endorsements of the chaincode package")
+
+	// unsigned packages are installed unless signatures are required
+	defer os.Remove(lscctestpath + "/example02.2")
+	res = install("2", nil, false)
+	assert.Equal(t, int32(shim.OK), res.Status, res.Message)
+
+	scc.requireSignedPackage = true
+	res = install("3", nil, false)
+	assert.NotEqual(t, int32(shim.OK), res.Status)
+	assert.Equal(t, UnsignedPackageErr("example02:3").Error(), res.Message)
+
+	defer os.Remove(lscctestpath + "/example02.4")
+	res = install("4", signer, false)
+	assert.Equal(t, int32(shim.OK), res.Status, res.Message)
+}
+
 //TestReinstall tests the install function
 func TestReinstall(t *testing.T) {
 	scc := new(LifeCycleSysCC)
@@ -259,11 +339,11 @@ func testDeploy(t *testing.T, ccname string, version string, path string, forceB
 			"test": &policymocks.MockChannelPolicyManager{MockPolicy: &policymocks.MockPolicy{Deserializer: identityDeserializer}},
 		},
 	}
-	scc.policyChecker = policy.NewPolicyChecker(
+	scc.aclProvider = aclmgmt.NewDefaultACLProviderWithPolicyChecker(policy.NewPolicyChecker(
 		policyManagerGetter,
 		identityDeserializer,
 		&policymocks.MockMSPPrincipalGetter{Principal: []byte("Alice")},
-	)
+	))
 	sProp, _ := utils.MockSignedEndorserProposalOrPanic("", &pb.ChaincodeSpec{}, []byte("Alice"), []byte("msg1"))
 	identityDeserializer.Msg = sProp.ProposalBytes
 	sProp.Signature = sProp.ProposalBytes
@@ -371,6 +451,138 @@ func TestRedeploy(t *testing.T) {
 	}
 }
 
+//TestDeployPackageMismatch tests that the code package of the deployment spec must be the installed one
+func TestDeployPackageMismatch(t *testing.T) {
+	scc := new(LifeCycleSysCC)
+	stub := shim.NewMockStub("lscc", scc)
+
+	if res := stub.MockInit("1", nil); res.Status != shim.OK {
+		fmt.Println("Init failed", string(res.Message))
+		t.FailNow()
+	}
+
+	path := "github.com/hyperledger/fabric/examples/chaincode/go/chaincode_example02"
+	_, err := constructDeploymentSpec("example02", path, "0", [][]byte{[]byte("init")}, true)
+	assert.NoError(t, err)
+	defer os.Remove(lscctestpath + "/example02.0")
+	_, err = constructDeploymentSpec("example02", path, "1", [][]byte{[]byte("init")}, true)
+	assert.NoError(t, err)
+	defer os.Remove(lscctestpath + "/example02.1")
+
+	// deployment specs of the installed name and version, with another code package
+	tampered := func(version string) []byte {
+		cds, err := constructDeploymentSpec("example02", path+"/tampered", version, [][]byte{[]byte("init")}, false)
+		assert.NoError(t, err)
+		return putils.MarshalOrPanic(cds)
+	}
+	installed := func(version string) []byte {
+		cds, err := constructDeploymentSpec("example02", path, version, [][]byte{[]byte("init")}, false)
+		assert.NoError(t, err)
+		return putils.MarshalOrPanic(cds)
+	}
+
+	sProp, _ := putils.MockSignedEndorserProposal2OrPanic(chainid, &pb.ChaincodeSpec{}, id)
+	res := stub.MockInvokeWithSignedProposal("1", [][]byte{[]byte(DEPLOY), []byte("test"), tampered("0")}, sProp)
+	assert.NotEqual(t, int32(shim.OK), res.Status)
+	assert.Contains(t, res.Message, InvalidCCOnFSError("").Error())
+
+	res = stub.MockInvokeWithSignedProposal("1", [][]byte{[]byte(DEPLOY), []byte("test"), installed("0")}, sProp)
+	assert.Equal(t, int32(shim.OK), res.Status, res.Message)
+
+	res = stub.MockInvokeWithSignedProposal("1", [][]byte{[]byte(UPGRADE), []byte("test"), tampered("1")}, sProp)
+	assert.NotEqual(t, int32(shim.OK), res.Status)
+	assert.Contains(t, res.Message, InvalidCCOnFSError("").Error())
+
+	res = stub.MockInvokeWithSignedProposal("1", [][]byte{[]byte(UPGRADE), []byte("test"), installed("1")}, sProp)
+	assert.Equal(t, int32(shim.OK), res.Status, res.Message)
+}
+
+//TestDeployWithCollectionConfigs tests deploying a chaincode along with the configs of its collections
+func TestDeployWithCollectionConfigs(t *testing.T) {
+	capabilities := &mockchannelconfig.ApplicationCapabilities{}
+	sysccprovider.RegisterSystemChaincodeProviderFactory(&scc.MocksccProviderFactory{ApplicationCapabilities: capabilities})
+	defer sysccprovider.RegisterSystemChaincodeProviderFactory(&scc.MocksccProviderFactory{})
+
+	scc := new(LifeCycleSysCC)
+	stub := shim.NewMockStub("lscc", scc)
+
+	if res := stub.MockInit("1", nil); res.Status != shim.OK {
+		fmt.Println("Init failed", string(res.Message))
+		t.FailNow()
+	}
+
+	cds, err := constructDeploymentSpec("example02", "github.com/hyperledger/fabric/examples/chaincode/go/chaincode_example02", "0", [][]byte{[]byte("init"), []byte("a"), []byte("100"), []byte("b"), []byte("200")}, true)
+	assert.NoError(t, err)
+	defer os.Remove(lscctestpath + "/example02.0")
+	b := utils.MarshalOrPanic(cds)
+
+	collections := utils.MarshalOrPanic(&common.CollectionConfigPackage{
+		Config: []*common.CollectionConfig{testCollectionConfig("coll1", 1, 2)},
+	})
+
+	// collection configs are refused until the application capabilities enable them
+	sProp, _ := putils.MockSignedEndorserProposal2OrPanic(chainid, &pb.ChaincodeSpec{}, id)
+	args := [][]byte{[]byte(DEPLOY), []byte("test"), b, nil, nil, nil, collections}
+	res := stub.MockInvokeWithSignedProposal("1", args, sProp)
+	assert.NotEqual(t, int32(shim.OK), res.Status)
+	assert.Contains(t, res.Message, "collection configs are not supported")
+	capabilities.CollectionUpgradeVal = true
+
+	// invalid collection configs are refused
+	args = [][]byte{[]byte(DEPLOY), []byte("test"), b, nil, nil, nil, []byte("barf")}
+	res = stub.MockInvokeWithSignedProposal("1", args, sProp)
+	assert.NotEqual(t, int32(shim.OK), res.Status)
+	assert.Contains(t, res.Message, "invalid collection configuration supplied")
+
+	args = [][]byte{[]byte(DEPLOY), []byte("test"), b, nil, nil, nil, collections}
+	res = stub.MockInvokeWithSignedProposal("1", args, sProp)
+	assert.Equal(t, int32(shim.OK), res.Status, res.Message)
+
+	stored, err := stub.GetState(privdata.BuildCollectionKVSKey("example02"))
+	assert.NoError(t, err)
+	assert.Equal(t, collections, stored)
+}
+
+func testCollectionConfig(name string, required, maximum int32) *common.CollectionConfig {
+	return &common.CollectionConfig{
+		Payload: &common.CollectionConfig_StaticCollectionConfig{
+			StaticCollectionConfig: &common.StaticCollectionConfig{
+				Name: name,
+				MemberOrgsPolicy: &common.CollectionPolicyConfig{
+					Payload: &common.CollectionPolicyConfig_SignaturePolicy{
+						SignaturePolicy: cauthdsl.SignedByAnyMember([]string{"Org1MSP", "Org2MSP"}),
+					},
+				},
+				RequiredPeerCount: required,
+				MaximumPeerCount:  maximum,
+			},
+		},
+	}
+}
+
+func TestValidateCollectionConfigs(t *testing.T) {
+	validate := func(configs ...*common.CollectionConfig) error {
+		return validateCollectionConfigs(utils.MarshalOrPanic(&common.CollectionConfigPackage{Config: configs}))
+	}
+
+	assert.NoError(t, validate(testCollectionConfig("coll1", 0, 0), testCollectionConfig("coll2", 1, 3)))
+
+	// no collection configs
+	assert.NoError(t, validateCollectionConfigs(nil))
+	assert.EqualError(t, validate(testCollectionConfig("", 1, 2)), "collection name not set")
+	assert.EqualError(t, validate(testCollectionConfig("coll1", 1, 2), testCollectionConfig("coll1", 1, 2)),
+		"collection coll1 defined more than once")
+	assert.EqualError(t, validate(testCollectionConfig("coll1", -1, 2)), "collection coll1 has a negative required peer count")
+	assert.EqualError(t, validate(testCollectionConfig("coll1", 3, 2)),
+		"collection coll1 has a maximum peer count (2) lower than its required peer count (3)")
+
+	noPolicy := testCollectionConfig("coll1", 1, 2)
+	noPolicy.GetStaticCollectionConfig().MemberOrgsPolicy = nil
+	assert.EqualError(t, validate(noPolicy), "collection coll1 has no member orgs policy")
+
+	assert.EqualError(t, validate(&common.CollectionConfig{}), "unknown collection configuration type <nil>")
+}
+
 //TestMultipleDeploy tests deploying multiple chaincodeschaincodes
 func TestMultipleDeploy(t *testing.T) {
 	scc := new(LifeCycleSysCC)
@@ -388,11 +600,11 @@ func TestMultipleDeploy(t *testing.T) {
 			"test": &policymocks.MockChannelPolicyManager{MockPolicy: &policymocks.MockPolicy{Deserializer: identityDeserializer}},
 		},
 	}
-	scc.policyChecker = policy.NewPolicyChecker(
+	scc.aclProvider = aclmgmt.NewDefaultACLProviderWithPolicyChecker(policy.NewPolicyChecker(
 		policyManagerGetter,
 		identityDeserializer,
 		&policymocks.MockMSPPrincipalGetter{Principal: []byte("Alice")},
-	)
+	))
 	sProp, _ := utils.MockSignedEndorserProposalOrPanic("", &pb.ChaincodeSpec{}, []byte("Alice"), []byte("msg1"))
 	identityDeserializer.Msg = sProp.ProposalBytes
 	sProp.Signature = sProp.ProposalBytes
@@ -474,11 +686,11 @@ func TestRetryFailedDeploy(t *testing.T) {
 			"test": &policymocks.MockChannelPolicyManager{MockPolicy: &policymocks.MockPolicy{Deserializer: identityDeserializer}},
 		},
 	}
-	scc.policyChecker = policy.NewPolicyChecker(
+	scc.aclProvider = aclmgmt.NewDefaultACLProviderWithPolicyChecker(policy.NewPolicyChecker(
 		policyManagerGetter,
 		identityDeserializer,
 		&policymocks.MockMSPPrincipalGetter{Principal: []byte("Alice")},
-	)
+	))
 	sProp, _ := utils.MockSignedEndorserProposalOrPanic("", &pb.ChaincodeSpec{}, []byte("Alice"), []byte("msg1"))
 	identityDeserializer.Msg = sProp.ProposalBytes
 	sProp.Signature = sProp.ProposalBytes
@@ -538,11 +750,11 @@ func TestTamperChaincode(t *testing.T) {
 			"test": &policymocks.MockChannelPolicyManager{MockPolicy: &policymocks.MockPolicy{Deserializer: identityDeserializer}},
 		},
 	}
-	scc.policyChecker = policy.NewPolicyChecker(
+	scc.aclProvider = aclmgmt.NewDefaultACLProviderWithPolicyChecker(policy.NewPolicyChecker(
 		policyManagerGetter,
 		identityDeserializer,
 		&policymocks.MockMSPPrincipalGetter{Principal: []byte("Alice")},
-	)
+	))
 	sProp, _ := utils.MockSignedEndorserProposalOrPanic("", &pb.ChaincodeSpec{}, []byte("Alice"), []byte("msg1"))
 	identityDeserializer.Msg = sProp.ProposalBytes
 	sProp.Signature = sProp.ProposalBytes
@@ -635,11 +847,11 @@ func TestIPolDeployDefaultFail(t *testing.T) {
 			chainid: &policymocks.MockChannelPolicyManager{MockPolicy: &policymocks.MockPolicy{Deserializer: identityDeserializer}},
 		},
 	}
-	scc.policyChecker = policy.NewPolicyChecker(
+	scc.aclProvider = aclmgmt.NewDefaultACLProviderWithPolicyChecker(policy.NewPolicyChecker(
 		policyManagerGetter,
 		identityDeserializer,
 		&policymocks.MockMSPPrincipalGetter{Principal: []byte("Alice")},
-	)
+	))
 	sProp, _ := utils.MockSignedEndorserProposalOrPanic("", &pb.ChaincodeSpec{}, []byte("Alice"), []byte("msg1"))
 	identityDeserializer.Msg = sProp.ProposalBytes
 	sProp.Signature = sProp.ProposalBytes
@@ -681,11 +893,11 @@ func testIPolDeploy(t *testing.T, iPol string, successExpected bool) {
 			chainid: &policymocks.MockChannelPolicyManager{MockPolicy: &policymocks.MockPolicy{Deserializer: identityDeserializer}},
 		},
 	}
-	scc.policyChecker = policy.NewPolicyChecker(
+	scc.aclProvider = aclmgmt.NewDefaultACLProviderWithPolicyChecker(policy.NewPolicyChecker(
 		policyManagerGetter,
 		identityDeserializer,
 		&policymocks.MockMSPPrincipalGetter{Principal: []byte("Alice")},
-	)
+	))
 	sProp, _ := utils.MockSignedEndorserProposalOrPanic("", &pb.ChaincodeSpec{}, []byte("Alice"), []byte("msg1"))
 	identityDeserializer.Msg = sProp.ProposalBytes
 	sProp.Signature = sProp.ProposalBytes
@@ -813,6 +1025,79 @@ func testUpgrade(t *testing.T, ccname string, version string, newccname string,
 	}
 }
 
+//TestUpgradeWithCollectionConfigs tests the updates of the collection configs of a chaincode upon its upgrade
+func TestUpgradeWithCollectionConfigs(t *testing.T) {
+	capabilities := &mockchannelconfig.ApplicationCapabilities{CollectionUpgradeVal: true}
+	sysccprovider.RegisterSystemChaincodeProviderFactory(&scc.MocksccProviderFactory{ApplicationCapabilities: capabilities})
+	defer sysccprovider.RegisterSystemChaincodeProviderFactory(&scc.MocksccProviderFactory{})
+
+	scc := new(LifeCycleSysCC)
+	stub := shim.NewMockStub("lscc", scc)
+
+	if res := stub.MockInit("1", nil); res.Status != shim.OK {
+		fmt.Println("Init failed", string(res.Message))
+		t.FailNow()
+	}
+
+	path := "github.com/hyperledger/fabric/examples/chaincode/go/chaincode_example02"
+	cds, err := constructDeploymentSpec("example02", path, "0", [][]byte{[]byte("init"), []byte("a"), []byte("100"), []byte("b"), []byte("200")}, true)
+	assert.NoError(t, err)
+	defer os.Remove(lscctestpath + "/example02.0")
+	newCds, err := constructDeploymentSpec("example02", path, "1", [][]byte{[]byte("init"), []byte("a"), []byte("100"), []byte("b"), []byte("200")}, true)
+	assert.NoError(t, err)
+	defer os.Remove(lscctestpath + "/example02.1")
+
+	collectionConfig := func(name string, blockToLive uint64, mspIDs ...string) *common.CollectionConfig {
+		c := testCollectionConfig(name, 1, 2)
+		c.GetStaticCollectionConfig().MemberOrgsPolicy.Payload = &common.CollectionPolicyConfig_SignaturePolicy{
+			SignaturePolicy: cauthdsl.SignedByAnyMember(mspIDs),
+		}
+		c.GetStaticCollectionConfig().BlockToLive = blockToLive
+		return c
+	}
+	collections := func(configs ...*common.CollectionConfig) []byte {
+		return utils.MarshalOrPanic(&common.CollectionConfigPackage{Config: configs})
+	}
+
+	sProp, _ := putils.MockSignedEndorserProposal2OrPanic(chainid, &pb.ChaincodeSpec{}, id)
+	deployed := collections(collectionConfig("coll1", 10, "DEFAULT"))
+	args := [][]byte{[]byte(DEPLOY), []byte("test"), utils.MarshalOrPanic(cds), nil, nil, nil, deployed}
+	res := stub.MockInvokeWithSignedProposal("1", args, sProp)
+	assert.Equal(t, int32(shim.OK), res.Status, res.Message)
+
+	upgrade := func(collectionConfigBytes []byte) pb.Response {
+		args := [][]byte{[]byte(UPGRADE), []byte("test"), utils.MarshalOrPanic(newCds), nil, nil, nil, collectionConfigBytes}
+		return stub.MockInvokeWithSignedProposal("1", args, sProp)
+	}
+
+	// a collection cannot be removed
+	res = upgrade(collections(collectionConfig("coll2", 0, "DEFAULT")))
+	assert.NotEqual(t, int32(shim.OK), res.Status)
+	assert.Equal(t, "collection coll1 cannot be removed upon upgrade", res.Message)
+
+	// nor can its block to live be modified
+	res = upgrade(collections(collectionConfig("coll1", 20, "DEFAULT")))
+	assert.NotEqual(t, int32(shim.OK), res.Status)
+	assert.Equal(t, "the block to live of collection coll1 cannot be modified upon upgrade (from 10 to 20)", res.Message)
+
+	// the members of the collections must be orgs of the channel
+	res = upgrade(collections(collectionConfig("coll1", 10, "DEFAULT", "Org3MSP")))
+	assert.NotEqual(t, int32(shim.OK), res.Status)
+	assert.Equal(t, "collection coll1 has member org Org3MSP which is not an org of the channel", res.Message)
+
+	stored, err := stub.GetState(privdata.BuildCollectionKVSKey("example02"))
+	assert.NoError(t, err)
+	assert.Equal(t, deployed, stored)
+
+	// collections may be added
+	upgraded := collections(collectionConfig("coll1", 10, "DEFAULT"), collectionConfig("coll2", 0, "DEFAULT"))
+	res = upgrade(upgraded)
+	assert.Equal(t, int32(shim.OK), res.Status, res.Message)
+	stored, err = stub.GetState(privdata.BuildCollectionKVSKey("example02"))
+	assert.NoError(t, err)
+	assert.Equal(t, upgraded, stored)
+}
+
 //TestIPolUpgrade tests chaincode deploy with an instantiation policy
 func TestIPolUpgrade(t *testing.T) {
 	// default policy, this should succeed
@@ -835,11 +1120,11 @@ func testIPolUpgrade(t *testing.T, iPol string, successExpected bool) {
 			chainid: &policymocks.MockChannelPolicyManager{MockPolicy: &policymocks.MockPolicy{Deserializer: identityDeserializer}},
 		},
 	}
-	scc.policyChecker = policy.NewPolicyChecker(
+	scc.aclProvider = aclmgmt.NewDefaultACLProviderWithPolicyChecker(policy.NewPolicyChecker(
 		policyManagerGetter,
 		identityDeserializer,
 		&policymocks.MockMSPPrincipalGetter{Principal: []byte("Alice")},
-	)
+	))
 	sProp, _ := utils.MockSignedEndorserProposalOrPanic("", &pb.ChaincodeSpec{}, []byte("Alice"), []byte("msg1"))
 	identityDeserializer.Msg = sProp.ProposalBytes
 	sProp.Signature = sProp.ProposalBytes
@@ -925,11 +1210,11 @@ func TestGetAPIsWithoutInstall(t *testing.T) {
 			"test": &policymocks.MockChannelPolicyManager{MockPolicy: &policymocks.MockPolicy{Deserializer: identityDeserializer}},
 		},
 	}
-	scc.policyChecker = policy.NewPolicyChecker(
+	scc.aclProvider = aclmgmt.NewDefaultACLProviderWithPolicyChecker(policy.NewPolicyChecker(
 		policyManagerGetter,
 		identityDeserializer,
 		&policymocks.MockMSPPrincipalGetter{Principal: []byte("Alice")},
-	)
+	))
 	sProp, _ := utils.MockSignedEndorserProposalOrPanic("", &pb.ChaincodeSpec{}, []byte("Alice"), []byte("msg1"))
 	identityDeserializer.Msg = sProp.ProposalBytes
 	sProp.Signature = sProp.ProposalBytes
@@ -1026,11 +1311,11 @@ func TestGetInstalledChaincodesAccessRights(t *testing.T) {
 			"test": &policymocks.MockChannelPolicyManager{MockPolicy: &policymocks.MockPolicy{Deserializer: identityDeserializer}},
 		},
 	}
-	scc.policyChecker = policy.NewPolicyChecker(
+	scc.aclProvider = aclmgmt.NewDefaultACLProviderWithPolicyChecker(policy.NewPolicyChecker(
 		policyManagerGetter,
 		identityDeserializer,
 		&policymocks.MockMSPPrincipalGetter{Principal: []byte("Alice")},
-	)
+	))
 
 	// Should pass
 	args := [][]byte{[]byte(GETINSTALLEDCHAINCODES)}
@@ -1070,11 +1355,11 @@ func TestGetChaincodesAccessRights(t *testing.T) {
 			"test": &policymocks.MockChannelPolicyManager{MockPolicy: &policymocks.MockPolicy{Deserializer: identityDeserializer}},
 		},
 	}
-	scc.policyChecker = policy.NewPolicyChecker(
+	scc.aclProvider = aclmgmt.NewDefaultACLProviderWithPolicyChecker(policy.NewPolicyChecker(
 		policyManagerGetter,
 		identityDeserializer,
 		&policymocks.MockMSPPrincipalGetter{Principal: []byte("Alice")},
-	)
+	))
 
 	// Should pass
 	args := [][]byte{[]byte(GETCHAINCODES)}
@@ -1114,11 +1399,11 @@ func TestGetCCAccessRights(t *testing.T) {
 			"test": &policymocks.MockChannelPolicyManager{MockPolicy: &policymocks.MockPolicy{Deserializer: identityDeserializer}},
 		},
 	}
-	scc.policyChecker = policy.NewPolicyChecker(
+	scc.aclProvider = aclmgmt.NewDefaultACLProviderWithPolicyChecker(policy.NewPolicyChecker(
 		policyManagerGetter,
 		identityDeserializer,
 		&policymocks.MockMSPPrincipalGetter{Principal: []byte("Alice")},
-	)
+	))
 
 	cds, err := constructDeploymentSpec("example02", "github.com/hyperledger/fabric/examples/chaincode/go/chaincode_example02", "0", [][]byte{[]byte("init"), []byte("a"), []byte("100"), []byte("b"), []byte("200")}, true)
 
@@ -1222,6 +1507,7 @@ func TestMain(m *testing.M) {
 
 	// setup the MSP manager so that we can sign/verify
 	msptesttools.LoadMSPSetupForTesting()
+	aclmgmt.RegisterACLProvider(nil)
 
 	id, err = mspmgmt.GetLocalMSP().GetDefaultSigningIdentity()
 	if err != nil {
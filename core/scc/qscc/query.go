@@ -21,13 +21,12 @@ import (
 	"strconv"
 
 	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/common/ledger/blkstorage"
 
-	"github.com/hyperledger/fabric/common/policies"
+	"github.com/hyperledger/fabric/core/aclmgmt"
 	"github.com/hyperledger/fabric/core/chaincode/shim"
 	"github.com/hyperledger/fabric/core/ledger"
 	"github.com/hyperledger/fabric/core/peer"
-	"github.com/hyperledger/fabric/core/policy"
-	"github.com/hyperledger/fabric/msp/mgmt"
 	pb "github.com/hyperledger/fabric/protos/peer"
 	"github.com/hyperledger/fabric/protos/utils"
 )
@@ -37,8 +36,10 @@ import (
 // - GetBlockByNumber returns a block
 // - GetBlockByHash returns a block
 // - GetTransactionByID returns a transaction
+// - GetBlocksByRange returns a page of the blocks of a range
+// - GetTransactionsByCreator returns a page of the transactions of a creator
 type LedgerQuerier struct {
-	policyChecker policy.PolicyChecker
+	aclProvider aclmgmt.ACLProvider
 }
 
 var qscclogger = flogging.MustGetLogger("qscc")
@@ -50,20 +51,38 @@ const (
 	GetBlockByHash     string = "GetBlockByHash"
 	GetTransactionByID string = "GetTransactionByID"
 	GetBlockByTxID     string = "GetBlockByTxID"
+
+	GetBlocksByRange         string = "GetBlocksByRange"
+	GetTransactionsByCreator string = "GetTransactionsByCreator"
+)
+
+// These bound the number of blocks or transactions returned by a page of the
+// paginated queries. A larger page size is lowered to the maximum
+const (
+	defaultPageSize = 10
+	maxPageSize     = 100
 )
 
+// aclResources maps the query functions to the resources whose ACL is checked
+var aclResources = map[string]string{
+	GetChainInfo:       aclmgmt.QSCC_GetChainInfo,
+	GetBlockByNumber:   aclmgmt.QSCC_GetBlockByNumber,
+	GetBlockByHash:     aclmgmt.QSCC_GetBlockByHash,
+	GetTransactionByID: aclmgmt.QSCC_GetTransactionByID,
+	GetBlockByTxID:     aclmgmt.QSCC_GetBlockByTxID,
+
+	GetBlocksByRange:         aclmgmt.QSCC_GetBlocksByRange,
+	GetTransactionsByCreator: aclmgmt.QSCC_GetTxsByCreator,
+}
+
 // Init is called once per chain when the chain is created.
 // This allows the chaincode to initialize any variables on the ledger prior
 // to any transaction execution on the chain.
 func (e *LedgerQuerier) Init(stub shim.ChaincodeStubInterface) pb.Response {
 	qscclogger.Info("Init QSCC")
 
-	// Init policy checker for access control
-	e.policyChecker = policy.NewPolicyChecker(
-		peer.NewChannelPolicyManagerGetter(),
-		mgmt.GetLocalMSP(),
-		mgmt.NewLocalMSPPrincipalGetter(),
-	)
+	// Init ACL provider for access control
+	e.aclProvider = aclmgmt.GetACLProvider()
 
 	return shim.Success(nil)
 }
@@ -75,6 +94,12 @@ func (e *LedgerQuerier) Init(stub shim.ChaincodeStubInterface) pb.Response {
 // # GetBlockByNumber: Return the block specified by block number in args[2]
 // # GetBlockByHash: Return the block specified by block hash in args[2]
 // # GetTransactionByID: Return the transaction specified by ID in args[2]
+// # GetBlocksByRange: Return the first page of the blocks numbered from args[2] to args[3]
+// included, of the optional page size in args[4]. The bookmark of the page is the args[2]
+// of the next one
+// # GetTransactionsByCreator: Return the page of the transactions created by the serialized
+// identity in args[2], of the optional page size in args[3], which starts at the optional
+// bookmark of the previous page in args[4]
 func (e *LedgerQuerier) Invoke(stub shim.ChaincodeStubInterface) pb.Response {
 	args := stub.GetArgs()
 
@@ -102,9 +127,14 @@ func (e *LedgerQuerier) Invoke(stub shim.ChaincodeStubInterface) pb.Response {
 		return shim.Error(fmt.Sprintf("Failed getting signed proposal from stub, %s: %s", cid, err))
 	}
 
-	// 2. check the channel reader policy
-	if err = e.policyChecker.CheckPolicy(cid, policies.ChannelApplicationReaders, sp); err != nil {
-		return shim.Error(fmt.Sprintf("Authorization request failed %s: %s", cid, err))
+	// 2. check the ACL of the resource of the function, the channel readers
+	// policy unless the channel config maps it to another policy
+	res, ok := aclResources[fname]
+	if !ok {
+		return shim.Error(fmt.Sprintf("Requested function %s not found.", fname))
+	}
+	if err = e.aclProvider.CheckACL(res, cid, sp); err != nil {
+		return shim.Error(fmt.Sprintf("Authorization request for [%s][%s] failed: [%s]", fname, cid, err))
 	}
 
 	switch fname {
@@ -118,6 +148,10 @@ func (e *LedgerQuerier) Invoke(stub shim.ChaincodeStubInterface) pb.Response {
 		return getChainInfo(targetLedger)
 	case GetBlockByTxID:
 		return getBlockByTxID(targetLedger, args[2])
+	case GetBlocksByRange:
+		return getBlocksByRange(targetLedger, args[2:])
+	case GetTransactionsByCreator:
+		return getTransactionsByCreator(targetLedger, args[2:])
 	}
 
 	return shim.Error(fmt.Sprintf("Requested function %s not found.", fname))
@@ -216,3 +250,120 @@ func getBlockByTxID(vledger ledger.PeerLedger, rawTxID []byte) pb.Response {
 
 	return shim.Success(bytes)
 }
+
+func getBlocksByRange(vledger ledger.PeerLedger, args [][]byte) pb.Response {
+	if len(args) < 2 {
+		return shim.Error("Block range must have a start and an end.")
+	}
+	start, err := strconv.ParseUint(string(args[0]), 10, 64)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("Failed to parse start block number with error %s", err))
+	}
+	end, err := strconv.ParseUint(string(args[1]), 10, 64)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("Failed to parse end block number with error %s", err))
+	}
+	if start > end {
+		return shim.Error(fmt.Sprintf("Invalid block range [%d, %d]", start, end))
+	}
+	pageSize, err := parsePageSize(args[2:])
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	binfo, err := vledger.GetBlockchainInfo()
+	if err != nil {
+		return shim.Error(fmt.Sprintf("Failed to get block info with error %s", err))
+	}
+	if start >= binfo.Height {
+		return shim.Error(fmt.Sprintf("Block number %d is beyond the height %d of the ledger", start, binfo.Height))
+	}
+	// the range ends at the last block of the ledger
+	if end >= binfo.Height {
+		end = binfo.Height - 1
+	}
+	last := end
+	if end-start >= uint64(pageSize) {
+		last = start + uint64(pageSize) - 1
+	}
+
+	resp := &pb.BlocksQueryResponse{}
+	for bnum := start; bnum <= last; bnum++ {
+		block, err := vledger.GetBlockByNumber(bnum)
+		if err != nil {
+			return shim.Error(fmt.Sprintf("Failed to get block number %d, error %s", bnum, err))
+		}
+		resp.Blocks = append(resp.Blocks, block)
+	}
+	if last < end {
+		resp.Bookmark = strconv.FormatUint(last+1, 10)
+	}
+
+	bytes, err := utils.Marshal(resp)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	return shim.Success(bytes)
+}
+
+func getTransactionsByCreator(vledger ledger.PeerLedger, args [][]byte) pb.Response {
+	if len(args[0]) == 0 {
+		return shim.Error("Creator must not be nil.")
+	}
+	pageSize, err := parsePageSize(args[1:])
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	var start blkstorage.TxPosition
+	if len(args) > 2 && len(args[2]) > 0 {
+		if start, err = parseTxBookmark(string(args[2])); err != nil {
+			return shim.Error(err.Error())
+		}
+	}
+
+	txs, next, err := vledger.GetTransactionsByCreator(args[0], start, pageSize)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("Failed to get transactions by creator, error %s", err))
+	}
+	resp := &pb.TransactionsQueryResponse{Transactions: txs}
+	if next != nil {
+		resp.Bookmark = fmt.Sprintf("%d:%d", next.BlockNum, next.TranNum)
+	}
+
+	bytes, err := utils.Marshal(resp)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	return shim.Success(bytes)
+}
+
+// parsePageSize returns the page size in the optional first argument,
+// lowered to the maximum, and the default page size if there is none
+func parsePageSize(args [][]byte) (int, error) {
+	if len(args) == 0 || len(args[0]) == 0 {
+		return defaultPageSize, nil
+	}
+	pageSize, err := strconv.Atoi(string(args[0]))
+	if err != nil {
+		return 0, fmt.Errorf("Failed to parse page size with error %s", err)
+	}
+	if pageSize <= 0 {
+		return 0, fmt.Errorf("Invalid page size %d", pageSize)
+	}
+	if pageSize > maxPageSize {
+		pageSize = maxPageSize
+	}
+	return pageSize, nil
+}
+
+// parseTxBookmark returns the position of the transaction located by
+// the bookmark, made of its block number and its number in the block
+func parseTxBookmark(bookmark string) (blkstorage.TxPosition, error) {
+	var pos blkstorage.TxPosition
+	if n, err := fmt.Sscanf(bookmark, "%d:%d", &pos.BlockNum, &pos.TranNum); err != nil || n != 2 {
+		return pos, fmt.Errorf("Invalid bookmark %s", bookmark)
+	}
+	return pos, nil
+}
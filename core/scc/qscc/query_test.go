@@ -21,9 +21,11 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric/common/ledger/testutil"
 	"github.com/hyperledger/fabric/common/policies"
 	"github.com/hyperledger/fabric/common/util"
+	"github.com/hyperledger/fabric/core/aclmgmt"
 	"github.com/hyperledger/fabric/core/chaincode/shim"
 	"github.com/hyperledger/fabric/core/peer"
 	"github.com/hyperledger/fabric/core/policy"
@@ -39,6 +41,7 @@ func setupTestLedger(chainid string, path string) (*shim.MockStub, error) {
 	viper.Set("peer.fileSystemPath", path)
 	peer.MockInitialize()
 	peer.MockCreateChain(chainid)
+	aclmgmt.RegisterACLProvider(nil)
 
 	lq := new(LedgerQuerier)
 	stub := shim.NewMockStub("LedgerQuerier", lq)
@@ -54,7 +57,7 @@ func TestQueryGetChainInfo(t *testing.T) {
 	stub, err := setupTestLedger(chainid, path)
 	defer os.RemoveAll(path)
 	if err != nil {
-		t.Fatalf(err.Error())
+		t.Fatal(err)
 	}
 
 	args := [][]byte{[]byte(GetChainInfo), []byte(chainid)}
@@ -76,7 +79,7 @@ func TestQueryGetTransactionByID(t *testing.T) {
 	stub, err := setupTestLedger(chainid, path)
 	defer os.RemoveAll(path)
 	if err != nil {
-		t.Fatalf(err.Error())
+		t.Fatal(err)
 	}
 
 	args := [][]byte{[]byte(GetTransactionByID), []byte(chainid), []byte("1")}
@@ -99,7 +102,7 @@ func TestQueryGetBlockByNumber(t *testing.T) {
 	stub, err := setupTestLedger(chainid, path)
 	defer os.RemoveAll(path)
 	if err != nil {
-		t.Fatalf(err.Error())
+		t.Fatal(err)
 	}
 
 	// block number 0 (genesis block) would already be present in the ledger
@@ -124,7 +127,7 @@ func TestQueryGetBlockByHash(t *testing.T) {
 	stub, err := setupTestLedger(chainid, path)
 	defer os.RemoveAll(path)
 	if err != nil {
-		t.Fatalf(err.Error())
+		t.Fatal(err)
 	}
 
 	args := [][]byte{[]byte(GetBlockByHash), []byte(chainid), []byte("0")}
@@ -142,7 +145,7 @@ func TestQueryGetBlockByTxID(t *testing.T) {
 	stub, err := setupTestLedger(chainid, path)
 	defer os.RemoveAll(path)
 	if err != nil {
-		t.Fatalf(err.Error())
+		t.Fatal(err)
 	}
 
 	args := [][]byte{[]byte(GetBlockByTxID), []byte(chainid), []byte("")}
@@ -156,7 +159,7 @@ func TestFailingAccessControl(t *testing.T) {
 	_, err := setupTestLedger(chainid, path)
 	defer os.RemoveAll(path)
 	if err != nil {
-		t.Fatalf(err.Error())
+		t.Fatal(err)
 	}
 	e := new(LedgerQuerier)
 	// Init the policy checker to have a failure
@@ -165,11 +168,11 @@ func TestFailingAccessControl(t *testing.T) {
 			chainid: &policymocks.MockChannelPolicyManager{MockPolicy: &policymocks.MockPolicy{Deserializer: &policymocks.MockIdentityDeserializer{Identity: []byte("Alice"), Msg: []byte("msg1")}}},
 		},
 	}
-	e.policyChecker = policy.NewPolicyChecker(
+	e.aclProvider = aclmgmt.NewDefaultACLProviderWithPolicyChecker(policy.NewPolicyChecker(
 		policyManagerGetter,
 		&policymocks.MockIdentityDeserializer{Identity: []byte("Alice"), Msg: []byte("msg1")},
 		&policymocks.MockMSPPrincipalGetter{Principal: []byte("Alice")},
-	)
+	))
 	stub := shim.NewMockStub("LedgerQuerier", e)
 
 	args := [][]byte{[]byte(GetChainInfo), []byte(chainid)}
@@ -182,7 +185,7 @@ func TestFailingAccessControl(t *testing.T) {
 	sProp, _ = utils.MockSignedEndorserProposalOrPanic(chainid, &peer2.ChaincodeSpec{}, []byte("Bob"), []byte("msg2"))
 	res = stub.MockInvokeWithSignedProposal("3", args, sProp)
 	assert.Equal(t, int32(shim.ERROR), res.Status, "GetChainInfo must fail: %s", res.Message)
-	assert.True(t, strings.HasPrefix(res.Message, "Authorization request failed"))
+	assert.True(t, strings.HasPrefix(res.Message, "Authorization request for [GetChainInfo]"))
 }
 
 func TestQueryNonexistentFunction(t *testing.T) {
@@ -191,7 +194,7 @@ func TestQueryNonexistentFunction(t *testing.T) {
 	stub, err := setupTestLedger(chainid, path)
 	defer os.RemoveAll(path)
 	if err != nil {
-		t.Fatalf(err.Error())
+		t.Fatal(err)
 	}
 
 	args := [][]byte{[]byte("GetBlocks"), []byte(chainid), []byte("arg1")}
@@ -207,7 +210,7 @@ func TestQueryGeneratedBlock(t *testing.T) {
 	stub, err := setupTestLedger(chainid, path)
 	defer os.RemoveAll(path)
 	if err != nil {
-		t.Fatalf(err.Error())
+		t.Fatal(err)
 	}
 
 	block1 := addBlockForTesting(t, chainid)
@@ -235,7 +238,7 @@ func TestQueryGeneratedBlock(t *testing.T) {
 				}
 				chdr, err := utils.UnmarshalChannelHeader(payload.Header.ChannelHeader)
 				if err != nil {
-					t.Fatalf(err.Error())
+					t.Fatal(err)
 				}
 				if common.HeaderType(chdr.Type) == common.HeaderType_ENDORSER_TRANSACTION {
 					args = [][]byte{[]byte(GetBlockByTxID), []byte(chainid), []byte(chdr.TxId)}
@@ -251,6 +254,104 @@ func TestQueryGeneratedBlock(t *testing.T) {
 	}
 }
 
+func TestQueryGetBlocksByRange(t *testing.T) {
+	chainid := "mytestchainid9"
+	path := "/var/hyperledger/test9/"
+	stub, err := setupTestLedger(chainid, path)
+	defer os.RemoveAll(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block1 := addBlockForTesting(t, chainid)
+
+	getBlocksByRange := func(args ...string) *peer2.BlocksQueryResponse {
+		invokeArgs := [][]byte{[]byte(GetBlocksByRange), []byte(chainid)}
+		for _, arg := range args {
+			invokeArgs = append(invokeArgs, []byte(arg))
+		}
+		res := stub.MockInvoke("1", invokeArgs)
+		if res.Status != shim.OK {
+			t.Log(res.Message)
+			return nil
+		}
+		resp := &peer2.BlocksQueryResponse{}
+		assert.NoError(t, proto.Unmarshal(res.Payload, resp))
+		return resp
+	}
+
+	// the range ends at the last block of the ledger
+	resp := getBlocksByRange("0", "10")
+	assert.Len(t, resp.Blocks, 2)
+	assert.True(t, proto.Equal(block1, resp.Blocks[1]))
+	assert.Empty(t, resp.Bookmark)
+
+	// the bookmark starts the next page
+	resp = getBlocksByRange("0", "1", "1")
+	assert.Len(t, resp.Blocks, 1)
+	assert.Equal(t, uint64(0), resp.Blocks[0].Header.Number)
+	assert.Equal(t, "1", resp.Bookmark)
+	resp = getBlocksByRange(resp.Bookmark, "1", "1")
+	assert.Len(t, resp.Blocks, 1)
+	assert.Equal(t, uint64(1), resp.Blocks[0].Header.Number)
+	assert.Empty(t, resp.Bookmark)
+
+	assert.Nil(t, getBlocksByRange("2", "10"), "the range should start below the height of the ledger")
+	assert.Nil(t, getBlocksByRange("1", "0"), "the range should not end before its start")
+	assert.Nil(t, getBlocksByRange("0", "1", "0"), "the page size should be positive")
+	assert.Nil(t, getBlocksByRange("0", "a"), "the range should be numbers")
+	assert.Nil(t, getBlocksByRange("0"), "the range should have an end")
+}
+
+func TestQueryGetTransactionsByCreator(t *testing.T) {
+	chainid := "mytestchainid10"
+	path := "/var/hyperledger/test10/"
+	stub, err := setupTestLedger(chainid, path)
+	defer os.RemoveAll(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block1 := addBlockForTesting(t, chainid)
+
+	// both transactions of the block are created by the same identity
+	env, err := utils.GetEnvelopeFromBlock(block1.Data.Data[0])
+	assert.NoError(t, err)
+	payload, err := utils.GetPayload(env)
+	assert.NoError(t, err)
+	shdr, err := utils.GetSignatureHeader(payload.Header.SignatureHeader)
+	assert.NoError(t, err)
+
+	getTransactionsByCreator := func(args ...[]byte) *peer2.TransactionsQueryResponse {
+		res := stub.MockInvoke("1", append([][]byte{[]byte(GetTransactionsByCreator), []byte(chainid)}, args...))
+		if res.Status != shim.OK {
+			t.Log(res.Message)
+			return nil
+		}
+		resp := &peer2.TransactionsQueryResponse{}
+		assert.NoError(t, proto.Unmarshal(res.Payload, resp))
+		return resp
+	}
+
+	resp := getTransactionsByCreator(shdr.Creator)
+	assert.Len(t, resp.Transactions, 2)
+	assert.Empty(t, resp.Bookmark)
+
+	resp = getTransactionsByCreator(shdr.Creator, []byte("1"))
+	assert.Len(t, resp.Transactions, 1)
+	assert.True(t, proto.Equal(env, resp.Transactions[0].TransactionEnvelope))
+	assert.Equal(t, int32(peer2.TxValidationCode_VALID), resp.Transactions[0].ValidationCode)
+	assert.Equal(t, "1:1", resp.Bookmark)
+	resp = getTransactionsByCreator(shdr.Creator, []byte("1"), []byte(resp.Bookmark))
+	assert.Len(t, resp.Transactions, 1)
+	assert.Empty(t, resp.Bookmark)
+
+	resp = getTransactionsByCreator([]byte("unknown creator"))
+	assert.Empty(t, resp.Transactions)
+
+	assert.Nil(t, getTransactionsByCreator(nil), "the creator should not be nil")
+	assert.Nil(t, getTransactionsByCreator(shdr.Creator, []byte("-1")), "the page size should be positive")
+	assert.Nil(t, getTransactionsByCreator(shdr.Creator, []byte("1"), []byte("garbage")), "the bookmark should be valid")
+}
+
 func addBlockForTesting(t *testing.T, chainid string) *common.Block {
 	bg, _ := testutil.NewBlockGenerator(t, chainid, false)
 	ledger := peer.GetLedger(chainid)
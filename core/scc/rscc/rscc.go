@@ -59,6 +59,10 @@ type Rscc struct {
 
 	//the cache of RSCC policies for all the channels
 	policyCache map[string]*policyProvider
+
+	//the provider of the peer wide resources, which are not bound to a channel
+	peerProvider     aclmgmt.ACLProvider
+	peerProviderOnce sync.Once
 }
 
 var rsccLogger = flogging.MustGetLogger("rscc")
@@ -92,8 +96,20 @@ func (e PolicyProviderNotFound) Error() string {
 //     that implements 1.0 functions
 //   . If a resource in RSCC Provider it'll use the policy defined there. Otherwise it'll defer
 //     to default provider
+//   . A resource checked without a channel is peer wide, it is checked by the default provider
 func (rscc *Rscc) CheckACL(resName string, channelID string, idinfo interface{}) error {
 	rsccLogger.Debugf("rscc acl check(%s, %s)", resName, channelID)
+
+	//peer wide resources are not defined in the config of a channel
+	if channelID == "" {
+		rscc.peerProviderOnce.Do(func() {
+			if rscc.peerProvider == nil {
+				rscc.peerProvider = aclmgmt.NewDefaultACLProvider()
+			}
+		})
+		return rscc.peerProvider.CheckACL(resName, channelID, idinfo)
+	}
+
 	rscc.RLock()
 	defer rscc.RUnlock()
 	pp := rscc.policyCache[channelID]
@@ -241,6 +241,10 @@ func TestACLPaths(t *testing.T) {
 	err = rscc.CheckACL("anyres", "myc", "id")
 	assert.Error(t, err, "should have received error")
 	assert.Equal(t, err.Error(), PolicyProviderNotFound("myc").Error())
+
+	//peer wide resource, checked without a channel by the peer provider
+	rscc.peerProvider = &mockDefaultACLProvider{resMap: map[string]string{"peerres": "peerresPol"}, throwPanic: "peerprovider"}
+	testProviderSource(t, rscc, "peerres", "", "id", "peerprovider")
 }
 
 func TestLedgerProcessor(t *testing.T) {
@@ -19,6 +19,8 @@ package scc
 import (
 	"fmt"
 
+	channelconfig "github.com/hyperledger/fabric/common/config/channel"
+	"github.com/hyperledger/fabric/common/policies"
 	"github.com/hyperledger/fabric/core/common/sysccprovider"
 	"github.com/hyperledger/fabric/core/ledger"
 	"github.com/hyperledger/fabric/core/peer"
@@ -65,6 +67,22 @@ func (c *sccProviderImpl) GetQueryExecutorForLedger(cid string) (ledger.QueryExe
 	return l.NewQueryExecutor()
 }
 
+// PolicyManager returns the policy manager of the specified channel
+func (c *sccProviderImpl) PolicyManager(cid string) (policies.Manager, bool) {
+	pm := peer.GetPolicyManager(cid)
+	return pm, pm != nil
+}
+
+// ApplicationCapabilities returns the application capabilities of the specified channel
+func (c *sccProviderImpl) ApplicationCapabilities(cid string) (channelconfig.ApplicationCapabilities, bool) {
+	return peer.GetApplicationCapabilities(cid)
+}
+
+// MSPIDs returns the MSP IDs of the application orgs of the specified channel
+func (c *sccProviderImpl) MSPIDs(cid string) []string {
+	return peer.GetMSPIDs(cid)
+}
+
 // IsSysCCAndNotInvokableExternal returns true if the supplied chaincode is
 // ia system chaincode and it NOT invokable
 func (c *sccProviderImpl) IsSysCCAndNotInvokableExternal(name string) bool {
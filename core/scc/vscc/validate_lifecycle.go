@@ -0,0 +1,173 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package vscc
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/core/common/privdata"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/rwsetutil"
+	"github.com/hyperledger/fabric/core/scc/lifecycle"
+	"github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/ledger/rwset/kvrwset"
+	"github.com/hyperledger/fabric/protos/msp"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	lc "github.com/hyperledger/fabric/protos/peer/lifecycle"
+	"github.com/hyperledger/fabric/protos/utils"
+)
+
+// ValidateLifecycleInvocation validates an invocation of the _lifecycle system chaincode. An approval
+// has to be written under the key of the org of the creator of the transaction, and a commit has to
+// follow the committed definition of the chaincode, keep its collections, and to be approved by the orgs
+// of the channel as required by its lifecycle policy. The approvals of a commit are the ones it read, as committed
+func (vscc *ValidatorOneValidSignature) ValidateLifecycleInvocation(chid string, cap *pb.ChaincodeActionPayload, payl *common.Payload) error {
+	cpp, err := utils.GetChaincodeProposalPayload(cap.ChaincodeProposalPayload)
+	if err != nil {
+		return fmt.Errorf("GetChaincodeProposalPayload error %s", err)
+	}
+	cis := &pb.ChaincodeInvocationSpec{}
+	if err = proto.Unmarshal(cpp.Input, cis); err != nil {
+		return fmt.Errorf("Unmarshal ChaincodeInvocationSpec error %s", err)
+	}
+	if cis.ChaincodeSpec == nil || cis.ChaincodeSpec.Input == nil || len(cis.ChaincodeSpec.Input.Args) != 2 {
+		return fmt.Errorf("VSCC error: committing invalid %s invocation", lifecycle.Name)
+	}
+	function := string(cis.ChaincodeSpec.Input.Args[0])
+	def, err := lifecycle.UnmarshalDefinition(cis.ChaincodeSpec.Input.Args[1])
+	if err != nil {
+		return err
+	}
+
+	lifecycleRWSet, err := lifecycleRWSet(cap)
+	if err != nil {
+		return err
+	}
+	if lifecycleRWSet == nil || len(lifecycleRWSet.Writes) != 1 {
+		return fmt.Errorf("%s can only issue a single putState upon %s", lifecycle.Name, function)
+	}
+	write := lifecycleRWSet.Writes[0]
+
+	qe, err := vscc.sccprovider.GetQueryExecutorForLedger(chid)
+	if err != nil {
+		return fmt.Errorf("Could not retrieve QueryExecutor for channel %s, error %s", chid, err)
+	}
+	defer qe.Done()
+
+	committedBytes, err := qe.GetState(lifecycle.Name, lifecycle.DefinitionKey(def.Name))
+	if err != nil {
+		return fmt.Errorf("Could not retrieve definition of chaincode %s on channel %s, error %s", def.Name, chid, err)
+	}
+	committed := &lc.ChaincodeDefinition{}
+	if err = proto.Unmarshal(committedBytes, committed); err != nil {
+		return fmt.Errorf("Unmarshalling ChaincodeDefinition failed, error %s", err)
+	}
+	if def.Sequence != committed.Sequence+1 {
+		return fmt.Errorf("Expected sequence %d for chaincode %s, found %d", committed.Sequence+1, def.Name, def.Sequence)
+	}
+
+	logger.Debugf("Validating %s for cc %s sequence %d", function, def.Name, def.Sequence)
+
+	switch function {
+	case lifecycle.ApproveFuncName:
+		shdr, err := utils.GetSignatureHeader(payl.Header.SignatureHeader)
+		if err != nil {
+			return err
+		}
+		creator := &msp.SerializedIdentity{}
+		if err = proto.Unmarshal(shdr.Creator, creator); err != nil {
+			return fmt.Errorf("Unmarshal creator error %s", err)
+		}
+		// an org can only approve for itself
+		if expected := lifecycle.ApprovalKey(def.Name, def.Sequence, creator.Mspid); write.Key != expected {
+			return fmt.Errorf("Expected key %s, found %s", expected, write.Key)
+		}
+		// the approval must be the signed proposal of the creator approving the definition
+		sp := &pb.SignedProposal{}
+		if err = proto.Unmarshal(write.Value, sp); err != nil {
+			return fmt.Errorf("Unmarshalling approval failed, error %s", err)
+		}
+		approval, err := lifecycle.ParseApproval(sp)
+		if err != nil {
+			return err
+		}
+		if approval.ChannelID != chid || !proto.Equal(approval.Definition, def) || !bytes.Equal(approval.SignedData.Identity, shdr.Creator) {
+			return fmt.Errorf("Approval of chaincode %s was not written as supplied", def.Name)
+		}
+
+	case lifecycle.CommitFuncName:
+		if expected := lifecycle.DefinitionKey(def.Name); write.Key != expected {
+			return fmt.Errorf("Expected key %s, found %s", expected, write.Key)
+		}
+		written := &lc.ChaincodeDefinition{}
+		if err = proto.Unmarshal(write.Value, written); err != nil || !proto.Equal(written, def) {
+			return fmt.Errorf("Definition of chaincode %s was not written as supplied", def.Name)
+		}
+		// the collections of the definition may replace the committed ones
+		if err = privdata.ValidateCollectionConfigUpgrade(committed.Collections, def.Collections); err != nil {
+			return err
+		}
+		approvals := make(map[string][]byte)
+		for _, read := range lifecycleRWSet.Reads {
+			if !lifecycle.IsApprovalKey(read.Key, def.Name, def.Sequence) {
+				continue
+			}
+			value, err := qe.GetState(lifecycle.Name, read.Key)
+			if err != nil {
+				return fmt.Errorf("Could not retrieve approval %s on channel %s, error %s", read.Key, chid, err)
+			}
+			if value != nil {
+				approvals[read.Key] = value
+			}
+		}
+		pm, ok := vscc.sccprovider.PolicyManager(chid)
+		if !ok {
+			return fmt.Errorf("No policy manager for channel %s", chid)
+		}
+		if err = lifecycle.EvaluateApprovals(chid, def, approvals, pm); err != nil {
+			return err
+		}
+
+	default:
+		return fmt.Errorf("VSCC error: committing an invocation of function %s of %s is invalid", function, lifecycle.Name)
+	}
+	return nil
+}
+
+// lifecycleRWSet returns the read-write set of the action in the namespace of _lifecycle,
+// nil if there is none. The action must not write to any other namespace
+func lifecycleRWSet(cap *pb.ChaincodeActionPayload) (*kvrwset.KVRWSet, error) {
+	if cap.Action == nil {
+		return nil, errors.New("nil action")
+	}
+	pRespPayload, err := utils.GetProposalResponsePayload(cap.Action.ProposalResponsePayload)
+	if err != nil {
+		return nil, fmt.Errorf("GetProposalResponsePayload error %s", err)
+	}
+	if pRespPayload.Extension == nil {
+		return nil, errors.New("nil pRespPayload.Extension")
+	}
+	respPayload, err := utils.GetChaincodeAction(pRespPayload.Extension)
+	if err != nil {
+		return nil, fmt.Errorf("GetChaincodeAction error %s", err)
+	}
+	txRWSet := &rwsetutil.TxRwSet{}
+	if err = txRWSet.FromProtoBytes(respPayload.Results); err != nil {
+		return nil, fmt.Errorf("txRWSet.FromProtoBytes error %s", err)
+	}
+	var lifecycleRWSet *kvrwset.KVRWSet
+	for _, ns := range txRWSet.NsRwSets {
+		if ns.NameSpace == lifecycle.Name {
+			lifecycleRWSet = ns.KvRwSet
+		} else if len(ns.KvRwSet.Writes) > 0 {
+			return nil, fmt.Errorf("%s invocation is attempting to write to namespace %s", lifecycle.Name, ns.NameSpace)
+		}
+	}
+	return lifecycleRWSet, nil
+}
@@ -0,0 +1,230 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package vscc
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/fabric/common/cauthdsl"
+	channelconfig "github.com/hyperledger/fabric/common/config/channel"
+	mockchannelconfig "github.com/hyperledger/fabric/common/mocks/config"
+	lm "github.com/hyperledger/fabric/common/mocks/ledger"
+	mockpolicies "github.com/hyperledger/fabric/common/mocks/policies"
+	"github.com/hyperledger/fabric/common/mocks/scc"
+	"github.com/hyperledger/fabric/common/policies"
+	"github.com/hyperledger/fabric/common/util"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	"github.com/hyperledger/fabric/core/common/sysccprovider"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/rwsetutil"
+	"github.com/hyperledger/fabric/core/scc/lifecycle"
+	mspmgmt "github.com/hyperledger/fabric/msp/mgmt"
+	"github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/peer"
+	lc "github.com/hyperledger/fabric/protos/peer/lifecycle"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// approvalsPolicy is satisfied by at least n valid signatures
+type approvalsPolicy struct {
+	n int
+}
+
+func (p *approvalsPolicy) Evaluate(signatureSet []*common.SignedData) error {
+	valid := 0
+	for _, sd := range signatureSet {
+		identity, err := mspmgmt.GetManagerForChain(util.GetTestChainID()).DeserializeIdentity(sd.Identity)
+		if err == nil && identity.Verify(sd.Data, sd.Signature) == nil {
+			valid++
+		}
+	}
+	if valid < p.n {
+		return fmt.Errorf("%d valid approvals, %d required", valid, p.n)
+	}
+	return nil
+}
+
+func newLifecycleDefinition(version string, sequence int64) *lc.ChaincodeDefinition {
+	return &lc.ChaincodeDefinition{
+		Name:              "mycc",
+		Version:           version,
+		Sequence:          sequence,
+		EndorsementPolicy: utils.MarshalOrPanic(cauthdsl.SignedByMspMember(mspid)),
+	}
+}
+
+func lifecycleCIS(function string, def *lc.ChaincodeDefinition) *peer.ChaincodeInvocationSpec {
+	return &peer.ChaincodeInvocationSpec{
+		ChaincodeSpec: &peer.ChaincodeSpec{
+			ChaincodeId: &peer.ChaincodeID{Name: lifecycle.Name},
+			Input:       &peer.ChaincodeInput{Args: [][]byte{[]byte(function), utils.MarshalOrPanic(def)}},
+			Type:        peer.ChaincodeSpec_GOLANG,
+		},
+	}
+}
+
+// createApproval returns the signed proposal approving the definition for the org of the signer
+func createApproval(t *testing.T, def *lc.ChaincodeDefinition) []byte {
+	prop, _, err := utils.CreateProposalFromCIS(common.HeaderType_ENDORSER_TRANSACTION, util.GetTestChainID(), lifecycleCIS(lifecycle.ApproveFuncName, def), sid)
+	require.NoError(t, err)
+	propBytes := utils.MarshalOrPanic(prop)
+	signature, err := id.Sign(propBytes)
+	require.NoError(t, err)
+	return utils.MarshalOrPanic(&peer.SignedProposal{ProposalBytes: propBytes, Signature: signature})
+}
+
+func createLifecycleTx(t *testing.T, function string, def *lc.ChaincodeDefinition, rwsetBuilder *rwsetutil.RWSetBuilder) []byte {
+	sr, err := rwsetBuilder.GetTxSimulationResults()
+	require.NoError(t, err)
+	res, err := sr.GetPubSimulationBytes()
+	require.NoError(t, err)
+	tx, err := createLSCCTxFromCIS(lifecycle.Name, util.GetSysCCVersion(), lifecycleCIS(function, def), res)
+	require.NoError(t, err)
+	envBytes, err := utils.GetBytesEnvelope(tx)
+	require.NoError(t, err)
+	return envBytes
+}
+
+func invokeLifecycleValidation(t *testing.T, state map[string]map[string][]byte, pm policies.Manager, envBytes []byte) peer.Response {
+	return invokeLifecycleValidationWith(t, state, pm, &mockchannelconfig.ApplicationCapabilities{LifecycleVal: true}, envBytes)
+}
+
+func invokeLifecycleValidationWith(t *testing.T, state map[string]map[string][]byte, pm policies.Manager, ac channelconfig.ApplicationCapabilities, envBytes []byte) peer.Response {
+	sysccprovider.RegisterSystemChaincodeProviderFactory(&scc.MocksccProviderFactory{Qe: lm.NewMockQueryExecutor(state), PolicyManager: pm, ApplicationCapabilities: ac})
+	defer sysccprovider.RegisterSystemChaincodeProviderFactory(&scc.MocksccProviderFactory{})
+	stub := shim.NewMockStub("validatoronevalidsignature", new(ValidatorOneValidSignature))
+	require.Equal(t, int32(shim.OK), stub.MockInit("1", [][]byte{}).Status)
+
+	policy, err := getSignedByMSPMemberPolicy(mspid)
+	require.NoError(t, err)
+	return stub.MockInvoke("1", [][]byte{[]byte("dv"), envBytes, policy})
+}
+
+func TestValidateLifecycleApproval(t *testing.T) {
+	def := newLifecycleDefinition("1.0", 1)
+	state := map[string]map[string][]byte{lifecycle.Name: {}}
+
+	approve := func(key string, approval []byte) peer.Response {
+		rwsetBuilder := rwsetutil.NewRWSetBuilder()
+		rwsetBuilder.AddToWriteSet(lifecycle.Name, key, approval)
+		return invokeLifecycleValidation(t, state, &mockpolicies.Manager{}, createLifecycleTx(t, lifecycle.ApproveFuncName, def, rwsetBuilder))
+	}
+
+	// good path: the creator approves the definition for its org
+	res := approve(lifecycle.ApprovalKey("mycc", 1, mspid), createApproval(t, def))
+	assert.Equal(t, int32(shim.OK), res.Status, res.Message)
+
+	// the application capabilities of the channel must enable _lifecycle
+	rwsetBuilder := rwsetutil.NewRWSetBuilder()
+	rwsetBuilder.AddToWriteSet(lifecycle.Name, lifecycle.ApprovalKey("mycc", 1, mspid), createApproval(t, def))
+	envBytes := createLifecycleTx(t, lifecycle.ApproveFuncName, def, rwsetBuilder)
+	res = invokeLifecycleValidationWith(t, state, &mockpolicies.Manager{}, &mockchannelconfig.ApplicationCapabilities{}, envBytes)
+	assert.Equal(t, int32(shim.ERROR), res.Status)
+	assert.Contains(t, res.Message, "do not enable it")
+
+	// an org cannot approve for another one
+	res = approve(lifecycle.ApprovalKey("mycc", 1, "OtherMSP"), createApproval(t, def))
+	assert.Equal(t, int32(shim.ERROR), res.Status)
+	assert.Contains(t, res.Message, "Expected key")
+
+	// the approval must be the one of the definition
+	res = approve(lifecycle.ApprovalKey("mycc", 1, mspid), createApproval(t, newLifecycleDefinition("2.0", 1)))
+	assert.Equal(t, int32(shim.ERROR), res.Status)
+	assert.Contains(t, res.Message, "was not written as supplied")
+	res = approve(lifecycle.ApprovalKey("mycc", 1, mspid), []byte("garbage"))
+	assert.Equal(t, int32(shim.ERROR), res.Status)
+
+	// the approval must be of the sequence following the committed definition
+	state[lifecycle.Name][lifecycle.DefinitionKey("mycc")] = utils.MarshalOrPanic(def)
+	res = approve(lifecycle.ApprovalKey("mycc", 1, mspid), createApproval(t, def))
+	assert.Equal(t, int32(shim.ERROR), res.Status)
+	assert.Contains(t, res.Message, "Expected sequence 2")
+
+	// an approval only writes to the namespace of _lifecycle
+	delete(state[lifecycle.Name], lifecycle.DefinitionKey("mycc"))
+	rwsetBuilder = rwsetutil.NewRWSetBuilder()
+	rwsetBuilder.AddToWriteSet(lifecycle.Name, lifecycle.ApprovalKey("mycc", 1, mspid), createApproval(t, def))
+	rwsetBuilder.AddToWriteSet("mycc", "key", []byte("value"))
+	res = invokeLifecycleValidation(t, state, &mockpolicies.Manager{}, createLifecycleTx(t, lifecycle.ApproveFuncName, def, rwsetBuilder))
+	assert.Equal(t, int32(shim.ERROR), res.Status)
+	assert.Contains(t, res.Message, "attempting to write to namespace mycc")
+}
+
+func TestValidateLifecycleCommit(t *testing.T) {
+	def := newLifecycleDefinition("1.0", 1)
+	approvalKey := lifecycle.ApprovalKey("mycc", 1, mspid)
+	state := map[string]map[string][]byte{lifecycle.Name: {approvalKey: createApproval(t, def)}}
+	pm := &mockpolicies.Manager{PolicyMap: map[string]policies.Policy{lifecycle.LifecycleEndorsementPolicyName: &approvalsPolicy{n: 1}}}
+
+	commit := func(def *lc.ChaincodeDefinition, readApprovals bool, written []byte) peer.Response {
+		rwsetBuilder := rwsetutil.NewRWSetBuilder()
+		if readApprovals {
+			rwsetBuilder.AddToReadSet(lifecycle.Name, approvalKey, nil)
+		}
+		rwsetBuilder.AddToWriteSet(lifecycle.Name, lifecycle.DefinitionKey("mycc"), written)
+		return invokeLifecycleValidation(t, state, pm, createLifecycleTx(t, lifecycle.CommitFuncName, def, rwsetBuilder))
+	}
+
+	// good path: the approvals read satisfy the policy
+	res := commit(def, true, utils.MarshalOrPanic(def))
+	assert.Equal(t, int32(shim.OK), res.Status, res.Message)
+
+	// the approvals which weren't read don't count
+	res = commit(def, false, utils.MarshalOrPanic(def))
+	assert.Equal(t, int32(shim.ERROR), res.Status)
+	assert.Contains(t, res.Message, "0 valid approvals, 1 required")
+
+	// and neither do the approvals of other definitions
+	otherDef := newLifecycleDefinition("2.0", 1)
+	res = commit(otherDef, true, utils.MarshalOrPanic(otherDef))
+	assert.Equal(t, int32(shim.ERROR), res.Status)
+	assert.Contains(t, res.Message, "0 valid approvals, 1 required")
+
+	// the definition written must be the one committed
+	res = commit(def, true, utils.MarshalOrPanic(otherDef))
+	assert.Equal(t, int32(shim.ERROR), res.Status)
+	assert.Contains(t, res.Message, "was not written as supplied")
+
+	// the definition must follow the committed one
+	nextDef := newLifecycleDefinition("2.0", 2)
+	res = commit(nextDef, true, utils.MarshalOrPanic(nextDef))
+	assert.Equal(t, int32(shim.ERROR), res.Status)
+	assert.Contains(t, res.Message, "Expected sequence 1")
+
+	// the definition must keep the collections of the committed one
+	committed := newLifecycleDefinition("1.0", 1)
+	committed.Collections = &common.CollectionConfigPackage{Config: []*common.CollectionConfig{{
+		Payload: &common.CollectionConfig_StaticCollectionConfig{
+			StaticCollectionConfig: &common.StaticCollectionConfig{
+				Name: "coll1",
+				MemberOrgsPolicy: &common.CollectionPolicyConfig{
+					Payload: &common.CollectionPolicyConfig_SignaturePolicy{SignaturePolicy: cauthdsl.SignedByAnyMember([]string{mspid})},
+				},
+			},
+		},
+	}}}
+	state[lifecycle.Name][lifecycle.DefinitionKey("mycc")] = utils.MarshalOrPanic(committed)
+	res = commit(nextDef, true, utils.MarshalOrPanic(nextDef))
+	assert.Equal(t, int32(shim.ERROR), res.Status)
+	assert.Contains(t, res.Message, "collection coll1 cannot be removed upon upgrade")
+	delete(state[lifecycle.Name], lifecycle.DefinitionKey("mycc"))
+
+	// the approvals are evaluated against the admins of the channel by default
+	pm = &mockpolicies.Manager{PolicyMap: map[string]policies.Policy{policies.ChannelApplicationAdmins: &approvalsPolicy{n: 2}}}
+	res = commit(def, true, utils.MarshalOrPanic(def))
+	assert.Equal(t, int32(shim.ERROR), res.Status)
+	assert.Contains(t, res.Message, policies.ChannelApplicationAdmins)
+
+	// no other function of _lifecycle can be committed
+	rwsetBuilder := rwsetutil.NewRWSetBuilder()
+	rwsetBuilder.AddToWriteSet(lifecycle.Name, lifecycle.DefinitionKey("mycc"), utils.MarshalOrPanic(def))
+	res = invokeLifecycleValidation(t, state, pm, createLifecycleTx(t, lifecycle.QueryDefinitionFuncName, def, rwsetBuilder))
+	assert.Equal(t, int32(shim.ERROR), res.Status)
+	assert.Contains(t, res.Message, "is invalid")
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package vscc
 
 import (
+	"bytes"
 	"fmt"
 
 	"errors"
@@ -25,10 +26,13 @@ import (
 
 	"github.com/hyperledger/fabric/common/cauthdsl"
 	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/common/policies"
 	"github.com/hyperledger/fabric/core/chaincode/shim"
 	"github.com/hyperledger/fabric/core/common/ccprovider"
+	"github.com/hyperledger/fabric/core/common/privdata"
 	"github.com/hyperledger/fabric/core/common/sysccprovider"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/rwsetutil"
+	"github.com/hyperledger/fabric/core/scc/lifecycle"
 	"github.com/hyperledger/fabric/core/scc/lscc"
 	mspmgmt "github.com/hyperledger/fabric/msp/mgmt"
 	"github.com/hyperledger/fabric/protos/common"
@@ -44,6 +48,38 @@ const (
 	DUPLICATED_IDENTITY_ERROR = "Endorsement policy evaluation failure might be caused by duplicated identities"
 )
 
+// ValidationParameterKey is the name of the metadata entry that holds the endorsement policy of a key,
+// as a serialized SignaturePolicyEnvelope. A transaction that writes the value or the metadata of the key
+// has to satisfy this policy in addition to the endorsement policy of the chaincode
+const ValidationParameterKey = "VALIDATION_PARAMETER"
+
+// InstantiationPolicyName is the policy recorded in the validation reason of a deploy or upgrade
+// transaction whose creator doesn't satisfy the instantiation policy of the chaincode
+const InstantiationPolicyName = "Instantiation"
+
+// lsccValidationError is an error validating an lscc invocation, along with the validation
+// code the committer marks the transaction with, and the name of the policy it violated, if any
+type lsccValidationError struct {
+	error
+	code   pb.TxValidationCode
+	policy string
+}
+
+// lsccValidationFailure returns the response of a failed validation of an lscc invocation. The
+// validation code of the failure is carried in a TxValidationReason payload, for the committer
+// to mark the transaction with
+func lsccValidationFailure(err error) pb.Response {
+	lsccErr, isLSCCErr := err.(*lsccValidationError)
+	if !isLSCCErr {
+		return shim.Error(err.Error())
+	}
+	reason, mErr := proto.Marshal(&pb.TxValidationReason{Code: lsccErr.code, Policy: lsccErr.policy, Message: err.Error()})
+	if mErr != nil {
+		return shim.Error(err.Error())
+	}
+	return pb.Response{Status: shim.ERROR, Message: err.Error(), Payload: reason}
+}
+
 // ValidatorOneValidSignature implements the default transaction validation policy,
 // which is to check the correctness of the read-write set and the endorsement
 // signatures
@@ -145,14 +181,24 @@ func (vscc *ValidatorOneValidSignature) Invoke(stub shim.ChaincodeStubInterface)
 		}
 
 		// evaluate the signature set against the policy
-		err = policy.Evaluate(signatureSet)
+		trace, err := policies.EvaluateWithTrace(policy, signatureSet)
 		if err != nil {
-			logger.Warningf("Endorsement policy failure for transaction txid=%s, err: %s", chdr.GetTxId(), err.Error())
+			logger.Warningf("Endorsement policy failure for transaction txid=%s, err: %s, evaluation %s", chdr.GetTxId(), err.Error(), trace)
 			if len(signatureSet) < len(cap.Action.Endorsements) {
 				// Warning: duplicated identities exist, endorsement failure might be cause by this reason
 				return shim.Error(DUPLICATED_IDENTITY_ERROR)
 			}
-			return shim.Error(fmt.Sprintf("VSCC error: policy evaluation failed, err %s", err))
+			return shim.Error(fmt.Sprintf("VSCC error: policy evaluation failed, err %s, evaluation %s", err, trace))
+		}
+
+		// evaluate the signature set against the endorsement policies of the keys written by the action,
+		// once the application capabilities of the channel enable them
+		if ac, ok := vscc.sccprovider.ApplicationCapabilities(chdr.ChannelId); ok && ac.KeyLevelEndorsement() {
+			err = vscc.checkKeyLevelPolicies(chdr.ChannelId, cap, signatureSet, pProvider)
+			if err != nil {
+				logger.Warningf("Key-level endorsement policy failure for transaction txid=%s, err: %s", chdr.GetTxId(), err.Error())
+				return shim.Error(fmt.Sprintf("VSCC error: key-level policy evaluation failed, err %s", err))
+			}
 		}
 
 		hdrExt, err := utils.GetChaincodeHeaderExtension(payl.Header)
@@ -168,6 +214,22 @@ func (vscc *ValidatorOneValidSignature) Invoke(stub shim.ChaincodeStubInterface)
 			err = vscc.ValidateLSCCInvocation(stub, chdr.ChannelId, env, cap, payl)
 			if err != nil {
 				logger.Errorf("VSCC error: ValidateLSCCInvocation failed, err %s", err)
+				return lsccValidationFailure(err)
+			}
+		}
+
+		// and to _lifecycle, which may only be invoked once the application capabilities of the channel enable it
+		if hdrExt.ChaincodeId.Name == lifecycle.Name {
+			logger.Debugf("VSCC info: doing special validation for %s", lifecycle.Name)
+
+			if ac, exists := vscc.sccprovider.ApplicationCapabilities(chdr.ChannelId); !exists || !ac.Lifecycle() {
+				logger.Errorf("VSCC error: %s is not enabled on channel %s", lifecycle.Name, chdr.ChannelId)
+				return shim.Error(fmt.Sprintf("VSCC error: committing an invocation of %s is invalid, the application capabilities of channel %s do not enable it", lifecycle.Name, chdr.ChannelId))
+			}
+
+			err = vscc.ValidateLifecycleInvocation(chdr.ChannelId, cap, payl)
+			if err != nil {
+				logger.Errorf("VSCC error: ValidateLifecycleInvocation failed, err %s", err)
 				return shim.Error(err.Error())
 			}
 		}
@@ -206,9 +268,9 @@ func (vscc *ValidatorOneValidSignature) checkInstantiationPolicy(chainName strin
 		Identity:  shdr.Creator,
 		Signature: env.Signature,
 	}}
-	err = instPol.Evaluate(sd)
+	trace, err := policies.EvaluateWithTrace(instPol, sd)
 	if err != nil {
-		return fmt.Errorf("chaincode instantiation policy violated, error %s", err)
+		return fmt.Errorf("chaincode instantiation policy violated, error %s, evaluation %s", err, trace)
 	}
 	return nil
 }
@@ -243,8 +305,8 @@ func (vscc *ValidatorOneValidSignature) ValidateLSCCInvocation(stub shim.Chainco
 	case lscc.UPGRADE, lscc.DEPLOY:
 		logger.Debugf("VSCC info: validating invocation of lscc function %s on arguments %#v", lsccFunc, lsccArgs)
 
-		if len(lsccArgs) < 2 || len(lsccArgs) > 5 {
-			return fmt.Errorf("Wrong number of arguments for invocation lscc(%s): expected between 2 and 5, received %d", lsccFunc, len(lsccArgs))
+		if len(lsccArgs) < 2 || len(lsccArgs) > 6 {
+			return fmt.Errorf("Wrong number of arguments for invocation lscc(%s): expected between 2 and 6, received %d", lsccFunc, len(lsccArgs))
 		}
 
 		cdsArgs, err := utils.GetChaincodeDeploymentSpec(lsccArgs[1])
@@ -297,27 +359,49 @@ func (vscc *ValidatorOneValidSignature) ValidateLSCCInvocation(stub shim.Chainco
 		if lsccrwset == nil {
 			return errors.New("No read write set for lscc was found")
 		}
-		// there can only be a single one
-		if len(lsccrwset.Writes) != 1 {
+		// there can only be a single one, besides the collection configs once the
+		// application capabilities of the channel enable them
+		cdWrite, collectionWrite, err := lsccWrites(lsccrwset, cdsArgs.ChaincodeSpec.ChaincodeId.Name)
+		if err != nil {
+			return err
+		}
+		ac, exists := vscc.sccprovider.ApplicationCapabilities(chid)
+		collectionUpgrade := exists && ac.CollectionUpgrade()
+		if !collectionUpgrade && collectionWrite != nil {
 			return errors.New("LSCC can only issue a single putState upon deploy/upgrade")
 		}
-		// the key name must be the chaincode id
-		if lsccrwset.Writes[0].Key != cdsArgs.ChaincodeSpec.ChaincodeId.Name {
-			return fmt.Errorf("Expected key %s, found %s", cdsArgs.ChaincodeSpec.ChaincodeId.Name, lsccrwset.Writes[0].Key)
+		// the collection configs written must be the ones of the invocation
+		if collectionUpgrade {
+			var collectionsArg []byte
+			if len(lsccArgs) > 5 {
+				collectionsArg = lsccArgs[5]
+			}
+			if len(collectionsArg) > 0 && (collectionWrite == nil || !bytes.Equal(collectionWrite.Value, collectionsArg)) {
+				return fmt.Errorf("Collection configs of chaincode %s were not written as supplied", cdsArgs.ChaincodeSpec.ChaincodeId.Name)
+			}
+			if len(collectionsArg) == 0 && collectionWrite != nil {
+				return fmt.Errorf("Collection configs of chaincode %s were written without being supplied", cdsArgs.ChaincodeSpec.ChaincodeId.Name)
+			}
 		}
 		// the value must be a ChaincodeData struct
 		cdRWSet := &ccprovider.ChaincodeData{}
-		err = proto.Unmarshal(lsccrwset.Writes[0].Value, cdRWSet)
+		err = proto.Unmarshal(cdWrite.Value, cdRWSet)
 		if err != nil {
 			return fmt.Errorf("Unmarhsalling of ChaincodeData failed, error %s", err)
 		}
 		// the name must match
 		if cdRWSet.Name != cdsArgs.ChaincodeSpec.ChaincodeId.Name {
-			return fmt.Errorf("Expected cc name %s, found %s", cdsArgs.ChaincodeSpec.ChaincodeId.Name, cdRWSet.Name)
+			return &lsccValidationError{
+				error: fmt.Errorf("Expected cc name %s, found %s", cdsArgs.ChaincodeSpec.ChaincodeId.Name, cdRWSet.Name),
+				code:  pb.TxValidationCode_CHAINCODE_DEFINITION_MISMATCH,
+			}
 		}
 		// the version must match
 		if cdRWSet.Version != cdsArgs.ChaincodeSpec.ChaincodeId.Version {
-			return fmt.Errorf("Expected cc version %s, found %s", cdsArgs.ChaincodeSpec.ChaincodeId.Version, cdRWSet.Version)
+			return &lsccValidationError{
+				error: fmt.Errorf("Expected cc version %s, found %s", cdsArgs.ChaincodeSpec.ChaincodeId.Version, cdRWSet.Version),
+				code:  pb.TxValidationCode_CHAINCODE_DEFINITION_MISMATCH,
+			}
 		}
 		// it must only write to 2 namespaces: LSCC's and the cc that we are deploying/upgrading
 		for _, ns := range txRWSet.NsRwSets {
@@ -335,7 +419,7 @@ func (vscc *ValidatorOneValidSignature) ValidateLSCCInvocation(stub shim.Chainco
 			/*****************************************************/
 			pol := cdRWSet.InstantiationPolicy
 			if pol == nil {
-				return fmt.Errorf("No installation policy was specified")
+				return instantiationPolicyError(errors.New("No installation policy was specified"))
 			}
 			// FIXME: could we actually pull the cds package from the
 			// file system to verify whether the policy that is specified
@@ -344,7 +428,7 @@ func (vscc *ValidatorOneValidSignature) ValidateLSCCInvocation(stub shim.Chainco
 			// CONS: this would be a point of non-determinism
 			err = vscc.checkInstantiationPolicy(chid, env, pol, payl)
 			if err != nil {
-				return err
+				return instantiationPolicyError(err)
 			}
 
 			/******************************************************************/
@@ -367,7 +451,7 @@ func (vscc *ValidatorOneValidSignature) ValidateLSCCInvocation(stub shim.Chainco
 			/*****************************************************/
 			pol := cdLedger.InstantiationPolicy
 			if pol == nil {
-				return fmt.Errorf("No installation policy was specified")
+				return instantiationPolicyError(errors.New("No installation policy was specified"))
 			}
 			// FIXME: could we actually pull the cds package from the
 			// file system to verify whether the policy that is specified
@@ -376,7 +460,7 @@ func (vscc *ValidatorOneValidSignature) ValidateLSCCInvocation(stub shim.Chainco
 			// CONS: this would be a point of non-determinism
 			err = vscc.checkInstantiationPolicy(chid, env, pol, payl)
 			if err != nil {
-				return err
+				return instantiationPolicyError(err)
 			}
 
 			/**********************************************************/
@@ -385,6 +469,16 @@ func (vscc *ValidatorOneValidSignature) ValidateLSCCInvocation(stub shim.Chainco
 			if cdLedger.Version == cdsArgs.ChaincodeSpec.ChaincodeId.Version {
 				return fmt.Errorf("Existing version of the cc on the ledger (%s) should be different from the upgraded one", cdsArgs.ChaincodeSpec.ChaincodeId.Version)
 			}
+
+			/*******************************************************************/
+			/* security check 4 - collection configs may replace existing ones */
+			/*******************************************************************/
+			if collectionWrite != nil {
+				err = vscc.validateCollectionConfigUpgrade(chid, cdsArgs.ChaincodeSpec.ChaincodeId.Name, collectionWrite.Value)
+				if err != nil {
+					return err
+				}
+			}
 		}
 
 		// all is good!
@@ -394,6 +488,102 @@ func (vscc *ValidatorOneValidSignature) ValidateLSCCInvocation(stub shim.Chainco
 	}
 }
 
+// instantiationPolicyError marks the error as a violation of the instantiation policy of the chaincode
+func instantiationPolicyError(err error) error {
+	return &lsccValidationError{error: err, code: pb.TxValidationCode_INSTANTIATION_POLICY_FAILURE, policy: InstantiationPolicyName}
+}
+
+// lsccWrites returns the write of the chaincode data of the chaincode deployed or upgraded
+// by an lscc invocation, and the write of its collection configs, nil if there is none
+func lsccWrites(lsccrwset *kvrwset.KVRWSet, ccname string) (*kvrwset.KVWrite, *kvrwset.KVWrite, error) {
+	if len(lsccrwset.Writes) != 1 && len(lsccrwset.Writes) != 2 {
+		return nil, nil, errors.New("LSCC can only issue a single putState upon deploy/upgrade")
+	}
+	var cdWrite, collectionWrite *kvrwset.KVWrite
+	for _, write := range lsccrwset.Writes {
+		switch write.Key {
+		case ccname:
+			cdWrite = write
+		case privdata.BuildCollectionKVSKey(ccname):
+			collectionWrite = write
+		default:
+			// the key name must be the chaincode id
+			return nil, nil, fmt.Errorf("Expected key %s, found %s", ccname, write.Key)
+		}
+	}
+	if cdWrite == nil {
+		return nil, nil, errors.New("LSCC can only issue a single putState upon deploy/upgrade")
+	}
+	return cdWrite, collectionWrite, nil
+}
+
+// checkKeyLevelPolicies evaluates the signature set against the endorsement policies held in the committed
+// metadata of the keys written by the action. A transaction writing a key whose metadata is updated by a preceding
+// transaction of the same block is invalidated by the committer, as its policy is not committed yet when checked here. An action whose read-write set cannot be parsed fails the check
+func (vscc *ValidatorOneValidSignature) checkKeyLevelPolicies(chid string, cap *pb.ChaincodeActionPayload, signatureSet []*common.SignedData, pProvider policies.Provider) error {
+	if cap.Action == nil {
+		return fmt.Errorf("VSCC error: no action in the chaincode action payload")
+	}
+	pRespPayload, err := utils.GetProposalResponsePayload(cap.Action.ProposalResponsePayload)
+	if err != nil {
+		return fmt.Errorf("GetProposalResponsePayload error %s", err)
+	}
+	if pRespPayload.Extension == nil {
+		return fmt.Errorf("nil pRespPayload.Extension")
+	}
+	respPayload, err := utils.GetChaincodeAction(pRespPayload.Extension)
+	if err != nil {
+		return fmt.Errorf("GetChaincodeAction error %s", err)
+	}
+	txRWSet := &rwsetutil.TxRwSet{}
+	if err = txRWSet.FromProtoBytes(respPayload.Results); err != nil {
+		return fmt.Errorf("txRWSet.FromProtoBytes error %s", err)
+	}
+
+	writtenKeys := make(map[string][]string)
+	for _, nsRWSet := range txRWSet.NsRwSets {
+		for _, kvWrite := range nsRWSet.KvRwSet.Writes {
+			writtenKeys[nsRWSet.NameSpace] = append(writtenKeys[nsRWSet.NameSpace], kvWrite.Key)
+		}
+		for _, metadataWrite := range nsRWSet.KvRwSet.MetadataWrites {
+			writtenKeys[nsRWSet.NameSpace] = append(writtenKeys[nsRWSet.NameSpace], metadataWrite.Key)
+		}
+	}
+	if len(writtenKeys) == 0 {
+		return nil
+	}
+
+	qe, err := vscc.sccprovider.GetQueryExecutorForLedger(chid)
+	if err != nil {
+		return fmt.Errorf("Could not retrieve QueryExecutor for channel %s, error %s", chid, err)
+	}
+	defer qe.Done()
+
+	// a policy shared by several keys is evaluated once
+	evaluated := make(map[string]bool)
+	for ns, keys := range writtenKeys {
+		for _, key := range keys {
+			metadata, err := qe.GetStateMetadata(ns, key)
+			if err != nil {
+				return fmt.Errorf("Could not retrieve metadata for key %s in namespace %s, error %s", key, ns, err)
+			}
+			policyBytes, ok := metadata[ValidationParameterKey]
+			if !ok || evaluated[string(policyBytes)] {
+				continue
+			}
+			policy, _, err := pProvider.NewPolicy(policyBytes)
+			if err != nil {
+				return fmt.Errorf("invalid endorsement policy for key %s in namespace %s, error %s", key, ns, err)
+			}
+			if trace, err := policies.EvaluateWithTrace(policy, signatureSet); err != nil {
+				return fmt.Errorf("endorsement policy for key %s in namespace %s not satisfied, error %s, evaluation %s", key, ns, err, trace)
+			}
+			evaluated[string(policyBytes)] = true
+		}
+	}
+	return nil
+}
+
 func (vscc *ValidatorOneValidSignature) getInstantiatedCC(chid, ccid string) (cd *ccprovider.ChaincodeData, exists bool, err error) {
 	qe, err := vscc.sccprovider.GetQueryExecutorForLedger(chid)
 	if err != nil {
@@ -423,6 +613,34 @@ func (vscc *ValidatorOneValidSignature) getInstantiatedCC(chid, ccid string) (cd
 	return
 }
 
+// validateCollectionConfigUpgrade checks that the collection configs written upon the upgrade
+// of the chaincode may replace the ones committed for it, if any
+func (vscc *ValidatorOneValidSignature) validateCollectionConfigUpgrade(chid, ccid string, collectionConfigBytes []byte) error {
+	qe, err := vscc.sccprovider.GetQueryExecutorForLedger(chid)
+	if err != nil {
+		return fmt.Errorf("Could not retrieve QueryExecutor for channel %s, error %s", chid, err)
+	}
+	defer qe.Done()
+
+	existingBytes, err := qe.GetState("lscc", privdata.BuildCollectionKVSKey(ccid))
+	if err != nil {
+		return fmt.Errorf("Could not retrieve collection configs of chaincode %s on channel %s, error %s", ccid, chid, err)
+	}
+	if existingBytes == nil {
+		return nil
+	}
+
+	existing := &common.CollectionConfigPackage{}
+	if err = proto.Unmarshal(existingBytes, existing); err != nil {
+		return fmt.Errorf("Unmarshalling existing CollectionConfigPackage failed, error %s", err)
+	}
+	updated := &common.CollectionConfigPackage{}
+	if err = proto.Unmarshal(collectionConfigBytes, updated); err != nil {
+		return fmt.Errorf("Unmarshalling CollectionConfigPackage failed, error %s", err)
+	}
+	return privdata.ValidateCollectionConfigUpgrade(existing, updated)
+}
+
 func (vscc *ValidatorOneValidSignature) deduplicateIdentity(cap *pb.ChaincodeActionPayload) ([]*common.SignedData, error) {
 	// this is the first part of the signed message
 	prespBytes := cap.Action.ProposalResponsePayload
@@ -28,12 +28,15 @@ import (
 
 	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric/common/cauthdsl"
+	mockchannelconfig "github.com/hyperledger/fabric/common/mocks/config"
 	lm "github.com/hyperledger/fabric/common/mocks/ledger"
 	"github.com/hyperledger/fabric/common/mocks/scc"
 	"github.com/hyperledger/fabric/common/util"
+	"github.com/hyperledger/fabric/core/aclmgmt"
 	"github.com/hyperledger/fabric/core/chaincode/shim"
 	"github.com/hyperledger/fabric/core/common/ccpackage"
 	"github.com/hyperledger/fabric/core/common/ccprovider"
+	"github.com/hyperledger/fabric/core/common/privdata"
 	"github.com/hyperledger/fabric/core/common/sysccprovider"
 	cutils "github.com/hyperledger/fabric/core/container/util"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/rwsetutil"
@@ -51,6 +54,10 @@ import (
 )
 
 func createTx(endorsedByDuplicatedIdentity bool) (*common.Envelope, error) {
+	return createTxWithResults(endorsedByDuplicatedIdentity, []byte("res"))
+}
+
+func createTxWithResults(endorsedByDuplicatedIdentity bool, results []byte) (*common.Envelope, error) {
 	ccid := &peer.ChaincodeID{Name: "foo", Version: "v1"}
 	cis := &peer.ChaincodeInvocationSpec{ChaincodeSpec: &peer.ChaincodeSpec{ChaincodeId: ccid}}
 
@@ -59,7 +66,7 @@ func createTx(endorsedByDuplicatedIdentity bool) (*common.Envelope, error) {
 		return nil, err
 	}
 
-	presp, err := utils.CreateProposalResponse(prop.Header, prop.Payload, &peer.Response{Status: 200}, []byte("res"), nil, ccid, nil, id)
+	presp, err := utils.CreateProposalResponse(prop.Header, prop.Payload, &peer.Response{Status: 200}, results, nil, ccid, nil, id)
 	if err != nil {
 		return nil, err
 	}
@@ -190,6 +197,39 @@ func createLSCCTxPutCds(ccname, ccver, f string, res, cdsbytes []byte, putcds bo
 		}
 	}
 
+	return createLSCCTxFromCIS(ccname, ccver, cis, res)
+}
+
+func createLSCCTxWithCollections(ccname, ccver, f string, res, collections []byte) (*common.Envelope, error) {
+	cds := &peer.ChaincodeDeploymentSpec{
+		ChaincodeSpec: &peer.ChaincodeSpec{
+			ChaincodeId: &peer.ChaincodeID{
+				Name:    ccname,
+				Version: ccver,
+			},
+			Type: peer.ChaincodeSpec_GOLANG,
+		},
+	}
+
+	cdsBytes, err := proto.Marshal(cds)
+	if err != nil {
+		return nil, err
+	}
+
+	cis := &peer.ChaincodeInvocationSpec{
+		ChaincodeSpec: &peer.ChaincodeSpec{
+			ChaincodeId: &peer.ChaincodeID{Name: "lscc"},
+			Input: &peer.ChaincodeInput{
+				Args: [][]byte{[]byte(f), []byte("barf"), cdsBytes, nil, nil, nil, collections},
+			},
+			Type: peer.ChaincodeSpec_GOLANG,
+		},
+	}
+
+	return createLSCCTxFromCIS(ccname, ccver, cis, res)
+}
+
+func createLSCCTxFromCIS(ccname, ccver string, cis *peer.ChaincodeInvocationSpec, res []byte) (*common.Envelope, error) {
 	prop, _, err := utils.CreateProposalFromCIS(common.HeaderType_ENDORSER_TRANSACTION, util.GetTestChainID(), cis, sid)
 	if err != nil {
 		return nil, err
@@ -257,6 +297,7 @@ func getSignedByMSPAdminPolicy(mspID string) ([]byte, error) {
 func TestInvoke(t *testing.T) {
 	v := new(ValidatorOneValidSignature)
 	stub := shim.NewMockStub("validatoronevalidsignature", v)
+	stub.MockInit("1", nil)
 
 	// Failed path: Invalid arguments
 	args := [][]byte{[]byte("dv")}
@@ -374,6 +415,77 @@ func TestInvoke(t *testing.T) {
 	}
 }
 
+func TestKeyLevelEndorsementPolicy(t *testing.T) {
+	rwsetBuilder := rwsetutil.NewRWSetBuilder()
+	rwsetBuilder.AddToWriteSet("foo", "k1", []byte("v1"))
+	sr, err := rwsetBuilder.GetTxSimulationResults()
+	assert.NoError(t, err)
+	results, err := sr.GetPubSimulationBytes()
+	assert.NoError(t, err)
+	tx, err := createTxWithResults(false, results)
+	assert.NoError(t, err)
+	envBytes, err := utils.GetBytesEnvelope(tx)
+	assert.NoError(t, err)
+	ccPolicy, err := getSignedByMSPMemberPolicy(mspid)
+	assert.NoError(t, err)
+
+	capabilities := &mockchannelconfig.ApplicationCapabilities{KeyLevelEndorsementVal: true}
+	invokeWith := func(envBytes, keyPolicy []byte) peer.Response {
+		qe := lm.NewMockQueryExecutor(map[string]map[string][]byte{})
+		if keyPolicy != nil {
+			qe.Metadata = map[string]map[string]map[string][]byte{"foo": {"k1": {ValidationParameterKey: keyPolicy}}}
+		}
+		sysccprovider.RegisterSystemChaincodeProviderFactory(&scc.MocksccProviderFactory{Qe: qe, ApplicationCapabilities: capabilities})
+		v := new(ValidatorOneValidSignature)
+		stub := shim.NewMockStub("validatoronevalidsignature", v)
+		res := stub.MockInit("1", nil)
+		assert.Equal(t, int32(shim.OK), res.Status)
+		return stub.MockInvoke("1", [][]byte{[]byte("dv"), envBytes, ccPolicy})
+	}
+	invoke := func(keyPolicy []byte) peer.Response {
+		return invokeWith(envBytes, keyPolicy)
+	}
+
+	// good path: the key has no endorsement policy
+	res := invoke(nil)
+	assert.Equal(t, int32(shim.OK), res.Status, res.Message)
+
+	// good path: the key policy is satisfied
+	keyPolicy, err := getSignedByMSPMemberPolicy(mspid)
+	assert.NoError(t, err)
+	res = invoke(keyPolicy)
+	assert.Equal(t, int32(shim.OK), res.Status, res.Message)
+
+	// bad path: the key policy requires the signature of another MSP
+	keyPolicy, err = getSignedByMSPMemberPolicy("barf")
+	assert.NoError(t, err)
+	res = invoke(keyPolicy)
+	assert.NotEqual(t, int32(shim.OK), res.Status)
+	assert.Contains(t, res.Message, "key-level policy evaluation failed")
+
+	// bad path: the key policy cannot be parsed
+	res = invoke([]byte("barf"))
+	assert.NotEqual(t, int32(shim.OK), res.Status)
+
+	// bad path: the rwset of the action cannot be parsed
+	tx, err = createTxWithResults(false, []byte("barf"))
+	assert.NoError(t, err)
+	badEnvBytes, err := utils.GetBytesEnvelope(tx)
+	assert.NoError(t, err)
+	res = invokeWith(badEnvBytes, nil)
+	assert.NotEqual(t, int32(shim.OK), res.Status)
+	assert.Contains(t, res.Message, "key-level policy evaluation failed")
+
+	// good path: the application capabilities of the channel don't enable the key level policies
+	capabilities = &mockchannelconfig.ApplicationCapabilities{}
+	keyPolicy, err = getSignedByMSPMemberPolicy("barf")
+	assert.NoError(t, err)
+	res = invoke(keyPolicy)
+	assert.Equal(t, int32(shim.OK), res.Status, res.Message)
+	res = invokeWith(badEnvBytes, nil)
+	assert.Equal(t, int32(shim.OK), res.Status, res.Message)
+}
+
 func TestInvalidFunction(t *testing.T) {
 	v := new(ValidatorOneValidSignature)
 	stub := shim.NewMockStub("validatoronevalidsignature", v)
@@ -959,6 +1071,162 @@ func TestValidateDeployOK(t *testing.T) {
 	}
 }
 
+func TestValidateDeployWithCollections(t *testing.T) {
+	v := new(ValidatorOneValidSignature)
+	stub := shim.NewMockStub("validatoronevalidsignature", v)
+
+	lccc := new(lscc.LifeCycleSysCC)
+	stublccc := shim.NewMockStub("lscc", lccc)
+
+	State := make(map[string]map[string][]byte)
+	State["lscc"] = stublccc.State
+	capabilities := &mockchannelconfig.ApplicationCapabilities{}
+	sysccprovider.RegisterSystemChaincodeProviderFactory(&scc.MocksccProviderFactory{Qe: lm.NewMockQueryExecutor(State), ApplicationCapabilities: capabilities})
+	defer sysccprovider.RegisterSystemChaincodeProviderFactory(&scc.MocksccProviderFactory{})
+	stub.MockPeerChaincode("lscc", stublccc)
+
+	r1 := stub.MockInit("1", [][]byte{})
+	if r1.Status != shim.OK {
+		fmt.Println("Init failed", string(r1.Message))
+		t.FailNow()
+	}
+
+	r := stublccc.MockInit("1", [][]byte{})
+	if r.Status != shim.OK {
+		fmt.Println("Init failed", string(r.Message))
+		t.FailNow()
+	}
+
+	ccname := "mycc"
+	ccver := "1"
+
+	defaultPolicy, err := getSignedByMSPAdminPolicy(mspid)
+	assert.NoError(t, err)
+	cdbytes := utils.MarshalOrPanic(&ccprovider.ChaincodeData{Name: ccname, Version: ccver, InstantiationPolicy: defaultPolicy})
+	collections := utils.MarshalOrPanic(&common.CollectionConfigPackage{
+		Config: []*common.CollectionConfig{{
+			Payload: &common.CollectionConfig_StaticCollectionConfig{
+				StaticCollectionConfig: &common.StaticCollectionConfig{Name: "coll1"},
+			},
+		}},
+	})
+	policy, err := getSignedByMSPMemberPolicy(mspid)
+	assert.NoError(t, err)
+
+	validate := func(collectionsArg []byte, writes map[string][]byte) peer.Response {
+		rwsetBuilder := rwsetutil.NewRWSetBuilder()
+		for key, value := range writes {
+			rwsetBuilder.AddToWriteSet("lscc", key, value)
+		}
+		sr, err := rwsetBuilder.GetTxSimulationResults()
+		assert.NoError(t, err)
+		res, err := sr.GetPubSimulationBytes()
+		assert.NoError(t, err)
+
+		tx, err := createLSCCTxWithCollections(ccname, ccver, lscc.DEPLOY, res, collectionsArg)
+		assert.NoError(t, err)
+		envBytes, err := utils.GetBytesEnvelope(tx)
+		assert.NoError(t, err)
+
+		return stub.MockInvoke("1", [][]byte{[]byte("dv"), envBytes, policy})
+	}
+
+	// the application capabilities of the channel don't enable the collection configs
+	res := validate(collections, map[string][]byte{ccname: cdbytes, privdata.BuildCollectionKVSKey(ccname): collections})
+	assert.Equal(t, "LSCC can only issue a single putState upon deploy/upgrade", res.Message)
+	res = validate(collections, map[string][]byte{ccname: cdbytes})
+	assert.Equal(t, int32(shim.OK), res.Status, res.Message)
+	capabilities.CollectionUpgradeVal = true
+
+	// good path: the collection configs are written as supplied
+	res = validate(collections, map[string][]byte{ccname: cdbytes, privdata.BuildCollectionKVSKey(ccname): collections})
+	assert.Equal(t, int32(shim.OK), res.Status, res.Message)
+
+	// the collection configs supplied are not written
+	res = validate(collections, map[string][]byte{ccname: cdbytes})
+	assert.Equal(t, "Collection configs of chaincode mycc were not written as supplied", res.Message)
+
+	// other collection configs than the supplied ones are written
+	res = validate(collections, map[string][]byte{ccname: cdbytes, privdata.BuildCollectionKVSKey(ccname): []byte("barf")})
+	assert.Equal(t, "Collection configs of chaincode mycc were not written as supplied", res.Message)
+
+	// collection configs are written without being supplied
+	res = validate(nil, map[string][]byte{ccname: cdbytes, privdata.BuildCollectionKVSKey(ccname): collections})
+	assert.Equal(t, "Collection configs of chaincode mycc were written without being supplied", res.Message)
+
+	// the collection configs of another chaincode are written
+	res = validate(collections, map[string][]byte{ccname: cdbytes, privdata.BuildCollectionKVSKey("othercc"): collections})
+	assert.Equal(t, "Expected key mycc, found othercc~collection", res.Message)
+}
+
+func TestValidateUpgradeWithCollections(t *testing.T) {
+	v := new(ValidatorOneValidSignature)
+	stub := shim.NewMockStub("validatoronevalidsignature", v)
+
+	ccname := "mycc"
+	defaultPolicy, err := getSignedByMSPAdminPolicy(mspid)
+	assert.NoError(t, err)
+	collectionConfig := func(name string, blockToLive uint64) *common.CollectionConfig {
+		return &common.CollectionConfig{
+			Payload: &common.CollectionConfig_StaticCollectionConfig{
+				StaticCollectionConfig: &common.StaticCollectionConfig{Name: name, BlockToLive: blockToLive},
+			},
+		}
+	}
+	collections := func(configs ...*common.CollectionConfig) []byte {
+		return utils.MarshalOrPanic(&common.CollectionConfigPackage{Config: configs})
+	}
+
+	State := map[string]map[string][]byte{"lscc": {
+		ccname: utils.MarshalOrPanic(&ccprovider.ChaincodeData{Name: ccname, Version: "1", InstantiationPolicy: defaultPolicy}),
+		privdata.BuildCollectionKVSKey(ccname): collections(collectionConfig("coll1", 10)),
+	}}
+	capabilities := &mockchannelconfig.ApplicationCapabilities{CollectionUpgradeVal: true}
+	sysccprovider.RegisterSystemChaincodeProviderFactory(&scc.MocksccProviderFactory{Qe: lm.NewMockQueryExecutor(State), ApplicationCapabilities: capabilities})
+	defer sysccprovider.RegisterSystemChaincodeProviderFactory(&scc.MocksccProviderFactory{})
+
+	r1 := stub.MockInit("1", [][]byte{})
+	if r1.Status != shim.OK {
+		fmt.Println("Init failed", string(r1.Message))
+		t.FailNow()
+	}
+
+	cdbytes := utils.MarshalOrPanic(&ccprovider.ChaincodeData{Name: ccname, Version: "2", InstantiationPolicy: defaultPolicy})
+	policy, err := getSignedByMSPMemberPolicy(mspid)
+	assert.NoError(t, err)
+
+	validate := func(upgraded []byte) peer.Response {
+		rwsetBuilder := rwsetutil.NewRWSetBuilder()
+		rwsetBuilder.AddToWriteSet("lscc", ccname, cdbytes)
+		rwsetBuilder.AddToWriteSet("lscc", privdata.BuildCollectionKVSKey(ccname), upgraded)
+		sr, err := rwsetBuilder.GetTxSimulationResults()
+		assert.NoError(t, err)
+		res, err := sr.GetPubSimulationBytes()
+		assert.NoError(t, err)
+
+		tx, err := createLSCCTxWithCollections(ccname, "2", lscc.UPGRADE, res, upgraded)
+		assert.NoError(t, err)
+		envBytes, err := utils.GetBytesEnvelope(tx)
+		assert.NoError(t, err)
+
+		return stub.MockInvoke("1", [][]byte{[]byte("dv"), envBytes, policy})
+	}
+
+	// good path: a collection is added
+	res := validate(collections(collectionConfig("coll1", 10), collectionConfig("coll2", 0)))
+	assert.Equal(t, int32(shim.OK), res.Status, res.Message)
+
+	// a collection is removed
+	res = validate(collections(collectionConfig("coll2", 0)))
+	assert.Equal(t, int32(shim.ERROR), res.Status)
+	assert.Equal(t, "collection coll1 cannot be removed upon upgrade", res.Message)
+
+	// the block to live of a collection is modified
+	res = validate(collections(collectionConfig("coll1", 5)))
+	assert.Equal(t, int32(shim.ERROR), res.Status)
+	assert.Equal(t, "the block to live of collection coll1 cannot be modified upon upgrade (from 10 to 5)", res.Message)
+}
+
 func TestValidateDeployWithPolicies(t *testing.T) {
 	v := new(ValidatorOneValidSignature)
 	stub := shim.NewMockStub("validatoronevalidsignature", v)
@@ -1405,6 +1673,61 @@ func TestValidateUpgradeWithPoliciesFail(t *testing.T) {
 	}
 }
 
+func TestValidateDeployValidationCodes(t *testing.T) {
+	v := new(ValidatorOneValidSignature)
+	stub := shim.NewMockStub("validatoronevalidsignature", v)
+
+	lccc := new(lscc.LifeCycleSysCC)
+	stublccc := shim.NewMockStub("lscc", lccc)
+
+	State := make(map[string]map[string][]byte)
+	State["lscc"] = stublccc.State
+	sysccprovider.RegisterSystemChaincodeProviderFactory(&scc.MocksccProviderFactory{Qe: lm.NewMockQueryExecutor(State)})
+	stub.MockPeerChaincode("lscc", stublccc)
+
+	r1 := stub.MockInit("1", [][]byte{})
+	assert.Equal(t, int32(shim.OK), r1.Status)
+	r := stublccc.MockInit("1", [][]byte{})
+	assert.Equal(t, int32(shim.OK), r.Status)
+
+	policy, err := getSignedByMSPMemberPolicy(mspid)
+	assert.NoError(t, err)
+	defaultPolicy, err := getSignedByMSPAdminPolicy(mspid)
+	assert.NoError(t, err)
+
+	// invoke runs vscc on the deploy of the chaincode writing the given results,
+	// and returns the validation reason vscc marked the failure with
+	invoke := func(ccname, ccver string, res []byte) *peer.TxValidationReason {
+		tx, err := createLSCCTx(ccname, ccver, lscc.DEPLOY, res)
+		assert.NoError(t, err)
+		envBytes, err := utils.GetBytesEnvelope(tx)
+		assert.NoError(t, err)
+		resp := stub.MockInvoke("1", [][]byte{[]byte("dv"), envBytes, policy})
+		if resp.Status == shim.OK {
+			return nil
+		}
+		reason := &peer.TxValidationReason{}
+		assert.NoError(t, proto.Unmarshal(resp.Payload, reason))
+		return reason
+	}
+
+	// the creator doesn't satisfy the instantiation policy
+	rejectAll := utils.MarshalOrPanic(cauthdsl.RejectAllPolicy)
+	res, err := createCCDataRWset("mycc", "mycc", "1", rejectAll)
+	assert.NoError(t, err)
+	reason := invoke("mycc", "1", res)
+	assert.NotNil(t, reason)
+	assert.Equal(t, peer.TxValidationCode_INSTANTIATION_POLICY_FAILURE, reason.Code)
+	assert.Equal(t, InstantiationPolicyName, reason.Policy)
+
+	// the version written isn't the one of the deployment spec
+	res, err = createCCDataRWset("mycc", "mycc", "2", defaultPolicy)
+	assert.NoError(t, err)
+	reason = invoke("mycc", "1", res)
+	assert.NotNil(t, reason)
+	assert.Equal(t, peer.TxValidationCode_CHAINCODE_DEFINITION_MISMATCH, reason.Code)
+}
+
 var id msp.SigningIdentity
 var sid []byte
 var mspid string
@@ -1449,6 +1772,7 @@ func TestMain(m *testing.M) {
 
 	// setup the MSP manager so that we can sign/verify
 	msptesttools.LoadMSPSetupForTesting()
+	aclmgmt.RegisterACLProvider(nil)
 
 	id, err = mspmgmt.GetLocalMSP().GetDefaultSigningIdentity()
 	if err != nil {
@@ -0,0 +1,201 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package core
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/crypto"
+	"github.com/hyperledger/fabric/msp"
+	"github.com/hyperledger/fabric/msp/mgmt"
+	"github.com/hyperledger/fabric/protos/common"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	"github.com/hyperledger/fabric/protos/utils"
+	"golang.org/x/net/context"
+)
+
+// maxStateCommitmentsPerRequest is the maximum number of state commitments returned by a
+// GetStateCommitments request, it bounds the blocks read to serve a request
+const maxStateCommitmentsPerRequest = 1000
+
+// StateComparatorSupport provides the state commitments of the channels of the peer and
+// reaches the other peers of the channels
+type StateComparatorSupport interface {
+	// LedgerHeight returns the height of the ledger of a channel joined by the peer
+	LedgerHeight(cid string) (uint64, error)
+
+	// PeerLedgerHeight returns the height of the ledger of a channel advertised by the peer
+	// at endpoint through gossip
+	PeerLedgerHeight(cid string, endpoint string) (uint64, error)
+
+	// GetStateCommitments returns the state commitments of the blocks start to end of a
+	// channel, both included, up to the last block committed by the peer
+	GetStateCommitments(cid string, start, end uint64) ([]*pb.BlockStateCommitment, error)
+
+	// Connect returns a client of the StateComparator service of the peer at endpoint, and
+	// the closer of its connection
+	Connect(endpoint string) (pb.StateComparatorClient, io.Closer, error)
+}
+
+// StateComparatorServer implements the StateComparator service of the peer. The state
+// commitments are served to the members of the local MSP, so that the comparisons, which
+// only the admins of the local MSP may request, are restricted to the peers of the organization
+type StateComparatorServer struct {
+	support StateComparatorSupport
+	access  *localMSPAccess
+	signer  crypto.LocalSigner
+}
+
+// NewStateComparatorServer creates and returns a StateComparator service instance. The signer
+// signs the requests sent to the other peers, and the requests received must be timestamped
+// within timeWindow of the time of the peer
+func NewStateComparatorServer(support StateComparatorSupport, localMSP msp.IdentityDeserializer, principalGetter mgmt.MSPPrincipalGetter, signer crypto.LocalSigner, timeWindow time.Duration) *StateComparatorServer {
+	return &StateComparatorServer{
+		support: support,
+		access:  newLocalMSPAccess(localMSP, principalGetter, timeWindow),
+		signer:  signer,
+	}
+}
+
+// GetStateCommitments returns the state commitments of a range of blocks of a channel
+func (s *StateComparatorServer) GetStateCommitments(ctx context.Context, env *common.Envelope) (*pb.StateCommitmentsResponse, error) {
+	payload, err := s.access.check(env, mgmt.Members)
+	if err != nil {
+		log.Warningf("Request for the state commitments denied: %s", err)
+		return nil, fmt.Errorf("access denied: %s", err)
+	}
+	req := &pb.StateCommitmentsRequest{}
+	if err = proto.Unmarshal(payload.Data, req); err != nil {
+		return nil, fmt.Errorf("failed unmarshalling the request: %s", err)
+	}
+	if req.ChannelId == "" {
+		return nil, errors.New("missing channel ID in the request")
+	}
+	if req.EndBlock < req.StartBlock {
+		return nil, fmt.Errorf("invalid block range [%d, %d]", req.StartBlock, req.EndBlock)
+	}
+	if req.EndBlock-req.StartBlock >= maxStateCommitmentsPerRequest {
+		req.EndBlock = req.StartBlock + maxStateCommitmentsPerRequest - 1
+	}
+
+	commitments, err := s.support.GetStateCommitments(req.ChannelId, req.StartBlock, req.EndBlock)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.StateCommitmentsResponse{Commitments: commitments}, nil
+}
+
+// CompareState compares the state commitments of a channel with another peer and reports the
+// first divergent block
+func (s *StateComparatorServer) CompareState(ctx context.Context, env *common.Envelope) (*pb.CompareStateResponse, error) {
+	payload, err := s.access.check(env, mgmt.Admins)
+	if err != nil {
+		log.Warningf("Request for a state comparison denied: %s", err)
+		return nil, fmt.Errorf("access denied: %s", err)
+	}
+	req := &pb.CompareStateRequest{}
+	if err = proto.Unmarshal(payload.Data, req); err != nil {
+		return nil, fmt.Errorf("failed unmarshalling the request: %s", err)
+	}
+	if req.ChannelId == "" {
+		return nil, errors.New("missing channel ID in the request")
+	}
+	if req.PeerEndpoint == "" {
+		return nil, errors.New("missing peer endpoint in the request")
+	}
+
+	// Compare up to the last block committed by both peers
+	localHeight, err := s.support.LedgerHeight(req.ChannelId)
+	if err != nil {
+		return nil, err
+	}
+	remoteHeight, err := s.support.PeerLedgerHeight(req.ChannelId, req.PeerEndpoint)
+	if err != nil {
+		return nil, err
+	}
+	height := localHeight
+	if remoteHeight < height {
+		height = remoteHeight
+	}
+	if height == 0 || req.StartBlock >= height {
+		return nil, fmt.Errorf("block [%d] is not committed by both peers, their ledgers have heights %d and %d", req.StartBlock, localHeight, remoteHeight)
+	}
+	last := height - 1
+	if req.EndBlock != 0 {
+		if req.EndBlock < req.StartBlock {
+			return nil, fmt.Errorf("invalid block range [%d, %d]", req.StartBlock, req.EndBlock)
+		}
+		if req.EndBlock < last {
+			last = req.EndBlock
+		}
+	}
+
+	client, closer, err := s.support.Connect(req.PeerEndpoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed connecting to peer [%s]: %s", req.PeerEndpoint, err)
+	}
+	defer closer.Close()
+
+	resp := &pb.CompareStateResponse{}
+	for start := req.StartBlock; start <= last; {
+		end := last
+		if end-start >= maxStateCommitmentsPerRequest {
+			end = start + maxStateCommitmentsPerRequest - 1
+		}
+		local, err := s.support.GetStateCommitments(req.ChannelId, start, end)
+		if err != nil {
+			return nil, err
+		}
+		remote, err := s.remoteStateCommitments(ctx, client, &pb.StateCommitmentsRequest{ChannelId: req.ChannelId, StartBlock: start, EndBlock: end})
+		if err != nil {
+			return nil, fmt.Errorf("failed getting the state commitments of peer [%s]: %s", req.PeerEndpoint, err)
+		}
+		if len(local) == 0 || len(remote) == 0 {
+			// the ledger of a peer was reset in the meantime
+			return nil, fmt.Errorf("no state commitments of block [%d], the ledgers have heights %d and %d", start, localHeight, remoteHeight)
+		}
+
+		for i := 0; i < len(local) && i < len(remote); i++ {
+			if local[i].BlockNum != remote[i].BlockNum {
+				return nil, fmt.Errorf("peer [%s] returned the state commitment of block [%d] instead of block [%d]", req.PeerEndpoint, remote[i].BlockNum, local[i].BlockNum)
+			}
+			if !bytes.Equal(local[i].Commitment, remote[i].Commitment) {
+				log.Warningf("The state of channel [%s] diverges from peer [%s] at block [%d]", req.ChannelId, req.PeerEndpoint, local[i].BlockNum)
+				resp.Diverged = true
+				resp.FirstDivergentBlock = local[i].BlockNum
+				resp.Local = local[i]
+				resp.Remote = remote[i]
+				return resp, nil
+			}
+			resp.ComparedBlocks++
+		}
+		compared := len(local)
+		if len(remote) < compared {
+			compared = len(remote)
+		}
+		start += uint64(compared)
+	}
+	return resp, nil
+}
+
+// remoteStateCommitments requests the state commitments of a range of blocks from another peer
+func (s *StateComparatorServer) remoteStateCommitments(ctx context.Context, client pb.StateComparatorClient, req *pb.StateCommitmentsRequest) ([]*pb.BlockStateCommitment, error) {
+	env, err := utils.CreateSignedEnvelope(common.HeaderType_MESSAGE, req.ChannelId, s.signer, req, 0, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed creating the request: %s", err)
+	}
+	resp, err := client.GetStateCommitments(ctx, env)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Commitments, nil
+}
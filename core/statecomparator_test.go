@@ -0,0 +1,242 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package core
+
+import (
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	mockcrypto "github.com/hyperledger/fabric/common/mocks/crypto"
+	"github.com/hyperledger/fabric/core/policy/mocks"
+	"github.com/hyperledger/fabric/msp/mgmt"
+	"github.com/hyperledger/fabric/protos/common"
+	mspproto "github.com/hyperledger/fabric/protos/msp"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+)
+
+// commitments returns the state commitments of the blocks start to end, the commitment of
+// a block being its number unless overridden
+func commitments(start, end uint64, overrides map[uint64]string) []*pb.BlockStateCommitment {
+	var res []*pb.BlockStateCommitment
+	for blockNum := start; blockNum <= end; blockNum++ {
+		commitment := []byte{byte(blockNum)}
+		if override, ok := overrides[blockNum]; ok {
+			commitment = []byte(override)
+		}
+		res = append(res, &pb.BlockStateCommitment{BlockNum: blockNum, Commitment: commitment})
+	}
+	return res
+}
+
+type mockStateComparatorSupport struct {
+	height       uint64
+	peerHeights  map[string]uint64
+	overrides    map[uint64]string
+	remote       *mockStateComparatorClient
+	err          error
+	closed       bool
+	requestedEnd uint64
+}
+
+func (m *mockStateComparatorSupport) LedgerHeight(cid string) (uint64, error) {
+	return m.height, m.err
+}
+
+func (m *mockStateComparatorSupport) PeerLedgerHeight(cid string, endpoint string) (uint64, error) {
+	height, ok := m.peerHeights[endpoint]
+	if !ok {
+		return 0, errors.New("unknown peer")
+	}
+	return height, nil
+}
+
+func (m *mockStateComparatorSupport) GetStateCommitments(cid string, start, end uint64) ([]*pb.BlockStateCommitment, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	m.requestedEnd = end
+	if end >= m.height {
+		end = m.height - 1
+	}
+	return commitments(start, end, m.overrides), nil
+}
+
+func (m *mockStateComparatorSupport) Connect(endpoint string) (pb.StateComparatorClient, io.Closer, error) {
+	if m.remote == nil {
+		return nil, nil, errors.New("connection refused")
+	}
+	return m.remote, m, nil
+}
+
+func (m *mockStateComparatorSupport) Close() error {
+	m.closed = true
+	return nil
+}
+
+// mockStateComparatorClient serves at most batchSize state commitments per request
+type mockStateComparatorClient struct {
+	batchSize uint64
+	overrides map[uint64]string
+	requests  []*pb.StateCommitmentsRequest
+}
+
+func (m *mockStateComparatorClient) GetStateCommitments(ctx context.Context, env *common.Envelope, opts ...grpc.CallOption) (*pb.StateCommitmentsResponse, error) {
+	payload, err := utils.UnmarshalPayload(env.Payload)
+	if err != nil {
+		return nil, err
+	}
+	req := &pb.StateCommitmentsRequest{}
+	if err = proto.Unmarshal(payload.Data, req); err != nil {
+		return nil, err
+	}
+	m.requests = append(m.requests, req)
+	end := req.EndBlock
+	if end-req.StartBlock >= m.batchSize {
+		end = req.StartBlock + m.batchSize - 1
+	}
+	return &pb.StateCommitmentsResponse{Commitments: commitments(req.StartBlock, end, m.overrides)}, nil
+}
+
+func (m *mockStateComparatorClient) CompareState(ctx context.Context, env *common.Envelope, opts ...grpc.CallOption) (*pb.CompareStateResponse, error) {
+	return nil, errors.New("not implemented")
+}
+
+// rolePrincipalGetter returns the principal of each role of the local MSP
+type rolePrincipalGetter map[string][]byte
+
+func (g rolePrincipalGetter) Get(role string) (*mspproto.MSPPrincipal, error) {
+	return &mspproto.MSPPrincipal{Principal: g[role]}, nil
+}
+
+// signedRequest returns an envelope of the request created by creator, whose signature is
+// its payload as the mock identities expect it
+func signedRequest(t *testing.T, creator []byte, req proto.Message) *common.Envelope {
+	env := signedEnvelope(t, creator)
+	payload, err := utils.UnmarshalPayload(env.Payload)
+	assert.NoError(t, err)
+	payload.Data = utils.MarshalOrPanic(req)
+	payloadBytes := utils.MarshalOrPanic(payload)
+	return &common.Envelope{Payload: payloadBytes, Signature: payloadBytes}
+}
+
+func newTestStateComparatorServer(support StateComparatorSupport, creator []byte) (*StateComparatorServer, *mocks.MockIdentityDeserializer) {
+	deserializer := &mocks.MockIdentityDeserializer{Identity: creator}
+	principals := rolePrincipalGetter{mgmt.Admins: []byte("Admin"), mgmt.Members: []byte("Member")}
+	return NewStateComparatorServer(support, deserializer, principals, &mockcrypto.LocalSigner{}, time.Minute), deserializer
+}
+
+func TestGetStateCommitments(t *testing.T) {
+	support := &mockStateComparatorSupport{height: 5}
+	server, deserializer := newTestStateComparatorServer(support, []byte("Member"))
+
+	env := signedRequest(t, []byte("Member"), &pb.StateCommitmentsRequest{ChannelId: "mychannel", StartBlock: 1, EndBlock: 10})
+	deserializer.Msg = env.Payload
+	resp, err := server.GetStateCommitments(context.Background(), env)
+	assert.NoError(t, err)
+	assert.Equal(t, commitments(1, 4, nil), resp.Commitments)
+
+	// the range is capped
+	env = signedRequest(t, []byte("Member"), &pb.StateCommitmentsRequest{ChannelId: "mychannel", StartBlock: 1, EndBlock: 5000})
+	deserializer.Msg = env.Payload
+	_, err = server.GetStateCommitments(context.Background(), env)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(maxStateCommitmentsPerRequest), support.requestedEnd)
+
+	// invalid requests
+	env = signedRequest(t, []byte("Member"), &pb.StateCommitmentsRequest{StartBlock: 1, EndBlock: 2})
+	deserializer.Msg = env.Payload
+	_, err = server.GetStateCommitments(context.Background(), env)
+	assert.EqualError(t, err, "missing channel ID in the request")
+
+	env = signedRequest(t, []byte("Member"), &pb.StateCommitmentsRequest{ChannelId: "mychannel", StartBlock: 2, EndBlock: 1})
+	deserializer.Msg = env.Payload
+	_, err = server.GetStateCommitments(context.Background(), env)
+	assert.EqualError(t, err, "invalid block range [2, 1]")
+
+	// the errors of the support are returned
+	support.err = errors.New("ledger failure")
+	env = signedRequest(t, []byte("Member"), &pb.StateCommitmentsRequest{ChannelId: "mychannel"})
+	deserializer.Msg = env.Payload
+	_, err = server.GetStateCommitments(context.Background(), env)
+	assert.EqualError(t, err, "ledger failure")
+
+	// the creator is not a member of the local MSP
+	deserializer.Identity = []byte("Stranger")
+	env = signedRequest(t, []byte("Stranger"), &pb.StateCommitmentsRequest{ChannelId: "mychannel"})
+	deserializer.Msg = env.Payload
+	_, err = server.GetStateCommitments(context.Background(), env)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "access denied: the creator is not a member of the local MSP")
+}
+
+func TestCompareState(t *testing.T) {
+	remote := &mockStateComparatorClient{batchSize: 2}
+	support := &mockStateComparatorSupport{height: 8, peerHeights: map[string]uint64{"peer1:7051": 6}, remote: remote}
+	server, deserializer := newTestStateComparatorServer(support, []byte("Admin"))
+
+	compare := func(req *pb.CompareStateRequest) (*pb.CompareStateResponse, error) {
+		env := signedRequest(t, []byte("Admin"), req)
+		deserializer.Msg = env.Payload
+		return server.CompareState(context.Background(), env)
+	}
+
+	// the blocks are compared up to the last block committed by both peers
+	resp, err := compare(&pb.CompareStateRequest{ChannelId: "mychannel", PeerEndpoint: "peer1:7051"})
+	assert.NoError(t, err)
+	assert.Equal(t, &pb.CompareStateResponse{ComparedBlocks: 6}, resp)
+	assert.Len(t, remote.requests, 3)
+	assert.Equal(t, &pb.StateCommitmentsRequest{ChannelId: "mychannel", StartBlock: 4, EndBlock: 5}, remote.requests[2])
+	assert.True(t, support.closed)
+
+	resp, err = compare(&pb.CompareStateRequest{ChannelId: "mychannel", PeerEndpoint: "peer1:7051", StartBlock: 2, EndBlock: 4})
+	assert.NoError(t, err)
+	assert.Equal(t, &pb.CompareStateResponse{ComparedBlocks: 3}, resp)
+
+	// the first divergent block is reported
+	remote.overrides = map[uint64]string{3: "remote3", 5: "remote5"}
+	resp, err = compare(&pb.CompareStateRequest{ChannelId: "mychannel", PeerEndpoint: "peer1:7051"})
+	assert.NoError(t, err)
+	assert.Equal(t, &pb.CompareStateResponse{
+		Diverged:            true,
+		FirstDivergentBlock: 3,
+		Local:               &pb.BlockStateCommitment{BlockNum: 3, Commitment: []byte{3}},
+		Remote:              &pb.BlockStateCommitment{BlockNum: 3, Commitment: []byte("remote3")},
+		ComparedBlocks:      3,
+	}, resp)
+
+	// invalid requests
+	_, err = compare(&pb.CompareStateRequest{PeerEndpoint: "peer1:7051"})
+	assert.EqualError(t, err, "missing channel ID in the request")
+	_, err = compare(&pb.CompareStateRequest{ChannelId: "mychannel"})
+	assert.EqualError(t, err, "missing peer endpoint in the request")
+	_, err = compare(&pb.CompareStateRequest{ChannelId: "mychannel", PeerEndpoint: "peer2:7051"})
+	assert.EqualError(t, err, "unknown peer")
+	_, err = compare(&pb.CompareStateRequest{ChannelId: "mychannel", PeerEndpoint: "peer1:7051", StartBlock: 6})
+	assert.EqualError(t, err, "block [6] is not committed by both peers, their ledgers have heights 8 and 6")
+	_, err = compare(&pb.CompareStateRequest{ChannelId: "mychannel", PeerEndpoint: "peer1:7051", StartBlock: 3, EndBlock: 2})
+	assert.EqualError(t, err, "invalid block range [3, 2]")
+
+	// the peer cannot be reached
+	support.remote = nil
+	_, err = compare(&pb.CompareStateRequest{ChannelId: "mychannel", PeerEndpoint: "peer1:7051"})
+	assert.EqualError(t, err, "failed connecting to peer [peer1:7051]: connection refused")
+
+	// the comparisons are restricted to the admins of the local MSP
+	deserializer.Identity = []byte("Member")
+	env := signedRequest(t, []byte("Member"), &pb.CompareStateRequest{ChannelId: "mychannel", PeerEndpoint: "peer1:7051"})
+	deserializer.Msg = env.Payload
+	_, err = server.CompareState(context.Background(), env)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "access denied: the creator is not an admin of the local MSP")
+}
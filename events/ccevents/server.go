@@ -0,0 +1,180 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package ccevents
+
+import (
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/policies"
+	"github.com/hyperledger/fabric/protos/common"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	"github.com/hyperledger/fabric/protos/utils"
+)
+
+// Ledger is the subset of the ledger of a channel the Server reads the committed blocks from
+type Ledger interface {
+	GetBlockchainInfo() (*common.BlockchainInfo, error)
+	GetBlockByNumber(blockNumber uint64) (*common.Block, error)
+}
+
+// Support provides the resources of the channels the peer has joined
+type Support interface {
+	// Ledger returns the ledger of the channel, or nil if the peer has not joined it
+	Ledger(channelID string) Ledger
+
+	// PolicyManager returns the policy manager of the channel, or nil if the peer has not joined it
+	PolicyManager(channelID string) policies.Manager
+}
+
+// Server implements the ChaincodeEvents service
+type Server struct {
+	store   *Store
+	support Support
+}
+
+// NewServer creates a server delivering the chaincode events persisted in the given store
+func NewServer(store *Store, support Support) *Server {
+	return &Server{
+		store:   store,
+		support: support,
+	}
+}
+
+// Deliver sends to a client, authorized by the Readers policy of the channel, the matching events
+// of the committed blocks from the requested start block on, and then of the blocks as they are
+// committed. The events of the blocks committed before the store was created are read from the ledger.
+func (s *Server) Deliver(env *common.Envelope, stream pb.ChaincodeEvents_DeliverServer) error {
+	payload, err := utils.UnmarshalPayload(env.Payload)
+	if err != nil {
+		logger.Warningf("Received an envelope with no payload: %s", err)
+		return sendStatus(stream, common.Status_BAD_REQUEST)
+	}
+	if payload.Header == nil {
+		logger.Warningf("Received an envelope with no header")
+		return sendStatus(stream, common.Status_BAD_REQUEST)
+	}
+	chdr, err := utils.UnmarshalChannelHeader(payload.Header.ChannelHeader)
+	if err != nil {
+		logger.Warningf("Failed to unmarshal channel header: %s", err)
+		return sendStatus(stream, common.Status_BAD_REQUEST)
+	}
+	request := &pb.ChaincodeEventsRequest{}
+	if err := proto.Unmarshal(payload.Data, request); err != nil {
+		logger.Warningf("[channel: %s] Received an invalid chaincode events request: %s", chdr.ChannelId, err)
+		return sendStatus(stream, common.Status_BAD_REQUEST)
+	}
+	if request.ChaincodeId == "" {
+		logger.Warningf("[channel: %s] Received a chaincode events request with no chaincode ID", chdr.ChannelId)
+		return sendStatus(stream, common.Status_BAD_REQUEST)
+	}
+	signedData, err := env.AsSignedData()
+	if err != nil {
+		logger.Warningf("[channel: %s] Received a chaincode events request with no signature: %s", chdr.ChannelId, err)
+		return sendStatus(stream, common.Status_BAD_REQUEST)
+	}
+
+	logger.Debugf("[channel: %s] Delivering the events %q of chaincode %s from block %d", chdr.ChannelId, request.EventName, request.ChaincodeId, request.StartBlock)
+	next := request.StartBlock
+	for {
+		// wait for the commit of the blocks following those read
+		signal := s.store.Wait(chdr.ChannelId)
+
+		ledger := s.support.Ledger(chdr.ChannelId)
+		if ledger == nil {
+			return sendStatus(stream, common.Status_NOT_FOUND)
+		}
+		// the access is checked again as new blocks are committed, in case it got revoked
+		if status := s.checkAccess(chdr.ChannelId, signedData); status != common.Status_SUCCESS {
+			return sendStatus(stream, status)
+		}
+
+		info, err := ledger.GetBlockchainInfo()
+		if err != nil {
+			logger.Errorf("[channel: %s] Failed to read the height of the ledger: %s", chdr.ChannelId, err)
+			return sendStatus(stream, common.Status_SERVICE_UNAVAILABLE)
+		}
+		for ; next < info.Height; next++ {
+			events, err := s.blockEvents(chdr.ChannelId, ledger, next)
+			if err != nil {
+				logger.Errorf("[channel: %s] Failed to read the events of block %d: %s", chdr.ChannelId, next, err)
+				return sendStatus(stream, common.Status_SERVICE_UNAVAILABLE)
+			}
+			matching := filterEvents(events, request)
+			if len(matching.Events) == 0 {
+				continue
+			}
+			if err := stream.Send(&pb.ChaincodeEventsResponse{Type: &pb.ChaincodeEventsResponse_BlockEvents{BlockEvents: matching}}); err != nil {
+				logger.Warningf("[channel: %s] Failed to send the events of block %d: %s", chdr.ChannelId, next, err)
+				return err
+			}
+		}
+
+		select {
+		case <-signal:
+		case <-stream.Context().Done():
+			logger.Debugf("[channel: %s] Client stopped receiving chaincode events", chdr.ChannelId)
+			return nil
+		}
+	}
+}
+
+func (s *Server) checkAccess(channelID string, signedData []*common.SignedData) common.Status {
+	policyManager := s.support.PolicyManager(channelID)
+	if policyManager == nil {
+		return common.Status_NOT_FOUND
+	}
+	policy, ok := policyManager.GetPolicy(policies.ChannelApplicationReaders)
+	if !ok {
+		logger.Errorf("[channel: %s] Could not find policy %s", channelID, policies.ChannelApplicationReaders)
+		return common.Status_FORBIDDEN
+	}
+	if err := policy.Evaluate(signedData); err != nil {
+		logger.Warningf("[channel: %s] Chaincode events request not authorized: %s", channelID, err)
+		return common.Status_FORBIDDEN
+	}
+	return common.Status_SUCCESS
+}
+
+// blockEvents returns the chaincode events of a block, which are read from the ledger and
+// persisted if the block was committed before the store was created
+func (s *Server) blockEvents(channelID string, ledger Ledger, blockNumber uint64) (*pb.ChaincodeBlockEvents, error) {
+	events, err := s.store.BlockEvents(channelID, blockNumber)
+	if err != nil || events != nil {
+		return events, err
+	}
+
+	block, err := ledger.GetBlockByNumber(blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	events, err = ExtractBlockEvents(block)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.store.put(channelID, events); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// filterEvents returns the events of a block matching the chaincode ID and, if set, the event name of a request
+func filterEvents(events *pb.ChaincodeBlockEvents, request *pb.ChaincodeEventsRequest) *pb.ChaincodeBlockEvents {
+	matching := &pb.ChaincodeBlockEvents{BlockNumber: events.BlockNumber}
+	for _, event := range events.Events {
+		if event.ChaincodeId != request.ChaincodeId {
+			continue
+		}
+		if request.EventName != "" && event.EventName != request.EventName {
+			continue
+		}
+		matching.Events = append(matching.Events, event)
+	}
+	return matching
+}
+
+func sendStatus(stream pb.ChaincodeEvents_DeliverServer, status common.Status) error {
+	return stream.Send(&pb.ChaincodeEventsResponse{Type: &pb.ChaincodeEventsResponse_Status{Status: status}})
+}
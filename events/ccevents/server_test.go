@@ -0,0 +1,206 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package ccevents
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	mockpolicies "github.com/hyperledger/fabric/common/mocks/policies"
+	"github.com/hyperledger/fabric/common/policies"
+	"github.com/hyperledger/fabric/protos/common"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+)
+
+type mockLedger struct {
+	sync.Mutex
+	blocks []*common.Block
+}
+
+func (l *mockLedger) GetBlockchainInfo() (*common.BlockchainInfo, error) {
+	l.Lock()
+	defer l.Unlock()
+	return &common.BlockchainInfo{Height: uint64(len(l.blocks))}, nil
+}
+
+func (l *mockLedger) GetBlockByNumber(blockNumber uint64) (*common.Block, error) {
+	l.Lock()
+	defer l.Unlock()
+	if blockNumber >= uint64(len(l.blocks)) {
+		return nil, errors.New("no such block")
+	}
+	return l.blocks[blockNumber], nil
+}
+
+func (l *mockLedger) append(block *common.Block) {
+	l.Lock()
+	defer l.Unlock()
+	l.blocks = append(l.blocks, block)
+}
+
+type mockSupport struct {
+	ledger        *mockLedger
+	policyManager *mockpolicies.Manager
+}
+
+func (s *mockSupport) Ledger(channelID string) Ledger {
+	if channelID != testChannelID {
+		return nil
+	}
+	return s.ledger
+}
+
+func (s *mockSupport) PolicyManager(channelID string) policies.Manager {
+	if channelID != testChannelID {
+		return nil
+	}
+	return s.policyManager
+}
+
+type mockStream struct {
+	grpc.ServerStream
+	ctx       context.Context
+	responses chan *pb.ChaincodeEventsResponse
+}
+
+func newMockStream() (*mockStream, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &mockStream{ctx: ctx, responses: make(chan *pb.ChaincodeEventsResponse, 10)}, cancel
+}
+
+func (m *mockStream) Context() context.Context {
+	return m.ctx
+}
+
+func (m *mockStream) Send(response *pb.ChaincodeEventsResponse) error {
+	m.responses <- response
+	return nil
+}
+
+func (m *mockStream) next(t *testing.T) *pb.ChaincodeEventsResponse {
+	select {
+	case response := <-m.responses:
+		return response
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for a response")
+		return nil
+	}
+}
+
+func makeRequest(channelID string, request *pb.ChaincodeEventsRequest) *common.Envelope {
+	return &common.Envelope{Payload: utils.MarshalOrPanic(&common.Payload{
+		Header: &common.Header{
+			ChannelHeader:   utils.MarshalOrPanic(&common.ChannelHeader{ChannelId: channelID}),
+			SignatureHeader: utils.MarshalOrPanic(&common.SignatureHeader{Creator: []byte("client")}),
+		},
+		Data: utils.MarshalOrPanic(request),
+	})}
+}
+
+func newTestServer(t *testing.T) (*Server, *mockSupport, func()) {
+	store, cleanup := newTestStore(t)
+	support := &mockSupport{
+		ledger:        &mockLedger{},
+		policyManager: &mockpolicies.Manager{Policy: &mockpolicies.Policy{}},
+	}
+	return NewServer(store, support), support, cleanup
+}
+
+func TestDeliverReplay(t *testing.T) {
+	server, support, cleanup := newTestServer(t)
+	defer cleanup()
+
+	transfer := &pb.ChaincodeEvent{ChaincodeId: "mycc", EventName: "transfer", Payload: []byte("1")}
+	mint := &pb.ChaincodeEvent{ChaincodeId: "mycc", EventName: "mint", Payload: []byte("2")}
+	other := &pb.ChaincodeEvent{ChaincodeId: "othercc", EventName: "transfer", Payload: []byte("3")}
+
+	// blocks 1 and 2 were committed before the store was created, so their events are read from the ledger
+	support.ledger.append(makeBlock(t, 0, []*pb.ChaincodeEvent{transfer, other}))
+	require.NoError(t, server.store.Persist(support.ledger.blocks[0]))
+	support.ledger.append(makeBlock(t, 1, []*pb.ChaincodeEvent{mint, transfer}))
+	support.ledger.append(makeBlock(t, 2, []*pb.ChaincodeEvent{other}))
+
+	stream, cancel := newMockStream()
+	done := make(chan error)
+	go func() {
+		done <- server.Deliver(makeRequest(testChannelID, &pb.ChaincodeEventsRequest{ChaincodeId: "mycc", EventName: "transfer"}), stream)
+	}()
+
+	response := stream.next(t)
+	assert.Equal(t, uint64(0), response.GetBlockEvents().BlockNumber)
+	require.Len(t, response.GetBlockEvents().Events, 1)
+	assert.Equal(t, []byte("1"), response.GetBlockEvents().Events[0].Payload)
+
+	response = stream.next(t)
+	assert.Equal(t, uint64(1), response.GetBlockEvents().BlockNumber)
+	require.Len(t, response.GetBlockEvents().Events, 1)
+	events, err := server.store.BlockEvents(testChannelID, 1)
+	require.NoError(t, err)
+	assert.Len(t, events.Events, 2, "the events read from the ledger should be persisted")
+
+	// the events of the blocks are delivered as they are committed
+	support.ledger.append(makeBlock(t, 3, []*pb.ChaincodeEvent{mint, transfer}))
+	require.NoError(t, server.store.Persist(support.ledger.blocks[3]))
+	response = stream.next(t)
+	assert.Equal(t, uint64(3), response.GetBlockEvents().BlockNumber)
+
+	cancel()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("Deliver did not return once the client went away")
+	}
+}
+
+func TestDeliverStartBlock(t *testing.T) {
+	server, support, cleanup := newTestServer(t)
+	defer cleanup()
+
+	mint := &pb.ChaincodeEvent{ChaincodeId: "mycc", EventName: "mint"}
+	for i := uint64(0); i < 3; i++ {
+		support.ledger.append(makeBlock(t, i, []*pb.ChaincodeEvent{mint}))
+	}
+
+	stream, cancel := newMockStream()
+	defer cancel()
+	go server.Deliver(makeRequest(testChannelID, &pb.ChaincodeEventsRequest{ChaincodeId: "mycc", StartBlock: 2}), stream)
+	assert.Equal(t, uint64(2), stream.next(t).GetBlockEvents().BlockNumber)
+}
+
+func TestDeliverRejected(t *testing.T) {
+	server, support, cleanup := newTestServer(t)
+	defer cleanup()
+
+	for _, test := range []struct {
+		name      string
+		env       *common.Envelope
+		policyErr error
+		status    common.Status
+	}{
+		{name: "NoPayload", env: &common.Envelope{Payload: []byte("garbage")}, status: common.Status_BAD_REQUEST},
+		{name: "NoHeader", env: &common.Envelope{Payload: utils.MarshalOrPanic(&common.Payload{})}, status: common.Status_BAD_REQUEST},
+		{name: "NoChaincode", env: makeRequest(testChannelID, &pb.ChaincodeEventsRequest{}), status: common.Status_BAD_REQUEST},
+		{name: "UnknownChannel", env: makeRequest("otherchannel", &pb.ChaincodeEventsRequest{ChaincodeId: "mycc"}), status: common.Status_NOT_FOUND},
+		{name: "Forbidden", env: makeRequest(testChannelID, &pb.ChaincodeEventsRequest{ChaincodeId: "mycc"}), policyErr: errors.New("not a reader"), status: common.Status_FORBIDDEN},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			support.policyManager.Policy.Err = test.policyErr
+			stream, cancel := newMockStream()
+			defer cancel()
+			assert.NoError(t, server.Deliver(test.env, stream))
+			assert.Equal(t, test.status, stream.next(t).GetStatus())
+		})
+	}
+}
@@ -0,0 +1,194 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package ccevents delivers the chaincode events of the committed blocks. Unlike the events of
+// the event hub, which are lost for the consumers not connected when they are sent, the events
+// are persisted per block, so that a client may replay them from any block.
+package ccevents
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/common/ledger/util"
+	"github.com/hyperledger/fabric/common/ledger/util/leveldbhelper"
+	ledgerUtil "github.com/hyperledger/fabric/core/ledger/util"
+	"github.com/hyperledger/fabric/protos/common"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	"github.com/hyperledger/fabric/protos/utils"
+)
+
+var logger = flogging.MustGetLogger("events/ccevents")
+
+// Store persists, per channel, the chaincode events emitted by the valid transactions of the
+// committed blocks, and signals the commit of new blocks to the deliveries waiting for them
+type Store struct {
+	provider *leveldbhelper.Provider
+	mutex    sync.Mutex
+	signals  map[string]chan struct{}
+}
+
+// NewStore opens the store at the given path
+func NewStore(dbPath string) *Store {
+	return &Store{
+		provider: leveldbhelper.NewProvider(&leveldbhelper.Conf{DBPath: dbPath}),
+		signals:  make(map[string]chan struct{}),
+	}
+}
+
+// Persist records the chaincode events of a committed block, and signals its commit
+func (s *Store) Persist(block *common.Block) error {
+	channelID, err := utils.GetChainIDFromBlock(block)
+	if err != nil {
+		return fmt.Errorf("could not determine channel of block %d: %s", block.Header.Number, err)
+	}
+
+	events, err := ExtractBlockEvents(block)
+	if err != nil {
+		return err
+	}
+
+	if err := s.put(channelID, events); err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	if signal, ok := s.signals[channelID]; ok {
+		close(signal)
+		delete(s.signals, channelID)
+	}
+	s.mutex.Unlock()
+	return nil
+}
+
+// BlockEvents returns the chaincode events of a block of a channel, or nil if they are not persisted
+func (s *Store) BlockEvents(channelID string, blockNumber uint64) (*pb.ChaincodeBlockEvents, error) {
+	eventsBytes, err := s.provider.GetDBHandle(channelID).Get(util.EncodeOrderPreservingVarUint64(blockNumber))
+	if err != nil {
+		return nil, err
+	}
+	if eventsBytes == nil {
+		return nil, nil
+	}
+
+	events := &pb.ChaincodeBlockEvents{}
+	if err := proto.Unmarshal(eventsBytes, events); err != nil {
+		return nil, fmt.Errorf("could not unmarshal events of block %d: %s", blockNumber, err)
+	}
+	return events, nil
+}
+
+// Wait returns a channel which is closed once the next block of the channel is persisted
+func (s *Store) Wait(channelID string) <-chan struct{} {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	signal, ok := s.signals[channelID]
+	if !ok {
+		signal = make(chan struct{})
+		s.signals[channelID] = signal
+	}
+	return signal
+}
+
+// Close closes the store
+func (s *Store) Close() {
+	s.provider.Close()
+}
+
+func (s *Store) put(channelID string, events *pb.ChaincodeBlockEvents) error {
+	eventsBytes, err := proto.Marshal(events)
+	if err != nil {
+		return err
+	}
+	return s.provider.GetDBHandle(channelID).Put(util.EncodeOrderPreservingVarUint64(events.BlockNumber), eventsBytes, true)
+}
+
+// ExtractBlockEvents returns the chaincode events emitted by the valid transactions of a committed block
+func ExtractBlockEvents(block *common.Block) (*pb.ChaincodeBlockEvents, error) {
+	events := &pb.ChaincodeBlockEvents{BlockNumber: block.Header.Number}
+	if block.Data == nil {
+		return events, nil
+	}
+
+	var txsFilter ledgerUtil.TxValidationFlags
+	if block.Metadata != nil && len(block.Metadata.Metadata) > int(common.BlockMetadataIndex_TRANSACTIONS_FILTER) {
+		txsFilter = ledgerUtil.TxValidationFlags(block.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER])
+	}
+
+	for txIndex, envBytes := range block.Data.Data {
+		// the transactions of a block which was not validated are not taken into account
+		if txIndex >= len(txsFilter) || txsFilter.IsInvalid(txIndex) {
+			continue
+		}
+
+		env, err := utils.GetEnvelopeFromBlock(envBytes)
+		if err != nil {
+			return nil, fmt.Errorf("could not unmarshal transaction %d of block %d: %s", txIndex, block.Header.Number, err)
+		}
+		payload, err := utils.GetPayload(env)
+		if err != nil {
+			return nil, fmt.Errorf("could not unmarshal payload of transaction %d of block %d: %s", txIndex, block.Header.Number, err)
+		}
+		if payload.Header == nil {
+			continue
+		}
+		chdr, err := utils.UnmarshalChannelHeader(payload.Header.ChannelHeader)
+		if err != nil {
+			return nil, fmt.Errorf("could not unmarshal channel header of transaction %d of block %d: %s", txIndex, block.Header.Number, err)
+		}
+		if common.HeaderType(chdr.Type) != common.HeaderType_ENDORSER_TRANSACTION {
+			continue
+		}
+
+		action, err := utils.GetActionFromEnvelope(envBytes)
+		if err != nil {
+			return nil, fmt.Errorf("could not read chaincode action of transaction %d of block %d: %s", txIndex, block.Header.Number, err)
+		}
+		if len(action.Events) == 0 {
+			continue
+		}
+		event, err := utils.GetChaincodeEvents(action.Events)
+		if err != nil {
+			return nil, fmt.Errorf("could not unmarshal chaincode event of transaction %d of block %d: %s", txIndex, block.Header.Number, err)
+		}
+		if event.ChaincodeId == "" {
+			continue
+		}
+		events.Events = append(events.Events, event)
+	}
+
+	return events, nil
+}
+
+var defaultStore struct {
+	sync.RWMutex
+	store *Store
+}
+
+// Initialize opens the store the blocks committed by the peer are recorded in, at the given path
+func Initialize(dbPath string) *Store {
+	defaultStore.Lock()
+	defer defaultStore.Unlock()
+	defaultStore.store = NewStore(dbPath)
+	return defaultStore.store
+}
+
+// BlockCommitted records the chaincode events of a block committed by the peer, if the store
+// of the peer is initialized
+func BlockCommitted(block *common.Block) {
+	defaultStore.RLock()
+	store := defaultStore.store
+	defaultStore.RUnlock()
+	if store == nil {
+		return
+	}
+
+	if err := store.Persist(block); err != nil {
+		logger.Errorf("Could not persist the chaincode events of block %d: %s", block.Header.Number, err)
+	}
+}
@@ -0,0 +1,130 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package ccevents
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	ledgerUtil "github.com/hyperledger/fabric/core/ledger/util"
+	"github.com/hyperledger/fabric/protos/common"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testChannelID = "testchannel"
+
+func newTestStore(t *testing.T) (*Store, func()) {
+	dir, err := ioutil.TempDir("", "ccevents")
+	require.NoError(t, err)
+	store := NewStore(dir)
+	return store, func() {
+		store.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func makeTransaction(eventBytes []byte) *common.Envelope {
+	action := &pb.ChaincodeAction{
+		Events:      eventBytes,
+		Response:    &pb.Response{Status: 200},
+		ChaincodeId: &pb.ChaincodeID{Name: "mycc", Version: "1.0"},
+	}
+	actionPayload := &pb.ChaincodeActionPayload{
+		Action: &pb.ChaincodeEndorsedAction{
+			ProposalResponsePayload: utils.MarshalOrPanic(&pb.ProposalResponsePayload{Extension: utils.MarshalOrPanic(action)}),
+		},
+	}
+	tx := &pb.Transaction{Actions: []*pb.TransactionAction{{Payload: utils.MarshalOrPanic(actionPayload)}}}
+	return &common.Envelope{Payload: utils.MarshalOrPanic(&common.Payload{
+		Header: &common.Header{
+			ChannelHeader: utils.MarshalOrPanic(&common.ChannelHeader{
+				Type:      int32(common.HeaderType_ENDORSER_TRANSACTION),
+				ChannelId: testChannelID,
+			}),
+		},
+		Data: utils.MarshalOrPanic(tx),
+	})}
+}
+
+// makeBlock creates a block whose transactions emit the given events, a nil event meaning that
+// the transaction emits none, and whose transactions at the given indexes are invalid
+func makeBlock(t *testing.T, number uint64, events []*pb.ChaincodeEvent, invalid ...int) *common.Block {
+	block := common.NewBlock(number, nil)
+	txsFilter := ledgerUtil.NewTxValidationFlags(len(events))
+	for _, event := range events {
+		var eventBytes []byte
+		if event != nil {
+			eventBytes = utils.MarshalOrPanic(event)
+		}
+		block.Data.Data = append(block.Data.Data, utils.MarshalOrPanic(makeTransaction(eventBytes)))
+	}
+	for _, i := range invalid {
+		txsFilter.SetFlag(i, pb.TxValidationCode_MVCC_READ_CONFLICT)
+	}
+	block.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER] = txsFilter
+	return block
+}
+
+func TestExtractBlockEvents(t *testing.T) {
+	event1 := &pb.ChaincodeEvent{ChaincodeId: "mycc", EventName: "transfer", Payload: []byte("1")}
+	event2 := &pb.ChaincodeEvent{ChaincodeId: "mycc", EventName: "transfer", Payload: []byte("2")}
+	event3 := &pb.ChaincodeEvent{ChaincodeId: "mycc", EventName: "mint", Payload: []byte("3")}
+
+	events, err := ExtractBlockEvents(makeBlock(t, 5, []*pb.ChaincodeEvent{event1, nil, event2, event3}, 2))
+	require.NoError(t, err)
+	assert.Equal(t, uint64(5), events.BlockNumber)
+	require.Len(t, events.Events, 2)
+	assert.Equal(t, []byte("1"), events.Events[0].Payload)
+	assert.Equal(t, []byte("3"), events.Events[1].Payload)
+
+	// a block which was not validated has no events
+	block := makeBlock(t, 6, []*pb.ChaincodeEvent{event1})
+	block.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER] = nil
+	events, err = ExtractBlockEvents(block)
+	require.NoError(t, err)
+	assert.Empty(t, events.Events)
+
+	block.Data.Data = [][]byte{[]byte("garbage")}
+	block.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER] = ledgerUtil.NewTxValidationFlags(1)
+	_, err = ExtractBlockEvents(block)
+	assert.Error(t, err)
+}
+
+func TestStore(t *testing.T) {
+	store, cleanup := newTestStore(t)
+	defer cleanup()
+
+	events, err := store.BlockEvents(testChannelID, 0)
+	require.NoError(t, err)
+	assert.Nil(t, events)
+
+	signal := store.Wait(testChannelID)
+	event := &pb.ChaincodeEvent{ChaincodeId: "mycc", EventName: "transfer", Payload: []byte("1")}
+	require.NoError(t, store.Persist(makeBlock(t, 0, []*pb.ChaincodeEvent{event})))
+	select {
+	case <-signal:
+	case <-time.After(time.Second):
+		t.Fatal("The commit of the block was not signaled")
+	}
+
+	events, err = store.BlockEvents(testChannelID, 0)
+	require.NoError(t, err)
+	require.Len(t, events.Events, 1)
+	assert.Equal(t, "transfer", events.Events[0].EventName)
+
+	// the events are recorded per channel
+	events, err = store.BlockEvents("otherchannel", 0)
+	require.NoError(t, err)
+	assert.Nil(t, events)
+
+	assert.Error(t, store.Persist(&common.Block{Header: &common.BlockHeader{Number: 1}, Data: &common.BlockData{}}))
+}
@@ -0,0 +1,155 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package comm
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/hyperledger/fabric/gossip/common"
+	proto "github.com/hyperledger/fabric/protos/gossip"
+)
+
+// SendResult is the outcome of sending a message to a remote peer waiting for its acknowledgement
+type SendResult struct {
+	error
+	RemotePeer
+}
+
+// Error returns the error of the send, or an empty string if the peer acknowledged the message
+func (sr SendResult) Error() string {
+	if sr.error != nil {
+		return sr.error.Error()
+	}
+	return ""
+}
+
+// AggregatedSendResult is the outcome of sending a message to several remote peers
+type AggregatedSendResult []SendResult
+
+// AckCount returns the number of peers that acknowledged the message
+func (ar AggregatedSendResult) AckCount() int {
+	c := 0
+	for _, ack := range ar {
+		if ack.error == nil {
+			c++
+		}
+	}
+	return c
+}
+
+// NackCount returns the number of peers that didn't acknowledge the message
+func (ar AggregatedSendResult) NackCount() int {
+	return len(ar) - ar.AckCount()
+}
+
+// String returns a summary of the errors the message was sent to the peers with
+func (ar AggregatedSendResult) String() string {
+	errMap := map[string]int{}
+	for _, ack := range ar {
+		if ack.error == nil {
+			continue
+		}
+		errMap[ack.Error()]++
+	}
+
+	ackCount := ar.AckCount()
+	output := map[string]interface{}{}
+	if ackCount > 0 {
+		output["successes"] = ackCount
+	}
+	if ackCount < len(ar) {
+		output["failures"] = errMap
+	}
+	return fmt.Sprintf("%v", output)
+}
+
+// topicForAck returns the topic the acknowledgement of the message
+// with the given nonce is published to, once the given peer sends it
+func topicForAck(nonce uint64, pkiID common.PKIidType) string {
+	return fmt.Sprintf("%d %s", nonce, hex.EncodeToString(pkiID))
+}
+
+// SendWithAck sends the message to the remote peers, and waits for minAck of them to acknowledge it, or
+// for the timeout to expire. It returns the results of the peers that responded, or failed, in the meantime
+func (c *commImpl) SendWithAck(msg *proto.SignedGossipMessage, timeout time.Duration, minAck int, peers ...*RemotePeer) AggregatedSendResult {
+	if len(peers) == 0 {
+		return nil
+	}
+	if minAck > len(peers) {
+		minAck = len(peers)
+	}
+
+	results := make(chan SendResult, len(peers))
+	for _, peer := range peers {
+		// Subscribe before sending, so that the acknowledgement isn't missed
+		sub := c.pubSub.Subscribe(topicForAck(msg.Nonce, peer.PKIID), timeout)
+		go func(peer *RemotePeer) {
+			results <- SendResult{error: c.sendAndWaitForAck(peer, msg, sub.Listen), RemotePeer: *peer}
+		}(peer)
+	}
+
+	var aggregated AggregatedSendResult
+	for range peers {
+		aggregated = append(aggregated, <-results)
+		if aggregated.AckCount() >= minAck {
+			break
+		}
+	}
+	return aggregated
+}
+
+// sendAndWaitForAck sends the message to the remote peer, and returns the error the peer failed processing it with,
+// or the error sending it failed with, or the error waiting for the acknowledgement of the peer failed with
+func (c *commImpl) sendAndWaitForAck(peer *RemotePeer, msg *proto.SignedGossipMessage, waitForAck func() (interface{}, error)) error {
+	if err := c.sendToEndpoint(peer, msg); err != nil {
+		return err
+	}
+	ack, err := waitForAck()
+	if err != nil {
+		return err
+	}
+	ackMsg, isAck := ack.(*proto.Acknowledgement)
+	if !isAck {
+		return fmt.Errorf("Received a message of type %s, expected *proto.Acknowledgement", reflect.TypeOf(ack))
+	}
+	if ackMsg.Error != "" {
+		return errors.New(ackMsg.Error)
+	}
+	return nil
+}
+
+// handleAck publishes the acknowledgement received from the remote peer to the
+// subscription waiting for it, and returns whether the message is an acknowledgement
+func (c *commImpl) handleAck(m *proto.SignedGossipMessage, pkiID common.PKIidType) bool {
+	ack := m.GetAck()
+	if ack == nil {
+		return false
+	}
+	if err := c.pubSub.Publish(topicForAck(m.Nonce, pkiID), ack); err != nil {
+		c.logger.Debug("Got an acknowledgement from", pkiID, "for nonce", m.Nonce, "no one is waiting for:", err)
+	}
+	return true
+}
+
+// ackFor creates the acknowledgement for the message with the given nonce
+func ackFor(nonce uint64, err error) *proto.GossipMessage {
+	ack := &proto.Acknowledgement{}
+	if err != nil {
+		ack.Error = err.Error()
+	}
+	return &proto.GossipMessage{
+		Tag:   proto.GossipMessage_EMPTY,
+		Nonce: nonce,
+		Content: &proto.GossipMessage_Ack{
+			Ack: ack,
+		},
+	}
+}
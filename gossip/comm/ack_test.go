@@ -0,0 +1,67 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package comm
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSendWithAck(t *testing.T) {
+	t.Parallel()
+	comm1, _ := newCommInstance(9640, naiveSec)
+	comm2, _ := newCommInstance(9641, naiveSec)
+	comm3, _ := newCommInstance(9642, naiveSec)
+	defer comm1.Stop()
+	defer comm2.Stop()
+	defer comm3.Stop()
+
+	// comm2 acknowledges the messages it receives, comm3 rejects them
+	go func() {
+		for m := range comm2.Accept(acceptAll) {
+			m.Ack(nil)
+		}
+	}()
+	go func() {
+		for m := range comm3.Accept(acceptAll) {
+			m.Ack(errors.New("bad block"))
+		}
+	}()
+
+	res := comm1.SendWithAck(createGossipMsg(), 5*time.Second, 2, remotePeer(9641), remotePeer(9642))
+	assert.Len(t, res, 2)
+	assert.Equal(t, 1, res.AckCount())
+	assert.Equal(t, 1, res.NackCount())
+	for _, r := range res {
+		if r.RemotePeer.Endpoint == "localhost:9642" {
+			assert.Equal(t, "bad block", r.Error())
+		} else {
+			assert.Empty(t, r.Error())
+		}
+	}
+	assert.Contains(t, res.String(), "bad block")
+
+	// Once minAck peers acknowledged the message, the rest of the peers aren't waited for
+	res = comm1.SendWithAck(createGossipMsg(), 5*time.Second, 1, remotePeer(9641), remotePeer(9643))
+	assert.Len(t, res, 1)
+	assert.Equal(t, 1, res.AckCount())
+
+	// A peer that doesn't send an acknowledgement times out
+	comm4, _ := newCommInstance(9643, naiveSec)
+	defer comm4.Stop()
+	comm4.Accept(acceptAll)
+	start := time.Now()
+	res = comm1.SendWithAck(createGossipMsg(), time.Second, 1, remotePeer(9643))
+	assert.Equal(t, 0, res.AckCount())
+	assert.Equal(t, 1, res.NackCount())
+	assert.True(t, time.Since(start) >= time.Second)
+
+	assert.Empty(t, comm1.SendWithAck(createGossipMsg(), time.Second, 1))
+}
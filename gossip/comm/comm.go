@@ -8,6 +8,7 @@ package comm
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/hyperledger/fabric/gossip/api"
 	"github.com/hyperledger/fabric/gossip/common"
@@ -24,6 +25,10 @@ type Comm interface {
 	// Send sends a message to remote peers
 	Send(msg *proto.SignedGossipMessage, peers ...*RemotePeer)
 
+	// SendWithAck sends a message to remote peers, waiting for acknowledgement from minAck of them,
+	// or until a certain timeout expires. The acknowledgements are matched by the nonce of the message
+	SendWithAck(msg *proto.SignedGossipMessage, timeout time.Duration, minAck int, peers ...*RemotePeer) AggregatedSendResult
+
 	// Probe probes a remote node and returns nil if its responsive,
 	// and an error if it's not.
 	Probe(peer *RemotePeer) error
@@ -8,7 +8,6 @@ package comm
 
 import (
 	"bytes"
-	"crypto/tls"
 	"errors"
 	"fmt"
 	"net"
@@ -17,6 +16,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/hyperledger/fabric/common/metrics"
 	"github.com/hyperledger/fabric/gossip/api"
 	"github.com/hyperledger/fabric/gossip/common"
 	"github.com/hyperledger/fabric/gossip/identity"
@@ -56,18 +56,40 @@ func NewCommInstanceWithServer(port int, idMapper identity.Mapper, peerIdentity
 
 	var ll net.Listener
 	var s *grpc.Server
-	var certHash []byte
+	var certs *TLSCertificates
 
-	if len(dialOpts) == 0 {
-		dialOpts = []grpc.DialOption{grpc.WithTimeout(util.GetDurationOrDefault("peer.gossip.dialTimeout", defDialTimeout))}
+	if port > 0 {
+		s, ll, secureDialOpts, certs = createGRPCLayer(port)
 	}
 
-	if port > 0 {
-		s, ll, secureDialOpts, certHash = createGRPCLayer(port)
+	return newCommInstanceWithServer(port, s, ll, certs, idMapper, peerIdentity, secureDialOpts, dialOpts...)
+}
+
+// NewSecureCommInstanceWithServer creates a comm instance that creates an underlying gRPC server,
+// which presents and verifies TLS certificates according to the given secure config
+func NewSecureCommInstanceWithServer(port int, secureConf *SecureConfig, idMapper identity.Mapper,
+	peerIdentity api.PeerIdentityType, dialOpts ...grpc.DialOption) (Comm, error) {
+
+	if err := secureConf.Certificates.validate(); err != nil {
+		return nil, err
+	}
+	s, ll, err := createSecureGRPCLayer(port, secureConf)
+	if err != nil {
+		return nil, err
+	}
+
+	return newCommInstanceWithServer(port, s, ll, secureConf.Certificates, idMapper, peerIdentity, secureConf.DialOpts(), dialOpts...)
+}
+
+func newCommInstanceWithServer(port int, s *grpc.Server, ll net.Listener, certs *TLSCertificates, idMapper identity.Mapper,
+	peerIdentity api.PeerIdentityType, secureDialOpts api.PeerSecureDialOpts, dialOpts ...grpc.DialOption) (Comm, error) {
+
+	if len(dialOpts) == 0 {
+		dialOpts = []grpc.DialOption{grpc.WithTimeout(util.GetDurationOrDefault("peer.gossip.dialTimeout", defDialTimeout))}
 	}
 
 	commInst := &commImpl{
-		selfCertHash:   certHash,
+		tlsCerts:       certs,
 		PKIID:          idMapper.GetPKIidOfCert(peerIdentity),
 		idMapper:       idMapper,
 		logger:         util.GetLogger(util.LoggingCommModule, fmt.Sprintf("%d", port)),
@@ -83,8 +105,10 @@ func NewCommInstanceWithServer(port int, idMapper identity.Mapper, peerIdentity
 		stopping:       int32(0),
 		exitChan:       make(chan struct{}, 1),
 		subscriptions:  make([]chan proto.ReceivedMessage, 0),
+		pubSub:         util.NewPubSub(),
+		metrics:        newCommMetrics(metrics.NewRootScope()),
 	}
-	commInst.connStore = newConnStore(commInst, commInst.logger)
+	commInst.connStore = newConnStore(commInst, commInst.logger, commInst.metrics)
 
 	if port > 0 {
 		commInst.stopWG.Add(1)
@@ -98,8 +122,9 @@ func NewCommInstanceWithServer(port int, idMapper identity.Mapper, peerIdentity
 	return commInst, nil
 }
 
-// NewCommInstance creates a new comm instance that binds itself to the given gRPC server
-func NewCommInstance(s *grpc.Server, cert *tls.Certificate, idStore identity.Mapper,
+// NewCommInstance creates a new comm instance that binds itself to the given gRPC server.
+// The certificates are those the gRPC server and the secure dial options present, if TLS is used
+func NewCommInstance(s *grpc.Server, certs *TLSCertificates, idStore identity.Mapper,
 	peerIdentity api.PeerIdentityType, secureDialOpts api.PeerSecureDialOpts,
 	dialOpts ...grpc.DialOption) (Comm, error) {
 
@@ -109,13 +134,12 @@ func NewCommInstance(s *grpc.Server, cert *tls.Certificate, idStore identity.Map
 		return nil, err
 	}
 
-	if cert != nil {
+	if certs != nil {
 		inst := commInst.(*commImpl)
-		if len(cert.Certificate) == 0 {
-			inst.logger.Panic("Certificate supplied but certificate chain is empty")
-		} else {
-			inst.selfCertHash = certHashFromRawCert(cert.Certificate[0])
+		if err := certs.validate(); err != nil {
+			inst.logger.Panic("Invalid certificates supplied:", err)
 		}
+		inst.tlsCerts = certs
 	}
 
 	proto.RegisterGossipServer(s, commInst.(*commImpl))
@@ -124,7 +148,7 @@ func NewCommInstance(s *grpc.Server, cert *tls.Certificate, idStore identity.Map
 }
 
 type commImpl struct {
-	selfCertHash   []byte
+	tlsCerts       *TLSCertificates
 	peerIdentity   api.PeerIdentityType
 	idMapper       identity.Mapper
 	logger         *logging.Logger
@@ -142,6 +166,8 @@ type commImpl struct {
 	subscriptions  []chan proto.ReceivedMessage
 	port           int
 	stopping       int32
+	metrics        *commMetrics
+	pubSub         *util.PubSub
 }
 
 func (c *commImpl) createConnection(endpoint string, expectedPKIID common.PKIidType) (*connection, error) {
@@ -183,6 +209,7 @@ func (c *commImpl) createConnection(endpoint string, expectedPKIID common.PKIidT
 			if expectedPKIID != nil && !bytes.Equal(pkiID, expectedPKIID) {
 				// PKIID is nil when we don't know the remote PKI id's
 				c.logger.Warning("Remote endpoint claims to be a different peer, expected", expectedPKIID, "but got", pkiID)
+				c.metrics.handshakeFailed(connInfo.Endpoint)
 				cc.Close()
 				return nil, errors.New("Authentication failure")
 			}
@@ -190,10 +217,14 @@ func (c *commImpl) createConnection(endpoint string, expectedPKIID common.PKIidT
 			conn.pkiID = pkiID
 			conn.info = connInfo
 			conn.logger = c.logger
+			conn.metrics = c.metrics
 			conn.cancel = cf
 
 			h := func(m *proto.SignedGossipMessage) {
 				c.logger.Debug("Got message:", m)
+				if c.handleAck(m, pkiID) {
+					return
+				}
 				c.msgPublisher.DeMultiplex(&ReceivedMessageImpl{
 					conn:                conn,
 					lock:                conn,
@@ -205,6 +236,7 @@ func (c *commImpl) createConnection(endpoint string, expectedPKIID common.PKIidT
 			return conn, nil
 		}
 		c.logger.Warning("Authentication failed:", err)
+		c.metrics.handshakeFailed(extractRemoteAddress(stream))
 	}
 	cc.Close()
 	return nil, err
@@ -224,9 +256,9 @@ func (c *commImpl) Send(msg *proto.SignedGossipMessage, peers ...*RemotePeer) {
 	}
 }
 
-func (c *commImpl) sendToEndpoint(peer *RemotePeer, msg *proto.SignedGossipMessage) {
+func (c *commImpl) sendToEndpoint(peer *RemotePeer, msg *proto.SignedGossipMessage) error {
 	if c.isStopping() {
-		return
+		return errors.New("Stopping")
 	}
 	c.logger.Debug("Entering, Sending to", peer.Endpoint, ", msg:", msg)
 	defer c.logger.Debug("Exiting")
@@ -239,10 +271,11 @@ func (c *commImpl) sendToEndpoint(peer *RemotePeer, msg *proto.SignedGossipMessa
 			c.disconnect(peer.PKIID)
 		}
 		conn.send(msg, disConnectOnErr)
-		return
+		return nil
 	}
 	c.logger.Warning("Failed obtaining connection for", peer, "reason:", err)
 	c.disconnect(peer.PKIID)
+	return err
 }
 
 func (c *commImpl) isStopping() bool {
@@ -301,6 +334,7 @@ func (c *commImpl) Handshake(remotePeer *RemotePeer) (api.PeerIdentityType, erro
 	connInfo, err := c.authenticateRemotePeer(stream)
 	if err != nil {
 		c.logger.Warning("Authentication failed:", err)
+		c.metrics.handshakeFailed(extractRemoteAddress(stream))
 		return nil, err
 	}
 	if len(remotePeer.PKIID) > 0 && !bytes.Equal(connInfo.ID, remotePeer.PKIID) {
@@ -406,7 +440,7 @@ func (c *commImpl) authenticateRemotePeer(stream stream) (*proto.ConnectionInfo,
 	var err error
 	var cMsg *proto.SignedGossipMessage
 	var signer proto.Signer
-	useTLS := c.selfCertHash != nil
+	useTLS := c.tlsCerts != nil
 
 	// If TLS is enabled, sign the connection message in order to bind
 	// the TLS session to the peer's identity
@@ -427,7 +461,7 @@ func (c *commImpl) authenticateRemotePeer(stream stream) (*proto.ConnectionInfo,
 		return nil, errors.New("No TLS certificate")
 	}
 
-	cMsg, err = c.createConnectionMsg(c.PKIID, c.selfCertHash, c.peerIdentity, signer)
+	cMsg, err = c.createConnectionMsg(c.PKIID, c.selfCertHash(stream), c.peerIdentity, signer)
 	if err != nil {
 		return nil, err
 	}
@@ -490,6 +524,20 @@ func (c *commImpl) authenticateRemotePeer(stream stream) (*proto.ConnectionInfo,
 	return connInfo, nil
 }
 
+// selfCertHash returns the hash of the TLS certificate this peer presents on the stream: its client
+// certificate on the streams it opens, and on the streams it accepts, its server certificate for
+// the server name the remote peer requested
+func (c *commImpl) selfCertHash(stream stream) []byte {
+	if c.tlsCerts == nil {
+		return nil
+	}
+	if _, isClient := stream.(proto.Gossip_GossipStreamClient); isClient {
+		return certHashFromRawCert(c.tlsCerts.clientCert().Certificate[0])
+	}
+	serverName := extractServerNameFromContext(stream.Context())
+	return certHashFromRawCert(c.tlsCerts.serverCert(serverName).Certificate[0])
+}
+
 func (c *commImpl) GossipStream(stream proto.Gossip_GossipStreamServer) error {
 	if c.isStopping() {
 		return errors.New("Shutting down")
@@ -497,6 +545,7 @@ func (c *commImpl) GossipStream(stream proto.Gossip_GossipStreamServer) error {
 	connInfo, err := c.authenticateRemotePeer(stream)
 	if err != nil {
 		c.logger.Error("Authentication failed:", err)
+		c.metrics.handshakeFailed(extractRemoteAddress(stream))
 		return err
 	}
 	c.logger.Debug("Servicing", extractRemoteAddress(stream))
@@ -510,6 +559,9 @@ func (c *commImpl) GossipStream(stream proto.Gossip_GossipStreamServer) error {
 	}
 
 	h := func(m *proto.SignedGossipMessage) {
+		if c.handleAck(m, connInfo.ID) {
+			return
+		}
 		c.msgPublisher.DeMultiplex(&ReceivedMessageImpl{
 			conn:                conn,
 			lock:                conn,
@@ -602,37 +654,25 @@ type stream interface {
 	grpc.Stream
 }
 
-func createGRPCLayer(port int) (*grpc.Server, net.Listener, api.PeerSecureDialOpts, []byte) {
-	var returnedCertHash []byte
-	var s *grpc.Server
-	var ll net.Listener
-	var err error
-	var serverOpts []grpc.ServerOption
-	var dialOpts []grpc.DialOption
-
-	cert := GenerateCertificatesOrPanic()
-	returnedCertHash = certHashFromRawCert(cert.Certificate[0])
+func createGRPCLayer(port int) (*grpc.Server, net.Listener, api.PeerSecureDialOpts, *TLSCertificates) {
+	serverCert := GenerateCertificatesOrPanic()
+	clientCert := GenerateCertificatesOrPanic()
+	certs := &TLSCertificates{Server: &serverCert, Client: &clientCert}
 
-	tlsConf := &tls.Config{
-		Certificates:       []tls.Certificate{cert},
-		ClientAuth:         tls.RequestClientCert,
-		InsecureSkipVerify: true,
+	secureConf := &SecureConfig{Certificates: certs}
+	s, ll, err := createSecureGRPCLayer(port, secureConf)
+	if err != nil {
+		panic(err)
 	}
-	serverOpts = append(serverOpts, grpc.Creds(credentials.NewTLS(tlsConf)))
-	ta := credentials.NewTLS(&tls.Config{
-		Certificates:       []tls.Certificate{cert},
-		InsecureSkipVerify: true,
-	})
-	dialOpts = append(dialOpts, grpc.WithTransportCredentials(ta))
+	return s, ll, secureConf.DialOpts(), certs
+}
 
+func createSecureGRPCLayer(port int, secureConf *SecureConfig) (*grpc.Server, net.Listener, error) {
 	listenAddress := fmt.Sprintf("%s:%d", "", port)
-	ll, err = net.Listen("tcp", listenAddress)
+	ll, err := net.Listen("tcp", listenAddress)
 	if err != nil {
-		panic(err)
-	}
-	secureDialOpts := func() []grpc.DialOption {
-		return dialOpts
+		return nil, nil, err
 	}
-	s = grpc.NewServer(serverOpts...)
-	return s, ll, secureDialOpts, returnedCertHash
+	s := grpc.NewServer(grpc.Creds(credentials.NewTLS(secureConf.ServerTLSConfig())))
+	return s, ll, nil
 }
@@ -29,15 +29,17 @@ type connectionStore struct {
 	logger           *logging.Logger          // logger
 	isClosing        bool                     // whether this connection store is shutting down
 	connFactory      connFactory              // creates a connection to remote peer
+	metrics          *commMetrics             // reports the traffic of the connections
 	sync.RWMutex                              // synchronize access to shared variables
 	pki2Conn         map[string]*connection   // mapping between pkiID to connections
 	destinationLocks map[string]*sync.RWMutex //mapping between pkiIDs and locks,
 	// used to prevent concurrent connection establishment to the same remote endpoint
 }
 
-func newConnStore(connFactory connFactory, logger *logging.Logger) *connectionStore {
+func newConnStore(connFactory connFactory, logger *logging.Logger, metrics *commMetrics) *connectionStore {
 	return &connectionStore{
 		connFactory:      connFactory,
+		metrics:          metrics,
 		isClosing:        false,
 		pki2Conn:         make(map[string]*connection),
 		destinationLocks: make(map[string]*sync.RWMutex),
@@ -169,6 +171,7 @@ func (cs *connectionStore) registerConn(connInfo *proto.ConnectionInfo, serverSt
 	conn.pkiID = connInfo.ID
 	conn.info = connInfo
 	conn.logger = cs.logger
+	conn.metrics = cs.metrics
 	cs.pki2Conn[string(connInfo.ID)] = conn
 	return conn
 }
@@ -201,6 +204,7 @@ type connection struct {
 	info         *proto.ConnectionInfo
 	outBuff      chan *msgSending
 	logger       *logging.Logger                 // logger
+	metrics      *commMetrics                    // reports the traffic of the connection
 	pkiID        common.PKIidType                // pkiID of the remote endpoint
 	handler      handler                         // function to invoke upon a message reception
 	conn         *grpc.ClientConn                // gRPC connection to remote endpoint
@@ -253,11 +257,13 @@ func (conn *connection) send(msg *proto.SignedGossipMessage, onErr func(error))
 		if conn.logger.IsEnabledFor(logging.DEBUG) {
 			conn.logger.Debug("Buffer to", conn.info.Endpoint, "overflowed, dropping message", msg.String())
 		}
+		conn.metrics.sendOverflow(conn.info.Endpoint)
 		return
 	}
 
 	m := &msgSending{
 		envelope: msg.Envelope,
+		msgType:  messageType(msg),
 		onErr:    onErr,
 	}
 
@@ -307,6 +313,7 @@ func (conn *connection) writeToStream() {
 				go m.onErr(err)
 				return
 			}
+			conn.metrics.messageSent(conn.info.Endpoint, m.msgType, m.envelope)
 		case stop := <-conn.stopChan:
 			conn.logger.Debug("Closing writing to stream")
 			conn.stopChan <- stop
@@ -341,6 +348,7 @@ func (conn *connection) readFromStream(errChan chan error, msgChan chan *proto.S
 			errChan <- err
 			conn.logger.Warning(conn.pkiID, "Got error, aborting:", err)
 		}
+		conn.metrics.messageReceived(conn.info.Endpoint, messageType(msg), envelope)
 		msgChan <- msg
 	}
 }
@@ -367,5 +375,6 @@ func (conn *connection) getStream() stream {
 
 type msgSending struct {
 	envelope *proto.Envelope
+	msgType  string
 	onErr    func(error)
 }
@@ -110,3 +110,17 @@ func extractCertificateHashFromContext(ctx context.Context) []byte {
 	raw := certs[0].Raw
 	return certHashFromRawCert(raw)
 }
+
+// extractServerNameFromContext extracts the server name the remote peer requested in the TLS handshake of the stream
+func extractServerNameFromContext(ctx context.Context) string {
+	pr, extracted := peer.FromContext(ctx)
+	if !extracted {
+		return ""
+	}
+
+	tlsInfo, isTLSConn := pr.AuthInfo.(credentials.TLSInfo)
+	if !isTLSConn {
+		return ""
+	}
+	return tlsInfo.State.ServerName
+}
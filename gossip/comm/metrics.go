@@ -0,0 +1,69 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package comm
+
+import (
+	"fmt"
+	"strings"
+
+	pb "github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/metrics"
+	proto "github.com/hyperledger/fabric/protos/gossip"
+)
+
+const (
+	messagesSentMetric       = "messages_sent"
+	bytesSentMetric          = "bytes_sent"
+	messagesReceivedMetric   = "messages_received"
+	bytesReceivedMetric      = "bytes_received"
+	sendOverflowsMetric      = "send_buffer_overflows"
+	handshakeFailuresMetric  = "handshake_failures"
+	unknownMessageType       = "Unknown"
+	gossipMessageContentType = "*gossip.GossipMessage_"
+)
+
+// commMetrics reports the traffic of the comm module with every remote peer,
+// tagged with the endpoint of the peer, to tell which neighbor is slow or flooding
+type commMetrics struct {
+	scope metrics.Scope
+}
+
+func newCommMetrics(scope metrics.Scope) *commMetrics {
+	return &commMetrics{scope: scope.SubScope("gossip_comm")}
+}
+
+// messageSent counts a message written to the stream of the remote peer
+func (m *commMetrics) messageSent(endpoint string, msgType string, envelope *proto.Envelope) {
+	scope := m.scope.Tagged(map[string]string{"peer": endpoint, "type": msgType})
+	scope.Counter(messagesSentMetric).Inc(1)
+	scope.Counter(bytesSentMetric).Inc(int64(pb.Size(envelope)))
+}
+
+// messageReceived counts a message read from the stream of the remote peer
+func (m *commMetrics) messageReceived(endpoint string, msgType string, envelope *proto.Envelope) {
+	scope := m.scope.Tagged(map[string]string{"peer": endpoint, "type": msgType})
+	scope.Counter(messagesReceivedMetric).Inc(1)
+	scope.Counter(bytesReceivedMetric).Inc(int64(pb.Size(envelope)))
+}
+
+// sendOverflow counts a message dropped as the send buffer of the remote peer is full
+func (m *commMetrics) sendOverflow(endpoint string) {
+	m.scope.Tagged(map[string]string{"peer": endpoint}).Counter(sendOverflowsMetric).Inc(1)
+}
+
+// handshakeFailed counts a connection to or from the remote peer that failed authentication
+func (m *commMetrics) handshakeFailed(endpoint string) {
+	m.scope.Tagged(map[string]string{"peer": endpoint}).Counter(handshakeFailuresMetric).Inc(1)
+}
+
+// messageType returns the name of the type of the content of the message, such as AliveMsg
+func messageType(msg *proto.SignedGossipMessage) string {
+	if msg == nil || msg.GossipMessage == nil || msg.Content == nil {
+		return unknownMessageType
+	}
+	return strings.TrimPrefix(fmt.Sprintf("%T", msg.Content), gossipMessageContentType)
+}
@@ -0,0 +1,152 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package comm
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	pb "github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/metrics"
+	proto "github.com/hyperledger/fabric/protos/gossip"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+)
+
+// countersScope records the counters reported through it, by name and tags
+type countersScope struct {
+	lock     *sync.Mutex
+	tags     map[string]string
+	counters map[string][]countedValue
+}
+
+type countedValue struct {
+	tags  map[string]string
+	value int64
+}
+
+func newCountersScope() *countersScope {
+	return &countersScope{lock: &sync.Mutex{}, counters: make(map[string][]countedValue)}
+}
+
+// count returns the sum of the counter with the given name, over the tags having the given values
+func (s *countersScope) count(name string, tags map[string]string) int64 {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	var sum int64
+	for _, v := range s.counters[name] {
+		matches := true
+		for k, val := range tags {
+			matches = matches && v.tags[k] == val
+		}
+		if matches {
+			sum += v.value
+		}
+	}
+	return sum
+}
+
+func (s *countersScope) Counter(name string) metrics.Counter {
+	return &counter{scope: s, name: name}
+}
+
+func (s *countersScope) Gauge(name string) metrics.Gauge {
+	return nil
+}
+
+func (s *countersScope) Timer(name string) metrics.Timer {
+	return nil
+}
+
+func (s *countersScope) Tagged(tags map[string]string) metrics.Scope {
+	merged := make(map[string]string)
+	for k, v := range s.tags {
+		merged[k] = v
+	}
+	for k, v := range tags {
+		merged[k] = v
+	}
+	return &countersScope{lock: s.lock, tags: merged, counters: s.counters}
+}
+
+func (s *countersScope) SubScope(name string) metrics.Scope {
+	return s
+}
+
+type counter struct {
+	scope *countersScope
+	name  string
+}
+
+func (c *counter) Inc(delta int64) {
+	c.scope.lock.Lock()
+	defer c.scope.lock.Unlock()
+	c.scope.counters[c.name] = append(c.scope.counters[c.name], countedValue{tags: c.scope.tags, value: delta})
+}
+
+func TestMessageType(t *testing.T) {
+	assert.Equal(t, "DataMsg", messageType(createGossipMsg()))
+	assert.Equal(t, unknownMessageType, messageType(nil))
+	assert.Equal(t, unknownMessageType, messageType(&proto.SignedGossipMessage{}))
+}
+
+func TestCommMetrics(t *testing.T) {
+	t.Parallel()
+	comm1, _ := newCommInstance(9630, naiveSec)
+	comm2, _ := newCommInstance(9631, naiveSec)
+	defer comm1.Stop()
+	defer comm2.Stop()
+	scope1, scope2 := newCountersScope(), newCountersScope()
+	comm1.(*commImpl).metrics.scope = scope1
+	comm2.(*commImpl).metrics.scope = scope2
+
+	// The messages and bytes sent and received are counted by type
+	m2 := comm2.Accept(acceptAll)
+	msg := createGossipMsg()
+	comm1.Send(msg, remotePeer(9631))
+	select {
+	case <-m2:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Didn't receive message")
+	}
+	dataMsg := map[string]string{"type": "DataMsg"}
+	waitUntil := func(pred func() bool) {
+		for start := time.Now(); !pred() && time.Since(start) < 5*time.Second; {
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	waitUntil(func() bool { return scope1.count(messagesSentMetric, dataMsg) == 1 })
+	assert.Equal(t, int64(1), scope1.count(messagesSentMetric, dataMsg))
+	assert.Equal(t, int64(pb.Size(msg.Envelope)), scope1.count(bytesSentMetric, dataMsg))
+	assert.Equal(t, int64(1), scope2.count(messagesReceivedMetric, dataMsg))
+	assert.Equal(t, scope1.count(bytesSentMetric, dataMsg), scope2.count(bytesReceivedMetric, dataMsg))
+	assert.Equal(t, int64(0), scope2.count(messagesReceivedMetric, map[string]string{"type": "DataMsg", "peer": ""}))
+
+	// The messages dropped as the send buffer of the remote peer is full are counted
+	conn := newConnection(nil, nil, nil, nil)
+	conn.info = &proto.ConnectionInfo{Endpoint: "localhost:9632"}
+	conn.logger = comm1.(*commImpl).logger
+	conn.metrics = comm1.(*commImpl).metrics
+	for i := 0; i < cap(conn.outBuff)+1; i++ {
+		conn.send(createGossipMsg(), func(error) {})
+	}
+	assert.Equal(t, int64(1), scope1.count(sendOverflowsMetric, map[string]string{"peer": "localhost:9632"}))
+
+	// The connections failing authentication are counted
+	dialOpts := append(comm1.(*commImpl).secureDialOpts(), grpc.WithBlock(), grpc.WithTimeout(time.Second))
+	cc, err := grpc.Dial("localhost:9631", dialOpts...)
+	assert.NoError(t, err)
+	defer cc.Close()
+	stream, err := proto.NewGossipClient(cc).GossipStream(context.Background())
+	assert.NoError(t, err)
+	stream.Send(createGossipMsg().Envelope)
+	stream.Recv()
+	waitUntil(func() bool { return scope2.count(handshakeFailuresMetric, nil) == 1 })
+	assert.Equal(t, int64(1), scope2.count(handshakeFailuresMetric, nil))
+}
@@ -7,6 +7,8 @@ SPDX-License-Identifier: Apache-2.0
 package mock
 
 import (
+	"time"
+
 	"github.com/hyperledger/fabric/gossip/api"
 	"github.com/hyperledger/fabric/gossip/comm"
 	"github.com/hyperledger/fabric/gossip/common"
@@ -100,6 +102,10 @@ func (packet *packetMock) GetConnectionInfo() *proto.ConnectionInfo {
 	return nil
 }
 
+// Ack returns to the sender an acknowledgement for the message
+func (packet *packetMock) Ack(err error) {
+}
+
 func (mock *commMock) start() {
 	logger.Debug("Starting communication mock module...")
 	for {
@@ -144,6 +150,16 @@ func (mock *commMock) Send(msg *proto.SignedGossipMessage, peers ...*comm.Remote
 	}
 }
 
+// SendWithAck sends a message to remote peers, which are all considered to acknowledge it
+func (mock *commMock) SendWithAck(msg *proto.SignedGossipMessage, timeout time.Duration, minAck int, peers ...*comm.RemotePeer) comm.AggregatedSendResult {
+	mock.Send(msg, peers...)
+	var results comm.AggregatedSendResult
+	for _, peer := range peers {
+		results = append(results, comm.SendResult{RemotePeer: *peer})
+	}
+	return results
+}
+
 // Probe probes a remote node and returns nil if its responsive,
 // and an error if it's not.
 func (mock *commMock) Probe(peer *comm.RemotePeer) error {
@@ -10,6 +10,7 @@ import (
 	"sync"
 
 	proto "github.com/hyperledger/fabric/protos/gossip"
+	"golang.org/x/net/context"
 )
 
 // ReceivedMessageImpl is an implementation of ReceivedMessage
@@ -36,6 +37,12 @@ func (m *ReceivedMessageImpl) Respond(msg *proto.GossipMessage) {
 	m.conn.send(sMsg, func(e error) {})
 }
 
+// Ack returns to the sender an acknowledgement for the message,
+// with the error processing the message failed with, if any
+func (m *ReceivedMessageImpl) Ack(err error) {
+	m.Respond(ackFor(m.GetGossipMessage().Nonce, err))
+}
+
 // GetGossipMessage returns the inner GossipMessage
 func (m *ReceivedMessageImpl) GetGossipMessage() *proto.SignedGossipMessage {
 	return m.SignedGossipMessage
@@ -46,3 +53,15 @@ func (m *ReceivedMessageImpl) GetGossipMessage() *proto.SignedGossipMessage {
 func (m *ReceivedMessageImpl) GetConnectionInfo() *proto.ConnectionInfo {
 	return m.connInfo
 }
+
+// Context returns the context of the stream the message was received from,
+// which is done once the connection to the remote peer is closed
+func (m *ReceivedMessageImpl) Context() context.Context {
+	if m.conn == nil {
+		return context.Background()
+	}
+	if stream := m.conn.getStream(); stream != nil {
+		return stream.Context()
+	}
+	return context.Background()
+}
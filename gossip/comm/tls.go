@@ -0,0 +1,176 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package comm
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+
+	"github.com/hyperledger/fabric/gossip/api"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// TLSCertificates are the TLS certificates a peer presents in gossip connections.
+// The peer may present distinct certificates to the peers connecting to it,
+// and to the peers it connects to
+type TLSCertificates struct {
+	// Server is presented to the peers connecting to this peer
+	Server *tls.Certificate
+	// SNI maps server names to the certificates presented instead of Server
+	// to the peers requesting them, e.g. the internal and external endpoints
+	// of the peer
+	SNI map[string]*tls.Certificate
+	// Client is presented to the peers this peer connects to, Server if nil
+	Client *tls.Certificate
+}
+
+// serverCert returns the certificate presented to the peers requesting the given server name
+func (certs *TLSCertificates) serverCert(serverName string) *tls.Certificate {
+	if cert, exists := certs.SNI[serverName]; exists {
+		return cert
+	}
+	return certs.Server
+}
+
+// clientCert returns the certificate presented to the peers this peer connects to
+func (certs *TLSCertificates) clientCert() *tls.Certificate {
+	if certs.Client != nil {
+		return certs.Client
+	}
+	return certs.Server
+}
+
+func (certs *TLSCertificates) validate() error {
+	if certs.Server == nil || len(certs.Server.Certificate) == 0 {
+		return errors.New("server certificate supplied but certificate chain is empty")
+	}
+	if certs.Client != nil && len(certs.Client.Certificate) == 0 {
+		return errors.New("client certificate supplied but certificate chain is empty")
+	}
+	for serverName, cert := range certs.SNI {
+		if cert == nil || len(cert.Certificate) == 0 {
+			return fmt.Errorf("certificate of server name %s supplied but certificate chain is empty", serverName)
+		}
+	}
+	return nil
+}
+
+// RootCAsByOrg returns the PEM-encoded TLS root and intermediate
+// certificates of the organizations, by organization
+type RootCAsByOrg func() map[string][][]byte
+
+// SecureConfig configures the TLS of the gRPC server and clients gossip creates
+type SecureConfig struct {
+	// Certificates are the certificates of the peer
+	Certificates *TLSCertificates
+	// ServerRootCAs are the CAs the certificates of the peers this peer connects to
+	// are verified with. A certificate is accepted if it chains to the CAs of one of
+	// the organizations, it isn't verified at all if ServerRootCAs is nil
+	ServerRootCAs RootCAsByOrg
+	// ClientRootCAs are the CAs the certificates of the peers connecting to this peer
+	// are verified with. A certificate is accepted if it chains to the CAs of one of
+	// the organizations, it isn't verified at all if ClientRootCAs is nil
+	ClientRootCAs RootCAsByOrg
+}
+
+// ServerTLSConfig returns the TLS configuration of a gRPC server accepting gossip
+// connections. The root CAs are resolved on each handshake, so that the server
+// keeps up with the updates of the channel configurations
+func (conf *SecureConfig) ServerTLSConfig() *tls.Config {
+	tlsConf := &tls.Config{
+		GetCertificate: func(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return conf.Certificates.serverCert(hello.ServerName), nil
+		},
+		ClientAuth: tls.RequestClientCert,
+	}
+	if conf.ClientRootCAs != nil {
+		tlsConf.ClientAuth = tls.RequireAnyClientCert
+		tlsConf.VerifyPeerCertificate = func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			return verifyByOrg(rawCerts, conf.ClientRootCAs())
+		}
+	}
+	return tlsConf
+}
+
+// ClientTLSConfig returns the TLS configuration of a gRPC client opening gossip connections.
+// It is meant to be created for each connection, as it holds the root CAs of the time it is created
+func (conf *SecureConfig) ClientTLSConfig() *tls.Config {
+	tlsConf := &tls.Config{
+		Certificates: []tls.Certificate{*conf.Certificates.clientCert()},
+	}
+	if conf.ServerRootCAs == nil {
+		tlsConf.InsecureSkipVerify = true
+		return tlsConf
+	}
+	// The server certificate is verified against the CAs of all organizations along with its host name,
+	// and then against the CAs of each organization, so that it doesn't chain across organizations
+	rootCAs := conf.ServerRootCAs()
+	tlsConf.RootCAs = x509.NewCertPool()
+	for _, orgRootCAs := range rootCAs {
+		appendCertsFromPEM(tlsConf.RootCAs, orgRootCAs)
+	}
+	tlsConf.VerifyPeerCertificate = func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+		return verifyByOrg(rawCerts, rootCAs)
+	}
+	return tlsConf
+}
+
+// DialOpts returns the secure dial options of the gossip connections this peer opens
+func (conf *SecureConfig) DialOpts() api.PeerSecureDialOpts {
+	return func() []grpc.DialOption {
+		return []grpc.DialOption{grpc.WithTransportCredentials(credentials.NewTLS(conf.ClientTLSConfig()))}
+	}
+}
+
+// verifyByOrg verifies that the certificate chain presented by a remote peer chains
+// to the CAs of one of the organizations
+func verifyByOrg(rawCerts [][]byte, rootCAs map[string][][]byte) error {
+	if len(rawCerts) == 0 {
+		return errors.New("no certificate presented")
+	}
+	certs := make([]*x509.Certificate, len(rawCerts))
+	for i, rawCert := range rawCerts {
+		cert, err := x509.ParseCertificate(rawCert)
+		if err != nil {
+			return fmt.Errorf("failed parsing certificate: %v", err)
+		}
+		certs[i] = cert
+	}
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+	for _, orgRootCAs := range rootCAs {
+		roots := x509.NewCertPool()
+		appendCertsFromPEM(roots, orgRootCAs)
+		opts := x509.VerifyOptions{
+			Roots:         roots,
+			Intermediates: intermediates,
+			KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+		}
+		if _, err := certs[0].Verify(opts); err == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("certificate of %s isn't issued by the TLS CAs of any organization", certs[0].Subject.CommonName)
+}
+
+func appendCertsFromPEM(pool *x509.CertPool, pemCerts [][]byte) {
+	for _, pemCert := range pemCerts {
+		for block, rest := pem.Decode(pemCert); block != nil; block, rest = pem.Decode(rest) {
+			cert, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				continue
+			}
+			pool.AddCert(cert)
+		}
+	}
+}
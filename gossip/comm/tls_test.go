@@ -0,0 +1,202 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package comm
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric/gossip/identity"
+	proto "github.com/hyperledger/fabric/protos/gossip"
+	"github.com/stretchr/testify/assert"
+)
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(t *testing.T, name string) *testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	raw, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(t, err)
+	cert, err := x509.ParseCertificate(raw)
+	assert.NoError(t, err)
+	return &testCA{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: raw})}
+}
+
+// issue issues a TLS certificate for the given host names
+func (ca *testCA) issue(t *testing.T, hosts ...string) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	sn, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	assert.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: sn,
+		Subject:      pkix.Name{CommonName: ca.cert.Subject.CommonName + " peer"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		DNSNames:     hosts,
+	}
+	raw, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	assert.NoError(t, err)
+	return tls.Certificate{Certificate: [][]byte{raw}, PrivateKey: key}
+}
+
+func newSecureCommInstance(t *testing.T, port int, certs *TLSCertificates, clientRootCAs map[string][][]byte) Comm {
+	id := []byte(fmt.Sprintf("localhost:%d", port))
+	secureConf := &SecureConfig{
+		Certificates: certs,
+		ClientRootCAs: func() map[string][][]byte {
+			return clientRootCAs
+		},
+	}
+	inst, err := NewSecureCommInstanceWithServer(port, secureConf, identity.NewIdentityMapper(naiveSec, id), id)
+	assert.NoError(t, err)
+	return inst
+}
+
+func TestSecureCommInstances(t *testing.T) {
+	t.Parallel()
+	ca1, ca2, ca3 := newTestCA(t, "org1"), newTestCA(t, "org2"), newTestCA(t, "org3")
+	server1, sni1, client1 := ca1.issue(t), ca1.issue(t, "localhost"), ca1.issue(t)
+	server2, client2 := ca2.issue(t), ca2.issue(t)
+	client3 := ca3.issue(t)
+
+	// The first peer presents a distinct certificate to the peers connecting to localhost
+	comm1 := newSecureCommInstance(t, 11611, &TLSCertificates{
+		Server: &server1,
+		SNI:    map[string]*tls.Certificate{"localhost": &sni1},
+		Client: &client1,
+	}, map[string][][]byte{"ORG2": {ca2.pem}})
+	defer comm1.Stop()
+	comm2 := newSecureCommInstance(t, 11612, &TLSCertificates{Server: &server2, Client: &client2},
+		map[string][][]byte{"ORG1": {ca1.pem}})
+	defer comm2.Stop()
+
+	m1 := comm1.Accept(acceptAll)
+	m2 := comm2.Accept(acceptAll)
+	out := make(chan uint64, 3)
+	reader := func(ch <-chan proto.ReceivedMessage) {
+		for m := range ch {
+			out <- m.GetGossipMessage().Nonce
+		}
+	}
+	go reader(m1)
+	go reader(m2)
+	comm1.Send(createGossipMsg(), remotePeer(11612))
+	time.Sleep(time.Second)
+	comm2.Send(createGossipMsg(), remotePeer(11611))
+	waitForMessages(t, out, 2, "Didn't receive 2 messages")
+
+	// The certificate of the third peer isn't issued by the CAs of the organizations the first peer trusts
+	comm3 := newSecureCommInstance(t, 11613, &TLSCertificates{Server: &client3}, nil)
+	defer comm3.Stop()
+	comm3.Send(createGossipMsg(), remotePeer(11611))
+	select {
+	case <-out:
+		assert.Fail(t, "Message from a peer with an untrusted certificate was received")
+	case <-time.After(time.Second * 2):
+	}
+}
+
+func TestServerCertificateBySNI(t *testing.T) {
+	t.Parallel()
+	ca := newTestCA(t, "org1")
+	server, sni, client := ca.issue(t), ca.issue(t, "localhost"), ca.issue(t)
+	comm := newSecureCommInstance(t, 11614, &TLSCertificates{
+		Server: &server,
+		SNI:    map[string]*tls.Certificate{"localhost": &sni},
+	}, map[string][][]byte{"ORG1": {ca.pem}})
+	defer comm.Stop()
+
+	presentedCert := func(address string) []byte {
+		conn, err := tls.Dial("tcp", address, &tls.Config{
+			Certificates:       []tls.Certificate{client},
+			InsecureSkipVerify: true,
+		})
+		assert.NoError(t, err)
+		if err != nil {
+			return nil
+		}
+		defer conn.Close()
+		return conn.ConnectionState().PeerCertificates[0].Raw
+	}
+	assert.Equal(t, sni.Certificate[0], presentedCert("localhost:11614"))
+	assert.Equal(t, server.Certificate[0], presentedCert("127.0.0.1:11614"))
+}
+
+func TestVerifyByOrg(t *testing.T) {
+	ca1, ca2, ca3 := newTestCA(t, "org1"), newTestCA(t, "org2"), newTestCA(t, "org3")
+	rootCAs := map[string][][]byte{
+		"ORG1": {ca1.pem},
+		"ORG2": {ca2.pem},
+	}
+	assert.NoError(t, verifyByOrg(ca1.issue(t).Certificate, rootCAs))
+	assert.NoError(t, verifyByOrg(ca2.issue(t).Certificate, rootCAs))
+	assert.Error(t, verifyByOrg(ca3.issue(t).Certificate, rootCAs))
+	assert.Error(t, verifyByOrg(nil, rootCAs))
+	assert.Error(t, verifyByOrg([][]byte{{1, 2, 3}}, rootCAs))
+
+	// The server certificate is verified against the CAs of each organization, along with its host name
+	secureConf := &SecureConfig{
+		Certificates:  &TLSCertificates{Server: &tls.Certificate{Certificate: ca1.issue(t).Certificate}},
+		ServerRootCAs: func() map[string][][]byte { return rootCAs },
+	}
+	tlsConf := secureConf.ClientTLSConfig()
+	assert.False(t, tlsConf.InsecureSkipVerify)
+	assert.NotNil(t, tlsConf.RootCAs)
+	assert.Error(t, tlsConf.VerifyPeerCertificate(ca3.issue(t).Certificate, nil))
+	assert.NoError(t, tlsConf.VerifyPeerCertificate(ca2.issue(t).Certificate, nil))
+	secureConf.ServerRootCAs = nil
+	assert.True(t, secureConf.ClientTLSConfig().InsecureSkipVerify)
+}
+
+func TestTLSCertificates(t *testing.T) {
+	ca := newTestCA(t, "org1")
+	server, sni, client := ca.issue(t), ca.issue(t, "localhost"), ca.issue(t)
+	certs := &TLSCertificates{Server: &server}
+	assert.NoError(t, certs.validate())
+	assert.Equal(t, &server, certs.clientCert())
+	assert.Equal(t, &server, certs.serverCert("localhost"))
+
+	certs.SNI = map[string]*tls.Certificate{"localhost": &sni}
+	certs.Client = &client
+	assert.NoError(t, certs.validate())
+	assert.Equal(t, &client, certs.clientCert())
+	assert.Equal(t, &sni, certs.serverCert("localhost"))
+	assert.Equal(t, &server, certs.serverCert(""))
+
+	certs.Client = &tls.Certificate{}
+	assert.Error(t, certs.validate())
+	certs.Client = &client
+	certs.SNI["peer0"] = nil
+	assert.Error(t, certs.validate())
+	assert.Error(t, (&TLSCertificates{}).validate())
+}
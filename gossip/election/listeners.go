@@ -0,0 +1,72 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package election
+
+import (
+	"sync"
+)
+
+// LeadershipListeners dispatches the leadership changes of the peer in a channel
+// to the callbacks of the components that registered to them, such as the
+// deliver service that pulls the blocks of the channel while the peer is its leader
+type LeadershipListeners struct {
+	lock      sync.Mutex
+	isLeader  bool
+	nextID    int
+	callbacks map[int]func(isLeader bool)
+}
+
+// NewLeadershipListeners returns a LeadershipListeners without callbacks,
+// of a peer that isn't the leader of the channel
+func NewLeadershipListeners() *LeadershipListeners {
+	return &LeadershipListeners{callbacks: make(map[int]func(bool))}
+}
+
+// Register registers the callback to the leadership changes, and returns a function
+// that unregisters it. The callback is invoked with true right away if the peer is
+// currently the leader, so that components registering late don't miss the leadership.
+// Callbacks are invoked one at a time, and must not register or unregister callbacks
+func (ll *LeadershipListeners) Register(callback func(isLeader bool)) (unregister func()) {
+	ll.lock.Lock()
+	defer ll.lock.Unlock()
+	id := ll.nextID
+	ll.nextID++
+	ll.callbacks[id] = callback
+	if ll.isLeader {
+		callback(true)
+	}
+	return func() {
+		ll.lock.Lock()
+		defer ll.lock.Unlock()
+		delete(ll.callbacks, id)
+	}
+}
+
+// Notify invokes the registered callbacks in the order they were registered,
+// if the leadership of the peer changed. It can be passed as the callback
+// of NewLeaderElectionService
+func (ll *LeadershipListeners) Notify(isLeader bool) {
+	ll.lock.Lock()
+	defer ll.lock.Unlock()
+	if ll.isLeader == isLeader {
+		return
+	}
+	ll.isLeader = isLeader
+	for id := 0; id < ll.nextID; id++ {
+		if callback, exists := ll.callbacks[id]; exists {
+			callback(isLeader)
+		}
+	}
+}
+
+// IsLeader returns whether the peer is the leader of the channel,
+// as notified last
+func (ll *LeadershipListeners) IsLeader() bool {
+	ll.lock.Lock()
+	defer ll.lock.Unlock()
+	return ll.isLeader
+}
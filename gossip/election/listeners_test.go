@@ -0,0 +1,56 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package election
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLeadershipListeners(t *testing.T) {
+	ll := NewLeadershipListeners()
+	var notified1, notified2 []bool
+	unregister1 := ll.Register(func(isLeader bool) {
+		notified1 = append(notified1, isLeader)
+	})
+	assert.False(t, ll.IsLeader())
+	assert.Empty(t, notified1)
+
+	// Only changes of the leadership are dispatched
+	ll.Notify(false)
+	ll.Notify(true)
+	ll.Notify(true)
+	assert.True(t, ll.IsLeader())
+	assert.Equal(t, []bool{true}, notified1)
+
+	// A callback registered while the peer is the leader is told so right away
+	ll.Register(func(isLeader bool) {
+		notified2 = append(notified2, isLeader)
+	})
+	assert.Equal(t, []bool{true}, notified2)
+
+	// An unregistered callback isn't invoked anymore
+	unregister1()
+	ll.Notify(false)
+	assert.False(t, ll.IsLeader())
+	assert.Equal(t, []bool{true}, notified1)
+	assert.Equal(t, []bool{true, false}, notified2)
+}
+
+func TestLeadershipListenersOrder(t *testing.T) {
+	ll := NewLeadershipListeners()
+	var order []int
+	for i := 0; i < 5; i++ {
+		i := i
+		ll.Register(func(bool) {
+			order = append(order, i)
+		})
+	}
+	ll.Notify(true)
+	assert.Equal(t, []int{0, 1, 2, 3, 4}, order)
+}
@@ -9,7 +9,9 @@ package gossip
 import (
 	"bytes"
 	"fmt"
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/hyperledger/fabric/gossip/api"
 	"github.com/hyperledger/fabric/gossip/common"
@@ -20,6 +22,10 @@ import (
 	"github.com/op/go-logging"
 )
 
+// wantedIdentityExpiration is the time a peer whose message arrived before its
+// identity is prioritized for, when its identity is pulled
+var wantedIdentityExpiration = time.Minute
+
 // certStore supports pull dissemination of identity messages
 type certStore struct {
 	sync.RWMutex
@@ -28,9 +34,16 @@ type certStore struct {
 	pull         pull.Mediator
 	logger       *logging.Logger
 	mcs          api.MessageCryptoService
+	// The peers this peer communicates with which wait for their identities
+	wanted *wantedIdentities
+	// Returns whether the peer is a known member of the network, nil if none is
+	isMember func(common.PKIidType) bool
 }
 
-func newCertStore(puller pull.Mediator, idMapper identity.Mapper, selfIdentity api.PeerIdentityType, mcs api.MessageCryptoService) *certStore {
+// newCertStore creates a certStore, which validates the pulled identities of the wanted peers first,
+// then the ones of the members of the network isMember returns true for, and then the others
+func newCertStore(puller pull.Mediator, idMapper identity.Mapper, selfIdentity api.PeerIdentityType, mcs api.MessageCryptoService,
+	wanted *wantedIdentities, isMember func(common.PKIidType) bool) *certStore {
 	selfPKIID := idMapper.GetPKIidOfCert(selfIdentity)
 	logger := util.GetLogger(util.LoggingGossipModule, string(selfPKIID))
 
@@ -40,6 +53,8 @@ func newCertStore(puller pull.Mediator, idMapper identity.Mapper, selfIdentity a
 		idMapper:     idMapper,
 		selfIdentity: selfIdentity,
 		logger:       logger,
+		wanted:       wanted,
+		isMember:     isMember,
 	}
 
 	if err := certStore.idMapper.Put(selfPKIID, selfIdentity); err != nil {
@@ -65,6 +80,7 @@ func newCertStore(puller pull.Mediator, idMapper identity.Mapper, selfIdentity a
 
 func (cs *certStore) handleMessage(msg proto.ReceivedMessage) {
 	if update := msg.GetGossipMessage().GetDataUpdate(); update != nil {
+		idMsgs := make([]*proto.SignedGossipMessage, 0, len(update.Data))
 		for _, env := range update.Data {
 			m, err := env.ToGossipMessage()
 			if err != nil {
@@ -75,6 +91,11 @@ func (cs *certStore) handleMessage(msg proto.ReceivedMessage) {
 				cs.logger.Warning("Got a non-identity message:", m, "aborting")
 				return
 			}
+			idMsgs = append(idMsgs, m)
+		}
+		// The identities of the peers this peer communicates with are validated and cached first,
+		// so that they don't wait for the validation of the others, nor for an invalid one
+		for _, m := range cs.prioritize(idMsgs) {
 			if err := cs.validateIdentityMsg(m); err != nil {
 				cs.logger.Warning("Failed validating identity message:", err)
 				return
@@ -84,6 +105,25 @@ func (cs *certStore) handleMessage(msg proto.ReceivedMessage) {
 	cs.pull.HandleMessage(msg)
 }
 
+// prioritize orders the identity messages by the priority of their validation: the identities of the
+// peers waiting for them first, then the identities of the members of the network, and then the others
+func (cs *certStore) prioritize(idMsgs []*proto.SignedGossipMessage) []*proto.SignedGossipMessage {
+	priority := func(m *proto.SignedGossipMessage) int {
+		pkiID := common.PKIidType(m.GetPeerIdentity().PkiId)
+		if cs.wanted != nil && cs.wanted.isWanted(pkiID) {
+			return 0
+		}
+		if cs.isMember != nil && cs.isMember(pkiID) {
+			return 1
+		}
+		return 2
+	}
+	sort.SliceStable(idMsgs, func(i, j int) bool {
+		return priority(idMsgs[i]) < priority(idMsgs[j])
+	})
+	return idMsgs
+}
+
 func (cs *certStore) validateIdentityMsg(msg *proto.SignedGossipMessage) error {
 	idMsg := msg.GetPeerIdentity()
 	if idMsg == nil {
@@ -106,7 +146,15 @@ func (cs *certStore) validateIdentityMsg(msg *proto.SignedGossipMessage) error {
 		return fmt.Errorf("Failed verifying message: %v", err)
 	}
 
-	return cs.mcs.ValidateIdentity(api.PeerIdentityType(idMsg.Cert))
+	// Validate the identity and cache it right away, the identities which failed validation
+	// recently are rejected by the identity mapper without being validated again
+	if err := cs.idMapper.Put(claimedPKIID, api.PeerIdentityType(cert)); err != nil {
+		return err
+	}
+	if cs.wanted != nil {
+		cs.wanted.satisfied(claimedPKIID)
+	}
+	return nil
 }
 
 func (cs *certStore) createIdentityMessage() (*proto.SignedGossipMessage, error) {
@@ -144,3 +192,44 @@ func (cs *certStore) listRevokedPeers(isSuspected api.PeerSuspector) []common.PK
 func (cs *certStore) stop() {
 	cs.pull.Stop()
 }
+
+// wantedIdentities tracks the peers whose messages arrived before their identities,
+// which are peers this peer communicates with that wait for their identities to be pulled
+type wantedIdentities struct {
+	sync.Mutex
+	wanted map[string]time.Time
+}
+
+func newWantedIdentities() *wantedIdentities {
+	return &wantedIdentities{wanted: make(map[string]time.Time)}
+}
+
+// want records that a message of the peer arrived before its identity, and
+// forgets the peers whose wanted identity expiration passed
+func (w *wantedIdentities) want(pkiID common.PKIidType) {
+	now := time.Now()
+	w.Lock()
+	defer w.Unlock()
+	for id, since := range w.wanted {
+		if now.Sub(since) > wantedIdentityExpiration {
+			delete(w.wanted, id)
+		}
+	}
+	w.wanted[string(pkiID)] = now
+}
+
+// isWanted returns whether a message of the peer arrived before its
+// identity less than the wanted identity expiration ago
+func (w *wantedIdentities) isWanted(pkiID common.PKIidType) bool {
+	w.Lock()
+	defer w.Unlock()
+	since, exists := w.wanted[string(pkiID)]
+	return exists && time.Since(since) <= wantedIdentityExpiration
+}
+
+// satisfied forgets the peer once its identity is known
+func (w *wantedIdentities) satisfied(pkiID common.PKIidType) {
+	w.Lock()
+	defer w.Unlock()
+	delete(w.wanted, string(pkiID))
+}
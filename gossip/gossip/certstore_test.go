@@ -66,6 +66,9 @@ func (s *sentMsg) GetConnectionInfo() *proto.ConnectionInfo {
 	return nil
 }
 
+func (s *sentMsg) Ack(err error) {
+}
+
 type senderMock struct {
 	mock.Mock
 }
@@ -112,6 +115,49 @@ func TestCertStoreShouldSucceed(t *testing.T) {
 	testCertificateUpdate(t, true, cs)
 }
 
+func TestCertStoreValidationPriority(t *testing.T) {
+	wanted := newWantedIdentities()
+	wanted.want(common.PKIidType("C"))
+	isMember := func(pkiID common.PKIidType) bool {
+		return string(pkiID) == "B"
+	}
+	cs := &certStore{wanted: wanted, isMember: isMember}
+	idMsg := func(pkiID string) *proto.SignedGossipMessage {
+		return &proto.SignedGossipMessage{
+			GossipMessage: &proto.GossipMessage{
+				Content: &proto.GossipMessage_PeerIdentity{
+					PeerIdentity: &proto.PeerIdentity{PkiId: []byte(pkiID), Cert: []byte(pkiID)},
+				},
+			},
+		}
+	}
+	pkiIDs := func(msgs []*proto.SignedGossipMessage) []string {
+		var ids []string
+		for _, m := range msgs {
+			ids = append(ids, string(m.GetPeerIdentity().PkiId))
+		}
+		return ids
+	}
+
+	// The identities of the wanted peers are validated first, then the ones of the members
+	msgs := []*proto.SignedGossipMessage{idMsg("A"), idMsg("B"), idMsg("D"), idMsg("C")}
+	assert.Equal(t, []string{"C", "B", "A", "D"}, pkiIDs(cs.prioritize(msgs)))
+
+	// A peer isn't wanted anymore once its identity is known
+	wanted.satisfied(common.PKIidType("C"))
+	assert.False(t, wanted.isWanted(common.PKIidType("C")))
+
+	// Or once the wanted identity expiration passes
+	defer func(expiration time.Duration) {
+		wantedIdentityExpiration = expiration
+	}(wantedIdentityExpiration)
+	wantedIdentityExpiration = time.Millisecond * 100
+	wanted.want(common.PKIidType("A"))
+	assert.True(t, wanted.isWanted(common.PKIidType("A")))
+	time.Sleep(time.Millisecond * 200)
+	assert.False(t, wanted.isWanted(common.PKIidType("A")))
+}
+
 func TestCertRevocation(t *testing.T) {
 	identityExpCheckInterval := identityExpirationCheckInterval
 	defer func() {
@@ -430,7 +476,7 @@ func createObjects(updateFactory func(uint64) proto.ReceivedMessage, msgCons pro
 	selfIdentity := api.PeerIdentityType("SELF")
 	certStore = newCertStore(&pullerMock{
 		Mediator: pullMediator,
-	}, identity.NewIdentityMapper(cs, selfIdentity), selfIdentity, cs)
+	}, identity.NewIdentityMapper(cs, selfIdentity), selfIdentity, cs, newWantedIdentities(), nil)
 
 	wg := sync.WaitGroup{}
 	wg.Add(1)
@@ -38,6 +38,19 @@ type Config struct {
 	RequestStateInfoInterval    time.Duration
 	BlockExpirationInterval     time.Duration
 	StateInfoCacheSweepInterval time.Duration
+	// ReadOnly is set for the channels of a read-only peer, which verifies the blocks of the
+	// local peer before disseminating them, like the ones it receives from remote peers
+	ReadOnly bool
+}
+
+// RuntimeConfig defines the channel parameters that can be
+// changed while the channel is running. A zero value of a field
+// restores the corresponding setting of the Config of the channel.
+type RuntimeConfig struct {
+	PublishStateInfoInterval time.Duration
+	RequestStateInfoInterval time.Duration
+	PullPeerNum              int
+	MaxBlockCountToStore     int
 }
 
 // GossipChannel defines an object that deals with all channel-related messages
@@ -63,13 +76,17 @@ type GossipChannel interface {
 	// HandleMessage processes a message sent by a remote peer
 	HandleMessage(proto.ReceivedMessage)
 
-	// AddToMsgStore adds a given GossipMessage to the message store
-	AddToMsgStore(msg *proto.SignedGossipMessage)
+	// AddToMsgStore adds a given GossipMessage to the message store. It returns
+	// false for the blocks a read-only channel fails to verify, which are not added
+	AddToMsgStore(msg *proto.SignedGossipMessage) bool
 
 	// ConfigureChannel (re)configures the list of organizations
 	// that are eligible to be in the channel
 	ConfigureChannel(joinMsg api.JoinChannelMessage)
 
+	// UpdateRuntimeConfig applies the given parameters to the running channel
+	UpdateRuntimeConfig(conf RuntimeConfig)
+
 	// Stop stops the channel's activity
 	Stop()
 }
@@ -126,7 +143,16 @@ type gossipChannel struct {
 	logger                    *logging.Logger
 	stateInfoPublishScheduler *time.Ticker
 	stateInfoRequestScheduler *time.Ticker
+	publishRescheduled        chan struct{}
+	requestRescheduled        chan struct{}
+	publishStateInfoInterval  time.Duration
+	requestStateInfoInterval  time.Duration
+	pullPeerNum               int32
 	memFilter                 *membershipFilter
+
+	// blockComparator holds the replacing policy of the blocks in the
+	// message store, bounding their count to the current setting
+	blockComparator atomic.Value
 }
 
 type membershipFilter struct {
@@ -158,13 +184,21 @@ func NewGossipChannel(pkiID common.PKIidType, org api.OrgIdentityType, mcs api.M
 		shouldGossipStateInfo:     int32(0),
 		stateInfoPublishScheduler: time.NewTicker(adapter.GetConf().PublishStateInfoInterval),
 		stateInfoRequestScheduler: time.NewTicker(adapter.GetConf().RequestStateInfoInterval),
+		publishRescheduled:        make(chan struct{}, 1),
+		requestRescheduled:        make(chan struct{}, 1),
+		publishStateInfoInterval:  adapter.GetConf().PublishStateInfoInterval,
+		requestStateInfoInterval:  adapter.GetConf().RequestStateInfoInterval,
+		pullPeerNum:               int32(adapter.GetConf().PullPeerNum),
 		orgs:    []api.OrgIdentityType{},
 		chainID: chainID,
 	}
 
 	gc.memFilter = &membershipFilter{adapter: gc.Adapter, gossipChannel: gc}
 
-	comparator := proto.NewGossipMessageComparator(adapter.GetConf().MaxBlockCountToStore)
+	gc.blockComparator.Store(proto.NewGossipMessageComparator(adapter.GetConf().MaxBlockCountToStore))
+	comparator := func(this interface{}, that interface{}) common.InvalidationResult {
+		return gc.blockComparator.Load().(common.MessageReplacingPolicy)(this, that)
+	}
 
 	gc.blocksPuller = gc.createBlockPuller()
 
@@ -233,9 +267,17 @@ func NewGossipChannel(pkiID common.PKIidType, org api.OrgIdentityType, mcs api.M
 	gc.ConfigureChannel(joinMsg)
 
 	// Periodically publish state info
-	go gc.periodicalInvocation(gc.publishStateInfo, gc.stateInfoPublishScheduler.C)
+	go gc.periodicalInvocation(gc.publishStateInfo, func() <-chan time.Time {
+		gc.RLock()
+		defer gc.RUnlock()
+		return gc.stateInfoPublishScheduler.C
+	}, gc.publishRescheduled)
 	// Periodically request state info
-	go gc.periodicalInvocation(gc.requestStateInfo, gc.stateInfoRequestScheduler.C)
+	go gc.periodicalInvocation(gc.requestStateInfo, func() <-chan time.Time {
+		gc.RLock()
+		defer gc.RUnlock()
+		return gc.stateInfoRequestScheduler.C
+	}, gc.requestRescheduled)
 	return gc
 }
 
@@ -243,18 +285,22 @@ func NewGossipChannel(pkiID common.PKIidType, org api.OrgIdentityType, mcs api.M
 func (gc *gossipChannel) Stop() {
 	gc.stopChan <- struct{}{}
 	gc.blocksPuller.Stop()
+	gc.Lock()
 	gc.stateInfoPublishScheduler.Stop()
 	gc.stateInfoRequestScheduler.Stop()
+	gc.Unlock()
 	gc.leaderMsgStore.Stop()
 	gc.stateInfoMsgStore.Stop()
 	gc.blockMsgStore.Stop()
 }
 
-func (gc *gossipChannel) periodicalInvocation(fn func(), c <-chan time.Time) {
+func (gc *gossipChannel) periodicalInvocation(fn func(), ticks func() <-chan time.Time, rescheduled <-chan struct{}) {
 	for {
 		select {
-		case <-c:
+		case <-ticks():
 			fn()
+		case <-rescheduled:
+			// The ticker was replaced, wait on the new one
 		case <-gc.stopChan:
 			gc.stopChan <- struct{}{}
 			return
@@ -286,7 +332,8 @@ func (gc *gossipChannel) requestStateInfo() {
 		gc.logger.Warning("Failed creating SignedGossipMessage:", err)
 		return
 	}
-	endpoints := filter.SelectPeers(gc.GetConf().PullPeerNum, gc.GetMembership(), gc.IsMemberInChan)
+	pullPeerNum := int(atomic.LoadInt32(&gc.pullPeerNum))
+	endpoints := filter.SelectPeers(pullPeerNum, gc.GetMembership(), gc.IsMemberInChan)
 	gc.Send(req, endpoints...)
 }
 
@@ -376,8 +423,12 @@ func (gc *gossipChannel) EligibleForChannel(member discovery.NetworkMember) bool
 }
 
 // AddToMsgStore adds a given GossipMessage to the message store
-func (gc *gossipChannel) AddToMsgStore(msg *proto.SignedGossipMessage) {
+func (gc *gossipChannel) AddToMsgStore(msg *proto.SignedGossipMessage) bool {
 	if msg.IsDataMsg() {
+		if gc.GetConf().ReadOnly && !gc.verifyBlock(msg.GossipMessage, gc.pkiID) {
+			gc.logger.Warning("Read-only peer failed verifying block", msg.GetDataMsg().Payload.GetSeqNum(), ", not disseminating it")
+			return false
+		}
 		gc.blockMsgStore.Add(msg)
 		gc.blocksPuller.Add(msg)
 	}
@@ -385,6 +436,52 @@ func (gc *gossipChannel) AddToMsgStore(msg *proto.SignedGossipMessage) {
 	if msg.IsStateInfoMsg() {
 		gc.stateInfoMsgStore.Add(msg)
 	}
+	return true
+}
+
+// UpdateRuntimeConfig applies the given parameters to the running channel,
+// restoring the settings of the Config of the channel for the zero ones
+func (gc *gossipChannel) UpdateRuntimeConfig(conf RuntimeConfig) {
+	localConf := gc.GetConf()
+	if conf.PublishStateInfoInterval <= 0 {
+		conf.PublishStateInfoInterval = localConf.PublishStateInfoInterval
+	}
+	if conf.RequestStateInfoInterval <= 0 {
+		conf.RequestStateInfoInterval = localConf.RequestStateInfoInterval
+	}
+	if conf.PullPeerNum <= 0 {
+		conf.PullPeerNum = localConf.PullPeerNum
+	}
+	if conf.MaxBlockCountToStore <= 0 {
+		conf.MaxBlockCountToStore = localConf.MaxBlockCountToStore
+	}
+
+	atomic.StoreInt32(&gc.pullPeerNum, int32(conf.PullPeerNum))
+	// The blocks beyond a lowered count are evicted as the next blocks are added
+	gc.blockComparator.Store(proto.NewGossipMessageComparator(conf.MaxBlockCountToStore))
+	gc.Lock()
+	defer gc.Unlock()
+	if conf.PublishStateInfoInterval != gc.publishStateInfoInterval {
+		gc.publishStateInfoInterval = conf.PublishStateInfoInterval
+		gc.stateInfoPublishScheduler.Stop()
+		gc.stateInfoPublishScheduler = time.NewTicker(conf.PublishStateInfoInterval)
+		reschedule(gc.publishRescheduled)
+	}
+	if conf.RequestStateInfoInterval != gc.requestStateInfoInterval {
+		gc.requestStateInfoInterval = conf.RequestStateInfoInterval
+		gc.stateInfoRequestScheduler.Stop()
+		gc.stateInfoRequestScheduler = time.NewTicker(conf.RequestStateInfoInterval)
+		reschedule(gc.requestRescheduled)
+	}
+	gc.logger.Infof("Channel %s runtime configuration updated to %+v", string(gc.chainID), conf)
+}
+
+func reschedule(rescheduled chan struct{}) {
+	select {
+	case rescheduled <- struct{}{}:
+	default:
+		// A reschedule is already pending
+	}
 }
 
 // ConfigureChannel (re)configures the list of organizations
@@ -450,17 +547,23 @@ func (gc *gossipChannel) HandleMessage(msg proto.ReceivedMessage) {
 		if m.IsDataMsg() {
 			if m.GetDataMsg().Payload == nil {
 				gc.logger.Warning("Payload is empty, got it from", msg.GetConnectionInfo().ID)
+				msg.Ack(fmt.Errorf("empty payload"))
 				return
 			}
 			// Would this block go into the message store if it was verified?
 			if !gc.blockMsgStore.CheckValid(msg.GetGossipMessage()) {
+				// The block is already received, or is older than the blocks in the store
+				msg.Ack(nil)
 				return
 			}
 			if !gc.verifyBlock(m.GossipMessage, msg.GetConnectionInfo().ID) {
 				gc.logger.Warning("Failed verifying block", m.GetDataMsg().Payload.SeqNum)
+				msg.Ack(fmt.Errorf("failed verifying block %d", m.GetDataMsg().Payload.SeqNum))
 				return
 			}
 			added = gc.blockMsgStore.Add(msg.GetGossipMessage())
+			// Acknowledge the block to peers sending it waiting for acknowledgements
+			msg.Ack(nil)
 		} else { // StateInfoMsg verification should be handled in a layer above
 			//  since we don't have access to the id mapper here
 			added = gc.stateInfoMsgStore.Add(msg.GetGossipMessage())
@@ -141,6 +141,7 @@ func (cs *cryptoService) ValidateIdentity(peerIdentity api.PeerIdentityType) err
 type receivedMsg struct {
 	PKIID common.PKIidType
 	msg   *proto.SignedGossipMessage
+	acks  chan error
 	mock.Mock
 }
 
@@ -164,6 +165,12 @@ func (m *receivedMsg) GetConnectionInfo() *proto.ConnectionInfo {
 	}
 }
 
+func (m *receivedMsg) Ack(err error) {
+	if m.acks != nil {
+		m.acks <- err
+	}
+}
+
 type gossipAdapterMock struct {
 	mock.Mock
 }
@@ -406,6 +413,66 @@ func TestChannelPeriodicalPublishStateInfo(t *testing.T) {
 	assert.Equal(t, ledgerHeight, int(height), "Received different ledger height than expected")
 }
 
+func TestChannelUpdateRuntimeConfig(t *testing.T) {
+	t.Parallel()
+	stateInfoReceptionChan := make(chan *proto.SignedGossipMessage, 1)
+
+	cs := &cryptoService{}
+	cs.On("VerifyBlock", mock.Anything).Return(nil)
+
+	slowConf := conf
+	slowConf.PublishStateInfoInterval = time.Hour
+	adapter := new(gossipAdapterMock)
+	adapter.On("GetConf").Return(slowConf)
+	configureAdapter(adapter)
+	adapter.On("Send", mock.Anything, mock.Anything)
+	adapter.On("Gossip", mock.Anything).Run(func(arg mock.Arguments) {
+		select {
+		case stateInfoReceptionChan <- arg.Get(0).(*proto.SignedGossipMessage):
+		default:
+		}
+	})
+
+	gc := NewGossipChannel(pkiIDInOrg1, orgInChannelA, cs, channelA, adapter, &joinChanMsg{}).(*gossipChannel)
+	gc.UpdateStateInfo(createStateInfoMsg(5, pkiIDInOrg1, channelA))
+	defer gc.Stop()
+
+	select {
+	case <-stateInfoReceptionChan:
+		t.Fatal("Shouldn't have published stateInfo before the interval was updated")
+	case <-time.After(time.Millisecond * 500):
+	}
+
+	gc.UpdateRuntimeConfig(RuntimeConfig{
+		PublishStateInfoInterval: time.Millisecond * 100,
+		PullPeerNum:              7,
+	})
+	select {
+	case <-time.After(time.Second * 5):
+		t.Fatal("Haven't sent stateInfo on time after the interval was updated")
+	case <-stateInfoReceptionChan:
+	}
+	assert.Equal(t, int32(7), atomic.LoadInt32(&gc.pullPeerNum))
+
+	// The count of blocks stored is bounded by the updated setting
+	gc.UpdateRuntimeConfig(RuntimeConfig{MaxBlockCountToStore: 2})
+	for seq := uint64(1); seq <= 5; seq++ {
+		gc.blockMsgStore.Add(createDataMsg(seq, channelA))
+	}
+	assert.Equal(t, 3, gc.blockMsgStore.Size())
+
+	// Zero values restore the settings of the Config of the channel
+	gc.UpdateRuntimeConfig(RuntimeConfig{})
+	assert.Equal(t, int32(slowConf.PullPeerNum), atomic.LoadInt32(&gc.pullPeerNum))
+	gc.RLock()
+	assert.Equal(t, slowConf.PublishStateInfoInterval, gc.publishStateInfoInterval)
+	gc.RUnlock()
+	for seq := uint64(6); seq <= 10; seq++ {
+		gc.blockMsgStore.Add(createDataMsg(seq, channelA))
+	}
+	assert.Equal(t, 8, gc.blockMsgStore.Size())
+}
+
 func TestChannelMsgStoreEviction(t *testing.T) {
 	t.Parallel()
 	// Scenario: Create 4 phases in which the pull mediator of the channel would receive blocks
@@ -776,6 +843,33 @@ func TestChannelIsSubscribed(t *testing.T) {
 	assert.True(t, gc.EligibleForChannel(discovery.NetworkMember{PKIid: pkiIDInOrg1}))
 }
 
+func TestReadOnlyChannelAddToMessageStore(t *testing.T) {
+	t.Parallel()
+
+	cs := &cryptoService{}
+	cs.On("VerifyBlock", mock.Anything).Return(errors.New("bad block"))
+	readOnlyConf := conf
+	readOnlyConf.ReadOnly = true
+	adapter := new(gossipAdapterMock)
+	adapter.On("GetConf").Return(readOnlyConf)
+	adapter.On("GetMembership").Return([]discovery.NetworkMember{})
+	gc := NewGossipChannel(pkiIDInOrg1, orgInChannelA, cs, channelA, adapter, &joinChanMsg{})
+	defer gc.Stop()
+
+	// A read-only channel doesn't store the blocks it fails to verify, so that they aren't disseminated
+	assert.False(t, gc.AddToMsgStore(dataMsgOfChannel(5, channelA)))
+	assert.Empty(t, gc.(*gossipChannel).blockMsgStore.Get())
+	assert.True(t, gc.AddToMsgStore(createStateInfoMsg(10, pkiIDInOrg1, channelA)))
+
+	// Other channels trust the blocks of the local peer
+	adapter = new(gossipAdapterMock)
+	configureAdapter(adapter)
+	gc = NewGossipChannel(pkiIDInOrg1, orgInChannelA, cs, channelA, adapter, &joinChanMsg{})
+	defer gc.Stop()
+	assert.True(t, gc.AddToMsgStore(dataMsgOfChannel(5, channelA)))
+	assert.Len(t, gc.(*gossipChannel).blockMsgStore.Get(), 1)
+}
+
 func TestChannelAddToMessageStore(t *testing.T) {
 	t.Parallel()
 
@@ -828,6 +922,54 @@ func TestChannelAddToMessageStore(t *testing.T) {
 	assert.True(t, gc.EligibleForChannel(discovery.NetworkMember{PKIid: pkiIDInOrg1}))
 }
 
+func TestChannelAcksDataMessages(t *testing.T) {
+	t.Parallel()
+
+	cs := &cryptoService{}
+	cs.On("VerifyBlock", mock.Anything).Return(nil)
+	adapter := new(gossipAdapterMock)
+	configureAdapter(adapter)
+	adapter.On("Gossip", mock.Anything)
+	adapter.On("DeMultiplex", mock.Anything)
+	gc := NewGossipChannel(pkiIDInOrg1, orgInChannelA, cs, channelA, adapter, &joinChanMsg{})
+	defer gc.Stop()
+
+	waitForAck := func(msg *proto.SignedGossipMessage) error {
+		acks := make(chan error, 1)
+		gc.HandleMessage(&receivedMsg{msg: msg, PKIID: pkiIDInOrg1, acks: acks})
+		select {
+		case err := <-acks:
+			return err
+		case <-time.After(time.Second):
+			t.Fatal("Data message wasn't acknowledged")
+		}
+		return nil
+	}
+
+	// A new block is acknowledged, and so is a block that was already received
+	assert.NoError(t, waitForAck(dataMsgOfChannel(5, channelA)))
+	assert.NoError(t, waitForAck(dataMsgOfChannel(5, channelA)))
+
+	// A block without a payload is rejected
+	emptyMsg := dataMsgOfChannel(6, channelA)
+	emptyMsg.GetDataMsg().Payload = nil
+	assert.Error(t, waitForAck(emptyMsg))
+
+	// A block that fails verification is rejected
+	cs = &cryptoService{}
+	cs.On("VerifyBlock", mock.Anything).Return(errors.New("bad block"))
+	gc2 := NewGossipChannel(pkiIDInOrg1, orgInChannelA, cs, channelA, adapter, &joinChanMsg{})
+	defer gc2.Stop()
+	acks := make(chan error, 1)
+	gc2.HandleMessage(&receivedMsg{msg: dataMsgOfChannel(7, channelA), PKIID: pkiIDInOrg1, acks: acks})
+	select {
+	case err := <-acks:
+		assert.Error(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("Data message wasn't acknowledged")
+	}
+}
+
 func TestChannelBlockExpiration(t *testing.T) {
 	t.Parallel()
 
@@ -9,12 +9,12 @@ package gossip
 import (
 	"time"
 
-	"crypto/tls"
-
 	"github.com/hyperledger/fabric/gossip/api"
 	"github.com/hyperledger/fabric/gossip/comm"
 	"github.com/hyperledger/fabric/gossip/common"
 	"github.com/hyperledger/fabric/gossip/discovery"
+	"github.com/hyperledger/fabric/gossip/filter"
+	"github.com/hyperledger/fabric/gossip/gossip/channel"
 	proto "github.com/hyperledger/fabric/protos/gossip"
 )
 
@@ -24,6 +24,11 @@ type Gossip interface {
 	// Send sends a message to remote peers
 	Send(msg *proto.GossipMessage, peers ...*comm.RemotePeer)
 
+	// SendByCriteria sends a message to the peers of a channel matching the given criteria,
+	// and waits for the given number of them to acknowledge it, retrying other peers in place
+	// of the ones that don't. Returns an error if not enough peers acknowledged the message
+	SendByCriteria(msg *proto.GossipMessage, criteria SendCriteria) error
+
 	// GetPeers returns the NetworkMembers considered alive
 	Peers() []discovery.NetworkMember
 
@@ -51,6 +56,9 @@ type Gossip interface {
 	// JoinChan makes the Gossip instance join a channel
 	JoinChan(joinMsg api.JoinChannelMessage, chainID common.ChainID)
 
+	// UpdateChannelConfig applies the given runtime parameters to a channel the instance has joined
+	UpdateChannelConfig(conf channel.RuntimeConfig, chainID common.ChainID)
+
 	// SuspectPeers makes the gossip instance validate identities of suspected peers, and close
 	// any connections to peers with identities that are found invalid
 	SuspectPeers(s api.PeerSuspector)
@@ -59,6 +67,15 @@ type Gossip interface {
 	Stop()
 }
 
+// SendCriteria defines how a message is sent to the peers of a channel waiting for acknowledgements
+type SendCriteria struct {
+	Timeout    time.Duration        // Time to wait for the acknowledgements of every round of sending
+	MinAck     int                  // Number of peers that need to acknowledge the message
+	MaxPeers   int                  // Max number of peers the message is sent to, including the retries
+	IsEligible filter.RoutingFilter // Selects the peers of the channel the message may be sent to
+	Channel    common.ChainID       // Channel of the peers the message is sent to
+}
+
 // Config is the configuration of the gossip component
 type Config struct {
 	BindPort            int      // Port we bind to, used only for tests
@@ -76,11 +93,12 @@ type Config struct {
 	PullPeerNum  int           // Number of peers to pull from
 
 	SkipBlockVerification bool // Should we skip verifying block messages or not
+	ReadOnly              bool // Whether the peer is read-only, and verifies its own blocks before disseminating them
 
-	PublishCertPeriod        time.Duration    // Time from startup certificates are included in Alive messages
-	PublishStateInfoInterval time.Duration    // Determines frequency of pushing state info messages to peers
-	RequestStateInfoInterval time.Duration    // Determines frequency of pulling state info messages from peers
-	TLSServerCert            *tls.Certificate // TLS certificate of the peer
+	PublishCertPeriod        time.Duration      // Time from startup certificates are included in Alive messages
+	PublishStateInfoInterval time.Duration      // Determines frequency of pushing state info messages to peers
+	RequestStateInfoInterval time.Duration      // Determines frequency of pulling state info messages from peers
+	TLS                      *comm.SecureConfig // TLS certificates of the peer, and root CAs of the organizations
 
 	InternalEndpoint string // Endpoint we publish to peers in our organization
 	ExternalEndpoint string // Peer publishes this endpoint instead of SelfEndpoint to foreign organizations
@@ -8,9 +8,9 @@ package gossip
 
 import (
 	"bytes"
-	"crypto/tls"
 	"errors"
 	"fmt"
+	"math/rand"
 	"reflect"
 	"sync"
 	"sync/atomic"
@@ -66,6 +66,7 @@ type gossipServiceImpl struct {
 	disSecAdap        *discoverySecurityAdapter
 	mcs               api.MessageCryptoService
 	stateInfoMsgStore msgstore.MessageStore
+	wantedIdentities  *wantedIdentities
 }
 
 // NewGossipService creates a gossip instance attached to a gRPC server
@@ -78,9 +79,9 @@ func NewGossipService(conf *Config, s *grpc.Server, secAdvisor api.SecurityAdvis
 
 	lgr := util.GetLogger(util.LoggingGossipModule, conf.ID)
 	if s == nil {
-		c, err = createCommWithServer(conf.BindPort, idMapper, selfIdentity, secureDialOpts)
+		c, err = createCommWithServer(conf.BindPort, conf.TLS, idMapper, selfIdentity, secureDialOpts)
 	} else {
-		c, err = createCommWithoutServer(s, conf.TLSServerCert, idMapper, selfIdentity, secureDialOpts)
+		c, err = createCommWithoutServer(s, conf.TLS, idMapper, selfIdentity, secureDialOpts)
 	}
 
 	if err != nil {
@@ -104,6 +105,7 @@ func NewGossipService(conf *Config, s *grpc.Server, secAdvisor api.SecurityAdvis
 		stopFlag:              int32(0),
 		stopSignal:            &sync.WaitGroup{},
 		includeIdentityPeriod: time.Now().Add(conf.PublishCertPeriod),
+		wantedIdentities:      newWantedIdentities(),
 	}
 	g.stateInfoMsgStore = g.newStateInfoMsgStore()
 
@@ -117,7 +119,9 @@ func NewGossipService(conf *Config, s *grpc.Server, secAdvisor api.SecurityAdvis
 	g.disc = discovery.NewDiscoveryService(g.selfNetworkMember(), g.discAdapter, g.disSecAdap, g.disclosurePolicy)
 	g.logger.Info("Creating gossip service with self membership of", g.selfNetworkMember())
 
-	g.certStore = newCertStore(g.createCertStorePuller(), idMapper, selfIdentity, mcs)
+	g.certStore = newCertStore(g.createCertStorePuller(), idMapper, selfIdentity, mcs, g.wantedIdentities, func(pkiID common.PKIidType) bool {
+		return g.disc.Lookup(pkiID) != nil
+	})
 
 	if g.conf.ExternalEndpoint == "" {
 		g.logger.Warning("External endpoint is empty, peer will not be accessible outside of its organization")
@@ -161,9 +165,13 @@ func newChannelState(g *gossipServiceImpl) *channelState {
 	}
 }
 
-func createCommWithoutServer(s *grpc.Server, cert *tls.Certificate, idStore identity.Mapper,
+func createCommWithoutServer(s *grpc.Server, secureConf *comm.SecureConfig, idStore identity.Mapper,
 	identity api.PeerIdentityType, secureDialOpts api.PeerSecureDialOpts) (comm.Comm, error) {
-	return comm.NewCommInstance(s, cert, idStore, identity, secureDialOpts)
+	var certs *comm.TLSCertificates
+	if secureConf != nil {
+		certs = secureConf.Certificates
+	}
+	return comm.NewCommInstance(s, certs, idStore, identity, secureDialOpts)
 }
 
 // NewGossipServiceWithServer creates a new gossip instance with a gRPC server
@@ -172,8 +180,11 @@ func NewGossipServiceWithServer(conf *Config, secAdvisor api.SecurityAdvisor, mc
 	return NewGossipService(conf, nil, secAdvisor, mcs, mapper, identity, secureDialOpts)
 }
 
-func createCommWithServer(port int, idStore identity.Mapper, identity api.PeerIdentityType,
+func createCommWithServer(port int, secureConf *comm.SecureConfig, idStore identity.Mapper, identity api.PeerIdentityType,
 	secureDialOpts api.PeerSecureDialOpts) (comm.Comm, error) {
+	if secureConf != nil {
+		return comm.NewSecureCommInstanceWithServer(port, secureConf, idStore, identity)
+	}
 	return comm.NewCommInstanceWithServer(port, idStore, identity, secureDialOpts)
 }
 
@@ -190,6 +201,16 @@ func (g *gossipServiceImpl) JoinChan(joinMsg api.JoinChannelMessage, chainID com
 	}
 }
 
+// UpdateChannelConfig applies the given runtime parameters to a channel the instance has joined
+func (g *gossipServiceImpl) UpdateChannelConfig(conf channel.RuntimeConfig, chainID common.ChainID) {
+	gc := g.chanState.getGossipChannelByChainID(chainID)
+	if gc == nil {
+		g.logger.Debug("No such channel", chainID)
+		return
+	}
+	gc.UpdateRuntimeConfig(conf)
+}
+
 // SuspectPeers makes the gossip instance validate identities of suspected peers, and close
 // any connections to peers with identities that are found invalid
 func (g *gossipServiceImpl) SuspectPeers(isSuspected api.PeerSuspector) {
@@ -623,8 +644,8 @@ func (g *gossipServiceImpl) Gossip(msg *proto.GossipMessage) {
 			g.logger.Warning("Failed obtaining gossipChannel of", msg.Channel, "aborting")
 			return
 		}
-		if msg.IsDataMsg() {
-			gc.AddToMsgStore(sMsg)
+		if msg.IsDataMsg() && !gc.AddToMsgStore(sMsg) {
+			return
 		}
 	}
 
@@ -644,6 +665,74 @@ func (g *gossipServiceImpl) Send(msg *proto.GossipMessage, peers ...*comm.Remote
 	g.comm.Send(m, peers...)
 }
 
+// SendByCriteria sends a message to the peers of a channel matching the given criteria, in rounds.
+// Every round sends the message, with a fresh nonce, to as many peers not tried yet as there are
+// acknowledgements missing, until enough peers acknowledge it or no peer is left to try.
+// Blocks are only sent to the peers of the organization, as when they are gossiped
+func (g *gossipServiceImpl) SendByCriteria(msg *proto.GossipMessage, criteria SendCriteria) error {
+	if criteria.MinAck <= 0 {
+		return nil
+	}
+	if criteria.Timeout <= 0 {
+		return errors.New("Timeout should be specified")
+	}
+	gc := g.chanState.getGossipChannelByChainID(criteria.Channel)
+	if gc == nil {
+		return fmt.Errorf("Requested to send for channel %s, but no such channel exists", string(criteria.Channel))
+	}
+	routing := []filter.RoutingFilter{gc.EligibleForChannel, gc.IsMemberInChan}
+	if msg.IsDataMsg() {
+		routing = append(routing, g.isInMyorg)
+	}
+	if criteria.IsEligible != nil {
+		routing = append(routing, criteria.IsEligible)
+	}
+	membership := gc.GetPeers()
+	maxPeers := criteria.MaxPeers
+	if maxPeers <= 0 {
+		maxPeers = len(membership)
+	}
+	candidates := filter.SelectPeers(maxPeers, membership, filter.CombineRoutingFilters(routing...))
+	if len(candidates) < criteria.MinAck {
+		return fmt.Errorf("Requested to send to at least %d peers, but know only of %d suitable peers", criteria.MinAck, len(candidates))
+	}
+	// Spread the load of the retries over the peers
+	shuffled := make([]*comm.RemotePeer, len(candidates))
+	for i, j := range rand.Perm(len(candidates)) {
+		shuffled[i] = candidates[j]
+	}
+	candidates = shuffled
+
+	var results comm.AggregatedSendResult
+	for acked := 0; acked < criteria.MinAck && len(candidates) > 0; {
+		n := criteria.MinAck - acked
+		if n > len(candidates) {
+			n = len(candidates)
+		}
+		var round []*comm.RemotePeer
+		round, candidates = candidates[:n], candidates[n:]
+
+		m := *msg
+		m.Nonce = util.RandomUInt64()
+		sMsg, err := m.NoopSign()
+		if err != nil {
+			return err
+		}
+		roundResults := g.comm.SendWithAck(sMsg, criteria.Timeout, n, round...)
+		for _, res := range roundResults {
+			if res.Error() != "" {
+				g.logger.Warning("Peer", res.RemotePeer.String(), "didn't acknowledge the message:", res.Error())
+			}
+		}
+		acked += roundResults.AckCount()
+		results = append(results, roundResults...)
+	}
+	if results.AckCount() < criteria.MinAck {
+		return fmt.Errorf("Acknowledged by %d peers out of the %d requested: %s", results.AckCount(), criteria.MinAck, results.String())
+	}
+	return nil
+}
+
 // GetPeers returns a mapping of endpoint --> []discovery.NetworkMember
 func (g *gossipServiceImpl) Peers() []discovery.NetworkMember {
 	return g.disc.GetMembership()
@@ -863,6 +952,7 @@ type discoverySecurityAdapter struct {
 	mcs                   api.MessageCryptoService
 	c                     comm.Comm
 	logger                *logging.Logger
+	wantedIdentities      *wantedIdentities
 }
 
 func (g *gossipServiceImpl) newDiscoverySecurityAdapter() *discoverySecurityAdapter {
@@ -874,6 +964,7 @@ func (g *gossipServiceImpl) newDiscoverySecurityAdapter() *discoverySecurityAdap
 		logger:                g.logger,
 		includeIdentityPeriod: g.includeIdentityPeriod,
 		identity:              g.selfIdentity,
+		wantedIdentities:      g.wantedIdentities,
 	}
 }
 
@@ -905,6 +996,8 @@ func (sa *discoverySecurityAdapter) ValidateAliveMsg(m *proto.SignedGossipMessag
 
 	if identity == nil {
 		sa.logger.Debug("Don't have certificate for", am)
+		// Prioritize the validation of the identity of the peer once it is pulled
+		sa.wantedIdentities.want(am.Membership.PkiId)
 		return false
 	}
 
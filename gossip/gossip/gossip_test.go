@@ -614,6 +614,55 @@ func TestDissemination(t *testing.T) {
 	testWG.Done()
 }
 
+func TestSendByCriteria(t *testing.T) {
+	t.Parallel()
+	portPrefix := 14610
+	stopped := int32(0)
+	go waitForTestCompletion(&stopped, t)
+
+	g1 := newGossipInstance(portPrefix, 0, 100)
+	g2 := newGossipInstance(portPrefix, 1, 100, 0)
+	g3 := newGossipInstance(portPrefix, 2, 100, 0)
+	g4 := newGossipInstance(portPrefix, 3, 100, 0)
+	peers := []Gossip{g1, g2, g3, g4}
+	for _, p := range peers {
+		p.JoinChan(&joinChanMsg{}, common.ChainID("A"))
+		p.UpdateChannelMetadata([]byte{}, common.ChainID("A"))
+		defer p.Stop()
+	}
+	waitUntilOrFail(t, func() bool {
+		return len(g1.PeersOfChannel(common.ChainID("A"))) == 3
+	})
+
+	msg := createDataMsg(1, []byte{}, common.ChainID("A"))
+	criteria := SendCriteria{
+		Timeout: 5 * time.Second,
+		MinAck:  3,
+		Channel: common.ChainID("A"),
+	}
+
+	// No acknowledgements are needed
+	assert.NoError(t, g1.SendByCriteria(msg, SendCriteria{}))
+
+	// Bad criteria
+	assert.Error(t, g1.SendByCriteria(msg, SendCriteria{MinAck: 1, Channel: common.ChainID("A")}))
+	assert.Error(t, g1.SendByCriteria(msg, SendCriteria{Timeout: time.Second, MinAck: 1, Channel: common.ChainID("B")}))
+
+	// Not enough peers to acknowledge the message
+	tooMany := criteria
+	tooMany.MinAck = 4
+	assert.Error(t, g1.SendByCriteria(msg, tooMany))
+	noneEligible := criteria
+	noneEligible.IsEligible = func(discovery.NetworkMember) bool {
+		return false
+	}
+	assert.Error(t, g1.SendByCriteria(msg, noneEligible))
+
+	// All the peers acknowledge the message
+	assert.NoError(t, g1.SendByCriteria(msg, criteria))
+	atomic.StoreInt32(&stopped, int32(1))
+}
+
 func TestMembershipConvergence(t *testing.T) {
 	t.Parallel()
 	portPrefix := 2610
@@ -59,6 +59,9 @@ func (pm *pullMsg) GetConnectionInfo() *proto.ConnectionInfo {
 	return nil
 }
 
+func (pm *pullMsg) Ack(err error) {
+}
+
 type pullInstance struct {
 	self          discovery.NetworkMember
 	mediator      Mediator
@@ -22,6 +22,10 @@ var (
 	// identityUsageThreshold sets the maximum time that an identity
 	// can not be used to verify some signature before it will be deleted
 	usageThreshold = time.Hour
+
+	// invalidIdentityExpiration sets the time an identity that failed
+	// validation is rejected for without being validated again
+	invalidIdentityExpiration = time.Minute
 )
 
 // Mapper holds mappings between pkiID
@@ -57,6 +61,11 @@ type identityMapperImpl struct {
 	pkiID2Cert map[string]*storedIdentity
 	sync.RWMutex
 	selfPKIID string
+
+	// The identities that failed validation, which are rejected without being
+	// validated again until they expire, guarded by invalidLock
+	invalidLock sync.Mutex
+	invalid     map[string]*invalidIdentity
 }
 
 // NewIdentityMapper method, all we need is a reference to a MessageCryptoService
@@ -66,6 +75,7 @@ func NewIdentityMapper(mcs api.MessageCryptoService, selfIdentity api.PeerIdenti
 		mcs:        mcs,
 		pkiID2Cert: make(map[string]*storedIdentity),
 		selfPKIID:  string(selfPKIID),
+		invalid:    make(map[string]*invalidIdentity),
 	}
 	if err := idMapper.Put(selfPKIID, selfIdentity); err != nil {
 		panic(fmt.Errorf("Failed putting our own identity into the identity mapper: %v", err))
@@ -83,7 +93,11 @@ func (is *identityMapperImpl) Put(pkiID common.PKIidType, identity api.PeerIdent
 		return errors.New("identity is nil")
 	}
 
+	if err := is.knownInvalid(identity); err != nil {
+		return err
+	}
 	if err := is.mcs.ValidateIdentity(identity); err != nil {
+		is.markInvalid(identity, err)
 		return err
 	}
 
@@ -168,6 +182,45 @@ func (is *identityMapperImpl) validateIdentities(isSuspected api.PeerSuspector)
 	return revokedIds
 }
 
+// knownInvalid returns the error the identity failed validation with,
+// if it did less than the invalid identity expiration ago
+func (is *identityMapperImpl) knownInvalid(identity api.PeerIdentityType) error {
+	is.invalidLock.Lock()
+	defer is.invalidLock.Unlock()
+	invalid, exists := is.invalid[string(identity)]
+	if !exists {
+		return nil
+	}
+	if time.Now().After(invalid.expiration) {
+		delete(is.invalid, string(identity))
+		return nil
+	}
+	return invalid.err
+}
+
+// markInvalid records that the identity failed validation with the given error, and
+// forgets the identities whose invalid identity expiration passed
+func (is *identityMapperImpl) markInvalid(identity api.PeerIdentityType, err error) {
+	now := time.Now()
+	is.invalidLock.Lock()
+	defer is.invalidLock.Unlock()
+	for id, invalid := range is.invalid {
+		if now.After(invalid.expiration) {
+			delete(is.invalid, id)
+		}
+	}
+	is.invalid[string(identity)] = &invalidIdentity{
+		err:        err,
+		expiration: now.Add(invalidIdentityExpiration),
+	}
+}
+
+// invalidIdentity is an identity that failed validation
+type invalidIdentity struct {
+	err        error
+	expiration time.Time
+}
+
 type storedIdentity struct {
 	lastAccessTime int64
 	peerIdentity   api.PeerIdentityType
@@ -202,3 +255,15 @@ func SetIdentityUsageThreshold(duration time.Duration) {
 func GetIdentityUsageThreshold() time.Duration {
 	return usageThreshold
 }
+
+// SetInvalidIdentityExpiration sets the time an identity that failed
+// validation is rejected for without being validated again
+func SetInvalidIdentityExpiration(duration time.Duration) {
+	invalidIdentityExpiration = duration
+}
+
+// GetInvalidIdentityExpiration returns the time an identity that failed
+// validation is rejected for without being validated again
+func GetInvalidIdentityExpiration() time.Duration {
+	return invalidIdentityExpiration
+}
@@ -182,3 +182,38 @@ func TestListInvalidIdentities(t *testing.T) {
 	assert.NotNil(t, cert)
 	stopChan <- struct{}{}
 }
+
+type countingCryptoService struct {
+	naiveCryptoService
+	validations int
+}
+
+func (cs *countingCryptoService) ValidateIdentity(peerIdentity api.PeerIdentityType) error {
+	cs.validations++
+	return cs.naiveCryptoService.ValidateIdentity(peerIdentity)
+}
+
+func TestInvalidIdentityCaching(t *testing.T) {
+	expiration := GetInvalidIdentityExpiration()
+	defer SetInvalidIdentityExpiration(expiration)
+	SetInvalidIdentityExpiration(time.Millisecond * 500)
+
+	mcs := &countingCryptoService{naiveCryptoService: naiveCryptoService{revokedIdentities: map[string]struct{}{}}}
+	idStore := NewIdentityMapper(mcs, dummyID)
+	identity := api.PeerIdentityType("yacovm")
+	pkiID := mcs.GetPKIidOfCert(identity)
+	mcs.revokedIdentities[string(pkiID)] = struct{}{}
+	validations := mcs.validations
+
+	// An identity that failed validation is rejected without being validated again
+	assert.Error(t, idStore.Put(pkiID, identity))
+	assert.Error(t, idStore.Put(pkiID, identity))
+	assert.Equal(t, validations+1, mcs.validations)
+
+	// Until it expires
+	delete(mcs.revokedIdentities, string(pkiID))
+	assert.Error(t, idStore.Put(pkiID, identity))
+	time.Sleep(time.Second)
+	assert.NoError(t, idStore.Put(pkiID, identity))
+	assert.Equal(t, validations+2, mcs.validations)
+}
@@ -13,8 +13,10 @@ import (
 	"strconv"
 	"time"
 
+	corecomm "github.com/hyperledger/fabric/core/comm"
 	"github.com/hyperledger/fabric/core/config"
 	"github.com/hyperledger/fabric/gossip/api"
+	"github.com/hyperledger/fabric/gossip/comm"
 	"github.com/hyperledger/fabric/gossip/gossip"
 	"github.com/hyperledger/fabric/gossip/identity"
 	"github.com/hyperledger/fabric/gossip/util"
@@ -36,13 +38,9 @@ func newConfig(selfEndpoint string, externalEndpoint string, bootPeers ...string
 		return nil, fmt.Errorf("misconfigured endpoint %s, failed to parse port number due to %s", selfEndpoint, err)
 	}
 
-	var cert *tls.Certificate
-	if viper.GetBool("peer.tls.enabled") {
-		certTmp, err := tls.LoadX509KeyPair(config.GetPath("peer.tls.cert.file"), config.GetPath("peer.tls.key.file"))
-		if err != nil {
-			return nil, fmt.Errorf("failed to load certificates because of %s", err)
-		}
-		cert = &certTmp
+	secureConf, err := NewSecureConfig()
+	if err != nil {
+		return nil, err
 	}
 
 	return &gossip.Config{
@@ -62,7 +60,36 @@ func newConfig(selfEndpoint string, externalEndpoint string, bootPeers ...string
 		RequestStateInfoInterval:   util.GetDurationOrDefault("peer.gossip.requestStateInfoInterval", 4*time.Second),
 		PublishStateInfoInterval:   util.GetDurationOrDefault("peer.gossip.publishStateInfoInterval", 4*time.Second),
 		SkipBlockVerification:      viper.GetBool("peer.gossip.skipBlockVerification"),
-		TLSServerCert:              cert,
+		ReadOnly:                   viper.GetBool("peer.readOnly"),
+		TLS:                        secureConf,
+	}, nil
+}
+
+// NewSecureConfig creates the TLS configuration of gossip from the peer configuration, or
+// returns nil if TLS is disabled. The peer presents its TLS certificate to the peers connecting
+// to it, and its TLS client certificate, if one is configured, to the peers it connects to.
+// The certificates of remote peers are verified with the TLS root CAs of the organizations
+// defined in the channel configurations, along with the statically configured ones
+func NewSecureConfig() (*comm.SecureConfig, error) {
+	if !viper.GetBool("peer.tls.enabled") {
+		return nil, nil
+	}
+	serverCert, err := tls.LoadX509KeyPair(config.GetPath("peer.tls.cert.file"), config.GetPath("peer.tls.key.file"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load certificates because of %s", err)
+	}
+	certs := &comm.TLSCertificates{Server: &serverCert}
+	if config.GetPath("peer.tls.clientCert.file") != "" {
+		clientCert, err := tls.LoadX509KeyPair(config.GetPath("peer.tls.clientCert.file"), config.GetPath("peer.tls.clientKey.file"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificates because of %s", err)
+		}
+		certs.Client = &clientCert
+	}
+	return &comm.SecureConfig{
+		Certificates:  certs,
+		ServerRootCAs: corecomm.GetCASupport().GetServerRootCAsByOrg,
+		ClientRootCAs: corecomm.GetCASupport().GetClientRootCAsByOrg,
 	}, nil
 }
 
@@ -9,6 +9,7 @@ package integration
 import (
 	"fmt"
 	"net"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -84,6 +85,44 @@ func TestBadInitialization(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestNewSecureConfig(t *testing.T) {
+	for _, key := range []string{"peer.tls.enabled", "peer.tls.cert.file", "peer.tls.key.file",
+		"peer.tls.clientCert.file", "peer.tls.clientKey.file"} {
+		defer viper.Set(key, viper.Get(key))
+	}
+	certPath := func(name string) string {
+		path, _ := filepath.Abs(filepath.Join("..", "..", "core", "comm", "testdata", "certs", name))
+		return path
+	}
+
+	viper.Set("peer.tls.enabled", false)
+	secureConf, err := NewSecureConfig()
+	assert.NoError(t, err)
+	assert.Nil(t, secureConf)
+
+	viper.Set("peer.tls.enabled", true)
+	viper.Set("peer.tls.cert.file", certPath("Org1-server1-cert.pem"))
+	viper.Set("peer.tls.key.file", certPath("Org1-server1-key.pem"))
+	secureConf, err = NewSecureConfig()
+	assert.NoError(t, err)
+	assert.NotNil(t, secureConf.Certificates.Server)
+	assert.Nil(t, secureConf.Certificates.Client)
+	assert.NotNil(t, secureConf.ServerRootCAs)
+	assert.NotNil(t, secureConf.ClientRootCAs)
+
+	// The peer presents a distinct certificate to the peers it connects to
+	viper.Set("peer.tls.clientCert.file", certPath("Org1-client1-cert.pem"))
+	viper.Set("peer.tls.clientKey.file", certPath("Org1-client1-key.pem"))
+	secureConf, err = NewSecureConfig()
+	assert.NoError(t, err)
+	assert.NotNil(t, secureConf.Certificates.Client)
+	assert.NotEqual(t, secureConf.Certificates.Server.Certificate, secureConf.Certificates.Client.Certificate)
+
+	viper.Set("peer.tls.clientKey.file", certPath("Org1-server1-key.pem"))
+	_, err = NewSecureConfig()
+	assert.Error(t, err)
+}
+
 func setupTestEnv() {
 	viper.SetConfigName("core")
 	viper.SetEnvPrefix("CORE")
@@ -9,6 +9,7 @@ package service
 import (
 	"reflect"
 
+	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric/common/config/channel"
 
 	"github.com/hyperledger/fabric/protos/peer"
@@ -24,6 +25,12 @@ type Config interface {
 
 	// Sequence should return the sequence number of the current configuration
 	Sequence() uint64
+
+	// GossipConfig returns the gossip parameters of the channel, or nil if there are none
+	GossipConfig() *peer.GossipConfig
+
+	// Capabilities returns the application capabilities required by the channel
+	Capabilities() config.ApplicationCapabilities
 }
 
 // ConfigProcessor receives config updates
@@ -33,12 +40,14 @@ type ConfigProcessor interface {
 }
 
 type configStore struct {
-	anchorPeers []*peer.AnchorPeer
-	orgMap      map[string]config.ApplicationOrg
+	anchorPeers  []*peer.AnchorPeer
+	orgMap       map[string]config.ApplicationOrg
+	gossipConfig *peer.GossipConfig
 }
 
 type configEventReceiver interface {
 	configUpdated(config Config)
+	gossipConfigUpdated(config Config)
 }
 
 type configEventer struct {
@@ -53,30 +62,48 @@ func newConfigEventer(receiver configEventReceiver) *configEventer {
 }
 
 // ProcessConfigUpdate should be invoked whenever a channel's configuration is intialized or updated
-// it invokes the associated method in configEventReceiver when configuration is updated
-// but only if the configuration value actually changed
+// it invokes the associated methods in configEventReceiver when configuration is updated
+// but only if the corresponding configuration values actually changed
 // Note, that a changing sequence number is ignored as changing configuration
 func (ce *configEventer) ProcessConfigUpdate(config Config) {
 	logger.Debugf("Processing new config for channel %s", config.ChainID())
 	orgMap := cloneOrgConfig(config.Organizations())
-	if ce.lastConfig != nil && reflect.DeepEqual(ce.lastConfig.orgMap, orgMap) {
-		logger.Debugf("Ignoring new config for channel %s because it contained no anchor peer updates", config.ChainID())
-		return
+	var gossipConfig *peer.GossipConfig
+	if gc := config.GossipConfig(); gc != nil {
+		gossipConfig = proto.Clone(gc).(*peer.GossipConfig)
+	}
+	lastConfig := ce.lastConfig
+	orgsUpdated := lastConfig == nil || !reflect.DeepEqual(lastConfig.orgMap, orgMap)
+	// The removal of the gossip config is called out as well, to restore the local settings
+	var lastGossipConfig *peer.GossipConfig
+	if lastConfig != nil {
+		lastGossipConfig = lastConfig.gossipConfig
 	}
+	gossipConfigUpdated := gossipConfig != nil && (lastGossipConfig == nil || !proto.Equal(lastGossipConfig, gossipConfig)) ||
+		gossipConfig == nil && lastGossipConfig != nil
 
 	var newAnchorPeers []*peer.AnchorPeer
 	for _, group := range config.Organizations() {
 		newAnchorPeers = append(newAnchorPeers, group.AnchorPeers()...)
 	}
 
-	newConfig := &configStore{
-		orgMap:      orgMap,
-		anchorPeers: newAnchorPeers,
+	ce.lastConfig = &configStore{
+		orgMap:       orgMap,
+		anchorPeers:  newAnchorPeers,
+		gossipConfig: gossipConfig,
 	}
-	ce.lastConfig = newConfig
 
-	logger.Debugf("Calling out because config was updated for channel %s", config.ChainID())
-	ce.receiver.configUpdated(config)
+	if orgsUpdated {
+		logger.Debugf("Calling out because config was updated for channel %s", config.ChainID())
+		ce.receiver.configUpdated(config)
+	} else {
+		logger.Debugf("Ignoring new config for channel %s because it contained no anchor peer updates", config.ChainID())
+	}
+
+	if gossipConfigUpdated {
+		logger.Debugf("Calling out because gossip config was updated for channel %s", config.ChainID())
+		ce.receiver.gossipConfigUpdated(config)
+	}
 }
 
 func cloneOrgConfig(src map[string]config.ApplicationOrg) map[string]config.ApplicationOrg {
@@ -11,8 +11,10 @@ import (
 	"testing"
 
 	"github.com/hyperledger/fabric/common/config/channel"
+	mockconfig "github.com/hyperledger/fabric/common/mocks/config"
 	"github.com/hyperledger/fabric/gossip/util"
 	"github.com/hyperledger/fabric/protos/peer"
+	"github.com/stretchr/testify/assert"
 )
 
 const testChainID = "foo"
@@ -22,8 +24,9 @@ func init() {
 }
 
 type mockReceiver struct {
-	orgs     map[string]config.ApplicationOrg
-	sequence uint64
+	orgs         map[string]config.ApplicationOrg
+	sequence     uint64
+	gossipConfig *peer.GossipConfig
 }
 
 func (mr *mockReceiver) configUpdated(config Config) {
@@ -32,6 +35,11 @@ func (mr *mockReceiver) configUpdated(config Config) {
 	mr.sequence = config.Sequence()
 }
 
+func (mr *mockReceiver) gossipConfigUpdated(config Config) {
+	logger.Debugf("[TEST] Setting gossip config to %v", config.GossipConfig())
+	mr.gossipConfig = config.GossipConfig()
+}
+
 type mockConfig mockReceiver
 
 func (mc *mockConfig) Sequence() uint64 {
@@ -46,6 +54,14 @@ func (mc *mockConfig) ChainID() string {
 	return testChainID
 }
 
+func (mc *mockConfig) GossipConfig() *peer.GossipConfig {
+	return mc.gossipConfig
+}
+
+func (mc *mockConfig) Capabilities() config.ApplicationCapabilities {
+	return &mockconfig.ApplicationCapabilities{}
+}
+
 const testOrgID = "testID"
 
 func TestInitialUpdate(t *testing.T) {
@@ -148,3 +164,48 @@ func TestUpdatedSeqOnly(t *testing.T) {
 		t.Errorf("Should not have cleared anchor peers when reprocessing newer config with higher sequence")
 	}
 }
+
+func TestGossipConfigUpdate(t *testing.T) {
+	mc := &mockConfig{
+		sequence: 7,
+		orgs: map[string]config.ApplicationOrg{
+			testOrgID: &appGrp{
+				anchorPeers: []*peer.AnchorPeer{{Port: 9}},
+			},
+		},
+		gossipConfig: &peer.GossipConfig{PullPeerNum: 5},
+	}
+
+	mr := &mockReceiver{}
+
+	ce := newConfigEventer(mr)
+	ce.ProcessConfigUpdate(mc)
+	assert.Equal(t, uint32(5), mr.gossipConfig.PullPeerNum)
+
+	// Same gossip config, nothing should be called out
+	mr.gossipConfig = nil
+	mr.orgs = nil
+	mc.sequence = 8
+	ce.ProcessConfigUpdate(mc)
+	assert.Nil(t, mr.gossipConfig)
+
+	// Only the gossip config changed
+	mc.sequence = 9
+	mc.gossipConfig = &peer.GossipConfig{PullPeerNum: 5, PublishStateInfoIntervalMs: 100}
+	ce.ProcessConfigUpdate(mc)
+	assert.Nil(t, mr.orgs, "Should not have updated anchor peers when only the gossip config changed")
+	assert.Equal(t, uint32(100), mr.gossipConfig.PublishStateInfoIntervalMs)
+
+	// The removal of the gossip config is called out, to restore the local settings
+	mr.gossipConfig = &peer.GossipConfig{}
+	mc.sequence = 10
+	mc.gossipConfig = nil
+	ce.ProcessConfigUpdate(mc)
+	assert.Nil(t, mr.gossipConfig)
+
+	// Still no gossip config, nothing should be called out
+	mr.gossipConfig = &peer.GossipConfig{}
+	mc.sequence = 11
+	ce.ProcessConfigUpdate(mc)
+	assert.NotNil(t, mr.gossipConfig, "Should not have called out when there is still no gossip config")
+}
@@ -7,22 +7,34 @@ SPDX-License-Identifier: Apache-2.0
 package service
 
 import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/hyperledger/fabric/core/committer"
+	"github.com/hyperledger/fabric/core/common/privdata"
 	"github.com/hyperledger/fabric/core/deliverservice"
 	"github.com/hyperledger/fabric/core/deliverservice/blocksprovider"
+	"github.com/hyperledger/fabric/core/ledger"
 	"github.com/hyperledger/fabric/gossip/api"
 	gossipCommon "github.com/hyperledger/fabric/gossip/common"
+	"github.com/hyperledger/fabric/gossip/discovery"
 	"github.com/hyperledger/fabric/gossip/election"
 	"github.com/hyperledger/fabric/gossip/gossip"
+	"github.com/hyperledger/fabric/gossip/gossip/channel"
 	"github.com/hyperledger/fabric/gossip/identity"
 	"github.com/hyperledger/fabric/gossip/integration"
 	"github.com/hyperledger/fabric/gossip/state"
 	"github.com/hyperledger/fabric/gossip/util"
 	"github.com/hyperledger/fabric/protos/common"
 	proto "github.com/hyperledger/fabric/protos/gossip"
+	"github.com/hyperledger/fabric/protos/ledger/rwset"
+	"github.com/hyperledger/fabric/protos/peer"
 	"github.com/spf13/viper"
+	"golang.org/x/net/context"
 	"google.golang.org/grpc"
 )
 
@@ -39,12 +51,37 @@ type GossipService interface {
 
 	// NewConfigEventer creates a ConfigProcessor which the configtx.Manager can ultimately route config updates to
 	NewConfigEventer() ConfigProcessor
-	// InitializeChannel allocates the state provider and should be invoked once per channel per execution
-	InitializeChannel(chainID string, committer committer.Committer, endpoints []string)
+	// InitializeChannel allocates the state provider and should be invoked once per channel per execution.
+	// The collection store tells which organizations are members of the collections of the chaincodes
+	// of the channel, a nil store treating every peer of the channel as a member.
+	InitializeChannel(chainID string, committer committer.Committer, collections privdata.CollectionStore, endpoints []string)
+	// CloseChannel stops the state provider, the leader election and the blocks delivery of a channel
+	// initialized by InitializeChannel, for rolling back the join of the channel. The state provider
+	// closes the committer of the channel
+	CloseChannel(chainID string)
 	// GetBlock returns block for given chain
 	GetBlock(chainID string, index uint64) *common.Block
 	// AddPayload appends message payload to for given chain
 	AddPayload(chainID string, payload *proto.Payload) error
+	// DistributePrivateData pushes the private data of a transaction endorsed on the given
+	// chain to the members of its collections
+	DistributePrivateData(chainID string, txID string, privateData *rwset.TxPvtReadWriteSet, blkHt uint64) error
+	// HealthCheck returns an error if the gossip service is stopped
+	HealthCheck(ctx context.Context) error
+	// DeliveryServiceHealthCheck returns an error if the delivery service pulling blocks from the
+	// ordering service is unhealthy. It returns nil until the delivery service is created
+	DeliveryServiceHealthCheck(ctx context.Context) error
+	// StopDeliveryService stops the delivery service pulling blocks from the ordering service,
+	// if it was created. The gossip service stops it as well when it's stopped
+	StopDeliveryService()
+	// ChannelsHealthCheck returns an error if the state provider of a channel
+	// halted it, as a block of the channel cannot be committed
+	ChannelsHealthCheck(ctx context.Context) error
+	// RegisterLeadershipCallback registers the callback to be invoked whenever the peer becomes,
+	// or stops being, the leader of the given channel, which pulls the blocks of the channel
+	// from the ordering service. It returns a function that unregisters the callback.
+	// The callback is invoked synchronously, hence it must not block nor call the GossipService
+	RegisterLeadershipCallback(chainID string, callback func(isLeader bool)) (unregister func())
 }
 
 // DeliveryServiceFactory factory to create and initialize delivery service instance
@@ -54,23 +91,40 @@ type DeliveryServiceFactory interface {
 }
 
 type deliveryFactoryImpl struct {
+	// Dial options of the connections to the other peers, which the
+	// blocks are fetched from while the ordering service is unreachable
+	secureDialOpts api.PeerSecureDialOpts
 }
 
-// Returns an instance of delivery client
-func (*deliveryFactoryImpl) Service(g GossipService, endpoints []string, mcs api.MessageCryptoService) (deliverclient.DeliverService, error) {
-	return deliverclient.NewDeliverService(&deliverclient.Config{
+// Returns an instance of delivery client, which falls back to the other peers of the organization
+// while the ordering service is unreachable if peer.deliveryclient.peerFallback.enabled is set
+func (f *deliveryFactoryImpl) Service(g GossipService, endpoints []string, mcs api.MessageCryptoService) (deliverclient.DeliverService, error) {
+	conf := &deliverclient.Config{
 		CryptoSvc:   mcs,
 		Gossip:      g,
 		Endpoints:   endpoints,
 		ConnFactory: deliverclient.DefaultConnectionFactory,
 		ABCFactory:  deliverclient.DefaultABCFactory,
-	})
+		BlockAck: blocksprovider.AckConfig{
+			MinAck:   viper.GetInt("peer.gossip.blockAck.minAck"),
+			Timeout:  viper.GetDuration("peer.gossip.blockAck.timeout"),
+			MaxPeers: viper.GetInt("peer.gossip.blockAck.maxPeers"),
+		},
+	}
+	if f.secureDialOpts != nil && viper.GetBool("peer.deliveryclient.peerFallback.enabled") {
+		conf.PeerConnFactory = deliverclient.PeerConnectionFactory(f.secureDialOpts)
+		conf.PeerFallbackRetryInterval = viper.GetDuration("peer.deliveryclient.peerFallback.retryOrderersInterval")
+	}
+	return deliverclient.NewDeliverService(conf)
 }
 
 type gossipServiceImpl struct {
 	gossipSvc
 	chains          map[string]state.GossipStateProvider
 	leaderElection  map[string]election.LeaderElectionService
+	leadership      map[string]*election.LeadershipListeners
+	distributors    map[string]PvtDataDistributor
+	pvtDataStops    map[string]chan struct{}
 	deliveryService deliverclient.DeliverService
 	deliveryFactory DeliveryServiceFactory
 	lock            sync.RWMutex
@@ -78,6 +132,41 @@ type gossipServiceImpl struct {
 	mcs             api.MessageCryptoService
 	peerIdentity    []byte
 	secAdv          api.SecurityAdvisor
+	stopped         bool
+
+	// pvtDataVerification is how the private data received
+	// along with the blocks is verified before commit
+	pvtDataVerification state.PvtDataVerificationMode
+
+	// leaderElectionModes holds the leader election modes of the channel configs, and
+	// leaderElectionConfigs the leader election settings applied to the channels whose
+	// blocks are delivered by the delivery service
+	leaderElectionModes   map[string]peer.GossipConfig_LeaderElection
+	leaderElectionConfigs map[string]leaderElectionConfig
+}
+
+// leaderElectionConfig defines how the peer decides whether it pulls the blocks
+// of a channel from the ordering service
+type leaderElectionConfig struct {
+	// dynamic is set if the peers of the organization elect a leader
+	dynamic bool
+	// staticLeader is set if the peer pulls the blocks without election
+	staticLeader bool
+}
+
+// leaderElectionConfigOf returns the leader election settings of the peer for the mode of the
+// channel config, which default to the peer.gossip.useLeaderElection and peer.gossip.orgLeader
+// settings of the peer
+func leaderElectionConfigOf(mode peer.GossipConfig_LeaderElection) leaderElectionConfig {
+	isStaticOrgLeader := viper.GetBool("peer.gossip.orgLeader")
+	switch mode {
+	case peer.GossipConfig_DYNAMIC:
+		return leaderElectionConfig{dynamic: true}
+	case peer.GossipConfig_STATIC:
+		return leaderElectionConfig{staticLeader: isStaticOrgLeader}
+	default:
+		return leaderElectionConfig{dynamic: viper.GetBool("peer.gossip.useLeaderElection"), staticLeader: isStaticOrgLeader}
+	}
 }
 
 // This is an implementation of api.JoinChannelMessage.
@@ -115,7 +204,7 @@ func InitGossipService(peerIdentity []byte, endpoint string, s *grpc.Server, mcs
 	// TODO: This is a temporary work-around to make the gossip leader election module load its logger at startup
 	// TODO: in order for the flogging package to register this logger in time so it can set the log levels as requested in the config
 	util.GetLogger(util.LoggingElectionModule, "")
-	return InitGossipServiceCustomDeliveryFactory(peerIdentity, endpoint, s, &deliveryFactoryImpl{},
+	return InitGossipServiceCustomDeliveryFactory(peerIdentity, endpoint, s, &deliveryFactoryImpl{secureDialOpts: secureDialOpts},
 		mcs, secAdv, secureDialOpts, bootPeers...)
 }
 
@@ -133,6 +222,13 @@ func InitGossipServiceCustomDeliveryFactory(peerIdentity []byte, endpoint string
 
 		logger.Info("Initialize gossip with endpoint", endpoint, "and bootstrap set", bootPeers)
 
+		// an unknown verification mode would leave the private data unverified
+		var verificationMode state.PvtDataVerificationMode
+		verificationMode, err = state.PvtDataVerificationModeFromString(viper.GetString("peer.gossip.pvtData.verificationMode"))
+		if err != nil {
+			return
+		}
+
 		idMapper := identity.NewIdentityMapper(mcs, peerIdentity)
 		gossip, err = integration.NewGossipComponent(peerIdentity, endpoint, s, secAdv,
 			mcs, idMapper, secureDialOpts, bootPeers...)
@@ -141,10 +237,17 @@ func InitGossipServiceCustomDeliveryFactory(peerIdentity []byte, endpoint string
 			gossipSvc:       gossip,
 			chains:          make(map[string]state.GossipStateProvider),
 			leaderElection:  make(map[string]election.LeaderElectionService),
+			leadership:      make(map[string]*election.LeadershipListeners),
+			distributors:    make(map[string]PvtDataDistributor),
+			pvtDataStops:    make(map[string]chan struct{}),
 			deliveryFactory: factory,
 			idMapper:        idMapper,
 			peerIdentity:    peerIdentity,
 			secAdv:          secAdv,
+
+			pvtDataVerification:   verificationMode,
+			leaderElectionModes:   make(map[string]peer.GossipConfig_LeaderElection),
+			leaderElectionConfigs: make(map[string]leaderElectionConfig),
 		}
 	})
 	return err
@@ -160,14 +263,83 @@ func (g *gossipServiceImpl) NewConfigEventer() ConfigProcessor {
 	return newConfigEventer(g)
 }
 
+// stateProviderConfig returns the configuration of the state providers of the channels, which
+// spill the payloads waiting to be committed to disk when peer.gossip.state.payloadsSpill.memoryWindow is set,
+// cap the bandwidth of the state responses by peer.gossip.state.responseBandwidth, offer the peers joining
+// the channels to push them the blocks they miss when peer.gossip.state.bootstrapOffers is set, trust
+// the ledger heights advertised by at least peer.gossip.state.heightQuorum peers, and retry the failed
+// commits of blocks as peer.gossip.state.commitRetry configures
+func stateProviderConfig() state.ProviderConfig {
+	config := state.ProviderConfig{
+		ResponseBandwidth: state.ResponseBandwidthConfig{
+			PerPeerBytesPerSecond:    viper.GetInt("peer.gossip.state.responseBandwidth.perPeer"),
+			PerChannelBytesPerSecond: viper.GetInt("peer.gossip.state.responseBandwidth.perChannel"),
+		},
+		BootstrapOffers: viper.GetBool("peer.gossip.state.bootstrapOffers"),
+		HeightQuorum:    viper.GetInt("peer.gossip.state.heightQuorum"),
+		CommitRetry: state.CommitRetryConfig{
+			MaxAttempts:    viper.GetInt("peer.gossip.state.commitRetry.maxAttempts"),
+			InitialBackoff: viper.GetDuration("peer.gossip.state.commitRetry.initialBackoff"),
+			MaxBackoff:     viper.GetDuration("peer.gossip.state.commitRetry.maxBackoff"),
+			QuarantineDir:  viper.GetString("peer.gossip.state.commitRetry.quarantineDir"),
+		},
+	}
+	if memoryWindow := viper.GetInt("peer.gossip.state.payloadsSpill.memoryWindow"); memoryWindow > 0 {
+		config.PayloadsSpill = &state.PayloadsSpillConfig{
+			Dir:          viper.GetString("peer.gossip.state.payloadsSpill.dir"),
+			MemoryWindow: uint64(memoryWindow),
+		}
+	}
+	return config
+}
+
 // InitializeChannel allocates the state provider and should be invoked once per channel per execution
-func (g *gossipServiceImpl) InitializeChannel(chainID string, committer committer.Committer, endpoints []string) {
+func (g *gossipServiceImpl) InitializeChannel(chainID string, committer committer.Committer, collections privdata.CollectionStore, endpoints []string) {
 	g.lock.Lock()
 	defer g.lock.Unlock()
 	// Initialize new state provider for given committer
 	logger.Debug("Creating state provider for chainID", chainID)
 	servicesAdapater := &state.ServicesMediator{GossipAdapter: g, MCSAdapter: g.mcs}
-	g.chains[chainID] = state.NewGossipStateProvider(chainID, servicesAdapater, committer)
+	coordinator := state.NewCoordinatorWithConfig(committer, state.CoordinatorConfig{
+		ChainID:             chainID,
+		PvtDataVerification: g.pvtDataVerification,
+		Collections:         collections,
+		SelfOrg:             string(g.secAdv.OrgByPeerIdentity(api.PeerIdentityType(g.peerIdentity))),
+	})
+	config := stateProviderConfig()
+	// the state differences are exchanged only between peers of the same organization,
+	// which validate the blocks alike, and only if the ledger of the channel supports them
+	if support, isSupport := committer.(state.StateDiffSupport); isSupport {
+		config.StateDiff.Support = support
+		if viper.GetBool("peer.gossip.state.stateDiff.enabled") {
+			selfOrg := g.secAdv.OrgByPeerIdentity(api.PeerIdentityType(g.peerIdentity))
+			config.StateDiff.Policy = state.SameOrgStateDiffPolicy(g.secAdv, selfOrg)
+			config.StateDiff.Sign = g.mcs.Sign
+			config.StateDiff.ResponseTimeout = viper.GetDuration("peer.gossip.state.stateDiff.responseTimeout")
+			config.StateDiff.SyncTimeout = viper.GetDuration("peer.gossip.state.stateDiff.syncTimeout")
+			config.StateDiff.PageSize = viper.GetInt("peer.gossip.state.stateDiff.pageSize")
+		}
+	}
+	g.chains[chainID] = state.NewGossipStateProviderWithConfig(chainID, servicesAdapater, coordinator, config)
+	g.chains[chainID].SetReplicationPolicy(replicationPolicy(chainID, g.orgOfPeer))
+	g.chains[chainID].SetStateRequestTimeout(viper.GetDuration("peer.gossip.state.requestTimeout"))
+
+	// the private data pushed by the endorsers is stored until commit, if the ledger keeps it,
+	// and the other peers of the channel are told that this peer serves private data
+	if persister, isPersister := committer.(ledger.TransientPvtDataPersister); isPersister {
+		_, pvtDataMsgs := g.Accept(pvtDataMsgFilter(chainID, g.mcs), true)
+		stop := make(chan struct{})
+		g.pvtDataStops[chainID] = stop
+		go receivePvtData(chainID, persister, pvtDataMsgs, stop)
+		g.chains[chainID].SetPvtDataAvailable(true)
+	}
+	if pushPeerCount := viper.GetInt("peer.gossip.pvtData.pushPeerCount"); pushPeerCount > 0 {
+		membership := AllChannelPeers
+		if collections != nil {
+			membership = &collectionMembership{chainID: chainID, collections: collections, orgOf: g.orgOfPeer}
+		}
+		g.distributors[chainID] = NewPvtDataDistributor(chainID, g, pushPeerCount, membership)
+	}
 	if g.deliveryService == nil {
 		var err error
 		g.deliveryService, err = g.deliveryFactory.Service(gossipServiceInstance, endpoints, g.mcs)
@@ -185,29 +357,76 @@ func (g *gossipServiceImpl) InitializeChannel(chainID string, committer committe
 		//
 		// are mutual exclusive, setting both to true is not defined, hence
 		// peer will panic and terminate
-		leaderElection := viper.GetBool("peer.gossip.useLeaderElection")
-		isStaticOrgLeader := viper.GetBool("peer.gossip.orgLeader")
-
-		if leaderElection && isStaticOrgLeader {
+		if viper.GetBool("peer.gossip.useLeaderElection") && viper.GetBool("peer.gossip.orgLeader") {
 			logger.Panic("Setting both orgLeader and useLeaderElection to true isn't supported, aborting execution")
 		}
 
-		if leaderElection {
-			logger.Debug("Delivery uses dynamic leader election mechanism, channel", chainID)
-			g.leaderElection[chainID] = g.newLeaderElectionComponent(chainID, g.onStatusChangeFactory(chainID, committer))
-		} else if isStaticOrgLeader {
-			logger.Debug("This peer is configured to connect to ordering service for blocks delivery, channel", chainID)
-			g.deliveryService.StartDeliverForChannel(chainID, committer, func() {})
-		} else {
-			logger.Debug("This peer is not configured to connect to ordering service for blocks delivery, channel", chainID)
-		}
+		// The delivery follows the leadership of the peer, however it's decided,
+		// as the leader election mode of the channel config may change it
+		g.leadershipListeners(chainID).Register(g.onStatusChangeFactory(chainID, committer))
+		g.startLeadership(chainID, leaderElectionConfigOf(g.leaderElectionModes[chainID]))
 	} else {
 		logger.Warning("Delivery client is down won't be able to pull blocks for chain", chainID)
 	}
 }
 
+// CloseChannel stops the state provider, the leader election and the blocks delivery of the channel
+func (g *gossipServiceImpl) CloseChannel(chainID string) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	logger.Info("Closing channel", chainID)
+	if electionService, exists := g.leaderElection[chainID]; exists {
+		electionService.Stop()
+		delete(g.leaderElection, chainID)
+	}
+	if leadership, exists := g.leadership[chainID]; exists {
+		leadership.Notify(false)
+		delete(g.leadership, chainID)
+	}
+	delete(g.leaderElectionConfigs, chainID)
+	delete(g.leaderElectionModes, chainID)
+	if g.deliveryService != nil {
+		// the delivery is only started for the channels this peer is a leader of
+		g.deliveryService.StopDeliverForChannel(chainID)
+	}
+	if ch, exists := g.chains[chainID]; exists {
+		ch.Stop()
+		delete(g.chains, chainID)
+	}
+	delete(g.distributors, chainID)
+	if stop, exists := g.pvtDataStops[chainID]; exists {
+		close(stop)
+		delete(g.pvtDataStops, chainID)
+	}
+}
+
+// orgOfPeer returns the MSP ID of the organization of a peer, empty if its identity is unknown
+func (g *gossipServiceImpl) orgOfPeer(peer discovery.NetworkMember) string {
+	identity, err := g.idMapper.Get(peer.PKIid)
+	if err != nil {
+		return ""
+	}
+	return string(g.secAdv.OrgByPeerIdentity(identity))
+}
+
+// DistributePrivateData pushes the private data of a transaction endorsed on the given chain
+// to the members of its collections, if the push of private data is enabled
+func (g *gossipServiceImpl) DistributePrivateData(chainID string, txID string, privateData *rwset.TxPvtReadWriteSet, blkHt uint64) error {
+	g.lock.RLock()
+	distributor, exists := g.distributors[chainID]
+	g.lock.RUnlock()
+	if !exists {
+		return nil
+	}
+	return distributor.Distribute(txID, privateData, blkHt)
+}
+
 // configUpdated constructs a joinChannelMessage and sends it to the gossipSvc
 func (g *gossipServiceImpl) configUpdated(config Config) {
+	if err := config.Capabilities().Supported(); err != nil {
+		logger.Error("Channel", config.ChainID(), "requires application capabilities this peer does not support:", err, ", aborting.")
+		return
+	}
 	myOrg := string(g.secAdv.OrgByPeerIdentity(api.PeerIdentityType(g.peerIdentity)))
 	if !g.amIinChannel(myOrg, config) {
 		logger.Error("Tried joining channel", config.ChainID(), "but our org(", myOrg, "), isn't "+
@@ -232,6 +451,24 @@ func (g *gossipServiceImpl) configUpdated(config Config) {
 	g.JoinChan(jcm, gossipCommon.ChainID(config.ChainID()))
 }
 
+// gossipConfigUpdated applies the gossip parameters of the channel config to the running
+// gossip instance of the channel. The parameters the channel config doesn't set, or all of
+// them when the GossipConfig is removed, are restored to the local settings of the peer
+func (g *gossipServiceImpl) gossipConfigUpdated(config Config) {
+	gossipConfig := config.GossipConfig()
+	logger.Info("Updating gossip parameters of channel", config.ChainID(), "to", gossipConfig)
+	g.UpdateChannelConfig(channel.RuntimeConfig{
+		PublishStateInfoInterval: time.Duration(gossipConfig.GetPublishStateInfoIntervalMs()) * time.Millisecond,
+		RequestStateInfoInterval: time.Duration(gossipConfig.GetRequestStateInfoIntervalMs()) * time.Millisecond,
+		PullPeerNum:              int(gossipConfig.GetPullPeerNum()),
+		MaxBlockCountToStore:     int(gossipConfig.GetMaxBlockCountToStore()),
+	}, gossipCommon.ChainID(config.ChainID()))
+
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	g.updateLeadership(config.ChainID(), gossipConfig.GetLeaderElection())
+}
+
 // GetBlock returns block for given chain
 func (g *gossipServiceImpl) GetBlock(chainID string, index uint64) *common.Block {
 	g.lock.RLock()
@@ -250,6 +487,7 @@ func (g *gossipServiceImpl) AddPayload(chainID string, payload *proto.Payload) e
 func (g *gossipServiceImpl) Stop() {
 	g.lock.Lock()
 	defer g.lock.Unlock()
+	g.stopped = true
 	for _, ch := range g.chains {
 		logger.Info("Stopping chain", ch)
 		ch.Stop()
@@ -265,6 +503,116 @@ func (g *gossipServiceImpl) Stop() {
 	}
 }
 
+// HealthCheck returns an error if the gossip service is stopped
+func (g *gossipServiceImpl) HealthCheck(ctx context.Context) error {
+	g.lock.RLock()
+	defer g.lock.RUnlock()
+	if g.stopped {
+		return errors.New("Gossip service is stopped")
+	}
+	return nil
+}
+
+// DeliveryServiceHealthCheck returns an error if the delivery service pulling blocks from the
+// ordering service is unhealthy. It returns nil until the delivery service is created
+func (g *gossipServiceImpl) DeliveryServiceHealthCheck(ctx context.Context) error {
+	g.lock.RLock()
+	deliveryService := g.deliveryService
+	g.lock.RUnlock()
+	if deliveryService == nil {
+		return nil
+	}
+	return deliveryService.HealthCheck(ctx)
+}
+
+// StopDeliveryService stops the delivery service pulling blocks from the ordering service,
+// if it was created, so that no block is delivered while the peer shuts down
+func (g *gossipServiceImpl) StopDeliveryService() {
+	g.lock.RLock()
+	deliveryService := g.deliveryService
+	g.lock.RUnlock()
+	if deliveryService != nil {
+		deliveryService.Stop()
+	}
+}
+
+// ChannelsHealthCheck returns an error if the state provider of a channel
+// halted it, as a block of the channel cannot be committed
+func (g *gossipServiceImpl) ChannelsHealthCheck(ctx context.Context) error {
+	g.lock.RLock()
+	defer g.lock.RUnlock()
+	chainIDs := make([]string, 0, len(g.chains))
+	for chainID := range g.chains {
+		chainIDs = append(chainIDs, chainID)
+	}
+	sort.Strings(chainIDs)
+	var halted []string
+	for _, chainID := range chainIDs {
+		if err := g.chains[chainID].HealthCheck(ctx); err != nil {
+			halted = append(halted, fmt.Sprintf("%s: %s", chainID, err))
+		}
+	}
+	if len(halted) > 0 {
+		return errors.New(strings.Join(halted, "; "))
+	}
+	return nil
+}
+
+// RegisterLeadershipCallback registers the callback to the leadership changes of the peer in the channel,
+// which may be registered before the channel is initialized
+func (g *gossipServiceImpl) RegisterLeadershipCallback(chainID string, callback func(isLeader bool)) (unregister func()) {
+	g.lock.Lock()
+	leadership := g.leadershipListeners(chainID)
+	g.lock.Unlock()
+	return leadership.Register(callback)
+}
+
+// leadershipListeners returns the listeners of the leadership changes of the peer in the channel,
+// creating them if needed. It is called with the lock held
+func (g *gossipServiceImpl) leadershipListeners(chainID string) *election.LeadershipListeners {
+	leadership, exists := g.leadership[chainID]
+	if !exists {
+		leadership = election.NewLeadershipListeners()
+		g.leadership[chainID] = leadership
+	}
+	return leadership
+}
+
+// startLeadership makes the peer elect the leader of the channel, or be its static leader,
+// per the leader election settings. It is called with the lock held
+func (g *gossipServiceImpl) startLeadership(chainID string, conf leaderElectionConfig) {
+	g.leaderElectionConfigs[chainID] = conf
+	leadership := g.leadershipListeners(chainID)
+	if conf.dynamic {
+		logger.Debug("Delivery uses dynamic leader election mechanism, channel", chainID)
+		g.leaderElection[chainID] = g.newLeaderElectionComponent(chainID, leadership.Notify)
+	} else if conf.staticLeader {
+		logger.Debug("This peer is configured to connect to ordering service for blocks delivery, channel", chainID)
+		leadership.Notify(true)
+	} else {
+		logger.Debug("This peer is not configured to connect to ordering service for blocks delivery, channel", chainID)
+	}
+}
+
+// updateLeadership applies the leader election mode of the channel config to the channel,
+// restarting its leadership if the settings of the peer changed. It is called with the lock held
+func (g *gossipServiceImpl) updateLeadership(chainID string, mode peer.GossipConfig_LeaderElection) {
+	g.leaderElectionModes[chainID] = mode
+	applied, started := g.leaderElectionConfigs[chainID]
+	conf := leaderElectionConfigOf(mode)
+	if !started || conf == applied {
+		// The leadership starts with the channel, if its blocks are delivered
+		return
+	}
+	logger.Info("Leader election of channel", chainID, "changed to", mode, ", restarting the leadership of the peer")
+	if electionService, exists := g.leaderElection[chainID]; exists {
+		electionService.Stop()
+		delete(g.leaderElection, chainID)
+	}
+	g.leadershipListeners(chainID).Notify(false)
+	g.startLeadership(chainID, conf)
+}
+
 func (g *gossipServiceImpl) newLeaderElectionComponent(chainID string, callback func(bool)) election.LeaderElectionService {
 	PKIid := g.idMapper.GetPKIidOfCert(g.peerIdentity)
 	adapter := election.NewAdapter(g, PKIid, gossipCommon.ChainID(chainID))
@@ -285,11 +633,14 @@ func (g *gossipServiceImpl) onStatusChangeFactory(chainID string, committer bloc
 		if isLeader {
 			yield := func() {
 				g.lock.RLock()
-				le := g.leaderElection[chainID]
+				le, exists := g.leaderElection[chainID]
 				g.lock.RUnlock()
-				le.Yield()
+				// A static leader has no one to yield the leadership to
+				if exists {
+					le.Yield()
+				}
 			}
-			logger.Info("Elected as a leader, starting delivery service for channel", chainID)
+			logger.Info("Became the leader, starting delivery service for channel", chainID)
 			if err := g.deliveryService.StartDeliverForChannel(chainID, committer, yield); err != nil {
 				logger.Error("Delivery service is not able to start blocks delivery for chain, due to", err)
 			}
@@ -8,6 +8,7 @@ package service
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"net"
 	"sync"
@@ -18,6 +19,7 @@ import (
 	"github.com/hyperledger/fabric/common/localmsp"
 	"github.com/hyperledger/fabric/core/deliverservice"
 	"github.com/hyperledger/fabric/core/deliverservice/blocksprovider"
+	"github.com/hyperledger/fabric/core/ledger"
 	"github.com/hyperledger/fabric/gossip/api"
 	gossipCommon "github.com/hyperledger/fabric/gossip/common"
 	"github.com/hyperledger/fabric/gossip/election"
@@ -33,6 +35,7 @@ import (
 	"github.com/hyperledger/fabric/protos/peer"
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
 	"google.golang.org/grpc"
 )
 
@@ -116,7 +119,7 @@ func TestLeaderElectionWithDeliverClient(t *testing.T) {
 		gossips[i].(*gossipServiceImpl).deliveryFactory = deliverServiceFactory
 		deliverServiceFactory.service.running[channelName] = false
 
-		gossips[i].InitializeChannel(channelName, &mockLedgerInfo{1}, []string{"localhost:5005"})
+		gossips[i].InitializeChannel(channelName, &mockLedgerInfo{1}, nil, []string{"localhost:5005"})
 		service, exist := gossips[i].(*gossipServiceImpl).leaderElection[channelName]
 		assert.True(t, exist, "Leader election service should be created for peer %d and channel %s", i, channelName)
 		services[i] = &electionService{nil, false, 0}
@@ -172,7 +175,7 @@ func TestWithStaticDeliverClientLeader(t *testing.T) {
 	for i := 0; i < n; i++ {
 		gossips[i].(*gossipServiceImpl).deliveryFactory = deliverServiceFactory
 		deliverServiceFactory.service.running[channelName] = false
-		gossips[i].InitializeChannel(channelName, &mockLedgerInfo{1}, []string{"localhost:5005"})
+		gossips[i].InitializeChannel(channelName, &mockLedgerInfo{1}, nil, []string{"localhost:5005"})
 	}
 
 	for i := 0; i < n; i++ {
@@ -183,7 +186,7 @@ func TestWithStaticDeliverClientLeader(t *testing.T) {
 	channelName = "chanB"
 	for i := 0; i < n; i++ {
 		deliverServiceFactory.service.running[channelName] = false
-		gossips[i].InitializeChannel(channelName, &mockLedgerInfo{1}, []string{"localhost:5005"})
+		gossips[i].InitializeChannel(channelName, &mockLedgerInfo{1}, nil, []string{"localhost:5005"})
 	}
 
 	for i := 0; i < n; i++ {
@@ -220,7 +223,7 @@ func TestWithStaticDeliverClientNotLeader(t *testing.T) {
 	for i := 0; i < n; i++ {
 		gossips[i].(*gossipServiceImpl).deliveryFactory = deliverServiceFactory
 		deliverServiceFactory.service.running[channelName] = false
-		gossips[i].InitializeChannel(channelName, &mockLedgerInfo{1}, []string{"localhost:5005"})
+		gossips[i].InitializeChannel(channelName, &mockLedgerInfo{1}, nil, []string{"localhost:5005"})
 	}
 
 	for i := 0; i < n; i++ {
@@ -231,6 +234,133 @@ func TestWithStaticDeliverClientNotLeader(t *testing.T) {
 	stopPeers(gossips)
 }
 
+func TestCloseChannel(t *testing.T) {
+	viper.Set("peer.gossip.useLeaderElection", false)
+	viper.Set("peer.gossip.orgLeader", true)
+	defer viper.Set("peer.gossip.orgLeader", false)
+
+	g := newGossipInstance(20100, 0, 100).(*gossipServiceImpl)
+	defer g.Stop()
+	g.secAdv = &secAdvMock{}
+	deliverService := &mockDeliverService{running: make(map[string]bool)}
+	g.deliveryFactory = &mockDeliverServiceFactory{service: deliverService}
+
+	g.InitializeChannel("chanA", &mockLedgerInfo{1}, nil, []string{"localhost:5005"})
+	g.InitializeChannel("chanB", &mockLedgerInfo{1}, nil, []string{"localhost:5005"})
+	assert.True(t, deliverService.running["chanA"])
+
+	g.CloseChannel("chanA")
+	assert.False(t, deliverService.running["chanA"], "Block deliverer should be stopped for the closed channel")
+	assert.True(t, deliverService.running["chanB"])
+	_, exists := g.chains["chanA"]
+	assert.False(t, exists)
+	_, exists = g.chains["chanB"]
+	assert.True(t, exists)
+
+	// The channel can be initialized again once closed
+	g.InitializeChannel("chanA", &mockLedgerInfo{1}, nil, []string{"localhost:5005"})
+	assert.True(t, deliverService.running["chanA"])
+}
+
+func TestLeadershipCallbacks(t *testing.T) {
+	viper.Set("peer.gossip.useLeaderElection", false)
+	viper.Set("peer.gossip.orgLeader", true)
+	defer viper.Set("peer.gossip.orgLeader", false)
+
+	g := newGossipInstance(20110, 0, 100).(*gossipServiceImpl)
+	defer g.Stop()
+	g.secAdv = &secAdvMock{}
+	deliverService := &mockDeliverService{running: make(map[string]bool)}
+	g.deliveryFactory = &mockDeliverServiceFactory{service: deliverService}
+
+	var chanA, chanB []bool
+	// A callback may be registered before the channel is initialized
+	g.RegisterLeadershipCallback("chanA", func(isLeader bool) {
+		chanA = append(chanA, isLeader)
+	})
+	g.InitializeChannel("chanA", &mockLedgerInfo{1}, nil, []string{"localhost:5005"})
+	assert.Equal(t, []bool{true}, chanA)
+	assert.True(t, deliverService.running["chanA"])
+
+	// or after the peer became the leader of the channel
+	g.InitializeChannel("chanB", &mockLedgerInfo{1}, nil, []string{"localhost:5005"})
+	unregister := g.RegisterLeadershipCallback("chanB", func(isLeader bool) {
+		chanB = append(chanB, isLeader)
+	})
+	assert.Equal(t, []bool{true}, chanB)
+
+	// Closing the channel relinquishes the leadership
+	unregister()
+	g.CloseChannel("chanA")
+	g.CloseChannel("chanB")
+	assert.Equal(t, []bool{true, false}, chanA)
+	assert.Equal(t, []bool{true}, chanB)
+	assert.False(t, deliverService.running["chanA"])
+	assert.False(t, deliverService.running["chanB"])
+}
+
+func TestLeaderElectionModeOfChannelConfig(t *testing.T) {
+	viper.Set("peer.gossip.useLeaderElection", false)
+	viper.Set("peer.gossip.orgLeader", true)
+	defer viper.Set("peer.gossip.orgLeader", false)
+
+	g := newGossipInstance(20120, 0, 100).(*gossipServiceImpl)
+	defer g.Stop()
+	g.secAdv = &secAdvMock{}
+	deliverService := &mockDeliverService{running: make(map[string]bool)}
+	g.deliveryFactory = &mockDeliverServiceFactory{service: deliverService}
+
+	// The mode of the channel config precedes the initialization of the channel
+	config := &mockConfig{gossipConfig: &peer.GossipConfig{LeaderElection: peer.GossipConfig_DYNAMIC}}
+	g.gossipConfigUpdated(config)
+	g.InitializeChannel(testChainID, &mockLedgerInfo{1}, nil, []string{"localhost:5005"})
+	_, elects := g.leaderElection[testChainID]
+	assert.True(t, elects, "The peer should elect the leader dynamically per the channel config")
+	assert.False(t, deliverService.running[testChainID])
+
+	// Removing the gossip config restores the local settings of the peer, a static leader
+	config.gossipConfig = nil
+	g.gossipConfigUpdated(config)
+	_, elects = g.leaderElection[testChainID]
+	assert.False(t, elects)
+	assert.True(t, deliverService.running[testChainID])
+
+	// The static mode with the same local settings doesn't restart the leadership
+	var leadership []bool
+	g.RegisterLeadershipCallback(testChainID, func(isLeader bool) {
+		leadership = append(leadership, isLeader)
+	})
+	config.gossipConfig = &peer.GossipConfig{LeaderElection: peer.GossipConfig_STATIC}
+	g.gossipConfigUpdated(config)
+	assert.Equal(t, []bool{true}, leadership)
+	assert.True(t, deliverService.running[testChainID])
+}
+
+// haltingStateProvider is a state provider of a channel which may be halted
+type haltingStateProvider struct {
+	state.GossipStateProvider
+	err error
+}
+
+func (p *haltingStateProvider) HealthCheck(ctx context.Context) error {
+	return p.err
+}
+
+func TestChannelsHealthCheck(t *testing.T) {
+	g := &gossipServiceImpl{
+		chains: map[string]state.GossipStateProvider{
+			"chanA": &haltingStateProvider{},
+			"chanB": &haltingStateProvider{},
+		},
+	}
+	assert.NoError(t, g.ChannelsHealthCheck(context.Background()))
+
+	g.chains["chanC"] = &haltingStateProvider{err: errors.New("channel halted at block 5")}
+	g.chains["chanB"] = &haltingStateProvider{err: errors.New("channel halted at block 3")}
+	err := g.ChannelsHealthCheck(context.Background())
+	assert.EqualError(t, err, "chanB: channel halted at block 3; chanC: channel halted at block 5")
+}
+
 func TestWithStaticDeliverClientBothStaticAndLeaderElection(t *testing.T) {
 	viper.Set("peer.gossip.useLeaderElection", true)
 	viper.Set("peer.gossip.orgLeader", true)
@@ -257,7 +387,7 @@ func TestWithStaticDeliverClientBothStaticAndLeaderElection(t *testing.T) {
 	for i := 0; i < n; i++ {
 		gossips[i].(*gossipServiceImpl).deliveryFactory = deliverServiceFactory
 		assert.Panics(t, func() {
-			gossips[i].InitializeChannel(channelName, &mockLedgerInfo{1}, []string{"localhost:5005"})
+			gossips[i].InitializeChannel(channelName, &mockLedgerInfo{1}, nil, []string{"localhost:5005"})
 		}, "Dynamic leader lection based and static connection to ordering service can't exist simultaniosly")
 	}
 
@@ -289,6 +419,10 @@ func (ds *mockDeliverService) StopDeliverForChannel(chainID string) error {
 func (ds *mockDeliverService) Stop() {
 }
 
+func (*mockDeliverService) HealthCheck(ctx context.Context) error {
+	return nil
+}
+
 type mockLedgerInfo struct {
 	Height uint64
 }
@@ -303,6 +437,16 @@ func (li *mockLedgerInfo) Commit(block *common.Block) error {
 	return nil
 }
 
+// Commit block and private data to the ledger
+func (li *mockLedgerInfo) CommitWithPvtData(blockAndPvtData *ledger.BlockAndPvtData) error {
+	return nil
+}
+
+// Recommit the last block of the ledger
+func (li *mockLedgerInfo) RecommitLostBlock(block *common.Block) error {
+	return nil
+}
+
 // Gets blocks with sequence numbers provided in the slice
 func (li *mockLedgerInfo) GetBlocks(blockSeqs []uint64) []*common.Block {
 	return make([]*common.Block, 0)
@@ -609,9 +753,16 @@ func newGossipInstance(portPrefix int, id int, maxMsgCount int, boot ...int) Gos
 		gossipSvc:       gossip,
 		chains:          make(map[string]state.GossipStateProvider),
 		leaderElection:  make(map[string]election.LeaderElectionService),
+		leadership:      make(map[string]*election.LeadershipListeners),
+		distributors:    make(map[string]PvtDataDistributor),
+		pvtDataStops:    make(map[string]chan struct{}),
 		deliveryFactory: &deliveryFactoryImpl{},
 		idMapper:        idMapper,
 		peerIdentity:    api.PeerIdentityType(conf.InternalEndpoint),
+		secAdv:          &secAdvMock{},
+
+		leaderElectionModes:   make(map[string]peer.GossipConfig_LeaderElection),
+		leaderElectionConfigs: make(map[string]leaderElectionConfig),
 	}
 
 	return gossipService
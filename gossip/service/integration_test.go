@@ -18,6 +18,7 @@ import (
 	"github.com/hyperledger/fabric/gossip/election"
 	"github.com/hyperledger/fabric/gossip/identity"
 	"github.com/hyperledger/fabric/gossip/state"
+	"github.com/hyperledger/fabric/protos/peer"
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
 )
@@ -96,13 +97,19 @@ func TestLeaderYield(t *testing.T) {
 			gossipSvc:       gossips[i],
 			chains:          make(map[string]state.GossipStateProvider),
 			leaderElection:  make(map[string]election.LeaderElectionService),
+			leadership:      make(map[string]*election.LeadershipListeners),
+			distributors:    make(map[string]PvtDataDistributor),
+			pvtDataStops:    make(map[string]chan struct{}),
 			deliveryFactory: &embeddingDeliveryServiceFactory{&deliveryFactoryImpl{}},
 			idMapper:        identity.NewIdentityMapper(mcs, peerIdentity),
 			peerIdentity:    peerIdentity,
 			secAdv:          &secAdvMock{},
+
+			leaderElectionModes:   make(map[string]peer.GossipConfig_LeaderElection),
+			leaderElectionConfigs: make(map[string]leaderElectionConfig),
 		}
 		gossipServiceInstance = gs
-		gs.InitializeChannel(channelName, &mockLedgerInfo{1}, []string{"localhost:7050"})
+		gs.InitializeChannel(channelName, &mockLedgerInfo{1}, nil, []string{"localhost:7050"})
 		return gs
 	}
 
@@ -7,15 +7,19 @@ SPDX-License-Identifier: Apache-2.0
 package service
 
 import (
+	"errors"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/hyperledger/fabric/common/config/channel"
+	mockconfig "github.com/hyperledger/fabric/common/mocks/config"
 	"github.com/hyperledger/fabric/gossip/api"
 	"github.com/hyperledger/fabric/gossip/comm"
 	"github.com/hyperledger/fabric/gossip/common"
 	"github.com/hyperledger/fabric/gossip/discovery"
+	"github.com/hyperledger/fabric/gossip/gossip"
+	"github.com/hyperledger/fabric/gossip/gossip/channel"
 	"github.com/hyperledger/fabric/gossip/util"
 	proto "github.com/hyperledger/fabric/protos/gossip"
 	"github.com/hyperledger/fabric/protos/peer"
@@ -46,6 +50,10 @@ func (*gossipMock) Send(msg *proto.GossipMessage, peers ...*comm.RemotePeer) {
 	panic("implement me")
 }
 
+func (*gossipMock) SendByCriteria(msg *proto.GossipMessage, criteria gossip.SendCriteria) error {
+	panic("implement me")
+}
+
 func (*gossipMock) Peers() []discovery.NetworkMember {
 	panic("implement me")
 }
@@ -74,6 +82,10 @@ func (g *gossipMock) JoinChan(joinMsg api.JoinChannelMessage, chainID common.Cha
 	g.Called(joinMsg, chainID)
 }
 
+func (g *gossipMock) UpdateChannelConfig(conf channel.RuntimeConfig, chainID common.ChainID) {
+	g.Called(conf, chainID)
+}
+
 func (*gossipMock) Stop() {
 	panic("implement me")
 }
@@ -95,7 +107,8 @@ func (ao *appOrgMock) AnchorPeers() []*peer.AnchorPeer {
 }
 
 type configMock struct {
-	orgs2AppOrgs map[string]config.ApplicationOrg
+	orgs2AppOrgs    map[string]config.ApplicationOrg
+	capabilitiesErr error
 }
 
 func (*configMock) ChainID() string {
@@ -110,6 +123,14 @@ func (*configMock) Sequence() uint64 {
 	return 0
 }
 
+func (*configMock) GossipConfig() *peer.GossipConfig {
+	return nil
+}
+
+func (c *configMock) Capabilities() config.ApplicationCapabilities {
+	return &mockconfig.ApplicationCapabilities{SupportedErr: c.capabilitiesErr}
+}
+
 func TestJoinChannelConfig(t *testing.T) {
 	// Scenarios: The channel we're joining has a single org - Org0
 	// but our org ID is actually Org0MSP in the negative path
@@ -151,6 +172,29 @@ func TestJoinChannelConfig(t *testing.T) {
 	}
 }
 
+func TestJoinChannelUnsupportedCapabilities(t *testing.T) {
+	// Scenario: The channel we're joining requires application capabilities
+	// our peer doesn't support, so we shouldn't join it
+
+	joinChan := make(chan struct{}, 1)
+	gMock := &gossipMock{}
+	gMock.On("JoinChan", mock.Anything, mock.Anything).Run(func(_ mock.Arguments) {
+		joinChan <- struct{}{}
+	})
+	g := &gossipServiceImpl{secAdv: &secAdvMock{}, peerIdentity: api.PeerIdentityType("Org0"), gossipSvc: gMock}
+	g.configUpdated(&configMock{
+		orgs2AppOrgs: map[string]config.ApplicationOrg{
+			"Org0": &appOrgMock{id: "Org0"},
+		},
+		capabilitiesErr: errors.New("Application capability V9_9 is required but not supported"),
+	})
+	select {
+	case <-time.After(time.Second):
+	case <-joinChan:
+		assert.Fail(t, "Joined a channel requiring unsupported capabilities")
+	}
+}
+
 func TestJoinChannelNoAnchorPeers(t *testing.T) {
 	// Scenario: The channel we're joining has 2 orgs but no anchor peers
 	// The test ensures that JoinChan is called with a JoinChannelMessage with Members
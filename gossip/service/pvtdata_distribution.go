@@ -0,0 +1,255 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package service
+
+import (
+	"bytes"
+	"fmt"
+
+	protoutils "github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/core/common/privdata"
+	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/gossip/api"
+	"github.com/hyperledger/fabric/gossip/comm"
+	gossipCommon "github.com/hyperledger/fabric/gossip/common"
+	"github.com/hyperledger/fabric/gossip/discovery"
+	"github.com/hyperledger/fabric/gossip/util"
+	"github.com/hyperledger/fabric/protos/common"
+	proto "github.com/hyperledger/fabric/protos/gossip"
+	"github.com/hyperledger/fabric/protos/ledger/rwset"
+)
+
+// PvtDataDistributor pushes the private write sets of the transactions endorsed by the peer
+// to other peers of the channel which are members of their collections. These store them in
+// their transient store until the transactions are committed, instead of having to pull them
+// at commit time.
+type PvtDataDistributor interface {
+	// Distribute pushes the private write set of the transaction, simulated at the given
+	// block height, collection by collection
+	Distribute(txID string, pvtData *rwset.TxPvtReadWriteSet, blkHt uint64) error
+}
+
+// CollectionMembership tells which peers of the channel are members of the collections of
+// the chaincodes, and to how many of them the private data of a collection is pushed
+type CollectionMembership interface {
+	// IsMember returns whether the peer is a member of the collection of the chaincode,
+	// that is whether it may receive the private data of the collection
+	IsMember(namespace string, collection string, peer discovery.NetworkMember) bool
+
+	// PeerCounts returns the minimum and maximum numbers of members of the collection of
+	// the chaincode its private data is pushed to, and false if the collection doesn't
+	// define them
+	PeerCounts(namespace string, collection string) (required int, maximum int, defined bool)
+}
+
+// AllChannelPeers is the collection membership of the channels whose collections are not
+// restricted to a subset of their peers, every peer of the channel is a member
+var AllChannelPeers CollectionMembership = allChannelPeers{}
+
+type allChannelPeers struct{}
+
+func (allChannelPeers) IsMember(namespace string, collection string, peer discovery.NetworkMember) bool {
+	return true
+}
+
+func (allChannelPeers) PeerCounts(namespace string, collection string) (int, int, bool) {
+	return 0, 0, false
+}
+
+// collectionMembership is the collection membership defined by the collection configs
+// committed for the chaincodes of the channel, a peer being a member of a collection
+// if its organization is
+type collectionMembership struct {
+	chainID     string
+	collections privdata.CollectionStore
+	// orgOf returns the MSP ID of the organization of a peer of the channel
+	orgOf func(peer discovery.NetworkMember) string
+}
+
+func (m *collectionMembership) IsMember(namespace string, collection string, peer discovery.NetworkMember) bool {
+	cc := common.CollectionCriteria{Channel: m.chainID, Namespace: namespace, Collection: collection}
+	eligible, err := privdata.IsOrgEligible(m.collections, cc, m.orgOf(peer))
+	if err != nil {
+		logger.Warning("Failed retrieving the collection", namespace+":"+collection, "of channel", m.chainID, ":", err)
+		return false
+	}
+	return eligible
+}
+
+func (m *collectionMembership) PeerCounts(namespace string, collection string) (int, int, bool) {
+	cc := common.CollectionCriteria{Channel: m.chainID, Namespace: namespace, Collection: collection}
+	policy, err := m.collections.RetrieveCollectionAccessPolicy(cc)
+	if err != nil {
+		return 0, 0, false
+	}
+	return policy.RequiredPeerCount(), policy.MaximumPeerCount(), true
+}
+
+// pvtDataGossip is the part of gossip the private data is pushed with
+type pvtDataGossip interface {
+	// PeersOfChannel returns the NetworkMembers considered alive in a channel
+	PeersOfChannel(gossipCommon.ChainID) []discovery.NetworkMember
+
+	// Send sends a message to remote peers
+	Send(msg *proto.GossipMessage, peers ...*comm.RemotePeer)
+}
+
+type distributorImpl struct {
+	chainID       string
+	gossip        pvtDataGossip
+	pushPeerCount int
+	membership    CollectionMembership
+}
+
+// NewPvtDataDistributor creates a PvtDataDistributor pushing the private data of each
+// collection to members of the collection picked at random, as many as the maximum peer
+// count of the collection or pushPeerCount if the collection doesn't define it
+func NewPvtDataDistributor(chainID string, gossip pvtDataGossip, pushPeerCount int, membership CollectionMembership) PvtDataDistributor {
+	return &distributorImpl{
+		chainID:       chainID,
+		gossip:        gossip,
+		pushPeerCount: pushPeerCount,
+		membership:    membership,
+	}
+}
+
+// Distribute pushes the private write set of the transaction, simulated at the given block height
+func (d *distributorImpl) Distribute(txID string, pvtData *rwset.TxPvtReadWriteSet, blkHt uint64) error {
+	if pvtData == nil || d.pushPeerCount <= 0 {
+		return nil
+	}
+	peers := d.gossip.PeersOfChannel(gossipCommon.ChainID(d.chainID))
+
+	for _, nsPvtRWSet := range pvtData.NsPvtRwset {
+		for _, collPvtRWSet := range nsPvtRWSet.CollectionPvtRwset {
+			ns, coll := nsPvtRWSet.Namespace, collPvtRWSet.CollectionName
+			required, count, defined := d.membership.PeerCounts(ns, coll)
+			if !defined {
+				required, count = 0, d.pushPeerCount
+			}
+			remotePeers := d.selectMembers(ns, coll, peers, count)
+			if len(remotePeers) < required {
+				return fmt.Errorf("private data of collection %s:%s of transaction %s requires to be pushed to %d peers, but only %d members are available", ns, coll, txID, required, len(remotePeers))
+			}
+			if len(remotePeers) == 0 {
+				logger.Debugf("No member of collection %s:%s to push the private data of transaction %s to", ns, coll, txID)
+				continue
+			}
+
+			// the private write set restricted to the collection
+			collPvtData, err := protoutils.Marshal(&rwset.TxPvtReadWriteSet{
+				DataModel: pvtData.DataModel,
+				NsPvtRwset: []*rwset.NsPvtReadWriteSet{{
+					Namespace:          ns,
+					CollectionPvtRwset: []*rwset.CollectionPvtReadWriteSet{collPvtRWSet},
+				}},
+			})
+			if err != nil {
+				return fmt.Errorf("failed marshaling the private data of collection %s:%s of transaction %s: %s", ns, coll, txID, err)
+			}
+
+			msg := &proto.GossipMessage{
+				Nonce:   util.RandomUInt64(),
+				Tag:     proto.GossipMessage_CHAN_ONLY,
+				Channel: []byte(d.chainID),
+				Content: &proto.GossipMessage_PrivateData{PrivateData: &proto.PrivateDataMessage{
+					Payload: &proto.PrivatePayload{
+						Namespace:        ns,
+						CollectionName:   coll,
+						TxId:             txID,
+						PrivateRwset:     collPvtData,
+						PrivateSimHeight: blkHt,
+					},
+				}},
+			}
+			logger.Debugf("Pushing the private data of collection %s:%s of transaction %s to %d peer(s)", ns, coll, txID, len(remotePeers))
+			d.gossip.Send(msg, remotePeers...)
+		}
+	}
+	return nil
+}
+
+// selectMembers picks at random at most count of the peers which are members of the collection
+func (d *distributorImpl) selectMembers(ns string, coll string, peers []discovery.NetworkMember, count int) []*comm.RemotePeer {
+	var members []discovery.NetworkMember
+	for _, peer := range peers {
+		if d.membership.IsMember(ns, coll, peer) {
+			members = append(members, peer)
+		}
+	}
+
+	if count > len(members) {
+		count = len(members)
+	}
+	var remotePeers []*comm.RemotePeer
+	for _, i := range util.GetRandomIndices(count, len(members)-1) {
+		remotePeers = append(remotePeers, &comm.RemotePeer{Endpoint: members[i].PreferredEndpoint(), PKIID: members[i].PKIid})
+	}
+	return remotePeers
+}
+
+// pvtDataMsgFilter returns a filter accepting the private data messages of the channel from
+// the peers of the channel
+func pvtDataMsgFilter(chainID string, mcs api.MessageCryptoService) func(message interface{}) bool {
+	return func(message interface{}) bool {
+		receivedMsg := message.(proto.ReceivedMessage)
+		msg := receivedMsg.GetGossipMessage()
+		if !msg.IsPrivateDataMsg() || !bytes.Equal(msg.Channel, []byte(chainID)) {
+			return false
+		}
+		// If we're not running with authentication, no point
+		// in enforcing access control
+		connInfo := receivedMsg.GetConnectionInfo()
+		if !connInfo.IsAuthenticated() {
+			return true
+		}
+		authErr := mcs.VerifyByChannel(gossipCommon.ChainID(chainID), connInfo.Identity, connInfo.Auth.Signature, connInfo.Auth.SignedData)
+		if authErr != nil {
+			logger.Warning("Got private data from", string(connInfo.Identity), "which isn't authorized for channel", chainID)
+			return false
+		}
+		return true
+	}
+}
+
+// receivePvtData stores the private data pushed by the endorsers of the channel into the
+// transient store, until the messages channel is closed or the channel is closed by CloseChannel
+func receivePvtData(chainID string, persister ledger.TransientPvtDataPersister, msgs <-chan proto.ReceivedMessage, stop <-chan struct{}) {
+	for {
+		select {
+		case msg, ok := <-msgs:
+			if !ok {
+				return
+			}
+			if msg == nil {
+				continue
+			}
+			if err := storePvtData(persister, msg); err != nil {
+				logger.Warning("Failed storing the private data received from", msg.GetConnectionInfo().Endpoint, "on channel", chainID, ":", err)
+			}
+		case <-stop:
+			return
+		}
+	}
+}
+
+func storePvtData(persister ledger.TransientPvtDataPersister, msg proto.ReceivedMessage) error {
+	payload := msg.GetGossipMessage().GetPrivateData().Payload
+	if payload == nil || payload.TxId == "" {
+		return fmt.Errorf("empty private data payload")
+	}
+
+	pvtData := &rwset.TxPvtReadWriteSet{}
+	if err := protoutils.Unmarshal(payload.PrivateRwset, pvtData); err != nil {
+		return fmt.Errorf("invalid private data of transaction %s: %s", payload.TxId, err)
+	}
+
+	// the private data of each collection pushed by an endorser is kept apart, the ledger
+	// picks the collections matching the hashes of the transaction at commit
+	endorserID := fmt.Sprintf("%x/%s/%s", []byte(msg.GetConnectionInfo().ID), payload.Namespace, payload.CollectionName)
+	return persister.PersistPvtData(payload.TxId, endorserID, payload.PrivateSimHeight, pvtData)
+}
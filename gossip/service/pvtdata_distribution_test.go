@@ -0,0 +1,344 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+
+	protoutils "github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/core/common/privdata"
+	"github.com/hyperledger/fabric/gossip/api"
+	"github.com/hyperledger/fabric/gossip/comm"
+	"github.com/hyperledger/fabric/gossip/common"
+	"github.com/hyperledger/fabric/gossip/discovery"
+	fcommon "github.com/hyperledger/fabric/protos/common"
+	proto "github.com/hyperledger/fabric/protos/gossip"
+	"github.com/hyperledger/fabric/protos/ledger/rwset"
+	"github.com/stretchr/testify/assert"
+)
+
+type pvtDataGossipMock struct {
+	sync.Mutex
+	peers []discovery.NetworkMember
+	sent  map[string][]*comm.RemotePeer
+}
+
+func (g *pvtDataGossipMock) PeersOfChannel(common.ChainID) []discovery.NetworkMember {
+	return g.peers
+}
+
+func (g *pvtDataGossipMock) Send(msg *proto.GossipMessage, peers ...*comm.RemotePeer) {
+	g.Lock()
+	defer g.Unlock()
+	payload := msg.GetPrivateData().Payload
+	g.sent[payload.Namespace+":"+payload.CollectionName] = peers
+}
+
+type pvtDataPersisterMock struct {
+	txID       string
+	endorserID string
+	blkHt      uint64
+	pvtData    *rwset.TxPvtReadWriteSet
+}
+
+func (p *pvtDataPersisterMock) PersistPvtData(txid string, endorserid string, endorsementBlkHt uint64, pvtSimResults *rwset.TxPvtReadWriteSet) error {
+	p.txID, p.endorserID, p.blkHt, p.pvtData = txid, endorserid, endorsementBlkHt, pvtSimResults
+	return nil
+}
+
+type membershipMock struct {
+	members map[string][]string
+	counts  map[string][2]int
+}
+
+func (m *membershipMock) IsMember(namespace string, collection string, peer discovery.NetworkMember) bool {
+	for _, member := range m.members[collection] {
+		if member == peer.Endpoint {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *membershipMock) PeerCounts(namespace string, collection string) (int, int, bool) {
+	counts, defined := m.counts[collection]
+	return counts[0], counts[1], defined
+}
+
+type accessPolicyMock struct {
+	orgs     []string
+	required int
+	maximum  int
+}
+
+func (p *accessPolicyMock) AccessFilter() privdata.Filter {
+	return func(fcommon.SignedData) bool {
+		return false
+	}
+}
+
+func (p *accessPolicyMock) RequiredPeerCount() int {
+	return p.required
+}
+
+func (p *accessPolicyMock) MaximumPeerCount() int {
+	return p.maximum
+}
+
+func (p *accessPolicyMock) MemberOrgs() []string {
+	return p.orgs
+}
+
+// collectionStoreMock holds collection configs for the chaincodes of configs,
+// with the collections of policies
+type collectionStoreMock struct {
+	configs  map[string]bool
+	policies map[string]*accessPolicyMock
+}
+
+func (s *collectionStoreMock) RetrieveCollection(cc fcommon.CollectionCriteria) (privdata.Collection, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (s *collectionStoreMock) RetrieveCollectionAccessPolicy(cc fcommon.CollectionCriteria) (privdata.CollectionAccessPolicy, error) {
+	policy, exists := s.policies[cc.Collection]
+	if !s.configs[cc.Namespace] || !exists {
+		return nil, privdata.NoSuchCollectionError(cc)
+	}
+	return policy, nil
+}
+
+func (s *collectionStoreMock) RetrieveCollectionConfigPackage(cc fcommon.CollectionCriteria) (*fcommon.CollectionConfigPackage, error) {
+	if !s.configs[cc.Namespace] {
+		return nil, nil
+	}
+	return &fcommon.CollectionConfigPackage{}, nil
+}
+
+func (s *collectionStoreMock) RetrieveCollectionConfigPackageAt(cc fcommon.CollectionCriteria, blockNum uint64) (*fcommon.CollectionConfigPackage, error) {
+	return s.RetrieveCollectionConfigPackage(cc)
+}
+
+func (s *collectionStoreMock) RetrieveCollectionAccessPolicyAt(cc fcommon.CollectionCriteria, blockNum uint64) (privdata.CollectionAccessPolicy, error) {
+	return s.RetrieveCollectionAccessPolicy(cc)
+}
+
+type pvtDataMCSMock struct {
+	naiveCryptoService
+	err error
+}
+
+func (mcs *pvtDataMCSMock) VerifyByChannel(_ common.ChainID, _ api.PeerIdentityType, _, _ []byte) error {
+	return mcs.err
+}
+
+type pvtDataReceivedMsg struct {
+	msg      *proto.SignedGossipMessage
+	connInfo *proto.ConnectionInfo
+}
+
+func (m *pvtDataReceivedMsg) Respond(msg *proto.GossipMessage) {
+}
+
+func (m *pvtDataReceivedMsg) GetGossipMessage() *proto.SignedGossipMessage {
+	return m.msg
+}
+
+func (m *pvtDataReceivedMsg) GetSourceEnvelope() *proto.Envelope {
+	return nil
+}
+
+func (m *pvtDataReceivedMsg) GetConnectionInfo() *proto.ConnectionInfo {
+	return m.connInfo
+}
+
+func (m *pvtDataReceivedMsg) Ack(err error) {
+}
+
+func newPvtDataGossipMock(n int) *pvtDataGossipMock {
+	g := &pvtDataGossipMock{sent: make(map[string][]*comm.RemotePeer)}
+	for i := 0; i < n; i++ {
+		g.peers = append(g.peers, discovery.NetworkMember{
+			Endpoint: fmt.Sprintf("p%d", i),
+			PKIid:    common.PKIidType(fmt.Sprintf("p%d", i)),
+		})
+	}
+	return g
+}
+
+func testPvtData() *rwset.TxPvtReadWriteSet {
+	return &rwset.TxPvtReadWriteSet{
+		DataModel: rwset.TxReadWriteSet_KV,
+		NsPvtRwset: []*rwset.NsPvtReadWriteSet{
+			{
+				Namespace: "ns1",
+				CollectionPvtRwset: []*rwset.CollectionPvtReadWriteSet{
+					{CollectionName: "coll1", Rwset: []byte("rwset1")},
+					{CollectionName: "coll2", Rwset: []byte("rwset2")},
+				},
+			},
+		},
+	}
+}
+
+func TestPvtDataDistribute(t *testing.T) {
+	g := newPvtDataGossipMock(5)
+	d := NewPvtDataDistributor("A", g, 2, AllChannelPeers)
+
+	assert.NoError(t, d.Distribute("tx1", testPvtData(), 10))
+	assert.Len(t, g.sent, 2)
+	assert.Len(t, g.sent["ns1:coll1"], 2)
+	assert.Len(t, g.sent["ns1:coll2"], 2)
+
+	// no private data
+	g = newPvtDataGossipMock(5)
+	d = NewPvtDataDistributor("A", g, 2, AllChannelPeers)
+	assert.NoError(t, d.Distribute("tx1", nil, 10))
+	assert.Len(t, g.sent, 0)
+
+	// the push is disabled
+	d = NewPvtDataDistributor("A", g, 0, AllChannelPeers)
+	assert.NoError(t, d.Distribute("tx1", testPvtData(), 10))
+	assert.Len(t, g.sent, 0)
+}
+
+func TestPvtDataDistributeToMembers(t *testing.T) {
+	g := newPvtDataGossipMock(5)
+	// only p1 and p3 are members of coll1, no peer is a member of coll2
+	membership := &membershipMock{members: map[string][]string{"coll1": {"p1", "p3"}}}
+	d := NewPvtDataDistributor("A", g, 3, membership)
+
+	assert.NoError(t, d.Distribute("tx1", testPvtData(), 10))
+	assert.Len(t, g.sent, 1)
+	endpoints := []string{}
+	for _, p := range g.sent["ns1:coll1"] {
+		endpoints = append(endpoints, p.Endpoint)
+	}
+	sort.Strings(endpoints)
+	assert.Equal(t, []string{"p1", "p3"}, endpoints)
+}
+
+func TestPvtDataDistributePeerCounts(t *testing.T) {
+	members := map[string][]string{
+		"coll1": {"p0", "p1", "p2", "p3"},
+		"coll2": {"p0", "p1", "p2", "p3"},
+	}
+
+	// the maximum peer count of the collections overrides the configured push peer count
+	g := newPvtDataGossipMock(5)
+	membership := &membershipMock{members: members, counts: map[string][2]int{"coll1": {1, 3}}}
+	d := NewPvtDataDistributor("A", g, 1, membership)
+	assert.NoError(t, d.Distribute("tx1", testPvtData(), 10))
+	assert.Len(t, g.sent["ns1:coll1"], 3)
+	assert.Len(t, g.sent["ns1:coll2"], 1)
+
+	// a collection which disables the push
+	g = newPvtDataGossipMock(5)
+	membership = &membershipMock{members: members, counts: map[string][2]int{"coll1": {0, 0}}}
+	d = NewPvtDataDistributor("A", g, 2, membership)
+	assert.NoError(t, d.Distribute("tx1", testPvtData(), 10))
+	assert.Len(t, g.sent, 1)
+	assert.Len(t, g.sent["ns1:coll2"], 2)
+
+	// not enough members to satisfy the required peer count
+	g = newPvtDataGossipMock(5)
+	membership = &membershipMock{members: members, counts: map[string][2]int{"coll1": {5, 6}}}
+	d = NewPvtDataDistributor("A", g, 2, membership)
+	err := d.Distribute("tx1", testPvtData(), 10)
+	assert.EqualError(t, err, "private data of collection ns1:coll1 of transaction tx1 requires to be pushed to 5 peers, but only 4 members are available")
+}
+
+func TestCollectionMembership(t *testing.T) {
+	orgs := map[string]string{"p0": "Org1MSP", "p1": "Org2MSP", "p2": "Org3MSP"}
+	membership := &collectionMembership{
+		chainID: "A",
+		collections: &collectionStoreMock{
+			configs: map[string]bool{"ns1": true},
+			policies: map[string]*accessPolicyMock{
+				"coll1": {orgs: []string{"Org1MSP", "Org2MSP"}, required: 1, maximum: 2},
+			},
+		},
+		orgOf: func(peer discovery.NetworkMember) string {
+			return orgs[peer.Endpoint]
+		},
+	}
+
+	assert.True(t, membership.IsMember("ns1", "coll1", discovery.NetworkMember{Endpoint: "p0"}))
+	assert.True(t, membership.IsMember("ns1", "coll1", discovery.NetworkMember{Endpoint: "p1"}))
+	assert.False(t, membership.IsMember("ns1", "coll1", discovery.NetworkMember{Endpoint: "p2"}))
+	// the collection isn't in the collection configs of the chaincode
+	assert.False(t, membership.IsMember("ns1", "coll2", discovery.NetworkMember{Endpoint: "p0"}))
+	// the chaincode has no collection configs
+	assert.True(t, membership.IsMember("ns2", "coll1", discovery.NetworkMember{Endpoint: "p2"}))
+
+	required, maximum, defined := membership.PeerCounts("ns1", "coll1")
+	assert.True(t, defined)
+	assert.Equal(t, 1, required)
+	assert.Equal(t, 2, maximum)
+	_, _, defined = membership.PeerCounts("ns1", "coll2")
+	assert.False(t, defined)
+}
+
+func TestStorePvtData(t *testing.T) {
+	collPvtData, _ := protoutils.Marshal(testPvtData())
+	newMsg := func(payload *proto.PrivatePayload) proto.ReceivedMessage {
+		msg, _ := (&proto.GossipMessage{
+			Tag:     proto.GossipMessage_CHAN_ONLY,
+			Channel: []byte("A"),
+			Content: &proto.GossipMessage_PrivateData{PrivateData: &proto.PrivateDataMessage{Payload: payload}},
+		}).NoopSign()
+		return &pvtDataReceivedMsg{msg: msg, connInfo: &proto.ConnectionInfo{ID: common.PKIidType("p1")}}
+	}
+
+	persister := &pvtDataPersisterMock{}
+	err := storePvtData(persister, newMsg(&proto.PrivatePayload{
+		Namespace:        "ns1",
+		CollectionName:   "coll1",
+		TxId:             "tx1",
+		PrivateRwset:     collPvtData,
+		PrivateSimHeight: 10,
+	}))
+	assert.NoError(t, err)
+	assert.Equal(t, "tx1", persister.txID)
+	assert.Equal(t, "7031/ns1/coll1", persister.endorserID)
+	assert.Equal(t, uint64(10), persister.blkHt)
+	assert.True(t, protoutils.Equal(testPvtData(), persister.pvtData))
+
+	err = storePvtData(persister, newMsg(&proto.PrivatePayload{Namespace: "ns1", CollectionName: "coll1"}))
+	assert.EqualError(t, err, "empty private data payload")
+
+	err = storePvtData(persister, newMsg(&proto.PrivatePayload{TxId: "tx2", PrivateRwset: []byte{1, 2, 3}}))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid private data of transaction tx2")
+}
+
+func TestPvtDataMsgFilter(t *testing.T) {
+	newMsg := func(channel string, connInfo *proto.ConnectionInfo) proto.ReceivedMessage {
+		msg, _ := (&proto.GossipMessage{
+			Tag:     proto.GossipMessage_CHAN_ONLY,
+			Channel: []byte(channel),
+			Content: &proto.GossipMessage_PrivateData{PrivateData: &proto.PrivateDataMessage{Payload: &proto.PrivatePayload{}}},
+		}).NoopSign()
+		return &pvtDataReceivedMsg{msg: msg, connInfo: connInfo}
+	}
+	authInfo := &proto.ConnectionInfo{
+		Identity: []byte("p1"),
+		Auth:     &proto.AuthInfo{Signature: []byte("sig"), SignedData: []byte("data")},
+	}
+
+	filter := pvtDataMsgFilter("A", &pvtDataMCSMock{})
+	assert.True(t, filter(newMsg("A", &proto.ConnectionInfo{})))
+	assert.True(t, filter(newMsg("A", authInfo)))
+	assert.False(t, filter(newMsg("B", &proto.ConnectionInfo{})))
+
+	filter = pvtDataMsgFilter("A", &pvtDataMCSMock{err: errors.New("not a member of channel A")})
+	assert.False(t, filter(newMsg("A", authInfo)))
+}
@@ -0,0 +1,59 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package service
+
+import (
+	"github.com/hyperledger/fabric/gossip/discovery"
+	"github.com/hyperledger/fabric/gossip/state"
+	"github.com/spf13/viper"
+)
+
+const replicationConfigKey = "peer.gossip.state.replication"
+
+// replicationConfig is the configuration of the replication policies of the channels
+type replicationConfig struct {
+	Default  channelReplicationConfig
+	Channels map[string]channelReplicationConfig
+}
+
+// channelReplicationConfig is the replication policy of channels, whose
+// unset fields are inherited from the default replication policy
+type channelReplicationConfig struct {
+	PreferredOrgs        []string
+	MaxConcurrentFetches int
+	MaxBytesPerSecond    int
+}
+
+// replicationPolicy returns the replication policy of the channel configured under
+// peer.gossip.state.replication, which resolves the organizations of peers with orgOf
+func replicationPolicy(chainID string, orgOf func(peer discovery.NetworkMember) string) state.ReplicationPolicy {
+	var conf replicationConfig
+	if err := viper.UnmarshalKey(replicationConfigKey, &conf); err != nil {
+		logger.Warning("Failed reading the replication policy of channel", chainID, "due to", err, ", using the defaults")
+		conf = replicationConfig{}
+	}
+
+	policyConf := conf.Default
+	if channelConf, exists := conf.Channels[chainID]; exists {
+		if len(channelConf.PreferredOrgs) > 0 {
+			policyConf.PreferredOrgs = channelConf.PreferredOrgs
+		}
+		if channelConf.MaxConcurrentFetches != 0 {
+			policyConf.MaxConcurrentFetches = channelConf.MaxConcurrentFetches
+		}
+		if channelConf.MaxBytesPerSecond != 0 {
+			policyConf.MaxBytesPerSecond = channelConf.MaxBytesPerSecond
+		}
+	}
+
+	return state.ReplicationPolicy{
+		PreferredOrgs:        policyConf.PreferredOrgs,
+		OrgOf:                orgOf,
+		MaxConcurrentFetches: policyConf.MaxConcurrentFetches,
+		MaxBytesPerSecond:    policyConf.MaxBytesPerSecond,
+	}
+}
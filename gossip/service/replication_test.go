@@ -0,0 +1,51 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package service
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric/gossip/discovery"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReplicationPolicy(t *testing.T) {
+	defer viper.Set(replicationConfigKey, nil)
+	orgOf := func(peer discovery.NetworkMember) string { return "ORG1" }
+
+	// Nothing configured
+	viper.Set(replicationConfigKey, nil)
+	policy := replicationPolicy("mychannel", orgOf)
+	assert.Empty(t, policy.PreferredOrgs)
+	assert.Zero(t, policy.MaxConcurrentFetches)
+	assert.Zero(t, policy.MaxBytesPerSecond)
+	assert.NotNil(t, policy.OrgOf)
+
+	viper.Set(replicationConfigKey, map[string]interface{}{
+		"default": map[string]interface{}{
+			"preferredOrgs":        []string{"ORG1"},
+			"maxConcurrentFetches": 1,
+			"maxBytesPerSecond":    1048576,
+		},
+		"channels": map[string]interface{}{
+			"payments": map[string]interface{}{
+				"preferredOrgs":        []string{"ORG2", "ORG3"},
+				"maxConcurrentFetches": 4,
+			},
+		},
+	})
+	policy = replicationPolicy("payments", orgOf)
+	assert.Equal(t, []string{"ORG2", "ORG3"}, policy.PreferredOrgs)
+	assert.Equal(t, 4, policy.MaxConcurrentFetches)
+	assert.Equal(t, 1048576, policy.MaxBytesPerSecond, "Expected the unset fields to be inherited from the default policy")
+
+	policy = replicationPolicy("archive", orgOf)
+	assert.Equal(t, []string{"ORG1"}, policy.PreferredOrgs)
+	assert.Equal(t, 1, policy.MaxConcurrentFetches)
+	assert.Equal(t, 1048576, policy.MaxBytesPerSecond)
+}
@@ -0,0 +1,345 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package state
+
+import (
+	"bytes"
+	"time"
+
+	pb "github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/gossip/comm"
+	common2 "github.com/hyperledger/fabric/gossip/common"
+	"github.com/hyperledger/fabric/gossip/util"
+	proto "github.com/hyperledger/fabric/protos/gossip"
+	"golang.org/x/net/context"
+)
+
+// The peers joining a channel, e.g. the peers of an organization added to the channel by a config update,
+// start from the genesis block and would otherwise catch up by anti-entropy only. A peer in sync with the
+// channel offers the peers it sees joining the channel far behind it to push them the blocks they miss. The
+// joining peer accepts a single offer at a time, with the height the blocks are to be pushed from, and then
+// acknowledges the blocks it commits. The offering peer pushes the blocks as state responses carrying the
+// nonce of the offer, up to a window of blocks past the height last acknowledged
+const (
+	defBootstrapOfferInterval = 5 * time.Second
+	defBootstrapAckTimeout    = 10 * time.Second
+
+	// The peers are offered blocks if they are behind by more blocks than that
+	defBootstrapMinLag = defMaxBlockDistance
+	// The blocks pushed past the height last acknowledged
+	defBootstrapWindow = defMaxBlockDistance
+)
+
+// bootstrapOffer is an offer to push blocks made to a peer joining the channel
+type bootstrapOffer struct {
+	peer    *comm.RemotePeer
+	offered time.Time
+	// Set once the offer is accepted
+	pushing bool
+	// The heights the peer acknowledges
+	acks chan uint64
+}
+
+// acceptedOffer is the offer to push blocks this peer accepted
+type acceptedOffer struct {
+	peer   *comm.RemotePeer
+	nonce  uint64
+	height uint64
+	// The last time blocks of the offer were pushed
+	active time.Time
+}
+
+// offerBootstraps watches the membership of the channel, and offers the peers joining
+// it far behind this peer to push them the blocks they miss, while this peer is in sync
+func (s *GossipStateProviderImpl) offerBootstraps() {
+	defer s.done.Done()
+
+	known := make(map[string]struct{})
+	for {
+		select {
+		case <-s.stopCh:
+			s.stopCh <- struct{}{}
+			return
+		case <-time.After(defBootstrapOfferInterval):
+			s.offerBootstrapsToNewPeers(known)
+		}
+	}
+}
+
+// offerBootstrapsToNewPeers offers the peers of the channel which aren't in the known peers, and
+// are far behind this peer, to push them the blocks they miss. The known peers are updated to
+// the current peers of the channel, so that peers leaving and joining again are offered again
+func (s *GossipStateProviderImpl) offerBootstrapsToNewPeers(known map[string]struct{}) {
+	height, err := s.coordinator.LedgerHeight()
+	if err != nil {
+		logger.Error("Cannot obtain ledger height, due to", err)
+		return
+	}
+	inSync := height-1 >= s.maxAvailableLedgerHeight()
+
+	present := make(map[string]struct{})
+	for _, member := range s.mediator.PeersOfChannel(common2.ChainID(s.chainID)) {
+		id := string(member.PKIid)
+		present[id] = struct{}{}
+		if _, isKnown := known[id]; isKnown {
+			continue
+		}
+		known[id] = struct{}{}
+		nodeMetastate, err := FromBytes(member.Metadata)
+		if err != nil || !inSync || nodeMetastate.LedgerHeight+defBootstrapMinLag >= height-1 {
+			continue
+		}
+		s.offerBootstrap(&comm.RemotePeer{Endpoint: member.PreferredEndpoint(), PKIID: member.PKIid}, height)
+	}
+	for id := range known {
+		if _, isPresent := present[id]; !isPresent {
+			delete(known, id)
+		}
+	}
+}
+
+// offerBootstrap offers the peer to push it the blocks up to the given height
+func (s *GossipStateProviderImpl) offerBootstrap(peer *comm.RemotePeer, height uint64) {
+	nonce := util.RandomUInt64()
+	s.bootstrapMutex.Lock()
+	if s.bootstrapOffers == nil {
+		s.bootstrapOffers = make(map[uint64]*bootstrapOffer)
+	}
+	// Forget the offers that were never accepted
+	for n, offer := range s.bootstrapOffers {
+		if !offer.pushing && time.Since(offer.offered) > defBootstrapAckTimeout {
+			delete(s.bootstrapOffers, n)
+		}
+	}
+	s.bootstrapOffers[nonce] = &bootstrapOffer{peer: peer, offered: time.Now(), acks: make(chan uint64, 1)}
+	s.bootstrapMutex.Unlock()
+
+	s.logger.Infof("Offering %s to push it the blocks up to height %d", peer.Endpoint, height)
+	s.mediator.Send(&proto.GossipMessage{
+		Nonce:   nonce,
+		Tag:     proto.GossipMessage_CHAN_OR_ORG,
+		Channel: []byte(s.chainID),
+		Content: &proto.GossipMessage_StateBootstrapOffer{
+			StateBootstrapOffer: &proto.StateBootstrapOffer{Height: height},
+		},
+	}, peer)
+}
+
+// handleBootstrapAccept starts pushing the blocks to the peer accepting an offer of this
+// peer, or passes the height the peer acknowledges to the push of the blocks
+func (s *GossipStateProviderImpl) handleBootstrapAccept(msg proto.ReceivedMessage) {
+	nonce := msg.GetGossipMessage().Nonce
+	height := msg.GetGossipMessage().GetStateBootstrapAccept().Height
+
+	s.bootstrapMutex.Lock()
+	offer, exists := s.bootstrapOffers[nonce]
+	if !exists || !bytes.Equal(offer.peer.PKIID, msg.GetConnectionInfo().ID) {
+		s.bootstrapMutex.Unlock()
+		logger.Debug("Ignoring acceptance of unknown bootstrap offer from", msg.GetConnectionInfo().Endpoint)
+		return
+	}
+	defer s.bootstrapMutex.Unlock()
+
+	if !offer.pushing {
+		offer.pushing = true
+		s.logger.Infof("%s accepted to be pushed the blocks from height %d", offer.peer.Endpoint, height)
+		go s.pushBootstrapBlocks(nonce, offer, height)
+		return
+	}
+	// Keep the latest height acknowledged only
+	select {
+	case <-offer.acks:
+	default:
+	}
+	offer.acks <- height
+}
+
+// pushBootstrapBlocks pushes the blocks from the given height to the peer which accepted the offer, up to the
+// height of the ledger. It gives up when the peer doesn't acknowledge the blocks pushed to it in time
+func (s *GossipStateProviderImpl) pushBootstrapBlocks(nonce uint64, offer *bootstrapOffer, next uint64) {
+	defer func() {
+		s.bootstrapMutex.Lock()
+		delete(s.bootstrapOffers, nonce)
+		s.bootstrapMutex.Unlock()
+	}()
+
+	acked := next
+	for {
+		height, err := s.coordinator.LedgerHeight()
+		if err != nil {
+			logger.Error("Cannot obtain ledger height, due to", err)
+			return
+		}
+		if next >= height {
+			s.logger.Infof("Pushed %s the blocks up to height %d", offer.peer.Endpoint, height)
+			return
+		}
+		select {
+		case h := <-offer.acks:
+			if h > acked {
+				acked = h
+			}
+		default:
+		}
+		if next >= acked+defBootstrapWindow {
+			select {
+			case h := <-offer.acks:
+				if h > acked {
+					acked = h
+				}
+				continue
+			case <-time.After(defBootstrapAckTimeout):
+				s.logger.Warningf("%s didn't acknowledge the blocks pushed to it past height %d, stop pushing blocks",
+					offer.peer.Endpoint, acked)
+				return
+			case <-s.ctx.Done():
+				return
+			}
+		}
+
+		end := min(next+defAntiEntropyBatchSize-1, height-1)
+		response, err := s.bootstrapResponse(nonce, next, end)
+		if err != nil {
+			s.logger.Errorf("Wasn't able to read blocks in range [%d...%d] to push to %s, due to %s",
+				next, end, offer.peer.Endpoint, err)
+			return
+		}
+		if !s.throttleBootstrapPush(offer.peer, response) {
+			return
+		}
+		s.mediator.Send(response, offer.peer)
+		payloads := response.GetStateResponse().Payloads
+		next = payloads[len(payloads)-1].SeqNum + 1
+	}
+}
+
+// bootstrapResponse returns the state response pushing the blocks in the range [start...end]
+func (s *GossipStateProviderImpl) bootstrapResponse(nonce uint64, start uint64, end uint64) (*proto.GossipMessage, error) {
+	ctx, cancel := context.WithTimeout(s.ctx, defAntiEntropyStateResponseTimeout)
+	defer cancel()
+	itr, err := s.coordinator.GetBlocksWithPvtDataIterator(start, end)
+	if err != nil {
+		return nil, err
+	}
+	defer itr.Close()
+	payloads, err := s.readPayloads(ctx, itr)
+	if len(payloads) == 0 {
+		if err == nil {
+			err = ctx.Err()
+		}
+		return nil, err
+	}
+	return &proto.GossipMessage{
+		Nonce:   nonce,
+		Tag:     proto.GossipMessage_CHAN_OR_ORG,
+		Channel: []byte(s.chainID),
+		Content: &proto.GossipMessage_StateResponse{
+			StateResponse: &proto.RemoteStateResponse{Payloads: payloads},
+		},
+	}, nil
+}
+
+// throttleBootstrapPush waits as long as the bandwidth caps of the state responses require before pushing the
+// response to the peer. It returns false if the response cannot be pushed in time, or the provider is stopped
+func (s *GossipStateProviderImpl) throttleBootstrapPush(peer *comm.RemotePeer, response *proto.GossipMessage) bool {
+	if s.responseThrottle == nil {
+		return true
+	}
+	size := pb.Size(response)
+	wait, admitted := s.responseThrottle.admit(string(peer.PKIID), size, defBootstrapAckTimeout)
+	if !admitted {
+		s.logger.Warningf("Pushing blocks to %s exceeds the bandwidth cap of the state responses, stop pushing blocks",
+			peer.Endpoint)
+		return false
+	}
+	select {
+	case <-time.After(wait):
+		return true
+	case <-s.ctx.Done():
+		return false
+	}
+}
+
+// handleBootstrapOffer accepts an offer to push the blocks this peer misses, unless
+// another offer is being pushed, and tells the height to push the blocks from
+func (s *GossipStateProviderImpl) handleBootstrapOffer(msg proto.ReceivedMessage) {
+	offered := msg.GetGossipMessage().GetStateBootstrapOffer().Height
+	height, err := s.coordinator.LedgerHeight()
+	if err != nil {
+		logger.Error("Cannot obtain ledger height, due to", err)
+		return
+	}
+	connInfo := msg.GetConnectionInfo()
+	if offered <= height {
+		logger.Debug("Ignoring bootstrap offer of", connInfo.Endpoint, "up to height", offered, "as the ledger is at height", height)
+		return
+	}
+
+	s.bootstrapMutex.Lock()
+	if s.acceptedOffer != nil && time.Since(s.acceptedOffer.active) < defBootstrapAckTimeout {
+		s.bootstrapMutex.Unlock()
+		logger.Debug("Ignoring bootstrap offer of", connInfo.Endpoint, "as blocks are being pushed by", s.acceptedOffer.peer.Endpoint)
+		return
+	}
+	s.acceptedOffer = &acceptedOffer{
+		peer:   &comm.RemotePeer{Endpoint: connInfo.Endpoint, PKIID: connInfo.ID},
+		nonce:  msg.GetGossipMessage().Nonce,
+		height: offered,
+		active: time.Now(),
+	}
+	s.bootstrapMutex.Unlock()
+
+	s.logger.Infof("Accepting the offer of %s to push the blocks from height %d up to height %d", connInfo.Endpoint, height, offered)
+	msg.Respond(s.bootstrapAccept(msg.GetGossipMessage().Nonce, height))
+}
+
+// handleBootstrapPush adds the blocks of a state response pushed by the peer whose offer
+// was accepted to the payloads buffer. It returns false if the response isn't pushed
+func (s *GossipStateProviderImpl) handleBootstrapPush(msg proto.ReceivedMessage) bool {
+	s.bootstrapMutex.Lock()
+	offer := s.acceptedOffer
+	isPush := offer != nil && offer.nonce == msg.GetGossipMessage().Nonce &&
+		bytes.Equal(offer.peer.PKIID, msg.GetConnectionInfo().ID)
+	if isPush {
+		offer.active = time.Now()
+	}
+	s.bootstrapMutex.Unlock()
+	if !isPush {
+		return false
+	}
+	if _, err := s.handleStateResponse(msg); err != nil {
+		s.logger.Warningf("Failed handling the blocks pushed by %s: %s", offer.peer.Endpoint, err)
+	}
+	return true
+}
+
+// ackBootstrapPush acknowledges the commit of the given block to the peer pushing the blocks,
+// once every batch of blocks, and ends the push once the offered height is reached
+func (s *GossipStateProviderImpl) ackBootstrapPush(seqNum uint64) {
+	height := seqNum + 1
+	s.bootstrapMutex.Lock()
+	offer := s.acceptedOffer
+	if offer != nil && height >= offer.height {
+		s.acceptedOffer = nil
+	}
+	s.bootstrapMutex.Unlock()
+	if offer == nil || (height%defAntiEntropyBatchSize != 0 && height < offer.height) {
+		return
+	}
+	s.mediator.Send(s.bootstrapAccept(offer.nonce, height), offer.peer)
+}
+
+func (s *GossipStateProviderImpl) bootstrapAccept(nonce uint64, height uint64) *proto.GossipMessage {
+	return &proto.GossipMessage{
+		Nonce:   nonce,
+		Tag:     proto.GossipMessage_CHAN_OR_ORG,
+		Channel: []byte(s.chainID),
+		Content: &proto.GossipMessage_StateBootstrapAccept{
+			StateBootstrapAccept: &proto.StateBootstrapAccept{Height: height},
+		},
+	}
+}
@@ -0,0 +1,200 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package state
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/gossip/common"
+	"github.com/hyperledger/fabric/gossip/discovery"
+	"github.com/hyperledger/fabric/gossip/state/mocks"
+	gutil "github.com/hyperledger/fabric/gossip/util"
+	pcomm "github.com/hyperledger/fabric/protos/common"
+	proto "github.com/hyperledger/fabric/protos/gossip"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// committingCoordinatorMock is a coordinator whose ledger height grows with the blocks it stores
+type committingCoordinatorMock struct {
+	coordinatorMock
+	height uint64
+}
+
+func (mock *committingCoordinatorMock) StoreBlock(block *pcomm.Block, data ...PvtDataCollections) ([]string, error) {
+	atomic.StoreUint64(&mock.height, block.Header.Number+1)
+	return nil, nil
+}
+
+func (mock *committingCoordinatorMock) LedgerHeight() (uint64, error) {
+	return atomic.LoadUint64(&mock.height), nil
+}
+
+func TestBootstrapPush(t *testing.T) {
+	chainID := "testchainid"
+	offererID, joinerID := common.PKIidType("offerer"), common.PKIidType("joiner")
+	offererComm := make(chan proto.ReceivedMessage, 10)
+	joinerComm := make(chan proto.ReceivedMessage, 10)
+	offerer, joiner := &mocks.GossipMock{}, &mocks.GossipMock{}
+	for g, comm := range map[*mocks.GossipMock]chan proto.ReceivedMessage{offerer: offererComm, joiner: joinerComm} {
+		g.On("Accept", mock.Anything, false).Return(make(<-chan *proto.GossipMessage), nil)
+		g.On("Accept", mock.Anything, true).Return(nil, (<-chan proto.ReceivedMessage)(comm))
+	}
+
+	// route delivers the messages a peer sends to the other peer, and the responses back
+	var route func(msg *proto.GossipMessage, from common.PKIidType, to chan proto.ReceivedMessage, back chan proto.ReceivedMessage, backID common.PKIidType)
+	route = func(msg *proto.GossipMessage, from common.PKIidType, to chan proto.ReceivedMessage, back chan proto.ReceivedMessage, backID common.PKIidType) {
+		signed, _ := msg.NoopSign()
+		received := new(receivedMessageMock)
+		received.On("GetGossipMessage").Return(signed)
+		received.On("GetConnectionInfo").Return(&proto.ConnectionInfo{ID: from, Endpoint: string(from)})
+		received.On("Respond", mock.Anything).Run(func(args mock.Arguments) {
+			route(args.Get(0).(*proto.GossipMessage), backID, back, to, from)
+		})
+		to <- received
+	}
+	var offers, pushes int32
+	offerer.On("Send", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		msg := args.Get(0).(*proto.GossipMessage)
+		if msg.GetStateBootstrapOffer() != nil {
+			atomic.AddInt32(&offers, 1)
+		} else if msg.GetStateResponse() != nil {
+			atomic.AddInt32(&pushes, 1)
+		}
+		route(msg, offererID, joinerComm, offererComm, joinerID)
+	})
+	joiner.On("Send", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		route(args.Get(0).(*proto.GossipMessage), joinerID, offererComm, joinerComm, offererID)
+	})
+
+	joinerMeta, _ := NewNodeMetastate(0).Bytes()
+	offererMeta, _ := NewNodeMetastate(299).Bytes()
+	offerer.On("PeersOfChannel", mock.Anything).Return([]discovery.NetworkMember{{PKIid: joinerID, Endpoint: "joiner", Metadata: joinerMeta}})
+	joiner.On("PeersOfChannel", mock.Anything).Return([]discovery.NetworkMember{{PKIid: offererID, Endpoint: "offerer", Metadata: offererMeta}})
+
+	offererCoord := &committingCoordinatorMock{height: 300}
+	for seqNum := uint64(1); seqNum < 300; seqNum++ {
+		offererCoord.On("GetPvtDataAndBlockByNum", seqNum).Return(pcomm.NewBlock(seqNum, []byte{}), PvtDataCollections{}, nil)
+	}
+	joinerCoord := &committingCoordinatorMock{height: 1}
+	for _, coord := range []*committingCoordinatorMock{offererCoord, joinerCoord} {
+		coord.On("Close")
+	}
+
+	cryptoService := &cryptoServiceMock{acceptor: noopPeerIdentityAcceptor}
+	offererState := NewGossipCoordinatedStateProvider(chainID, &ServicesMediator{GossipAdapter: offerer, MCSAdapter: cryptoService}, offererCoord).(*GossipStateProviderImpl)
+	defer offererState.Stop()
+	joinerState := NewGossipCoordinatedStateProvider(chainID, &ServicesMediator{GossipAdapter: joiner, MCSAdapter: cryptoService}, joinerCoord).(*GossipStateProviderImpl)
+	defer joinerState.Stop()
+
+	// The joining peer is offered the blocks once, and is pushed all of them
+	known := make(map[string]struct{})
+	offererState.offerBootstrapsToNewPeers(known)
+	offererState.offerBootstrapsToNewPeers(known)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&offers))
+	waitUntilTrueOrTimeout(t, func() bool {
+		height, _ := joinerCoord.LedgerHeight()
+		return height == 300
+	}, 10*time.Second)
+	assert.Equal(t, int32(30), atomic.LoadInt32(&pushes))
+	waitUntilTrueOrTimeout(t, func() bool {
+		offererState.bootstrapMutex.Lock()
+		defer offererState.bootstrapMutex.Unlock()
+		return len(offererState.bootstrapOffers) == 0
+	}, 10*time.Second)
+	joinerState.bootstrapMutex.Lock()
+	assert.Nil(t, joinerState.acceptedOffer)
+	joinerState.bootstrapMutex.Unlock()
+
+	// Peers which aren't far behind are not offered the blocks
+	atomic.StoreUint64(&offererCoord.height, 50)
+	offererState.offerBootstrapsToNewPeers(make(map[string]struct{}))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&offers))
+}
+
+func TestBootstrapOfferAcceptance(t *testing.T) {
+	g := &mocks.GossipMock{}
+	g.On("Send", mock.Anything, mock.Anything)
+	coord := new(coordinatorMock)
+	coord.On("LedgerHeight", mock.Anything).Return(uint64(10), nil)
+	s := &GossipStateProviderImpl{
+		chainID:     "testchainid",
+		logger:      flogging.MustGetContextLogger(gutil.LoggingStateModule),
+		coordinator: coord,
+		mediator:    &ServicesMediator{GossipAdapter: g, MCSAdapter: &cryptoServiceMock{}},
+		payloads:    NewPayloadsBuffer(10),
+	}
+
+	newMsg := func(from string, gossipMsg *proto.GossipMessage) *receivedMessageMock {
+		signed, _ := gossipMsg.NoopSign()
+		msg := new(receivedMessageMock)
+		msg.On("GetGossipMessage").Return(signed)
+		msg.On("GetConnectionInfo").Return(&proto.ConnectionInfo{ID: common.PKIidType(from), Endpoint: from})
+		msg.On("Respond", mock.Anything)
+		return msg
+	}
+	offer := func(nonce uint64, height uint64) *proto.GossipMessage {
+		return &proto.GossipMessage{
+			Nonce:   nonce,
+			Channel: []byte("testchainid"),
+			Content: &proto.GossipMessage_StateBootstrapOffer{StateBootstrapOffer: &proto.StateBootstrapOffer{Height: height}},
+		}
+	}
+	push := func(nonce uint64, payload *proto.Payload) *proto.GossipMessage {
+		return &proto.GossipMessage{
+			Nonce:   nonce,
+			Channel: []byte("testchainid"),
+			Content: &proto.GossipMessage_StateResponse{StateResponse: &proto.RemoteStateResponse{Payloads: []*proto.Payload{payload}}},
+		}
+	}
+
+	// An offer of blocks the peer has is ignored
+	msg := newMsg("p1", offer(1, 10))
+	s.handleBootstrapOffer(msg)
+	msg.AssertNotCalled(t, "Respond", mock.Anything)
+
+	// An offer of missing blocks is accepted with the height of the ledger
+	msg = newMsg("p1", offer(2, 100))
+	s.handleBootstrapOffer(msg)
+	msg.AssertCalled(t, "Respond", s.bootstrapAccept(2, 10))
+
+	// Offers are ignored while the accepted offer is being pushed
+	msg = newMsg("p2", offer(3, 100))
+	s.handleBootstrapOffer(msg)
+	msg.AssertNotCalled(t, "Respond", mock.Anything)
+
+	// Only the blocks pushed by the peer of the accepted offer are handled
+	payload, _ := randomPayloadWithSeqNum(10)
+	assert.False(t, s.handleBootstrapPush(newMsg("p2", push(2, payload))))
+	assert.False(t, s.handleBootstrapPush(newMsg("p1", push(3, payload))))
+	assert.Equal(t, 0, s.payloads.Size())
+	assert.True(t, s.handleBootstrapPush(newMsg("p1", push(2, payload))))
+	assert.Equal(t, 1, s.payloads.Size())
+
+	// The commits are acknowledged every batch of blocks, and up to the offered height
+	s.ackBootstrapPush(10)
+	g.AssertNumberOfCalls(t, "Send", 0)
+	s.ackBootstrapPush(19)
+	g.AssertCalled(t, "Send", s.bootstrapAccept(2, 20), mock.Anything)
+	s.ackBootstrapPush(99)
+	g.AssertCalled(t, "Send", s.bootstrapAccept(2, 100), mock.Anything)
+	assert.Nil(t, s.acceptedOffer)
+
+	// Another offer is accepted once the push is done
+	msg = newMsg("p2", offer(4, 200))
+	s.handleBootstrapOffer(msg)
+	msg.AssertCalled(t, "Respond", s.bootstrapAccept(4, 10))
+
+	// Or once the push of the accepted offer stalls
+	s.acceptedOffer.active = time.Now().Add(-defBootstrapAckTimeout)
+	msg = newMsg("p3", offer(5, 200))
+	s.handleBootstrapOffer(msg)
+	msg.AssertCalled(t, "Respond", s.bootstrapAccept(5, 10))
+}
@@ -0,0 +1,174 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package state
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	pb "github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/protos/common"
+	proto "github.com/hyperledger/fabric/protos/gossip"
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+)
+
+const (
+	defCommitMaxAttempts    = 5
+	defCommitInitialBackoff = 500 * time.Millisecond
+	defCommitMaxBackoff     = 30 * time.Second
+)
+
+// CommitRetryConfig configures how the state provider retries committing a block to the
+// ledger, and where it quarantines the block once it gives up committing it
+type CommitRetryConfig struct {
+	// MaxAttempts is the number of attempts to commit a block before
+	// quarantining it, 5 if not positive
+	MaxAttempts int
+	// InitialBackoff is the wait before retrying the first failed
+	// commit, doubled on every retry, 500ms if not positive
+	InitialBackoff time.Duration
+	// MaxBackoff caps the wait between the retries, 30s if not positive
+	MaxBackoff time.Duration
+	// QuarantineDir is the directory the blocks which cannot be committed are persisted
+	// to for inspection, the default directory for temporary files if empty
+	QuarantineDir string
+}
+
+// withDefaults returns the configuration with the defaults of the fields not set
+func (conf CommitRetryConfig) withDefaults() CommitRetryConfig {
+	if conf.MaxAttempts <= 0 {
+		conf.MaxAttempts = defCommitMaxAttempts
+	}
+	if conf.InitialBackoff <= 0 {
+		conf.InitialBackoff = defCommitInitialBackoff
+	}
+	if conf.MaxBackoff <= 0 {
+		conf.MaxBackoff = defCommitMaxBackoff
+	}
+	if conf.QuarantineDir == "" {
+		conf.QuarantineDir = os.TempDir()
+	}
+	return conf
+}
+
+// quarantinedBlock is the block a channel is halted at, as it cannot be committed
+type quarantinedBlock struct {
+	seqNum uint64
+	// The file the block is persisted to, empty if it could not be persisted
+	path string
+	err  error
+}
+
+// commitWithRetry commits the block to the ledger, and retries the failed commits with exponential backoff.
+// Once the attempts of the retry policy are exhausted the block is quarantined and the channel halted.
+// It returns false if the block isn't committed, as it is quarantined or the provider is stopped
+func (s *GossipStateProviderImpl) commitWithRetry(block *common.Block, pvtData PvtDataCollections) bool {
+	backoff := s.commitRetry.InitialBackoff
+	for attempt := 1; ; attempt++ {
+		var err error
+		if attempt == 1 {
+			err = s.commitBlock(block, pvtData)
+		} else {
+			err = s.retryCommit(block, pvtData)
+		}
+		if err == nil {
+			return true
+		}
+		if attempt >= s.commitRetry.MaxAttempts {
+			s.quarantine(block, pvtData, err)
+			return false
+		}
+		s.logger.Warningf("Failed committing block %d (attempt %d of %d), retrying in %s: %s",
+			block.Header.Number, attempt, s.commitRetry.MaxAttempts, backoff, err)
+		select {
+		case <-time.After(backoff):
+		case <-s.ctx.Done():
+			return false
+		}
+		if backoff *= 2; backoff > s.commitRetry.MaxBackoff {
+			backoff = s.commitRetry.MaxBackoff
+		}
+	}
+}
+
+// retryCommit commits the block again, unless the failed commit added it to the block store already,
+// which would reject it. In that case only its private data and state are committed
+func (s *GossipStateProviderImpl) retryCommit(block *common.Block, pvtData PvtDataCollections) error {
+	height, err := s.coordinator.LedgerHeight()
+	if err != nil {
+		return errors.Wrap(err, "failed reading ledger height")
+	}
+	if height <= block.Header.Number {
+		return s.commitBlock(block, pvtData)
+	}
+	s.logger.Infof("Block %d was added to the block store by the failed commit, committing its private data and state", block.Header.Number)
+	if err := s.coordinator.RecommitLostBlock(block); err != nil {
+		return err
+	}
+	s.blockCommitted(block, pvtData)
+	return nil
+}
+
+// quarantine persists the block which cannot be committed, along with its private data, as a
+// gossip payload to the quarantine directory for the operator to inspect it, and halts the channel.
+// No block of the channel is committed past it until the peer is restarted
+func (s *GossipStateProviderImpl) quarantine(block *common.Block, pvtData PvtDataCollections, commitErr error) {
+	quarantined := &quarantinedBlock{seqNum: block.Header.Number, err: commitErr}
+	path, err := s.persistQuarantined(block, pvtData)
+	if err != nil {
+		s.logger.Errorf("Failed persisting quarantined block %d: %s", block.Header.Number, err)
+	} else {
+		quarantined.path = path
+	}
+	s.quarantined.Store(quarantined)
+	s.logger.Errorf("Halting channel %s, block %d cannot be committed and is quarantined in %s: %s",
+		s.chainID, block.Header.Number, quarantined.path, commitErr)
+}
+
+// persistQuarantined writes the block and its private data to a file of the quarantine directory named
+// after the channel and the sequence number of the block, and returns the path of the file
+func (s *GossipStateProviderImpl) persistQuarantined(block *common.Block, pvtData PvtDataCollections) (string, error) {
+	data, err := pb.Marshal(block)
+	if err != nil {
+		return "", errors.Wrap(err, "failed marshaling block")
+	}
+	privateData, err := pvtData.Marshal()
+	if err != nil {
+		return "", errors.Wrap(err, "failed marshaling private data")
+	}
+	payload, err := pb.Marshal(&proto.Payload{SeqNum: block.Header.Number, Data: data, PrivateData: privateData})
+	if err != nil {
+		return "", errors.Wrap(err, "failed marshaling payload")
+	}
+	if err := os.MkdirAll(s.commitRetry.QuarantineDir, 0755); err != nil {
+		return "", errors.Wrap(err, "failed creating quarantine directory")
+	}
+	path := filepath.Join(s.commitRetry.QuarantineDir, fmt.Sprintf("%s_%d.block", s.chainID, block.Header.Number))
+	if err := ioutil.WriteFile(path, payload, 0644); err != nil {
+		return "", errors.Wrap(err, "failed writing quarantined block")
+	}
+	return path, nil
+}
+
+// haltedAt returns the block the channel is halted at, or nil if it isn't halted
+func (s *GossipStateProviderImpl) haltedAt() *quarantinedBlock {
+	quarantined, _ := s.quarantined.Load().(*quarantinedBlock)
+	return quarantined
+}
+
+// HealthCheck returns an error once the channel is halted, as a block cannot be committed
+func (s *GossipStateProviderImpl) HealthCheck(ctx context.Context) error {
+	quarantined := s.haltedAt()
+	if quarantined == nil {
+		return nil
+	}
+	return errors.Errorf("channel halted at block %d, quarantined in %s: %s", quarantined.seqNum, quarantined.path, quarantined.err)
+}
@@ -0,0 +1,124 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package state
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	pb "github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/gossip/state/mocks"
+	gutil "github.com/hyperledger/fabric/gossip/util"
+	pcomm "github.com/hyperledger/fabric/protos/common"
+	proto "github.com/hyperledger/fabric/protos/gossip"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/net/context"
+)
+
+func TestCommitRetryConfigDefaults(t *testing.T) {
+	conf := CommitRetryConfig{}.withDefaults()
+	assert.Equal(t, CommitRetryConfig{
+		MaxAttempts:    defCommitMaxAttempts,
+		InitialBackoff: defCommitInitialBackoff,
+		MaxBackoff:     defCommitMaxBackoff,
+		QuarantineDir:  os.TempDir(),
+	}, conf)
+	conf = CommitRetryConfig{MaxAttempts: 1, InitialBackoff: time.Second, MaxBackoff: time.Minute, QuarantineDir: "/quarantine"}
+	assert.Equal(t, conf, conf.withDefaults())
+}
+
+func TestCommitWithRetry(t *testing.T) {
+	dir, err := ioutil.TempDir("", "quarantine")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	g := &mocks.GossipMock{}
+	g.On("UpdateChannelMetadata", mock.Anything, mock.Anything)
+	newProvider := func(coord Coordinator) *GossipStateProviderImpl {
+		ctx, cancel := context.WithCancel(context.Background())
+		return &GossipStateProviderImpl{
+			chainID:     "testchainid",
+			logger:      flogging.MustGetContextLogger(gutil.LoggingStateModule),
+			coordinator: coord,
+			mediator:    &ServicesMediator{GossipAdapter: g},
+			payloads:    NewPayloadsBuffer(1),
+			ctx:         ctx,
+			cancel:      cancel,
+			commitRetry: CommitRetryConfig{
+				MaxAttempts:    3,
+				InitialBackoff: time.Millisecond,
+				MaxBackoff:     2 * time.Millisecond,
+				QuarantineDir:  dir,
+			}.withDefaults(),
+		}
+	}
+
+	// A block whose commit fails transiently is committed once retried
+	coord := new(coordinatorMock)
+	coord.On("StoreBlock", mock.Anything, mock.Anything).Return([]string{}, errors.New("ledger busy")).Twice()
+	coord.On("StoreBlock", mock.Anything, mock.Anything).Return([]string{}, nil).Once()
+	coord.On("LedgerHeight").Return(uint64(1), nil)
+	s := newProvider(coord)
+	assert.True(t, s.commitWithRetry(pcomm.NewBlock(1, []byte{}), nil))
+	coord.AssertNumberOfCalls(t, "StoreBlock", 3)
+	assert.NoError(t, s.HealthCheck(context.Background()))
+
+	// A block which the failed commit added to the block store is not stored again,
+	// only its private data and state are committed
+	coord = new(coordinatorMock)
+	coord.On("StoreBlock", mock.Anything, mock.Anything).Return([]string{}, errors.New("state database unavailable")).Once()
+	coord.On("LedgerHeight").Return(uint64(2), nil)
+	coord.On("RecommitLostBlock", mock.Anything).Return(errors.New("state database unavailable")).Once()
+	coord.On("RecommitLostBlock", mock.Anything).Return(nil).Once()
+	s = newProvider(coord)
+	assert.True(t, s.commitWithRetry(pcomm.NewBlock(1, []byte{}), nil))
+	coord.AssertNumberOfCalls(t, "StoreBlock", 1)
+	coord.AssertNumberOfCalls(t, "RecommitLostBlock", 2)
+	assert.NoError(t, s.HealthCheck(context.Background()))
+
+	// A block which cannot be committed is quarantined, and the channel halted
+	coord = new(coordinatorMock)
+	coord.On("StoreBlock", mock.Anything, mock.Anything).Return([]string{}, errors.New("invalid block"))
+	coord.On("LedgerHeight").Return(uint64(1), nil)
+	s = newProvider(coord)
+	block := pcomm.NewBlock(1, []byte{})
+	assert.False(t, s.commitWithRetry(block, nil))
+	coord.AssertNumberOfCalls(t, "StoreBlock", 3)
+	err = s.HealthCheck(context.Background())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "channel halted at block 1")
+	assert.Contains(t, err.Error(), "invalid block")
+
+	bytes, err := ioutil.ReadFile(filepath.Join(dir, "testchainid_1.block"))
+	assert.NoError(t, err)
+	payload := &proto.Payload{}
+	assert.NoError(t, pb.Unmarshal(bytes, payload))
+	assert.Equal(t, uint64(1), payload.SeqNum)
+	quarantined := &pcomm.Block{}
+	assert.NoError(t, pb.Unmarshal(payload.Data, quarantined))
+	assert.True(t, pb.Equal(block, quarantined))
+
+	// The halted channel doesn't accept blocks
+	assert.Error(t, s.AddPayload(&proto.Payload{SeqNum: 2}))
+	assert.Equal(t, 0, s.payloads.Size())
+
+	// The retries end once the provider is stopped
+	coord = new(coordinatorMock)
+	coord.On("StoreBlock", mock.Anything, mock.Anything).Return([]string{}, errors.New("ledger busy"))
+	s = newProvider(coord)
+	s.commitRetry.InitialBackoff = time.Hour
+	s.cancel()
+	assert.False(t, s.commitWithRetry(pcomm.NewBlock(1, []byte{}), nil))
+	coord.AssertNumberOfCalls(t, "StoreBlock", 1)
+	assert.NoError(t, s.HealthCheck(context.Background()))
+}
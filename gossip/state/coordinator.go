@@ -10,12 +10,15 @@ import (
 	"fmt"
 
 	"github.com/golang/protobuf/proto"
+	commonledger "github.com/hyperledger/fabric/common/ledger"
 	"github.com/hyperledger/fabric/core/committer"
+	"github.com/hyperledger/fabric/core/common/privdata"
 	"github.com/hyperledger/fabric/core/ledger"
 	"github.com/hyperledger/fabric/protos/common"
 	"github.com/hyperledger/fabric/protos/gossip"
 	"github.com/hyperledger/fabric/protos/ledger/rwset"
 	"github.com/pkg/errors"
+	"golang.org/x/net/context"
 )
 
 // PvtData a placeholder to represent private data
@@ -90,11 +93,21 @@ type Coordinator interface {
 	// returns missing transaction ids
 	StoreBlock(block *common.Block, data ...PvtDataCollections) ([]string, error)
 
-	// GetPvtDataAndBlockByNum returns block and related to the block private data
-	GetPvtDataAndBlockByNum(seqNum uint64, filter PvtDataFilter) (*common.Block, PvtDataCollections, error)
+	// RecommitLostBlock completes the commit of the last block of the ledger, which a failed
+	// StoreBlock added to the block store without committing its private data or state
+	RecommitLostBlock(block *common.Block) error
 
-	// GetBlockByNum returns block and related to the block private data
-	GetBlockByNum(seqNum uint64) (*common.Block, error)
+	// GetPvtDataAndBlockByNum returns block and related to the block private data,
+	// or the error of the context if it is done before the block is read
+	GetPvtDataAndBlockByNum(ctx context.Context, seqNum uint64, filter PvtDataFilter) (*common.Block, PvtDataCollections, error)
+
+	// GetBlockByNum returns block and related to the block private data,
+	// or the error of the context if it is done before the block is read
+	GetBlockByNum(ctx context.Context, seqNum uint64) (*common.Block, error)
+
+	// GetBlocksWithPvtDataIterator returns an iterator over the blocks in the range [start...end]
+	// along with their private data, which ends at the last block of the ledger if it is lower
+	GetBlocksWithPvtDataIterator(start uint64, end uint64) (BlocksWithPvtDataIterator, error)
 
 	// Get recent block sequence number
 	LedgerHeight() (uint64, error)
@@ -103,24 +116,223 @@ type Coordinator interface {
 	Close()
 }
 
+// BlocksWithPvtDataIterator iterates over the blocks of a range along with their private data
+type BlocksWithPvtDataIterator interface {
+	// Next returns the next block of the range and its private data, or a nil block once the range
+	// is exhausted. It returns the error of the context if it is done before the block is read
+	Next(ctx context.Context) (*common.Block, PvtDataCollections, error)
+
+	// Close releases the resources held by the iterator
+	Close()
+}
+
+// blocksIteratorSupport is implemented by the committers which read
+// a range of blocks sequentially from the ledger
+type blocksIteratorSupport interface {
+	// GetBlocksIterator returns an iterator over the blocks of the ledger, starting from the given block.
+	// The iterator blocks until the next block is committed once it reaches the height of the ledger
+	GetBlocksIterator(startSeqNum uint64) (commonledger.ResultsIterator, error)
+}
+
+// CoordinatorConfig defines the behavior of the coordinator
+type CoordinatorConfig struct {
+	// ChainID is the channel the coordinator commits blocks for
+	ChainID string
+	// PvtDataVerification determines how private data that doesn't
+	// match the hashes in the block is treated before commit
+	PvtDataVerification PvtDataVerificationMode
+	// Collections retrieves the collections of the chaincodes of the channel,
+	// the private data of the collections the organization of the peer isn't a
+	// member of is dropped. If nil, the private data of every collection is kept.
+	Collections privdata.CollectionStore
+	// SelfOrg is the MSP ID of the organization of the peer
+	SelfOrg string
+}
+
 type coordinator struct {
 	committer.Committer
+	verifier    *pvtDataVerifier
+	chainID     string
+	collections privdata.CollectionStore
+	selfOrg     string
 }
 
 // NewCoordinator creates a new instance of coordinator
 func NewCoordinator(committer committer.Committer) Coordinator {
-	return &coordinator{Committer: committer}
+	return NewCoordinatorWithConfig(committer, CoordinatorConfig{})
+}
+
+// NewCoordinatorWithConfig creates a new instance of coordinator with the given configuration
+func NewCoordinatorWithConfig(committer committer.Committer, config CoordinatorConfig) Coordinator {
+	return &coordinator{
+		Committer:   committer,
+		verifier:    newPvtDataVerifier(config.ChainID, config.PvtDataVerification),
+		chainID:     config.ChainID,
+		collections: config.Collections,
+		selfOrg:     config.SelfOrg,
+	}
 }
 
 func (c *coordinator) StoreBlock(block *common.Block, data ...PvtDataCollections) ([]string, error) {
+	// The collections of the block the peer is not eligible for are recorded as such,
+	// the ledger records the other collections it has no private data of as missing
+	eligibility := c.newEligibilityChecker(block.Header.Number)
+	ineligible := c.ineligibleData(block, eligibility)
 	// Need to check whenever there are missing private rwset
-	if len(data) == 0 {
-		return nil, c.Commit(block)
+	if len(data) == 0 || c.verifier.mode == PvtDataVerificationNone {
+		if len(ineligible) == 0 {
+			return nil, c.Commit(block)
+		}
+		return nil, c.CommitWithPvtData(&ledger.BlockAndPvtData{Block: block, MissingPvtData: ineligible})
+	}
+	blockAndPvtData := &ledger.BlockAndPvtData{
+		Block:          block,
+		BlockPvtData:   make(map[uint64]*ledger.TxPvtData),
+		MissingPvtData: ineligible,
+	}
+	var rejectedTxIDs []string
+	for _, pvtData := range data {
+		verified, rejected, err := c.verifier.verify(block, c.eligibleData(pvtData, eligibility))
+		if err != nil {
+			return nil, err
+		}
+		rejectedTxIDs = append(rejectedTxIDs, rejected...)
+		for _, txPvtData := range verified {
+			seqInBlock := txPvtData.Payload.SeqInBlock
+			blockAndPvtData.BlockPvtData[seqInBlock] = addTxPvtData(blockAndPvtData.BlockPvtData[seqInBlock], txPvtData.Payload)
+		}
 	}
-	return nil, c.Commit(block)
+	// The collections whose private data was rejected are left out of the private data
+	// committed along with the block, the ledger records them as missing
+	return rejectedTxIDs, c.CommitWithPvtData(blockAndPvtData)
 }
 
-func (c *coordinator) GetPvtDataAndBlockByNum(seqNum uint64, filter PvtDataFilter) (*common.Block, PvtDataCollections, error) {
+// addTxPvtData adds to the private data of a transaction the collections of the given
+// private data of the transaction it doesn't have yet
+func addTxPvtData(txPvtData *ledger.TxPvtData, added *ledger.TxPvtData) *ledger.TxPvtData {
+	if txPvtData == nil {
+		return added
+	}
+	for _, ns := range added.WriteSet.NsPvtRwset {
+		for _, coll := range ns.CollectionPvtRwset {
+			if txPvtData.Has(ns.Namespace, coll.CollectionName) {
+				continue
+			}
+			nsAdded := false
+			for _, existingNs := range txPvtData.WriteSet.NsPvtRwset {
+				if existingNs.Namespace == ns.Namespace {
+					existingNs.CollectionPvtRwset = append(existingNs.CollectionPvtRwset, coll)
+					nsAdded = true
+					break
+				}
+			}
+			if !nsAdded {
+				txPvtData.WriteSet.NsPvtRwset = append(txPvtData.WriteSet.NsPvtRwset, &rwset.NsPvtReadWriteSet{
+					Namespace:          ns.Namespace,
+					CollectionPvtRwset: []*rwset.CollectionPvtReadWriteSet{coll},
+				})
+			}
+		}
+	}
+	return txPvtData
+}
+
+// eligibilityChecker tells whether the organization of the peer is a member of the collections
+// as configured at the height of a block, the collection configs the transactions of the block were
+// validated against. The collection configs may have changed since, upon an upgrade of their chaincode
+type eligibilityChecker struct {
+	c        *coordinator
+	blockNum uint64
+	checked  map[string]map[string]bool
+}
+
+func (c *coordinator) newEligibilityChecker(blockNum uint64) *eligibilityChecker {
+	return &eligibilityChecker{c: c, blockNum: blockNum, checked: make(map[string]map[string]bool)}
+}
+
+// isEligible returns whether the organization of the peer is a member of the collection,
+// the collections whose membership cannot be determined being deemed ineligible
+func (e *eligibilityChecker) isEligible(ns, coll string) bool {
+	if isEligible, checked := e.checked[ns][coll]; checked {
+		return isEligible
+	}
+	cc := common.CollectionCriteria{Channel: e.c.chainID, Namespace: ns, Collection: coll}
+	isEligible, err := privdata.IsOrgEligibleAt(e.c.collections, cc, e.blockNum, e.c.selfOrg)
+	if err != nil {
+		logger.Warningf("Could not determine the membership of collection %s/%s at block %d: %s", ns, coll, e.blockNum, err)
+		isEligible = false
+	}
+	if e.checked[ns] == nil {
+		e.checked[ns] = make(map[string]bool)
+	}
+	e.checked[ns][coll] = isEligible
+	return isEligible
+}
+
+// ineligibleData returns the collections of the transactions of the block the organization
+// of the peer is not a member of
+func (c *coordinator) ineligibleData(block *common.Block, eligibility *eligibilityChecker) ledger.TxMissingPvtDataMap {
+	ineligible := make(ledger.TxMissingPvtDataMap)
+	if c.collections == nil || block.Data == nil {
+		return ineligible
+	}
+	for seqInBlock := range block.Data.Data {
+		_, hashes, err := hashesOfTx(block, uint64(seqInBlock))
+		if err != nil {
+			// not an endorser transaction, it has no private data
+			continue
+		}
+		for ns, colls := range hashes {
+			for coll := range colls {
+				if !eligibility.isEligible(ns, coll) {
+					ineligible.Add(uint64(seqInBlock), ns, coll, false)
+				}
+			}
+		}
+	}
+	return ineligible
+}
+
+// eligibleData returns the private data of the collections the organization of the peer
+// is a member of, the collections whose membership cannot be determined being dropped
+func (c *coordinator) eligibleData(data PvtDataCollections, eligibility *eligibilityChecker) PvtDataCollections {
+	if c.collections == nil {
+		return data
+	}
+	var eligible PvtDataCollections
+	for _, pvtData := range data {
+		if pvtData == nil || pvtData.Payload == nil || pvtData.Payload.WriteSet == nil {
+			continue
+		}
+		writeSet := &rwset.TxPvtReadWriteSet{DataModel: pvtData.Payload.WriteSet.DataModel}
+		for _, ns := range pvtData.Payload.WriteSet.NsPvtRwset {
+			eligibleNs := &rwset.NsPvtReadWriteSet{Namespace: ns.Namespace}
+			for _, coll := range ns.CollectionPvtRwset {
+				if !eligibility.isEligible(ns.Namespace, coll.CollectionName) {
+					logger.Debugf("Dropping private data of collection %s/%s, %s isn't a member", ns.Namespace, coll.CollectionName, c.selfOrg)
+					continue
+				}
+				eligibleNs.CollectionPvtRwset = append(eligibleNs.CollectionPvtRwset, coll)
+			}
+			if len(eligibleNs.CollectionPvtRwset) != 0 {
+				writeSet.NsPvtRwset = append(writeSet.NsPvtRwset, eligibleNs)
+			}
+		}
+		if len(writeSet.NsPvtRwset) == 0 {
+			continue
+		}
+		eligible = append(eligible, &PvtData{Payload: &ledger.TxPvtData{
+			SeqInBlock: pvtData.Payload.SeqInBlock,
+			WriteSet:   writeSet,
+		}})
+	}
+	return eligible
+}
+
+func (c *coordinator) GetPvtDataAndBlockByNum(ctx context.Context, seqNum uint64, filter PvtDataFilter) (*common.Block, PvtDataCollections, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
 	blocks := c.GetBlocks([]uint64{seqNum})
 	if len(blocks) == 0 {
 		return nil, nil, fmt.Errorf("Cannot retreive block number %d", seqNum)
@@ -128,10 +340,106 @@ func (c *coordinator) GetPvtDataAndBlockByNum(seqNum uint64, filter PvtDataFilte
 	return blocks[0], nil, nil
 }
 
-func (c *coordinator) GetBlockByNum(seqNum uint64) (*common.Block, error) {
+func (c *coordinator) GetBlockByNum(ctx context.Context, seqNum uint64) (*common.Block, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	blocks := c.GetBlocks([]uint64{seqNum})
 	if len(blocks) == 0 {
 		return nil, fmt.Errorf("Cannot retreive block number %d", seqNum)
 	}
 	return blocks[0], nil
 }
+
+func (c *coordinator) GetBlocksWithPvtDataIterator(start uint64, end uint64) (BlocksWithPvtDataIterator, error) {
+	if start > end {
+		return nil, errors.Errorf("invalid range of blocks [%d...%d]", start, end)
+	}
+	height, err := c.LedgerHeight()
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed reading the ledger height")
+	}
+	if start >= height {
+		return nil, errors.Errorf("block %d is beyond the ledger height %d", start, height)
+	}
+	// The iterator of the ledger waits for the blocks beyond its height
+	if end >= height {
+		end = height - 1
+	}
+
+	itrSupport, isItrSupport := c.Committer.(blocksIteratorSupport)
+	if !isItrSupport {
+		return newLookupBlocksIterator(start, end, func(ctx context.Context, seqNum uint64) (*common.Block, PvtDataCollections, error) {
+			return c.GetPvtDataAndBlockByNum(ctx, seqNum, nil)
+		}), nil
+	}
+	itr, err := itrSupport.GetBlocksIterator(start)
+	if err != nil {
+		return nil, errors.WithMessage(err, fmt.Sprintf("failed iterating over the blocks from %d", start))
+	}
+	return &ledgerBlocksIterator{itr: itr, next: start, end: end}, nil
+}
+
+// ledgerBlocksIterator iterates over the blocks of a range with the blocks iterator of the ledger,
+// which reads them sequentially
+type ledgerBlocksIterator struct {
+	itr  commonledger.ResultsIterator
+	next uint64
+	end  uint64
+}
+
+func (i *ledgerBlocksIterator) Next(ctx context.Context) (*common.Block, PvtDataCollections, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+	if i.next > i.end {
+		return nil, nil, nil
+	}
+	res, err := i.itr.Next()
+	if err != nil {
+		return nil, nil, errors.WithMessage(err, fmt.Sprintf("failed reading block %d", i.next))
+	}
+	block, isBlock := res.(*common.Block)
+	if !isBlock || block == nil || block.Header == nil || block.Header.Number != i.next {
+		return nil, nil, errors.Errorf("failed reading block %d, got %v instead", i.next, res)
+	}
+	i.next++
+	return block, nil, nil
+}
+
+func (i *ledgerBlocksIterator) Close() {
+	i.itr.Close()
+}
+
+// newLookupBlocksIterator returns an iterator over the blocks in the range [start...end],
+// which looks up each of them with the given function
+func newLookupBlocksIterator(start uint64, end uint64, lookup func(ctx context.Context, seqNum uint64) (*common.Block, PvtDataCollections, error)) BlocksWithPvtDataIterator {
+	return &lookupBlocksIterator{lookup: lookup, next: start, end: end}
+}
+
+type lookupBlocksIterator struct {
+	lookup func(ctx context.Context, seqNum uint64) (*common.Block, PvtDataCollections, error)
+	next   uint64
+	end    uint64
+}
+
+func (i *lookupBlocksIterator) Next(ctx context.Context) (*common.Block, PvtDataCollections, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+	if i.next > i.end {
+		return nil, nil, nil
+	}
+	block, pvtData, err := i.lookup(ctx, i.next)
+	if err == nil && (block == nil || block.Header == nil) {
+		err = errors.Errorf("block %d not found", i.next)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	i.next++
+	return block, pvtData, nil
+}
+
+func (i *lookupBlocksIterator) Close() {
+}
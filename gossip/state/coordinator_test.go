@@ -10,11 +10,13 @@ import (
 	"fmt"
 	"testing"
 
+	commonledger "github.com/hyperledger/fabric/common/ledger"
 	"github.com/hyperledger/fabric/core/ledger"
 	"github.com/hyperledger/fabric/protos/common"
 	"github.com/hyperledger/fabric/protos/ledger/rwset"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"golang.org/x/net/context"
 )
 
 type committerMock struct {
@@ -26,6 +28,16 @@ func (mock *committerMock) Commit(block *common.Block) error {
 	return args.Error(0)
 }
 
+func (mock *committerMock) CommitWithPvtData(blockAndPvtData *ledger.BlockAndPvtData) error {
+	args := mock.Called(blockAndPvtData)
+	return args.Error(0)
+}
+
+func (mock *committerMock) RecommitLostBlock(block *common.Block) error {
+	args := mock.Called(block)
+	return args.Error(0)
+}
+
 func (mock *committerMock) LedgerHeight() (uint64, error) {
 	args := mock.Called()
 	return args.Get(0).(uint64), args.Error(1)
@@ -225,12 +237,12 @@ func TestNewCoordinator(t *testing.T) {
 
 	coord := NewCoordinator(committer)
 
-	b, err := coord.GetBlockByNum(1)
+	b, err := coord.GetBlockByNum(context.Background(), 1)
 
 	assertion.NoError(err)
 	assertion.Equal(block, b)
 
-	b, err = coord.GetBlockByNum(2)
+	b, err = coord.GetBlockByNum(context.Background(), 2)
 
 	assertion.Error(err)
 	assertion.Nil(b)
@@ -244,3 +256,90 @@ func TestNewCoordinator(t *testing.T) {
 	assertion.NoError(err)
 	assertion.Empty(missingPvtTx)
 }
+
+type iterableCommitterMock struct {
+	*committerMock
+	blocks []*common.Block
+}
+
+func (mock *iterableCommitterMock) GetBlocksIterator(startSeqNum uint64) (commonledger.ResultsIterator, error) {
+	mock.Called(startSeqNum)
+	return &blocksIteratorMock{blocks: mock.blocks[startSeqNum:]}, nil
+}
+
+type blocksIteratorMock struct {
+	blocks []*common.Block
+	closed bool
+}
+
+func (itr *blocksIteratorMock) Next() (commonledger.QueryResult, error) {
+	if len(itr.blocks) == 0 {
+		panic("The ledger blocks iterator waits for blocks beyond the ledger height")
+	}
+	block := itr.blocks[0]
+	itr.blocks = itr.blocks[1:]
+	return block, nil
+}
+
+func (itr *blocksIteratorMock) Close() {
+	itr.closed = true
+}
+
+func TestGetBlocksWithPvtDataIterator(t *testing.T) {
+	var blocks []*common.Block
+	for seqNum := uint64(0); seqNum < 5; seqNum++ {
+		blocks = append(blocks, common.NewBlock(seqNum, []byte{}))
+	}
+	readAll := func(itr BlocksWithPvtDataIterator) ([]*common.Block, error) {
+		defer itr.Close()
+		var read []*common.Block
+		for {
+			block, _, err := itr.Next(context.Background())
+			if block == nil || err != nil {
+				return read, err
+			}
+			read = append(read, block)
+		}
+	}
+
+	// The blocks are read sequentially with the iterator of the ledger, up to its height
+	committer := &iterableCommitterMock{committerMock: new(committerMock), blocks: blocks}
+	committer.On("LedgerHeight").Return(uint64(5), nil)
+	committer.On("GetBlocksIterator", uint64(2)).Return()
+	coord := NewCoordinator(committer)
+	itr, err := coord.GetBlocksWithPvtDataIterator(2, 10)
+	assert.NoError(t, err)
+	read, err := readAll(itr)
+	assert.NoError(t, err)
+	assert.Equal(t, blocks[2:], read)
+	committer.AssertNotCalled(t, "GetBlocks", mock.Anything)
+
+	_, err = coord.GetBlocksWithPvtDataIterator(5, 10)
+	assert.Error(t, err)
+	_, err = coord.GetBlocksWithPvtDataIterator(3, 2)
+	assert.Error(t, err)
+
+	// The reads stop once the context is done
+	itr, err = coord.GetBlocksWithPvtDataIterator(2, 4)
+	assert.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	block, _, err := itr.Next(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, blocks[2], block)
+	cancel()
+	_, _, err = itr.Next(ctx)
+	assert.Equal(t, context.Canceled, err)
+	itr.Close()
+	assert.True(t, itr.(*ledgerBlocksIterator).itr.(*blocksIteratorMock).closed)
+
+	// Committers without blocks iterators are looked up one block at a time
+	lookupCommitter := new(committerMock)
+	lookupCommitter.On("LedgerHeight").Return(uint64(5), nil)
+	lookupCommitter.On("GetBlocks", []uint64{3}).Return([]*common.Block{blocks[3]})
+	lookupCommitter.On("GetBlocks", []uint64{4}).Return(nil)
+	itr, err = NewCoordinator(lookupCommitter).GetBlocksWithPvtDataIterator(3, 4)
+	assert.NoError(t, err)
+	read, err = readAll(itr)
+	assert.Error(t, err)
+	assert.Equal(t, blocks[3:4], read)
+}
@@ -11,28 +11,55 @@ import (
 	"encoding/binary"
 )
 
+// Capability is a state transfer feature a peer supports for a channel,
+// advertised along with its ledger height
+type Capability uint32
+
+const (
+	// StateDiffCapability indicates that the peer serves the state
+	// differences of the channel since a checkpoint, i.e. state snapshots
+	StateDiffCapability Capability = 1 << iota
+	// FragmentedResponsesCapability indicates that the peer handles state
+	// responses split into several messages. It is reserved for the peers
+	// which fragment their responses, this implementation doesn't advertise it
+	FragmentedResponsesCapability
+)
+
 // NodeMetastate information to store the information about current
-// height of the ledger (last accepted block sequence number).
+// height of the ledger (last accepted block sequence number), and
+// the state transfer capabilities of the peer for the channel.
 type NodeMetastate struct {
 
 	// Actual ledger height
 	LedgerHeight uint64
+
+	// Capabilities the peer supports for the channel
+	Capabilities Capability
+
+	// PvtDataAvailable indicates that the peer stores the private data
+	// of the channel, and serves it along with the blocks it transfers
+	PvtDataAvailable bool
+
+	// legacy is set for the meta states of peers that only advertise their ledger height
+	legacy bool
 }
 
-// NewNodeMetastate creates new meta data with given ledger height148.69
+// NewNodeMetastate creates new meta data with given ledger height
 func NewNodeMetastate(height uint64) *NodeMetastate {
-	return &NodeMetastate{height}
+	return &NodeMetastate{LedgerHeight: height}
 }
 
-// Bytes decodes meta state into byte array for serialization
+// Bytes decodes meta state into byte array for serialization. The capabilities
+// follow the ledger height, which peers of older versions read on its own
 func (n *NodeMetastate) Bytes() ([]byte, error) {
 	buffer := new(bytes.Buffer)
 	// Explicitly specify byte order for write into the buffer
 	// to provide cross platform support, note the it consistent
 	// with FromBytes function
-	err := binary.Write(buffer, binary.BigEndian, *n)
-	if err != nil {
-		return nil, err
+	for _, field := range []interface{}{n.LedgerHeight, uint32(n.Capabilities), n.PvtDataAvailable} {
+		if err := binary.Write(buffer, binary.BigEndian, field); err != nil {
+			return nil, err
+		}
 	}
 	return buffer.Bytes(), nil
 }
@@ -47,6 +74,22 @@ func (n *NodeMetastate) Update(height uint64) {
 	n.LedgerHeight = height
 }
 
+// HasCapability returns true if the peer advertised the given capability
+func (n *NodeMetastate) HasCapability(capability Capability) bool {
+	return n.Capabilities&capability != 0
+}
+
+// mayServe returns false if the peer advertised capabilities lacking the given one. The peers
+// which only advertise their ledger height are assumed to have it, as they don't tell
+func (n *NodeMetastate) mayServe(capability Capability) bool {
+	return n.legacy || n.HasCapability(capability)
+}
+
+// mayServePvtData returns false if the peer advertised that it doesn't store private data
+func (n *NodeMetastate) mayServePvtData() bool {
+	return n.legacy || n.PvtDataAvailable
+}
+
 // FromBytes - encode from byte array into meta data structure
 func FromBytes(buf []byte) (*NodeMetastate, error) {
 	state := NodeMetastate{}
@@ -54,9 +97,21 @@ func FromBytes(buf []byte) (*NodeMetastate, error) {
 	// As bytes are written in the big endian to keep supporting
 	// cross platforming and for consistency reasons read also
 	// done using same order
-	err := binary.Read(reader, binary.BigEndian, &state)
-	if err != nil {
+	if err := binary.Read(reader, binary.BigEndian, &state.LedgerHeight); err != nil {
+		return nil, err
+	}
+	if reader.Len() == 0 {
+		state.legacy = true
+		return &state, nil
+	}
+	var capabilities uint32
+	if err := binary.Read(reader, binary.BigEndian, &capabilities); err != nil {
+		return nil, err
+	}
+	state.Capabilities = Capability(capabilities)
+	if err := binary.Read(reader, binary.BigEndian, &state.PvtDataAvailable); err != nil {
 		return nil, err
 	}
+	// Trailing bytes are left to the fields of future versions
 	return &state, nil
 }
@@ -7,6 +7,8 @@ SPDX-License-Identifier: Apache-2.0
 package state
 
 import (
+	"bytes"
+	"encoding/binary"
 	"testing"
 
 	"github.com/hyperledger/fabric/gossip/util"
@@ -68,3 +70,50 @@ func TestNodeMetastate_FromBytes(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, updatedState.Height(), uint64(17))
 }
+
+func TestNodeMetastateCapabilities(t *testing.T) {
+	metastate := NewNodeMetastate(42)
+	metastate.Capabilities = StateDiffCapability
+	metastate.PvtDataAvailable = true
+	metaBytes, err := metastate.Bytes()
+	assert.NoError(t, err)
+
+	state, err := FromBytes(metaBytes)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(42), state.Height())
+	assert.True(t, state.HasCapability(StateDiffCapability))
+	assert.False(t, state.HasCapability(FragmentedResponsesCapability))
+	assert.True(t, state.mayServe(StateDiffCapability))
+	assert.False(t, state.mayServe(FragmentedResponsesCapability))
+	assert.True(t, state.mayServePvtData())
+
+	// Peers of older versions read the ledger height only
+	var height uint64
+	assert.NoError(t, binary.Read(bytes.NewReader(metaBytes), binary.BigEndian, &height))
+	assert.Equal(t, uint64(42), height)
+
+	// Trailing fields of future versions are ignored
+	state, err = FromBytes(append(metaBytes, 1, 2, 3))
+	assert.NoError(t, err)
+	assert.Equal(t, metastate, state)
+
+	// Truncated capabilities
+	_, err = FromBytes(metaBytes[:10])
+	assert.Error(t, err)
+}
+
+func TestNodeMetastateLegacy(t *testing.T) {
+	// Peers of older versions only advertise their ledger height
+	legacyBytes := make([]byte, 8)
+	binary.BigEndian.PutUint64(legacyBytes, 42)
+	state, err := FromBytes(legacyBytes)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(42), state.Height())
+	assert.False(t, state.HasCapability(StateDiffCapability))
+	assert.False(t, state.PvtDataAvailable)
+	assert.True(t, state.mayServe(StateDiffCapability), "Expected peers not advertising capabilities to be assumed to have them")
+	assert.True(t, state.mayServePvtData())
+
+	_, err = FromBytes(legacyBytes[:4])
+	assert.Error(t, err)
+}
@@ -11,6 +11,8 @@ import (
 	"github.com/hyperledger/fabric/gossip/comm"
 	"github.com/hyperledger/fabric/gossip/common"
 	"github.com/hyperledger/fabric/gossip/discovery"
+	"github.com/hyperledger/fabric/gossip/gossip"
+	"github.com/hyperledger/fabric/gossip/gossip/channel"
 	proto "github.com/hyperledger/fabric/protos/gossip"
 	"github.com/stretchr/testify/mock"
 )
@@ -28,6 +30,14 @@ func (g *GossipMock) Send(msg *proto.GossipMessage, peers ...*comm.RemotePeer) {
 	g.Called(msg, peers)
 }
 
+func (g *GossipMock) SendByCriteria(msg *proto.GossipMessage, criteria gossip.SendCriteria) error {
+	args := g.Called(msg, criteria)
+	if args.Get(0) == nil {
+		return nil
+	}
+	return args.Get(0).(error)
+}
+
 func (g *GossipMock) Peers() []discovery.NetworkMember {
 	return g.Called().Get(0).([]discovery.NetworkMember)
 }
@@ -59,6 +69,9 @@ func (g *GossipMock) Accept(acceptor common.MessageAcceptor, passThrough bool) (
 func (g *GossipMock) JoinChan(joinMsg api.JoinChannelMessage, chainID common.ChainID) {
 }
 
+func (g *GossipMock) UpdateChannelConfig(conf channel.RuntimeConfig, chainID common.ChainID) {
+}
+
 func (g *GossipMock) Stop() {
 
 }
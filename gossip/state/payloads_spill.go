@@ -0,0 +1,156 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package state
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+
+	pb "github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/ledger/util/leveldbhelper"
+	proto "github.com/hyperledger/fabric/protos/gossip"
+	"github.com/pkg/errors"
+)
+
+// PayloadsSpillConfig configures the disk-backed overflow of the payloads buffer,
+// which bounds the memory the buffer takes while a peer catches up with thousands of blocks
+type PayloadsSpillConfig struct {
+	// Dir is the directory the temporary store of the spilled payloads is created in,
+	// the default directory for temporary files if empty
+	Dir string
+	// MemoryWindow is the number of payloads past the next expected sequence
+	// number kept in memory, the payloads beyond it are spilled to disk
+	MemoryWindow uint64
+}
+
+// spillingPayloadsBuffer is a payloads buffer that keeps in memory the payloads near the commit
+// frontier, and spills the others to a temporary LevelDB store until the frontier gets near them
+type spillingPayloadsBuffer struct {
+	*PayloadsBufferImpl
+
+	window uint64
+	dir    string
+	db     *leveldbhelper.DB
+
+	// Guards the spilled payloads, and their promotion back to memory
+	mutex   sync.Mutex
+	spilled map[uint64]struct{}
+}
+
+// NewSpillingPayloadsBuffer creates a payloads buffer which keeps in memory the payloads within the
+// memory window of the configuration past the next expected sequence number, and spills the
+// payloads beyond it to disk. The spilled payloads are promoted back to memory as the next expected
+// sequence number advances, and the store of the spilled payloads is removed once the buffer is closed
+func NewSpillingPayloadsBuffer(next uint64, conf PayloadsSpillConfig) (PayloadsBuffer, error) {
+	if conf.MemoryWindow == 0 {
+		return nil, errors.New("the memory window of the payloads buffer must be positive")
+	}
+	dir, err := ioutil.TempDir(conf.Dir, "payloads")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed creating the store of the spilled payloads")
+	}
+	db := leveldbhelper.CreateDB(&leveldbhelper.Conf{DBPath: dir})
+	db.Open()
+	return &spillingPayloadsBuffer{
+		PayloadsBufferImpl: NewPayloadsBuffer(next).(*PayloadsBufferImpl),
+		window:             conf.MemoryWindow,
+		dir:                dir,
+		db:                 db,
+		spilled:            make(map[uint64]struct{}),
+	}, nil
+}
+
+// Push adds the payload to the buffer, in memory if it is within the
+// memory window past the next expected sequence number, or on disk
+func (b *spillingPayloadsBuffer) Push(payload *proto.Payload) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	seqNum := payload.SeqNum
+	if seqNum < b.Next()+b.window {
+		return b.PayloadsBufferImpl.Push(payload)
+	}
+	if _, exists := b.spilled[seqNum]; exists {
+		return fmt.Errorf("Payload with sequence number = %d has been already processed", seqNum)
+	}
+	bytes, err := pb.Marshal(payload)
+	if err != nil {
+		return errors.Wrapf(err, "failed marshaling payload %d", seqNum)
+	}
+	if err := b.db.Put(spillKey(seqNum), bytes, false); err != nil {
+		return errors.Wrapf(err, "failed spilling payload %d to disk", seqNum)
+	}
+	b.spilled[seqNum] = struct{}{}
+	return nil
+}
+
+// Pop removes and returns the payload of the next expected sequence number, if it
+// arrived, and promotes the spilled payloads which the memory window reaches to memory
+func (b *spillingPayloadsBuffer) Pop() *proto.Payload {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	payload := b.PayloadsBufferImpl.Pop()
+	if payload != nil && len(b.spilled) > 0 {
+		b.promote()
+	}
+	return payload
+}
+
+// promote moves the spilled payloads within the memory window back to memory
+func (b *spillingPayloadsBuffer) promote() {
+	next := b.Next()
+	itr := b.db.GetIterator(spillKey(next), spillKey(next+b.window))
+	defer itr.Release()
+	for itr.Next() {
+		seqNum := binary.BigEndian.Uint64(itr.Key())
+		payload := &proto.Payload{}
+		if err := pb.Unmarshal(itr.Value(), payload); err != nil {
+			b.logger.Errorf("Dropping spilled payload %d, failed unmarshaling it: %s", seqNum, err)
+		} else if err := b.PayloadsBufferImpl.Push(payload); err != nil {
+			b.logger.Warningf("Dropping spilled payload %d: %s", seqNum, err)
+		}
+		if err := b.db.Delete(spillKey(seqNum), false); err != nil {
+			b.logger.Errorf("Failed removing spilled payload %d from disk: %s", seqNum, err)
+		}
+		delete(b.spilled, seqNum)
+	}
+}
+
+// Size returns the number of payloads stored within the buffer, in memory and on disk
+func (b *spillingPayloadsBuffer) Size() int {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.PayloadsBufferImpl.Size() + len(b.spilled)
+}
+
+// Close cleanups the resources of the buffer, and removes the store of the spilled payloads
+func (b *spillingPayloadsBuffer) Close() {
+	b.PayloadsBufferImpl.Close()
+	b.release()
+}
+
+// release removes the store of the spilled payloads, the payloads pushed
+// beyond the memory window afterwards are refused
+func (b *spillingPayloadsBuffer) release() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.db.Close()
+	if err := os.RemoveAll(b.dir); err != nil {
+		b.logger.Errorf("Failed removing the store of the spilled payloads %s: %s", b.dir, err)
+	}
+	b.spilled = make(map[uint64]struct{})
+}
+
+func spillKey(seqNum uint64) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, seqNum)
+	return key
+}
@@ -0,0 +1,67 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package state
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSpillingPayloadsBuffer(t *testing.T) {
+	dir, err := ioutil.TempDir("", "spill")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	_, err = NewSpillingPayloadsBuffer(1, PayloadsSpillConfig{Dir: dir})
+	assert.Error(t, err)
+
+	buffer, err := NewSpillingPayloadsBuffer(1, PayloadsSpillConfig{Dir: dir, MemoryWindow: 3})
+	assert.NoError(t, err)
+	spilling := buffer.(*spillingPayloadsBuffer)
+
+	// The payloads beyond the memory window are spilled to disk, in any order
+	pushed := make(map[uint64][]byte)
+	for _, seqNum := range []uint64{10, 2, 7, 3, 1, 5, 4, 9, 8, 6} {
+		payload, err := randomPayloadWithSeqNum(seqNum)
+		assert.NoError(t, err)
+		assert.NoError(t, buffer.Push(payload))
+		pushed[seqNum] = payload.Data
+	}
+	assert.Equal(t, 10, buffer.Size())
+	assert.Equal(t, 3, spilling.PayloadsBufferImpl.Size())
+	assert.Len(t, spilling.spilled, 7)
+
+	// Duplicates are refused, in memory and on disk
+	dup, _ := randomPayloadWithSeqNum(2)
+	assert.Error(t, buffer.Push(dup))
+	dup, _ = randomPayloadWithSeqNum(8)
+	assert.Error(t, buffer.Push(dup))
+
+	// The spilled payloads are promoted back to memory as the payloads are popped
+	for seqNum := uint64(1); seqNum <= 10; seqNum++ {
+		payload := buffer.Pop()
+		if !assert.NotNil(t, payload, "Payload %d is missing", seqNum) {
+			return
+		}
+		assert.Equal(t, seqNum, payload.SeqNum)
+		assert.Equal(t, pushed[seqNum], payload.Data)
+		assert.True(t, spilling.PayloadsBufferImpl.Size() <= 3)
+	}
+	assert.Nil(t, buffer.Pop())
+	assert.Equal(t, 0, buffer.Size())
+	assert.Equal(t, uint64(11), buffer.Next())
+
+	// The store of the spilled payloads is removed once the buffer is released
+	_, err = os.Stat(spilling.dir)
+	assert.NoError(t, err)
+	spilling.release()
+	_, err = os.Stat(spilling.dir)
+	assert.True(t, os.IsNotExist(err))
+}
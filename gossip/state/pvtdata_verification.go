@@ -0,0 +1,170 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package state
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/metrics"
+	"github.com/hyperledger/fabric/common/util"
+	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/ledger/rwset"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/pkg/errors"
+)
+
+// PvtDataVerificationMode defines how the coordinator treats received
+// private write sets which don't match the hashes recorded in the block
+type PvtDataVerificationMode int
+
+const (
+	// PvtDataVerificationNone skips the verification of private data, the
+	// private data received along with a block is then not committed
+	PvtDataVerificationNone PvtDataVerificationMode = iota
+	// PvtDataVerificationLenient drops mismatching collections and
+	// commits the block with the rest of the private data
+	PvtDataVerificationLenient
+	// PvtDataVerificationStrict refuses to commit a block which arrived
+	// with mismatching private data, the block is retried and eventually
+	// quarantined as the commit retry policy of the state provider defines
+	PvtDataVerificationStrict
+)
+
+// PvtDataVerificationModeFromString parses the given verification mode name,
+// an empty name is treated as PvtDataVerificationNone
+func PvtDataVerificationModeFromString(mode string) (PvtDataVerificationMode, error) {
+	switch strings.ToLower(mode) {
+	case "", "none":
+		return PvtDataVerificationNone, nil
+	case "lenient":
+		return PvtDataVerificationLenient, nil
+	case "strict":
+		return PvtDataVerificationStrict, nil
+	}
+	return PvtDataVerificationNone, errors.Errorf("unknown private data verification mode %s", mode)
+}
+
+// collHashes maps namespace to collection name to the hash of the collection's private write set
+type collHashes map[string]map[string][]byte
+
+type pvtDataVerifier struct {
+	mode    PvtDataVerificationMode
+	metrics metrics.Scope
+}
+
+func newPvtDataVerifier(chainID string, mode PvtDataVerificationMode) *pvtDataVerifier {
+	return &pvtDataVerifier{
+		mode:    mode,
+		metrics: metrics.NewRootScope().SubScope("gossip_state").Tagged(map[string]string{"channel": chainID}),
+	}
+}
+
+// verify checks the private write sets against the hashes recorded in the block.
+// It returns the private data that passed the verification, and the ids of the
+// transactions which had (some of) their private data dropped due to a mismatch.
+// In strict mode, a mismatch fails the verification altogether.
+func (v *pvtDataVerifier) verify(block *common.Block, data PvtDataCollections) (PvtDataCollections, []string, error) {
+	var verified PvtDataCollections
+	var rejectedTxIDs []string
+	for _, pvtData := range data {
+		if pvtData == nil || pvtData.Payload == nil || pvtData.Payload.WriteSet == nil {
+			continue
+		}
+		seqInBlock := pvtData.Payload.SeqInBlock
+		txID, hashes, err := hashesOfTx(block, seqInBlock)
+		if err != nil {
+			v.metrics.Counter("pvtdata_hash_mismatch").Inc(1)
+			if v.mode == PvtDataVerificationStrict {
+				return nil, nil, errors.WithMessage(err, "failed verifying private data")
+			}
+			logger.Warningf("Dropping private data of transaction %d in block %d: %s", seqInBlock, block.Header.Number, err)
+			continue
+		}
+		writeSet, mismatches := v.filterWriteSet(pvtData.Payload.WriteSet, hashes)
+		if len(mismatches) != 0 {
+			if v.mode == PvtDataVerificationStrict {
+				return nil, nil, errors.Errorf("private data of transaction %s in block %d doesn't match the hashes "+
+					"in the block for collections %v", txID, block.Header.Number, mismatches)
+			}
+			logger.Warningf("Dropping private data of transaction %s in block %d for collections %v "+
+				"since it doesn't match the hashes in the block", txID, block.Header.Number, mismatches)
+			rejectedTxIDs = append(rejectedTxIDs, txID)
+		}
+		if len(writeSet.NsPvtRwset) == 0 {
+			continue
+		}
+		verified = append(verified, &PvtData{Payload: &ledger.TxPvtData{
+			SeqInBlock: seqInBlock,
+			WriteSet:   writeSet,
+		}})
+	}
+	return verified, rejectedTxIDs, nil
+}
+
+// filterWriteSet returns a copy of the given write set that contains only the
+// collections that match the given hashes, and the names (in the form namespace/collection)
+// of the collections that don't match
+func (v *pvtDataVerifier) filterWriteSet(writeSet *rwset.TxPvtReadWriteSet, hashes collHashes) (*rwset.TxPvtReadWriteSet, []string) {
+	var mismatches []string
+	filtered := &rwset.TxPvtReadWriteSet{DataModel: writeSet.DataModel}
+	for _, ns := range writeSet.NsPvtRwset {
+		filteredNs := &rwset.NsPvtReadWriteSet{Namespace: ns.Namespace}
+		for _, coll := range ns.CollectionPvtRwset {
+			expected := hashes[ns.Namespace][coll.CollectionName]
+			if expected == nil || !bytes.Equal(util.ComputeSHA256(coll.Rwset), expected) {
+				mismatches = append(mismatches, ns.Namespace+"/"+coll.CollectionName)
+				v.metrics.Tagged(map[string]string{
+					"namespace":  ns.Namespace,
+					"collection": coll.CollectionName,
+				}).Counter("pvtdata_hash_mismatch").Inc(1)
+				continue
+			}
+			filteredNs.CollectionPvtRwset = append(filteredNs.CollectionPvtRwset, coll)
+		}
+		if len(filteredNs.CollectionPvtRwset) != 0 {
+			filtered.NsPvtRwset = append(filtered.NsPvtRwset, filteredNs)
+		}
+	}
+	return filtered, mismatches
+}
+
+// hashesOfTx extracts the transaction id and the private write set hashes
+// of the transaction at the given position in the block
+func hashesOfTx(block *common.Block, seqInBlock uint64) (string, collHashes, error) {
+	if block.Data == nil || seqInBlock >= uint64(len(block.Data.Data)) {
+		return "", nil, errors.Errorf("block %d has no transaction at position %d", block.Header.Number, seqInBlock)
+	}
+	env, err := utils.GetEnvelopeFromBlock(block.Data.Data[seqInBlock])
+	if err != nil {
+		return "", nil, errors.WithMessage(err, "invalid envelope")
+	}
+	chdr, err := utils.ChannelHeader(env)
+	if err != nil {
+		return "", nil, errors.WithMessage(err, "invalid channel header")
+	}
+	action, err := utils.GetActionFromEnvelope(block.Data.Data[seqInBlock])
+	if err != nil {
+		return "", nil, errors.WithMessage(err, "invalid chaincode action")
+	}
+	txRWSet := &rwset.TxReadWriteSet{}
+	if err := proto.Unmarshal(action.Results, txRWSet); err != nil {
+		return "", nil, errors.Wrap(err, "invalid read-write set")
+	}
+	hashes := make(collHashes)
+	for _, ns := range txRWSet.NsRwset {
+		for _, coll := range ns.CollectionHashedRwset {
+			if _, exists := hashes[ns.Namespace]; !exists {
+				hashes[ns.Namespace] = make(map[string][]byte)
+			}
+			hashes[ns.Namespace][coll.CollectionName] = coll.PvtRwsetHash
+		}
+	}
+	return chdr.TxId, hashes, nil
+}
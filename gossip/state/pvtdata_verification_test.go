@@ -0,0 +1,356 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package state
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/util"
+	"github.com/hyperledger/fabric/core/common/privdata"
+	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/ledger/rwset"
+	"github.com/hyperledger/fabric/protos/peer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// createBlockWithPvtHashes creates a block with a single endorser transaction, that
+// records the hashes of the given collection write sets (keyed by namespace/collection name)
+func createBlockWithPvtHashes(t *testing.T, txID string, hashedColls map[string]map[string][]byte) *common.Block {
+	txRWSet := &rwset.TxReadWriteSet{}
+	for ns, colls := range hashedColls {
+		nsRWSet := &rwset.NsReadWriteSet{Namespace: ns}
+		for coll, pvtRWSet := range colls {
+			nsRWSet.CollectionHashedRwset = append(nsRWSet.CollectionHashedRwset, &rwset.CollectionHashedReadWriteSet{
+				CollectionName: coll,
+				PvtRwsetHash:   util.ComputeSHA256(pvtRWSet),
+			})
+		}
+		txRWSet.NsRwset = append(txRWSet.NsRwset, nsRWSet)
+	}
+	marshal := func(msg proto.Message) []byte {
+		b, err := proto.Marshal(msg)
+		assert.NoError(t, err)
+		return b
+	}
+	env := &common.Envelope{
+		Payload: marshal(&common.Payload{
+			Header: &common.Header{
+				ChannelHeader: marshal(&common.ChannelHeader{TxId: txID}),
+			},
+			Data: marshal(&peer.Transaction{
+				Actions: []*peer.TransactionAction{{
+					Payload: marshal(&peer.ChaincodeActionPayload{
+						Action: &peer.ChaincodeEndorsedAction{
+							ProposalResponsePayload: marshal(&peer.ProposalResponsePayload{
+								Extension: marshal(&peer.ChaincodeAction{Results: marshal(txRWSet)}),
+							}),
+						},
+					}),
+				}},
+			}),
+		}),
+	}
+	return &common.Block{
+		Header: &common.BlockHeader{Number: 1},
+		Data:   &common.BlockData{Data: [][]byte{marshal(env)}},
+	}
+}
+
+func pvtDataOf(seqInBlock uint64, ns string, colls map[string][]byte) *PvtData {
+	nsPvtRWSet := &rwset.NsPvtReadWriteSet{Namespace: ns}
+	for coll, pvtRWSet := range colls {
+		nsPvtRWSet.CollectionPvtRwset = append(nsPvtRWSet.CollectionPvtRwset, &rwset.CollectionPvtReadWriteSet{
+			CollectionName: coll,
+			Rwset:          pvtRWSet,
+		})
+	}
+	return &PvtData{Payload: &ledger.TxPvtData{
+		SeqInBlock: seqInBlock,
+		WriteSet:   &rwset.TxPvtReadWriteSet{NsPvtRwset: []*rwset.NsPvtReadWriteSet{nsPvtRWSet}},
+	}}
+}
+
+func TestPvtDataVerificationModeFromString(t *testing.T) {
+	for name, expected := range map[string]PvtDataVerificationMode{
+		"":        PvtDataVerificationNone,
+		"none":    PvtDataVerificationNone,
+		"Lenient": PvtDataVerificationLenient,
+		"strict":  PvtDataVerificationStrict,
+	} {
+		mode, err := PvtDataVerificationModeFromString(name)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, mode)
+	}
+	_, err := PvtDataVerificationModeFromString("paranoid")
+	assert.Error(t, err)
+}
+
+func TestPvtDataVerification(t *testing.T) {
+	block := createBlockWithPvtHashes(t, "tx1", map[string]map[string][]byte{
+		"ns1": {
+			"c1": []byte{1, 2, 3},
+			"c2": []byte{4, 5, 6},
+		},
+	})
+	matching := PvtDataCollections{pvtDataOf(0, "ns1", map[string][]byte{
+		"c1": []byte{1, 2, 3},
+		"c2": []byte{4, 5, 6},
+	})}
+	tampered := PvtDataCollections{pvtDataOf(0, "ns1", map[string][]byte{
+		"c1": []byte{1, 2, 3},
+		"c2": []byte{6, 6, 6},
+	})}
+	outOfBlock := PvtDataCollections{pvtDataOf(1, "ns1", map[string][]byte{
+		"c1": []byte{1, 2, 3},
+	})}
+
+	v := newPvtDataVerifier("testchainid", PvtDataVerificationLenient)
+	verified, rejected, err := v.verify(block, matching)
+	assert.NoError(t, err)
+	assert.Empty(t, rejected)
+	assert.Len(t, verified, 1)
+	assert.Len(t, verified[0].Payload.WriteSet.NsPvtRwset[0].CollectionPvtRwset, 2)
+
+	// Lenient verification drops only the mismatching collection
+	verified, rejected, err = v.verify(block, tampered)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"tx1"}, rejected)
+	assert.Len(t, verified, 1)
+	colls := verified[0].Payload.WriteSet.NsPvtRwset[0].CollectionPvtRwset
+	assert.Len(t, colls, 1)
+	assert.Equal(t, "c1", colls[0].CollectionName)
+
+	verified, rejected, err = v.verify(block, outOfBlock)
+	assert.NoError(t, err)
+	assert.Empty(t, rejected)
+	assert.Empty(t, verified)
+
+	// Strict verification fails on any mismatch
+	v = newPvtDataVerifier("testchainid", PvtDataVerificationStrict)
+	verified, rejected, err = v.verify(block, matching)
+	assert.NoError(t, err)
+	assert.Empty(t, rejected)
+	assert.Len(t, verified, 1)
+
+	_, _, err = v.verify(block, tampered)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "ns1/c2")
+
+	_, _, err = v.verify(block, outOfBlock)
+	assert.Error(t, err)
+}
+
+func TestCoordinatorStoreBlockVerifiesPvtData(t *testing.T) {
+	block := createBlockWithPvtHashes(t, "tx1", map[string]map[string][]byte{
+		"ns1": {"c1": []byte{1, 2, 3}, "c2": []byte{4, 5, 6}},
+	})
+	tampered := PvtDataCollections{pvtDataOf(0, "ns1", map[string][]byte{"c1": []byte{1, 2, 3}, "c2": []byte{6, 5, 4}})}
+
+	committer := &committerMock{}
+	committer.On("Commit", block).Return(nil)
+	committer.On("CommitWithPvtData", mock.Anything).Return(nil)
+
+	// Without verification the received private data isn't committed
+	none := NewCoordinatorWithConfig(committer, CoordinatorConfig{ChainID: "testchainid"})
+	rejected, err := none.StoreBlock(block, tampered)
+	assert.NoError(t, err)
+	assert.Empty(t, rejected)
+	committer.AssertCalled(t, "Commit", block)
+	committer.AssertNotCalled(t, "CommitWithPvtData", mock.Anything)
+
+	// The verified private data is committed along with the block, the mismatching
+	// collection is left out for the ledger to record it as missing
+	lenient := NewCoordinatorWithConfig(committer, CoordinatorConfig{
+		ChainID:             "testchainid",
+		PvtDataVerification: PvtDataVerificationLenient,
+	})
+	rejected, err = lenient.StoreBlock(block, tampered)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"tx1"}, rejected)
+	committer.AssertNumberOfCalls(t, "CommitWithPvtData", 1)
+	committed := committer.Calls[len(committer.Calls)-1].Arguments.Get(0).(*ledger.BlockAndPvtData)
+	assert.Equal(t, block, committed.Block)
+	assert.True(t, committed.BlockPvtData[0].Has("ns1", "c1"))
+	assert.False(t, committed.BlockPvtData[0].Has("ns1", "c2"))
+
+	// Strict verification refuses to commit the block, for the state provider to retry it
+	strict := NewCoordinatorWithConfig(committer, CoordinatorConfig{
+		ChainID:             "testchainid",
+		PvtDataVerification: PvtDataVerificationStrict,
+	})
+	_, err = strict.StoreBlock(block, tampered)
+	assert.Error(t, err)
+	committer.AssertNumberOfCalls(t, "Commit", 1)
+	committer.AssertNumberOfCalls(t, "CommitWithPvtData", 1)
+}
+
+// collectionStoreMock defines collections whose members are the organizations
+// mapped to their name, the chaincodes defining them are listed in namespaces.
+// Below the block upgradedAt, the collections were defined by previousMembers
+type collectionStoreMock struct {
+	namespaces      []string
+	members         map[string][]string
+	previousMembers map[string][]string
+	upgradedAt      uint64
+}
+
+type accessPolicyMock []string
+
+func (ap accessPolicyMock) AccessFilter() privdata.Filter {
+	return func(common.SignedData) bool { return true }
+}
+
+func (ap accessPolicyMock) RequiredPeerCount() int {
+	return 0
+}
+
+func (ap accessPolicyMock) MaximumPeerCount() int {
+	return 0
+}
+
+func (ap accessPolicyMock) MemberOrgs() []string {
+	return ap
+}
+
+func (cs *collectionStoreMock) RetrieveCollection(cc common.CollectionCriteria) (privdata.Collection, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (cs *collectionStoreMock) RetrieveCollectionAccessPolicy(cc common.CollectionCriteria) (privdata.CollectionAccessPolicy, error) {
+	members, exists := cs.members[cc.Collection]
+	if !exists {
+		return nil, privdata.NoSuchCollectionError(cc)
+	}
+	return accessPolicyMock(members), nil
+}
+
+func (cs *collectionStoreMock) RetrieveCollectionConfigPackage(cc common.CollectionCriteria) (*common.CollectionConfigPackage, error) {
+	for _, ns := range cs.namespaces {
+		if ns == cc.Namespace {
+			return &common.CollectionConfigPackage{}, nil
+		}
+	}
+	return nil, nil
+}
+
+func (cs *collectionStoreMock) RetrieveCollectionConfigPackageAt(cc common.CollectionCriteria, blockNum uint64) (*common.CollectionConfigPackage, error) {
+	return cs.RetrieveCollectionConfigPackage(cc)
+}
+
+func (cs *collectionStoreMock) RetrieveCollectionAccessPolicyAt(cc common.CollectionCriteria, blockNum uint64) (privdata.CollectionAccessPolicy, error) {
+	if blockNum >= cs.upgradedAt || cs.previousMembers == nil {
+		return cs.RetrieveCollectionAccessPolicy(cc)
+	}
+	members, exists := cs.previousMembers[cc.Collection]
+	if !exists {
+		return nil, privdata.NoSuchCollectionError(cc)
+	}
+	return accessPolicyMock(members), nil
+}
+
+func TestCoordinatorStoreBlockDropsIneligiblePvtData(t *testing.T) {
+	block := createBlockWithPvtHashes(t, "tx1", map[string]map[string][]byte{
+		"ns1": {"c1": []byte{1, 2, 3}, "c2": []byte{4, 5, 6}, "c3": []byte{7, 8, 9}},
+		"ns2": {"c1": []byte{1, 2, 3}},
+	})
+
+	var committed []*ledger.BlockAndPvtData
+	committer := &committerMock{}
+	committer.On("CommitWithPvtData", mock.Anything).Run(func(args mock.Arguments) {
+		committed = append(committed, args.Get(0).(*ledger.BlockAndPvtData))
+	}).Return(nil)
+
+	coordinator := NewCoordinatorWithConfig(committer, CoordinatorConfig{
+		ChainID:             "testchainid",
+		PvtDataVerification: PvtDataVerificationLenient,
+		Collections: &collectionStoreMock{
+			namespaces: []string{"ns1"},
+			members:    map[string][]string{"c1": {"Org1MSP"}, "c2": {"Org2MSP"}},
+		},
+		SelfOrg: "Org1MSP",
+	})
+
+	// c2 isn't open to Org1MSP and c3 isn't a collection of ns1, hence their
+	// private data is dropped rather than verified
+	rejected, err := coordinator.StoreBlock(block, PvtDataCollections{
+		pvtDataOf(0, "ns1", map[string][]byte{"c1": []byte{1, 2, 3}, "c2": []byte{6, 5, 4}, "c3": []byte{9, 8, 7}}),
+	})
+	assert.NoError(t, err)
+	assert.Empty(t, rejected)
+	assert.True(t, committed[0].BlockPvtData[0].Has("ns1", "c1"))
+	assert.False(t, committed[0].BlockPvtData[0].Has("ns1", "c2"))
+	assert.False(t, committed[0].BlockPvtData[0].Has("ns1", "c3"))
+
+	// ns2 has no collection configs, hence its collections are open to every organization
+	rejected, err = coordinator.StoreBlock(block, PvtDataCollections{
+		pvtDataOf(0, "ns2", map[string][]byte{"c1": []byte{3, 2, 1}}),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"tx1"}, rejected)
+	assert.Empty(t, committed[1].BlockPvtData)
+
+	// The private data of a transaction received in several parts is committed at once
+	_, err = coordinator.StoreBlock(block, PvtDataCollections{
+		pvtDataOf(0, "ns1", map[string][]byte{"c1": []byte{1, 2, 3}}),
+		pvtDataOf(0, "ns2", map[string][]byte{"c1": []byte{1, 2, 3}}),
+	})
+	assert.NoError(t, err)
+	assert.True(t, committed[2].BlockPvtData[0].Has("ns1", "c1"))
+	assert.True(t, committed[2].BlockPvtData[0].Has("ns2", "c1"))
+}
+
+func TestCoordinatorStoreBlockEligibilityAtBlockHeight(t *testing.T) {
+	block := createBlockWithPvtHashes(t, "tx1", map[string]map[string][]byte{
+		"ns1": {"c1": []byte{1, 2, 3}, "c2": []byte{4, 5, 6}},
+	})
+
+	var committed []*ledger.BlockAndPvtData
+	committer := &committerMock{}
+	committer.On("CommitWithPvtData", mock.Anything).Run(func(args mock.Arguments) {
+		committed = append(committed, args.Get(0).(*ledger.BlockAndPvtData))
+	}).Return(nil)
+
+	// Org1MSP was a member of c2 until the chaincode was upgraded at block 10
+	coordinator := NewCoordinatorWithConfig(committer, CoordinatorConfig{
+		ChainID:             "testchainid",
+		PvtDataVerification: PvtDataVerificationLenient,
+		Collections: &collectionStoreMock{
+			namespaces:      []string{"ns1"},
+			members:         map[string][]string{"c1": {"Org1MSP"}, "c2": {"Org2MSP"}},
+			previousMembers: map[string][]string{"c1": {"Org2MSP"}, "c2": {"Org1MSP"}},
+			upgradedAt:      10,
+		},
+		SelfOrg: "Org1MSP",
+	})
+
+	pvtData := PvtDataCollections{
+		pvtDataOf(0, "ns1", map[string][]byte{"c1": []byte{1, 2, 3}, "c2": []byte{4, 5, 6}}),
+	}
+	block.Header.Number = 5
+	_, err := coordinator.StoreBlock(block, pvtData)
+	assert.NoError(t, err)
+	assert.False(t, committed[0].BlockPvtData[0].Has("ns1", "c1"))
+	assert.True(t, committed[0].BlockPvtData[0].Has("ns1", "c2"))
+	assert.Equal(t, ledger.TxMissingPvtDataMap{0: {{Namespace: "ns1", Collection: "c1", IsEligible: false}}}, committed[0].MissingPvtData)
+
+	block.Header.Number = 10
+	_, err = coordinator.StoreBlock(block, pvtData)
+	assert.NoError(t, err)
+	assert.True(t, committed[1].BlockPvtData[0].Has("ns1", "c1"))
+	assert.False(t, committed[1].BlockPvtData[0].Has("ns1", "c2"))
+	assert.Equal(t, ledger.TxMissingPvtDataMap{0: {{Namespace: "ns1", Collection: "c2", IsEligible: false}}}, committed[1].MissingPvtData)
+
+	// The ineligible collections are recorded even when no private data comes along with the block
+	_, err = coordinator.StoreBlock(block)
+	assert.NoError(t, err)
+	assert.Nil(t, committed[2].BlockPvtData)
+	assert.Equal(t, ledger.TxMissingPvtDataMap{0: {{Namespace: "ns1", Collection: "c2", IsEligible: false}}}, committed[2].MissingPvtData)
+}
@@ -0,0 +1,319 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package state
+
+import (
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric/gossip/comm"
+	"github.com/hyperledger/fabric/gossip/discovery"
+	"github.com/hyperledger/fabric/gossip/util"
+	proto "github.com/hyperledger/fabric/protos/gossip"
+)
+
+const defMaxConcurrentFetches = 1
+
+// ReplicationPolicy configures how the state provider of a channel catches up
+// with the other peers of the channel, so that a peer joined to many channels
+// can prioritize the catch-up of some of them over the others
+type ReplicationPolicy struct {
+	// PreferredOrgs are the organizations whose peers missing blocks are requested
+	// from, in order of preference. Peers of other organizations are only asked
+	// when no peer of the preferred organizations has the missing blocks
+	PreferredOrgs []string
+	// OrgOf returns the organization of a peer, empty if it is unknown.
+	// PreferredOrgs is ignored without it
+	OrgOf func(peer discovery.NetworkMember) string
+	// MaxConcurrentFetches is the number of batches of missing
+	// blocks requested from peers at once, 1 if unset
+	MaxConcurrentFetches int
+	// MaxBytesPerSecond caps the rate at which missing blocks
+	// are fetched from peers, 0 for no cap
+	MaxBytesPerSecond int
+}
+
+// SetReplicationPolicy sets the policy the missing blocks of the
+// channel are fetched from the other peers of the channel by
+func (s *GossipStateProviderImpl) SetReplicationPolicy(policy ReplicationPolicy) {
+	if policy.MaxConcurrentFetches <= 0 {
+		policy.MaxConcurrentFetches = defMaxConcurrentFetches
+	}
+	if policy.MaxBytesPerSecond < 0 {
+		policy.MaxBytesPerSecond = 0
+	}
+	if len(policy.PreferredOrgs) > 0 && policy.OrgOf == nil {
+		logger.Warning("No organization resolver is configured, ignoring the preferred organizations of channel", s.chainID)
+		policy.PreferredOrgs = nil
+	}
+	s.replication.Store(&policy)
+	s.logger.Infof("Replication policy set: preferred orgs %v, max concurrent fetches %d, max bytes per second %d",
+		policy.PreferredOrgs, policy.MaxConcurrentFetches, policy.MaxBytesPerSecond)
+}
+
+func (s *GossipStateProviderImpl) replicationPolicy() *ReplicationPolicy {
+	if policy, _ := s.replication.Load().(*ReplicationPolicy); policy != nil {
+		return policy
+	}
+	return &ReplicationPolicy{MaxConcurrentFetches: defMaxConcurrentFetches}
+}
+
+// bandwidthLimiter paces the fetches of a channel so that
+// their rate doesn't exceed the given bytes per second
+type bandwidthLimiter struct {
+	mutex          sync.Mutex
+	bytesPerSecond int
+	next           time.Time
+}
+
+// reserve accounts for n fetched bytes, and returns how long to
+// wait before fetching more to keep within the rate of the limiter
+func (l *bandwidthLimiter) reserve(n int) time.Duration {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	l.next = l.next.Add(l.duration(n))
+	return l.next.Sub(now)
+}
+
+// reserveNext accounts for n bytes about to be sent, and returns how long to wait before
+// sending them for the bytes accounted for before them to be sent within the rate of the limiter
+func (l *bandwidthLimiter) reserveNext(n int) time.Duration {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	wait := l.next.Sub(now)
+	l.next = l.next.Add(l.duration(n))
+	return wait
+}
+
+// cancel gives back the last n bytes accounted for, which were not sent
+func (l *bandwidthLimiter) cancel(n int) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.next = l.next.Add(-l.duration(n))
+}
+
+// idle returns whether the bytes accounted for by the limiter have all been sent
+func (l *bandwidthLimiter) idle() bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return !l.next.After(time.Now())
+}
+
+// duration returns how long sending n bytes takes at the rate of the limiter
+func (l *bandwidthLimiter) duration(n int) time.Duration {
+	return time.Duration(n) * time.Second / time.Duration(l.bytesPerSecond)
+}
+
+// rangeFetcher fetches the missing blocks of a range from the peers of the channel, in batches
+// requested concurrently. The state responses are routed to the fetch waiting for them by nonce
+type rangeFetcher struct {
+	s         *GossipStateProviderImpl
+	policy    *ReplicationPolicy
+	limiter   *bandwidthLimiter
+	mutex     sync.Mutex
+	responses map[uint64]chan proto.ReceivedMessage
+	failed    bool
+	stopped   chan struct{}
+}
+
+func newRangeFetcher(s *GossipStateProviderImpl, policy *ReplicationPolicy) *rangeFetcher {
+	f := &rangeFetcher{
+		s:         s,
+		policy:    policy,
+		responses: make(map[uint64]chan proto.ReceivedMessage),
+		stopped:   make(chan struct{}),
+	}
+	if policy.MaxBytesPerSecond > 0 {
+		f.limiter = &bandwidthLimiter{bytesPerSecond: policy.MaxBytesPerSecond}
+	}
+	return f
+}
+
+// fetch requests the blocks in the range [start...end] in batches, up to the max concurrent
+// fetches of the policy at once. It gives up on the range once a batch cannot be fetched
+func (f *rangeFetcher) fetch(start uint64, end uint64) {
+	batches := make(chan [2]uint64)
+	var workers sync.WaitGroup
+	for i := 0; i < f.policy.MaxConcurrentFetches; i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for batch := range batches {
+				if !f.fetchBatch(batch[0], batch[1]) {
+					f.fail()
+				}
+			}
+		}()
+	}
+
+	done := make(chan struct{})
+	routed := make(chan struct{})
+	go f.routeResponses(done, routed)
+
+	for prev := start; prev <= end && !f.hasFailed(); {
+		next := min(end, prev+defAntiEntropyBatchSize)
+		select {
+		case batches <- [2]uint64{prev, next}:
+			prev = next + 1
+		case <-f.stopped:
+			prev = end + 1
+		}
+	}
+	close(batches)
+	workers.Wait()
+	close(done)
+	<-routed
+}
+
+// routeResponses routes the state responses to the fetches waiting for them until the fetch is done,
+// or the state provider is stopped
+func (f *rangeFetcher) routeResponses(done <-chan struct{}, routed chan<- struct{}) {
+	defer close(routed)
+	for {
+		select {
+		case msg := <-f.s.stateResponseCh:
+			f.mutex.Lock()
+			responses, exists := f.responses[msg.GetGossipMessage().Nonce]
+			f.mutex.Unlock()
+			if !exists {
+				continue
+			}
+			select {
+			case responses <- msg:
+			default:
+			}
+		case <-f.s.stopCh:
+			f.s.stopCh <- struct{}{}
+			close(f.stopped)
+			return
+		case <-done:
+			return
+		}
+	}
+}
+
+// fetchBatch fetches the blocks in the range [from...to], retrying with other peers when
+// they don't respond. It returns false if the blocks could not be fetched
+func (f *rangeFetcher) fetchBatch(from uint64, to uint64) bool {
+	for from <= to {
+		gossipMsg := f.s.stateRequestMessage(from, to)
+		responses := f.await(gossipMsg.Nonce)
+
+		index, fetched := f.request(gossipMsg, responses, from, to)
+		f.mutex.Lock()
+		delete(f.responses, gossipMsg.Nonce)
+		f.mutex.Unlock()
+		if !fetched || index < from {
+			return false
+		}
+		from = index + 1
+	}
+	return true
+}
+
+// request sends the state request to peers until one of them responds, and returns the highest
+// sequence number of the blocks received, and whether any were received
+func (f *rangeFetcher) request(gossipMsg *proto.GossipMessage, responses <-chan proto.ReceivedMessage, from uint64, to uint64) (uint64, bool) {
+	for tryCounts := 0; ; {
+		if tryCounts > defAntiEntropyMaxRetries {
+			f.s.logger.Warningf("Wasn't  able to get blocks in range [%d...%d], after %d retries",
+				from, to, tryCounts)
+			return 0, false
+		}
+		// Select peers to ask for blocks, the last try doesn't prefer the preferred organizations
+		// in case their peers are unresponsive
+		peer, err := f.s.selectPeerToRequestFrom(to, tryCounts < defAntiEntropyMaxRetries)
+		if err != nil {
+			f.s.logger.Warningf("Cannot send state request for blocks in range [%d...%d], due to %s",
+				from, to, err)
+			return 0, false
+		}
+
+		f.s.logger.Debugf("State transfer, with peer %s, requesting blocks in range [%d...%d]",
+			peer.Endpoint, from, to)
+
+		f.s.mediator.Send(gossipMsg, peer)
+		tryCounts++
+
+		// Wait until timeout or response arrival
+		select {
+		case msg := <-responses:
+			// Got corresponding response for state request, can continue
+			index, err := f.s.handleStateResponse(msg)
+			if err != nil {
+				f.s.logger.Warningf("Wasn't able to process state response for "+
+					"blocks [%d...%d], due to %s", from, to, err)
+				continue
+			}
+			f.throttle(msg)
+			return index, true
+		case <-time.After(defAntiEntropyStateResponseTimeout):
+		case <-f.stopped:
+			return 0, false
+		}
+	}
+}
+
+func (f *rangeFetcher) await(nonce uint64) <-chan proto.ReceivedMessage {
+	responses := make(chan proto.ReceivedMessage, 1)
+	f.mutex.Lock()
+	f.responses[nonce] = responses
+	f.mutex.Unlock()
+	return responses
+}
+
+// throttle waits as long as the bandwidth cap of the policy requires after fetching the blocks of the response
+func (f *rangeFetcher) throttle(msg proto.ReceivedMessage) {
+	if f.limiter == nil {
+		return
+	}
+	size := 0
+	for _, payload := range msg.GetGossipMessage().GetStateResponse().GetPayloads() {
+		size += len(payload.Data)
+		for _, pvtData := range payload.PrivateData {
+			size += len(pvtData)
+		}
+	}
+	select {
+	case <-time.After(f.limiter.reserve(size)):
+	case <-f.stopped:
+	}
+}
+
+func (f *rangeFetcher) fail() {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.failed = true
+}
+
+func (f *rangeFetcher) hasFailed() bool {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.failed
+}
+
+// selectPreferredPeer selects a peer of the most preferred organization among the members
+// accepted by the predicate, or returns nil if none of them is in a preferred organization
+func (p *ReplicationPolicy) selectPreferredPeer(s *GossipStateProviderImpl, predicate func(peer discovery.NetworkMember) bool) *comm.RemotePeer {
+	for _, org := range p.PreferredOrgs {
+		peers := s.filterPeers(func(peer discovery.NetworkMember) bool {
+			return predicate(peer) && p.OrgOf(peer) == org
+		})
+		if len(peers) > 0 {
+			return peers[util.RandomInt(len(peers))]
+		}
+	}
+	return nil
+}
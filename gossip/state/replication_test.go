@@ -0,0 +1,192 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package state
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	pb "github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/gossip/comm"
+	"github.com/hyperledger/fabric/gossip/common"
+	"github.com/hyperledger/fabric/gossip/discovery"
+	"github.com/hyperledger/fabric/gossip/state/mocks"
+	"github.com/hyperledger/fabric/gossip/util"
+	pcomm "github.com/hyperledger/fabric/protos/common"
+	proto "github.com/hyperledger/fabric/protos/gossip"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestBandwidthLimiter(t *testing.T) {
+	limiter := &bandwidthLimiter{bytesPerSecond: 1000}
+	assert.InDelta(t, float64(500*time.Millisecond), float64(limiter.reserve(500)), float64(50*time.Millisecond))
+	assert.InDelta(t, float64(time.Second), float64(limiter.reserve(500)), float64(50*time.Millisecond))
+
+	// Reservations don't accumulate past the time they are due
+	limiter.next = time.Now().Add(-time.Hour)
+	assert.InDelta(t, float64(100*time.Millisecond), float64(limiter.reserve(100)), float64(50*time.Millisecond))
+}
+
+func TestSetReplicationPolicy(t *testing.T) {
+	s := &GossipStateProviderImpl{chainID: "testchainid", logger: flogging.MustGetContextLogger(util.LoggingStateModule)}
+	assert.Equal(t, &ReplicationPolicy{MaxConcurrentFetches: 1}, s.replicationPolicy())
+
+	s.SetReplicationPolicy(ReplicationPolicy{PreferredOrgs: []string{"ORG1"}, MaxConcurrentFetches: -1, MaxBytesPerSecond: -1})
+	assert.Equal(t, &ReplicationPolicy{MaxConcurrentFetches: 1}, s.replicationPolicy(), "Expected the preferred orgs to be ignored without an org resolver")
+
+	s.SetReplicationPolicy(ReplicationPolicy{
+		PreferredOrgs:        []string{"ORG1"},
+		OrgOf:                func(discovery.NetworkMember) string { return "ORG1" },
+		MaxConcurrentFetches: 4,
+		MaxBytesPerSecond:    1024,
+	})
+	policy := s.replicationPolicy()
+	assert.Equal(t, []string{"ORG1"}, policy.PreferredOrgs)
+	assert.Equal(t, 4, policy.MaxConcurrentFetches)
+	assert.Equal(t, 1024, policy.MaxBytesPerSecond)
+}
+
+func TestRequestBlocksWithReplicationPolicy(t *testing.T) {
+	chainID := "testchainid"
+	commChan := make(chan proto.ReceivedMessage)
+	g := &mocks.GossipMock{}
+	g.On("Accept", mock.Anything, false).Return(make(<-chan *proto.GossipMessage), nil)
+	g.On("Accept", mock.Anything, true).Return(nil, (<-chan proto.ReceivedMessage)(commChan))
+	g.On("UpdateChannelMetadata", mock.Anything, mock.Anything)
+
+	// The peer of the preferred org only has the first batch of the missing blocks
+	member := func(endpoint string, height uint64) discovery.NetworkMember {
+		metaBytes, err := (&NodeMetastate{LedgerHeight: height}).Bytes()
+		assert.NoError(t, err)
+		return discovery.NetworkMember{PKIid: common.PKIidType(endpoint), Endpoint: endpoint, Metadata: metaBytes}
+	}
+	g.On("PeersOfChannel", mock.Anything).Return([]discovery.NetworkMember{member("org1:7051", 25), member("org2:7051", 12)})
+	orgs := map[string]string{"org1:7051": "ORG1", "org2:7051": "ORG2"}
+
+	var lock sync.Mutex
+	inFlight, maxInFlight := 0, 0
+	requestedFrom := make(map[uint64]string)
+	g.On("Send", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		request := args.Get(0).(*proto.GossipMessage)
+		peer := args.Get(1).([]*comm.RemotePeer)[0]
+		stateRequest := request.GetStateRequest()
+		lock.Lock()
+		inFlight++
+		if inFlight > maxInFlight {
+			maxInFlight = inFlight
+		}
+		requestedFrom[stateRequest.StartSeqNum] = peer.Endpoint
+		lock.Unlock()
+
+		response := &proto.RemoteStateResponse{}
+		for seqNum := stateRequest.StartSeqNum; seqNum <= stateRequest.EndSeqNum; seqNum++ {
+			blockBytes, _ := pb.Marshal(pcomm.NewBlock(seqNum, []byte{}))
+			response.Payloads = append(response.Payloads, &proto.Payload{SeqNum: seqNum, Data: blockBytes})
+		}
+		signedResponse, _ := (&proto.GossipMessage{
+			Nonce:   request.Nonce,
+			Channel: []byte(chainID),
+			Content: &proto.GossipMessage_StateResponse{StateResponse: response},
+		}).NoopSign()
+		responseMsg := new(receivedMessageMock)
+		responseMsg.On("GetGossipMessage").Return(signedResponse)
+		go func() {
+			// Delay the responses for the requests to overlap
+			time.Sleep(100 * time.Millisecond)
+			lock.Lock()
+			inFlight--
+			lock.Unlock()
+			commChan <- responseMsg
+		}()
+	})
+
+	coord := new(coordinatorMock)
+	coord.On("LedgerHeight", mock.Anything).Return(uint64(1), nil)
+	coord.On("Close")
+	stored := make(chan uint64, 30)
+	coord.On("StoreBlock", mock.Anything, mock.Anything).Return([]string{}, nil).Run(func(args mock.Arguments) {
+		stored <- args.Get(0).(*pcomm.Block).Header.Number
+	})
+
+	cryptoService := &cryptoServiceMock{acceptor: noopPeerIdentityAcceptor}
+	s := NewGossipCoordinatedStateProvider(chainID, &ServicesMediator{GossipAdapter: g, MCSAdapter: cryptoService}, coord).(*GossipStateProviderImpl)
+	defer s.Stop()
+	s.SetReplicationPolicy(ReplicationPolicy{
+		PreferredOrgs:        []string{"ORG2"},
+		OrgOf:                func(peer discovery.NetworkMember) string { return orgs[peer.Endpoint] },
+		MaxConcurrentFetches: 3,
+	})
+
+	s.requestBlocksInRange(1, 24)
+
+	for seqNum := uint64(1); seqNum <= 24; seqNum++ {
+		select {
+		case committed := <-stored:
+			assert.Equal(t, seqNum, committed)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Block %d wasn't committed", seqNum)
+		}
+	}
+	lock.Lock()
+	defer lock.Unlock()
+	assert.Equal(t, map[uint64]string{1: "org2:7051", 12: "org1:7051", 23: "org1:7051"}, requestedFrom,
+		"Expected the blocks to be requested from the preferred org when it has them")
+	assert.True(t, maxInFlight > 1 && maxInFlight <= 3, "Expected up to 3 concurrent requests, got %d", maxInFlight)
+}
+
+func TestSelectPeerServingPvtData(t *testing.T) {
+	member := func(endpoint string, metastate *NodeMetastate, legacy bool) discovery.NetworkMember {
+		metaBytes, err := metastate.Bytes()
+		assert.NoError(t, err)
+		if legacy {
+			metaBytes = metaBytes[:8]
+		}
+		return discovery.NetworkMember{PKIid: common.PKIidType(endpoint), Endpoint: endpoint, Metadata: metaBytes}
+	}
+	members := []discovery.NetworkMember{
+		member("legacy:7051", &NodeMetastate{LedgerHeight: 20}, true),
+		member("nopvt:7051", &NodeMetastate{LedgerHeight: 30}, false),
+		member("pvt:7051", &NodeMetastate{LedgerHeight: 20, PvtDataAvailable: true}, false),
+	}
+	g := &mocks.GossipMock{}
+	g.On("PeersOfChannel", mock.Anything).Return(members)
+	g.On("UpdateChannelMetadata", mock.Anything, mock.Anything)
+	coord := new(coordinatorMock)
+	coord.On("LedgerHeight", mock.Anything).Return(uint64(10), nil)
+	s := &GossipStateProviderImpl{
+		chainID:     "testchainid",
+		logger:      flogging.MustGetContextLogger(util.LoggingStateModule),
+		mediator:    &ServicesMediator{GossipAdapter: g},
+		coordinator: coord,
+	}
+
+	selected := func(height uint64) map[string]struct{} {
+		endpoints := make(map[string]struct{})
+		for i := 0; i < 100; i++ {
+			peer, err := s.selectPeerToRequestFrom(height, true)
+			assert.NoError(t, err)
+			endpoints[peer.Endpoint] = struct{}{}
+		}
+		return endpoints
+	}
+
+	// Without private data, any peer with the blocks is asked
+	assert.Len(t, selected(15), 3)
+
+	// With private data, the peers which advertise they don't store it are avoided,
+	// unless no other peer has the blocks
+	s.SetPvtDataAvailable(true)
+	assert.Equal(t, &NodeMetastate{LedgerHeight: 9, PvtDataAvailable: true}, s.nodeMetastate(9))
+	assert.Equal(t, map[string]struct{}{"legacy:7051": {}, "pvt:7051": {}}, selected(15))
+	assert.Equal(t, map[string]struct{}{"nopvt:7051": {}}, selected(25))
+
+	_, err := s.selectPeerToRequestFrom(35, true)
+	assert.Error(t, err)
+}
@@ -0,0 +1,95 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package state
+
+import (
+	"sync"
+	"time"
+)
+
+// ResponseBandwidthConfig caps the outbound bandwidth of the state responses the state
+// provider of a channel sends, so that the anti-entropy of many lagging peers cannot
+// saturate the network link of the peer serving them
+type ResponseBandwidthConfig struct {
+	// PerPeerBytesPerSecond caps the rate of the state
+	// responses sent to each peer, 0 for no cap
+	PerPeerBytesPerSecond int
+	// PerChannelBytesPerSecond caps the rate of the state responses
+	// of the channel sent to all peers together, 0 for no cap
+	PerChannelBytesPerSecond int
+}
+
+// responseThrottle paces the state responses of a channel within
+// the per peer and per channel caps of its configuration
+type responseThrottle struct {
+	conf    ResponseBandwidthConfig
+	channel *bandwidthLimiter
+
+	mutex sync.Mutex
+	peers map[string]*bandwidthLimiter
+}
+
+// newResponseThrottle creates a throttle of the state responses of a
+// channel, or returns nil if the configuration caps none of them
+func newResponseThrottle(conf ResponseBandwidthConfig) *responseThrottle {
+	if conf.PerPeerBytesPerSecond <= 0 && conf.PerChannelBytesPerSecond <= 0 {
+		return nil
+	}
+	t := &responseThrottle{
+		conf:  conf,
+		peers: make(map[string]*bandwidthLimiter),
+	}
+	if conf.PerChannelBytesPerSecond > 0 {
+		t.channel = &bandwidthLimiter{bytesPerSecond: conf.PerChannelBytesPerSecond}
+	}
+	return t
+}
+
+// admit accounts for a response of the given size to the given peer, and returns how long to wait
+// before sending it to keep within the caps. The response is refused, and not accounted for, if it
+// cannot be sent within maxWait
+func (t *responseThrottle) admit(peer string, size int, maxWait time.Duration) (time.Duration, bool) {
+	var limiters []*bandwidthLimiter
+	if t.channel != nil {
+		limiters = append(limiters, t.channel)
+	}
+	if t.conf.PerPeerBytesPerSecond > 0 {
+		limiters = append(limiters, t.peerLimiter(peer))
+	}
+
+	var wait time.Duration
+	for _, limiter := range limiters {
+		if w := limiter.reserveNext(size); w > wait {
+			wait = w
+		}
+	}
+	if wait > maxWait {
+		for _, limiter := range limiters {
+			limiter.cancel(size)
+		}
+		return 0, false
+	}
+	return wait, true
+}
+
+// peerLimiter returns the limiter of the responses sent to the given
+// peer, and forgets the limiters of the peers no response is paced for
+func (t *responseThrottle) peerLimiter(peer string) *bandwidthLimiter {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	for p, limiter := range t.peers {
+		if p != peer && limiter.idle() {
+			delete(t.peers, p)
+		}
+	}
+	limiter, exists := t.peers[peer]
+	if !exists {
+		limiter = &bandwidthLimiter{bytesPerSecond: t.conf.PerPeerBytesPerSecond}
+		t.peers[peer] = limiter
+	}
+	return limiter
+}
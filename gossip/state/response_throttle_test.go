@@ -0,0 +1,49 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package state
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResponseThrottle(t *testing.T) {
+	assert.Nil(t, newResponseThrottle(ResponseBandwidthConfig{}))
+
+	delta := float64(50 * time.Millisecond)
+	throttle := newResponseThrottle(ResponseBandwidthConfig{PerPeerBytesPerSecond: 1000, PerChannelBytesPerSecond: 2000})
+
+	// The first response to each peer is sent right away, within the per channel cap
+	wait, admitted := throttle.admit("p1", 1000, time.Second)
+	assert.True(t, admitted)
+	assert.Equal(t, time.Duration(0), wait)
+	wait, admitted = throttle.admit("p2", 1000, time.Second)
+	assert.True(t, admitted)
+	assert.InDelta(t, float64(500*time.Millisecond), float64(wait), delta)
+
+	// The next response to a peer waits for the per peer cap
+	wait, admitted = throttle.admit("p1", 500, 2*time.Second)
+	assert.True(t, admitted)
+	assert.InDelta(t, float64(time.Second), float64(wait), delta)
+
+	// A response that cannot be sent in time is refused, and not accounted for
+	_, admitted = throttle.admit("p1", 500, time.Second)
+	assert.False(t, admitted)
+	wait, admitted = throttle.admit("p3", 100, 2*time.Second)
+	assert.True(t, admitted)
+	assert.InDelta(t, float64(1250*time.Millisecond), float64(wait), delta)
+
+	// The limiters of idle peers are forgotten
+	assert.Len(t, throttle.peers, 3)
+	for _, limiter := range throttle.peers {
+		limiter.next = time.Now().Add(-time.Second)
+	}
+	throttle.admit("p4", 100, time.Hour)
+	assert.Len(t, throttle.peers, 1)
+}
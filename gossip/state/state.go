@@ -10,11 +10,13 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"sort"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	pb "github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/flogging"
 	"github.com/hyperledger/fabric/core/committer"
 	"github.com/hyperledger/fabric/gossip/api"
 	"github.com/hyperledger/fabric/gossip/comm"
@@ -24,8 +26,13 @@ import (
 	"github.com/hyperledger/fabric/protos/common"
 	proto "github.com/hyperledger/fabric/protos/gossip"
 	"github.com/op/go-logging"
+	"golang.org/x/net/context"
 )
 
+// The state transfer message exchange and the interfaces of this package are described
+// in state_transfer_api.json, for peer implementations that need to interoperate with it
+//go:generate go run $GOPATH/src/github.com/hyperledger/fabric/common/tools/stateapidesc/main.go -root ../.. -output state_transfer_api.json
+
 // GossipStateProvider is the interface to acquire sequences of the ledger blocks
 // capable to full fill missing blocks by running state replication and
 // sending request to get missing block to other nodes
@@ -35,6 +42,26 @@ type GossipStateProvider interface {
 
 	AddPayload(payload *proto.Payload) error
 
+	// SetReplicationPolicy sets the policy the missing blocks
+	// are fetched from the other peers of the channel by
+	SetReplicationPolicy(policy ReplicationPolicy)
+
+	// SetPvtDataAvailable sets whether the peer stores the private data of
+	// the channel, which it advertises to the other peers of the channel
+	SetPvtDataAvailable(available bool)
+
+	// SetStateRequestTimeout sets the deadline of serving the state requests
+	// of other peers, the ledger reads of the requests are canceled past it
+	SetStateRequestTimeout(timeout time.Duration)
+
+	// AddCommitHook registers a hook invoked with every block the provider commits,
+	// once the block is committed to the ledger, in the order the hooks are added
+	AddCommitHook(hook CommitHook)
+
+	// HealthCheck returns an error once the channel is halted, as a block
+	// cannot be committed to the ledger and is quarantined
+	HealthCheck(ctx context.Context) error
+
 	// Stop terminates state transfer object
 	Stop()
 }
@@ -44,12 +71,20 @@ const (
 	defAntiEntropyStateResponseTimeout = 3 * time.Second
 	defAntiEntropyBatchSize            = 10
 
+	// The requesters give up on state responses past their timeout
+	defStateRequestTimeout = defAntiEntropyStateResponseTimeout
+
 	defChannelBufferSize     = 100
 	defAntiEntropyMaxRetries = 3
 
 	defMaxBlockDistance = 100
 )
 
+// CommitHook post-processes a block committed to the ledger by the state provider, along with the
+// private data received with the block, e.g. to index the block off-chain or to notify other systems
+// of it. The hooks run in the commit loop, an error of a hook is logged and doesn't fail the commit
+type CommitHook func(block *common.Block, pvtData PvtDataCollections) error
+
 // GossipAdapter defines gossip/communication required interface for state provider
 type GossipAdapter interface {
 	// Send sends a message to remote peers
@@ -100,6 +135,9 @@ type GossipStateProviderImpl struct {
 	// Chain id
 	chainID string
 
+	// Logger attaching the chain id to the records it logs
+	logger *flogging.ContextLogger
+
 	mediator *ServicesMediator
 
 	// Channel to read gossip messages from
@@ -114,15 +152,80 @@ type GossipStateProviderImpl struct {
 
 	stateResponseCh chan proto.ReceivedMessage
 
-	stateRequestCh chan proto.ReceivedMessage
+	stateRequestCh chan *stateRequest
 
 	stopCh chan struct{}
 
+	// Context of the state requests being served, canceled upon stop
+	ctx context.Context
+
+	cancel context.CancelFunc
+
+	// Deadline of serving a state request, in nanoseconds
+	stateRequestTimeout int64
+
+	// Paces the state responses within their bandwidth caps, nil if uncapped
+	responseThrottle *responseThrottle
+
+	// Number of peers that must advertise a ledger height for the anti-entropy to trust it
+	heightQuorum int
+
+	// Highest sequence number of the verified blocks received, which backs
+	// the ledger heights advertised by fewer peers than the quorum
+	verifiedSeqNum uint64
+
 	done sync.WaitGroup
 
 	once sync.Once
 
 	stateTransferActive int32
+
+	// Differential state sync, nil if the ledger doesn't support it
+	stateDiff *stateDiffSync
+
+	// Set to 1 while the recovery of the state database is deferred
+	stateDeferred int32
+
+	// Holds the *ReplicationPolicy missing blocks are fetched by, once set
+	replication atomic.Value
+
+	// Set to 1 if the peer stores the private data of the channel
+	pvtDataAvailable int32
+
+	// Guards the bootstrap offers made to other peers, and the one accepted
+	bootstrapMutex sync.Mutex
+
+	// The offers to push blocks made to peers joining the channel, by nonce
+	bootstrapOffers map[uint64]*bootstrapOffer
+
+	// The offer to push blocks this peer accepted, nil if none is being pushed
+	acceptedOffer *acceptedOffer
+
+	// Guards the registration of commit hooks
+	commitHooksLock sync.Mutex
+
+	// Holds the []CommitHook invoked with the committed blocks
+	commitHooks atomic.Value
+
+	// Retry policy of the failed commits
+	commitRetry CommitRetryConfig
+
+	// Holds the *quarantinedBlock the channel is halted at, once halted
+	quarantined atomic.Value
+}
+
+// stateRequest is a state request of a remote peer queued to be served,
+// along with the context it is served in
+type stateRequest struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	msg    proto.ReceivedMessage
+}
+
+// streamMessage is implemented by the received messages that tell
+// when the connection to the peer which sent them is closed
+type streamMessage interface {
+	Context() context.Context
 }
 
 var logger *logging.Logger // package-level logger
@@ -131,9 +234,42 @@ func init() {
 	logger = util.GetLogger(util.LoggingStateModule, "")
 }
 
+// ProviderConfig configures the state provider of a channel
+type ProviderConfig struct {
+	// PayloadsSpill enables the disk-backed overflow of the buffer of the
+	// payloads waiting to be committed, if set
+	PayloadsSpill *PayloadsSpillConfig
+
+	// ResponseBandwidth caps the outbound bandwidth of the
+	// state responses sent to the other peers of the channel
+	ResponseBandwidth ResponseBandwidthConfig
+
+	// BootstrapOffers enables offering the peers which join the channel far
+	// behind this peer to push them the blocks they miss, while it is in sync
+	BootstrapOffers bool
+
+	// HeightQuorum is the number of peers that must advertise a ledger height for the
+	// anti-entropy to request the blocks up to it, unless a block of that height was
+	// received and verified. 0 or 1 trusts the maximum height any peer advertises
+	HeightQuorum int
+
+	// CommitRetry configures the retries of the failed commits of blocks, and
+	// the quarantine of the blocks which cannot be committed
+	CommitRetry CommitRetryConfig
+
+	// StateDiff configures the differential state synchronization, which brings
+	// a state database whose recovery was deferred up to date
+	StateDiff StateDiffConfig
+}
+
 // NewGossipCoordinatedStateProvider creates state provider with coordinator instance
 // to orchestrate arrival of private rwsets and blocks before committing them into the ledger.
 func NewGossipCoordinatedStateProvider(chainID string, services *ServicesMediator, coordinator Coordinator) GossipStateProvider {
+	return NewGossipStateProviderWithConfig(chainID, services, coordinator, ProviderConfig{})
+}
+
+// NewGossipStateProviderWithConfig creates state provider with coordinator instance, and the given configuration
+func NewGossipStateProviderWithConfig(chainID string, services *ServicesMediator, coordinator Coordinator, config ProviderConfig) GossipStateProvider {
 
 	logger := util.GetLogger(util.LoggingStateModule, "")
 
@@ -180,6 +316,16 @@ func NewGossipCoordinatedStateProvider(chainID string, services *ServicesMediato
 		return nil
 	}
 
+	payloads := NewPayloadsBuffer(height)
+	if config.PayloadsSpill != nil {
+		if payloads, err = NewSpillingPayloadsBuffer(height, *config.PayloadsSpill); err != nil {
+			logger.Warning("Buffering the payloads of channel", chainID, "in memory only:", err)
+			payloads = NewPayloadsBuffer(height)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
 	s := &GossipStateProviderImpl{
 		// MessageCryptoService
 		mediator: services,
@@ -187,6 +333,8 @@ func NewGossipCoordinatedStateProvider(chainID string, services *ServicesMediato
 		// Chain ID
 		chainID: chainID,
 
+		logger: flogging.MustGetContextLogger(util.LoggingStateModule).WithChannel(chainID),
+
 		// Channel to read new messages from
 		gossipChan: gossipChan,
 
@@ -194,24 +342,48 @@ func NewGossipCoordinatedStateProvider(chainID string, services *ServicesMediato
 		commChan: commChan,
 
 		// Create a queue for payload received
-		payloads: NewPayloadsBuffer(height),
+		payloads: payloads,
 
 		coordinator: coordinator,
 
 		stateResponseCh: make(chan proto.ReceivedMessage, defChannelBufferSize),
 
-		stateRequestCh: make(chan proto.ReceivedMessage, defChannelBufferSize),
+		stateRequestCh: make(chan *stateRequest, defChannelBufferSize),
 
 		stopCh: make(chan struct{}, 1),
 
+		ctx: ctx,
+
+		cancel: cancel,
+
+		stateRequestTimeout: int64(defStateRequestTimeout),
+
+		responseThrottle: newResponseThrottle(config.ResponseBandwidth),
+
+		heightQuorum: config.HeightQuorum,
+
+		commitRetry: config.CommitRetry.withDefaults(),
+
 		stateTransferActive: 0,
 
 		once: sync.Once{},
 	}
 
-	nodeMetastate := NewNodeMetastate(height - 1)
+	if support := config.StateDiff.Support; support != nil {
+		if checkpoint, err := support.StateCheckpoint(); err != nil {
+			s.logger.Warningf("Differential state sync is disabled, the state checkpoint cannot be read: %s", err)
+		} else {
+			s.stateDiff = newStateDiffSync(chainID, config.StateDiff)
+			if checkpoint < height {
+				s.logger.Infof("State database is at checkpoint %d, behind the ledger height %d", checkpoint, height)
+				s.stateDeferred = 1
+			}
+		}
+	}
+
+	nodeMetastate := s.nodeMetastate(height - 1)
 
-	logger.Infof("Updating node metadata information, "+
+	s.logger.Infof("Updating node metadata information, "+
 		"current ledger sequence is at = %d, next expected block is = %d", nodeMetastate.LedgerHeight, s.payloads.Next())
 
 	b, err := nodeMetastate.Bytes()
@@ -232,6 +404,11 @@ func NewGossipCoordinatedStateProvider(chainID string, services *ServicesMediato
 	go s.antiEntropy()
 	// Taking care of state request messages
 	go s.processStateRequests()
+	if config.BootstrapOffers {
+		s.done.Add(1)
+		// Offer the peers joining the channel to push them the blocks they miss
+		go s.offerBootstraps()
+	}
 
 	return s
 }
@@ -252,7 +429,7 @@ func (s *GossipStateProviderImpl) listen() {
 			go s.queueNewMessage(msg)
 		case msg := <-s.commChan:
 			logger.Debug("Direct message ", msg)
-			go s.directMessage(msg)
+			go s.directMessage(s.ctx, msg)
 		case <-s.stopCh:
 			s.stopCh <- struct{}{}
 			logger.Debug("Stop listening for new messages")
@@ -261,7 +438,9 @@ func (s *GossipStateProviderImpl) listen() {
 	}
 }
 
-func (s *GossipStateProviderImpl) directMessage(msg proto.ReceivedMessage) {
+// directMessage handles a message of a remote peer in the given context, the state
+// requests are served within the deadline of the state requests unless the peer disconnects
+func (s *GossipStateProviderImpl) directMessage(ctx context.Context, msg proto.ReceivedMessage) {
 	logger.Debug("[ENTER] -> directMessage")
 	defer logger.Debug("[EXIT] ->  directMessage")
 
@@ -282,15 +461,33 @@ func (s *GossipStateProviderImpl) directMessage(msg proto.ReceivedMessage) {
 		if len(s.stateRequestCh) < defChannelBufferSize {
 			// Forward state request to the channel, if there are too
 			// many message of state request ignore to avoid flooding.
-			s.stateRequestCh <- msg
+			reqCtx, cancel := s.requestContext(ctx, msg, time.Duration(atomic.LoadInt64(&s.stateRequestTimeout)))
+			s.stateRequestCh <- &stateRequest{ctx: reqCtx, cancel: cancel, msg: msg}
 		}
 	} else if incoming.GetStateResponse() != nil {
+		// The blocks requested along with the state differences are handled by the differential state sync
+		if s.handleStateDiffResponse(msg) {
+			return
+		}
+		// The blocks pushed by the peer whose bootstrap offer was
+		// accepted are handled regardless of the state transfer
+		if s.handleBootstrapPush(msg) {
+			return
+		}
 		// If no state transfer procedure activate there is
 		// no reason to process the message
 		if atomic.LoadInt32(&s.stateTransferActive) == 1 {
 			// Send signal of state response message
 			s.stateResponseCh <- msg
 		}
+	} else if incoming.GetStateDiffRequest() != nil {
+		s.handleStateDiffRequest(ctx, msg)
+	} else if incoming.GetStateDiffResponse() != nil {
+		s.handleStateDiffResponse(msg)
+	} else if incoming.GetStateBootstrapOffer() != nil {
+		s.handleBootstrapOffer(msg)
+	} else if incoming.GetStateBootstrapAccept() != nil {
+		s.handleBootstrapAccept(msg)
 	}
 }
 
@@ -299,8 +496,9 @@ func (s *GossipStateProviderImpl) processStateRequests() {
 
 	for {
 		select {
-		case msg := <-s.stateRequestCh:
-			s.handleStateRequest(msg)
+		case req := <-s.stateRequestCh:
+			s.handleStateRequest(req.ctx, req.msg)
+			req.cancel()
 		case <-s.stopCh:
 			s.stopCh <- struct{}{}
 			return
@@ -308,9 +506,38 @@ func (s *GossipStateProviderImpl) processStateRequests() {
 	}
 }
 
+// requestContext returns the context a request of a remote peer is served in, which
+// expires past the given timeout, or once the peer disconnects
+func (s *GossipStateProviderImpl) requestContext(ctx context.Context, msg proto.ReceivedMessage, timeout time.Duration) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	sm, isStreamMessage := msg.(streamMessage)
+	if !isStreamMessage {
+		return ctx, cancel
+	}
+	go func() {
+		select {
+		case <-sm.Context().Done():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, cancel
+}
+
+// SetStateRequestTimeout sets the deadline of serving the state requests
+// of other peers, the ledger reads of the requests are canceled past it
+func (s *GossipStateProviderImpl) SetStateRequestTimeout(timeout time.Duration) {
+	if timeout <= 0 {
+		timeout = defStateRequestTimeout
+	}
+	atomic.StoreInt64(&s.stateRequestTimeout, int64(timeout))
+	s.logger.Infof("State request timeout set to %v", timeout)
+}
+
 // Handle state request message, validate batch size, read current leader state to
-// obtain required blocks, build response message and send it back
-func (s *GossipStateProviderImpl) handleStateRequest(msg proto.ReceivedMessage) {
+// obtain required blocks, build response message and send it back. The request is
+// dropped once its context is done, as the requester doesn't wait for it anymore
+func (s *GossipStateProviderImpl) handleStateRequest(ctx context.Context, msg proto.ReceivedMessage) {
 	if msg == nil {
 		return
 	}
@@ -341,20 +568,83 @@ func (s *GossipStateProviderImpl) handleStateRequest(msg proto.ReceivedMessage)
 	endSeqNum := min(currentHeight, request.EndSeqNum)
 
 	response := &proto.RemoteStateResponse{Payloads: make([]*proto.Payload, 0)}
-	for seqNum := request.StartSeqNum; seqNum <= endSeqNum; seqNum++ {
-		logger.Debug("Reading block ", seqNum, " with private data from the coordinator service")
-		block, pvtData, err := s.coordinator.GetPvtDataAndBlockByNum(seqNum, nil)
-
+	// Read the blocks of the range sequentially rather than looking up each of them
+	itr, err := s.coordinator.GetBlocksWithPvtDataIterator(request.StartSeqNum, endSeqNum)
+	if err != nil {
+		logger.Errorf("Wasn't able to read blocks in range [%d...%d] from ledger, due to %s",
+			request.StartSeqNum, endSeqNum, err)
+	} else {
+		defer itr.Close()
+		payloads, err := s.readPayloads(ctx, itr)
+		if ctx.Err() != nil {
+			s.logger.Warningf("Dropping state request for blocks [%d...%d] of %s: %s",
+				request.StartSeqNum, endSeqNum, msg.GetConnectionInfo().Endpoint, ctx.Err())
+			return
+		}
 		if err != nil {
-			logger.Errorf("Wasn't able to read block with sequence number %d from ledger, "+
-				"due to %s skipping....", seqNum, err)
-			continue
+			logger.Errorf("Wasn't able to read blocks in range [%d...%d] from ledger, due to %s, "+
+				"responding with %d blocks", request.StartSeqNum, endSeqNum, err, len(payloads))
 		}
+		response.Payloads = append(response.Payloads, payloads...)
+	}
+	// Sending back response with missing blocks
+	s.sendStateResponse(ctx, msg, &proto.GossipMessage{
+		// Copy nonce field from the request, so it will be possible to match response
+		Nonce:   msg.GetGossipMessage().Nonce,
+		Tag:     proto.GossipMessage_CHAN_OR_ORG,
+		Channel: []byte(s.chainID),
+		Content: &proto.GossipMessage_StateResponse{response},
+	})
+}
+
+// sendStateResponse sends the response to the peer which requested it, paced within the bandwidth
+// caps of the state responses. The response is dropped if the caps don't let it be sent before the
+// request expires, as the requester doesn't wait for it anymore
+func (s *GossipStateProviderImpl) sendStateResponse(ctx context.Context, msg proto.ReceivedMessage, response *proto.GossipMessage) {
+	if s.responseThrottle == nil {
+		msg.Respond(response)
+		return
+	}
+	maxWait := time.Duration(atomic.LoadInt64(&s.stateRequestTimeout))
+	if deadline, hasDeadline := ctx.Deadline(); hasDeadline {
+		maxWait = deadline.Sub(time.Now())
+	}
+	connInfo := msg.GetConnectionInfo()
+	size := pb.Size(response)
+	wait, admitted := s.responseThrottle.admit(string(connInfo.ID), size, maxWait)
+	if !admitted {
+		s.logger.Warningf("Dropping state response of %d bytes to %s, it cannot be sent "+
+			"within the bandwidth cap of the state responses before the request expires", size, connInfo.Endpoint)
+		return
+	}
+	if wait == 0 {
+		msg.Respond(response)
+		return
+	}
+	logger.Debugf("Delaying state response of %d bytes to %s by %v", size, connInfo.Endpoint, wait)
+	go func() {
+		select {
+		case <-time.After(wait):
+			msg.Respond(response)
+		case <-s.ctx.Done():
+		}
+	}()
+}
 
+// readPayloads reads the blocks of the iterator along with their private data until it is
+// exhausted, and returns them as payloads. It stops at the first block that cannot be read
+func (s *GossipStateProviderImpl) readPayloads(ctx context.Context, itr BlocksWithPvtDataIterator) ([]*proto.Payload, error) {
+	var payloads []*proto.Payload
+	for {
+		block, pvtData, err := itr.Next(ctx)
+		if err != nil {
+			return payloads, err
+		}
 		if block == nil {
-			logger.Errorf("Wasn't able to read block with sequence number %d from ledger, skipping....", seqNum)
-			continue
+			return payloads, nil
 		}
+		seqNum := block.Header.Number
+		logger.Debug("Read block ", seqNum, " with private data from the coordinator service")
 
 		blockBytes, err := pb.Marshal(block)
 
@@ -378,42 +668,38 @@ func (s *GossipStateProviderImpl) handleStateRequest(msg proto.ReceivedMessage)
 		}
 
 		// Appending result to the response
-		response.Payloads = append(response.Payloads, &proto.Payload{
+		payloads = append(payloads, &proto.Payload{
 			SeqNum:      seqNum,
 			Data:        blockBytes,
 			PrivateData: pvtBytes,
 		})
 	}
-	// Sending back response with missing blocks
-	msg.Respond(&proto.GossipMessage{
-		// Copy nonce field from the request, so it will be possible to match response
-		Nonce:   msg.GetGossipMessage().Nonce,
-		Tag:     proto.GossipMessage_CHAN_OR_ORG,
-		Channel: []byte(s.chainID),
-		Content: &proto.GossipMessage_StateResponse{response},
-	})
 }
 
 func (s *GossipStateProviderImpl) handleStateResponse(msg proto.ReceivedMessage) (uint64, error) {
 	max := uint64(0)
 	// Send signal that response for given nonce has been received
 	response := msg.GetGossipMessage().GetStateResponse()
+	if quarantined := s.haltedAt(); quarantined != nil {
+		return uint64(0), fmt.Errorf("Channel is halted at block %d", quarantined.seqNum)
+	}
 	// Extract payloads, verify and push into buffer
 	if len(response.GetPayloads()) == 0 {
 		return uint64(0), errors.New("Received state transfer response without payload")
 	}
 	for _, payload := range response.GetPayloads() {
-		logger.Debugf("Received payload with sequence number %d.", payload.SeqNum)
+		s.logger.Debugf("Received payload with sequence number %d.", payload.SeqNum)
 		if err := s.mediator.VerifyBlock(common2.ChainID(s.chainID), payload.SeqNum, payload.Data); err != nil {
-			logger.Warningf("Error verifying block with sequence number %d, due to %s", payload.SeqNum, err)
+			s.logger.Warningf("Error verifying block with sequence number %d, due to %s", payload.SeqNum, err)
 			return uint64(0), err
 		}
+		s.blockVerified(payload.SeqNum)
 		if max < payload.SeqNum {
 			max = payload.SeqNum
 		}
 		err := s.payloads.Push(payload)
 		if err != nil {
-			logger.Warningf("Payload with sequence number %d was received earlier", payload.SeqNum)
+			s.logger.Warningf("Payload with sequence number %d was received earlier", payload.SeqNum)
 		}
 	}
 	return max, nil
@@ -425,10 +711,15 @@ func (s *GossipStateProviderImpl) Stop() {
 	// and stop channel won't be used again
 	s.once.Do(func() {
 		s.stopCh <- struct{}{}
+		// Cancel the state requests being served
+		s.cancel()
 		// Make sure all go-routines has finished
 		s.done.Wait()
 		// Close all resources
 		s.coordinator.Close()
+		if spilling, isSpilling := s.payloads.(*spillingPayloadsBuffer); isSpilling {
+			spilling.release()
+		}
 		close(s.stateRequestCh)
 		close(s.stateResponseCh)
 		close(s.stopCh)
@@ -445,6 +736,10 @@ func (s *GossipStateProviderImpl) queueNewMessage(msg *proto.GossipMessage) {
 
 	dataMsg := msg.GetDataMsg()
 	if dataMsg != nil {
+		// The blocks gossiped are verified by the gossip layer
+		if payload := dataMsg.GetPayload(); payload != nil {
+			s.blockVerified(payload.SeqNum)
+		}
 		if err := s.AddPayload(dataMsg.GetPayload()); err != nil {
 			logger.Warning("Failed adding payload:", err)
 			return
@@ -458,41 +753,47 @@ func (s *GossipStateProviderImpl) queueNewMessage(msg *proto.GossipMessage) {
 func (s *GossipStateProviderImpl) deliverPayloads() {
 	defer s.done.Done()
 
+	// The blocks cannot be committed until the state database is up to date
+	if !s.catchUpState() {
+		s.logger.Debugf("State provider has been stopped, finishing to push new blocks.")
+		return
+	}
+
 	for {
 		select {
 		// Wait for notification that next seq has arrived
 		case <-s.payloads.Ready():
-			logger.Debugf("Ready to transfer payloads to the ledger, next sequence number is = [%d]", s.payloads.Next())
+			s.logger.Debugf("Ready to transfer payloads to the ledger, next sequence number is = [%d]", s.payloads.Next())
 			// Collect all subsequent payloads
 			for payload := s.payloads.Pop(); payload != nil; payload = s.payloads.Pop() {
 				rawBlock := &common.Block{}
 				if err := pb.Unmarshal(payload.Data, rawBlock); err != nil {
-					logger.Errorf("Error getting block with seqNum = %d due to (%s)...dropping block", payload.SeqNum, err)
+					s.logger.Errorf("Error getting block with seqNum = %d due to (%s)...dropping block", payload.SeqNum, err)
 					continue
 				}
 				if rawBlock.Data == nil || rawBlock.Header == nil {
-					logger.Errorf("Block with claimed sequence %d has no header (%v) or data (%v)",
+					s.logger.Errorf("Block with claimed sequence %d has no header (%v) or data (%v)",
 						payload.SeqNum, rawBlock.Header, rawBlock.Data)
 					continue
 				}
-				logger.Debug("New block with claimed sequence number ", payload.SeqNum, " transactions num ", len(rawBlock.Data.Data))
+				s.logger.Debugf("New block with claimed sequence number %d, transactions num %d", payload.SeqNum, len(rawBlock.Data.Data))
 
 				// Read all private data into slice
-				pvt := make([]*PvtData, 0)
 				var p PvtDataCollections
 				err := p.Unmarshal(payload.PrivateData)
 				if err != nil {
-					logger.Errorf("Wasn't able to unmarshal private data for block seqNum = %d due to (%s)...dropping block", payload.SeqNum, err)
+					s.logger.Errorf("Wasn't able to unmarshal private data for block seqNum = %d due to (%s)...dropping block", payload.SeqNum, err)
 					continue
 				}
 
-				if err := s.commitBlock(rawBlock, pvt); err != nil {
-					logger.Panicf("Cannot commit block to the ledger due to %s", err)
+				if !s.commitWithRetry(rawBlock, p) {
+					// The channel is halted, or the provider stopped
+					return
 				}
 			}
 		case <-s.stopCh:
 			s.stopCh <- struct{}{}
-			logger.Debug("State provider has been stopped, finishing to push new blocks.")
+			s.logger.Debugf("State provider has been stopped, finishing to push new blocks.")
 			return
 		}
 	}
@@ -523,82 +824,69 @@ func (s *GossipStateProviderImpl) antiEntropy() {
 			if current-1 >= max {
 				continue
 			}
+			if s.haltedAt() != nil {
+				// No missing block can be committed past the quarantined one
+				continue
+			}
 
 			s.requestBlocksInRange(uint64(current), uint64(max))
 		}
 	}
 }
 
-// Iterate over all available peers and check advertised meta state to
-// find maximum available ledger height across peers
+// Iterate over all available peers and check advertised meta state to find maximum
+// available ledger height across peers, which a quorum of the peers advertise or a
+// verified block received backs, so that a single peer advertising a height it
+// doesn't have cannot make this peer request missing blocks endlessly
 func (s *GossipStateProviderImpl) maxAvailableLedgerHeight() uint64 {
-	max := uint64(0)
+	var heights []uint64
 	for _, p := range s.mediator.PeersOfChannel(common2.ChainID(s.chainID)) {
 		if nodeMetastate, err := FromBytes(p.Metadata); err == nil {
-			if max < nodeMetastate.LedgerHeight {
-				max = nodeMetastate.LedgerHeight
+			heights = append(heights, nodeMetastate.LedgerHeight)
+		}
+	}
+	return quorumHeight(heights, s.heightQuorum, atomic.LoadUint64(&s.verifiedSeqNum))
+}
+
+// quorumHeight returns the maximum of the given ledger heights which is advertised by
+// at least quorum peers, or by all of them if there are fewer, or which doesn't exceed
+// the sequence number of a verified block
+func quorumHeight(heights []uint64, quorum int, verifiedSeqNum uint64) uint64 {
+	sort.Slice(heights, func(i, j int) bool {
+		return heights[i] > heights[j]
+	})
+	if quorum > len(heights) {
+		quorum = len(heights)
+	}
+	for i, height := range heights {
+		// The height is advertised by the i+1 peers advertising heights as high as it
+		if i+1 >= quorum || height <= verifiedSeqNum {
+			if i > 0 {
+				logger.Debugf("Ignoring ledger height %d advertised by fewer than %d peers", heights[0], quorum)
 			}
+			return height
+		}
+	}
+	return 0
+}
+
+// blockVerified records the sequence number of a verified block received
+func (s *GossipStateProviderImpl) blockVerified(seqNum uint64) {
+	for {
+		verified := atomic.LoadUint64(&s.verifiedSeqNum)
+		if seqNum <= verified || atomic.CompareAndSwapUint64(&s.verifiedSeqNum, verified, seqNum) {
+			return
 		}
 	}
-	return max
 }
 
 // GetBlocksInRange capable to acquire blocks with sequence
-// numbers in the range [start...end].
+// numbers in the range [start...end], as the replication policy permits.
 func (s *GossipStateProviderImpl) requestBlocksInRange(start uint64, end uint64) {
 	atomic.StoreInt32(&s.stateTransferActive, 1)
 	defer atomic.StoreInt32(&s.stateTransferActive, 0)
 
-	for prev := start; prev <= end; {
-		next := min(end, prev+defAntiEntropyBatchSize)
-
-		gossipMsg := s.stateRequestMessage(prev, next)
-
-		responseReceived := false
-		tryCounts := 0
-
-		for !responseReceived {
-			if tryCounts > defAntiEntropyMaxRetries {
-				logger.Warningf("Wasn't  able to get blocks in range [%d...%d], after %d retries",
-					prev, next, tryCounts)
-				return
-			}
-			// Select peers to ask for blocks
-			peer, err := s.selectPeerToRequestFrom(next)
-			if err != nil {
-				logger.Warningf("Cannot send state request for blocks in range [%d...%d], due to",
-					prev, next, err)
-				return
-			}
-
-			logger.Debugf("State transfer, with peer %s, requesting blocks in range [%d...%d], "+
-				"for chainID %s", peer.Endpoint, prev, next, s.chainID)
-
-			s.mediator.Send(gossipMsg, peer)
-			tryCounts++
-
-			// Wait until timeout or response arrival
-			select {
-			case msg := <-s.stateResponseCh:
-				if msg.GetGossipMessage().Nonce != gossipMsg.Nonce {
-					continue
-				}
-				// Got corresponding response for state request, can continue
-				index, err := s.handleStateResponse(msg)
-				if err != nil {
-					logger.Warningf("Wasn't able to process state response for "+
-						"blocks [%d...%d], due to %s", prev, next, err)
-					continue
-				}
-				prev = index + 1
-				responseReceived = true
-			case <-time.After(defAntiEntropyStateResponseTimeout):
-			case <-s.stopCh:
-				s.stopCh <- struct{}{}
-				return
-			}
-		}
-	}
+	newRangeFetcher(s, s.replicationPolicy()).fetch(start, end)
 }
 
 // Generate state request message for given blocks in range [beginSeq...endSeq]
@@ -616,18 +904,42 @@ func (s *GossipStateProviderImpl) stateRequestMessage(beginSeq uint64, endSeq ui
 	}
 }
 
-// Select peer which has required blocks to ask missing blocks from
-func (s *GossipStateProviderImpl) selectPeerToRequestFrom(height uint64) (*comm.RemotePeer, error) {
-	// Filter peers which posses required range of missing blocks
-	peers := s.filterPeers(s.hasRequiredHeight(height))
+// Select peer which has required blocks to ask missing blocks from, preferring the peers which
+// serve private data when this peer stores it, and then the peers of the preferred organizations
+// of the replication policy if preferOrgs is set
+func (s *GossipStateProviderImpl) selectPeerToRequestFrom(height uint64, preferOrgs bool) (*comm.RemotePeer, error) {
+	policy := s.replicationPolicy()
+	for _, predicate := range s.blockSources(height) {
+		if preferOrgs {
+			if peer := policy.selectPreferredPeer(s, predicate); peer != nil {
+				return peer, nil
+			}
+		}
+		// Filter peers which posses required range of missing blocks
+		peers := s.filterPeers(predicate)
 
-	n := len(peers)
-	if n == 0 {
-		return nil, errors.New("there are no peers to ask for missing blocks from")
+		if n := len(peers); n > 0 {
+			// Select peers to ask for blocks
+			return peers[util.RandomInt(n)], nil
+		}
 	}
+	return nil, errors.New("there are no peers to ask for missing blocks from")
+}
 
-	// Select peers to ask for blocks
-	return peers[util.RandomInt(n)], nil
+// blockSources returns the predicates of the peers to ask for the missing blocks up to the
+// given height, in order of preference. When this peer stores private data, the peers which
+// advertise that they don't are only asked when no other peer has the blocks, as they
+// cannot serve the private data of the blocks
+func (s *GossipStateProviderImpl) blockSources(height uint64) []func(peer discovery.NetworkMember) bool {
+	hasRequiredHeight := s.hasRequiredHeight(height)
+	if atomic.LoadInt32(&s.pvtDataAvailable) == 0 {
+		return []func(peer discovery.NetworkMember) bool{hasRequiredHeight}
+	}
+	servesPvtData := func(peer discovery.NetworkMember) bool {
+		nodeMetadata, err := FromBytes(peer.Metadata)
+		return err == nil && nodeMetadata.mayServePvtData() && hasRequiredHeight(peer)
+	}
+	return []func(peer discovery.NetworkMember) bool{servesPvtData, hasRequiredHeight}
 }
 
 // filterPeers return list of peers which aligns the predicate provided
@@ -657,11 +969,55 @@ func (s *GossipStateProviderImpl) hasRequiredHeight(height uint64) func(peer dis
 	}
 }
 
+// SetPvtDataAvailable sets whether the peer stores the private data of
+// the channel, which it advertises to the other peers of the channel
+func (s *GossipStateProviderImpl) SetPvtDataAvailable(available bool) {
+	var flag int32
+	if available {
+		flag = 1
+	}
+	if atomic.SwapInt32(&s.pvtDataAvailable, flag) != flag {
+		s.advertiseMetastate()
+	}
+}
+
+// nodeMetastate returns the meta state advertised with the given ledger sequence
+func (s *GossipStateProviderImpl) nodeMetastate(seq uint64) *NodeMetastate {
+	nodeMetastate := NewNodeMetastate(seq)
+	if s.servesStateDiff() {
+		nodeMetastate.Capabilities |= StateDiffCapability
+	}
+	nodeMetastate.PvtDataAvailable = atomic.LoadInt32(&s.pvtDataAvailable) == 1
+	return nodeMetastate
+}
+
+// updateMetastate advertises the meta state with the given ledger sequence in the channel metadata
+func (s *GossipStateProviderImpl) updateMetastate(seq uint64) {
+	// Decode nodeMetastate to byte array
+	b, err := s.nodeMetastate(seq).Bytes()
+	if err != nil {
+		s.logger.Errorf("Unable to serialize node meta nodeMetastate, error = %s", err)
+		return
+	}
+	s.mediator.UpdateChannelMetadata(b, common2.ChainID(s.chainID))
+}
+
+// advertiseMetastate advertises the meta state with the current ledger
+// sequence, once the capabilities of the peer change
+func (s *GossipStateProviderImpl) advertiseMetastate() {
+	height, err := s.coordinator.LedgerHeight()
+	if err != nil || height == 0 {
+		s.logger.Errorf("Cannot advertise node meta state without the ledger height, error = %v", err)
+		return
+	}
+	s.updateMetastate(height - 1)
+}
+
 // GetBlock return ledger block given its sequence number as a parameter
 func (s *GossipStateProviderImpl) GetBlock(index uint64) *common.Block {
 	// Try to read missing block from the ledger, should return no nil with
 	// content including at least one block
-	if block, err := s.coordinator.GetBlockByNum(index); block != nil && err != nil {
+	if block, err := s.coordinator.GetBlockByNum(context.Background(), index); block != nil && err != nil {
 		return block
 	}
 
@@ -673,7 +1029,10 @@ func (s *GossipStateProviderImpl) AddPayload(payload *proto.Payload) error {
 	if payload == nil {
 		return errors.New("Given payload is nil")
 	}
-	logger.Debug("Adding new payload into the buffer, seqNum = ", payload.SeqNum)
+	if quarantined := s.haltedAt(); quarantined != nil {
+		return fmt.Errorf("Channel is halted at block %d, cannot enqueue block with sequence of %d", quarantined.seqNum, payload.SeqNum)
+	}
+	s.logger.Debugf("Adding new payload into the buffer, seqNum = %d", payload.SeqNum)
 	height, err := s.coordinator.LedgerHeight()
 	if err != nil {
 		return fmt.Errorf("Failed obtaining ledger height: %v", err)
@@ -686,29 +1045,57 @@ func (s *GossipStateProviderImpl) AddPayload(payload *proto.Payload) error {
 	return s.payloads.Push(payload)
 }
 
-func (s *GossipStateProviderImpl) commitBlock(block *common.Block, pvtData []*PvtData) error {
+func (s *GossipStateProviderImpl) commitBlock(block *common.Block, pvtData PvtDataCollections) error {
 
 	// Commit block with available private transactions
 	if _, err := s.coordinator.StoreBlock(block, pvtData); err != nil {
-		logger.Errorf("Got error while committing(%s)", err)
+		s.logger.Errorf("Got error while committing(%s)", err)
 		return err
 	}
 
-	// Update ledger level within node metadata
-	nodeMetastate := NewNodeMetastate(block.Header.Number)
-	// Decode nodeMetastate to byte array
-	b, err := nodeMetastate.Bytes()
-	if err == nil {
-		s.mediator.UpdateChannelMetadata(b, common2.ChainID(s.chainID))
-	} else {
+	s.blockCommitted(block, pvtData)
+	return nil
+}
 
-		logger.Errorf("Unable to serialize node meta nodeMetastate, error = %s", err)
-	}
+// blockCommitted updates the node metadata with the committed block and post-processes it
+func (s *GossipStateProviderImpl) blockCommitted(block *common.Block, pvtData PvtDataCollections) {
+	// Update ledger level within node metadata
+	s.updateMetastate(block.Header.Number)
+	// Acknowledge the blocks pushed by the peer whose bootstrap offer was accepted
+	s.ackBootstrapPush(block.Header.Number)
+	// Post-process the block by the hooks registered
+	s.runCommitHooks(block, pvtData)
+
+	s.logger.Debugf("Created block [%d] with %d transaction(s)",
+		block.Header.Number, len(block.Data.Data))
+}
 
-	logger.Debugf("Channel [%s]: Created block [%d] with %d transaction(s)",
-		s.chainID, block.Header.Number, len(block.Data.Data))
+// AddCommitHook registers a hook invoked with every block the provider commits,
+// once the block is committed to the ledger, in the order the hooks are added
+func (s *GossipStateProviderImpl) AddCommitHook(hook CommitHook) {
+	s.commitHooksLock.Lock()
+	defer s.commitHooksLock.Unlock()
+	hooks, _ := s.commitHooks.Load().([]CommitHook)
+	// Copy the hooks, as the commit loop may be reading them
+	s.commitHooks.Store(append(append([]CommitHook(nil), hooks...), hook))
+}
 
-	return nil
+// runCommitHooks invokes the commit hooks with the committed block, a hook
+// failing or panicking doesn't prevent the other hooks from running
+func (s *GossipStateProviderImpl) runCommitHooks(block *common.Block, pvtData PvtDataCollections) {
+	hooks, _ := s.commitHooks.Load().([]CommitHook)
+	for i, hook := range hooks {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					s.logger.Errorf("Commit hook %d panicked post-processing block %d: %v", i, block.Header.Number, r)
+				}
+			}()
+			if err := hook(block, pvtData); err != nil {
+				s.logger.Errorf("Commit hook %d failed post-processing block %d: %s", i, block.Header.Number, err)
+			}
+		}()
+	}
 }
 
 func min(a uint64, b uint64) uint64 {
@@ -17,6 +17,7 @@ import (
 
 	pb "github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric/common/configtx/test"
+	"github.com/hyperledger/fabric/common/flogging"
 	"github.com/hyperledger/fabric/common/util"
 	"github.com/hyperledger/fabric/core/committer"
 	"github.com/hyperledger/fabric/core/ledger"
@@ -36,6 +37,7 @@ import (
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"golang.org/x/net/context"
 )
 
 var (
@@ -168,6 +170,16 @@ func (mc *mockCommitter) Commit(block *pcomm.Block) error {
 	return nil
 }
 
+func (mc *mockCommitter) CommitWithPvtData(blockAndPvtData *ledger.BlockAndPvtData) error {
+	mc.Called(blockAndPvtData.Block)
+	return nil
+}
+
+func (mc *mockCommitter) RecommitLostBlock(block *pcomm.Block) error {
+	mc.Called(block)
+	return nil
+}
+
 func (mc *mockCommitter) LedgerHeight() (uint64, error) {
 	mc.Lock()
 	defer mc.Unlock()
@@ -265,13 +277,217 @@ func TestNilDirectMsg(t *testing.T) {
 	g.On("Accept", mock.Anything, true).Return(nil, make(<-chan proto.ReceivedMessage))
 	p := newPeerNodeWithGossip(newGossipConfig(0), mc, noopPeerIdentityAcceptor, g)
 	defer p.shutdown()
-	p.s.(*GossipStateProviderImpl).handleStateRequest(nil)
-	p.s.(*GossipStateProviderImpl).directMessage(nil)
+	p.s.(*GossipStateProviderImpl).handleStateRequest(context.Background(), nil)
+	p.s.(*GossipStateProviderImpl).directMessage(context.Background(), nil)
 	sMsg, _ := p.s.(*GossipStateProviderImpl).stateRequestMessage(uint64(10), uint64(8)).NoopSign()
 	req := &comm.ReceivedMessageImpl{
 		SignedGossipMessage: sMsg,
 	}
-	p.s.(*GossipStateProviderImpl).directMessage(req)
+	p.s.(*GossipStateProviderImpl).directMessage(context.Background(), req)
+}
+
+type streamMessageMock struct {
+	*receivedMessageMock
+	ctx context.Context
+}
+
+func (m *streamMessageMock) Context() context.Context {
+	return m.ctx
+}
+
+func TestStateRequestContext(t *testing.T) {
+	coord := new(coordinatorMock)
+	coord.On("LedgerHeight", mock.Anything).Return(uint64(10), nil)
+	coord.On("GetPvtDataAndBlockByNum", mock.Anything).Return(pcomm.NewBlock(1, []byte{}), PvtDataCollections{}, nil)
+	s := &GossipStateProviderImpl{
+		chainID:     "testchainid",
+		logger:      flogging.MustGetContextLogger(gutil.LoggingStateModule),
+		coordinator: coord,
+	}
+	s.SetStateRequestTimeout(0)
+	assert.Equal(t, defStateRequestTimeout, time.Duration(s.stateRequestTimeout))
+
+	sMsg, _ := (&proto.GossipMessage{
+		Channel: []byte("testchainid"),
+		Content: &proto.GossipMessage_StateRequest{StateRequest: &proto.RemoteStateRequest{StartSeqNum: 1, EndSeqNum: 3}},
+	}).NoopSign()
+	newRequest := func(streamCtx context.Context) *streamMessageMock {
+		msg := &streamMessageMock{receivedMessageMock: new(receivedMessageMock), ctx: streamCtx}
+		msg.On("GetGossipMessage").Return(sMsg)
+		msg.On("GetConnectionInfo").Return(&proto.ConnectionInfo{Endpoint: "peer1:7051"})
+		msg.On("Respond", mock.Anything)
+		return msg
+	}
+
+	// The request of a connected peer is served
+	msg := newRequest(context.Background())
+	ctx, cancel := s.requestContext(context.Background(), msg, time.Minute)
+	s.handleStateRequest(ctx, msg)
+	cancel()
+	msg.AssertNumberOfCalls(t, "Respond", 1)
+
+	// The request of a peer which disconnected is dropped without reading the ledger
+	streamCtx, disconnect := context.WithCancel(context.Background())
+	msg = newRequest(streamCtx)
+	ctx, cancel = s.requestContext(context.Background(), msg, time.Minute)
+	defer cancel()
+	disconnect()
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		assert.Fail(t, "Expected the request context to be done once the requester disconnected")
+	}
+	coord.Calls = nil
+	s.handleStateRequest(ctx, msg)
+	msg.AssertNotCalled(t, "Respond", mock.Anything)
+	coord.AssertNotCalled(t, "GetPvtDataAndBlockByNum", mock.Anything)
+
+	// The request expires past the state request timeout
+	s.SetStateRequestTimeout(50 * time.Millisecond)
+	msg = newRequest(context.Background())
+	ctx, cancel = s.requestContext(context.Background(), msg, time.Duration(s.stateRequestTimeout))
+	defer cancel()
+	<-ctx.Done()
+	assert.Equal(t, context.DeadlineExceeded, ctx.Err())
+	s.handleStateRequest(ctx, msg)
+	msg.AssertNotCalled(t, "Respond", mock.Anything)
+}
+
+func TestStateResponseThrottle(t *testing.T) {
+	coord := new(coordinatorMock)
+	coord.On("LedgerHeight", mock.Anything).Return(uint64(10), nil)
+	coord.On("GetPvtDataAndBlockByNum", mock.Anything).Return(pcomm.NewBlock(1, []byte{}), PvtDataCollections{}, nil)
+	stopCtx, stop := context.WithCancel(context.Background())
+	defer stop()
+	s := &GossipStateProviderImpl{
+		chainID:          "testchainid",
+		logger:           flogging.MustGetContextLogger(gutil.LoggingStateModule),
+		coordinator:      coord,
+		ctx:              stopCtx,
+		responseThrottle: newResponseThrottle(ResponseBandwidthConfig{PerPeerBytesPerSecond: 100}),
+	}
+
+	sMsg, _ := (&proto.GossipMessage{
+		Channel: []byte("testchainid"),
+		Content: &proto.GossipMessage_StateRequest{StateRequest: &proto.RemoteStateRequest{StartSeqNum: 1, EndSeqNum: 3}},
+	}).NoopSign()
+	responded := make(chan struct{}, 1)
+	msg := new(receivedMessageMock)
+	msg.On("GetGossipMessage").Return(sMsg)
+	msg.On("GetConnectionInfo").Return(&proto.ConnectionInfo{ID: common.PKIidType("peer1"), Endpoint: "peer1:7051"})
+	msg.On("Respond", mock.Anything).Run(func(mock.Arguments) {
+		responded <- struct{}{}
+	})
+	serve := func(timeout time.Duration) {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		s.handleStateRequest(ctx, msg)
+	}
+
+	// The first response is sent right away
+	serve(time.Minute)
+	select {
+	case <-responded:
+	default:
+		assert.Fail(t, "Expected the first state response to be sent right away")
+	}
+
+	// The next response cannot be sent within the cap before the request expires, and is dropped
+	serve(10 * time.Millisecond)
+	msg.AssertNumberOfCalls(t, "Respond", 1)
+
+	// The next response is delayed within the cap
+	serve(time.Minute)
+	msg.AssertNumberOfCalls(t, "Respond", 1)
+	select {
+	case <-responded:
+	case <-time.After(10 * time.Second):
+		assert.Fail(t, "Expected the delayed state response to be sent")
+	}
+}
+
+func TestCommitHooks(t *testing.T) {
+	coord := new(coordinatorMock)
+	coord.On("StoreBlock", mock.Anything, mock.Anything).Return([]string{}, nil)
+	s := &GossipStateProviderImpl{
+		chainID:     "testchainid",
+		logger:      flogging.MustGetContextLogger(gutil.LoggingStateModule),
+		coordinator: coord,
+		mediator:    &ServicesMediator{GossipAdapter: &mocks.GossipMock{}},
+	}
+
+	var invoked []string
+	pvtData := PvtDataCollections{&PvtData{Payload: &ledger.TxPvtData{SeqInBlock: 1}}}
+	s.AddCommitHook(func(block *pcomm.Block, data PvtDataCollections) error {
+		assert.Equal(t, uint64(5), block.Header.Number)
+		assert.Equal(t, pvtData, data)
+		invoked = append(invoked, "first")
+		return nil
+	})
+	s.AddCommitHook(func(block *pcomm.Block, data PvtDataCollections) error {
+		invoked = append(invoked, "failing")
+		return errors.New("failed indexing block")
+	})
+	s.AddCommitHook(func(block *pcomm.Block, data PvtDataCollections) error {
+		invoked = append(invoked, "panicking")
+		panic("nil index")
+	})
+	s.AddCommitHook(func(block *pcomm.Block, data PvtDataCollections) error {
+		invoked = append(invoked, "last")
+		return nil
+	})
+
+	// The hooks run in order once the block is committed, regardless of the failures of others
+	assert.NoError(t, s.commitBlock(pcomm.NewBlock(5, []byte{}), pvtData))
+	coord.AssertCalled(t, "StoreBlock", mock.Anything, mock.Anything)
+	assert.Equal(t, []string{"first", "failing", "panicking", "last"}, invoked)
+
+	// The hooks don't run when the block isn't committed
+	invoked = nil
+	coord = new(coordinatorMock)
+	coord.On("StoreBlock", mock.Anything, mock.Anything).Return([]string{}, errors.New("ledger unavailable"))
+	s.coordinator = coord
+	assert.Error(t, s.commitBlock(pcomm.NewBlock(6, []byte{}), nil))
+	assert.Empty(t, invoked)
+}
+
+func TestMaxAvailableLedgerHeightQuorum(t *testing.T) {
+	g := &mocks.GossipMock{}
+	var peers []discovery.NetworkMember
+	for i, height := range []uint64{1000, 20, 30, 30, 10} {
+		metadata, _ := NewNodeMetastate(height).Bytes()
+		peers = append(peers, discovery.NetworkMember{PKIid: common.PKIidType(fmt.Sprintf("p%d", i)), Metadata: metadata})
+	}
+	peers = append(peers, discovery.NetworkMember{PKIid: common.PKIidType("p5"), Metadata: []byte{1}})
+	g.On("PeersOfChannel", mock.Anything).Return(peers)
+	s := &GossipStateProviderImpl{
+		chainID:  "testchainid",
+		logger:   flogging.MustGetContextLogger(gutil.LoggingStateModule),
+		mediator: &ServicesMediator{GossipAdapter: g},
+	}
+
+	// Without a quorum, the maximum height advertised is trusted
+	assert.Equal(t, uint64(1000), s.maxAvailableLedgerHeight())
+	s.heightQuorum = 1
+	assert.Equal(t, uint64(1000), s.maxAvailableLedgerHeight())
+
+	// The height of a single peer is ignored in favor of the one advertised by the quorum
+	s.heightQuorum = 2
+	assert.Equal(t, uint64(30), s.maxAvailableLedgerHeight())
+	s.heightQuorum = 4
+	assert.Equal(t, uint64(20), s.maxAvailableLedgerHeight())
+
+	// All peers are required when there are fewer than the quorum
+	s.heightQuorum = 10
+	assert.Equal(t, uint64(10), s.maxAvailableLedgerHeight())
+
+	// Heights backed by a verified block are trusted
+	s.heightQuorum = 2
+	s.blockVerified(500)
+	s.blockVerified(100)
+	assert.Equal(t, uint64(30), s.maxAvailableLedgerHeight())
+	s.blockVerified(1000)
+	assert.Equal(t, uint64(1000), s.maxAvailableLedgerHeight())
 }
 
 func TestNilAddPayload(t *testing.T) {
@@ -878,12 +1094,18 @@ type coordinatorMock struct {
 	mock.Mock
 }
 
-func (mock *coordinatorMock) GetPvtDataAndBlockByNum(seqNum uint64, filter PvtDataFilter) (*pcomm.Block, PvtDataCollections, error) {
+func (mock *coordinatorMock) GetPvtDataAndBlockByNum(ctx context.Context, seqNum uint64, filter PvtDataFilter) (*pcomm.Block, PvtDataCollections, error) {
 	args := mock.Called(seqNum)
 	return args.Get(0).(*pcomm.Block), args.Get(1).(PvtDataCollections), args.Error(2)
 }
 
-func (mock *coordinatorMock) GetBlockByNum(seqNum uint64) (*pcomm.Block, error) {
+func (mock *coordinatorMock) GetBlocksWithPvtDataIterator(start uint64, end uint64) (BlocksWithPvtDataIterator, error) {
+	return newLookupBlocksIterator(start, end, func(ctx context.Context, seqNum uint64) (*pcomm.Block, PvtDataCollections, error) {
+		return mock.GetPvtDataAndBlockByNum(ctx, seqNum, nil)
+	}), nil
+}
+
+func (mock *coordinatorMock) GetBlockByNum(ctx context.Context, seqNum uint64) (*pcomm.Block, error) {
 	args := mock.Called(seqNum)
 	return args.Get(0).(*pcomm.Block), args.Error(1)
 }
@@ -893,6 +1115,11 @@ func (mock *coordinatorMock) StoreBlock(block *pcomm.Block, data ...PvtDataColle
 	return args.Get(0).([]string), args.Error(1)
 }
 
+func (mock *coordinatorMock) RecommitLostBlock(block *pcomm.Block) error {
+	args := mock.Called(block)
+	return args.Error(0)
+}
+
 func (mock *coordinatorMock) LedgerHeight() (uint64, error) {
 	args := mock.Called()
 	return args.Get(0).(uint64), args.Error(1)
@@ -925,6 +1152,9 @@ func (mock *receivedMessageMock) GetConnectionInfo() *proto.ConnectionInfo {
 	return args.Get(0).(*proto.ConnectionInfo)
 }
 
+func (mock *receivedMessageMock) Ack(err error) {
+}
+
 type testData struct {
 	block   *pcomm.Block
 	pvtData PvtDataCollections
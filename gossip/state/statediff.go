@@ -0,0 +1,677 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package state
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"hash"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	pb "github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/gossip/api"
+	"github.com/hyperledger/fabric/gossip/comm"
+	common2 "github.com/hyperledger/fabric/gossip/common"
+	"github.com/hyperledger/fabric/gossip/discovery"
+	"github.com/hyperledger/fabric/gossip/util"
+	"github.com/hyperledger/fabric/protos/common"
+	proto "github.com/hyperledger/fabric/protos/gossip"
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+)
+
+const (
+	defStateDiffResponseTimeout = 30 * time.Second
+	defStateDiffSyncTimeout     = 2 * time.Minute
+	defStateDiffPageSize        = 1000
+	// maxStateDiffPageSize caps the number of entries of a page, whichever page size is requested
+	maxStateDiffPageSize = 10000
+	// maxStateDiffPageBytes caps the size of the entries of a page, well below the
+	// message size the peers accept, unless the page has a single larger entry
+	maxStateDiffPageBytes = 8 * 1024 * 1024
+	// maxServedStateDiffs is the number of state differences, since distinct checkpoints,
+	// kept for the peers requesting their pages
+	maxServedStateDiffs = 4
+)
+
+// StateDiffSupport gives the differential state synchronization access to the ledger of
+// the channel, whose state database recovery may be deferred when the ledger is opened
+type StateDiffSupport interface {
+	// StateCheckpoint returns the height the state database of the ledger reflects,
+	// which is below the ledger height while the recovery of the state database is deferred
+	StateCheckpoint() (uint64, error)
+
+	// StateDiffSince returns the key-value pairs written or deleted in blocks
+	// at or above the given checkpoint height, and the ledger height they reflect
+	StateDiffSince(checkpoint uint64) (uint64, []*ledger.StateDiffEntry, error)
+
+	// CommitValidatedBlock adds a block validated by another peer to the
+	// ledger while the recovery of the state database is deferred
+	CommitValidatedBlock(blockAndPvtData *ledger.BlockAndPvtData) error
+
+	// ApplyStateDiff applies the given key-value pairs to the deferred state
+	// database, which is at the checkpoint height, and advances it to the given height
+	ApplyStateDiff(checkpoint uint64, height uint64, entries []*ledger.StateDiffEntry) error
+
+	// RecoverState brings the deferred state database up to date by replaying the blocks
+	RecoverState() error
+}
+
+// StateDiffPolicy returns nil if state differences may be
+// exchanged with the peer of the given identity, or an error otherwise
+type StateDiffPolicy func(peerIdentity api.PeerIdentityType) error
+
+// SameOrgStateDiffPolicy returns a StateDiffPolicy that permits
+// exchanging state differences only with peers of the given organization
+func SameOrgStateDiffPolicy(secAdv api.SecurityAdvisor, org api.OrgIdentityType) StateDiffPolicy {
+	return func(peerIdentity api.PeerIdentityType) error {
+		peerOrg := secAdv.OrgByPeerIdentity(peerIdentity)
+		if len(peerOrg) == 0 || !bytes.Equal(peerOrg, org) {
+			return errors.Errorf("peer is not in organization %s", string(org))
+		}
+		return nil
+	}
+}
+
+// StateDiffConfig configures the differential state synchronization of a channel.
+//
+// The served state differences are sorted and kept while their pages are requested, and their checksum,
+// which covers the channel, the checkpoint, the height and all the entries, is signed by the serving peer.
+// The requesting peer recomputes the checksum over all the pages, and checks the signature against the
+// identity of the serving peer, which the policy authorizes, before committing the blocks and applying
+// the state differences. The state differences applied are thus those the authorized peer vouched for,
+// whichever peers relayed them. The authorized peer is trusted to serve its own state, as it is trusted
+// to have validated the blocks it serves along with the state differences
+type StateDiffConfig struct {
+	// Support gives access to the ledger of the channel, the state
+	// differences are neither served nor requested if it is nil
+	Support StateDiffSupport
+
+	// Policy authorizes the peers state differences are exchanged with, the
+	// state differences are neither served nor requested if it is nil. A deferred
+	// state database is then brought up to date by replaying the blocks
+	Policy StateDiffPolicy
+
+	// Sign signs the checksum of the state differences served with the
+	// identity of this peer, the state differences are not served if it is nil
+	Sign func(message []byte) ([]byte, error)
+
+	// ResponseTimeout is the time to wait for a page of state differences,
+	// or for a block response, before trying another peer
+	ResponseTimeout time.Duration
+
+	// SyncTimeout is the time spent trying to sync the deferred state database by
+	// state differences before replaying the blocks instead
+	SyncTimeout time.Duration
+
+	// PageSize is the number of entries of the pages of state differences requested
+	PageSize int
+}
+
+type stateDiffSync struct {
+	StateDiffConfig
+	responses chan proto.ReceivedMessage
+	// Nonce of the request whose response is awaited, 0 if none
+	pending uint64
+	// Verifies the private data of the blocks fetched along with the state differences
+	verifier *pvtDataVerifier
+
+	servedLock sync.Mutex
+	// The state differences served, by their checkpoint
+	served map[uint64]*servedStateDiff
+}
+
+// servedStateDiff is the state differences since a checkpoint, whose pages are served to the peers
+type servedStateDiff struct {
+	checkpoint uint64
+	height     uint64
+	entries    []*proto.StateDiffEntry
+	checksum   []byte
+	signature  []byte
+	lastServed time.Time
+}
+
+func newStateDiffSync(chainID string, conf StateDiffConfig) *stateDiffSync {
+	if conf.ResponseTimeout <= 0 {
+		conf.ResponseTimeout = defStateDiffResponseTimeout
+	}
+	if conf.SyncTimeout <= 0 {
+		conf.SyncTimeout = defStateDiffSyncTimeout
+	}
+	if conf.PageSize <= 0 || conf.PageSize > maxStateDiffPageSize {
+		conf.PageSize = defStateDiffPageSize
+	}
+	return &stateDiffSync{
+		StateDiffConfig: conf,
+		responses:       make(chan proto.ReceivedMessage, defChannelBufferSize),
+		verifier:        newPvtDataVerifier(chainID, PvtDataVerificationStrict),
+		served:          make(map[uint64]*servedStateDiff),
+	}
+}
+
+// servesStateDiff returns whether the state differences are served to the authorized peers,
+// which requires the state database to be up to date with the ledger
+func (s *GossipStateProviderImpl) servesStateDiff() bool {
+	sds := s.stateDiff
+	return sds != nil && sds.Policy != nil && sds.Sign != nil && atomic.LoadInt32(&s.stateDeferred) == 0
+}
+
+// catchUpState brings the state database up to date with the ledger if its recovery was deferred when
+// the ledger was opened, by the state differences of an authorized peer if the policy is set, and else,
+// or if they cannot be synced within the sync timeout, by replaying the blocks. It returns false if the
+// provider stopped meanwhile
+func (s *GossipStateProviderImpl) catchUpState() bool {
+	sds := s.stateDiff
+	if sds == nil || atomic.LoadInt32(&s.stateDeferred) == 0 {
+		return true
+	}
+	if sds.Policy != nil {
+		deadline := time.Now().Add(sds.SyncTimeout)
+		for {
+			err := s.syncStateDiff(sds)
+			if err == nil {
+				s.stateCaughtUp()
+				return true
+			}
+			if s.ctx.Err() != nil {
+				return false
+			}
+			if !time.Now().Add(defAntiEntropyInterval).Before(deadline) {
+				s.logger.Warningf("Replaying the blocks to the state database, as syncing the state differences failed: %s", err)
+				break
+			}
+			s.logger.Warningf("Retrying to sync the state differences in %v, due to: %s", defAntiEntropyInterval, err)
+			if !s.sleep(defAntiEntropyInterval) {
+				return false
+			}
+		}
+	}
+	for {
+		err := sds.Support.RecoverState()
+		if err == nil {
+			s.stateCaughtUp()
+			return true
+		}
+		s.logger.Errorf("Failed replaying the blocks to the state database, retrying in %v: %s", defAntiEntropyInterval, err)
+		if !s.sleep(defAntiEntropyInterval) {
+			return false
+		}
+	}
+}
+
+// sleep waits for the given duration, it returns false if the provider stopped meanwhile
+func (s *GossipStateProviderImpl) sleep(d time.Duration) bool {
+	select {
+	case <-time.After(d):
+		return true
+	case <-s.ctx.Done():
+		return false
+	}
+}
+
+// stateCaughtUp ends the deferral of the state database, and lets the
+// other peers know that the state differences are served, if they are
+func (s *GossipStateProviderImpl) stateCaughtUp() {
+	atomic.StoreInt32(&s.stateDeferred, 0)
+	s.logger.Infof("State database is up to date with the ledger")
+	s.advertiseMetastate()
+}
+
+// syncStateDiff brings the deferred state database from its checkpoint up to date by fetching the
+// key-value pairs written since the checkpoint from an authorized peer, along with the blocks this
+// peer misses up to the height they reflect, instead of replaying the blocks since the checkpoint
+func (s *GossipStateProviderImpl) syncStateDiff(sds *stateDiffSync) error {
+	checkpoint, err := sds.Support.StateCheckpoint()
+	if err != nil {
+		return errors.WithMessage(err, "failed reading the state checkpoint")
+	}
+	height, err := s.coordinator.LedgerHeight()
+	if err != nil {
+		return errors.WithMessage(err, "failed reading the ledger height")
+	}
+	if checkpoint >= height {
+		return errors.Errorf("state database at checkpoint %d isn't behind the ledger height %d", checkpoint, height)
+	}
+
+	// The peers advertise the sequence of their last block
+	hasRequiredHeight := s.hasRequiredHeight(height - 1)
+	peers := s.filterPeers(func(peer discovery.NetworkMember) bool {
+		nodeMetadata, err := FromBytes(peer.Metadata)
+		return err == nil && nodeMetadata.HasCapability(StateDiffCapability) && hasRequiredHeight(peer)
+	})
+	if len(peers) == 0 {
+		return errors.Errorf("there are no peers serving state differences at height %d", height)
+	}
+
+	for i, idx := range util.GetRandomIndices(int(min(uint64(len(peers)), defAntiEntropyMaxRetries)), len(peers)-1) {
+		if i > 0 {
+			s.logger.Warningf("Retrying differential state sync from checkpoint %d due to: %s", checkpoint, err)
+		}
+		if err = s.syncStateDiffFrom(sds, peers[idx], checkpoint, height); err == nil || s.ctx.Err() != nil {
+			return err
+		}
+	}
+	return errors.WithMessage(err, "failed syncing state differences")
+}
+
+// syncStateDiffFrom syncs the state differences since the checkpoint from the given peer, and the
+// blocks from the given ledger height up to the height the state differences reflect
+func (s *GossipStateProviderImpl) syncStateDiffFrom(sds *stateDiffSync, peer *comm.RemotePeer, checkpoint uint64, height uint64) error {
+	diff, err := s.fetchStateDiff(sds, peer, checkpoint, height)
+	if err != nil {
+		return err
+	}
+
+	// The blocks the state differences cover are fetched from the same peer, whose validation of them is trusted
+	for next := height; next < diff.Height; {
+		end := min(next+defAntiEntropyBatchSize-1, diff.Height-1)
+		msg, err := s.exchange(sds, peer, s.stateRequestMessage(next, end))
+		if err != nil {
+			return err
+		}
+		payloads := msg.GetGossipMessage().GetStateResponse().GetPayloads()
+		if len(payloads) == 0 {
+			return errors.Errorf("%s sent no blocks in range [%d...%d]", peer.Endpoint, next, end)
+		}
+		for _, payload := range payloads {
+			if payload.SeqNum != next {
+				return errors.Errorf("%s sent block %d while block %d was expected", peer.Endpoint, payload.SeqNum, next)
+			}
+			if err := s.commitValidatedPayload(sds, payload); err != nil {
+				return errors.WithMessage(err, "failed committing block from "+peer.Endpoint)
+			}
+			next++
+		}
+	}
+
+	s.logger.Infof("Applying %d state differences for blocks [%d...%d)", len(diff.Entries), checkpoint, diff.Height)
+	return sds.Support.ApplyStateDiff(checkpoint, diff.Height, toLedgerEntries(diff.Entries))
+}
+
+// fetchStateDiff requests the pages of the state differences since the checkpoint from the given peer,
+// and returns them gathered once their checksum and the signature of the peer are verified
+func (s *GossipStateProviderImpl) fetchStateDiff(sds *stateDiffSync, peer *comm.RemotePeer, checkpoint uint64, height uint64) (*proto.StateDiffResponse, error) {
+	s.logger.Debugf("Requesting state differences since checkpoint %d from %s", checkpoint, peer.Endpoint)
+	var diff *proto.StateDiffResponse
+	var identity api.PeerIdentityType
+	for start := uint64(0); diff == nil || start < diff.Total; {
+		var pinnedHeight uint64
+		if diff != nil {
+			pinnedHeight = diff.Height
+		}
+		msg, err := s.exchange(sds, peer, &proto.GossipMessage{
+			Nonce:   util.RandomUInt64(),
+			Tag:     proto.GossipMessage_CHAN_OR_ORG,
+			Channel: []byte(s.chainID),
+			Content: &proto.GossipMessage_StateDiffRequest{
+				StateDiffRequest: &proto.StateDiffRequest{
+					Checkpoint: checkpoint,
+					Height:     pinnedHeight,
+					Start:      start,
+					PageSize:   uint32(sds.PageSize),
+				},
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		page := msg.GetGossipMessage().GetStateDiffResponse()
+		if err := verifyStateDiffPage(page, diff, checkpoint, height, start); err != nil {
+			return nil, errors.WithMessage(err, "invalid state difference response from "+peer.Endpoint)
+		}
+		if diff == nil {
+			identity = msg.GetConnectionInfo().Identity
+			diff = &proto.StateDiffResponse{
+				Checkpoint: page.Checkpoint,
+				Height:     page.Height,
+				Checksum:   page.Checksum,
+				Total:      page.Total,
+				Signature:  page.Signature,
+			}
+		} else if !bytes.Equal(identity, msg.GetConnectionInfo().Identity) {
+			return nil, errors.Errorf("%s changed its identity while serving state differences", peer.Endpoint)
+		}
+		diff.Entries = append(diff.Entries, page.Entries...)
+		start += uint64(len(page.Entries))
+	}
+
+	if !bytes.Equal(diff.Checksum, stateDiffChecksum(s.chainID, diff)) {
+		return nil, errors.Errorf("checksum mismatch of the state differences from %s", peer.Endpoint)
+	}
+	if err := s.mediator.VerifyByChannel(common2.ChainID(s.chainID), identity, diff.Signature, diff.Checksum); err != nil {
+		return nil, errors.WithMessage(err, "invalid signature of the state differences from "+peer.Endpoint)
+	}
+	return diff, nil
+}
+
+// exchange sends the request to the given peer, and waits for its response. The response
+// must come from a peer authorized by the policy, and is dropped otherwise
+func (s *GossipStateProviderImpl) exchange(sds *stateDiffSync, peer *comm.RemotePeer, request *proto.GossipMessage) (proto.ReceivedMessage, error) {
+	atomic.StoreUint64(&sds.pending, request.Nonce)
+	defer atomic.StoreUint64(&sds.pending, 0)
+	s.mediator.Send(request, peer)
+
+	timeout := time.After(sds.ResponseTimeout)
+	for {
+		select {
+		case msg := <-sds.responses:
+			if msg.GetGossipMessage().Nonce != request.Nonce {
+				continue
+			}
+			if err := sds.Policy(msg.GetConnectionInfo().Identity); err != nil {
+				s.logger.Warningf("Dropping response from %s: %s", msg.GetConnectionInfo().Endpoint, err)
+				continue
+			}
+			return msg, nil
+		case <-timeout:
+			return nil, errors.Errorf("timed out waiting for a response from %s", peer.Endpoint)
+		case <-s.ctx.Done():
+			return nil, errors.New("state provider is stopping")
+		}
+	}
+}
+
+// commitValidatedPayload commits the block of the payload, validated by the peer it was fetched
+// from, along with its private data, and advances the buffer of the payloads past it
+func (s *GossipStateProviderImpl) commitValidatedPayload(sds *stateDiffSync, payload *proto.Payload) error {
+	if err := s.mediator.VerifyBlock(common2.ChainID(s.chainID), payload.SeqNum, payload.Data); err != nil {
+		return errors.WithMessage(err, "failed verifying block")
+	}
+	block := &common.Block{}
+	if err := pb.Unmarshal(payload.Data, block); err != nil {
+		return errors.WithMessage(err, "failed unmarshaling block")
+	}
+	if block.Data == nil || block.Header == nil {
+		return errors.Errorf("block %d has no header or data", payload.SeqNum)
+	}
+	var pvtData PvtDataCollections
+	if err := pvtData.Unmarshal(payload.PrivateData); err != nil {
+		return errors.WithMessage(err, "failed unmarshaling private data")
+	}
+	verified, _, err := sds.verifier.verify(block, pvtData)
+	if err != nil {
+		return err
+	}
+	blockAndPvtData := &ledger.BlockAndPvtData{Block: block, BlockPvtData: make(map[uint64]*ledger.TxPvtData)}
+	for _, txPvtData := range verified {
+		seqInBlock := txPvtData.Payload.SeqInBlock
+		blockAndPvtData.BlockPvtData[seqInBlock] = addTxPvtData(blockAndPvtData.BlockPvtData[seqInBlock], txPvtData.Payload)
+	}
+	if err := sds.Support.CommitValidatedBlock(blockAndPvtData); err != nil {
+		return err
+	}
+	s.blockCommitted(block, verified)
+	// The payload may already be buffered, either way the buffer moves past it
+	s.payloads.Push(payload)
+	s.payloads.Pop()
+	return nil
+}
+
+// verifyStateDiffPage checks that the page starts at the given position of the state differences since the
+// checkpoint, and that they reflect at least the given ledger height. The pages after the first one must be of
+// the same state differences as the first one, which the given gathered state differences hold the fields of
+func verifyStateDiffPage(page *proto.StateDiffResponse, diff *proto.StateDiffResponse, checkpoint uint64, height uint64, start uint64) error {
+	if page == nil {
+		return errors.New("no state differences")
+	}
+	if page.Checkpoint != checkpoint {
+		return errors.Errorf("expected checkpoint %d, got %d", checkpoint, page.Checkpoint)
+	}
+	if diff == nil && (page.Height < height || page.Height <= checkpoint) {
+		return errors.Errorf("height %d is below the ledger height %d", page.Height, height)
+	}
+	if diff != nil && (page.Height != diff.Height || page.Total != diff.Total ||
+		!bytes.Equal(page.Checksum, diff.Checksum) || !bytes.Equal(page.Signature, diff.Signature)) {
+		return errors.Errorf("state differences at height %d changed to height %d while paging", diff.Height, page.Height)
+	}
+	if page.Start != start {
+		return errors.Errorf("expected page at %d, got page at %d", start, page.Start)
+	}
+	if len(page.Entries) == 0 && start < page.Total || start+uint64(len(page.Entries)) > page.Total {
+		return errors.Errorf("page at %d has %d of the %d entries", start, len(page.Entries), page.Total)
+	}
+	return nil
+}
+
+// handleStateDiffRequest responds to an authorized peer with a page of
+// the state differences since the checkpoint it asked for, unless
+// the peer disconnects or stops waiting for them meanwhile
+func (s *GossipStateProviderImpl) handleStateDiffRequest(ctx context.Context, msg proto.ReceivedMessage) {
+	if !s.servesStateDiff() {
+		logger.Debug("State differences aren't served, ignoring state difference request")
+		return
+	}
+	sds := s.stateDiff
+	connInfo := msg.GetConnectionInfo()
+	if err := sds.Policy(connInfo.Identity); err != nil {
+		s.logger.Warningf("Refusing state difference request from %s: %s", connInfo.Endpoint, err)
+		return
+	}
+	// the requester waits for the response as long as this peer would
+	ctx, cancel := s.requestContext(ctx, msg, sds.ResponseTimeout)
+	defer cancel()
+	request := msg.GetGossipMessage().GetStateDiffRequest()
+	served, err := s.servedStateDiff(sds, request.Checkpoint, request.Height)
+	if err != nil {
+		s.logger.Errorf("Failed computing state differences since checkpoint %d: %s", request.Checkpoint, err)
+		return
+	}
+	if ctx.Err() != nil {
+		s.logger.Warningf("Dropping state difference request of %s: %s", connInfo.Endpoint, ctx.Err())
+		return
+	}
+	msg.Respond(&proto.GossipMessage{
+		// Copy nonce field from the request, so it will be possible to match response
+		Nonce:   msg.GetGossipMessage().Nonce,
+		Tag:     proto.GossipMessage_CHAN_OR_ORG,
+		Channel: []byte(s.chainID),
+		Content: &proto.GossipMessage_StateDiffResponse{
+			StateDiffResponse: served.page(request.Start, int(request.PageSize)),
+		},
+	})
+}
+
+// servedStateDiff returns the state differences since the checkpoint whose pages are served. The first page,
+// asked at height 0, is of the state differences up to the current height, and the next ones are of the state
+// differences at the height asked, which are kept while they are paged. If they are gone, the state differences
+// up to the current height are served instead, and the requesting peer starts over
+func (s *GossipStateProviderImpl) servedStateDiff(sds *stateDiffSync, checkpoint uint64, height uint64) (*servedStateDiff, error) {
+	sds.servedLock.Lock()
+	defer sds.servedLock.Unlock()
+	now := time.Now()
+	for cp, served := range sds.served {
+		if now.Sub(served.lastServed) > sds.SyncTimeout {
+			delete(sds.served, cp)
+		}
+	}
+
+	served, exists := sds.served[checkpoint]
+	if exists && height == 0 {
+		// the state differences kept are served anew unless blocks were committed since
+		stateHeight, err := sds.Support.StateCheckpoint()
+		if err != nil {
+			return nil, err
+		}
+		height = stateHeight
+	}
+	if !exists || served.height != height {
+		diffHeight, entries, err := sds.Support.StateDiffSince(checkpoint)
+		if err != nil {
+			return nil, err
+		}
+		served = &servedStateDiff{checkpoint: checkpoint, height: diffHeight, entries: toProtoEntries(entries)}
+		sortStateDiffEntries(served.entries)
+		served.checksum = stateDiffChecksum(s.chainID, &proto.StateDiffResponse{
+			Checkpoint: checkpoint,
+			Height:     diffHeight,
+			Entries:    served.entries,
+		})
+		if served.signature, err = sds.Sign(served.checksum); err != nil {
+			return nil, errors.WithMessage(err, "failed signing state differences")
+		}
+		sds.keepServed(served)
+	}
+	served.lastServed = now
+	return served, nil
+}
+
+// keepServed keeps the given state differences for the peers requesting their pages, while
+// dropping the state differences served the longest ago if too many are kept
+func (sds *stateDiffSync) keepServed(served *servedStateDiff) {
+	sds.served[served.checkpoint] = served
+	for len(sds.served) > maxServedStateDiffs {
+		var oldest *servedStateDiff
+		for _, kept := range sds.served {
+			if oldest == nil || kept.lastServed.Before(oldest.lastServed) {
+				oldest = kept
+			}
+		}
+		delete(sds.served, oldest.checkpoint)
+	}
+}
+
+// page returns the page of up to pageSize entries, and up to maxStateDiffPageBytes, at the given position
+func (served *servedStateDiff) page(start uint64, pageSize int) *proto.StateDiffResponse {
+	if pageSize <= 0 || pageSize > maxStateDiffPageSize {
+		pageSize = defStateDiffPageSize
+	}
+	response := &proto.StateDiffResponse{
+		Checkpoint: served.checkpoint,
+		Height:     served.height,
+		Checksum:   served.checksum,
+		Start:      start,
+		Total:      uint64(len(served.entries)),
+		Signature:  served.signature,
+	}
+	size := 0
+	for i := start; i < uint64(len(served.entries)) && len(response.Entries) < pageSize; i++ {
+		size += pb.Size(served.entries[i])
+		if len(response.Entries) > 0 && size > maxStateDiffPageBytes {
+			break
+		}
+		response.Entries = append(response.Entries, served.entries[i])
+	}
+	return response
+}
+
+// sortStateDiffEntries sorts the entries by namespace, collection, key and key hash,
+// so that the pages of the same state differences are the same once computed anew
+func sortStateDiffEntries(entries []*proto.StateDiffEntry) {
+	sort.Slice(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.Collection != b.Collection {
+			return a.Collection < b.Collection
+		}
+		if a.Key != b.Key {
+			return a.Key < b.Key
+		}
+		return bytes.Compare(a.KeyHash, b.KeyHash) < 0
+	})
+}
+
+// handleStateDiffResponse hands the response to the differential state sync if it awaits
+// it, and returns whether it did. The state responses carrying the blocks the state
+// differences cover are matched by their nonce as well
+func (s *GossipStateProviderImpl) handleStateDiffResponse(msg proto.ReceivedMessage) bool {
+	sds := s.stateDiff
+	if sds == nil {
+		return false
+	}
+	pending := atomic.LoadUint64(&sds.pending)
+	if pending == 0 || msg.GetGossipMessage().Nonce != pending {
+		return false
+	}
+	select {
+	case sds.responses <- msg:
+	default:
+		logger.Warning("Dropping state difference response, too many responses are pending")
+	}
+	return true
+}
+
+// toProtoEntries converts the state differences read from the ledger to the entries of a response
+func toProtoEntries(entries []*ledger.StateDiffEntry) []*proto.StateDiffEntry {
+	protoEntries := make([]*proto.StateDiffEntry, len(entries))
+	for i, entry := range entries {
+		protoEntries[i] = &proto.StateDiffEntry{
+			Namespace:  entry.Namespace,
+			Collection: entry.Collection,
+			Key:        entry.Key,
+			KeyHash:    entry.KeyHash,
+			Value:      entry.Value,
+			Metadata:   entry.Metadata,
+			IsDelete:   entry.IsDelete,
+			BlockNum:   entry.BlockNum,
+			TxNum:      entry.TxNum,
+		}
+	}
+	return protoEntries
+}
+
+// toLedgerEntries converts the entries of a response to the state differences applied to the ledger
+func toLedgerEntries(protoEntries []*proto.StateDiffEntry) []*ledger.StateDiffEntry {
+	entries := make([]*ledger.StateDiffEntry, len(protoEntries))
+	for i, entry := range protoEntries {
+		entries[i] = &ledger.StateDiffEntry{
+			Namespace:  entry.Namespace,
+			Collection: entry.Collection,
+			Key:        entry.Key,
+			KeyHash:    entry.KeyHash,
+			Value:      entry.Value,
+			Metadata:   entry.Metadata,
+			IsDelete:   entry.IsDelete,
+			BlockNum:   entry.BlockNum,
+			TxNum:      entry.TxNum,
+		}
+	}
+	return entries
+}
+
+// stateDiffChecksum computes the checksum of the given state differences of the channel,
+// over all their entries, while ignoring the checksum and the signature they already carry
+func stateDiffChecksum(chainID string, response *proto.StateDiffResponse) []byte {
+	h := sha256.New()
+	writeBytes(h, []byte(chainID))
+	writeUint64(h, response.Checkpoint)
+	writeUint64(h, response.Height)
+	writeUint64(h, uint64(len(response.Entries)))
+	for _, entry := range response.Entries {
+		writeBytes(h, []byte(entry.Namespace))
+		writeBytes(h, []byte(entry.Collection))
+		writeBytes(h, []byte(entry.Key))
+		writeBytes(h, entry.KeyHash)
+		writeBytes(h, entry.Value)
+		writeBytes(h, entry.Metadata)
+		if entry.IsDelete {
+			h.Write([]byte{1})
+		} else {
+			h.Write([]byte{0})
+		}
+		writeUint64(h, entry.BlockNum)
+		writeUint64(h, entry.TxNum)
+	}
+	return h.Sum(nil)
+}
+
+func writeUint64(h hash.Hash, n uint64) {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, n)
+	h.Write(b)
+}
+
+func writeBytes(h hash.Hash, b []byte) {
+	writeUint64(h, uint64(len(b)))
+	h.Write(b)
+}
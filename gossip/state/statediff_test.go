@@ -0,0 +1,459 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package state
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/gossip/api"
+	"github.com/hyperledger/fabric/gossip/common"
+	"github.com/hyperledger/fabric/gossip/discovery"
+	"github.com/hyperledger/fabric/gossip/state/mocks"
+	pcomm "github.com/hyperledger/fabric/protos/common"
+	proto "github.com/hyperledger/fabric/protos/gossip"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type stateDiffSupportMock struct {
+	mock.Mock
+}
+
+func (mock *stateDiffSupportMock) StateCheckpoint() (uint64, error) {
+	args := mock.Called()
+	return args.Get(0).(uint64), args.Error(1)
+}
+
+func (mock *stateDiffSupportMock) StateDiffSince(checkpoint uint64) (uint64, []*ledger.StateDiffEntry, error) {
+	args := mock.Called(checkpoint)
+	return args.Get(0).(uint64), args.Get(1).([]*ledger.StateDiffEntry), args.Error(2)
+}
+
+func (mock *stateDiffSupportMock) CommitValidatedBlock(blockAndPvtData *ledger.BlockAndPvtData) error {
+	return mock.Called(blockAndPvtData.Block.Header.Number).Error(0)
+}
+
+func (mock *stateDiffSupportMock) ApplyStateDiff(checkpoint uint64, height uint64, entries []*ledger.StateDiffEntry) error {
+	return mock.Called(checkpoint, height, entries).Error(0)
+}
+
+func (mock *stateDiffSupportMock) RecoverState() error {
+	return mock.Called().Error(0)
+}
+
+type secAdvMock map[string]string
+
+func (sa secAdvMock) OrgByPeerIdentity(identity api.PeerIdentityType) api.OrgIdentityType {
+	return api.OrgIdentityType(sa[string(identity)])
+}
+
+// stateDiffCryptoMock verifies the signatures made by signerOf
+type stateDiffCryptoMock struct {
+	cryptoServiceMock
+}
+
+func (*stateDiffCryptoMock) VerifyByChannel(_ common.ChainID, identity api.PeerIdentityType, signature, message []byte) error {
+	if !bytes.Equal(signature, append([]byte(identity), message...)) {
+		return errors.New("invalid signature")
+	}
+	return nil
+}
+
+// signerOf returns a signing function of the given identity whose signatures stateDiffCryptoMock verifies
+func signerOf(identity string) func([]byte) ([]byte, error) {
+	return func(message []byte) ([]byte, error) {
+		return append([]byte(identity), message...), nil
+	}
+}
+
+// stateDiffPeer configures a peer of newStateDiffPeers
+type stateDiffPeer struct {
+	identity string
+	height   uint64
+	config   StateDiffConfig
+}
+
+// newStateDiffPeers creates a requesting and a responding state provider, and routes the messages the
+// requester sends to the responder, which sees the requester under its identity, while the requester sees
+// the responder under the responder identity. The responder serves the blocks below its height, and the
+// peers sign with their identity unless their configuration sets another signing function
+func newStateDiffPeers(t *testing.T, requester, responder stateDiffPeer, mutateResponse func(*proto.GossipMessage)) (*GossipStateProviderImpl, *GossipStateProviderImpl) {
+	chainID := "testChainID"
+	for _, peer := range []*stateDiffPeer{&requester, &responder} {
+		if peer.config.Sign == nil {
+			peer.config.Sign = signerOf(peer.identity)
+		}
+	}
+	requesterComm := make(chan proto.ReceivedMessage)
+	responderComm := make(chan proto.ReceivedMessage)
+	requesterGossip, responderGossip := &mocks.GossipMock{}, &mocks.GossipMock{}
+	requesterCoord, responderCoord := new(coordinatorMock), new(coordinatorMock)
+
+	for g, comm := range map[*mocks.GossipMock]chan proto.ReceivedMessage{requesterGossip: requesterComm, responderGossip: responderComm} {
+		g.On("Accept", mock.Anything, false).Return(make(<-chan *proto.GossipMessage), nil)
+		g.On("Accept", mock.Anything, true).Return(nil, (<-chan proto.ReceivedMessage)(comm))
+		g.On("UpdateChannelMetadata", mock.Anything, mock.Anything)
+	}
+	requesterCoord.On("LedgerHeight").Return(requester.height, nil)
+	responderCoord.On("LedgerHeight").Return(responder.height, nil)
+	for seqNum := uint64(0); seqNum < responder.height; seqNum++ {
+		responderCoord.On("GetPvtDataAndBlockByNum", seqNum).Return(pcomm.NewBlock(seqNum, []byte{}), PvtDataCollections{}, nil)
+	}
+	for _, coord := range []*coordinatorMock{requesterCoord, responderCoord} {
+		coord.On("Close")
+	}
+
+	metaBytes, err := (&NodeMetastate{LedgerHeight: responder.height - 1, Capabilities: StateDiffCapability}).Bytes()
+	assert.NoError(t, err)
+	requesterGossip.On("PeersOfChannel", mock.Anything).Return([]discovery.NetworkMember{{
+		PKIid:    common.PKIidType([]byte{1}),
+		Endpoint: "responder:7051",
+		Metadata: metaBytes,
+	}})
+	responderGossip.On("PeersOfChannel", mock.Anything).Return([]discovery.NetworkMember{})
+
+	requesterGossip.On("Send", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		request, _ := args.Get(0).(*proto.GossipMessage).NoopSign()
+		requestMsg := new(receivedMessageMock)
+		requestMsg.On("GetGossipMessage").Return(request)
+		requestMsg.On("GetConnectionInfo").Return(&proto.ConnectionInfo{ID: common.PKIidType("requester"), Identity: api.PeerIdentityType(requester.identity)})
+		requestMsg.On("Respond", mock.Anything).Run(func(args mock.Arguments) {
+			response := args.Get(0).(*proto.GossipMessage)
+			if mutateResponse != nil {
+				mutateResponse(response)
+			}
+			responseMsg := new(receivedMessageMock)
+			signedResponse, _ := response.NoopSign()
+			responseMsg.On("GetGossipMessage").Return(signedResponse)
+			responseMsg.On("GetConnectionInfo").Return(&proto.ConnectionInfo{Identity: api.PeerIdentityType(responder.identity)})
+			requesterComm <- responseMsg
+		})
+		responderComm <- requestMsg
+	})
+
+	cryptoService := &stateDiffCryptoMock{cryptoServiceMock{acceptor: noopPeerIdentityAcceptor}}
+	responderState := NewGossipStateProviderWithConfig(chainID, &ServicesMediator{GossipAdapter: responderGossip, MCSAdapter: cryptoService},
+		responderCoord, ProviderConfig{StateDiff: responder.config})
+	requesterState := NewGossipStateProviderWithConfig(chainID, &ServicesMediator{GossipAdapter: requesterGossip, MCSAdapter: cryptoService},
+		requesterCoord, ProviderConfig{StateDiff: requester.config})
+	return requesterState.(*GossipStateProviderImpl), responderState.(*GossipStateProviderImpl)
+}
+
+// waitForCall returns a channel closed once the mock call is made
+func waitForCall(call *mock.Call) chan struct{} {
+	called := make(chan struct{})
+	call.Run(func(mock.Arguments) { close(called) })
+	return called
+}
+
+func assertCalled(t *testing.T, called chan struct{}) {
+	select {
+	case <-called:
+	case <-time.After(10 * time.Second):
+		t.Fatal("Timed out waiting for the state database to catch up")
+	}
+}
+
+func TestSameOrgStateDiffPolicy(t *testing.T) {
+	policy := SameOrgStateDiffPolicy(secAdvMock{"p1": "ORG1", "p2": "ORG2"}, api.OrgIdentityType("ORG1"))
+	assert.NoError(t, policy(api.PeerIdentityType("p1")))
+	assert.Error(t, policy(api.PeerIdentityType("p2")))
+	assert.Error(t, policy(api.PeerIdentityType("unknown")))
+}
+
+func TestStateDiffChecksum(t *testing.T) {
+	newResponse := func() *proto.StateDiffResponse {
+		return &proto.StateDiffResponse{
+			Checkpoint: 3,
+			Height:     5,
+			Entries: []*proto.StateDiffEntry{
+				{Namespace: "ns", Key: "k1", Value: []byte{1}, Metadata: []byte{2}, BlockNum: 3},
+				{Namespace: "ns", Key: "k2", IsDelete: true, BlockNum: 4, TxNum: 1},
+				{Namespace: "ns", Collection: "coll", KeyHash: []byte{3}, Value: []byte{4}, BlockNum: 4},
+			},
+		}
+	}
+	response := newResponse()
+	checksum := stateDiffChecksum("testChainID", response)
+	response.Checksum, response.Signature, response.Start, response.Total = checksum, []byte{6}, 1, 3
+	assert.Equal(t, checksum, stateDiffChecksum("testChainID", response), "checksum should not depend on the page and its signature")
+	assert.NotEqual(t, checksum, stateDiffChecksum("otherChainID", newResponse()))
+
+	for _, mutate := range []func(*proto.StateDiffResponse){
+		func(r *proto.StateDiffResponse) { r.Entries[1].IsDelete = false },
+		func(r *proto.StateDiffResponse) { r.Entries[0].Metadata = nil },
+		func(r *proto.StateDiffResponse) { r.Entries[2].Collection = "other" },
+		func(r *proto.StateDiffResponse) { r.Entries[2].KeyHash = []byte{5} },
+		func(r *proto.StateDiffResponse) { r.Entries = r.Entries[1:] },
+		// Moving bytes between adjacent fields changes the checksum
+		func(r *proto.StateDiffResponse) { r.Entries[0].Namespace, r.Entries[0].Key = "nsk", "1" },
+	} {
+		response := newResponse()
+		mutate(response)
+		assert.NotEqual(t, checksum, stateDiffChecksum("testChainID", response))
+	}
+}
+
+func TestVerifyStateDiffPage(t *testing.T) {
+	diff := &proto.StateDiffResponse{Checkpoint: 3, Height: 7, Checksum: []byte{1}, Total: 3, Signature: []byte{2}}
+	newPage := func(start uint64, entries int) *proto.StateDiffResponse {
+		page := *diff
+		page.Start = start
+		for i := 0; i < entries; i++ {
+			page.Entries = append(page.Entries, &proto.StateDiffEntry{Namespace: "ns", Key: "k"})
+		}
+		return &page
+	}
+	assert.NoError(t, verifyStateDiffPage(newPage(0, 2), nil, 3, 5, 0))
+	assert.NoError(t, verifyStateDiffPage(newPage(2, 1), diff, 3, 5, 2))
+	empty := newPage(0, 0)
+	empty.Total = 0
+	assert.NoError(t, verifyStateDiffPage(empty, nil, 3, 5, 0))
+
+	for name, test := range map[string]struct {
+		page  *proto.StateDiffResponse
+		diff  *proto.StateDiffResponse
+		start uint64
+	}{
+		"no page":               {},
+		"other checkpoint":      {page: func() *proto.StateDiffResponse { p := newPage(0, 1); p.Checkpoint = 2; return p }()},
+		"below ledger height":   {page: func() *proto.StateDiffResponse { p := newPage(0, 1); p.Height = 4; return p }()},
+		"other position":        {page: newPage(1, 1)},
+		"no progress":           {page: newPage(2, 0), diff: diff, start: 2},
+		"beyond total":          {page: newPage(2, 2), diff: diff, start: 2},
+		"height moved":          {page: func() *proto.StateDiffResponse { p := newPage(2, 1); p.Height = 8; return p }(), diff: diff, start: 2},
+		"checksum changed":      {page: func() *proto.StateDiffResponse { p := newPage(2, 1); p.Checksum = []byte{3}; return p }(), diff: diff, start: 2},
+		"signature changed":     {page: func() *proto.StateDiffResponse { p := newPage(2, 1); p.Signature = []byte{3}; return p }(), diff: diff, start: 2},
+		"total entries changed": {page: func() *proto.StateDiffResponse { p := newPage(2, 1); p.Total = 4; return p }(), diff: diff, start: 2},
+	} {
+		assert.Error(t, verifyStateDiffPage(test.page, test.diff, 3, 5, test.start), name)
+	}
+}
+
+func TestServedStateDiffPage(t *testing.T) {
+	served := &servedStateDiff{checkpoint: 3, height: 7, checksum: []byte{1}, signature: []byte{2}}
+	for _, key := range []string{"a", "b", "c"} {
+		served.entries = append(served.entries, &proto.StateDiffEntry{Namespace: "ns", Key: key, Value: []byte(key)})
+	}
+	page := served.page(1, 1)
+	assert.Equal(t, served.entries[1:2], page.Entries)
+	assert.Equal(t, uint64(1), page.Start)
+	assert.Equal(t, uint64(3), page.Total)
+	assert.Equal(t, served.checksum, page.Checksum)
+	assert.Equal(t, served.signature, page.Signature)
+	assert.Equal(t, served.entries, served.page(0, 0).Entries)
+	assert.Empty(t, served.page(3, 10).Entries)
+
+	// the pages are capped in bytes as well, while holding at least one entry
+	large := make([]byte, maxStateDiffPageBytes/2+1)
+	served.entries = []*proto.StateDiffEntry{{Namespace: "ns", Key: "a", Value: large}, {Namespace: "ns", Key: "b", Value: large}}
+	assert.Len(t, served.page(0, 10).Entries, 1)
+	assert.Len(t, served.page(1, 10).Entries, 1)
+}
+
+func TestSortStateDiffEntries(t *testing.T) {
+	entries := []*proto.StateDiffEntry{
+		{Namespace: "ns2", Key: "a"},
+		{Namespace: "ns1", Collection: "coll", KeyHash: []byte{2}},
+		{Namespace: "ns1", Collection: "coll", KeyHash: []byte{1}},
+		{Namespace: "ns1", Key: "b"},
+		{Namespace: "ns1", Collection: "coll", Key: "a"},
+	}
+	sortStateDiffEntries(entries)
+	assert.Equal(t, []*proto.StateDiffEntry{
+		{Namespace: "ns1", Key: "b"},
+		{Namespace: "ns1", Collection: "coll", KeyHash: []byte{1}},
+		{Namespace: "ns1", Collection: "coll", KeyHash: []byte{2}},
+		{Namespace: "ns1", Collection: "coll", Key: "a"},
+		{Namespace: "ns2", Key: "a"},
+	}, entries)
+}
+
+func TestStateDiffEntriesConversion(t *testing.T) {
+	entries := []*ledger.StateDiffEntry{
+		{Namespace: "mycc", Key: "a", Value: []byte("100"), Metadata: []byte("md"), BlockNum: 3, TxNum: 2},
+		{Namespace: "mycc", Collection: "coll", KeyHash: []byte("hash"), IsDelete: true, BlockNum: 4},
+	}
+	assert.Equal(t, entries, toLedgerEntries(toProtoEntries(entries)))
+}
+
+func TestStateDiffSync(t *testing.T) {
+	policy := SameOrgStateDiffPolicy(secAdvMock{"requester": "ORG1", "responder": "ORG1"}, api.OrgIdentityType("ORG1"))
+	entries := []*ledger.StateDiffEntry{
+		{Namespace: "mycc", Key: "a", Value: []byte("100"), BlockNum: 3},
+		{Namespace: "mycc", Key: "b", IsDelete: true, BlockNum: 6},
+		{Namespace: "mycc", Collection: "coll", Key: "c", Value: []byte("secret"), BlockNum: 4},
+	}
+
+	// The requester's state database is at height 3 while its ledger is at height 5, the responder
+	// serves the state differences up to its ledger height of 7 a page per entry, and the blocks 5 and 6
+	requesterSupport, responderSupport := &stateDiffSupportMock{}, &stateDiffSupportMock{}
+	requesterSupport.On("StateCheckpoint").Return(uint64(3), nil)
+	requesterSupport.On("CommitValidatedBlock", mock.Anything).Return(nil)
+	applied := waitForCall(requesterSupport.On("ApplyStateDiff", uint64(3), uint64(7), entries).Return(nil))
+	responderSupport.On("StateCheckpoint").Return(uint64(7), nil)
+	responderSupport.On("StateDiffSince", uint64(3)).Return(uint64(7), entries, nil)
+
+	requester, responder := newStateDiffPeers(t,
+		stateDiffPeer{identity: "requester", height: 5, config: StateDiffConfig{Support: requesterSupport, Policy: policy, ResponseTimeout: time.Second, PageSize: 1}},
+		stateDiffPeer{identity: "responder", height: 7, config: StateDiffConfig{Support: responderSupport, Policy: policy}},
+		nil)
+	defer requester.Stop()
+	defer responder.Stop()
+
+	assertCalled(t, applied)
+	// The blocks the state differences cover are committed in order, before the state differences are applied
+	var committed []uint64
+	for _, call := range requesterSupport.Calls {
+		if call.Method == "CommitValidatedBlock" {
+			committed = append(committed, call.Arguments.Get(0).(uint64))
+		}
+	}
+	assert.Equal(t, []uint64{5, 6}, committed)
+	requesterSupport.AssertNotCalled(t, "RecoverState")
+	assert.Equal(t, uint64(7), requester.payloads.Next())
+	// The state differences are computed once, and kept while their pages are served
+	responderSupport.AssertNumberOfCalls(t, "StateDiffSince", 1)
+
+	// Only the peers whose state database is up to date serve the state differences
+	assert.True(t, responder.servesStateDiff())
+	waitUntilTrueOrTimeout(t, requester.servesStateDiff, 5*time.Second)
+	assert.True(t, requester.nodeMetastate(6).HasCapability(StateDiffCapability))
+}
+
+func TestStateDiffSyncFallback(t *testing.T) {
+	policy := SameOrgStateDiffPolicy(secAdvMock{"requester": "ORG1", "responder": "ORG1", "outsider": "ORG2"}, api.OrgIdentityType("ORG1"))
+	entries := []*ledger.StateDiffEntry{{Namespace: "mycc", Key: "a", Value: []byte("100"), BlockNum: 3}}
+
+	for _, test := range []struct {
+		name              string
+		requesterIdentity string
+		responderIdentity string
+		requesterPolicy   StateDiffPolicy
+		responderHeight   uint64
+		mutateResponse    func(*proto.GossipMessage)
+		responderSign     func([]byte) ([]byte, error)
+		sinceErr          error
+	}{
+		{
+			name:              "no policy",
+			requesterIdentity: "requester",
+			responderIdentity: "responder",
+		},
+		{
+			name:              "requester from another org",
+			requesterIdentity: "outsider",
+			responderIdentity: "responder",
+			requesterPolicy:   policy,
+		},
+		{
+			name:              "responder from another org",
+			requesterIdentity: "requester",
+			responderIdentity: "outsider",
+			requesterPolicy:   policy,
+		},
+		{
+			name:              "tampered response",
+			requesterIdentity: "requester",
+			responderIdentity: "responder",
+			requesterPolicy:   policy,
+			mutateResponse: func(msg *proto.GossipMessage) {
+				if response := msg.GetStateDiffResponse(); response != nil {
+					response.Entries[0].Value = []byte("1000000")
+				}
+			},
+		},
+		{
+			name:              "forged entries",
+			requesterIdentity: "requester",
+			responderIdentity: "responder",
+			requesterPolicy:   policy,
+			mutateResponse: func(msg *proto.GossipMessage) {
+				if response := msg.GetStateDiffResponse(); response != nil {
+					response.Entries[0].Value = []byte("1000000")
+					response.Checksum = stateDiffChecksum("testChainID", response)
+				}
+			},
+		},
+		{
+			name:              "signed by another peer",
+			requesterIdentity: "requester",
+			responderIdentity: "responder",
+			requesterPolicy:   policy,
+			responderSign:     signerOf("requester"),
+		},
+		{
+			name:              "responder fails reading its state",
+			requesterIdentity: "requester",
+			responderIdentity: "responder",
+			requesterPolicy:   policy,
+			sinceErr:          errors.New("state db is unavailable"),
+		},
+		{
+			name:              "responder behind the requester",
+			requesterIdentity: "requester",
+			responderIdentity: "responder",
+			requesterPolicy:   policy,
+			responderHeight:   4,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			responderHeight := test.responderHeight
+			if responderHeight == 0 {
+				responderHeight = 7
+			}
+			requesterSupport, responderSupport := &stateDiffSupportMock{}, &stateDiffSupportMock{}
+			requesterSupport.On("StateCheckpoint").Return(uint64(3), nil)
+			recovered := waitForCall(requesterSupport.On("RecoverState").Return(nil))
+			responderSupport.On("StateCheckpoint").Return(responderHeight, nil)
+			responderSupport.On("StateDiffSince", uint64(3)).Return(responderHeight, entries, test.sinceErr)
+
+			requester, responder := newStateDiffPeers(t,
+				stateDiffPeer{identity: test.requesterIdentity, height: 5, config: StateDiffConfig{
+					Support: requesterSupport, Policy: test.requesterPolicy,
+					ResponseTimeout: time.Millisecond * 200, SyncTimeout: time.Millisecond}},
+				stateDiffPeer{identity: test.responderIdentity, height: responderHeight, config: StateDiffConfig{
+					Support: responderSupport, Policy: policy, Sign: test.responderSign}},
+				test.mutateResponse)
+			defer requester.Stop()
+			defer responder.Stop()
+
+			assertCalled(t, recovered)
+			requesterSupport.AssertNotCalled(t, "CommitValidatedBlock", mock.Anything)
+			requesterSupport.AssertNotCalled(t, "ApplyStateDiff", mock.Anything, mock.Anything, mock.Anything)
+			if test.requesterPolicy == nil {
+				responderSupport.AssertNotCalled(t, "StateDiffSince", mock.Anything)
+			}
+		})
+	}
+}
+
+func TestStateDiffNotDeferred(t *testing.T) {
+	policy := SameOrgStateDiffPolicy(secAdvMock{"requester": "ORG1", "responder": "ORG1"}, api.OrgIdentityType("ORG1"))
+	requesterSupport, responderSupport := &stateDiffSupportMock{}, &stateDiffSupportMock{}
+	requesterSupport.On("StateCheckpoint").Return(uint64(5), nil)
+	responderSupport.On("StateCheckpoint").Return(uint64(0), errors.New("ledger does not sync its state by state differences"))
+
+	requester, responder := newStateDiffPeers(t,
+		stateDiffPeer{identity: "requester", height: 5, config: StateDiffConfig{Support: requesterSupport, Policy: policy}},
+		stateDiffPeer{identity: "responder", height: 7, config: StateDiffConfig{Support: responderSupport, Policy: policy}},
+		nil)
+	defer requester.Stop()
+	defer responder.Stop()
+
+	// A state database up to date is neither synced nor recovered
+	assert.True(t, requester.catchUpState())
+	assert.True(t, requester.servesStateDiff())
+	requesterSupport.AssertNotCalled(t, "RecoverState")
+	// The differential state sync is disabled for the ledgers not supporting it
+	assert.Nil(t, responder.stateDiff)
+	assert.False(t, responder.servesStateDiff())
+}
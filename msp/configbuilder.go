@@ -38,8 +38,26 @@ type OrganizationalUnitIdentifiersConfiguration struct {
 	OrganizationalUnitIdentifier string `yaml:"OrganizationalUnitIdentifier,omitempty"`
 }
 
+// NodeOUs contains information on how to tell apart clients, peers and admins
+// based on the OUs of their certificates
+type NodeOUs struct {
+	Enable             bool                                        `yaml:"Enable,omitempty"`
+	ClientOUIdentifier *OrganizationalUnitIdentifiersConfiguration `yaml:"ClientOUIdentifier,omitempty"`
+	PeerOUIdentifier   *OrganizationalUnitIdentifiersConfiguration `yaml:"PeerOUIdentifier,omitempty"`
+	AdminOUIdentifier  *OrganizationalUnitIdentifiersConfiguration `yaml:"AdminOUIdentifier,omitempty"`
+}
+
+// CryptoConfiguration overrides the hash functions used by the MSP,
+// e.g. to use SM3 in deployments requiring the GM crypto suite
+type CryptoConfiguration struct {
+	SignatureHashFamily            string `yaml:"SignatureHashFamily,omitempty"`
+	IdentityIdentifierHashFunction string `yaml:"IdentityIdentifierHashFunction,omitempty"`
+}
+
 type Configuration struct {
 	OrganizationalUnitIdentifiers []*OrganizationalUnitIdentifiersConfiguration `yaml:"OrganizationalUnitIdentifiers,omitempty"`
+	NodeOUs                       *NodeOUs                                      `yaml:"NodeOUs,omitempty"`
+	CryptoConfig                  *CryptoConfiguration                          `yaml:"CryptoConfig,omitempty"`
 }
 
 func readFile(file string) ([]byte, error) {
@@ -121,6 +139,16 @@ func SetupBCCSPKeystoreConfig(bccspConfig *factory.FactoryOpts, keystoreDir stri
 			bccspConfig.SwOpts = factory.GetDefaultOpts().SwOpts
 		}
 
+		// A vault keystore takes precedence over the file one and
+		// also defaults to the MSP keystore folder
+		if bccspConfig.SwOpts.VaultKeystore != nil {
+			bccspConfig.SwOpts.Ephemeral = false
+			if bccspConfig.SwOpts.VaultKeystore.KeyStorePath == "" {
+				bccspConfig.SwOpts.VaultKeystore.KeyStorePath = keystoreDir
+			}
+			return bccspConfig
+		}
+
 		// Only override the KeyStorePath if it was left empty
 		if bccspConfig.SwOpts.FileKeystore == nil ||
 			bccspConfig.SwOpts.FileKeystore.KeyStorePath == "" {
@@ -216,6 +244,11 @@ func getMspConfig(dir string, ID string, sigid *msp.SigningIdentityInfo) (*msp.M
 	// if the configuration file is there then load it
 	// otherwise skip it
 	var ouis []*msp.FabricOUIdentifier
+	var nodeOUs *msp.FabricNodeOUs
+	cryptoConfig := &msp.FabricCryptoConfig{
+		SignatureHashFamily:            bccsp.SHA2,
+		IdentityIdentifierHashFunction: bccsp.SHA256,
+	}
 	_, err = os.Stat(configFile)
 	if err == nil {
 		// load the file, if there is a failure in loading it then
@@ -246,16 +279,37 @@ func getMspConfig(dir string, ID string, sigid *msp.SigningIdentityInfo) (*msp.M
 				ouis = append(ouis, oui)
 			}
 		}
+
+		// Prepare NodeOUs
+		if configuration.NodeOUs != nil {
+			nodeOUs = &msp.FabricNodeOUs{Enable: configuration.NodeOUs.Enable}
+			nodeOUs.ClientOuIdentifier, err = getNodeOUIdentifier(dir, configuration.NodeOUs.ClientOUIdentifier)
+			if err != nil {
+				return nil, err
+			}
+			nodeOUs.PeerOuIdentifier, err = getNodeOUIdentifier(dir, configuration.NodeOUs.PeerOUIdentifier)
+			if err != nil {
+				return nil, err
+			}
+			nodeOUs.AdminOuIdentifier, err = getNodeOUIdentifier(dir, configuration.NodeOUs.AdminOUIdentifier)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		// Override the default FabricCryptoConfig
+		if configuration.CryptoConfig != nil {
+			if configuration.CryptoConfig.SignatureHashFamily != "" {
+				cryptoConfig.SignatureHashFamily = configuration.CryptoConfig.SignatureHashFamily
+			}
+			if configuration.CryptoConfig.IdentityIdentifierHashFunction != "" {
+				cryptoConfig.IdentityIdentifierHashFunction = configuration.CryptoConfig.IdentityIdentifierHashFunction
+			}
+		}
 	} else {
 		mspLogger.Debugf("MSP configuration file not found at [%s]: [%s]", configFile, err)
 	}
 
-	// Set FabricCryptoConfig
-	cryptoConfig := &msp.FabricCryptoConfig{
-		SignatureHashFamily:            bccsp.SHA2,
-		IdentityIdentifierHashFunction: bccsp.SHA256,
-	}
-
 	// Compose FabricMSPConfig
 	fmspconf := &msp.FabricMSPConfig{
 		Admins:            admincert,
@@ -268,6 +322,7 @@ func getMspConfig(dir string, ID string, sigid *msp.SigningIdentityInfo) (*msp.M
 		CryptoConfig:                  cryptoConfig,
 		TlsRootCerts:                  tlsCACerts,
 		TlsIntermediateCerts:          tlsIntermediateCerts,
+		FabricNodeOus:                 nodeOUs,
 	}
 
 	fmpsjs, _ := proto.Marshal(fmspconf)
@@ -276,3 +331,22 @@ func getMspConfig(dir string, ID string, sigid *msp.SigningIdentityInfo) (*msp.M
 
 	return mspconf, nil
 }
+
+// getNodeOUIdentifier loads the certificate of the given NodeOU identifier, if
+// any; the certificate is optional, and if omitted only the OU is matched
+func getNodeOUIdentifier(dir string, ouID *OrganizationalUnitIdentifiersConfiguration) (*msp.FabricOUIdentifier, error) {
+	if ouID == nil {
+		return nil, nil
+	}
+
+	oui := &msp.FabricOUIdentifier{OrganizationalUnitIdentifier: ouID.OrganizationalUnitIdentifier}
+	if ouID.Certificate != "" {
+		f := filepath.Join(dir, ouID.Certificate)
+		raw, err := readFile(f)
+		if err != nil {
+			return nil, fmt.Errorf("Failed loading NodeOUs certificate at [%s]: [%s]", f, err)
+		}
+		oui.Certificate = raw
+	}
+	return oui, nil
+}
@@ -17,9 +17,17 @@ limitations under the License.
 package msp
 
 import (
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/bccsp"
+	"github.com/hyperledger/fabric/bccsp/gm/sm3"
 	"github.com/hyperledger/fabric/core/config"
+	"github.com/hyperledger/fabric/protos/msp"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -44,3 +52,34 @@ func TestReadFileUtils(t *testing.T) {
 	_, err = readPemFile("/dev/null")
 	assert.Error(t, err)
 }
+
+func TestGetVerifyingMspConfigCryptoConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cryptoconfig")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	for sub, file := range map[string]string{cacerts: "cacert.pem", admincerts: "admin.pem"} {
+		assert.NoError(t, os.MkdirAll(filepath.Join(dir, sub), 0755))
+		raw, err := ioutil.ReadFile(filepath.Join("testdata", "nodeous", sub, file))
+		assert.NoError(t, err)
+		assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, sub, file), raw, 0644))
+	}
+	configYaml := "CryptoConfig:\n  SignatureHashFamily: SM3\n  IdentityIdentifierHashFunction: SM3\n"
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, configfilename), []byte(configYaml), 0644))
+
+	conf, err := GetVerifyingMspConfig(dir, "DEFAULT")
+	assert.NoError(t, err)
+	fabricConf := &msp.FabricMSPConfig{}
+	assert.NoError(t, proto.Unmarshal(conf.Config, fabricConf))
+	assert.Equal(t, bccsp.SM3, fabricConf.CryptoConfig.SignatureHashFamily)
+	assert.Equal(t, bccsp.SM3, fabricConf.CryptoConfig.IdentityIdentifierHashFunction)
+
+	// identities are identified by the SM3 hash of their certificate
+	thisMSP, err := NewBccspMsp()
+	assert.NoError(t, err)
+	assert.NoError(t, thisMSP.Setup(conf))
+	id, _, err := thisMSP.(*bccspmsp).getIdentityFromConf(fabricConf.Admins[0])
+	assert.NoError(t, err)
+	digest := sm3.Sum(id.(*identity).cert.Raw)
+	assert.Equal(t, hex.EncodeToString(digest[:]), id.GetIdentifier().Id)
+}
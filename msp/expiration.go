@@ -0,0 +1,54 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package msp
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/protos/msp"
+	"github.com/pkg/errors"
+)
+
+// ErrIdentityExpired is returned, wrapped, by CheckExpiration for the identities
+// whose certificate expired. The validation of identities by the MSPs ignores the
+// expiration of certificates, the admission paths of the orderer and the peer
+// check it with CheckExpiration
+var ErrIdentityExpired = errors.New("identity expired")
+
+// ExpiresAt returns when the given serialized identity expires, or a zero time.Time
+// in case we cannot determine that
+func ExpiresAt(identityBytes []byte) time.Time {
+	sId := &msp.SerializedIdentity{}
+	// If protobuf parsing failed, we make no decisions about the expiration time
+	if err := proto.Unmarshal(identityBytes, sId); err != nil {
+		return time.Time{}
+	}
+	bl, _ := pem.Decode(sId.IdBytes)
+	if bl == nil {
+		// If the identity isn't a PEM block, we make no decisions about the expiration time
+		return time.Time{}
+	}
+	cert, err := x509.ParseCertificate(bl.Bytes)
+	if err != nil {
+		return time.Time{}
+	}
+	return cert.NotAfter
+}
+
+// CheckExpiration returns an error wrapping ErrIdentityExpired if the certificate of the
+// given serialized identity is past its NotAfter at the given time. Identities whose
+// expiration cannot be determined never expire
+func CheckExpiration(identityBytes []byte, now time.Time) error {
+	expirationTime := ExpiresAt(identityBytes)
+	if expirationTime.IsZero() || now.Before(expirationTime) {
+		return nil
+	}
+	return errors.Wrapf(errors.WithStack(ErrIdentityExpired), "certificate expired at %s", expirationTime)
+}
@@ -0,0 +1,59 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package msp
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/protos/msp"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckExpiration(t *testing.T) {
+	notAfter := time.Now().Truncate(time.Second)
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "client"},
+		NotBefore:    notAfter.Add(-time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(t, err)
+	identity, err := proto.Marshal(&msp.SerializedIdentity{
+		Mspid:   "SampleOrg",
+		IdBytes: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+	})
+	assert.NoError(t, err)
+
+	assert.True(t, notAfter.Equal(ExpiresAt(identity)))
+	assert.NoError(t, CheckExpiration(identity, notAfter.Add(-time.Second)))
+	err = CheckExpiration(identity, notAfter.Add(time.Second))
+	assert.Error(t, err)
+	assert.Equal(t, ErrIdentityExpired, errors.Cause(err))
+
+	t.Run("NotACertificate", func(t *testing.T) {
+		identity, err := proto.Marshal(&msp.SerializedIdentity{IdBytes: []byte("foo")})
+		assert.NoError(t, err)
+		assert.True(t, ExpiresAt(identity).IsZero())
+		assert.NoError(t, CheckExpiration(identity, time.Now()))
+	})
+	t.Run("Garbage", func(t *testing.T) {
+		assert.True(t, ExpiresAt([]byte("garbage")).IsZero())
+	})
+}
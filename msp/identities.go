@@ -145,14 +145,9 @@ func (id *identity) Verify(msg []byte, sig []byte) error {
 	// mspIdentityLogger.Infof("Verifying signature")
 
 	// Compute Hash
-	hashOpt, err := id.getHashOpt(id.msp.cryptoConfig.SignatureHashFamily)
+	digest, err := id.getDigest(msg)
 	if err != nil {
-		return fmt.Errorf("Failed getting hash function options [%s]", err)
-	}
-
-	digest, err := id.msp.bccsp.Hash(msg, hashOpt)
-	if err != nil {
-		return fmt.Errorf("Failed computing digest [%s]", err)
+		return err
 	}
 
 	if mspIdentityLogger.IsEnabledFor(logging.DEBUG) {
@@ -190,12 +185,35 @@ func (id *identity) Serialize() ([]byte, error) {
 	return idBytes, nil
 }
 
+// getDigest returns the bytes that the key of this identity signs for msg.
+// Ed25519 hashes the message as part of the signature scheme, therefore
+// Ed25519 identities sign the message itself; every other algorithm signs
+// the hash of the message computed with the hash family of the MSP.
+func (id *identity) getDigest(msg []byte) ([]byte, error) {
+	if id.cert.PublicKeyAlgorithm == x509.Ed25519 {
+		return msg, nil
+	}
+
+	hashOpt, err := id.getHashOpt(id.msp.cryptoConfig.SignatureHashFamily)
+	if err != nil {
+		return nil, fmt.Errorf("Failed getting hash function options [%s]", err)
+	}
+
+	digest, err := id.msp.bccsp.Hash(msg, hashOpt)
+	if err != nil {
+		return nil, fmt.Errorf("Failed computing digest [%s]", err)
+	}
+	return digest, nil
+}
+
 func (id *identity) getHashOpt(hashFamily string) (bccsp.HashOpts, error) {
 	switch hashFamily {
 	case bccsp.SHA2:
 		return bccsp.GetHashOpt(bccsp.SHA256)
 	case bccsp.SHA3:
 		return bccsp.GetHashOpt(bccsp.SHA3_256)
+	case bccsp.SM3:
+		return bccsp.GetHashOpt(bccsp.SM3)
 	}
 	return nil, fmt.Errorf("hash famility not recognized [%s]", hashFamily)
 }
@@ -222,14 +240,9 @@ func (id *signingidentity) Sign(msg []byte) ([]byte, error) {
 	//mspIdentityLogger.Infof("Signing message")
 
 	// Compute Hash
-	hashOpt, err := id.getHashOpt(id.msp.cryptoConfig.SignatureHashFamily)
+	digest, err := id.getDigest(msg)
 	if err != nil {
-		return nil, fmt.Errorf("Failed getting hash function options [%s]", err)
-	}
-
-	digest, err := id.msp.bccsp.Hash(msg, hashOpt)
-	if err != nil {
-		return nil, fmt.Errorf("Failed computing digest [%s]", err)
+		return nil, err
 	}
 
 	if len(msg) < 32 {
@@ -0,0 +1,162 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package mgmt
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/bccsp/factory"
+	"github.com/hyperledger/fabric/msp"
+	mspproto "github.com/hyperledger/fabric/protos/msp"
+)
+
+// refreshableMSP wraps the local MSP so that the instance returned by
+// GetLocalMSP, which many components hold on to, picks up updated CRLs
+// and intermediate certificates when the MSP is set up again. Every Setup
+// builds a fresh MSP and swaps it in only if the new configuration is valid.
+type refreshableMSP struct {
+	sync.RWMutex
+	msp  msp.MSP
+	conf *mspproto.MSPConfig
+}
+
+func newRefreshableMSP() (*refreshableMSP, error) {
+	inner, err := msp.NewBccspMsp()
+	if err != nil {
+		return nil, err
+	}
+	return &refreshableMSP{msp: inner}, nil
+}
+
+func (r *refreshableMSP) current() msp.MSP {
+	r.RLock()
+	defer r.RUnlock()
+	return r.msp
+}
+
+// Setup sets up a new MSP instance from the given configuration and, on
+// success, replaces the current one with it
+func (r *refreshableMSP) Setup(conf *mspproto.MSPConfig) error {
+	inner, err := msp.NewBccspMsp()
+	if err != nil {
+		return err
+	}
+	if err := inner.Setup(conf); err != nil {
+		return err
+	}
+
+	r.Lock()
+	defer r.Unlock()
+	r.msp = inner
+	r.conf = conf
+	return nil
+}
+
+// refresh sets up the MSP again if the given configuration differs from the
+// one currently in use
+func (r *refreshableMSP) refresh(conf *mspproto.MSPConfig) (bool, error) {
+	r.RLock()
+	unchanged := proto.Equal(r.conf, conf)
+	r.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+
+	if err := r.Setup(conf); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *refreshableMSP) DeserializeIdentity(serializedIdentity []byte) (msp.Identity, error) {
+	return r.current().DeserializeIdentity(serializedIdentity)
+}
+
+func (r *refreshableMSP) GetType() msp.ProviderType {
+	return r.current().GetType()
+}
+
+func (r *refreshableMSP) GetIdentifier() (string, error) {
+	return r.current().GetIdentifier()
+}
+
+func (r *refreshableMSP) GetSigningIdentity(identifier *msp.IdentityIdentifier) (msp.SigningIdentity, error) {
+	return r.current().GetSigningIdentity(identifier)
+}
+
+func (r *refreshableMSP) GetDefaultSigningIdentity() (msp.SigningIdentity, error) {
+	return r.current().GetDefaultSigningIdentity()
+}
+
+func (r *refreshableMSP) GetTLSRootCerts() [][]byte {
+	return r.current().GetTLSRootCerts()
+}
+
+func (r *refreshableMSP) GetTLSIntermediateCerts() [][]byte {
+	return r.current().GetTLSIntermediateCerts()
+}
+
+func (r *refreshableMSP) Validate(id msp.Identity) error {
+	return r.current().Validate(id)
+}
+
+func (r *refreshableMSP) SatisfiesPrincipal(id msp.Identity, principal *mspproto.MSPPrincipal) error {
+	return r.current().SatisfiesPrincipal(id, principal)
+}
+
+var (
+	refreshLock      sync.Mutex
+	localMspDir      string
+	localMspBCCSP    *factory.FactoryOpts
+	refreshListeners []func()
+)
+
+// RefreshLocalMsp reads the local MSP folder the local MSP was loaded from
+// again, so that updated CRLs and intermediate certificates take effect
+// without a restart. If the configuration changed, the local MSP is set up
+// again and the registered refresh listeners are invoked.
+func RefreshLocalMsp() error {
+	refreshLock.Lock()
+	defer refreshLock.Unlock()
+
+	if localMspDir == "" {
+		return fmt.Errorf("The local MSP was not loaded from a folder")
+	}
+
+	id, _ := GetLocalMSP().GetIdentifier()
+	conf, err := msp.GetLocalMspConfig(localMspDir, localMspBCCSP, id)
+	if err != nil {
+		return err
+	}
+
+	refreshed, err := getLocalMsp().refresh(conf)
+	if err != nil {
+		return err
+	}
+	if !refreshed {
+		mspLogger.Debugf("Local MSP configuration in %s is unchanged", localMspDir)
+		return nil
+	}
+
+	mspLogger.Infof("Refreshed local MSP %s from %s", id, localMspDir)
+	for _, listener := range refreshListeners {
+		listener()
+	}
+	return nil
+}
+
+// AddLocalMspRefreshListener registers a function to be invoked every time
+// the local MSP is refreshed, e.g. to invalidate identities which might have
+// been revoked
+func AddLocalMspRefreshListener(listener func()) {
+	refreshLock.Lock()
+	defer refreshLock.Unlock()
+
+	refreshListeners = append(refreshListeners, listener)
+}
@@ -0,0 +1,120 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package mgmt
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/core/config"
+	"github.com/hyperledger/fabric/msp"
+	mspproto "github.com/hyperledger/fabric/protos/msp"
+	"github.com/stretchr/testify/assert"
+)
+
+func copyMspDir(t *testing.T, src, dst string) {
+	err := filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return os.MkdirAll(filepath.Join(dst, rel), 0755)
+		}
+		raw, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return ioutil.WriteFile(filepath.Join(dst, rel), raw, 0644)
+	})
+	assert.NoError(t, err)
+}
+
+func TestRefreshableMSPRevocation(t *testing.T) {
+	// testdata/revocation contains a CRL revoking its signing certificate
+	revocationDir := filepath.Join("..", "testdata", "revocation")
+	mspDir, err := ioutil.TempDir("", "refreshmsp")
+	assert.NoError(t, err)
+	defer os.RemoveAll(mspDir)
+	copyMspDir(t, revocationDir, mspDir)
+	assert.NoError(t, os.RemoveAll(filepath.Join(mspDir, "crls")))
+
+	r, err := newRefreshableMSP()
+	assert.NoError(t, err)
+	conf, err := msp.GetVerifyingMspConfig(mspDir, "DEFAULT")
+	assert.NoError(t, err)
+	assert.NoError(t, r.Setup(conf))
+
+	signcert, err := ioutil.ReadFile(filepath.Join(mspDir, "signcerts", "signcert.pem"))
+	assert.NoError(t, err)
+	serializedID, err := proto.Marshal(&mspproto.SerializedIdentity{Mspid: "DEFAULT", IdBytes: signcert})
+	assert.NoError(t, err)
+	id, err := r.DeserializeIdentity(serializedID)
+	assert.NoError(t, err)
+	assert.NoError(t, r.Validate(id))
+
+	refreshed, err := r.refresh(conf)
+	assert.NoError(t, err)
+	assert.False(t, refreshed, "An unchanged configuration should not trigger a refresh")
+
+	copyMspDir(t, filepath.Join(revocationDir, "crls"), filepath.Join(mspDir, "crls"))
+	conf, err = msp.GetVerifyingMspConfig(mspDir, "DEFAULT")
+	assert.NoError(t, err)
+	refreshed, err = r.refresh(conf)
+	assert.NoError(t, err)
+	assert.True(t, refreshed)
+
+	id, err = r.DeserializeIdentity(serializedID)
+	assert.NoError(t, err)
+	assert.Error(t, r.Validate(id), "The identity should be revoked once the CRL is picked up")
+
+	// a bad configuration leaves the current MSP in place
+	_, err = r.refresh(&mspproto.MSPConfig{Config: []byte("barf")})
+	assert.Error(t, err)
+	mspID, err := r.GetIdentifier()
+	assert.NoError(t, err)
+	assert.Equal(t, "DEFAULT", mspID)
+}
+
+func TestRefreshLocalMsp(t *testing.T) {
+	devMspDir, err := config.GetDevMspDir()
+	assert.NoError(t, err)
+	mspDir, err := ioutil.TempDir("", "refreshmsp")
+	assert.NoError(t, err)
+	defer os.RemoveAll(mspDir)
+	copyMspDir(t, devMspDir, mspDir)
+	defer LoadDevMsp()
+
+	assert.NoError(t, LoadLocalMsp(mspDir, nil, "DEFAULT"))
+	lclMsp := GetLocalMSP()
+
+	refreshes := 0
+	AddLocalMspRefreshListener(func() { refreshes++ })
+
+	assert.NoError(t, RefreshLocalMsp())
+	assert.Equal(t, 0, refreshes, "An unchanged MSP folder should not trigger a refresh")
+
+	admincert, err := ioutil.ReadFile(filepath.Join(mspDir, "admincerts", "admincert.pem"))
+	assert.NoError(t, err)
+	assert.NoError(t, os.MkdirAll(filepath.Join(mspDir, "intermediatecerts"), 0755))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(mspDir, "admincerts", "admincert2.pem"), admincert, 0644))
+	assert.NoError(t, RefreshLocalMsp())
+	assert.Equal(t, 1, refreshes)
+	assert.True(t, lclMsp == GetLocalMSP(), "The local MSP instance should survive a refresh")
+
+	assert.NoError(t, os.RemoveAll(filepath.Join(mspDir, "signcerts")))
+	assert.Error(t, RefreshLocalMsp())
+	assert.Equal(t, 1, refreshes)
+	_, err = GetLocalMSP().GetDefaultSigningIdentity()
+	assert.NoError(t, err, "A failed refresh should leave the local MSP in place")
+}
@@ -40,7 +40,15 @@ func LoadLocalMsp(dir string, bccspConfig *factory.FactoryOpts, mspID string) er
 		return err
 	}
 
-	return GetLocalMSP().Setup(conf)
+	if err := GetLocalMSP().Setup(conf); err != nil {
+		return err
+	}
+
+	refreshLock.Lock()
+	defer refreshLock.Unlock()
+	localMspDir = dir
+	localMspBCCSP = bccspConfig
+	return nil
 }
 
 // Loads the development local MSP for use in testing.  Not valid for production/runtime context
@@ -59,7 +67,7 @@ func LoadDevMsp() error {
 // HOWEVER IN THE INTERIM, THESE HELPER FUNCTIONS ARE REQUIRED
 
 var m sync.Mutex
-var localMsp msp.MSP
+var localMsp *refreshableMSP
 var mspMap map[string]msp.MSPManager = make(map[string]msp.MSPManager)
 var mspLogger = flogging.MustGetLogger("msp")
 
@@ -125,7 +133,11 @@ func XXXSetMSPManager(chainID string, manager msp.MSPManager) {
 
 // GetLocalMSP returns the local msp (and creates it if it doesn't exist)
 func GetLocalMSP() msp.MSP {
-	var lclMsp msp.MSP
+	return getLocalMsp()
+}
+
+func getLocalMsp() *refreshableMSP {
+	var lclMsp *refreshableMSP
 	var created bool = false
 	{
 		m.Lock()
@@ -135,7 +147,7 @@ func GetLocalMSP() msp.MSP {
 		if lclMsp == nil {
 			var err error
 			created = true
-			lclMsp, err = msp.NewBccspMsp()
+			lclMsp, err = newRefreshableMSP()
 			if err != nil {
 				mspLogger.Fatalf("Failed to initialize local MSP, received err %s", err)
 			}
@@ -18,6 +18,7 @@ package msp
 
 import (
 	"crypto/ecdsa"
+	"crypto/ed25519"
 	"crypto/x509"
 	"encoding/hex"
 	"encoding/pem"
@@ -178,6 +179,29 @@ func TestNotFoundInBCCSP(t *testing.T) {
 	assert.Contains(t, "KeyMaterial not found in SigningIdentityInfo", err.Error())
 }
 
+func TestNewBccspMspWithCSP(t *testing.T) {
+	_, err := NewBccspMspWithCSP(nil)
+	assert.Error(t, err)
+
+	dir, err := config.GetDevMspDir()
+	assert.NoError(t, err)
+	conf, err := GetLocalMspConfig(dir, nil, "DEFAULT")
+	assert.NoError(t, err)
+	ks, err := sw.NewFileBasedKeyStore(nil, filepath.Join(dir, "keystore"), true)
+	assert.NoError(t, err)
+	csp, err := sw.New(256, "SHA2", ks)
+	assert.NoError(t, err)
+
+	thisMSP, err := NewBccspMspWithCSP(csp)
+	assert.NoError(t, err)
+	assert.NoError(t, thisMSP.Setup(conf))
+	id, err := thisMSP.GetDefaultSigningIdentity()
+	assert.NoError(t, err)
+	sig, err := id.Sign([]byte("msg"))
+	assert.NoError(t, err)
+	assert.NoError(t, id.Verify([]byte("msg"), sig))
+}
+
 func TestGetIdentities(t *testing.T) {
 	_, err := localMsp.GetDefaultSigningIdentity()
 	if err != nil {
@@ -462,6 +486,60 @@ func TestSignAndVerifyOtherHash(t *testing.T) {
 	id.(*signingidentity).msp.cryptoConfig.SignatureHashFamily = hash
 }
 
+func TestSignAndVerifySM3(t *testing.T) {
+	id, err := localMsp.GetDefaultSigningIdentity()
+	assert.NoError(t, err)
+
+	hash := id.(*signingidentity).msp.cryptoConfig.SignatureHashFamily
+	id.(*signingidentity).msp.cryptoConfig.SignatureHashFamily = bccsp.SM3
+	defer func() { id.(*signingidentity).msp.cryptoConfig.SignatureHashFamily = hash }()
+
+	msg := []byte("foo")
+	sig, err := id.Sign(msg)
+	assert.NoError(t, err)
+	assert.NoError(t, id.Verify(msg, sig))
+
+	// a signature over the SHA2 digest does not verify
+	id.(*signingidentity).msp.cryptoConfig.SignatureHashFamily = bccsp.SHA2
+	assert.Error(t, id.Verify(msg, sig))
+}
+
+func TestSignAndVerifyEd25519(t *testing.T) {
+	// testdata/ed25519 contains an Ed25519 CA and an Ed25519 signing identity issued by it
+	thisMSP := getLocalMSP(t, "testdata/ed25519")
+
+	id, err := thisMSP.GetDefaultSigningIdentity()
+	assert.NoError(t, err)
+	assert.NoError(t, id.Validate())
+	assert.Equal(t, x509.Ed25519, id.(*signingidentity).cert.PublicKeyAlgorithm)
+
+	msg := []byte("foo")
+	sig, err := id.Sign(msg)
+	assert.NoError(t, err)
+	assert.NoError(t, id.Verify(msg, sig))
+
+	// Ed25519 identities sign the message itself rather than its digest
+	assert.True(t, ed25519.Verify(id.(*signingidentity).cert.PublicKey.(ed25519.PublicKey), msg, sig))
+
+	serializedID, err := id.Serialize()
+	assert.NoError(t, err)
+	idBack, err := thisMSP.DeserializeIdentity(serializedID)
+	assert.NoError(t, err)
+	assert.NoError(t, idBack.Validate())
+	assert.NoError(t, idBack.Verify(msg, sig))
+	assert.Error(t, idBack.Verify([]byte("bar"), sig))
+	assert.Error(t, idBack.Verify(msg, sig[1:]))
+
+	// the hash family of the MSP does not apply to Ed25519 identities
+	thisMSP.(*bccspmsp).cryptoConfig.SignatureHashFamily = bccsp.SHA3
+	assert.NoError(t, idBack.Verify(msg, sig))
+
+	// Ed25519 identities are not valid in an MSP with other roots of trust
+	idBack, err = localMsp.DeserializeIdentity(serializedID)
+	assert.NoError(t, err)
+	assert.Error(t, localMsp.Validate(idBack))
+}
+
 func TestSignAndVerify_longMessage(t *testing.T) {
 	id, err := localMsp.GetDefaultSigningIdentity()
 	if err != nil {
@@ -729,6 +807,67 @@ func TestAdminPolicyPrincipal(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func marshalOrPanic(msg proto.Message) []byte {
+	raw, err := proto.Marshal(msg)
+	if err != nil {
+		panic(err)
+	}
+	return raw
+}
+
+func TestCombinedPolicyPrincipal(t *testing.T) {
+	id, err := localMsp.GetDefaultSigningIdentity()
+	assert.NoError(t, err)
+
+	member := &msp.MSPPrincipal{
+		PrincipalClassification: msp.MSPPrincipal_ROLE,
+		Principal:               marshalOrPanic(&msp.MSPRole{Role: msp.MSPRole_MEMBER, MspIdentifier: "DEFAULT"})}
+	admin := &msp.MSPPrincipal{
+		PrincipalClassification: msp.MSPPrincipal_ROLE,
+		Principal:               marshalOrPanic(&msp.MSPRole{Role: msp.MSPRole_ADMIN, MspIdentifier: "DEFAULT"})}
+	nominal := &msp.MSPPrincipal{
+		PrincipalClassification: msp.MSPPrincipal_ANONYMITY,
+		Principal:               marshalOrPanic(&msp.MSPIdentityAnonymity{AnonymityType: msp.MSPIdentityAnonymity_NOMINAL})}
+	otherMSP := &msp.MSPPrincipal{
+		PrincipalClassification: msp.MSPPrincipal_ROLE,
+		Principal:               marshalOrPanic(&msp.MSPRole{Role: msp.MSPRole_MEMBER, MspIdentifier: "DEFAULTBARFBARF"})}
+
+	combined := func(principals ...*msp.MSPPrincipal) *msp.MSPPrincipal {
+		return &msp.MSPPrincipal{
+			PrincipalClassification: msp.MSPPrincipal_COMBINED,
+			Principal:               marshalOrPanic(&msp.CombinedPrincipal{Principals: principals})}
+	}
+
+	assert.NoError(t, id.SatisfiesPrincipal(combined(member, admin)))
+	assert.NoError(t, id.SatisfiesPrincipal(combined(member, combined(admin, nominal))))
+	assert.Error(t, id.SatisfiesPrincipal(combined(member, otherMSP)))
+	assert.Error(t, id.SatisfiesPrincipal(combined(member, combined(otherMSP))))
+	assert.Error(t, id.SatisfiesPrincipal(combined()))
+	assert.Error(t, id.SatisfiesPrincipal(&msp.MSPPrincipal{
+		PrincipalClassification: msp.MSPPrincipal_COMBINED,
+		Principal:               []byte("barf")}))
+}
+
+func TestAnonymityPolicyPrincipal(t *testing.T) {
+	id, err := localMsp.GetDefaultSigningIdentity()
+	assert.NoError(t, err)
+
+	principal := &msp.MSPPrincipal{
+		PrincipalClassification: msp.MSPPrincipal_ANONYMITY,
+		Principal:               marshalOrPanic(&msp.MSPIdentityAnonymity{AnonymityType: msp.MSPIdentityAnonymity_NOMINAL})}
+	assert.NoError(t, id.SatisfiesPrincipal(principal))
+
+	// x509 identities are never anonymous
+	principal.Principal = marshalOrPanic(&msp.MSPIdentityAnonymity{AnonymityType: msp.MSPIdentityAnonymity_ANONYMOUS})
+	assert.Error(t, id.SatisfiesPrincipal(principal))
+
+	principal.Principal = marshalOrPanic(&msp.MSPIdentityAnonymity{AnonymityType: 35})
+	assert.Error(t, id.SatisfiesPrincipal(principal))
+
+	principal.Principal = []byte("barf")
+	assert.Error(t, id.SatisfiesPrincipal(principal))
+}
+
 func TestAdminPolicyPrincipalFails(t *testing.T) {
 	id, err := localMsp.GetDefaultSigningIdentity()
 	assert.NoError(t, err)
@@ -70,6 +70,13 @@ type bccspmsp struct {
 
 	// cryptoConfig contains
 	cryptoConfig *m.FabricCryptoConfig
+
+	// NodeOUs configuration
+	ouEnforcement bool
+	// These are the OUIdentifiers of the clients, peers and admins.
+	// Here, we are assuming that a certificate is either a client, a peer
+	// or an admin
+	clientOU, peerOU, adminOU *OUIdentifier
 }
 
 // NewBccspMsp returns an MSP instance backed up by a BCCSP
@@ -86,6 +93,18 @@ func NewBccspMsp() (MSP, error) {
 	return theMsp, nil
 }
 
+// NewBccspMspWithCSP returns an MSP instance backed up by the given
+// BCCSP crypto provider rather than the default one, e.g. one whose
+// keys are held by a hardware security module
+func NewBccspMspWithCSP(csp bccsp.BCCSP) (MSP, error) {
+	if csp == nil {
+		return nil, fmt.Errorf("nil crypto provider")
+	}
+	mspLogger.Debugf("Creating BCCSP-based MSP instance with a dedicated crypto provider")
+
+	return &bccspmsp{bccsp: csp}, nil
+}
+
 func (msp *bccspmsp) getCertFromPem(idBytes []byte) (*x509.Certificate, error) {
 	if idBytes == nil {
 		return nil, fmt.Errorf("getIdentityFromConf error: nil idBytes")
@@ -146,9 +165,13 @@ func (msp *bccspmsp) getSigningIdentityFromConf(sidInfo *m.SigningIdentityInfo)
 		}
 
 		pemKey, _ := pem.Decode(sidInfo.PrivateSigner.KeyMaterial)
-		privKey, err = msp.bccsp.KeyImport(pemKey.Bytes, &bccsp.ECDSAPrivateKeyImportOpts{Temporary: true})
+		if idPub.(*identity).cert.PublicKeyAlgorithm == x509.Ed25519 {
+			privKey, err = msp.bccsp.KeyImport(pemKey.Bytes, &bccsp.ED25519PrivateKeyImportOpts{Temporary: true})
+		} else {
+			privKey, err = msp.bccsp.KeyImport(pemKey.Bytes, &bccsp.ECDSAPrivateKeyImportOpts{Temporary: true})
+		}
 		if err != nil {
-			return nil, fmt.Errorf("getIdentityFromBytes error: Failed to import EC private key, err %s", err)
+			return nil, fmt.Errorf("getIdentityFromBytes error: Failed to import private key, err %s", err)
 		}
 	}
 
@@ -295,6 +318,11 @@ func (msp *bccspmsp) Setup(conf1 *m.MSPConfig) error {
 		return err
 	}
 
+	// setup the NodeOUs
+	if err := msp.setupNodeOUs(conf); err != nil {
+		return err
+	}
+
 	// setup TLS CAs
 	if err := msp.setupTLSCAs(conf); err != nil {
 		return err
@@ -419,6 +447,48 @@ func (msp *bccspmsp) deserializeIdentityInternal(serializedIdentity []byte) (Ide
 
 // SatisfiesPrincipal returns null if the identity matches the principal or an error otherwise
 func (msp *bccspmsp) SatisfiesPrincipal(id Identity, principal *m.MSPPrincipal) error {
+	principals, err := collectPrincipals(principal)
+	if err != nil {
+		return err
+	}
+	// an identity satisfies a combined principal if it satisfies all of its principals
+	for _, principal := range principals {
+		err = msp.satisfiesPrincipalInternal(id, principal)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// collectPrincipals flattens combined principals, possibly nested,
+// into the slice of the non-combined principals they are made of
+func collectPrincipals(principal *m.MSPPrincipal) ([]*m.MSPPrincipal, error) {
+	if principal.PrincipalClassification != m.MSPPrincipal_COMBINED {
+		return []*m.MSPPrincipal{principal}, nil
+	}
+
+	combined := &m.CombinedPrincipal{}
+	err := proto.Unmarshal(principal.Principal, combined)
+	if err != nil {
+		return nil, fmt.Errorf("Could not unmarshal CombinedPrincipal from principal, err %s", err)
+	}
+	if len(combined.Principals) == 0 {
+		return nil, errors.New("No principals in CombinedPrincipal")
+	}
+
+	var principals []*m.MSPPrincipal
+	for _, cp := range combined.Principals {
+		internal, err := collectPrincipals(cp)
+		if err != nil {
+			return nil, err
+		}
+		principals = append(principals, internal...)
+	}
+	return principals, nil
+}
+
+func (msp *bccspmsp) satisfiesPrincipalInternal(id Identity, principal *m.MSPPrincipal) error {
 	switch principal.PrincipalClassification {
 	// in this case, we have to check whether the
 	// identity has a role in the msp - member or admin
@@ -456,7 +526,34 @@ func (msp *bccspmsp) SatisfiesPrincipal(id Identity, principal *m.MSPPrincipal)
 				}
 			}
 
+			// with NodeOUs, admins can also be identified by the admin OU
+			if msp.ouEnforcement && msp.adminOU != nil {
+				if err := msp.Validate(id); err != nil {
+					return err
+				}
+				if msp.hasOU(id, msp.adminOU) {
+					return nil
+				}
+			}
+
 			return errors.New("This identity is not an admin")
+		case m.MSPRole_CLIENT, m.MSPRole_PEER:
+			mspLogger.Debugf("Checking if identity satisfies %s role for %s", mspRole.Role, msp.name)
+			if !msp.ouEnforcement {
+				return fmt.Errorf("NodeOUs not activated for MSP %s. Cannot tell apart identities.", msp.name)
+			}
+			if err := msp.Validate(id); err != nil {
+				return fmt.Errorf("The identity is not valid under this MSP [%s]: %s", msp.name, err)
+			}
+
+			nodeOU := msp.clientOU
+			if mspRole.Role == m.MSPRole_PEER {
+				nodeOU = msp.peerOU
+			}
+			if !msp.hasOU(id, nodeOU) {
+				return fmt.Errorf("The identity is not a [%s] under this MSP [%s]", mspRole.Role, msp.name)
+			}
+			return nil
 		default:
 			return fmt.Errorf("Invalid MSP role type %d", int32(mspRole.Role))
 		}
@@ -504,6 +601,22 @@ func (msp *bccspmsp) SatisfiesPrincipal(id Identity, principal *m.MSPPrincipal)
 
 		// if we are here, no match was found, return an error
 		return errors.New("The identities do not match")
+	case m.MSPPrincipal_ANONYMITY:
+		anon := &m.MSPIdentityAnonymity{}
+		err := proto.Unmarshal(principal.Principal, anon)
+		if err != nil {
+			return fmt.Errorf("Could not unmarshal MSPIdentityAnonymity from principal, err %s", err)
+		}
+
+		// x509 identities are always nominal
+		switch anon.AnonymityType {
+		case m.MSPIdentityAnonymity_ANONYMOUS:
+			return errors.New("Principal is anonymous, but X.509 MSP does not support anonymous identities")
+		case m.MSPIdentityAnonymity_NOMINAL:
+			return nil
+		default:
+			return fmt.Errorf("Unknown principal anonymity type: %d", anon.AnonymityType)
+		}
 	default:
 		return fmt.Errorf("Invalid principal type %d", int32(principal.PrincipalClassification))
 	}
@@ -790,66 +903,75 @@ func (msp *bccspmsp) setupSigningIdentity(conf *m.FabricMSPConfig) error {
 	return nil
 }
 
-func (msp *bccspmsp) setupOUs(conf *m.FabricMSPConfig) error {
-	msp.ouIdentifiers = make(map[string][][]byte)
-	for _, ou := range conf.OrganizationalUnitIdentifiers {
+func (msp *bccspmsp) getCertifiersIdentifier(certRaw []byte) ([]byte, error) {
+	// 1. check that certificate is registered in msp.rootCerts or msp.intermediateCerts
+	cert, err := msp.getCertFromPem(certRaw)
+	if err != nil {
+		return nil, fmt.Errorf("Failed getting certificate for [%v]: [%s]", certRaw, err)
+	}
 
-		// 1. check that certificate is registered in msp.rootCerts or msp.intermediateCerts
-		cert, err := msp.getCertFromPem(ou.Certificate)
-		if err != nil {
-			return fmt.Errorf("Failed getting certificate for [%v]: [%s]", ou, err)
-		}
+	// 2. Sanitize it to ensure like for like comparison
+	cert, err = msp.sanitizeCert(cert)
+	if err != nil {
+		return nil, fmt.Errorf("sanitizeCert failed %s", err)
+	}
 
-		// 2. Sanitize it to ensure like for like comparison
-		cert, err = msp.sanitizeCert(cert)
-		if err != nil {
-			return fmt.Errorf("sanitizeCert failed %s", err)
+	found := false
+	root := false
+	// Search among root certificates
+	for _, v := range msp.rootCerts {
+		if v.(*identity).cert.Equal(cert) {
+			found = true
+			root = true
+			break
 		}
-
-		found := false
-		root := false
-		// Search among root certificates
-		for _, v := range msp.rootCerts {
+	}
+	if !found {
+		// Search among root intermediate certificates
+		for _, v := range msp.intermediateCerts {
 			if v.(*identity).cert.Equal(cert) {
 				found = true
-				root = true
 				break
 			}
 		}
-		if !found {
-			// Search among root intermediate certificates
-			for _, v := range msp.intermediateCerts {
-				if v.(*identity).cert.Equal(cert) {
-					found = true
-					break
-				}
-			}
-		}
-		if !found {
-			// Certificate not valid, reject configuration
-			return fmt.Errorf("Failed adding OU. Certificate [%v] not in root or intermediate certs.", ou.Certificate)
-		}
+	}
+	if !found {
+		// Certificate not valid, reject configuration
+		return nil, fmt.Errorf("Failed adding OU. Certificate [%v] not in root or intermediate certs.", cert)
+	}
 
-		// 3. get the certification path for it
-		var certifiersIdentifier []byte
-		var chain []*x509.Certificate
-		if root {
-			chain = []*x509.Certificate{cert}
-		} else {
-			chain, err = msp.getValidationChain(cert, true)
-			if err != nil {
-				return fmt.Errorf("Failed computing validation chain for [%v]. [%s]", cert, err)
-			}
+	// 3. get the certification path for it
+	var certifiersIdentifier []byte
+	var chain []*x509.Certificate
+	if root {
+		chain = []*x509.Certificate{cert}
+	} else {
+		chain, err = msp.getValidationChain(cert, true)
+		if err != nil {
+			return nil, fmt.Errorf("Failed computing validation chain for [%v]. [%s]", cert, err)
 		}
+	}
+
+	// 4. compute the hash of the certification path
+	certifiersIdentifier, err = msp.getCertificationChainIdentifierFromChain(chain)
+	if err != nil {
+		return nil, fmt.Errorf("Failed computing Certifiers Identifier for [%v]. [%s]", certRaw, err)
+	}
+
+	return certifiersIdentifier, nil
+}
+
+func (msp *bccspmsp) setupOUs(conf *m.FabricMSPConfig) error {
+	msp.ouIdentifiers = make(map[string][][]byte)
+	for _, ou := range conf.OrganizationalUnitIdentifiers {
 
-		// 4. compute the hash of the certification path
-		certifiersIdentifier, err = msp.getCertificationChainIdentifierFromChain(chain)
+		certifiersIdentifier, err := msp.getCertifiersIdentifier(ou.Certificate)
 		if err != nil {
-			return fmt.Errorf("Failed computing Certifiers Identifier for [%v]. [%s]", ou.Certificate, err)
+			return fmt.Errorf("Failed getting certifiers identifier for [%v]: [%s]", ou, err)
 		}
 
 		// Check for duplicates
-		found = false
+		found := false
 		for _, id := range msp.ouIdentifiers[ou.OrganizationalUnitIdentifier] {
 			if bytes.Equal(id, certifiersIdentifier) {
 				mspLogger.Warningf("Duplicate found in ou identifiers [%s, %v]", ou.OrganizationalUnitIdentifier, id)
@@ -870,6 +992,51 @@ func (msp *bccspmsp) setupOUs(conf *m.FabricMSPConfig) error {
 	return nil
 }
 
+func (msp *bccspmsp) setupNodeOUs(conf *m.FabricMSPConfig) error {
+	msp.ouEnforcement = false
+	msp.clientOU, msp.peerOU, msp.adminOU = nil, nil, nil
+	if conf.FabricNodeOus == nil || !conf.FabricNodeOus.Enable {
+		return nil
+	}
+
+	if conf.FabricNodeOus.ClientOuIdentifier == nil || len(conf.FabricNodeOus.ClientOuIdentifier.OrganizationalUnitIdentifier) == 0 {
+		return errors.New("Failed setting up NodeOUs. ClientOU must be different from nil.")
+	}
+	if conf.FabricNodeOus.PeerOuIdentifier == nil || len(conf.FabricNodeOus.PeerOuIdentifier.OrganizationalUnitIdentifier) == 0 {
+		return errors.New("Failed setting up NodeOUs. PeerOU must be different from nil.")
+	}
+
+	var err error
+	if msp.clientOU, err = msp.getNodeOUIdentifier(conf.FabricNodeOus.ClientOuIdentifier); err != nil {
+		return fmt.Errorf("Failed setting up NodeOUs. Invalid ClientOU: [%s]", err)
+	}
+	if msp.peerOU, err = msp.getNodeOUIdentifier(conf.FabricNodeOus.PeerOuIdentifier); err != nil {
+		return fmt.Errorf("Failed setting up NodeOUs. Invalid PeerOU: [%s]", err)
+	}
+	// the admin OU is optional, admins can still be listed explicitly
+	if conf.FabricNodeOus.AdminOuIdentifier != nil && len(conf.FabricNodeOus.AdminOuIdentifier.OrganizationalUnitIdentifier) != 0 {
+		if msp.adminOU, err = msp.getNodeOUIdentifier(conf.FabricNodeOus.AdminOuIdentifier); err != nil {
+			return fmt.Errorf("Failed setting up NodeOUs. Invalid AdminOU: [%s]", err)
+		}
+	}
+
+	msp.ouEnforcement = true
+	return nil
+}
+
+func (msp *bccspmsp) getNodeOUIdentifier(ou *m.FabricOUIdentifier) (*OUIdentifier, error) {
+	nodeOU := &OUIdentifier{OrganizationalUnitIdentifier: ou.OrganizationalUnitIdentifier}
+	// without a certificate, only the OU itself is matched
+	if len(ou.Certificate) != 0 {
+		certifiersIdentifier, err := msp.getCertifiersIdentifier(ou.Certificate)
+		if err != nil {
+			return nil, err
+		}
+		nodeOU.CertifiersIdentifier = certifiersIdentifier
+	}
+	return nodeOU, nil
+}
+
 func (msp *bccspmsp) setupTLSCAs(conf *m.FabricMSPConfig) error {
 
 	opts := &x509.VerifyOptions{Roots: x509.NewCertPool(), Intermediates: x509.NewCertPool()}
@@ -1094,9 +1261,37 @@ func (msp *bccspmsp) validateIdentityOUs(id *identity) error {
 		}
 	}
 
+	// With NodeOUs enabled, the identity must be exactly one of
+	// client, peer or admin
+	if msp.ouEnforcement {
+		counter := 0
+		for _, nodeOU := range []*OUIdentifier{msp.clientOU, msp.peerOU, msp.adminOU} {
+			if nodeOU != nil && msp.hasOU(id, nodeOU) {
+				counter++
+			}
+		}
+		if counter != 1 {
+			return fmt.Errorf("The identity must be a client, a peer or an admin identity to be valid, not a combination of them. OUs: [%v], MSP: [%s]", id.GetOrganizationalUnits(), msp.name)
+		}
+	}
+
 	return nil
 }
 
+// hasOU returns whether the identity carries the given OU, certified by the
+// given chain of trust when the OU identifier specifies one
+func (msp *bccspmsp) hasOU(id Identity, nodeOU *OUIdentifier) bool {
+	for _, OU := range id.GetOrganizationalUnits() {
+		if OU.OrganizationalUnitIdentifier != nodeOU.OrganizationalUnitIdentifier {
+			continue
+		}
+		if len(nodeOU.CertifiersIdentifier) == 0 || bytes.Equal(nodeOU.CertifiersIdentifier, OU.CertifiersIdentifier) {
+			return true
+		}
+	}
+	return false
+}
+
 func (msp *bccspmsp) getValidityOptsForCert(cert *x509.Certificate) x509.VerifyOptions {
 	// First copy the opts to override the CurrentTime field
 	// in order to make the certificate passing the expiration test
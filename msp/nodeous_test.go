@@ -0,0 +1,139 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package msp
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/protos/msp"
+	"github.com/stretchr/testify/assert"
+)
+
+// testdata/nodeous:
+// the configuration enables NodeOUs with OU=client (certified by the root CA),
+// OU=peer and OU=admin; the users folder contains a certificate for each of
+// them, one with both the client and the peer OUs and one with none of them
+func getNodeOUsMSP(t *testing.T, conf *msp.MSPConfig) MSP {
+	thisMSP, err := NewBccspMsp()
+	assert.NoError(t, err)
+	assert.NoError(t, thisMSP.Setup(conf))
+	return thisMSP
+}
+
+func getNodeOUsIdentity(t *testing.T, thisMSP MSP, name string) Identity {
+	pem, err := readPemFile(filepath.Join("testdata", "nodeous", "users", name))
+	assert.NoError(t, err)
+	id, _, err := thisMSP.(*bccspmsp).getIdentityFromConf(pem)
+	assert.NoError(t, err)
+	return id
+}
+
+func rolePrincipal(t *testing.T, role msp.MSPRole_MSPRoleType) *msp.MSPPrincipal {
+	principalBytes, err := proto.Marshal(&msp.MSPRole{Role: role, MspIdentifier: "DEFAULT"})
+	assert.NoError(t, err)
+	return &msp.MSPPrincipal{
+		PrincipalClassification: msp.MSPPrincipal_ROLE,
+		Principal:               principalBytes}
+}
+
+func TestNodeOUs(t *testing.T) {
+	conf, err := GetVerifyingMspConfig(filepath.Join("testdata", "nodeous"), "DEFAULT")
+	assert.NoError(t, err)
+	thisMSP := getNodeOUsMSP(t, conf)
+
+	client := getNodeOUsIdentity(t, thisMSP, "client.pem")
+	peer := getNodeOUsIdentity(t, thisMSP, "peer.pem")
+	admin := getNodeOUsIdentity(t, thisMSP, "adminou.pem")
+
+	for _, id := range []Identity{client, peer, admin} {
+		assert.NoError(t, thisMSP.Validate(id))
+		assert.NoError(t, thisMSP.SatisfiesPrincipal(id, rolePrincipal(t, msp.MSPRole_MEMBER)))
+	}
+
+	assert.NoError(t, thisMSP.SatisfiesPrincipal(client, rolePrincipal(t, msp.MSPRole_CLIENT)))
+	assert.Error(t, thisMSP.SatisfiesPrincipal(client, rolePrincipal(t, msp.MSPRole_PEER)))
+	assert.Error(t, thisMSP.SatisfiesPrincipal(client, rolePrincipal(t, msp.MSPRole_ADMIN)))
+
+	assert.NoError(t, thisMSP.SatisfiesPrincipal(peer, rolePrincipal(t, msp.MSPRole_PEER)))
+	assert.Error(t, thisMSP.SatisfiesPrincipal(peer, rolePrincipal(t, msp.MSPRole_CLIENT)))
+	assert.Error(t, thisMSP.SatisfiesPrincipal(peer, rolePrincipal(t, msp.MSPRole_ADMIN)))
+
+	assert.NoError(t, thisMSP.SatisfiesPrincipal(admin, rolePrincipal(t, msp.MSPRole_ADMIN)))
+	assert.Error(t, thisMSP.SatisfiesPrincipal(admin, rolePrincipal(t, msp.MSPRole_CLIENT)))
+	assert.Error(t, thisMSP.SatisfiesPrincipal(admin, rolePrincipal(t, msp.MSPRole_PEER)))
+}
+
+func TestNodeOUsInvalidIdentities(t *testing.T) {
+	conf, err := GetVerifyingMspConfig(filepath.Join("testdata", "nodeous"), "DEFAULT")
+	assert.NoError(t, err)
+	thisMSP := getNodeOUsMSP(t, conf)
+
+	// an identity must be exactly one of client, peer or admin
+	for _, name := range []string{"clientpeer.pem", "none.pem"} {
+		id := getNodeOUsIdentity(t, thisMSP, name)
+		assert.Error(t, thisMSP.Validate(id), "Identity %s should be invalid", name)
+		assert.Error(t, thisMSP.SatisfiesPrincipal(id, rolePrincipal(t, msp.MSPRole_MEMBER)))
+		assert.Error(t, thisMSP.SatisfiesPrincipal(id, rolePrincipal(t, msp.MSPRole_CLIENT)))
+		assert.Error(t, thisMSP.SatisfiesPrincipal(id, rolePrincipal(t, msp.MSPRole_PEER)))
+	}
+}
+
+func TestNodeOUsDisabled(t *testing.T) {
+	conf, err := GetVerifyingMspConfig(filepath.Join("testdata", "nodeous"), "DEFAULT")
+	assert.NoError(t, err)
+	fabricConf := &msp.FabricMSPConfig{}
+	assert.NoError(t, proto.Unmarshal(conf.Config, fabricConf))
+	fabricConf.FabricNodeOus.Enable = false
+	conf.Config, err = proto.Marshal(fabricConf)
+	assert.NoError(t, err)
+	thisMSP := getNodeOUsMSP(t, conf)
+
+	// without NodeOUs any identity is a valid member, but roles cannot be told apart
+	id := getNodeOUsIdentity(t, thisMSP, "clientpeer.pem")
+	assert.NoError(t, thisMSP.Validate(id))
+	assert.Error(t, thisMSP.SatisfiesPrincipal(id, rolePrincipal(t, msp.MSPRole_CLIENT)))
+	assert.Error(t, thisMSP.SatisfiesPrincipal(id, rolePrincipal(t, msp.MSPRole_PEER)))
+}
+
+func TestNodeOUsBadConfig(t *testing.T) {
+	conf, err := GetVerifyingMspConfig(filepath.Join("testdata", "nodeous"), "DEFAULT")
+	assert.NoError(t, err)
+	fabricConf := &msp.FabricMSPConfig{}
+	assert.NoError(t, proto.Unmarshal(conf.Config, fabricConf))
+
+	setup := func(nodeOUs *msp.FabricNodeOUs) error {
+		badConf := proto.Clone(fabricConf).(*msp.FabricMSPConfig)
+		badConf.FabricNodeOus = nodeOUs
+		raw, err := proto.Marshal(badConf)
+		assert.NoError(t, err)
+		thisMSP, err := NewBccspMsp()
+		assert.NoError(t, err)
+		return thisMSP.Setup(&msp.MSPConfig{Type: conf.Type, Config: raw})
+	}
+
+	// the client and peer OUs are mandatory
+	assert.Error(t, setup(&msp.FabricNodeOUs{Enable: true, PeerOuIdentifier: fabricConf.FabricNodeOus.PeerOuIdentifier}))
+	assert.Error(t, setup(&msp.FabricNodeOUs{Enable: true, ClientOuIdentifier: fabricConf.FabricNodeOus.ClientOuIdentifier}))
+
+	// the certificate of an OU identifier must be a CA of the MSP
+	admincert, err := readPemFile(filepath.Join("testdata", "nodeous", "admincerts", "admin.pem"))
+	assert.NoError(t, err)
+	assert.Error(t, setup(&msp.FabricNodeOUs{
+		Enable:             true,
+		ClientOuIdentifier: &msp.FabricOUIdentifier{Certificate: admincert, OrganizationalUnitIdentifier: "client"},
+		PeerOuIdentifier:   fabricConf.FabricNodeOus.PeerOuIdentifier,
+	}))
+
+	// the admins listed explicitly must be valid under NodeOUs too
+	assert.Error(t, setup(&msp.FabricNodeOUs{
+		Enable:             true,
+		ClientOuIdentifier: &msp.FabricOUIdentifier{OrganizationalUnitIdentifier: "notclient"},
+		PeerOuIdentifier:   fabricConf.FabricNodeOus.PeerOuIdentifier,
+	}))
+}
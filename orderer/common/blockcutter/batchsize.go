@@ -0,0 +1,49 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package blockcutter
+
+import (
+	"github.com/hyperledger/fabric/common/config/channel"
+	cb "github.com/hyperledger/fabric/protos/common"
+)
+
+// BatchSizePolicyName is the name of the policy cutting batches according to the
+// BatchSize of the channel, which the orderer uses unless configured otherwise
+const BatchSizePolicyName = "batchsize"
+
+type batchSizePolicy struct {
+	sharedConfigManager config.Orderer
+}
+
+// NewBatchSizePolicy creates a Policy which isolates the messages larger than
+// BatchSize.PreferredMaxBytes, and cuts a batch before it exceeds PreferredMaxBytes
+// or once it holds MaxMessageCount messages
+func NewBatchSizePolicy(sharedConfigManager config.Orderer) Policy {
+	return &batchSizePolicy{sharedConfigManager: sharedConfigManager}
+}
+
+func (p *batchSizePolicy) Isolate(msg *cb.Envelope) bool {
+	messageSizeBytes := messageSizeBytes(msg)
+	if messageSizeBytes > p.sharedConfigManager.BatchSize().PreferredMaxBytes {
+		logger.Debugf("The current message, with %v bytes, is larger than the preferred batch size of %v bytes and will be isolated.", messageSizeBytes, p.sharedConfigManager.BatchSize().PreferredMaxBytes)
+		return true
+	}
+	return false
+}
+
+func (p *batchSizePolicy) CutBefore(pending []*cb.Envelope, pendingSizeBytes uint32, msg *cb.Envelope) bool {
+	messageSizeBytes := messageSizeBytes(msg)
+	if pendingSizeBytes+messageSizeBytes > p.sharedConfigManager.BatchSize().PreferredMaxBytes {
+		logger.Debugf("The current message, with %v bytes, will overflow the pending batch of %v bytes.", messageSizeBytes, pendingSizeBytes)
+		return true
+	}
+	return false
+}
+
+func (p *batchSizePolicy) CutAfter(pending []*cb.Envelope, pendingSizeBytes uint32) bool {
+	return uint32(len(pending)) >= p.sharedConfigManager.BatchSize().MaxMessageCount
+}
@@ -18,6 +18,7 @@ package blockcutter
 
 import (
 	"github.com/hyperledger/fabric/common/config/channel"
+	"github.com/hyperledger/fabric/common/metrics"
 	cb "github.com/hyperledger/fabric/protos/common"
 
 	"github.com/op/go-logging"
@@ -37,16 +38,40 @@ type Receiver interface {
 	Cut() []*cb.Envelope
 }
 
+// The reasons a batch is cut for, with which the cut batches are counted
+const (
+	cutReasonIsolated = "isolated"
+	cutReasonOverflow = "overflow"
+	cutReasonFull     = "full"
+	cutReasonExternal = "external"
+)
+
 type receiver struct {
 	sharedConfigManager   config.Orderer
+	policy                Policy
 	pendingBatch          []*cb.Envelope
 	pendingBatchSizeBytes uint32
+
+	scope     metrics.Scope
+	messages  metrics.Counter
+	fillRatio metrics.Gauge
 }
 
 // NewReceiverImpl creates a Receiver implementation based on the given configtxorderer manager
 func NewReceiverImpl(sharedConfigManager config.Orderer) Receiver {
+	return NewReceiver(sharedConfigManager, NewBatchSizePolicy(sharedConfigManager), metrics.NewRootScope().SubScope("blockcutter"))
+}
+
+// NewReceiver creates a Receiver implementation cutting batches according to the given policy.
+// It counts the ordered messages and the batches cut for each reason, and reports how full the
+// batches are relative to the BatchSize.MaxMessageCount of the channel, in the given scope
+func NewReceiver(sharedConfigManager config.Orderer, policy Policy, scope metrics.Scope) Receiver {
 	return &receiver{
 		sharedConfigManager: sharedConfigManager,
+		policy:              policy,
+		scope:               scope,
+		messages:            scope.Counter("messages"),
+		fillRatio:           scope.Gauge("batch_fill_ratio"),
 	}
 }
 
@@ -57,49 +82,48 @@ func NewReceiverImpl(sharedConfigManager config.Orderer) Receiver {
 // messageBatches length: 0, pending: true
 //   - no batch is cut and there are messages pending
 // messageBatches length: 1, pending: false
-//   - the message count reaches BatchSize.MaxMessageCount
+//   - the policy cuts the batch once the current message is enqueued
 // messageBatches length: 1, pending: true
-//   - the current message will cause the pending batch size in bytes to exceed BatchSize.PreferredMaxBytes.
+//   - the policy cuts the pending batch before the current message is enqueued
 // messageBatches length: 2, pending: false
-//   - the current message size in bytes exceeds BatchSize.PreferredMaxBytes, therefore isolated in its own batch.
+//   - the policy isolates the current message in its own batch.
 // messageBatches length: 2, pending: true
 //   - impossible
 //
 // Note that messageBatches can not be greater than 2.
 func (r *receiver) Ordered(msg *cb.Envelope) (messageBatches [][]*cb.Envelope, pending bool) {
-	messageSizeBytes := messageSizeBytes(msg)
-	if messageSizeBytes > r.sharedConfigManager.BatchSize().PreferredMaxBytes {
-		logger.Debugf("The current message, with %v bytes, is larger than the preferred batch size of %v bytes and will be isolated.", messageSizeBytes, r.sharedConfigManager.BatchSize().PreferredMaxBytes)
+	r.messages.Inc(1)
+
+	if r.policy.Isolate(msg) {
+		logger.Debugf("The current message will be isolated.")
 
 		// cut pending batch, if it has any messages
 		if len(r.pendingBatch) > 0 {
-			messageBatch := r.Cut()
+			messageBatch := r.cut(cutReasonIsolated)
 			messageBatches = append(messageBatches, messageBatch)
 		}
 
 		// create new batch with single message
 		messageBatches = append(messageBatches, []*cb.Envelope{msg})
+		r.recordBatch(messageBatches[len(messageBatches)-1], cutReasonIsolated)
 
 		return
 	}
 
-	messageWillOverflowBatchSizeBytes := r.pendingBatchSizeBytes+messageSizeBytes > r.sharedConfigManager.BatchSize().PreferredMaxBytes
-
-	if messageWillOverflowBatchSizeBytes {
-		logger.Debugf("The current message, with %v bytes, will overflow the pending batch of %v bytes.", messageSizeBytes, r.pendingBatchSizeBytes)
+	if len(r.pendingBatch) > 0 && r.policy.CutBefore(r.pendingBatch, r.pendingBatchSizeBytes, msg) {
 		logger.Debugf("Pending batch would overflow if current message is added, cutting batch now.")
-		messageBatch := r.Cut()
+		messageBatch := r.cut(cutReasonOverflow)
 		messageBatches = append(messageBatches, messageBatch)
 	}
 
 	logger.Debugf("Enqueuing message into batch")
 	r.pendingBatch = append(r.pendingBatch, msg)
-	r.pendingBatchSizeBytes += messageSizeBytes
+	r.pendingBatchSizeBytes += messageSizeBytes(msg)
 	pending = true
 
-	if uint32(len(r.pendingBatch)) >= r.sharedConfigManager.BatchSize().MaxMessageCount {
+	if r.policy.CutAfter(r.pendingBatch, r.pendingBatchSizeBytes) {
 		logger.Debugf("Batch size met, cutting batch")
-		messageBatch := r.Cut()
+		messageBatch := r.cut(cutReasonFull)
 		messageBatches = append(messageBatches, messageBatch)
 		pending = false
 	}
@@ -109,12 +133,27 @@ func (r *receiver) Ordered(msg *cb.Envelope) (messageBatches [][]*cb.Envelope, p
 
 // Cut returns the current batch and starts a new one
 func (r *receiver) Cut() []*cb.Envelope {
+	return r.cut(cutReasonExternal)
+}
+
+func (r *receiver) cut(reason string) []*cb.Envelope {
 	batch := r.pendingBatch
 	r.pendingBatch = nil
 	r.pendingBatchSizeBytes = 0
+	r.recordBatch(batch, reason)
 	return batch
 }
 
+func (r *receiver) recordBatch(batch []*cb.Envelope, reason string) {
+	if len(batch) == 0 {
+		return
+	}
+	r.scope.Tagged(map[string]string{"reason": reason}).Counter("batches").Inc(1)
+	if maxMessageCount := r.sharedConfigManager.BatchSize().MaxMessageCount; maxMessageCount > 0 {
+		r.fillRatio.Update(float64(len(batch)) / float64(maxMessageCount))
+	}
+}
+
 func messageSizeBytes(message *cb.Envelope) uint32 {
 	return uint32(len(message.Payload) + len(message.Signature))
 }
@@ -19,7 +19,9 @@ package blockcutter
 import (
 	"testing"
 
+	"github.com/hyperledger/fabric/common/config/channel"
 	mockconfig "github.com/hyperledger/fabric/common/mocks/config"
+	mockmetrics "github.com/hyperledger/fabric/common/mocks/metrics"
 	cb "github.com/hyperledger/fabric/protos/common"
 	ab "github.com/hyperledger/fabric/protos/orderer"
 
@@ -105,3 +107,88 @@ func TestBatchSizePreferredMaxBytesOverflowNoPending(t *testing.T) {
 		assert.Len(t, batch, 1, "Should have had one normal tx in batch %d", i)
 	}
 }
+
+// quotaPolicy cuts a batch once it holds the given number of messages with the same payload
+type quotaPolicy struct {
+	quota int
+}
+
+func (p *quotaPolicy) Isolate(msg *cb.Envelope) bool {
+	return false
+}
+
+func (p *quotaPolicy) CutBefore(pending []*cb.Envelope, pendingSizeBytes uint32, msg *cb.Envelope) bool {
+	return false
+}
+
+func (p *quotaPolicy) CutAfter(pending []*cb.Envelope, pendingSizeBytes uint32) bool {
+	last := pending[len(pending)-1]
+	count := 0
+	for _, msg := range pending {
+		if string(msg.Payload) == string(last.Payload) {
+			count++
+		}
+	}
+	return count >= p.quota
+}
+
+func TestCustomPolicy(t *testing.T) {
+	other := &cb.Envelope{Payload: []byte("OTHER")}
+	r := NewReceiver(&mockconfig.Orderer{BatchSizeVal: &ab.BatchSize{MaxMessageCount: 10}}, &quotaPolicy{quota: 2}, mockmetrics.NewScope())
+
+	batches, pending := r.Ordered(tx)
+	assert.Nil(t, batches, "Should not have created batch")
+	assert.True(t, pending, "Should have message pending in the receiver")
+
+	batches, pending = r.Ordered(other)
+	assert.Nil(t, batches, "Should not have created batch")
+	assert.True(t, pending, "Should have message pending in the receiver")
+
+	batches, pending = r.Ordered(tx)
+	assert.Len(t, batches, 1, "Should have created one batch")
+	assert.Equal(t, []*cb.Envelope{tx, other, tx}, batches[0], "Should have cut the batch once the quota was met")
+	assert.False(t, pending, "Should not have message pending in the receiver")
+}
+
+func TestRegisterPolicy(t *testing.T) {
+	factory := func(config.Orderer) Policy { return &quotaPolicy{quota: 1} }
+	assert.Error(t, RegisterPolicy(BatchSizePolicyName, factory))
+	assert.NoError(t, RegisterPolicy("TestRegisterPolicy", factory))
+	assert.Error(t, RegisterPolicy("TestRegisterPolicy", factory))
+
+	registered, ok := LookupPolicy("TestRegisterPolicy")
+	assert.True(t, ok, "Should have found the registered policy")
+	assert.Equal(t, &quotaPolicy{quota: 1}, registered(&mockconfig.Orderer{}))
+	_, ok = LookupPolicy("Missing")
+	assert.False(t, ok, "Should not have found an unregistered policy")
+}
+
+func TestLoadPolicyPlugin(t *testing.T) {
+	assert.Error(t, LoadPolicyPlugin("Missing", "/does/not/exist.so"))
+}
+
+func TestReceiverMetrics(t *testing.T) {
+	txBytes := messageSizeBytes(tx)
+	scope := mockmetrics.NewScope()
+	sharedConfig := &mockconfig.Orderer{BatchSizeVal: &ab.BatchSize{MaxMessageCount: 4, AbsoluteMaxBytes: 1000, PreferredMaxBytes: txBytes * 3}}
+	r := NewReceiver(sharedConfig, NewBatchSizePolicy(sharedConfig), scope)
+
+	// the fourth message overflows the preferred max bytes
+	for i := 0; i < 4; i++ {
+		r.Ordered(tx)
+	}
+	assert.Equal(t, 0.75, scope.GaugeOf("batch_fill_ratio", nil).Value())
+	// the large message is isolated, cutting the pending message along
+	r.Ordered(txLarge)
+	assert.Equal(t, 0.25, scope.GaugeOf("batch_fill_ratio", nil).Value())
+	r.Ordered(tx)
+	r.Cut()
+	// an empty batch is not counted
+	r.Cut()
+
+	assert.Equal(t, int64(6), scope.CounterOf("messages", nil).Value())
+	assert.Equal(t, int64(1), scope.CounterOf("batches", map[string]string{"reason": cutReasonOverflow}).Value())
+	assert.Equal(t, int64(2), scope.CounterOf("batches", map[string]string{"reason": cutReasonIsolated}).Value())
+	assert.Equal(t, int64(0), scope.CounterOf("batches", map[string]string{"reason": cutReasonFull}).Value())
+	assert.Equal(t, int64(1), scope.CounterOf("batches", map[string]string{"reason": cutReasonExternal}).Value())
+}
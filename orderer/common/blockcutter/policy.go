@@ -0,0 +1,84 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package blockcutter
+
+import (
+	"fmt"
+	"plugin"
+	"sync"
+
+	"github.com/hyperledger/fabric/common/config/channel"
+	cb "github.com/hyperledger/fabric/protos/common"
+)
+
+// PolicyPluginSymbol is the name of the function a cutting policy plugin must export.
+// The function must be of type func(config.Orderer) blockcutter.Policy
+const PolicyPluginSymbol = "NewPolicy"
+
+// Policy decides where the ordered messages are cut into batches. The receiver consults
+// it for every message, so that cutting rules other than the batch size of the channel,
+// such as per-chaincode quotas, may be tried out without changing the receiver.
+type Policy interface {
+	// Isolate returns whether the message is ordered in a batch of its own
+	Isolate(msg *cb.Envelope) bool
+
+	// CutBefore returns whether the pending batch is cut before the message is enqueued
+	CutBefore(pending []*cb.Envelope, pendingSizeBytes uint32, msg *cb.Envelope) bool
+
+	// CutAfter returns whether the pending batch is cut once its last message is enqueued
+	CutAfter(pending []*cb.Envelope, pendingSizeBytes uint32) bool
+}
+
+// PolicyFactory creates the cutting policy of a channel from its orderer config
+type PolicyFactory func(sharedConfigManager config.Orderer) Policy
+
+var policyRegistry = struct {
+	lock      sync.RWMutex
+	factories map[string]PolicyFactory
+}{
+	factories: map[string]PolicyFactory{
+		BatchSizePolicyName: NewBatchSizePolicy,
+	},
+}
+
+// RegisterPolicy makes the cutting policy created by the given factory available under the
+// given name. It returns an error if a policy is already registered under the name
+func RegisterPolicy(name string, factory PolicyFactory) error {
+	policyRegistry.lock.Lock()
+	defer policyRegistry.lock.Unlock()
+	if _, ok := policyRegistry.factories[name]; ok {
+		return fmt.Errorf("cutting policy %s is already registered", name)
+	}
+	policyRegistry.factories[name] = factory
+	return nil
+}
+
+// LoadPolicyPlugin opens the Go plugin at the given path and registers the cutting policy
+// created by its PolicyPluginSymbol function under the given name
+func LoadPolicyPlugin(name, path string) error {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return fmt.Errorf("could not open cutting policy plugin %s: %s", path, err)
+	}
+	symbol, err := p.Lookup(PolicyPluginSymbol)
+	if err != nil {
+		return fmt.Errorf("could not find %s in cutting policy plugin %s: %s", PolicyPluginSymbol, path, err)
+	}
+	newPolicy, ok := symbol.(func(config.Orderer) Policy)
+	if !ok {
+		return fmt.Errorf("%s in cutting policy plugin %s is of type %T, not a cutting policy factory", PolicyPluginSymbol, path, symbol)
+	}
+	return RegisterPolicy(name, newPolicy)
+}
+
+// LookupPolicy returns the factory of the cutting policy registered under the given name
+func LookupPolicy(name string) (PolicyFactory, bool) {
+	policyRegistry.lock.RLock()
+	defer policyRegistry.lock.RUnlock()
+	factory, ok := policyRegistry.factories[name]
+	return factory, ok
+}
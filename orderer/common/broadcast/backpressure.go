@@ -0,0 +1,142 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package broadcast
+
+import (
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric/common/metrics"
+	cb "github.com/hyperledger/fabric/protos/common"
+
+	"github.com/pkg/errors"
+)
+
+// ErrQueueFull is returned when a message is broadcast to a channel whose ingress queue is full
+var ErrQueueFull = errors.New("ingress queue is full")
+
+// NewBackpressureRegistrar returns a ChannelSupportRegistrar whose channels hold at most depth messages
+// at a time, from the moment a message starts being processed until it has been passed on to consensus.
+// The messages broadcast to a channel whose queue is full are rejected with SERVICE_UNAVAILABLE and a
+// hint to retry after the given duration, so that the orderer sheds load instead of piling up messages
+func NewBackpressureRegistrar(sm ChannelSupportRegistrar, depth int, retryAfter time.Duration) ChannelSupportRegistrar {
+	return &backpressureRegistrar{
+		ChannelSupportRegistrar: sm,
+		depth:                   depth,
+		retryAfter:              retryAfter,
+		scope:                   metrics.NewRootScope().SubScope("broadcast"),
+		queues:                  make(map[string]*ingressQueue),
+	}
+}
+
+type backpressureRegistrar struct {
+	ChannelSupportRegistrar
+	depth      int
+	retryAfter time.Duration
+	scope      metrics.Scope
+
+	mutex  sync.Mutex
+	queues map[string]*ingressQueue
+}
+
+func (r *backpressureRegistrar) BroadcastChannelSupport(msg *cb.Envelope) (*cb.ChannelHeader, bool, ChannelSupport, error) {
+	chdr, isConfig, cs, err := r.ChannelSupportRegistrar.BroadcastChannelSupport(msg)
+	if err != nil {
+		return chdr, isConfig, cs, err
+	}
+	return chdr, isConfig, &backpressureChannelSupport{ChannelSupport: cs, queue: r.ingressQueue(chdr.ChannelId)}, nil
+}
+
+func (r *backpressureRegistrar) ingressQueue(channelID string) *ingressQueue {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	q, ok := r.queues[channelID]
+	if !ok {
+		q = newIngressQueue(channelID, r.depth, r.retryAfter, r.scope.Tagged(map[string]string{"channel": channelID}))
+		r.queues[channelID] = q
+	}
+	return q
+}
+
+// backpressureChannelSupport takes a place in the ingress queue of the channel when a message starts
+// being processed, and gives it back once the message has been rejected or passed on to consensus.
+// The handler passes every message that was processed successfully on to consensus
+type backpressureChannelSupport struct {
+	ChannelSupport
+	queue *ingressQueue
+}
+
+func (cs *backpressureChannelSupport) ProcessNormalMsg(env *cb.Envelope) (uint64, error) {
+	if err := cs.queue.enter(); err != nil {
+		return 0, err
+	}
+	configSeq, err := cs.ChannelSupport.ProcessNormalMsg(env)
+	if err != nil {
+		cs.queue.leave()
+	}
+	return configSeq, err
+}
+
+func (cs *backpressureChannelSupport) ProcessConfigUpdateMsg(env *cb.Envelope) (*cb.Envelope, uint64, error) {
+	if err := cs.queue.enter(); err != nil {
+		return nil, 0, err
+	}
+	config, configSeq, err := cs.ChannelSupport.ProcessConfigUpdateMsg(env)
+	if err != nil {
+		cs.queue.leave()
+	}
+	return config, configSeq, err
+}
+
+func (cs *backpressureChannelSupport) Order(env *cb.Envelope, configSeq uint64) error {
+	defer cs.queue.leave()
+	return cs.ChannelSupport.Order(env, configSeq)
+}
+
+func (cs *backpressureChannelSupport) Configure(configUpdateMsg *cb.Envelope, config *cb.Envelope, configSeq uint64) error {
+	defer cs.queue.leave()
+	return cs.ChannelSupport.Configure(configUpdateMsg, config, configSeq)
+}
+
+// ingressQueue bounds the number of messages of a channel in flight
+type ingressQueue struct {
+	channelID  string
+	retryAfter time.Duration
+	slots      chan struct{}
+
+	depth      metrics.Gauge
+	rejections metrics.Counter
+}
+
+func newIngressQueue(channelID string, depth int, retryAfter time.Duration, scope metrics.Scope) *ingressQueue {
+	return &ingressQueue{
+		channelID:  channelID,
+		retryAfter: retryAfter,
+		slots:      make(chan struct{}, depth),
+		depth:      scope.Gauge("queue_depth"),
+		rejections: scope.Counter("queue_full_rejections"),
+	}
+}
+
+// enter takes a place in the queue, or returns an error wrapping ErrQueueFull if there is none left
+func (q *ingressQueue) enter() error {
+	select {
+	case q.slots <- struct{}{}:
+		q.depth.Update(float64(len(q.slots)))
+		return nil
+	default:
+		q.rejections.Inc(1)
+		logger.Debugf("[channel: %s] Ingress queue is full with %d messages", q.channelID, cap(q.slots))
+		return errors.Wrapf(errors.WithStack(ErrQueueFull), "channel %s has %d messages in flight, retry after %s",
+			q.channelID, cap(q.slots), q.retryAfter)
+	}
+}
+
+func (q *ingressQueue) leave() {
+	<-q.slots
+	q.depth.Update(float64(len(q.slots)))
+}
@@ -0,0 +1,74 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package broadcast
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	cb "github.com/hyperledger/fabric/protos/common"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackpressureRegistrar(t *testing.T) {
+	mm := getMockSupportManager()
+	r := NewBackpressureRegistrar(mm, 2, 50*time.Millisecond)
+
+	_, _, cs1, err := r.BroadcastChannelSupport(nil)
+	assert.NoError(t, err)
+	_, _, cs2, _ := r.BroadcastChannelSupport(nil)
+	assert.Equal(t, cs1.(*backpressureChannelSupport).queue, cs2.(*backpressureChannelSupport).queue,
+		"Messages of a channel should share an ingress queue")
+
+	_, err = cs1.ProcessNormalMsg(nil)
+	assert.NoError(t, err)
+	_, _, err = cs2.ProcessConfigUpdateMsg(nil)
+	assert.NoError(t, err)
+
+	_, err = cs1.ProcessNormalMsg(nil)
+	assert.Equal(t, ErrQueueFull, errors.Cause(err))
+	assert.Regexp(t, "retry after 50ms", err.Error())
+	assert.Equal(t, cb.Status_SERVICE_UNAVAILABLE, ClassifyError(err))
+
+	// Ordering a message makes room for the next one
+	assert.NoError(t, cs1.Order(nil, 0))
+	_, err = cs1.ProcessNormalMsg(nil)
+	assert.NoError(t, err)
+	assert.NoError(t, cs2.Configure(nil, nil, 0))
+
+	// A rejected message gives its place back
+	mm.MsgProcessorVal.ProcessErr = fmt.Errorf("Rejected")
+	_, err = cs1.ProcessNormalMsg(nil)
+	assert.EqualError(t, err, "Rejected")
+	assert.Len(t, cs1.(*backpressureChannelSupport).queue.slots, 1)
+
+	mm.MsgProcessorErr = fmt.Errorf("Error")
+	_, _, _, err = r.BroadcastChannelSupport(nil)
+	assert.Error(t, err)
+}
+
+func TestBackpressureHandler(t *testing.T) {
+	mm := getMockSupportManager()
+	r := NewBackpressureRegistrar(mm, 1, time.Second)
+	bh := NewHandlerImpl(r)
+
+	// Fill the queue of the channel with a message being processed elsewhere
+	_, _, cs, _ := r.BroadcastChannelSupport(nil)
+	_, err := cs.ProcessNormalMsg(nil)
+	assert.NoError(t, err)
+
+	m := newMockB()
+	defer close(m.recvChan)
+	go bh.Handle(m)
+	m.recvChan <- nil
+	reply := <-m.sendChan
+	assert.Equal(t, cb.Status_SERVICE_UNAVAILABLE, reply.Status)
+	assert.Regexp(t, "retry after 1s", reply.Info)
+}
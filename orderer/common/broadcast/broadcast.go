@@ -7,8 +7,12 @@ SPDX-License-Identifier: Apache-2.0
 package broadcast
 
 import (
+	"fmt"
 	"io"
+	"time"
 
+	"github.com/hyperledger/fabric/common/metrics"
+	"github.com/hyperledger/fabric/msp"
 	"github.com/hyperledger/fabric/orderer/common/msgprocessor"
 	cb "github.com/hyperledger/fabric/protos/common"
 	ab "github.com/hyperledger/fabric/protos/orderer"
@@ -51,13 +55,16 @@ type Consenter interface {
 }
 
 type handlerImpl struct {
-	sm ChannelSupportRegistrar
+	sm       ChannelSupportRegistrar
+	receipts ReceiptSupportRegistrar
+	scope    metrics.Scope
 }
 
 // NewHandlerImpl constructs a new implementation of the Handler interface
 func NewHandlerImpl(sm ChannelSupportRegistrar) Handler {
 	return &handlerImpl{
-		sm: sm,
+		sm:    sm,
+		scope: metrics.NewRootScope().SubScope("broadcast"),
 	}
 }
 
@@ -75,39 +82,44 @@ func (bh *handlerImpl) Handle(srv ab.AtomicBroadcast_BroadcastServer) error {
 			return err
 		}
 
+		received := time.Now()
 		chdr, isConfig, processor, err := bh.sm.BroadcastChannelSupport(msg)
 		if err != nil {
 			logger.Warningf("[channel: %s] Could not get message processor: %s", chdr.ChannelId, err)
-			return srv.Send(&ab.BroadcastResponse{Status: cb.Status_INTERNAL_SERVER_ERROR, Info: err.Error()})
+			return bh.respond(srv, chdr, isConfig, received, &ab.BroadcastResponse{Status: cb.Status_INTERNAL_SERVER_ERROR, Info: err.Error()})
 		}
 
+		var configSeq uint64
+		enqueued := msg
 		if !isConfig {
 			logger.Debugf("[channel: %s] Broadcast is processing normal message with txid '%s' of type %s", chdr.ChannelId, chdr.TxId, cb.HeaderType_name[chdr.Type])
 
-			configSeq, err := processor.ProcessNormalMsg(msg)
+			configSeq, err = processor.ProcessNormalMsg(msg)
 			if err != nil {
 				logger.Warningf("[channel: %s] Rejecting broadcast of normal message because of error: %s", chdr.ChannelId, err)
-				return srv.Send(&ab.BroadcastResponse{Status: ClassifyError(err), Info: err.Error()})
+				return bh.respond(srv, chdr, isConfig, received, &ab.BroadcastResponse{Status: ClassifyError(err), Info: err.Error()})
 			}
 
 			err = processor.Order(msg, configSeq)
 			if err != nil {
 				logger.Warningf("[channel: %s] Rejecting broadcast of normal message with SERVICE_UNAVAILABLE: rejected by Order: %s", chdr.ChannelId, err)
-				return srv.Send(&ab.BroadcastResponse{Status: cb.Status_SERVICE_UNAVAILABLE, Info: err.Error()})
+				return bh.respond(srv, chdr, isConfig, received, &ab.BroadcastResponse{Status: cb.Status_SERVICE_UNAVAILABLE, Info: err.Error()})
 			}
 		} else { // isConfig
 			logger.Debugf("[channel: %s] Broadcast is processing config update message", chdr.ChannelId)
 
-			config, configSeq, err := processor.ProcessConfigUpdateMsg(msg)
+			var config *cb.Envelope
+			config, configSeq, err = processor.ProcessConfigUpdateMsg(msg)
 			if err != nil {
 				logger.Warningf("[channel: %s] Rejecting broadcast of config message because of error: %s", chdr.ChannelId, err)
-				return srv.Send(&ab.BroadcastResponse{Status: ClassifyError(err), Info: err.Error()})
+				return bh.respond(srv, chdr, isConfig, received, &ab.BroadcastResponse{Status: ClassifyError(err), Info: err.Error()})
 			}
 
+			enqueued = config
 			err = processor.Configure(msg, config, configSeq)
 			if err != nil {
 				logger.Warningf("[channel: %s] Rejecting broadcast of config message with SERVICE_UNAVAILABLE: rejected by Configure: %s", chdr.ChannelId, err)
-				return srv.Send(&ab.BroadcastResponse{Status: cb.Status_SERVICE_UNAVAILABLE, Info: err.Error()})
+				return bh.respond(srv, chdr, isConfig, received, &ab.BroadcastResponse{Status: cb.Status_SERVICE_UNAVAILABLE, Info: err.Error()})
 			}
 		}
 
@@ -115,7 +127,19 @@ func (bh *handlerImpl) Handle(srv ab.AtomicBroadcast_BroadcastServer) error {
 			logger.Debugf("[channel: %s] Broadcast has successfully enqueued message of type %s", chdr.ChannelId, cb.HeaderType_name[chdr.Type])
 		}
 
-		err = srv.Send(&ab.BroadcastResponse{Status: cb.Status_SUCCESS})
+		// The bytes enqueued are accounted to the channel whether its bandwidth is limited or not
+		bh.scope.Tagged(map[string]string{"channel": chdr.ChannelId}).Counter("bytes").Inc(int64(len(msg.GetPayload()) + len(msg.GetSignature())))
+
+		resp := &ab.BroadcastResponse{Status: cb.Status_SUCCESS}
+		if bh.receipts != nil {
+			// The message is already enqueued, so a receipt which cannot be issued does not fail the broadcast
+			if resp.Receipt, err = bh.receipt(chdr, enqueued, configSeq); err != nil {
+				logger.Warningf("[channel: %s] Could not issue ordering receipt: %s", chdr.ChannelId, err)
+				resp.Info = fmt.Sprintf("could not issue ordering receipt: %s", err)
+			}
+		}
+
+		err = bh.respond(srv, chdr, isConfig, received, resp)
 		if err != nil {
 			logger.Warningf("[channel: %s] Error sending to stream: %s", chdr.ChannelId, err)
 			return err
@@ -123,6 +147,21 @@ func (bh *handlerImpl) Handle(srv ab.AtomicBroadcast_BroadcastServer) error {
 	}
 }
 
+// respond sends the response to a broadcast message, counting the messages broadcast to each
+// channel by status and timing how long they took to be enqueued for ordering
+func (bh *handlerImpl) respond(srv ab.AtomicBroadcast_BroadcastServer, chdr *cb.ChannelHeader, isConfig bool, received time.Time, resp *ab.BroadcastResponse) error {
+	msgType := "normal"
+	if isConfig {
+		msgType = "config"
+	}
+	scope := bh.scope.Tagged(map[string]string{"channel": chdr.GetChannelId(), "type": msgType})
+	scope.Tagged(map[string]string{"status": resp.Status.String()}).Counter("messages").Inc(1)
+	if resp.Status == cb.Status_SUCCESS {
+		scope.Timer("enqueue_latency").Record(time.Since(received))
+	}
+	return srv.Send(resp)
+}
+
 // ClassifyError converts an error type into a status code.
 func ClassifyError(err error) cb.Status {
 	switch errors.Cause(err) {
@@ -130,6 +169,10 @@ func ClassifyError(err error) cb.Status {
 		return cb.Status_NOT_FOUND
 	case msgprocessor.ErrPermissionDenied:
 		return cb.Status_FORBIDDEN
+	case msp.ErrIdentityExpired:
+		return cb.Status_UNAUTHORIZED
+	case msgprocessor.ErrRateLimited, msgprocessor.ErrMaintenanceMode, ErrQueueFull:
+		return cb.Status_SERVICE_UNAVAILABLE
 	default:
 		return cb.Status_BAD_REQUEST
 	}
@@ -12,6 +12,8 @@ import (
 	"testing"
 	"time"
 
+	mockmetrics "github.com/hyperledger/fabric/common/mocks/metrics"
+	"github.com/hyperledger/fabric/msp"
 	"github.com/hyperledger/fabric/orderer/common/msgprocessor"
 	cb "github.com/hyperledger/fabric/protos/common"
 	ab "github.com/hyperledger/fabric/protos/orderer"
@@ -123,6 +125,10 @@ func (ms *mockSupport) ProcessConfigUpdateMsg(msg *cb.Envelope) (*cb.Envelope, u
 	return ms.ProcessConfigEnv, ms.ProcessConfigSeq, ms.ProcessErr
 }
 
+func (ms *mockSupport) SimulateConfigUpdateMsg(msg *cb.Envelope) (*cb.ConfigEnvelope, error) {
+	panic("UNIMPLMENTED")
+}
+
 func getMockSupportManager() *mockSupportManager {
 	return &mockSupportManager{
 		MsgProcessorVal: &mockSupport{},
@@ -162,6 +168,29 @@ func TestEnqueueFailure(t *testing.T) {
 	}
 }
 
+func TestBroadcastMetrics(t *testing.T) {
+	mm := getMockSupportManager()
+	scope := mockmetrics.NewScope()
+	bh := &handlerImpl{sm: mm, scope: scope.SubScope("broadcast")}
+	m := newMockB()
+	defer close(m.recvChan)
+	go bh.Handle(m)
+
+	for i := 0; i < 2; i++ {
+		m.recvChan <- nil
+		assert.Equal(t, cb.Status_SUCCESS, (<-m.sendChan).Status)
+	}
+	mm.MsgProcessorVal.ProcessErr = msgprocessor.ErrPermissionDenied
+	m.recvChan <- nil
+	assert.Equal(t, cb.Status_FORBIDDEN, (<-m.sendChan).Status)
+
+	tags := map[string]string{"channel": "", "type": "normal", "status": "SUCCESS"}
+	assert.Equal(t, int64(2), scope.CounterOf("broadcast.messages", tags).Value())
+	tags["status"] = "FORBIDDEN"
+	assert.Equal(t, int64(1), scope.CounterOf("broadcast.messages", tags).Value())
+	assert.Len(t, scope.TimerOf("broadcast.enqueue_latency", map[string]string{"channel": "", "type": "normal"}).Values(), 2)
+}
+
 func TestClassifyError(t *testing.T) {
 	t.Run("NotFound", func(t *testing.T) {
 		assert.Equal(t, cb.Status_NOT_FOUND, ClassifyError(msgprocessor.ErrChannelDoesNotExist))
@@ -169,6 +198,13 @@ func TestClassifyError(t *testing.T) {
 	t.Run("Forbidden", func(t *testing.T) {
 		assert.Equal(t, cb.Status_FORBIDDEN, ClassifyError(msgprocessor.ErrPermissionDenied))
 	})
+	t.Run("Unauthorized", func(t *testing.T) {
+		assert.Equal(t, cb.Status_UNAUTHORIZED, ClassifyError(errors.Wrap(msp.ErrIdentityExpired, "certificate expired")))
+	})
+	t.Run("ServiceUnavailable", func(t *testing.T) {
+		assert.Equal(t, cb.Status_SERVICE_UNAVAILABLE, ClassifyError(msgprocessor.ErrRateLimited))
+		assert.Equal(t, cb.Status_SERVICE_UNAVAILABLE, ClassifyError(msgprocessor.ErrMaintenanceMode))
+	})
 	t.Run("WrappedErr", func(t *testing.T) {
 		assert.Equal(t, cb.Status_NOT_FOUND, ClassifyError(errors.Wrap(msgprocessor.ErrChannelDoesNotExist, "A wrapped error")))
 	})
@@ -0,0 +1,210 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package broadcast
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	cb "github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/utils"
+)
+
+// NewFairOrderingRegistrar returns a ChannelSupportRegistrar whose channels hold the normal messages
+// they receive within a reordering window, and then pass them on to consensus ordered by arrival with
+// per-client fairness: each client, identified by the creator of the message, gets one message
+// through per round, so a fast client cannot monopolize the blocks. A window is cut after the given
+// duration from its first message, or as soon as it holds maxMessages messages. Order returns once the
+// message has been passed on to consensus, which delays each broadcast by up to the window duration.
+// Config update messages are not held, as they are few and are revalidated by consensus anyway
+func NewFairOrderingRegistrar(sm ChannelSupportRegistrar, window time.Duration, maxMessages int) ChannelSupportRegistrar {
+	return &fairOrderingRegistrar{
+		ChannelSupportRegistrar: sm,
+		window:                  window,
+		maxMessages:             maxMessages,
+		windows:                 make(map[string]*reorderingWindow),
+	}
+}
+
+type fairOrderingRegistrar struct {
+	ChannelSupportRegistrar
+	window      time.Duration
+	maxMessages int
+
+	mutex   sync.Mutex
+	windows map[string]*reorderingWindow
+}
+
+func (r *fairOrderingRegistrar) BroadcastChannelSupport(msg *cb.Envelope) (*cb.ChannelHeader, bool, ChannelSupport, error) {
+	chdr, isConfig, cs, err := r.ChannelSupportRegistrar.BroadcastChannelSupport(msg)
+	if err != nil || isConfig {
+		return chdr, isConfig, cs, err
+	}
+	return chdr, isConfig, &fairChannelSupport{ChannelSupport: cs, window: r.reorderingWindow(chdr.ChannelId)}, nil
+}
+
+func (r *fairOrderingRegistrar) reorderingWindow(channelID string) *reorderingWindow {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	w, ok := r.windows[channelID]
+	if !ok {
+		w = newReorderingWindow(r.window, r.maxMessages)
+		r.windows[channelID] = w
+	}
+	return w
+}
+
+// fairChannelSupport passes the normal messages through the reordering window of the channel
+type fairChannelSupport struct {
+	ChannelSupport
+	window *reorderingWindow
+}
+
+func (cs *fairChannelSupport) Order(env *cb.Envelope, configSeq uint64) error {
+	return cs.window.order(cs.ChannelSupport, env, configSeq)
+}
+
+type pendingMessage struct {
+	consenter Consenter
+	env       *cb.Envelope
+	configSeq uint64
+	client    string
+	result    chan error
+}
+
+// reorderingWindow collects the messages of a channel and hands them to consensus window by window.
+// Each cut window gets a sequence number, and the windows are handed to consensus in that sequence
+type reorderingWindow struct {
+	window      time.Duration
+	maxMessages int
+
+	mutex   sync.Mutex
+	pending []*pendingMessage
+	// seq is the sequence number of the window being collected
+	seq uint64
+
+	flushLock sync.Mutex
+	flushCond *sync.Cond
+	// flushed is the number of windows handed to consensus
+	flushed uint64
+}
+
+func newReorderingWindow(window time.Duration, maxMessages int) *reorderingWindow {
+	w := &reorderingWindow{window: window, maxMessages: maxMessages}
+	w.flushCond = sync.NewCond(&w.flushLock)
+	return w
+}
+
+// order adds the message to the window and waits until it has been passed on to consensus
+func (w *reorderingWindow) order(consenter Consenter, env *cb.Envelope, configSeq uint64) error {
+	msg := &pendingMessage{
+		consenter: consenter,
+		env:       env,
+		configSeq: configSeq,
+		client:    messageClient(env),
+		result:    make(chan error, 1),
+	}
+
+	w.mutex.Lock()
+	w.pending = append(w.pending, msg)
+	if len(w.pending) == 1 {
+		seq := w.seq
+		time.AfterFunc(w.window, func() { w.cut(seq) })
+	}
+	var batch []*pendingMessage
+	var seq uint64
+	full := w.maxMessages > 0 && len(w.pending) >= w.maxMessages
+	if full {
+		batch, seq = w.takePending()
+	}
+	w.mutex.Unlock()
+
+	if full {
+		logger.Debugf("Reordering window is full with %d messages, cutting it", len(batch))
+		w.flush(batch, seq)
+	}
+	return <-msg.result
+}
+
+// cut hands the window with the given sequence number to consensus, unless it was already cut because it was full
+func (w *reorderingWindow) cut(seq uint64) {
+	w.mutex.Lock()
+	if seq != w.seq {
+		w.mutex.Unlock()
+		return
+	}
+	batch, _ := w.takePending()
+	w.mutex.Unlock()
+	w.flush(batch, seq)
+}
+
+// takePending must be called with the mutex held
+func (w *reorderingWindow) takePending() ([]*pendingMessage, uint64) {
+	batch, seq := w.pending, w.seq
+	w.pending = nil
+	w.seq++
+	return batch, seq
+}
+
+// flush waits for the preceding windows to be handed to consensus, and then passes
+// on the messages of the given window in fair order
+func (w *reorderingWindow) flush(batch []*pendingMessage, seq uint64) {
+	w.flushLock.Lock()
+	for w.flushed != seq {
+		w.flushCond.Wait()
+	}
+	w.flushLock.Unlock()
+
+	for _, msg := range fairOrder(batch) {
+		msg.result <- msg.consenter.Order(msg.env, msg.configSeq)
+	}
+
+	w.flushLock.Lock()
+	w.flushed++
+	w.flushCond.Broadcast()
+	w.flushLock.Unlock()
+}
+
+// fairOrder orders the messages in rounds. Each round holds the next message of every client
+// that has messages left, and the messages within a round are ordered by arrival
+func fairOrder(batch []*pendingMessage) []*pendingMessage {
+	// Arrival indexes of the messages of each client, in arrival order
+	clientMsgs := make(map[string][]int)
+	for i, msg := range batch {
+		clientMsgs[msg.client] = append(clientMsgs[msg.client], i)
+	}
+
+	ordered := make([]*pendingMessage, 0, len(batch))
+	for round := 0; len(ordered) < len(batch); round++ {
+		var roundMsgs []int
+		for _, msgs := range clientMsgs {
+			if round < len(msgs) {
+				roundMsgs = append(roundMsgs, msgs[round])
+			}
+		}
+		sort.Ints(roundMsgs)
+		for _, i := range roundMsgs {
+			ordered = append(ordered, batch[i])
+		}
+	}
+	return ordered
+}
+
+// messageClient returns the creator of the message, or an empty string if it cannot be extracted,
+// in which case all such messages are treated as coming from the same client
+func messageClient(env *cb.Envelope) string {
+	payload, err := utils.UnmarshalPayload(env.GetPayload())
+	if err != nil || payload.Header == nil {
+		return ""
+	}
+	shdr, err := utils.GetSignatureHeader(payload.Header.SignatureHeader)
+	if err != nil {
+		return ""
+	}
+	return string(shdr.Creator)
+}
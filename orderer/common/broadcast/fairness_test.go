@@ -0,0 +1,209 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package broadcast
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	cb "github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/utils"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingConsenter struct {
+	mutex   sync.Mutex
+	ordered []string
+	err     error
+}
+
+func (rc *recordingConsenter) Order(env *cb.Envelope, configSeq uint64) error {
+	rc.mutex.Lock()
+	defer rc.mutex.Unlock()
+	rc.ordered = append(rc.ordered, string(utils.UnmarshalPayloadOrPanic(env.Payload).Data))
+	return rc.err
+}
+
+func (rc *recordingConsenter) Configure(configUpdate *cb.Envelope, config *cb.Envelope, configSeq uint64) error {
+	panic("UNIMPLMENTED")
+}
+
+func (rc *recordingConsenter) orderedMsgs() []string {
+	rc.mutex.Lock()
+	defer rc.mutex.Unlock()
+	return rc.ordered
+}
+
+func clientEnvelope(client string, data string) *cb.Envelope {
+	return &cb.Envelope{Payload: utils.MarshalOrPanic(&cb.Payload{
+		Header: &cb.Header{SignatureHeader: utils.MarshalOrPanic(&cb.SignatureHeader{Creator: []byte(client)})},
+		Data:   []byte(data),
+	})}
+}
+
+func pendingMsgs(consenter Consenter, clients ...string) []*pendingMessage {
+	var batch []*pendingMessage
+	for i, client := range clients {
+		batch = append(batch, &pendingMessage{
+			consenter: consenter,
+			env:       clientEnvelope(client, fmt.Sprintf("%s%d", client, i)),
+			client:    client,
+			result:    make(chan error, 1),
+		})
+	}
+	return batch
+}
+
+func TestFairOrder(t *testing.T) {
+	batch := pendingMsgs(nil, "A", "A", "A", "B", "A", "C", "B")
+	var ordered []string
+	for _, msg := range fairOrder(batch) {
+		ordered = append(ordered, string(utils.UnmarshalPayloadOrPanic(msg.env.Payload).Data))
+	}
+	assert.Equal(t, []string{"A0", "B3", "C5", "A1", "B6", "A2", "A4"}, ordered)
+	assert.Empty(t, fairOrder(nil))
+}
+
+func TestMessageClient(t *testing.T) {
+	assert.Equal(t, "A", messageClient(clientEnvelope("A", "foo")))
+	assert.Equal(t, "", messageClient(&cb.Envelope{Payload: []byte("garbage")}))
+	assert.Equal(t, "", messageClient(nil))
+}
+
+// orderInWindow submits the messages of the given clients one at a time,
+// waiting for each to be held in the window before submitting the next
+func orderInWindow(t *testing.T, w *reorderingWindow, rc *recordingConsenter, clients ...string) chan error {
+	results := make(chan error, len(clients))
+	for i, client := range clients {
+		env := clientEnvelope(client, fmt.Sprintf("%s%d", client, i))
+		go func() {
+			results <- w.order(rc, env, 0)
+		}()
+		if i == len(clients)-1 {
+			break
+		}
+		waitForPending(t, w, i+1)
+	}
+	return results
+}
+
+func waitForPending(t *testing.T, w *reorderingWindow, n int) {
+	for start := time.Now(); time.Since(start) < time.Second; time.Sleep(time.Millisecond) {
+		w.mutex.Lock()
+		pending := len(w.pending)
+		w.mutex.Unlock()
+		if pending == n {
+			return
+		}
+	}
+	t.Fatalf("Window should hold %d messages", n)
+}
+
+func TestReorderingWindowFull(t *testing.T) {
+	rc := &recordingConsenter{}
+	w := newReorderingWindow(time.Hour, 5)
+	results := orderInWindow(t, w, rc, "A", "A", "A", "B", "C")
+	for i := 0; i < 5; i++ {
+		select {
+		case err := <-results:
+			assert.NoError(t, err)
+		case <-time.After(time.Second):
+			t.Fatalf("Full window should have been cut")
+		}
+	}
+	assert.Equal(t, []string{"A0", "B3", "C4", "A1", "A2"}, rc.orderedMsgs())
+
+	// The timer of the window cut because it was full does not cut the next window
+	w.cut(0)
+	assert.Equal(t, uint64(1), w.seq)
+}
+
+func TestReorderingWindowTimeout(t *testing.T) {
+	rc := &recordingConsenter{err: fmt.Errorf("Reject")}
+	w := newReorderingWindow(10*time.Millisecond, 0)
+	results := orderInWindow(t, w, rc, "A", "B", "A")
+	for i := 0; i < 3; i++ {
+		select {
+		case err := <-results:
+			assert.EqualError(t, err, "Reject")
+		case <-time.After(time.Second):
+			t.Fatalf("Window should have been cut after its duration")
+		}
+	}
+	assert.Equal(t, []string{"A0", "B1", "A2"}, rc.orderedMsgs())
+
+	// A new window is opened for the next messages
+	rc.mutex.Lock()
+	rc.err = nil
+	rc.mutex.Unlock()
+	results = orderInWindow(t, w, rc, "C")
+	assert.NoError(t, <-results)
+	assert.Equal(t, []string{"A0", "B1", "A2", "C0"}, rc.orderedMsgs())
+}
+
+func TestReorderingWindowsFlushInSequence(t *testing.T) {
+	rc := &recordingConsenter{}
+	w := newReorderingWindow(time.Hour, 0)
+	w.seq, w.flushed = 2, 1
+
+	done := make(chan struct{})
+	go func() {
+		w.flush(pendingMsgs(rc, "A"), 2)
+		close(done)
+	}()
+	select {
+	case <-done:
+		t.Fatalf("Window should wait for the preceding window to be flushed")
+	case <-time.After(100 * time.Millisecond):
+	}
+	w.flush(pendingMsgs(rc, "B"), 1)
+	<-done
+	assert.Equal(t, []string{"B0", "A0"}, rc.orderedMsgs())
+	assert.Equal(t, uint64(3), w.flushed)
+}
+
+func TestFairOrderingRegistrar(t *testing.T) {
+	mm := getMockSupportManager()
+	r := NewFairOrderingRegistrar(mm, time.Millisecond, 10)
+
+	_, _, cs, err := r.BroadcastChannelSupport(nil)
+	assert.NoError(t, err)
+	fcs, ok := cs.(*fairChannelSupport)
+	assert.True(t, ok, "Normal messages should go through the reordering window")
+	assert.NoError(t, fcs.Order(clientEnvelope("A", "foo"), 0))
+	_, _, cs, _ = r.BroadcastChannelSupport(nil)
+	assert.Equal(t, fcs.window, cs.(*fairChannelSupport).window, "Messages of a channel should share a reordering window")
+
+	mm.MsgProcessorIsConfig = true
+	_, _, cs, _ = r.BroadcastChannelSupport(nil)
+	assert.Equal(t, mm.MsgProcessorVal, cs, "Config update messages should not be held")
+
+	mm.MsgProcessorIsConfig = false
+	mm.MsgProcessorErr = fmt.Errorf("Error")
+	_, _, _, err = r.BroadcastChannelSupport(nil)
+	assert.Error(t, err)
+}
+
+func TestFairOrderingHandler(t *testing.T) {
+	mm := getMockSupportManager()
+	bh := NewHandlerImpl(NewFairOrderingRegistrar(mm, time.Millisecond, 10))
+	m := newMockB()
+	defer close(m.recvChan)
+	go bh.Handle(m)
+
+	m.recvChan <- clientEnvelope("A", "foo")
+	reply := <-m.sendChan
+	assert.Equal(t, cb.Status_SUCCESS, reply.Status)
+
+	mm.MsgProcessorVal.rejectEnqueue = true
+	m.recvChan <- clientEnvelope("A", "bar")
+	reply = <-m.sendChan
+	assert.Equal(t, cb.Status_SERVICE_UNAVAILABLE, reply.Status)
+}
@@ -0,0 +1,68 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package broadcast
+
+import (
+	"github.com/hyperledger/fabric/orderer/common/msgprocessor"
+	cb "github.com/hyperledger/fabric/protos/common"
+)
+
+// NewIngressFilterRegistrar returns a ChannelSupportRegistrar which applies the given rules to the
+// messages broadcast to the orderer, on top of the filters of their channel. Unlike the filters of
+// the channels, which the consenters apply again when they order a message or when the config of
+// the channel changes, the ingress rules are applied once, when the message is received. They may
+// therefore depend on the local config or the state of this orderer, or on the current time,
+// without different orderers reaching different decisions on the same ordered message.
+// The rules are applied once the processor of the channel accepted the message, so that they only
+// see messages whose signature has been checked
+func NewIngressFilterRegistrar(sm ChannelSupportRegistrar, rules ...msgprocessor.Rule) ChannelSupportRegistrar {
+	return &ingressFilterRegistrar{
+		ChannelSupportRegistrar: sm,
+		rules:                   msgprocessor.NewRuleSet(rules),
+	}
+}
+
+type ingressFilterRegistrar struct {
+	ChannelSupportRegistrar
+	rules *msgprocessor.RuleSet
+}
+
+func (r *ingressFilterRegistrar) BroadcastChannelSupport(msg *cb.Envelope) (*cb.ChannelHeader, bool, ChannelSupport, error) {
+	chdr, isConfig, cs, err := r.ChannelSupportRegistrar.BroadcastChannelSupport(msg)
+	if err != nil {
+		return chdr, isConfig, cs, err
+	}
+	return chdr, isConfig, &ingressFilterChannelSupport{ChannelSupport: cs, rules: r.rules}, nil
+}
+
+// ingressFilterChannelSupport applies the ingress rules to the messages the channel accepted
+type ingressFilterChannelSupport struct {
+	ChannelSupport
+	rules *msgprocessor.RuleSet
+}
+
+func (cs *ingressFilterChannelSupport) ProcessNormalMsg(env *cb.Envelope) (uint64, error) {
+	configSeq, err := cs.ChannelSupport.ProcessNormalMsg(env)
+	if err != nil {
+		return 0, err
+	}
+	if err := cs.rules.Apply(env); err != nil {
+		return 0, err
+	}
+	return configSeq, nil
+}
+
+func (cs *ingressFilterChannelSupport) ProcessConfigUpdateMsg(env *cb.Envelope) (*cb.Envelope, uint64, error) {
+	config, configSeq, err := cs.ChannelSupport.ProcessConfigUpdateMsg(env)
+	if err != nil {
+		return nil, 0, err
+	}
+	if err := cs.rules.Apply(env); err != nil {
+		return nil, 0, err
+	}
+	return config, configSeq, nil
+}
@@ -0,0 +1,86 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package broadcast
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/fabric/orderer/common/msgprocessor"
+	cb "github.com/hyperledger/fabric/protos/common"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+type countingRule struct {
+	applied int
+	err     error
+}
+
+func (cr *countingRule) Apply(message *cb.Envelope) error {
+	cr.applied++
+	return cr.err
+}
+
+func TestIngressFilterRegistrar(t *testing.T) {
+	mm := getMockSupportManager()
+	mm.MsgProcessorVal.ProcessConfigSeq = 3
+	rule := &countingRule{}
+	r := NewIngressFilterRegistrar(mm, rule)
+
+	_, _, cs, err := r.BroadcastChannelSupport(nil)
+	assert.NoError(t, err)
+
+	configSeq, err := cs.ProcessNormalMsg(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(3), configSeq)
+	_, configSeq, err = cs.ProcessConfigUpdateMsg(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(3), configSeq)
+	assert.Equal(t, 2, rule.applied)
+
+	t.Run("RejectedByRule", func(t *testing.T) {
+		rule.err = errors.Wrap(errors.WithStack(msgprocessor.ErrRateLimited), "client exceeded 1 messages per second")
+		defer func() { rule.err = nil }()
+		_, err := cs.ProcessNormalMsg(nil)
+		assert.Equal(t, msgprocessor.ErrRateLimited, errors.Cause(err))
+		assert.Equal(t, cb.Status_SERVICE_UNAVAILABLE, ClassifyError(err))
+		_, _, err = cs.ProcessConfigUpdateMsg(nil)
+		assert.Equal(t, msgprocessor.ErrRateLimited, errors.Cause(err))
+	})
+
+	t.Run("RejectedByChannel", func(t *testing.T) {
+		applied := rule.applied
+		mm.MsgProcessorVal.ProcessErr = fmt.Errorf("Rejected")
+		defer func() { mm.MsgProcessorVal.ProcessErr = nil }()
+		_, err := cs.ProcessNormalMsg(nil)
+		assert.EqualError(t, err, "Rejected")
+		assert.Equal(t, applied, rule.applied, "The rules should not see the messages the channel rejected")
+	})
+
+	t.Run("NoChannel", func(t *testing.T) {
+		mm.MsgProcessorErr = fmt.Errorf("Error")
+		defer func() { mm.MsgProcessorErr = nil }()
+		_, _, _, err := r.BroadcastChannelSupport(nil)
+		assert.Error(t, err)
+	})
+}
+
+func TestIngressFilterHandler(t *testing.T) {
+	mm := getMockSupportManager()
+	rule := &countingRule{err: errors.Wrap(errors.WithStack(msgprocessor.ErrRateLimited), "client exceeded 1 messages per second")}
+	bh := NewHandlerImpl(NewIngressFilterRegistrar(mm, rule))
+
+	m := newMockB()
+	defer close(m.recvChan)
+	go bh.Handle(m)
+	m.recvChan <- nil
+	reply := <-m.sendChan
+	assert.Equal(t, cb.Status_SERVICE_UNAVAILABLE, reply.Status)
+	assert.Regexp(t, "client exceeded 1 messages per second", reply.Info)
+}
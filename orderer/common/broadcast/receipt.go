@@ -0,0 +1,94 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package broadcast
+
+import (
+	"github.com/hyperledger/fabric/common/crypto"
+	"github.com/hyperledger/fabric/common/metrics"
+	"github.com/hyperledger/fabric/common/util"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/utils"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+)
+
+// ReceiptSupport provides the resources of a channel needed to issue ordering receipts
+type ReceiptSupport interface {
+	// Signer returns the signer of the channel
+	Signer() crypto.LocalSigner
+
+	// Height returns the number of blocks in the ledger of the channel
+	Height() uint64
+}
+
+// ReceiptSupportRegistrar provides a way for the Handler to look up the ReceiptSupport for a channel
+type ReceiptSupportRegistrar interface {
+	// ReceiptSupport returns the ReceiptSupport of the channel, or false if the channel does not exist
+	ReceiptSupport(channelID string) (ReceiptSupport, bool)
+}
+
+// NewHandlerImplWithReceipts constructs a new implementation of the Handler interface which
+// answers each message it successfully enqueues for ordering with an ordering receipt, signed
+// by the orderer, so that clients can prove the submission before the message is cut into a block
+func NewHandlerImplWithReceipts(sm ChannelSupportRegistrar, rs ReceiptSupportRegistrar) Handler {
+	return &handlerImpl{
+		sm:       sm,
+		receipts: rs,
+		scope:    metrics.NewRootScope().SubScope("broadcast"),
+	}
+}
+
+// receipt returns the ordering receipt of a message enqueued for ordering against the config sequence.
+// The enqueued envelope is the message itself, or the config envelope a config update produced
+func (bh *handlerImpl) receipt(chdr *cb.ChannelHeader, enqueued *cb.Envelope, configSeq uint64) (*ab.OrderingReceipt, error) {
+	support, ok := bh.receipts.ReceiptSupport(chdr.ChannelId)
+	if !ok {
+		return nil, errors.Errorf("channel %s does not exist", chdr.ChannelId)
+	}
+
+	envBytes, err := utils.Marshal(enqueued)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not marshal envelope")
+	}
+	receipt := &ab.OrderingReceipt{
+		ChannelId:      chdr.ChannelId,
+		TxId:           chdr.TxId,
+		EnvelopeHash:   util.ComputeSHA256(envBytes),
+		ConfigSequence: configSeq,
+		Height:         support.Height(),
+	}
+
+	sh, err := support.Signer().NewSignatureHeader()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create signature header")
+	}
+	if receipt.SignatureHeader, err = utils.Marshal(sh); err != nil {
+		return nil, errors.Wrap(err, "could not marshal signature header")
+	}
+	signedBytes, err := ReceiptSignedBytes(receipt)
+	if err != nil {
+		return nil, err
+	}
+	if receipt.Signature, err = support.Signer().Sign(signedBytes); err != nil {
+		return nil, errors.Wrap(err, "could not sign receipt")
+	}
+	return receipt, nil
+}
+
+// ReceiptSignedBytes returns the bytes of an ordering receipt the orderer signs, the receipt
+// marshaled with an empty signature, so that the signature covers every other field
+func ReceiptSignedBytes(receipt *ab.OrderingReceipt) ([]byte, error) {
+	unsigned := proto.Clone(receipt).(*ab.OrderingReceipt)
+	unsigned.Signature = nil
+	signedBytes, err := utils.Marshal(unsigned)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not marshal receipt")
+	}
+	return signedBytes, nil
+}
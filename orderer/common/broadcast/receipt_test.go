@@ -0,0 +1,145 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package broadcast
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric/common/crypto"
+	mockcrypto "github.com/hyperledger/fabric/common/mocks/crypto"
+	"github.com/hyperledger/fabric/common/util"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/utils"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockReceiptSupport struct {
+	signer crypto.LocalSigner
+	height uint64
+}
+
+func (mrs *mockReceiptSupport) Signer() crypto.LocalSigner {
+	return mrs.signer
+}
+
+func (mrs *mockReceiptSupport) Height() uint64 {
+	return mrs.height
+}
+
+type mockReceiptSupportRegistrar map[string]*mockReceiptSupport
+
+func (mrr mockReceiptSupportRegistrar) ReceiptSupport(channelID string) (ReceiptSupport, bool) {
+	rs, ok := mrr[channelID]
+	return rs, ok
+}
+
+type erroneousSigner struct {
+	crypto.LocalSigner
+}
+
+func (es *erroneousSigner) Sign(msg []byte) ([]byte, error) {
+	return nil, errors.New("signing failed")
+}
+
+func TestOrderingReceipts(t *testing.T) {
+	env := &cb.Envelope{Payload: []byte("payload"), Signature: []byte("signature")}
+	support := &mockReceiptSupport{signer: mockcrypto.FakeLocalSigner, height: 5}
+
+	mm := getMockSupportManager()
+	mm.MsgProcessorVal.ProcessConfigSeq = 3
+	bh := NewHandlerImplWithReceipts(mm, mockReceiptSupportRegistrar{"": support})
+	m := newMockB()
+	defer close(m.recvChan)
+	go bh.Handle(m)
+
+	m.recvChan <- env
+	reply := <-m.sendChan
+	assert.Equal(t, cb.Status_SUCCESS, reply.Status)
+	receipt := reply.Receipt
+	if assert.NotNil(t, receipt, "Should have issued a receipt for the enqueued message") {
+		assert.Equal(t, util.ComputeSHA256(utils.MarshalOrPanic(env)), receipt.EnvelopeHash)
+		assert.Equal(t, uint64(3), receipt.ConfigSequence)
+		assert.Equal(t, uint64(5), receipt.Height)
+
+		sh := &cb.SignatureHeader{}
+		assert.NoError(t, proto.Unmarshal(receipt.SignatureHeader, sh))
+		assert.Equal(t, []byte("IdentityBytes"), sh.Creator)
+		// The mock signer signs a message with the message itself
+		unsigned := proto.Clone(receipt).(*ab.OrderingReceipt)
+		unsigned.Signature = nil
+		assert.Equal(t, utils.MarshalOrPanic(unsigned), receipt.Signature, "The signature should cover the whole receipt")
+		signedBytes, err := ReceiptSignedBytes(receipt)
+		assert.NoError(t, err)
+		assert.Equal(t, signedBytes, receipt.Signature)
+	}
+
+	t.Run("ConfigUpdate", func(t *testing.T) {
+		config := &cb.Envelope{Payload: []byte("config"), Signature: []byte("orderer signature")}
+		mm.MsgProcessorIsConfig = true
+		mm.MsgProcessorVal.ProcessConfigEnv = config
+		defer func() {
+			mm.MsgProcessorIsConfig = false
+			mm.MsgProcessorVal.ProcessConfigEnv = nil
+		}()
+		bh := NewHandlerImplWithReceipts(mm, mockReceiptSupportRegistrar{"": support})
+		m := newMockB()
+		defer close(m.recvChan)
+		go bh.Handle(m)
+
+		m.recvChan <- env
+		reply := <-m.sendChan
+		assert.Equal(t, cb.Status_SUCCESS, reply.Status)
+		if assert.NotNil(t, reply.Receipt) {
+			assert.Equal(t, util.ComputeSHA256(utils.MarshalOrPanic(config)), reply.Receipt.EnvelopeHash,
+				"The receipt should be issued for the config envelope enqueued for ordering")
+		}
+	})
+
+	t.Run("Rejected", func(t *testing.T) {
+		mm.MsgProcessorVal.rejectEnqueue = true
+		defer func() { mm.MsgProcessorVal.rejectEnqueue = false }()
+		bh := NewHandlerImplWithReceipts(mm, mockReceiptSupportRegistrar{"": support})
+		m := newMockB()
+		defer close(m.recvChan)
+		go bh.Handle(m)
+
+		m.recvChan <- env
+		reply := <-m.sendChan
+		assert.Equal(t, cb.Status_SERVICE_UNAVAILABLE, reply.Status)
+		assert.Nil(t, reply.Receipt, "Should not have issued a receipt for a rejected message")
+	})
+
+	t.Run("SigningFailure", func(t *testing.T) {
+		support := &mockReceiptSupport{signer: &erroneousSigner{LocalSigner: mockcrypto.FakeLocalSigner}}
+		bh := NewHandlerImplWithReceipts(mm, mockReceiptSupportRegistrar{"": support})
+		m := newMockB()
+		defer close(m.recvChan)
+		go bh.Handle(m)
+
+		m.recvChan <- env
+		reply := <-m.sendChan
+		assert.Equal(t, cb.Status_SUCCESS, reply.Status, "The message is enqueued even if no receipt is issued")
+		assert.Nil(t, reply.Receipt)
+		assert.Equal(t, "could not issue ordering receipt: could not sign receipt: signing failed", reply.Info)
+	})
+
+	t.Run("MissingChannel", func(t *testing.T) {
+		bh := NewHandlerImplWithReceipts(mm, mockReceiptSupportRegistrar{})
+		m := newMockB()
+		defer close(m.recvChan)
+		go bh.Handle(m)
+
+		m.recvChan <- env
+		reply := <-m.sendChan
+		assert.Equal(t, cb.Status_SUCCESS, reply.Status)
+		assert.Nil(t, reply.Receipt)
+	})
+}
@@ -0,0 +1,277 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package channelparticipation serves the channel participation API, through which an
+// administrator lists the channels of the orderer, joins it to a channel with a config
+// block, removes it from a channel, and simulates config updates before submitting them.
+package channelparticipation
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/gorilla/mux"
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/common/tools/protolator"
+	"github.com/hyperledger/fabric/orderer/common/multichannel"
+	cb "github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/utils"
+	logging "github.com/op/go-logging"
+)
+
+const pkgLogID = "orderer/channelparticipation"
+
+var logger *logging.Logger
+
+func init() {
+	logger = flogging.MustGetLogger(pkgLogID)
+}
+
+const (
+	// URLBaseV1 is the base path of version 1 of the API
+	URLBaseV1 = "/participation/v1/"
+	// URLChannels is the path of the channels collection
+	URLChannels = URLBaseV1 + "channels"
+	// FormDataConfigBlockKey is the form field carrying the config block a channel is joined with
+	FormDataConfigBlockKey = "config-block"
+	// URLConfigUpdateSimulation is the path, relative to the URL of a channel, where config updates are simulated
+	URLConfigUpdateSimulation = "/config-update-simulation"
+	// FormDataConfigUpdateKey is the form field carrying the CONFIG_UPDATE envelope to simulate
+	FormDataConfigUpdateKey = "config-update"
+)
+
+// Registrar joins and removes the channels of the orderer
+type Registrar interface {
+	ChannelList() []multichannel.ChannelInfo
+	ChannelInfo(chainID string) (multichannel.ChannelInfo, error)
+	JoinChannel(chainID string, configBlock *cb.Block) (multichannel.ChannelInfo, error)
+	RemoveChannel(chainID string) error
+	SimulateConfigUpdate(configUpdate *cb.Envelope) (*cb.ConfigEnvelope, error)
+}
+
+// ChannelInfoShort names a channel, and the URL describing it
+type ChannelInfoShort struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+// ChannelList lists the channels of the orderer. The system channel, if any, is listed apart
+type ChannelList struct {
+	SystemChannel *ChannelInfoShort  `json:"systemChannel"`
+	Channels      []ChannelInfoShort `json:"channels"`
+}
+
+// ChannelInfo describes a channel of the orderer
+type ChannelInfo struct {
+	Name          string `json:"name"`
+	URL           string `json:"url"`
+	ConsensusType string `json:"consensusType"`
+	Height        uint64 `json:"height"`
+}
+
+// Error is the body of the responses to the requests which failed
+type Error struct {
+	Error string `json:"error"`
+}
+
+// HTTPHandler serves the channel participation API
+type HTTPHandler struct {
+	registrar          Registrar
+	maxRequestBodySize int64
+	router             *mux.Router
+}
+
+// NewHTTPHandler creates a handler serving the channel participation API, which rejects the
+// config blocks larger than the given size
+func NewHTTPHandler(registrar Registrar, maxRequestBodySize uint32) *HTTPHandler {
+	h := &HTTPHandler{
+		registrar:          registrar,
+		maxRequestBodySize: int64(maxRequestBodySize),
+		router:             mux.NewRouter(),
+	}
+	h.router.HandleFunc(URLChannels, h.listChannels).Methods("GET")
+	h.router.HandleFunc(URLChannels, h.joinChannel).Methods("POST")
+	h.router.HandleFunc(URLChannels+"/{channelID}", h.channelInfo).Methods("GET")
+	h.router.HandleFunc(URLChannels+"/{channelID}", h.removeChannel).Methods("DELETE")
+	h.router.HandleFunc(URLChannels+"/{channelID}"+URLConfigUpdateSimulation, h.simulateConfigUpdate).Methods("POST")
+	return h
+}
+
+// ServeHTTP implements the http.Handler interface
+func (h *HTTPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.router.ServeHTTP(w, r)
+}
+
+func (h *HTTPHandler) listChannels(w http.ResponseWriter, r *http.Request) {
+	list := ChannelList{Channels: []ChannelInfoShort{}}
+	for _, info := range h.registrar.ChannelList() {
+		short := ChannelInfoShort{Name: info.Name, URL: channelURL(info.Name)}
+		if info.SystemChannel {
+			list.SystemChannel = &short
+			continue
+		}
+		list.Channels = append(list.Channels, short)
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+func (h *HTTPHandler) channelInfo(w http.ResponseWriter, r *http.Request) {
+	channelID := mux.Vars(r)["channelID"]
+	info, err := h.registrar.ChannelInfo(channelID)
+	if err != nil {
+		writeError(w, statusCode(err), err)
+		return
+	}
+	writeJSON(w, http.StatusOK, toChannelInfo(info))
+}
+
+func (h *HTTPHandler) joinChannel(w http.ResponseWriter, r *http.Request) {
+	blockBytes, err := h.readFormFile(w, r, FormDataConfigBlockKey)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	block := &cb.Block{}
+	if err := proto.Unmarshal(blockBytes, block); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("could not unmarshal config block: %s", err))
+		return
+	}
+	channelID, err := utils.GetChainIDFromBlock(block)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("could not read channel of config block: %s", err))
+		return
+	}
+
+	info, err := h.registrar.JoinChannel(channelID, block)
+	if err != nil {
+		logger.Warningf("Failed to join channel %s: %s", channelID, err)
+		writeError(w, statusCode(err), err)
+		return
+	}
+	logger.Infof("Joined channel %s", channelID)
+	w.Header().Set("Location", channelURL(channelID))
+	writeJSON(w, http.StatusCreated, toChannelInfo(info))
+}
+
+func (h *HTTPHandler) removeChannel(w http.ResponseWriter, r *http.Request) {
+	channelID := mux.Vars(r)["channelID"]
+	if err := h.registrar.RemoveChannel(channelID); err != nil {
+		logger.Warningf("Failed to remove channel %s: %s", channelID, err)
+		code := statusCode(err)
+		if code == http.StatusBadRequest {
+			code = http.StatusInternalServerError
+		}
+		writeError(w, code, err)
+		return
+	}
+	logger.Infof("Removed channel %s", channelID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// simulateConfigUpdate validates the CONFIG_UPDATE envelope posted for a channel, exactly as when it
+// is broadcast, and responds with the resulting config envelope, encoded as JSON, without ordering it
+func (h *HTTPHandler) simulateConfigUpdate(w http.ResponseWriter, r *http.Request) {
+	channelID := mux.Vars(r)["channelID"]
+	envBytes, err := h.readFormFile(w, r, FormDataConfigUpdateKey)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	configUpdate, err := utils.UnmarshalEnvelope(envBytes)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("could not unmarshal config update: %s", err))
+		return
+	}
+	chdr, err := utils.ChannelHeader(configUpdate)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("could not read channel header of config update: %s", err))
+		return
+	}
+	if chdr.ChannelId != channelID {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("config update is for channel %s, not %s", chdr.ChannelId, channelID))
+		return
+	}
+
+	config, err := h.registrar.SimulateConfigUpdate(configUpdate)
+	if err != nil {
+		logger.Debugf("Simulated config update for channel %s is invalid: %s", channelID, err)
+		writeError(w, statusCode(err), err)
+		return
+	}
+	var buffer bytes.Buffer
+	if err := protolator.DeepMarshalJSON(&buffer, config); err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("could not encode resulting config: %s", err))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(buffer.Bytes())
+}
+
+// readFormFile reads the file carried by the given form field of a request, no larger than the
+// maximal request body size
+func (h *HTTPHandler) readFormFile(w http.ResponseWriter, r *http.Request, key string) ([]byte, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, h.maxRequestBodySize)
+	file, _, err := r.FormFile(key)
+	if err != nil {
+		return nil, fmt.Errorf("could not read form field %s: %s", key, err)
+	}
+	defer file.Close()
+	content, err := ioutil.ReadAll(file)
+	if err != nil {
+		return nil, fmt.Errorf("could not read %s: %s", key, err)
+	}
+	return content, nil
+}
+
+// statusCode returns the status of a response to a request failing with the given error
+func statusCode(err error) int {
+	switch err {
+	case multichannel.ErrChannelNotExist:
+		return http.StatusNotFound
+	case multichannel.ErrChannelAlreadyExists:
+		return http.StatusConflict
+	case multichannel.ErrSystemChannelExists:
+		return http.StatusMethodNotAllowed
+	case multichannel.ErrTooManyChannels:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+func channelURL(channelID string) string {
+	return URLChannels + "/" + channelID
+}
+
+func toChannelInfo(info multichannel.ChannelInfo) ChannelInfo {
+	return ChannelInfo{
+		Name:          info.Name,
+		URL:           channelURL(info.Name),
+		ConsensusType: info.ConsensusType,
+		Height:        info.Height,
+	}
+}
+
+func writeError(w http.ResponseWriter, code int, err error) {
+	if code == http.StatusMethodNotAllowed {
+		// the channels of an orderer with a system channel are only listed
+		w.Header().Set("Allow", "GET")
+	}
+	writeJSON(w, code, Error{Error: err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, code int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		logger.Errorf("Failed to encode response: %s", err)
+	}
+}
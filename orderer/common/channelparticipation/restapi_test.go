@@ -0,0 +1,263 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelparticipation
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/orderer/common/multichannel"
+	cb "github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockRegistrar struct {
+	channels   []multichannel.ChannelInfo
+	joinErr    error
+	removeErr  error
+	joined     *cb.Block
+	removedIDs []string
+	config     *cb.ConfigEnvelope
+	simErr     error
+	simulated  *cb.Envelope
+}
+
+func (r *mockRegistrar) ChannelList() []multichannel.ChannelInfo {
+	return r.channels
+}
+
+func (r *mockRegistrar) ChannelInfo(chainID string) (multichannel.ChannelInfo, error) {
+	for _, info := range r.channels {
+		if info.Name == chainID {
+			return info, nil
+		}
+	}
+	return multichannel.ChannelInfo{}, multichannel.ErrChannelNotExist
+}
+
+func (r *mockRegistrar) JoinChannel(chainID string, configBlock *cb.Block) (multichannel.ChannelInfo, error) {
+	if r.joinErr != nil {
+		return multichannel.ChannelInfo{}, r.joinErr
+	}
+	r.joined = configBlock
+	return multichannel.ChannelInfo{Name: chainID, Height: 1, ConsensusType: "solo"}, nil
+}
+
+func (r *mockRegistrar) RemoveChannel(chainID string) error {
+	if r.removeErr != nil {
+		return r.removeErr
+	}
+	r.removedIDs = append(r.removedIDs, chainID)
+	return nil
+}
+
+func (r *mockRegistrar) SimulateConfigUpdate(configUpdate *cb.Envelope) (*cb.ConfigEnvelope, error) {
+	r.simulated = configUpdate
+	return r.config, r.simErr
+}
+
+func configBlock(channelID string) *cb.Block {
+	env := &cb.Envelope{Payload: utils.MarshalOrPanic(&cb.Payload{
+		Header: &cb.Header{ChannelHeader: utils.MarshalOrPanic(&cb.ChannelHeader{
+			Type:      int32(cb.HeaderType_CONFIG),
+			ChannelId: channelID,
+		})},
+	})}
+	block := cb.NewBlock(0, nil)
+	block.Data.Data = [][]byte{utils.MarshalOrPanic(env)}
+	block.Header.DataHash = block.Data.Hash()
+	return block
+}
+
+func joinRequest(t *testing.T, blockBytes []byte) *http.Request {
+	return formRequest(t, URLChannels, FormDataConfigBlockKey, blockBytes)
+}
+
+func formRequest(t *testing.T, url, key string, content []byte) *http.Request {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile(key, key+".pb")
+	require.NoError(t, err)
+	_, err = part.Write(content)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	req := httptest.NewRequest("POST", url, body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return req
+}
+
+func serve(h http.Handler, req *http.Request) *httptest.ResponseRecorder {
+	resp := httptest.NewRecorder()
+	h.ServeHTTP(resp, req)
+	return resp
+}
+
+func TestListChannels(t *testing.T) {
+	registrar := &mockRegistrar{channels: []multichannel.ChannelInfo{
+		{Name: "bar", Height: 3, ConsensusType: "etcdraft"},
+		{Name: "foo", Height: 5, ConsensusType: "solo"},
+	}}
+	h := NewHTTPHandler(registrar, 1024*1024)
+
+	resp := serve(h, httptest.NewRequest("GET", URLChannels, nil))
+	require.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "application/json", resp.Header().Get("Content-Type"))
+	list := &ChannelList{}
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), list))
+	assert.Nil(t, list.SystemChannel)
+	assert.Equal(t, []ChannelInfoShort{
+		{Name: "bar", URL: URLChannels + "/bar"},
+		{Name: "foo", URL: URLChannels + "/foo"},
+	}, list.Channels)
+
+	registrar.channels = []multichannel.ChannelInfo{{Name: "system", SystemChannel: true}}
+	resp = serve(h, httptest.NewRequest("GET", URLChannels, nil))
+	require.Equal(t, http.StatusOK, resp.Code)
+	list = &ChannelList{}
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), list))
+	assert.Equal(t, &ChannelInfoShort{Name: "system", URL: URLChannels + "/system"}, list.SystemChannel)
+	assert.Empty(t, list.Channels)
+}
+
+func TestChannelInfo(t *testing.T) {
+	registrar := &mockRegistrar{channels: []multichannel.ChannelInfo{{Name: "foo", Height: 5, ConsensusType: "solo"}}}
+	h := NewHTTPHandler(registrar, 1024*1024)
+
+	resp := serve(h, httptest.NewRequest("GET", URLChannels+"/foo", nil))
+	require.Equal(t, http.StatusOK, resp.Code)
+	info := &ChannelInfo{}
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), info))
+	assert.Equal(t, &ChannelInfo{Name: "foo", URL: URLChannels + "/foo", ConsensusType: "solo", Height: 5}, info)
+
+	resp = serve(h, httptest.NewRequest("GET", URLChannels+"/bar", nil))
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+	respErr := &Error{}
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), respErr))
+	assert.Equal(t, multichannel.ErrChannelNotExist.Error(), respErr.Error)
+}
+
+func TestJoinChannel(t *testing.T) {
+	registrar := &mockRegistrar{}
+	h := NewHTTPHandler(registrar, 1024*1024)
+
+	block := configBlock("foo")
+	resp := serve(h, joinRequest(t, utils.MarshalOrPanic(block)))
+	require.Equal(t, http.StatusCreated, resp.Code, resp.Body.String())
+	assert.Equal(t, URLChannels+"/foo", resp.Header().Get("Location"))
+	info := &ChannelInfo{}
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), info))
+	assert.Equal(t, &ChannelInfo{Name: "foo", URL: URLChannels + "/foo", ConsensusType: "solo", Height: 1}, info)
+	assert.Equal(t, block.Header.Hash(), registrar.joined.Header.Hash())
+
+	for _, test := range []struct {
+		name string
+		err  error
+		code int
+	}{
+		{name: "AlreadyExists", err: multichannel.ErrChannelAlreadyExists, code: http.StatusConflict},
+		{name: "SystemChannelExists", err: multichannel.ErrSystemChannelExists, code: http.StatusMethodNotAllowed},
+		{name: "TooManyChannels", err: multichannel.ErrTooManyChannels, code: http.StatusServiceUnavailable},
+		{name: "InvalidBlock", err: fmt.Errorf("block 0 is not a config block"), code: http.StatusBadRequest},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			registrar.joinErr = test.err
+			resp := serve(h, joinRequest(t, utils.MarshalOrPanic(block)))
+			assert.Equal(t, test.code, resp.Code)
+			respErr := &Error{}
+			require.NoError(t, json.Unmarshal(resp.Body.Bytes(), respErr))
+			assert.Equal(t, test.err.Error(), respErr.Error)
+		})
+	}
+}
+
+func TestJoinChannelBadRequest(t *testing.T) {
+	registrar := &mockRegistrar{}
+	h := NewHTTPHandler(registrar, 1024)
+
+	// no config block
+	req := httptest.NewRequest("POST", URLChannels, bytes.NewReader([]byte("block")))
+	assert.Equal(t, http.StatusBadRequest, serve(h, req).Code)
+	// not a block
+	assert.Equal(t, http.StatusBadRequest, serve(h, joinRequest(t, []byte("block"))).Code)
+	// too large
+	assert.Equal(t, http.StatusBadRequest, serve(h, joinRequest(t, make([]byte, 2048))).Code)
+	assert.Nil(t, registrar.joined)
+}
+
+func TestRemoveChannel(t *testing.T) {
+	registrar := &mockRegistrar{}
+	h := NewHTTPHandler(registrar, 1024*1024)
+
+	resp := serve(h, httptest.NewRequest("DELETE", URLChannels+"/foo", nil))
+	assert.Equal(t, http.StatusNoContent, resp.Code)
+	assert.Equal(t, []string{"foo"}, registrar.removedIDs)
+
+	registrar.removeErr = multichannel.ErrChannelNotExist
+	assert.Equal(t, http.StatusNotFound, serve(h, httptest.NewRequest("DELETE", URLChannels+"/foo", nil)).Code)
+
+	registrar.removeErr = multichannel.ErrSystemChannelExists
+	resp = serve(h, httptest.NewRequest("DELETE", URLChannels+"/foo", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.Code)
+	assert.Equal(t, "GET", resp.Header().Get("Allow"))
+
+	registrar.removeErr = fmt.Errorf("could not remove ledger of channel foo")
+	assert.Equal(t, http.StatusInternalServerError, serve(h, httptest.NewRequest("DELETE", URLChannels+"/foo", nil)).Code)
+}
+
+func TestSimulateConfigUpdate(t *testing.T) {
+	registrar := &mockRegistrar{config: &cb.ConfigEnvelope{Config: &cb.Config{Sequence: 4}}}
+	h := NewHTTPHandler(registrar, 1024*1024)
+	url := URLChannels + "/foo" + URLConfigUpdateSimulation
+
+	configUpdate := &cb.Envelope{Payload: utils.MarshalOrPanic(&cb.Payload{
+		Header: &cb.Header{ChannelHeader: utils.MarshalOrPanic(&cb.ChannelHeader{
+			Type:      int32(cb.HeaderType_CONFIG_UPDATE),
+			ChannelId: "foo",
+		})},
+	})}
+	resp := serve(h, formRequest(t, url, FormDataConfigUpdateKey, utils.MarshalOrPanic(configUpdate)))
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	assert.Equal(t, "application/json", resp.Header().Get("Content-Type"))
+	assert.Contains(t, resp.Body.String(), `"sequence": "4"`)
+	assert.True(t, proto.Equal(configUpdate, registrar.simulated))
+
+	t.Run("Invalid", func(t *testing.T) {
+		registrar.simErr = fmt.Errorf("implicit policy evaluation failed")
+		defer func() { registrar.simErr = nil }()
+		resp := serve(h, formRequest(t, url, FormDataConfigUpdateKey, utils.MarshalOrPanic(configUpdate)))
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+		respErr := &Error{}
+		require.NoError(t, json.Unmarshal(resp.Body.Bytes(), respErr))
+		assert.Equal(t, "implicit policy evaluation failed", respErr.Error)
+	})
+
+	t.Run("OtherChannel", func(t *testing.T) {
+		registrar.simulated = nil
+		resp := serve(h, formRequest(t, URLChannels+"/bar"+URLConfigUpdateSimulation, FormDataConfigUpdateKey, utils.MarshalOrPanic(configUpdate)))
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+		assert.Nil(t, registrar.simulated)
+	})
+
+	t.Run("NotAnEnvelope", func(t *testing.T) {
+		resp := serve(h, formRequest(t, url, FormDataConfigUpdateKey, []byte("garbage")))
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+
+	t.Run("NoConfigUpdate", func(t *testing.T) {
+		resp := serve(h, formRequest(t, url, FormDataConfigBlockKey, utils.MarshalOrPanic(configUpdate)))
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+}
@@ -19,6 +19,7 @@ package deliver
 import (
 	"io"
 
+	"github.com/hyperledger/fabric/common/metrics"
 	"github.com/hyperledger/fabric/common/policies"
 	"github.com/hyperledger/fabric/orderer/common/ledger"
 	"github.com/hyperledger/fabric/orderer/common/msgprocessor"
@@ -58,21 +59,48 @@ type Support interface {
 }
 
 type deliverServer struct {
-	sm SupportManager
+	sm         SupportManager
+	policyName string
+	scope      metrics.Scope
+	sessions   *sessionRegistry
+	streams    *clientStreams
 }
 
 // NewHandlerImpl creates an implementation of the Handler interface
 func NewHandlerImpl(sm SupportManager) Handler {
+	return NewHandlerImplWithPolicy(sm, policies.ChannelReaders)
+}
+
+// NewHandlerImplWithPolicy creates an implementation of the Handler interface which authorizes
+// the deliver requests against the named policy of the channel, rather than the channel Readers
+func NewHandlerImplWithPolicy(sm SupportManager, policyName string) Handler {
+	return newDeliverServer(sm, policyName, 0)
+}
+
+// NewHandlerImplWithStreamLimit creates an implementation of the Handler interface which delivers
+// blocks on at most maxStreamsPerClient streams of every client identity at a time, and rejects the
+// deliver requests beyond with SERVICE_UNAVAILABLE. A maxStreamsPerClient of 0 is unlimited
+func NewHandlerImplWithStreamLimit(sm SupportManager, maxStreamsPerClient int) Handler {
+	return newDeliverServer(sm, policies.ChannelReaders, maxStreamsPerClient)
+}
+
+func newDeliverServer(sm SupportManager, policyName string, maxStreamsPerClient int) *deliverServer {
 	return &deliverServer{
-		sm: sm,
+		sm:         sm,
+		policyName: policyName,
+		scope:      metrics.NewRootScope().SubScope("deliver"),
+		sessions:   newSessionRegistry(),
+		streams:    newClientStreams(maxStreamsPerClient),
 	}
 }
 
 func (ds *deliverServer) Handle(srv ab.AtomicBroadcast_DeliverServer) error {
 	logger.Debugf("Starting new deliver loop")
+	stream := newDeliverStream(srv)
+	defer stream.close()
 	for {
 		logger.Debugf("Attempting to read seek info message")
-		envelope, err := srv.Recv()
+		envelope, err := stream.recv()
 		if err == io.EOF {
 			logger.Debugf("Received EOF, hangup")
 			return nil
@@ -83,7 +111,7 @@ func (ds *deliverServer) Handle(srv ab.AtomicBroadcast_DeliverServer) error {
 			return err
 		}
 
-		if err := ds.deliverBlocks(srv, envelope); err != nil {
+		if err := ds.deliverBlocks(stream, envelope); err != nil {
 			return err
 		}
 
@@ -91,7 +119,7 @@ func (ds *deliverServer) Handle(srv ab.AtomicBroadcast_DeliverServer) error {
 	}
 }
 
-func (ds *deliverServer) deliverBlocks(srv ab.AtomicBroadcast_DeliverServer, envelope *cb.Envelope) error {
+func (ds *deliverServer) deliverBlocks(srv *deliverStream, envelope *cb.Envelope) error {
 
 	payload, err := utils.UnmarshalPayload(envelope.Payload)
 	if err != nil {
@@ -118,6 +146,9 @@ func (ds *deliverServer) deliverBlocks(srv ab.AtomicBroadcast_DeliverServer, env
 		return sendStatusReply(srv, cb.Status_NOT_FOUND)
 	}
 
+	scope := ds.scope.Tagged(map[string]string{"channel": chdr.ChannelId})
+	scope.Counter("requests").Inc(1)
+
 	erroredChan := chain.Errored()
 	select {
 	case <-erroredChan:
@@ -129,7 +160,7 @@ func (ds *deliverServer) deliverBlocks(srv ab.AtomicBroadcast_DeliverServer, env
 
 	lastConfigSequence := chain.Sequence()
 
-	sf := msgprocessor.NewSigFilter(policies.ChannelReaders, chain.PolicyManager())
+	sf := msgprocessor.NewSigFilter(ds.policyName, chain.PolicyManager())
 	if err := sf.Apply(envelope); err != nil {
 		logger.Warningf("[channel: %s] Received unauthorized deliver request: %s", chdr.ChannelId, err)
 		return sendStatusReply(srv, cb.Status_FORBIDDEN)
@@ -141,13 +172,51 @@ func (ds *deliverServer) deliverBlocks(srv ab.AtomicBroadcast_DeliverServer, env
 		return sendStatusReply(srv, cb.Status_BAD_REQUEST)
 	}
 
-	if seekInfo.Start == nil || seekInfo.Stop == nil {
+	resuming := len(seekInfo.Session.GetToken()) != 0
+	if (seekInfo.Start == nil && !resuming) || seekInfo.Stop == nil {
 		logger.Warningf("[channel: %s] Received seekInfo message with missing start or stop %v, %v", chdr.ChannelId, seekInfo.Start, seekInfo.Stop)
 		return sendStatusReply(srv, cb.Status_BAD_REQUEST)
 	}
 
+	if _, ok := ab.SeekInfo_SeekContentType_name[int32(seekInfo.ContentType)]; !ok {
+		logger.Warningf("[channel: %s] Received seekInfo message with unknown content type %d", chdr.ChannelId, seekInfo.ContentType)
+		return sendStatusReply(srv, cb.Status_BAD_REQUEST)
+	}
+
 	logger.Debugf("[channel: %s] Received seekInfo (%p) %v", chdr.ChannelId, seekInfo, seekInfo)
 
+	// The sessions belong to the creator of the request which opened them, and the streams
+	// delivering blocks are limited per creator
+	var creator []byte
+	if seekInfo.Session != nil || ds.streams.max > 0 {
+		shdr, err := utils.GetSignatureHeader(payload.Header.SignatureHeader)
+		if err != nil {
+			logger.Warningf("[channel: %s] Failed to unmarshal signature header: %s", chdr.ChannelId, err)
+			return sendStatusReply(srv, cb.Status_BAD_REQUEST)
+		}
+		creator = shdr.Creator
+	}
+
+	if !ds.streams.acquire(creator) {
+		logger.Warningf("[channel: %s] Rejecting deliver request because the client exceeds %d concurrent deliver streams", chdr.ChannelId, ds.streams.max)
+		scope.Counter("streams_rejected").Inc(1)
+		return sendStatusReply(srv, cb.Status_SERVICE_UNAVAILABLE)
+	}
+	defer ds.streams.release(creator)
+
+	// A resumed session starts at its first block not acknowledged
+	var sess *session
+	if resuming {
+		if sess, err = ds.sessions.resume(seekInfo.Session.Token, chdr.ChannelId, creator); err != nil {
+			logger.Warningf("[channel: %s] Cannot resume deliver session %x: %s", chdr.ChannelId, seekInfo.Session.Token, err)
+			if err == errSessionMismatch {
+				return sendStatusReply(srv, cb.Status_FORBIDDEN)
+			}
+			return sendStatusReply(srv, cb.Status_NOT_FOUND)
+		}
+		seekInfo.Start = &ab.SeekPosition{Type: &ab.SeekPosition_Specified{Specified: &ab.SeekSpecified{Number: sess.nextBlock()}}}
+	}
+
 	cursor, number := chain.Reader().Iterator(seekInfo.Start)
 	defer cursor.Close()
 	var stopNum uint64
@@ -158,18 +227,46 @@ func (ds *deliverServer) deliverBlocks(srv ab.AtomicBroadcast_DeliverServer, env
 		stopNum = chain.Reader().Height() - 1
 	case *ab.SeekPosition_Specified:
 		stopNum = stop.Specified.Number
+		if stopNum < number && resuming {
+			logger.Debugf("[channel: %s] Deliver session %x already acknowledged block %d", chdr.ChannelId, seekInfo.Session.Token, stopNum)
+			return sendStatusReply(srv, cb.Status_SUCCESS)
+		}
 		if stopNum < number {
 			logger.Warningf("[channel: %s] Received invalid seekInfo message: start number %d greater than stop number %d", chdr.ChannelId, number, stopNum)
 			return sendStatusReply(srv, cb.Status_BAD_REQUEST)
 		}
 	}
 
+	// Open a new session at the start position, and deliver the session on the stream
+	var attachment uint64
+	if seekInfo.Session != nil {
+		if sess == nil {
+			if sess, err = ds.sessions.open(chdr.ChannelId, creator, number); err != nil {
+				logger.Warningf("[channel: %s] Cannot open deliver session: %s", chdr.ChannelId, err)
+				return sendStatusReply(srv, cb.Status_SERVICE_UNAVAILABLE)
+			}
+		}
+		attachment = sess.attach(seekInfo.Session.AckWindow)
+		defer ds.sessions.detach(sess, attachment)
+		// the acknowledgments received after the deliver ends still apply to the session
+		srv.setSession(sess, attachment)
+		srv.readInBackground()
+		logger.Debugf("[channel: %s] Delivering session %x from block %d", chdr.ChannelId, sess.token, number)
+		if err := sendSessionReply(srv, sess.token, number); err != nil {
+			logger.Warningf("[channel: %s] Error sending to stream: %s", chdr.ChannelId, err)
+			return err
+		}
+	}
+
 	for {
 		if seekInfo.Behavior == ab.SeekInfo_BLOCK_UNTIL_READY {
 			select {
 			case <-erroredChan:
 				logger.Warningf("[channel: %s] Aborting deliver request because of consenter error", chdr.ChannelId)
 				return sendStatusReply(srv, cb.Status_SERVICE_UNAVAILABLE)
+			case <-srv.closedChan():
+				logger.Debugf("[channel: %s] Aborting deliver request because the stream is closed", chdr.ChannelId)
+				return nil
 			case <-cursor.ReadyChan():
 			}
 		} else {
@@ -195,12 +292,39 @@ func (ds *deliverServer) deliverBlocks(srv ab.AtomicBroadcast_DeliverServer, env
 			return sendStatusReply(srv, status)
 		}
 
+		// Wait for the acknowledgments of the client while the block is beyond the ack window
+		for sess != nil {
+			ok, acked := sess.canSend(attachment, block.Header.Number)
+			if ok {
+				break
+			}
+			if acked == nil {
+				logger.Warningf("[channel: %s] Deliver session %x was resumed on another stream", chdr.ChannelId, sess.token)
+				return sendStatusReply(srv, cb.Status_SERVICE_UNAVAILABLE)
+			}
+			select {
+			case <-erroredChan:
+				logger.Warningf("[channel: %s] Aborting deliver request because of consenter error", chdr.ChannelId)
+				return sendStatusReply(srv, cb.Status_SERVICE_UNAVAILABLE)
+			case <-srv.closedChan():
+				logger.Debugf("[channel: %s] Aborting deliver request because the stream is closed", chdr.ChannelId)
+				return nil
+			case <-acked:
+			}
+		}
+
 		logger.Debugf("[channel: %s] Delivering block for (%p)", chdr.ChannelId, seekInfo)
 
-		if err := sendBlockReply(srv, block); err != nil {
+		content := blockContent(block, seekInfo.ContentType)
+		if err := sendBlockReply(srv, content); err != nil {
 			logger.Warningf("[channel: %s] Error sending to stream: %s", chdr.ChannelId, err)
 			return err
 		}
+		scope.Counter("blocks_sent").Inc(1)
+		scope.Counter("bytes_sent").Inc(int64(proto.Size(content)))
+		if sess != nil {
+			sess.blockSent(attachment, block.Header.Number)
+		}
 
 		if stopNum == block.Header.Number {
 			break
@@ -225,6 +349,27 @@ func sendStatusReply(srv ab.AtomicBroadcast_DeliverServer, status cb.Status) err
 
 }
 
+func sendSessionReply(srv ab.AtomicBroadcast_DeliverServer, token []byte, nextBlock uint64) error {
+	return srv.Send(&ab.DeliverResponse{
+		Type: &ab.DeliverResponse_Session{Session: &ab.DeliverSessionInfo{Token: token, NextBlock: nextBlock}},
+	})
+}
+
+// blockContent returns the part of the block requested by the content type of a deliver request
+func blockContent(block *cb.Block, contentType ab.SeekInfo_SeekContentType) *cb.Block {
+	switch contentType {
+	case ab.SeekInfo_HEADER_WITH_SIG:
+		return &cb.Block{Header: block.Header, Metadata: block.Metadata}
+	case ab.SeekInfo_FILTERED:
+		if utils.IsConfigBlock(block) {
+			return block
+		}
+		return &cb.Block{Header: block.Header, Metadata: block.Metadata}
+	default:
+		return block
+	}
+}
+
 func sendBlockReply(srv ab.AtomicBroadcast_DeliverServer, block *cb.Block) error {
 	return srv.Send(&ab.DeliverResponse{
 		Type: &ab.DeliverResponse_Block{Block: block},
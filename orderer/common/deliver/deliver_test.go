@@ -22,6 +22,7 @@ import (
 	"testing"
 	"time"
 
+	mockmetrics "github.com/hyperledger/fabric/common/mocks/metrics"
 	mockpolicies "github.com/hyperledger/fabric/common/mocks/policies"
 	"github.com/hyperledger/fabric/common/policies"
 	"github.com/hyperledger/fabric/common/tools/configtxgen/provisional"
@@ -294,6 +295,30 @@ func TestUnauthorizedSeek(t *testing.T) {
 	}
 }
 
+func TestPolicyNameSeek(t *testing.T) {
+	mm := newMockMultichainManager()
+	policyManager := mm.chains[systemChainID].policyManager
+	policyManager.Policy.Err = fmt.Errorf("Fail to evaluate policy")
+	policyManager.PolicyMap = map[string]policies.Policy{policies.ChannelApplicationReaders: &mockpolicies.Policy{}}
+
+	m := newMockD()
+	defer close(m.recvChan)
+	ds := NewHandlerImplWithPolicy(mm, policies.ChannelApplicationReaders)
+
+	go ds.Handle(m)
+
+	m.recvChan <- makeSeek(systemChainID, &ab.SeekInfo{Start: seekSpecified(uint64(0)), Stop: seekSpecified(uint64(0)), Behavior: ab.SeekInfo_BLOCK_UNTIL_READY})
+
+	select {
+	case deliverReply := <-m.sendChan:
+		if deliverReply.GetBlock() == nil {
+			t.Fatalf("Received an error on the reply channel")
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Timed out waiting to get the block")
+	}
+}
+
 func TestRevokedAuthorizationSeek(t *testing.T) {
 	mm := newMockMultichainManager()
 	for i := 1; i < ledgerSize; i++ {
@@ -672,3 +697,113 @@ func TestMissingSeekPosition(t *testing.T) {
 		t.Fatalf("Timed out waiting to get all blocks")
 	}
 }
+
+func TestHeaderWithSigSeek(t *testing.T) {
+	m := newMockD()
+	defer close(m.recvChan)
+
+	ds := initializeDeliverHandler()
+	go ds.Handle(m)
+
+	m.recvChan <- makeSeek(systemChainID, &ab.SeekInfo{Start: seekOldest, Stop: seekNewest, Behavior: ab.SeekInfo_BLOCK_UNTIL_READY, ContentType: ab.SeekInfo_HEADER_WITH_SIG})
+
+	count := uint64(0)
+	for {
+		select {
+		case deliverReply := <-m.sendChan:
+			if deliverReply.GetBlock() == nil {
+				assert.Equal(t, cb.Status_SUCCESS, deliverReply.GetStatus(), "Received an error on the reply channel")
+				assert.Equal(t, uint64(ledgerSize), count, "Received wrong number of blocks")
+				return
+			}
+			assert.Equal(t, count, deliverReply.GetBlock().Header.Number)
+			assert.NotNil(t, deliverReply.GetBlock().Metadata, "Expected the block metadata")
+			assert.Nil(t, deliverReply.GetBlock().Data, "Expected no block data")
+		case <-time.After(time.Second):
+			t.Fatalf("Timed out waiting to get all blocks")
+		}
+		count++
+	}
+}
+
+func TestFilteredSeek(t *testing.T) {
+	m := newMockD()
+	defer close(m.recvChan)
+
+	mm := newMockMultichainManager()
+	l := mm.chains[systemChainID].ledger
+	configEnv := &cb.Envelope{Payload: utils.MarshalOrPanic(&cb.Payload{
+		Header: &cb.Header{ChannelHeader: utils.MarshalOrPanic(&cb.ChannelHeader{
+			Type:      int32(cb.HeaderType_CONFIG),
+			ChannelId: systemChainID,
+		})},
+	})}
+	l.Append(ledger.CreateNextBlock(l, []*cb.Envelope{&cb.Envelope{Payload: []byte("1")}}))
+	l.Append(ledger.CreateNextBlock(l, []*cb.Envelope{configEnv}))
+
+	ds := NewHandlerImpl(mm)
+	go ds.Handle(m)
+
+	m.recvChan <- makeSeek(systemChainID, &ab.SeekInfo{Start: seekSpecified(1), Stop: seekSpecified(2), Behavior: ab.SeekInfo_BLOCK_UNTIL_READY, ContentType: ab.SeekInfo_FILTERED})
+
+	for _, expectData := range []bool{false, true} {
+		select {
+		case deliverReply := <-m.sendChan:
+			block := deliverReply.GetBlock()
+			if block == nil {
+				t.Fatalf("Received an error on the reply channel")
+			}
+			assert.NotNil(t, block.Metadata, "Expected the block metadata")
+			assert.Equal(t, expectData, block.Data != nil, "Expected only the config block with its data")
+		case <-time.After(time.Second):
+			t.Fatalf("Timed out waiting to get all blocks")
+		}
+	}
+
+	select {
+	case deliverReply := <-m.sendChan:
+		assert.Equal(t, cb.Status_SUCCESS, deliverReply.GetStatus(), "Received an error on the reply channel")
+	case <-time.After(time.Second):
+		t.Fatalf("Timed out waiting to get all blocks")
+	}
+}
+
+func TestUnknownContentTypeSeek(t *testing.T) {
+	m := newMockD()
+	defer close(m.recvChan)
+
+	ds := initializeDeliverHandler()
+	go ds.Handle(m)
+
+	m.recvChan <- makeSeek(systemChainID, &ab.SeekInfo{Start: seekNewest, Stop: seekNewest, Behavior: ab.SeekInfo_BLOCK_UNTIL_READY, ContentType: ab.SeekInfo_SeekContentType(42)})
+
+	select {
+	case deliverReply := <-m.sendChan:
+		assert.Equal(t, cb.Status_BAD_REQUEST, deliverReply.GetStatus(), "Received wrong error on the reply channel")
+	case <-time.After(time.Second):
+		t.Fatalf("Timed out waiting to get all blocks")
+	}
+}
+
+func TestDeliverMetrics(t *testing.T) {
+	m := newMockD()
+	defer close(m.recvChan)
+
+	ds := initializeDeliverHandler().(*deliverServer)
+	scope := mockmetrics.NewScope()
+	ds.scope = scope.SubScope("deliver")
+	go ds.Handle(m)
+
+	m.recvChan <- makeSeek(systemChainID, &ab.SeekInfo{Start: seekSpecified(3), Stop: seekSpecified(5), Behavior: ab.SeekInfo_BLOCK_UNTIL_READY})
+	for i := 0; i < 4; i++ {
+		select {
+		case <-m.sendChan:
+		case <-time.After(time.Second):
+			t.Fatalf("Timed out waiting to get all blocks")
+		}
+	}
+
+	tags := map[string]string{"channel": systemChainID}
+	assert.Equal(t, int64(1), scope.CounterOf("deliver.requests", tags).Value())
+	assert.Equal(t, int64(3), scope.CounterOf("deliver.blocks_sent", tags).Value())
+}
@@ -0,0 +1,355 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package deliver
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/crypto"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/utils"
+)
+
+const (
+	// sessionTTL is the time a resumable deliver session is kept once it is no longer delivered
+	sessionTTL = 10 * time.Minute
+
+	// maxSessions is the maximum number of resumable deliver sessions kept
+	maxSessions = 10000
+
+	// maxClientSessions is the maximum number of resumable deliver sessions kept per client, so
+	// that a single client cannot take all the sessions. Once a client reaches it, opening a new
+	// session of the client removes the session of the client no longer delivered the longest
+	maxClientSessions = 100
+)
+
+var (
+	errTooManySessions       = errors.New("too many deliver sessions")
+	errTooManyClientSessions = errors.New("too many deliver sessions of the client")
+	errUnknownSession        = errors.New("unknown or expired deliver session")
+	errSessionMismatch       = errors.New("the deliver session belongs to another client or channel")
+)
+
+// session is a resumable deliver session. It tracks the first block not acknowledged by the
+// client, from which the deliver resumes when the client reconnects
+type session struct {
+	token     []byte
+	channelID string
+	creator   []byte
+
+	mutex      sync.Mutex
+	ackWindow  uint64
+	next       uint64        // the first block not acknowledged
+	sent       uint64        // the block following the last block sent
+	attachment uint64        // incremented every time the session is delivered on a new stream
+	detachedAt time.Time     // the time the session stopped being delivered, zero while delivered
+	acked      chan struct{} // closed and replaced on acknowledgments and on attachments
+}
+
+// attach starts the deliver of the session on a stream, taking it over from the stream which
+// delivered it previously if any. It returns the attachment of the stream
+func (s *session) attach(ackWindow uint32) uint64 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.attachment++
+	s.ackWindow = uint64(ackWindow)
+	s.sent = s.next
+	s.detachedAt = time.Time{}
+	s.notify()
+	return s.attachment
+}
+
+// detach ends the deliver of the session on the stream of the attachment
+func (s *session) detach(attachment uint64, now time.Time) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.attachment == attachment {
+		s.detachedAt = now
+	}
+}
+
+// expired returns whether the session is no longer delivered since the TTL of the sessions
+func (s *session) expired(now time.Time) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return !s.detachedAt.IsZero() && now.Sub(s.detachedAt) > sessionTTL
+}
+
+// detachedSince returns the time the session stopped being delivered, zero while it is delivered
+func (s *session) detachedSince() time.Time {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.detachedAt
+}
+
+// nextBlock returns the first block not acknowledged
+func (s *session) nextBlock() uint64 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.next
+}
+
+// ack acknowledges the blocks up to blockNum on the stream of the attachment. The blocks not
+// sent yet cannot be acknowledged
+func (s *session) ack(attachment uint64, blockNum uint64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.attachment != attachment || blockNum >= s.sent || blockNum < s.next {
+		return
+	}
+	s.next = blockNum + 1
+	s.notify()
+}
+
+// blockSent records that the block was sent on the stream of the attachment
+func (s *session) blockSent(attachment uint64, blockNum uint64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.attachment == attachment && blockNum >= s.sent {
+		s.sent = blockNum + 1
+	}
+}
+
+// canSend returns whether the block may be sent on the stream of the attachment, and if not a
+// channel closed once it may have changed. It returns false and a nil channel once the session
+// is taken over by another stream
+func (s *session) canSend(attachment uint64, blockNum uint64) (bool, <-chan struct{}) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.attachment != attachment {
+		return false, nil
+	}
+	if s.ackWindow == 0 || blockNum < s.next+s.ackWindow {
+		return true, nil
+	}
+	return false, s.acked
+}
+
+// notify wakes up the streams waiting for acknowledgments, it must be called with the mutex held
+func (s *session) notify() {
+	close(s.acked)
+	s.acked = make(chan struct{})
+}
+
+// sessionRegistry holds the resumable deliver sessions
+type sessionRegistry struct {
+	mutex    sync.Mutex
+	sessions map[string]*session
+	now      func() time.Time
+}
+
+func newSessionRegistry() *sessionRegistry {
+	return &sessionRegistry{
+		sessions: make(map[string]*session),
+		now:      time.Now,
+	}
+}
+
+// open creates a session of the client for the channel, starting at block next
+func (r *sessionRegistry) open(channelID string, creator []byte, next uint64) (*session, error) {
+	token, err := crypto.GetRandomNonce()
+	if err != nil {
+		return nil, err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.purge()
+	if !r.makeRoomFor(creator) {
+		return nil, errTooManyClientSessions
+	}
+	if len(r.sessions) >= maxSessions {
+		return nil, errTooManySessions
+	}
+	s := &session{
+		token:     token,
+		channelID: channelID,
+		creator:   creator,
+		next:      next,
+		acked:     make(chan struct{}),
+	}
+	r.sessions[hex.EncodeToString(token)] = s
+	return s, nil
+}
+
+// resume returns the session of the token, which must belong to the client and the channel
+func (r *sessionRegistry) resume(token []byte, channelID string, creator []byte) (*session, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.purge()
+	s, ok := r.sessions[hex.EncodeToString(token)]
+	if !ok {
+		return nil, errUnknownSession
+	}
+	if s.channelID != channelID || !bytes.Equal(s.creator, creator) {
+		return nil, errSessionMismatch
+	}
+	return s, nil
+}
+
+// detach ends the deliver of the session on the stream of the attachment
+func (r *sessionRegistry) detach(s *session, attachment uint64) {
+	s.detach(attachment, r.now())
+}
+
+// makeRoomFor returns whether the client may open one more session. Once the client holds
+// maxClientSessions sessions, its session no longer delivered the longest is removed, and
+// false is returned if all its sessions are delivered. It must be called with the mutex held
+func (r *sessionRegistry) makeRoomFor(creator []byte) bool {
+	count := 0
+	var oldestKey string
+	var oldest time.Time
+	for key, s := range r.sessions {
+		if !bytes.Equal(s.creator, creator) {
+			continue
+		}
+		count++
+		if detachedAt := s.detachedSince(); !detachedAt.IsZero() && (oldest.IsZero() || detachedAt.Before(oldest)) {
+			oldestKey, oldest = key, detachedAt
+		}
+	}
+	if count < maxClientSessions {
+		return true
+	}
+	if oldest.IsZero() {
+		return false
+	}
+	delete(r.sessions, oldestKey)
+	return true
+}
+
+// purge removes the expired sessions, it must be called with the mutex held
+func (r *sessionRegistry) purge() {
+	now := r.now()
+	for key, s := range r.sessions {
+		if s.expired(now) {
+			delete(r.sessions, key)
+		}
+	}
+}
+
+// deliverStream reads the envelopes of a deliver stream. Once a resumable session is delivered
+// on the stream, the envelopes are read in the background, so that the acknowledgments of the
+// session are processed while its blocks are sent
+type deliverStream struct {
+	ab.AtomicBroadcast_DeliverServer
+
+	received chan received // nil until the envelopes are read in the background
+	closed   chan struct{} // closed once reading from the stream failed
+	done     chan struct{} // closed once the deliver of the stream ends
+
+	mutex      sync.Mutex
+	session    *session
+	attachment uint64
+}
+
+type received struct {
+	envelope *cb.Envelope
+	err      error
+}
+
+func newDeliverStream(srv ab.AtomicBroadcast_DeliverServer) *deliverStream {
+	return &deliverStream{AtomicBroadcast_DeliverServer: srv, done: make(chan struct{})}
+}
+
+// recv returns the next envelope of the stream which is not an acknowledgment
+func (s *deliverStream) recv() (*cb.Envelope, error) {
+	if s.received != nil {
+		r := <-s.received
+		return r.envelope, r.err
+	}
+	for {
+		envelope, err := s.Recv()
+		if err != nil || !s.handleAck(envelope) {
+			return envelope, err
+		}
+	}
+}
+
+// readInBackground starts reading the envelopes of the stream in the background
+func (s *deliverStream) readInBackground() {
+	if s.received != nil {
+		return
+	}
+	s.received = make(chan received)
+	s.closed = make(chan struct{})
+	go func() {
+		for {
+			envelope, err := s.Recv()
+			if err == nil && s.handleAck(envelope) {
+				continue
+			}
+			if err != nil {
+				close(s.closed)
+			}
+			select {
+			case s.received <- received{envelope: envelope, err: err}:
+			case <-s.done:
+				return
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+}
+
+// closedChan returns a channel closed once reading from the stream failed, it never closes
+// unless the envelopes are read in the background
+func (s *deliverStream) closedChan() <-chan struct{} {
+	return s.closed
+}
+
+// close ends the deliver of the stream
+func (s *deliverStream) close() {
+	close(s.done)
+}
+
+// setSession sets the session last delivered on the stream, to which the acknowledgments apply
+func (s *deliverStream) setSession(session *session, attachment uint64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.session = session
+	s.attachment = attachment
+}
+
+// handleAck returns whether the envelope is an acknowledgment, and applies it to the session
+// delivered on the stream if any
+func (s *deliverStream) handleAck(envelope *cb.Envelope) bool {
+	if envelope == nil {
+		return false
+	}
+	payload, err := utils.UnmarshalPayload(envelope.Payload)
+	if err != nil || payload.Header == nil {
+		return false
+	}
+	chdr, err := utils.UnmarshalChannelHeader(payload.Header.ChannelHeader)
+	if err != nil || cb.HeaderType(chdr.Type) != cb.HeaderType_DELIVER_ACK {
+		return false
+	}
+	ack := &ab.DeliverAck{}
+	if err = proto.Unmarshal(payload.Data, ack); err != nil {
+		logger.Warningf("Received a malformed deliver acknowledgment: %s", err)
+		return true
+	}
+
+	s.mutex.Lock()
+	session, attachment := s.session, s.attachment
+	s.mutex.Unlock()
+	if session == nil || session.channelID != chdr.ChannelId {
+		logger.Debugf("Ignoring deliver acknowledgment of block %d of channel %s outside of a session", ack.BlockNumber, chdr.ChannelId)
+		return true
+	}
+	session.ack(attachment, ack.BlockNumber)
+	return true
+}
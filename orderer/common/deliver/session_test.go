@@ -0,0 +1,256 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package deliver
+
+import (
+	"testing"
+	"time"
+
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func makeSessionSeek(chainID string, creator []byte, seekInfo *ab.SeekInfo) *cb.Envelope {
+	return &cb.Envelope{
+		Payload: utils.MarshalOrPanic(&cb.Payload{
+			Header: &cb.Header{
+				ChannelHeader:   utils.MarshalOrPanic(&cb.ChannelHeader{ChannelId: chainID, Type: int32(cb.HeaderType_DELIVER_SEEK_INFO)}),
+				SignatureHeader: utils.MarshalOrPanic(&cb.SignatureHeader{Creator: creator}),
+			},
+			Data: utils.MarshalOrPanic(seekInfo),
+		}),
+	}
+}
+
+func makeAck(chainID string, blockNum uint64) *cb.Envelope {
+	return &cb.Envelope{
+		Payload: utils.MarshalOrPanic(&cb.Payload{
+			Header: &cb.Header{
+				ChannelHeader: utils.MarshalOrPanic(&cb.ChannelHeader{ChannelId: chainID, Type: int32(cb.HeaderType_DELIVER_ACK)}),
+			},
+			Data: utils.MarshalOrPanic(&ab.DeliverAck{BlockNumber: blockNum}),
+		}),
+	}
+}
+
+func nextReply(t *testing.T, m *mockD) *ab.DeliverResponse {
+	select {
+	case deliverReply := <-m.sendChan:
+		return deliverReply
+	case <-time.After(time.Second):
+		t.Fatalf("Timed out waiting for a reply")
+	}
+	return nil
+}
+
+func expectBlocks(t *testing.T, m *mockD, start, end uint64) {
+	for blockNum := start; blockNum <= end; blockNum++ {
+		block := nextReply(t, m).GetBlock()
+		if assert.NotNil(t, block, "Expected block %d", blockNum) {
+			assert.Equal(t, blockNum, block.Header.Number)
+		}
+	}
+}
+
+func expectNoReply(t *testing.T, m *mockD) {
+	select {
+	case deliverReply := <-m.sendChan:
+		t.Fatalf("Unexpected reply %v", deliverReply)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestDeliverSession(t *testing.T) {
+	ds := initializeDeliverHandler()
+	creator := []byte("client")
+
+	// open a session with an ack window of 3 blocks
+	m := newMockD()
+	done := make(chan error)
+	go func() { done <- ds.Handle(m) }()
+	m.recvChan <- makeSessionSeek(systemChainID, creator, &ab.SeekInfo{
+		Start:    seekOldest,
+		Stop:     seekNewest,
+		Behavior: ab.SeekInfo_BLOCK_UNTIL_READY,
+		Session:  &ab.DeliverSession{AckWindow: 3},
+	})
+	info := nextReply(t, m).GetSession()
+	assert.NotNil(t, info)
+	assert.NotEmpty(t, info.Token)
+	assert.Equal(t, uint64(0), info.NextBlock)
+	expectBlocks(t, m, 0, 2)
+	expectNoReply(t, m)
+
+	// the acknowledgments open the window
+	m.recvChan <- makeAck(systemChainID, 1)
+	expectBlocks(t, m, 3, 4)
+	expectNoReply(t, m)
+
+	// the blocks not sent yet cannot be acknowledged
+	m.recvChan <- makeAck(systemChainID, 8)
+	expectNoReply(t, m)
+
+	// the client hangs up
+	close(m.recvChan)
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatalf("Timed out waiting for the deliver to end")
+	}
+
+	// the session resumes after the last acknowledged block without a start position
+	m = newMockD()
+	defer close(m.recvChan)
+	go ds.Handle(m)
+	m.recvChan <- makeSessionSeek(systemChainID, creator, &ab.SeekInfo{
+		Stop:     seekNewest,
+		Behavior: ab.SeekInfo_BLOCK_UNTIL_READY,
+		Session:  &ab.DeliverSession{Token: info.Token},
+	})
+	resumed := nextReply(t, m).GetSession()
+	assert.NotNil(t, resumed)
+	assert.Equal(t, info.Token, resumed.Token)
+	assert.Equal(t, uint64(2), resumed.NextBlock)
+	expectBlocks(t, m, 2, ledgerSize-1)
+	assert.Equal(t, cb.Status_SUCCESS, nextReply(t, m).GetStatus())
+
+	// the session was fully acknowledged up to the stop position
+	m.recvChan <- makeAck(systemChainID, 5)
+	m.recvChan <- makeSessionSeek(systemChainID, creator, &ab.SeekInfo{
+		Stop:    seekSpecified(5),
+		Session: &ab.DeliverSession{Token: info.Token},
+	})
+	assert.Equal(t, cb.Status_SUCCESS, nextReply(t, m).GetStatus())
+
+	// the session belongs to another client
+	m.recvChan <- makeSessionSeek(systemChainID, []byte("stranger"), &ab.SeekInfo{
+		Stop:    seekNewest,
+		Session: &ab.DeliverSession{Token: info.Token},
+	})
+	assert.Equal(t, cb.Status_FORBIDDEN, nextReply(t, m).GetStatus())
+
+	// unknown session
+	m.recvChan <- makeSessionSeek(systemChainID, creator, &ab.SeekInfo{
+		Stop:    seekNewest,
+		Session: &ab.DeliverSession{Token: []byte("unknown")},
+	})
+	assert.Equal(t, cb.Status_NOT_FOUND, nextReply(t, m).GetStatus())
+}
+
+func TestDeliverSessionTakeOver(t *testing.T) {
+	ds := initializeDeliverHandler()
+	creator := []byte("client")
+
+	m1 := newMockD()
+	defer close(m1.recvChan)
+	go ds.Handle(m1)
+	m1.recvChan <- makeSessionSeek(systemChainID, creator, &ab.SeekInfo{
+		Start:    seekOldest,
+		Stop:     seekNewest,
+		Behavior: ab.SeekInfo_BLOCK_UNTIL_READY,
+		Session:  &ab.DeliverSession{AckWindow: 2},
+	})
+	info := nextReply(t, m1).GetSession()
+	assert.NotNil(t, info)
+	expectBlocks(t, m1, 0, 1)
+
+	// the session is resumed on another stream while the first one waits for acknowledgments
+	m2 := newMockD()
+	defer close(m2.recvChan)
+	go ds.Handle(m2)
+	m2.recvChan <- makeSessionSeek(systemChainID, creator, &ab.SeekInfo{
+		Stop:     seekSpecified(2),
+		Behavior: ab.SeekInfo_BLOCK_UNTIL_READY,
+		Session:  &ab.DeliverSession{Token: info.Token},
+	})
+	assert.Equal(t, cb.Status_SERVICE_UNAVAILABLE, nextReply(t, m1).GetStatus())
+	assert.Equal(t, uint64(0), nextReply(t, m2).GetSession().NextBlock)
+	expectBlocks(t, m2, 0, 2)
+	assert.Equal(t, cb.Status_SUCCESS, nextReply(t, m2).GetStatus())
+
+	// the acknowledgments of the first stream no longer apply
+	m1.recvChan <- makeAck(systemChainID, 1)
+	m2.recvChan <- makeSessionSeek(systemChainID, creator, &ab.SeekInfo{
+		Stop:     seekSpecified(0),
+		Behavior: ab.SeekInfo_BLOCK_UNTIL_READY,
+		Session:  &ab.DeliverSession{Token: info.Token},
+	})
+	assert.Equal(t, uint64(0), nextReply(t, m2).GetSession().NextBlock)
+	expectBlocks(t, m2, 0, 0)
+	assert.Equal(t, cb.Status_SUCCESS, nextReply(t, m2).GetStatus())
+}
+
+func TestSessionRegistry(t *testing.T) {
+	r := newSessionRegistry()
+	now := time.Now()
+	r.now = func() time.Time { return now }
+
+	s, err := r.open("mychannel", []byte("client"), 5)
+	assert.NoError(t, err)
+	attachment := s.attach(0)
+
+	resumed, err := r.resume(s.token, "mychannel", []byte("client"))
+	assert.NoError(t, err)
+	assert.Equal(t, s, resumed)
+	_, err = r.resume(s.token, "otherchannel", []byte("client"))
+	assert.Equal(t, errSessionMismatch, err)
+	_, err = r.resume(s.token, "mychannel", []byte("stranger"))
+	assert.Equal(t, errSessionMismatch, err)
+
+	// the sessions delivered do not expire
+	now = now.Add(2 * sessionTTL)
+	_, err = r.resume(s.token, "mychannel", []byte("client"))
+	assert.NoError(t, err)
+
+	// the sessions expire once no longer delivered since the TTL
+	r.detach(s, attachment)
+	now = now.Add(sessionTTL)
+	_, err = r.resume(s.token, "mychannel", []byte("client"))
+	assert.NoError(t, err)
+	now = now.Add(time.Second)
+	_, err = r.resume(s.token, "mychannel", []byte("client"))
+	assert.Equal(t, errUnknownSession, err)
+	assert.Empty(t, r.sessions)
+}
+
+func TestSessionRegistryClientLimit(t *testing.T) {
+	r := newSessionRegistry()
+	now := time.Now()
+	r.now = func() time.Time { return now }
+
+	var sessions []*session
+	var attachments []uint64
+	for i := 0; i < maxClientSessions; i++ {
+		s, err := r.open("mychannel", []byte("greedy"), 0)
+		assert.NoError(t, err)
+		sessions = append(sessions, s)
+		attachments = append(attachments, s.attach(0))
+	}
+
+	// the client cannot open more sessions while all its sessions are delivered
+	_, err := r.open("mychannel", []byte("greedy"), 0)
+	assert.Equal(t, errTooManyClientSessions, err)
+	// while the other clients still can
+	_, err = r.open("mychannel", []byte("client"), 0)
+	assert.NoError(t, err)
+
+	// the session of the client no longer delivered the longest makes room for the new one
+	r.detach(sessions[3], attachments[3])
+	now = now.Add(time.Second)
+	r.detach(sessions[1], attachments[1])
+	s, err := r.open("mychannel", []byte("greedy"), 0)
+	assert.NoError(t, err)
+	s.attach(0)
+	_, err = r.resume(sessions[3].token, "mychannel", []byte("greedy"))
+	assert.Equal(t, errUnknownSession, err)
+	_, err = r.resume(sessions[1].token, "mychannel", []byte("greedy"))
+	assert.NoError(t, err)
+	assert.Len(t, r.sessions, maxClientSessions+1)
+}
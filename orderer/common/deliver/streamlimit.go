@@ -0,0 +1,52 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package deliver
+
+import "sync"
+
+// clientStreams counts the deliver streams of every client which currently deliver blocks
+type clientStreams struct {
+	max int // the maximum number of streams per client, 0 if unlimited
+
+	mutex   sync.Mutex
+	streams map[string]int
+}
+
+func newClientStreams(max int) *clientStreams {
+	return &clientStreams{
+		max:     max,
+		streams: make(map[string]int),
+	}
+}
+
+// acquire returns whether the client may deliver blocks on one more stream, in which case the
+// stream must be released once it stops delivering blocks
+func (c *clientStreams) acquire(creator []byte) bool {
+	if c.max <= 0 {
+		return true
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.streams[string(creator)] >= c.max {
+		return false
+	}
+	c.streams[string(creator)]++
+	return true
+}
+
+// release ends the deliver of blocks of the client on a stream it acquired
+func (c *clientStreams) release(creator []byte) {
+	if c.max <= 0 {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.streams[string(creator)]--
+	if c.streams[string(creator)] <= 0 {
+		delete(c.streams, string(creator))
+	}
+}
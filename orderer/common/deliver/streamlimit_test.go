@@ -0,0 +1,59 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package deliver
+
+import (
+	"testing"
+	"time"
+
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeliverStreamLimit(t *testing.T) {
+	ds := NewHandlerImplWithStreamLimit(newMockMultichainManager(), 1)
+	waitForBlock1 := &ab.SeekInfo{Start: seekSpecified(1), Stop: seekSpecified(1), Behavior: ab.SeekInfo_BLOCK_UNTIL_READY}
+
+	// the stream of a session stops delivering blocks once the client hangs up
+	m1 := newMockD()
+	done := make(chan error)
+	go func() { done <- ds.Handle(m1) }()
+	m1.recvChan <- makeSessionSeek(systemChainID, []byte("client"), &ab.SeekInfo{
+		Start:    seekSpecified(1),
+		Stop:     seekSpecified(1),
+		Behavior: ab.SeekInfo_BLOCK_UNTIL_READY,
+		Session:  &ab.DeliverSession{},
+	})
+	assert.NotNil(t, nextReply(t, m1).GetSession())
+	expectNoReply(t, m1)
+
+	// the client already delivers blocks on a stream
+	m2 := newMockD()
+	defer close(m2.recvChan)
+	go ds.Handle(m2)
+	m2.recvChan <- makeSessionSeek(systemChainID, []byte("client"), waitForBlock1)
+	assert.Equal(t, cb.Status_SERVICE_UNAVAILABLE, nextReply(t, m2).GetStatus())
+
+	// the streams of the other clients are not limited
+	m3 := newMockD()
+	defer close(m3.recvChan)
+	go ds.Handle(m3)
+	m3.recvChan <- makeSessionSeek(systemChainID, []byte("other"), waitForBlock1)
+	expectNoReply(t, m3)
+
+	// the streams done delivering blocks no longer count
+	close(m1.recvChan)
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatalf("Timed out waiting for the deliver to end")
+	}
+	m2.recvChan <- makeSessionSeek(systemChainID, []byte("client"), waitForBlock1)
+	expectNoReply(t, m2)
+}
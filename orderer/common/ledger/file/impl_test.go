@@ -24,6 +24,7 @@ import (
 	"testing"
 
 	cl "github.com/hyperledger/fabric/common/ledger"
+	"github.com/hyperledger/fabric/common/ledger/blkstorage"
 	"github.com/hyperledger/fabric/common/tools/configtxgen/provisional"
 	"github.com/hyperledger/fabric/orderer/common/ledger"
 	cb "github.com/hyperledger/fabric/protos/common"
@@ -117,6 +118,22 @@ func (mbs *mockBlockStore) RetrieveTxValidationCodeByTxID(txID string) (peer.TxV
 	return mbs.txValidationCode, mbs.defaultError
 }
 
+func (mbs *mockBlockStore) RetrieveTxValidationCodesByTxIDs(txIDs []string) (map[string]peer.TxValidationCode, error) {
+	return nil, mbs.defaultError
+}
+
+func (mbs *mockBlockStore) RetrieveTxsByCreator(creator []byte, start blkstorage.TxPosition, limit int) ([]*peer.ProcessedTransaction, *blkstorage.TxPosition, error) {
+	return nil, nil, mbs.defaultError
+}
+
+func (mbs *mockBlockStore) RetrieveTxsByMSPIDAndChaincode(mspID string, chaincodeName string, start blkstorage.TxPosition, limit int) ([]*peer.ProcessedTransaction, *blkstorage.TxPosition, error) {
+	return nil, nil, mbs.defaultError
+}
+
+func (mbs *mockBlockStore) VerifyChain(startHeight uint64, endHeight uint64) (*blkstorage.CorruptionReport, error) {
+	return &blkstorage.CorruptionReport{StartHeight: startHeight, EndHeight: endHeight}, mbs.defaultError
+}
+
 func (*mockBlockStore) Shutdown() {
 }
 
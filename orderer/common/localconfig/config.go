@@ -21,6 +21,7 @@ import (
 	"time"
 
 	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/common/metrics"
 	"github.com/hyperledger/fabric/common/viperutil"
 
 	"github.com/Shopify/sarama"
@@ -33,6 +34,7 @@ import (
 
 	bccsp "github.com/hyperledger/fabric/bccsp/factory"
 	"github.com/hyperledger/fabric/common/tools/configtxgen/provisional"
+	"github.com/hyperledger/fabric/orderer/common/blockcutter"
 )
 
 const (
@@ -65,24 +67,41 @@ type TopLevel struct {
 	FileLedger FileLedger
 	RAMLedger  RAMLedger
 	Kafka      Kafka
+	EtcdRaft   EtcdRaft
 	Debug      Debug
 }
 
 // General contains config which should be common among all orderer types.
 type General struct {
-	LedgerType     string
-	ListenAddress  string
-	ListenPort     uint16
-	TLS            TLS
-	GenesisMethod  string
-	GenesisProfile string
-	SystemChannel  string
-	GenesisFile    string
-	Profile        Profile
-	LogLevel       string
-	LocalMSPDir    string
-	LocalMSPID     string
-	BCCSP          *bccsp.FactoryOpts
+	LedgerType           string
+	ListenAddress        string
+	ListenPort           uint16
+	ReusePortListeners   int
+	TLS                  TLS
+	FairOrdering         FairOrdering
+	RateLimit            RateLimit
+	EnvelopeExpiration   EnvelopeExpiration
+	Backpressure         Backpressure
+	OrderingReceipts     OrderingReceipts
+	ResourceLimits       ResourceLimits
+	RulePlugins          []RulePlugin
+	BlockCutter          BlockCutter
+	Metrics              Metrics
+	Replication          Replication
+	ChannelParticipation ChannelParticipation
+	Operations           Operations
+	GenesisMethod        string
+	GenesisProfile       string
+	SystemChannel        string
+	GenesisFile          string
+	Profile              Profile
+	LogLevel             string
+	LogFormat            string
+	LocalMSPDir          string
+	LocalMSPID           string
+	LocalMSPRefresh      time.Duration
+	ChannelSigners       []ChannelSigner
+	BCCSP                *bccsp.FactoryOpts
 }
 
 // TLS contains config for TLS connections.
@@ -95,6 +114,117 @@ type TLS struct {
 	ClientRootCAs     []string
 }
 
+// FairOrdering contains configuration for the reordering window that interleaves
+// the messages of the clients broadcasting to a channel before they are ordered.
+type FairOrdering struct {
+	Enabled     bool
+	Window      time.Duration
+	MaxMessages int
+}
+
+// RateLimit contains configuration for limiting the rate at which each client,
+// identified by the creator of its messages, may broadcast messages.
+type RateLimit struct {
+	Enabled            bool
+	EnvelopesPerSecond float64
+	Burst              int
+}
+
+// EnvelopeExpiration contains configuration for rejecting the messages broadcast long
+// after they were created, by their timestamp and their epoch.
+type EnvelopeExpiration struct {
+	Enabled     bool
+	TimeWindow  time.Duration
+	EpochWindow uint64
+}
+
+// Backpressure contains configuration for bounding the number of messages broadcast
+// to a channel that may be in flight at once.
+type Backpressure struct {
+	Enabled    bool
+	QueueDepth int
+	RetryAfter time.Duration
+}
+
+// OrderingReceipts contains configuration for answering the messages broadcast with
+// receipts, signed by the orderer, as soon as they are enqueued for ordering.
+type OrderingReceipts struct {
+	Enabled bool
+}
+
+// ResourceLimits contains configuration for bounding the resources of the orderer which
+// channels and clients may use. A limit of 0 is unlimited.
+type ResourceLimits struct {
+	MaxChannels                int
+	MaxDeliverStreamsPerClient int
+	ChannelBytesPerSecond      int
+	ChannelBurstBytes          int
+}
+
+// RulePlugin contains configuration for a Go plugin providing a message filter
+// rule, which channels may apply by naming it in their MessageFilterRules.
+type RulePlugin struct {
+	Name string
+	Path string
+}
+
+// ChannelSigner contains configuration for an identity signing the blocks of a channel
+// in place of the local MSP. The identity is the default signing identity of the MSP in
+// MSPDir, whose private key is retrieved through its own BCCSP, which may be an HSM.
+type ChannelSigner struct {
+	Channel string
+	MSPDir  string
+	MSPID   string
+	BCCSP   *bccsp.FactoryOpts
+}
+
+// BlockCutter contains configuration for the policy with which the ordered messages of
+// every channel are cut into batches, and for the Go plugin providing it if it is not built in.
+type BlockCutter struct {
+	Policy     string
+	PluginPath string
+}
+
+// Metrics contains configuration for the reporter the metrics of the orderer are reported to.
+type Metrics struct {
+	Reporter string
+	Interval time.Duration
+}
+
+// Replication contains configuration for pulling the chains of the channels from
+// the other orderers, as an orderer joining an existing ordering service does.
+type Replication struct {
+	RetryInterval time.Duration
+	MaxRetries    int
+	PullInterval  time.Duration
+}
+
+// ChannelParticipation contains configuration for the channel participation API,
+// through which an administrator joins the orderer to channels and removes it from them.
+type ChannelParticipation struct {
+	Enabled            bool
+	ListenAddress      string
+	MaxRequestBodySize uint32
+}
+
+// Operations contains configuration for the operations server, which reports the liveness
+// and the readiness of the orderer from the health of its components.
+type Operations struct {
+	Enabled            bool
+	ListenAddress      string
+	HealthCheckTimeout time.Duration
+	TLS                OperationsTLS
+}
+
+// OperationsTLS contains the TLS configuration of the operations server.
+type OperationsTLS struct {
+	Enabled            bool
+	PrivateKey         string
+	Certificate        string
+	ClientAuthRequired bool
+	ClientRootCAs      []string
+}
+
 // Profile contains configuration for Go pprof profiling.
 type Profile struct {
 	Enabled bool
@@ -114,10 +244,21 @@ type RAMLedger struct {
 
 // Kafka contains configuration for the Kafka-based orderer.
 type Kafka struct {
-	Retry   Retry
-	Verbose bool
-	Version sarama.KafkaVersion // TODO Move this to global config
-	TLS     TLS
+	Retry      Retry
+	Verbose    bool
+	Version    sarama.KafkaVersion // TODO Move this to global config
+	TLS        TLS
+	Checkpoint Checkpoint
+}
+
+// Checkpoint contains configuration for the periodic persistence of the last
+// offset processed by each channel, past which the orderer resumes consuming
+// the partition of the channel after a restart.
+type Checkpoint struct {
+	Enabled           bool
+	Dir               string
+	Interval          time.Duration
+	MaxReplayMessages int64
 }
 
 // Retry contains configuration related to retries and timeouts when the
@@ -163,6 +304,12 @@ type Consumer struct {
 	RetryBackoff time.Duration
 }
 
+// EtcdRaft contains configuration for the etcd/raft-based orderer.
+type EtcdRaft struct {
+	WALDir  string
+	SnapDir string
+}
+
 // Debug contains configuration for the orderer's debug parameters
 type Debug struct {
 	BroadcastTraceDir string
@@ -178,6 +325,48 @@ var defaults = TopLevel{
 		GenesisProfile: "SampleSingleMSPSolo",
 		SystemChannel:  provisional.TestChainID,
 		GenesisFile:    "genesisblock",
+		FairOrdering: FairOrdering{
+			Enabled:     false,
+			Window:      10 * time.Millisecond,
+			MaxMessages: 1000,
+		},
+		RateLimit: RateLimit{
+			Enabled:            false,
+			EnvelopesPerSecond: 100,
+			Burst:              200,
+		},
+		EnvelopeExpiration: EnvelopeExpiration{
+			Enabled:     false,
+			TimeWindow:  15 * time.Minute,
+			EpochWindow: 0,
+		},
+		Backpressure: Backpressure{
+			Enabled:    false,
+			QueueDepth: 1000,
+			RetryAfter: 100 * time.Millisecond,
+		},
+		BlockCutter: BlockCutter{
+			Policy: blockcutter.BatchSizePolicyName,
+		},
+		Metrics: Metrics{
+			Reporter: metrics.NullReporterName,
+			Interval: time.Minute,
+		},
+		Replication: Replication{
+			RetryInterval: 5 * time.Second,
+			MaxRetries:    10,
+			PullInterval:  10 * time.Second,
+		},
+		ChannelParticipation: ChannelParticipation{
+			Enabled:            false,
+			ListenAddress:      "127.0.0.1:7055",
+			MaxRequestBodySize: 1024 * 1024,
+		},
+		Operations: Operations{
+			Enabled:            false,
+			ListenAddress:      "127.0.0.1:8443",
+			HealthCheckTimeout: 30 * time.Second,
+		},
 		Profile: Profile{
 			Enabled: false,
 			Address: "0.0.0.0:6060",
@@ -222,6 +411,15 @@ var defaults = TopLevel{
 		TLS: TLS{
 			Enabled: false,
 		},
+		Checkpoint: Checkpoint{
+			Enabled:  false,
+			Dir:      "/var/hyperledger/production/orderer/kafka/checkpoint",
+			Interval: 10 * time.Second,
+		},
+	},
+	EtcdRaft: EtcdRaft{
+		WALDir:  "/var/hyperledger/production/orderer/etcdraft/wal",
+		SnapDir: "/var/hyperledger/production/orderer/etcdraft/snapshot",
 	},
 	Debug: Debug{
 		BroadcastTraceDir: "",
@@ -263,10 +461,22 @@ func (c *TopLevel) completeInitialization(configDir string) {
 		c.General.TLS.ClientRootCAs = translateCAs(configDir, c.General.TLS.ClientRootCAs)
 		cf.TranslatePathInPlace(configDir, &c.General.TLS.PrivateKey)
 		cf.TranslatePathInPlace(configDir, &c.General.TLS.Certificate)
+		c.General.Operations.TLS.ClientRootCAs = translateCAs(configDir, c.General.Operations.TLS.ClientRootCAs)
+		cf.TranslatePathInPlace(configDir, &c.General.Operations.TLS.PrivateKey)
+		cf.TranslatePathInPlace(configDir, &c.General.Operations.TLS.Certificate)
 		cf.TranslatePathInPlace(configDir, &c.General.GenesisFile)
 		cf.TranslatePathInPlace(configDir, &c.General.LocalMSPDir)
+		for i := range c.General.ChannelSigners {
+			cf.TranslatePathInPlace(configDir, &c.General.ChannelSigners[i].MSPDir)
+		}
 	}()
 
+	for _, signer := range c.General.ChannelSigners {
+		if signer.Channel == "" || signer.MSPDir == "" || signer.MSPID == "" {
+			logger.Panicf("General.ChannelSigners entries must set Channel, MSPDir and MSPID.")
+		}
+	}
+
 	for {
 		switch {
 		case c.General.LedgerType == "":
@@ -300,6 +510,74 @@ func (c *TopLevel) completeInitialization(configDir string) {
 		case c.Kafka.TLS.Enabled && c.Kafka.TLS.RootCAs == nil:
 			logger.Panicf("General.Kafka.TLS.CertificatePool must be set if General.Kafka.TLS.Enabled is set to true.")
 
+		case c.General.FairOrdering.Enabled && c.General.FairOrdering.Window == 0:
+			logger.Infof("Fair ordering enabled and General.FairOrdering.Window unset, setting to %v", defaults.General.FairOrdering.Window)
+			c.General.FairOrdering.Window = defaults.General.FairOrdering.Window
+		case c.General.FairOrdering.Enabled && c.General.FairOrdering.MaxMessages == 0:
+			logger.Infof("Fair ordering enabled and General.FairOrdering.MaxMessages unset, setting to %d", defaults.General.FairOrdering.MaxMessages)
+			c.General.FairOrdering.MaxMessages = defaults.General.FairOrdering.MaxMessages
+
+		case c.General.RateLimit.Enabled && c.General.RateLimit.EnvelopesPerSecond == 0:
+			logger.Infof("Rate limit enabled and General.RateLimit.EnvelopesPerSecond unset, setting to %v", defaults.General.RateLimit.EnvelopesPerSecond)
+			c.General.RateLimit.EnvelopesPerSecond = defaults.General.RateLimit.EnvelopesPerSecond
+		case c.General.RateLimit.Enabled && c.General.RateLimit.Burst == 0:
+			logger.Infof("Rate limit enabled and General.RateLimit.Burst unset, setting to %d", defaults.General.RateLimit.Burst)
+			c.General.RateLimit.Burst = defaults.General.RateLimit.Burst
+
+		case c.General.EnvelopeExpiration.Enabled && c.General.EnvelopeExpiration.TimeWindow == 0:
+			logger.Infof("Envelope expiration enabled and General.EnvelopeExpiration.TimeWindow unset, setting to %v", defaults.General.EnvelopeExpiration.TimeWindow)
+			c.General.EnvelopeExpiration.TimeWindow = defaults.General.EnvelopeExpiration.TimeWindow
+
+		case c.General.Backpressure.Enabled && c.General.Backpressure.QueueDepth == 0:
+			logger.Infof("Backpressure enabled and General.Backpressure.QueueDepth unset, setting to %d", defaults.General.Backpressure.QueueDepth)
+			c.General.Backpressure.QueueDepth = defaults.General.Backpressure.QueueDepth
+		case c.General.Backpressure.Enabled && c.General.Backpressure.RetryAfter == 0:
+			logger.Infof("Backpressure enabled and General.Backpressure.RetryAfter unset, setting to %v", defaults.General.Backpressure.RetryAfter)
+			c.General.Backpressure.RetryAfter = defaults.General.Backpressure.RetryAfter
+
+		case c.General.ResourceLimits.ChannelBytesPerSecond > 0 && c.General.ResourceLimits.ChannelBurstBytes == 0:
+			logger.Infof("General.ResourceLimits.ChannelBytesPerSecond set and General.ResourceLimits.ChannelBurstBytes unset, setting to %d", 2*c.General.ResourceLimits.ChannelBytesPerSecond)
+			c.General.ResourceLimits.ChannelBurstBytes = 2 * c.General.ResourceLimits.ChannelBytesPerSecond
+
+		case c.General.BlockCutter.Policy == "":
+			logger.Infof("General.BlockCutter.Policy unset, setting to %s", defaults.General.BlockCutter.Policy)
+			c.General.BlockCutter.Policy = defaults.General.BlockCutter.Policy
+
+		case c.General.Metrics.Reporter == "":
+			logger.Infof("General.Metrics.Reporter unset, setting to %s", defaults.General.Metrics.Reporter)
+			c.General.Metrics.Reporter = defaults.General.Metrics.Reporter
+		case c.General.Metrics.Interval == 0:
+			logger.Infof("General.Metrics.Interval unset, setting to %v", defaults.General.Metrics.Interval)
+			c.General.Metrics.Interval = defaults.General.Metrics.Interval
+
+		case c.General.Replication.RetryInterval == 0:
+			logger.Infof("General.Replication.RetryInterval unset, setting to %v", defaults.General.Replication.RetryInterval)
+			c.General.Replication.RetryInterval = defaults.General.Replication.RetryInterval
+		case c.General.Replication.MaxRetries == 0:
+			logger.Infof("General.Replication.MaxRetries unset, setting to %d", defaults.General.Replication.MaxRetries)
+			c.General.Replication.MaxRetries = defaults.General.Replication.MaxRetries
+		case c.General.Replication.PullInterval == 0:
+			logger.Infof("General.Replication.PullInterval unset, setting to %v", defaults.General.Replication.PullInterval)
+			c.General.Replication.PullInterval = defaults.General.Replication.PullInterval
+
+		case c.General.ChannelParticipation.Enabled && c.General.ChannelParticipation.ListenAddress == "":
+			logger.Infof("Channel participation enabled and General.ChannelParticipation.ListenAddress unset, setting to %s", defaults.General.ChannelParticipation.ListenAddress)
+			c.General.ChannelParticipation.ListenAddress = defaults.General.ChannelParticipation.ListenAddress
+		case c.General.ChannelParticipation.Enabled && c.General.ChannelParticipation.MaxRequestBodySize == 0:
+			logger.Infof("Channel participation enabled and General.ChannelParticipation.MaxRequestBodySize unset, setting to %d", defaults.General.ChannelParticipation.MaxRequestBodySize)
+			c.General.ChannelParticipation.MaxRequestBodySize = defaults.General.ChannelParticipation.MaxRequestBodySize
+		case c.General.GenesisMethod == "none" && !c.General.ChannelParticipation.Enabled:
+			logger.Panicf("General.ChannelParticipation.Enabled must be set to true if General.GenesisMethod is set to none.")
+
+		case c.General.Operations.Enabled && c.General.Operations.ListenAddress == "":
+			logger.Infof("Operations enabled and General.Operations.ListenAddress unset, setting to %s", defaults.General.Operations.ListenAddress)
+			c.General.Operations.ListenAddress = defaults.General.Operations.ListenAddress
+		case c.General.Operations.Enabled && c.General.Operations.HealthCheckTimeout == 0:
+			logger.Infof("Operations enabled and General.Operations.HealthCheckTimeout unset, setting to %v", defaults.General.Operations.HealthCheckTimeout)
+			c.General.Operations.HealthCheckTimeout = defaults.General.Operations.HealthCheckTimeout
+		case c.General.Operations.TLS.Enabled && (c.General.Operations.TLS.Certificate == "" || c.General.Operations.TLS.PrivateKey == ""):
+			logger.Panicf("General.Operations.TLS.Certificate and General.Operations.TLS.PrivateKey must be set if General.Operations.TLS.Enabled is set to true.")
+
 		case c.General.Profile.Enabled && c.General.Profile.Address == "":
 			logger.Infof("Profiling enabled and General.Profile.Address unset, setting to %s", defaults.General.Profile.Address)
 			c.General.Profile.Address = defaults.General.Profile.Address
@@ -360,6 +638,20 @@ func (c *TopLevel) completeInitialization(configDir string) {
 			logger.Infof("Kafka.Version unset, setting to %v", defaults.Kafka.Version)
 			c.Kafka.Version = defaults.Kafka.Version
 
+		case c.Kafka.Checkpoint.Enabled && c.Kafka.Checkpoint.Dir == "":
+			logger.Infof("Kafka checkpoints enabled and Kafka.Checkpoint.Dir unset, setting to %s", defaults.Kafka.Checkpoint.Dir)
+			c.Kafka.Checkpoint.Dir = defaults.Kafka.Checkpoint.Dir
+		case c.Kafka.Checkpoint.Enabled && c.Kafka.Checkpoint.Interval == 0:
+			logger.Infof("Kafka checkpoints enabled and Kafka.Checkpoint.Interval unset, setting to %v", defaults.Kafka.Checkpoint.Interval)
+			c.Kafka.Checkpoint.Interval = defaults.Kafka.Checkpoint.Interval
+
+		case c.EtcdRaft.WALDir == "":
+			logger.Infof("EtcdRaft.WALDir unset, setting to %s", defaults.EtcdRaft.WALDir)
+			c.EtcdRaft.WALDir = defaults.EtcdRaft.WALDir
+		case c.EtcdRaft.SnapDir == "":
+			logger.Infof("EtcdRaft.SnapDir unset, setting to %s", defaults.EtcdRaft.SnapDir)
+			c.EtcdRaft.SnapDir = defaults.EtcdRaft.SnapDir
+
 		default:
 			return
 		}
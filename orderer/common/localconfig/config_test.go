@@ -21,10 +21,12 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/hyperledger/fabric/common/tools/configtxgen/provisional"
+	cf "github.com/hyperledger/fabric/core/config"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -122,3 +124,77 @@ func TestProfileConfig(t *testing.T) {
 	uconf.completeInitialization(DummyPath)
 	assert.Equal(t, defaults.General.Profile.Address, uconf.General.Profile.Address, "Expected profile address to be filled with default value")
 }
+
+func TestChannelParticipationConfig(t *testing.T) {
+	uconf := &TopLevel{General: General{ChannelParticipation: ChannelParticipation{Enabled: true}, GenesisMethod: "none"}}
+	uconf.completeInitialization(DummyPath)
+	assert.Equal(t, defaults.General.ChannelParticipation.ListenAddress, uconf.General.ChannelParticipation.ListenAddress, "Expected listen address to be filled with default value")
+	assert.Equal(t, defaults.General.ChannelParticipation.MaxRequestBodySize, uconf.General.ChannelParticipation.MaxRequestBodySize, "Expected max request body size to be filled with default value")
+
+	uconf = &TopLevel{General: General{GenesisMethod: "none"}}
+	assert.Panics(t, func() { uconf.completeInitialization(DummyPath) }, "Expected a panic without channel participation")
+}
+
+func TestOperationsConfig(t *testing.T) {
+	uconf := &TopLevel{General: General{Operations: Operations{Enabled: true}}}
+	uconf.completeInitialization(DummyPath)
+	assert.Equal(t, defaults.General.Operations.ListenAddress, uconf.General.Operations.ListenAddress, "Expected listen address to be filled with default value")
+	assert.Equal(t, defaults.General.Operations.HealthCheckTimeout, uconf.General.Operations.HealthCheckTimeout, "Expected health check timeout to be filled with default value")
+
+	uconf = &TopLevel{General: General{Operations: Operations{Enabled: true, TLS: OperationsTLS{Enabled: true}}}}
+	assert.Panics(t, func() { uconf.completeInitialization(DummyPath) }, "Expected a panic without the TLS key pair of the operations server")
+}
+
+func TestMetricsConfig(t *testing.T) {
+	uconf := &TopLevel{}
+	uconf.completeInitialization(DummyPath)
+	assert.Equal(t, defaults.General.Metrics.Reporter, uconf.General.Metrics.Reporter, "Expected reporter to be filled with default value")
+	assert.Equal(t, defaults.General.Metrics.Interval, uconf.General.Metrics.Interval, "Expected interval to be filled with default value")
+}
+
+func TestRulePluginsConfig(t *testing.T) {
+	name, err := ioutil.TempDir("", "hyperledger_fabric")
+	assert.Nil(t, err, "Error creating temp dir: %s", err)
+	defer os.RemoveAll(name)
+
+	devConfigDir, err := cf.GetDevConfigDir()
+	assert.NoError(t, err, "Error locating the sample config")
+	sampleConfig, err := ioutil.ReadFile(filepath.Join(devConfigDir, "orderer.yaml"))
+	assert.NoError(t, err, "Error reading sample config")
+	rulePlugins := "    RulePlugins:\n      - Name: ExampleRule\n        Path: /plugins/examplerule.so\n"
+	config := strings.Replace(string(sampleConfig), "    RulePlugins:\n", rulePlugins, 1)
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(name, "orderer.yaml"), []byte(config), 0600))
+
+	os.Setenv("FABRIC_CFG_PATH", name)
+	defer os.Unsetenv("FABRIC_CFG_PATH")
+
+	conf := Load()
+	assert.Equal(t, []RulePlugin{{Name: "ExampleRule", Path: "/plugins/examplerule.so"}}, conf.General.RulePlugins)
+}
+
+func TestChannelSignersConfig(t *testing.T) {
+	name, err := ioutil.TempDir("", "hyperledger_fabric")
+	assert.Nil(t, err, "Error creating temp dir: %s", err)
+	defer os.RemoveAll(name)
+
+	devConfigDir, err := cf.GetDevConfigDir()
+	assert.NoError(t, err, "Error locating the sample config")
+	sampleConfig, err := ioutil.ReadFile(filepath.Join(devConfigDir, "orderer.yaml"))
+	assert.NoError(t, err, "Error reading sample config")
+	channelSigners := "    ChannelSigners:\n      - Channel: mychannel\n        MSPDir: mychannel/msp\n        MSPID: MyConsortiumOrdererMSP\n"
+	config := strings.Replace(string(sampleConfig), "    ChannelSigners:\n", channelSigners, 1)
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(name, "orderer.yaml"), []byte(config), 0600))
+
+	os.Setenv("FABRIC_CFG_PATH", name)
+	defer os.Unsetenv("FABRIC_CFG_PATH")
+
+	conf := Load()
+	assert.Equal(t, []ChannelSigner{{
+		Channel: "mychannel",
+		MSPDir:  filepath.Join(name, "mychannel/msp"),
+		MSPID:   "MyConsortiumOrdererMSP",
+	}}, conf.General.ChannelSigners)
+
+	uconf := &TopLevel{General: General{ChannelSigners: []ChannelSigner{{Channel: "mychannel"}}}}
+	assert.Panics(t, func() { uconf.completeInitialization(DummyPath) }, "Expected a channel signer without MSP to be rejected")
+}
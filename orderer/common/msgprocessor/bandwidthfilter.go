@@ -0,0 +1,87 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package msgprocessor
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	cb "github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/utils"
+
+	"github.com/pkg/errors"
+)
+
+// ChannelBandwidthRule rejects the messages broadcast to a channel once the channel receives more
+// bytes per second than allowed. Each channel gets a bucket of bytes which refills at the allowed
+// rate up to the burst size, the size of every message being taken from the bucket of its channel.
+// A single ChannelBandwidthRule is meant to be applied to the messages of all the channels when the
+// orderer receives them. It depends on the time and on the messages received by this orderer, so it
+// must not be part of the filters of a channel, which the consenters apply again to the messages they order
+type ChannelBandwidthRule struct {
+	rate  float64
+	burst float64
+	now   func() time.Time
+
+	lock      sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+// NewChannelBandwidthFilter creates a filter which allows each channel bytesPerSecond bytes per
+// second on average, and bursts of up to burst bytes. Messages larger than burst are always rejected
+func NewChannelBandwidthFilter(bytesPerSecond float64, burst int) *ChannelBandwidthRule {
+	return &ChannelBandwidthRule{
+		rate:      bytesPerSecond,
+		burst:     float64(burst),
+		now:       time.Now,
+		buckets:   make(map[string]*tokenBucket),
+		lastSweep: time.Now(),
+	}
+}
+
+// Apply returns an error wrapping ErrRateLimited if the channel of the message exceeded its bandwidth
+func (r *ChannelBandwidthRule) Apply(message *cb.Envelope) error {
+	chdr, err := utils.ChannelHeader(message)
+	if err != nil {
+		return fmt.Errorf("could not determine channel ID: %s", err)
+	}
+	size := float64(len(message.Payload) + len(message.Signature))
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	now := r.now()
+	r.sweep(now)
+
+	bucket, ok := r.buckets[chdr.ChannelId]
+	if !ok {
+		bucket = &tokenBucket{tokens: r.burst, last: now}
+		r.buckets[chdr.ChannelId] = bucket
+	}
+	bucket.refill(now, r.rate, r.burst)
+	if bucket.tokens < size {
+		return errors.Wrapf(errors.WithStack(ErrRateLimited), "channel %s exceeded %v bytes per second", chdr.ChannelId, r.rate)
+	}
+	bucket.tokens -= size
+	return nil
+}
+
+// sweep removes the buckets that refilled completely, so that the channels which stopped
+// receiving messages do not take up memory
+func (r *ChannelBandwidthRule) sweep(now time.Time) {
+	if now.Sub(r.lastSweep) < rateLimitSweepInterval {
+		return
+	}
+	r.lastSweep = now
+	for channelID, bucket := range r.buckets {
+		bucket.refill(now, r.rate, r.burst)
+		if bucket.tokens >= r.burst {
+			delete(r.buckets, channelID)
+		}
+	}
+}
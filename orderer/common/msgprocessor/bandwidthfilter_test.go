@@ -0,0 +1,65 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package msgprocessor
+
+import (
+	"testing"
+	"time"
+
+	cb "github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/utils"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func makeEnvelopeTo(channelID string) *cb.Envelope {
+	return &cb.Envelope{
+		Payload: utils.MarshalOrPanic(&cb.Payload{
+			Header: &cb.Header{
+				ChannelHeader: utils.MarshalOrPanic(&cb.ChannelHeader{ChannelId: channelID}),
+			},
+			Data: make([]byte, 100),
+		}),
+		Signature: make([]byte, 10),
+	}
+}
+
+func TestChannelBandwidthFilter(t *testing.T) {
+	size := len(makeEnvelopeTo("channel1").Payload) + 10
+	now := time.Now()
+	bwf := NewChannelBandwidthFilter(float64(10*size), 2*size)
+	bwf.now = func() time.Time { return now }
+
+	t.Run("Burst", func(t *testing.T) {
+		assert.Nil(t, bwf.Apply(makeEnvelopeTo("channel1")))
+		assert.Nil(t, bwf.Apply(makeEnvelopeTo("channel1")))
+		err := bwf.Apply(makeEnvelopeTo("channel1"))
+		assert.NotNil(t, err)
+		assert.Equal(t, ErrRateLimited, errors.Cause(err))
+	})
+	t.Run("OtherChannel", func(t *testing.T) {
+		assert.Nil(t, bwf.Apply(makeEnvelopeTo("channel2")))
+	})
+	t.Run("Refill", func(t *testing.T) {
+		now = now.Add(100 * time.Millisecond)
+		assert.Nil(t, bwf.Apply(makeEnvelopeTo("channel1")))
+		assert.NotNil(t, bwf.Apply(makeEnvelopeTo("channel1")))
+	})
+	t.Run("Sweep", func(t *testing.T) {
+		now = now.Add(rateLimitSweepInterval)
+		assert.Nil(t, bwf.Apply(makeEnvelopeTo("channel3")))
+		assert.Len(t, bwf.buckets, 1)
+	})
+	t.Run("TooLarge", func(t *testing.T) {
+		small := NewChannelBandwidthFilter(float64(10*size), size-1)
+		assert.NotNil(t, small.Apply(makeEnvelopeTo("channel1")))
+	})
+	t.Run("BadChannelHeader", func(t *testing.T) {
+		assert.NotNil(t, bwf.Apply(&cb.Envelope{}))
+	})
+}
@@ -0,0 +1,83 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package msgprocessor
+
+import (
+	"fmt"
+	"time"
+
+	cb "github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/utils"
+)
+
+// ChannelHeights returns the height of the ledger of a channel, or false if the orderer
+// does not serve the channel
+type ChannelHeights func(channelID string) (uint64, bool)
+
+// EnvelopeExpirationRule rejects the messages which were created too long before, or too far
+// after, they are received, so that messages held back for long cannot be replayed into the
+// ordering pipeline. A message is created at the timestamp of its channel header, which must be
+// within the time window of the current time of the orderer.
+// Fabric leaves the epoch of the channel header at 0 and gives it no meaning. This rule takes a
+// non-zero epoch to be the height of the ledger of the channel when the client created the message,
+// which must be within the epoch window of the current height of the channel, so that clients may
+// opt into a shorter lifetime for their messages. The epoch is not bound to the TLS session of the
+// client, as the channel header carries no TLS certificate hash, so a message can still be replayed
+// over another connection within the windows.
+// The rule depends on the clock and the ledger height of the orderer, so it is applied when the
+// orderer receives a message, and must not be part of the filters of a channel, which the consenters
+// apply again to the messages they order. A single EnvelopeExpirationRule is meant to be applied to
+// the messages of all the channels
+type EnvelopeExpirationRule struct {
+	timeWindow  time.Duration
+	epochWindow uint64
+	heights     ChannelHeights
+	now         func() time.Time
+}
+
+// NewEnvelopeExpirationFilter creates a filter which rejects the messages whose timestamp is more
+// than timeWindow away from the current time, and the messages bound to an epoch which is more
+// than epochWindow blocks behind the height of their channel. An epochWindow of 0 disables
+// the epoch check
+func NewEnvelopeExpirationFilter(timeWindow time.Duration, epochWindow uint64, heights ChannelHeights) *EnvelopeExpirationRule {
+	return &EnvelopeExpirationRule{
+		timeWindow:  timeWindow,
+		epochWindow: epochWindow,
+		heights:     heights,
+		now:         time.Now,
+	}
+}
+
+// Apply returns an error if the message is outside the time window, or its epoch expired
+func (r *EnvelopeExpirationRule) Apply(message *cb.Envelope) error {
+	chdr, err := utils.ChannelHeader(message)
+	if err != nil {
+		return fmt.Errorf("could not determine channel header: %s", err)
+	}
+
+	if chdr.Timestamp == nil {
+		return fmt.Errorf("message has no timestamp")
+	}
+	timestamp := time.Unix(chdr.Timestamp.Seconds, int64(chdr.Timestamp.Nanos))
+	now := r.now()
+	if timestamp.Before(now.Add(-r.timeWindow)) || timestamp.After(now.Add(r.timeWindow)) {
+		return fmt.Errorf("message timestamp %s is more than %s away from the orderer time %s", timestamp.UTC(), r.timeWindow, now.UTC())
+	}
+
+	if r.epochWindow == 0 || chdr.Epoch == 0 {
+		return nil
+	}
+	height, ok := r.heights(chdr.ChannelId)
+	if !ok {
+		// Channel creation messages are bound to the epoch of a channel which does not exist yet
+		return nil
+	}
+	if chdr.Epoch+r.epochWindow < height {
+		return fmt.Errorf("message epoch %d expired, the channel is at height %d and epochs are valid for %d blocks", chdr.Epoch, height, r.epochWindow)
+	}
+	return nil
+}
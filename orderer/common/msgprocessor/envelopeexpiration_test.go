@@ -0,0 +1,82 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package msgprocessor
+
+import (
+	"testing"
+	"time"
+
+	cb "github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/utils"
+
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/stretchr/testify/assert"
+)
+
+func makeTimestampedEnvelope(ts time.Time, epoch uint64) *cb.Envelope {
+	return &cb.Envelope{
+		Payload: utils.MarshalOrPanic(&cb.Payload{
+			Header: &cb.Header{
+				ChannelHeader: utils.MarshalOrPanic(&cb.ChannelHeader{
+					ChannelId: "mychannel",
+					Timestamp: &timestamp.Timestamp{Seconds: ts.Unix(), Nanos: int32(ts.Nanosecond())},
+					Epoch:     epoch,
+				}),
+			},
+		}),
+	}
+}
+
+func TestEnvelopeExpirationFilter(t *testing.T) {
+	now := time.Now()
+	heights := func(channelID string) (uint64, bool) {
+		if channelID != "mychannel" {
+			return 0, false
+		}
+		return 100, true
+	}
+	eef := NewEnvelopeExpirationFilter(time.Minute, 10, heights)
+	eef.now = func() time.Time { return now }
+
+	t.Run("WithinWindow", func(t *testing.T) {
+		assert.Nil(t, eef.Apply(makeTimestampedEnvelope(now.Add(-59*time.Second), 0)))
+		assert.Nil(t, eef.Apply(makeTimestampedEnvelope(now.Add(59*time.Second), 0)))
+	})
+	t.Run("TooOld", func(t *testing.T) {
+		assert.NotNil(t, eef.Apply(makeTimestampedEnvelope(now.Add(-2*time.Minute), 0)))
+	})
+	t.Run("TooNew", func(t *testing.T) {
+		assert.NotNil(t, eef.Apply(makeTimestampedEnvelope(now.Add(2*time.Minute), 0)))
+	})
+	t.Run("NoTimestamp", func(t *testing.T) {
+		env := makeEnvelopeOfType(cb.HeaderType_ENDORSER_TRANSACTION, nil)
+		assert.EqualError(t, eef.Apply(env), "message has no timestamp")
+	})
+	t.Run("BoundEpochValid", func(t *testing.T) {
+		assert.Nil(t, eef.Apply(makeTimestampedEnvelope(now, 90)))
+	})
+	t.Run("BoundEpochExpired", func(t *testing.T) {
+		assert.EqualError(t, eef.Apply(makeTimestampedEnvelope(now, 89)),
+			"message epoch 89 expired, the channel is at height 100 and epochs are valid for 10 blocks")
+	})
+	t.Run("UnboundEpoch", func(t *testing.T) {
+		assert.Nil(t, eef.Apply(makeTimestampedEnvelope(now, 0)), "Messages not bound to an epoch have no epoch to check")
+	})
+	t.Run("UnknownChannel", func(t *testing.T) {
+		eef := NewEnvelopeExpirationFilter(time.Minute, 10, func(string) (uint64, bool) { return 0, false })
+		eef.now = func() time.Time { return now }
+		assert.Nil(t, eef.Apply(makeTimestampedEnvelope(now, 1)))
+	})
+	t.Run("EpochCheckDisabled", func(t *testing.T) {
+		eef := NewEnvelopeExpirationFilter(time.Minute, 0, heights)
+		eef.now = func() time.Time { return now }
+		assert.Nil(t, eef.Apply(makeTimestampedEnvelope(now, 1)))
+	})
+	t.Run("BadChannelHeader", func(t *testing.T) {
+		assert.NotNil(t, eef.Apply(&cb.Envelope{}))
+	})
+}
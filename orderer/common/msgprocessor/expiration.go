@@ -0,0 +1,59 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package msgprocessor
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric/msp"
+	cb "github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/utils"
+)
+
+// NewExpirationRejectRule returns a rule that rejects messages signed by identities
+// whose certificates had expired when the messages were created, provided the orderer
+// capabilities of the channel enable the expiration check. The consenters apply the
+// rule again to the messages they order, so the certificates are checked against the
+// timestamp of the channel header of a message rather than the clock of the orderer,
+// which would let orderers reach different decisions on the same message. How far a
+// timestamp may be from the clock of the orderer is checked at broadcast ingress
+func NewExpirationRejectRule(support Support) Rule {
+	return &expirationRejectRule{support: support}
+}
+
+type expirationRejectRule struct {
+	support Support
+}
+
+// Apply checks whether the identity that created the envelope had expired at the timestamp
+// of the envelope, and returns an error wrapping msp.ErrIdentityExpired if it had
+func (exp *expirationRejectRule) Apply(message *cb.Envelope) error {
+	ordererConf, ok := exp.support.OrdererConfig()
+	if !ok {
+		return errors.New("channel has no orderer config, cannot determine whether to check identity expiration")
+	}
+	if !ordererConf.Capabilities().ExpirationCheck() {
+		return nil
+	}
+
+	chdr, err := utils.ChannelHeader(message)
+	if err != nil {
+		return fmt.Errorf("could not determine channel header: %s", err)
+	}
+	if chdr.Timestamp == nil {
+		return errors.New("message has no timestamp to check the expiration of its creator against")
+	}
+
+	signedData, err := message.AsSignedData()
+	if err != nil {
+		return fmt.Errorf("could not convert message to signedData: %s", err)
+	}
+
+	return msp.CheckExpiration(signedData[0].Identity, time.Unix(chdr.Timestamp.Seconds, int64(chdr.Timestamp.Nanos)))
+}
@@ -0,0 +1,106 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package msgprocessor
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	mockconfig "github.com/hyperledger/fabric/common/mocks/config"
+	fabricmsp "github.com/hyperledger/fabric/msp"
+	cb "github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/msp"
+	"github.com/hyperledger/fabric/protos/utils"
+
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func makeIdentity(t *testing.T, notAfter time.Time) []byte {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "client"},
+		NotBefore:    notAfter.Add(-time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(t, err)
+	return utils.MarshalOrPanic(&msp.SerializedIdentity{
+		Mspid:   "SampleOrg",
+		IdBytes: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+	})
+}
+
+func makeSignedMessage(creator []byte, ts time.Time) *cb.Envelope {
+	return &cb.Envelope{
+		Payload: utils.MarshalOrPanic(&cb.Payload{
+			Header: &cb.Header{
+				ChannelHeader: utils.MarshalOrPanic(&cb.ChannelHeader{
+					Timestamp: &timestamp.Timestamp{Seconds: ts.Unix(), Nanos: int32(ts.Nanosecond())},
+				}),
+				SignatureHeader: utils.MarshalOrPanic(&cb.SignatureHeader{Creator: creator}),
+			},
+		}),
+	}
+}
+
+func TestExpirationRejectRule(t *testing.T) {
+	capabilities := &mockconfig.OrdererCapabilities{ExpirationCheckVal: true}
+	ordererConfig := &mockconfig.Orderer{CapabilitiesVal: capabilities}
+	rule := NewExpirationRejectRule(&mockSizeFilterSupport{ordererConfig: ordererConfig})
+
+	now := time.Now()
+	expiredIdentity := makeIdentity(t, now.Add(-time.Minute))
+	expired := makeSignedMessage(expiredIdentity, now)
+	valid := makeSignedMessage(makeIdentity(t, now.Add(time.Hour)), now)
+
+	t.Run("Valid", func(t *testing.T) {
+		assert.NoError(t, rule.Apply(valid))
+	})
+	t.Run("Expired", func(t *testing.T) {
+		err := rule.Apply(expired)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "identity expired")
+		assert.Equal(t, fabricmsp.ErrIdentityExpired, errors.Cause(err))
+	})
+	t.Run("CreatedBeforeExpiration", func(t *testing.T) {
+		assert.NoError(t, rule.Apply(makeSignedMessage(expiredIdentity, now.Add(-2*time.Minute))),
+			"The certificate should be checked against the timestamp of the message, not the current time")
+	})
+	t.Run("NoTimestamp", func(t *testing.T) {
+		env := &cb.Envelope{
+			Payload: utils.MarshalOrPanic(&cb.Payload{
+				Header: &cb.Header{ChannelHeader: utils.MarshalOrPanic(&cb.ChannelHeader{ChannelId: "mychannel"})},
+			}),
+		}
+		assert.EqualError(t, rule.Apply(env), "message has no timestamp to check the expiration of its creator against")
+	})
+	t.Run("NotACertificate", func(t *testing.T) {
+		assert.NoError(t, rule.Apply(makeSignedMessage(utils.MarshalOrPanic(&msp.SerializedIdentity{IdBytes: []byte("foo")}), now)))
+	})
+	t.Run("BadMessage", func(t *testing.T) {
+		assert.Error(t, rule.Apply(&cb.Envelope{Payload: []byte("garbage")}))
+	})
+	t.Run("CapabilityDisabled", func(t *testing.T) {
+		capabilities.ExpirationCheckVal = false
+		defer func() { capabilities.ExpirationCheckVal = true }()
+		assert.NoError(t, rule.Apply(expired))
+	})
+	t.Run("NoOrdererConfig", func(t *testing.T) {
+		assert.Error(t, NewExpirationRejectRule(&mockSizeFilterSupport{}).Apply(valid))
+	})
+}
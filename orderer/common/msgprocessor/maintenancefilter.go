@@ -0,0 +1,104 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package msgprocessor
+
+import (
+	"bytes"
+	"fmt"
+
+	channelconfig "github.com/hyperledger/fabric/common/config/channel"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/utils"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// migrationSourceType and migrationTargetType are the consensus types between
+	// which the consensus type of a channel may be migrated
+	migrationSourceType = "kafka"
+	migrationTargetType = "etcdraft"
+)
+
+// MaintenanceFilter freezes the channels in maintenance mode, and validates the
+// consensus-type migration state transitions of the config transactions.
+// While a channel is in maintenance mode, only config transactions are accepted,
+// and the consensus type of the channel may change from Kafka to Raft
+type MaintenanceFilter struct {
+	support LimitedSupport
+}
+
+// NewMaintenanceFilter creates a new maintenance filter for the channel of the support
+func NewMaintenanceFilter(support LimitedSupport) *MaintenanceFilter {
+	return &MaintenanceFilter{support: support}
+}
+
+// Apply returns an error wrapping ErrMaintenanceMode for the transactions which are not
+// config transactions while the channel is in maintenance mode, and an error for the config
+// transactions which make an invalid consensus-type migration state transition
+func (mf *MaintenanceFilter) Apply(message *cb.Envelope) error {
+	ordererConf, ok := mf.support.OrdererConfig()
+	if !ok {
+		return errors.New("channel has no orderer config, cannot determine whether it is in maintenance mode")
+	}
+	if !ordererConf.Capabilities().ConsensusTypeMigration() && ordererConf.ConsensusState() == ab.ConsensusType_STATE_NORMAL {
+		return nil
+	}
+
+	chdr, err := utils.ChannelHeader(message)
+	if err != nil {
+		return fmt.Errorf("could not determine channel header: %s", err)
+	}
+	switch cb.HeaderType(chdr.Type) {
+	case cb.HeaderType_CONFIG_UPDATE:
+		return nil
+	case cb.HeaderType_CONFIG:
+		return mf.inspect(message, ordererConf)
+	}
+	if ordererConf.ConsensusState() == ab.ConsensusType_STATE_MAINTENANCE {
+		return errors.Wrapf(errors.WithStack(ErrMaintenanceMode), "channel %s only accepts config transactions", chdr.ChannelId)
+	}
+	return nil
+}
+
+// inspect validates the consensus-type migration state transition of the config transaction
+func (mf *MaintenanceFilter) inspect(message *cb.Envelope, ordererConf channelconfig.Orderer) error {
+	configEnvelope, err := resultingConfig(message)
+	if err != nil {
+		return fmt.Errorf("could not extract config: %s", err)
+	}
+	next, err := channelconfig.ConsensusTypeOf(configEnvelope.Config)
+	if err != nil {
+		return err
+	}
+	if next == nil {
+		// The orderer config of the channel is validated when the config is applied
+		return nil
+	}
+
+	currentType, currentState := ordererConf.ConsensusType(), ordererConf.ConsensusState()
+	switch {
+	case next.Type != currentType:
+		if currentState != ab.ConsensusType_STATE_MAINTENANCE || next.State != ab.ConsensusType_STATE_MAINTENANCE {
+			return fmt.Errorf("consensus type can only change from %s to %s in maintenance mode", currentType, next.Type)
+		}
+		if currentType != migrationSourceType || next.Type != migrationTargetType {
+			return fmt.Errorf("consensus type cannot migrate from %s to %s, only from %s to %s is supported",
+				currentType, next.Type, migrationSourceType, migrationTargetType)
+		}
+		if len(next.Metadata) == 0 {
+			return fmt.Errorf("consensus type %s requires consensus metadata", next.Type)
+		}
+	case next.State != currentState:
+		if !bytes.Equal(next.Metadata, ordererConf.ConsensusMetadata()) {
+			return fmt.Errorf("consensus metadata cannot change along with the consensus state, from %s to %s", currentState, next.State)
+		}
+		logger.Infof("Consensus state of the channel changes from %s to %s", currentState, next.State)
+	}
+	return nil
+}
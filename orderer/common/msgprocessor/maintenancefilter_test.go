@@ -0,0 +1,149 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package msgprocessor
+
+import (
+	"testing"
+
+	channelconfig "github.com/hyperledger/fabric/common/config/channel"
+	mockconfig "github.com/hyperledger/fabric/common/mocks/config"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/utils"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func makeEnvelopeOfType(headerType cb.HeaderType, data []byte) *cb.Envelope {
+	return &cb.Envelope{
+		Payload: utils.MarshalOrPanic(&cb.Payload{
+			Header: &cb.Header{
+				ChannelHeader: utils.MarshalOrPanic(&cb.ChannelHeader{Type: int32(headerType), ChannelId: "mychannel"}),
+			},
+			Data: data,
+		}),
+	}
+}
+
+func makeConsensusTypeConfig(consensusType *ab.ConsensusType) *cb.Envelope {
+	channelGroup := cb.NewConfigGroup()
+	channelGroup.Groups[channelconfig.OrdererGroupKey] = cb.NewConfigGroup()
+	channelGroup.Groups[channelconfig.OrdererGroupKey].Values[channelconfig.ConsensusTypeKey] = &cb.ConfigValue{
+		Value: utils.MarshalOrPanic(consensusType),
+	}
+	return makeEnvelopeOfType(cb.HeaderType_CONFIG, utils.MarshalOrPanic(&cb.ConfigEnvelope{
+		Config: &cb.Config{ChannelGroup: channelGroup},
+	}))
+}
+
+func newMaintenanceSupport(consensusType string, state ab.ConsensusType_State) *mockSupport {
+	return &mockSupport{
+		msc: &mockconfig.Orderer{
+			ConsensusTypeVal:     consensusType,
+			ConsensusMetadataVal: []byte("metadata"),
+			ConsensusStateVal:    state,
+			CapabilitiesVal:      &mockconfig.OrdererCapabilities{ConsensusTypeMigrationVal: true},
+		},
+	}
+}
+
+func TestMaintenanceFilterNormalMode(t *testing.T) {
+	mf := NewMaintenanceFilter(newMaintenanceSupport("kafka", ab.ConsensusType_STATE_NORMAL))
+	assert.Nil(t, mf.Apply(makeEnvelopeOfType(cb.HeaderType_ENDORSER_TRANSACTION, nil)))
+
+	t.Run("NoCapability", func(t *testing.T) {
+		mf := NewMaintenanceFilter(&mockSupport{msc: &mockconfig.Orderer{ConsensusTypeVal: "kafka"}})
+		assert.Nil(t, mf.Apply(makeConsensusTypeConfig(&ab.ConsensusType{Type: "etcdraft"})))
+	})
+	t.Run("EnterMaintenance", func(t *testing.T) {
+		assert.Nil(t, mf.Apply(makeConsensusTypeConfig(&ab.ConsensusType{
+			Type:     "kafka",
+			Metadata: []byte("metadata"),
+			State:    ab.ConsensusType_STATE_MAINTENANCE,
+		})))
+	})
+	t.Run("EnterMaintenanceChangingMetadata", func(t *testing.T) {
+		assert.EqualError(t, mf.Apply(makeConsensusTypeConfig(&ab.ConsensusType{
+			Type:  "kafka",
+			State: ab.ConsensusType_STATE_MAINTENANCE,
+		})), "consensus metadata cannot change along with the consensus state, from STATE_NORMAL to STATE_MAINTENANCE")
+	})
+	t.Run("ChangeTypeOutsideMaintenance", func(t *testing.T) {
+		assert.EqualError(t, mf.Apply(makeConsensusTypeConfig(&ab.ConsensusType{
+			Type:     "etcdraft",
+			Metadata: []byte("raft"),
+			State:    ab.ConsensusType_STATE_MAINTENANCE,
+		})), "consensus type can only change from kafka to etcdraft in maintenance mode")
+	})
+}
+
+func TestMaintenanceFilterMaintenanceMode(t *testing.T) {
+	mf := NewMaintenanceFilter(newMaintenanceSupport("kafka", ab.ConsensusType_STATE_MAINTENANCE))
+
+	t.Run("NormalTransaction", func(t *testing.T) {
+		err := mf.Apply(makeEnvelopeOfType(cb.HeaderType_ENDORSER_TRANSACTION, nil))
+		assert.NotNil(t, err)
+		assert.Equal(t, ErrMaintenanceMode, errors.Cause(err))
+	})
+	t.Run("ChannelCreation", func(t *testing.T) {
+		err := mf.Apply(makeEnvelopeOfType(cb.HeaderType_ORDERER_TRANSACTION, nil))
+		assert.Equal(t, ErrMaintenanceMode, errors.Cause(err))
+	})
+	t.Run("ConfigUpdate", func(t *testing.T) {
+		assert.Nil(t, mf.Apply(makeEnvelopeOfType(cb.HeaderType_CONFIG_UPDATE, nil)))
+	})
+	t.Run("OtherConfig", func(t *testing.T) {
+		assert.Nil(t, mf.Apply(makeEnvelopeOfType(cb.HeaderType_CONFIG, utils.MarshalOrPanic(&cb.ConfigEnvelope{
+			Config: &cb.Config{ChannelGroup: cb.NewConfigGroup()},
+		}))))
+	})
+	t.Run("Migrate", func(t *testing.T) {
+		assert.Nil(t, mf.Apply(makeConsensusTypeConfig(&ab.ConsensusType{
+			Type:     "etcdraft",
+			Metadata: []byte("raft"),
+			State:    ab.ConsensusType_STATE_MAINTENANCE,
+		})))
+	})
+	t.Run("MigrateLeavingMaintenance", func(t *testing.T) {
+		assert.NotNil(t, mf.Apply(makeConsensusTypeConfig(&ab.ConsensusType{
+			Type:     "etcdraft",
+			Metadata: []byte("raft"),
+			State:    ab.ConsensusType_STATE_NORMAL,
+		})))
+	})
+	t.Run("MigrateWithoutMetadata", func(t *testing.T) {
+		assert.EqualError(t, mf.Apply(makeConsensusTypeConfig(&ab.ConsensusType{
+			Type:  "etcdraft",
+			State: ab.ConsensusType_STATE_MAINTENANCE,
+		})), "consensus type etcdraft requires consensus metadata")
+	})
+	t.Run("MigrateToUnsupportedType", func(t *testing.T) {
+		assert.EqualError(t, mf.Apply(makeConsensusTypeConfig(&ab.ConsensusType{
+			Type:     "solo",
+			Metadata: []byte("solo"),
+			State:    ab.ConsensusType_STATE_MAINTENANCE,
+		})), "consensus type cannot migrate from kafka to solo, only from kafka to etcdraft is supported")
+	})
+	t.Run("ExitMaintenance", func(t *testing.T) {
+		assert.Nil(t, mf.Apply(makeConsensusTypeConfig(&ab.ConsensusType{
+			Type:     "kafka",
+			Metadata: []byte("metadata"),
+			State:    ab.ConsensusType_STATE_NORMAL,
+		})))
+	})
+	t.Run("BadConsensusType", func(t *testing.T) {
+		env := makeConsensusTypeConfig(&ab.ConsensusType{})
+		configEnvelope, _ := resultingConfig(env)
+		configEnvelope.Config.ChannelGroup.Groups[channelconfig.OrdererGroupKey].Values[channelconfig.ConsensusTypeKey].Value = []byte("garbage")
+		env = makeEnvelopeOfType(cb.HeaderType_CONFIG, utils.MarshalOrPanic(configEnvelope))
+		assert.NotNil(t, mf.Apply(env))
+	})
+	t.Run("BadChannelHeader", func(t *testing.T) {
+		assert.NotNil(t, mf.Apply(&cb.Envelope{}))
+	})
+}
@@ -10,9 +10,12 @@ package msgprocessor
 
 import (
 	"errors"
+	"fmt"
 
+	"github.com/hyperledger/fabric/common/configtx"
 	"github.com/hyperledger/fabric/common/flogging"
 	cb "github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/utils"
 )
 
 var logger = flogging.MustGetLogger("common/msgprocessor")
@@ -31,6 +34,14 @@ var ErrChannelDoesNotExist = errors.New("channel does not exist")
 // which are not permitted due to an authorization failure.
 var ErrPermissionDenied = errors.New("permission denied")
 
+// ErrRateLimited is returned by errors which are caused by transactions of a client
+// which broadcasts faster than the orderer allows
+var ErrRateLimited = errors.New("rate limit exceeded")
+
+// ErrMaintenanceMode is returned by errors which are caused by transactions broadcast
+// to a channel in maintenance mode, in which only config transactions are accepted
+var ErrMaintenanceMode = errors.New("channel is in maintenance mode")
+
 // Classification represents the possible message types for the system.
 type Classification int
 
@@ -58,4 +69,39 @@ type Processor interface {
 	// return the resulting config message and the configSeq the config was computed from.  If the config update message
 	// is invalid, an error is returned.
 	ProcessConfigUpdateMsg(env *cb.Envelope) (config *cb.Envelope, configSeq uint64, err error)
+
+	// SimulateConfigUpdateMsg is a dry-run of ProcessConfigUpdateMsg: the config update is validated in the same way,
+	// but rather than the message to be ordered, the config which would result from it is returned.
+	SimulateConfigUpdateMsg(env *cb.Envelope) (*cb.ConfigEnvelope, error)
+}
+
+// resultingConfig extracts the config carried by the message produced by ProcessConfigUpdateMsg,
+// which is either a CONFIG message, or an ORDERER_TRANSACTION wrapping the CONFIG message of a new channel
+func resultingConfig(config *cb.Envelope) (*cb.ConfigEnvelope, error) {
+	payload, err := utils.UnmarshalPayload(config.Payload)
+	if err != nil {
+		return nil, err
+	}
+
+	if payload.Header == nil {
+		return nil, fmt.Errorf("config message has no header")
+	}
+
+	chdr, err := utils.UnmarshalChannelHeader(payload.Header.ChannelHeader)
+	if err != nil {
+		return nil, err
+	}
+
+	switch chdr.Type {
+	case int32(cb.HeaderType_CONFIG):
+		return configtx.UnmarshalConfigEnvelope(payload.Data)
+	case int32(cb.HeaderType_ORDERER_TRANSACTION):
+		wrapped, err := utils.UnmarshalEnvelope(payload.Data)
+		if err != nil {
+			return nil, err
+		}
+		return resultingConfig(wrapped)
+	default:
+		return nil, fmt.Errorf("unexpected config message type %d", chdr.Type)
+	}
 }
@@ -0,0 +1,109 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package msgprocessor
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	cb "github.com/hyperledger/fabric/protos/common"
+
+	"github.com/pkg/errors"
+)
+
+// rateLimitSweepInterval is the interval at which the buckets of the clients that stopped
+// broadcasting are removed
+const rateLimitSweepInterval = time.Minute
+
+// RateLimitRule rejects the messages of a client once the client broadcasts faster than allowed.
+// A client is identified by the creator in the signature header of its messages, and gets a bucket
+// of tokens which refills at the allowed rate up to the burst size, one token being taken per message.
+// A single RateLimitRule is meant to be applied to the messages of all the channels when the orderer
+// receives them, so that a client cannot get around the limit by spreading its messages over channels.
+// It depends on the time and on the messages received by this orderer, so it must not be part of the
+// filters of a channel, which the consenters apply again to the messages they order
+type RateLimitRule struct {
+	rate  float64
+	burst float64
+	now   func() time.Time
+
+	lock      sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimitFilter creates a filter which allows each client envelopesPerSecond messages per
+// second on average, and bursts of up to burst messages
+func NewRateLimitFilter(envelopesPerSecond float64, burst int) *RateLimitRule {
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimitRule{
+		rate:      envelopesPerSecond,
+		burst:     float64(burst),
+		now:       time.Now,
+		buckets:   make(map[string]*tokenBucket),
+		lastSweep: time.Now(),
+	}
+}
+
+// Apply returns an error wrapping ErrRateLimited if the client of the message exceeded its rate
+func (r *RateLimitRule) Apply(message *cb.Envelope) error {
+	signedData, err := message.AsSignedData()
+	if err != nil {
+		return fmt.Errorf("could not convert message to signedData: %s", err)
+	}
+	creator := string(signedData[0].Identity)
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	now := r.now()
+	r.sweep(now)
+
+	bucket, ok := r.buckets[creator]
+	if !ok {
+		bucket = &tokenBucket{tokens: r.burst, last: now}
+		r.buckets[creator] = bucket
+	}
+	bucket.refill(now, r.rate, r.burst)
+	if bucket.tokens < 1 {
+		return errors.Wrapf(errors.WithStack(ErrRateLimited), "client exceeded %v messages per second", r.rate)
+	}
+	bucket.tokens--
+	return nil
+}
+
+func (b *tokenBucket) refill(now time.Time, rate, burst float64) {
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens += elapsed.Seconds() * rate
+		if b.tokens > burst {
+			b.tokens = burst
+		}
+		b.last = now
+	}
+}
+
+// sweep removes the buckets that refilled completely, which are the same as new buckets,
+// so that the clients that stopped broadcasting do not take up memory
+func (r *RateLimitRule) sweep(now time.Time) {
+	if now.Sub(r.lastSweep) < rateLimitSweepInterval {
+		return
+	}
+	r.lastSweep = now
+	for creator, bucket := range r.buckets {
+		bucket.refill(now, r.rate, r.burst)
+		if bucket.tokens >= r.burst {
+			delete(r.buckets, creator)
+		}
+	}
+}
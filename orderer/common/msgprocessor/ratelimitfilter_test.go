@@ -0,0 +1,58 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package msgprocessor
+
+import (
+	"testing"
+	"time"
+
+	cb "github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/utils"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func makeEnvelopeFrom(creator string) *cb.Envelope {
+	return &cb.Envelope{
+		Payload: utils.MarshalOrPanic(&cb.Payload{
+			Header: &cb.Header{
+				SignatureHeader: utils.MarshalOrPanic(&cb.SignatureHeader{Creator: []byte(creator)}),
+			},
+		}),
+	}
+}
+
+func TestRateLimitFilter(t *testing.T) {
+	now := time.Now()
+	rlf := NewRateLimitFilter(10, 2)
+	rlf.now = func() time.Time { return now }
+
+	t.Run("Burst", func(t *testing.T) {
+		assert.Nil(t, rlf.Apply(makeEnvelopeFrom("client1")))
+		assert.Nil(t, rlf.Apply(makeEnvelopeFrom("client1")))
+		err := rlf.Apply(makeEnvelopeFrom("client1"))
+		assert.NotNil(t, err)
+		assert.Equal(t, ErrRateLimited, errors.Cause(err))
+	})
+	t.Run("OtherClient", func(t *testing.T) {
+		assert.Nil(t, rlf.Apply(makeEnvelopeFrom("client2")))
+	})
+	t.Run("Refill", func(t *testing.T) {
+		now = now.Add(100 * time.Millisecond)
+		assert.Nil(t, rlf.Apply(makeEnvelopeFrom("client1")))
+		assert.NotNil(t, rlf.Apply(makeEnvelopeFrom("client1")))
+	})
+	t.Run("Sweep", func(t *testing.T) {
+		now = now.Add(rateLimitSweepInterval)
+		assert.Nil(t, rlf.Apply(makeEnvelopeFrom("client3")))
+		assert.Len(t, rlf.buckets, 1)
+	})
+	t.Run("EmptyPayload", func(t *testing.T) {
+		assert.NotNil(t, rlf.Apply(&cb.Envelope{}))
+	})
+}
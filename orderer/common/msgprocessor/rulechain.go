@@ -0,0 +1,196 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package msgprocessor
+
+import (
+	"fmt"
+	"plugin"
+	"reflect"
+	"sync"
+
+	channelconfig "github.com/hyperledger/fabric/common/config/channel"
+	"github.com/hyperledger/fabric/common/policies"
+	cb "github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/utils"
+)
+
+// RulePluginSymbol is the name of the function a rule plugin must export. The function
+// must be of type func(channelconfig.Resources) (msgprocessor.Rule, error)
+const RulePluginSymbol = "NewRule"
+
+// RuleFactory creates the rule named in the channel config for the channel with the given resources
+type RuleFactory func(resources channelconfig.Resources) (Rule, error)
+
+var ruleRegistry = struct {
+	lock      sync.RWMutex
+	factories map[string]RuleFactory
+}{
+	factories: map[string]RuleFactory{
+		"EmptyReject": func(channelconfig.Resources) (Rule, error) {
+			return EmptyRejectRule, nil
+		},
+		"MaxBytes": func(resources channelconfig.Resources) (Rule, error) {
+			return NewSizeFilter(resources), nil
+		},
+		"ChannelReaders": func(resources channelconfig.Resources) (Rule, error) {
+			return NewSigFilter(policies.ChannelReaders, resources.PolicyManager()), nil
+		},
+		"ChannelWriters": func(resources channelconfig.Resources) (Rule, error) {
+			return NewSigFilter(policies.ChannelWriters, resources.PolicyManager()), nil
+		},
+	},
+}
+
+// RegisterRule makes the rule created by the given factory available to the channel configs
+// under the given name. It returns an error if a rule is already registered under the name
+func RegisterRule(name string, factory RuleFactory) error {
+	ruleRegistry.lock.Lock()
+	defer ruleRegistry.lock.Unlock()
+	if _, ok := ruleRegistry.factories[name]; ok {
+		return fmt.Errorf("rule %s is already registered", name)
+	}
+	ruleRegistry.factories[name] = factory
+	return nil
+}
+
+// LoadRulePlugin opens the Go plugin at the given path and registers the rule created by
+// its RulePluginSymbol function under the given name
+func LoadRulePlugin(name, path string) error {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return fmt.Errorf("could not open rule plugin %s: %s", path, err)
+	}
+	symbol, err := p.Lookup(RulePluginSymbol)
+	if err != nil {
+		return fmt.Errorf("could not find %s in rule plugin %s: %s", RulePluginSymbol, path, err)
+	}
+	newRule, ok := symbol.(func(channelconfig.Resources) (Rule, error))
+	if !ok {
+		return fmt.Errorf("%s in rule plugin %s is of type %T, not a rule factory", RulePluginSymbol, path, symbol)
+	}
+	return RegisterRule(name, newRule)
+}
+
+func lookupRule(name string) (RuleFactory, bool) {
+	ruleRegistry.lock.RLock()
+	defer ruleRegistry.lock.RUnlock()
+	factory, ok := ruleRegistry.factories[name]
+	return factory, ok
+}
+
+// ConfiguredRules applies the rules named by the MessageFilterRules of the channel config, in order.
+// The rules are created again whenever a config update changes the names, and a message is rejected
+// if a name is not registered, so that a channel never accepts messages without the checks its
+// config asks for. Which rules are registered depends on the plugins loaded by each orderer, so the
+// configured rules are only applied when the orderer receives a message, and must not be part of the
+// filters of a channel, which the consenters apply again to the messages they order
+type ConfiguredRules struct {
+	resources channelconfig.Resources
+
+	lock  sync.Mutex
+	names []string
+	rules []Rule
+}
+
+// NewConfiguredRules creates a rule which applies the rules named in the config of the channel
+func NewConfiguredRules(resources channelconfig.Resources) *ConfiguredRules {
+	return &ConfiguredRules{resources: resources}
+}
+
+// Apply applies the configured rules to the message, returning the error of the first one rejecting it
+func (cr *ConfiguredRules) Apply(message *cb.Envelope) error {
+	rules, err := cr.currentRules()
+	if err != nil {
+		return err
+	}
+	for _, rule := range rules {
+		if err := rule.Apply(message); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (cr *ConfiguredRules) currentRules() ([]Rule, error) {
+	ordererConfig, ok := cr.resources.OrdererConfig()
+	if !ok {
+		return nil, fmt.Errorf("could not find the orderer config to load the message filter rules from")
+	}
+	names := ordererConfig.MessageFilterRules()
+
+	cr.lock.Lock()
+	defer cr.lock.Unlock()
+	if cr.rules != nil && reflect.DeepEqual(names, cr.names) {
+		return cr.rules, nil
+	}
+
+	rules := make([]Rule, 0, len(names))
+	for _, name := range names {
+		factory, ok := lookupRule(name)
+		if !ok {
+			return nil, fmt.Errorf("message filter rule %s is not registered with this orderer", name)
+		}
+		rule, err := factory(cr.resources)
+		if err != nil {
+			return nil, fmt.Errorf("could not create message filter rule %s: %s", name, err)
+		}
+		rules = append(rules, rule)
+	}
+	logger.Debugf("Applying message filter rules %v", names)
+	cr.names = names
+	cr.rules = rules
+	return rules, nil
+}
+
+// ChannelResources returns the resources of the channel a message is broadcast to, or false if the
+// orderer does not serve the channel
+type ChannelResources func(channelID string) (channelconfig.Resources, bool)
+
+// IngressConfiguredRules applies to each message the ConfiguredRules of the channel it is broadcast to.
+// A single IngressConfiguredRules is meant to be applied to the messages of all the channels when the
+// orderer receives them
+type IngressConfiguredRules struct {
+	resources ChannelResources
+
+	lock     sync.Mutex
+	channels map[string]*ConfiguredRules
+}
+
+// NewIngressConfiguredRules creates a rule which applies the rules named in the config of the channel
+// of each message, looking up the channels with the given function
+func NewIngressConfiguredRules(resources ChannelResources) *IngressConfiguredRules {
+	return &IngressConfiguredRules{
+		resources: resources,
+		channels:  make(map[string]*ConfiguredRules),
+	}
+}
+
+// Apply applies the configured rules of the channel of the message, returning the error of the first
+// one rejecting it. The messages to channels the orderer does not serve are left to the channel processors
+func (icr *IngressConfiguredRules) Apply(message *cb.Envelope) error {
+	chdr, err := utils.ChannelHeader(message)
+	if err != nil {
+		return fmt.Errorf("could not determine channel ID: %s", err)
+	}
+	resources, ok := icr.resources(chdr.ChannelId)
+	if !ok {
+		return nil
+	}
+	return icr.configuredRules(chdr.ChannelId, resources).Apply(message)
+}
+
+func (icr *IngressConfiguredRules) configuredRules(channelID string, resources channelconfig.Resources) *ConfiguredRules {
+	icr.lock.Lock()
+	defer icr.lock.Unlock()
+	// A channel which was removed and joined again has new resources
+	cr, ok := icr.channels[channelID]
+	if !ok || cr.resources != resources {
+		cr = NewConfiguredRules(resources)
+		icr.channels[channelID] = cr
+	}
+	return cr
+}
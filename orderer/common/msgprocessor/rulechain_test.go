@@ -0,0 +1,137 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package msgprocessor
+
+import (
+	"fmt"
+	"testing"
+
+	channelconfig "github.com/hyperledger/fabric/common/config/channel"
+	mockconfig "github.com/hyperledger/fabric/common/mocks/config"
+	cb "github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/utils"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mockRuleResources struct {
+	*mockconfig.Resources
+}
+
+func (m *mockRuleResources) OrdererConfig() (channelconfig.Orderer, bool) {
+	return m.OrdererConfigVal, m.OrdererConfigVal != nil
+}
+
+type countingRule struct {
+	applied int
+}
+
+func (r *countingRule) Apply(message *cb.Envelope) error {
+	r.applied++
+	return nil
+}
+
+func TestRegisterRule(t *testing.T) {
+	assert.Error(t, RegisterRule("EmptyReject", func(channelconfig.Resources) (Rule, error) { return AcceptRule, nil }))
+	assert.NoError(t, RegisterRule("TestRegisterRule", func(channelconfig.Resources) (Rule, error) { return AcceptRule, nil }))
+	assert.Error(t, RegisterRule("TestRegisterRule", func(channelconfig.Resources) (Rule, error) { return AcceptRule, nil }))
+}
+
+func TestLoadRulePlugin(t *testing.T) {
+	assert.Error(t, LoadRulePlugin("Missing", "/does/not/exist.so"))
+}
+
+func TestConfiguredRules(t *testing.T) {
+	counting := &countingRule{}
+	created := 0
+	assert.NoError(t, RegisterRule("TestConfiguredRulesCounting", func(channelconfig.Resources) (Rule, error) {
+		created++
+		return counting, nil
+	}))
+	assert.NoError(t, RegisterRule("TestConfiguredRulesBroken", func(channelconfig.Resources) (Rule, error) {
+		return nil, fmt.Errorf("broken")
+	}))
+
+	ordererConfig := &mockconfig.Orderer{}
+	cr := NewConfiguredRules(&mockRuleResources{&mockconfig.Resources{OrdererConfigVal: ordererConfig}})
+
+	t.Run("NoRules", func(t *testing.T) {
+		assert.NoError(t, cr.Apply(&cb.Envelope{}))
+	})
+
+	t.Run("BuiltIn", func(t *testing.T) {
+		ordererConfig.MessageFilterRulesVal = []string{"EmptyReject"}
+		assert.Equal(t, ErrEmptyMessage, cr.Apply(&cb.Envelope{}))
+		assert.NoError(t, cr.Apply(&cb.Envelope{Payload: []byte("payload")}))
+	})
+
+	t.Run("Registered", func(t *testing.T) {
+		ordererConfig.MessageFilterRulesVal = []string{"EmptyReject", "TestConfiguredRulesCounting"}
+		assert.Equal(t, ErrEmptyMessage, cr.Apply(&cb.Envelope{}))
+		assert.NoError(t, cr.Apply(&cb.Envelope{Payload: []byte("payload")}))
+		assert.NoError(t, cr.Apply(&cb.Envelope{Payload: []byte("payload")}))
+		assert.Equal(t, 2, counting.applied)
+		assert.Equal(t, 1, created, "The rules should only be created again when the config changes")
+	})
+
+	t.Run("Unregistered", func(t *testing.T) {
+		ordererConfig.MessageFilterRulesVal = []string{"TestConfiguredRulesUnregistered"}
+		assert.Error(t, cr.Apply(&cb.Envelope{Payload: []byte("payload")}))
+	})
+
+	t.Run("FactoryError", func(t *testing.T) {
+		ordererConfig.MessageFilterRulesVal = []string{"TestConfiguredRulesBroken"}
+		assert.Error(t, cr.Apply(&cb.Envelope{Payload: []byte("payload")}))
+	})
+
+	t.Run("NoOrdererConfig", func(t *testing.T) {
+		cr := NewConfiguredRules(&mockRuleResources{&mockconfig.Resources{}})
+		assert.Error(t, cr.Apply(&cb.Envelope{Payload: []byte("payload")}))
+	})
+}
+
+func TestIngressConfiguredRules(t *testing.T) {
+	ordererConfig := &mockconfig.Orderer{MessageFilterRulesVal: []string{"EmptyReject"}}
+	resources := map[string]channelconfig.Resources{
+		"mychannel": &mockRuleResources{&mockconfig.Resources{OrdererConfigVal: ordererConfig}},
+	}
+	icr := NewIngressConfiguredRules(func(channelID string) (channelconfig.Resources, bool) {
+		res, ok := resources[channelID]
+		return res, ok
+	})
+
+	makeEnvelope := func(channelID string, data []byte) *cb.Envelope {
+		return &cb.Envelope{Payload: utils.MarshalOrPanic(&cb.Payload{
+			Header: &cb.Header{ChannelHeader: utils.MarshalOrPanic(&cb.ChannelHeader{ChannelId: channelID})},
+			Data:   data,
+		})}
+	}
+
+	t.Run("ConfiguredChannel", func(t *testing.T) {
+		assert.NoError(t, icr.Apply(makeEnvelope("mychannel", []byte("data"))))
+		ordererConfig.MessageFilterRulesVal = []string{"TestIngressConfiguredRulesUnregistered"}
+		defer func() { ordererConfig.MessageFilterRulesVal = []string{"EmptyReject"} }()
+		assert.Error(t, icr.Apply(makeEnvelope("mychannel", []byte("data"))))
+	})
+
+	t.Run("UnknownChannel", func(t *testing.T) {
+		assert.NoError(t, icr.Apply(makeEnvelope("otherchannel", nil)))
+	})
+
+	t.Run("RejoinedChannel", func(t *testing.T) {
+		first := icr.configuredRules("mychannel", resources["mychannel"])
+		assert.Equal(t, first, icr.configuredRules("mychannel", resources["mychannel"]))
+		resources["mychannel"] = &mockRuleResources{&mockconfig.Resources{OrdererConfigVal: ordererConfig}}
+		assert.NoError(t, icr.Apply(makeEnvelope("mychannel", []byte("data"))))
+		assert.True(t, first != icr.configuredRules("mychannel", resources["mychannel"]),
+			"The rules of a channel should be created again when its resources change")
+	})
+
+	t.Run("BadChannelHeader", func(t *testing.T) {
+		assert.Error(t, icr.Apply(&cb.Envelope{Payload: []byte("garbage")}))
+	})
+}
@@ -8,6 +8,7 @@ package msgprocessor
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/hyperledger/fabric/common/policies"
 	cb "github.com/hyperledger/fabric/protos/common"
@@ -16,20 +17,22 @@ import (
 )
 
 type sigFilter struct {
-	policyName    string
+	policyNames   []string
 	policyManager policies.Manager
 }
 
 // NewSigFilter creates a new signature filter, at every evaluation, the policy manager is called
-// to retrieve the latest version of the policy
-func NewSigFilter(policyName string, policyManager policies.Manager) Rule {
+// to retrieve the latest version of the policy. The message is accepted if it satisfies the named
+// policy or any of the alternative policies, which are evaluated in order, so that messages keep
+// being accepted while a policy is being renamed
+func NewSigFilter(policyName string, policyManager policies.Manager, alternativePolicyNames ...string) Rule {
 	return &sigFilter{
-		policyName:    policyName,
+		policyNames:   append([]string{policyName}, alternativePolicyNames...),
 		policyManager: policyManager,
 	}
 }
 
-// Apply applies the policy given, resulting in Reject or Forward, never Accept
+// Apply applies the policies given, resulting in Reject or Forward, never Accept
 func (sf *sigFilter) Apply(message *cb.Envelope) error {
 	signedData, err := message.AsSignedData()
 
@@ -37,14 +40,25 @@ func (sf *sigFilter) Apply(message *cb.Envelope) error {
 		return fmt.Errorf("could not convert message to signedData: %s", err)
 	}
 
-	policy, ok := sf.policyManager.GetPolicy(sf.policyName)
-	if !ok {
-		return fmt.Errorf("could not find policy %s", sf.policyName)
+	var evaluationErrs []string
+	for _, policyName := range sf.policyNames {
+		policy, ok := sf.policyManager.GetPolicy(policyName)
+		if !ok {
+			logger.Debugf("Could not find policy %s", policyName)
+			continue
+		}
+
+		trace, err := policies.EvaluateWithTrace(policy, signedData)
+		if err == nil {
+			logger.Debugf("Message satisfied policy %s: %s", policyName, trace)
+			return nil
+		}
+		logger.Debugf("Message did not satisfy policy %s: %s", policyName, trace)
+		evaluationErrs = append(evaluationErrs, fmt.Sprintf("policy %s: %s, evaluation %s", policyName, err, trace))
 	}
 
-	err = policy.Evaluate(signedData)
-	if err != nil {
-		return errors.Wrap(errors.WithStack(ErrPermissionDenied), err.Error())
+	if len(evaluationErrs) == 0 {
+		return fmt.Errorf("could not find policy %s", strings.Join(sf.policyNames, " or "))
 	}
-	return nil
+	return errors.Wrap(errors.WithStack(ErrPermissionDenied), strings.Join(evaluationErrs, "; "))
 }
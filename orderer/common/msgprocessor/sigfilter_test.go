@@ -11,6 +11,7 @@ import (
 	"testing"
 
 	mockpolicies "github.com/hyperledger/fabric/common/mocks/policies"
+	"github.com/hyperledger/fabric/common/policies"
 	cb "github.com/hyperledger/fabric/protos/common"
 	"github.com/hyperledger/fabric/protos/utils"
 
@@ -58,3 +59,34 @@ func TestErrorOnPolicy(t *testing.T) {
 	assert.NotNil(t, err)
 	assert.Equal(t, ErrPermissionDenied, errors.Cause(err))
 }
+
+func TestAlternativePolicies(t *testing.T) {
+	mpm := &mockpolicies.Manager{
+		PolicyMap: map[string]policies.Policy{
+			"reject": &mockpolicies.Policy{Err: fmt.Errorf("Error")},
+			"accept": &mockpolicies.Policy{},
+		},
+	}
+
+	t.Run("AlternativeSatisfied", func(t *testing.T) {
+		assert.Nil(t, NewSigFilter("reject", mpm, "accept").Apply(makeEnvelope()))
+	})
+
+	t.Run("MissingPolicySkipped", func(t *testing.T) {
+		assert.Nil(t, NewSigFilter("missing", mpm, "accept").Apply(makeEnvelope()))
+	})
+
+	t.Run("NoneSatisfied", func(t *testing.T) {
+		err := NewSigFilter("reject", mpm, "missing").Apply(makeEnvelope())
+		assert.NotNil(t, err)
+		assert.Equal(t, ErrPermissionDenied, errors.Cause(err))
+		assert.Regexp(t, "policy reject", err.Error())
+		assert.Regexp(t, `evaluation policy: not satisfied \[Error\]`, err.Error())
+	})
+
+	t.Run("NoneFound", func(t *testing.T) {
+		err := NewSigFilter("missing", mpm, "alsomissing").Apply(makeEnvelope())
+		assert.NotNil(t, err)
+		assert.Regexp(t, "could not find policy missing or alsomissing", err.Error())
+	})
+}
@@ -7,18 +7,21 @@ SPDX-License-Identifier: Apache-2.0
 package msgprocessor
 
 import (
+	"errors"
 	"fmt"
 
+	channelconfig "github.com/hyperledger/fabric/common/config/channel"
 	cb "github.com/hyperledger/fabric/protos/common"
-	ab "github.com/hyperledger/fabric/protos/orderer"
 )
 
-// Support defines the subset of the channel support required to create this filter
+// Support defines the subset of the channel resources required to create this filter
 type Support interface {
-	BatchSize() *ab.BatchSize
+	OrdererConfig() (channelconfig.Orderer, bool)
 }
 
-// New creates a size filter which rejects messages larger than maxBytes
+// NewSizeFilter creates a size filter which rejects messages larger than the absolute max bytes
+// of the batch size of the channel. The limit is read from the channel config on each Apply, so
+// that a config update changing the batch size of the channel takes effect on the next message
 func NewSizeFilter(support Support) *MaxBytesRule {
 	return &MaxBytesRule{support: support}
 }
@@ -30,7 +33,11 @@ type MaxBytesRule struct {
 
 // Apply returns an error if the message exceeds the configured absolute max batch size.
 func (r *MaxBytesRule) Apply(message *cb.Envelope) error {
-	maxBytes := r.support.BatchSize().AbsoluteMaxBytes
+	ordererConf, ok := r.support.OrdererConfig()
+	if !ok {
+		return errors.New("channel has no orderer config, cannot determine the maximum allowed message size")
+	}
+	maxBytes := ordererConf.BatchSize().AbsoluteMaxBytes
 	if size := messageByteSize(message); size > maxBytes {
 		return fmt.Errorf("message payload is %d bytes and exceeds maximum allowed %d bytes", size, maxBytes)
 	}
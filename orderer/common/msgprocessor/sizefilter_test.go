@@ -10,6 +10,7 @@ import (
 	"testing"
 
 	"github.com/golang/protobuf/proto"
+	channelconfig "github.com/hyperledger/fabric/common/config/channel"
 	mockconfig "github.com/hyperledger/fabric/common/mocks/config"
 	cb "github.com/hyperledger/fabric/protos/common"
 	ab "github.com/hyperledger/fabric/protos/orderer"
@@ -20,7 +21,8 @@ import (
 func TestMaxBytesRule(t *testing.T) {
 	dataSize := uint32(100)
 	maxBytes := calcMessageBytesForPayloadDataSize(dataSize)
-	msf := NewSizeFilter(&mockconfig.Orderer{BatchSizeVal: &ab.BatchSize{AbsoluteMaxBytes: maxBytes}})
+	ordererConfig := &mockconfig.Orderer{BatchSizeVal: &ab.BatchSize{AbsoluteMaxBytes: maxBytes}}
+	msf := NewSizeFilter(&mockSizeFilterSupport{ordererConfig: ordererConfig})
 
 	t.Run("LessThan", func(t *testing.T) {
 		assert.Nil(t, msf.Apply(makeMessage(make([]byte, dataSize-1))))
@@ -31,6 +33,25 @@ func TestMaxBytesRule(t *testing.T) {
 	t.Run("TooBig", func(t *testing.T) {
 		assert.NotNil(t, msf.Apply(makeMessage(make([]byte, dataSize+1))))
 	})
+	t.Run("ConfigUpdate", func(t *testing.T) {
+		ordererConfig.BatchSizeVal = &ab.BatchSize{AbsoluteMaxBytes: maxBytes + 1}
+		defer func() { ordererConfig.BatchSizeVal = &ab.BatchSize{AbsoluteMaxBytes: maxBytes} }()
+		assert.Nil(t, msf.Apply(makeMessage(make([]byte, dataSize+1))))
+	})
+	t.Run("NoOrdererConfig", func(t *testing.T) {
+		assert.NotNil(t, NewSizeFilter(&mockSizeFilterSupport{}).Apply(makeMessage(make([]byte, dataSize))))
+	})
+}
+
+type mockSizeFilterSupport struct {
+	ordererConfig *mockconfig.Orderer
+}
+
+func (m *mockSizeFilterSupport) OrdererConfig() (channelconfig.Orderer, bool) {
+	if m.ordererConfig == nil {
+		return nil, false
+	}
+	return m.ordererConfig, true
 }
 
 func calcMessageBytesForPayloadDataSize(dataSize uint32) uint32 {
@@ -46,14 +46,15 @@ func NewStandardChannel(support StandardChannelSupport, filters *RuleSet) *Stand
 
 // CreateStandardChannelFilters creates the set of filters for a normal (non-system) chain
 func CreateStandardChannelFilters(filterSupport channelconfig.Resources) *RuleSet {
-	ordererConfig, ok := filterSupport.OrdererConfig()
-	if !ok {
+	if _, ok := filterSupport.OrdererConfig(); !ok {
 		logger.Panicf("Missing orderer config")
 	}
 	return NewRuleSet([]Rule{
 		EmptyRejectRule,
-		NewSizeFilter(ordererConfig),
+		NewExpirationRejectRule(filterSupport),
+		NewSizeFilter(filterSupport),
 		NewSigFilter(policies.ChannelWriters, filterSupport.PolicyManager()),
+		NewMaintenanceFilter(filterSupport),
 	})
 }
 
@@ -119,3 +120,14 @@ func (s *StandardChannel) ProcessConfigUpdateMsg(env *cb.Envelope) (config *cb.E
 
 	return config, seq, nil
 }
+
+// SimulateConfigUpdateMsg validates the config update msg exactly as ProcessConfigUpdateMsg does, including the
+// policy checks, and returns the config which would result from it, without anything being ordered.
+func (s *StandardChannel) SimulateConfigUpdateMsg(env *cb.Envelope) (*cb.ConfigEnvelope, error) {
+	config, _, err := s.ProcessConfigUpdateMsg(env)
+	if err != nil {
+		return nil, err
+	}
+
+	return resultingConfig(config)
+}
@@ -12,6 +12,7 @@ import (
 
 	"github.com/hyperledger/fabric/common/crypto"
 	cb "github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/utils"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -102,3 +103,36 @@ func TestConfigUpdateMsg(t *testing.T) {
 		assert.Nil(t, err)
 	})
 }
+
+func TestSimulateConfigUpdateMsg(t *testing.T) {
+	t.Run("BadMsg", func(t *testing.T) {
+		ms := &mockSystemChannelFilterSupport{
+			ProposeConfigUpdateErr: fmt.Errorf("An error"),
+		}
+		config, err := NewStandardChannel(ms, NewRuleSet([]Rule{AcceptRule})).SimulateConfigUpdateMsg(&cb.Envelope{})
+		assert.Nil(t, config)
+		assert.Equal(t, ms.ProposeConfigUpdateErr, err)
+	})
+	t.Run("Success", func(t *testing.T) {
+		ms := &mockSystemChannelFilterSupport{
+			SequenceVal:            7,
+			ProposeConfigUpdateVal: &cb.ConfigEnvelope{Config: &cb.Config{Sequence: 8}},
+		}
+		config, err := NewStandardChannel(ms, NewRuleSet([]Rule{AcceptRule})).SimulateConfigUpdateMsg(&cb.Envelope{})
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(8), config.Config.Sequence)
+	})
+}
+
+func TestResultingConfig(t *testing.T) {
+	_, err := resultingConfig(&cb.Envelope{Payload: []byte("garbage")})
+	assert.Error(t, err)
+
+	_, err = resultingConfig(&cb.Envelope{Payload: utils.MarshalOrPanic(&cb.Payload{})})
+	assert.EqualError(t, err, "config message has no header")
+
+	_, err = resultingConfig(&cb.Envelope{Payload: utils.MarshalOrPanic(&cb.Payload{
+		Header: &cb.Header{ChannelHeader: utils.MarshalOrPanic(&cb.ChannelHeader{Type: int32(cb.HeaderType_ENDORSER_TRANSACTION)})},
+	})})
+	assert.EqualError(t, err, "unexpected config message type 3")
+}
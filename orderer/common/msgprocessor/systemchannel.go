@@ -43,14 +43,15 @@ func NewSystemChannel(support StandardChannelSupport, templator ChannelConfigTem
 
 // CreateSystemChannelFilters creates the set of filters for the ordering system chain.
 func CreateSystemChannelFilters(chainCreator ChainCreator, ledgerResources channelconfig.Resources) *RuleSet {
-	ordererConfig, ok := ledgerResources.OrdererConfig()
-	if !ok {
+	if _, ok := ledgerResources.OrdererConfig(); !ok {
 		logger.Panicf("Cannot create system channel filters without orderer config")
 	}
 	return NewRuleSet([]Rule{
 		EmptyRejectRule,
-		NewSizeFilter(ordererConfig),
+		NewExpirationRejectRule(ledgerResources),
+		NewSizeFilter(ledgerResources),
 		NewSigFilter(policies.ChannelWriters, ledgerResources.PolicyManager()),
+		NewMaintenanceFilter(ledgerResources),
 		NewSystemChannelFilter(ledgerResources, chainCreator),
 	})
 }
@@ -128,6 +129,17 @@ func (s *SystemChannel) ProcessConfigUpdateMsg(envConfigUpdate *cb.Envelope) (co
 	return wrappedOrdererTransaction, s.support.Sequence(), nil
 }
 
+// SimulateConfigUpdateMsg validates the CONFIG_UPDATE exactly as ProcessConfigUpdateMsg does, and returns the
+// config which would result from it.  In the channel creation case, this is the genesis config of the new channel.
+func (s *SystemChannel) SimulateConfigUpdateMsg(envConfigUpdate *cb.Envelope) (*cb.ConfigEnvelope, error) {
+	config, _, err := s.ProcessConfigUpdateMsg(envConfigUpdate)
+	if err != nil {
+		return nil, err
+	}
+
+	return resultingConfig(config)
+}
+
 // DefaultTemplatorSupport is the subset of the channel config required by the DefaultTemplator.
 type DefaultTemplatorSupport interface {
 	// ConsortiumsConfig returns the ordering system channel's Consortiums config.
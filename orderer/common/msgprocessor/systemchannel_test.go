@@ -202,6 +202,65 @@ func TestSystemChannelConfigUpdateMsg(t *testing.T) {
 	})
 }
 
+func TestSystemChannelSimulateConfigUpdateMsg(t *testing.T) {
+	t.Run("NormalUpdate", func(t *testing.T) {
+		mscs := &mockSystemChannelSupport{}
+		ms := &mockSystemChannelFilterSupport{
+			ProposeConfigUpdateVal: &cb.ConfigEnvelope{Config: &cb.Config{Sequence: 3}},
+		}
+		config, err := NewSystemChannel(ms, mscs, NewRuleSet([]Rule{AcceptRule})).SimulateConfigUpdateMsg(&cb.Envelope{
+			Payload: utils.MarshalOrPanic(&cb.Payload{
+				Header: &cb.Header{
+					ChannelHeader: utils.MarshalOrPanic(&cb.ChannelHeader{
+						ChannelId: testChannelID,
+					}),
+				},
+			}),
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(3), config.Config.Sequence)
+	})
+	t.Run("ChannelCreation", func(t *testing.T) {
+		mscs := &mockSystemChannelSupport{
+			NewChannelConfigVal: &mockconfigtx.Manager{
+				ProposeConfigUpdateVal: &cb.ConfigEnvelope{Config: &cb.Config{Sequence: 1}},
+			},
+		}
+		ms := &mockSystemChannelFilterSupport{
+			ProposeConfigUpdateVal: &cb.ConfigEnvelope{},
+		}
+		config, err := NewSystemChannel(ms, mscs, NewRuleSet([]Rule{AcceptRule})).SimulateConfigUpdateMsg(&cb.Envelope{
+			Payload: utils.MarshalOrPanic(&cb.Payload{
+				Header: &cb.Header{
+					ChannelHeader: utils.MarshalOrPanic(&cb.ChannelHeader{
+						ChannelId: testChannelID + "different",
+					}),
+				},
+			}),
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(1), config.Config.Sequence)
+	})
+	t.Run("BadByFilter", func(t *testing.T) {
+		mscs := &mockSystemChannelSupport{
+			NewChannelConfigVal: &mockconfigtx.Manager{
+				ProposeConfigUpdateVal: &cb.ConfigEnvelope{},
+			},
+		}
+		ms := &mockSystemChannelFilterSupport{}
+		_, err := NewSystemChannel(ms, mscs, NewRuleSet([]Rule{RejectRule})).SimulateConfigUpdateMsg(&cb.Envelope{
+			Payload: utils.MarshalOrPanic(&cb.Payload{
+				Header: &cb.Header{
+					ChannelHeader: utils.MarshalOrPanic(&cb.ChannelHeader{
+						ChannelId: testChannelID + "different",
+					}),
+				},
+			}),
+		})
+		assert.Equal(t, RejectRule.Apply(nil), err)
+	})
+}
+
 type mockDefaultTemplatorSupport struct {
 	channelconfig.Resources
 }
@@ -24,6 +24,10 @@ type ChainCreator interface {
 
 	// ChannelsCount returns the count of channels which currently exist.
 	ChannelsCount() int
+
+	// MaxChannels returns the maximum number of channels, including the system channel,
+	// which this orderer serves according to its local configuration, or 0 if unlimited.
+	MaxChannels() int
 }
 
 // LimitedSupport defines the subset of the channel resources required by the systemchannel filter.
@@ -80,6 +84,10 @@ func (scf *SystemChainFilter) Apply(env *cb.Envelope) error {
 		}
 	}
 
+	if localMaxChannels := scf.cc.MaxChannels(); localMaxChannels > 0 && scf.cc.ChannelsCount() >= localMaxChannels {
+		return fmt.Errorf("channel creation would exceed maximum number of channels of this orderer: %d", localMaxChannels)
+	}
+
 	configTx := &cb.Envelope{}
 	err = proto.Unmarshal(msgData.Data, configTx)
 	if err != nil {
@@ -68,6 +68,7 @@ type mockChainCreator struct {
 	ms                  *mockSupport
 	newChains           []*cb.Envelope
 	NewChannelConfigErr error
+	maxChannels         int
 }
 
 func newMockChainCreator() *mockChainCreator {
@@ -85,6 +86,10 @@ func (mcc *mockChainCreator) ChannelsCount() int {
 	return len(mcc.newChains)
 }
 
+func (mcc *mockChainCreator) MaxChannels() int {
+	return mcc.maxChannels
+}
+
 func (mcc *mockChainCreator) NewChannelConfig(envConfigUpdate *cb.Envelope) (configtxapi.Manager, error) {
 	if mcc.NewChannelConfigErr != nil {
 		return nil, mcc.NewChannelConfigErr
@@ -173,6 +178,35 @@ func TestNumChainsExceeded(t *testing.T) {
 	assert.Regexp(t, "exceed maximimum number", err)
 }
 
+func TestLocalMaxChannelsExceeded(t *testing.T) {
+	newChainID := "newchannel"
+
+	mcc := newMockChainCreator()
+	mcc.maxChannels = 2
+	mcc.newChains = make([]*cb.Envelope, 1)
+
+	configEnv, err := configtx.NewCompositeTemplate(
+		configtx.NewSimpleTemplate(
+			channelconfig.DefaultHashingAlgorithm(),
+			channelconfig.DefaultBlockDataHashingStructure(),
+			channelconfig.TemplateOrdererAddresses([]string{"foo"}),
+		),
+		channelconfig.NewChainCreationTemplate("SampleConsortium", []string{}),
+	).Envelope(newChainID)
+	if err != nil {
+		t.Fatalf("Error constructing configtx")
+	}
+	wrapped := wrapConfigTx(makeConfigTxFromConfigUpdateEnvelope(newChainID, configEnv))
+
+	err = NewSystemChannelFilter(mcc.ms, mcc).Apply(wrapped)
+	assert.Nil(t, err, "Transaction should be within the limit of the orderer")
+
+	mcc.newChains = make([]*cb.Envelope, 2)
+	err = NewSystemChannelFilter(mcc.ms, mcc).Apply(wrapped)
+	assert.NotNil(t, err, "Transaction had created too many channels")
+	assert.Regexp(t, "exceed maximum number of channels of this orderer: 2", err)
+}
+
 func TestBadProposal(t *testing.T) {
 	mcc := newMockChainCreator()
 	sysFilter := NewSystemChannelFilter(mcc.ms, mcc)
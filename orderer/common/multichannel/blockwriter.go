@@ -9,6 +9,7 @@ package multichannel
 import (
 	"sync"
 
+	channelconfig "github.com/hyperledger/fabric/common/config/channel"
 	"github.com/hyperledger/fabric/common/configtx"
 	configtxapi "github.com/hyperledger/fabric/common/configtx/api"
 	"github.com/hyperledger/fabric/common/crypto"
@@ -24,6 +25,7 @@ type blockWriterSupport interface {
 	crypto.LocalSigner
 	ledger.ReadWriter
 	configtxapi.Manager
+	ChannelConfig() channelconfig.Channel
 }
 
 // BlockWriter efficiently writes the blockchain to disk.
@@ -78,7 +80,7 @@ func (bw *BlockWriter) CreateNextBlock(messages []*cb.Envelope) *cb.Block {
 	}
 
 	block := cb.NewBlock(bw.lastBlock.Header.Number+1, previousBlockHash)
-	block.Header.DataHash = data.Hash()
+	block.Header.DataHash = data.HeaderDataHash(bw.support.ChannelConfig().Capabilities().MerkleDataHash())
 	block.Data = data
 
 	return block
@@ -120,10 +122,21 @@ func (bw *BlockWriter) WriteConfigBlock(block *cb.Block, encodedMetadataValue []
 			logger.Panicf("Told to write a config block with new channel, but did not have config envelope encoded: %s", err)
 		}
 
+		previousType := consensusType(bw.support.ConfigEnvelope())
+
 		err = bw.support.Apply(configEnvelope)
 		if err != nil {
 			logger.Panicf("Told to write a config block with new config, but could not apply it: %s", err)
 		}
+
+		if nextType := consensusType(configEnvelope); previousType != "" && nextType != previousType {
+			logger.Infof("[channel: %s] Consensus type migrates from %s to %s at block %d", bw.support.ChainID(), previousType, nextType, block.Header.Number)
+			// The metadata of the previous consenter is meaningless to the next one, which
+			// starts from the consensus metadata of the config instead
+			bw.WriteBlock(block, nil)
+			bw.registrar.switchConsenter(bw.support.ChainID())
+			return
+		}
 	default:
 		logger.Panicf("Told to write a config block with unknown header type: %v", chdr.Type)
 	}
@@ -131,6 +144,15 @@ func (bw *BlockWriter) WriteConfigBlock(block *cb.Block, encodedMetadataValue []
 	bw.WriteBlock(block, encodedMetadataValue)
 }
 
+// consensusType returns the consensus type of the config envelope, or an empty string if it has none
+func consensusType(configEnvelope *cb.ConfigEnvelope) string {
+	ct, err := channelconfig.ConsensusTypeOf(configEnvelope.GetConfig())
+	if err != nil {
+		logger.Panicf("Config has an invalid consensus type: %s", err)
+	}
+	return ct.GetType()
+}
+
 // WriteBlock should be invoked for blocks which contain normal transactions.
 // It sets the target block as the pending next block, and returns before it is committed.
 // Before returning, it acquires the committing lock, and spawns a go routine which will
@@ -19,7 +19,9 @@ package multichannel
 import (
 	"testing"
 
+	channelconfig "github.com/hyperledger/fabric/common/config/channel"
 	"github.com/hyperledger/fabric/common/crypto"
+	mockconfig "github.com/hyperledger/fabric/common/mocks/config"
 	mockconfigtx "github.com/hyperledger/fabric/common/mocks/configtx"
 	"github.com/hyperledger/fabric/common/tools/configtxgen/provisional"
 	"github.com/hyperledger/fabric/orderer/common/ledger"
@@ -32,13 +34,21 @@ type mockBlockWriterSupport struct {
 	*mockconfigtx.Manager
 	crypto.LocalSigner
 	ledger.ReadWriter
+	ChannelConfigVal *mockconfig.Channel
+}
+
+func (mbws *mockBlockWriterSupport) ChannelConfig() channelconfig.Channel {
+	if mbws.ChannelConfigVal == nil {
+		return &mockconfig.Channel{}
+	}
+	return mbws.ChannelConfigVal
 }
 
 func TestCreateBlock(t *testing.T) {
 	seedBlock := cb.NewBlock(7, []byte("lasthash"))
 	seedBlock.Data.Data = [][]byte{[]byte("somebytes")}
 
-	bw := &BlockWriter{lastBlock: seedBlock}
+	bw := &BlockWriter{support: &mockBlockWriterSupport{}, lastBlock: seedBlock}
 	block := bw.CreateNextBlock([]*cb.Envelope{
 		&cb.Envelope{Payload: []byte("some other bytes")},
 	})
@@ -48,6 +58,27 @@ func TestCreateBlock(t *testing.T) {
 	assert.Equal(t, seedBlock.Header.Hash(), block.Header.PreviousHash)
 }
 
+func TestCreateBlockMerkleDataHash(t *testing.T) {
+	seedBlock := cb.NewBlock(7, []byte("lasthash"))
+
+	bw := &BlockWriter{
+		support: &mockBlockWriterSupport{
+			ChannelConfigVal: &mockconfig.Channel{
+				CapabilitiesVal: &mockconfig.ChannelCapabilities{MerkleDataHashVal: true},
+			},
+		},
+		lastBlock: seedBlock,
+	}
+	block := bw.CreateNextBlock([]*cb.Envelope{
+		&cb.Envelope{Payload: []byte("some bytes")},
+		&cb.Envelope{Payload: []byte("some other bytes")},
+	})
+
+	assert.Equal(t, block.Data.MerkleRoot(), block.Header.DataHash)
+	assert.NotEqual(t, block.Data.Hash(), block.Header.DataHash)
+	assert.True(t, block.Data.MatchesDataHash(block.Header.DataHash))
+}
+
 func TestBlockSignature(t *testing.T) {
 	bw := &BlockWriter{
 		support: &mockBlockWriterSupport{
@@ -9,6 +9,7 @@ package multichannel
 import (
 	configtxapi "github.com/hyperledger/fabric/common/configtx/api"
 	"github.com/hyperledger/fabric/common/crypto"
+	"github.com/hyperledger/fabric/common/metrics"
 	"github.com/hyperledger/fabric/orderer/common/blockcutter"
 	"github.com/hyperledger/fabric/orderer/common/ledger"
 	"github.com/hyperledger/fabric/orderer/common/msgprocessor"
@@ -44,11 +45,15 @@ func newChainSupport(
 		logger.Fatalf("[channel: %s] Error extracting orderer metadata: %s", ledgerResources.ConfigtxManager().ChainID(), err)
 	}
 
+	// Set up the block cutter with the cutting policy of the orderer
+	sharedConfig := ledgerResources.SharedConfig()
+	cutterScope := metrics.NewRootScope().SubScope("blockcutter").Tagged(map[string]string{"channel": ledgerResources.ConfigtxManager().ChainID()})
+
 	// Construct limited support needed as a parameter for additional support
 	cs := &ChainSupport{
 		ledgerResources: ledgerResources,
 		LocalSigner:     signer,
-		cutter:          blockcutter.NewReceiverImpl(ledgerResources.SharedConfig()),
+		cutter:          blockcutter.NewReceiver(sharedConfig, registrar.cuttingPolicy(sharedConfig), cutterScope),
 		Manager:         ledgerResources.ConfigtxManager(),
 	}
 
@@ -88,6 +93,11 @@ func (cs *ChainSupport) start() {
 	cs.Chain.Start()
 }
 
+// Block returns the block with the given number, or nil if the ledger does not hold it.
+func (cs *ChainSupport) Block(number uint64) *cb.Block {
+	return ledger.GetBlock(cs.Reader(), number)
+}
+
 // BlockCutter returns the blockcutter.Receiver instance for this channel.
 func (cs *ChainSupport) BlockCutter() blockcutter.Receiver {
 	return cs.cutter
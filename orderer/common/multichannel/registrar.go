@@ -10,10 +10,14 @@ SPDX-License-Identifier: Apache-2.0
 package multichannel
 
 import (
+	"errors"
 	"fmt"
+	"sort"
+	"sync"
 
 	channelconfig "github.com/hyperledger/fabric/common/config/channel"
 	configtxapi "github.com/hyperledger/fabric/common/configtx/api"
+	"github.com/hyperledger/fabric/orderer/common/blockcutter"
 	"github.com/hyperledger/fabric/orderer/common/ledger"
 	"github.com/hyperledger/fabric/orderer/common/msgprocessor"
 	"github.com/hyperledger/fabric/orderer/consensus"
@@ -21,6 +25,7 @@ import (
 	ab "github.com/hyperledger/fabric/protos/orderer"
 	"github.com/hyperledger/fabric/protos/utils"
 	"github.com/op/go-logging"
+	"golang.org/x/net/context"
 
 	"github.com/hyperledger/fabric/common/crypto"
 )
@@ -32,6 +37,52 @@ const (
 	epoch      = 0
 )
 
+var (
+	// ErrChannelAlreadyExists is returned when joining a channel the orderer already serves
+	ErrChannelAlreadyExists = errors.New("channel already exists")
+	// ErrChannelNotExist is returned when removing a channel the orderer does not serve
+	ErrChannelNotExist = errors.New("channel does not exist")
+	// ErrSystemChannelExists is returned when joining or removing a channel on an orderer
+	// with a system channel, whose channels are created through the system channel
+	ErrSystemChannelExists = errors.New("system channel exists")
+	// ErrTooManyChannels is returned when joining a channel would exceed the maximum number
+	// of channels of the orderer
+	ErrTooManyChannels = errors.New("maximum number of channels reached")
+)
+
+// ChainReplicator pulls the blocks of the channel of a config block into the ledger of the
+// channel, up to and including that block
+type ChainReplicator func(configBlock *cb.Block) error
+
+// HealthCheck returns an error naming the channels whose consenters report an error, for the
+// operations server. The consenter of a channel reports an error by closing its Errored channel,
+// for instance while the Kafka based consenter is disconnected from the Kafka cluster
+func (r *Registrar) HealthCheck(ctx context.Context) error {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	var errored []string
+	for chainID, cs := range r.chains {
+		select {
+		case <-cs.Errored():
+			errored = append(errored, chainID)
+		default:
+		}
+	}
+	if len(errored) == 0 {
+		return nil
+	}
+	sort.Strings(errored)
+	return fmt.Errorf("consenters of channels %v report an error", errored)
+}
+
+// ChannelInfo describes a channel the orderer serves
+type ChannelInfo struct {
+	Name          string
+	Height        uint64
+	ConsensusType string
+	SystemChannel bool
+}
+
 type configResources struct {
 	channelconfig.Resources
 }
@@ -51,6 +102,8 @@ type ledgerResources struct {
 
 // Registrar serves as a point of access and control for the individual channel resources.
 type Registrar struct {
+	// lock guards the replacement of the chains map
+	lock            sync.RWMutex
 	chains          map[string]*ChainSupport
 	consenters      map[string]consensus.Consenter
 	ledgerFactory   ledger.Factory
@@ -58,6 +111,14 @@ type Registrar struct {
 	systemChannelID string
 	systemChannel   *ChainSupport
 	templator       msgprocessor.ChannelConfigTemplator
+	// cuttingPolicy creates the policy with which the messages of each channel are cut into batches
+	cuttingPolicy blockcutter.PolicyFactory
+	// replicate pulls the blocks preceding the config block a channel is joined with
+	replicate ChainReplicator
+	// participationLock serializes the joining and removal of channels
+	participationLock sync.Mutex
+	// maxChannels is the maximum number of channels served, 0 if unlimited
+	maxChannels int
 }
 
 func getConfigTx(reader ledger.Reader) *cb.Envelope {
@@ -74,13 +135,37 @@ func getConfigTx(reader ledger.Reader) *cb.Envelope {
 	return utils.ExtractEnvelopeOrPanic(configBlock, 0)
 }
 
-// NewRegistrar produces an instance of a *Registrar.
-func NewRegistrar(ledgerFactory ledger.Factory, consenters map[string]consensus.Consenter, signer crypto.LocalSigner) *Registrar {
+// NewRegistrar produces an instance of a *Registrar. The messages of every channel are cut
+// into batches with the policy the given factory creates.
+func NewRegistrar(ledgerFactory ledger.Factory, consenters map[string]consensus.Consenter, signer crypto.LocalSigner, cuttingPolicy blockcutter.PolicyFactory) *Registrar {
+	r := newRegistrar(ledgerFactory, consenters, signer, cuttingPolicy, nil)
+	if r.systemChannelID == "" {
+		logger.Panicf("No system chain found.  If bootstrapping, does your system channel contain a consortiums group definition?")
+	}
+	return r
+}
+
+// NewParticipationRegistrar produces an instance of a *Registrar whose channels are managed
+// through the channel participation API. Unlike with NewRegistrar, the orderer may have no
+// system channel, in which case its channels are joined and removed one at a time. The
+// replicate function pulls the blocks preceding the config block a channel is joined with,
+// unless it is the genesis block of the channel.
+func NewParticipationRegistrar(ledgerFactory ledger.Factory, consenters map[string]consensus.Consenter, signer crypto.LocalSigner, cuttingPolicy blockcutter.PolicyFactory, replicate ChainReplicator) *Registrar {
+	r := newRegistrar(ledgerFactory, consenters, signer, cuttingPolicy, replicate)
+	if r.systemChannelID == "" {
+		logger.Infof("No system chain found, channels are joined through the channel participation API")
+	}
+	return r
+}
+
+func newRegistrar(ledgerFactory ledger.Factory, consenters map[string]consensus.Consenter, signer crypto.LocalSigner, cuttingPolicy blockcutter.PolicyFactory, replicate ChainReplicator) *Registrar {
 	r := &Registrar{
 		chains:        make(map[string]*ChainSupport),
 		ledgerFactory: ledgerFactory,
 		consenters:    consenters,
 		signer:        signer,
+		cuttingPolicy: cuttingPolicy,
+		replicate:     replicate,
 	}
 
 	existingChains := ledgerFactory.ChainIDs()
@@ -104,7 +189,7 @@ func NewRegistrar(ledgerFactory ledger.Factory, consenters map[string]consensus.
 				r,
 				ledgerResources,
 				consenters,
-				signer)
+				r.signerFor(chainID))
 			r.templator = msgprocessor.NewDefaultTemplator(chain)
 			chain.Processor = msgprocessor.NewSystemChannel(chain, r.templator, msgprocessor.CreateSystemChannelFilters(r, chain))
 
@@ -131,17 +216,13 @@ func NewRegistrar(ledgerFactory ledger.Factory, consenters map[string]consensus.
 				r,
 				ledgerResources,
 				consenters,
-				signer)
+				r.signerFor(chainID))
 			r.chains[chainID] = chain
 			chain.start()
 		}
 
 	}
 
-	if r.systemChannelID == "" {
-		logger.Panicf("No system chain found.  If bootstrapping, does your system channel contain a consortiums group definition?")
-	}
-
 	return r
 }
 
@@ -159,9 +240,15 @@ func (r *Registrar) BroadcastChannelSupport(msg *cb.Envelope) (*cb.ChannelHeader
 		return nil, false, nil, fmt.Errorf("could not determine channel ID: %s", err)
 	}
 
+	r.lock.RLock()
 	cs, ok := r.chains[chdr.ChannelId]
+	systemChannel := r.systemChannel
+	r.lock.RUnlock()
 	if !ok {
-		cs = r.systemChannel
+		if systemChannel == nil {
+			return nil, false, nil, fmt.Errorf("channel %s does not exist", chdr.ChannelId)
+		}
+		cs = systemChannel
 	}
 
 	class, err := cs.ClassifyMsg(chdr)
@@ -179,8 +266,26 @@ func (r *Registrar) BroadcastChannelSupport(msg *cb.Envelope) (*cb.ChannelHeader
 	return chdr, isConfig, cs, nil
 }
 
+// SimulateConfigUpdate validates a CONFIG_UPDATE message, against the config of its channel or, for a
+// channel creation, of the system channel, and returns the config which would result from it, without
+// ordering it
+func (r *Registrar) SimulateConfigUpdate(configUpdate *cb.Envelope) (*cb.ConfigEnvelope, error) {
+	chdr, _, cs, err := r.BroadcastChannelSupport(configUpdate)
+	if err != nil {
+		return nil, err
+	}
+
+	if chdr.Type != int32(cb.HeaderType_CONFIG_UPDATE) {
+		return nil, fmt.Errorf("message of type %s is not a config update", cb.HeaderType(chdr.Type))
+	}
+
+	return cs.SimulateConfigUpdateMsg(configUpdate)
+}
+
 // GetChain retrieves the chain support for a chain (and whether it exists)
 func (r *Registrar) GetChain(chainID string) (*ChainSupport, bool) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
 	cs, ok := r.chains[chainID]
 	return cs, ok
 }
@@ -208,28 +313,249 @@ func (r *Registrar) newChain(configtx *cb.Envelope) {
 	ledgerResources := r.newLedgerResources(configtx)
 	ledgerResources.Append(ledger.CreateNextBlock(ledgerResources, []*cb.Envelope{configtx}))
 
+	chainID := ledgerResources.ConfigtxManager().ChainID()
+	cs := newChainSupport(r, ledgerResources, r.consenters, r.signerFor(chainID))
+
+	logger.Infof("Created and starting new chain %s", chainID)
+
+	cs.start()
+	r.addChain(chainID, cs)
+}
+
+// addChain registers the chain support of a channel
+func (r *Registrar) addChain(chainID string, cs *ChainSupport) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
 	// Copy the map to allow concurrent reads from broadcast/deliver while the new chainSupport is
 	newChains := make(map[string]*ChainSupport)
 	for key, value := range r.chains {
 		newChains[key] = value
 	}
+	newChains[chainID] = cs
+	r.chains = newChains
+	if chainID == r.systemChannelID {
+		r.systemChannel = cs
+	}
+}
 
-	cs := newChainSupport(r, ledgerResources, r.consenters, r.signer)
-	chainID := ledgerResources.ConfigtxManager().ChainID()
+// switchConsenter replaces the chain of the channel with a chain of the consenter of its
+// new consensus type, once the config block which changed the consensus type is committed.
+// The chain is replaced in the background, as it is the chain being replaced which writes
+// the config block
+func (r *Registrar) switchConsenter(chainID string) {
+	cs, ok := r.GetChain(chainID)
+	if !ok {
+		logger.Panicf("[channel: %s] Cannot switch the consenter of a channel which does not exist", chainID)
+	}
+	consensusType := cs.SharedConfig().ConsensusType()
+	if _, ok := r.consenters[consensusType]; !ok {
+		logger.Panicf("[channel: %s] Cannot switch to consensus type %s, which this orderer does not support", chainID, consensusType)
+	}
 
-	logger.Infof("Created and starting new chain %s", chainID)
+	go func() {
+		// Wait for the config block to be committed
+		cs.committingBlock.Lock()
+		cs.committingBlock.Unlock()
 
-	newChains[string(chainID)] = cs
-	cs.start()
+		cs.Halt()
+		newCS := newChainSupport(r, cs.ledgerResources, r.consenters, r.signerFor(chainID))
+		if chainID == r.systemChannelID {
+			newCS.Processor = msgprocessor.NewSystemChannel(newCS, r.templator, msgprocessor.CreateSystemChannelFilters(r, newCS))
+		}
+		logger.Infof("[channel: %s] Switched to consensus type %s, starting chain", chainID, consensusType)
 
-	r.chains = newChains
+		newCS.start()
+		r.addChain(chainID, newCS)
+	}()
+}
+
+// SetMaxChannels limits the number of channels, including the system channel, which the
+// orderer serves. The channels beyond it are neither created nor joined. It must be called
+// before serving requests.
+func (r *Registrar) SetMaxChannels(maxChannels int) {
+	if maxChannels > 0 && r.ChannelsCount() > maxChannels {
+		logger.Warningf("The orderer already serves %d channels, more than the maximum of %d", r.ChannelsCount(), maxChannels)
+	}
+	r.maxChannels = maxChannels
+}
+
+// MaxChannels returns the maximum number of channels which the orderer serves, 0 if unlimited.
+func (r *Registrar) MaxChannels() int {
+	return r.maxChannels
 }
 
 // ChannelsCount returns the count of the current total number of channels.
 func (r *Registrar) ChannelsCount() int {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
 	return len(r.chains)
 }
 
+// ChannelList returns the channels the orderer serves, sorted by name
+func (r *Registrar) ChannelList() []ChannelInfo {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	var channels []ChannelInfo
+	for _, cs := range r.chains {
+		channels = append(channels, r.channelInfo(cs))
+	}
+	sort.Slice(channels, func(i, j int) bool { return channels[i].Name < channels[j].Name })
+	return channels
+}
+
+// ChannelInfo describes a channel the orderer serves
+func (r *Registrar) ChannelInfo(chainID string) (ChannelInfo, error) {
+	cs, ok := r.GetChain(chainID)
+	if !ok {
+		return ChannelInfo{}, ErrChannelNotExist
+	}
+	return r.channelInfo(cs), nil
+}
+
+func (r *Registrar) channelInfo(cs *ChainSupport) ChannelInfo {
+	return ChannelInfo{
+		Name:          cs.ChainID(),
+		Height:        cs.Height(),
+		ConsensusType: cs.SharedConfig().ConsensusType(),
+		SystemChannel: cs.ChainID() == r.systemChannelID,
+	}
+}
+
+// JoinChannel joins the orderer to the channel of the given config block, which is either the
+// genesis block of the channel, or a later config block, in which case the blocks preceding it
+// are pulled from the orderers first. Only an orderer without a system channel joins channels
+// this way.
+func (r *Registrar) JoinChannel(chainID string, configBlock *cb.Block) (ChannelInfo, error) {
+	r.participationLock.Lock()
+	defer r.participationLock.Unlock()
+
+	if r.systemChannelID != "" {
+		return ChannelInfo{}, ErrSystemChannelExists
+	}
+	if _, ok := r.GetChain(chainID); ok {
+		return ChannelInfo{}, ErrChannelAlreadyExists
+	}
+	if r.maxChannels > 0 && r.ChannelsCount() >= r.maxChannels {
+		return ChannelInfo{}, ErrTooManyChannels
+	}
+	configTx, err := r.validateJoinBlock(chainID, configBlock)
+	if err != nil {
+		return ChannelInfo{}, err
+	}
+	for _, existing := range r.ledgerFactory.ChainIDs() {
+		if existing == chainID {
+			return ChannelInfo{}, fmt.Errorf("ledger of channel %s already exists", chainID)
+		}
+	}
+
+	if configBlock.Header.Number == 0 {
+		err = r.appendGenesisBlock(chainID, configBlock)
+	} else if r.replicate == nil {
+		err = fmt.Errorf("cannot pull the blocks preceding block %d of channel %s", configBlock.Header.Number, chainID)
+	} else {
+		logger.Infof("Joining channel %s with block %d, pulling the blocks preceding it", chainID, configBlock.Header.Number)
+		err = r.replicate(configBlock)
+	}
+	if err != nil {
+		if removeErr := r.ledgerFactory.Remove(chainID); removeErr != nil {
+			logger.Warningf("Failed to remove the ledger of channel %s: %s", chainID, removeErr)
+		}
+		return ChannelInfo{}, err
+	}
+
+	cs := newChainSupport(r, r.newLedgerResources(configTx), r.consenters, r.signerFor(chainID))
+	logger.Infof("Joined and starting channel %s", chainID)
+	cs.start()
+	r.addChain(chainID, cs)
+	return r.channelInfo(cs), nil
+}
+
+// validateJoinBlock checks that a block is a config block of the given channel, which this
+// orderer can serve, and returns its config transaction
+func (r *Registrar) validateJoinBlock(chainID string, configBlock *cb.Block) (*cb.Envelope, error) {
+	if configBlock == nil || configBlock.Header == nil || configBlock.Data == nil {
+		return nil, fmt.Errorf("config block is malformed")
+	}
+	if !configBlock.Data.MatchesDataHash(configBlock.Header.DataHash) {
+		return nil, fmt.Errorf("data hash of block %d does not match its data", configBlock.Header.Number)
+	}
+	configTx, err := utils.ExtractEnvelope(configBlock, 0)
+	if err != nil {
+		return nil, fmt.Errorf("could not extract config transaction: %s", err)
+	}
+	chdr, err := utils.ChannelHeader(configTx)
+	if err != nil {
+		return nil, fmt.Errorf("could not read channel header: %s", err)
+	}
+	if chdr.Type != int32(cb.HeaderType_CONFIG) {
+		return nil, fmt.Errorf("block %d is not a config block", configBlock.Header.Number)
+	}
+	if chdr.ChannelId != chainID {
+		return nil, fmt.Errorf("block is a config block of channel %s, not %s", chdr.ChannelId, chainID)
+	}
+
+	configManager, err := channelconfig.New(configTx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config of channel %s: %s", chainID, err)
+	}
+	if _, ok := configManager.ConsortiumsConfig(); ok {
+		return nil, fmt.Errorf("channel %s is a system channel", chainID)
+	}
+	ordererConfig, ok := configManager.OrdererConfig()
+	if !ok {
+		return nil, fmt.Errorf("config of channel %s has no orderer configuration", chainID)
+	}
+	if _, ok := r.consenters[ordererConfig.ConsensusType()]; !ok {
+		return nil, fmt.Errorf("unknown consensus type %s of channel %s", ordererConfig.ConsensusType(), chainID)
+	}
+	return configTx, nil
+}
+
+func (r *Registrar) appendGenesisBlock(chainID string, genesisBlock *cb.Block) error {
+	rl, err := r.ledgerFactory.GetOrCreate(chainID)
+	if err != nil {
+		return fmt.Errorf("could not create ledger of channel %s: %s", chainID, err)
+	}
+	if err := rl.Append(genesisBlock); err != nil {
+		return fmt.Errorf("could not append genesis block of channel %s: %s", chainID, err)
+	}
+	return nil
+}
+
+// RemoveChannel halts the chain of a channel and removes its ledger. Only an orderer without
+// a system channel removes channels this way.
+func (r *Registrar) RemoveChannel(chainID string) error {
+	r.participationLock.Lock()
+	defer r.participationLock.Unlock()
+
+	if r.systemChannelID != "" {
+		return ErrSystemChannelExists
+	}
+
+	r.lock.Lock()
+	cs, ok := r.chains[chainID]
+	if !ok {
+		r.lock.Unlock()
+		return ErrChannelNotExist
+	}
+	newChains := make(map[string]*ChainSupport)
+	for key, value := range r.chains {
+		if key != chainID {
+			newChains[key] = value
+		}
+	}
+	r.chains = newChains
+	r.lock.Unlock()
+
+	cs.Halt()
+	if err := r.ledgerFactory.Remove(chainID); err != nil {
+		return fmt.Errorf("could not remove ledger of channel %s: %s", chainID, err)
+	}
+	logger.Infof("Removed channel %s", chainID)
+	return nil
+}
+
 // NewChannelConfig produces a new template channel configuration based on the system channel's current config.
 func (r *Registrar) NewChannelConfig(envConfigUpdate *cb.Envelope) (configtxapi.Manager, error) {
 	return r.templator.NewChannelConfig(envConfigUpdate)
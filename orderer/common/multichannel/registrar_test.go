@@ -7,17 +7,21 @@ SPDX-License-Identifier: Apache-2.0
 package multichannel
 
 import (
+	"fmt"
 	"reflect"
 	"testing"
 	"time"
 
 	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/capabilities"
 	channelconfig "github.com/hyperledger/fabric/common/config/channel"
 	"github.com/hyperledger/fabric/common/crypto"
 	mockcrypto "github.com/hyperledger/fabric/common/mocks/crypto"
 	genesisconfig "github.com/hyperledger/fabric/common/tools/configtxgen/localconfig"
 	"github.com/hyperledger/fabric/common/tools/configtxgen/provisional"
+	"github.com/hyperledger/fabric/common/tools/configtxlator/update"
 	"github.com/hyperledger/fabric/msp"
+	"github.com/hyperledger/fabric/orderer/common/blockcutter"
 	"github.com/hyperledger/fabric/orderer/common/ledger"
 	ramledger "github.com/hyperledger/fabric/orderer/common/ledger/ram"
 	"github.com/hyperledger/fabric/orderer/consensus"
@@ -28,6 +32,7 @@ import (
 	mmsp "github.com/hyperledger/fabric/common/mocks/msp"
 	logging "github.com/op/go-logging"
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
 )
 
 var conf *genesisconfig.Profile
@@ -108,7 +113,7 @@ func TestNoSystemChain(t *testing.T) {
 	consenters := make(map[string]consensus.Consenter)
 	consenters[conf.Orderer.OrdererType] = &mockConsenter{}
 
-	assert.Panics(t, func() { NewRegistrar(lf, consenters, mockCrypto()) }, "Should have panicked when starting without a system chain")
+	assert.Panics(t, func() { NewRegistrar(lf, consenters, mockCrypto(), blockcutter.NewBatchSizePolicy) }, "Should have panicked when starting without a system chain")
 }
 
 // This test checks to make sure that the orderer refuses to come up if there are multiple system channels
@@ -126,7 +131,95 @@ func TestMultiSystemChannel(t *testing.T) {
 	consenters := make(map[string]consensus.Consenter)
 	consenters[conf.Orderer.OrdererType] = &mockConsenter{}
 
-	assert.Panics(t, func() { NewRegistrar(lf, consenters, mockCrypto()) }, "Two system channels should have caused panic")
+	assert.Panics(t, func() { NewRegistrar(lf, consenters, mockCrypto(), blockcutter.NewBatchSizePolicy) }, "Two system channels should have caused panic")
+}
+
+// This test checks that a participation registrar comes up without a system channel, and joins and removes channels
+func TestJoinRemoveChannel(t *testing.T) {
+	lf := ramledger.New(10)
+	consenters := make(map[string]consensus.Consenter)
+	consenters[conf.Orderer.OrdererType] = &mockConsenter{}
+
+	var replicated []*cb.Block
+	manager := NewParticipationRegistrar(lf, consenters, mockCrypto(), blockcutter.NewBatchSizePolicy, func(configBlock *cb.Block) error {
+		replicated = append(replicated, configBlock)
+		return fmt.Errorf("orderers unreachable")
+	})
+	assert.Empty(t, manager.ChannelList())
+
+	noConsortiumConf := genesisconfig.Load("SampleNoConsortium")
+	fooGenesis := provisional.New(noConsortiumConf).GenesisBlockForChannel("foo")
+	info, err := manager.JoinChannel("foo", fooGenesis)
+	assert.NoError(t, err)
+	assert.Equal(t, ChannelInfo{Name: "foo", Height: 1, ConsensusType: conf.Orderer.OrdererType}, info)
+	_, ok := manager.GetChain("foo")
+	assert.True(t, ok, "Should have gotten the joined chain")
+	assert.Equal(t, []ChannelInfo{info}, manager.ChannelList())
+
+	_, err = manager.JoinChannel("foo", fooGenesis)
+	assert.Equal(t, ErrChannelAlreadyExists, err)
+	_, err = manager.JoinChannel("bar", fooGenesis)
+	assert.EqualError(t, err, "block is a config block of channel foo, not bar")
+	_, err = manager.JoinChannel("system", provisional.New(conf).GenesisBlockForChannel("system"))
+	assert.EqualError(t, err, "channel system is a system channel")
+
+	// a later config block is joined by pulling the blocks preceding it
+	barBlock := provisional.New(noConsortiumConf).GenesisBlockForChannel("bar")
+	barBlock.Header.Number = 5
+	_, err = manager.JoinChannel("bar", barBlock)
+	assert.EqualError(t, err, "orderers unreachable")
+	assert.Equal(t, []*cb.Block{barBlock}, replicated)
+	assert.Equal(t, []string{"foo"}, lf.ChainIDs())
+
+	assert.Equal(t, ErrChannelNotExist, manager.RemoveChannel("bar"))
+	assert.NoError(t, manager.RemoveChannel("foo"))
+	_, ok = manager.GetChain("foo")
+	assert.False(t, ok, "Should not have found the removed chain")
+	assert.Empty(t, lf.ChainIDs())
+	_, err = manager.ChannelInfo("foo")
+	assert.Equal(t, ErrChannelNotExist, err)
+
+	// the channel may be joined again once removed
+	_, err = manager.JoinChannel("foo", fooGenesis)
+	assert.NoError(t, err)
+}
+
+// This test checks that the channels joined are limited by the maximum number of channels
+func TestJoinChannelMaxChannels(t *testing.T) {
+	lf := ramledger.New(10)
+	consenters := make(map[string]consensus.Consenter)
+	consenters[conf.Orderer.OrdererType] = &mockConsenter{}
+
+	manager := NewParticipationRegistrar(lf, consenters, mockCrypto(), blockcutter.NewBatchSizePolicy, nil)
+	manager.SetMaxChannels(1)
+	assert.Equal(t, 1, manager.MaxChannels())
+
+	noConsortiumConf := genesisconfig.Load("SampleNoConsortium")
+	_, err := manager.JoinChannel("foo", provisional.New(noConsortiumConf).GenesisBlockForChannel("foo"))
+	assert.NoError(t, err)
+	_, err = manager.JoinChannel("bar", provisional.New(noConsortiumConf).GenesisBlockForChannel("bar"))
+	assert.Equal(t, ErrTooManyChannels, err)
+	assert.Equal(t, []string{"foo"}, lf.ChainIDs())
+
+	// the channels removed make room for others
+	assert.NoError(t, manager.RemoveChannel("foo"))
+	_, err = manager.JoinChannel("bar", provisional.New(noConsortiumConf).GenesisBlockForChannel("bar"))
+	assert.NoError(t, err)
+}
+
+// This test checks that the channels of an orderer with a system channel are not joined or removed one at a time
+func TestJoinRemoveChannelWithSystemChannel(t *testing.T) {
+	lf, _ := NewRAMLedgerAndFactory(10)
+	consenters := make(map[string]consensus.Consenter)
+	consenters[conf.Orderer.OrdererType] = &mockConsenter{}
+
+	manager := NewParticipationRegistrar(lf, consenters, mockCrypto(), blockcutter.NewBatchSizePolicy, nil)
+	assert.Equal(t, []ChannelInfo{{Name: provisional.TestChainID, Height: 1, ConsensusType: conf.Orderer.OrdererType, SystemChannel: true}}, manager.ChannelList())
+
+	fooGenesis := provisional.New(genesisconfig.Load("SampleNoConsortium")).GenesisBlockForChannel("foo")
+	_, err := manager.JoinChannel("foo", fooGenesis)
+	assert.Equal(t, ErrSystemChannelExists, err)
+	assert.Equal(t, ErrSystemChannelExists, manager.RemoveChannel(provisional.TestChainID))
 }
 
 // This test essentially brings the entire system up and is ultimately what main.go will replicate
@@ -136,7 +229,7 @@ func TestManagerImpl(t *testing.T) {
 	consenters := make(map[string]consensus.Consenter)
 	consenters[conf.Orderer.OrdererType] = &mockConsenter{}
 
-	manager := NewRegistrar(lf, consenters, mockCrypto())
+	manager := NewRegistrar(lf, consenters, mockCrypto(), blockcutter.NewBatchSizePolicy)
 
 	_, ok := manager.GetChain("Fake")
 	assert.False(t, ok, "Should not have found a chain that was not created")
@@ -167,6 +260,35 @@ func TestManagerImpl(t *testing.T) {
 	}
 }
 
+type erroredChain struct {
+	consensus.Chain
+	errorChan chan struct{}
+}
+
+func (ec *erroredChain) Errored() <-chan struct{} {
+	return ec.errorChan
+}
+
+func TestRegistrarHealthCheck(t *testing.T) {
+	lf, _ := NewRAMLedgerAndFactory(10)
+
+	consenters := make(map[string]consensus.Consenter)
+	consenters[conf.Orderer.OrdererType] = &mockConsenter{}
+
+	manager := NewRegistrar(lf, consenters, mockCrypto(), blockcutter.NewBatchSizePolicy)
+	assert.NoError(t, manager.HealthCheck(context.Background()))
+
+	chainSupport, ok := manager.GetChain(provisional.TestChainID)
+	assert.True(t, ok)
+	errored := &erroredChain{Chain: chainSupport.Chain, errorChan: make(chan struct{})}
+	chainSupport.Chain = errored
+	assert.NoError(t, manager.HealthCheck(context.Background()), "The consenter has not reported an error yet")
+
+	close(errored.errorChan)
+	err := manager.HealthCheck(context.Background())
+	assert.EqualError(t, err, fmt.Sprintf("consenters of channels [%s] report an error", provisional.TestChainID))
+}
+
 // This test brings up the entire system, with the mock consenter, including the broadcasters etc. and creates a new chain
 func TestNewChain(t *testing.T) {
 	expectedLastConfigBlockNumber := uint64(0)
@@ -178,7 +300,7 @@ func TestNewChain(t *testing.T) {
 	consenters := make(map[string]consensus.Consenter)
 	consenters[conf.Orderer.OrdererType] = &mockConsenter{}
 
-	manager := NewRegistrar(lf, consenters, mockCrypto())
+	manager := NewRegistrar(lf, consenters, mockCrypto(), blockcutter.NewBatchSizePolicy)
 
 	envConfigUpdate, err := channelconfig.MakeChainCreationTransaction(newChainID, genesisconfig.SampleConsortiumName, mockSigningIdentity)
 	assert.NoError(t, err, "Constructing chain creation tx")
@@ -271,6 +393,117 @@ func TestNewChain(t *testing.T) {
 	assert.Equal(t, expectedLastConfigSeq, rcs.lastConfigSeq, "On restart, incorrect lastConfigSeq")
 }
 
+func TestSimulateConfigUpdate(t *testing.T) {
+	newChainID := "test-simulated-chain"
+
+	lf, _ := NewRAMLedgerAndFactory(10)
+
+	consenters := make(map[string]consensus.Consenter)
+	consenters[conf.Orderer.OrdererType] = &mockConsenter{}
+
+	manager := NewRegistrar(lf, consenters, mockCrypto(), blockcutter.NewBatchSizePolicy)
+
+	envConfigUpdate, err := channelconfig.MakeChainCreationTransaction(newChainID, genesisconfig.SampleConsortiumName, mockSigningIdentity)
+	assert.NoError(t, err, "Constructing chain creation tx")
+
+	configEnv, err := manager.SimulateConfigUpdate(envConfigUpdate)
+	assert.NoError(t, err, "Simulating chain creation")
+	assert.Equal(t, uint64(1), configEnv.GetConfig().Sequence)
+	_, ok := manager.GetChain(newChainID)
+	assert.False(t, ok, "Simulating a chain creation should not create the chain")
+
+	_, err = manager.SimulateConfigUpdate(makeNormalTx(provisional.TestChainID, 0))
+	assert.EqualError(t, err, "message of type ENDORSER_TRANSACTION is not a config update")
+
+	_, err = manager.SimulateConfigUpdate(&cb.Envelope{})
+	assert.Error(t, err)
+}
+
+// makeOrdererConfigTx returns a config transaction of the channel of the chain support, updating its
+// orderer config with the given consensus type and capabilities
+func makeOrdererConfigTx(t *testing.T, cs *ChainSupport, consensusType *ab.ConsensusType, capabilities map[string]*cb.Capability) *cb.Envelope {
+	original := cs.ConfigEnvelope().Config
+	updated := proto.Clone(original).(*cb.Config)
+	updated.Sequence++
+	ordererGroup := updated.ChannelGroup.Groups[channelconfig.OrdererGroupKey]
+	ordererGroup.Values[channelconfig.ConsensusTypeKey].Value = utils.MarshalOrPanic(consensusType)
+	if _, ok := ordererGroup.Values[channelconfig.CapabilitiesKey]; !ok {
+		ordererGroup.Values[channelconfig.CapabilitiesKey] = &cb.ConfigValue{ModPolicy: ordererGroup.ModPolicy}
+	}
+	ordererGroup.Values[channelconfig.CapabilitiesKey].Value = utils.MarshalOrPanic(&cb.Capabilities{Capabilities: capabilities})
+
+	configUpdate, err := update.Compute(original, updated)
+	assert.NoError(t, err)
+	configUpdate.ChannelId = cs.ChainID()
+	configUpdateTx, err := utils.CreateSignedEnvelope(cb.HeaderType_CONFIG_UPDATE, cs.ChainID(), mockCrypto(), &cb.ConfigUpdateEnvelope{
+		ConfigUpdate: utils.MarshalOrPanic(configUpdate),
+	}, msgVersion, epoch)
+	assert.NoError(t, err)
+
+	// The resulting config has the versions of the config update applied
+	configEnv, err := cs.ProposeConfigUpdate(configUpdateTx)
+	assert.NoError(t, err)
+	configTx, err := utils.CreateSignedEnvelope(cb.HeaderType_CONFIG, cs.ChainID(), mockCrypto(), configEnv, msgVersion, epoch)
+	assert.NoError(t, err)
+	return configTx
+}
+
+type recordingConsenter struct {
+	mockConsenter
+	metadata chan *cb.Metadata
+}
+
+func (rc *recordingConsenter) HandleChain(support consensus.ConsenterSupport, metadata *cb.Metadata) (consensus.Chain, error) {
+	rc.metadata <- metadata
+	return rc.mockConsenter.HandleChain(support, metadata)
+}
+
+func TestSwitchConsenter(t *testing.T) {
+	lf, rl := NewRAMLedgerAndFactory(10)
+	next := &recordingConsenter{metadata: make(chan *cb.Metadata, 1)}
+	consenters := map[string]consensus.Consenter{
+		conf.Orderer.OrdererType: &mockConsenter{},
+		"next":                   next,
+	}
+	manager := NewRegistrar(lf, consenters, mockCrypto(), blockcutter.NewBatchSizePolicy)
+	cs, ok := manager.GetChain(manager.SystemChannelID())
+	assert.True(t, ok)
+	migration := map[string]*cb.Capability{capabilities.OrdererV1_2: {}}
+
+	// Entering maintenance mode keeps the consenter
+	configTx := makeOrdererConfigTx(t, cs, &ab.ConsensusType{Type: conf.Orderer.OrdererType, State: ab.ConsensusType_STATE_MAINTENANCE}, migration)
+	cs.WriteConfigBlock(cs.CreateNextBlock([]*cb.Envelope{configTx}), []byte("metadata"))
+	assert.Equal(t, ab.ConsensusType_STATE_MAINTENANCE, cs.SharedConfig().ConsensusState())
+
+	// Wait for the commit to complete
+	cs.committingBlock.Lock()
+	cs.committingBlock.Unlock()
+
+	// Changing the consensus type switches the consenter once the config block is committed
+	configTx = makeOrdererConfigTx(t, cs, &ab.ConsensusType{Type: "next", Metadata: []byte("next"), State: ab.ConsensusType_STATE_MAINTENANCE}, migration)
+	cs.WriteConfigBlock(cs.CreateNextBlock([]*cb.Envelope{configTx}), []byte("metadata"))
+
+	select {
+	case metadata := <-next.metadata:
+		assert.Empty(t, metadata.Value, "Expected the next consenter not to be handed the metadata of the previous one")
+	case <-time.After(time.Second):
+		t.Fatalf("Consenter not switched after timeout")
+	}
+	assert.Equal(t, uint64(3), rl.Height())
+	for {
+		switched, _ := manager.GetChain(manager.SystemChannelID())
+		if switched != cs {
+			assert.Equal(t, "next", switched.SharedConfig().ConsensusType())
+			assert.Equal(t, uint64(2), switched.lastConfigBlockNum)
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	_, _, systemChannel, err := manager.BroadcastChannelSupport(makeNormalTx("nonexistent", 0))
+	assert.NoError(t, err)
+	assert.NotEqual(t, cs, systemChannel, "Expected the switched chain to serve as the system channel")
+}
+
 func testLastConfigBlockNumber(t *testing.T, block *cb.Block, expectedBlockNumber uint64) {
 	metadataItem := &cb.Metadata{}
 	err := proto.Unmarshal(block.Metadata.Metadata[cb.BlockMetadataIndex_LAST_CONFIG], metadataItem)
@@ -0,0 +1,48 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package multichannel
+
+import (
+	"github.com/hyperledger/fabric/common/crypto"
+)
+
+// ChannelSigner is a crypto.LocalSigner which signs the blocks and the transactions of
+// some channels with distinct identities. When the signer of a Registrar is a ChannelSigner,
+// the resources of every channel sign with the signer it returns for the channel
+type ChannelSigner interface {
+	crypto.LocalSigner
+
+	// ForChannel returns the signer of the channel
+	ForChannel(chainID string) crypto.LocalSigner
+}
+
+type channelSigner struct {
+	crypto.LocalSigner
+	signers map[string]crypto.LocalSigner
+}
+
+// NewChannelSigner returns a ChannelSigner which signs for each channel in the given map
+// with its signer, and for the other channels with the default signer
+func NewChannelSigner(defaultSigner crypto.LocalSigner, signers map[string]crypto.LocalSigner) ChannelSigner {
+	return &channelSigner{LocalSigner: defaultSigner, signers: signers}
+}
+
+// ForChannel returns the signer of the channel
+func (cs *channelSigner) ForChannel(chainID string) crypto.LocalSigner {
+	if signer, ok := cs.signers[chainID]; ok {
+		return signer
+	}
+	return cs.LocalSigner
+}
+
+// signerFor returns the signer of the channel
+func (r *Registrar) signerFor(chainID string) crypto.LocalSigner {
+	if cs, ok := r.signer.(ChannelSigner); ok {
+		return cs.ForChannel(chainID)
+	}
+	return r.signer
+}
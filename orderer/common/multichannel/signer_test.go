@@ -0,0 +1,53 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package multichannel
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric/common/crypto"
+	mockcrypto "github.com/hyperledger/fabric/common/mocks/crypto"
+	"github.com/hyperledger/fabric/common/tools/configtxgen/provisional"
+	"github.com/hyperledger/fabric/orderer/common/blockcutter"
+	"github.com/hyperledger/fabric/orderer/consensus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChannelSigner(t *testing.T) {
+	channelSigner := &mockcrypto.LocalSigner{Identity: []byte("ChannelIdentity")}
+	signer := NewChannelSigner(mockCrypto(), map[string]crypto.LocalSigner{"mychannel": channelSigner})
+
+	assert.Equal(t, channelSigner, signer.ForChannel("mychannel"))
+	assert.Equal(t, mockCrypto(), signer.ForChannel("otherchannel"), "Channels without a signer should use the default signer")
+
+	sh, err := signer.NewSignatureHeader()
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("IdentityBytes"), sh.Creator, "The channel signer should sign with the default signer")
+}
+
+func TestRegistrarChannelSigner(t *testing.T) {
+	lf, _ := NewRAMLedgerAndFactory(10)
+
+	consenters := make(map[string]consensus.Consenter)
+	consenters[conf.Orderer.OrdererType] = &mockConsenter{}
+
+	t.Run("DefaultSigner", func(t *testing.T) {
+		manager := NewRegistrar(lf, consenters, mockCrypto(), blockcutter.NewBatchSizePolicy)
+		chainSupport, ok := manager.GetChain(provisional.TestChainID)
+		assert.True(t, ok)
+		assert.Equal(t, mockCrypto(), chainSupport.LocalSigner)
+	})
+
+	t.Run("ChannelSigner", func(t *testing.T) {
+		channelSigner := &mockcrypto.LocalSigner{Identity: []byte("ChannelIdentity")}
+		signer := NewChannelSigner(mockCrypto(), map[string]crypto.LocalSigner{provisional.TestChainID: channelSigner})
+		manager := NewRegistrar(lf, consenters, signer, blockcutter.NewBatchSizePolicy)
+		chainSupport, ok := manager.GetChain(provisional.TestChainID)
+		assert.True(t, ok)
+		assert.Equal(t, channelSigner, chainSupport.LocalSigner, "The channel should sign with its own signer")
+	})
+}
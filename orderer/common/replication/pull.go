@@ -0,0 +1,157 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package replication
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+
+	"github.com/hyperledger/fabric/common/crypto"
+	"github.com/hyperledger/fabric/core/comm"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/utils"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// Dialer connects to the orderer at the given endpoint
+type Dialer func(endpoint string) (*grpc.ClientConn, error)
+
+// NewDialer returns a Dialer which connects to the orderers with the given TLS certificate,
+// trusting the given root CAs. TLS is not used if certificate is nil
+func NewDialer(certificate *tls.Certificate, rootCAs [][]byte) (Dialer, error) {
+	if certificate == nil {
+		return func(endpoint string) (*grpc.ClientConn, error) {
+			return comm.NewClientConnectionWithAddress(endpoint, true, false, nil)
+		}, nil
+	}
+
+	certPool := x509.NewCertPool()
+	for _, rootCA := range rootCAs {
+		if !certPool.AppendCertsFromPEM(rootCA) {
+			return nil, fmt.Errorf("could not parse root CA certificate")
+		}
+	}
+	creds := credentials.NewTLS(&tls.Config{
+		Certificates: []tls.Certificate{*certificate},
+		RootCAs:      certPool,
+	})
+	return func(endpoint string) (*grpc.ClientConn, error) {
+		return comm.NewClientConnectionWithAddress(endpoint, true, true, creds)
+	}, nil
+}
+
+// PullBlocks pulls the blocks of a channel from start to end, inclusive, from the orderer reached
+// through conn, and passes them in order to deliver
+func PullBlocks(conn *grpc.ClientConn, channel string, signer crypto.LocalSigner, start, end uint64, deliver func(block *cb.Block) error) error {
+	stream, cancel, err := seek(conn, channel, signer,
+		&ab.SeekPosition{Type: &ab.SeekPosition_Specified{Specified: &ab.SeekSpecified{Number: start}}},
+		&ab.SeekPosition{Type: &ab.SeekPosition_Specified{Specified: &ab.SeekSpecified{Number: end}}})
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	next := start
+	for {
+		block, err := receiveBlock(stream)
+		if err != nil {
+			return fmt.Errorf("could not receive block %d: %s", next, err)
+		}
+		if block.Header.Number != next {
+			return fmt.Errorf("expected block %d but got block %d", next, block.Header.Number)
+		}
+		if err := deliver(block); err != nil {
+			return err
+		}
+		if next == end {
+			return nil
+		}
+		next++
+	}
+}
+
+// Height returns the number of blocks of a channel at the orderer reached through conn
+func Height(conn *grpc.ClientConn, channel string, signer crypto.LocalSigner) (uint64, error) {
+	newest := &ab.SeekPosition{Type: &ab.SeekPosition_Newest{Newest: &ab.SeekNewest{}}}
+	stream, cancel, err := seek(conn, channel, signer, newest, newest)
+	if err != nil {
+		return 0, err
+	}
+	defer cancel()
+
+	block, err := receiveBlock(stream)
+	if err != nil {
+		return 0, fmt.Errorf("could not receive newest block: %s", err)
+	}
+	return block.Header.Number + 1, nil
+}
+
+// VerifyBlock checks that the block follows the previous one, and that its data matches its header
+func VerifyBlock(previous, block *cb.Block) error {
+	if block.Header == nil || block.Data == nil {
+		return fmt.Errorf("block is malformed")
+	}
+	if block.Header.Number != previous.Header.Number+1 || !bytes.Equal(block.Header.PreviousHash, previous.Header.Hash()) {
+		return fmt.Errorf("block %d does not follow block %d", block.Header.Number, previous.Header.Number)
+	}
+	if !block.Data.MatchesDataHash(block.Header.DataHash) {
+		return fmt.Errorf("data hash of block %d does not match its data", block.Header.Number)
+	}
+	return nil
+}
+
+func seek(conn *grpc.ClientConn, channel string, signer crypto.LocalSigner, start, stop *ab.SeekPosition) (ab.AtomicBroadcast_DeliverClient, context.CancelFunc, error) {
+	seekInfo := &ab.SeekInfo{Start: start, Stop: stop, Behavior: ab.SeekInfo_FAIL_IF_NOT_READY}
+	env, err := utils.CreateSignedEnvelope(cb.HeaderType_DELIVER_SEEK_INFO, channel, signer, seekInfo, 0, 0)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not create seek request: %s", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := ab.NewAtomicBroadcastClient(conn).Deliver(ctx)
+	if err != nil {
+		cancel()
+		return nil, nil, err
+	}
+	if err := stream.Send(env); err != nil {
+		cancel()
+		return nil, nil, err
+	}
+	if err := stream.CloseSend(); err != nil {
+		cancel()
+		return nil, nil, err
+	}
+	return stream, cancel, nil
+}
+
+func receiveBlock(stream ab.AtomicBroadcast_DeliverClient) (*cb.Block, error) {
+	resp, err := stream.Recv()
+	if err == io.EOF {
+		return nil, fmt.Errorf("stream ended")
+	}
+	if err != nil {
+		return nil, err
+	}
+	switch t := resp.Type.(type) {
+	case *ab.DeliverResponse_Block:
+		if t.Block == nil || t.Block.Header == nil {
+			return nil, fmt.Errorf("got malformed block")
+		}
+		return t.Block, nil
+	case *ab.DeliverResponse_Status:
+		return nil, fmt.Errorf("got status %s", t.Status)
+	default:
+		return nil, fmt.Errorf("got unexpected response")
+	}
+}
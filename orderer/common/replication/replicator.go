@@ -0,0 +1,325 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package replication
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	channelconfig "github.com/hyperledger/fabric/common/config/channel"
+	"github.com/hyperledger/fabric/common/configtx"
+	"github.com/hyperledger/fabric/common/crypto"
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/orderer/common/ledger"
+	cb "github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/utils"
+	logging "github.com/op/go-logging"
+)
+
+const pkgLogID = "orderer/replication"
+
+var logger *logging.Logger
+
+func init() {
+	logger = flogging.MustGetLogger(pkgLogID)
+}
+
+// Replicator onboards an orderer which joins an existing ordering service. Such an orderer is
+// bootstrapped with the last config block of the system channel rather than with its genesis
+// block. The replicator pulls the system channel up to that block from the orderers listed in
+// it, trusting the blocks which hash chain to it, and then pulls the channels created by the
+// system channel. A channel which cannot be pulled, as happens when the orderer is not allowed
+// to read it, is skipped. The replicator also pulls a single channel up to a config block of it,
+// as when an orderer joins a channel with that block
+type Replicator struct {
+	// Channel is the channel of the boot block, which is the system channel when onboarding
+	Channel       string
+	BootBlock     *cb.Block
+	Endpoints     []string
+	LedgerFactory ledger.Factory
+	Signer        crypto.LocalSigner
+	Dial          Dialer
+	RetryInterval time.Duration
+	MaxRetries    int
+}
+
+// NewReplicator creates a replicator from the given boot block, which is a config block
+func NewReplicator(bootBlock *cb.Block, lf ledger.Factory, signer crypto.LocalSigner, dial Dialer, retryInterval time.Duration, maxRetries int) (*Replicator, error) {
+	if bootBlock == nil || bootBlock.Header == nil {
+		return nil, fmt.Errorf("boot block is malformed")
+	}
+	channel, err := utils.GetChainIDFromBlock(bootBlock)
+	if err != nil {
+		return nil, fmt.Errorf("could not read channel of boot block: %s", err)
+	}
+	endpoints, err := ordererAddresses(bootBlock)
+	if err != nil {
+		return nil, err
+	}
+	return &Replicator{
+		Channel:       channel,
+		BootBlock:     bootBlock,
+		Endpoints:     endpoints,
+		LedgerFactory: lf,
+		Signer:        signer,
+		Dial:          dial,
+		RetryInterval: retryInterval,
+		MaxRetries:    maxRetries,
+	}, nil
+}
+
+// IsReplicationNeeded returns whether the ledger of the channel lacks the boot block
+func (r *Replicator) IsReplicationNeeded() (bool, error) {
+	if r.BootBlock.Header.Number == 0 {
+		return false, nil
+	}
+	chainLedger, err := r.LedgerFactory.GetOrCreate(r.Channel)
+	if err != nil {
+		return false, err
+	}
+	return chainLedger.Height() <= r.BootBlock.Header.Number, nil
+}
+
+// ReplicateChains pulls the system channel up to the boot block, then the channels it created
+func (r *Replicator) ReplicateChains() error {
+	if err := r.ReplicateChain(); err != nil {
+		return err
+	}
+	channels, err := r.createdChannels()
+	if err != nil {
+		return err
+	}
+	for _, channel := range channels {
+		if err := r.replicateChannel(channel.name, channel.genesis); err != nil {
+			logger.Warningf("Skipping channel %s: %s", channel.name, err)
+		}
+	}
+	return nil
+}
+
+// ReplicateChain pulls the channel of the boot block up to the boot block
+func (r *Replicator) ReplicateChain() error {
+	chainLedger, err := r.LedgerFactory.GetOrCreate(r.Channel)
+	if err != nil {
+		return err
+	}
+	start, end := chainLedger.Height(), r.BootBlock.Header.Number
+	logger.Infof("Replicating blocks %d to %d of channel %s", start, end, r.Channel)
+
+	// the blocks are trusted once they hash chain to the boot block, so they are held in memory
+	// until the whole chain is verified
+	var blocks []*cb.Block
+	err = r.retry(func(endpoint string) error {
+		blocks = blocks[:0]
+		return r.pull(endpoint, r.Channel, start, end, func(block *cb.Block) error {
+			blocks = append(blocks, block)
+			return nil
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("could not pull channel %s: %s", r.Channel, err)
+	}
+
+	if !bytes.Equal(blocks[len(blocks)-1].Header.Hash(), r.BootBlock.Header.Hash()) {
+		return fmt.Errorf("block %d of channel %s pulled does not match the boot block", end, r.Channel)
+	}
+	for i := len(blocks) - 1; i > 0; i-- {
+		if err := VerifyBlock(blocks[i-1], blocks[i]); err != nil {
+			return fmt.Errorf("invalid block of channel %s: %s", r.Channel, err)
+		}
+	}
+	if start > 0 {
+		previous := ledger.GetBlock(chainLedger, start-1)
+		if err := VerifyBlock(previous, blocks[0]); err != nil {
+			return fmt.Errorf("invalid block of channel %s: %s", r.Channel, err)
+		}
+	}
+
+	for _, block := range blocks {
+		if err := chainLedger.Append(block); err != nil {
+			return fmt.Errorf("could not append block %d of channel %s: %s", block.Header.Number, r.Channel, err)
+		}
+	}
+	return nil
+}
+
+type createdChannel struct {
+	name    string
+	genesis *cb.Block
+}
+
+// createdChannels returns the channels created by the transactions of the system channel, along
+// with their genesis blocks, which are derived from the transactions as the orderers do
+func (r *Replicator) createdChannels() ([]createdChannel, error) {
+	systemLedger, err := r.LedgerFactory.GetOrCreate(r.Channel)
+	if err != nil {
+		return nil, err
+	}
+	var channels []createdChannel
+	for number := uint64(0); number < systemLedger.Height(); number++ {
+		block := ledger.GetBlock(systemLedger, number)
+		if block == nil || block.Data == nil {
+			return nil, fmt.Errorf("could not read block %d of system channel %s", number, r.Channel)
+		}
+		for i := range block.Data.Data {
+			env, err := utils.ExtractEnvelope(block, i)
+			if err != nil {
+				return nil, err
+			}
+			payload, err := utils.UnmarshalPayload(env.Payload)
+			if err != nil {
+				return nil, err
+			}
+			if payload.Header == nil {
+				continue
+			}
+			chdr, err := utils.UnmarshalChannelHeader(payload.Header.ChannelHeader)
+			if err != nil {
+				return nil, err
+			}
+			if chdr.Type != int32(cb.HeaderType_ORDERER_TRANSACTION) {
+				continue
+			}
+
+			configTx, err := utils.UnmarshalEnvelope(payload.Data)
+			if err != nil {
+				return nil, fmt.Errorf("block %d of system channel %s holds a malformed channel creation: %s", number, r.Channel, err)
+			}
+			channel, err := utils.ChannelID(configTx)
+			if err != nil {
+				return nil, fmt.Errorf("block %d of system channel %s holds a malformed channel creation: %s", number, r.Channel, err)
+			}
+			data := &cb.BlockData{Data: [][]byte{utils.MarshalOrPanic(configTx)}}
+			genesis := cb.NewBlock(0, nil)
+			genesis.Header.DataHash = data.Hash()
+			genesis.Data = data
+			channels = append(channels, createdChannel{name: channel, genesis: genesis})
+		}
+	}
+	return channels, nil
+}
+
+// replicateChannel pulls the blocks of a channel the ledger lacks, verifying that they hash chain
+// to the genesis block of the channel
+func (r *Replicator) replicateChannel(channel string, genesis *cb.Block) error {
+	var chainLedger ledger.ReadWriter
+	var last *cb.Block
+	for _, existing := range r.LedgerFactory.ChainIDs() {
+		if existing != channel {
+			continue
+		}
+		var err error
+		chainLedger, err = r.LedgerFactory.GetOrCreate(channel)
+		if err != nil {
+			return err
+		}
+		if chainLedger.Height() > 0 {
+			last = ledger.GetBlock(chainLedger, chainLedger.Height()-1)
+		}
+	}
+
+	return r.retry(func(endpoint string) error {
+		conn, err := r.Dial(endpoint)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		height, err := Height(conn, channel, r.Signer)
+		if err != nil {
+			return err
+		}
+		start := uint64(0)
+		if last != nil {
+			start = last.Header.Number + 1
+		}
+		if start >= height {
+			return nil
+		}
+		logger.Infof("Replicating blocks %d to %d of channel %s from %s", start, height-1, channel, endpoint)
+
+		return PullBlocks(conn, channel, r.Signer, start, height-1, func(block *cb.Block) error {
+			if last == nil {
+				if !bytes.Equal(block.Header.Hash(), genesis.Header.Hash()) {
+					return fmt.Errorf("genesis block does not match the channel creation transaction")
+				}
+			} else if err := VerifyBlock(last, block); err != nil {
+				return err
+			}
+			// the ledger is only created once a block is verified, so that the orderer does not
+			// serve the channels it could not pull
+			if chainLedger == nil {
+				if chainLedger, err = r.LedgerFactory.GetOrCreate(channel); err != nil {
+					return err
+				}
+			}
+			if err := chainLedger.Append(block); err != nil {
+				return fmt.Errorf("could not append block %d: %s", block.Header.Number, err)
+			}
+			last = block
+			return nil
+		})
+	})
+}
+
+func (r *Replicator) pull(endpoint, channel string, start, end uint64, deliver func(block *cb.Block) error) error {
+	conn, err := r.Dial(endpoint)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	return PullBlocks(conn, channel, r.Signer, start, end, deliver)
+}
+
+// retry calls f with each endpoint in turn until it succeeds, for up to MaxRetries rounds
+func (r *Replicator) retry(f func(endpoint string) error) error {
+	var err error
+	for round := 0; round <= r.MaxRetries; round++ {
+		if round > 0 {
+			time.Sleep(r.RetryInterval)
+		}
+		for _, endpoint := range r.Endpoints {
+			if err = f(endpoint); err == nil {
+				return nil
+			}
+			logger.Debugf("Failed to replicate from %s: %s", endpoint, err)
+		}
+	}
+	if err == nil {
+		err = fmt.Errorf("no orderer endpoints")
+	}
+	return err
+}
+
+// ordererAddresses returns the orderer addresses of the channel config carried by a config block
+func ordererAddresses(block *cb.Block) ([]string, error) {
+	env, err := utils.ExtractEnvelope(block, 0)
+	if err != nil {
+		return nil, fmt.Errorf("could not extract config transaction: %s", err)
+	}
+	payload, err := utils.UnmarshalPayload(env.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("could not unmarshal config transaction: %s", err)
+	}
+	configEnvelope, err := configtx.UnmarshalConfigEnvelope(payload.Data)
+	if err != nil {
+		return nil, fmt.Errorf("block %d is not a config block: %s", block.Header.Number, err)
+	}
+	if configEnvelope.Config == nil || configEnvelope.Config.ChannelGroup == nil {
+		return nil, fmt.Errorf("config of block %d has no channel group", block.Header.Number)
+	}
+	value, ok := configEnvelope.Config.ChannelGroup.Values[channelconfig.OrdererAddressesKey]
+	if !ok {
+		return nil, fmt.Errorf("config of block %d has no orderer addresses", block.Header.Number)
+	}
+	addresses := &cb.OrdererAddresses{}
+	if err := proto.Unmarshal(value.Value, addresses); err != nil {
+		return nil, fmt.Errorf("could not unmarshal orderer addresses: %s", err)
+	}
+	return addresses.Addresses, nil
+}
@@ -0,0 +1,224 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package replication
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	channelconfig "github.com/hyperledger/fabric/common/config/channel"
+	mockcrypto "github.com/hyperledger/fabric/common/mocks/crypto"
+	"github.com/hyperledger/fabric/orderer/common/ledger"
+	ramledger "github.com/hyperledger/fabric/orderer/common/ledger/ram"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+const systemChannel = "systemchannel"
+
+// deliverServer serves the blocks of its chains over the Deliver API
+type deliverServer struct {
+	chains map[string][]*cb.Block
+}
+
+func (s *deliverServer) Broadcast(srv ab.AtomicBroadcast_BroadcastServer) error {
+	return fmt.Errorf("not implemented")
+}
+
+func (s *deliverServer) Deliver(srv ab.AtomicBroadcast_DeliverServer) error {
+	env, err := srv.Recv()
+	if err != nil {
+		return err
+	}
+	payload, err := utils.UnmarshalPayload(env.Payload)
+	if err != nil {
+		return err
+	}
+	chdr, err := utils.UnmarshalChannelHeader(payload.Header.ChannelHeader)
+	if err != nil {
+		return err
+	}
+	seekInfo := &ab.SeekInfo{}
+	if err := proto.Unmarshal(payload.Data, seekInfo); err != nil {
+		return err
+	}
+
+	blocks := s.chains[chdr.ChannelId]
+	position := func(p *ab.SeekPosition) uint64 {
+		switch t := p.Type.(type) {
+		case *ab.SeekPosition_Newest:
+			return uint64(len(blocks)) - 1
+		case *ab.SeekPosition_Specified:
+			return t.Specified.Number
+		}
+		return 0
+	}
+	start, stop := position(seekInfo.Start), position(seekInfo.Stop)
+	if len(blocks) == 0 || stop >= uint64(len(blocks)) {
+		return srv.Send(&ab.DeliverResponse{Type: &ab.DeliverResponse_Status{Status: cb.Status_NOT_FOUND}})
+	}
+	for i := start; i <= stop; i++ {
+		if err := srv.Send(&ab.DeliverResponse{Type: &ab.DeliverResponse_Block{Block: blocks[i]}}); err != nil {
+			return err
+		}
+	}
+	return srv.Send(&ab.DeliverResponse{Type: &ab.DeliverResponse_Status{Status: cb.Status_SUCCESS}})
+}
+
+func startDeliverServer(t *testing.T, chains map[string][]*cb.Block) (string, func()) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := grpc.NewServer()
+	ab.RegisterAtomicBroadcastServer(server, &deliverServer{chains: chains})
+	go server.Serve(listener)
+	return listener.Addr().String(), server.Stop
+}
+
+func envelope(headerType cb.HeaderType, channel string, data []byte) *cb.Envelope {
+	return &cb.Envelope{Payload: utils.MarshalOrPanic(&cb.Payload{
+		Header: &cb.Header{ChannelHeader: utils.MarshalOrPanic(&cb.ChannelHeader{Type: int32(headerType), ChannelId: channel})},
+		Data:   data,
+	})}
+}
+
+func configEnvelope(channel string, endpoint string) *cb.Envelope {
+	config := &cb.ConfigEnvelope{Config: &cb.Config{ChannelGroup: &cb.ConfigGroup{
+		Values: map[string]*cb.ConfigValue{
+			channelconfig.OrdererAddressesKey: {Value: utils.MarshalOrPanic(&cb.OrdererAddresses{Addresses: []string{endpoint}})},
+		},
+	}}}
+	return envelope(cb.HeaderType_CONFIG, channel, utils.MarshalOrPanic(config))
+}
+
+func nextBlock(previous *cb.Block, envs ...*cb.Envelope) *cb.Block {
+	data := &cb.BlockData{}
+	for _, env := range envs {
+		data.Data = append(data.Data, utils.MarshalOrPanic(env))
+	}
+	block := cb.NewBlock(0, nil)
+	if previous != nil {
+		block = cb.NewBlock(previous.Header.Number+1, previous.Header.Hash())
+	}
+	block.Header.DataHash = data.Hash()
+	block.Data = data
+	return block
+}
+
+// testChains returns a system channel which created the channels foo and bar, and the chain of foo
+func testChains(endpoint string) map[string][]*cb.Block {
+	fooConfig := envelope(cb.HeaderType_CONFIG, "foo", []byte("foo config"))
+	barConfig := envelope(cb.HeaderType_CONFIG, "bar", []byte("bar config"))
+
+	system := []*cb.Block{nextBlock(nil, configEnvelope(systemChannel, endpoint))}
+	system = append(system, nextBlock(system[0], envelope(cb.HeaderType_ORDERER_TRANSACTION, systemChannel, utils.MarshalOrPanic(fooConfig))))
+	system = append(system, nextBlock(system[1], envelope(cb.HeaderType_ORDERER_TRANSACTION, systemChannel, utils.MarshalOrPanic(barConfig))))
+	system = append(system, nextBlock(system[2], configEnvelope(systemChannel, endpoint)))
+
+	foo := []*cb.Block{nextBlock(nil, fooConfig)}
+	for i := 1; i < 4; i++ {
+		foo = append(foo, nextBlock(foo[i-1], envelope(cb.HeaderType_ENDORSER_TRANSACTION, "foo", []byte{byte(i)})))
+	}
+	return map[string][]*cb.Block{systemChannel: system, "foo": foo}
+}
+
+func newTestReplicator(t *testing.T, bootBlock *cb.Block, lf ledger.Factory) *Replicator {
+	dialer, err := NewDialer(nil, nil)
+	require.NoError(t, err)
+	r, err := NewReplicator(bootBlock, lf, &mockcrypto.LocalSigner{}, dialer, time.Millisecond, 1)
+	require.NoError(t, err)
+	return r
+}
+
+func TestReplicateChains(t *testing.T) {
+	chains := make(map[string][]*cb.Block)
+	endpoint, stop := startDeliverServer(t, chains)
+	defer stop()
+	for channel, blocks := range testChains(endpoint) {
+		chains[channel] = blocks
+	}
+
+	bootBlock := chains[systemChannel][3]
+	lf := ramledger.New(10)
+	r := newTestReplicator(t, bootBlock, lf)
+	assert.Equal(t, systemChannel, r.Channel)
+	assert.Equal(t, []string{endpoint}, r.Endpoints)
+
+	needed, err := r.IsReplicationNeeded()
+	require.NoError(t, err)
+	assert.True(t, needed)
+
+	require.NoError(t, r.ReplicateChains())
+	needed, err = r.IsReplicationNeeded()
+	require.NoError(t, err)
+	assert.False(t, needed)
+
+	// bar could not be pulled, so the orderer does not serve it
+	chainIDs := lf.ChainIDs()
+	sort.Strings(chainIDs)
+	assert.Equal(t, []string{"foo", systemChannel}, chainIDs)
+	for _, channel := range []string{systemChannel, "foo"} {
+		chainLedger, err := lf.GetOrCreate(channel)
+		require.NoError(t, err)
+		require.Equal(t, uint64(len(chains[channel])), chainLedger.Height())
+		for i, block := range chains[channel] {
+			assert.Equal(t, block.Header.Hash(), ledger.GetBlock(chainLedger, uint64(i)).Header.Hash())
+		}
+	}
+}
+
+func TestReplicateChainsBootBlockMismatch(t *testing.T) {
+	chains := testChains("orderer:7050")
+	endpoint, stop := startDeliverServer(t, chains)
+	defer stop()
+
+	// the boot block is not the one the orderers wrote
+	bootBlock := nextBlock(chains[systemChannel][2], configEnvelope(systemChannel, endpoint))
+	lf := ramledger.New(10)
+	r := newTestReplicator(t, bootBlock, lf)
+	assert.Error(t, r.ReplicateChains())
+
+	systemLedger, err := lf.GetOrCreate(systemChannel)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), systemLedger.Height())
+}
+
+func TestReplicateChainsUnreachable(t *testing.T) {
+	chains := testChains("orderer:7050")
+	endpoint, stop := startDeliverServer(t, chains)
+	stop()
+
+	r := newTestReplicator(t, chains[systemChannel][3], ramledger.New(10))
+	r.Endpoints = []string{endpoint}
+	assert.Error(t, r.ReplicateChains())
+}
+
+func TestIsReplicationNeededGenesis(t *testing.T) {
+	chains := testChains("orderer:7050")
+	r := newTestReplicator(t, chains[systemChannel][0], ramledger.New(10))
+	needed, err := r.IsReplicationNeeded()
+	require.NoError(t, err)
+	assert.False(t, needed)
+}
+
+func TestVerifyBlock(t *testing.T) {
+	chains := testChains("orderer:7050")
+	blocks := chains["foo"]
+	assert.NoError(t, VerifyBlock(blocks[0], blocks[1]))
+	assert.Error(t, VerifyBlock(blocks[0], blocks[2]))
+
+	tampered := proto.Clone(blocks[2]).(*cb.Block)
+	tampered.Data.Data[0] = []byte("tampered")
+	assert.Error(t, VerifyBlock(blocks[1], tampered))
+}
@@ -7,6 +7,8 @@ SPDX-License-Identifier: Apache-2.0
 package server
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"io/ioutil"
 	"log"
@@ -14,26 +16,36 @@ import (
 	"net/http"
 	_ "net/http/pprof" // This is essentially the main package for the orderer
 	"os"
+	"time"
 
+	channelconfig "github.com/hyperledger/fabric/common/config/channel"
 	"github.com/hyperledger/fabric/common/crypto"
 	"github.com/hyperledger/fabric/common/flogging"
 	genesisconfig "github.com/hyperledger/fabric/common/tools/configtxgen/localconfig"
 	"github.com/hyperledger/fabric/common/tools/configtxgen/provisional"
 	"github.com/hyperledger/fabric/core/comm"
+	"github.com/hyperledger/fabric/core/operations"
+	"github.com/hyperledger/fabric/orderer/common/blockcutter"
 	"github.com/hyperledger/fabric/orderer/common/bootstrap/file"
+	"github.com/hyperledger/fabric/orderer/common/channelparticipation"
 	"github.com/hyperledger/fabric/orderer/common/ledger"
 	"github.com/hyperledger/fabric/orderer/common/localconfig"
 	"github.com/hyperledger/fabric/orderer/common/metadata"
+	"github.com/hyperledger/fabric/orderer/common/msgprocessor"
 	"github.com/hyperledger/fabric/orderer/common/multichannel"
+	"github.com/hyperledger/fabric/orderer/common/replication"
 	"github.com/hyperledger/fabric/orderer/consensus"
+	"github.com/hyperledger/fabric/orderer/consensus/etcdraft"
 	"github.com/hyperledger/fabric/orderer/consensus/kafka"
 	"github.com/hyperledger/fabric/orderer/consensus/solo"
 	cb "github.com/hyperledger/fabric/protos/common"
 	ab "github.com/hyperledger/fabric/protos/orderer"
+	etcdraftpb "github.com/hyperledger/fabric/protos/orderer/etcdraft"
 	"github.com/hyperledger/fabric/protos/utils"
 
 	"github.com/Shopify/sarama"
 	"github.com/hyperledger/fabric/common/localmsp"
+	"github.com/hyperledger/fabric/common/metrics"
 	mspmgmt "github.com/hyperledger/fabric/msp/mgmt"
 	"github.com/hyperledger/fabric/orderer/common/performance"
 	"github.com/op/go-logging"
@@ -63,7 +75,9 @@ func Main() {
 
 	conf := config.Load()
 	initializeLoggingLevel(conf)
+	initializeMetrics(conf)
 	initializeLocalMsp(conf)
+	initializeLocalMspRefresh(conf)
 
 	Start(fullCmd, conf)
 }
@@ -71,15 +85,20 @@ func Main() {
 // Start provides a layer of abstraction for benchmark test
 func Start(cmd string, conf *config.TopLevel) {
 	signer := localmsp.NewSigner()
-	manager := initializeMultichannelRegistrar(conf, signer)
-	server := NewServer(manager, signer, &conf.Debug)
+	raftConsenter := initializeEtcdRaftConsenter(conf)
+	manager := initializeMultichannelRegistrar(conf, signer, raftConsenter)
+	ingressRules := initializeIngressRules(conf, manager)
+	server := NewServer(manager, signer, &conf.Debug, &conf.General.FairOrdering, &conf.General.Backpressure, &conf.General.OrderingReceipts, &conf.General.ResourceLimits, ingressRules...)
 
 	switch cmd {
 	case start.FullCommand(): // "start" command
 		logger.Infof("Starting %s", metadata.GetVersionInfo())
 		initializeProfilingService(conf)
+		initializeChannelParticipationService(conf, manager)
+		initializeOperationsSystem(conf, manager)
 		grpcServer := initializeGrpcServer(conf)
 		ab.RegisterAtomicBroadcastServer(grpcServer.Server(), server)
+		etcdraftpb.RegisterClusterServer(grpcServer.Server(), raftConsenter)
 		logger.Info("Beginning to serve requests")
 		grpcServer.Start()
 	case benchmark.FullCommand(): // "benchmark" command
@@ -90,14 +109,26 @@ func Start(cmd string, conf *config.TopLevel) {
 	}
 }
 
-// Set the logging level
+// Set the logging format and level
 func initializeLoggingLevel(conf *config.TopLevel) {
+	if conf.General.LogFormat != "" {
+		flogging.InitBackend(flogging.SetFormat(conf.General.LogFormat), os.Stderr)
+	}
 	flogging.InitFromSpec(conf.General.LogLevel)
 	if conf.Kafka.Verbose {
 		sarama.Logger = log.New(os.Stdout, "[sarama] ", log.Ldate|log.Lmicroseconds|log.Lshortfile)
 	}
 }
 
+// Report the metrics to the configured reporter
+func initializeMetrics(conf *config.TopLevel) {
+	opts := metrics.Opts{Reporter: conf.General.Metrics.Reporter, Interval: conf.General.Metrics.Interval}
+	if err := metrics.SetReporter(opts); err != nil {
+		logger.Panicf("Failed to initialize metrics: %s", err)
+	}
+	logger.Infof("Reporting metrics to the %s reporter", opts.Reporter)
+}
+
 // Start the profiling service if enabled.
 func initializeProfilingService(conf *config.TopLevel) {
 	if conf.General.Profile.Enabled {
@@ -109,6 +140,74 @@ func initializeProfilingService(conf *config.TopLevel) {
 	}
 }
 
+// Start the channel participation service if enabled.
+func initializeChannelParticipationService(conf *config.TopLevel, registrar *multichannel.Registrar) {
+	if !conf.General.ChannelParticipation.Enabled {
+		return
+	}
+	server := &http.Server{
+		Addr:    conf.General.ChannelParticipation.ListenAddress,
+		Handler: channelparticipation.NewHTTPHandler(registrar, conf.General.ChannelParticipation.MaxRequestBodySize),
+	}
+	if !conf.General.TLS.Enabled {
+		go func() {
+			logger.Info("Starting channel participation service on:", server.Addr)
+			logger.Panic("Channel participation service failed:", server.ListenAndServe())
+		}()
+		return
+	}
+
+	// the administrators are authenticated by their client TLS certificates
+	certificate, err := tls.LoadX509KeyPair(conf.General.TLS.Certificate, conf.General.TLS.PrivateKey)
+	if err != nil {
+		logger.Fatalf("Failed to load TLS key pair: %s", err)
+	}
+	clientCAs := x509.NewCertPool()
+	for _, clientRoot := range conf.General.TLS.ClientRootCAs {
+		root, err := ioutil.ReadFile(clientRoot)
+		if err != nil {
+			logger.Fatalf("Failed to load ClientRootCAs file '%s' (%s)", clientRoot, err)
+		}
+		if !clientCAs.AppendCertsFromPEM(root) {
+			logger.Fatalf("Failed to parse ClientRootCAs file '%s'", clientRoot)
+		}
+	}
+	server.TLSConfig = &tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    clientCAs,
+	}
+	go func() {
+		logger.Info("Starting channel participation service with TLS on:", server.Addr)
+		logger.Panic("Channel participation service failed:", server.ListenAndServeTLS("", ""))
+	}()
+}
+
+// Start the operations server if enabled, reporting the health of the consenters of the channels.
+func initializeOperationsSystem(conf *config.TopLevel, registrar *multichannel.Registrar) *operations.System {
+	if !conf.General.Operations.Enabled {
+		return nil
+	}
+	system := operations.NewSystem(operations.Options{
+		ListenAddress:      conf.General.Operations.ListenAddress,
+		HealthCheckTimeout: conf.General.Operations.HealthCheckTimeout,
+		TLS: operations.TLS{
+			Enabled:            conf.General.Operations.TLS.Enabled,
+			CertFile:           conf.General.Operations.TLS.Certificate,
+			KeyFile:            conf.General.Operations.TLS.PrivateKey,
+			ClientCertRequired: conf.General.Operations.TLS.ClientAuthRequired,
+			ClientRootCAs:      conf.General.Operations.TLS.ClientRootCAs,
+		},
+	})
+	if err := system.RegisterReadinessChecker("consenters", registrar); err != nil {
+		logger.Panicf("Failed to register the health checker of the consenters: %s", err)
+	}
+	if err := system.Start(); err != nil {
+		logger.Panicf("Failed to start the operations server: %s", err)
+	}
+	return system
+}
+
 func initializeSecureServerConfig(conf *config.TopLevel) comm.SecureServerConfig {
 	// secure server config
 	secureConfig := comm.SecureServerConfig{
@@ -184,16 +283,86 @@ func initializeBootstrapChannel(conf *config.TopLevel, lf ledger.Factory) {
 	}
 }
 
+// replicateChains pulls the chains from the other orderers when the orderer is bootstrapped with
+// a config block of the system channel rather than with its genesis block, unless it did already
+func replicateChains(conf *config.TopLevel, lf ledger.Factory, signer crypto.LocalSigner, bootBlock *cb.Block) {
+	replicator, err := replication.NewReplicator(bootBlock, lf, signer, initializeReplicationDialer(conf),
+		conf.General.Replication.RetryInterval, conf.General.Replication.MaxRetries)
+	if err != nil {
+		logger.Fatalf("Invalid boot block: %s", err)
+	}
+	needed, err := replicator.IsReplicationNeeded()
+	if err != nil {
+		logger.Fatalf("Failed to read the system channel: %s", err)
+	}
+	if !needed {
+		return
+	}
+	logger.Infof("Boot block is block %d of system channel %s, replicating the chains from %v",
+		bootBlock.Header.Number, replicator.Channel, replicator.Endpoints)
+	if err := replicator.ReplicateChains(); err != nil {
+		logger.Fatalf("Failed to replicate the chains: %s", err)
+	}
+}
+
+// chainReplicator pulls the blocks preceding the config block a channel is joined with
+func chainReplicator(conf *config.TopLevel, lf ledger.Factory, signer crypto.LocalSigner) multichannel.ChainReplicator {
+	dialer := initializeReplicationDialer(conf)
+	return func(configBlock *cb.Block) error {
+		replicator, err := replication.NewReplicator(configBlock, lf, signer, dialer,
+			conf.General.Replication.RetryInterval, conf.General.Replication.MaxRetries)
+		if err != nil {
+			return err
+		}
+		return replicator.ReplicateChain()
+	}
+}
+
+func initializeReplicationDialer(conf *config.TopLevel) replication.Dialer {
+	var certificate *tls.Certificate
+	var rootCAs [][]byte
+	if conf.General.TLS.Enabled {
+		keyPair, err := tls.LoadX509KeyPair(conf.General.TLS.Certificate, conf.General.TLS.PrivateKey)
+		if err != nil {
+			logger.Fatalf("Failed to load TLS key pair: %s", err)
+		}
+		certificate = &keyPair
+		for _, rootCAFile := range conf.General.TLS.RootCAs {
+			rootCA, err := ioutil.ReadFile(rootCAFile)
+			if err != nil {
+				logger.Fatalf("Failed to load RootCAs file '%s' (%s)", rootCAFile, err)
+			}
+			rootCAs = append(rootCAs, rootCA)
+		}
+	}
+	dialer, err := replication.NewDialer(certificate, rootCAs)
+	if err != nil {
+		logger.Fatalf("Failed to create replication dialer: %s", err)
+	}
+	return dialer
+}
+
 func initializeGrpcServer(conf *config.TopLevel) comm.GRPCServer {
 	secureConfig := initializeSecureServerConfig(conf)
 
-	lis, err := net.Listen("tcp", fmt.Sprintf("%s:%d", conf.General.ListenAddress, conf.General.ListenPort))
-	if err != nil {
-		logger.Fatal("Failed to listen:", err)
+	address := fmt.Sprintf("%s:%d", conf.General.ListenAddress, conf.General.ListenPort)
+	var listeners []net.Listener
+	if conf.General.ReusePortListeners > 1 {
+		var err error
+		listeners, err = comm.ListenReusePort(address, conf.General.ReusePortListeners)
+		if err != nil {
+			logger.Fatal("Failed to listen:", err)
+		}
+	} else {
+		lis, err := net.Listen("tcp", address)
+		if err != nil {
+			logger.Fatal("Failed to listen:", err)
+		}
+		listeners = []net.Listener{lis}
 	}
 
 	// Create GRPC server - return if an error occurs
-	grpcServer, err := comm.NewGRPCServerFromListener(lis, secureConfig)
+	grpcServer, err := comm.NewGRPCServerFromListeners(listeners, secureConfig)
 	if err != nil {
 		logger.Fatal("Failed to return new GRPC server:", err)
 	}
@@ -209,10 +378,42 @@ func initializeLocalMsp(conf *config.TopLevel) {
 	}
 }
 
-func initializeMultichannelRegistrar(conf *config.TopLevel, signer crypto.LocalSigner) *multichannel.Registrar {
+// Periodically read the local MSP folder again if enabled, so that updated
+// CRLs and intermediate certificates take effect without a restart.
+func initializeLocalMspRefresh(conf *config.TopLevel) {
+	if conf.General.LocalMSPRefresh <= 0 {
+		return
+	}
+	logger.Infof("Refreshing the local MSP from %s every %s", conf.General.LocalMSPDir, conf.General.LocalMSPRefresh)
+	go func() {
+		for range time.Tick(conf.General.LocalMSPRefresh) {
+			if err := mspmgmt.RefreshLocalMsp(); err != nil {
+				logger.Errorf("Failed to refresh local MSP: %s", err)
+			}
+		}
+	}()
+}
+
+func initializeEtcdRaftConsenter(conf *config.TopLevel) *etcdraft.Consenter {
+	raftConsenter, err := etcdraft.New(conf)
+	if err != nil {
+		logger.Fatalf("Failed to initialize etcd/raft consenter: %s", err)
+	}
+	return raftConsenter
+}
+
+func initializeMultichannelRegistrar(conf *config.TopLevel, signer crypto.LocalSigner, raftConsenter *etcdraft.Consenter) *multichannel.Registrar {
 	lf, _ := createLedgerFactory(conf)
+	// Are we joining an existing ordering service?
+	if conf.General.GenesisMethod == "file" {
+		if bootBlock := file.New(conf.General.GenesisFile).GenesisBlock(); bootBlock.Header.Number > 0 {
+			replicateChains(conf, lf, signer, bootBlock)
+		}
+	}
 	// Are we bootstrapping?
-	if len(lf.ChainIDs()) == 0 {
+	if conf.General.GenesisMethod == "none" {
+		logger.Info("Not bootstrapping a system channel, channels are joined through the channel participation API")
+	} else if len(lf.ChainIDs()) == 0 {
 		initializeBootstrapChannel(conf, lf)
 	} else {
 		logger.Info("Not bootstrapping because of existing chains")
@@ -220,7 +421,106 @@ func initializeMultichannelRegistrar(conf *config.TopLevel, signer crypto.LocalS
 
 	consenters := make(map[string]consensus.Consenter)
 	consenters["solo"] = solo.New()
-	consenters["kafka"] = kafka.New(conf.Kafka.TLS, conf.Kafka.Retry, conf.Kafka.Version)
+	consenters["kafka"] = kafka.New(conf.Kafka.TLS, conf.Kafka.Retry, conf.Kafka.Version, conf.Kafka.Checkpoint)
+	consenters[etcdraft.ConsensusType] = raftConsenter
+
+	for _, rulePlugin := range conf.General.RulePlugins {
+		if err := msgprocessor.LoadRulePlugin(rulePlugin.Name, rulePlugin.Path); err != nil {
+			logger.Panicf("Failed to load message filter rule %s: %s", rulePlugin.Name, err)
+		}
+		logger.Infof("Loaded message filter rule %s from %s", rulePlugin.Name, rulePlugin.Path)
+	}
+
+	cuttingPolicy := initializeCuttingPolicy(conf)
 
-	return multichannel.NewRegistrar(lf, consenters, signer)
+	registrarSigner := initializeChannelSigners(conf, signer)
+
+	var registrar *multichannel.Registrar
+	if conf.General.ChannelParticipation.Enabled {
+		registrar = multichannel.NewParticipationRegistrar(lf, consenters, registrarSigner, cuttingPolicy, chainReplicator(conf, lf, signer))
+	} else {
+		registrar = multichannel.NewRegistrar(lf, consenters, registrarSigner, cuttingPolicy)
+	}
+	if maxChannels := conf.General.ResourceLimits.MaxChannels; maxChannels > 0 {
+		logger.Infof("Limiting the number of channels to %d", maxChannels)
+		registrar.SetMaxChannels(maxChannels)
+	}
+	return registrar
+}
+
+// initializeIngressRules creates the rules applied to the messages broadcast to the orderer when they
+// are received. They depend on the local config and state of the orderer, so they are not part of the
+// filters of the channels, which the consenters apply again to the messages they order
+func initializeIngressRules(conf *config.TopLevel, registrar *multichannel.Registrar) []msgprocessor.Rule {
+	// The rules named in the config of the channels may come from plugins this orderer loaded
+	rules := []msgprocessor.Rule{msgprocessor.NewIngressConfiguredRules(func(channelID string) (channelconfig.Resources, bool) {
+		cs, ok := registrar.GetChain(channelID)
+		if !ok {
+			// The messages creating a channel are broadcast to the system channel
+			if cs, ok = registrar.GetChain(registrar.SystemChannelID()); !ok {
+				return nil, false
+			}
+		}
+		return cs, true
+	})}
+	if expiration := conf.General.EnvelopeExpiration; expiration.Enabled {
+		logger.Infof("Rejecting the messages whose timestamp is more than %v away from the orderer time, or whose epoch is more than %d blocks old",
+			expiration.TimeWindow, expiration.EpochWindow)
+		rules = append(rules, msgprocessor.NewEnvelopeExpirationFilter(expiration.TimeWindow, expiration.EpochWindow, func(channelID string) (uint64, bool) {
+			cs, ok := registrar.GetChain(channelID)
+			if !ok {
+				return 0, false
+			}
+			return cs.Height(), true
+		}))
+	}
+	if conf.General.RateLimit.Enabled {
+		logger.Infof("Limiting the broadcast rate of each client to %v messages per second, with bursts of %d messages",
+			conf.General.RateLimit.EnvelopesPerSecond, conf.General.RateLimit.Burst)
+		rules = append(rules, msgprocessor.NewRateLimitFilter(conf.General.RateLimit.EnvelopesPerSecond, conf.General.RateLimit.Burst))
+	}
+	if limits := conf.General.ResourceLimits; limits.ChannelBytesPerSecond > 0 {
+		logger.Infof("Limiting the broadcast bandwidth of each channel to %d bytes per second, with bursts of %d bytes",
+			limits.ChannelBytesPerSecond, limits.ChannelBurstBytes)
+		rules = append(rules, msgprocessor.NewChannelBandwidthFilter(float64(limits.ChannelBytesPerSecond), limits.ChannelBurstBytes))
+	}
+	return rules
+}
+
+// initializeChannelSigners returns a signer which signs for the channels of
+// General.ChannelSigners with their own identities, and for the other channels
+// with the local MSP signer
+func initializeChannelSigners(conf *config.TopLevel, signer crypto.LocalSigner) crypto.LocalSigner {
+	if len(conf.General.ChannelSigners) == 0 {
+		return signer
+	}
+	signers := make(map[string]crypto.LocalSigner)
+	for _, channelSigner := range conf.General.ChannelSigners {
+		if _, exists := signers[channelSigner.Channel]; exists {
+			logger.Panicf("Channel %s has more than one signer", channelSigner.Channel)
+		}
+		s, err := localmsp.NewSignerFromMSPDir(channelSigner.MSPDir, channelSigner.BCCSP, channelSigner.MSPID)
+		if err != nil {
+			logger.Panicf("Failed to load the signer of channel %s from %s: %s", channelSigner.Channel, channelSigner.MSPDir, err)
+		}
+		logger.Infof("Signing for channel %s with the identity of MSP %s in %s", channelSigner.Channel, channelSigner.MSPID, channelSigner.MSPDir)
+		signers[channelSigner.Channel] = s
+	}
+	return multichannel.NewChannelSigner(signer, signers)
+}
+
+func initializeCuttingPolicy(conf *config.TopLevel) blockcutter.PolicyFactory {
+	name := conf.General.BlockCutter.Policy
+	if path := conf.General.BlockCutter.PluginPath; path != "" {
+		if err := blockcutter.LoadPolicyPlugin(name, path); err != nil {
+			logger.Panicf("Failed to load cutting policy %s: %s", name, err)
+		}
+		logger.Infof("Loaded cutting policy %s from %s", name, path)
+	}
+	cuttingPolicy, ok := blockcutter.LookupPolicy(name)
+	if !ok {
+		logger.Panicf("Cutting policy %s is not registered with this orderer", name)
+	}
+	logger.Infof("Cutting the messages of every channel into batches with the %s policy", name)
+	return cuttingPolicy
 }
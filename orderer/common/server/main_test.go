@@ -21,8 +21,12 @@ import (
 	"github.com/hyperledger/fabric/bccsp/factory"
 	"github.com/hyperledger/fabric/common/flogging"
 	"github.com/hyperledger/fabric/common/localmsp"
+	"github.com/hyperledger/fabric/common/metrics"
 	"github.com/hyperledger/fabric/common/tools/configtxgen/provisional"
 	coreconfig "github.com/hyperledger/fabric/core/config"
+	"github.com/hyperledger/fabric/core/operations"
+	mspmgmt "github.com/hyperledger/fabric/msp/mgmt"
+	"github.com/hyperledger/fabric/orderer/common/blockcutter"
 	"github.com/hyperledger/fabric/orderer/common/localconfig"
 	"github.com/op/go-logging"
 	"github.com/stretchr/testify/assert"
@@ -211,6 +215,32 @@ func TestInitializeLocalMsp(t *testing.T) {
 	})
 }
 
+func TestInitializeLocalMspRefresh(t *testing.T) {
+	localMSPDir, _ := coreconfig.GetDevMspDir()
+	conf := &config.TopLevel{
+		General: config.General{
+			LocalMSPDir: localMSPDir,
+			LocalMSPID:  "DEFAULT",
+			BCCSP: &factory.FactoryOpts{
+				ProviderName: "SW",
+				SwOpts: &factory.SwOpts{
+					HashFamily: "SHA2",
+					SecLevel:   256,
+					Ephemeral:  true,
+				},
+			},
+		},
+	}
+	initializeLocalMsp(conf)
+	assert.NotPanics(t, func() { initializeLocalMspRefresh(conf) }, "A disabled refresh should be a no-op")
+
+	conf.General.LocalMSPRefresh = 10 * time.Millisecond
+	assert.NotPanics(t, func() { initializeLocalMspRefresh(conf) })
+	time.Sleep(50 * time.Millisecond)
+	_, err := mspmgmt.GetLocalMSP().GetDefaultSigningIdentity()
+	assert.NoError(t, err, "The local MSP should still be usable after being refreshed")
+}
+
 func TestInitializeMultiChainManager(t *testing.T) {
 	localMSPDir, _ := coreconfig.GetDevMspDir()
 	conf := &config.TopLevel{
@@ -229,14 +259,99 @@ func TestInitializeMultiChainManager(t *testing.T) {
 					Ephemeral:  true,
 				},
 			},
+			Replication: config.Replication{PullInterval: 10 * time.Second},
+			BlockCutter: config.BlockCutter{Policy: blockcutter.BatchSizePolicyName},
 		},
 	}
 	assert.NotPanics(t, func() {
 		initializeLocalMsp(conf)
-		initializeMultichannelRegistrar(conf, localmsp.NewSigner())
+		initializeMultichannelRegistrar(conf, localmsp.NewSigner(), initializeEtcdRaftConsenter(conf))
 	})
 }
 
+func TestInitializeMultiChainManagerWithoutSystemChannel(t *testing.T) {
+	localMSPDir, _ := coreconfig.GetDevMspDir()
+	conf := &config.TopLevel{
+		General: config.General{
+			LedgerType:    "ram",
+			GenesisMethod: "none",
+			LocalMSPDir:   localMSPDir,
+			LocalMSPID:    "DEFAULT",
+			BCCSP: &factory.FactoryOpts{
+				ProviderName: "SW",
+				SwOpts: &factory.SwOpts{
+					HashFamily: "SHA2",
+					SecLevel:   256,
+					Ephemeral:  true,
+				},
+			},
+			Replication:          config.Replication{PullInterval: 10 * time.Second},
+			ChannelParticipation: config.ChannelParticipation{Enabled: true},
+			BlockCutter:          config.BlockCutter{Policy: blockcutter.BatchSizePolicyName},
+		},
+	}
+	initializeLocalMsp(conf)
+	registrar := initializeMultichannelRegistrar(conf, localmsp.NewSigner(), initializeEtcdRaftConsenter(conf))
+	assert.Empty(t, registrar.ChannelList())
+	assert.Equal(t, "", registrar.SystemChannelID())
+}
+
+func TestInitializeOperationsSystem(t *testing.T) {
+	localMSPDir, _ := coreconfig.GetDevMspDir()
+	conf := &config.TopLevel{
+		General: config.General{
+			LedgerType:    "ram",
+			GenesisMethod: "none",
+			LocalMSPDir:   localMSPDir,
+			LocalMSPID:    "DEFAULT",
+			BCCSP: &factory.FactoryOpts{
+				ProviderName: "SW",
+				SwOpts: &factory.SwOpts{
+					HashFamily: "SHA2",
+					SecLevel:   256,
+					Ephemeral:  true,
+				},
+			},
+			Replication:          config.Replication{PullInterval: 10 * time.Second},
+			ChannelParticipation: config.ChannelParticipation{Enabled: true},
+			BlockCutter:          config.BlockCutter{Policy: blockcutter.BatchSizePolicyName},
+		},
+	}
+	initializeLocalMsp(conf)
+	registrar := initializeMultichannelRegistrar(conf, localmsp.NewSigner(), initializeEtcdRaftConsenter(conf))
+	assert.Nil(t, initializeOperationsSystem(conf, registrar), "Expected no operations server when disabled")
+
+	conf.General.Operations = config.Operations{Enabled: true, ListenAddress: "127.0.0.1:0", HealthCheckTimeout: time.Second}
+	system := initializeOperationsSystem(conf, registrar)
+	assert.NotNil(t, system)
+	defer system.Stop()
+	resp, err := http.Get("http://" + system.Addr() + operations.URLReadiness)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestInitializeCuttingPolicy(t *testing.T) {
+	conf := &config.TopLevel{General: config.General{BlockCutter: config.BlockCutter{Policy: blockcutter.BatchSizePolicyName}}}
+	assert.NotNil(t, initializeCuttingPolicy(conf))
+
+	conf.General.BlockCutter.Policy = "Missing"
+	assert.Panics(t, func() { initializeCuttingPolicy(conf) }, "Expected a panic for an unregistered cutting policy")
+
+	conf.General.BlockCutter.PluginPath = "/does/not/exist.so"
+	assert.Panics(t, func() { initializeCuttingPolicy(conf) }, "Expected a panic for a missing cutting policy plugin")
+}
+
+func TestInitializeMetrics(t *testing.T) {
+	conf := &config.TopLevel{General: config.General{Metrics: config.Metrics{Reporter: metrics.LogReporterName, Interval: time.Minute}}}
+	assert.NotPanics(t, func() { initializeMetrics(conf) })
+	conf.General.Metrics.Reporter = metrics.NullReporterName
+	assert.NotPanics(t, func() { initializeMetrics(conf) })
+
+	conf.General.Metrics.Reporter = "Missing"
+	assert.Panics(t, func() { initializeMetrics(conf) }, "Expected a panic for an unregistered metrics reporter")
+}
+
 func TestInitializeGrpcServer(t *testing.T) {
 	// get a free random port
 	listenAddr := func() string {
@@ -17,6 +17,7 @@ import (
 	"github.com/hyperledger/fabric/orderer/common/broadcast"
 	"github.com/hyperledger/fabric/orderer/common/deliver"
 	localconfig "github.com/hyperledger/fabric/orderer/common/localconfig"
+	"github.com/hyperledger/fabric/orderer/common/msgprocessor"
 	"github.com/hyperledger/fabric/orderer/common/multichannel"
 	cb "github.com/hyperledger/fabric/protos/common"
 	ab "github.com/hyperledger/fabric/protos/orderer"
@@ -32,6 +33,14 @@ func (bs broadcastSupport) BroadcastChannelSupport(msg *cb.Envelope) (*cb.Channe
 	return bs.Registrar.BroadcastChannelSupport(msg)
 }
 
+func (bs broadcastSupport) ReceiptSupport(channelID string) (broadcast.ReceiptSupport, bool) {
+	cs, ok := bs.Registrar.GetChain(channelID)
+	if !ok {
+		return nil, false
+	}
+	return cs, true
+}
+
 type deliverSupport struct {
 	*multichannel.Registrar
 }
@@ -46,11 +55,32 @@ type server struct {
 	debug *localconfig.Debug
 }
 
-// NewServer creates an ab.AtomicBroadcastServer based on the broadcast target and ledger Reader
-func NewServer(r *multichannel.Registrar, signer crypto.LocalSigner, debug *localconfig.Debug) ab.AtomicBroadcastServer {
+// NewServer creates an ab.AtomicBroadcastServer based on the broadcast target and ledger Reader.
+// The ingress rules are applied to the messages broadcast to the server when they are received
+func NewServer(r *multichannel.Registrar, signer crypto.LocalSigner, debug *localconfig.Debug, fairOrdering *localconfig.FairOrdering, backpressure *localconfig.Backpressure, orderingReceipts *localconfig.OrderingReceipts, resourceLimits *localconfig.ResourceLimits, ingressRules ...msgprocessor.Rule) ab.AtomicBroadcastServer {
+	var bs broadcast.ChannelSupportRegistrar = broadcastSupport{Registrar: r}
+	if len(ingressRules) > 0 {
+		bs = broadcast.NewIngressFilterRegistrar(bs, ingressRules...)
+	}
+	if fairOrdering.Enabled {
+		logger.Infof("Fair ordering enabled with a reordering window of %v and at most %d messages", fairOrdering.Window, fairOrdering.MaxMessages)
+		bs = broadcast.NewFairOrderingRegistrar(bs, fairOrdering.Window, fairOrdering.MaxMessages)
+	}
+	if backpressure.Enabled {
+		logger.Infof("Backpressure enabled with at most %d messages in flight per channel", backpressure.QueueDepth)
+		bs = broadcast.NewBackpressureRegistrar(bs, backpressure.QueueDepth, backpressure.RetryAfter)
+	}
+	if resourceLimits.MaxDeliverStreamsPerClient > 0 {
+		logger.Infof("Limiting the deliver streams of each client to %d", resourceLimits.MaxDeliverStreamsPerClient)
+	}
+	bh := broadcast.NewHandlerImpl(bs)
+	if orderingReceipts.Enabled {
+		logger.Infof("Ordering receipts enabled, answering the messages enqueued for ordering with signed receipts")
+		bh = broadcast.NewHandlerImplWithReceipts(bs, broadcastSupport{Registrar: r})
+	}
 	s := &server{
-		dh:    deliver.NewHandlerImpl(deliverSupport{Registrar: r}),
-		bh:    broadcast.NewHandlerImpl(broadcastSupport{Registrar: r}),
+		dh:    deliver.NewHandlerImplWithStreamLimit(deliverSupport{Registrar: r}, resourceLimits.MaxDeliverStreamsPerClient),
+		bh:    bh,
 		debug: debug,
 	}
 	return s
@@ -81,6 +81,9 @@ type ConsenterSupport interface {
 	// SharedConfig provides the shared config from the channel's current config block.
 	SharedConfig() config.Orderer
 
+	// ChannelConfig provides the channel config from the channel's current config block.
+	ChannelConfig() config.Channel
+
 	// CreateNextBlock takes a list of messages and creates the next block based on the block with highest block number committed to the ledger
 	// Note that either WriteBlock or WriteConfigBlock must be called before invoking this method a second time.
 	CreateNextBlock(messages []*cb.Envelope) *cb.Block
@@ -99,4 +102,7 @@ type ConsenterSupport interface {
 
 	// Height returns the number of blocks in the chain this channel is associated with.
 	Height() uint64
+
+	// Block returns the block with the given number, or nil if the ledger does not hold it.
+	Block(number uint64) *cb.Block
 }
@@ -0,0 +1,46 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"github.com/hyperledger/fabric/common/config/channel"
+	cb "github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/utils"
+)
+
+// blockCreator creates the blocks proposed by the leader. Unlike ConsenterSupport.CreateNextBlock,
+// it chains the blocks to the last block created rather than to the last block written, as the
+// leader creates blocks while the ones it proposed before are still being agreed upon
+type blockCreator struct {
+	lastBlock *cb.Block
+	support   channelConfigSupport
+}
+
+// channelConfigSupport provides the channel config the data hash of a new block depends on
+type channelConfigSupport interface {
+	ChannelConfig() config.Channel
+}
+
+func newBlockCreator(lastBlock *cb.Block, support channelConfigSupport) *blockCreator {
+	return &blockCreator{lastBlock: lastBlock, support: support}
+}
+
+func (bc *blockCreator) createNextBlock(messages []*cb.Envelope) *cb.Block {
+	data := &cb.BlockData{
+		Data: make([][]byte, len(messages)),
+	}
+	for i, msg := range messages {
+		data.Data[i] = utils.MarshalOrPanic(msg)
+	}
+
+	block := cb.NewBlock(bc.lastBlock.Header.Number+1, bc.lastBlock.Header.Hash())
+	block.Header.DataHash = data.HeaderDataHash(bc.support.ChannelConfig().Capabilities().MerkleDataHash())
+	block.Data = data
+
+	bc.lastBlock = block
+	return block
+}
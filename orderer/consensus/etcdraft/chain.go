@@ -0,0 +1,843 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/coreos/etcd/raft"
+	"github.com/coreos/etcd/raft/raftpb"
+	"github.com/hyperledger/fabric/common/metrics"
+	"github.com/hyperledger/fabric/orderer/common/replication"
+	"github.com/hyperledger/fabric/orderer/consensus"
+	cb "github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/orderer/etcdraft"
+	"github.com/hyperledger/fabric/protos/utils"
+
+	"golang.org/x/net/context"
+)
+
+const (
+	// maxSizePerMsg bounds the size of the entries the leader appends to a follower at once
+	maxSizePerMsg = 1024 * 1024
+
+	// senderBufferSize is the number of raft messages queued for a consenter before
+	// the consenter is reported unreachable
+	senderBufferSize = 256
+)
+
+// submission is a message submitted to the leader for ordering, along with the
+// channel on which the leader replies whether it accepted the message
+type submission struct {
+	req  *etcdraft.SubmitRequest
+	errC chan error
+}
+
+// leaderState is the state of a chain while its node is the raft leader
+type leaderState struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// ready is set once the entries of the previous terms are applied, from which
+	// point on the leader creates the blocks
+	ready         bool
+	electionIndex uint64
+	blockCreator  *blockCreator
+
+	proposeC           chan *cb.Block
+	inflightBlocks     int
+	configInflight     bool
+	confChangeInflight bool
+	transferring       bool
+
+	// createdAt holds the time the blocks in flight were created, by block number
+	createdAt map[uint64]time.Time
+}
+
+// Chain orders the messages of a channel with an etcd/raft node. The leader cuts the
+// messages into blocks, which it proposes to the raft log; every node writes the blocks
+// to its ledger once they are committed. The followers pass the messages broadcast to
+// them on to the leader. The raft IDs of the consenters are recorded, along with the
+// index of the raft entry of the block, in the orderer metadata of every block.
+//
+// The consenters of the channel are the ones listed in the consensus metadata of the
+// channel config. Once a config block adding or removing a consenter is written, the
+// leader proposes the corresponding raft configuration change. Only one consenter may be
+// added or removed by a config update. The options of the consensus metadata are read
+// when the chain starts, so a change takes effect when the orderer restarts
+type Chain struct {
+	raftID    uint64
+	channelID string
+	support   consensus.ConsenterSupport
+	rpc       RPC
+	opts      options
+	storage   *RaftStorage
+	config    *raft.Config
+	peers     []raft.Peer
+	restart   bool
+
+	node raft.Node
+
+	submitC  chan *submission
+	startC   chan struct{}
+	haltC    chan struct{}
+	doneC    chan struct{}
+	haltOnce sync.Once
+
+	// leader is the raft ID of the current leader, accessed atomically
+	leader uint64
+
+	// the state below is only accessed by the goroutine running the chain
+	raftMetadata *etcdraft.RaftMetadata
+	confState    raftpb.ConfState
+	lastBlock    *cb.Block
+	appliedIndex uint64
+	snapDataSize uint32
+	leaderState  *leaderState
+	batchTimer   <-chan time.Time
+	senders      map[uint64]chan raftpb.Message
+	halting      bool
+	removed      bool
+
+	// latency records the time the blocks the node proposed as the leader took to be committed
+	latency metrics.Timer
+}
+
+// NewChain creates the chain of the node with the given raft ID, whose last block carries
+// the given raft metadata. The node starts as a member of the initial consenters of the
+// channel if nothing was written to the channel through raft yet, and joins the consenters
+// otherwise. It is restarted from its storage if restart is true
+func NewChain(
+	support consensus.ConsenterSupport,
+	raftID uint64,
+	raftMetadata *etcdraft.RaftMetadata,
+	opts options,
+	rpc RPC,
+	storage *RaftStorage,
+	restart bool,
+) (*Chain, error) {
+	lastBlock := support.Block(support.Height() - 1)
+	if lastBlock == nil {
+		return nil, fmt.Errorf("could not read block %d", support.Height()-1)
+	}
+
+	snapshot := storage.Snapshot()
+	c := &Chain{
+		raftID:       raftID,
+		channelID:    support.ChainID(),
+		support:      support,
+		rpc:          rpc,
+		opts:         opts,
+		storage:      storage,
+		restart:      restart,
+		submitC:      make(chan *submission),
+		startC:       make(chan struct{}),
+		haltC:        make(chan struct{}),
+		doneC:        make(chan struct{}),
+		raftMetadata: raftMetadata,
+		confState:    snapshot.Metadata.ConfState,
+		lastBlock:    lastBlock,
+		appliedIndex: snapshot.Metadata.Index,
+		senders:      make(map[uint64]chan raftpb.Message),
+		latency: metrics.NewRootScope().SubScope("consensus").Tagged(map[string]string{
+			"channel": support.ChainID(),
+			"type":    ConsensusType,
+		}).Timer("consensus_latency"),
+	}
+	c.config = &raft.Config{
+		ID:                        raftID,
+		ElectionTick:              opts.electionTick,
+		HeartbeatTick:             opts.heartbeatTick,
+		Storage:                   storage.ram,
+		MaxSizePerMsg:             maxSizePerMsg,
+		MaxInflightMsgs:           opts.maxInflightBlocks,
+		CheckQuorum:               true,
+		PreVote:                   true,
+		Logger:                    logger,
+		DisableProposalForwarding: true,
+	}
+	if !restart && raftMetadata.RaftIndex == 0 {
+		for _, id := range sortedIDs(raftMetadata.Consenters) {
+			c.peers = append(c.peers, raft.Peer{ID: id})
+		}
+	}
+	rpc.Configure(copyConsenters(raftMetadata.Consenters))
+	return c, nil
+}
+
+// Start starts the raft node and the goroutine running the chain
+func (c *Chain) Start() {
+	switch {
+	case c.restart:
+		logger.Infof("[channel: %s] Restarting raft node %d", c.channelID, c.raftID)
+		c.node = raft.RestartNode(c.config)
+	case len(c.peers) > 0:
+		logger.Infof("[channel: %s] Starting raft node %d with peers %v", c.channelID, c.raftID, sortedIDs(c.raftMetadata.Consenters))
+		c.node = raft.StartNode(c.config, c.peers)
+	default:
+		logger.Infof("[channel: %s] Starting raft node %d to join the consenters", c.channelID, c.raftID)
+		c.node = raft.StartNode(c.config, nil)
+	}
+	close(c.startC)
+	go c.run()
+}
+
+// Halt stops the chain. If the node is the leader, it first transfers the leadership to
+// the most up to date follower, so that the channel does not wait for an election
+func (c *Chain) Halt() {
+	c.haltOnce.Do(func() { close(c.haltC) })
+	select {
+	case <-c.startC:
+		<-c.doneC
+	default:
+	}
+}
+
+// Errored returns a channel which is closed once the chain stopped
+func (c *Chain) Errored() <-chan struct{} {
+	return c.doneC
+}
+
+// Order submits a normal message for ordering
+func (c *Chain) Order(env *cb.Envelope, configSeq uint64) error {
+	return c.Submit(&etcdraft.SubmitRequest{Channel: c.channelID, LastValidationSeq: configSeq, Content: env}, false)
+}
+
+// Configure submits a config message for ordering
+func (c *Chain) Configure(configUpdate *cb.Envelope, config *cb.Envelope, configSeq uint64) error {
+	return c.Submit(&etcdraft.SubmitRequest{Channel: c.channelID, LastValidationSeq: configSeq, Content: config, ConfigUpdate: configUpdate}, false)
+}
+
+// Submit passes a message to the leader for ordering. Only the messages broadcast to this
+// node are forwarded to the leader, and not the ones forwarded by another consenter, so that
+// the messages do not bounce between nodes which disagree on the leader
+func (c *Chain) Submit(req *etcdraft.SubmitRequest, forwarded bool) error {
+	leader := atomic.LoadUint64(&c.leader)
+	if leader == raft.None {
+		return fmt.Errorf("no raft leader for channel %s", c.channelID)
+	}
+
+	if leader != c.raftID {
+		if forwarded {
+			return fmt.Errorf("node %d is not the raft leader of channel %s, node %d is", c.raftID, c.channelID, leader)
+		}
+		resp, err := c.rpc.Submit(leader, req)
+		if err != nil {
+			return fmt.Errorf("could not forward message to raft leader %d: %s", leader, err)
+		}
+		if resp.Status != cb.Status_SUCCESS {
+			return fmt.Errorf("raft leader %d rejected message with status %s: %s", leader, resp.Status, resp.Info)
+		}
+		return nil
+	}
+
+	s := &submission{req: req, errC: make(chan error, 1)}
+	select {
+	case c.submitC <- s:
+	case <-c.haltC:
+		return fmt.Errorf("chain of channel %s is halted", c.channelID)
+	case <-c.doneC:
+		return fmt.Errorf("chain of channel %s is halted", c.channelID)
+	}
+	return <-s.errC
+}
+
+// Step passes a raft message sent by the consenter with the given raft ID to the raft node
+func (c *Chain) Step(msg *raftpb.Message, sender uint64) error {
+	select {
+	case <-c.startC:
+	default:
+		return fmt.Errorf("chain of channel %s is not started", c.channelID)
+	}
+	if msg.From != sender {
+		return fmt.Errorf("node %d sent a message from node %d", sender, msg.From)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), rpcTimeout)
+	defer cancel()
+	return c.node.Step(ctx, *msg)
+}
+
+func (c *Chain) run() {
+	ticker := time.NewTicker(c.opts.tickInterval)
+	defer func() {
+		ticker.Stop()
+		if c.leaderState != nil {
+			c.leaderState.cancel()
+		}
+		c.node.Stop()
+		if err := c.storage.Close(); err != nil {
+			logger.Errorf("[channel: %s] Failed to close raft storage: %s", c.channelID, err)
+		}
+		close(c.doneC)
+	}()
+
+	// a crash may have happened after a snapshot was saved but before its block was written
+	if snapshot := c.storage.Snapshot(); !raft.IsEmptySnap(snapshot) {
+		if !c.catchUp(snapshot) {
+			return
+		}
+	}
+
+	haltC := c.haltC
+	var haltDeadline <-chan time.Time
+	for {
+		var submitC chan *submission
+		if c.acceptingSubmissions() {
+			submitC = c.submitC
+		}
+
+		select {
+		case s := <-submitC:
+			s.errC <- c.serve(s.req)
+
+		case <-c.batchTimer:
+			c.batchTimer = nil
+			if batch := c.support.BlockCutter().Cut(); len(batch) > 0 {
+				logger.Debugf("[channel: %s] Batch timer expired, creating block", c.channelID)
+				c.proposeBatches([][]*cb.Envelope{batch})
+			}
+
+		case <-ticker.C:
+			c.node.Tick()
+
+		case rd := <-c.node.Ready():
+			if !c.ready(rd) || c.removed || (c.halting && c.leaderState == nil) {
+				return
+			}
+
+		case <-haltC:
+			haltC = nil
+			c.halting = true
+			if !c.transferLeadership() {
+				return
+			}
+			haltDeadline = time.After(time.Duration(c.opts.electionTick) * c.opts.tickInterval)
+
+		case <-haltDeadline:
+			logger.Warningf("[channel: %s] Leadership transfer did not complete before halting", c.channelID)
+			return
+		}
+	}
+}
+
+// acceptingSubmissions returns whether the submissions may be received. The followers receive
+// them to reject them, while the leader holds them back until it creates blocks, while a config
+// block is in flight and while the maximum number of blocks are in flight
+func (c *Chain) acceptingSubmissions() bool {
+	ls := c.leaderState
+	if ls == nil || c.halting {
+		return true
+	}
+	return ls.ready && !ls.configInflight && ls.inflightBlocks < c.opts.maxInflightBlocks
+}
+
+// ready handles a Ready of the raft node, and returns false if the chain must stop
+func (c *Chain) ready(rd raft.Ready) bool {
+	if err := c.storage.Store(rd.Entries, rd.HardState, rd.Snapshot, rd.MustSync); err != nil {
+		logger.Panicf("[channel: %s] Failed to persist raft state: %s", c.channelID, err)
+	}
+	if !raft.IsEmptySnap(rd.Snapshot) {
+		c.confState = rd.Snapshot.Metadata.ConfState
+		c.appliedIndex = rd.Snapshot.Metadata.Index
+		if !c.catchUp(rd.Snapshot) {
+			return false
+		}
+	}
+	c.send(rd.Messages)
+	if rd.SoftState != nil {
+		c.leadershipChanged(rd.SoftState)
+	}
+	c.apply(rd.CommittedEntries)
+	c.node.Advance()
+
+	if ls := c.leaderState; ls != nil {
+		if !ls.ready && c.appliedIndex >= ls.electionIndex {
+			logger.Infof("[channel: %s] Raft leader %d is ready to create blocks after block %d",
+				c.channelID, c.raftID, c.lastBlock.Header.Number)
+			ls.ready = true
+			ls.blockCreator = newBlockCreator(c.lastBlock, c.support)
+		}
+		if ls.ready && !c.halting {
+			c.reconcile()
+		}
+	}
+	return true
+}
+
+func (c *Chain) leadershipChanged(ss *raft.SoftState) {
+	atomic.StoreUint64(&c.leader, ss.Lead)
+	isLeader := ss.RaftState == raft.StateLeader
+
+	if isLeader && c.leaderState == nil {
+		logger.Infof("[channel: %s] Raft node %d became the leader", c.channelID, c.raftID)
+		electionIndex, _ := c.storage.ram.LastIndex()
+		ctx, cancel := context.WithCancel(context.Background())
+		c.leaderState = &leaderState{
+			ctx:           ctx,
+			cancel:        cancel,
+			electionIndex: electionIndex,
+			proposeC:      make(chan *cb.Block, c.opts.maxInflightBlocks+2),
+			createdAt:     make(map[uint64]time.Time),
+		}
+		go c.propose(ctx, c.leaderState.proposeC)
+		return
+	}
+
+	if !isLeader && c.leaderState != nil {
+		logger.Infof("[channel: %s] Raft node %d is no longer the leader, the leader is now node %d", c.channelID, c.raftID, ss.Lead)
+		c.leaderState.cancel()
+		c.leaderState = nil
+		c.batchTimer = nil
+		if batch := c.support.BlockCutter().Cut(); len(batch) > 0 {
+			logger.Warningf("[channel: %s] Discarding %d messages which were not cut into a block", c.channelID, len(batch))
+		}
+	}
+}
+
+// serve orders a message submitted to the leader
+func (c *Chain) serve(req *etcdraft.SubmitRequest) error {
+	ls := c.leaderState
+	if c.halting {
+		return fmt.Errorf("chain of channel %s is halting", c.channelID)
+	}
+	if ls == nil {
+		return fmt.Errorf("node %d is not the raft leader of channel %s", c.raftID, c.channelID)
+	}
+	if req.Content == nil {
+		return fmt.Errorf("message has no content")
+	}
+
+	seq := c.support.Sequence()
+	if req.ConfigUpdate != nil {
+		config := req.Content
+		if req.LastValidationSeq < seq {
+			var err error
+			config, _, err = c.support.ProcessConfigUpdateMsg(req.ConfigUpdate)
+			if err != nil {
+				return fmt.Errorf("bad config message: %s", err)
+			}
+		}
+		if err := c.checkConsenterChanges(config); err != nil {
+			return err
+		}
+
+		var batches [][]*cb.Envelope
+		if batch := c.support.BlockCutter().Cut(); len(batch) > 0 {
+			batches = append(batches, batch)
+		}
+		batches = append(batches, []*cb.Envelope{config})
+		c.proposeBatches(batches)
+		c.batchTimer = nil
+		ls.configInflight = true
+		return nil
+	}
+
+	if req.LastValidationSeq < seq {
+		if _, err := c.support.ProcessNormalMsg(req.Content); err != nil {
+			return fmt.Errorf("bad normal message: %s", err)
+		}
+	}
+	batches, pending := c.support.BlockCutter().Ordered(req.Content)
+	c.proposeBatches(batches)
+	if len(batches) > 0 {
+		c.batchTimer = nil
+	}
+	if pending && c.batchTimer == nil {
+		c.batchTimer = time.After(c.support.SharedConfig().BatchTimeout())
+	}
+	return nil
+}
+
+// checkConsenterChanges rejects the config updates changing more than one consenter, or
+// changing a consenter before the raft configuration caught up with the previous change
+func (c *Chain) checkConsenterChanges(config *cb.Envelope) error {
+	metadata, ok, err := configEnvelopeMetadata(config)
+	if err != nil {
+		return fmt.Errorf("bad config message: %s", err)
+	}
+	if !ok {
+		return nil
+	}
+	added, removed := membershipChanges(c.raftMetadata, metadata.Consenters)
+	switch changes := len(added) + len(removed); {
+	case changes == 0:
+		return nil
+	case changes > 1:
+		return fmt.Errorf("config update adds %d and removes %d consenters, but only one consenter may be added or removed at a time",
+			len(added), len(removed))
+	case c.leaderState.confChangeInflight || !c.membershipReconciled():
+		return fmt.Errorf("the previous change of the consenters of channel %s is still in progress", c.channelID)
+	}
+	return nil
+}
+
+func (c *Chain) proposeBatches(batches [][]*cb.Envelope) {
+	ls := c.leaderState
+	for _, batch := range batches {
+		block := ls.blockCreator.createNextBlock(batch)
+		ls.createdAt[block.Header.Number] = time.Now()
+		ls.proposeC <- block
+		ls.inflightBlocks++
+	}
+}
+
+// propose proposes the blocks created by the leader until the leadership ends
+func (c *Chain) propose(ctx context.Context, proposeC <-chan *cb.Block) {
+	for {
+		select {
+		case block := <-proposeC:
+			if err := c.node.Propose(ctx, utils.MarshalOrPanic(block)); err != nil {
+				logger.Warningf("[channel: %s] Failed to propose block %d: %s", c.channelID, block.Header.Number, err)
+				return
+			}
+			logger.Debugf("[channel: %s] Proposed block %d", c.channelID, block.Header.Number)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (c *Chain) apply(entries []raftpb.Entry) {
+	for i := range entries {
+		if entries[i].Index <= c.appliedIndex {
+			continue
+		}
+		switch entries[i].Type {
+		case raftpb.EntryNormal:
+			// the leader appends an empty entry when it is elected
+			if len(entries[i].Data) > 0 {
+				c.applyBlock(&entries[i])
+			}
+		case raftpb.EntryConfChange:
+			c.applyConfChange(&entries[i])
+		}
+		c.appliedIndex = entries[i].Index
+	}
+}
+
+func (c *Chain) applyBlock(entry *raftpb.Entry) {
+	block, err := utils.UnmarshalBlock(entry.Data)
+	if err != nil {
+		logger.Panicf("[channel: %s] Failed to unmarshal block of raft entry %d: %s", c.channelID, entry.Index, err)
+	}
+	// the entries following the snapshot are applied again on restart
+	if block.Header.Number <= c.lastBlock.Header.Number {
+		logger.Debugf("[channel: %s] Skipping block %d of raft entry %d which was written already",
+			c.channelID, block.Header.Number, entry.Index)
+		return
+	}
+	if block.Header.Number != c.lastBlock.Header.Number+1 {
+		logger.Panicf("[channel: %s] Got block %d in raft entry %d after block %d",
+			c.channelID, block.Header.Number, entry.Index, c.lastBlock.Header.Number)
+	}
+
+	if ls := c.leaderState; ls != nil {
+		if ls.inflightBlocks > 0 {
+			ls.inflightBlocks--
+		}
+		if createdAt, ok := ls.createdAt[block.Header.Number]; ok {
+			c.latency.Record(time.Since(createdAt))
+			delete(ls.createdAt, block.Header.Number)
+		}
+	}
+	c.raftMetadata.RaftIndex = entry.Index
+	if isConfigBlock(block) {
+		c.writeConfigBlock(block)
+	} else {
+		c.support.WriteBlock(block, utils.MarshalOrPanic(c.raftMetadata))
+	}
+	c.lastBlock = block
+
+	c.snapDataSize += uint32(len(entry.Data))
+	if c.snapDataSize >= c.opts.snapshotIntervalSize {
+		if err := c.storage.TakeSnapshot(entry.Index, c.confState, entry.Data); err != nil {
+			logger.Errorf("[channel: %s] Failed to take snapshot at raft index %d: %s", c.channelID, entry.Index, err)
+		}
+		c.snapDataSize = 0
+	}
+}
+
+// writeConfigBlock writes a config block, updating the consenters of the raft metadata
+// to the ones of the new channel config
+func (c *Chain) writeConfigBlock(block *cb.Block) {
+	env, err := utils.ExtractEnvelope(block, 0)
+	if err != nil {
+		logger.Panicf("[channel: %s] Failed to extract config transaction of block %d: %s", c.channelID, block.Header.Number, err)
+	}
+	metadata, reconfigured, err := configEnvelopeMetadata(env)
+	if err != nil {
+		logger.Errorf("[channel: %s] Failed to read consenters of config block %d, keeping the current ones: %s",
+			c.channelID, block.Header.Number, err)
+		reconfigured = false
+	}
+
+	if reconfigured {
+		added, removed := membershipChanges(c.raftMetadata, metadata.Consenters)
+		for _, consenter := range added {
+			logger.Infof("[channel: %s] Adding consenter %s as raft node %d", c.channelID, endpoint(consenter), c.raftMetadata.NextConsenterId)
+			c.raftMetadata.Consenters[c.raftMetadata.NextConsenterId] = consenter
+			c.raftMetadata.NextConsenterId++
+		}
+		for _, id := range removed {
+			logger.Infof("[channel: %s] Removing consenter %s, raft node %d", c.channelID, endpoint(c.raftMetadata.Consenters[id]), id)
+			delete(c.raftMetadata.Consenters, id)
+		}
+	}
+
+	c.support.WriteConfigBlock(block, utils.MarshalOrPanic(c.raftMetadata))
+	if reconfigured {
+		c.rpc.Configure(copyConsenters(c.raftMetadata.Consenters))
+	}
+	if ls := c.leaderState; ls != nil {
+		ls.configInflight = false
+	}
+}
+
+func (c *Chain) applyConfChange(entry *raftpb.Entry) {
+	var cc raftpb.ConfChange
+	if err := cc.Unmarshal(entry.Data); err != nil {
+		logger.Panicf("[channel: %s] Failed to unmarshal raft configuration change of entry %d: %s", c.channelID, entry.Index, err)
+	}
+	c.confState = *c.node.ApplyConfChange(cc)
+	if ls := c.leaderState; ls != nil {
+		ls.confChangeInflight = false
+	}
+
+	switch cc.Type {
+	case raftpb.ConfChangeAddNode:
+		logger.Infof("[channel: %s] Raft node %d is a member, the members are %v", c.channelID, cc.NodeID, c.confState.Nodes)
+	case raftpb.ConfChangeRemoveNode:
+		logger.Infof("[channel: %s] Raft node %d is no longer a member, the members are %v", c.channelID, cc.NodeID, c.confState.Nodes)
+		if cc.NodeID == c.raftID {
+			logger.Warningf("[channel: %s] This node was removed from the consenters, halting", c.channelID)
+			c.removed = true
+		}
+	}
+}
+
+// membershipReconciled returns whether the raft configuration matches the consenters
+func (c *Chain) membershipReconciled() bool {
+	if len(c.confState.Nodes) != len(c.raftMetadata.Consenters) {
+		return false
+	}
+	for _, id := range c.confState.Nodes {
+		if _, ok := c.raftMetadata.Consenters[id]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// reconcile proposes the raft configuration change bringing the raft configuration one
+// step closer to the consenters of the channel config. A leader which is no longer a
+// consenter transfers the leadership rather than removing itself
+func (c *Chain) reconcile() {
+	ls := c.leaderState
+	if ls.confChangeInflight || ls.configInflight || ls.transferring {
+		return
+	}
+
+	members := make(map[uint64]bool)
+	for _, id := range c.confState.Nodes {
+		members[id] = true
+	}
+	for _, id := range sortedIDs(c.raftMetadata.Consenters) {
+		if !members[id] {
+			c.proposeConfChange(raftpb.ConfChange{
+				Type:    raftpb.ConfChangeAddNode,
+				NodeID:  id,
+				Context: utils.MarshalOrPanic(c.raftMetadata.Consenters[id]),
+			})
+			return
+		}
+	}
+	for _, id := range c.confState.Nodes {
+		if _, ok := c.raftMetadata.Consenters[id]; ok {
+			continue
+		}
+		if id == c.raftID {
+			ls.transferring = c.transferLeadership()
+			return
+		}
+		c.proposeConfChange(raftpb.ConfChange{Type: raftpb.ConfChangeRemoveNode, NodeID: id})
+		return
+	}
+}
+
+func (c *Chain) proposeConfChange(cc raftpb.ConfChange) {
+	ls := c.leaderState
+	ls.confChangeInflight = true
+	logger.Infof("[channel: %s] Proposing raft configuration change %s of node %d", c.channelID, cc.Type, cc.NodeID)
+	go func() {
+		if err := c.node.ProposeConfChange(ls.ctx, cc); err != nil {
+			logger.Warningf("[channel: %s] Failed to propose raft configuration change %s of node %d: %s",
+				c.channelID, cc.Type, cc.NodeID, err)
+		}
+	}()
+}
+
+// transferLeadership transfers the leadership to the follower with the most entries, and
+// returns false if this node is not the leader or there is no follower
+func (c *Chain) transferLeadership() bool {
+	if c.leaderState == nil {
+		return false
+	}
+	var transferee, match uint64
+	for id, progress := range c.node.Status().Progress {
+		if id != c.raftID && (transferee == raft.None || progress.Match > match) {
+			transferee, match = id, progress.Match
+		}
+	}
+	if transferee == raft.None {
+		return false
+	}
+	logger.Infof("[channel: %s] Transferring the raft leadership to node %d", c.channelID, transferee)
+	c.node.TransferLeadership(c.leaderState.ctx, c.raftID, transferee)
+	return true
+}
+
+// catchUp writes the blocks up to the block of the snapshot, pulling them from the other
+// consenters. It returns false if the chain halted before it caught up
+func (c *Chain) catchUp(snapshot raftpb.Snapshot) bool {
+	target, err := utils.UnmarshalBlock(snapshot.Data)
+	if err != nil {
+		logger.Panicf("[channel: %s] Failed to unmarshal block of snapshot at raft index %d: %s", c.channelID, snapshot.Metadata.Index, err)
+	}
+	if target.Header.Number <= c.lastBlock.Header.Number {
+		return true
+	}
+	logger.Infof("[channel: %s] Catching up from block %d to block %d of snapshot at raft index %d",
+		c.channelID, c.lastBlock.Header.Number, target.Header.Number, snapshot.Metadata.Index)
+
+	retry := time.Duration(c.opts.electionTick) * c.opts.tickInterval
+	for {
+		for _, id := range c.catchUpSources() {
+			err := c.rpc.Pull(id, c.lastBlock.Header.Number+1, target.Header.Number, c.writePulledBlock)
+			if err == nil {
+				c.rpc.Configure(copyConsenters(c.raftMetadata.Consenters))
+				return true
+			}
+			logger.Warningf("[channel: %s] Failed to pull blocks from raft node %d: %s", c.channelID, id, err)
+		}
+		select {
+		case <-time.After(retry):
+		case <-c.haltC:
+			return false
+		}
+	}
+}
+
+// catchUpSources returns the other consenters, starting with the leader
+func (c *Chain) catchUpSources() []uint64 {
+	leader := atomic.LoadUint64(&c.leader)
+	var sources []uint64
+	if leader != raft.None && leader != c.raftID {
+		sources = append(sources, leader)
+	}
+	for _, id := range sortedIDs(c.raftMetadata.Consenters) {
+		if id != c.raftID && id != leader {
+			sources = append(sources, id)
+		}
+	}
+	return sources
+}
+
+func (c *Chain) writePulledBlock(block *cb.Block) error {
+	raftMetadata, err := writePulledBlock(c.support, c.lastBlock, block)
+	if err != nil {
+		return err
+	}
+	c.raftMetadata = raftMetadata
+	c.lastBlock = block
+	return nil
+}
+
+// writePulledBlock writes a block pulled from another consenter with the raft metadata it
+// carries, after checking that it follows the last block, and returns its raft metadata
+func writePulledBlock(support consensus.ConsenterSupport, lastBlock, block *cb.Block) (*etcdraft.RaftMetadata, error) {
+	if err := replication.VerifyBlock(lastBlock, block); err != nil {
+		return nil, err
+	}
+	raftMetadata, err := blockRaftMetadata(block)
+	if err != nil {
+		return nil, err
+	}
+
+	if isConfigBlock(block) {
+		support.WriteConfigBlock(block, utils.MarshalOrPanic(raftMetadata))
+	} else {
+		support.WriteBlock(block, utils.MarshalOrPanic(raftMetadata))
+	}
+	return raftMetadata, nil
+}
+
+// send queues the raft messages for the goroutines sending them to their destination
+func (c *Chain) send(msgs []raftpb.Message) {
+	for _, msg := range msgs {
+		if msg.To == raft.None {
+			continue
+		}
+		sendC, ok := c.senders[msg.To]
+		if !ok {
+			sendC = make(chan raftpb.Message, senderBufferSize)
+			c.senders[msg.To] = sendC
+			go c.sendLoop(msg.To, sendC)
+		}
+		select {
+		case sendC <- msg:
+		default:
+			logger.Debugf("[channel: %s] Dropping raft message to node %d whose queue is full", c.channelID, msg.To)
+			c.node.ReportUnreachable(msg.To)
+			if msg.Type == raftpb.MsgSnap {
+				c.node.ReportSnapshot(msg.To, raft.SnapshotFailure)
+			}
+		}
+	}
+}
+
+func (c *Chain) sendLoop(dest uint64, sendC <-chan raftpb.Message) {
+	for {
+		select {
+		case msg := <-sendC:
+			err := c.rpc.Step(dest, &msg)
+			if err != nil {
+				logger.Debugf("[channel: %s] Failed to send raft message to node %d: %s", c.channelID, dest, err)
+				c.node.ReportUnreachable(dest)
+			}
+			if msg.Type == raftpb.MsgSnap {
+				status := raft.SnapshotFinish
+				if err != nil {
+					status = raft.SnapshotFailure
+				}
+				c.node.ReportSnapshot(dest, status)
+			}
+		case <-c.doneC:
+			return
+		}
+	}
+}
+
+func sortedIDs(consenters map[uint64]*etcdraft.Consenter) []uint64 {
+	ids := make([]uint64, 0, len(consenters))
+	for id := range consenters {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+func copyConsenters(consenters map[uint64]*etcdraft.Consenter) map[uint64]*etcdraft.Consenter {
+	result := make(map[uint64]*etcdraft.Consenter, len(consenters))
+	for id, consenter := range consenters {
+		result[id] = consenter
+	}
+	return result
+}
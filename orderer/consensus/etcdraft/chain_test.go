@@ -0,0 +1,478 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/coreos/etcd/raft/raftpb"
+	mockconfig "github.com/hyperledger/fabric/common/mocks/config"
+	mockmetrics "github.com/hyperledger/fabric/common/mocks/metrics"
+	"github.com/hyperledger/fabric/orderer/common/blockcutter"
+	mockmultichannel "github.com/hyperledger/fabric/orderer/mocks/common/multichannel"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/orderer/etcdraft"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testChannelID = "mychannel"
+
+// testSupport keeps the blocks written by a chain in memory
+type testSupport struct {
+	*mockmultichannel.ConsenterSupport
+	cutter blockcutter.Receiver
+
+	lock   sync.Mutex
+	blocks []*cb.Block
+}
+
+func newTestSupport(genesis *cb.Block) *testSupport {
+	sharedConfig := &mockconfig.Orderer{
+		ConsensusTypeVal: ConsensusType,
+		BatchSizeVal:     &ab.BatchSize{MaxMessageCount: 2, AbsoluteMaxBytes: 1024 * 1024, PreferredMaxBytes: 1024 * 1024},
+		BatchTimeoutVal:  50 * time.Millisecond,
+	}
+	return &testSupport{
+		ConsenterSupport: &mockmultichannel.ConsenterSupport{ChainIDVal: testChannelID, SharedConfigVal: sharedConfig},
+		cutter:           blockcutter.NewReceiverImpl(sharedConfig),
+		blocks:           []*cb.Block{genesis},
+	}
+}
+
+func (ts *testSupport) BlockCutter() blockcutter.Receiver {
+	return ts.cutter
+}
+
+func (ts *testSupport) WriteBlock(block *cb.Block, encodedMetadataValue []byte) {
+	block.Metadata.Metadata[cb.BlockMetadataIndex_ORDERER] = utils.MarshalOrPanic(&cb.Metadata{Value: encodedMetadataValue})
+	ts.lock.Lock()
+	defer ts.lock.Unlock()
+	ts.blocks = append(ts.blocks, block)
+}
+
+func (ts *testSupport) WriteConfigBlock(block *cb.Block, encodedMetadataValue []byte) {
+	ts.WriteBlock(block, encodedMetadataValue)
+}
+
+func (ts *testSupport) Height() uint64 {
+	ts.lock.Lock()
+	defer ts.lock.Unlock()
+	return uint64(len(ts.blocks))
+}
+
+func (ts *testSupport) Block(number uint64) *cb.Block {
+	ts.lock.Lock()
+	defer ts.lock.Unlock()
+	if number >= uint64(len(ts.blocks)) {
+		return nil
+	}
+	return ts.blocks[number]
+}
+
+// testNetwork connects the chains of a channel in memory
+type testNetwork struct {
+	lock    sync.RWMutex
+	chains  map[uint64]*Chain
+	support map[uint64]*testSupport
+}
+
+func (n *testNetwork) chain(id uint64) (*Chain, error) {
+	n.lock.RLock()
+	defer n.lock.RUnlock()
+	chain, ok := n.chains[id]
+	if !ok {
+		return nil, fmt.Errorf("node %d is unreachable", id)
+	}
+	return chain, nil
+}
+
+// testRPC is the RPC of a chain over a testNetwork
+type testRPC struct {
+	network *testNetwork
+	self    uint64
+}
+
+func (r *testRPC) Configure(consenters map[uint64]*etcdraft.Consenter) {}
+
+func (r *testRPC) Step(dest uint64, msg *raftpb.Message) error {
+	chain, err := r.network.chain(dest)
+	if err != nil {
+		return err
+	}
+	return chain.Step(msg, r.self)
+}
+
+func (r *testRPC) Submit(dest uint64, req *etcdraft.SubmitRequest) (*etcdraft.SubmitResponse, error) {
+	chain, err := r.network.chain(dest)
+	if err != nil {
+		return nil, err
+	}
+	if err := chain.Submit(req, true); err != nil {
+		return &etcdraft.SubmitResponse{Status: cb.Status_SERVICE_UNAVAILABLE, Info: err.Error()}, nil
+	}
+	return &etcdraft.SubmitResponse{Status: cb.Status_SUCCESS}, nil
+}
+
+func (r *testRPC) Pull(dest uint64, start, end uint64, deliver func(block *cb.Block) error) error {
+	r.network.lock.RLock()
+	support := r.network.support[dest]
+	r.network.lock.RUnlock()
+	for i := start; i <= end; i++ {
+		block := support.Block(i)
+		if block == nil {
+			return fmt.Errorf("node %d has no block %d", dest, i)
+		}
+		if err := deliver(block); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *testRPC) Height(dest uint64) (uint64, error) {
+	r.network.lock.RLock()
+	support, ok := r.network.support[dest]
+	r.network.lock.RUnlock()
+	if !ok {
+		return 0, fmt.Errorf("node %d is unreachable", dest)
+	}
+	return support.Height(), nil
+}
+
+type testCluster struct {
+	t       *testing.T
+	dir     string
+	genesis *cb.Block
+	network *testNetwork
+	opts    options
+	nodes   uint64
+}
+
+func newTestCluster(t *testing.T, nodes uint64) *testCluster {
+	dir, err := ioutil.TempDir("", "etcdraft-chain")
+	require.NoError(t, err)
+
+	genesis := cb.NewBlock(0, nil)
+	genesis.Data = &cb.BlockData{Data: [][]byte{utils.MarshalOrPanic(testEnvelope("genesis"))}}
+	genesis.Header.DataHash = genesis.Data.Hash()
+
+	return &testCluster{
+		t:       t,
+		dir:     dir,
+		genesis: genesis,
+		network: &testNetwork{chains: make(map[uint64]*Chain), support: make(map[uint64]*testSupport)},
+		opts: options{
+			tickInterval:         10 * time.Millisecond,
+			electionTick:         10,
+			heartbeatTick:        1,
+			maxInflightBlocks:    5,
+			snapshotIntervalSize: defaultSnapshotIntervalSize,
+		},
+		nodes: nodes,
+	}
+}
+
+// start starts the chain of the given node, restarting it from its storage if it ran before
+func (tc *testCluster) start(id uint64) *Chain {
+	raftMetadata := &etcdraft.RaftMetadata{Consenters: make(map[uint64]*etcdraft.Consenter), NextConsenterId: tc.nodes + 1}
+	for i := uint64(1); i <= tc.nodes; i++ {
+		raftMetadata.Consenters[i] = &etcdraft.Consenter{Host: "orderer", Port: uint32(i)}
+	}
+
+	tc.network.lock.RLock()
+	support, ok := tc.network.support[id]
+	tc.network.lock.RUnlock()
+	if !ok {
+		support = newTestSupport(tc.genesis)
+	}
+	if support.Height() > 1 {
+		recorded, err := blockRaftMetadata(support.Block(support.Height() - 1))
+		require.NoError(tc.t, err)
+		raftMetadata = recorded
+	}
+
+	nodeDir := filepath.Join(tc.dir, fmt.Sprintf("node%d", id))
+	storage, existing, err := CreateStorage(filepath.Join(nodeDir, "wal"), filepath.Join(nodeDir, "snap"))
+	require.NoError(tc.t, err)
+	chain, err := NewChain(support, id, raftMetadata, tc.opts, &testRPC{network: tc.network, self: id}, storage, existing)
+	require.NoError(tc.t, err)
+	chain.latency = &mockmetrics.Timer{}
+
+	tc.network.lock.Lock()
+	tc.network.chains[id] = chain
+	tc.network.support[id] = support
+	tc.network.lock.Unlock()
+	chain.Start()
+	return chain
+}
+
+func (tc *testCluster) startAll() {
+	for id := uint64(1); id <= tc.nodes; id++ {
+		tc.start(id)
+	}
+}
+
+func (tc *testCluster) halt(id uint64) {
+	chain, err := tc.network.chain(id)
+	require.NoError(tc.t, err)
+	chain.Halt()
+	tc.network.lock.Lock()
+	delete(tc.network.chains, id)
+	tc.network.lock.Unlock()
+}
+
+func (tc *testCluster) stop() {
+	for id := uint64(1); id <= tc.nodes; id++ {
+		if chain, err := tc.network.chain(id); err == nil {
+			chain.Halt()
+		}
+	}
+	os.RemoveAll(tc.dir)
+}
+
+// leader waits for the running chains to agree on a leader
+func (tc *testCluster) leader() uint64 {
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		tc.network.lock.RLock()
+		var leader uint64
+		agreed := true
+		for _, chain := range tc.network.chains {
+			l := atomic.LoadUint64(&chain.leader)
+			if l == 0 || (leader != 0 && l != leader) {
+				agreed = false
+			}
+			leader = l
+		}
+		_, running := tc.network.chains[leader]
+		tc.network.lock.RUnlock()
+		if agreed && running {
+			return leader
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	tc.t.Fatal("no leader was elected")
+	return 0
+}
+
+// waitHeight waits for the running chains to write the given number of blocks
+func (tc *testCluster) waitHeight(height uint64) {
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		reached := true
+		tc.network.lock.RLock()
+		for id := range tc.network.chains {
+			if tc.network.support[id].Height() < height {
+				reached = false
+			}
+		}
+		tc.network.lock.RUnlock()
+		if reached {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	tc.t.Fatalf("blocks were not written up to height %d", height)
+}
+
+// assertSameLedgers asserts that the nodes wrote the same blocks
+func (tc *testCluster) assertSameLedgers(ids ...uint64) {
+	reference := tc.network.support[ids[0]]
+	for _, id := range ids[1:] {
+		support := tc.network.support[id]
+		require.Equal(tc.t, reference.Height(), support.Height())
+		for i := uint64(0); i < reference.Height(); i++ {
+			assert.Equal(tc.t, reference.Block(i).Header.Hash(), support.Block(i).Header.Hash(), "block %d of node %d", i, id)
+		}
+	}
+}
+
+func testEnvelope(content string) *cb.Envelope {
+	return &cb.Envelope{Payload: utils.MarshalOrPanic(&cb.Payload{
+		Header: &cb.Header{ChannelHeader: utils.MarshalOrPanic(&cb.ChannelHeader{
+			Type:      int32(cb.HeaderType_ENDORSER_TRANSACTION),
+			ChannelId: testChannelID,
+		})},
+		Data: []byte(content),
+	})}
+}
+
+func TestChainOrdersMessages(t *testing.T) {
+	tc := newTestCluster(t, 3)
+	defer tc.stop()
+	tc.startAll()
+	leader := tc.leader()
+
+	// the followers forward the messages to the leader
+	for i := 0; i < 6; i++ {
+		chain, err := tc.network.chain(uint64(i%3 + 1))
+		require.NoError(t, err)
+		require.NoError(t, chain.Order(testEnvelope(fmt.Sprintf("message %d", i)), 0))
+	}
+	tc.waitHeight(4)
+	tc.assertSameLedgers(1, 2, 3)
+
+	block := tc.network.support[leader].Block(3)
+	assert.Len(t, block.Data.Data, 2)
+	raftMetadata, err := blockRaftMetadata(block)
+	require.NoError(t, err)
+	assert.Len(t, raftMetadata.Consenters, 3)
+	assert.NotZero(t, raftMetadata.RaftIndex)
+}
+
+func TestChainBatchTimeout(t *testing.T) {
+	tc := newTestCluster(t, 3)
+	defer tc.stop()
+	tc.startAll()
+	leader := tc.leader()
+
+	chain, err := tc.network.chain(leader)
+	require.NoError(t, err)
+	require.NoError(t, chain.Order(testEnvelope("lonely message"), 0))
+	tc.waitHeight(2)
+	tc.assertSameLedgers(1, 2, 3)
+	assert.Len(t, tc.network.support[leader].Block(1).Data.Data, 1)
+}
+
+func TestChainConsensusLatency(t *testing.T) {
+	tc := newTestCluster(t, 3)
+	defer tc.stop()
+	tc.startAll()
+	leader := tc.leader()
+
+	chain, err := tc.network.chain(leader)
+	require.NoError(t, err)
+	require.NoError(t, chain.Order(testEnvelope("message"), 0))
+	tc.waitHeight(2)
+
+	// only the leader proposing the block records how long it took to be committed
+	for id := uint64(1); id <= 3; id++ {
+		chain, err := tc.network.chain(id)
+		require.NoError(t, err)
+		expected := 0
+		if id == leader {
+			expected = 1
+		}
+		assert.Len(t, chain.latency.(*mockmetrics.Timer).Values(), expected)
+	}
+}
+
+func TestChainRejectsForwardedMessageAtFollower(t *testing.T) {
+	tc := newTestCluster(t, 3)
+	defer tc.stop()
+	tc.startAll()
+	leader := tc.leader()
+
+	follower := leader%3 + 1
+	chain, err := tc.network.chain(follower)
+	require.NoError(t, err)
+	err = chain.Submit(&etcdraft.SubmitRequest{Channel: testChannelID, Content: testEnvelope("message")}, true)
+	assert.Error(t, err)
+}
+
+func TestChainLeaderHalt(t *testing.T) {
+	tc := newTestCluster(t, 3)
+	defer tc.stop()
+	tc.startAll()
+	leader := tc.leader()
+
+	chain, err := tc.network.chain(leader)
+	require.NoError(t, err)
+	require.NoError(t, chain.Order(testEnvelope("message 0"), 0))
+	require.NoError(t, chain.Order(testEnvelope("message 1"), 0))
+	tc.waitHeight(2)
+
+	// the halted leader hands the leadership over to another node
+	tc.halt(leader)
+	select {
+	case <-chain.Errored():
+	default:
+		t.Fatal("halted chain is not errored")
+	}
+	assert.Error(t, chain.Order(testEnvelope("message 2"), 0))
+
+	newLeader := tc.leader()
+	assert.NotEqual(t, leader, newLeader)
+	chain, err = tc.network.chain(newLeader)
+	require.NoError(t, err)
+	require.NoError(t, chain.Order(testEnvelope("message 2"), 0))
+	require.NoError(t, chain.Order(testEnvelope("message 3"), 0))
+	tc.waitHeight(3)
+
+	var running []uint64
+	for id := uint64(1); id <= 3; id++ {
+		if id != leader {
+			running = append(running, id)
+		}
+	}
+	tc.assertSameLedgers(running...)
+
+	// the restarted node catches up with the blocks written while it was down
+	tc.start(leader)
+	tc.waitHeight(3)
+	tc.assertSameLedgers(1, 2, 3)
+}
+
+func TestChainRestart(t *testing.T) {
+	tc := newTestCluster(t, 1)
+	defer tc.stop()
+	tc.start(1)
+	tc.leader()
+
+	chain, err := tc.network.chain(1)
+	require.NoError(t, err)
+	for i := 0; i < 4; i++ {
+		require.NoError(t, chain.Order(testEnvelope(fmt.Sprintf("message %d", i)), 0))
+	}
+	tc.waitHeight(3)
+	tc.halt(1)
+
+	chain = tc.start(1)
+	tc.leader()
+	require.NoError(t, chain.Order(testEnvelope("message 4"), 0))
+	require.NoError(t, chain.Order(testEnvelope("message 5"), 0))
+	tc.waitHeight(4)
+
+	support := tc.network.support[1]
+	assert.Equal(t, uint64(4), support.Height())
+	for i := uint64(1); i < support.Height(); i++ {
+		assert.Equal(t, support.Block(i-1).Header.Hash(), support.Block(i).Header.PreviousHash)
+	}
+}
+
+func TestChainSnapshot(t *testing.T) {
+	tc := newTestCluster(t, 3)
+	tc.opts.snapshotIntervalSize = 1
+	defer tc.stop()
+	tc.startAll()
+	leader := tc.leader()
+
+	follower := leader%3 + 1
+	tc.halt(follower)
+
+	chain, err := tc.network.chain(leader)
+	require.NoError(t, err)
+	for i := 0; i < 2*snapshotCatchUpEntries+4; i++ {
+		require.NoError(t, chain.Order(testEnvelope(fmt.Sprintf("message %d", i)), 0))
+	}
+	tc.waitHeight(snapshotCatchUpEntries + 3)
+
+	// the entries the follower missed are compacted, so it catches up from a snapshot
+	tc.start(follower)
+	tc.waitHeight(snapshotCatchUpEntries + 3)
+	tc.assertSameLedgers(1, 2, 3)
+}
@@ -0,0 +1,282 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/coreos/etcd/raft/raftpb"
+	"github.com/hyperledger/fabric/common/flogging"
+	localconfig "github.com/hyperledger/fabric/orderer/common/localconfig"
+	"github.com/hyperledger/fabric/orderer/consensus"
+	cb "github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/orderer/etcdraft"
+	logging "github.com/op/go-logging"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+const pkgLogID = "orderer/etcdraft"
+
+var logger *logging.Logger
+
+func init() {
+	logger = flogging.MustGetLogger(pkgLogID)
+}
+
+// ConsensusType is the consensus type of the channels ordered by the etcd/raft consenter
+const ConsensusType = "etcdraft"
+
+// Consenter creates the etcd/raft chains of the channels, and serves the Cluster service
+// through which the consenters of a channel communicate. A consenter is identified in the
+// consensus metadata of the channel config by its server TLS certificate when TLS is
+// enabled, and by its listen address and port otherwise
+type Consenter struct {
+	walDir       string
+	snapDir      string
+	pullInterval time.Duration
+	tlsEnabled   bool
+	serverCert   []byte
+	endpoint     string
+	comm         *Comm
+
+	lock   sync.RWMutex
+	chains map[string]*registeredChain
+}
+
+type registeredChain struct {
+	chain *Chain
+	rpc   *channelRPC
+}
+
+// New creates an etcd/raft-based consenter. Called by orderer's main.go.
+func New(conf *localconfig.TopLevel) (*Consenter, error) {
+	c := &Consenter{
+		walDir:       conf.EtcdRaft.WALDir,
+		snapDir:      conf.EtcdRaft.SnapDir,
+		pullInterval: conf.General.Replication.PullInterval,
+		endpoint:     net.JoinHostPort(conf.General.ListenAddress, strconv.Itoa(int(conf.General.ListenPort))),
+		chains:       make(map[string]*registeredChain),
+	}
+	if c.pullInterval <= 0 {
+		return nil, fmt.Errorf("replication pull interval must be positive, not %s", c.pullInterval)
+	}
+
+	if !conf.General.TLS.Enabled {
+		comm, err := NewComm(nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		c.comm = comm
+		return c, nil
+	}
+
+	certificate, err := tls.LoadX509KeyPair(conf.General.TLS.Certificate, conf.General.TLS.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("could not load TLS key pair: %s", err)
+	}
+	var rootCAs [][]byte
+	for _, rootCAFile := range conf.General.TLS.RootCAs {
+		rootCA, err := ioutil.ReadFile(rootCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not read root CA certificate: %s", err)
+		}
+		rootCAs = append(rootCAs, rootCA)
+	}
+	comm, err := NewComm(&certificate, rootCAs)
+	if err != nil {
+		return nil, err
+	}
+	c.tlsEnabled = true
+	c.serverCert = certificate.Certificate[0]
+	c.comm = comm
+	return c, nil
+}
+
+// HandleChain creates the chain of a channel. Implements the consensus.Consenter interface.
+// The metadata is the orderer metadata of the last block, which records the raft IDs of the
+// consenters. If this orderer was added to the consenters after the last block, the chain
+// catches up with the channel before joining the consenters. A chain is created for the
+// channels this orderer is not a consenter of too, and rejects the messages broadcast to it
+func (c *Consenter) HandleChain(support consensus.ConsenterSupport, metadata *cb.Metadata) (consensus.Chain, error) {
+	configMetadata, err := consensusMetadata(support.SharedConfig())
+	if err != nil {
+		return nil, fmt.Errorf("invalid consensus metadata of channel %s: %s", support.ChainID(), err)
+	}
+	opts, err := newOptions(configMetadata.Options)
+	if err != nil {
+		return nil, fmt.Errorf("invalid options of channel %s: %s", support.ChainID(), err)
+	}
+	raftMetadata, err := readRaftMetadata(metadata, configMetadata)
+	if err != nil {
+		return nil, fmt.Errorf("invalid raft metadata of channel %s: %s", support.ChainID(), err)
+	}
+
+	rpc := newChannelRPC(c.comm, support.ChainID(), support)
+	if raftID, ok := c.selfID(raftMetadata.Consenters); ok {
+		return c.newChain(support, raftID, raftMetadata, opts, rpc)
+	}
+
+	if !c.isConsenter(configMetadata.Consenters) {
+		logger.Warningf("[channel: %s] This orderer is not a consenter of the channel", support.ChainID())
+		return &inactiveChain{channelID: support.ChainID(), doneC: make(chan struct{})}, nil
+	}
+	logger.Infof("[channel: %s] This orderer was added to the consenters of the channel after block %d, catching up",
+		support.ChainID(), support.Height()-1)
+	return newFollower(support, raftMetadata, rpc, c.pullInterval, c.selfID,
+		func(raftID uint64, raftMetadata *etcdraft.RaftMetadata) (*Chain, error) {
+			return c.newChain(support, raftID, raftMetadata, opts, rpc)
+		})
+}
+
+// newChain creates the chain of the raft node with the given ID, and registers it to serve
+// the Cluster service for its channel
+func (c *Consenter) newChain(support consensus.ConsenterSupport, raftID uint64, raftMetadata *etcdraft.RaftMetadata, opts options, rpc *channelRPC) (*Chain, error) {
+	storage, existing, err := CreateStorage(filepath.Join(c.walDir, support.ChainID()), filepath.Join(c.snapDir, support.ChainID()))
+	if err != nil {
+		return nil, fmt.Errorf("could not create raft storage of channel %s: %s", support.ChainID(), err)
+	}
+	chain, err := NewChain(support, raftID, raftMetadata, opts, rpc, storage, existing)
+	if err != nil {
+		storage.Close()
+		return nil, err
+	}
+
+	c.lock.Lock()
+	c.chains[support.ChainID()] = &registeredChain{chain: chain, rpc: rpc}
+	c.lock.Unlock()
+	return chain, nil
+}
+
+func (c *Consenter) selfID(consenters map[uint64]*etcdraft.Consenter) (uint64, bool) {
+	for id, consenter := range consenters {
+		if c.isSelf(consenter) {
+			return id, true
+		}
+	}
+	return 0, false
+}
+
+func (c *Consenter) isConsenter(consenters []*etcdraft.Consenter) bool {
+	for _, consenter := range consenters {
+		if c.isSelf(consenter) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Consenter) isSelf(consenter *etcdraft.Consenter) bool {
+	if !c.tlsEnabled {
+		return endpoint(consenter) == c.endpoint
+	}
+	_, ok := consenterByCert(map[uint64]*etcdraft.Consenter{0: consenter}, c.serverCert, true)
+	return ok
+}
+
+// Step passes a raft message sent by another consenter to the chain of its channel.
+// Implements the etcdraft.ClusterServer interface
+func (c *Consenter) Step(ctx context.Context, req *etcdraft.StepRequest) (*etcdraft.StepResponse, error) {
+	rc, err := c.chain(req.Channel)
+	if err != nil {
+		return nil, err
+	}
+	msg := &raftpb.Message{}
+	if err := msg.Unmarshal(req.Payload); err != nil {
+		return nil, fmt.Errorf("could not unmarshal raft message: %s", err)
+	}
+	sender, err := c.sender(ctx, rc, msg.From)
+	if err != nil {
+		return nil, err
+	}
+	if err := rc.chain.Step(msg, sender); err != nil {
+		return nil, err
+	}
+	return &etcdraft.StepResponse{}, nil
+}
+
+// Submit orders a message forwarded by another consenter of the channel, which this orderer
+// is the leader of. Implements the etcdraft.ClusterServer interface
+func (c *Consenter) Submit(ctx context.Context, req *etcdraft.SubmitRequest) (*etcdraft.SubmitResponse, error) {
+	rc, err := c.chain(req.Channel)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := c.sender(ctx, rc, 0); err != nil {
+		return nil, err
+	}
+	if err := rc.chain.Submit(req, true); err != nil {
+		logger.Debugf("[channel: %s] Rejecting forwarded message: %s", req.Channel, err)
+		return &etcdraft.SubmitResponse{Status: cb.Status_SERVICE_UNAVAILABLE, Info: err.Error()}, nil
+	}
+	return &etcdraft.SubmitResponse{Status: cb.Status_SUCCESS}, nil
+}
+
+func (c *Consenter) chain(channel string) (*registeredChain, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	rc, ok := c.chains[channel]
+	if !ok {
+		return nil, fmt.Errorf("this orderer is not a consenter of channel %s", channel)
+	}
+	return rc, nil
+}
+
+// sender returns the raft ID of the consenter which made a call, which is identified by its
+// client TLS certificate. Without TLS, the consenter is trusted to be the claimed one
+func (c *Consenter) sender(ctx context.Context, rc *registeredChain, claimed uint64) (uint64, error) {
+	if !c.tlsEnabled {
+		return claimed, nil
+	}
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return 0, fmt.Errorf("could not identify the caller")
+	}
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.PeerCertificates) == 0 {
+		return 0, fmt.Errorf("caller presented no client TLS certificate")
+	}
+	id, ok := rc.rpc.consenterByCert(tlsInfo.State.PeerCertificates[0].Raw)
+	if !ok {
+		return 0, fmt.Errorf("caller is not a consenter of channel %s", rc.chain.channelID)
+	}
+	return id, nil
+}
+
+// inactiveChain is the chain of a channel this orderer is not a consenter of
+type inactiveChain struct {
+	channelID string
+	doneC     chan struct{}
+	haltOnce  sync.Once
+}
+
+func (c *inactiveChain) Order(env *cb.Envelope, configSeq uint64) error {
+	return fmt.Errorf("this orderer is not a consenter of channel %s", c.channelID)
+}
+
+func (c *inactiveChain) Configure(configUpdate *cb.Envelope, config *cb.Envelope, configSeq uint64) error {
+	return fmt.Errorf("this orderer is not a consenter of channel %s", c.channelID)
+}
+
+func (c *inactiveChain) Errored() <-chan struct{} {
+	return c.doneC
+}
+
+func (c *inactiveChain) Start() {}
+
+func (c *inactiveChain) Halt() {
+	c.haltOnce.Do(func() { close(c.doneC) })
+}
@@ -0,0 +1,117 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	localconfig "github.com/hyperledger/fabric/orderer/common/localconfig"
+	cb "github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/orderer/etcdraft"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"golang.org/x/net/context"
+)
+
+func newTestConsenter(t *testing.T, dir string, port uint16) *Consenter {
+	conf := &localconfig.TopLevel{
+		General: localconfig.General{
+			ListenAddress: "127.0.0.1",
+			ListenPort:    port,
+			Replication:   localconfig.Replication{PullInterval: 10 * time.Millisecond},
+		},
+		EtcdRaft: localconfig.EtcdRaft{WALDir: filepath.Join(dir, "wal"), SnapDir: filepath.Join(dir, "snap")},
+	}
+	consenter, err := New(conf)
+	require.NoError(t, err)
+	return consenter
+}
+
+func testConsensusMetadata(ports ...uint32) []byte {
+	metadata := &etcdraft.Metadata{Options: &etcdraft.Options{TickInterval: "10ms"}}
+	for _, port := range ports {
+		metadata.Consenters = append(metadata.Consenters, &etcdraft.Consenter{Host: "127.0.0.1", Port: port})
+	}
+	return utils.MarshalOrPanic(metadata)
+}
+
+func TestHandleChain(t *testing.T) {
+	dir, err := ioutil.TempDir("", "etcdraft-consenter")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	consenter := newTestConsenter(t, dir, 7050)
+	support := newTestSupport(cb.NewBlock(0, nil))
+	support.SharedConfigVal.ConsensusMetadataVal = testConsensusMetadata(7050)
+
+	chain, err := consenter.HandleChain(support, nil)
+	require.NoError(t, err)
+	require.IsType(t, &Chain{}, chain)
+	assert.Equal(t, uint64(1), chain.(*Chain).raftID)
+	chain.Start()
+	defer chain.Halt()
+
+	// a single consenter elects itself and orders the messages
+	deadline := time.Now().Add(10 * time.Second)
+	for atomic.LoadUint64(&chain.(*Chain).leader) != 1 {
+		require.True(t, time.Now().Before(deadline), "no leader was elected")
+		time.Sleep(10 * time.Millisecond)
+	}
+	support.SharedConfigVal.BatchSizeVal.MaxMessageCount = 1
+	require.NoError(t, chain.Order(testEnvelope("message"), 0))
+
+	_, err = consenter.Submit(context.Background(), &etcdraft.SubmitRequest{Channel: "otherchannel", Content: testEnvelope("message")})
+	assert.Error(t, err)
+	resp, err := consenter.Submit(context.Background(), &etcdraft.SubmitRequest{Channel: testChannelID, Content: testEnvelope("message")})
+	require.NoError(t, err)
+	assert.Equal(t, cb.Status_SUCCESS, resp.Status)
+}
+
+func TestHandleChainNotConsenter(t *testing.T) {
+	dir, err := ioutil.TempDir("", "etcdraft-consenter")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	consenter := newTestConsenter(t, dir, 7050)
+	support := newTestSupport(cb.NewBlock(0, nil))
+	support.SharedConfigVal.ConsensusMetadataVal = testConsensusMetadata(8050, 9050)
+
+	chain, err := consenter.HandleChain(support, nil)
+	require.NoError(t, err)
+	chain.Start()
+	assert.Error(t, chain.Order(testEnvelope("message"), 0))
+	assert.Error(t, chain.Configure(testEnvelope("update"), testEnvelope("config"), 0))
+	chain.Halt()
+	<-chain.Errored()
+
+	_, err = consenter.Step(context.Background(), &etcdraft.StepRequest{Channel: testChannelID})
+	assert.Error(t, err)
+}
+
+func TestHandleChainBadMetadata(t *testing.T) {
+	dir, err := ioutil.TempDir("", "etcdraft-consenter")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	consenter := newTestConsenter(t, dir, 7050)
+	support := newTestSupport(cb.NewBlock(0, nil))
+
+	support.SharedConfigVal.ConsensusMetadataVal = []byte("garbage")
+	_, err = consenter.HandleChain(support, nil)
+	assert.Error(t, err)
+
+	support.SharedConfigVal.ConsensusMetadataVal = utils.MarshalOrPanic(&etcdraft.Metadata{})
+	_, err = consenter.HandleChain(support, nil)
+	assert.Error(t, err)
+}
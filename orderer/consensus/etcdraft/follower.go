@@ -0,0 +1,187 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric/orderer/consensus"
+	cb "github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/orderer/etcdraft"
+)
+
+// follower is the chain of a channel this orderer is a consenter of as of the channel config,
+// but not as of the last block of its ledger, as happens when the orderer is added to the
+// consenters of a channel. The follower is read-only: it pulls the blocks of the channel from
+// the consenters until it writes the config block adding this orderer, and then joins the
+// consenters with a raft node, to which it passes the messages from then on
+type follower struct {
+	support      consensus.ConsenterSupport
+	rpc          RPC
+	raftMetadata *etcdraft.RaftMetadata
+	lastBlock    *cb.Block
+	pullInterval time.Duration
+
+	// selfID returns the raft ID of this orderer among the given consenters
+	selfID func(consenters map[uint64]*etcdraft.Consenter) (uint64, bool)
+	// join creates the chain of the raft node joining the consenters
+	join func(raftID uint64, raftMetadata *etcdraft.RaftMetadata) (*Chain, error)
+
+	lock  sync.RWMutex
+	chain *Chain
+
+	startC   chan struct{}
+	haltC    chan struct{}
+	doneC    chan struct{}
+	haltOnce sync.Once
+}
+
+func newFollower(
+	support consensus.ConsenterSupport,
+	raftMetadata *etcdraft.RaftMetadata,
+	rpc RPC,
+	pullInterval time.Duration,
+	selfID func(consenters map[uint64]*etcdraft.Consenter) (uint64, bool),
+	join func(raftID uint64, raftMetadata *etcdraft.RaftMetadata) (*Chain, error),
+) (*follower, error) {
+	lastBlock := support.Block(support.Height() - 1)
+	if lastBlock == nil {
+		return nil, fmt.Errorf("could not read block %d", support.Height()-1)
+	}
+	rpc.Configure(copyConsenters(raftMetadata.Consenters))
+	return &follower{
+		support:      support,
+		rpc:          rpc,
+		raftMetadata: raftMetadata,
+		lastBlock:    lastBlock,
+		pullInterval: pullInterval,
+		selfID:       selfID,
+		join:         join,
+		startC:       make(chan struct{}),
+		haltC:        make(chan struct{}),
+		doneC:        make(chan struct{}),
+	}, nil
+}
+
+// Start starts pulling the blocks of the channel
+func (f *follower) Start() {
+	close(f.startC)
+	go f.run()
+}
+
+// Halt stops pulling the blocks of the channel, or halts the chain of the raft node once joined
+func (f *follower) Halt() {
+	f.haltOnce.Do(func() { close(f.haltC) })
+	select {
+	case <-f.startC:
+		<-f.doneC
+	default:
+	}
+}
+
+// Errored returns a channel which is closed once the follower stopped, or the chain of the raft
+// node it joined stopped
+func (f *follower) Errored() <-chan struct{} {
+	return f.doneC
+}
+
+// Order passes a normal message to the chain of the raft node once joined
+func (f *follower) Order(env *cb.Envelope, configSeq uint64) error {
+	chain, err := f.joined()
+	if err != nil {
+		return err
+	}
+	return chain.Order(env, configSeq)
+}
+
+// Configure passes a config message to the chain of the raft node once joined
+func (f *follower) Configure(configUpdate *cb.Envelope, config *cb.Envelope, configSeq uint64) error {
+	chain, err := f.joined()
+	if err != nil {
+		return err
+	}
+	return chain.Configure(configUpdate, config, configSeq)
+}
+
+func (f *follower) joined() (*Chain, error) {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+	if f.chain == nil {
+		return nil, fmt.Errorf("this orderer is catching up with channel %s before joining its consenters", f.support.ChainID())
+	}
+	return f.chain, nil
+}
+
+func (f *follower) run() {
+	defer close(f.doneC)
+	ticker := time.NewTicker(f.pullInterval)
+	defer ticker.Stop()
+
+	for {
+		if raftID, ok := f.selfID(f.raftMetadata.Consenters); ok {
+			f.joinConsenters(raftID)
+			return
+		}
+		f.pull()
+		select {
+		case <-ticker.C:
+		case <-f.haltC:
+			return
+		}
+	}
+}
+
+func (f *follower) joinConsenters(raftID uint64) {
+	logger.Infof("[channel: %s] Caught up with block %d, joining the consenters as raft node %d",
+		f.support.ChainID(), f.lastBlock.Header.Number, raftID)
+	chain, err := f.join(raftID, f.raftMetadata)
+	if err != nil {
+		logger.Errorf("[channel: %s] Failed to join the consenters: %s", f.support.ChainID(), err)
+		return
+	}
+	f.lock.Lock()
+	f.chain = chain
+	f.lock.Unlock()
+
+	chain.Start()
+	select {
+	case <-chain.Errored():
+	case <-f.haltC:
+		chain.Halt()
+	}
+}
+
+// pull writes the blocks the consenters wrote since the last block
+func (f *follower) pull() {
+	for _, id := range sortedIDs(f.raftMetadata.Consenters) {
+		height, err := f.rpc.Height(id)
+		if err != nil {
+			logger.Debugf("[channel: %s] Failed to read height of the chain of raft node %d: %s", f.support.ChainID(), id, err)
+			continue
+		}
+		if height <= f.lastBlock.Header.Number+1 {
+			continue
+		}
+		err = f.rpc.Pull(id, f.lastBlock.Header.Number+1, height-1, func(block *cb.Block) error {
+			raftMetadata, err := writePulledBlock(f.support, f.lastBlock, block)
+			if err != nil {
+				return err
+			}
+			f.raftMetadata = raftMetadata
+			f.lastBlock = block
+			return nil
+		})
+		f.rpc.Configure(copyConsenters(f.raftMetadata.Consenters))
+		if err != nil {
+			logger.Warningf("[channel: %s] Failed to pull blocks from raft node %d: %s", f.support.ChainID(), id, err)
+			continue
+		}
+		return
+	}
+}
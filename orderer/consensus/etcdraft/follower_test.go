@@ -0,0 +1,130 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	channelconfig "github.com/hyperledger/fabric/common/config/channel"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/orderer/etcdraft"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testConsenters(ids ...uint32) []*etcdraft.Consenter {
+	var consenters []*etcdraft.Consenter
+	for _, id := range ids {
+		consenters = append(consenters, &etcdraft.Consenter{Host: "orderer", Port: id})
+	}
+	return consenters
+}
+
+func testConfigEnvelope(consenters []*etcdraft.Consenter) *cb.Envelope {
+	consensusType := &ab.ConsensusType{
+		Type:     ConsensusType,
+		Metadata: utils.MarshalOrPanic(&etcdraft.Metadata{Consenters: consenters}),
+	}
+	config := &cb.ConfigEnvelope{Config: &cb.Config{ChannelGroup: &cb.ConfigGroup{
+		Groups: map[string]*cb.ConfigGroup{
+			channelconfig.OrdererGroupKey: {
+				Values: map[string]*cb.ConfigValue{
+					channelconfig.ConsensusTypeKey: {Value: utils.MarshalOrPanic(consensusType)},
+				},
+			},
+		},
+	}}}
+	return &cb.Envelope{Payload: utils.MarshalOrPanic(&cb.Payload{
+		Header: &cb.Header{ChannelHeader: utils.MarshalOrPanic(&cb.ChannelHeader{
+			Type:      int32(cb.HeaderType_CONFIG),
+			ChannelId: testChannelID,
+		})},
+		Data: utils.MarshalOrPanic(config),
+	})}
+}
+
+func TestFollowerJoinsConsenters(t *testing.T) {
+	tc := newTestCluster(t, 3)
+	defer tc.stop()
+	tc.startAll()
+	leader := tc.leader()
+
+	chain, err := tc.network.chain(leader)
+	require.NoError(t, err)
+	require.NoError(t, chain.Order(testEnvelope("message 0"), 0))
+	require.NoError(t, chain.Order(testEnvelope("message 1"), 0))
+	tc.waitHeight(2)
+
+	// the fourth orderer starts from the genesis block, which does not list it
+	support := newTestSupport(tc.genesis)
+	raftMetadata, err := readRaftMetadata(nil, &etcdraft.Metadata{Consenters: testConsenters(1, 2, 3)})
+	require.NoError(t, err)
+	selfID := func(consenters map[uint64]*etcdraft.Consenter) (uint64, bool) {
+		for id, consenter := range consenters {
+			if consenter.Port == 4 {
+				return id, true
+			}
+		}
+		return 0, false
+	}
+	rpc := &testRPC{network: tc.network, self: 4}
+	join := func(raftID uint64, raftMetadata *etcdraft.RaftMetadata) (*Chain, error) {
+		nodeDir := filepath.Join(tc.dir, "node4")
+		storage, existing, err := CreateStorage(filepath.Join(nodeDir, "wal"), filepath.Join(nodeDir, "snap"))
+		if err != nil {
+			return nil, err
+		}
+		chain, err := NewChain(support, raftID, raftMetadata, tc.opts, rpc, storage, existing)
+		if err != nil {
+			return nil, err
+		}
+		tc.network.lock.Lock()
+		tc.network.chains[raftID] = chain
+		tc.network.lock.Unlock()
+		return chain, nil
+	}
+	f, err := newFollower(support, raftMetadata, rpc, 10*time.Millisecond, selfID, join)
+	require.NoError(t, err)
+	f.Start()
+	defer f.Halt()
+
+	// the follower pulls the blocks, but does not order messages
+	tc.network.lock.Lock()
+	tc.network.support[4] = support
+	tc.network.lock.Unlock()
+	tc.waitHeight(2)
+	assert.Error(t, f.Order(testEnvelope("message 2"), 0))
+
+	// the follower joins once it writes the config block adding it
+	require.NoError(t, chain.Configure(testEnvelope("update"), testConfigEnvelope(testConsenters(1, 2, 3, 4)), 0))
+	tc.waitHeight(3)
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		if _, err := f.joined(); err == nil {
+			break
+		}
+		require.True(t, time.Now().Before(deadline), "follower did not join the consenters")
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	leader = tc.leader()
+	for i := 2; i < 6; i++ {
+		require.NoError(t, f.Order(testEnvelope(fmt.Sprintf("message %d", i)), 0))
+	}
+	tc.waitHeight(5)
+	tc.assertSameLedgers(1, 2, 3, 4)
+
+	raftMetadata, err = blockRaftMetadata(support.Block(support.Height() - 1))
+	require.NoError(t, err)
+	assert.Len(t, raftMetadata.Consenters, 4)
+	assert.Equal(t, uint64(5), raftMetadata.NextConsenterId)
+}
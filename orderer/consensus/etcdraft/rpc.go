@@ -0,0 +1,187 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/coreos/etcd/raft/raftpb"
+	"github.com/hyperledger/fabric/common/crypto"
+	"github.com/hyperledger/fabric/core/comm"
+	"github.com/hyperledger/fabric/orderer/common/replication"
+	cb "github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/orderer/etcdraft"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// rpcTimeout bounds the time a Step or Submit call to another consenter may take
+const rpcTimeout = 5 * time.Second
+
+// RPC is used by a chain to reach the other consenters of its channel, which it
+// identifies by their raft IDs
+type RPC interface {
+	// Configure sets the consenters the chain may reach
+	Configure(consenters map[uint64]*etcdraft.Consenter)
+
+	// Step sends a raft message to the given consenter
+	Step(dest uint64, msg *raftpb.Message) error
+
+	// Submit passes a message broadcast to this node on to the given consenter, which is the leader
+	Submit(dest uint64, req *etcdraft.SubmitRequest) (*etcdraft.SubmitResponse, error)
+
+	// Pull delivers the blocks of the channel from start to end, inclusive, from the ledger of the
+	// given consenter
+	Pull(dest uint64, start, end uint64, deliver func(block *cb.Block) error) error
+
+	// Height returns the number of blocks of the channel in the ledger of the given consenter
+	Height(dest uint64) (uint64, error)
+}
+
+// Comm holds the connections of an orderer to the other consenters. The connections
+// are shared by the chains of all the channels
+type Comm struct {
+	tlsEnabled bool
+	creds      credentials.TransportCredentials
+
+	lock  sync.Mutex
+	conns map[string]*grpc.ClientConn
+}
+
+// NewComm creates a Comm which connects to the other consenters with the given TLS
+// certificate, trusting the given root CAs. TLS is not used if certificate is nil
+func NewComm(certificate *tls.Certificate, rootCAs [][]byte) (*Comm, error) {
+	c := &Comm{conns: make(map[string]*grpc.ClientConn)}
+	if certificate == nil {
+		return c, nil
+	}
+
+	certPool := x509.NewCertPool()
+	for _, rootCA := range rootCAs {
+		if !certPool.AppendCertsFromPEM(rootCA) {
+			return nil, fmt.Errorf("could not parse root CA certificate")
+		}
+	}
+	c.tlsEnabled = true
+	c.creds = credentials.NewTLS(&tls.Config{
+		Certificates: []tls.Certificate{*certificate},
+		RootCAs:      certPool,
+	})
+	return c, nil
+}
+
+func (c *Comm) conn(endpoint string) (*grpc.ClientConn, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if conn, ok := c.conns[endpoint]; ok {
+		return conn, nil
+	}
+	conn, err := comm.NewClientConnectionWithAddress(endpoint, false, c.tlsEnabled, c.creds)
+	if err != nil {
+		return nil, fmt.Errorf("could not connect to %s: %s", endpoint, err)
+	}
+	c.conns[endpoint] = conn
+	return conn, nil
+}
+
+// Close closes the connections to the other consenters
+func (c *Comm) Close() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for endpoint, conn := range c.conns {
+		conn.Close()
+		delete(c.conns, endpoint)
+	}
+}
+
+// channelRPC implements RPC for the chain of a channel over the connections of a Comm
+type channelRPC struct {
+	comm    *Comm
+	channel string
+	signer  crypto.LocalSigner
+
+	lock       sync.RWMutex
+	consenters map[uint64]*etcdraft.Consenter
+}
+
+func newChannelRPC(comm *Comm, channel string, signer crypto.LocalSigner) *channelRPC {
+	return &channelRPC{comm: comm, channel: channel, signer: signer}
+}
+
+func (r *channelRPC) Configure(consenters map[uint64]*etcdraft.Consenter) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.consenters = consenters
+}
+
+// consenterByCert returns the raft ID of the consenter with the given DER encoded client TLS certificate
+func (r *channelRPC) consenterByCert(der []byte) (uint64, bool) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	return consenterByCert(r.consenters, der, false)
+}
+
+func (r *channelRPC) conn(dest uint64) (*grpc.ClientConn, error) {
+	r.lock.RLock()
+	consenter, ok := r.consenters[dest]
+	r.lock.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("consenter %d is not a member of channel %s", dest, r.channel)
+	}
+	return r.comm.conn(endpoint(consenter))
+}
+
+func (r *channelRPC) Step(dest uint64, msg *raftpb.Message) error {
+	conn, err := r.conn(dest)
+	if err != nil {
+		return err
+	}
+	payload, err := msg.Marshal()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), rpcTimeout)
+	defer cancel()
+	_, err = etcdraft.NewClusterClient(conn).Step(ctx, &etcdraft.StepRequest{Channel: r.channel, Payload: payload})
+	return err
+}
+
+func (r *channelRPC) Submit(dest uint64, req *etcdraft.SubmitRequest) (*etcdraft.SubmitResponse, error) {
+	conn, err := r.conn(dest)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), rpcTimeout)
+	defer cancel()
+	return etcdraft.NewClusterClient(conn).Submit(ctx, req)
+}
+
+func (r *channelRPC) Pull(dest uint64, start, end uint64, deliver func(block *cb.Block) error) error {
+	conn, err := r.conn(dest)
+	if err != nil {
+		return err
+	}
+	return replication.PullBlocks(conn, r.channel, r.signer, start, end, deliver)
+}
+
+func (r *channelRPC) Height(dest uint64) (uint64, error) {
+	conn, err := r.conn(dest)
+	if err != nil {
+		return 0, err
+	}
+	return replication.Height(conn, r.channel, r.signer)
+}
+
+func endpoint(consenter *etcdraft.Consenter) string {
+	return fmt.Sprintf("%s:%d", consenter.Host, consenter.Port)
+}
@@ -0,0 +1,152 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/coreos/etcd/raft/raftpb"
+)
+
+const (
+	snapSuffix = ".snap"
+
+	// snapRetained is the number of snapshots kept on disk
+	snapRetained = 3
+)
+
+// ErrNoSnapshot is returned by Snapshotter.Load when there is no valid snapshot on disk
+var ErrNoSnapshot = errors.New("no snapshot available")
+
+// Snapshotter saves the raft snapshots of a channel to files named after the term and
+// the index of the snapshot, each holding a CRC32-C checksum followed by the snapshot
+type Snapshotter struct {
+	dir string
+}
+
+// NewSnapshotter returns a Snapshotter which saves the snapshots to the given directory,
+// creating the directory if it does not exist
+func NewSnapshotter(dir string) (*Snapshotter, error) {
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return nil, fmt.Errorf("could not create snapshot directory %s: %s", dir, err)
+	}
+	return &Snapshotter{dir: dir}, nil
+}
+
+// SaveSnap writes the snapshot to disk, and removes the oldest snapshots beyond the
+// number retained
+func (s *Snapshotter) SaveSnap(snapshot raftpb.Snapshot) error {
+	data, err := snapshot.Marshal()
+	if err != nil {
+		return err
+	}
+	content := make([]byte, 4+len(data))
+	binary.BigEndian.PutUint32(content[:4], crc32.Checksum(data, crcTable))
+	copy(content[4:], data)
+
+	name := fmt.Sprintf("%016x-%016x%s", snapshot.Metadata.Term, snapshot.Metadata.Index, snapSuffix)
+	path := filepath.Join(s.dir, name)
+	tmp := path + ".tmp"
+	if err := writeFileSync(tmp, content); err != nil {
+		return fmt.Errorf("could not write snapshot %s: %s", tmp, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("could not rename snapshot %s: %s", tmp, err)
+	}
+	if err := syncDir(s.dir); err != nil {
+		return err
+	}
+	return s.purge()
+}
+
+// Load returns the newest valid snapshot on disk, or ErrNoSnapshot if there is none
+func (s *Snapshotter) Load() (raftpb.Snapshot, error) {
+	names, err := s.snapNames()
+	if err != nil {
+		return raftpb.Snapshot{}, err
+	}
+	for i := len(names) - 1; i >= 0; i-- {
+		snapshot, err := s.read(names[i])
+		if err != nil {
+			logger.Warningf("Ignoring snapshot %s: %s", names[i], err)
+			continue
+		}
+		return snapshot, nil
+	}
+	return raftpb.Snapshot{}, ErrNoSnapshot
+}
+
+func (s *Snapshotter) read(name string) (raftpb.Snapshot, error) {
+	var snapshot raftpb.Snapshot
+	content, err := ioutil.ReadFile(filepath.Join(s.dir, name))
+	if err != nil {
+		return snapshot, err
+	}
+	if len(content) < 4 {
+		return snapshot, fmt.Errorf("snapshot is truncated")
+	}
+	if crc32.Checksum(content[4:], crcTable) != binary.BigEndian.Uint32(content[:4]) {
+		return snapshot, fmt.Errorf("checksum mismatch")
+	}
+	err = snapshot.Unmarshal(content[4:])
+	return snapshot, err
+}
+
+// snapNames returns the names of the snapshot files, from the oldest to the newest
+func (s *Snapshotter) snapNames() ([]string, error) {
+	files, err := ioutil.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("could not read snapshot directory %s: %s", s.dir, err)
+	}
+	var names []string
+	for _, fi := range files {
+		if strings.HasSuffix(fi.Name(), snapSuffix) {
+			names = append(names, fi.Name())
+		}
+	}
+	// the zero padded term and index sort the names in the order of the snapshots
+	sort.Strings(names)
+	return names, nil
+}
+
+func (s *Snapshotter) purge() error {
+	names, err := s.snapNames()
+	if err != nil {
+		return err
+	}
+	for len(names) > snapRetained {
+		if err := os.Remove(filepath.Join(s.dir, names[0])); err != nil {
+			return fmt.Errorf("could not remove snapshot %s: %s", names[0], err)
+		}
+		names = names[1:]
+	}
+	return nil
+}
+
+func writeFileSync(path string, content []byte) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0640)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(content); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
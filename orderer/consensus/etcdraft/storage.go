@@ -0,0 +1,146 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"fmt"
+
+	"github.com/coreos/etcd/raft"
+	"github.com/coreos/etcd/raft/raftpb"
+)
+
+// snapshotCatchUpEntries is the number of entries kept in memory below the index of a
+// snapshot, so that the followers lagging slightly behind catch up without a snapshot
+const snapshotCatchUpEntries = 16
+
+// RaftStorage keeps the raft log of a channel in memory for the raft node, and persists
+// it to a write ahead log and snapshot files so that it survives a restart
+type RaftStorage struct {
+	ram  *raft.MemoryStorage
+	wal  *WAL
+	snap *Snapshotter
+
+	snapshot raftpb.Snapshot
+}
+
+// CreateStorage opens the WAL and snapshots of a channel in the given directories, and
+// loads them into memory. It returns whether a WAL existed already, in which case the raft
+// node of the channel must be restarted rather than started
+func CreateStorage(walDir, snapDir string) (*RaftStorage, bool, error) {
+	snap, err := NewSnapshotter(snapDir)
+	if err != nil {
+		return nil, false, err
+	}
+	wal, existing, err := OpenWAL(walDir)
+	if err != nil {
+		return nil, false, err
+	}
+
+	rs := &RaftStorage{ram: raft.NewMemoryStorage(), wal: wal, snap: snap}
+	if !existing {
+		return rs, false, nil
+	}
+
+	snapshot, err := snap.Load()
+	if err != nil && err != ErrNoSnapshot {
+		wal.Close()
+		return nil, false, err
+	}
+	if err == nil {
+		logger.Debugf("Loaded snapshot at term %d and index %d", snapshot.Metadata.Term, snapshot.Metadata.Index)
+		if err := rs.ram.ApplySnapshot(snapshot); err != nil {
+			wal.Close()
+			return nil, false, fmt.Errorf("could not apply snapshot: %s", err)
+		}
+		rs.snapshot = snapshot
+	}
+
+	state, entries, err := wal.ReadAll(snapshot.Metadata.Index)
+	if err != nil {
+		wal.Close()
+		return nil, false, err
+	}
+	logger.Debugf("Loaded %d entries and hard state %+v from the WAL", len(entries), state)
+	if err := rs.ram.SetHardState(state); err != nil {
+		wal.Close()
+		return nil, false, fmt.Errorf("could not set hard state: %s", err)
+	}
+	if err := rs.ram.Append(entries); err != nil {
+		wal.Close()
+		return nil, false, fmt.Errorf("could not append entries: %s", err)
+	}
+	return rs, true, nil
+}
+
+// Store persists the entries, hard state and snapshot of a raft Ready, then makes them
+// available to the raft node
+func (rs *RaftStorage) Store(entries []raftpb.Entry, state raftpb.HardState, snapshot raftpb.Snapshot, sync bool) error {
+	if !raft.IsEmptySnap(snapshot) {
+		if err := rs.snap.SaveSnap(snapshot); err != nil {
+			return err
+		}
+	}
+	if err := rs.wal.Save(state, entries, sync); err != nil {
+		return err
+	}
+	if !raft.IsEmptySnap(snapshot) {
+		if err := rs.ram.ApplySnapshot(snapshot); err != nil {
+			if err == raft.ErrSnapOutOfDate {
+				logger.Warningf("Attempted to apply out of date snapshot at term %d and index %d",
+					snapshot.Metadata.Term, snapshot.Metadata.Index)
+			} else {
+				return fmt.Errorf("could not apply snapshot: %s", err)
+			}
+		} else {
+			rs.snapshot = snapshot
+		}
+	}
+	if !raft.IsEmptyHardState(state) {
+		if err := rs.ram.SetHardState(state); err != nil {
+			return fmt.Errorf("could not set hard state: %s", err)
+		}
+	}
+	if err := rs.ram.Append(entries); err != nil {
+		return fmt.Errorf("could not append entries: %s", err)
+	}
+	return nil
+}
+
+// TakeSnapshot creates a snapshot of the state at the given applied index, saves it to
+// disk and compacts the raft log preceding it
+func (rs *RaftStorage) TakeSnapshot(index uint64, confState raftpb.ConfState, data []byte) error {
+	snapshot, err := rs.ram.CreateSnapshot(index, &confState, data)
+	if err != nil {
+		return fmt.Errorf("could not create snapshot at index %d: %s", index, err)
+	}
+	if err := rs.snap.SaveSnap(snapshot); err != nil {
+		return err
+	}
+	rs.snapshot = snapshot
+	if err := rs.wal.ReleaseTo(index); err != nil {
+		return err
+	}
+
+	if index <= snapshotCatchUpEntries {
+		return nil
+	}
+	if err := rs.ram.Compact(index - snapshotCatchUpEntries); err != nil && err != raft.ErrCompacted {
+		return fmt.Errorf("could not compact raft log: %s", err)
+	}
+	logger.Debugf("Took snapshot at index %d and compacted the raft log up to index %d", index, index-snapshotCatchUpEntries)
+	return nil
+}
+
+// Snapshot returns the latest snapshot saved, which is empty if there is none
+func (rs *RaftStorage) Snapshot() raftpb.Snapshot {
+	return rs.snapshot
+}
+
+// Close closes the WAL
+func (rs *RaftStorage) Close() error {
+	return rs.wal.Close()
+}
@@ -0,0 +1,186 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/coreos/etcd/raft/raftpb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testEntries(first, last, term uint64) []raftpb.Entry {
+	var entries []raftpb.Entry
+	for i := first; i <= last; i++ {
+		entries = append(entries, raftpb.Entry{Term: term, Index: i, Data: []byte{byte(i)}})
+	}
+	return entries
+}
+
+func TestWALReopen(t *testing.T) {
+	dir, err := ioutil.TempDir("", "etcdraft-wal")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	wal, existing, err := OpenWAL(dir)
+	require.NoError(t, err)
+	assert.False(t, existing)
+	require.NoError(t, wal.Save(raftpb.HardState{Term: 1, Vote: 1, Commit: 3}, testEntries(1, 5, 1), true))
+	// a new leader overwrites the uncommitted entries
+	require.NoError(t, wal.Save(raftpb.HardState{Term: 2, Vote: 2, Commit: 4}, testEntries(4, 6, 2), true))
+	require.NoError(t, wal.Close())
+
+	wal, existing, err = OpenWAL(dir)
+	require.NoError(t, err)
+	assert.True(t, existing)
+	state, entries, err := wal.ReadAll(0)
+	require.NoError(t, err)
+	assert.Equal(t, raftpb.HardState{Term: 2, Vote: 2, Commit: 4}, state)
+	assert.Equal(t, append(testEntries(1, 3, 1), testEntries(4, 6, 2)...), entries)
+
+	// the WAL is appended to once read
+	require.NoError(t, wal.Save(raftpb.HardState{Term: 2, Vote: 2, Commit: 7}, testEntries(7, 7, 2), true))
+	require.NoError(t, wal.Close())
+
+	wal, _, err = OpenWAL(dir)
+	require.NoError(t, err)
+	defer wal.Close()
+	state, entries, err = wal.ReadAll(4)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(7), state.Commit)
+	assert.Equal(t, testEntries(5, 7, 2), entries)
+}
+
+func TestWALTornRecord(t *testing.T) {
+	dir, err := ioutil.TempDir("", "etcdraft-wal")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	wal, _, err := OpenWAL(dir)
+	require.NoError(t, err)
+	require.NoError(t, wal.Save(raftpb.HardState{Term: 1, Commit: 3}, testEntries(1, 3, 1), true))
+	require.NoError(t, wal.Close())
+
+	segments, err := filepath.Glob(filepath.Join(dir, "*"+walSuffix))
+	require.NoError(t, err)
+	require.Len(t, segments, 1)
+	info, err := os.Stat(segments[0])
+	require.NoError(t, err)
+	require.NoError(t, os.Truncate(segments[0], info.Size()-1))
+
+	wal, _, err = OpenWAL(dir)
+	require.NoError(t, err)
+	defer wal.Close()
+	// the torn hard state is dropped, the entries preceding it are kept
+	state, entries, err := wal.ReadAll(0)
+	require.NoError(t, err)
+	assert.True(t, isEmptyHardState(state))
+	assert.Equal(t, testEntries(1, 3, 1), entries)
+
+	// the WAL is appended to after the last complete record
+	require.NoError(t, wal.Save(raftpb.HardState{Term: 1, Commit: 4}, testEntries(4, 4, 1), true))
+	require.NoError(t, wal.Close())
+	wal, _, err = OpenWAL(dir)
+	require.NoError(t, err)
+	state, entries, err = wal.ReadAll(0)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(4), state.Commit)
+	assert.Equal(t, testEntries(1, 4, 1), entries)
+}
+
+func TestWALCorruptRecord(t *testing.T) {
+	dir, err := ioutil.TempDir("", "etcdraft-wal")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	wal, _, err := OpenWAL(dir)
+	require.NoError(t, err)
+	require.NoError(t, wal.Save(raftpb.HardState{Term: 1, Commit: 3}, testEntries(1, 3, 1), true))
+	require.NoError(t, wal.Close())
+
+	segments, err := filepath.Glob(filepath.Join(dir, "*"+walSuffix))
+	require.NoError(t, err)
+	content, err := ioutil.ReadFile(segments[0])
+	require.NoError(t, err)
+	content[walRecordHeaderSize+1] ^= 0xff
+	require.NoError(t, ioutil.WriteFile(segments[0], content, 0640))
+
+	wal, _, err = OpenWAL(dir)
+	require.NoError(t, err)
+	defer wal.Close()
+	_, _, err = wal.ReadAll(0)
+	assert.Error(t, err)
+}
+
+func TestSnapshotter(t *testing.T) {
+	dir, err := ioutil.TempDir("", "etcdraft-snap")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	snap, err := NewSnapshotter(dir)
+	require.NoError(t, err)
+	_, err = snap.Load()
+	assert.Equal(t, ErrNoSnapshot, err)
+
+	for i := uint64(1); i <= snapRetained+2; i++ {
+		require.NoError(t, snap.SaveSnap(raftpb.Snapshot{
+			Data:     []byte{byte(i)},
+			Metadata: raftpb.SnapshotMetadata{Term: 1, Index: i * 10, ConfState: raftpb.ConfState{Nodes: []uint64{1, 2}}},
+		}))
+	}
+	names, err := snap.snapNames()
+	require.NoError(t, err)
+	assert.Len(t, names, snapRetained)
+
+	snapshot, err := snap.Load()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(50), snapshot.Metadata.Index)
+	assert.Equal(t, []byte{5}, snapshot.Data)
+
+	// a corrupted snapshot is skipped in favor of the previous one
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, names[len(names)-1]), []byte("garbage"), 0640))
+	snapshot, err = snap.Load()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(40), snapshot.Metadata.Index)
+}
+
+func TestRaftStorageRestart(t *testing.T) {
+	dir, err := ioutil.TempDir("", "etcdraft-storage")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	walDir, snapDir := filepath.Join(dir, "wal"), filepath.Join(dir, "snap")
+
+	rs, existing, err := CreateStorage(walDir, snapDir)
+	require.NoError(t, err)
+	assert.False(t, existing)
+	require.NoError(t, rs.Store(testEntries(1, 30, 1), raftpb.HardState{Term: 1, Vote: 1, Commit: 30}, raftpb.Snapshot{}, true))
+	require.NoError(t, rs.TakeSnapshot(20, raftpb.ConfState{Nodes: []uint64{1}}, []byte("snapshot")))
+	first, err := rs.ram.FirstIndex()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(20-snapshotCatchUpEntries+1), first)
+	require.NoError(t, rs.Close())
+
+	rs, existing, err = CreateStorage(walDir, snapDir)
+	require.NoError(t, err)
+	assert.True(t, existing)
+	defer rs.Close()
+	assert.Equal(t, uint64(20), rs.Snapshot().Metadata.Index)
+	assert.Equal(t, []byte("snapshot"), rs.Snapshot().Data)
+	first, err = rs.ram.FirstIndex()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(21), first)
+	last, err := rs.ram.LastIndex()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(30), last)
+	state, _, err := rs.ram.InitialState()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(30), state.Commit)
+}
@@ -0,0 +1,244 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"bytes"
+	"encoding/pem"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	channelconfig "github.com/hyperledger/fabric/common/config/channel"
+	"github.com/hyperledger/fabric/common/configtx"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/orderer/etcdraft"
+	"github.com/hyperledger/fabric/protos/utils"
+)
+
+// The options applied when the channel config leaves them unset
+const (
+	defaultTickInterval         = 500 * time.Millisecond
+	defaultElectionTick         = 10
+	defaultHeartbeatTick        = 1
+	defaultMaxInflightBlocks    = 5
+	defaultSnapshotIntervalSize = 20 * 1024 * 1024
+)
+
+// options are the options of the raft nodes of a channel, with the defaults applied
+type options struct {
+	tickInterval         time.Duration
+	electionTick         int
+	heartbeatTick        int
+	maxInflightBlocks    int
+	snapshotIntervalSize uint32
+}
+
+func newOptions(opts *etcdraft.Options) (options, error) {
+	o := options{
+		tickInterval:         defaultTickInterval,
+		electionTick:         defaultElectionTick,
+		heartbeatTick:        defaultHeartbeatTick,
+		maxInflightBlocks:    defaultMaxInflightBlocks,
+		snapshotIntervalSize: defaultSnapshotIntervalSize,
+	}
+	if opts == nil {
+		return o, nil
+	}
+	if opts.TickInterval != "" {
+		tickInterval, err := time.ParseDuration(opts.TickInterval)
+		if err != nil {
+			return o, fmt.Errorf("invalid tick interval %s: %s", opts.TickInterval, err)
+		}
+		if tickInterval <= 0 {
+			return o, fmt.Errorf("tick interval must be positive, not %s", opts.TickInterval)
+		}
+		o.tickInterval = tickInterval
+	}
+	if opts.ElectionTick != 0 {
+		o.electionTick = int(opts.ElectionTick)
+	}
+	if opts.HeartbeatTick != 0 {
+		o.heartbeatTick = int(opts.HeartbeatTick)
+	}
+	if o.electionTick <= o.heartbeatTick {
+		return o, fmt.Errorf("election tick (%d) must be greater than heartbeat tick (%d)", o.electionTick, o.heartbeatTick)
+	}
+	if opts.MaxInflightBlocks != 0 {
+		o.maxInflightBlocks = int(opts.MaxInflightBlocks)
+	}
+	if opts.SnapshotIntervalSize != 0 {
+		o.snapshotIntervalSize = opts.SnapshotIntervalSize
+	}
+	return o, nil
+}
+
+// consensusMetadata returns the etcd/raft metadata of the consensus type of the channel config
+func consensusMetadata(ordererConfig channelconfig.Orderer) (*etcdraft.Metadata, error) {
+	metadata := &etcdraft.Metadata{}
+	if err := proto.Unmarshal(ordererConfig.ConsensusMetadata(), metadata); err != nil {
+		return nil, fmt.Errorf("could not unmarshal consensus metadata: %s", err)
+	}
+	if len(metadata.Consenters) == 0 {
+		return nil, fmt.Errorf("consensus metadata lists no consenters")
+	}
+	return metadata, nil
+}
+
+// configEnvelopeMetadata returns the etcd/raft metadata of the channel config carried by the given
+// config transaction. It returns false if the transaction does not reconfigure the channel, as
+// is the case of the transactions of the system channel which create channels
+func configEnvelopeMetadata(env *cb.Envelope) (*etcdraft.Metadata, bool, error) {
+	payload, err := utils.UnmarshalPayload(env.Payload)
+	if err != nil {
+		return nil, false, err
+	}
+	if payload.Header == nil {
+		return nil, false, fmt.Errorf("config transaction has no header")
+	}
+	chdr, err := utils.UnmarshalChannelHeader(payload.Header.ChannelHeader)
+	if err != nil {
+		return nil, false, err
+	}
+	if chdr.Type != int32(cb.HeaderType_CONFIG) {
+		return nil, false, nil
+	}
+
+	configEnvelope, err := configtx.UnmarshalConfigEnvelope(payload.Data)
+	if err != nil {
+		return nil, false, err
+	}
+	if configEnvelope.Config == nil || configEnvelope.Config.ChannelGroup == nil {
+		return nil, false, fmt.Errorf("config transaction has no channel group")
+	}
+	ordererGroup, ok := configEnvelope.Config.ChannelGroup.Groups[channelconfig.OrdererGroupKey]
+	if !ok {
+		return nil, false, fmt.Errorf("config has no orderer group")
+	}
+	value, ok := ordererGroup.Values[channelconfig.ConsensusTypeKey]
+	if !ok {
+		return nil, false, fmt.Errorf("config has no consensus type")
+	}
+	consensusType := &ab.ConsensusType{}
+	if err := proto.Unmarshal(value.Value, consensusType); err != nil {
+		return nil, false, fmt.Errorf("could not unmarshal consensus type: %s", err)
+	}
+	if consensusType.Type != ConsensusType {
+		return nil, false, fmt.Errorf("changing the consensus type to %s is not supported", consensusType.Type)
+	}
+	metadata := &etcdraft.Metadata{}
+	if err := proto.Unmarshal(consensusType.Metadata, metadata); err != nil {
+		return nil, false, fmt.Errorf("could not unmarshal consensus metadata: %s", err)
+	}
+	if len(metadata.Consenters) == 0 {
+		return nil, false, fmt.Errorf("consensus metadata lists no consenters")
+	}
+	return metadata, true, nil
+}
+
+// readRaftMetadata returns the raft metadata recorded in the orderer metadata of the last block
+// of a channel. The metadata of a new channel, whose genesis block carries none, assigns the raft
+// IDs 1 to n to the n consenters of the channel config
+func readRaftMetadata(blockMetadata *cb.Metadata, configMetadata *etcdraft.Metadata) (*etcdraft.RaftMetadata, error) {
+	if blockMetadata != nil && len(blockMetadata.Value) > 0 {
+		raftMetadata := &etcdraft.RaftMetadata{}
+		if err := proto.Unmarshal(blockMetadata.Value, raftMetadata); err != nil {
+			return nil, fmt.Errorf("could not unmarshal raft metadata: %s", err)
+		}
+		return raftMetadata, nil
+	}
+
+	raftMetadata := &etcdraft.RaftMetadata{
+		Consenters:      make(map[uint64]*etcdraft.Consenter),
+		NextConsenterId: 1,
+	}
+	for _, consenter := range configMetadata.Consenters {
+		raftMetadata.Consenters[raftMetadata.NextConsenterId] = consenter
+		raftMetadata.NextConsenterId++
+	}
+	return raftMetadata, nil
+}
+
+// blockRaftMetadata returns the raft metadata recorded in the orderer metadata of the block
+func blockRaftMetadata(block *cb.Block) (*etcdraft.RaftMetadata, error) {
+	if block.Metadata == nil || len(block.Metadata.Metadata) <= int(cb.BlockMetadataIndex_ORDERER) {
+		return nil, fmt.Errorf("block %d has no orderer metadata", block.Header.Number)
+	}
+	blockMetadata, err := utils.GetMetadataFromBlock(block, cb.BlockMetadataIndex_ORDERER)
+	if err != nil {
+		return nil, fmt.Errorf("could not unmarshal orderer metadata of block %d: %s", block.Header.Number, err)
+	}
+	if len(blockMetadata.Value) == 0 {
+		return nil, fmt.Errorf("block %d has no raft metadata", block.Header.Number)
+	}
+	return readRaftMetadata(blockMetadata, nil)
+}
+
+// membershipChanges compares the consenters of the raft metadata with the consenters of a new
+// channel config, and returns the consenters added and the raft IDs of the consenters removed
+func membershipChanges(raftMetadata *etcdraft.RaftMetadata, consenters []*etcdraft.Consenter) (added []*etcdraft.Consenter, removed []uint64) {
+	kept := make(map[uint64]bool)
+	for _, consenter := range consenters {
+		id, ok := consenterID(raftMetadata.Consenters, consenter)
+		if !ok {
+			added = append(added, consenter)
+			continue
+		}
+		kept[id] = true
+	}
+	for id := range raftMetadata.Consenters {
+		if !kept[id] {
+			removed = append(removed, id)
+		}
+	}
+	sort.Slice(removed, func(i, j int) bool { return removed[i] < removed[j] })
+	return added, removed
+}
+
+// consenterID returns the raft ID of the given consenter
+func consenterID(consenters map[uint64]*etcdraft.Consenter, consenter *etcdraft.Consenter) (uint64, bool) {
+	for id, c := range consenters {
+		if proto.Equal(c, consenter) {
+			return id, true
+		}
+	}
+	return 0, false
+}
+
+// consenterByCert returns the raft ID of the consenter whose client or server TLS certificate
+// is the given DER encoded certificate
+func consenterByCert(consenters map[uint64]*etcdraft.Consenter, der []byte, server bool) (uint64, bool) {
+	for id, c := range consenters {
+		cert := c.ClientTlsCert
+		if server {
+			cert = c.ServerTlsCert
+		}
+		if block, _ := pem.Decode(cert); block != nil && bytes.Equal(block.Bytes, der) {
+			return id, true
+		}
+	}
+	return 0, false
+}
+
+// isConfigBlock returns whether the block holds a config transaction, which must be written
+// with WriteConfigBlock
+func isConfigBlock(block *cb.Block) bool {
+	if block.Data == nil || len(block.Data.Data) != 1 {
+		return false
+	}
+	env, err := utils.ExtractEnvelope(block, 0)
+	if err != nil {
+		return false
+	}
+	chdr, err := utils.ChannelHeader(env)
+	if err != nil {
+		return false
+	}
+	return chdr.Type == int32(cb.HeaderType_CONFIG) || chdr.Type == int32(cb.HeaderType_ORDERER_TRANSACTION)
+}
@@ -0,0 +1,97 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	cb "github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/orderer/etcdraft"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewOptions(t *testing.T) {
+	opts, err := newOptions(nil)
+	require.NoError(t, err)
+	assert.Equal(t, options{
+		tickInterval:         defaultTickInterval,
+		electionTick:         defaultElectionTick,
+		heartbeatTick:        defaultHeartbeatTick,
+		maxInflightBlocks:    defaultMaxInflightBlocks,
+		snapshotIntervalSize: defaultSnapshotIntervalSize,
+	}, opts)
+
+	opts, err = newOptions(&etcdraft.Options{TickInterval: "100ms", ElectionTick: 20, HeartbeatTick: 2, MaxInflightBlocks: 3, SnapshotIntervalSize: 1024})
+	require.NoError(t, err)
+	assert.Equal(t, options{
+		tickInterval:         100 * time.Millisecond,
+		electionTick:         20,
+		heartbeatTick:        2,
+		maxInflightBlocks:    3,
+		snapshotIntervalSize: 1024,
+	}, opts)
+
+	_, err = newOptions(&etcdraft.Options{TickInterval: "fast"})
+	assert.Error(t, err)
+	_, err = newOptions(&etcdraft.Options{TickInterval: "-1s"})
+	assert.Error(t, err)
+	_, err = newOptions(&etcdraft.Options{ElectionTick: 2, HeartbeatTick: 2})
+	assert.Error(t, err)
+}
+
+func TestReadRaftMetadata(t *testing.T) {
+	consenters := []*etcdraft.Consenter{{Host: "orderer0", Port: 7050}, {Host: "orderer1", Port: 7050}}
+
+	raftMetadata, err := readRaftMetadata(nil, &etcdraft.Metadata{Consenters: consenters})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3), raftMetadata.NextConsenterId)
+	assert.Equal(t, uint64(0), raftMetadata.RaftIndex)
+	assert.True(t, proto.Equal(consenters[0], raftMetadata.Consenters[1]))
+	assert.True(t, proto.Equal(consenters[1], raftMetadata.Consenters[2]))
+
+	recorded := &etcdraft.RaftMetadata{
+		Consenters:      map[uint64]*etcdraft.Consenter{2: consenters[1], 5: consenters[0]},
+		NextConsenterId: 6,
+		RaftIndex:       42,
+	}
+	raftMetadata, err = readRaftMetadata(&cb.Metadata{Value: utils.MarshalOrPanic(recorded)}, &etcdraft.Metadata{Consenters: consenters})
+	require.NoError(t, err)
+	assert.True(t, proto.Equal(recorded, raftMetadata))
+
+	_, err = readRaftMetadata(&cb.Metadata{Value: []byte("garbage")}, nil)
+	assert.Error(t, err)
+}
+
+func TestMembershipChanges(t *testing.T) {
+	raftMetadata := &etcdraft.RaftMetadata{
+		Consenters: map[uint64]*etcdraft.Consenter{
+			1: {Host: "orderer0", Port: 7050},
+			2: {Host: "orderer1", Port: 7050},
+			3: {Host: "orderer2", Port: 7050},
+		},
+	}
+
+	added, removed := membershipChanges(raftMetadata, []*etcdraft.Consenter{
+		{Host: "orderer0", Port: 7050},
+		{Host: "orderer1", Port: 7050},
+		{Host: "orderer2", Port: 7050},
+	})
+	assert.Empty(t, added)
+	assert.Empty(t, removed)
+
+	added, removed = membershipChanges(raftMetadata, []*etcdraft.Consenter{
+		{Host: "orderer1", Port: 7050},
+		{Host: "orderer3", Port: 7050},
+	})
+	require.Len(t, added, 1)
+	assert.Equal(t, "orderer3", added[0].Host)
+	assert.Equal(t, []uint64{1, 3}, removed)
+}
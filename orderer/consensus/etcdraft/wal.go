@@ -0,0 +1,358 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/coreos/etcd/raft/raftpb"
+)
+
+const (
+	walSuffix = ".wal"
+
+	// walSegmentSize is the size after which the WAL moves on to a new segment file
+	walSegmentSize = 64 * 1024 * 1024
+
+	// walRecordHeaderSize is the size of the length and checksum preceding the type and data of a record
+	walRecordHeaderSize = 8
+)
+
+const (
+	entryRecord byte = iota + 1
+	stateRecord
+)
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+// WAL is the write ahead log of the raft entries and hard states of a channel.
+// It is made of segment files named after their sequence number and the index of
+// the first entry written to them. Each record is framed as the length of its type
+// and data, a CRC32-C checksum of its type and data, its type and its data.
+// Every segment starts with the hard state at the time it was created, so that the
+// segments preceding it can be released once a snapshot covers their entries
+type WAL struct {
+	dir string
+
+	segments []walSegment
+	file     *os.File
+	writer   *bufio.Writer
+	size     int64
+
+	lastIndex uint64
+	state     raftpb.HardState
+}
+
+type walSegment struct {
+	seq   uint64
+	index uint64
+}
+
+func (s walSegment) name() string {
+	return fmt.Sprintf("%016x-%016x%s", s.seq, s.index, walSuffix)
+}
+
+// OpenWAL opens the WAL in the given directory, creating the directory if it does not exist.
+// It returns whether the directory held a WAL already
+func OpenWAL(dir string) (*WAL, bool, error) {
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return nil, false, fmt.Errorf("could not create WAL directory %s: %s", dir, err)
+	}
+	segments, err := listSegments(dir)
+	if err != nil {
+		return nil, false, err
+	}
+	w := &WAL{dir: dir, segments: segments}
+	if len(segments) == 0 {
+		return w, false, w.cut(1)
+	}
+	return w, true, nil
+}
+
+func listSegments(dir string) ([]walSegment, error) {
+	names, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("could not read WAL directory %s: %s", dir, err)
+	}
+	var segments []walSegment
+	for _, fi := range names {
+		if !strings.HasSuffix(fi.Name(), walSuffix) {
+			continue
+		}
+		var s walSegment
+		if _, err := fmt.Sscanf(fi.Name(), "%016x-%016x.wal", &s.seq, &s.index); err != nil {
+			logger.Warningf("Ignoring unexpected file %s in WAL directory %s", fi.Name(), dir)
+			continue
+		}
+		segments = append(segments, s)
+	}
+	sort.Slice(segments, func(i, j int) bool { return segments[i].seq < segments[j].seq })
+	return segments, nil
+}
+
+// ReadAll reads the hard state and the entries following the snapshot at the given index,
+// and prepares the WAL for appending. A record torn by a crash while it was written to the
+// last segment is truncated; a corrupted record anywhere else is an error
+func (w *WAL) ReadAll(snapshotIndex uint64) (raftpb.HardState, []raftpb.Entry, error) {
+	if err := w.Close(); err != nil {
+		return raftpb.HardState{}, nil, err
+	}
+	var entries []raftpb.Entry
+	for i, s := range w.segments {
+		last := i == len(w.segments)-1
+		path := filepath.Join(w.dir, s.name())
+		f, err := os.OpenFile(path, os.O_RDWR, 0640)
+		if err != nil {
+			return raftpb.HardState{}, nil, fmt.Errorf("could not open WAL segment %s: %s", path, err)
+		}
+
+		offset, err := readRecords(f, func(recordType byte, data []byte) error {
+			switch recordType {
+			case entryRecord:
+				var e raftpb.Entry
+				if err := e.Unmarshal(data); err != nil {
+					return err
+				}
+				if e.Index <= snapshotIndex {
+					return nil
+				}
+				// a later entry with the same index replaces the ones written before it
+				switch {
+				case len(entries) == 0 || e.Index > entries[len(entries)-1].Index:
+				case e.Index < entries[0].Index:
+					entries = entries[:0]
+				default:
+					entries = entries[:e.Index-entries[0].Index]
+				}
+				entries = append(entries, e)
+				w.lastIndex = e.Index
+			case stateRecord:
+				if err := w.state.Unmarshal(data); err != nil {
+					return err
+				}
+			default:
+				return fmt.Errorf("unknown record type %d", recordType)
+			}
+			return nil
+		})
+		if err != nil {
+			if !last || err != errTornRecord {
+				f.Close()
+				return raftpb.HardState{}, nil, fmt.Errorf("WAL segment %s is corrupted at offset %d: %s", path, offset, err)
+			}
+			logger.Warningf("Truncating torn record at offset %d of WAL segment %s", offset, path)
+			if err := f.Truncate(offset); err != nil {
+				f.Close()
+				return raftpb.HardState{}, nil, fmt.Errorf("could not truncate WAL segment %s: %s", path, err)
+			}
+		}
+
+		if !last {
+			f.Close()
+			continue
+		}
+		if _, err := f.Seek(offset, io.SeekStart); err != nil {
+			f.Close()
+			return raftpb.HardState{}, nil, fmt.Errorf("could not seek WAL segment %s: %s", path, err)
+		}
+		w.file = f
+		w.writer = bufio.NewWriter(f)
+		w.size = offset
+	}
+	if w.lastIndex < snapshotIndex {
+		w.lastIndex = snapshotIndex
+	}
+	return w.state, entries, nil
+}
+
+var errTornRecord = fmt.Errorf("torn record")
+
+// readRecords passes the records of the file to the given function, and returns the offset
+// following the last record it read. The error is errTornRecord if the last record is incomplete
+// or does not match its checksum, as happens when the process crashes while writing it
+func readRecords(f *os.File, process func(recordType byte, data []byte) error) (int64, error) {
+	reader := bufio.NewReader(f)
+	var offset int64
+	header := make([]byte, walRecordHeaderSize)
+	for {
+		if _, err := io.ReadFull(reader, header); err != nil {
+			if err == io.EOF {
+				return offset, nil
+			}
+			return offset, errTornRecord
+		}
+		length := binary.BigEndian.Uint32(header[:4])
+		checksum := binary.BigEndian.Uint32(header[4:])
+		if length == 0 {
+			return offset, errTornRecord
+		}
+		record := make([]byte, length)
+		if _, err := io.ReadFull(reader, record); err != nil {
+			return offset, errTornRecord
+		}
+		if crc32.Checksum(record, crcTable) != checksum {
+			// only the last record may be torn, a mismatch before it is a corruption
+			if _, err := reader.Peek(1); err == io.EOF {
+				return offset, errTornRecord
+			}
+			return offset, fmt.Errorf("checksum mismatch")
+		}
+		if err := process(record[0], record[1:]); err != nil {
+			return offset, err
+		}
+		offset += int64(walRecordHeaderSize) + int64(length)
+	}
+}
+
+// Save appends the entries and, unless it is empty, the hard state to the WAL.
+// The WAL is synced to disk if sync is true
+func (w *WAL) Save(state raftpb.HardState, entries []raftpb.Entry, sync bool) error {
+	for i := range entries {
+		data, err := entries[i].Marshal()
+		if err != nil {
+			return err
+		}
+		if err := w.write(entryRecord, data); err != nil {
+			return err
+		}
+		w.lastIndex = entries[i].Index
+	}
+	if !isEmptyHardState(state) {
+		w.state = state
+		data, err := state.Marshal()
+		if err != nil {
+			return err
+		}
+		if err := w.write(stateRecord, data); err != nil {
+			return err
+		}
+	}
+
+	if w.size >= walSegmentSize {
+		return w.cut(w.lastIndex + 1)
+	}
+	if sync {
+		return w.sync()
+	}
+	return w.writer.Flush()
+}
+
+func (w *WAL) write(recordType byte, data []byte) error {
+	record := make([]byte, walRecordHeaderSize+1+len(data))
+	record[walRecordHeaderSize] = recordType
+	copy(record[walRecordHeaderSize+1:], data)
+	binary.BigEndian.PutUint32(record[:4], uint32(1+len(data)))
+	binary.BigEndian.PutUint32(record[4:8], crc32.Checksum(record[walRecordHeaderSize:], crcTable))
+	if _, err := w.writer.Write(record); err != nil {
+		return fmt.Errorf("could not write to WAL: %s", err)
+	}
+	w.size += int64(len(record))
+	return nil
+}
+
+func (w *WAL) sync() error {
+	if err := w.writer.Flush(); err != nil {
+		return fmt.Errorf("could not write to WAL: %s", err)
+	}
+	if err := w.file.Sync(); err != nil {
+		return fmt.Errorf("could not sync WAL: %s", err)
+	}
+	return nil
+}
+
+// cut closes the current segment and starts a new one for the entries from the given index on
+func (w *WAL) cut(index uint64) error {
+	if w.file != nil {
+		if err := w.sync(); err != nil {
+			return err
+		}
+		if err := w.file.Close(); err != nil {
+			return fmt.Errorf("could not close WAL segment: %s", err)
+		}
+	}
+
+	s := walSegment{index: index}
+	if len(w.segments) > 0 {
+		s.seq = w.segments[len(w.segments)-1].seq + 1
+	}
+	path := filepath.Join(w.dir, s.name())
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0640)
+	if err != nil {
+		return fmt.Errorf("could not create WAL segment %s: %s", path, err)
+	}
+	w.segments = append(w.segments, s)
+	w.file = f
+	w.writer = bufio.NewWriter(f)
+	w.size = 0
+
+	if !isEmptyHardState(w.state) {
+		data, err := w.state.Marshal()
+		if err != nil {
+			return err
+		}
+		if err := w.write(stateRecord, data); err != nil {
+			return err
+		}
+	}
+	if err := w.sync(); err != nil {
+		return err
+	}
+	return syncDir(w.dir)
+}
+
+// ReleaseTo removes the segments which only hold entries up to the given index.
+// It is invoked once a snapshot covering these entries is saved
+func (w *WAL) ReleaseTo(index uint64) error {
+	released := 0
+	for i := 0; i+1 < len(w.segments); i++ {
+		if w.segments[i+1].index > index+1 {
+			break
+		}
+		path := filepath.Join(w.dir, w.segments[i].name())
+		if err := os.Remove(path); err != nil {
+			return fmt.Errorf("could not remove WAL segment %s: %s", path, err)
+		}
+		released++
+	}
+	w.segments = w.segments[released:]
+	return nil
+}
+
+// Close syncs and closes the WAL
+func (w *WAL) Close() error {
+	if w.file == nil {
+		return nil
+	}
+	if err := w.sync(); err != nil {
+		return err
+	}
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
+
+func isEmptyHardState(state raftpb.HardState) bool {
+	return state.Term == 0 && state.Vote == 0 && state.Commit == 0
+}
+
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
@@ -13,6 +13,7 @@ import (
 
 	"github.com/Shopify/sarama"
 	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/metrics"
 	localconfig "github.com/hyperledger/fabric/orderer/common/localconfig"
 	"github.com/hyperledger/fabric/orderer/common/msgprocessor"
 	"github.com/hyperledger/fabric/orderer/consensus"
@@ -44,12 +45,22 @@ func newChain(consenter commonConsenter, support consensus.ConsenterSupport, las
 	errorChan := make(chan struct{})
 	close(errorChan) // We need this closed when starting up
 
+	var checkpoints *checkpointer
+	if consenter.checkpointOptions().Enabled {
+		checkpoints = &checkpointer{
+			store:   checkpointStore{dir: consenter.checkpointOptions().Dir},
+			chainID: support.ChainID(),
+		}
+	}
+
 	return &chainImpl{
 		consenter:           consenter,
 		support:             support,
 		channel:             newChannel(support.ChainID(), defaultPartition),
 		lastOffsetPersisted: lastOffsetPersisted,
 		lastCutBlockNumber:  lastCutBlockNumber,
+		lastOffsetProcessed: lastOffsetPersisted,
+		checkpoints:         checkpoints,
 
 		errorChan: errorChan,
 		haltChan:  make(chan struct{}),
@@ -65,6 +76,12 @@ type chainImpl struct {
 	lastOffsetPersisted int64
 	lastCutBlockNumber  uint64
 
+	// The last offset processed and the messages pending in the block cutter,
+	// from which the checkpoints are taken. Checkpoints is nil unless enabled.
+	lastOffsetProcessed int64
+	pending             pendingMessages
+	checkpoints         *checkpointer
+
 	producer        sarama.SyncProducer
 	parentConsumer  sarama.Consumer
 	channelConsumer sarama.PartitionConsumer
@@ -188,6 +205,11 @@ func startThread(chain *chainImpl) {
 	}
 	logger.Infof("[channel: %s] Parent consumer set up successfully", chain.channel.topic())
 
+	// Make sure the messages to consume again are still retained by the partition
+	if chain.checkpoints != nil {
+		validateReplayWindow(chain)
+	}
+
 	// Set up the channel consumer
 	chain.channelConsumer, err = setupChannelConsumerForChannel(chain.consenter.retryOptions(), chain.haltChan, chain.parentConsumer, chain.channel, chain.lastOffsetPersisted+1)
 	if err != nil {
@@ -208,6 +230,11 @@ func startThread(chain *chainImpl) {
 // channel's ledger.
 func (chain *chainImpl) processMessagesToBlocks() ([]uint64, error) {
 	counts := make([]uint64, 11) // For metrics and tests
+	// The time regular messages took from being posted to being consumed, as stamped by Kafka 0.10+
+	latency := metrics.NewRootScope().SubScope("consensus").Tagged(map[string]string{
+		"channel": chain.support.ChainID(),
+		"type":    "kafka",
+	}).Timer("consensus_latency")
 	msg := new(ab.KafkaMessage)
 	var timer <-chan time.Time
 
@@ -219,12 +246,23 @@ func (chain *chainImpl) processMessagesToBlocks() ([]uint64, error) {
 		}
 	}()
 
+	var checkpointTicker <-chan time.Time
+	if chain.checkpoints != nil {
+		ticker := time.NewTicker(chain.consenter.checkpointOptions().Interval)
+		defer ticker.Stop()
+		checkpointTicker = ticker.C
+		chain.checkpoint() // Existing channels get their first checkpoint
+	}
+
 	for {
 		select {
 		case <-chain.haltChan:
 			logger.Warningf("[channel: %s] Consenter for channel exiting", chain.support.ChainID())
+			chain.checkpoint()
 			counts[indexExitChanPass]++
 			return counts, nil
+		case <-checkpointTicker:
+			chain.checkpoint()
 		case kafkaErr := <-chain.channelConsumer.Errors():
 			logger.Errorf("[channel: %s] Error during consumption: %s", chain.support.ChainID(), kafkaErr)
 			counts[indexRecvError]++
@@ -257,6 +295,7 @@ func (chain *chainImpl) processMessagesToBlocks() ([]uint64, error) {
 				// This shouldn't happen, it should be filtered at ingress
 				logger.Criticalf("[channel: %s] Unable to unmarshal consumed message = %s", chain.support.ChainID(), err)
 				counts[indexUnmarshalError]++
+				chain.lastOffsetProcessed = in.Offset
 				continue
 			} else {
 				logger.Debugf("[channel: %s] Successfully unmarshalled consumed message, offset is %d. Inspecting type...", chain.support.ChainID(), in.Offset)
@@ -267,7 +306,7 @@ func (chain *chainImpl) processMessagesToBlocks() ([]uint64, error) {
 				_ = processConnect(chain.support.ChainID())
 				counts[indexProcessConnectPass]++
 			case *ab.KafkaMessage_TimeToCut:
-				if err := processTimeToCut(msg.GetTimeToCut(), chain.support, &chain.lastCutBlockNumber, &timer, in.Offset); err != nil {
+				if err := processTimeToCut(msg.GetTimeToCut(), chain.support, &chain.lastCutBlockNumber, &timer, in.Offset, &chain.pending); err != nil {
 					logger.Warningf("[channel: %s] %s", chain.support.ChainID(), err)
 					logger.Criticalf("[channel: %s] Consenter for channel exiting", chain.support.ChainID())
 					counts[indexProcessTimeToCutError]++
@@ -275,13 +314,17 @@ func (chain *chainImpl) processMessagesToBlocks() ([]uint64, error) {
 				}
 				counts[indexProcessTimeToCutPass]++
 			case *ab.KafkaMessage_Regular:
-				if err := processRegular(msg.GetRegular(), chain.support, &timer, in.Offset, &chain.lastCutBlockNumber); err != nil {
+				if !in.Timestamp.IsZero() {
+					latency.Record(time.Since(in.Timestamp))
+				}
+				if err := processRegular(msg.GetRegular(), chain.support, &timer, in.Offset, &chain.lastCutBlockNumber, &chain.pending); err != nil {
 					logger.Warningf("[channel: %s] Error when processing incoming message of type REGULAR = %s", chain.support.ChainID(), err)
 					counts[indexProcessRegularError]++
 				} else {
 					counts[indexProcessRegularPass]++
 				}
 			}
+			chain.lastOffsetProcessed = in.Offset
 		case <-timer:
 			if err := sendTimeToCut(chain.producer, chain.channel, chain.lastCutBlockNumber+1, &timer); err != nil {
 				logger.Errorf("[channel: %s] cannot post time-to-cut message = %s", chain.support.ChainID(), err)
@@ -294,6 +337,15 @@ func (chain *chainImpl) processMessagesToBlocks() ([]uint64, error) {
 	}
 }
 
+// checkpoint persists the last offset whose messages and all the messages
+// preceding it were either written to blocks or discarded, if enabled.
+func (chain *chainImpl) checkpoint() {
+	if chain.checkpoints == nil {
+		return
+	}
+	chain.checkpoints.checkpoint(chain.pending.safeOffset(chain.lastOffsetProcessed), chain.lastCutBlockNumber)
+}
+
 func (chain *chainImpl) closeKafkaObjects() []error {
 	var errs []error
 
@@ -386,7 +438,7 @@ func processConnect(channelName string) error {
 	return nil
 }
 
-func processRegular(regularMessage *ab.KafkaMessageRegular, support consensus.ConsenterSupport, timer *<-chan time.Time, receivedOffset int64, lastCutBlockNumber *uint64) error {
+func processRegular(regularMessage *ab.KafkaMessageRegular, support consensus.ConsenterSupport, timer *<-chan time.Time, receivedOffset int64, lastCutBlockNumber *uint64, pendingMsgs *pendingMessages) error {
 	env := new(cb.Envelope)
 	if err := proto.Unmarshal(regularMessage.Payload, env); err != nil {
 		// This shouldn't happen, it should be filtered at ingress
@@ -421,6 +473,7 @@ func processRegular(regularMessage *ab.KafkaMessageRegular, support consensus.Co
 		encodedLastOffsetPersisted := utils.MarshalOrPanic(&ab.KafkaMetadata{LastOffsetPersisted: receivedOffset})
 		support.WriteConfigBlock(block, encodedLastOffsetPersisted)
 		*lastCutBlockNumber++
+		pendingMsgs.cut()
 		*timer = nil
 	case msgprocessor.NormalMsg:
 		_, err := support.ProcessNormalMsg(env)
@@ -431,6 +484,12 @@ func processRegular(regularMessage *ab.KafkaMessageRegular, support consensus.Co
 
 		batches, pending := support.BlockCutter().Ordered(env)
 		logger.Debugf("[channel: %s] Ordering results: items in batch = %d, pending = %v", support.ChainID(), len(batches), pending)
+		if len(batches) > 0 {
+			pendingMsgs.cut() // The batches hold all the messages pending before this one
+		}
+		if pending {
+			pendingMsgs.enqueued(receivedOffset)
+		}
 		if len(batches) == 0 && *timer == nil {
 			*timer = time.After(support.SharedConfig().BatchTimeout())
 			logger.Debugf("[channel: %s] Just began %s batch timer", support.ChainID(), support.SharedConfig().BatchTimeout().String())
@@ -462,7 +521,7 @@ func processRegular(regularMessage *ab.KafkaMessageRegular, support consensus.Co
 	return nil
 }
 
-func processTimeToCut(ttcMessage *ab.KafkaMessageTimeToCut, support consensus.ConsenterSupport, lastCutBlockNumber *uint64, timer *<-chan time.Time, receivedOffset int64) error {
+func processTimeToCut(ttcMessage *ab.KafkaMessageTimeToCut, support consensus.ConsenterSupport, lastCutBlockNumber *uint64, timer *<-chan time.Time, receivedOffset int64, pendingMsgs *pendingMessages) error {
 	ttcNumber := ttcMessage.GetBlockNumber()
 	logger.Debugf("[channel: %s] It's a time-to-cut message for block %d", support.ChainID(), ttcNumber)
 	if ttcNumber == *lastCutBlockNumber+1 {
@@ -477,6 +536,7 @@ func processTimeToCut(ttcMessage *ab.KafkaMessageTimeToCut, support consensus.Co
 		encodedLastOffsetPersisted := utils.MarshalOrPanic(&ab.KafkaMetadata{LastOffsetPersisted: receivedOffset})
 		support.WriteBlock(block, encodedLastOffsetPersisted)
 		*lastCutBlockNumber++
+		pendingMsgs.cut()
 		logger.Debugf("[channel: %s] Proper time-to-cut received, just cut block %d", support.ChainID(), *lastCutBlockNumber)
 		return nil
 	} else if ttcNumber > *lastCutBlockNumber+1 {
@@ -520,6 +580,20 @@ func sendTimeToCut(producer sarama.SyncProducer, channel channel, timeToCutBlock
 	return err
 }
 
+// Verifies the replay window of a chain, which is not verified if the Kafka
+// cluster cannot be reached.
+func validateReplayWindow(chain *chainImpl) {
+	client, err := sarama.NewClient(chain.support.SharedConfig().KafkaBrokers(), chain.consenter.brokerConfig())
+	if err != nil {
+		logger.Warningf("[channel: %s] Cannot validate the replay window: %s", chain.channel.topic(), err)
+		return
+	}
+	defer client.Close()
+	if err := checkReplayWindow(client, chain.channel, chain.lastOffsetPersisted+1, chain.consenter.checkpointOptions().MaxReplayMessages); err != nil {
+		logger.Panicf("[channel: %s] Cannot resume consuming the partition: %s", chain.channel.topic(), err)
+	}
+}
+
 // Sets up the partition consumer for a channel using the given retry options.
 func setupChannelConsumerForChannel(retryOptions localconfig.Retry, haltChan chan struct{}, parentConsumer sarama.Consumer, channel channel, startFrom int64) (sarama.PartitionConsumer, error) {
 	var err error
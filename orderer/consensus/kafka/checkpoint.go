@@ -0,0 +1,182 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package kafka
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/Shopify/sarama"
+	"github.com/golang/protobuf/proto"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+)
+
+// checkpointStore keeps the checkpoint of each channel in a file of its own.
+// The file is replaced as a whole on every write, so that it holds nothing but
+// the latest checkpoint and is never left partially written.
+type checkpointStore struct {
+	dir string
+}
+
+func (s checkpointStore) path(chainID string) string {
+	return filepath.Join(s.dir, chainID)
+}
+
+// read returns the checkpoint of the channel, or nil if it has none.
+func (s checkpointStore) read(chainID string) (*ab.KafkaCheckpoint, error) {
+	data, err := ioutil.ReadFile(s.path(chainID))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	checkpoint := &ab.KafkaCheckpoint{}
+	if err := proto.Unmarshal(data, checkpoint); err != nil {
+		return nil, fmt.Errorf("cannot unmarshal checkpoint: %s", err)
+	}
+	return checkpoint, nil
+}
+
+// write replaces the checkpoint of the channel.
+func (s checkpointStore) write(chainID string, checkpoint *ab.KafkaCheckpoint) error {
+	data, err := proto.Marshal(checkpoint)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(s.dir, chainID+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path(chainID))
+}
+
+// checkpointer periodically persists the last offset processed by a chain.
+type checkpointer struct {
+	store   checkpointStore
+	chainID string
+	last    ab.KafkaCheckpoint
+}
+
+// checkpoint persists the offset and the number of the last block cut, unless
+// they were already.
+func (c *checkpointer) checkpoint(offset int64, lastCutBlockNumber uint64) {
+	checkpoint := ab.KafkaCheckpoint{LastOffsetProcessed: offset, LastBlockNumber: lastCutBlockNumber}
+	if checkpoint == c.last {
+		return
+	}
+	if err := c.store.write(c.chainID, &checkpoint); err != nil {
+		logger.Errorf("[channel: %s] Cannot persist checkpoint at offset %d: %s", c.chainID, offset, err)
+		return
+	}
+	c.last = checkpoint
+	logger.Debugf("[channel: %s] Persisted checkpoint at offset %d and block %d", c.chainID, offset, lastCutBlockNumber)
+}
+
+// resumeOffset returns the offset past which a chain resumes consuming its
+// partition. The checkpoint of the chain is only trusted if it was taken at the
+// last block of the ledger, otherwise the ledger was either extended since, in
+// which case it records a more recent offset, or replaced, e.g. by a backup.
+// Channels with no checkpoint resume from the offset persisted in the ledger.
+func resumeOffset(checkpoint *ab.KafkaCheckpoint, lastOffsetPersisted int64, lastCutBlockNumber uint64, chainID string) int64 {
+	switch {
+	case checkpoint == nil:
+		logger.Infof("[channel: %s] No checkpoint found, resuming from the offset %d persisted in the ledger", chainID, lastOffsetPersisted)
+		return lastOffsetPersisted
+	case checkpoint.LastBlockNumber > lastCutBlockNumber:
+		logger.Warningf("[channel: %s] Ignoring checkpoint taken at block %d, beyond the last block %d of the ledger",
+			chainID, checkpoint.LastBlockNumber, lastCutBlockNumber)
+		return lastOffsetPersisted
+	case checkpoint.LastBlockNumber < lastCutBlockNumber || checkpoint.LastOffsetProcessed <= lastOffsetPersisted:
+		return lastOffsetPersisted
+	}
+	logger.Infof("[channel: %s] Resuming from the checkpointed offset %d rather than the offset %d persisted in the ledger",
+		chainID, checkpoint.LastOffsetProcessed, lastOffsetPersisted)
+	return checkpoint.LastOffsetProcessed
+}
+
+// pendingMessages tracks the oldest message pending in the block cutter, from
+// which the partition must be consumed again after a restart.
+type pendingMessages struct {
+	pending bool
+	oldest  int64
+}
+
+// enqueued records that the message at the offset is pending in the block
+// cutter.
+func (p *pendingMessages) enqueued(offset int64) {
+	if !p.pending {
+		p.pending = true
+		p.oldest = offset
+	}
+}
+
+// cut records that the messages pending in the block cutter were cut into
+// blocks.
+func (p *pendingMessages) cut() {
+	p.pending = false
+}
+
+// safeOffset returns the last offset whose messages and all the messages
+// preceding it were either written to blocks or discarded, given the last
+// offset processed.
+func (p *pendingMessages) safeOffset(lastOffsetProcessed int64) int64 {
+	if p.pending {
+		return p.oldest - 1
+	}
+	return lastOffsetProcessed
+}
+
+// offsetGetter looks up the offsets of a partition, as sarama.Client does.
+type offsetGetter interface {
+	GetOffset(topic string, partitionID int32, time int64) (int64, error)
+}
+
+// checkReplayWindow verifies that the partition of the channel still retains
+// the message at the offset the chain starts consuming from, and warns when
+// more than maxReplayMessages messages are to be consumed again. The window is
+// not validated when the offsets of the partition cannot be looked up.
+func checkReplayWindow(client offsetGetter, channel channel, startFrom int64, maxReplayMessages int64) error {
+	if startFrom < 0 {
+		return nil // Starting from the oldest offset
+	}
+	oldest, err := client.GetOffset(channel.topic(), channel.partition(), sarama.OffsetOldest)
+	if err != nil {
+		logger.Warningf("[channel: %s] Cannot look up the oldest offset of the partition: %s", channel.topic(), err)
+		return nil
+	}
+	if startFrom < oldest {
+		return fmt.Errorf("the partition no longer retains offset %d, its oldest offset is %d", startFrom, oldest)
+	}
+	newest, err := client.GetOffset(channel.topic(), channel.partition(), sarama.OffsetNewest)
+	if err != nil {
+		logger.Warningf("[channel: %s] Cannot look up the newest offset of the partition: %s", channel.topic(), err)
+		return nil
+	}
+	if replay := newest - startFrom; maxReplayMessages > 0 && replay > maxReplayMessages {
+		logger.Warningf("[channel: %s] Consuming %d messages from offset %d before catching up with the partition",
+			channel.topic(), replay, startFrom)
+	}
+	return nil
+}
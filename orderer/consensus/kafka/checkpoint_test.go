@@ -0,0 +1,172 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package kafka
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Shopify/sarama"
+	"github.com/Shopify/sarama/mocks"
+	mockconfig "github.com/hyperledger/fabric/common/mocks/config"
+	localconfig "github.com/hyperledger/fabric/orderer/common/localconfig"
+	mockblockcutter "github.com/hyperledger/fabric/orderer/mocks/common/blockcutter"
+	mockmultichannel "github.com/hyperledger/fabric/orderer/mocks/common/multichannel"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckpointStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kafka-checkpoint")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	store := checkpointStore{dir: filepath.Join(dir, "checkpoint")}
+
+	checkpoint, err := store.read("mychannel")
+	assert.NoError(t, err)
+	assert.Nil(t, checkpoint, "Expected no checkpoint for a channel never checkpointed")
+
+	assert.NoError(t, store.write("mychannel", &ab.KafkaCheckpoint{LastOffsetProcessed: 5, LastBlockNumber: 2}))
+	assert.NoError(t, store.write("mychannel", &ab.KafkaCheckpoint{LastOffsetProcessed: 7, LastBlockNumber: 3}))
+	checkpoint, err = store.read("mychannel")
+	assert.NoError(t, err)
+	assert.Equal(t, &ab.KafkaCheckpoint{LastOffsetProcessed: 7, LastBlockNumber: 3}, checkpoint)
+	files, err := ioutil.ReadDir(store.dir)
+	assert.NoError(t, err)
+	assert.Len(t, files, 1, "Expected the checkpoint to be replaced")
+
+	assert.NoError(t, ioutil.WriteFile(store.path("otherchannel"), []byte("garbage"), 0644))
+	_, err = store.read("otherchannel")
+	assert.Error(t, err)
+}
+
+func TestResumeOffset(t *testing.T) {
+	testCases := []struct {
+		name       string
+		checkpoint *ab.KafkaCheckpoint
+		expected   int64
+	}{
+		{"NoCheckpoint", nil, 10},
+		{"CheckpointAtLastBlock", &ab.KafkaCheckpoint{LastOffsetProcessed: 15, LastBlockNumber: 3}, 15},
+		{"CheckpointBeforeLastBlock", &ab.KafkaCheckpoint{LastOffsetProcessed: 8, LastBlockNumber: 2}, 10},
+		{"CheckpointBeyondLastBlock", &ab.KafkaCheckpoint{LastOffsetProcessed: 20, LastBlockNumber: 4}, 10},
+		{"CheckpointOlderThanLedger", &ab.KafkaCheckpoint{LastOffsetProcessed: 9, LastBlockNumber: 3}, 10},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, resumeOffset(tc.checkpoint, 10, 3, "mychannel"))
+		})
+	}
+}
+
+func TestPendingMessages(t *testing.T) {
+	p := &pendingMessages{}
+	assert.Equal(t, int64(4), p.safeOffset(4))
+	p.enqueued(5)
+	p.enqueued(6)
+	assert.Equal(t, int64(4), p.safeOffset(7), "Expected the messages from the oldest pending one to be consumed again")
+	p.cut()
+	assert.Equal(t, int64(7), p.safeOffset(7))
+}
+
+type mockOffsetGetter struct {
+	oldest, newest int64
+	err            error
+}
+
+func (m *mockOffsetGetter) GetOffset(topic string, partitionID int32, time int64) (int64, error) {
+	if time == sarama.OffsetOldest {
+		return m.oldest, m.err
+	}
+	return m.newest, m.err
+}
+
+func TestCheckReplayWindow(t *testing.T) {
+	mockChannel := newChannel("mychannel", defaultPartition)
+	client := &mockOffsetGetter{oldest: 100, newest: 1000}
+
+	assert.NoError(t, checkReplayWindow(client, mockChannel, sarama.OffsetOldest, 10), "Expected no validation when starting from the oldest offset")
+	assert.NoError(t, checkReplayWindow(client, mockChannel, 500, 0))
+	assert.NoError(t, checkReplayWindow(client, mockChannel, 500, 10), "Expected a long replay window to only be warned about")
+	assert.EqualError(t, checkReplayWindow(client, mockChannel, 50, 0), "the partition no longer retains offset 50, its oldest offset is 100")
+
+	client.err = fmt.Errorf("unreachable")
+	assert.NoError(t, checkReplayWindow(client, mockChannel, 50, 0), "Expected no validation when the offsets cannot be looked up")
+}
+
+func TestProcessMessagesToBlocksCheckpoint(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kafka-checkpoint")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	mockChannel := newChannel("mockChannelFoo", defaultPartition)
+	mockBrokerConfigCopy := *mockBrokerConfig
+	mockBrokerConfigCopy.ChannelBufferSize = 0
+	mockParentConsumer := mocks.NewConsumer(t, &mockBrokerConfigCopy)
+	mpc := mockParentConsumer.ExpectConsumePartition(mockChannel.topic(), mockChannel.partition(), int64(0))
+	mockChannelConsumer, err := mockParentConsumer.ConsumePartition(mockChannel.topic(), mockChannel.partition(), int64(0))
+	assert.NoError(t, err, "Expected no error when setting up the mock partition consumer")
+
+	consenter := newMockConsenter(mockBrokerConfig, mockLocalConfig.General.TLS, mockLocalConfig.Kafka.Retry, mockLocalConfig.Kafka.Version)
+	consenter.checkpointVal = localconfig.Checkpoint{Enabled: true, Dir: dir, Interval: longTimeout}
+	store := checkpointStore{dir: dir}
+
+	errorChan := make(chan struct{})
+	close(errorChan)
+	haltChan := make(chan struct{})
+	lastCutBlockNumber := uint64(3)
+	mockSupport := &mockmultichannel.ConsenterSupport{
+		Blocks:         make(chan *cb.Block),
+		BlockCutterVal: mockblockcutter.NewReceiver(),
+		ChainIDVal:     mockChannel.topic(),
+		HeightVal:      lastCutBlockNumber,
+		SharedConfigVal: &mockconfig.Orderer{
+			BatchTimeoutVal: longTimeout,
+		},
+	}
+	defer close(mockSupport.BlockCutterVal.Block)
+
+	chain := &chainImpl{
+		consenter:       consenter,
+		parentConsumer:  mockParentConsumer,
+		channelConsumer: mockChannelConsumer,
+
+		channel:             mockChannel,
+		support:             mockSupport,
+		lastCutBlockNumber:  lastCutBlockNumber,
+		lastOffsetProcessed: -1,
+		checkpoints:         &checkpointer{store: store, chainID: mockChannel.topic()},
+
+		errorChan: errorChan,
+		haltChan:  haltChan,
+	}
+
+	done := make(chan struct{})
+	go func() {
+		_, err = chain.processMessagesToBlocks()
+		done <- struct{}{}
+	}()
+
+	// The CONNECT message at offset 1 needs not be consumed again, unlike the
+	// REGULAR message at offset 2 which is pending in the block cutter
+	mpc.YieldMessage(newMockConsumerMessage(newConnectMessage()))
+	mpc.YieldMessage(newMockConsumerMessage(newRegularMessage(utils.MarshalOrPanic(newMockEnvelope("fooMessage")))))
+	mockSupport.BlockCutterVal.Block <- struct{}{}
+
+	close(haltChan)
+	<-done
+	assert.NoError(t, err)
+
+	checkpoint, err := store.read(mockChannel.topic())
+	assert.NoError(t, err)
+	assert.Equal(t, &ab.KafkaCheckpoint{LastOffsetProcessed: 1, LastBlockNumber: lastCutBlockNumber}, checkpoint)
+}
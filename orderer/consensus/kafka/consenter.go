@@ -24,13 +24,14 @@ func init() {
 }
 
 // New creates a Kafka-based consenter. Called by orderer's main.go.
-func New(tlsConfig localconfig.TLS, retryOptions localconfig.Retry, kafkaVersion sarama.KafkaVersion) consensus.Consenter {
+func New(tlsConfig localconfig.TLS, retryOptions localconfig.Retry, kafkaVersion sarama.KafkaVersion, checkpoint localconfig.Checkpoint) consensus.Consenter {
 	brokerConfig := newBrokerConfig(tlsConfig, retryOptions, kafkaVersion, defaultPartition)
 	return &consenterImpl{
 		brokerConfigVal: brokerConfig,
 		tlsConfigVal:    tlsConfig,
 		retryOptionsVal: retryOptions,
-		kafkaVersionVal: kafkaVersion}
+		kafkaVersionVal: kafkaVersion,
+		checkpointVal:   checkpoint}
 }
 
 // consenterImpl holds the implementation of type that satisfies the
@@ -41,6 +42,7 @@ type consenterImpl struct {
 	tlsConfigVal    localconfig.TLS
 	retryOptionsVal localconfig.Retry
 	kafkaVersionVal sarama.KafkaVersion
+	checkpointVal   localconfig.Checkpoint
 }
 
 // HandleChain creates/returns a reference to a consensus.Chain object for the
@@ -50,6 +52,15 @@ type consenterImpl struct {
 // existingChains.
 func (consenter *consenterImpl) HandleChain(support consensus.ConsenterSupport, metadata *cb.Metadata) (consensus.Chain, error) {
 	lastOffsetPersisted := getLastOffsetPersisted(metadata.Value, support.ChainID())
+	if consenter.checkpointVal.Enabled {
+		// The chain resumes past the last offset it processed, if more recent
+		store := checkpointStore{dir: consenter.checkpointVal.Dir}
+		checkpoint, err := store.read(support.ChainID())
+		if err != nil {
+			logger.Warningf("[channel: %s] Ignoring unreadable checkpoint: %s", support.ChainID(), err)
+		}
+		lastOffsetPersisted = resumeOffset(checkpoint, lastOffsetPersisted, getLastCutBlockNumber(support.Height()), support.ChainID())
+	}
 	return newChain(consenter, support, lastOffsetPersisted)
 }
 
@@ -60,6 +71,7 @@ func (consenter *consenterImpl) HandleChain(support consensus.ConsenterSupport,
 type commonConsenter interface {
 	brokerConfig() *sarama.Config
 	retryOptions() localconfig.Retry
+	checkpointOptions() localconfig.Checkpoint
 }
 
 func (consenter *consenterImpl) brokerConfig() *sarama.Config {
@@ -70,6 +82,10 @@ func (consenter *consenterImpl) retryOptions() localconfig.Retry {
 	return consenter.retryOptionsVal
 }
 
+func (consenter *consenterImpl) checkpointOptions() localconfig.Checkpoint {
+	return consenter.checkpointVal
+}
+
 // closeable allows the shut down of the calling resource.
 type closeable interface {
 	close() error
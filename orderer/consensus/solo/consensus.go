@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/hyperledger/fabric/common/metrics"
 	"github.com/hyperledger/fabric/orderer/consensus"
 	cb "github.com/hyperledger/fabric/protos/common"
 	"github.com/op/go-logging"
@@ -33,12 +34,16 @@ type chain struct {
 	support  consensus.ConsenterSupport
 	sendChan chan *message
 	exitChan chan struct{}
+
+	// latency records, for every block written, how long the oldest message in it waited
+	latency metrics.Timer
 }
 
 type message struct {
 	configSeq  uint64
 	configMsg  *cb.Envelope
 	initialMsg *cb.Envelope
+	received   time.Time
 }
 
 // New creates a new consenter for the solo consensus scheme.
@@ -58,6 +63,10 @@ func newChain(support consensus.ConsenterSupport) *chain {
 		support:  support,
 		sendChan: make(chan *message),
 		exitChan: make(chan struct{}),
+		latency: metrics.NewRootScope().SubScope("consensus").Tagged(map[string]string{
+			"channel": support.ChainID(),
+			"type":    "solo",
+		}).Timer("consensus_latency"),
 	}
 }
 
@@ -80,6 +89,7 @@ func (ch *chain) Order(env *cb.Envelope, configSeq uint64) error {
 	case ch.sendChan <- &message{
 		configSeq:  configSeq,
 		initialMsg: env,
+		received:   time.Now(),
 	}:
 		return nil
 	case <-ch.exitChan:
@@ -94,6 +104,7 @@ func (ch *chain) Configure(impetus *cb.Envelope, config *cb.Envelope, configSeq
 		configSeq:  configSeq,
 		initialMsg: impetus,
 		configMsg:  config,
+		received:   time.Now(),
 	}:
 		return nil
 	case <-ch.exitChan:
@@ -109,6 +120,8 @@ func (ch *chain) Errored() <-chan struct{} {
 func (ch *chain) main() {
 	var timer <-chan time.Time
 	var err error
+	// pendingSince is the time the oldest message pending in the block cutter was received
+	var pendingSince time.Time
 
 	for {
 		seq := ch.support.Sequence()
@@ -124,7 +137,10 @@ func (ch *chain) main() {
 						continue
 					}
 				}
-				batches, _ := ch.support.BlockCutter().Ordered(msg.initialMsg)
+				batches, pending := ch.support.BlockCutter().Ordered(msg.initialMsg)
+				if pendingSince.IsZero() {
+					pendingSince = msg.received
+				}
 				if len(batches) == 0 && timer == nil {
 					timer = time.After(ch.support.SharedConfig().BatchTimeout())
 					continue
@@ -132,9 +148,14 @@ func (ch *chain) main() {
 				for _, batch := range batches {
 					block := ch.support.CreateNextBlock(batch)
 					ch.support.WriteBlock(block, nil)
+					ch.latency.Record(time.Since(pendingSince))
 				}
 				if len(batches) > 0 {
 					timer = nil
+					pendingSince = time.Time{}
+					if pending {
+						pendingSince = msg.received
+					}
 				}
 			} else {
 				// ConfigMsg
@@ -149,11 +170,14 @@ func (ch *chain) main() {
 				if batch != nil {
 					block := ch.support.CreateNextBlock(batch)
 					ch.support.WriteBlock(block, nil)
+					ch.latency.Record(time.Since(pendingSince))
 				}
 
 				block := ch.support.CreateNextBlock([]*cb.Envelope{msg.configMsg})
 				ch.support.WriteConfigBlock(block, nil)
+				ch.latency.Record(time.Since(msg.received))
 				timer = nil
+				pendingSince = time.Time{}
 			}
 		case <-timer:
 			//clear the timer
@@ -167,6 +191,8 @@ func (ch *chain) main() {
 			logger.Debugf("Batch timer expired, creating block")
 			block := ch.support.CreateNextBlock(batch)
 			ch.support.WriteBlock(block, nil)
+			ch.latency.Record(time.Since(pendingSince))
+			pendingSince = time.Time{}
 		case <-ch.exitChan:
 			logger.Debugf("Exiting")
 			return
@@ -21,6 +21,7 @@ import (
 	"time"
 
 	mockconfig "github.com/hyperledger/fabric/common/mocks/config"
+	mockmetrics "github.com/hyperledger/fabric/common/mocks/metrics"
 	mockblockcutter "github.com/hyperledger/fabric/orderer/mocks/common/blockcutter"
 	mockmultichannel "github.com/hyperledger/fabric/orderer/mocks/common/multichannel"
 	cb "github.com/hyperledger/fabric/protos/common"
@@ -165,6 +166,33 @@ func TestBatchTimer(t *testing.T) {
 	}
 }
 
+func TestConsensusLatency(t *testing.T) {
+	batchTimeout := 10 * time.Millisecond
+	support := &mockmultichannel.ConsenterSupport{
+		Blocks:          make(chan *cb.Block),
+		BlockCutterVal:  mockblockcutter.NewReceiver(),
+		SharedConfigVal: &mockconfig.Orderer{BatchTimeoutVal: batchTimeout},
+	}
+	defer close(support.BlockCutterVal.Block)
+	bs := newChain(support)
+	latency := &mockmetrics.Timer{}
+	bs.latency = latency
+	wg := goWithWait(bs.main)
+
+	syncQueueMessage(testMessage, bs, support.BlockCutterVal)
+	select {
+	case <-support.Blocks:
+	case <-time.After(time.Second):
+		t.Fatalf("Expected a block to be cut because of batch timer expiration but did not")
+	}
+
+	bs.Halt()
+	<-wg.done
+	values := latency.Values()
+	assert.Len(t, values, 1, "Expected the latency of the block to be recorded")
+	assert.True(t, values[0] >= batchTimeout, "Expected the message to have waited for the batch timeout")
+}
+
 func TestBatchTimerHaltOnFilledBatch(t *testing.T) {
 	batchTimeout, _ := time.ParseDuration("1h")
 	support := &mockmultichannel.ConsenterSupport{
@@ -36,6 +36,9 @@ type ConsenterSupport struct {
 	// SharedConfigVal is the value returned by SharedConfig()
 	SharedConfigVal *mockconfig.Orderer
 
+	// ChannelConfigVal is the value returned by ChannelConfig() if set
+	ChannelConfigVal *mockconfig.Channel
+
 	// BlockCutterVal is the value returned by BlockCutter()
 	BlockCutterVal *mockblockcutter.Receiver
 
@@ -69,8 +72,17 @@ type ConsenterSupport struct {
 	// ProcessConfigUpdateMsgErr is returned as the error for ProcessConfigUpdateMsg
 	ProcessConfigUpdateMsgErr error
 
+	// SimulateConfigUpdateMsgVal is returned as the config for SimulateConfigUpdateMsg
+	SimulateConfigUpdateMsgVal *cb.ConfigEnvelope
+
+	// SimulateConfigUpdateMsgErr is returned as the error for SimulateConfigUpdateMsg
+	SimulateConfigUpdateMsgErr error
+
 	// SequenceVal is returned by Sequence
 	SequenceVal uint64
+
+	// BlockByIndex is the map of blocks returned by Block
+	BlockByIndex map[uint64]*cb.Block
 }
 
 // BlockCutter returns BlockCutterVal
@@ -83,6 +95,14 @@ func (mcs *ConsenterSupport) SharedConfig() config.Orderer {
 	return mcs.SharedConfigVal
 }
 
+// ChannelConfig returns ChannelConfigVal if set, otherwise a channel config requiring no capabilities
+func (mcs *ConsenterSupport) ChannelConfig() config.Channel {
+	if mcs.ChannelConfigVal == nil {
+		return &mockconfig.Channel{}
+	}
+	return mcs.ChannelConfigVal
+}
+
 // CreateNextBlock creates a simple block structure with the given data
 func (mcs *ConsenterSupport) CreateNextBlock(data []*cb.Envelope) *cb.Block {
 	block := cb.NewBlock(0, nil)
@@ -119,6 +139,11 @@ func (mcs *ConsenterSupport) Height() uint64 {
 	return mcs.HeightVal
 }
 
+// Block returns the block with the given number from BlockByIndex
+func (mcs *ConsenterSupport) Block(number uint64) *cb.Block {
+	return mcs.BlockByIndex[number]
+}
+
 // Sign returns the bytes passed in
 func (mcs *ConsenterSupport) Sign(message []byte) ([]byte, error) {
 	return message, nil
@@ -144,6 +169,11 @@ func (mcs *ConsenterSupport) ProcessConfigUpdateMsg(env *cb.Envelope) (config *c
 	return mcs.ProcessConfigUpdateMsgVal, mcs.ConfigSeqVal, mcs.ProcessConfigUpdateMsgErr
 }
 
+// SimulateConfigUpdateMsg returns SimulateConfigUpdateMsgVal, SimulateConfigUpdateMsgErr
+func (mcs *ConsenterSupport) SimulateConfigUpdateMsg(env *cb.Envelope) (*cb.ConfigEnvelope, error) {
+	return mcs.SimulateConfigUpdateMsgVal, mcs.SimulateConfigUpdateMsgErr
+}
+
 // Sequence returns SequenceVal
 func (mcs *ConsenterSupport) Sequence() uint64 {
 	return mcs.SequenceVal
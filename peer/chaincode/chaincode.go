@@ -62,6 +62,7 @@ var (
 	chaincodeLang     string
 	chaincodeCtorJSON string
 	chaincodePath     string
+	chaincodeConn     string
 	chaincodeName     string
 	chaincodeUsr      string // Not used
 	chaincodeQueryRaw bool
@@ -100,6 +101,8 @@ func resetFlags() {
 		fmt.Sprintf("Constructor message for the %s in JSON format", chainFuncName))
 	flags.StringVarP(&chaincodePath, "path", "p", common.UndefinedParamValue,
 		fmt.Sprintf("Path to %s", chainFuncName))
+	flags.StringVarP(&chaincodeConn, "connection", "", common.UndefinedParamValue,
+		fmt.Sprintf("Path to the connection definition of a %s running as an external server, installed instead of the code at path", chainFuncName))
 	flags.StringVarP(&chaincodeName, "name", "n", common.UndefinedParamValue,
 		fmt.Sprint("Name of the chaincode"))
 	flags.StringVarP(&chaincodeVersion, "version", "v", common.UndefinedParamValue,
@@ -25,6 +25,7 @@ import (
 
 	"github.com/hyperledger/fabric/core/common/ccpackage"
 	"github.com/hyperledger/fabric/core/common/ccprovider"
+	"github.com/hyperledger/fabric/core/container/externalcontroller"
 	"github.com/hyperledger/fabric/peer/common"
 	pcommon "github.com/hyperledger/fabric/protos/common"
 	pb "github.com/hyperledger/fabric/protos/peer"
@@ -58,6 +59,7 @@ func installCmd(cf *ChaincodeCmdFactory) *cobra.Command {
 		"lang",
 		"ctor",
 		"path",
+		"connection",
 		"name",
 		"version",
 	}
@@ -107,6 +109,10 @@ func genChaincodeDeploymentSpec(cmd *cobra.Command, chaincodeName, chaincodeVers
 		return nil, err
 	}
 
+	if chaincodeConn != common.UndefinedParamValue {
+		return getExternalChaincodeDeploymentSpec(spec, chaincodeConn)
+	}
+
 	cds, err := getChaincodeDeploymentSpec(spec, true)
 	if err != nil {
 		return nil, fmt.Errorf("Error getting chaincode code %s: %s", chainFuncName, err)
@@ -115,6 +121,22 @@ func genChaincodeDeploymentSpec(cmd *cobra.Command, chaincodeName, chaincodeVers
 	return cds, nil
 }
 
+//getExternalChaincodeDeploymentSpec creates the ChaincodeDeploymentSpec of a chaincode running
+//as an external server, whose code package is the connection definition read from connFile
+func getExternalChaincodeDeploymentSpec(spec *pb.ChaincodeSpec, connFile string) (*pb.ChaincodeDeploymentSpec, error) {
+	conn, err := ioutil.ReadFile(connFile)
+	if err != nil {
+		return nil, fmt.Errorf("Error reading chaincode connection definition %s: %s", connFile, err)
+	}
+	if _, err = externalcontroller.ParseConnectionInfo(conn); err != nil {
+		return nil, err
+	}
+	if spec.ChaincodeId.Path == common.UndefinedParamValue {
+		spec.ChaincodeId.Path = ""
+	}
+	return &pb.ChaincodeDeploymentSpec{ChaincodeSpec: spec, CodePackage: conn, ExecEnv: pb.ChaincodeDeploymentSpec_EXTERNAL}, nil
+}
+
 //getPackageFromFile get the chaincode package from file and the extracted ChaincodeDeploymentSpec
 func getPackageFromFile(ccpackfile string) (proto.Message, *pb.ChaincodeDeploymentSpec, error) {
 	b, err := ioutil.ReadFile(ccpackfile)
@@ -168,7 +190,7 @@ func chaincodeInstall(cmd *cobra.Command, ccpackfile string, cf *ChaincodeCmdFac
 
 	var ccpackmsg proto.Message
 	if ccpackfile == "" {
-		if chaincodePath == common.UndefinedParamValue || chaincodeVersion == common.UndefinedParamValue || chaincodeName == common.UndefinedParamValue {
+		if (chaincodePath == common.UndefinedParamValue && chaincodeConn == common.UndefinedParamValue) || chaincodeVersion == common.UndefinedParamValue || chaincodeName == common.UndefinedParamValue {
 			return fmt.Errorf("Must supply value for %s name, path and version parameters.", chainFuncName)
 		}
 		//generate a raw ChaincodeDeploymentSpec
@@ -160,6 +160,53 @@ func TestInstallFromBadPackage(t *testing.T) {
 	}
 }
 
+// TestInstallExternal tests the install of a chaincode running as an external server
+func TestInstallExternal(t *testing.T) {
+	pdir := newTempDir()
+	defer os.RemoveAll(pdir)
+	defer func() { chaincodeConn = common.UndefinedParamValue }()
+
+	connfile := pdir + "/connection.json"
+	err := ioutil.WriteFile(connfile, []byte(`{"address": "extcc:9999", "dial_timeout": "10s"}`), 0700)
+	if err != nil {
+		t.Fatalf("could not create connection definition :%v", err)
+	}
+	badconnfile := pdir + "/badconnection.json"
+	err = ioutil.WriteFile(badconnfile, []byte(`{"dial_timeout": "10s"}`), 0700)
+	if err != nil {
+		t.Fatalf("could not create connection definition :%v", err)
+	}
+
+	fsPath := "/tmp/installtest"
+
+	cmd, mockCF := initInstallTest(fsPath, t)
+	defer cleanupInstallTest(fsPath)
+
+	mockResponse := &pb.ProposalResponse{
+		Response:    &pb.Response{Status: 200},
+		Endorsement: &pb.Endorsement{},
+	}
+	mockCF.EndorserClient = common.GetMockEndorserClient(mockResponse, nil)
+
+	cmd.SetArgs([]string{"-n", "extcc", "-v", "0", "--connection", connfile})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("error executing install command for an external chaincode: %v", err)
+	}
+
+	cds, err := getExternalChaincodeDeploymentSpec(&pb.ChaincodeSpec{ChaincodeId: &pb.ChaincodeID{Name: "extcc", Version: "0", Path: common.UndefinedParamValue}}, connfile)
+	if err != nil {
+		t.Fatalf("error creating the deployment spec of an external chaincode: %v", err)
+	}
+	if cds.ExecEnv != pb.ChaincodeDeploymentSpec_EXTERNAL || cds.ChaincodeSpec.ChaincodeId.Path != "" {
+		t.Fatalf("unexpected deployment spec of an external chaincode: %v", cds)
+	}
+
+	cmd.SetArgs([]string{"-n", "extcc", "-v", "0", "--connection", badconnfile})
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("expected error installing an external chaincode without address")
+	}
+}
+
 func installEx02() error {
 	signer, err := common.GetDefaultSigner()
 	if err != nil {
@@ -62,6 +62,13 @@ var (
 	caFile                     string
 	ordererTLSHostnameOverride string
 	timeout                    int
+
+	// fetch related variables
+	fetchFormat        string
+	deliverPeerAddress string
+
+	// join related variables
+	blockStreamPath string
 )
 
 // Cmd returns the cobra command for Node
@@ -102,6 +109,9 @@ func resetFlags() {
 	flags.StringVarP(&chainID, "channelID", "c", common.UndefinedParamValue, "In case of a newChain command, the channel ID to create.")
 	flags.StringVarP(&channelTxFile, "file", "f", "", "Configuration transaction file generated by a tool such as configtxgen for submitting to orderer")
 	flags.IntVarP(&timeout, "timeout", "t", 5, "Channel creation timeout")
+	flags.StringVarP(&fetchFormat, "format", "", "proto", "Format of the fetched blocks, proto or json")
+	flags.StringVarP(&deliverPeerAddress, "peerAddress", "", "", "Fetch the blocks from the peer at this address instead of the ordering service")
+	flags.StringVarP(&blockStreamPath, "blockstream", "s", common.UndefinedParamValue, "Path to file containing a block stream of the channel, starting at its genesis block")
 }
 
 func attachFlags(cmd *cobra.Command, names []string) {
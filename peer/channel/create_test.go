@@ -126,6 +126,18 @@ func (m *mockDeliverClient) getNewestBlock() (*cb.Block, error) {
 	return m.readBlock()
 }
 
+func (m *mockDeliverClient) getBlockRange(start, stop uint64, handler func(*cb.Block) error) error {
+	if m.err != nil {
+		return m.err
+	}
+	for num := start; num <= stop; num++ {
+		if err := handler(&cb.Block{Header: &cb.BlockHeader{Number: num}}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (m *mockDeliverClient) Close() error {
 	return nil
 }
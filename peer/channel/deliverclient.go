@@ -21,9 +21,12 @@ import (
 	"time"
 
 	"github.com/hyperledger/fabric/common/localmsp"
+	"github.com/hyperledger/fabric/core/peer"
 	"github.com/hyperledger/fabric/protos/common"
 	ab "github.com/hyperledger/fabric/protos/orderer"
+	pb "github.com/hyperledger/fabric/protos/peer"
 	"github.com/hyperledger/fabric/protos/utils"
+	"golang.org/x/net/context"
 	"google.golang.org/grpc"
 )
 
@@ -31,30 +34,47 @@ type deliverClientIntf interface {
 	getSpecifiedBlock(num uint64) (*common.Block, error)
 	getOldestBlock() (*common.Block, error)
 	getNewestBlock() (*common.Block, error)
+	getBlockRange(start, stop uint64, handler func(*common.Block) error) error
 	Close() error
 }
 
+// deliverStream is the block stream of the Deliver service of either an orderer or a peer
+type deliverStream interface {
+	Send(*common.Envelope) error
+	Recv() (*ab.DeliverResponse, error)
+}
+
 type deliverClient struct {
-	conn    *grpc.ClientConn
-	client  ab.AtomicBroadcast_DeliverClient
-	chainID string
+	conn       *grpc.ClientConn
+	client     deliverStream
+	chainID    string
+	headerType common.HeaderType
 }
 
 func newDeliverClient(conn *grpc.ClientConn, client ab.AtomicBroadcast_DeliverClient, chainID string) *deliverClient {
-	return &deliverClient{conn: conn, client: client, chainID: chainID}
+	return &deliverClient{conn: conn, client: client, chainID: chainID, headerType: common.HeaderType_CONFIG_UPDATE}
 }
 
-func seekHelper(chainID string, position *ab.SeekPosition) *common.Envelope {
-	seekInfo := &ab.SeekInfo{
-		Start:    position,
-		Stop:     position,
-		Behavior: ab.SeekInfo_BLOCK_UNTIL_READY,
+// newPeerDeliverClient returns a deliver client reading the blocks of
+// the channel from the ledger of the peer at the given address
+func newPeerDeliverClient(address string, chainID string) (*deliverClient, error) {
+	conn, err := peer.NewPeerClientConnectionWithAddress(address)
+	if err != nil {
+		return nil, fmt.Errorf("Error connecting to peer %s due to %s", address, err)
 	}
+	client, err := pb.NewDeliverClient(conn).Deliver(context.TODO())
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("Error connecting to the deliver service of peer %s due to %s", address, err)
+	}
+	return &deliverClient{conn: conn, client: client, chainID: chainID, headerType: common.HeaderType_DELIVER_SEEK_INFO}, nil
+}
 
+func seekInfoHelper(headerType common.HeaderType, chainID string, seekInfo *ab.SeekInfo) *common.Envelope {
 	//TODO- epoch and msgVersion may need to be obtained for nowfollowing usage in orderer/configupdate/configupdate.go
 	msgVersion := int32(0)
 	epoch := uint64(0)
-	env, err := utils.CreateSignedEnvelope(common.HeaderType_CONFIG_UPDATE, chainID, localmsp.NewSigner(), seekInfo, msgVersion, epoch)
+	env, err := utils.CreateSignedEnvelope(headerType, chainID, localmsp.NewSigner(), seekInfo, msgVersion, epoch)
 	if err != nil {
 		logger.Errorf("Error signing envelope:  %s", err)
 		return nil
@@ -62,16 +82,24 @@ func seekHelper(chainID string, position *ab.SeekPosition) *common.Envelope {
 	return env
 }
 
+func (r *deliverClient) seek(position *ab.SeekPosition) error {
+	return r.client.Send(seekInfoHelper(r.headerType, r.chainID, &ab.SeekInfo{
+		Start:    position,
+		Stop:     position,
+		Behavior: ab.SeekInfo_BLOCK_UNTIL_READY,
+	}))
+}
+
 func (r *deliverClient) seekSpecified(blockNumber uint64) error {
-	return r.client.Send(seekHelper(r.chainID, &ab.SeekPosition{Type: &ab.SeekPosition_Specified{Specified: &ab.SeekSpecified{Number: blockNumber}}}))
+	return r.seek(&ab.SeekPosition{Type: &ab.SeekPosition_Specified{Specified: &ab.SeekSpecified{Number: blockNumber}}})
 }
 
 func (r *deliverClient) seekOldest() error {
-	return r.client.Send(seekHelper(r.chainID, &ab.SeekPosition{Type: &ab.SeekPosition_Oldest{Oldest: &ab.SeekOldest{}}}))
+	return r.seek(&ab.SeekPosition{Type: &ab.SeekPosition_Oldest{Oldest: &ab.SeekOldest{}}})
 }
 
 func (r *deliverClient) seekNewest() error {
-	return r.client.Send(seekHelper(r.chainID, &ab.SeekPosition{Type: &ab.SeekPosition_Newest{Newest: &ab.SeekNewest{}}}))
+	return r.seek(&ab.SeekPosition{Type: &ab.SeekPosition_Newest{Newest: &ab.SeekNewest{}}})
 }
 
 func (r *deliverClient) readBlock() (*common.Block, error) {
@@ -122,6 +150,40 @@ func (r *deliverClient) getNewestBlock() (*common.Block, error) {
 	return r.readBlock()
 }
 
+// getBlockRange passes the blocks from start to stop, both included, to the given handler in order.
+// It fails if some of the blocks have not been committed yet
+func (r *deliverClient) getBlockRange(start, stop uint64, handler func(*common.Block) error) error {
+	err := r.client.Send(seekInfoHelper(r.headerType, r.chainID, &ab.SeekInfo{
+		Start:    &ab.SeekPosition{Type: &ab.SeekPosition_Specified{Specified: &ab.SeekSpecified{Number: start}}},
+		Stop:     &ab.SeekPosition{Type: &ab.SeekPosition_Specified{Specified: &ab.SeekSpecified{Number: stop}}},
+		Behavior: ab.SeekInfo_FAIL_IF_NOT_READY,
+	}))
+	if err != nil {
+		return fmt.Errorf("Received error: %s", err)
+	}
+
+	for {
+		msg, err := r.client.Recv()
+		if err != nil {
+			return fmt.Errorf("Error receiving: %s", err)
+		}
+		switch t := msg.Type.(type) {
+		case *ab.DeliverResponse_Status:
+			if t.Status != common.Status_SUCCESS {
+				return fmt.Errorf("can't read the blocks: %v", t)
+			}
+			return nil
+		case *ab.DeliverResponse_Block:
+			logger.Debugf("Received block: %v", t.Block.Header.Number)
+			if err := handler(t.Block); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("response error: unknown type %T", t)
+		}
+	}
+}
+
 func (r *deliverClient) Close() error {
 	return r.conn.Close()
 }
@@ -17,27 +17,41 @@ limitations under the License.
 package channel
 
 import (
+	"bufio"
+	"bytes"
 	"fmt"
 	"io/ioutil"
+	"os"
 	"strconv"
+	"strings"
 
 	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/ledger/blockstream"
+	"github.com/hyperledger/fabric/common/tools/protolator"
 	cb "github.com/hyperledger/fabric/protos/common"
 	"github.com/hyperledger/fabric/protos/utils"
 	"github.com/spf13/cobra"
 )
 
+const (
+	protoFormat = "proto"
+	jsonFormat  = "json"
+)
+
 func fetchCmd(cf *ChannelCmdFactory) *cobra.Command {
 	fetchCmd := &cobra.Command{
-		Use:   "fetch <newest|oldest|config|(number)> [outputfile]",
-		Short: "Fetch a block",
-		Long:  "Fetch a specified block, writing it to a file.",
+		Use:   "fetch <newest|oldest|config|(number)|(start):(end)> [outputfile]",
+		Short: "Fetch a block or a range of blocks",
+		Long: "Fetch a specified block, writing it to a file. A range of blocks, whose end may be newest, " +
+			"is written as a block stream, which can be used to join a peer to the channel.",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return fetch(cmd, args, cf)
 		},
 	}
 	flagList := []string{
 		"channelID",
+		"format",
+		"peerAddress",
 	}
 	attachFlags(fetchCmd, flagList)
 
@@ -46,19 +60,33 @@ func fetchCmd(cf *ChannelCmdFactory) *cobra.Command {
 
 func fetch(cmd *cobra.Command, args []string, cf *ChannelCmdFactory) error {
 	var err error
+	if len(args) == 0 {
+		return fmt.Errorf("fetch target required, oldest, newest, config, a number or a range")
+	}
+
+	if len(args) > 2 {
+		return fmt.Errorf("trailing args detected")
+	}
+
+	if fetchFormat != protoFormat && fetchFormat != jsonFormat {
+		return fmt.Errorf("unknown format %s, expected %s or %s", fetchFormat, protoFormat, jsonFormat)
+	}
+
 	if cf == nil {
-		cf, err = InitCmdFactory(EndorserNotRequired, OrdererRequired)
-		if err != nil {
+		if cf, err = initFetchCmdFactory(); err != nil {
 			return err
 		}
 	}
 
-	if len(args) == 0 {
-		return fmt.Errorf("fetch target required, oldest, newest, config, or a number")
+	var file string
+	if len(args) == 1 {
+		file = chainID + "_" + strings.Replace(args[0], ":", "_", 1) + fileExtension(args[0])
+	} else {
+		file = args[1]
 	}
 
-	if len(args) > 2 {
-		return fmt.Errorf("trailing args detected")
+	if strings.Contains(args[0], ":") {
+		return fetchRange(args[0], file, cf)
 	}
 
 	var block *cb.Block
@@ -90,21 +118,102 @@ func fetch(cmd *cobra.Command, args []string, cf *ChannelCmdFactory) error {
 		return err
 	}
 
-	b, err := proto.Marshal(block)
-	if err != nil {
+	var b []byte
+	if fetchFormat == jsonFormat {
+		buf := &bytes.Buffer{}
+		if err = protolator.DeepMarshalJSON(buf, block); err != nil {
+			return err
+		}
+		b = buf.Bytes()
+	} else if b, err = proto.Marshal(block); err != nil {
 		return err
 	}
 
-	var file string
-	if len(args) == 1 {
-		file = chainID + "_" + args[0] + ".block"
-	} else {
-		file = args[1]
-	}
-
 	if err = ioutil.WriteFile(file, b, 0644); err != nil {
 		return err
 	}
 
 	return nil
 }
+
+// initFetchCmdFactory connects to the deliver service of either the ordering service,
+// or the peer given with the peerAddress flag
+func initFetchCmdFactory() (*ChannelCmdFactory, error) {
+	if deliverPeerAddress == "" {
+		return InitCmdFactory(EndorserNotRequired, OrdererRequired)
+	}
+	cf, err := InitCmdFactory(EndorserNotRequired, OrdererNotRequired)
+	if err != nil {
+		return nil, err
+	}
+	if cf.DeliverClient, err = newPeerDeliverClient(deliverPeerAddress, chainID); err != nil {
+		return nil, err
+	}
+	return cf, nil
+}
+
+func fileExtension(target string) string {
+	switch {
+	case fetchFormat == jsonFormat:
+		return ".json"
+	case strings.Contains(target, ":"):
+		return ".blocks"
+	default:
+		return ".block"
+	}
+}
+
+// fetchRange fetches the blocks of the given range, of the form <start>:<end>,
+// into the given file. The blocks are written as a block stream, or as JSON
+// documents separated by new lines
+func fetchRange(target string, file string, cf *ChannelCmdFactory) error {
+	bounds := strings.SplitN(target, ":", 2)
+	start, err := strconv.ParseUint(bounds[0], 10, 64)
+	if err != nil {
+		return fmt.Errorf("fetch range start illegal: %s", bounds[0])
+	}
+	var end uint64
+	if bounds[1] == "newest" {
+		newest, err := cf.DeliverClient.getNewestBlock()
+		if err != nil {
+			return err
+		}
+		end = newest.Header.Number
+	} else if end, err = strconv.ParseUint(bounds[1], 10, 64); err != nil {
+		return fmt.Errorf("fetch range end illegal: %s", bounds[1])
+	}
+	if start > end {
+		return fmt.Errorf("fetch range illegal: %d is greater than %d", start, end)
+	}
+
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	writer := blockstream.NewWriter(w)
+	next := start
+	err = cf.DeliverClient.getBlockRange(start, end, func(block *cb.Block) error {
+		if block.Header == nil || block.Header.Number != next {
+			return fmt.Errorf("received block out of order, expected block %d", next)
+		}
+		next++
+		if fetchFormat == jsonFormat {
+			if err := protolator.DeepMarshalJSON(w, block); err != nil {
+				return err
+			}
+			_, err := w.WriteString("\n")
+			return err
+		}
+		return writer.Write(block)
+	})
+	if err != nil {
+		return err
+	}
+	if next != end+1 {
+		return fmt.Errorf("fetch of blocks %d to %d stopped at block %d", start, end, next)
+	}
+	return w.Flush()
+}
@@ -17,9 +17,15 @@ limitations under the License.
 package channel
 
 import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/hyperledger/fabric/common/ledger/blockstream"
 	"github.com/hyperledger/fabric/peer/common"
 	"github.com/stretchr/testify/assert"
 )
@@ -68,3 +74,76 @@ func TestFetchChain(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestFetchBlockRange(t *testing.T) {
+	InitMSP()
+	resetFlags()
+
+	mockchain := "mockchain"
+	dir, err := ioutil.TempDir("/tmp", "fetchtest")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	signer, err := common.GetDefaultSigner()
+	assert.NoError(t, err)
+
+	mockCF := &ChannelCmdFactory{
+		BroadcastFactory: mockBroadcastClientFactory,
+		Signer:           signer,
+		DeliverClient:    &mockDeliverClient{},
+	}
+
+	streamFile := filepath.Join(dir, "mockchain.blocks")
+	cmd := fetchCmd(mockCF)
+	AddFlags(cmd)
+	cmd.SetArgs([]string{"-c", mockchain, "2:4", streamFile})
+	assert.NoError(t, cmd.Execute())
+
+	f, err := os.Open(streamFile)
+	assert.NoError(t, err)
+	defer f.Close()
+	r := blockstream.NewReader(f)
+	for num := uint64(2); num <= 4; num++ {
+		block, err := r.Read()
+		assert.NoError(t, err)
+		assert.Equal(t, num, block.Header.Number)
+	}
+	_, err = r.Read()
+	assert.Equal(t, io.EOF, err)
+
+	// the blocks can also be written as JSON documents
+	jsonFile := filepath.Join(dir, "mockchain.json")
+	cmd = fetchCmd(mockCF)
+	AddFlags(cmd)
+	cmd.SetArgs([]string{"-c", mockchain, "--format", "json", "0:1", jsonFile})
+	assert.NoError(t, cmd.Execute())
+	jsonBytes, err := ioutil.ReadFile(jsonFile)
+	assert.NoError(t, err)
+	assert.Len(t, bytes.Split(bytes.TrimSpace(jsonBytes), []byte("\n}\n")), 2)
+
+	for _, test := range []struct {
+		args     []string
+		errorMsg string
+	}{
+		{[]string{"-c", mockchain, "4:2", streamFile}, "fetch range illegal: 4 is greater than 2"},
+		{[]string{"-c", mockchain, "a:2", streamFile}, "fetch range start illegal: a"},
+		{[]string{"-c", mockchain, "2:b", streamFile}, "fetch range end illegal: b"},
+		{[]string{"-c", mockchain, "--format", "xml", "2:4", streamFile}, "unknown format xml, expected proto or json"},
+	} {
+		resetFlags()
+		cmd = fetchCmd(mockCF)
+		AddFlags(cmd)
+		cmd.SetArgs(test.args)
+		err = cmd.Execute()
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), test.errorMsg)
+	}
+
+	// a failure of the deliver service
+	resetFlags()
+	mockCF.DeliverClient = &mockDeliverClient{err: errors.New("deliver service failure")}
+	cmd = fetchCmd(mockCF)
+	AddFlags(cmd)
+	cmd.SetArgs([]string{"-c", mockchain, "2:4", streamFile})
+	assert.EqualError(t, cmd.Execute(), "deliver service failure")
+}
@@ -17,10 +17,13 @@ limitations under the License.
 package channel
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"io/ioutil"
 
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/ledger/blockstream"
 	"github.com/hyperledger/fabric/core/scc/cscc"
 	"github.com/hyperledger/fabric/peer/common"
 	pcommon "github.com/hyperledger/fabric/protos/common"
@@ -44,6 +47,7 @@ func joinCmd(cf *ChannelCmdFactory) *cobra.Command {
 	}
 	flagList := []string{
 		"blockpath",
+		"blockstream",
 	}
 	attachFlags(joinCmd, flagList)
 
@@ -65,16 +69,35 @@ func (e ProposalFailedErr) Error() string {
 }
 
 func getJoinCCSpec() (*pb.ChaincodeSpec, error) {
-	if genesisBlockPath == common.UndefinedParamValue {
-		return nil, errors.New("Must supply genesis block file")
-	}
+	var input *pb.ChaincodeInput
+	if blockStreamPath != common.UndefinedParamValue {
+		blockStream, err := ioutil.ReadFile(blockStreamPath)
+		if err != nil {
+			return nil, GBFileNotFoundErr(err.Error())
+		}
+		// the genesis block is the first block of the stream
+		gb, err := blockstream.NewReader(bytes.NewReader(blockStream)).Read()
+		if err != nil {
+			return nil, fmt.Errorf("Error reading the genesis block from the block stream: %s", err)
+		}
+		gbBytes, err := proto.Marshal(gb)
+		if err != nil {
+			return nil, err
+		}
+		input = &pb.ChaincodeInput{Args: [][]byte{[]byte(cscc.JoinChain), gbBytes, blockStream}}
+	} else {
+		if genesisBlockPath == common.UndefinedParamValue {
+			return nil, errors.New("Must supply genesis block file")
+		}
 
-	gb, err := ioutil.ReadFile(genesisBlockPath)
-	if err != nil {
-		return nil, GBFileNotFoundErr(err.Error())
+		gb, err := ioutil.ReadFile(genesisBlockPath)
+		if err != nil {
+			return nil, GBFileNotFoundErr(err.Error())
+		}
+		input = &pb.ChaincodeInput{Args: [][]byte{[]byte(cscc.JoinChain), gb}}
 	}
+
 	// Build the spec
-	input := &pb.ChaincodeInput{Args: [][]byte{[]byte(cscc.JoinChain), gb}}
 
 	spec := &pb.ChaincodeSpec{
 		Type:        pb.ChaincodeSpec_Type(pb.ChaincodeSpec_Type_value["GOLANG"]),
@@ -129,7 +152,7 @@ func executeJoin(cf *ChannelCmdFactory) (err error) {
 }
 
 func join(cmd *cobra.Command, args []string, cf *ChannelCmdFactory) error {
-	if genesisBlockPath == common.UndefinedParamValue {
+	if genesisBlockPath == common.UndefinedParamValue && blockStreamPath == common.UndefinedParamValue {
 		return errors.New("Must supply genesis block path")
 	}
 
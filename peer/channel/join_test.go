@@ -17,12 +17,16 @@
 package channel
 
 import (
+	"bytes"
 	"io/ioutil"
 	"os"
 	"path/filepath"
 	"testing"
 
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/ledger/blockstream"
 	"github.com/hyperledger/fabric/peer/common"
+	cb "github.com/hyperledger/fabric/protos/common"
 	pb "github.com/hyperledger/fabric/protos/peer"
 	"github.com/stretchr/testify/assert"
 )
@@ -73,6 +77,62 @@ func TestJoin(t *testing.T) {
 	assert.NoError(t, cmd.Execute(), "expected join command to succeed")
 }
 
+func TestJoinWithBlockStream(t *testing.T) {
+	InitMSP()
+	resetFlags()
+
+	dir, err := ioutil.TempDir("/tmp", "jointest")
+	assert.NoError(t, err, "Could not create the directory %s", dir)
+	defer os.RemoveAll(dir)
+
+	buf := &bytes.Buffer{}
+	w := blockstream.NewWriter(buf)
+	for num := uint64(0); num < 3; num++ {
+		assert.NoError(t, w.Write(&cb.Block{Header: &cb.BlockHeader{Number: num}}))
+	}
+	mockstreamfile := filepath.Join(dir, "mockjointest.blocks")
+	err = ioutil.WriteFile(mockstreamfile, buf.Bytes(), 0644)
+	assert.NoError(t, err, "Could not write to the file %s", mockstreamfile)
+
+	// the genesis block is the first block of the stream, which is sent along
+	blockStreamPath = mockstreamfile
+	spec, err := getJoinCCSpec()
+	assert.NoError(t, err)
+	assert.Len(t, spec.Input.Args, 3)
+	gb := &cb.Block{}
+	assert.NoError(t, proto.Unmarshal(spec.Input.Args[1], gb))
+	assert.Equal(t, uint64(0), gb.Header.Number)
+	assert.Equal(t, buf.Bytes(), spec.Input.Args[2])
+
+	signer, err := common.GetDefaultSigner()
+	assert.NoError(t, err, "Get default signer error: %v", err)
+	mockCF := &ChannelCmdFactory{
+		EndorserClient: common.GetMockEndorserClient(&pb.ProposalResponse{
+			Response:    &pb.Response{Status: 200},
+			Endorsement: &pb.Endorsement{},
+		}, nil),
+		BroadcastFactory: mockBroadcastClientFactory,
+		Signer:           signer,
+	}
+
+	resetFlags()
+	cmd := joinCmd(mockCF)
+	AddFlags(cmd)
+	cmd.SetArgs([]string{"-s", mockstreamfile})
+	assert.NoError(t, cmd.Execute(), "expected join command to succeed")
+
+	// an empty block stream has no genesis block
+	emptystreamfile := filepath.Join(dir, "empty.blocks")
+	assert.NoError(t, ioutil.WriteFile(emptystreamfile, []byte{}, 0644))
+	resetFlags()
+	cmd = joinCmd(mockCF)
+	AddFlags(cmd)
+	cmd.SetArgs([]string{"-s", emptystreamfile})
+	err = cmd.Execute()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "Error reading the genesis block from the block stream")
+}
+
 func TestJoinNonExistentBlock(t *testing.T) {
 	InitMSP()
 	resetFlags()
@@ -23,6 +23,7 @@ import (
 
 	"github.com/hyperledger/fabric/bccsp"
 	"github.com/hyperledger/fabric/bccsp/factory"
+	channelconfig "github.com/hyperledger/fabric/common/config/channel"
 	"github.com/hyperledger/fabric/common/crypto"
 	"github.com/hyperledger/fabric/common/flogging"
 	"github.com/hyperledger/fabric/common/policies"
@@ -52,6 +53,12 @@ type mspMessageCryptoService struct {
 	deserializer               mgmt.DeserializersManager
 }
 
+// channelCapabilitiesGetter is implemented by the policy manager getters
+// that also give access to the capabilities of a given channel
+type channelCapabilitiesGetter interface {
+	ChannelCapabilities(channelID string) (channelconfig.ChannelCapabilities, bool)
+}
+
 // NewMCS creates a new instance of mspMessageCryptoService
 // that implements MessageCryptoService.
 // The method takes in input:
@@ -113,6 +120,19 @@ func (s *mspMessageCryptoService) GetPKIidOfCert(peerIdentity api.PeerIdentityTy
 	return digest
 }
 
+// matchesDataHash checks Header.DataHash against block.Data. Once the channel requires
+// Merkle data hashes only the Merkle root is accepted; before that, or when the channel
+// config is not known yet, a block may still carry either form, as the blocks following
+// the config block that enables the capability may be verified before it is committed
+func (s *mspMessageCryptoService) matchesDataHash(channelID string, block *pcommon.Block) bool {
+	if ccg, ok := s.channelPolicyManagerGetter.(channelCapabilitiesGetter); ok {
+		if cc, ok := ccg.ChannelCapabilities(channelID); ok && cc.MerkleDataHash() {
+			return bytes.Equal(block.Data.HeaderDataHash(true), block.Header.DataHash)
+		}
+	}
+	return block.Data.MatchesDataHash(block.Header.DataHash)
+}
+
 // VerifyBlock returns nil if the block is properly signed, and the claimed seqNum is the
 // sequence number that the block's header contains.
 // else returns error
@@ -154,7 +174,7 @@ func (s *mspMessageCryptoService) VerifyBlock(chainID common.ChainID, seqNum uin
 
 	// - Verify that Header.DataHash is equal to the hash of block.Data
 	// This is to ensure that the header is consistent with the data carried by this block
-	if !bytes.Equal(block.Data.Hash(), block.Header.DataHash) {
+	if !s.matchesDataHash(channelID, block) {
 		return fmt.Errorf("Header.DataHash is different from Hash(block.Data) for block with id [%d] on channel [%s]", block.Header.Number, chainID)
 	}
 
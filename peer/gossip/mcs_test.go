@@ -210,6 +210,56 @@ func TestVerifyBlock(t *testing.T) {
 	assert.Error(t, msgCryptoService.VerifyBlock([]byte("C"), 42, nil))
 }
 
+func TestVerifyBlockMerkleDataHash(t *testing.T) {
+	aliceSigner := &mockscrypto.LocalSigner{Identity: []byte("Alice")}
+	policyManagerGetter := &mocks.ChannelPolicyManagerGetterWithCapabilities{
+		ChannelPolicyManagerGetterWithManager: &mocks.ChannelPolicyManagerGetterWithManager{
+			map[string]policies.Manager{
+				"C": &mocks.ChannelPolicyManager{&mocks.Policy{&mocks.IdentityDeserializer{[]byte("Alice"), []byte("msg1")}}},
+			},
+		},
+		MerkleDataHash: map[string]bool{},
+	}
+
+	msgCryptoService := NewMCS(
+		policyManagerGetter,
+		aliceSigner,
+		&mocks.DeserializersManager{
+			LocalDeserializer: &mocks.IdentityDeserializer{[]byte("Alice"), []byte("msg1")},
+		},
+	)
+	deserializer := policyManagerGetter.Managers["C"].(*mocks.ChannelPolicyManager).Policy.(*mocks.Policy).Deserializer.(*mocks.IdentityDeserializer)
+
+	flatBlockRaw, flatMsg := mockBlock(t, "C", 42, aliceSigner, nil)
+	block, err := utils.GetBlockFromBlockBytes(flatBlockRaw)
+	assert.NoError(t, err)
+	block.Header.DataHash = block.Data.MerkleRoot()
+	merkleBlockRaw, merkleMsg := signMockBlock(t, block, aliceSigner)
+
+	// Both forms are accepted as long as the channel does not require Merkle data hashes,
+	// or its capabilities are unknown
+	for _, merkle := range []*bool{nil, new(bool)} {
+		if merkle == nil {
+			delete(policyManagerGetter.MerkleDataHash, "C")
+		} else {
+			policyManagerGetter.MerkleDataHash["C"] = *merkle
+		}
+		deserializer.Msg = flatMsg
+		assert.NoError(t, msgCryptoService.VerifyBlock([]byte("C"), 42, flatBlockRaw))
+		deserializer.Msg = merkleMsg
+		assert.NoError(t, msgCryptoService.VerifyBlock([]byte("C"), 42, merkleBlockRaw))
+	}
+
+	// Only the Merkle root is accepted once the channel requires it
+	policyManagerGetter.MerkleDataHash["C"] = true
+	deserializer.Msg = flatMsg
+	err = msgCryptoService.VerifyBlock([]byte("C"), 42, flatBlockRaw)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "Header.DataHash is different")
+	deserializer.Msg = merkleMsg
+	assert.NoError(t, msgCryptoService.VerifyBlock([]byte("C"), 42, merkleBlockRaw))
+}
+
 func mockBlock(t *testing.T, channel string, seqNum uint64, localSigner crypto.LocalSigner, dataHash []byte) ([]byte, []byte) {
 	block := common.NewBlock(seqNum, nil)
 
@@ -226,6 +276,10 @@ func mockBlock(t *testing.T, channel string, seqNum uint64, localSigner crypto.L
 		block.Header.DataHash = block.Data.Hash()
 	}
 
+	return signMockBlock(t, block, localSigner)
+}
+
+func signMockBlock(t *testing.T, block *common.Block, localSigner crypto.LocalSigner) ([]byte, []byte) {
 	// Add signer's signature to the block
 	shdr, err := localSigner.NewSignatureHeader()
 	assert.NoError(t, err, "Failed generating signature header")
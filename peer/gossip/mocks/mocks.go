@@ -23,6 +23,8 @@ import (
 
 	"errors"
 
+	channelconfig "github.com/hyperledger/fabric/common/config/channel"
+	mockconfig "github.com/hyperledger/fabric/common/mocks/config"
 	mockpolicies "github.com/hyperledger/fabric/common/mocks/policies"
 	"github.com/hyperledger/fabric/common/policies"
 	"github.com/hyperledger/fabric/msp"
@@ -44,6 +46,16 @@ func (c *ChannelPolicyManagerGetterWithManager) Manager(channelID string) (polic
 	return c.Managers[channelID], true
 }
 
+type ChannelPolicyManagerGetterWithCapabilities struct {
+	*ChannelPolicyManagerGetterWithManager
+	MerkleDataHash map[string]bool
+}
+
+func (c *ChannelPolicyManagerGetterWithCapabilities) ChannelCapabilities(channelID string) (channelconfig.ChannelCapabilities, bool) {
+	merkle, ok := c.MerkleDataHash[channelID]
+	return &mockconfig.ChannelCapabilities{MerkleDataHashVal: merkle}, ok
+}
+
 type ChannelPolicyManager struct {
 	Policy policies.Policy
 }
@@ -30,10 +30,17 @@ import (
 	authHandler "github.com/hyperledger/fabric/core/handlers/auth"
 	"github.com/hyperledger/fabric/core/handlers/library"
 	"github.com/hyperledger/fabric/core/ledger/customtx"
+	"github.com/hyperledger/fabric/core/ledger/ledgerconfig"
 	"github.com/hyperledger/fabric/core/ledger/ledgermgmt"
+	"github.com/hyperledger/fabric/core/ledger/util/couchdb"
+	"github.com/hyperledger/fabric/core/operations"
 	"github.com/hyperledger/fabric/core/peer"
+	"github.com/hyperledger/fabric/core/peer/subsystem"
 	"github.com/hyperledger/fabric/core/scc"
+	"github.com/hyperledger/fabric/events/ccevents"
 	"github.com/hyperledger/fabric/events/producer"
+	"github.com/hyperledger/fabric/gossip/api"
+	"github.com/hyperledger/fabric/gossip/integration"
 	"github.com/hyperledger/fabric/gossip/service"
 	"github.com/hyperledger/fabric/msp/mgmt"
 	"github.com/hyperledger/fabric/peer/common"
@@ -44,6 +51,7 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/grpclog"
 )
 
@@ -97,6 +105,16 @@ func serve(args []string) error {
 
 	ledgermgmt.Initialize(txprocessors)
 
+	// subsystems drives the start and shutdown of the peer's components, which start in
+	// the order they are registered unless their dependencies require otherwise
+	subsystems := subsystem.NewManager()
+	if err := subsystems.Register("ledger", &subsystem.Hooks{
+		StopFunc:        ledgermgmt.Close,
+		HealthCheckFunc: ledgermgmt.HealthCheck,
+	}); err != nil {
+		return err
+	}
+
 	// Parameter overrides must be processed before any parameters are
 	// cached. Failures to cache cause the server to terminate immediately.
 	if chaincodeDevMode {
@@ -111,6 +129,14 @@ func serve(args []string) error {
 		return err
 	}
 
+	// the keepalive and message size settings of the gRPC clients and servers of the peer,
+	// which the gossip, deliver client and chaincode server settings default to
+	grpcOpts, err := comm.GRPCOptionsFromConfig("peer")
+	if err != nil {
+		return err
+	}
+	comm.SetGRPCOptions(grpcOpts)
+
 	peerEndpoint, err := peer.GetPeerEndpoint()
 	if err != nil {
 		err = fmt.Errorf("Failed to get Peer Endpoint: %s", err)
@@ -146,13 +172,34 @@ func serve(args []string) error {
 
 	ccSrv, ccEpFunc := createChaincodeServer(peerServer, listenAddr)
 	registerChaincodeSupport(ccSrv.Server(), ccEpFunc)
-	go ccSrv.Start()
+	if err := subsystems.Register("chaincode", &subsystem.Hooks{
+		StartFunc: func() error {
+			go ccSrv.Start()
+			return nil
+		},
+		StopFunc: ccSrv.Stop,
+	}, "ledger"); err != nil {
+		return err
+	}
 
 	logger.Debugf("Running peer")
 
 	// Register the Admin server
 	pb.RegisterAdminServer(peerServer.Server(), core.NewAdminServer())
 
+	// The requests of the Introspection and StateComparator servers must be timestamped within
+	// this window of the time of the peer
+	authTimeWindow := viper.GetDuration("peer.authentication.timewindow")
+
+	// Register the Introspection server, restricted to the admins of the local MSP
+	introspectionSupport := peer.NewIntrospectionSupport(chaincode.GetChain().ContainerStatuses)
+	pb.RegisterIntrospectionServer(peerServer.Server(), core.NewIntrospectionServer(introspectionSupport, mgmt.GetLocalMSP(), mgmt.NewLocalMSPPrincipalGetter(), authTimeWindow))
+
+	// Register the StateComparator server, which compares the state of the channels with the
+	// other peers of the organization
+	stateComparator := core.NewStateComparatorServer(peer.NewStateComparatorSupport(), mgmt.GetLocalMSP(), mgmt.NewLocalMSPPrincipalGetter(), localmsp.NewSigner(), authTimeWindow)
+	pb.RegisterStateComparatorServer(peerServer.Server(), stateComparator)
+
 	serverEndorser := endorser.NewEndorserServer()
 	libConf := library.Config{
 		AuthFilterFactory: viper.GetString("peer.handlers.authFilter"),
@@ -164,6 +211,19 @@ func serve(args []string) error {
 	// Register the Endorser server
 	pb.RegisterEndorserServer(peerServer.Server(), auth)
 
+	// Register the chaincode events server, whose events are persisted as the blocks are committed
+	ccEventsStore := ccevents.Initialize(ledgerconfig.GetChaincodeEventsStorePath())
+	if err := subsystems.Register("ccevents", &subsystem.Hooks{StopFunc: ccEventsStore.Close}, "ledger"); err != nil {
+		return err
+	}
+	pb.RegisterChaincodeEventsServer(peerServer.Server(), ccevents.NewServer(ccEventsStore, peer.NewChaincodeEventsSupport()))
+
+	// Register the Deliver server, streaming the committed blocks to the clients of the channels
+	pb.RegisterDeliverServer(peerServer.Server(), peer.NewDeliverServer())
+
+	// Register the transaction status server, notifying the clients of the commit of their transactions
+	pb.RegisterTxStatusServer(peerServer.Server(), peer.NewTxStatusServer(peer.NewTxStatusSupport()))
+
 	// Initialize gossip component
 	bootstrap := viper.GetStringSlice("peer.gossip.bootstrap")
 
@@ -178,18 +238,26 @@ func serve(args []string) error {
 		mgmt.NewDeserializersManager())
 	secAdv := peergossip.NewSecurityAdvisor(mgmt.NewDeserializersManager())
 
+	// the gossip connections the peer opens present its TLS client certificate,
+	// and verify the peers with the TLS root CAs of their organizations
+	gossipSecureConf, err := integration.NewSecureConfig()
+	if err != nil {
+		return err
+	}
+
+	gossipGRPCOpts, err := comm.GRPCOptionsFromConfig("peer.gossip")
+	if err != nil {
+		return err
+	}
+
 	// callback function for secure dial options for gossip service
 	secureDialOpts := func() []grpc.DialOption {
 		var dialOpts []grpc.DialOption
-		// set max send/recv msg sizes
-		dialOpts = append(dialOpts, grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(comm.MaxRecvMsgSize()),
-			grpc.MaxCallSendMsgSize(comm.MaxSendMsgSize())))
-		// set the keepalive options
-		dialOpts = append(dialOpts, comm.ClientKeepaliveOptions()...)
-
-		if comm.TLSEnabled() {
-			tlsCert := peerServer.ServerCertificate()
-			dialOpts = append(dialOpts, grpc.WithTransportCredentials(comm.GetCASupport().GetPeerCredentials(tlsCert)))
+		// set max send/recv msg sizes, and the keepalive options
+		dialOpts = append(dialOpts, gossipGRPCOpts.DialOptions()...)
+
+		if gossipSecureConf != nil {
+			dialOpts = append(dialOpts, grpc.WithTransportCredentials(credentials.NewTLS(gossipSecureConf.ClientTLSConfig())))
 		} else {
 			dialOpts = append(dialOpts, grpc.WithInsecure())
 		}
@@ -200,19 +268,52 @@ func serve(args []string) error {
 	if err != nil {
 		return err
 	}
-	defer service.GetGossipService().Stop()
+	gossipService := service.GetGossipService()
+	if err := subsystems.Register("gossip", &subsystem.Hooks{
+		StopFunc:        gossipService.Stop,
+		HealthCheckFunc: gossipService.HealthCheck,
+	}, "ledger"); err != nil {
+		return err
+	}
+	// the delivery of the blocks from the ordering service stops before gossip and the ledgers
+	if err := subsystems.Register("deliverclient", &subsystem.Hooks{
+		StopFunc:        gossipService.StopDeliveryService,
+		HealthCheckFunc: gossipService.DeliveryServiceHealthCheck,
+	}, "gossip"); err != nil {
+		return err
+	}
+
+	// a refreshed local MSP may carry new CRLs, so revalidate the identities
+	// of all known peers and close the sessions of the revoked ones
+	mgmt.AddLocalMspRefreshListener(func() {
+		service.GetGossipService().SuspectPeers(func(identity api.PeerIdentityType) bool {
+			return true
+		})
+	})
 
 	//initialize system chaincodes
-	initSysCCs()
+	if err := subsystems.Register("syscc", &subsystem.Hooks{
+		StartFunc: func() error {
+			initSysCCs()
+			return nil
+		},
+	}, "chaincode", "gossip"); err != nil {
+		return err
+	}
 
 	//this brings up all the chains (including testchainid)
-	peer.Initialize(func(cid string) {
-		logger.Debugf("Deploying system CC, for chain <%s>", cid)
-		scc.DeploySysCCs(cid)
-	})
-
-	logger.Infof("Starting peer with ID=[%s], network ID=[%s], address=[%s]",
-		peerEndpoint.Id, viper.GetString("peer.networkId"), peerEndpoint.Address)
+	if err := subsystems.Register("channels", &subsystem.Hooks{
+		StartFunc: func() error {
+			peer.Initialize(func(cid string) {
+				logger.Debugf("Deploying system CC, for chain <%s>", cid)
+				scc.DeploySysCCs(cid)
+			})
+			return nil
+		},
+		HealthCheckFunc: gossipService.ChannelsHealthCheck,
+	}, "syscc", "deliverclient"); err != nil {
+		return err
+	}
 
 	// Start the grpc server. Done in a goroutine so we can deploy the
 	// genesis block if needed.
@@ -226,25 +327,60 @@ func serve(args []string) error {
 		serve <- nil
 	}()
 
-	go func() {
-		var grpcErr error
-		if grpcErr = peerServer.Start(); grpcErr != nil {
-			grpcErr = fmt.Errorf("grpc server exited with error: %s", grpcErr)
-		} else {
-			logger.Info("peer server exited")
-		}
-		serve <- grpcErr
-	}()
-
-	if err := writePid(config.GetPath("peer.fileSystemPath")+"/peer.pid", os.Getpid()); err != nil {
+	if err := subsystems.Register("peerserver", &subsystem.Hooks{
+		StartFunc: func() error {
+			go func() {
+				var grpcErr error
+				if grpcErr = peerServer.Start(); grpcErr != nil {
+					grpcErr = fmt.Errorf("grpc server exited with error: %s", grpcErr)
+				} else {
+					logger.Info("peer server exited")
+				}
+				serve <- grpcErr
+			}()
+			return writePid(config.GetPath("peer.fileSystemPath")+"/peer.pid", os.Getpid())
+		},
+		StopFunc: peerServer.Stop,
+	}, "channels"); err != nil {
 		return err
 	}
 
 	// Start the event hub server
 	if ehubGrpcServer != nil {
-		go ehubGrpcServer.Start()
+		if err := subsystems.Register("eventhub", &subsystem.Hooks{
+			StartFunc: func() error {
+				go ehubGrpcServer.Start()
+				return nil
+			},
+			StopFunc: ehubGrpcServer.Stop,
+		}, "peerserver"); err != nil {
+			return err
+		}
 	}
 
+	if viper.GetBool("operations.enabled") {
+		system, err := newOperationsSystem(subsystems)
+		if err != nil {
+			return err
+		}
+		if err := subsystems.Register("operations", &subsystem.Hooks{
+			StartFunc: system.Start,
+			StopFunc: func() {
+				system.Stop()
+			},
+		}, "ledger", "gossip"); err != nil {
+			return err
+		}
+	}
+
+	logger.Infof("Starting peer with ID=[%s], network ID=[%s], address=[%s]",
+		peerEndpoint.Id, viper.GetString("peer.networkId"), peerEndpoint.Address)
+
+	if err := subsystems.Start(); err != nil {
+		return err
+	}
+	defer subsystems.Stop()
+
 	// Start profiling http endpoint if enabled
 	if viper.GetBool("peer.profile.enabled") {
 		go func() {
@@ -306,6 +442,11 @@ func createChaincodeServer(peerServer comm.GRPCServer, peerListenAddress string)
 		if err != nil {
 			panic(err)
 		}
+		grpcOpts, err := comm.GRPCOptionsFromConfig("peer.chaincodeServer")
+		if err != nil {
+			panic(err)
+		}
+		config.GRPCOptions = &grpcOpts
 
 		srv, err = comm.NewGRPCServer(cclistenAddress, config)
 		if err != nil {
@@ -384,6 +525,56 @@ func createEventHubServer(secureConfig comm.SecureServerConfig) (comm.GRPCServer
 	return grpcServer, nil
 }
 
+// newOperationsSystem creates the operations server, which reports the health of the ledger and
+// gossip subsystems as the liveness of the peer, and additionally the health of the deliverclient
+// and channels subsystems and of CouchDB as its readiness
+func newOperationsSystem(subsystems *subsystem.Manager) (*operations.System, error) {
+	var clientRootCAs []string
+	for _, file := range viper.GetStringSlice("operations.tls.clientRootCAs.files") {
+		clientRootCAs = append(clientRootCAs, config.TranslatePath(filepath.Dir(viper.ConfigFileUsed()), file))
+	}
+	system := operations.NewSystem(operations.Options{
+		ListenAddress:      viper.GetString("operations.listenAddress"),
+		HealthCheckTimeout: viper.GetDuration("operations.healthCheckTimeout"),
+		TLS: operations.TLS{
+			Enabled:            viper.GetBool("operations.tls.enabled"),
+			CertFile:           config.GetPath("operations.tls.cert.file"),
+			KeyFile:            config.GetPath("operations.tls.key.file"),
+			ClientCertRequired: viper.GetBool("operations.tls.clientAuthRequired"),
+			ClientRootCAs:      clientRootCAs,
+		},
+	})
+
+	for _, name := range []string{"ledger", "gossip"} {
+		s, err := subsystems.Get(name)
+		if err != nil {
+			return nil, err
+		}
+		if err := system.RegisterLivenessChecker(name, s); err != nil {
+			return nil, err
+		}
+	}
+	for _, name := range []string{"deliverclient", "channels"} {
+		s, err := subsystems.Get(name)
+		if err != nil {
+			return nil, err
+		}
+		if err := system.RegisterReadinessChecker(name, s); err != nil {
+			return nil, err
+		}
+	}
+	if ledgerconfig.IsCouchDBEnabled() {
+		couchInstance, err := couchdb.CreateCouchInstanceFromDefinition(couchdb.GetCouchDBDefinition())
+		if err != nil {
+			return nil, err
+		}
+		if err := system.RegisterReadinessChecker("couchdb", couchInstance); err != nil {
+			return nil, err
+		}
+	}
+	return system, nil
+}
+
 func writePid(fileName string, pid int) error {
 	err := os.MkdirAll(filepath.Dir(fileName), 0755)
 	if err != nil {
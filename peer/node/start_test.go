@@ -17,16 +17,23 @@ limitations under the License.
 package node
 
 import (
+	"encoding/json"
+	"errors"
 	"io/ioutil"
+	"net/http"
 	"os"
 	"strconv"
 	"syscall"
 	"testing"
 	"time"
 
+	"github.com/hyperledger/fabric/core/ledger/ledgermgmt"
+	"github.com/hyperledger/fabric/core/operations"
+	"github.com/hyperledger/fabric/core/peer/subsystem"
 	"github.com/hyperledger/fabric/msp/mgmt/testtools"
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
 )
 
 func TestStartCmd(t *testing.T) {
@@ -116,3 +123,48 @@ func TestWritePid(t *testing.T) {
 		})
 	}
 }
+
+func TestNewOperationsSystem(t *testing.T) {
+	viper.Set("operations.listenAddress", "127.0.0.1:0")
+	viper.Set("operations.healthCheckTimeout", time.Second)
+	defer viper.Set("operations.listenAddress", "")
+
+	subsystems := subsystem.NewManager()
+	assert.NoError(t, subsystems.Register("ledger", &subsystem.Hooks{HealthCheckFunc: ledgermgmt.HealthCheck}))
+	assert.NoError(t, subsystems.Register("gossip", &subsystem.Hooks{}))
+	_, err := newOperationsSystem(subsystems)
+	assert.EqualError(t, err, "subsystem deliverclient is not registered")
+
+	assert.NoError(t, subsystems.Register("deliverclient", &subsystem.Hooks{
+		HealthCheckFunc: func(ctx context.Context) error {
+			return errors.New("delivery terminated")
+		},
+	}))
+	assert.NoError(t, subsystems.Register("channels", &subsystem.Hooks{}))
+	system, err := newOperationsSystem(subsystems)
+	assert.NoError(t, err)
+	assert.NoError(t, system.Start())
+	defer system.Stop()
+
+	getStatus := func(path string) (int, operations.HealthStatus) {
+		resp, err := http.Get("http://" + system.Addr() + path)
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+		var status operations.HealthStatus
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&status))
+		return resp.StatusCode, status
+	}
+
+	// ledger mgmt is not running, and the delivery of blocks terminated
+	code, status := getStatus(operations.URLLiveness)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Len(t, status.FailedChecks, 1)
+	assert.Equal(t, "ledger", status.FailedChecks[0].Component)
+
+	code, status = getStatus(operations.URLReadiness)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	if assert.Len(t, status.FailedChecks, 2) {
+		assert.Equal(t, operations.FailedCheck{Component: "deliverclient", Reason: "delivery terminated"}, status.FailedChecks[0])
+		assert.Equal(t, "ledger", status.FailedChecks[1].Component)
+	}
+}
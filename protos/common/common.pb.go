@@ -10,6 +10,7 @@ It is generated from these files:
 	common/configuration.proto
 	common/ledger.proto
 	common/policies.proto
+	common/collection.proto
 
 It has these top-level messages:
 	LastConfig
@@ -39,11 +40,18 @@ It has these top-level messages:
 	BlockDataHashingStructure
 	OrdererAddresses
 	Consortium
+	Capabilities
+	Capability
 	BlockchainInfo
 	Policy
 	SignaturePolicyEnvelope
 	SignaturePolicy
 	ImplicitMetaPolicy
+	CollectionConfigPackage
+	CollectionConfig
+	StaticCollectionConfig
+	CollectionPolicyConfig
+	CollectionCriteria
 */
 package common
 
@@ -70,6 +78,7 @@ const (
 	Status_UNKNOWN                  Status = 0
 	Status_SUCCESS                  Status = 200
 	Status_BAD_REQUEST              Status = 400
+	Status_UNAUTHORIZED             Status = 401
 	Status_FORBIDDEN                Status = 403
 	Status_NOT_FOUND                Status = 404
 	Status_REQUEST_ENTITY_TOO_LARGE Status = 413
@@ -81,6 +90,7 @@ var Status_name = map[int32]string{
 	0:   "UNKNOWN",
 	200: "SUCCESS",
 	400: "BAD_REQUEST",
+	401: "UNAUTHORIZED",
 	403: "FORBIDDEN",
 	404: "NOT_FOUND",
 	413: "REQUEST_ENTITY_TOO_LARGE",
@@ -91,6 +101,7 @@ var Status_value = map[string]int32{
 	"UNKNOWN":                  0,
 	"SUCCESS":                  200,
 	"BAD_REQUEST":              400,
+	"UNAUTHORIZED":             401,
 	"FORBIDDEN":                403,
 	"NOT_FOUND":                404,
 	"REQUEST_ENTITY_TOO_LARGE": 413,
@@ -113,6 +124,7 @@ const (
 	HeaderType_ORDERER_TRANSACTION  HeaderType = 4
 	HeaderType_DELIVER_SEEK_INFO    HeaderType = 5
 	HeaderType_CHAINCODE_PACKAGE    HeaderType = 6
+	HeaderType_DELIVER_ACK          HeaderType = 7
 )
 
 var HeaderType_name = map[int32]string{
@@ -123,6 +135,7 @@ var HeaderType_name = map[int32]string{
 	4: "ORDERER_TRANSACTION",
 	5: "DELIVER_SEEK_INFO",
 	6: "CHAINCODE_PACKAGE",
+	7: "DELIVER_ACK",
 }
 var HeaderType_value = map[string]int32{
 	"MESSAGE":              0,
@@ -132,6 +145,7 @@ var HeaderType_value = map[string]int32{
 	"ORDERER_TRANSACTION":  4,
 	"DELIVER_SEEK_INFO":    5,
 	"CHAINCODE_PACKAGE":    6,
+	"DELIVER_ACK":          7,
 }
 
 func (x HeaderType) String() string {
@@ -147,6 +161,8 @@ const (
 	BlockMetadataIndex_LAST_CONFIG         BlockMetadataIndex = 1
 	BlockMetadataIndex_TRANSACTIONS_FILTER BlockMetadataIndex = 2
 	BlockMetadataIndex_ORDERER             BlockMetadataIndex = 3
+	// e.g. For Kafka, this is where we store the last offset written to the local ledger.
+	BlockMetadataIndex_TRANSACTIONS_VALIDATION_REASONS BlockMetadataIndex = 4
 )
 
 var BlockMetadataIndex_name = map[int32]string{
@@ -154,12 +170,14 @@ var BlockMetadataIndex_name = map[int32]string{
 	1: "LAST_CONFIG",
 	2: "TRANSACTIONS_FILTER",
 	3: "ORDERER",
+	4: "TRANSACTIONS_VALIDATION_REASONS",
 }
 var BlockMetadataIndex_value = map[string]int32{
-	"SIGNATURES":          0,
-	"LAST_CONFIG":         1,
-	"TRANSACTIONS_FILTER": 2,
-	"ORDERER":             3,
+	"SIGNATURES":                      0,
+	"LAST_CONFIG":                     1,
+	"TRANSACTIONS_FILTER":             2,
+	"ORDERER":                         3,
+	"TRANSACTIONS_VALIDATION_REASONS": 4,
 }
 
 func (x BlockMetadataIndex) String() string {
@@ -544,62 +562,64 @@ func init() {
 func init() { proto.RegisterFile("common/common.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 900 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x84, 0x55, 0xdf, 0x6e, 0xe3, 0xc4,
-	0x1b, 0xad, 0xe3, 0xfc, 0x69, 0xbe, 0x34, 0xad, 0x3b, 0xd9, 0xfe, 0xd6, 0xbf, 0xc2, 0x6a, 0x23,
-	0xc3, 0xa2, 0xd2, 0x4a, 0x89, 0x28, 0x37, 0x70, 0xe9, 0xd8, 0x93, 0xd6, 0x6a, 0xd6, 0x2e, 0x63,
-	0x67, 0x11, 0xbb, 0x48, 0x96, 0x93, 0x4c, 0x13, 0x8b, 0xc4, 0x8e, 0x6c, 0xa7, 0x6a, 0x5f, 0x02,
-	0x21, 0xc1, 0x0d, 0x17, 0xbc, 0x00, 0x4f, 0xc2, 0x5b, 0xf0, 0x12, 0x48, 0xdc, 0xa2, 0xf1, 0x8c,
-	0xbd, 0x49, 0x59, 0x89, 0xab, 0xcc, 0x39, 0x73, 0x3c, 0xdf, 0x99, 0xf3, 0x7d, 0xb1, 0xa1, 0x33,
-	0x8d, 0x57, 0xab, 0x38, 0xea, 0xf3, 0x9f, 0xde, 0x3a, 0x89, 0xb3, 0x18, 0xd5, 0x39, 0x3a, 0x7d,
-	0x39, 0x8f, 0xe3, 0xf9, 0x92, 0xf6, 0x73, 0x76, 0xb2, 0xb9, 0xeb, 0x67, 0xe1, 0x8a, 0xa6, 0x59,
-	0xb0, 0x5a, 0x73, 0xa1, 0xa6, 0x01, 0x8c, 0x82, 0x34, 0x33, 0xe2, 0xe8, 0x2e, 0x9c, 0xa3, 0x67,
-	0x50, 0x0b, 0xa3, 0x19, 0x7d, 0x50, 0xa5, 0xae, 0x74, 0x56, 0x25, 0x1c, 0x68, 0xef, 0x60, 0xff,
-	0x35, 0xcd, 0x82, 0x59, 0x90, 0x05, 0x4c, 0x71, 0x1f, 0x2c, 0x37, 0x34, 0x57, 0x1c, 0x10, 0x0e,
-	0xd0, 0xd7, 0x00, 0x69, 0x38, 0x8f, 0x82, 0x6c, 0x93, 0xd0, 0x54, 0xad, 0x74, 0xe5, 0xb3, 0xd6,
-	0xe5, 0xff, 0x7b, 0xc2, 0x51, 0xf1, 0xac, 0x5b, 0x28, 0xc8, 0x96, 0x58, 0xfb, 0x1e, 0x8e, 0xff,
-	0x25, 0x40, 0x9f, 0x83, 0x52, 0x4a, 0xfc, 0x05, 0x0d, 0x66, 0x34, 0x11, 0x05, 0x8f, 0x4a, 0xfe,
-	0x3a, 0xa7, 0xd1, 0xc7, 0xd0, 0x2c, 0x29, 0xb5, 0x92, 0x6b, 0xde, 0x13, 0xda, 0x5b, 0xa8, 0x0b,
-	0xdd, 0x2b, 0x38, 0x9c, 0x2e, 0x82, 0x28, 0xa2, 0xcb, 0xdd, 0x03, 0xdb, 0x82, 0x15, 0xb2, 0x0f,
-	0x55, 0xae, 0x7c, 0xb0, 0xb2, 0xf6, 0xa7, 0x04, 0x6d, 0x63, 0xe7, 0x61, 0x04, 0xd5, 0xec, 0x71,
-	0xcd, 0xb3, 0xa9, 0x91, 0x7c, 0x8d, 0x54, 0x68, 0xdc, 0xd3, 0x24, 0x0d, 0xe3, 0x28, 0x3f, 0xa7,
-	0x46, 0x0a, 0x88, 0xbe, 0x82, 0x66, 0xd9, 0x0d, 0x55, 0xee, 0x4a, 0x67, 0xad, 0xcb, 0xd3, 0x1e,
-	0xef, 0x57, 0xaf, 0xe8, 0x57, 0xcf, 0x2b, 0x14, 0xe4, 0xbd, 0x18, 0xbd, 0x00, 0x28, 0xee, 0x12,
-	0xce, 0xd4, 0x6a, 0x57, 0x3a, 0x6b, 0x92, 0xa6, 0x60, 0xac, 0x19, 0xea, 0x40, 0x2d, 0x7b, 0x60,
-	0x3b, 0xb5, 0x7c, 0xa7, 0x9a, 0x3d, 0x58, 0x33, 0xd6, 0x38, 0xba, 0x8e, 0xa7, 0x0b, 0xb5, 0xce,
-	0x5b, 0x9b, 0x03, 0x96, 0x1e, 0x7d, 0xc8, 0x68, 0x94, 0xfb, 0x6b, 0xf0, 0xf4, 0x4a, 0x42, 0xd3,
-	0xe1, 0xc8, 0x7d, 0x12, 0xb7, 0x0a, 0x8d, 0x69, 0x42, 0x83, 0x2c, 0x2e, 0xf2, 0x2b, 0x20, 0x2b,
-	0x10, 0xc5, 0xd1, 0xb4, 0x68, 0x02, 0x07, 0x1a, 0x86, 0xc6, 0x6d, 0xf0, 0xb8, 0x8c, 0x83, 0x19,
-	0xfa, 0x0c, 0xea, 0x5b, 0xc9, 0xb7, 0x2e, 0x0f, 0x8b, 0x01, 0xe1, 0x47, 0x13, 0xb1, 0xcb, 0x52,
-	0x64, 0xd3, 0x20, 0xce, 0xc9, 0xd7, 0xda, 0x00, 0xf6, 0x71, 0x74, 0x4f, 0x97, 0x31, 0x4f, 0x74,
-	0xcd, 0x8f, 0x2c, 0x2c, 0x08, 0xf8, 0x1f, 0xb3, 0xf0, 0xa3, 0x04, 0xb5, 0xc1, 0x32, 0x9e, 0xfe,
-	0x80, 0x2e, 0x9e, 0x38, 0xe9, 0x14, 0x4e, 0xf2, 0xed, 0x27, 0x76, 0x5e, 0x6d, 0xd9, 0x69, 0x5d,
-	0x1e, 0xef, 0x48, 0xcd, 0x20, 0x0b, 0xb8, 0x43, 0xf4, 0x05, 0xec, 0xaf, 0xc4, 0x1c, 0x8b, 0x66,
-	0x9e, 0xec, 0x48, 0x8b, 0x21, 0x27, 0xa5, 0x4c, 0x9b, 0x43, 0x6b, 0xab, 0x20, 0xfa, 0x1f, 0xd4,
-	0xa3, 0xcd, 0x6a, 0x22, 0x5c, 0x55, 0x89, 0x40, 0xe8, 0x13, 0x68, 0xaf, 0x13, 0x7a, 0x1f, 0xc6,
-	0x9b, 0xd4, 0x5f, 0x04, 0xe9, 0x42, 0xdc, 0xec, 0xa0, 0x20, 0xaf, 0x83, 0x74, 0x81, 0x3e, 0x82,
-	0x26, 0x3b, 0x93, 0x0b, 0xe4, 0x5c, 0xb0, 0xcf, 0x08, 0xb6, 0xa9, 0xbd, 0x84, 0x66, 0x69, 0xb7,
-	0x8c, 0x57, 0xea, 0xca, 0x65, 0xbc, 0x17, 0xd0, 0xde, 0x31, 0x89, 0x4e, 0xb7, 0x6e, 0xc3, 0x85,
-	0x25, 0x3e, 0xff, 0x5d, 0x82, 0xba, 0x9b, 0x05, 0xd9, 0x26, 0x45, 0x2d, 0x68, 0x8c, 0xed, 0x1b,
-	0xdb, 0xf9, 0xd6, 0x56, 0xf6, 0xd0, 0x01, 0x34, 0xdc, 0xb1, 0x61, 0x60, 0xd7, 0x55, 0xfe, 0x90,
-	0x90, 0x02, 0xad, 0x81, 0x6e, 0xfa, 0x04, 0x7f, 0x33, 0xc6, 0xae, 0xa7, 0xfc, 0x24, 0xa3, 0x43,
-	0x68, 0x0e, 0x1d, 0x32, 0xb0, 0x4c, 0x13, 0xdb, 0xca, 0xcf, 0x39, 0xb6, 0x1d, 0xcf, 0x1f, 0x3a,
-	0x63, 0xdb, 0x54, 0x7e, 0x91, 0xd1, 0x0b, 0x50, 0x85, 0xda, 0xc7, 0xb6, 0x67, 0x79, 0xdf, 0xf9,
-	0x9e, 0xe3, 0xf8, 0x23, 0x9d, 0x5c, 0x61, 0xe5, 0x37, 0x19, 0x9d, 0xc2, 0x89, 0x65, 0x7b, 0x98,
-	0xd8, 0xfa, 0xc8, 0x77, 0x31, 0x79, 0x83, 0x89, 0x8f, 0x09, 0x71, 0x88, 0xf2, 0x97, 0x8c, 0x54,
-	0xe8, 0x30, 0xca, 0x32, 0xb0, 0x3f, 0xb6, 0xf5, 0x37, 0xba, 0x35, 0xd2, 0x07, 0x23, 0xac, 0xfc,
-	0x2d, 0x9f, 0xff, 0x2a, 0x01, 0xf0, 0x7c, 0x3d, 0xf6, 0x6f, 0x6c, 0x41, 0xe3, 0x35, 0x76, 0x5d,
-	0xfd, 0x0a, 0x2b, 0x7b, 0x08, 0xa0, 0x6e, 0x38, 0xf6, 0xd0, 0xba, 0x52, 0x24, 0x74, 0x0c, 0x6d,
-	0xbe, 0xf6, 0xc7, 0xb7, 0xa6, 0xee, 0x61, 0xa5, 0x82, 0x54, 0x78, 0x86, 0x6d, 0xd3, 0x21, 0x2e,
-	0x26, 0xbe, 0x47, 0x74, 0xdb, 0xd5, 0x0d, 0xcf, 0x72, 0x6c, 0x45, 0x46, 0xcf, 0xa1, 0xe3, 0x10,
-	0x13, 0x93, 0x27, 0x1b, 0x55, 0x74, 0x02, 0xc7, 0x26, 0x1e, 0x59, 0xcc, 0x9b, 0x8b, 0xf1, 0x8d,
-	0x6f, 0xd9, 0x43, 0x47, 0xa9, 0x31, 0xda, 0xb8, 0xd6, 0x2d, 0xdb, 0x70, 0x4c, 0xec, 0xdf, 0xea,
-	0xc6, 0x0d, 0xab, 0x5f, 0x3f, 0x7f, 0x07, 0x68, 0x27, 0x75, 0x8b, 0xbd, 0x6d, 0xd1, 0x21, 0x80,
-	0x6b, 0x5d, 0xd9, 0xba, 0x37, 0x26, 0xd8, 0x55, 0xf6, 0xd0, 0x11, 0xb4, 0x46, 0xba, 0xeb, 0xf9,
-	0xa5, 0xd5, 0xe7, 0xd0, 0xd9, 0xaa, 0xea, 0xfa, 0x43, 0x6b, 0xe4, 0x61, 0xa2, 0x54, 0xd8, 0xe5,
-	0x84, 0x2d, 0x45, 0x1e, 0xb8, 0xf0, 0x69, 0x9c, 0xcc, 0x7b, 0x8b, 0xc7, 0x35, 0x4d, 0x96, 0x74,
-	0x36, 0xa7, 0x49, 0xef, 0x2e, 0x98, 0x24, 0xe1, 0x94, 0xbf, 0x5b, 0x52, 0x31, 0x9c, 0x6f, 0x2f,
-	0xe6, 0x61, 0xb6, 0xd8, 0x4c, 0x18, 0xec, 0x6f, 0x89, 0xfb, 0x5c, 0xcc, 0x3f, 0x1c, 0xa9, 0xf8,
-	0xb8, 0x4c, 0xea, 0x39, 0xfc, 0xf2, 0x9f, 0x00, 0x00, 0x00, 0xff, 0xff, 0xa1, 0xcb, 0xe1, 0xb4,
-	0x74, 0x06, 0x00, 0x00,
+	// 940 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x84, 0x55, 0xdf, 0x6e, 0xe3, 0xc4,
+	0x1b, 0xad, 0xeb, 0xc4, 0x69, 0xbe, 0xf4, 0xcf, 0x74, 0xb2, 0xfd, 0xad, 0x7f, 0x85, 0x55, 0x23,
+	0x2f, 0x8b, 0x4a, 0x2b, 0x25, 0xa2, 0xdc, 0xc0, 0xe5, 0xc4, 0x9e, 0xb4, 0x56, 0xb3, 0x76, 0x99,
+	0x71, 0x8a, 0x28, 0x48, 0x96, 0x93, 0xb8, 0x49, 0x44, 0x62, 0x47, 0xb6, 0x53, 0xb5, 0x77, 0x3c,
+	0x01, 0x02, 0xc1, 0x2d, 0x2f, 0xc0, 0x3b, 0x70, 0xcf, 0x5b, 0xf0, 0x12, 0x48, 0xdc, 0x22, 0x7b,
+	0x6c, 0x6f, 0x52, 0x56, 0xe2, 0x2a, 0x73, 0xce, 0x1c, 0x7f, 0x73, 0xe6, 0x7c, 0x5f, 0x6c, 0x68,
+	0x8e, 0xc2, 0xc5, 0x22, 0x0c, 0x3a, 0xe2, 0xa7, 0xbd, 0x8c, 0xc2, 0x24, 0xc4, 0x8a, 0x40, 0xc7,
+	0x27, 0x93, 0x30, 0x9c, 0xcc, 0xfd, 0x4e, 0xc6, 0x0e, 0x57, 0xf7, 0x9d, 0x64, 0xb6, 0xf0, 0xe3,
+	0xc4, 0x5b, 0x2c, 0x85, 0x50, 0xd3, 0x00, 0xfa, 0x5e, 0x9c, 0xe8, 0x61, 0x70, 0x3f, 0x9b, 0xe0,
+	0x17, 0x50, 0x9d, 0x05, 0x63, 0xff, 0x51, 0x95, 0x5a, 0xd2, 0x69, 0x85, 0x09, 0xa0, 0x7d, 0x03,
+	0x3b, 0x6f, 0xfd, 0xc4, 0x1b, 0x7b, 0x89, 0x97, 0x2a, 0x1e, 0xbc, 0xf9, 0xca, 0xcf, 0x14, 0xbb,
+	0x4c, 0x00, 0xfc, 0x05, 0x40, 0x3c, 0x9b, 0x04, 0x5e, 0xb2, 0x8a, 0xfc, 0x58, 0xdd, 0x6e, 0xc9,
+	0xa7, 0x8d, 0x8b, 0xff, 0xb7, 0x73, 0x47, 0xc5, 0xb3, 0xbc, 0x50, 0xb0, 0x35, 0xb1, 0xf6, 0x2d,
+	0x1c, 0xfe, 0x4b, 0x80, 0x3f, 0x01, 0x54, 0x4a, 0xdc, 0xa9, 0xef, 0x8d, 0xfd, 0x28, 0x3f, 0xf0,
+	0xa0, 0xe4, 0xaf, 0x32, 0x1a, 0x7f, 0x08, 0xf5, 0x92, 0x52, 0xb7, 0x33, 0xcd, 0x3b, 0x42, 0xbb,
+	0x03, 0x25, 0xd7, 0xbd, 0x81, 0xfd, 0xd1, 0xd4, 0x0b, 0x02, 0x7f, 0xbe, 0x59, 0x70, 0x2f, 0x67,
+	0x73, 0xd9, 0xfb, 0x4e, 0xde, 0x7e, 0xef, 0xc9, 0xda, 0x9f, 0x12, 0xec, 0xe9, 0x1b, 0x0f, 0x63,
+	0xa8, 0x24, 0x4f, 0x4b, 0x91, 0x4d, 0x95, 0x65, 0x6b, 0xac, 0x42, 0xed, 0xc1, 0x8f, 0xe2, 0x59,
+	0x18, 0x64, 0x75, 0xaa, 0xac, 0x80, 0xf8, 0x73, 0xa8, 0x97, 0xdd, 0x50, 0xe5, 0x96, 0x74, 0xda,
+	0xb8, 0x38, 0x6e, 0x8b, 0x7e, 0xb5, 0x8b, 0x7e, 0xb5, 0x9d, 0x42, 0xc1, 0xde, 0x89, 0xf1, 0x2b,
+	0x80, 0xe2, 0x2e, 0xb3, 0xb1, 0x5a, 0x69, 0x49, 0xa7, 0x75, 0x56, 0xcf, 0x19, 0x73, 0x8c, 0x9b,
+	0x50, 0x4d, 0x1e, 0xd3, 0x9d, 0x6a, 0xb6, 0x53, 0x49, 0x1e, 0xcd, 0x71, 0xda, 0x38, 0x7f, 0x19,
+	0x8e, 0xa6, 0xaa, 0x22, 0x5a, 0x9b, 0x81, 0x34, 0x3d, 0xff, 0x31, 0xf1, 0x83, 0xcc, 0x5f, 0x4d,
+	0xa4, 0x57, 0x12, 0x1a, 0x81, 0x03, 0xfe, 0x2c, 0x6e, 0x15, 0x6a, 0xa3, 0xc8, 0xf7, 0x92, 0xb0,
+	0xc8, 0xaf, 0x80, 0xe9, 0x01, 0x41, 0x18, 0x8c, 0x8a, 0x26, 0x08, 0xa0, 0x51, 0xa8, 0xdd, 0x78,
+	0x4f, 0xf3, 0xd0, 0x1b, 0xe3, 0x8f, 0x41, 0x59, 0x4b, 0xbe, 0x71, 0xb1, 0x5f, 0x0c, 0x88, 0x28,
+	0xcd, 0x94, 0x69, 0x99, 0x62, 0x3a, 0x0d, 0x79, 0x9d, 0x6c, 0xad, 0x75, 0x61, 0x87, 0x06, 0x0f,
+	0xfe, 0x3c, 0x14, 0x89, 0x2e, 0x45, 0xc9, 0xc2, 0x42, 0x0e, 0xff, 0x63, 0x16, 0x7e, 0x90, 0xa0,
+	0xda, 0x9d, 0x87, 0xa3, 0xef, 0xf0, 0xf9, 0x33, 0x27, 0xcd, 0xc2, 0x49, 0xb6, 0xfd, 0xcc, 0xce,
+	0x9b, 0x35, 0x3b, 0x8d, 0x8b, 0xc3, 0x0d, 0xa9, 0xe1, 0x25, 0x9e, 0x70, 0x88, 0x3f, 0x85, 0x9d,
+	0x45, 0x3e, 0xc7, 0x79, 0x33, 0x8f, 0x36, 0xa4, 0xc5, 0x90, 0xb3, 0x52, 0xa6, 0x4d, 0xa0, 0xb1,
+	0x76, 0x20, 0xfe, 0x1f, 0x28, 0xc1, 0x6a, 0x31, 0xcc, 0x5d, 0x55, 0x58, 0x8e, 0xf0, 0x6b, 0xd8,
+	0x5b, 0x46, 0xfe, 0xc3, 0x2c, 0x5c, 0xc5, 0xee, 0xd4, 0x8b, 0xa7, 0xf9, 0xcd, 0x76, 0x0b, 0xf2,
+	0xca, 0x8b, 0xa7, 0xf8, 0x03, 0xa8, 0xa7, 0x35, 0x85, 0x40, 0xce, 0x04, 0x3b, 0x29, 0x91, 0x6e,
+	0x6a, 0x27, 0x50, 0x2f, 0xed, 0x96, 0xf1, 0x4a, 0x2d, 0xb9, 0x8c, 0xf7, 0x1c, 0xf6, 0x36, 0x4c,
+	0xe2, 0xe3, 0xb5, 0xdb, 0x08, 0x61, 0x89, 0xcf, 0x7e, 0x97, 0x40, 0xe1, 0x89, 0x97, 0xac, 0x62,
+	0xdc, 0x80, 0xda, 0xc0, 0xba, 0xb6, 0xec, 0xaf, 0x2c, 0xb4, 0x85, 0x77, 0xa1, 0xc6, 0x07, 0xba,
+	0x4e, 0x39, 0x47, 0x7f, 0x48, 0x18, 0x41, 0xa3, 0x4b, 0x0c, 0x97, 0xd1, 0x2f, 0x07, 0x94, 0x3b,
+	0xe8, 0x47, 0x19, 0x1f, 0xc2, 0xee, 0xc0, 0x22, 0x03, 0xe7, 0xca, 0x66, 0xe6, 0x1d, 0x35, 0xd0,
+	0x4f, 0x32, 0xde, 0x87, 0x7a, 0xcf, 0x66, 0x5d, 0xd3, 0x30, 0xa8, 0x85, 0x7e, 0xce, 0xb0, 0x65,
+	0x3b, 0x6e, 0xcf, 0x1e, 0x58, 0x06, 0xfa, 0x45, 0xc6, 0xaf, 0x40, 0xcd, 0x0b, 0xb8, 0xd4, 0x72,
+	0x4c, 0xe7, 0x6b, 0xd7, 0xb1, 0x6d, 0xb7, 0x4f, 0xd8, 0x25, 0x45, 0xbf, 0xca, 0xf8, 0x18, 0x8e,
+	0x4c, 0xcb, 0xa1, 0xcc, 0x22, 0x7d, 0x97, 0x53, 0x76, 0x4b, 0x99, 0x4b, 0x19, 0xb3, 0x19, 0xfa,
+	0x4b, 0xc6, 0x2a, 0x34, 0x53, 0xca, 0xd4, 0xa9, 0x3b, 0xb0, 0xc8, 0x2d, 0x31, 0xfb, 0xa4, 0xdb,
+	0xa7, 0xe8, 0x6f, 0xf9, 0xec, 0x37, 0x09, 0x40, 0x44, 0xee, 0xa4, 0x7f, 0xd0, 0x06, 0xd4, 0xde,
+	0x52, 0xce, 0xc9, 0x25, 0x45, 0x5b, 0x18, 0x40, 0xd1, 0x6d, 0xab, 0x67, 0x5e, 0x22, 0x09, 0x1f,
+	0xc2, 0x9e, 0x58, 0xbb, 0x83, 0x1b, 0x83, 0x38, 0x14, 0x6d, 0x63, 0x15, 0x5e, 0x50, 0xcb, 0xb0,
+	0x19, 0xa7, 0xcc, 0x75, 0x18, 0xb1, 0x38, 0xd1, 0x1d, 0xd3, 0xb6, 0x90, 0x8c, 0x5f, 0x42, 0xd3,
+	0x66, 0x06, 0x65, 0xcf, 0x36, 0x2a, 0xf8, 0x08, 0x0e, 0x0d, 0xda, 0x37, 0x53, 0x6f, 0x9c, 0xd2,
+	0x6b, 0xd7, 0xb4, 0x7a, 0x36, 0xaa, 0xa6, 0xb4, 0x7e, 0x45, 0x4c, 0x4b, 0xb7, 0x0d, 0xea, 0xde,
+	0x10, 0xfd, 0x3a, 0x3d, 0x5f, 0xc1, 0x07, 0xd0, 0x28, 0xd4, 0x44, 0xbf, 0x46, 0xb5, 0xb3, 0xef,
+	0x25, 0xc0, 0x1b, 0xad, 0x31, 0xd3, 0x57, 0x32, 0xde, 0x07, 0xe0, 0xe6, 0xa5, 0x45, 0x9c, 0x01,
+	0xa3, 0x1c, 0x6d, 0xa5, 0xcf, 0xf5, 0x09, 0x77, 0xdc, 0xd2, 0xfc, 0x4b, 0x68, 0xae, 0xf9, 0xe0,
+	0x6e, 0xcf, 0xec, 0x3b, 0x94, 0xa1, 0xed, 0xf4, 0xba, 0xb9, 0x51, 0x24, 0xe3, 0xd7, 0x70, 0xb2,
+	0xa1, 0xba, 0x25, 0x7d, 0xd3, 0x20, 0xe9, 0xda, 0x65, 0x94, 0x70, 0xdb, 0xe2, 0xa8, 0xd2, 0xe5,
+	0xf0, 0x51, 0x18, 0x4d, 0xda, 0xd3, 0xa7, 0xa5, 0x1f, 0xcd, 0xfd, 0xf1, 0xc4, 0x8f, 0xda, 0xf7,
+	0xde, 0x30, 0x9a, 0x8d, 0xc4, 0x5b, 0x2a, 0xce, 0xc7, 0xfc, 0xee, 0x7c, 0x32, 0x4b, 0xa6, 0xab,
+	0x61, 0x0a, 0x3b, 0x6b, 0xe2, 0x8e, 0x10, 0x8b, 0x4f, 0x50, 0x9c, 0x7f, 0xa6, 0x86, 0x4a, 0x06,
+	0x3f, 0xfb, 0x67, 0x00, 0xa6, 0x3e, 0xe2, 0xae, 0xbe, 0x06, 0x00, 0x00,
 }
@@ -0,0 +1,132 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package common
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/hyperledger/fabric/common/util"
+)
+
+// The Merkle tree over the block data follows the construction of RFC 6962, where
+// leaves and inner nodes are hashed with distinct prefixes, and the left subtree of
+// every node holds the largest power of two of leaves smaller than the node's leaves
+const (
+	merkleLeafPrefix = 0x00
+	merkleNodePrefix = 0x01
+)
+
+// MerkleRoot returns the root of the binary Merkle tree whose leaves are the
+// entries of the block data. The DataHash of the block header is this root
+// once the channel requires the ChannelV1_2 capability, and the flat hash
+// returned by Hash before that
+func (b *BlockData) MerkleRoot() []byte {
+	if len(b.Data) == 0 {
+		return util.ComputeSHA256(nil)
+	}
+	return merkleRoot(b.Data)
+}
+
+// HeaderDataHash returns the DataHash of the header of a block holding the data,
+// which is the Merkle root of the data if merkle is true, and its flat hash otherwise
+func (b *BlockData) HeaderDataHash(merkle bool) []byte {
+	if merkle {
+		return b.MerkleRoot()
+	}
+	return b.Hash()
+}
+
+// MatchesDataHash returns true if the given DataHash of a block header is either the
+// Merkle root or the flat hash of the data. It is meant for the verifiers which cannot tell
+// which of them the channel required when the block was created. The two cannot be confused
+// as long as the data does not start with the prefix of a Merkle leaf or node, which marshaled
+// envelopes never start with, so such data never matches
+func (b *BlockData) MatchesDataHash(dataHash []byte) bool {
+	for _, entry := range b.Data {
+		if len(entry) == 0 {
+			continue
+		}
+		if entry[0] == merkleLeafPrefix || entry[0] == merkleNodePrefix {
+			return false
+		}
+		break
+	}
+	return bytes.Equal(b.Hash(), dataHash) || bytes.Equal(b.MerkleRoot(), dataHash)
+}
+
+// MerklePath returns the hashes of the sibling subtrees on the way from the leaf
+// of the entry at the given index up to the root, ordered from the leaf upwards
+func (b *BlockData) MerklePath(index uint64) ([][]byte, error) {
+	if index >= uint64(len(b.Data)) {
+		return nil, fmt.Errorf("index %d is out of range, block data has %d entries", index, len(b.Data))
+	}
+	return merklePath(index, b.Data), nil
+}
+
+// VerifyMerklePath returns true if the given path leads from the entry at the given
+// index of a block data of count entries to the given Merkle root
+func VerifyMerklePath(data []byte, index uint64, count uint64, path [][]byte, root []byte) bool {
+	if index >= count {
+		return false
+	}
+	fn, sn := index, count-1
+	r := merkleLeafHash(data)
+	for _, p := range path {
+		if sn == 0 {
+			return false
+		}
+		if fn&1 == 1 || fn == sn {
+			r = merkleNodeHash(p, r)
+			for fn&1 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			r = merkleNodeHash(r, p)
+		}
+		fn >>= 1
+		sn >>= 1
+	}
+	return sn == 0 && bytes.Equal(r, root)
+}
+
+func merkleRoot(leaves [][]byte) []byte {
+	if len(leaves) == 1 {
+		return merkleLeafHash(leaves[0])
+	}
+	k := merkleSplit(len(leaves))
+	return merkleNodeHash(merkleRoot(leaves[:k]), merkleRoot(leaves[k:]))
+}
+
+func merklePath(index uint64, leaves [][]byte) [][]byte {
+	if len(leaves) == 1 {
+		return nil
+	}
+	k := merkleSplit(len(leaves))
+	if index < uint64(k) {
+		return append(merklePath(index, leaves[:k]), merkleRoot(leaves[k:]))
+	}
+	return append(merklePath(index-uint64(k), leaves[k:]), merkleRoot(leaves[:k]))
+}
+
+// merkleSplit returns the largest power of two smaller than n, for n > 1
+func merkleSplit(n int) int {
+	k := 1
+	for k<<1 < n {
+		k <<= 1
+	}
+	return k
+}
+
+func merkleLeafHash(data []byte) []byte {
+	return util.ComputeSHA256(util.ConcatenateBytes([]byte{merkleLeafPrefix}, data))
+}
+
+func merkleNodeHash(left, right []byte) []byte {
+	return util.ComputeSHA256(util.ConcatenateBytes([]byte{merkleNodePrefix}, left, right))
+}
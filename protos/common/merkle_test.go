@@ -0,0 +1,73 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package common
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/fabric/common/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMerkleRoot(t *testing.T) {
+	assert.Equal(t, util.ComputeSHA256(nil), (&BlockData{}).MerkleRoot())
+
+	a, b, c := []byte("a"), []byte("b"), []byte("c")
+	leafA := util.ComputeSHA256(append([]byte{0}, a...))
+	leafB := util.ComputeSHA256(append([]byte{0}, b...))
+	leafC := util.ComputeSHA256(append([]byte{0}, c...))
+	nodeAB := util.ComputeSHA256(util.ConcatenateBytes([]byte{1}, leafA, leafB))
+	assert.Equal(t, leafA, (&BlockData{Data: [][]byte{a}}).MerkleRoot())
+	assert.Equal(t, nodeAB, (&BlockData{Data: [][]byte{a, b}}).MerkleRoot())
+	// The left subtree holds the largest power of two of leaves
+	assert.Equal(t, util.ComputeSHA256(util.ConcatenateBytes([]byte{1}, nodeAB, leafC)),
+		(&BlockData{Data: [][]byte{a, b, c}}).MerkleRoot())
+}
+
+func TestMerklePath(t *testing.T) {
+	for count := 1; count <= 17; count++ {
+		blockData := &BlockData{}
+		for i := 0; i < count; i++ {
+			blockData.Data = append(blockData.Data, []byte(fmt.Sprintf("tx%d", i)))
+		}
+		root := blockData.MerkleRoot()
+		for index := 0; index < count; index++ {
+			path, err := blockData.MerklePath(uint64(index))
+			assert.NoError(t, err)
+			assert.True(t, VerifyMerklePath(blockData.Data[index], uint64(index), uint64(count), path, root),
+				"path of entry %d of %d should verify", index, count)
+
+			assert.False(t, VerifyMerklePath([]byte("forged"), uint64(index), uint64(count), path, root))
+			if count > 1 {
+				otherIndex := (index + 1) % count
+				assert.False(t, VerifyMerklePath(blockData.Data[index], uint64(otherIndex), uint64(count), path, root))
+				assert.False(t, VerifyMerklePath(blockData.Data[index], uint64(index), uint64(count), path[1:], root))
+			}
+		}
+		_, err := blockData.MerklePath(uint64(count))
+		assert.Error(t, err)
+	}
+}
+
+func TestHeaderDataHash(t *testing.T) {
+	blockData := &BlockData{Data: [][]byte{[]byte("\x0atx0"), []byte("\x0atx1"), []byte("\x0atx2")}}
+	assert.Equal(t, blockData.Hash(), blockData.HeaderDataHash(false))
+	assert.Equal(t, blockData.MerkleRoot(), blockData.HeaderDataHash(true))
+
+	assert.True(t, blockData.MatchesDataHash(blockData.Hash()))
+	assert.True(t, blockData.MatchesDataHash(blockData.MerkleRoot()))
+	assert.False(t, blockData.MatchesDataHash(util.ComputeSHA256([]byte("other"))))
+
+	// The data made of the node of the Merkle tree has the Merkle root for flat hash
+	left, right := merkleRoot(blockData.Data[:2]), merkleRoot(blockData.Data[2:])
+	forged := &BlockData{Data: [][]byte{util.ConcatenateBytes([]byte{merkleNodePrefix}, left, right)}}
+	assert.Equal(t, blockData.MerkleRoot(), forged.Hash())
+	assert.False(t, forged.MatchesDataHash(blockData.MerkleRoot()))
+	forged.Data = append([][]byte{nil}, forged.Data...)
+	assert.False(t, forged.MatchesDataHash(blockData.MerkleRoot()))
+}
@@ -161,9 +161,16 @@ func (m *GossipMessage) IsPullMsg() bool {
 		m.GetHello() != nil || m.GetDataDig() != nil
 }
 
+// IsPrivateDataMsg returns whether this GossipMessage is a private data message
+func (m *GossipMessage) IsPrivateDataMsg() bool {
+	return m.GetPrivateData() != nil
+}
+
 // IsRemoteStateMessage returns whether this GossipMessage is related to state synchronization
 func (m *GossipMessage) IsRemoteStateMessage() bool {
-	return m.GetStateRequest() != nil || m.GetStateResponse() != nil
+	return m.GetStateRequest() != nil || m.GetStateResponse() != nil ||
+		m.GetStateDiffRequest() != nil || m.GetStateDiffResponse() != nil ||
+		m.GetStateBootstrapOffer() != nil || m.GetStateBootstrapAccept() != nil
 }
 
 // GetPullMsgType returns the phase of the pull mechanism this GossipMessage belongs to
@@ -288,6 +295,13 @@ func (m *GossipMessage) IsTagLegal() error {
 		return nil
 	}
 
+	if m.IsPrivateDataMsg() {
+		if m.Tag != GossipMessage_CHAN_ONLY {
+			return fmt.Errorf("Tag should be %s", GossipMessage_Tag_name[int32(GossipMessage_CHAN_ONLY)])
+		}
+		return nil
+	}
+
 	if m.IsLeadershipMsg() {
 		if m.Tag != GossipMessage_CHAN_AND_ORG {
 			return fmt.Errorf("Tag should be %s", GossipMessage_Tag_name[int32(GossipMessage_CHAN_AND_ORG)])
@@ -328,6 +342,10 @@ type ReceivedMessage interface {
 	// GetConnectionInfo returns information about the remote peer
 	// that sent the message
 	GetConnectionInfo() *ConnectionInfo
+
+	// Ack returns to the sender an acknowledgement for the message,
+	// with the error processing the message failed with, if any
+	Ack(err error)
 }
 
 // ConnectionInfo represents information about
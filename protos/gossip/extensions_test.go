@@ -443,6 +443,43 @@ func TestCheckGossipMessageTypes(t *testing.T) {
 		},
 	})
 	assert.True(t, msg.IsRemoteStateMessage())
+
+	// Create state difference request and response messages
+	msg = signedGossipMessage(channelID, GossipMessage_EMPTY, &GossipMessage_StateDiffRequest{
+		StateDiffRequest: &StateDiffRequest{Checkpoint: 1},
+	})
+	assert.True(t, msg.IsRemoteStateMessage())
+
+	msg = signedGossipMessage(channelID, GossipMessage_EMPTY, &GossipMessage_StateDiffResponse{
+		StateDiffResponse: &StateDiffResponse{Checkpoint: 1, Height: 2},
+	})
+	assert.True(t, msg.IsRemoteStateMessage())
+
+	// Create bootstrap offer and accept messages
+	msg = signedGossipMessage(channelID, GossipMessage_CHAN_OR_ORG, &GossipMessage_StateBootstrapOffer{
+		StateBootstrapOffer: &StateBootstrapOffer{Height: 100},
+	})
+	assert.True(t, msg.IsRemoteStateMessage())
+	assert.NoError(t, msg.IsTagLegal())
+
+	msg = signedGossipMessage(channelID, GossipMessage_CHAN_OR_ORG, &GossipMessage_StateBootstrapAccept{
+		StateBootstrapAccept: &StateBootstrapAccept{Height: 1},
+	})
+	assert.True(t, msg.IsRemoteStateMessage())
+	assert.NoError(t, msg.IsTagLegal())
+
+	// Create private data message
+	msg = signedGossipMessage(channelID, GossipMessage_CHAN_ONLY, &GossipMessage_PrivateData{
+		PrivateData: &PrivateDataMessage{Payload: &PrivatePayload{Namespace: "ns", CollectionName: "coll", TxId: "tx"}},
+	})
+	assert.True(t, msg.IsPrivateDataMsg())
+	assert.False(t, msg.IsRemoteStateMessage())
+	assert.NoError(t, msg.IsTagLegal())
+
+	msg = signedGossipMessage(channelID, GossipMessage_CHAN_AND_ORG, &GossipMessage_PrivateData{
+		PrivateData: &PrivateDataMessage{},
+	})
+	assert.Error(t, msg.IsTagLegal())
 }
 
 func TestGossipPullMessageType(t *testing.T) {
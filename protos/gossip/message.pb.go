@@ -24,6 +24,7 @@ It has these top-level messages:
 	DataMessage
 	Payload
 	PrivatePayload
+	PrivateDataMessage
 	AliveMessage
 	LeadershipMessage
 	PeerTime
@@ -33,6 +34,12 @@ It has these top-level messages:
 	Empty
 	RemoteStateRequest
 	RemoteStateResponse
+	StateBootstrapOffer
+	StateBootstrapAccept
+	Acknowledgement
+	StateDiffRequest
+	StateDiffResponse
+	StateDiffEntry
 	RemotePvtDataRequest
 	RemotePvtDataResponse
 	PvtDataPayload
@@ -300,6 +307,12 @@ type GossipMessage struct {
 	//	*GossipMessage_StateResponse
 	//	*GossipMessage_LeadershipMsg
 	//	*GossipMessage_PeerIdentity
+	//	*GossipMessage_StateDiffRequest
+	//	*GossipMessage_StateDiffResponse
+	//	*GossipMessage_PrivateData
+	//	*GossipMessage_StateBootstrapOffer
+	//	*GossipMessage_StateBootstrapAccept
+	//	*GossipMessage_Ack
 	Content isGossipMessage_Content `protobuf_oneof:"content"`
 }
 
@@ -363,24 +376,48 @@ type GossipMessage_LeadershipMsg struct {
 type GossipMessage_PeerIdentity struct {
 	PeerIdentity *PeerIdentity `protobuf:"bytes,21,opt,name=peer_identity,json=peerIdentity,oneof"`
 }
-
-func (*GossipMessage_AliveMsg) isGossipMessage_Content()         {}
-func (*GossipMessage_MemReq) isGossipMessage_Content()           {}
-func (*GossipMessage_MemRes) isGossipMessage_Content()           {}
-func (*GossipMessage_DataMsg) isGossipMessage_Content()          {}
-func (*GossipMessage_Hello) isGossipMessage_Content()            {}
-func (*GossipMessage_DataDig) isGossipMessage_Content()          {}
-func (*GossipMessage_DataReq) isGossipMessage_Content()          {}
-func (*GossipMessage_DataUpdate) isGossipMessage_Content()       {}
-func (*GossipMessage_Empty) isGossipMessage_Content()            {}
-func (*GossipMessage_Conn) isGossipMessage_Content()             {}
-func (*GossipMessage_StateInfo) isGossipMessage_Content()        {}
-func (*GossipMessage_StateSnapshot) isGossipMessage_Content()    {}
-func (*GossipMessage_StateInfoPullReq) isGossipMessage_Content() {}
-func (*GossipMessage_StateRequest) isGossipMessage_Content()     {}
-func (*GossipMessage_StateResponse) isGossipMessage_Content()    {}
-func (*GossipMessage_LeadershipMsg) isGossipMessage_Content()    {}
-func (*GossipMessage_PeerIdentity) isGossipMessage_Content()     {}
+type GossipMessage_StateDiffRequest struct {
+	StateDiffRequest *StateDiffRequest `protobuf:"bytes,22,opt,name=state_diff_request,json=stateDiffRequest,oneof"`
+}
+type GossipMessage_StateDiffResponse struct {
+	StateDiffResponse *StateDiffResponse `protobuf:"bytes,23,opt,name=state_diff_response,json=stateDiffResponse,oneof"`
+}
+type GossipMessage_PrivateData struct {
+	PrivateData *PrivateDataMessage `protobuf:"bytes,24,opt,name=private_data,json=privateData,oneof"`
+}
+type GossipMessage_StateBootstrapOffer struct {
+	StateBootstrapOffer *StateBootstrapOffer `protobuf:"bytes,25,opt,name=state_bootstrap_offer,json=stateBootstrapOffer,oneof"`
+}
+type GossipMessage_StateBootstrapAccept struct {
+	StateBootstrapAccept *StateBootstrapAccept `protobuf:"bytes,26,opt,name=state_bootstrap_accept,json=stateBootstrapAccept,oneof"`
+}
+type GossipMessage_Ack struct {
+	Ack *Acknowledgement `protobuf:"bytes,27,opt,name=ack,oneof"`
+}
+
+func (*GossipMessage_AliveMsg) isGossipMessage_Content()             {}
+func (*GossipMessage_MemReq) isGossipMessage_Content()               {}
+func (*GossipMessage_MemRes) isGossipMessage_Content()               {}
+func (*GossipMessage_DataMsg) isGossipMessage_Content()              {}
+func (*GossipMessage_Hello) isGossipMessage_Content()                {}
+func (*GossipMessage_DataDig) isGossipMessage_Content()              {}
+func (*GossipMessage_DataReq) isGossipMessage_Content()              {}
+func (*GossipMessage_DataUpdate) isGossipMessage_Content()           {}
+func (*GossipMessage_Empty) isGossipMessage_Content()                {}
+func (*GossipMessage_Conn) isGossipMessage_Content()                 {}
+func (*GossipMessage_StateInfo) isGossipMessage_Content()            {}
+func (*GossipMessage_StateSnapshot) isGossipMessage_Content()        {}
+func (*GossipMessage_StateInfoPullReq) isGossipMessage_Content()     {}
+func (*GossipMessage_StateRequest) isGossipMessage_Content()         {}
+func (*GossipMessage_StateResponse) isGossipMessage_Content()        {}
+func (*GossipMessage_LeadershipMsg) isGossipMessage_Content()        {}
+func (*GossipMessage_PeerIdentity) isGossipMessage_Content()         {}
+func (*GossipMessage_StateDiffRequest) isGossipMessage_Content()     {}
+func (*GossipMessage_StateDiffResponse) isGossipMessage_Content()    {}
+func (*GossipMessage_PrivateData) isGossipMessage_Content()          {}
+func (*GossipMessage_StateBootstrapOffer) isGossipMessage_Content()  {}
+func (*GossipMessage_StateBootstrapAccept) isGossipMessage_Content() {}
+func (*GossipMessage_Ack) isGossipMessage_Content()                  {}
 
 func (m *GossipMessage) GetContent() isGossipMessage_Content {
 	if m != nil {
@@ -529,6 +566,48 @@ func (m *GossipMessage) GetPeerIdentity() *PeerIdentity {
 	return nil
 }
 
+func (m *GossipMessage) GetStateDiffRequest() *StateDiffRequest {
+	if x, ok := m.GetContent().(*GossipMessage_StateDiffRequest); ok {
+		return x.StateDiffRequest
+	}
+	return nil
+}
+
+func (m *GossipMessage) GetStateDiffResponse() *StateDiffResponse {
+	if x, ok := m.GetContent().(*GossipMessage_StateDiffResponse); ok {
+		return x.StateDiffResponse
+	}
+	return nil
+}
+
+func (m *GossipMessage) GetPrivateData() *PrivateDataMessage {
+	if x, ok := m.GetContent().(*GossipMessage_PrivateData); ok {
+		return x.PrivateData
+	}
+	return nil
+}
+
+func (m *GossipMessage) GetStateBootstrapOffer() *StateBootstrapOffer {
+	if x, ok := m.GetContent().(*GossipMessage_StateBootstrapOffer); ok {
+		return x.StateBootstrapOffer
+	}
+	return nil
+}
+
+func (m *GossipMessage) GetStateBootstrapAccept() *StateBootstrapAccept {
+	if x, ok := m.GetContent().(*GossipMessage_StateBootstrapAccept); ok {
+		return x.StateBootstrapAccept
+	}
+	return nil
+}
+
+func (m *GossipMessage) GetAck() *Acknowledgement {
+	if x, ok := m.GetContent().(*GossipMessage_Ack); ok {
+		return x.Ack
+	}
+	return nil
+}
+
 // XXX_OneofFuncs is for the internal use of the proto package.
 func (*GossipMessage) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) error, func(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error), func(msg proto.Message) (n int), []interface{}) {
 	return _GossipMessage_OneofMarshaler, _GossipMessage_OneofUnmarshaler, _GossipMessage_OneofSizer, []interface{}{
@@ -549,6 +628,12 @@ func (*GossipMessage) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer)
 		(*GossipMessage_StateResponse)(nil),
 		(*GossipMessage_LeadershipMsg)(nil),
 		(*GossipMessage_PeerIdentity)(nil),
+		(*GossipMessage_StateDiffRequest)(nil),
+		(*GossipMessage_StateDiffResponse)(nil),
+		(*GossipMessage_PrivateData)(nil),
+		(*GossipMessage_StateBootstrapOffer)(nil),
+		(*GossipMessage_StateBootstrapAccept)(nil),
+		(*GossipMessage_Ack)(nil),
 	}
 }
 
@@ -641,6 +726,36 @@ func _GossipMessage_OneofMarshaler(msg proto.Message, b *proto.Buffer) error {
 		if err := b.EncodeMessage(x.PeerIdentity); err != nil {
 			return err
 		}
+	case *GossipMessage_StateDiffRequest:
+		b.EncodeVarint(22<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.StateDiffRequest); err != nil {
+			return err
+		}
+	case *GossipMessage_StateDiffResponse:
+		b.EncodeVarint(23<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.StateDiffResponse); err != nil {
+			return err
+		}
+	case *GossipMessage_PrivateData:
+		b.EncodeVarint(24<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.PrivateData); err != nil {
+			return err
+		}
+	case *GossipMessage_StateBootstrapOffer:
+		b.EncodeVarint(25<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.StateBootstrapOffer); err != nil {
+			return err
+		}
+	case *GossipMessage_StateBootstrapAccept:
+		b.EncodeVarint(26<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.StateBootstrapAccept); err != nil {
+			return err
+		}
+	case *GossipMessage_Ack:
+		b.EncodeVarint(27<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Ack); err != nil {
+			return err
+		}
 	case nil:
 	default:
 		return fmt.Errorf("GossipMessage.Content has unexpected type %T", x)
@@ -787,6 +902,54 @@ func _GossipMessage_OneofUnmarshaler(msg proto.Message, tag, wire int, b *proto.
 		err := b.DecodeMessage(msg)
 		m.Content = &GossipMessage_PeerIdentity{msg}
 		return true, err
+	case 22: // content.state_diff_request
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(StateDiffRequest)
+		err := b.DecodeMessage(msg)
+		m.Content = &GossipMessage_StateDiffRequest{msg}
+		return true, err
+	case 23: // content.state_diff_response
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(StateDiffResponse)
+		err := b.DecodeMessage(msg)
+		m.Content = &GossipMessage_StateDiffResponse{msg}
+		return true, err
+	case 24: // content.private_data
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(PrivateDataMessage)
+		err := b.DecodeMessage(msg)
+		m.Content = &GossipMessage_PrivateData{msg}
+		return true, err
+	case 25: // content.state_bootstrap_offer
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(StateBootstrapOffer)
+		err := b.DecodeMessage(msg)
+		m.Content = &GossipMessage_StateBootstrapOffer{msg}
+		return true, err
+	case 26: // content.state_bootstrap_accept
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(StateBootstrapAccept)
+		err := b.DecodeMessage(msg)
+		m.Content = &GossipMessage_StateBootstrapAccept{msg}
+		return true, err
+	case 27: // content.ack
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(Acknowledgement)
+		err := b.DecodeMessage(msg)
+		m.Content = &GossipMessage_Ack{msg}
+		return true, err
 	default:
 		return false, nil
 	}
@@ -881,6 +1044,36 @@ func _GossipMessage_OneofSizer(msg proto.Message) (n int) {
 		n += proto.SizeVarint(21<<3 | proto.WireBytes)
 		n += proto.SizeVarint(uint64(s))
 		n += s
+	case *GossipMessage_StateDiffRequest:
+		s := proto.Size(x.StateDiffRequest)
+		n += proto.SizeVarint(22<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *GossipMessage_StateDiffResponse:
+		s := proto.Size(x.StateDiffResponse)
+		n += proto.SizeVarint(23<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *GossipMessage_PrivateData:
+		s := proto.Size(x.PrivateData)
+		n += proto.SizeVarint(24<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *GossipMessage_StateBootstrapOffer:
+		s := proto.Size(x.StateBootstrapOffer)
+		n += proto.SizeVarint(25<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *GossipMessage_StateBootstrapAccept:
+		s := proto.Size(x.StateBootstrapAccept)
+		n += proto.SizeVarint(26<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *GossipMessage_Ack:
+		s := proto.Size(x.Ack)
+		n += proto.SizeVarint(27<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
 	case nil:
 	default:
 		panic(fmt.Sprintf("proto: unexpected type %T in oneof", x))
@@ -1233,6 +1426,12 @@ func (m *Payload) GetPrivateData() [][]byte {
 type PrivatePayload struct {
 	CollectionName string   `protobuf:"bytes,1,opt,name=collection_name,json=collectionName" json:"collection_name,omitempty"`
 	PrivateData    [][]byte `protobuf:"bytes,2,rep,name=private_data,json=privateData,proto3" json:"private_data,omitempty"`
+	Namespace      string   `protobuf:"bytes,3,opt,name=namespace" json:"namespace,omitempty"`
+	TxId           string   `protobuf:"bytes,4,opt,name=tx_id,json=txId" json:"tx_id,omitempty"`
+	// Encodes marshaled bytes of rwset.TxPvtReadWriteSet
+	// restricted to the collection
+	PrivateRwset     []byte `protobuf:"bytes,5,opt,name=private_rwset,json=privateRwset,proto3" json:"private_rwset,omitempty"`
+	PrivateSimHeight uint64 `protobuf:"varint,6,opt,name=private_sim_height,json=privateSimHeight" json:"private_sim_height,omitempty"`
 }
 
 func (m *PrivatePayload) Reset()                    { *m = PrivatePayload{} }
@@ -1254,6 +1453,53 @@ func (m *PrivatePayload) GetPrivateData() [][]byte {
 	return nil
 }
 
+func (m *PrivatePayload) GetNamespace() string {
+	if m != nil {
+		return m.Namespace
+	}
+	return ""
+}
+
+func (m *PrivatePayload) GetTxId() string {
+	if m != nil {
+		return m.TxId
+	}
+	return ""
+}
+
+func (m *PrivatePayload) GetPrivateRwset() []byte {
+	if m != nil {
+		return m.PrivateRwset
+	}
+	return nil
+}
+
+func (m *PrivatePayload) GetPrivateSimHeight() uint64 {
+	if m != nil {
+		return m.PrivateSimHeight
+	}
+	return 0
+}
+
+// PrivateDataMessage message which includes the private
+// data of a collection pushed to the collection members
+// once the transaction has been endorsed
+type PrivateDataMessage struct {
+	Payload *PrivatePayload `protobuf:"bytes,1,opt,name=payload" json:"payload,omitempty"`
+}
+
+func (m *PrivateDataMessage) Reset()                    { *m = PrivateDataMessage{} }
+func (m *PrivateDataMessage) String() string            { return proto.CompactTextString(m) }
+func (*PrivateDataMessage) ProtoMessage()               {}
+func (*PrivateDataMessage) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{16} }
+
+func (m *PrivateDataMessage) GetPayload() *PrivatePayload {
+	if m != nil {
+		return m.Payload
+	}
+	return nil
+}
+
 // AliveMessage is sent to inform remote peers
 // of a peer's existence and activity
 type AliveMessage struct {
@@ -1265,7 +1511,7 @@ type AliveMessage struct {
 func (m *AliveMessage) Reset()                    { *m = AliveMessage{} }
 func (m *AliveMessage) String() string            { return proto.CompactTextString(m) }
 func (*AliveMessage) ProtoMessage()               {}
-func (*AliveMessage) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{16} }
+func (*AliveMessage) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{17} }
 
 func (m *AliveMessage) GetMembership() *Member {
 	if m != nil {
@@ -1299,7 +1545,7 @@ type LeadershipMessage struct {
 func (m *LeadershipMessage) Reset()                    { *m = LeadershipMessage{} }
 func (m *LeadershipMessage) String() string            { return proto.CompactTextString(m) }
 func (*LeadershipMessage) ProtoMessage()               {}
-func (*LeadershipMessage) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{17} }
+func (*LeadershipMessage) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{18} }
 
 func (m *LeadershipMessage) GetPkiId() []byte {
 	if m != nil {
@@ -1331,7 +1577,7 @@ type PeerTime struct {
 func (m *PeerTime) Reset()                    { *m = PeerTime{} }
 func (m *PeerTime) String() string            { return proto.CompactTextString(m) }
 func (*PeerTime) ProtoMessage()               {}
-func (*PeerTime) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{18} }
+func (*PeerTime) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{19} }
 
 func (m *PeerTime) GetIncNum() uint64 {
 	if m != nil {
@@ -1357,7 +1603,7 @@ type MembershipRequest struct {
 func (m *MembershipRequest) Reset()                    { *m = MembershipRequest{} }
 func (m *MembershipRequest) String() string            { return proto.CompactTextString(m) }
 func (*MembershipRequest) ProtoMessage()               {}
-func (*MembershipRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{19} }
+func (*MembershipRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{20} }
 
 func (m *MembershipRequest) GetSelfInformation() *Envelope {
 	if m != nil {
@@ -1382,7 +1628,7 @@ type MembershipResponse struct {
 func (m *MembershipResponse) Reset()                    { *m = MembershipResponse{} }
 func (m *MembershipResponse) String() string            { return proto.CompactTextString(m) }
 func (*MembershipResponse) ProtoMessage()               {}
-func (*MembershipResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{20} }
+func (*MembershipResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{21} }
 
 func (m *MembershipResponse) GetAlive() []*Envelope {
 	if m != nil {
@@ -1409,7 +1655,7 @@ type Member struct {
 func (m *Member) Reset()                    { *m = Member{} }
 func (m *Member) String() string            { return proto.CompactTextString(m) }
 func (*Member) ProtoMessage()               {}
-func (*Member) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{21} }
+func (*Member) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{22} }
 
 func (m *Member) GetEndpoint() string {
 	if m != nil {
@@ -1439,7 +1685,7 @@ type Empty struct {
 func (m *Empty) Reset()                    { *m = Empty{} }
 func (m *Empty) String() string            { return proto.CompactTextString(m) }
 func (*Empty) ProtoMessage()               {}
-func (*Empty) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{22} }
+func (*Empty) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{23} }
 
 // RemoteStateRequest is used to ask a set of blocks
 // from a remote peer
@@ -1451,7 +1697,7 @@ type RemoteStateRequest struct {
 func (m *RemoteStateRequest) Reset()                    { *m = RemoteStateRequest{} }
 func (m *RemoteStateRequest) String() string            { return proto.CompactTextString(m) }
 func (*RemoteStateRequest) ProtoMessage()               {}
-func (*RemoteStateRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{23} }
+func (*RemoteStateRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{24} }
 
 func (m *RemoteStateRequest) GetStartSeqNum() uint64 {
 	if m != nil {
@@ -1476,7 +1722,7 @@ type RemoteStateResponse struct {
 func (m *RemoteStateResponse) Reset()                    { *m = RemoteStateResponse{} }
 func (m *RemoteStateResponse) String() string            { return proto.CompactTextString(m) }
 func (*RemoteStateResponse) ProtoMessage()               {}
-func (*RemoteStateResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{24} }
+func (*RemoteStateResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{25} }
 
 func (m *RemoteStateResponse) GetPayloads() []*Payload {
 	if m != nil {
@@ -1485,6 +1731,265 @@ func (m *RemoteStateResponse) GetPayloads() []*Payload {
 	return nil
 }
 
+// StateBootstrapOffer is sent by a peer in sync with its channel
+// to a peer that joined the channel far behind it, offering to push
+// it the blocks up to (and not including) the given height
+type StateBootstrapOffer struct {
+	Height uint64 `protobuf:"varint,1,opt,name=height" json:"height,omitempty"`
+}
+
+func (m *StateBootstrapOffer) Reset()                    { *m = StateBootstrapOffer{} }
+func (m *StateBootstrapOffer) String() string            { return proto.CompactTextString(m) }
+func (*StateBootstrapOffer) ProtoMessage()               {}
+func (*StateBootstrapOffer) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{26} }
+
+func (m *StateBootstrapOffer) GetHeight() uint64 {
+	if m != nil {
+		return m.Height
+	}
+	return 0
+}
+
+// StateBootstrapAccept is sent back by the peer accepting a
+// StateBootstrapOffer, with the ledger height the blocks are to be
+// pushed from, and once again for every batch of blocks it commits
+type StateBootstrapAccept struct {
+	Height uint64 `protobuf:"varint,1,opt,name=height" json:"height,omitempty"`
+}
+
+func (m *StateBootstrapAccept) Reset()                    { *m = StateBootstrapAccept{} }
+func (m *StateBootstrapAccept) String() string            { return proto.CompactTextString(m) }
+func (*StateBootstrapAccept) ProtoMessage()               {}
+func (*StateBootstrapAccept) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{27} }
+
+func (m *StateBootstrapAccept) GetHeight() uint64 {
+	if m != nil {
+		return m.Height
+	}
+	return 0
+}
+
+// Acknowledgement is sent back by a peer receiving a message that was
+// sent waiting for acknowledgements, with the nonce of the message.
+// error is empty if the message was processed successfully
+type Acknowledgement struct {
+	Error string `protobuf:"bytes,1,opt,name=error" json:"error,omitempty"`
+}
+
+func (m *Acknowledgement) Reset()                    { *m = Acknowledgement{} }
+func (m *Acknowledgement) String() string            { return proto.CompactTextString(m) }
+func (*Acknowledgement) ProtoMessage()               {}
+func (*Acknowledgement) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{28} }
+
+func (m *Acknowledgement) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+// StateDiffRequest is used to ask a remote peer for a page of the
+// state key-value pairs written after the given checkpoint. The first
+// page is asked at height 0, the next ones at the height of the first
+// page, so that all the pages are of the same state differences
+type StateDiffRequest struct {
+	Checkpoint uint64 `protobuf:"varint,1,opt,name=checkpoint" json:"checkpoint,omitempty"`
+	Height     uint64 `protobuf:"varint,2,opt,name=height" json:"height,omitempty"`
+	// start is the position of the first entry of the page
+	// among the entries of the state differences
+	Start    uint64 `protobuf:"varint,3,opt,name=start" json:"start,omitempty"`
+	PageSize uint32 `protobuf:"varint,4,opt,name=page_size,json=pageSize" json:"page_size,omitempty"`
+}
+
+func (m *StateDiffRequest) Reset()                    { *m = StateDiffRequest{} }
+func (m *StateDiffRequest) String() string            { return proto.CompactTextString(m) }
+func (*StateDiffRequest) ProtoMessage()               {}
+func (*StateDiffRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{29} }
+
+func (m *StateDiffRequest) GetCheckpoint() uint64 {
+	if m != nil {
+		return m.Checkpoint
+	}
+	return 0
+}
+
+func (m *StateDiffRequest) GetHeight() uint64 {
+	if m != nil {
+		return m.Height
+	}
+	return 0
+}
+
+func (m *StateDiffRequest) GetStart() uint64 {
+	if m != nil {
+		return m.Start
+	}
+	return 0
+}
+
+func (m *StateDiffRequest) GetPageSize() uint32 {
+	if m != nil {
+		return m.PageSize
+	}
+	return 0
+}
+
+// StateDiffResponse is used to send a remote peer a page of the
+// state key-value pairs written after the requested checkpoint,
+// up to (and not including) the given height
+type StateDiffResponse struct {
+	Checkpoint uint64            `protobuf:"varint,1,opt,name=checkpoint" json:"checkpoint,omitempty"`
+	Height     uint64            `protobuf:"varint,2,opt,name=height" json:"height,omitempty"`
+	Entries    []*StateDiffEntry `protobuf:"bytes,3,rep,name=entries" json:"entries,omitempty"`
+	// checksum is the SHA256 hash over the channel, the checkpoint,
+	// the height and all the entries of the state differences,
+	// the same for all the pages
+	Checksum []byte `protobuf:"bytes,4,opt,name=checksum,proto3" json:"checksum,omitempty"`
+	Start    uint64 `protobuf:"varint,5,opt,name=start" json:"start,omitempty"`
+	// total is the number of entries of the state differences
+	Total uint64 `protobuf:"varint,6,opt,name=total" json:"total,omitempty"`
+	// signature is the signature of the checksum
+	// by the identity of the responding peer
+	Signature []byte `protobuf:"bytes,7,opt,name=signature,proto3" json:"signature,omitempty"`
+}
+
+func (m *StateDiffResponse) Reset()                    { *m = StateDiffResponse{} }
+func (m *StateDiffResponse) String() string            { return proto.CompactTextString(m) }
+func (*StateDiffResponse) ProtoMessage()               {}
+func (*StateDiffResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{30} }
+
+func (m *StateDiffResponse) GetCheckpoint() uint64 {
+	if m != nil {
+		return m.Checkpoint
+	}
+	return 0
+}
+
+func (m *StateDiffResponse) GetHeight() uint64 {
+	if m != nil {
+		return m.Height
+	}
+	return 0
+}
+
+func (m *StateDiffResponse) GetEntries() []*StateDiffEntry {
+	if m != nil {
+		return m.Entries
+	}
+	return nil
+}
+
+func (m *StateDiffResponse) GetChecksum() []byte {
+	if m != nil {
+		return m.Checksum
+	}
+	return nil
+}
+
+func (m *StateDiffResponse) GetStart() uint64 {
+	if m != nil {
+		return m.Start
+	}
+	return 0
+}
+
+func (m *StateDiffResponse) GetTotal() uint64 {
+	if m != nil {
+		return m.Total
+	}
+	return 0
+}
+
+func (m *StateDiffResponse) GetSignature() []byte {
+	if m != nil {
+		return m.Signature
+	}
+	return nil
+}
+
+// StateDiffEntry is a single key-value pair of a StateDiffResponse.
+// The entries of private data carry their collection, and the
+// entries of hashed private data carry the key hash instead of the key
+type StateDiffEntry struct {
+	Namespace  string `protobuf:"bytes,1,opt,name=namespace" json:"namespace,omitempty"`
+	Key        string `protobuf:"bytes,2,opt,name=key" json:"key,omitempty"`
+	Value      []byte `protobuf:"bytes,3,opt,name=value,proto3" json:"value,omitempty"`
+	IsDelete   bool   `protobuf:"varint,4,opt,name=is_delete,json=isDelete" json:"is_delete,omitempty"`
+	BlockNum   uint64 `protobuf:"varint,5,opt,name=block_num,json=blockNum" json:"block_num,omitempty"`
+	TxNum      uint64 `protobuf:"varint,6,opt,name=tx_num,json=txNum" json:"tx_num,omitempty"`
+	Collection string `protobuf:"bytes,7,opt,name=collection" json:"collection,omitempty"`
+	KeyHash    []byte `protobuf:"bytes,8,opt,name=key_hash,json=keyHash,proto3" json:"key_hash,omitempty"`
+	Metadata   []byte `protobuf:"bytes,9,opt,name=metadata,proto3" json:"metadata,omitempty"`
+}
+
+func (m *StateDiffEntry) Reset()                    { *m = StateDiffEntry{} }
+func (m *StateDiffEntry) String() string            { return proto.CompactTextString(m) }
+func (*StateDiffEntry) ProtoMessage()               {}
+func (*StateDiffEntry) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{31} }
+
+func (m *StateDiffEntry) GetNamespace() string {
+	if m != nil {
+		return m.Namespace
+	}
+	return ""
+}
+
+func (m *StateDiffEntry) GetKey() string {
+	if m != nil {
+		return m.Key
+	}
+	return ""
+}
+
+func (m *StateDiffEntry) GetValue() []byte {
+	if m != nil {
+		return m.Value
+	}
+	return nil
+}
+
+func (m *StateDiffEntry) GetIsDelete() bool {
+	if m != nil {
+		return m.IsDelete
+	}
+	return false
+}
+
+func (m *StateDiffEntry) GetBlockNum() uint64 {
+	if m != nil {
+		return m.BlockNum
+	}
+	return 0
+}
+
+func (m *StateDiffEntry) GetTxNum() uint64 {
+	if m != nil {
+		return m.TxNum
+	}
+	return 0
+}
+
+func (m *StateDiffEntry) GetCollection() string {
+	if m != nil {
+		return m.Collection
+	}
+	return ""
+}
+
+func (m *StateDiffEntry) GetKeyHash() []byte {
+	if m != nil {
+		return m.KeyHash
+	}
+	return nil
+}
+
+func (m *StateDiffEntry) GetMetadata() []byte {
+	if m != nil {
+		return m.Metadata
+	}
+	return nil
+}
+
 // RemotePrivateDataRequest message used to request
 // missing private rwset
 type RemotePvtDataRequest struct {
@@ -1494,7 +1999,7 @@ type RemotePvtDataRequest struct {
 func (m *RemotePvtDataRequest) Reset()                    { *m = RemotePvtDataRequest{} }
 func (m *RemotePvtDataRequest) String() string            { return proto.CompactTextString(m) }
 func (*RemotePvtDataRequest) ProtoMessage()               {}
-func (*RemotePvtDataRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{25} }
+func (*RemotePvtDataRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{32} }
 
 func (m *RemotePvtDataRequest) GetDigest() []string {
 	if m != nil {
@@ -1512,7 +2017,7 @@ type RemotePvtDataResponse struct {
 func (m *RemotePvtDataResponse) Reset()                    { *m = RemotePvtDataResponse{} }
 func (m *RemotePvtDataResponse) String() string            { return proto.CompactTextString(m) }
 func (*RemotePvtDataResponse) ProtoMessage()               {}
-func (*RemotePvtDataResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{26} }
+func (*RemotePvtDataResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{33} }
 
 func (m *RemotePvtDataResponse) GetPayloads() []*PrivatePayload {
 	if m != nil {
@@ -1525,13 +2030,15 @@ func (m *RemotePvtDataResponse) GetPayloads() []*PrivatePayload {
 // inside the block
 type PvtDataPayload struct {
 	TxSeqInBlock uint64 `protobuf:"varint,1,opt,name=tx_seq_in_block,json=txSeqInBlock" json:"tx_seq_in_block,omitempty"`
-	Payload      []byte `protobuf:"bytes,2,opt,name=payload,proto3" json:"payload,omitempty"`
+	// Encodes marhslaed bytes of rwset.TxPvtReadWriteSet
+	// defined in rwset.proto
+	Payload []byte `protobuf:"bytes,2,opt,name=payload,proto3" json:"payload,omitempty"`
 }
 
 func (m *PvtDataPayload) Reset()                    { *m = PvtDataPayload{} }
 func (m *PvtDataPayload) String() string            { return proto.CompactTextString(m) }
 func (*PvtDataPayload) ProtoMessage()               {}
-func (*PvtDataPayload) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{27} }
+func (*PvtDataPayload) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{34} }
 
 func (m *PvtDataPayload) GetTxSeqInBlock() uint64 {
 	if m != nil {
@@ -1564,6 +2071,7 @@ func init() {
 	proto.RegisterType((*DataMessage)(nil), "gossip.DataMessage")
 	proto.RegisterType((*Payload)(nil), "gossip.Payload")
 	proto.RegisterType((*PrivatePayload)(nil), "gossip.PrivatePayload")
+	proto.RegisterType((*PrivateDataMessage)(nil), "gossip.PrivateDataMessage")
 	proto.RegisterType((*AliveMessage)(nil), "gossip.AliveMessage")
 	proto.RegisterType((*LeadershipMessage)(nil), "gossip.LeadershipMessage")
 	proto.RegisterType((*PeerTime)(nil), "gossip.PeerTime")
@@ -1573,6 +2081,12 @@ func init() {
 	proto.RegisterType((*Empty)(nil), "gossip.Empty")
 	proto.RegisterType((*RemoteStateRequest)(nil), "gossip.RemoteStateRequest")
 	proto.RegisterType((*RemoteStateResponse)(nil), "gossip.RemoteStateResponse")
+	proto.RegisterType((*StateBootstrapOffer)(nil), "gossip.StateBootstrapOffer")
+	proto.RegisterType((*StateBootstrapAccept)(nil), "gossip.StateBootstrapAccept")
+	proto.RegisterType((*Acknowledgement)(nil), "gossip.Acknowledgement")
+	proto.RegisterType((*StateDiffRequest)(nil), "gossip.StateDiffRequest")
+	proto.RegisterType((*StateDiffResponse)(nil), "gossip.StateDiffResponse")
+	proto.RegisterType((*StateDiffEntry)(nil), "gossip.StateDiffEntry")
 	proto.RegisterType((*RemotePvtDataRequest)(nil), "gossip.RemotePvtDataRequest")
 	proto.RegisterType((*RemotePvtDataResponse)(nil), "gossip.RemotePvtDataResponse")
 	proto.RegisterType((*PvtDataPayload)(nil), "gossip.PvtDataPayload")
@@ -1725,98 +2239,129 @@ var _Gossip_serviceDesc = grpc.ServiceDesc{
 func init() { proto.RegisterFile("gossip/message.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 1488 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0xb4, 0x17, 0xdd, 0x6f, 0xdb, 0x40,
-	0x3d, 0xdf, 0x89, 0x7f, 0xf9, 0x68, 0x7a, 0x6d, 0x87, 0x29, 0x13, 0x14, 0x8b, 0x8d, 0x42, 0x47,
-	0x3a, 0x75, 0x20, 0x26, 0x0d, 0x84, 0xd2, 0x26, 0x34, 0xd1, 0x96, 0xb4, 0xb8, 0x9d, 0xa0, 0x08,
-	0xc9, 0xba, 0xc6, 0x57, 0xc7, 0xd4, 0x3e, 0xbb, 0xbe, 0x4b, 0x59, 0x1f, 0x11, 0x6f, 0xbc, 0xf0,
-	0xca, 0x9f, 0x8b, 0xee, 0xce, 0x76, 0xec, 0xba, 0x9d, 0xb4, 0x49, 0xbc, 0xf9, 0xf7, 0xfd, 0xfd,
-	0xbb, 0x9f, 0x61, 0xdb, 0x09, 0x18, 0x73, 0xc3, 0x43, 0x9f, 0x30, 0x86, 0x1d, 0x32, 0x08, 0xa3,
-	0x80, 0x07, 0xa8, 0xa1, 0xb0, 0xc6, 0xbf, 0xca, 0xd0, 0x1a, 0xd3, 0x7b, 0xe2, 0x05, 0x21, 0x41,
-	0x3a, 0x34, 0x43, 0xfc, 0xe0, 0x05, 0xd8, 0xd6, 0xcb, 0x7b, 0xe5, 0xfd, 0x8e, 0x99, 0x80, 0xe8,
-	0x25, 0x68, 0xcc, 0x75, 0x28, 0xe6, 0xab, 0x88, 0xe8, 0x15, 0x49, 0x5b, 0x23, 0xd0, 0x1f, 0x60,
-	0x83, 0x91, 0x45, 0x44, 0xb8, 0x45, 0x62, 0x55, 0x7a, 0x75, 0xaf, 0xbc, 0xdf, 0x3e, 0x7a, 0x31,
-	0x50, 0x66, 0x06, 0x17, 0x92, 0x9c, 0x18, 0x32, 0x7b, 0x2c, 0x07, 0x1b, 0x13, 0xe8, 0xe5, 0x39,
-	0xbe, 0xd7, 0x15, 0x63, 0x08, 0x0d, 0xa5, 0x09, 0xbd, 0x81, 0xbe, 0x4b, 0x39, 0x89, 0x28, 0xf6,
-	0xc6, 0xd4, 0x0e, 0x03, 0x97, 0x72, 0xa9, 0x4a, 0x9b, 0x94, 0xcc, 0x02, 0xe5, 0x58, 0x83, 0xe6,
-	0x22, 0xa0, 0x9c, 0x50, 0x6e, 0xfc, 0x57, 0x83, 0xee, 0xa9, 0x74, 0x7b, 0xa6, 0x52, 0x86, 0xb6,
-	0xa1, 0x4e, 0x03, 0xba, 0x20, 0x52, 0xbe, 0x66, 0x2a, 0x40, 0xb8, 0xb8, 0x58, 0x62, 0x4a, 0x89,
-	0x17, 0xbb, 0x91, 0x80, 0xe8, 0x00, 0xaa, 0x1c, 0x3b, 0x32, 0x07, 0xbd, 0xa3, 0x1f, 0x26, 0x39,
-	0xc8, 0xe9, 0x1c, 0x5c, 0x62, 0xc7, 0x14, 0x5c, 0xe8, 0x1d, 0x68, 0xd8, 0x73, 0xef, 0x89, 0xe5,
-	0x33, 0x47, 0xaf, 0xcb, 0xb4, 0x6d, 0x27, 0x22, 0x43, 0x41, 0x88, 0x25, 0x26, 0x25, 0xb3, 0x25,
-	0x19, 0x67, 0xcc, 0x41, 0xbf, 0x86, 0xa6, 0x4f, 0x7c, 0x2b, 0x22, 0x77, 0x7a, 0x43, 0x8a, 0xa4,
-	0x56, 0x66, 0xc4, 0xbf, 0x26, 0x11, 0x5b, 0xba, 0xa1, 0x49, 0xee, 0x56, 0x84, 0xf1, 0x49, 0xc9,
-	0x6c, 0xf8, 0xc4, 0x37, 0xc9, 0x1d, 0xfa, 0x4d, 0x22, 0xc5, 0xf4, 0xa6, 0x94, 0xda, 0x7d, 0x4a,
-	0x8a, 0x85, 0x01, 0x65, 0x24, 0x15, 0x63, 0xe8, 0x2d, 0xb4, 0x6c, 0xcc, 0xb1, 0x74, 0xb0, 0x25,
-	0xe5, 0xb6, 0x12, 0xb9, 0x11, 0xe6, 0x78, 0xed, 0x5f, 0x53, 0xb0, 0x09, 0xf7, 0x0e, 0xa0, 0xbe,
-	0x24, 0x9e, 0x17, 0xe8, 0x5a, 0x9e, 0x5d, 0xa5, 0x60, 0x22, 0x48, 0x93, 0x92, 0xa9, 0x78, 0xd0,
-	0x61, 0xac, 0xde, 0x76, 0x1d, 0x1d, 0x24, 0x3f, 0xca, 0xaa, 0x1f, 0xb9, 0x8e, 0x8a, 0x42, 0x6a,
-	0x1f, 0xb9, 0x4e, 0xea, 0x8f, 0x88, 0xbe, 0x5d, 0xf4, 0x67, 0x1d, 0xb7, 0x94, 0x50, 0x81, 0xb7,
-	0xa5, 0xc4, 0x2a, 0xb4, 0x31, 0x27, 0x7a, 0xa7, 0x68, 0xe5, 0xb3, 0xa4, 0x4c, 0x4a, 0x26, 0xd8,
-	0x29, 0x84, 0x5e, 0x41, 0x9d, 0xf8, 0x21, 0x7f, 0xd0, 0xbb, 0x52, 0xa0, 0x9b, 0x08, 0x8c, 0x05,
-	0x52, 0x04, 0x20, 0xa9, 0xe8, 0x00, 0x6a, 0x8b, 0x80, 0x52, 0xbd, 0x27, 0xb9, 0x76, 0x12, 0xae,
-	0x93, 0x80, 0xd2, 0x31, 0xe3, 0xf8, 0xda, 0x73, 0xd9, 0x72, 0x52, 0x32, 0x25, 0x13, 0x3a, 0x02,
-	0x60, 0x1c, 0x73, 0x62, 0xb9, 0xf4, 0x26, 0xd0, 0x37, 0xa4, 0xc8, 0x66, 0x3a, 0x26, 0x82, 0x32,
-	0xa5, 0x37, 0x22, 0x3b, 0x1a, 0x4b, 0x00, 0x74, 0x0c, 0x3d, 0x25, 0xc3, 0x28, 0x0e, 0xd9, 0x32,
-	0xe0, 0x7a, 0x3f, 0x5f, 0xf4, 0x54, 0xee, 0x22, 0x66, 0x98, 0x94, 0xcc, 0xae, 0x14, 0x49, 0x10,
-	0x68, 0x06, 0x5b, 0x6b, 0xbb, 0x56, 0xb8, 0xf2, 0x3c, 0x99, 0xbf, 0x4d, 0xa9, 0xe8, 0x65, 0x41,
-	0xd1, 0xf9, 0xca, 0xf3, 0xd6, 0x89, 0xec, 0xb3, 0x47, 0x78, 0x34, 0x04, 0xa5, 0x5f, 0x28, 0x11,
-	0x4c, 0x3a, 0xca, 0x37, 0x94, 0x49, 0xfc, 0x80, 0x13, 0xa9, 0x6e, 0xad, 0xa6, 0xc3, 0x32, 0x30,
-	0x1a, 0x25, 0x51, 0x45, 0x71, 0xcb, 0xe9, 0x5b, 0x52, 0xc7, 0x8f, 0x9e, 0xd4, 0x91, 0x76, 0x65,
-	0x97, 0x65, 0x11, 0x22, 0x37, 0x1e, 0xc1, 0xb6, 0x6a, 0x5e, 0xd9, 0xa2, 0xdb, 0xf9, 0xdc, 0x7c,
-	0x4a, 0xa9, 0xeb, 0x46, 0xed, 0xae, 0x45, 0x44, 0xbb, 0x7e, 0x80, 0x6e, 0x48, 0x48, 0x64, 0xb9,
-	0x36, 0xa1, 0xdc, 0xe5, 0x0f, 0xfa, 0x4e, 0x7e, 0x0c, 0xcf, 0x09, 0x89, 0xa6, 0x31, 0x4d, 0x84,
-	0x11, 0x66, 0x60, 0xc3, 0x82, 0xea, 0x25, 0x76, 0x50, 0x17, 0xb4, 0xcf, 0xf3, 0xd1, 0xf8, 0x8f,
-	0xd3, 0xf9, 0x78, 0xd4, 0x2f, 0x21, 0x0d, 0xea, 0xe3, 0xd9, 0xf9, 0xe5, 0x55, 0xbf, 0x8c, 0x3a,
-	0xd0, 0x3a, 0x33, 0x4f, 0xad, 0xb3, 0xf9, 0xa7, 0xab, 0x7e, 0x45, 0xf0, 0x9d, 0x4c, 0x86, 0x73,
-	0x05, 0x56, 0x51, 0x1f, 0x3a, 0x12, 0x1c, 0xce, 0x47, 0xd6, 0x99, 0x79, 0xda, 0xaf, 0xa1, 0x0d,
-	0x68, 0x2b, 0x06, 0x53, 0x22, 0xea, 0xd9, 0xd5, 0xf4, 0x9f, 0x32, 0x68, 0x69, 0x89, 0xd0, 0x2e,
-	0xb4, 0x7c, 0xc2, 0xb1, 0x68, 0xd8, 0x78, 0x49, 0xa6, 0x30, 0x1a, 0x80, 0xc6, 0x5d, 0x9f, 0x30,
-	0x8e, 0xfd, 0x50, 0xae, 0xa7, 0xf6, 0x51, 0x3f, 0x1b, 0xce, 0xa5, 0xeb, 0x13, 0x73, 0xcd, 0x82,
-	0x76, 0xa0, 0x11, 0xde, 0xba, 0x96, 0x6b, 0xcb, 0xad, 0xd5, 0x31, 0xeb, 0xe1, 0xad, 0x3b, 0xb5,
-	0xd1, 0x4f, 0xa0, 0x1d, 0x2f, 0x35, 0x6b, 0x36, 0x3c, 0xd1, 0x6b, 0x92, 0x06, 0x31, 0x6a, 0x36,
-	0x3c, 0x31, 0x86, 0xb0, 0x59, 0x68, 0x3e, 0xf4, 0x06, 0x5a, 0xc4, 0x23, 0x3e, 0xa1, 0x9c, 0xe9,
-	0xe5, 0xbd, 0x6a, 0xd6, 0x76, 0xfa, 0x04, 0xa4, 0x1c, 0xc6, 0x6f, 0x61, 0xfb, 0xa9, 0xb6, 0x7b,
-	0x6c, 0xbb, 0x5c, 0xb0, 0x7d, 0x03, 0xdd, 0xdc, 0x8c, 0x65, 0x82, 0x28, 0x67, 0x83, 0xd8, 0x85,
-	0x56, 0x5a, 0x59, 0xb5, 0xa9, 0x53, 0x18, 0x19, 0xd0, 0xe5, 0x1e, 0xb3, 0x16, 0x24, 0xe2, 0xd6,
-	0x12, 0xb3, 0x65, 0x1c, 0x7e, 0x9b, 0x7b, 0xec, 0x84, 0x44, 0x7c, 0x82, 0xd9, 0xd2, 0xf8, 0x0c,
-	0x9d, 0x6c, 0x07, 0x3c, 0x67, 0x06, 0x41, 0x4d, 0xa8, 0x89, 0x4d, 0xc8, 0xef, 0x5c, 0x89, 0xaa,
-	0xf9, 0x12, 0x19, 0x3e, 0xb4, 0x33, 0xeb, 0xea, 0xf9, 0x47, 0xc6, 0x96, 0x0b, 0x90, 0xe9, 0x95,
-	0xbd, 0xea, 0xbe, 0x66, 0x26, 0x20, 0x1a, 0x40, 0xcb, 0x67, 0x8e, 0xc5, 0x1f, 0xe2, 0xd7, 0xb6,
-	0xb7, 0xde, 0x82, 0x22, 0x8b, 0x33, 0xe6, 0x5c, 0x3e, 0x84, 0xc4, 0x6c, 0xfa, 0xea, 0xc3, 0x08,
-	0xa0, 0x9d, 0x59, 0xbf, 0xcf, 0x98, 0xcb, 0xfa, 0x5b, 0x29, 0xb4, 0xd4, 0xb7, 0x19, 0xfc, 0x02,
-	0xb0, 0xde, 0xac, 0xcf, 0xd8, 0xfb, 0x19, 0xd4, 0x62, 0x5b, 0x4f, 0x77, 0x49, 0xed, 0xbb, 0x2c,
-	0x7b, 0xca, 0xb2, 0x7a, 0x39, 0xfe, 0xef, 0x89, 0x7d, 0xaf, 0xea, 0x98, 0x1c, 0x0b, 0xbf, 0xc8,
-	0x5f, 0x2e, 0xed, 0xa3, 0x8d, 0x54, 0x5a, 0xa1, 0xd3, 0x53, 0xc6, 0xb8, 0x82, 0x66, 0x8c, 0x43,
-	0x3f, 0x80, 0x26, 0x23, 0x77, 0x16, 0x5d, 0xf9, 0xb1, 0x9b, 0x0d, 0x46, 0xee, 0xe6, 0x2b, 0x5f,
-	0x74, 0x55, 0xa6, 0x1a, 0x2a, 0x1f, 0x3f, 0x85, 0x4e, 0x18, 0xb9, 0xf7, 0x62, 0x77, 0xc6, 0x9d,
-	0x55, 0x15, 0x3d, 0x1b, 0xe3, 0x84, 0x33, 0xc6, 0xdf, 0xa0, 0x77, 0xae, 0xc0, 0xc4, 0xc2, 0xcf,
-	0x61, 0x63, 0x11, 0x78, 0x1e, 0x59, 0x70, 0x37, 0xa0, 0x16, 0xc5, 0xbe, 0x4a, 0x88, 0x66, 0xf6,
-	0xd6, 0xe8, 0x39, 0xf6, 0x49, 0x41, 0x7b, 0xa5, 0xa8, 0xfd, 0xdf, 0x65, 0xe8, 0x64, 0x6f, 0x13,
-	0x34, 0x00, 0xf0, 0xd3, 0x13, 0x22, 0x8e, 0xbb, 0x97, 0x3f, 0x2e, 0xcc, 0x0c, 0xc7, 0x37, 0xaf,
-	0xa7, 0xec, 0x08, 0xd7, 0xf2, 0x23, 0x6c, 0xfc, 0xb3, 0x0c, 0x9b, 0x85, 0x25, 0xff, 0xdc, 0x90,
-	0x7e, 0xab, 0xe1, 0x57, 0xd0, 0x73, 0x99, 0x65, 0x93, 0x85, 0x87, 0x23, 0x2c, 0x52, 0x24, 0x5b,
-	0xa2, 0x65, 0x76, 0x5d, 0x36, 0x5a, 0x23, 0x8d, 0xdf, 0x41, 0x2b, 0x91, 0x16, 0xa5, 0x74, 0xe9,
-	0x22, 0x5b, 0x4a, 0x97, 0x2e, 0x44, 0x29, 0x33, 0x35, 0xae, 0x64, 0x6b, 0x6c, 0xdc, 0xc0, 0x66,
-	0xe1, 0x6c, 0x43, 0x1f, 0xa0, 0xcf, 0x88, 0x77, 0x23, 0xdf, 0xeb, 0xc8, 0x57, 0xb6, 0xcb, 0x79,
-	0x87, 0xd3, 0x31, 0xd9, 0x10, 0x9c, 0xd3, 0x35, 0xa3, 0xe8, 0xf9, 0x5b, 0x1a, 0xfc, 0x83, 0xc6,
-	0xc5, 0x53, 0x80, 0x71, 0x0d, 0xa8, 0x78, 0xe8, 0xa1, 0xd7, 0x50, 0x97, 0x77, 0xe5, 0xb3, 0xab,
-	0x5a, 0x91, 0xe5, 0xac, 0x12, 0x6c, 0x7f, 0x65, 0x56, 0x09, 0xb6, 0x8d, 0x3f, 0x43, 0x43, 0xd9,
-	0x10, 0x35, 0x23, 0xb9, 0xc3, 0xdb, 0x4c, 0xe1, 0xaf, 0xee, 0x99, 0xa7, 0x9f, 0x22, 0xa3, 0x09,
-	0x75, 0x79, 0x77, 0x19, 0x7f, 0x01, 0x54, 0xbc, 0x2e, 0xc4, 0x22, 0x67, 0x1c, 0x47, 0xdc, 0xca,
-	0x8f, 0x51, 0x5b, 0x22, 0x2f, 0xd4, 0x2c, 0xfd, 0x18, 0xda, 0x84, 0xda, 0x56, 0xbe, 0x08, 0x1a,
-	0xa1, 0xb6, 0xa2, 0x1b, 0xc7, 0xb0, 0xf5, 0xc4, 0xcd, 0x81, 0x0e, 0xa0, 0x15, 0x4f, 0x6c, 0xf2,
-	0x9c, 0x15, 0x46, 0x3a, 0x65, 0x30, 0x06, 0xb0, 0xad, 0x74, 0x9c, 0xdf, 0xf3, 0xec, 0x7a, 0x7f,
-	0x01, 0x0d, 0xb5, 0x60, 0xa4, 0x0a, 0xcd, 0x8c, 0x21, 0xe3, 0x23, 0xec, 0x3c, 0xe2, 0x8f, 0xad,
-	0x1e, 0x15, 0xac, 0xa6, 0x7f, 0x53, 0xf9, 0xc9, 0xce, 0x18, 0xff, 0x13, 0xf4, 0x62, 0x35, 0xc9,
-	0xd4, 0xbf, 0x82, 0x0d, 0xfe, 0x45, 0x46, 0xec, 0x52, 0xeb, 0xda, 0x0b, 0x16, 0xb7, 0x71, 0x62,
-	0x3a, 0xfc, 0xcb, 0x05, 0xb9, 0x9b, 0xd2, 0x63, 0x81, 0xcb, 0xfe, 0x6e, 0x55, 0x72, 0xbf, 0x5b,
-	0xbf, 0xfc, 0x3d, 0xb4, 0x33, 0x5b, 0xef, 0xf1, 0x99, 0xd3, 0x05, 0xed, 0xf8, 0xd3, 0xd9, 0xc9,
-	0x47, 0x6b, 0x76, 0x71, 0xda, 0x2f, 0x8b, 0x6b, 0x66, 0x3a, 0x1a, 0xcf, 0x2f, 0xa7, 0x97, 0x57,
-	0x12, 0x53, 0x39, 0xfa, 0x3b, 0x34, 0xd4, 0xab, 0x83, 0xde, 0x43, 0x47, 0x7d, 0x5d, 0xf0, 0x88,
-	0x60, 0x1f, 0x15, 0x1a, 0x68, 0xb7, 0x80, 0x31, 0x4a, 0xfb, 0xe5, 0xb7, 0x65, 0xf4, 0x1a, 0x6a,
-	0xe7, 0x2e, 0x75, 0x50, 0xfe, 0xfe, 0xde, 0xcd, 0x83, 0x46, 0xe9, 0xf8, 0x57, 0x7f, 0x3d, 0x70,
-	0x5c, 0xbe, 0x5c, 0x5d, 0x0f, 0x16, 0x81, 0x7f, 0xb8, 0x7c, 0x08, 0x49, 0xe4, 0x11, 0xdb, 0x21,
-	0xd1, 0xe1, 0x0d, 0xbe, 0x8e, 0xdc, 0xc5, 0xa1, 0xfc, 0xf5, 0x65, 0x87, 0x4a, 0xec, 0xba, 0x21,
-	0xc1, 0x77, 0xff, 0x0b, 0x00, 0x00, 0xff, 0xff, 0x8a, 0x9e, 0x0f, 0xde, 0x21, 0x0f, 0x00, 0x00,
+	// 1973 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xb4, 0x18, 0x59, 0x6f, 0x1b, 0xc7,
+	0x99, 0x2b, 0x9e, 0xfb, 0xf1, 0x10, 0x35, 0x3a, 0xbc, 0x96, 0x83, 0x54, 0xdd, 0xd6, 0x89, 0x5b,
+	0x39, 0x94, 0xa1, 0xb4, 0x68, 0x80, 0xb4, 0x08, 0x24, 0x51, 0x31, 0x05, 0x5b, 0x47, 0x46, 0x32,
+	0x5a, 0xf7, 0x65, 0x31, 0x5a, 0x0e, 0xc9, 0xad, 0xf6, 0xd2, 0xce, 0x50, 0x91, 0x02, 0xf4, 0xa1,
+	0xe8, 0x5b, 0x5f, 0xfa, 0xaf, 0xfa, 0x43, 0xda, 0xff, 0x51, 0x14, 0x33, 0xb3, 0x27, 0x97, 0x32,
+	0x60, 0x03, 0x7d, 0xdb, 0xef, 0x9e, 0xf9, 0xee, 0x1d, 0xd8, 0x98, 0x06, 0x8c, 0x39, 0xe1, 0x9e,
+	0x47, 0x19, 0x23, 0x53, 0x3a, 0x08, 0xa3, 0x80, 0x07, 0xa8, 0xa1, 0xb0, 0xe6, 0xdf, 0x35, 0x68,
+	0x1d, 0xfb, 0x77, 0xd4, 0x0d, 0x42, 0x8a, 0x0c, 0x68, 0x86, 0xe4, 0xc1, 0x0d, 0xc8, 0xd8, 0xd0,
+	0x76, 0xb4, 0x17, 0x1d, 0x9c, 0x80, 0xe8, 0x33, 0xd0, 0x99, 0x33, 0xf5, 0x09, 0x9f, 0x47, 0xd4,
+	0x58, 0x91, 0xb4, 0x0c, 0x81, 0xbe, 0x83, 0x55, 0x46, 0xed, 0x88, 0x72, 0x8b, 0xc6, 0xaa, 0x8c,
+	0xea, 0x8e, 0xf6, 0xa2, 0xbd, 0xbf, 0x35, 0x50, 0x66, 0x06, 0x97, 0x92, 0x9c, 0x18, 0xc2, 0x3d,
+	0x56, 0x80, 0xcd, 0x11, 0xf4, 0x8a, 0x1c, 0x9f, 0x7a, 0x14, 0xf3, 0x00, 0x1a, 0x4a, 0x13, 0x7a,
+	0x09, 0x7d, 0xc7, 0xe7, 0x34, 0xf2, 0x89, 0x7b, 0xec, 0x8f, 0xc3, 0xc0, 0xf1, 0xb9, 0x54, 0xa5,
+	0x8f, 0x2a, 0xb8, 0x44, 0x39, 0xd4, 0xa1, 0x69, 0x07, 0x3e, 0xa7, 0x3e, 0x37, 0xff, 0xd5, 0x81,
+	0xee, 0x6b, 0x79, 0xec, 0x53, 0xe5, 0x32, 0xb4, 0x01, 0x75, 0x3f, 0xf0, 0x6d, 0x2a, 0xe5, 0x6b,
+	0x58, 0x01, 0xe2, 0x88, 0xf6, 0x8c, 0xf8, 0x3e, 0x75, 0xe3, 0x63, 0x24, 0x20, 0xda, 0x85, 0x2a,
+	0x27, 0x53, 0xe9, 0x83, 0xde, 0xfe, 0xd3, 0xc4, 0x07, 0x05, 0x9d, 0x83, 0x2b, 0x32, 0xc5, 0x82,
+	0x0b, 0x7d, 0x0d, 0x3a, 0x71, 0x9d, 0x3b, 0x6a, 0x79, 0x6c, 0x6a, 0xd4, 0xa5, 0xdb, 0x36, 0x12,
+	0x91, 0x03, 0x41, 0x88, 0x25, 0x46, 0x15, 0xdc, 0x92, 0x8c, 0xa7, 0x6c, 0x8a, 0x7e, 0x03, 0x4d,
+	0x8f, 0x7a, 0x56, 0x44, 0x6f, 0x8d, 0x86, 0x14, 0x49, 0xad, 0x9c, 0x52, 0xef, 0x9a, 0x46, 0x6c,
+	0xe6, 0x84, 0x98, 0xde, 0xce, 0x29, 0xe3, 0xa3, 0x0a, 0x6e, 0x78, 0xd4, 0xc3, 0xf4, 0x16, 0xfd,
+	0x36, 0x91, 0x62, 0x46, 0x53, 0x4a, 0x6d, 0x2f, 0x93, 0x62, 0x61, 0xe0, 0x33, 0x9a, 0x8a, 0x31,
+	0xf4, 0x0a, 0x5a, 0x63, 0xc2, 0x89, 0x3c, 0x60, 0x4b, 0xca, 0xad, 0x27, 0x72, 0x43, 0xc2, 0x49,
+	0x76, 0xbe, 0xa6, 0x60, 0x13, 0xc7, 0xdb, 0x85, 0xfa, 0x8c, 0xba, 0x6e, 0x60, 0xe8, 0x45, 0x76,
+	0xe5, 0x82, 0x91, 0x20, 0x8d, 0x2a, 0x58, 0xf1, 0xa0, 0xbd, 0x58, 0xfd, 0xd8, 0x99, 0x1a, 0x20,
+	0xf9, 0x51, 0x5e, 0xfd, 0xd0, 0x99, 0xaa, 0x5b, 0x48, 0xed, 0x43, 0x67, 0x9a, 0x9e, 0x47, 0xdc,
+	0xbe, 0x5d, 0x3e, 0x4f, 0x76, 0x6f, 0x29, 0xa1, 0x2e, 0xde, 0x96, 0x12, 0xf3, 0x70, 0x4c, 0x38,
+	0x35, 0x3a, 0x65, 0x2b, 0xef, 0x24, 0x65, 0x54, 0xc1, 0x30, 0x4e, 0x21, 0xf4, 0x1c, 0xea, 0xd4,
+	0x0b, 0xf9, 0x83, 0xd1, 0x95, 0x02, 0xdd, 0x44, 0xe0, 0x58, 0x20, 0xc5, 0x05, 0x24, 0x15, 0xed,
+	0x42, 0xcd, 0x0e, 0x7c, 0xdf, 0xe8, 0x49, 0xae, 0xcd, 0x84, 0xeb, 0x28, 0xf0, 0xfd, 0x63, 0xc6,
+	0xc9, 0xb5, 0xeb, 0xb0, 0xd9, 0xa8, 0x82, 0x25, 0x13, 0xda, 0x07, 0x60, 0x9c, 0x70, 0x6a, 0x39,
+	0xfe, 0x24, 0x30, 0x56, 0xa5, 0xc8, 0x5a, 0x5a, 0x26, 0x82, 0x72, 0xe2, 0x4f, 0x84, 0x77, 0x74,
+	0x96, 0x00, 0xe8, 0x10, 0x7a, 0x4a, 0x86, 0xf9, 0x24, 0x64, 0xb3, 0x80, 0x1b, 0xfd, 0x62, 0xd0,
+	0x53, 0xb9, 0xcb, 0x98, 0x61, 0x54, 0xc1, 0x5d, 0x29, 0x92, 0x20, 0xd0, 0x29, 0xac, 0x67, 0x76,
+	0xad, 0x70, 0xee, 0xba, 0xd2, 0x7f, 0x6b, 0x52, 0xd1, 0x67, 0x25, 0x45, 0x17, 0x73, 0xd7, 0xcd,
+	0x1c, 0xd9, 0x67, 0x0b, 0x78, 0x74, 0x00, 0x4a, 0xbf, 0x15, 0x29, 0x26, 0x03, 0x15, 0x13, 0x0a,
+	0x53, 0x2f, 0xe0, 0x54, 0xaa, 0xcb, 0xd4, 0x74, 0x58, 0x0e, 0x46, 0xc3, 0xe4, 0x56, 0x51, 0x9c,
+	0x72, 0xc6, 0xba, 0xd4, 0xf1, 0x6c, 0xa9, 0x8e, 0x34, 0x2b, 0xbb, 0x2c, 0x8f, 0x10, 0xbe, 0x71,
+	0x29, 0x19, 0xab, 0xe4, 0x95, 0x29, 0xba, 0x51, 0xf4, 0xcd, 0xdb, 0x94, 0x9a, 0x25, 0x6a, 0x37,
+	0x13, 0x11, 0xe9, 0xfa, 0x2d, 0x74, 0x43, 0x4a, 0x23, 0xcb, 0x19, 0x53, 0x9f, 0x3b, 0xfc, 0xc1,
+	0xd8, 0x2c, 0x96, 0xe1, 0x05, 0xa5, 0xd1, 0x49, 0x4c, 0x13, 0xd7, 0x08, 0x73, 0x30, 0x1a, 0x01,
+	0x52, 0xd7, 0x18, 0x3b, 0x93, 0x49, 0xea, 0x8e, 0x2d, 0xa9, 0xc1, 0x28, 0xf8, 0x75, 0xe8, 0x4c,
+	0x26, 0x8b, 0x3e, 0xcd, 0xe1, 0xd0, 0x1b, 0x58, 0x2f, 0x68, 0x8a, 0xbd, 0xf2, 0x64, 0x49, 0xac,
+	0x95, 0x58, 0xea, 0x93, 0x35, 0xb6, 0x88, 0x44, 0xdf, 0x41, 0x27, 0x8c, 0x9c, 0x3b, 0xa9, 0x8e,
+	0x70, 0x62, 0x18, 0xc5, 0xf8, 0x5c, 0x28, 0x5a, 0xb1, 0x7e, 0xdb, 0x61, 0x86, 0x45, 0x3f, 0xc0,
+	0xa6, 0x3a, 0xcd, 0x75, 0x10, 0x70, 0xc6, 0x23, 0x12, 0x5a, 0xc1, 0x64, 0x42, 0x23, 0xe3, 0x69,
+	0x31, 0x4a, 0xf2, 0x3c, 0x87, 0x09, 0xcf, 0xb9, 0x60, 0x19, 0x55, 0xf0, 0x3a, 0x2b, 0xa3, 0xd1,
+	0x15, 0x6c, 0x2d, 0xaa, 0x24, 0xb6, 0x4d, 0x43, 0x6e, 0x6c, 0x2f, 0x49, 0xc3, 0x54, 0xf8, 0x40,
+	0xf2, 0x8c, 0x2a, 0x78, 0x83, 0x2d, 0xc1, 0x8b, 0x6e, 0x4b, 0xec, 0x1b, 0xe3, 0x99, 0x54, 0xf1,
+	0x24, 0x6d, 0x9d, 0xf6, 0x8d, 0x1f, 0xfc, 0xe8, 0xd2, 0xf1, 0x94, 0x7a, 0xd4, 0x17, 0xd2, 0x82,
+	0xcb, 0xb4, 0xa0, 0x7a, 0x45, 0xa6, 0xa8, 0x0b, 0xfa, 0xbb, 0xb3, 0xe1, 0xf1, 0xf7, 0x27, 0x67,
+	0xc7, 0xc3, 0x7e, 0x05, 0xe9, 0x50, 0x3f, 0x3e, 0xbd, 0xb8, 0x7a, 0xdf, 0xd7, 0x50, 0x07, 0x5a,
+	0xe7, 0xf8, 0xb5, 0x75, 0x7e, 0xf6, 0xf6, 0x7d, 0x7f, 0x45, 0xf0, 0x1d, 0x8d, 0x0e, 0xce, 0x14,
+	0x58, 0x45, 0x7d, 0xe8, 0x48, 0xf0, 0xe0, 0x6c, 0x68, 0x9d, 0xe3, 0xd7, 0xfd, 0x1a, 0x5a, 0x85,
+	0xb6, 0x62, 0xc0, 0x12, 0x51, 0xcf, 0x0f, 0x92, 0x7f, 0x6a, 0xa0, 0xa7, 0x05, 0x85, 0xb6, 0xa1,
+	0xe5, 0x51, 0x4e, 0x64, 0x30, 0xd4, 0x48, 0x4b, 0x61, 0x34, 0x00, 0x9d, 0x3b, 0x1e, 0x65, 0x9c,
+	0x78, 0xa1, 0x1c, 0x26, 0xed, 0xfd, 0x7e, 0x3e, 0xf9, 0xae, 0x1c, 0x8f, 0xe2, 0x8c, 0x05, 0x6d,
+	0x42, 0x23, 0xbc, 0x71, 0x2c, 0x67, 0x2c, 0x67, 0x4c, 0x07, 0xd7, 0xc3, 0x1b, 0xe7, 0x64, 0x8c,
+	0x7e, 0x06, 0xed, 0x78, 0x04, 0x59, 0xa7, 0x07, 0x47, 0x46, 0x4d, 0xd2, 0x20, 0x46, 0x9d, 0x1e,
+	0x1c, 0x99, 0x07, 0xb0, 0x56, 0x6a, 0x15, 0xe8, 0x25, 0xb4, 0xa8, 0x2b, 0x9d, 0xc4, 0x0c, 0x6d,
+	0xa7, 0x9a, 0xb7, 0x9d, 0x0e, 0xec, 0x94, 0xc3, 0xfc, 0x1d, 0x6c, 0x2c, 0x6b, 0x12, 0x8b, 0xb6,
+	0xb5, 0x92, 0xed, 0x09, 0x74, 0x0b, 0x1d, 0x31, 0x77, 0x09, 0x2d, 0x7f, 0x89, 0x6d, 0x68, 0xa5,
+	0x75, 0xa8, 0xe6, 0x6a, 0x0a, 0x23, 0x13, 0xba, 0xdc, 0x65, 0x96, 0x4d, 0x23, 0x6e, 0xcd, 0x08,
+	0x9b, 0xc5, 0xd7, 0x6f, 0x73, 0x97, 0x1d, 0xd1, 0x88, 0x8f, 0x08, 0x9b, 0x99, 0xef, 0xa0, 0x93,
+	0xaf, 0xd7, 0xc7, 0xcc, 0x20, 0xa8, 0x09, 0x35, 0xb1, 0x09, 0xf9, 0x5d, 0x08, 0x51, 0xb5, 0x18,
+	0x22, 0xd3, 0x83, 0x76, 0x6e, 0xb8, 0x3c, 0xbe, 0x12, 0x8c, 0xe5, 0xb8, 0x62, 0xc6, 0xca, 0x4e,
+	0xf5, 0x85, 0x8e, 0x13, 0x10, 0x0d, 0xa0, 0xe5, 0xb1, 0xa9, 0xc5, 0x1f, 0xe2, 0xdd, 0xa8, 0x97,
+	0xcd, 0x2c, 0xe1, 0xc5, 0x53, 0x36, 0xbd, 0x7a, 0x08, 0x29, 0x6e, 0x7a, 0xea, 0xc3, 0x0c, 0xa0,
+	0x9d, 0x1b, 0x96, 0x8f, 0x98, 0xcb, 0x9f, 0x77, 0xa5, 0x94, 0x52, 0x1f, 0x67, 0xf0, 0x1e, 0x20,
+	0x9b, 0x83, 0x8f, 0xd8, 0xfb, 0x25, 0xd4, 0x62, 0x5b, 0xcb, 0xb3, 0xa4, 0xf6, 0x49, 0x96, 0x5d,
+	0x80, 0x6c, 0xce, 0xff, 0xdf, 0x1d, 0xfb, 0x0d, 0xb4, 0x73, 0x4d, 0x0f, 0xfd, 0xaa, 0xb8, 0x67,
+	0xb6, 0xf7, 0x57, 0x53, 0x69, 0x85, 0x4e, 0x17, 0x4f, 0xf3, 0x3d, 0x34, 0x63, 0x1c, 0x7a, 0x02,
+	0x4d, 0x46, 0x6f, 0x2d, 0x7f, 0xee, 0xc5, 0xc7, 0x6c, 0x30, 0x7a, 0x7b, 0x36, 0xf7, 0x44, 0x56,
+	0xe5, 0xa2, 0x21, 0xbf, 0xd1, 0xcf, 0x17, 0x3a, 0x71, 0x75, 0xa7, 0x2a, 0x72, 0x36, 0xd7, 0x6b,
+	0xcd, 0x7f, 0x6b, 0xd0, 0x8b, 0x3b, 0x72, 0x62, 0xe2, 0x4b, 0x58, 0xb5, 0x03, 0xd7, 0xa5, 0x36,
+	0x77, 0x02, 0xdf, 0xf2, 0x89, 0xa7, 0x3c, 0xa2, 0xe3, 0x5e, 0x86, 0x3e, 0x23, 0x1e, 0x2d, 0xa9,
+	0x5f, 0x29, 0xa9, 0x17, 0x2b, 0xb3, 0x50, 0xc0, 0x42, 0x62, 0x2b, 0x27, 0xe9, 0x38, 0x43, 0xa0,
+	0x75, 0xa8, 0xf3, 0x7b, 0x51, 0x1f, 0x35, 0x49, 0xa9, 0xf1, 0xfb, 0x93, 0x31, 0xfa, 0x05, 0x74,
+	0x13, 0xad, 0xd1, 0x8f, 0x8c, 0x72, 0xb9, 0x99, 0x76, 0x70, 0x62, 0x0a, 0x0b, 0x1c, 0x7a, 0x09,
+	0x28, 0x61, 0x62, 0x8e, 0x67, 0xcd, 0xa8, 0x33, 0x9d, 0x71, 0xb9, 0x90, 0xd6, 0x70, 0x3f, 0xa6,
+	0x5c, 0x3a, 0xde, 0x48, 0xe2, 0xcd, 0xef, 0x01, 0x95, 0xa7, 0x0e, 0x7a, 0xb5, 0x18, 0x80, 0xad,
+	0x85, 0x11, 0x55, 0x8a, 0xc3, 0x3f, 0x34, 0xe8, 0xe4, 0x17, 0x63, 0x34, 0x00, 0xf0, 0xd2, 0xfd,
+	0x35, 0xd6, 0xd2, 0x2b, 0x6e, 0xb6, 0x38, 0xc7, 0xf1, 0xd1, 0xdd, 0x36, 0xdf, 0x91, 0x6a, 0xc5,
+	0x8e, 0x64, 0xfe, 0x4d, 0x83, 0xb5, 0xd2, 0x86, 0xf1, 0x58, 0xcf, 0xf9, 0x58, 0xc3, 0xcf, 0xa1,
+	0xe7, 0x30, 0x6b, 0x4c, 0x6d, 0x97, 0x44, 0x44, 0x04, 0x5c, 0x06, 0xaf, 0x85, 0xbb, 0x0e, 0x1b,
+	0x66, 0x48, 0xf3, 0xf7, 0xd0, 0x4a, 0xa4, 0x45, 0x66, 0x3a, 0xbe, 0x9d, 0xcf, 0x4c, 0xc7, 0xb7,
+	0x45, 0x66, 0xe6, 0x52, 0x76, 0x25, 0x9f, 0xb2, 0xe6, 0x04, 0xd6, 0x4a, 0xff, 0x0c, 0xe8, 0x5b,
+	0xe8, 0x33, 0xea, 0x4e, 0xe4, 0xb2, 0x18, 0x79, 0xca, 0xb6, 0xb6, 0xa3, 0x2d, 0xad, 0xfa, 0x55,
+	0xc1, 0x79, 0x92, 0x31, 0x8a, 0x12, 0x16, 0xb3, 0xd7, 0x8f, 0x53, 0x51, 0x01, 0xe6, 0x35, 0xa0,
+	0xf2, 0x5f, 0x06, 0xfa, 0x02, 0xea, 0xf2, 0xa7, 0xe6, 0xd1, 0xc9, 0xa3, 0xc8, 0xb2, 0xf5, 0x50,
+	0x32, 0xfe, 0x40, 0xeb, 0xa1, 0x64, 0x6c, 0xfe, 0x11, 0x1a, 0xca, 0x86, 0x88, 0x19, 0x2d, 0xfc,
+	0xf5, 0xe1, 0x14, 0xfe, 0x60, 0xdb, 0x5c, 0x3e, 0x59, 0xcd, 0x26, 0xd4, 0xe5, 0xd2, 0x6f, 0xfe,
+	0x09, 0x50, 0x79, 0xb5, 0x15, 0x73, 0x89, 0x71, 0x12, 0x71, 0xab, 0xd8, 0x15, 0xda, 0x12, 0x79,
+	0xa9, 0x5a, 0xc3, 0xe7, 0xd0, 0xa6, 0xfe, 0xd8, 0x2a, 0x06, 0x41, 0xa7, 0xfe, 0x58, 0xd1, 0xcd,
+	0x43, 0x58, 0x5f, 0xb2, 0xf0, 0xa2, 0x5d, 0x68, 0xc5, 0x89, 0x9f, 0x4c, 0xe7, 0x52, 0x87, 0x4a,
+	0x19, 0xcc, 0xaf, 0x60, 0x7d, 0xc9, 0x3a, 0x86, 0xb6, 0xa0, 0x11, 0xd7, 0x66, 0x9c, 0x13, 0x0a,
+	0x32, 0x07, 0xb0, 0x51, 0x64, 0x8f, 0x37, 0xaa, 0xc7, 0xf8, 0xbf, 0x84, 0xd5, 0x85, 0xb5, 0x4a,
+	0xc4, 0x9a, 0x46, 0x51, 0x10, 0xc5, 0x4e, 0x56, 0x80, 0xf9, 0x57, 0xe8, 0x2f, 0x6e, 0xbc, 0xe8,
+	0x73, 0x00, 0x7b, 0x46, 0xed, 0x9b, 0x2c, 0x26, 0x35, 0x9c, 0xc3, 0xe4, 0x8c, 0xae, 0xe4, 0x8d,
+	0x0a, 0x0b, 0xd2, 0x8d, 0x32, 0x20, 0x35, 0xac, 0x00, 0xf4, 0x0c, 0xf4, 0x90, 0x4c, 0x45, 0xdf,
+	0xf9, 0x89, 0xca, 0xa2, 0xec, 0x0a, 0x37, 0x4c, 0xe9, 0xa5, 0xf3, 0x13, 0x35, 0xff, 0xa3, 0xc1,
+	0x5a, 0xce, 0x7e, 0xec, 0xc9, 0x4f, 0x3d, 0xc0, 0x2b, 0x68, 0x52, 0x9f, 0x47, 0x0e, 0x65, 0xb2,
+	0x75, 0xe7, 0x5f, 0x35, 0x12, 0x1b, 0xc7, 0x3e, 0x8f, 0x1e, 0x70, 0xc2, 0x26, 0x12, 0x4c, 0xea,
+	0x65, 0x73, 0x2f, 0x69, 0x18, 0x09, 0x9c, 0x5d, 0xa7, 0x9e, 0xbf, 0xce, 0x06, 0xd4, 0x79, 0xc0,
+	0x89, 0x1b, 0x37, 0x4f, 0x05, 0x14, 0x9f, 0x3a, 0x9a, 0x8b, 0x4f, 0x1d, 0xff, 0xd5, 0xa0, 0x57,
+	0x3c, 0x41, 0xb1, 0xd1, 0x6b, 0x8b, 0x8d, 0xbe, 0x0f, 0xd5, 0x1b, 0xaa, 0x96, 0x2a, 0x1d, 0x8b,
+	0x4f, 0x61, 0xf6, 0x8e, 0xb8, 0x73, 0x9a, 0x24, 0xbb, 0x04, 0x84, 0x6f, 0x65, 0xdb, 0x71, 0x29,
+	0x57, 0xbe, 0x6d, 0xe1, 0x96, 0xe8, 0x38, 0x02, 0x16, 0xc4, 0x6b, 0x37, 0xb0, 0x6f, 0x64, 0x12,
+	0xab, 0x3b, 0xb4, 0x24, 0x42, 0xe4, 0xf8, 0x26, 0x34, 0xf8, 0xbd, 0xa4, 0x24, 0xf7, 0xb8, 0x57,
+	0xa9, 0x0f, 0xd9, 0xd0, 0x92, 0x17, 0xd1, 0x71, 0x0e, 0x83, 0x9e, 0x42, 0xeb, 0x86, 0x3e, 0xa8,
+	0x8d, 0xae, 0xa5, 0x9e, 0x52, 0x6e, 0xe8, 0x83, 0xd8, 0xe6, 0x0a, 0xb5, 0xaa, 0x2f, 0xac, 0x64,
+	0x03, 0xd8, 0x50, 0x15, 0x73, 0x71, 0xc7, 0xf3, 0xbb, 0xd9, 0x16, 0x34, 0xd4, 0x76, 0x20, 0x0b,
+	0x46, 0xc7, 0x31, 0x64, 0xbe, 0x81, 0xcd, 0x05, 0xfe, 0x38, 0x33, 0xf6, 0x4b, 0x35, 0xf6, 0xd8,
+	0x10, 0xca, 0x4a, 0xed, 0x07, 0xe8, 0xc5, 0x6a, 0x62, 0x1a, 0x7a, 0x0e, 0xab, 0xfc, 0x5e, 0xd6,
+	0xb7, 0xe3, 0x5b, 0xd2, 0x25, 0x71, 0x92, 0x75, 0xf8, 0xfd, 0x25, 0xbd, 0x3d, 0xf1, 0x0f, 0x05,
+	0x2e, 0xff, 0xb2, 0xb5, 0x52, 0x78, 0xd9, 0xfa, 0xf5, 0x1f, 0xa0, 0x9d, 0x5b, 0x59, 0x16, 0xff,
+	0x51, 0xba, 0xa0, 0x1f, 0xbe, 0x3d, 0x3f, 0x7a, 0x63, 0x9d, 0x5e, 0xbe, 0xee, 0x6b, 0xe2, 0x57,
+	0xe4, 0x64, 0x78, 0x7c, 0x76, 0x75, 0x72, 0xf5, 0x5e, 0x62, 0x56, 0xf6, 0xff, 0x02, 0x0d, 0xb5,
+	0x32, 0xa2, 0x6f, 0xa0, 0xa3, 0xbe, 0x2e, 0x79, 0x44, 0x89, 0x87, 0x4a, 0xed, 0x72, 0xbb, 0x84,
+	0x31, 0x2b, 0x2f, 0xb4, 0x57, 0x1a, 0xfa, 0x02, 0x6a, 0x17, 0x8e, 0x3f, 0x45, 0xc5, 0xa7, 0x8e,
+	0xed, 0x22, 0x68, 0x56, 0x0e, 0xbf, 0xfa, 0xf3, 0xee, 0xd4, 0xe1, 0xb3, 0xf9, 0xf5, 0xc0, 0x0e,
+	0xbc, 0xbd, 0xd9, 0x43, 0x48, 0x23, 0xd9, 0x13, 0xa2, 0xbd, 0x09, 0xb9, 0x8e, 0x1c, 0x7b, 0x4f,
+	0xbe, 0x32, 0xb2, 0x3d, 0x25, 0x76, 0xdd, 0x90, 0xe0, 0xd7, 0xff, 0x1b, 0x00, 0x52, 0xa7, 0xcb,
+	0x0e, 0x8c, 0x14, 0x00, 0x00,
 }
@@ -54,6 +54,12 @@ func TestMethods(t *testing.T) {
 		&RemoteStateResponse{},
 		&LeadershipMessage{},
 		&PeerIdentity{},
+		&StateDiffRequest{},
+		&StateDiffResponse{},
+		&StateDiffEntry{},
+		&StateBootstrapOffer{},
+		&StateBootstrapAccept{},
+		&Acknowledgement{},
 	}
 
 	for _, msg := range msgs {
@@ -82,6 +88,11 @@ func TestMethods(t *testing.T) {
 		&GossipMessage_StateResponse{},
 		&GossipMessage_LeadershipMsg{},
 		&GossipMessage_PeerIdentity{},
+		&GossipMessage_StateDiffRequest{},
+		&GossipMessage_StateDiffResponse{},
+		&GossipMessage_StateBootstrapOffer{},
+		&GossipMessage_StateBootstrapAccept{},
+		&GossipMessage_Ack{},
 	}
 
 	for _, ct := range contentTypes {
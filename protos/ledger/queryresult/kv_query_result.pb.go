@@ -10,6 +10,7 @@ It is generated from these files:
 It has these top-level messages:
 	KV
 	KeyModification
+	KeyRangeModification
 */
 package queryresult
 
@@ -104,31 +105,85 @@ func (m *KeyModification) GetIsDelete() bool {
 	return false
 }
 
+// KeyRangeModification -- QueryResult for history query over a range of keys. Holds
+// the key modified along with the transaction ID, value, timestamp, and delete marker.
+type KeyRangeModification struct {
+	Key       string                     `protobuf:"bytes,1,opt,name=key" json:"key,omitempty"`
+	TxId      string                     `protobuf:"bytes,2,opt,name=tx_id,json=txId" json:"tx_id,omitempty"`
+	Value     []byte                     `protobuf:"bytes,3,opt,name=value,proto3" json:"value,omitempty"`
+	Timestamp *google_protobuf.Timestamp `protobuf:"bytes,4,opt,name=timestamp" json:"timestamp,omitempty"`
+	IsDelete  bool                       `protobuf:"varint,5,opt,name=is_delete,json=isDelete" json:"is_delete,omitempty"`
+}
+
+func (m *KeyRangeModification) Reset()                    { *m = KeyRangeModification{} }
+func (m *KeyRangeModification) String() string            { return proto.CompactTextString(m) }
+func (*KeyRangeModification) ProtoMessage()               {}
+func (*KeyRangeModification) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{2} }
+
+func (m *KeyRangeModification) GetKey() string {
+	if m != nil {
+		return m.Key
+	}
+	return ""
+}
+
+func (m *KeyRangeModification) GetTxId() string {
+	if m != nil {
+		return m.TxId
+	}
+	return ""
+}
+
+func (m *KeyRangeModification) GetValue() []byte {
+	if m != nil {
+		return m.Value
+	}
+	return nil
+}
+
+func (m *KeyRangeModification) GetTimestamp() *google_protobuf.Timestamp {
+	if m != nil {
+		return m.Timestamp
+	}
+	return nil
+}
+
+func (m *KeyRangeModification) GetIsDelete() bool {
+	if m != nil {
+		return m.IsDelete
+	}
+	return false
+}
+
 func init() {
 	proto.RegisterType((*KV)(nil), "queryresult.KV")
 	proto.RegisterType((*KeyModification)(nil), "queryresult.KeyModification")
+	proto.RegisterType((*KeyRangeModification)(nil), "queryresult.KeyRangeModification")
 }
 
 func init() { proto.RegisterFile("ledger/queryresult/kv_query_result.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 286 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x64, 0x51, 0x4f, 0x4b, 0xc3, 0x30,
-	0x1c, 0xa5, 0xdd, 0x26, 0x6b, 0x26, 0x28, 0xd1, 0x43, 0x99, 0x82, 0x65, 0xa7, 0x9e, 0x12, 0xd1,
-	0x83, 0x9e, 0xc5, 0x8b, 0x0e, 0x2f, 0x45, 0x3c, 0x78, 0x29, 0x69, 0xfb, 0x6b, 0x17, 0xda, 0x2e,
-	0x35, 0x7f, 0xc6, 0xfa, 0x39, 0xfc, 0xc2, 0x62, 0xb2, 0xd9, 0x82, 0xb7, 0xbc, 0xf7, 0x7b, 0xef,
-	0xf1, 0x78, 0x41, 0x71, 0x03, 0x45, 0x05, 0x92, 0x7e, 0x19, 0x90, 0xbd, 0x04, 0x65, 0x1a, 0x4d,
-	0xeb, 0x5d, 0x6a, 0x61, 0xea, 0x30, 0xe9, 0xa4, 0xd0, 0x02, 0x2f, 0x46, 0x92, 0xe5, 0x4d, 0x25,
-	0x44, 0xd5, 0x00, 0xb5, 0xa7, 0xcc, 0x94, 0x54, 0xf3, 0x16, 0x94, 0x66, 0x6d, 0xe7, 0xd4, 0xab,
-	0x57, 0xe4, 0xaf, 0x3f, 0xf0, 0x35, 0x0a, 0xb6, 0xac, 0x05, 0xd5, 0xb1, 0x1c, 0x42, 0x2f, 0xf2,
-	0xe2, 0x20, 0x19, 0x08, 0x7c, 0x8e, 0x26, 0x35, 0xf4, 0xa1, 0x6f, 0xf9, 0xdf, 0x27, 0xbe, 0x44,
-	0xb3, 0x1d, 0x6b, 0x0c, 0x84, 0x93, 0xc8, 0x8b, 0x4f, 0x13, 0x07, 0x56, 0xdf, 0x1e, 0x3a, 0x5b,
-	0x43, 0xff, 0x26, 0x0a, 0x5e, 0xf2, 0x9c, 0x69, 0x2e, 0xb6, 0xf8, 0x02, 0xcd, 0xf4, 0x3e, 0xe5,
-	0xc5, 0x21, 0x75, 0xaa, 0xf7, 0x2f, 0xc5, 0x60, 0xf7, 0x47, 0x76, 0xfc, 0x88, 0x82, 0xbf, 0x76,
-	0x36, 0x78, 0x71, 0xb7, 0x24, 0xae, 0x3f, 0x39, 0xf6, 0x27, 0xef, 0x47, 0x45, 0x32, 0x88, 0xf1,
-	0x15, 0x0a, 0xb8, 0x4a, 0x0b, 0x68, 0x40, 0x43, 0x38, 0x8d, 0xbc, 0x78, 0x9e, 0xcc, 0xb9, 0x7a,
-	0xb6, 0xf8, 0xa9, 0x46, 0xb7, 0x42, 0x56, 0x64, 0xd3, 0x77, 0x20, 0xdd, 0x88, 0xa4, 0x64, 0x99,
-	0xe4, 0xb9, 0x0b, 0x55, 0xe4, 0x40, 0x8e, 0x66, 0xfb, 0x7c, 0xa8, 0xb8, 0xde, 0x98, 0x8c, 0xe4,
-	0xa2, 0xa5, 0x23, 0x23, 0x75, 0x46, 0xb7, 0xa6, 0xa2, 0xff, 0xbf, 0x24, 0x3b, 0xb1, 0xa7, 0xfb,
-	0x9f, 0x00, 0x00, 0x00, 0xff, 0xff, 0xa2, 0xb7, 0x3e, 0x86, 0xaf, 0x01, 0x00, 0x00,
+	// 321 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x92, 0xb1, 0x4e, 0xc3, 0x30,
+	0x10, 0x86, 0xe5, 0xb4, 0x45, 0x8d, 0x8b, 0x04, 0x32, 0x1d, 0xa2, 0x82, 0x44, 0xd5, 0x29, 0x93,
+	0x8d, 0x60, 0x80, 0x19, 0xb1, 0x40, 0xc5, 0x12, 0x21, 0x06, 0x96, 0xc8, 0x49, 0xae, 0xa9, 0xd5,
+	0xa4, 0x0e, 0xb6, 0x53, 0x35, 0xcf, 0xc1, 0x5b, 0xf0, 0x94, 0x88, 0x38, 0x6d, 0x52, 0x01, 0x0b,
+	0x9b, 0xff, 0xbb, 0xff, 0x3f, 0x7d, 0xa7, 0x33, 0xf6, 0x33, 0x48, 0x52, 0x50, 0xec, 0xbd, 0x04,
+	0x55, 0x29, 0xd0, 0x65, 0x66, 0xd8, 0x6a, 0x13, 0xd6, 0x32, 0xb4, 0x9a, 0x16, 0x4a, 0x1a, 0x49,
+	0x46, 0x1d, 0xcb, 0xe4, 0x32, 0x95, 0x32, 0xcd, 0x80, 0xd5, 0xad, 0xa8, 0x5c, 0x30, 0x23, 0x72,
+	0xd0, 0x86, 0xe7, 0x85, 0x75, 0xcf, 0x9e, 0xb0, 0x33, 0x7f, 0x25, 0x17, 0xd8, 0x5d, 0xf3, 0x1c,
+	0x74, 0xc1, 0x63, 0xf0, 0xd0, 0x14, 0xf9, 0x6e, 0xd0, 0x16, 0xc8, 0x29, 0xee, 0xad, 0xa0, 0xf2,
+	0x9c, 0xba, 0xfe, 0xfd, 0x24, 0x63, 0x3c, 0xd8, 0xf0, 0xac, 0x04, 0xaf, 0x37, 0x45, 0xfe, 0x71,
+	0x60, 0xc5, 0xec, 0x03, 0xe1, 0x93, 0x39, 0x54, 0xcf, 0x32, 0x11, 0x0b, 0x11, 0x73, 0x23, 0xe4,
+	0x9a, 0x9c, 0xe1, 0x81, 0xd9, 0x86, 0x22, 0x69, 0xa6, 0xf6, 0xcd, 0xf6, 0x31, 0x69, 0xe3, 0x4e,
+	0x27, 0x4e, 0xee, 0xb0, 0xbb, 0xa7, 0xab, 0x07, 0x8f, 0xae, 0x27, 0xd4, 0xf2, 0xd3, 0x1d, 0x3f,
+	0x7d, 0xd9, 0x39, 0x82, 0xd6, 0x4c, 0xce, 0xb1, 0x2b, 0x74, 0x98, 0x40, 0x06, 0x06, 0xbc, 0xfe,
+	0x14, 0xf9, 0xc3, 0x60, 0x28, 0xf4, 0x43, 0xad, 0x67, 0x9f, 0x08, 0x8f, 0xe7, 0x50, 0x05, 0x7c,
+	0x9d, 0xc2, 0x01, 0x5a, 0xb3, 0x16, 0x6a, 0xd7, 0xda, 0xc3, 0x3a, 0xbf, 0xc1, 0xf6, 0xfe, 0x84,
+	0xed, 0xff, 0x1b, 0x76, 0x70, 0x08, 0x7b, 0xbf, 0xc2, 0x57, 0x52, 0xa5, 0x74, 0x59, 0x15, 0xa0,
+	0xec, 0xc5, 0xe9, 0x82, 0x47, 0x4a, 0xc4, 0x76, 0xa8, 0xa6, 0x4d, 0xb1, 0x73, 0xe3, 0xb7, 0xdb,
+	0x54, 0x98, 0x65, 0x19, 0xd1, 0x58, 0xe6, 0xac, 0x13, 0x64, 0x36, 0x68, 0x4f, 0xaf, 0xd9, 0xcf,
+	0xff, 0x13, 0x1d, 0xd5, 0xad, 0x9b, 0xaf, 0x01, 0x00, 0xec, 0xa9, 0xcc, 0x66, 0x5c, 0x02, 0x00,
+	0x00,
 }
@@ -17,9 +17,12 @@ It has these top-level messages:
 	SigningIdentityInfo
 	KeyInfo
 	FabricOUIdentifier
+	FabricNodeOUs
 	MSPPrincipal
 	OrganizationUnit
 	MSPRole
+	MSPIdentityAnonymity
+	CombinedPrincipal
 */
 package msp
 
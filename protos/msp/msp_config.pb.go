@@ -91,6 +91,9 @@ type FabricMSPConfig struct {
 	// List of TLS intermediate certificates trusted by this MSP;
 	// They are returned by GetTLSIntermediateCerts.
 	TlsIntermediateCerts [][]byte `protobuf:"bytes,10,rep,name=tls_intermediate_certs,json=tlsIntermediateCerts,proto3" json:"tls_intermediate_certs,omitempty"`
+	// fabric_node_ous contains the configuration to distinguish clients,
+	// peers and admins based on the OUs of their certificates.
+	FabricNodeOus *FabricNodeOUs `protobuf:"bytes,11,opt,name=fabric_node_ous,json=fabricNodeOus" json:"fabric_node_ous,omitempty"`
 }
 
 func (m *FabricMSPConfig) Reset()                    { *m = FabricMSPConfig{} }
@@ -168,6 +171,13 @@ func (m *FabricMSPConfig) GetTlsIntermediateCerts() [][]byte {
 	return nil
 }
 
+func (m *FabricMSPConfig) GetFabricNodeOus() *FabricNodeOUs {
+	if m != nil {
+		return m.FabricNodeOus
+	}
+	return nil
+}
+
 // FabricCryptoConfig contains configuration parameters
 // for the cryptographic algorithms used by the MSP
 // this configuration refers to
@@ -301,6 +311,55 @@ func (m *FabricOUIdentifier) GetOrganizationalUnitIdentifier() string {
 	return ""
 }
 
+// FabricNodeOUs contains configuration to tell apart clients, peers and
+// admins based on OUs. If NodeOUs recognition is enabled then an msp
+// identity that does not contain exactly one of the specified OUs is
+// considered invalid.
+type FabricNodeOUs struct {
+	// If true then an msp identity that does not contain exactly one of
+	// the specified OUs is considered invalid.
+	Enable bool `protobuf:"varint,1,opt,name=enable" json:"enable,omitempty"`
+	// OU Identifier of the clients
+	ClientOuIdentifier *FabricOUIdentifier `protobuf:"bytes,2,opt,name=client_ou_identifier,json=clientOuIdentifier" json:"client_ou_identifier,omitempty"`
+	// OU Identifier of the peers
+	PeerOuIdentifier *FabricOUIdentifier `protobuf:"bytes,3,opt,name=peer_ou_identifier,json=peerOuIdentifier" json:"peer_ou_identifier,omitempty"`
+	// OU Identifier of the admins
+	AdminOuIdentifier *FabricOUIdentifier `protobuf:"bytes,4,opt,name=admin_ou_identifier,json=adminOuIdentifier" json:"admin_ou_identifier,omitempty"`
+}
+
+func (m *FabricNodeOUs) Reset()                    { *m = FabricNodeOUs{} }
+func (m *FabricNodeOUs) String() string            { return proto.CompactTextString(m) }
+func (*FabricNodeOUs) ProtoMessage()               {}
+func (*FabricNodeOUs) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{6} }
+
+func (m *FabricNodeOUs) GetEnable() bool {
+	if m != nil {
+		return m.Enable
+	}
+	return false
+}
+
+func (m *FabricNodeOUs) GetClientOuIdentifier() *FabricOUIdentifier {
+	if m != nil {
+		return m.ClientOuIdentifier
+	}
+	return nil
+}
+
+func (m *FabricNodeOUs) GetPeerOuIdentifier() *FabricOUIdentifier {
+	if m != nil {
+		return m.PeerOuIdentifier
+	}
+	return nil
+}
+
+func (m *FabricNodeOUs) GetAdminOuIdentifier() *FabricOUIdentifier {
+	if m != nil {
+		return m.AdminOuIdentifier
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*MSPConfig)(nil), "msp.MSPConfig")
 	proto.RegisterType((*FabricMSPConfig)(nil), "msp.FabricMSPConfig")
@@ -308,48 +367,55 @@ func init() {
 	proto.RegisterType((*SigningIdentityInfo)(nil), "msp.SigningIdentityInfo")
 	proto.RegisterType((*KeyInfo)(nil), "msp.KeyInfo")
 	proto.RegisterType((*FabricOUIdentifier)(nil), "msp.FabricOUIdentifier")
+	proto.RegisterType((*FabricNodeOUs)(nil), "msp.FabricNodeOUs")
 }
 
 func init() { proto.RegisterFile("msp/msp_config.proto", fileDescriptor1) }
 
 var fileDescriptor1 = []byte{
-	// 599 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x7c, 0x54, 0xdf, 0x6e, 0xd3, 0x3c,
-	0x14, 0x57, 0xd7, 0xad, 0xfb, 0x7a, 0x9a, 0x76, 0xfb, 0xbc, 0x31, 0x72, 0xc1, 0x46, 0x16, 0x40,
-	0xe4, 0x86, 0x54, 0xda, 0x90, 0xb8, 0xe1, 0x6a, 0x45, 0x13, 0x15, 0x54, 0x4c, 0xa9, 0x76, 0xc3,
-	0x4d, 0xe4, 0xa6, 0x4e, 0x6a, 0x35, 0xb1, 0x23, 0xdb, 0x9d, 0x14, 0xc4, 0x5b, 0xf0, 0xaa, 0x3c,
-	0x00, 0x8a, 0xed, 0xb6, 0x29, 0x4c, 0xbb, 0xb3, 0x7f, 0x7f, 0x4e, 0x8f, 0x7f, 0xe7, 0x34, 0x70,
-	0x5a, 0xc8, 0x72, 0x58, 0xc8, 0x32, 0x4e, 0x38, 0x4b, 0x69, 0x16, 0x96, 0x82, 0x2b, 0x8e, 0xda,
-	0x85, 0x2c, 0xfd, 0x0f, 0xd0, 0x9d, 0x4c, 0xef, 0x46, 0x1a, 0x47, 0x08, 0xf6, 0x55, 0x55, 0x12,
-	0xb7, 0xe5, 0xb5, 0x82, 0x83, 0x48, 0x9f, 0xd1, 0x19, 0x74, 0x8c, 0xcb, 0xdd, 0xf3, 0x5a, 0x81,
-	0x13, 0xd9, 0x9b, 0xff, 0xbb, 0x0d, 0x47, 0xb7, 0x78, 0x26, 0x68, 0xb2, 0xe3, 0x67, 0xb8, 0x30,
-	0xfe, 0x6e, 0xa4, 0xcf, 0xe8, 0x1c, 0x40, 0x70, 0xae, 0xe2, 0x84, 0x08, 0x25, 0xdd, 0x3d, 0xaf,
-	0x1d, 0x38, 0x51, 0xb7, 0x46, 0x46, 0x35, 0x80, 0xde, 0x01, 0xa2, 0x4c, 0x11, 0x51, 0x90, 0x39,
-	0xc5, 0x8a, 0x58, 0x59, 0x5b, 0xcb, 0xfe, 0x6f, 0x32, 0x46, 0x7e, 0x06, 0x1d, 0x3c, 0x2f, 0x28,
-	0x93, 0xee, 0xbe, 0x96, 0xd8, 0x1b, 0x7a, 0x0b, 0x47, 0x82, 0x3c, 0xf0, 0x04, 0x2b, 0xca, 0x59,
-	0x9c, 0x53, 0xa9, 0xdc, 0x03, 0x2d, 0x18, 0x6c, 0xe1, 0xaf, 0x54, 0x2a, 0x34, 0x82, 0x63, 0x49,
-	0x33, 0x46, 0x59, 0x16, 0xd3, 0x39, 0x61, 0x8a, 0xaa, 0xca, 0xed, 0x78, 0xad, 0xa0, 0x77, 0xe5,
-	0x86, 0x85, 0x2c, 0xc3, 0xa9, 0x21, 0xc7, 0x96, 0x1b, 0xb3, 0x94, 0x47, 0x47, 0x72, 0x17, 0x44,
-	0x31, 0xbc, 0xe4, 0x22, 0xc3, 0x8c, 0xfe, 0xd0, 0x85, 0x71, 0x1e, 0xaf, 0x18, 0x55, 0xb6, 0x60,
-	0x4a, 0x89, 0x90, 0xee, 0xa1, 0xd7, 0x0e, 0x7a, 0x57, 0xcf, 0x75, 0x4d, 0x13, 0xd3, 0xb7, 0xfb,
-	0xf1, 0x86, 0x8f, 0xce, 0x77, 0xfd, 0xf7, 0x8c, 0xaa, 0x2d, 0x2b, 0xd1, 0x47, 0xe8, 0x27, 0xa2,
-	0x2a, 0x15, 0xb7, 0x13, 0x73, 0xff, 0xd3, 0x2d, 0x36, 0xcb, 0x8d, 0x34, 0x6f, 0x82, 0x8f, 0x9c,
-	0xa4, 0x71, 0x43, 0xaf, 0x61, 0xa0, 0x72, 0x19, 0x37, 0x62, 0xef, 0xea, 0x2c, 0x1c, 0x95, 0xcb,
-	0x68, 0x93, 0xfc, 0x7b, 0x38, 0xab, 0x55, 0x8f, 0xa4, 0x0f, 0x5a, 0x7d, 0xaa, 0x72, 0x39, 0xfe,
-	0x7b, 0x00, 0xfe, 0xaf, 0x16, 0xa0, 0x7f, 0x1b, 0x40, 0x57, 0xf0, 0xac, 0x0e, 0x09, 0xab, 0x95,
-	0x20, 0xf1, 0x02, 0xcb, 0x45, 0x9c, 0xe2, 0x82, 0xe6, 0x95, 0x5d, 0x85, 0x93, 0x0d, 0xf9, 0x19,
-	0xcb, 0xc5, 0xad, 0xa6, 0xd0, 0x18, 0x2e, 0xd7, 0x23, 0x68, 0x44, 0x67, 0xdd, 0x2b, 0x96, 0xd4,
-	0xd1, 0xe8, 0xa5, 0xeb, 0x46, 0x17, 0x6b, 0xe1, 0x36, 0x24, 0x5d, 0xc8, 0xaa, 0x7c, 0x0e, 0x27,
-	0x8f, 0x0c, 0x0e, 0xbd, 0x82, 0x7e, 0xb9, 0x9a, 0xe5, 0x34, 0x89, 0xeb, 0xdf, 0x27, 0x42, 0x77,
-	0xe3, 0x44, 0x8e, 0x01, 0xa7, 0x1a, 0x43, 0xd7, 0x30, 0x28, 0x05, 0x7d, 0xa8, 0x9f, 0x6f, 0x55,
-	0x7b, 0x3a, 0x6c, 0x47, 0x87, 0xfd, 0x85, 0x98, 0x1d, 0xe8, 0x5b, 0x8d, 0x31, 0xf9, 0x53, 0x38,
-	0xb4, 0x0c, 0x7a, 0x03, 0x83, 0x25, 0x69, 0xbe, 0xc0, 0xbe, 0xb9, 0xbf, 0x24, 0x8d, 0x76, 0xd1,
-	0x25, 0x38, 0xb5, 0xac, 0xc0, 0x8a, 0x08, 0x8a, 0x73, 0xfb, 0x6f, 0xea, 0x2d, 0x49, 0x35, 0xb1,
-	0x90, 0xff, 0x73, 0x1d, 0x6d, 0x73, 0x55, 0x90, 0x07, 0xbd, 0x7a, 0x2c, 0x34, 0xa5, 0x09, 0x56,
-	0xc4, 0x3e, 0xa1, 0x09, 0xa1, 0x4f, 0x70, 0xf1, 0xf4, 0x3a, 0xda, 0x14, 0x5f, 0x3c, 0xb5, 0x74,
-	0x37, 0x31, 0x5c, 0x72, 0x91, 0x85, 0x8b, 0xaa, 0x24, 0x22, 0x27, 0xf3, 0x8c, 0x88, 0x30, 0xd5,
-	0xdd, 0x98, 0xcf, 0x85, 0xac, 0xe3, 0xb8, 0x39, 0x9e, 0xc8, 0xd2, 0x8c, 0xfc, 0x0e, 0x27, 0x4b,
-	0x9c, 0x91, 0xef, 0x41, 0x46, 0xd5, 0x62, 0x35, 0x0b, 0x13, 0x5e, 0x0c, 0x1b, 0xde, 0xa1, 0xf1,
-	0x0e, 0x8d, 0xb7, 0xfe, 0xf8, 0xcc, 0x3a, 0xfa, 0x7c, 0xfd, 0x27, 0x00, 0x00, 0xff, 0xff, 0x2e,
-	0xb1, 0x83, 0xaa, 0x8e, 0x04, 0x00, 0x00,
+	// 697 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x7c, 0x54, 0x4d, 0x6f, 0x1a, 0x3b,
+	0x14, 0x15, 0x1f, 0x21, 0xe1, 0x32, 0x40, 0xe2, 0xe4, 0xe5, 0xcd, 0xe2, 0x25, 0x8f, 0xf0, 0x5e,
+	0x55, 0x36, 0x05, 0x29, 0xa9, 0x54, 0xa9, 0xea, 0x2a, 0xb4, 0x69, 0x51, 0x9b, 0x26, 0x1a, 0x94,
+	0x4d, 0x37, 0x96, 0x19, 0xcc, 0x60, 0x31, 0x63, 0x8f, 0x6c, 0x4f, 0x24, 0xaa, 0xae, 0xfa, 0x17,
+	0xfa, 0x47, 0xfa, 0x13, 0xab, 0xb1, 0x9d, 0x30, 0x90, 0x88, 0x9d, 0x7d, 0xef, 0x39, 0xc7, 0xd7,
+	0xe7, 0x5e, 0x1b, 0x8e, 0x12, 0x95, 0x0e, 0x12, 0x95, 0xe2, 0x50, 0xf0, 0x19, 0x8b, 0xfa, 0xa9,
+	0x14, 0x5a, 0xa0, 0x4a, 0xa2, 0xd2, 0xee, 0x1b, 0xa8, 0x5f, 0x8f, 0x6f, 0x87, 0x26, 0x8e, 0x10,
+	0x54, 0xf5, 0x32, 0xa5, 0x7e, 0xa9, 0x53, 0xea, 0xed, 0x04, 0x66, 0x8d, 0x8e, 0xa1, 0x66, 0x59,
+	0x7e, 0xb9, 0x53, 0xea, 0x79, 0x81, 0xdb, 0x75, 0x7f, 0x57, 0xa1, 0x7d, 0x45, 0x26, 0x92, 0x85,
+	0x6b, 0x7c, 0x4e, 0x12, 0xcb, 0xaf, 0x07, 0x66, 0x8d, 0x4e, 0x00, 0xa4, 0x10, 0x1a, 0x87, 0x54,
+	0x6a, 0xe5, 0x97, 0x3b, 0x95, 0x9e, 0x17, 0xd4, 0xf3, 0xc8, 0x30, 0x0f, 0xa0, 0x57, 0x80, 0x18,
+	0xd7, 0x54, 0x26, 0x74, 0xca, 0x88, 0xa6, 0x0e, 0x56, 0x31, 0xb0, 0x83, 0x62, 0xc6, 0xc2, 0x8f,
+	0xa1, 0x46, 0xa6, 0x09, 0xe3, 0xca, 0xaf, 0x1a, 0x88, 0xdb, 0xa1, 0x97, 0xd0, 0x96, 0xf4, 0x5e,
+	0x84, 0x44, 0x33, 0xc1, 0x71, 0xcc, 0x94, 0xf6, 0x77, 0x0c, 0xa0, 0xb5, 0x0a, 0x7f, 0x61, 0x4a,
+	0xa3, 0x21, 0xec, 0x2b, 0x16, 0x71, 0xc6, 0x23, 0xcc, 0xa6, 0x94, 0x6b, 0xa6, 0x97, 0x7e, 0xad,
+	0x53, 0xea, 0x35, 0xce, 0xfd, 0x7e, 0xa2, 0xd2, 0xfe, 0xd8, 0x26, 0x47, 0x2e, 0x37, 0xe2, 0x33,
+	0x11, 0xb4, 0xd5, 0x7a, 0x10, 0x61, 0xf8, 0x57, 0xc8, 0x88, 0x70, 0xf6, 0xdd, 0x08, 0x93, 0x18,
+	0x67, 0x9c, 0x69, 0x27, 0x38, 0x63, 0x54, 0x2a, 0x7f, 0xb7, 0x53, 0xe9, 0x35, 0xce, 0xff, 0x36,
+	0x9a, 0xd6, 0xa6, 0x9b, 0xbb, 0xd1, 0x63, 0x3e, 0x38, 0x59, 0xe7, 0xdf, 0x71, 0xa6, 0x57, 0x59,
+	0x85, 0xde, 0x41, 0x33, 0x94, 0xcb, 0x54, 0x0b, 0xd7, 0x31, 0x7f, 0xaf, 0x53, 0xda, 0x90, 0x1b,
+	0x9a, 0xbc, 0x35, 0x3e, 0xf0, 0xc2, 0xc2, 0x0e, 0xfd, 0x0f, 0x2d, 0x1d, 0x2b, 0x5c, 0xb0, 0xbd,
+	0x6e, 0xbc, 0xf0, 0x74, 0xac, 0x82, 0x47, 0xe7, 0x5f, 0xc3, 0x71, 0x8e, 0x7a, 0xc6, 0x7d, 0x30,
+	0xe8, 0x23, 0x1d, 0xab, 0xd1, 0x93, 0x06, 0xbc, 0x85, 0xf6, 0xcc, 0x9c, 0x8f, 0xb9, 0x98, 0x52,
+	0x2c, 0x32, 0xe5, 0x37, 0x4c, 0x6d, 0xa8, 0x50, 0xdb, 0x57, 0x31, 0xa5, 0x37, 0x77, 0x2a, 0x68,
+	0xce, 0x56, 0xdb, 0x4c, 0x75, 0x7f, 0x95, 0x00, 0x3d, 0x2d, 0x1e, 0x9d, 0xc3, 0x5f, 0xb9, 0xc1,
+	0x44, 0x67, 0x92, 0xe2, 0x39, 0x51, 0x73, 0x3c, 0x23, 0x09, 0x8b, 0x97, 0x6e, 0x8c, 0x0e, 0x1f,
+	0x93, 0x9f, 0x88, 0x9a, 0x5f, 0x99, 0x14, 0x1a, 0xc1, 0xd9, 0x43, 0xfb, 0x0a, 0xb6, 0x3b, 0x76,
+	0xc6, 0xc3, 0xdc, 0x56, 0x33, 0xb0, 0xf5, 0xe0, 0xf4, 0x01, 0xb8, 0x32, 0xd8, 0x08, 0x39, 0x54,
+	0x57, 0xc0, 0xe1, 0x33, 0x4d, 0x47, 0xff, 0x41, 0x33, 0xcd, 0x26, 0x31, 0x0b, 0x71, 0x7e, 0x3e,
+	0x95, 0xa6, 0x1a, 0x2f, 0xf0, 0x6c, 0x70, 0x6c, 0x62, 0xe8, 0x02, 0x5a, 0xa9, 0x64, 0xf7, 0xb9,
+	0x75, 0x0e, 0x55, 0x36, 0x66, 0x78, 0xc6, 0x8c, 0xcf, 0xd4, 0xce, 0x4f, 0xd3, 0x61, 0x2c, 0xa9,
+	0x3b, 0x86, 0x5d, 0x97, 0x41, 0x2f, 0xa0, 0xb5, 0xa0, 0xc5, 0x1b, 0xb8, 0x3b, 0x37, 0x17, 0xb4,
+	0x50, 0x2e, 0x3a, 0x03, 0x2f, 0x87, 0x25, 0x44, 0x53, 0xc9, 0x48, 0xec, 0x5e, 0x62, 0x63, 0x41,
+	0x97, 0xd7, 0x2e, 0xd4, 0xfd, 0x01, 0xe8, 0xe9, 0x98, 0xa1, 0x0e, 0x34, 0xf2, 0x96, 0xb2, 0x19,
+	0x0b, 0x89, 0xa6, 0xee, 0x0a, 0xc5, 0x10, 0x7a, 0x0f, 0xa7, 0xdb, 0x47, 0xd9, 0xb9, 0xf8, 0xcf,
+	0xb6, 0x81, 0xed, 0xfe, 0x2c, 0x43, 0x73, 0xad, 0xf5, 0xf9, 0x43, 0xa5, 0x9c, 0x4c, 0x62, 0x7b,
+	0xe8, 0x5e, 0xe0, 0x76, 0x68, 0x04, 0x47, 0x61, 0xcc, 0x28, 0xd7, 0x58, 0x64, 0x9b, 0xa7, 0x6c,
+	0x79, 0x2f, 0xc8, 0x92, 0x6e, 0xb2, 0xc2, 0xe5, 0x3e, 0x00, 0x4a, 0x29, 0x95, 0x1b, 0x42, 0x95,
+	0xed, 0x42, 0xfb, 0x39, 0x65, 0x4d, 0xe6, 0x23, 0x1c, 0x9a, 0x4f, 0x64, 0x43, 0xa7, 0xba, 0x5d,
+	0xe7, 0xc0, 0x70, 0x8a, 0x42, 0x97, 0x18, 0xce, 0x84, 0x8c, 0xfa, 0xf3, 0x65, 0x4a, 0x65, 0x4c,
+	0xa7, 0x11, 0x95, 0x7d, 0x3b, 0xff, 0xf6, 0xbf, 0x55, 0xb9, 0xd4, 0xe5, 0xfe, 0xb5, 0x4a, 0xed,
+	0xdc, 0xdf, 0x92, 0x70, 0x41, 0x22, 0xfa, 0xad, 0x17, 0x31, 0x3d, 0xcf, 0x26, 0xfd, 0x50, 0x24,
+	0x83, 0x02, 0x77, 0x60, 0xb9, 0x03, 0xcb, 0xcd, 0x7f, 0xef, 0x49, 0xcd, 0xac, 0x2f, 0xfe, 0x0c,
+	0x00, 0xaa, 0x0e, 0xf5, 0xc8, 0xcf, 0x05, 0x00, 0x00,
 }
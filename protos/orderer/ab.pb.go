@@ -11,22 +11,28 @@ It is generated from these files:
 
 It has these top-level messages:
 	BroadcastResponse
+	OrderingReceipt
 	SeekNewest
 	SeekOldest
 	SeekSpecified
 	SeekPosition
 	SeekInfo
+	DeliverSession
+	DeliverSessionInfo
+	DeliverAck
 	DeliverResponse
 	ConsensusType
 	BatchSize
 	BatchTimeout
 	KafkaBrokers
 	ChannelRestrictions
+	MessageFilterRules
 	KafkaMessage
 	KafkaMessageRegular
 	KafkaMessageTimeToCut
 	KafkaMessageConnect
 	KafkaMetadata
+	KafkaCheckpoint
 */
 package orderer
 
@@ -70,13 +76,39 @@ var SeekInfo_SeekBehavior_value = map[string]int32{
 func (x SeekInfo_SeekBehavior) String() string {
 	return proto.EnumName(SeekInfo_SeekBehavior_name, int32(x))
 }
-func (SeekInfo_SeekBehavior) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{5, 0} }
+func (SeekInfo_SeekBehavior) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{6, 0} }
+
+type SeekInfo_SeekContentType int32
+
+const (
+	SeekInfo_BLOCK           SeekInfo_SeekContentType = 0
+	SeekInfo_HEADER_WITH_SIG SeekInfo_SeekContentType = 1
+	SeekInfo_FILTERED        SeekInfo_SeekContentType = 2
+)
+
+var SeekInfo_SeekContentType_name = map[int32]string{
+	0: "BLOCK",
+	1: "HEADER_WITH_SIG",
+	2: "FILTERED",
+}
+var SeekInfo_SeekContentType_value = map[string]int32{
+	"BLOCK":           0,
+	"HEADER_WITH_SIG": 1,
+	"FILTERED":        2,
+}
+
+func (x SeekInfo_SeekContentType) String() string {
+	return proto.EnumName(SeekInfo_SeekContentType_name, int32(x))
+}
+func (SeekInfo_SeekContentType) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{6, 1} }
 
 type BroadcastResponse struct {
 	// Status code, which may be used to programatically respond to success/failure
 	Status common.Status `protobuf:"varint,1,opt,name=status,enum=common.Status" json:"status,omitempty"`
 	// Info string which may contain additional information about the status returned
 	Info string `protobuf:"bytes,2,opt,name=info" json:"info,omitempty"`
+	// Receipt of the ordering service for the message, when it issues receipts
+	Receipt *OrderingReceipt `protobuf:"bytes,3,opt,name=receipt" json:"receipt,omitempty"`
 }
 
 func (m *BroadcastResponse) Reset()                    { *m = BroadcastResponse{} }
@@ -98,13 +130,95 @@ func (m *BroadcastResponse) GetInfo() string {
 	return ""
 }
 
+func (m *BroadcastResponse) GetReceipt() *OrderingReceipt {
+	if m != nil {
+		return m.Receipt
+	}
+	return nil
+}
+
+// OrderingReceipt is issued by an orderer when it has enqueued a message for ordering,
+// before the message is cut into a block, so that the client can prove the submission
+type OrderingReceipt struct {
+	// The channel the message was broadcast to
+	ChannelId string `protobuf:"bytes,1,opt,name=channel_id,json=channelId" json:"channel_id,omitempty"`
+	// The transaction ID of the message
+	TxId string `protobuf:"bytes,2,opt,name=tx_id,json=txId" json:"tx_id,omitempty"`
+	// The SHA256 hash of the marshaled envelope enqueued for ordering, which is the message
+	// itself, or the config envelope produced from the message if it is a config update
+	EnvelopeHash []byte `protobuf:"bytes,3,opt,name=envelope_hash,json=envelopeHash,proto3" json:"envelope_hash,omitempty"`
+	// The config sequence the message was validated against
+	ConfigSequence uint64 `protobuf:"varint,4,opt,name=config_sequence,json=configSequence" json:"config_sequence,omitempty"`
+	// The height of the ledger of the channel when the message was enqueued, the message
+	// is ordered in a block whose number is no less than it
+	Height uint64 `protobuf:"varint,5,opt,name=height" json:"height,omitempty"`
+	// The marshaled common.SignatureHeader of the orderer
+	SignatureHeader []byte `protobuf:"bytes,6,opt,name=signature_header,json=signatureHeader,proto3" json:"signature_header,omitempty"`
+	// The signature of the orderer over the marshaled receipt with an empty signature
+	Signature []byte `protobuf:"bytes,7,opt,name=signature,proto3" json:"signature,omitempty"`
+}
+
+func (m *OrderingReceipt) Reset()                    { *m = OrderingReceipt{} }
+func (m *OrderingReceipt) String() string            { return proto.CompactTextString(m) }
+func (*OrderingReceipt) ProtoMessage()               {}
+func (*OrderingReceipt) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{1} }
+
+func (m *OrderingReceipt) GetChannelId() string {
+	if m != nil {
+		return m.ChannelId
+	}
+	return ""
+}
+
+func (m *OrderingReceipt) GetTxId() string {
+	if m != nil {
+		return m.TxId
+	}
+	return ""
+}
+
+func (m *OrderingReceipt) GetEnvelopeHash() []byte {
+	if m != nil {
+		return m.EnvelopeHash
+	}
+	return nil
+}
+
+func (m *OrderingReceipt) GetConfigSequence() uint64 {
+	if m != nil {
+		return m.ConfigSequence
+	}
+	return 0
+}
+
+func (m *OrderingReceipt) GetHeight() uint64 {
+	if m != nil {
+		return m.Height
+	}
+	return 0
+}
+
+func (m *OrderingReceipt) GetSignatureHeader() []byte {
+	if m != nil {
+		return m.SignatureHeader
+	}
+	return nil
+}
+
+func (m *OrderingReceipt) GetSignature() []byte {
+	if m != nil {
+		return m.Signature
+	}
+	return nil
+}
+
 type SeekNewest struct {
 }
 
 func (m *SeekNewest) Reset()                    { *m = SeekNewest{} }
 func (m *SeekNewest) String() string            { return proto.CompactTextString(m) }
 func (*SeekNewest) ProtoMessage()               {}
-func (*SeekNewest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{1} }
+func (*SeekNewest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{2} }
 
 type SeekOldest struct {
 }
@@ -112,7 +226,7 @@ type SeekOldest struct {
 func (m *SeekOldest) Reset()                    { *m = SeekOldest{} }
 func (m *SeekOldest) String() string            { return proto.CompactTextString(m) }
 func (*SeekOldest) ProtoMessage()               {}
-func (*SeekOldest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{2} }
+func (*SeekOldest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{3} }
 
 type SeekSpecified struct {
 	Number uint64 `protobuf:"varint,1,opt,name=number" json:"number,omitempty"`
@@ -121,7 +235,7 @@ type SeekSpecified struct {
 func (m *SeekSpecified) Reset()                    { *m = SeekSpecified{} }
 func (m *SeekSpecified) String() string            { return proto.CompactTextString(m) }
 func (*SeekSpecified) ProtoMessage()               {}
-func (*SeekSpecified) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{3} }
+func (*SeekSpecified) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{4} }
 
 func (m *SeekSpecified) GetNumber() uint64 {
 	if m != nil {
@@ -141,7 +255,7 @@ type SeekPosition struct {
 func (m *SeekPosition) Reset()                    { *m = SeekPosition{} }
 func (m *SeekPosition) String() string            { return proto.CompactTextString(m) }
 func (*SeekPosition) ProtoMessage()               {}
-func (*SeekPosition) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{4} }
+func (*SeekPosition) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{5} }
 
 type isSeekPosition_Type interface {
 	isSeekPosition_Type()
@@ -289,17 +403,23 @@ func _SeekPosition_OneofSizer(msg proto.Message) (n int) {
 // the requested blocks are available, if FAIL_IF_NOT_READY is specified, the reply will return an
 // error indicating that the block is not found.  To request that all blocks be returned indefinitely
 // as they are created, behavior should be set to BLOCK_UNTIL_READY and the stop should be set to
-// specified with a number of MAX_UINT64
+// specified with a number of MAX_UINT64. The content type specifies which part of the blocks
+// is returned: clients which only track the height of the chain may request the header and
+// metadata of the blocks, and clients which only track the config of the channel may request
+// the config blocks in full and the header and metadata of the other blocks. A client may open a
+// resumable deliver session, or resume one, by setting the session
 type SeekInfo struct {
-	Start    *SeekPosition         `protobuf:"bytes,1,opt,name=start" json:"start,omitempty"`
-	Stop     *SeekPosition         `protobuf:"bytes,2,opt,name=stop" json:"stop,omitempty"`
-	Behavior SeekInfo_SeekBehavior `protobuf:"varint,3,opt,name=behavior,enum=orderer.SeekInfo_SeekBehavior" json:"behavior,omitempty"`
+	Start       *SeekPosition            `protobuf:"bytes,1,opt,name=start" json:"start,omitempty"`
+	Stop        *SeekPosition            `protobuf:"bytes,2,opt,name=stop" json:"stop,omitempty"`
+	Behavior    SeekInfo_SeekBehavior    `protobuf:"varint,3,opt,name=behavior,enum=orderer.SeekInfo_SeekBehavior" json:"behavior,omitempty"`
+	ContentType SeekInfo_SeekContentType `protobuf:"varint,4,opt,name=content_type,json=contentType,enum=orderer.SeekInfo_SeekContentType" json:"content_type,omitempty"`
+	Session     *DeliverSession          `protobuf:"bytes,5,opt,name=session" json:"session,omitempty"`
 }
 
 func (m *SeekInfo) Reset()                    { *m = SeekInfo{} }
 func (m *SeekInfo) String() string            { return proto.CompactTextString(m) }
 func (*SeekInfo) ProtoMessage()               {}
-func (*SeekInfo) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{5} }
+func (*SeekInfo) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{6} }
 
 func (m *SeekInfo) GetStart() *SeekPosition {
 	if m != nil {
@@ -322,17 +442,106 @@ func (m *SeekInfo) GetBehavior() SeekInfo_SeekBehavior {
 	return SeekInfo_BLOCK_UNTIL_READY
 }
 
+func (m *SeekInfo) GetContentType() SeekInfo_SeekContentType {
+	if m != nil {
+		return m.ContentType
+	}
+	return SeekInfo_BLOCK
+}
+
+func (m *SeekInfo) GetSession() *DeliverSession {
+	if m != nil {
+		return m.Session
+	}
+	return nil
+}
+
+// DeliverSession opens a resumable deliver session when its token is empty, and otherwise resumes
+// the session of the token. The orderer remembers the last block acknowledged in a session, so that
+// a client reconnecting with the same identity to the same channel resumes the deliver after it:
+// the start position of the SeekInfo is then ignored. With a non zero ack_window, the orderer sends
+// at most ack_window blocks past the last acknowledged block before waiting for acknowledgments
+type DeliverSession struct {
+	Token     []byte `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	AckWindow uint32 `protobuf:"varint,2,opt,name=ack_window,json=ackWindow" json:"ack_window,omitempty"`
+}
+
+func (m *DeliverSession) Reset()                    { *m = DeliverSession{} }
+func (m *DeliverSession) String() string            { return proto.CompactTextString(m) }
+func (*DeliverSession) ProtoMessage()               {}
+func (*DeliverSession) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{7} }
+
+func (m *DeliverSession) GetToken() []byte {
+	if m != nil {
+		return m.Token
+	}
+	return nil
+}
+
+func (m *DeliverSession) GetAckWindow() uint32 {
+	if m != nil {
+		return m.AckWindow
+	}
+	return 0
+}
+
+// DeliverSessionInfo is sent at the beginning of the deliver of a resumable session, with the token
+// to present to resume it and the number of the first block delivered
+type DeliverSessionInfo struct {
+	Token     []byte `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	NextBlock uint64 `protobuf:"varint,2,opt,name=next_block,json=nextBlock" json:"next_block,omitempty"`
+}
+
+func (m *DeliverSessionInfo) Reset()                    { *m = DeliverSessionInfo{} }
+func (m *DeliverSessionInfo) String() string            { return proto.CompactTextString(m) }
+func (*DeliverSessionInfo) ProtoMessage()               {}
+func (*DeliverSessionInfo) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{8} }
+
+func (m *DeliverSessionInfo) GetToken() []byte {
+	if m != nil {
+		return m.Token
+	}
+	return nil
+}
+
+func (m *DeliverSessionInfo) GetNextBlock() uint64 {
+	if m != nil {
+		return m.NextBlock
+	}
+	return 0
+}
+
+// DeliverAck acknowledges the blocks of a resumable deliver session up to block_number, it is the
+// Payload data of an Envelope of type DELIVER_ACK sent on the deliver stream. Acknowledgments
+// need not be signed, as they only apply to the session being delivered on the stream
+type DeliverAck struct {
+	BlockNumber uint64 `protobuf:"varint,1,opt,name=block_number,json=blockNumber" json:"block_number,omitempty"`
+}
+
+func (m *DeliverAck) Reset()                    { *m = DeliverAck{} }
+func (m *DeliverAck) String() string            { return proto.CompactTextString(m) }
+func (*DeliverAck) ProtoMessage()               {}
+func (*DeliverAck) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{9} }
+
+func (m *DeliverAck) GetBlockNumber() uint64 {
+	if m != nil {
+		return m.BlockNumber
+	}
+	return 0
+}
+
 type DeliverResponse struct {
 	// Types that are valid to be assigned to Type:
 	//	*DeliverResponse_Status
 	//	*DeliverResponse_Block
+	//	*DeliverResponse_Session
 	Type isDeliverResponse_Type `protobuf_oneof:"Type"`
 }
 
 func (m *DeliverResponse) Reset()                    { *m = DeliverResponse{} }
 func (m *DeliverResponse) String() string            { return proto.CompactTextString(m) }
 func (*DeliverResponse) ProtoMessage()               {}
-func (*DeliverResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{6} }
+func (*DeliverResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{10} }
 
 type isDeliverResponse_Type interface {
 	isDeliverResponse_Type()
@@ -344,9 +553,13 @@ type DeliverResponse_Status struct {
 type DeliverResponse_Block struct {
 	Block *common.Block `protobuf:"bytes,2,opt,name=block,oneof"`
 }
+type DeliverResponse_Session struct {
+	Session *DeliverSessionInfo `protobuf:"bytes,3,opt,name=session,oneof"`
+}
 
-func (*DeliverResponse_Status) isDeliverResponse_Type() {}
-func (*DeliverResponse_Block) isDeliverResponse_Type()  {}
+func (*DeliverResponse_Status) isDeliverResponse_Type()  {}
+func (*DeliverResponse_Block) isDeliverResponse_Type()   {}
+func (*DeliverResponse_Session) isDeliverResponse_Type() {}
 
 func (m *DeliverResponse) GetType() isDeliverResponse_Type {
 	if m != nil {
@@ -369,11 +582,19 @@ func (m *DeliverResponse) GetBlock() *common.Block {
 	return nil
 }
 
+func (m *DeliverResponse) GetSession() *DeliverSessionInfo {
+	if x, ok := m.GetType().(*DeliverResponse_Session); ok {
+		return x.Session
+	}
+	return nil
+}
+
 // XXX_OneofFuncs is for the internal use of the proto package.
 func (*DeliverResponse) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) error, func(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error), func(msg proto.Message) (n int), []interface{}) {
 	return _DeliverResponse_OneofMarshaler, _DeliverResponse_OneofUnmarshaler, _DeliverResponse_OneofSizer, []interface{}{
 		(*DeliverResponse_Status)(nil),
 		(*DeliverResponse_Block)(nil),
+		(*DeliverResponse_Session)(nil),
 	}
 }
 
@@ -389,6 +610,11 @@ func _DeliverResponse_OneofMarshaler(msg proto.Message, b *proto.Buffer) error {
 		if err := b.EncodeMessage(x.Block); err != nil {
 			return err
 		}
+	case *DeliverResponse_Session:
+		b.EncodeVarint(3<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Session); err != nil {
+			return err
+		}
 	case nil:
 	default:
 		return fmt.Errorf("DeliverResponse.Type has unexpected type %T", x)
@@ -414,6 +640,14 @@ func _DeliverResponse_OneofUnmarshaler(msg proto.Message, tag, wire int, b *prot
 		err := b.DecodeMessage(msg)
 		m.Type = &DeliverResponse_Block{msg}
 		return true, err
+	case 3: // Type.session
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(DeliverSessionInfo)
+		err := b.DecodeMessage(msg)
+		m.Type = &DeliverResponse_Session{msg}
+		return true, err
 	default:
 		return false, nil
 	}
@@ -431,6 +665,11 @@ func _DeliverResponse_OneofSizer(msg proto.Message) (n int) {
 		n += proto.SizeVarint(2<<3 | proto.WireBytes)
 		n += proto.SizeVarint(uint64(s))
 		n += s
+	case *DeliverResponse_Session:
+		s := proto.Size(x.Session)
+		n += proto.SizeVarint(3<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
 	case nil:
 	default:
 		panic(fmt.Sprintf("proto: unexpected type %T in oneof", x))
@@ -440,13 +679,18 @@ func _DeliverResponse_OneofSizer(msg proto.Message) (n int) {
 
 func init() {
 	proto.RegisterType((*BroadcastResponse)(nil), "orderer.BroadcastResponse")
+	proto.RegisterType((*OrderingReceipt)(nil), "orderer.OrderingReceipt")
 	proto.RegisterType((*SeekNewest)(nil), "orderer.SeekNewest")
 	proto.RegisterType((*SeekOldest)(nil), "orderer.SeekOldest")
 	proto.RegisterType((*SeekSpecified)(nil), "orderer.SeekSpecified")
 	proto.RegisterType((*SeekPosition)(nil), "orderer.SeekPosition")
 	proto.RegisterType((*SeekInfo)(nil), "orderer.SeekInfo")
+	proto.RegisterType((*DeliverSession)(nil), "orderer.DeliverSession")
+	proto.RegisterType((*DeliverSessionInfo)(nil), "orderer.DeliverSessionInfo")
+	proto.RegisterType((*DeliverAck)(nil), "orderer.DeliverAck")
 	proto.RegisterType((*DeliverResponse)(nil), "orderer.DeliverResponse")
 	proto.RegisterEnum("orderer.SeekInfo_SeekBehavior", SeekInfo_SeekBehavior_name, SeekInfo_SeekBehavior_value)
+	proto.RegisterEnum("orderer.SeekInfo_SeekContentType", SeekInfo_SeekContentType_name, SeekInfo_SeekContentType_value)
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -625,37 +869,58 @@ var _AtomicBroadcast_serviceDesc = grpc.ServiceDesc{
 func init() { proto.RegisterFile("orderer/ab.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 505 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x7c, 0x93, 0xdf, 0x6e, 0x12, 0x51,
-	0x10, 0xc6, 0x59, 0xa5, 0xb4, 0x8c, 0x2d, 0xa5, 0xa7, 0x69, 0x43, 0xb8, 0x30, 0xcd, 0x26, 0x55,
-	0x8c, 0xba, 0x6b, 0x30, 0xf1, 0x42, 0x4d, 0x0c, 0x6b, 0xdb, 0x40, 0x24, 0x60, 0x16, 0x7a, 0xa1,
-	0x37, 0x64, 0x77, 0x19, 0x60, 0xed, 0xb2, 0x67, 0x73, 0xce, 0x01, 0xd3, 0xa7, 0xf0, 0x45, 0x7c,
-	0x24, 0x1f, 0xc6, 0x9c, 0x3f, 0xbb, 0x48, 0xd5, 0x5e, 0xb1, 0x33, 0xf3, 0xfb, 0xce, 0x7c, 0x33,
-	0x19, 0xa0, 0x4e, 0xd9, 0x14, 0x19, 0x32, 0x37, 0x08, 0x9d, 0x8c, 0x51, 0x41, 0xc9, 0xae, 0xc9,
-	0x34, 0x8f, 0x23, 0xba, 0x5c, 0xd2, 0xd4, 0xd5, 0x3f, 0xba, 0x6a, 0x0f, 0xe1, 0xc8, 0x63, 0x34,
-	0x98, 0x46, 0x01, 0x17, 0x3e, 0xf2, 0x8c, 0xa6, 0x1c, 0xc9, 0x13, 0xa8, 0x70, 0x11, 0x88, 0x15,
-	0x6f, 0x58, 0x67, 0x56, 0xab, 0xd6, 0xae, 0x39, 0x46, 0x33, 0x52, 0x59, 0xdf, 0x54, 0x09, 0x81,
-	0x72, 0x9c, 0xce, 0x68, 0xe3, 0xc1, 0x99, 0xd5, 0xaa, 0xfa, 0xea, 0xdb, 0xde, 0x07, 0x18, 0x21,
-	0xde, 0x0c, 0xf0, 0x3b, 0x72, 0x91, 0x47, 0xc3, 0x64, 0x2a, 0xa3, 0xa7, 0x70, 0x20, 0xa3, 0x51,
-	0x86, 0x51, 0x3c, 0x8b, 0x71, 0x4a, 0x4e, 0xa1, 0x92, 0xae, 0x96, 0x21, 0x32, 0xd5, 0xa8, 0xec,
-	0x9b, 0xc8, 0xfe, 0x69, 0xc1, 0xbe, 0x24, 0x3f, 0x53, 0x1e, 0x8b, 0x98, 0xa6, 0xe4, 0x25, 0x54,
-	0x52, 0xf5, 0xa2, 0x02, 0x1f, 0xb5, 0x8f, 0x1d, 0x33, 0x95, 0xb3, 0x69, 0xd6, 0x2d, 0xf9, 0x06,
-	0x92, 0x38, 0x55, 0x2d, 0x95, 0xb5, 0xbb, 0xb8, 0x76, 0x23, 0x71, 0x0d, 0x91, 0x37, 0x50, 0xe5,
-	0xb9, 0xa7, 0xc6, 0x43, 0xa5, 0x38, 0xdd, 0x52, 0x14, 0x8e, 0xbb, 0x25, 0x7f, 0x83, 0x7a, 0x15,
-	0x28, 0x8f, 0x6f, 0x33, 0xb4, 0x7f, 0x59, 0xb0, 0x27, 0xb1, 0x5e, 0x3a, 0xa3, 0xe4, 0x39, 0xec,
-	0x70, 0x11, 0xb0, 0xdc, 0xe9, 0xc9, 0xd6, 0x43, 0xf9, 0x40, 0xbe, 0x66, 0xc8, 0x33, 0x28, 0x73,
-	0x41, 0x33, 0x63, 0xf3, 0x3f, 0xac, 0x42, 0xc8, 0x5b, 0xd8, 0x0b, 0x71, 0x11, 0xac, 0x63, 0xca,
-	0x94, 0xc7, 0x5a, 0xfb, 0xf1, 0x16, 0x2e, 0x9b, 0xab, 0x0f, 0xcf, 0x50, 0x7e, 0xc1, 0xdb, 0xef,
-	0xf5, 0x3a, 0xf3, 0x0a, 0x39, 0x81, 0x23, 0xaf, 0x3f, 0xfc, 0xf8, 0x69, 0x72, 0x3d, 0x18, 0xf7,
-	0xfa, 0x13, 0xff, 0xb2, 0x73, 0xf1, 0xa5, 0x5e, 0x92, 0xe9, 0xab, 0x4e, 0xaf, 0x3f, 0xe9, 0x5d,
-	0x4d, 0x06, 0xc3, 0xb1, 0x49, 0x5b, 0xf6, 0x37, 0x38, 0xbc, 0xc0, 0x24, 0x5e, 0x23, 0x2b, 0x2e,
-	0xa4, 0x75, 0xff, 0x85, 0xc8, 0xdd, 0x9a, 0x1b, 0x39, 0x87, 0x9d, 0x30, 0xa1, 0xd1, 0x8d, 0x19,
-	0xf1, 0x20, 0x07, 0x3d, 0x99, 0xec, 0x96, 0x7c, 0x5d, 0xcd, 0x57, 0xd9, 0xfe, 0x61, 0xc1, 0x61,
-	0x47, 0xd0, 0x65, 0x1c, 0x15, 0x67, 0x49, 0x3e, 0x40, 0x75, 0x13, 0xd4, 0xf3, 0x07, 0x2e, 0xd3,
-	0x35, 0x26, 0x34, 0xc3, 0x66, 0xb3, 0x58, 0xc3, 0x5f, 0x97, 0x6c, 0x97, 0x5a, 0xd6, 0x2b, 0x8b,
-	0xbc, 0x83, 0x5d, 0x33, 0xc0, 0x3f, 0xe4, 0x8d, 0x42, 0x7e, 0x67, 0x48, 0x2d, 0xf6, 0xae, 0xe1,
-	0x9c, 0xb2, 0xb9, 0xb3, 0xb8, 0xcd, 0x90, 0x25, 0x38, 0x9d, 0x23, 0x73, 0x66, 0x41, 0xc8, 0xe2,
-	0x48, 0xff, 0x83, 0x78, 0x2e, 0xff, 0xfa, 0x62, 0x1e, 0x8b, 0xc5, 0x2a, 0x94, 0x0d, 0xdc, 0x3f,
-	0x68, 0x57, 0xd3, 0xae, 0xa6, 0x5d, 0x43, 0x87, 0x15, 0x15, 0xbf, 0xfe, 0x1d, 0x00, 0x00, 0xff,
-	0xff, 0x4b, 0x88, 0xa4, 0x39, 0xb1, 0x03, 0x00, 0x00,
+	// 847 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x7c, 0x95, 0xdd, 0x8e, 0xdb, 0x44,
+	0x14, 0xc7, 0xe3, 0x6d, 0x3e, 0x36, 0x27, 0xd9, 0x24, 0x9d, 0xa5, 0xc5, 0x5a, 0x3e, 0xb4, 0x35,
+	0x2a, 0x4d, 0x05, 0x24, 0x10, 0x24, 0x90, 0x00, 0xa9, 0x4a, 0x9a, 0x2c, 0xb1, 0x58, 0xed, 0xa2,
+	0x49, 0xaa, 0x0a, 0x6e, 0x2c, 0xc7, 0x9e, 0x8d, 0x47, 0xc9, 0xce, 0x18, 0xcf, 0x64, 0x3f, 0xae,
+	0x79, 0x00, 0xee, 0x78, 0x01, 0x6e, 0x79, 0x3c, 0x1e, 0x00, 0xcd, 0x87, 0x9d, 0x66, 0xbb, 0xed,
+	0x55, 0x7c, 0xfe, 0xe7, 0x37, 0xe7, 0xfc, 0xed, 0x33, 0x33, 0x81, 0x0e, 0xcf, 0x62, 0x92, 0x91,
+	0xac, 0x1f, 0x2e, 0x7a, 0x69, 0xc6, 0x25, 0x47, 0x35, 0xab, 0x1c, 0x1d, 0x46, 0xfc, 0xf2, 0x92,
+	0xb3, 0xbe, 0xf9, 0x31, 0x59, 0xef, 0x4f, 0x07, 0x1e, 0x8e, 0x32, 0x1e, 0xc6, 0x51, 0x28, 0x24,
+	0x26, 0x22, 0xe5, 0x4c, 0x10, 0xf4, 0x39, 0x54, 0x85, 0x0c, 0xe5, 0x46, 0xb8, 0xce, 0xb1, 0xd3,
+	0x6d, 0x0d, 0x5a, 0x3d, 0xbb, 0x68, 0xa6, 0x55, 0x6c, 0xb3, 0x08, 0x41, 0x99, 0xb2, 0x0b, 0xee,
+	0xee, 0x1d, 0x3b, 0xdd, 0x3a, 0xd6, 0xcf, 0x68, 0x00, 0xb5, 0x8c, 0x44, 0x84, 0xa6, 0xd2, 0x7d,
+	0x70, 0xec, 0x74, 0x1b, 0x03, 0xb7, 0x67, 0x1d, 0xf4, 0xce, 0xd5, 0x2f, 0x65, 0x4b, 0x6c, 0xf2,
+	0x38, 0x07, 0xbd, 0xff, 0x1c, 0x68, 0xdf, 0x49, 0xa2, 0x4f, 0x00, 0xa2, 0x24, 0x64, 0x8c, 0xac,
+	0x03, 0x1a, 0x6b, 0x1f, 0x75, 0x5c, 0xb7, 0x8a, 0x1f, 0xa3, 0x43, 0xa8, 0xc8, 0x1b, 0x95, 0xb1,
+	0xbd, 0xe5, 0x8d, 0x1f, 0xa3, 0xcf, 0xe0, 0x80, 0xb0, 0x2b, 0xb2, 0xe6, 0x29, 0x09, 0x92, 0x50,
+	0x24, 0xda, 0x41, 0x13, 0x37, 0x73, 0x71, 0x1a, 0x8a, 0x04, 0x3d, 0x83, 0x76, 0xc4, 0xd9, 0x05,
+	0x5d, 0x06, 0x82, 0xfc, 0xb1, 0x21, 0x2c, 0x22, 0x6e, 0xf9, 0xd8, 0xe9, 0x96, 0x71, 0xcb, 0xc8,
+	0x33, 0xab, 0xa2, 0xc7, 0x50, 0x4d, 0x08, 0x5d, 0x26, 0xd2, 0xad, 0xe8, 0xbc, 0x8d, 0xd0, 0x73,
+	0xe8, 0x08, 0xba, 0x64, 0xa1, 0xdc, 0x64, 0x24, 0x48, 0x48, 0x18, 0x93, 0xcc, 0xad, 0xea, 0x46,
+	0xed, 0x42, 0x9f, 0x6a, 0x19, 0x7d, 0x0c, 0xf5, 0x42, 0x72, 0x6b, 0x9a, 0xd9, 0x0a, 0x5e, 0x13,
+	0x60, 0x46, 0xc8, 0xea, 0x8c, 0x5c, 0x13, 0x21, 0xf3, 0xe8, 0x7c, 0x1d, 0xab, 0xe8, 0x19, 0x1c,
+	0xa8, 0x68, 0x96, 0x92, 0x88, 0x5e, 0x50, 0x12, 0x2b, 0x37, 0x6c, 0x73, 0xb9, 0x20, 0x99, 0xfe,
+	0x16, 0x65, 0x6c, 0x23, 0xef, 0x5f, 0x07, 0x9a, 0x8a, 0xfc, 0x95, 0x0b, 0x2a, 0x29, 0x67, 0xe8,
+	0x2b, 0xa8, 0x32, 0x5d, 0x51, 0x83, 0x8d, 0xc1, 0x61, 0xf1, 0xfd, 0xb7, 0xcd, 0xa6, 0x25, 0x6c,
+	0x21, 0x85, 0x73, 0xdd, 0xd2, 0xdd, 0xbb, 0x07, 0x37, 0x6e, 0x14, 0x6e, 0x20, 0xf4, 0x1d, 0xd4,
+	0x45, 0xee, 0xc9, 0x0e, 0xf8, 0xf1, 0xce, 0x8a, 0xc2, 0xf1, 0xb4, 0x84, 0xb7, 0xe8, 0xa8, 0x0a,
+	0xe5, 0xf9, 0x6d, 0x4a, 0xbc, 0xbf, 0x1f, 0xc0, 0xbe, 0xc2, 0x7c, 0xb5, 0x57, 0xbe, 0x80, 0x8a,
+	0x90, 0x61, 0x96, 0x3b, 0x7d, 0xb4, 0x53, 0x28, 0x7f, 0x21, 0x6c, 0x18, 0xf4, 0x1c, 0xca, 0x42,
+	0xf2, 0xd4, 0xdd, 0x7b, 0x1f, 0xab, 0x11, 0xf4, 0x03, 0xec, 0x2f, 0x48, 0x12, 0x5e, 0x51, 0x9e,
+	0x69, 0x8f, 0xad, 0xc1, 0xa7, 0x3b, 0xb8, 0x6a, 0xae, 0x1f, 0x46, 0x96, 0xc2, 0x05, 0x8f, 0xc6,
+	0xd0, 0x8c, 0x38, 0x93, 0x84, 0xc9, 0x40, 0xde, 0xa6, 0x66, 0x6f, 0xb4, 0x06, 0x4f, 0xee, 0x5f,
+	0xff, 0xd2, 0x90, 0xea, 0xcd, 0x70, 0x23, 0xda, 0x06, 0xe8, 0x1b, 0xa8, 0x09, 0x22, 0x04, 0xe5,
+	0x4c, 0x6f, 0x9e, 0xc6, 0xe0, 0xc3, 0xa2, 0xc0, 0x98, 0xac, 0xe9, 0x15, 0xc9, 0x66, 0x26, 0x8d,
+	0x73, 0xce, 0xfb, 0x09, 0x9a, 0x6f, 0x5a, 0x42, 0x8f, 0xe0, 0xe1, 0xe8, 0xf4, 0xfc, 0xe5, 0x2f,
+	0xc1, 0xab, 0xb3, 0xb9, 0x7f, 0x1a, 0xe0, 0xc9, 0x70, 0xfc, 0x5b, 0xa7, 0xa4, 0xe4, 0x93, 0xa1,
+	0x7f, 0x1a, 0xf8, 0x27, 0xc1, 0xd9, 0xf9, 0xdc, 0xca, 0x8e, 0xf7, 0x02, 0xda, 0x77, 0x0c, 0xa1,
+	0x3a, 0x54, 0x74, 0x81, 0x4e, 0x09, 0x1d, 0x42, 0x7b, 0x3a, 0x19, 0x8e, 0x27, 0x38, 0x78, 0xed,
+	0xcf, 0xa7, 0xc1, 0xcc, 0xff, 0xb9, 0xe3, 0xa0, 0x26, 0xec, 0x9f, 0xf8, 0xa7, 0xf3, 0x09, 0x9e,
+	0x8c, 0x3b, 0x7b, 0xde, 0x04, 0x5a, 0xbb, 0xce, 0xd0, 0x07, 0x50, 0x91, 0x7c, 0x45, 0x98, 0x9e,
+	0x4e, 0x13, 0x9b, 0x40, 0x9d, 0xcb, 0x30, 0x5a, 0x05, 0xd7, 0x94, 0xc5, 0xfc, 0x5a, 0x0f, 0xe3,
+	0x00, 0xd7, 0xc3, 0x68, 0xf5, 0x5a, 0x0b, 0x9e, 0x0f, 0x68, 0xb7, 0x8c, 0x1e, 0xf4, 0x3b, 0x4b,
+	0x31, 0x72, 0x23, 0x83, 0xc5, 0x9a, 0x47, 0x2b, 0x5d, 0xaa, 0x8c, 0xeb, 0x4a, 0x19, 0x29, 0xc1,
+	0xeb, 0x03, 0xd8, 0x52, 0xc3, 0x68, 0x85, 0x9e, 0x40, 0x53, 0x73, 0xc1, 0xce, 0x29, 0x68, 0x68,
+	0xed, 0xcc, 0x1c, 0x85, 0x7f, 0x1c, 0x68, 0xdb, 0x15, 0xc5, 0x55, 0xd6, 0x7d, 0xff, 0x55, 0xa6,
+	0x76, 0xb6, 0xc9, 0xa3, 0xa7, 0x50, 0xd9, 0x1a, 0x69, 0x0c, 0x0e, 0x72, 0x50, 0x9b, 0x99, 0x96,
+	0xb0, 0xc9, 0xa2, 0xef, 0xb7, 0x93, 0x35, 0xdb, 0xff, 0xa3, 0x77, 0x4c, 0x56, 0xbd, 0xf8, 0xb4,
+	0x54, 0xcc, 0x37, 0x3f, 0x01, 0x83, 0xbf, 0x1c, 0x68, 0x0f, 0x25, 0xbf, 0xa4, 0x51, 0x71, 0xf1,
+	0xa2, 0x17, 0x50, 0xdf, 0x06, 0x9d, 0xbc, 0xf3, 0xc4, 0x5e, 0x5b, 0x47, 0x47, 0x45, 0x8b, 0xb7,
+	0xee, 0x6a, 0xaf, 0xd4, 0x75, 0xbe, 0x76, 0xd0, 0x8f, 0x50, 0xb3, 0xdd, 0xef, 0x59, 0xee, 0xde,
+	0x75, 0xb8, 0xbb, 0x78, 0xf4, 0x0a, 0x9e, 0xf2, 0x6c, 0xd9, 0x4b, 0x6e, 0x53, 0x92, 0xad, 0x49,
+	0xbc, 0x24, 0x59, 0xef, 0x22, 0x5c, 0x64, 0x34, 0x32, 0x7f, 0x12, 0x22, 0x5f, 0xfe, 0xfb, 0x97,
+	0x4b, 0x2a, 0x93, 0xcd, 0x42, 0x35, 0xe8, 0xbf, 0x41, 0xf7, 0x0d, 0xdd, 0x37, 0x74, 0xdf, 0xd2,
+	0x8b, 0xaa, 0x8e, 0xbf, 0xfd, 0x7f, 0x00, 0x63, 0xae, 0x41, 0xc8, 0x94, 0x06, 0x00, 0x00,
 }
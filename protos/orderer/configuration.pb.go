@@ -12,8 +12,33 @@ var _ = proto.Marshal
 var _ = fmt.Errorf
 var _ = math.Inf
 
+type ConsensusType_State int32
+
+const (
+	ConsensusType_STATE_NORMAL      ConsensusType_State = 0
+	ConsensusType_STATE_MAINTENANCE ConsensusType_State = 1
+)
+
+var ConsensusType_State_name = map[int32]string{
+	0: "STATE_NORMAL",
+	1: "STATE_MAINTENANCE",
+}
+var ConsensusType_State_value = map[string]int32{
+	"STATE_NORMAL":      0,
+	"STATE_MAINTENANCE": 1,
+}
+
+func (x ConsensusType_State) String() string {
+	return proto.EnumName(ConsensusType_State_name, int32(x))
+}
+func (ConsensusType_State) EnumDescriptor() ([]byte, []int) { return fileDescriptor1, []int{0, 0} }
+
 type ConsensusType struct {
 	Type string `protobuf:"bytes,1,opt,name=type" json:"type,omitempty"`
+	// Opaque metadata, whose content depends on the consensus type
+	Metadata []byte `protobuf:"bytes,2,opt,name=metadata,proto3" json:"metadata,omitempty"`
+	// The state of the channel with respect to consensus-type migration
+	State ConsensusType_State `protobuf:"varint,3,opt,name=state,enum=orderer.ConsensusType_State" json:"state,omitempty"`
 }
 
 func (m *ConsensusType) Reset()                    { *m = ConsensusType{} }
@@ -28,6 +53,20 @@ func (m *ConsensusType) GetType() string {
 	return ""
 }
 
+func (m *ConsensusType) GetMetadata() []byte {
+	if m != nil {
+		return m.Metadata
+	}
+	return nil
+}
+
+func (m *ConsensusType) GetState() ConsensusType_State {
+	if m != nil {
+		return m.State
+	}
+	return ConsensusType_STATE_NORMAL
+}
+
 type BatchSize struct {
 	// Simply specified as number of messages for now, in the future
 	// we may want to allow this to be specified by size in bytes
@@ -121,36 +160,63 @@ func (m *ChannelRestrictions) GetMaxCount() uint64 {
 	return 0
 }
 
+// MessageFilterRules names the rules, in addition to the standard ones, the messages
+// broadcast to the channel must pass, in the order they are applied
+type MessageFilterRules struct {
+	Rules []string `protobuf:"bytes,1,rep,name=rules" json:"rules,omitempty"`
+}
+
+func (m *MessageFilterRules) Reset()                    { *m = MessageFilterRules{} }
+func (m *MessageFilterRules) String() string            { return proto.CompactTextString(m) }
+func (*MessageFilterRules) ProtoMessage()               {}
+func (*MessageFilterRules) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{5} }
+
+func (m *MessageFilterRules) GetRules() []string {
+	if m != nil {
+		return m.Rules
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*ConsensusType)(nil), "orderer.ConsensusType")
 	proto.RegisterType((*BatchSize)(nil), "orderer.BatchSize")
 	proto.RegisterType((*BatchTimeout)(nil), "orderer.BatchTimeout")
 	proto.RegisterType((*KafkaBrokers)(nil), "orderer.KafkaBrokers")
 	proto.RegisterType((*ChannelRestrictions)(nil), "orderer.ChannelRestrictions")
+	proto.RegisterType((*MessageFilterRules)(nil), "orderer.MessageFilterRules")
+	proto.RegisterEnum("orderer.ConsensusType_State", ConsensusType_State_name, ConsensusType_State_value)
 }
 
 func init() { proto.RegisterFile("orderer/configuration.proto", fileDescriptor1) }
 
 var fileDescriptor1 = []byte{
-	// 313 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x4c, 0xd0, 0xcd, 0x4a, 0xc3, 0x40,
-	0x10, 0x07, 0x70, 0x62, 0x8b, 0xb5, 0x8b, 0x45, 0xbb, 0xbd, 0x04, 0x7a, 0x29, 0x11, 0xa1, 0x48,
-	0x49, 0x40, 0xdf, 0x20, 0x3d, 0x4a, 0x2f, 0xb1, 0x5e, 0xbc, 0x94, 0x4d, 0x3a, 0x49, 0x96, 0x36,
-	0x3b, 0x61, 0x76, 0x03, 0x89, 0xef, 0xe1, 0xfb, 0xca, 0x6e, 0x52, 0xed, 0x6d, 0x3e, 0x7e, 0x0b,
-	0xb3, 0x7f, 0xb6, 0x44, 0x3a, 0x02, 0x01, 0x45, 0x19, 0xaa, 0x5c, 0x16, 0x0d, 0x09, 0x23, 0x51,
-	0x85, 0x35, 0xa1, 0x41, 0x3e, 0x19, 0x96, 0xc1, 0x13, 0x9b, 0x6d, 0x51, 0x69, 0x50, 0xba, 0xd1,
-	0xfb, 0xae, 0x06, 0xce, 0xd9, 0xd8, 0x74, 0x35, 0xf8, 0xde, 0xca, 0x5b, 0x4f, 0x13, 0x57, 0x07,
-	0x3f, 0x1e, 0x9b, 0xc6, 0xc2, 0x64, 0xe5, 0x87, 0xfc, 0x06, 0xfe, 0xc2, 0xe6, 0x95, 0x68, 0x0f,
-	0x15, 0x68, 0x2d, 0x0a, 0x38, 0x64, 0xd8, 0x28, 0xe3, 0xf8, 0x2c, 0x79, 0xa8, 0x44, 0xbb, 0xeb,
-	0xe7, 0x5b, 0x3b, 0xe6, 0x1b, 0xc6, 0x45, 0xaa, 0xf1, 0xdc, 0x18, 0x38, 0xd8, 0x47, 0x69, 0x67,
-	0x40, 0xfb, 0x37, 0x0e, 0x3f, 0x5e, 0x36, 0x3b, 0xd1, 0xc6, 0x76, 0xce, 0x43, 0xb6, 0xa8, 0x09,
-	0x72, 0x20, 0x82, 0xe3, 0x15, 0x1f, 0x39, 0x3e, 0xff, 0x5b, 0x5d, 0x7c, 0xb0, 0x66, 0xf7, 0xee,
-	0xac, 0xbd, 0xac, 0x00, 0x1b, 0xc3, 0x7d, 0x36, 0x31, 0x7d, 0x39, 0x9c, 0x7f, 0x69, 0xad, 0x7c,
-	0x17, 0xf9, 0x49, 0xc4, 0x84, 0x27, 0x20, 0x6d, 0x65, 0xda, 0x97, 0xbe, 0xb7, 0x1a, 0x59, 0x39,
-	0xb4, 0xc1, 0x2b, 0x5b, 0x6c, 0x4b, 0xa1, 0x14, 0x9c, 0x13, 0xd0, 0x86, 0x64, 0x66, 0x53, 0xd3,
-	0x7c, 0xc9, 0xa6, 0xf6, 0xa0, 0xff, 0xcf, 0x8e, 0x93, 0xbb, 0x4a, 0xb4, 0xee, 0x97, 0xf1, 0x27,
-	0x7b, 0x46, 0x2a, 0xc2, 0xb2, 0xab, 0x81, 0xce, 0x70, 0x2c, 0x80, 0xc2, 0x5c, 0xa4, 0x24, 0xb3,
-	0x3e, 0x6d, 0x1d, 0x0e, 0x69, 0x7f, 0x6d, 0x0a, 0x69, 0xca, 0x26, 0x0d, 0x33, 0xac, 0xa2, 0x2b,
-	0x1d, 0xf5, 0x3a, 0xea, 0x75, 0x34, 0xe8, 0xf4, 0xd6, 0xf5, 0x6f, 0xbf, 0x01, 0x00, 0x00, 0xff,
-	0xff, 0x0c, 0x47, 0xa2, 0x55, 0xca, 0x01, 0x00, 0x00,
+	// 422 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x54, 0x92, 0xd1, 0x8a, 0xda, 0x40,
+	0x14, 0x86, 0x9b, 0xba, 0x76, 0xd7, 0x83, 0xb6, 0x3a, 0xdb, 0x42, 0xe8, 0xf6, 0x42, 0x02, 0x05,
+	0x59, 0x96, 0xa4, 0xd8, 0x27, 0x50, 0xb1, 0x50, 0x5a, 0x2d, 0x8c, 0xe9, 0x4d, 0x6f, 0x64, 0x12,
+	0x8f, 0x31, 0x6c, 0x92, 0x09, 0x67, 0x26, 0xa0, 0x7d, 0x8f, 0x3e, 0x42, 0xdf, 0xb3, 0xcc, 0x4c,
+	0xdc, 0xba, 0x77, 0xff, 0xff, 0x9f, 0x2f, 0x93, 0xf3, 0x67, 0x02, 0x77, 0x92, 0x76, 0x48, 0x48,
+	0x51, 0x2a, 0xab, 0x7d, 0x9e, 0x35, 0x24, 0x74, 0x2e, 0xab, 0xb0, 0x26, 0xa9, 0x25, 0xbb, 0x6e,
+	0x87, 0xc1, 0x5f, 0x0f, 0x06, 0x0b, 0x59, 0x29, 0xac, 0x54, 0xa3, 0xe2, 0x53, 0x8d, 0x8c, 0xc1,
+	0x95, 0x3e, 0xd5, 0xe8, 0x7b, 0x63, 0x6f, 0xd2, 0xe3, 0x56, 0xb3, 0xf7, 0x70, 0x53, 0xa2, 0x16,
+	0x3b, 0xa1, 0x85, 0xff, 0x72, 0xec, 0x4d, 0xfa, 0xfc, 0xc9, 0xb3, 0x29, 0x74, 0x95, 0x16, 0x1a,
+	0xfd, 0xce, 0xd8, 0x9b, 0xbc, 0x9e, 0x7e, 0x08, 0xdb, 0xa3, 0xc3, 0x67, 0xc7, 0x86, 0x1b, 0xc3,
+	0x70, 0x87, 0x06, 0x9f, 0xa0, 0x6b, 0x3d, 0x1b, 0x42, 0x7f, 0x13, 0xcf, 0xe2, 0xe5, 0x76, 0xfd,
+	0x83, 0xaf, 0x66, 0xdf, 0x87, 0x2f, 0xd8, 0x3b, 0x18, 0xb9, 0x64, 0x35, 0xfb, 0xba, 0x8e, 0x97,
+	0xeb, 0xd9, 0x7a, 0xb1, 0x1c, 0x7a, 0xc1, 0x1f, 0x0f, 0x7a, 0x73, 0xa1, 0xd3, 0xc3, 0x26, 0xff,
+	0x8d, 0xec, 0x1e, 0x46, 0xa5, 0x38, 0x6e, 0x4b, 0x54, 0x4a, 0x64, 0xb8, 0x4d, 0x65, 0x53, 0x69,
+	0xbb, 0xf0, 0x80, 0xbf, 0x29, 0xc5, 0x71, 0xe5, 0xf2, 0x85, 0x89, 0xd9, 0x03, 0x30, 0x91, 0x28,
+	0x59, 0x34, 0x1a, 0xb7, 0xe6, 0xa1, 0xe4, 0xa4, 0x51, 0xd9, 0x16, 0x03, 0x3e, 0x3c, 0x4f, 0x56,
+	0xe2, 0x38, 0x37, 0x39, 0x0b, 0xe1, 0xb6, 0x26, 0xdc, 0x23, 0x11, 0xee, 0x2e, 0xf0, 0x8e, 0xc5,
+	0x47, 0x4f, 0xa3, 0x33, 0x1f, 0x4c, 0xa0, 0x6f, 0xd7, 0x8a, 0xf3, 0x12, 0x65, 0xa3, 0x99, 0x0f,
+	0xd7, 0xda, 0xc9, 0xf6, 0x03, 0x9e, 0xad, 0x21, 0xbf, 0x89, 0xfd, 0xa3, 0x98, 0x93, 0x7c, 0x44,
+	0x52, 0x86, 0x4c, 0x9c, 0xf4, 0xbd, 0x71, 0xc7, 0x90, 0xad, 0x0d, 0xa6, 0x70, 0xbb, 0x38, 0x88,
+	0xaa, 0xc2, 0x82, 0xa3, 0xd2, 0x94, 0xa7, 0xe6, 0xe2, 0x14, 0xbb, 0x83, 0x9e, 0x59, 0xe8, 0x7f,
+	0xd9, 0x2b, 0x7e, 0x53, 0x8a, 0xa3, 0x6d, 0x19, 0xdc, 0x03, 0x6b, 0x5b, 0x7f, 0xc9, 0x0b, 0x8d,
+	0xc4, 0x9b, 0x02, 0x15, 0x7b, 0x0b, 0x5d, 0x32, 0xa2, 0x7d, 0x83, 0x33, 0xf3, 0x9f, 0xf0, 0x51,
+	0x52, 0x16, 0x1e, 0x4e, 0x35, 0x52, 0x81, 0xbb, 0x0c, 0x29, 0xdc, 0x8b, 0x84, 0xf2, 0xd4, 0xfd,
+	0x1c, 0xea, 0x7c, 0x83, 0xbf, 0x1e, 0xb2, 0x5c, 0x1f, 0x9a, 0x24, 0x4c, 0x65, 0x19, 0x5d, 0xd0,
+	0x91, 0xa3, 0x23, 0x47, 0x47, 0x2d, 0x9d, 0xbc, 0xb2, 0xfe, 0xf3, 0xbf, 0x01, 0x00, 0xca, 0x2e,
+	0x4a, 0x5a, 0x79, 0x02, 0x00, 0x00,
 }
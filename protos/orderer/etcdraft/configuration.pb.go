@@ -0,0 +1,193 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: orderer/etcdraft/configuration.proto
+
+/*
+Package etcdraft is a generated protocol buffer package.
+
+It is generated from these files:
+	orderer/etcdraft/configuration.proto
+	orderer/etcdraft/etcdraft.proto
+
+It has these top-level messages:
+	Metadata
+	Consenter
+	Options
+	RaftMetadata
+	StepRequest
+	StepResponse
+	SubmitRequest
+	SubmitResponse
+*/
+package etcdraft
+
+import proto "github.com/golang/protobuf/proto"
+import fmt "fmt"
+import math "math"
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
+
+// Metadata is serialized and set as the value of ConsensusType.Metadata in
+// a channel configuration when the ConsensusType.Type is set to "etcdraft".
+type Metadata struct {
+	Consenters []*Consenter `protobuf:"bytes,1,rep,name=consenters" json:"consenters,omitempty"`
+	Options    *Options     `protobuf:"bytes,2,opt,name=options" json:"options,omitempty"`
+}
+
+func (m *Metadata) Reset()                    { *m = Metadata{} }
+func (m *Metadata) String() string            { return proto.CompactTextString(m) }
+func (*Metadata) ProtoMessage()               {}
+func (*Metadata) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{0} }
+
+func (m *Metadata) GetConsenters() []*Consenter {
+	if m != nil {
+		return m.Consenters
+	}
+	return nil
+}
+
+func (m *Metadata) GetOptions() *Options {
+	if m != nil {
+		return m.Options
+	}
+	return nil
+}
+
+// Consenter represents a consenting node (i.e. replica).
+type Consenter struct {
+	Host          string `protobuf:"bytes,1,opt,name=host" json:"host,omitempty"`
+	Port          uint32 `protobuf:"varint,2,opt,name=port" json:"port,omitempty"`
+	ClientTlsCert []byte `protobuf:"bytes,3,opt,name=client_tls_cert,json=clientTlsCert,proto3" json:"client_tls_cert,omitempty"`
+	ServerTlsCert []byte `protobuf:"bytes,4,opt,name=server_tls_cert,json=serverTlsCert,proto3" json:"server_tls_cert,omitempty"`
+}
+
+func (m *Consenter) Reset()                    { *m = Consenter{} }
+func (m *Consenter) String() string            { return proto.CompactTextString(m) }
+func (*Consenter) ProtoMessage()               {}
+func (*Consenter) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{1} }
+
+func (m *Consenter) GetHost() string {
+	if m != nil {
+		return m.Host
+	}
+	return ""
+}
+
+func (m *Consenter) GetPort() uint32 {
+	if m != nil {
+		return m.Port
+	}
+	return 0
+}
+
+func (m *Consenter) GetClientTlsCert() []byte {
+	if m != nil {
+		return m.ClientTlsCert
+	}
+	return nil
+}
+
+func (m *Consenter) GetServerTlsCert() []byte {
+	if m != nil {
+		return m.ServerTlsCert
+	}
+	return nil
+}
+
+// Options to be specified for all the etcd/raft nodes of a channel.
+type Options struct {
+	// The duration of a raft tick, e.g. "500ms"
+	TickInterval string `protobuf:"bytes,1,opt,name=tick_interval,json=tickInterval" json:"tick_interval,omitempty"`
+	// The number of ticks without hearing from the leader after which a follower starts an election
+	ElectionTick uint32 `protobuf:"varint,2,opt,name=election_tick,json=electionTick" json:"election_tick,omitempty"`
+	// The number of ticks between the heartbeats of the leader
+	HeartbeatTick uint32 `protobuf:"varint,3,opt,name=heartbeat_tick,json=heartbeatTick" json:"heartbeat_tick,omitempty"`
+	// The number of blocks the leader may have in flight to each follower
+	MaxInflightBlocks uint32 `protobuf:"varint,4,opt,name=max_inflight_blocks,json=maxInflightBlocks" json:"max_inflight_blocks,omitempty"`
+	// The number of bytes of blocks written after which a snapshot is taken
+	SnapshotIntervalSize uint32 `protobuf:"varint,5,opt,name=snapshot_interval_size,json=snapshotIntervalSize" json:"snapshot_interval_size,omitempty"`
+}
+
+func (m *Options) Reset()                    { *m = Options{} }
+func (m *Options) String() string            { return proto.CompactTextString(m) }
+func (*Options) ProtoMessage()               {}
+func (*Options) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{2} }
+
+func (m *Options) GetTickInterval() string {
+	if m != nil {
+		return m.TickInterval
+	}
+	return ""
+}
+
+func (m *Options) GetElectionTick() uint32 {
+	if m != nil {
+		return m.ElectionTick
+	}
+	return 0
+}
+
+func (m *Options) GetHeartbeatTick() uint32 {
+	if m != nil {
+		return m.HeartbeatTick
+	}
+	return 0
+}
+
+func (m *Options) GetMaxInflightBlocks() uint32 {
+	if m != nil {
+		return m.MaxInflightBlocks
+	}
+	return 0
+}
+
+func (m *Options) GetSnapshotIntervalSize() uint32 {
+	if m != nil {
+		return m.SnapshotIntervalSize
+	}
+	return 0
+}
+
+func init() {
+	proto.RegisterType((*Metadata)(nil), "etcdraft.Metadata")
+	proto.RegisterType((*Consenter)(nil), "etcdraft.Consenter")
+	proto.RegisterType((*Options)(nil), "etcdraft.Options")
+}
+
+func init() { proto.RegisterFile("orderer/etcdraft/configuration.proto", fileDescriptor0) }
+
+var fileDescriptor0 = []byte{
+	// 382 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x5c, 0x92, 0x4f, 0x6b, 0xdc, 0x30,
+	0x14, 0xc4, 0x71, 0x37, 0x6d, 0x12, 0x65, 0xdd, 0x12, 0xa5, 0x14, 0x1f, 0xcd, 0xf6, 0x0f, 0x86,
+	0x82, 0x0c, 0x49, 0xfb, 0x05, 0x92, 0x53, 0x0e, 0xa5, 0xe0, 0xe6, 0xd4, 0x8b, 0x91, 0xe5, 0x67,
+	0x5b, 0xac, 0xd6, 0x32, 0x4f, 0x2f, 0x21, 0xdd, 0x6b, 0xbf, 0x68, 0x3f, 0x4a, 0xb1, 0x64, 0x7b,
+	0x97, 0xdc, 0xc4, 0xcc, 0x6f, 0x1e, 0x03, 0x1a, 0xf6, 0xc9, 0x62, 0x0d, 0x08, 0x98, 0x03, 0xa9,
+	0x1a, 0x65, 0x43, 0xb9, 0xb2, 0x7d, 0xa3, 0xdb, 0x47, 0x94, 0xa4, 0x6d, 0x2f, 0x06, 0xb4, 0x64,
+	0xf9, 0xd9, 0xec, 0x6e, 0x0c, 0x3b, 0xfb, 0x01, 0x24, 0x6b, 0x49, 0x92, 0xdf, 0x30, 0xa6, 0x6c,
+	0xef, 0xa0, 0x27, 0x40, 0x97, 0x44, 0xe9, 0x2a, 0xbb, 0xb8, 0xbe, 0x12, 0x33, 0x2a, 0xee, 0x66,
+	0xaf, 0x38, 0xc2, 0xf8, 0x57, 0x76, 0x6a, 0x87, 0xf1, 0xb4, 0x4b, 0x5e, 0xa5, 0x51, 0x76, 0x71,
+	0x7d, 0x79, 0x48, 0xfc, 0x0c, 0x46, 0x31, 0x13, 0x9b, 0xbf, 0x11, 0x3b, 0x5f, 0xce, 0x70, 0xce,
+	0x4e, 0x3a, 0xeb, 0x28, 0x89, 0xd2, 0x28, 0x3b, 0x2f, 0xfc, 0x7b, 0xd4, 0x06, 0x8b, 0xe4, 0x6f,
+	0xc5, 0x85, 0x7f, 0xf3, 0x2f, 0xec, 0x9d, 0x32, 0x1a, 0x7a, 0x2a, 0xc9, 0xb8, 0x52, 0x01, 0x52,
+	0xb2, 0x4a, 0xa3, 0x6c, 0x5d, 0xc4, 0x41, 0x7e, 0x30, 0xee, 0x0e, 0x02, 0xe7, 0x00, 0x9f, 0x00,
+	0x0f, 0xdc, 0x49, 0xe0, 0x82, 0x3c, 0x71, 0x9b, 0x7f, 0x11, 0x3b, 0x9d, 0xaa, 0xf1, 0x8f, 0x2c,
+	0x26, 0xad, 0xb6, 0xa5, 0x1e, 0x1b, 0x3d, 0x49, 0x33, 0x95, 0x59, 0x8f, 0xe2, 0xfd, 0xa4, 0x8d,
+	0x10, 0x18, 0x50, 0x63, 0xa2, 0x1c, 0x8d, 0xa9, 0xdd, 0x7a, 0x16, 0x1f, 0xb4, 0xda, 0xf2, 0xcf,
+	0xec, 0x6d, 0x07, 0x12, 0xa9, 0x02, 0x49, 0x81, 0x5a, 0x79, 0x2a, 0x5e, 0x54, 0x8f, 0x09, 0x76,
+	0xb5, 0x93, 0xcf, 0xa5, 0xee, 0x1b, 0xa3, 0xdb, 0x8e, 0xca, 0xca, 0x58, 0xb5, 0x75, 0xbe, 0x68,
+	0x5c, 0x5c, 0xee, 0xe4, 0xf3, 0xfd, 0xe4, 0xdc, 0x7a, 0x83, 0x7f, 0x63, 0x1f, 0x5c, 0x2f, 0x07,
+	0xd7, 0x59, 0x5a, 0x4a, 0x96, 0x4e, 0xef, 0x21, 0x79, 0xed, 0x23, 0xef, 0x67, 0x77, 0x6e, 0xfb,
+	0x4b, 0xef, 0xe1, 0xb6, 0x65, 0xc2, 0x62, 0x2b, 0xba, 0x3f, 0x03, 0xa0, 0x81, 0xba, 0x05, 0x14,
+	0x8d, 0xac, 0x50, 0xab, 0x30, 0x00, 0x27, 0xa6, 0x99, 0x2c, 0x7f, 0xf5, 0xfb, 0x7b, 0xab, 0xa9,
+	0x7b, 0xac, 0x84, 0xb2, 0xbb, 0xfc, 0x28, 0x96, 0x87, 0x58, 0x1e, 0x62, 0xf9, 0xcb, 0x75, 0x55,
+	0x6f, 0xbc, 0x71, 0xf3, 0x7f, 0x00, 0x86, 0x9a, 0xea, 0x39, 0x78, 0x02, 0x00, 0x00,
+}
@@ -0,0 +1,310 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: orderer/etcdraft/etcdraft.proto
+
+package etcdraft
+
+import proto "github.com/golang/protobuf/proto"
+import fmt "fmt"
+import math "math"
+import common "github.com/hyperledger/fabric/protos/common"
+
+import (
+	context "golang.org/x/net/context"
+	grpc "google.golang.org/grpc"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// RaftMetadata is the encoded value of the Metadata message stored in the
+// ORDERER block metadata index for the case of the etcd/raft-based orderer.
+type RaftMetadata struct {
+	// The consenters of the channel, by raft node id
+	Consenters map[uint64]*Consenter `protobuf:"bytes,1,rep,name=consenters" json:"consenters,omitempty" protobuf_key:"varint,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// The raft node id of the next consenter added to the channel
+	NextConsenterId uint64 `protobuf:"varint,2,opt,name=next_consenter_id,json=nextConsenterId" json:"next_consenter_id,omitempty"`
+	// The index of the raft entry the block was written from
+	RaftIndex uint64 `protobuf:"varint,3,opt,name=raft_index,json=raftIndex" json:"raft_index,omitempty"`
+}
+
+func (m *RaftMetadata) Reset()                    { *m = RaftMetadata{} }
+func (m *RaftMetadata) String() string            { return proto.CompactTextString(m) }
+func (*RaftMetadata) ProtoMessage()               {}
+func (*RaftMetadata) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{0} }
+
+func (m *RaftMetadata) GetConsenters() map[uint64]*Consenter {
+	if m != nil {
+		return m.Consenters
+	}
+	return nil
+}
+
+func (m *RaftMetadata) GetNextConsenterId() uint64 {
+	if m != nil {
+		return m.NextConsenterId
+	}
+	return 0
+}
+
+func (m *RaftMetadata) GetRaftIndex() uint64 {
+	if m != nil {
+		return m.RaftIndex
+	}
+	return 0
+}
+
+// StepRequest wraps a marshalled raftpb.Message.
+type StepRequest struct {
+	Channel string `protobuf:"bytes,1,opt,name=channel" json:"channel,omitempty"`
+	Payload []byte `protobuf:"bytes,2,opt,name=payload,proto3" json:"payload,omitempty"`
+}
+
+func (m *StepRequest) Reset()                    { *m = StepRequest{} }
+func (m *StepRequest) String() string            { return proto.CompactTextString(m) }
+func (*StepRequest) ProtoMessage()               {}
+func (*StepRequest) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{1} }
+
+func (m *StepRequest) GetChannel() string {
+	if m != nil {
+		return m.Channel
+	}
+	return ""
+}
+
+func (m *StepRequest) GetPayload() []byte {
+	if m != nil {
+		return m.Payload
+	}
+	return nil
+}
+
+type StepResponse struct {
+}
+
+func (m *StepResponse) Reset()                    { *m = StepResponse{} }
+func (m *StepResponse) String() string            { return proto.CompactTextString(m) }
+func (*StepResponse) ProtoMessage()               {}
+func (*StepResponse) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{2} }
+
+// SubmitRequest wraps a message to be ordered, along with the config
+// sequence it was validated against. For config messages, the config
+// update the config message was computed from is included.
+type SubmitRequest struct {
+	Channel           string           `protobuf:"bytes,1,opt,name=channel" json:"channel,omitempty"`
+	LastValidationSeq uint64           `protobuf:"varint,2,opt,name=last_validation_seq,json=lastValidationSeq" json:"last_validation_seq,omitempty"`
+	Content           *common.Envelope `protobuf:"bytes,3,opt,name=content" json:"content,omitempty"`
+	ConfigUpdate      *common.Envelope `protobuf:"bytes,4,opt,name=config_update,json=configUpdate" json:"config_update,omitempty"`
+}
+
+func (m *SubmitRequest) Reset()                    { *m = SubmitRequest{} }
+func (m *SubmitRequest) String() string            { return proto.CompactTextString(m) }
+func (*SubmitRequest) ProtoMessage()               {}
+func (*SubmitRequest) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{3} }
+
+func (m *SubmitRequest) GetChannel() string {
+	if m != nil {
+		return m.Channel
+	}
+	return ""
+}
+
+func (m *SubmitRequest) GetLastValidationSeq() uint64 {
+	if m != nil {
+		return m.LastValidationSeq
+	}
+	return 0
+}
+
+func (m *SubmitRequest) GetContent() *common.Envelope {
+	if m != nil {
+		return m.Content
+	}
+	return nil
+}
+
+func (m *SubmitRequest) GetConfigUpdate() *common.Envelope {
+	if m != nil {
+		return m.ConfigUpdate
+	}
+	return nil
+}
+
+type SubmitResponse struct {
+	Status common.Status `protobuf:"varint,1,opt,name=status,enum=common.Status" json:"status,omitempty"`
+	Info   string        `protobuf:"bytes,2,opt,name=info" json:"info,omitempty"`
+}
+
+func (m *SubmitResponse) Reset()                    { *m = SubmitResponse{} }
+func (m *SubmitResponse) String() string            { return proto.CompactTextString(m) }
+func (*SubmitResponse) ProtoMessage()               {}
+func (*SubmitResponse) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{4} }
+
+func (m *SubmitResponse) GetStatus() common.Status {
+	if m != nil {
+		return m.Status
+	}
+	return common.Status_UNKNOWN
+}
+
+func (m *SubmitResponse) GetInfo() string {
+	if m != nil {
+		return m.Info
+	}
+	return ""
+}
+
+func init() {
+	proto.RegisterType((*RaftMetadata)(nil), "etcdraft.RaftMetadata")
+	proto.RegisterType((*StepRequest)(nil), "etcdraft.StepRequest")
+	proto.RegisterType((*StepResponse)(nil), "etcdraft.StepResponse")
+	proto.RegisterType((*SubmitRequest)(nil), "etcdraft.SubmitRequest")
+	proto.RegisterType((*SubmitResponse)(nil), "etcdraft.SubmitResponse")
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// Client API for Cluster service
+
+type ClusterClient interface {
+	// Step passes a raft message to the chain of the channel on the receiving node.
+	Step(ctx context.Context, in *StepRequest, opts ...grpc.CallOption) (*StepResponse, error)
+	// Submit passes a message broadcast to a follower on to the leader for ordering.
+	Submit(ctx context.Context, in *SubmitRequest, opts ...grpc.CallOption) (*SubmitResponse, error)
+}
+
+type clusterClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewClusterClient(cc *grpc.ClientConn) ClusterClient {
+	return &clusterClient{cc}
+}
+
+func (c *clusterClient) Step(ctx context.Context, in *StepRequest, opts ...grpc.CallOption) (*StepResponse, error) {
+	out := new(StepResponse)
+	err := grpc.Invoke(ctx, "/etcdraft.Cluster/Step", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *clusterClient) Submit(ctx context.Context, in *SubmitRequest, opts ...grpc.CallOption) (*SubmitResponse, error) {
+	out := new(SubmitResponse)
+	err := grpc.Invoke(ctx, "/etcdraft.Cluster/Submit", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Server API for Cluster service
+
+type ClusterServer interface {
+	// Step passes a raft message to the chain of the channel on the receiving node.
+	Step(context.Context, *StepRequest) (*StepResponse, error)
+	// Submit passes a message broadcast to a follower on to the leader for ordering.
+	Submit(context.Context, *SubmitRequest) (*SubmitResponse, error)
+}
+
+func RegisterClusterServer(s *grpc.Server, srv ClusterServer) {
+	s.RegisterService(&_Cluster_serviceDesc, srv)
+}
+
+func _Cluster_Step_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StepRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ClusterServer).Step(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/etcdraft.Cluster/Step",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ClusterServer).Step(ctx, req.(*StepRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Cluster_Submit_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SubmitRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ClusterServer).Submit(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/etcdraft.Cluster/Submit",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ClusterServer).Submit(ctx, req.(*SubmitRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _Cluster_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "etcdraft.Cluster",
+	HandlerType: (*ClusterServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Step",
+			Handler:    _Cluster_Step_Handler,
+		},
+		{
+			MethodName: "Submit",
+			Handler:    _Cluster_Submit_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "orderer/etcdraft/etcdraft.proto",
+}
+
+func init() { proto.RegisterFile("orderer/etcdraft/etcdraft.proto", fileDescriptor1) }
+
+var fileDescriptor1 = []byte{
+	// 495 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x84, 0x53, 0xc1, 0x6a, 0xdb, 0x40,
+	0x10, 0x45, 0x89, 0x6b, 0xd7, 0x63, 0xc7, 0x49, 0xd6, 0xb4, 0x15, 0x86, 0xd2, 0x60, 0x4a, 0x48,
+	0x73, 0x90, 0xc0, 0xc5, 0x50, 0xda, 0x53, 0x1b, 0x52, 0x08, 0xb4, 0x97, 0x35, 0xed, 0xa1, 0x17,
+	0xb1, 0x96, 0xc6, 0xb6, 0xa8, 0xbc, 0x2b, 0xaf, 0x46, 0x26, 0x3e, 0xf4, 0xcf, 0xfa, 0x5b, 0xbd,
+	0x97, 0xdd, 0xb5, 0x14, 0xe1, 0x50, 0x7a, 0xd2, 0xce, 0xbc, 0xf7, 0x66, 0xde, 0xce, 0x68, 0xe1,
+	0x95, 0xd2, 0x09, 0x6a, 0xd4, 0x21, 0x52, 0x9c, 0x68, 0xb1, 0xa0, 0xfa, 0x10, 0xe4, 0x5a, 0x91,
+	0x62, 0x4f, 0xab, 0x78, 0x34, 0x8c, 0xd5, 0x7a, 0xad, 0x64, 0xe8, 0x3e, 0x0e, 0x1e, 0xbd, 0x7e,
+	0xa4, 0x8f, 0x95, 0x5c, 0xa4, 0xcb, 0x52, 0x0b, 0x4a, 0x2b, 0xd6, 0xf8, 0x8f, 0x07, 0x7d, 0x2e,
+	0x16, 0xf4, 0x15, 0x49, 0x24, 0x82, 0x04, 0xfb, 0x0c, 0x10, 0x2b, 0x59, 0xa0, 0x24, 0xd4, 0x85,
+	0xef, 0x5d, 0x1c, 0x5f, 0xf5, 0x26, 0x97, 0x41, 0xdd, 0xba, 0xc9, 0x0d, 0x6e, 0x6a, 0xe2, 0xad,
+	0x24, 0xbd, 0xe3, 0x0d, 0x25, 0xbb, 0x86, 0x73, 0x89, 0xf7, 0x14, 0xd5, 0xa9, 0x28, 0x4d, 0xfc,
+	0xa3, 0x0b, 0xef, 0xaa, 0xc5, 0x4f, 0x0d, 0x50, 0x6b, 0xef, 0x12, 0xf6, 0x12, 0xc0, 0x14, 0x8f,
+	0x52, 0x99, 0xe0, 0xbd, 0x7f, 0x6c, 0x49, 0x5d, 0x93, 0xb9, 0x33, 0x89, 0x11, 0x87, 0xd3, 0x83,
+	0x4e, 0xec, 0x0c, 0x8e, 0x7f, 0xe2, 0xce, 0xf7, 0x2c, 0xd5, 0x1c, 0xd9, 0x1b, 0x78, 0xb2, 0x15,
+	0x59, 0x89, 0xb6, 0x47, 0x6f, 0x32, 0x7c, 0xb0, 0x5c, 0x6b, 0xb9, 0x63, 0xbc, 0x3f, 0x7a, 0xe7,
+	0x8d, 0x3f, 0x42, 0x6f, 0x46, 0x98, 0x73, 0xdc, 0x94, 0x58, 0x10, 0xf3, 0xa1, 0x13, 0xaf, 0x84,
+	0x94, 0x98, 0xd9, 0x9a, 0x5d, 0x5e, 0x85, 0x06, 0xc9, 0xc5, 0x2e, 0x53, 0xc2, 0xb9, 0xef, 0xf3,
+	0x2a, 0x1c, 0x0f, 0xa0, 0xef, 0x4a, 0x14, 0xb9, 0xe9, 0x30, 0xfe, 0xed, 0xc1, 0xc9, 0xac, 0x9c,
+	0xaf, 0x53, 0xfa, 0x7f, 0xd5, 0x00, 0x86, 0x99, 0x28, 0x28, 0xda, 0x8a, 0x2c, 0x4d, 0xec, 0x3e,
+	0xa2, 0x02, 0x37, 0xfb, 0xf9, 0x9c, 0x1b, 0xe8, 0x7b, 0x8d, 0xcc, 0x70, 0xc3, 0xae, 0xa1, 0x13,
+	0x2b, 0x49, 0x28, 0xc9, 0x8e, 0xa7, 0x37, 0x39, 0x0b, 0xf6, 0xcb, 0xbe, 0x95, 0x5b, 0xcc, 0x54,
+	0x8e, 0xbc, 0x22, 0xb0, 0x29, 0x9c, 0xb8, 0x4d, 0x47, 0x65, 0x9e, 0x08, 0x42, 0xbf, 0xf5, 0x0f,
+	0x45, 0xdf, 0xd1, 0xbe, 0x59, 0xd6, 0xf8, 0x0b, 0x0c, 0x2a, 0xf7, 0xee, 0x42, 0xec, 0x12, 0xda,
+	0x05, 0x09, 0x2a, 0x0b, 0xeb, 0x7e, 0x30, 0x19, 0x54, 0x15, 0x66, 0x36, 0xcb, 0xf7, 0x28, 0x63,
+	0xd0, 0x4a, 0xe5, 0x42, 0x59, 0xf7, 0x5d, 0x6e, 0xcf, 0x93, 0x5f, 0xd0, 0xb9, 0xc9, 0xca, 0x82,
+	0x50, 0xb3, 0x29, 0xb4, 0xcc, 0x9c, 0xd8, 0xb3, 0x87, 0x95, 0x34, 0x46, 0x3f, 0x7a, 0x7e, 0x98,
+	0xde, 0x77, 0xff, 0x00, 0x6d, 0xe7, 0x87, 0xbd, 0x68, 0x30, 0x9a, 0xf3, 0x1d, 0xf9, 0x8f, 0x01,
+	0x27, 0xfe, 0xb4, 0x84, 0x40, 0xe9, 0x65, 0xb0, 0xda, 0xe5, 0xa8, 0x33, 0x4c, 0x96, 0xa8, 0x83,
+	0x85, 0x98, 0xeb, 0x34, 0x76, 0xbf, 0x7d, 0x11, 0xec, 0x1f, 0x47, 0x5d, 0xe0, 0xc7, 0x74, 0x99,
+	0xd2, 0xaa, 0x9c, 0x9b, 0x2b, 0x86, 0x0d, 0x59, 0xe8, 0x64, 0xa1, 0x93, 0x85, 0x87, 0x6f, 0x6a,
+	0xde, 0xb6, 0xc0, 0xdb, 0xbf, 0x03, 0x00, 0xb3, 0xda, 0x4c, 0x88, 0xae, 0x03, 0x00, 0x00,
+}
@@ -240,36 +240,69 @@ func (m *KafkaMetadata) GetLastOffsetPersisted() int64 {
 	return 0
 }
 
+// KafkaCheckpoint is persisted by the Kafka-based orderer outside of the
+// ledger. It records the last offset of the partition of a channel whose
+// messages were all either written to blocks or discarded, so that the
+// orderer resumes consuming the partition past it after a restart even when
+// no block was cut since.
+type KafkaCheckpoint struct {
+	LastOffsetProcessed int64  `protobuf:"varint,1,opt,name=last_offset_processed,json=lastOffsetProcessed" json:"last_offset_processed,omitempty"`
+	LastBlockNumber     uint64 `protobuf:"varint,2,opt,name=last_block_number,json=lastBlockNumber" json:"last_block_number,omitempty"`
+}
+
+func (m *KafkaCheckpoint) Reset()                    { *m = KafkaCheckpoint{} }
+func (m *KafkaCheckpoint) String() string            { return proto.CompactTextString(m) }
+func (*KafkaCheckpoint) ProtoMessage()               {}
+func (*KafkaCheckpoint) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{5} }
+
+func (m *KafkaCheckpoint) GetLastOffsetProcessed() int64 {
+	if m != nil {
+		return m.LastOffsetProcessed
+	}
+	return 0
+}
+
+func (m *KafkaCheckpoint) GetLastBlockNumber() uint64 {
+	if m != nil {
+		return m.LastBlockNumber
+	}
+	return 0
+}
+
 func init() {
 	proto.RegisterType((*KafkaMessage)(nil), "orderer.KafkaMessage")
 	proto.RegisterType((*KafkaMessageRegular)(nil), "orderer.KafkaMessageRegular")
 	proto.RegisterType((*KafkaMessageTimeToCut)(nil), "orderer.KafkaMessageTimeToCut")
 	proto.RegisterType((*KafkaMessageConnect)(nil), "orderer.KafkaMessageConnect")
 	proto.RegisterType((*KafkaMetadata)(nil), "orderer.KafkaMetadata")
+	proto.RegisterType((*KafkaCheckpoint)(nil), "orderer.KafkaCheckpoint")
 }
 
 func init() { proto.RegisterFile("orderer/kafka.proto", fileDescriptor2) }
 
 var fileDescriptor2 = []byte{
-	// 316 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x7c, 0x91, 0x4f, 0x6b, 0xc2, 0x40,
-	0x10, 0xc5, 0xb5, 0x8a, 0xd2, 0xd1, 0x5e, 0x22, 0x42, 0x0e, 0xa5, 0xb4, 0x42, 0xa1, 0x87, 0x92,
-	0x80, 0xbd, 0x94, 0x9e, 0x8a, 0x5e, 0x84, 0xd2, 0x3f, 0x2c, 0xf6, 0xd2, 0x4b, 0xd8, 0x6c, 0x26,
-	0x31, 0x98, 0xb8, 0x61, 0x77, 0x72, 0xf0, 0x3b, 0xf6, 0x43, 0x95, 0xec, 0x6e, 0x40, 0x4a, 0xf0,
-	0x38, 0xf3, 0x7e, 0x8f, 0xf7, 0x76, 0x16, 0x66, 0x52, 0x25, 0xa8, 0x50, 0x85, 0x7b, 0x9e, 0xee,
-	0x79, 0x50, 0x29, 0x49, 0xd2, 0x1b, 0xbb, 0xe5, 0xe2, 0xb7, 0x0f, 0xd3, 0xb7, 0x46, 0x78, 0x47,
-	0xad, 0x79, 0x86, 0xde, 0x33, 0x8c, 0x15, 0x66, 0x75, 0xc1, 0x95, 0xdf, 0xbf, 0xed, 0x3f, 0x4c,
-	0x96, 0xd7, 0x81, 0x63, 0x83, 0x53, 0x8e, 0x59, 0x66, 0xd3, 0x63, 0x2d, 0xee, 0xbd, 0xc2, 0x84,
-	0xf2, 0x12, 0x23, 0x92, 0x91, 0xa8, 0xc9, 0xbf, 0x30, 0xee, 0x9b, 0x4e, 0xf7, 0x36, 0x2f, 0x71,
-	0x2b, 0xd7, 0x35, 0x6d, 0x7a, 0xec, 0x92, 0xda, 0xa1, 0xc9, 0x16, 0xf2, 0x70, 0x40, 0x41, 0xfe,
-	0xe0, 0x4c, 0xf6, 0xda, 0x32, 0x4d, 0xb6, 0xc3, 0x57, 0x23, 0x18, 0x6e, 0x8f, 0x15, 0x2e, 0x42,
-	0x98, 0x75, 0xb4, 0xf4, 0x7c, 0x18, 0x57, 0xfc, 0x58, 0x48, 0x9e, 0x98, 0x47, 0x4d, 0x59, 0x3b,
-	0x2e, 0x5e, 0x60, 0xde, 0x59, 0xcc, 0xbb, 0x83, 0x69, 0x5c, 0x48, 0xb1, 0x8f, 0x0e, 0x75, 0x19,
-	0xa3, 0x3d, 0xc6, 0x90, 0x4d, 0xcc, 0xee, 0xc3, 0xac, 0xfe, 0x87, 0xb9, 0x5a, 0x67, 0xc2, 0xd6,
-	0x70, 0xe5, 0x0c, 0xc4, 0x13, 0x4e, 0xdc, 0x5b, 0xc2, 0xbc, 0xe0, 0x9a, 0x22, 0x99, 0xa6, 0x1a,
-	0x29, 0xaa, 0x50, 0xe9, 0x5c, 0x13, 0x5a, 0xe3, 0x80, 0xcd, 0x1a, 0xf1, 0xd3, 0x68, 0x5f, 0xad,
-	0xb4, 0xfa, 0x86, 0x7b, 0xa9, 0xb2, 0x60, 0x77, 0xac, 0x50, 0x15, 0x98, 0x64, 0xa8, 0x82, 0x94,
-	0xc7, 0x2a, 0x17, 0xf6, 0x6b, 0x75, 0x7b, 0xb2, 0x9f, 0xc7, 0x2c, 0xa7, 0x5d, 0x1d, 0x07, 0x42,
-	0x96, 0xe1, 0x09, 0x1d, 0x5a, 0x3a, 0xb4, 0x74, 0xe8, 0xe8, 0x78, 0x64, 0xe6, 0xa7, 0xbf, 0x00,
-	0x00, 0x00, 0xff, 0xff, 0x15, 0x78, 0x4d, 0xd8, 0x2f, 0x02, 0x00, 0x00,
+	// 353 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x7c, 0x92, 0x4d, 0x4b, 0xfb, 0x40,
+	0x10, 0xc6, 0xfb, 0x46, 0xcb, 0x7f, 0xdb, 0x3f, 0xc5, 0x94, 0x42, 0x0e, 0x22, 0x1a, 0x10, 0x44,
+	0x24, 0x81, 0x7a, 0x11, 0x4f, 0xd2, 0x5c, 0x0a, 0xe2, 0x0b, 0xa1, 0x5e, 0xbc, 0x84, 0xcd, 0x66,
+	0x92, 0x86, 0xbc, 0x6c, 0xdc, 0x9d, 0x1c, 0xfa, 0x1d, 0xfd, 0x50, 0x92, 0xdd, 0x04, 0xab, 0x86,
+	0x1e, 0x67, 0x9e, 0xdf, 0x33, 0xcf, 0x4c, 0xb2, 0x64, 0xc1, 0x45, 0x08, 0x02, 0x84, 0x93, 0xd2,
+	0x28, 0xa5, 0x76, 0x29, 0x38, 0x72, 0x63, 0xd2, 0x34, 0xad, 0xcf, 0x3e, 0x99, 0x3d, 0xd6, 0xc2,
+	0x13, 0x48, 0x49, 0x63, 0x30, 0xee, 0xc8, 0x44, 0x40, 0x5c, 0x65, 0x54, 0x98, 0xfd, 0xf3, 0xfe,
+	0xd5, 0x74, 0x75, 0x6a, 0x37, 0xac, 0x7d, 0xc8, 0x79, 0x9a, 0xd9, 0xf4, 0xbc, 0x16, 0x37, 0x1e,
+	0xc8, 0x14, 0x93, 0x1c, 0x7c, 0xe4, 0x3e, 0xab, 0xd0, 0x1c, 0x28, 0xf7, 0x59, 0xa7, 0x7b, 0x9b,
+	0xe4, 0xb0, 0xe5, 0x6e, 0x85, 0x9b, 0x9e, 0xf7, 0x0f, 0xdb, 0xa2, 0xce, 0x66, 0xbc, 0x28, 0x80,
+	0xa1, 0x39, 0x3c, 0x92, 0xed, 0x6a, 0xa6, 0xce, 0x6e, 0xf0, 0xf5, 0x98, 0x8c, 0xb6, 0xfb, 0x12,
+	0x2c, 0x87, 0x2c, 0x3a, 0xb6, 0x34, 0x4c, 0x32, 0x29, 0xe9, 0x3e, 0xe3, 0x34, 0x54, 0x47, 0xcd,
+	0xbc, 0xb6, 0xb4, 0xee, 0xc9, 0xb2, 0x73, 0x31, 0xe3, 0x82, 0xcc, 0x82, 0x8c, 0xb3, 0xd4, 0x2f,
+	0xaa, 0x3c, 0x00, 0xfd, 0x31, 0x46, 0xde, 0x54, 0xf5, 0x9e, 0x55, 0xeb, 0x77, 0x58, 0xb3, 0xd6,
+	0x91, 0x30, 0x97, 0xfc, 0x6f, 0x0c, 0x48, 0x43, 0x8a, 0xd4, 0x58, 0x91, 0x65, 0x46, 0x25, 0xfa,
+	0x3c, 0x8a, 0x24, 0xa0, 0x5f, 0x82, 0x90, 0x89, 0x44, 0xd0, 0xc6, 0xa1, 0xb7, 0xa8, 0xc5, 0x17,
+	0xa5, 0xbd, 0xb6, 0x92, 0xf5, 0x41, 0xe6, 0x6a, 0x88, 0xbb, 0x03, 0x96, 0x96, 0x3c, 0x29, 0xf0,
+	0xcf, 0x18, 0xc1, 0x19, 0x48, 0xd9, 0x39, 0xa6, 0x95, 0x8c, 0x6b, 0x72, 0xa2, 0x3c, 0x3f, 0x8e,
+	0x1c, 0xa8, 0x23, 0xe7, 0xb5, 0xb0, 0xfe, 0x3e, 0x74, 0xfd, 0x46, 0x2e, 0xb9, 0x88, 0xed, 0xdd,
+	0xbe, 0x04, 0x91, 0x41, 0x18, 0x83, 0xb0, 0x23, 0x1a, 0x88, 0x84, 0xe9, 0xd7, 0x24, 0xdb, 0xbf,
+	0xf4, 0x7e, 0x13, 0x27, 0xb8, 0xab, 0x02, 0x9b, 0xf1, 0xdc, 0x39, 0xa0, 0x1d, 0x4d, 0x3b, 0x9a,
+	0x76, 0x1a, 0x3a, 0x18, 0xab, 0xfa, 0xf6, 0x6b, 0x00, 0x71, 0x85, 0x78, 0x94, 0xa2, 0x02, 0x00,
+	0x00,
 }
@@ -32,14 +32,17 @@ It has these top-level messages:
 	ChaincodeMessage
 	PutStateInfo
 	GetStateByRange
+	GetStateByPartialCompositeKeyWithPagination
 	GetQueryResult
 	GetHistoryForKey
 	QueryStateNext
 	QueryStateClose
 	QueryResultBytes
 	QueryResponse
+	QueryResponseMetadata
 	AnchorPeers
 	AnchorPeer
+	GossipConfig
 	ChaincodeReg
 	Interest
 	Register
@@ -47,6 +50,11 @@ It has these top-level messages:
 	Unregister
 	SignedEvent
 	Event
+	ChaincodeEventsRequest
+	ChaincodeBlockEvents
+	ChaincodeEventsResponse
+	TxStatusRequest
+	TxStatusResponse
 	PeerID
 	PeerEndpoint
 	SignedProposal
@@ -70,6 +78,8 @@ It has these top-level messages:
 	TransactionAction
 	ChaincodeActionPayload
 	ChaincodeEndorsedAction
+	TxValidationReasons
+	TxValidationReason
 */
 package peer
 
@@ -215,6 +225,8 @@ type AdminClient interface {
 	GetModuleLogLevel(ctx context.Context, in *LogLevelRequest, opts ...grpc.CallOption) (*LogLevelResponse, error)
 	SetModuleLogLevel(ctx context.Context, in *LogLevelRequest, opts ...grpc.CallOption) (*LogLevelResponse, error)
 	RevertLogLevels(ctx context.Context, in *google_protobuf.Empty, opts ...grpc.CallOption) (*google_protobuf.Empty, error)
+	// Reload the local MSP folder so that updated CRLs and intermediate certificates take effect
+	RefreshLocalMsp(ctx context.Context, in *google_protobuf.Empty, opts ...grpc.CallOption) (*google_protobuf.Empty, error)
 }
 
 type adminClient struct {
@@ -270,6 +282,15 @@ func (c *adminClient) RevertLogLevels(ctx context.Context, in *google_protobuf.E
 	return out, nil
 }
 
+func (c *adminClient) RefreshLocalMsp(ctx context.Context, in *google_protobuf.Empty, opts ...grpc.CallOption) (*google_protobuf.Empty, error) {
+	out := new(google_protobuf.Empty)
+	err := grpc.Invoke(ctx, "/protos.Admin/RefreshLocalMsp", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // Server API for Admin service
 
 type AdminServer interface {
@@ -279,6 +300,8 @@ type AdminServer interface {
 	GetModuleLogLevel(context.Context, *LogLevelRequest) (*LogLevelResponse, error)
 	SetModuleLogLevel(context.Context, *LogLevelRequest) (*LogLevelResponse, error)
 	RevertLogLevels(context.Context, *google_protobuf.Empty) (*google_protobuf.Empty, error)
+	// Reload the local MSP folder so that updated CRLs and intermediate certificates take effect
+	RefreshLocalMsp(context.Context, *google_protobuf.Empty) (*google_protobuf.Empty, error)
 }
 
 func RegisterAdminServer(s *grpc.Server, srv AdminServer) {
@@ -375,6 +398,24 @@ func _Admin_RevertLogLevels_Handler(srv interface{}, ctx context.Context, dec fu
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Admin_RefreshLocalMsp_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(google_protobuf.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).RefreshLocalMsp(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protos.Admin/RefreshLocalMsp",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).RefreshLocalMsp(ctx, req.(*google_protobuf.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _Admin_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "protos.Admin",
 	HandlerType: (*AdminServer)(nil),
@@ -399,6 +440,10 @@ var _Admin_serviceDesc = grpc.ServiceDesc{
 			MethodName: "RevertLogLevels",
 			Handler:    _Admin_RevertLogLevels_Handler,
 		},
+		{
+			MethodName: "RefreshLocalMsp",
+			Handler:    _Admin_RefreshLocalMsp_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "peer/admin.proto",
@@ -407,32 +452,32 @@ var _Admin_serviceDesc = grpc.ServiceDesc{
 func init() { proto.RegisterFile("peer/admin.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 418 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0xac, 0x93, 0x41, 0x6f, 0xd3, 0x30,
-	0x14, 0xc7, 0x9b, 0x42, 0x0b, 0x79, 0x1b, 0xcc, 0x58, 0x08, 0xaa, 0x4e, 0x08, 0x94, 0x13, 0x5c,
-	0x1c, 0x69, 0x1c, 0x38, 0x20, 0x0e, 0xdd, 0x12, 0x06, 0x62, 0x4b, 0x23, 0x67, 0x15, 0x02, 0x09,
-	0x4d, 0x49, 0xf3, 0xe6, 0x55, 0x38, 0x73, 0xb0, 0x9d, 0x4a, 0xfb, 0x3a, 0x7c, 0x2e, 0x3e, 0x0c,
-	0x4a, 0xdc, 0x68, 0x13, 0xb0, 0x03, 0x82, 0x93, 0xf3, 0xde, 0xfb, 0xff, 0xff, 0x71, 0x7e, 0xb1,
-	0x81, 0xd4, 0x88, 0x3a, 0xcc, 0xcb, 0x6a, 0x75, 0xc1, 0x6a, 0xad, 0xac, 0xa2, 0xe3, 0x6e, 0x31,
-	0xd3, 0x5d, 0xa1, 0x94, 0x90, 0x18, 0x76, 0x65, 0xd1, 0x9c, 0x85, 0x58, 0xd5, 0xf6, 0xd2, 0x89,
-	0x82, 0xef, 0x1e, 0x6c, 0x67, 0xa8, 0xd7, 0xa8, 0x33, 0x9b, 0xdb, 0xc6, 0xd0, 0x57, 0x30, 0x36,
-	0xdd, 0xd3, 0xc4, 0x7b, 0xe6, 0x3d, 0xbf, 0xbf, 0xf7, 0xd4, 0x09, 0x0d, 0xbb, 0xae, 0x62, 0x6e,
-	0x39, 0x50, 0x25, 0xf2, 0x8d, 0x3c, 0xf8, 0x04, 0x70, 0xd5, 0xa5, 0xf7, 0xc0, 0x5f, 0x24, 0x51,
-	0xfc, 0xf6, 0x7d, 0x12, 0x47, 0x64, 0x40, 0xb7, 0xe0, 0x4e, 0x76, 0x32, 0xe3, 0x27, 0x71, 0x44,
-	0x3c, 0x57, 0xcc, 0xd3, 0x34, 0x8e, 0xc8, 0x90, 0x02, 0x8c, 0xd3, 0xd9, 0x22, 0x8b, 0x23, 0x72,
-	0x8b, 0xfa, 0x30, 0x8a, 0x39, 0x9f, 0x73, 0x72, 0xbb, 0xd5, 0x2c, 0x92, 0x0f, 0xc9, 0xfc, 0x63,
-	0x42, 0x46, 0xc1, 0x31, 0xec, 0x1c, 0x29, 0x71, 0x84, 0x6b, 0x94, 0x1c, 0xbf, 0x35, 0x68, 0x2c,
-	0x7d, 0x02, 0x20, 0x95, 0x38, 0xad, 0x54, 0xd9, 0x48, 0xec, 0xb6, 0xea, 0x73, 0x5f, 0x2a, 0x71,
-	0xdc, 0x35, 0xe8, 0x2e, 0xb4, 0xc5, 0xa9, 0x6c, 0x2d, 0x93, 0x61, 0x37, 0xbd, 0x2b, 0x37, 0x11,
-	0x41, 0x02, 0xe4, 0x2a, 0xce, 0xd4, 0xea, 0xc2, 0xe0, 0xbf, 0xe4, 0xed, 0xfd, 0x18, 0xc2, 0x68,
-	0xd6, 0x82, 0xa7, 0xaf, 0xc1, 0x3f, 0x44, 0xbb, 0x21, 0xf9, 0x88, 0x39, 0xf0, 0xac, 0x07, 0xcf,
-	0xe2, 0x16, 0xfc, 0xf4, 0xe1, 0x9f, 0x88, 0x06, 0x03, 0xfa, 0x06, 0xb6, 0x32, 0x9b, 0x6b, 0xeb,
-	0xda, 0x7f, 0x6d, 0x7f, 0x07, 0x0f, 0x0e, 0xd1, 0xba, 0xfd, 0xf6, 0x9f, 0x47, 0x1f, 0xf7, 0xe2,
-	0x5f, 0xf8, 0x4d, 0x27, 0xbf, 0x0f, 0x1c, 0x09, 0x97, 0x94, 0xfd, 0x9f, 0xa4, 0x03, 0xd8, 0xe1,
-	0xb8, 0x46, 0x6d, 0xfb, 0xd9, 0xcd, 0x54, 0x6e, 0xe8, 0x07, 0x83, 0xfd, 0x2f, 0x10, 0x28, 0x2d,
-	0xd8, 0xf9, 0x65, 0x8d, 0x5a, 0x62, 0x29, 0x50, 0xb3, 0xb3, 0xbc, 0xd0, 0xab, 0x65, 0xff, 0xe2,
-	0xf6, 0xe4, 0xef, 0x6f, 0x77, 0x7f, 0x20, 0xcd, 0x97, 0x5f, 0x73, 0x81, 0x9f, 0x5f, 0x88, 0x95,
-	0x3d, 0x6f, 0x0a, 0xb6, 0x54, 0x55, 0x78, 0xcd, 0x18, 0x3a, 0xa3, 0xbb, 0x0a, 0x26, 0x6c, 0x8d,
-	0x85, 0xbb, 0x26, 0x2f, 0x7f, 0x06, 0x00, 0x00, 0xff, 0xff, 0x6e, 0xe6, 0xef, 0xb7, 0x41, 0x03,
-	0x00, 0x00,
+	// 425 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x93, 0xc1, 0x6f, 0xd3, 0x30,
+	0x14, 0xc6, 0xd7, 0x8d, 0x16, 0xf2, 0x36, 0x98, 0xb1, 0x10, 0x54, 0x9d, 0x10, 0x28, 0x27, 0xb8,
+	0x38, 0xd2, 0x38, 0x70, 0x40, 0x1c, 0xba, 0x25, 0x0c, 0x44, 0x9b, 0x56, 0xce, 0x2a, 0x04, 0x12,
+	0x9a, 0xd2, 0xe4, 0xd5, 0xad, 0x70, 0xe6, 0x60, 0x3b, 0x95, 0xf6, 0xe7, 0xc0, 0x5f, 0x8a, 0x12,
+	0x37, 0xda, 0x04, 0xec, 0x50, 0xc1, 0xc9, 0x79, 0xef, 0x7d, 0xdf, 0xa7, 0x97, 0x9f, 0x65, 0x20,
+	0x25, 0xa2, 0x0e, 0xd2, 0xbc, 0x58, 0x5d, 0xb2, 0x52, 0x2b, 0xab, 0x68, 0xaf, 0x39, 0xcc, 0xe0,
+	0x48, 0x28, 0x25, 0x24, 0x06, 0x4d, 0x39, 0xaf, 0x16, 0x01, 0x16, 0xa5, 0xbd, 0x72, 0x22, 0xff,
+	0x67, 0x07, 0x0e, 0x12, 0xd4, 0x6b, 0xd4, 0x89, 0x4d, 0x6d, 0x65, 0xe8, 0x6b, 0xe8, 0x99, 0xe6,
+	0xab, 0xdf, 0x79, 0xde, 0x79, 0xf1, 0xe0, 0xf8, 0x99, 0x13, 0x1a, 0x76, 0x53, 0xc5, 0xdc, 0x71,
+	0xaa, 0x72, 0xe4, 0x1b, 0xb9, 0xff, 0x19, 0xe0, 0xba, 0x4b, 0xef, 0x83, 0x37, 0x8b, 0xc3, 0xe8,
+	0xdd, 0x87, 0x38, 0x0a, 0xc9, 0x0e, 0xdd, 0x87, 0xbb, 0xc9, 0xf9, 0x90, 0x9f, 0x47, 0x21, 0xe9,
+	0xb8, 0x62, 0x32, 0x9d, 0x46, 0x21, 0xd9, 0xa5, 0x00, 0xbd, 0xe9, 0x70, 0x96, 0x44, 0x21, 0xd9,
+	0xa3, 0x1e, 0x74, 0x23, 0xce, 0x27, 0x9c, 0xdc, 0xa9, 0x35, 0xb3, 0xf8, 0x63, 0x3c, 0xf9, 0x14,
+	0x93, 0xae, 0x3f, 0x86, 0xc3, 0x91, 0x12, 0x23, 0x5c, 0xa3, 0xe4, 0xf8, 0xbd, 0x42, 0x63, 0xe9,
+	0x53, 0x00, 0xa9, 0xc4, 0x45, 0xa1, 0xf2, 0x4a, 0x62, 0xb3, 0xaa, 0xc7, 0x3d, 0xa9, 0xc4, 0xb8,
+	0x69, 0xd0, 0x23, 0xa8, 0x8b, 0x0b, 0x59, 0x5b, 0xfa, 0xbb, 0xcd, 0xf4, 0x9e, 0xdc, 0x44, 0xf8,
+	0x31, 0x90, 0xeb, 0x38, 0x53, 0xaa, 0x4b, 0x83, 0xff, 0x92, 0x77, 0xfc, 0x63, 0x0f, 0xba, 0xc3,
+	0x1a, 0x3c, 0x7d, 0x03, 0xde, 0x19, 0xda, 0x0d, 0xc9, 0xc7, 0xcc, 0x81, 0x67, 0x2d, 0x78, 0x16,
+	0xd5, 0xe0, 0x07, 0x8f, 0xfe, 0x46, 0xd4, 0xdf, 0xa1, 0x6f, 0x61, 0x3f, 0xb1, 0xa9, 0xb6, 0xae,
+	0xbd, 0xb5, 0xfd, 0x3d, 0x3c, 0x3c, 0x43, 0xeb, 0xf6, 0x6d, 0x7f, 0x8f, 0x3e, 0x69, 0xc5, 0xbf,
+	0xf1, 0x1b, 0xf4, 0xff, 0x1c, 0x38, 0x12, 0x2e, 0x29, 0xf9, 0x3f, 0x49, 0xa7, 0x70, 0xc8, 0x71,
+	0x8d, 0xda, 0xb6, 0xb3, 0xdb, 0xa9, 0xdc, 0xd2, 0x6f, 0x43, 0x16, 0x1a, 0xcd, 0x72, 0xa4, 0xb2,
+	0x54, 0x8e, 0x4d, 0xb9, 0x7d, 0xc8, 0xc9, 0x57, 0xf0, 0x95, 0x16, 0x6c, 0x79, 0x55, 0xa2, 0x96,
+	0x98, 0x0b, 0xd4, 0x6c, 0x91, 0xce, 0xf5, 0x2a, 0x6b, 0xb7, 0x2f, 0x11, 0xf5, 0xc9, 0x41, 0x73,
+	0x8d, 0xd3, 0x34, 0xfb, 0x96, 0x0a, 0xfc, 0xf2, 0x52, 0xac, 0xec, 0xb2, 0x9a, 0xb3, 0x4c, 0x15,
+	0xc1, 0x0d, 0x63, 0xe0, 0x8c, 0xee, 0x3d, 0x99, 0xa0, 0x36, 0xce, 0xdd, 0x5b, 0x7b, 0xf5, 0x6b,
+	0x00, 0xe1, 0x7b, 0x1c, 0x3c, 0x86, 0x03, 0x00, 0x00,
 }
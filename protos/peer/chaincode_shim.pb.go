@@ -21,25 +21,26 @@ var _ = math.Inf
 type ChaincodeMessage_Type int32
 
 const (
-	ChaincodeMessage_UNDEFINED           ChaincodeMessage_Type = 0
-	ChaincodeMessage_REGISTER            ChaincodeMessage_Type = 1
-	ChaincodeMessage_REGISTERED          ChaincodeMessage_Type = 2
-	ChaincodeMessage_INIT                ChaincodeMessage_Type = 3
-	ChaincodeMessage_READY               ChaincodeMessage_Type = 4
-	ChaincodeMessage_TRANSACTION         ChaincodeMessage_Type = 5
-	ChaincodeMessage_COMPLETED           ChaincodeMessage_Type = 6
-	ChaincodeMessage_ERROR               ChaincodeMessage_Type = 7
-	ChaincodeMessage_GET_STATE           ChaincodeMessage_Type = 8
-	ChaincodeMessage_PUT_STATE           ChaincodeMessage_Type = 9
-	ChaincodeMessage_DEL_STATE           ChaincodeMessage_Type = 10
-	ChaincodeMessage_INVOKE_CHAINCODE    ChaincodeMessage_Type = 11
-	ChaincodeMessage_RESPONSE            ChaincodeMessage_Type = 13
-	ChaincodeMessage_GET_STATE_BY_RANGE  ChaincodeMessage_Type = 14
-	ChaincodeMessage_GET_QUERY_RESULT    ChaincodeMessage_Type = 15
-	ChaincodeMessage_QUERY_STATE_NEXT    ChaincodeMessage_Type = 16
-	ChaincodeMessage_QUERY_STATE_CLOSE   ChaincodeMessage_Type = 17
-	ChaincodeMessage_KEEPALIVE           ChaincodeMessage_Type = 18
-	ChaincodeMessage_GET_HISTORY_FOR_KEY ChaincodeMessage_Type = 19
+	ChaincodeMessage_UNDEFINED                                          ChaincodeMessage_Type = 0
+	ChaincodeMessage_REGISTER                                           ChaincodeMessage_Type = 1
+	ChaincodeMessage_REGISTERED                                         ChaincodeMessage_Type = 2
+	ChaincodeMessage_INIT                                               ChaincodeMessage_Type = 3
+	ChaincodeMessage_READY                                              ChaincodeMessage_Type = 4
+	ChaincodeMessage_TRANSACTION                                        ChaincodeMessage_Type = 5
+	ChaincodeMessage_COMPLETED                                          ChaincodeMessage_Type = 6
+	ChaincodeMessage_ERROR                                              ChaincodeMessage_Type = 7
+	ChaincodeMessage_GET_STATE                                          ChaincodeMessage_Type = 8
+	ChaincodeMessage_PUT_STATE                                          ChaincodeMessage_Type = 9
+	ChaincodeMessage_DEL_STATE                                          ChaincodeMessage_Type = 10
+	ChaincodeMessage_INVOKE_CHAINCODE                                   ChaincodeMessage_Type = 11
+	ChaincodeMessage_RESPONSE                                           ChaincodeMessage_Type = 13
+	ChaincodeMessage_GET_STATE_BY_RANGE                                 ChaincodeMessage_Type = 14
+	ChaincodeMessage_GET_QUERY_RESULT                                   ChaincodeMessage_Type = 15
+	ChaincodeMessage_QUERY_STATE_NEXT                                   ChaincodeMessage_Type = 16
+	ChaincodeMessage_QUERY_STATE_CLOSE                                  ChaincodeMessage_Type = 17
+	ChaincodeMessage_KEEPALIVE                                          ChaincodeMessage_Type = 18
+	ChaincodeMessage_GET_HISTORY_FOR_KEY                                ChaincodeMessage_Type = 19
+	ChaincodeMessage_GET_STATE_BY_PARTIAL_COMPOSITE_KEY_WITH_PAGINATION ChaincodeMessage_Type = 20
 )
 
 var ChaincodeMessage_Type_name = map[int32]string{
@@ -62,6 +63,7 @@ var ChaincodeMessage_Type_name = map[int32]string{
 	17: "QUERY_STATE_CLOSE",
 	18: "KEEPALIVE",
 	19: "GET_HISTORY_FOR_KEY",
+	20: "GET_STATE_BY_PARTIAL_COMPOSITE_KEY_WITH_PAGINATION",
 }
 var ChaincodeMessage_Type_value = map[string]int32{
 	"UNDEFINED":           0,
@@ -83,6 +85,7 @@ var ChaincodeMessage_Type_value = map[string]int32{
 	"QUERY_STATE_CLOSE":   17,
 	"KEEPALIVE":           18,
 	"GET_HISTORY_FOR_KEY": 19,
+	"GET_STATE_BY_PARTIAL_COMPOSITE_KEY_WITH_PAGINATION": 20,
 }
 
 func (x ChaincodeMessage_Type) String() string {
@@ -197,6 +200,55 @@ func (m *GetStateByRange) GetEndKey() string {
 	return ""
 }
 
+// GetStateByPartialCompositeKeyWithPagination requests a page of at most page_size
+// of the keys starting with the partial composite key built from object_type and
+// attributes. The page begins at the bookmark, or at the first such key if empty
+type GetStateByPartialCompositeKeyWithPagination struct {
+	ObjectType string   `protobuf:"bytes,1,opt,name=object_type,json=objectType" json:"object_type,omitempty"`
+	Attributes []string `protobuf:"bytes,2,rep,name=attributes" json:"attributes,omitempty"`
+	PageSize   int32    `protobuf:"varint,3,opt,name=page_size,json=pageSize" json:"page_size,omitempty"`
+	Bookmark   string   `protobuf:"bytes,4,opt,name=bookmark" json:"bookmark,omitempty"`
+}
+
+func (m *GetStateByPartialCompositeKeyWithPagination) Reset() {
+	*m = GetStateByPartialCompositeKeyWithPagination{}
+}
+func (m *GetStateByPartialCompositeKeyWithPagination) String() string {
+	return proto.CompactTextString(m)
+}
+func (*GetStateByPartialCompositeKeyWithPagination) ProtoMessage() {}
+func (*GetStateByPartialCompositeKeyWithPagination) Descriptor() ([]byte, []int) {
+	return fileDescriptor3, []int{3}
+}
+
+func (m *GetStateByPartialCompositeKeyWithPagination) GetObjectType() string {
+	if m != nil {
+		return m.ObjectType
+	}
+	return ""
+}
+
+func (m *GetStateByPartialCompositeKeyWithPagination) GetAttributes() []string {
+	if m != nil {
+		return m.Attributes
+	}
+	return nil
+}
+
+func (m *GetStateByPartialCompositeKeyWithPagination) GetPageSize() int32 {
+	if m != nil {
+		return m.PageSize
+	}
+	return 0
+}
+
+func (m *GetStateByPartialCompositeKeyWithPagination) GetBookmark() string {
+	if m != nil {
+		return m.Bookmark
+	}
+	return ""
+}
+
 type GetQueryResult struct {
 	Query string `protobuf:"bytes,1,opt,name=query" json:"query,omitempty"`
 }
@@ -204,7 +256,7 @@ type GetQueryResult struct {
 func (m *GetQueryResult) Reset()                    { *m = GetQueryResult{} }
 func (m *GetQueryResult) String() string            { return proto.CompactTextString(m) }
 func (*GetQueryResult) ProtoMessage()               {}
-func (*GetQueryResult) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{3} }
+func (*GetQueryResult) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{4} }
 
 func (m *GetQueryResult) GetQuery() string {
 	if m != nil {
@@ -220,7 +272,7 @@ type GetHistoryForKey struct {
 func (m *GetHistoryForKey) Reset()                    { *m = GetHistoryForKey{} }
 func (m *GetHistoryForKey) String() string            { return proto.CompactTextString(m) }
 func (*GetHistoryForKey) ProtoMessage()               {}
-func (*GetHistoryForKey) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{4} }
+func (*GetHistoryForKey) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{5} }
 
 func (m *GetHistoryForKey) GetKey() string {
 	if m != nil {
@@ -236,7 +288,7 @@ type QueryStateNext struct {
 func (m *QueryStateNext) Reset()                    { *m = QueryStateNext{} }
 func (m *QueryStateNext) String() string            { return proto.CompactTextString(m) }
 func (*QueryStateNext) ProtoMessage()               {}
-func (*QueryStateNext) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{5} }
+func (*QueryStateNext) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{6} }
 
 func (m *QueryStateNext) GetId() string {
 	if m != nil {
@@ -252,7 +304,7 @@ type QueryStateClose struct {
 func (m *QueryStateClose) Reset()                    { *m = QueryStateClose{} }
 func (m *QueryStateClose) String() string            { return proto.CompactTextString(m) }
 func (*QueryStateClose) ProtoMessage()               {}
-func (*QueryStateClose) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{6} }
+func (*QueryStateClose) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{7} }
 
 func (m *QueryStateClose) GetId() string {
 	if m != nil {
@@ -268,7 +320,7 @@ type QueryResultBytes struct {
 func (m *QueryResultBytes) Reset()                    { *m = QueryResultBytes{} }
 func (m *QueryResultBytes) String() string            { return proto.CompactTextString(m) }
 func (*QueryResultBytes) ProtoMessage()               {}
-func (*QueryResultBytes) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{7} }
+func (*QueryResultBytes) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{8} }
 
 func (m *QueryResultBytes) GetResultBytes() []byte {
 	if m != nil {
@@ -278,15 +330,16 @@ func (m *QueryResultBytes) GetResultBytes() []byte {
 }
 
 type QueryResponse struct {
-	Results []*QueryResultBytes `protobuf:"bytes,1,rep,name=results" json:"results,omitempty"`
-	HasMore bool                `protobuf:"varint,2,opt,name=has_more,json=hasMore" json:"has_more,omitempty"`
-	Id      string              `protobuf:"bytes,3,opt,name=id" json:"id,omitempty"`
+	Results  []*QueryResultBytes `protobuf:"bytes,1,rep,name=results" json:"results,omitempty"`
+	HasMore  bool                `protobuf:"varint,2,opt,name=has_more,json=hasMore" json:"has_more,omitempty"`
+	Id       string              `protobuf:"bytes,3,opt,name=id" json:"id,omitempty"`
+	Metadata []byte              `protobuf:"bytes,4,opt,name=metadata,proto3" json:"metadata,omitempty"`
 }
 
 func (m *QueryResponse) Reset()                    { *m = QueryResponse{} }
 func (m *QueryResponse) String() string            { return proto.CompactTextString(m) }
 func (*QueryResponse) ProtoMessage()               {}
-func (*QueryResponse) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{8} }
+func (*QueryResponse) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{9} }
 
 func (m *QueryResponse) GetResults() []*QueryResultBytes {
 	if m != nil {
@@ -309,16 +362,51 @@ func (m *QueryResponse) GetId() string {
 	return ""
 }
 
+func (m *QueryResponse) GetMetadata() []byte {
+	if m != nil {
+		return m.Metadata
+	}
+	return nil
+}
+
+// QueryResponseMetadata is the metadata of a paginated query response. The
+// bookmark is where the next page begins, and is empty after the last page
+type QueryResponseMetadata struct {
+	FetchedRecordsCount int32  `protobuf:"varint,1,opt,name=fetched_records_count,json=fetchedRecordsCount" json:"fetched_records_count,omitempty"`
+	Bookmark            string `protobuf:"bytes,2,opt,name=bookmark" json:"bookmark,omitempty"`
+}
+
+func (m *QueryResponseMetadata) Reset()                    { *m = QueryResponseMetadata{} }
+func (m *QueryResponseMetadata) String() string            { return proto.CompactTextString(m) }
+func (*QueryResponseMetadata) ProtoMessage()               {}
+func (*QueryResponseMetadata) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{10} }
+
+func (m *QueryResponseMetadata) GetFetchedRecordsCount() int32 {
+	if m != nil {
+		return m.FetchedRecordsCount
+	}
+	return 0
+}
+
+func (m *QueryResponseMetadata) GetBookmark() string {
+	if m != nil {
+		return m.Bookmark
+	}
+	return ""
+}
+
 func init() {
 	proto.RegisterType((*ChaincodeMessage)(nil), "protos.ChaincodeMessage")
 	proto.RegisterType((*PutStateInfo)(nil), "protos.PutStateInfo")
 	proto.RegisterType((*GetStateByRange)(nil), "protos.GetStateByRange")
+	proto.RegisterType((*GetStateByPartialCompositeKeyWithPagination)(nil), "protos.GetStateByPartialCompositeKeyWithPagination")
 	proto.RegisterType((*GetQueryResult)(nil), "protos.GetQueryResult")
 	proto.RegisterType((*GetHistoryForKey)(nil), "protos.GetHistoryForKey")
 	proto.RegisterType((*QueryStateNext)(nil), "protos.QueryStateNext")
 	proto.RegisterType((*QueryStateClose)(nil), "protos.QueryStateClose")
 	proto.RegisterType((*QueryResultBytes)(nil), "protos.QueryResultBytes")
 	proto.RegisterType((*QueryResponse)(nil), "protos.QueryResponse")
+	proto.RegisterType((*QueryResponseMetadata)(nil), "protos.QueryResponseMetadata")
 	proto.RegisterEnum("protos.ChaincodeMessage_Type", ChaincodeMessage_Type_name, ChaincodeMessage_Type_value)
 }
 
@@ -426,57 +514,166 @@ var _ChaincodeSupport_serviceDesc = grpc.ServiceDesc{
 	Metadata: "peer/chaincode_shim.proto",
 }
 
+// Client API for Chaincode service
+
+type ChaincodeClient interface {
+	Connect(ctx context.Context, opts ...grpc.CallOption) (Chaincode_ConnectClient, error)
+}
+
+type chaincodeClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewChaincodeClient(cc *grpc.ClientConn) ChaincodeClient {
+	return &chaincodeClient{cc}
+}
+
+func (c *chaincodeClient) Connect(ctx context.Context, opts ...grpc.CallOption) (Chaincode_ConnectClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_Chaincode_serviceDesc.Streams[0], c.cc, "/protos.Chaincode/Connect", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &chaincodeConnectClient{stream}
+	return x, nil
+}
+
+type Chaincode_ConnectClient interface {
+	Send(*ChaincodeMessage) error
+	Recv() (*ChaincodeMessage, error)
+	grpc.ClientStream
+}
+
+type chaincodeConnectClient struct {
+	grpc.ClientStream
+}
+
+func (x *chaincodeConnectClient) Send(m *ChaincodeMessage) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *chaincodeConnectClient) Recv() (*ChaincodeMessage, error) {
+	m := new(ChaincodeMessage)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Server API for Chaincode service
+
+type ChaincodeServer interface {
+	Connect(Chaincode_ConnectServer) error
+}
+
+func RegisterChaincodeServer(s *grpc.Server, srv ChaincodeServer) {
+	s.RegisterService(&_Chaincode_serviceDesc, srv)
+}
+
+func _Chaincode_Connect_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ChaincodeServer).Connect(&chaincodeConnectServer{stream})
+}
+
+type Chaincode_ConnectServer interface {
+	Send(*ChaincodeMessage) error
+	Recv() (*ChaincodeMessage, error)
+	grpc.ServerStream
+}
+
+type chaincodeConnectServer struct {
+	grpc.ServerStream
+}
+
+func (x *chaincodeConnectServer) Send(m *ChaincodeMessage) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *chaincodeConnectServer) Recv() (*ChaincodeMessage, error) {
+	m := new(ChaincodeMessage)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+var _Chaincode_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "protos.Chaincode",
+	HandlerType: (*ChaincodeServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Connect",
+			Handler:       _Chaincode_Connect_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "peer/chaincode_shim.proto",
+}
+
 func init() { proto.RegisterFile("peer/chaincode_shim.proto", fileDescriptor3) }
 
 var fileDescriptor3 = []byte{
-	// 771 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x74, 0x94, 0x6f, 0x6f, 0xe2, 0x46,
-	0x10, 0xc6, 0x8f, 0x3f, 0x49, 0x60, 0x20, 0xb0, 0xb7, 0xb9, 0xa6, 0x3e, 0xa4, 0xaa, 0xd4, 0xaa,
-	0x2a, 0xfa, 0xc6, 0xb4, 0xb4, 0xaa, 0xfa, 0xae, 0x22, 0xb0, 0x21, 0x56, 0x88, 0xcd, 0xad, 0x9d,
-	0xd3, 0xd1, 0x37, 0x96, 0x03, 0x1b, 0x63, 0x15, 0x58, 0x77, 0x77, 0x39, 0x9d, 0x3f, 0x61, 0xbf,
-	0x4c, 0x3f, 0xc4, 0x69, 0x6d, 0x4c, 0xb8, 0x9c, 0xf2, 0xca, 0x7e, 0x66, 0x7e, 0xf3, 0xcc, 0xec,
-	0x6a, 0x35, 0xf0, 0x36, 0x61, 0x4c, 0xf4, 0x17, 0xab, 0x30, 0xde, 0x2e, 0xf8, 0x92, 0x05, 0x72,
-	0x15, 0x6f, 0xac, 0x44, 0x70, 0xc5, 0xf1, 0x69, 0xf6, 0x91, 0x9d, 0xce, 0x33, 0x84, 0x7d, 0x64,
-	0x5b, 0x95, 0x33, 0x9d, 0x8b, 0x2c, 0x97, 0x08, 0x9e, 0x70, 0x19, 0xae, 0xf7, 0xc1, 0xef, 0x23,
-	0xce, 0xa3, 0x35, 0xeb, 0x67, 0xea, 0x61, 0xf7, 0xd8, 0x57, 0xf1, 0x86, 0x49, 0x15, 0x6e, 0x92,
-	0x1c, 0x30, 0xff, 0xaf, 0x02, 0x1a, 0x15, 0x7e, 0x77, 0x4c, 0xca, 0x30, 0x62, 0xf8, 0x57, 0xa8,
-	0xaa, 0x34, 0x61, 0x46, 0xa9, 0x5b, 0xea, 0xb5, 0x06, 0xdf, 0xe5, 0xa8, 0xb4, 0x9e, 0x73, 0x96,
-	0x9f, 0x26, 0x8c, 0x66, 0x28, 0xfe, 0x13, 0xea, 0x07, 0x6b, 0xa3, 0xdc, 0x2d, 0xf5, 0x1a, 0x83,
-	0x8e, 0x95, 0x37, 0xb7, 0x8a, 0xe6, 0x96, 0x5f, 0x10, 0xf4, 0x09, 0xc6, 0x06, 0x9c, 0x25, 0x61,
-	0xba, 0xe6, 0xe1, 0xd2, 0xa8, 0x74, 0x4b, 0xbd, 0x26, 0x2d, 0x24, 0xc6, 0x50, 0x55, 0x9f, 0xe2,
-	0xa5, 0x51, 0xed, 0x96, 0x7a, 0x75, 0x9a, 0xfd, 0xe3, 0x01, 0xd4, 0x8a, 0x23, 0x1a, 0x27, 0x59,
-	0x9b, 0xcb, 0x62, 0x3c, 0x2f, 0x8e, 0xb6, 0x6c, 0x39, 0xdb, 0x67, 0xe9, 0x81, 0xc3, 0x7f, 0x41,
-	0xfb, 0xd9, 0x95, 0x19, 0xa7, 0x5f, 0x96, 0x1e, 0x4e, 0x46, 0x74, 0x96, 0xb6, 0x16, 0x5f, 0x68,
-	0xf3, 0xbf, 0x32, 0x54, 0xf5, 0x59, 0xf1, 0x39, 0xd4, 0xef, 0x9d, 0x31, 0xb9, 0xb6, 0x1d, 0x32,
-	0x46, 0xaf, 0x70, 0x13, 0x6a, 0x94, 0x4c, 0x6c, 0xcf, 0x27, 0x14, 0x95, 0x70, 0x0b, 0xa0, 0x50,
-	0x64, 0x8c, 0xca, 0xb8, 0x06, 0x55, 0xdb, 0xb1, 0x7d, 0x54, 0xc1, 0x75, 0x38, 0xa1, 0x64, 0x38,
-	0x9e, 0xa3, 0x2a, 0x6e, 0x43, 0xc3, 0xa7, 0x43, 0xc7, 0x1b, 0x8e, 0x7c, 0xdb, 0x75, 0xd0, 0x89,
-	0xb6, 0x1c, 0xb9, 0x77, 0xb3, 0x29, 0xf1, 0xc9, 0x18, 0x9d, 0x6a, 0x94, 0x50, 0xea, 0x52, 0x74,
-	0xa6, 0x33, 0x13, 0xe2, 0x07, 0x9e, 0x3f, 0xf4, 0x09, 0xaa, 0x69, 0x39, 0xbb, 0x2f, 0x64, 0x5d,
-	0xcb, 0x31, 0x99, 0xee, 0x25, 0xe0, 0x37, 0x80, 0x6c, 0xe7, 0xbd, 0x7b, 0x4b, 0x82, 0xd1, 0xcd,
-	0xd0, 0x76, 0x46, 0xee, 0x98, 0xa0, 0x46, 0x3e, 0xa0, 0x37, 0x73, 0x1d, 0x8f, 0xa0, 0x73, 0x7c,
-	0x09, 0xf8, 0x60, 0x18, 0x5c, 0xcd, 0x03, 0x3a, 0x74, 0x26, 0x04, 0xb5, 0x74, 0xad, 0x8e, 0xbf,
-	0xbb, 0x27, 0x74, 0x1e, 0x50, 0xe2, 0xdd, 0x4f, 0x7d, 0xd4, 0xd6, 0xd1, 0x3c, 0x92, 0xf3, 0x0e,
-	0xf9, 0xe0, 0x23, 0x84, 0xbf, 0x81, 0xd7, 0xc7, 0xd1, 0xd1, 0xd4, 0xf5, 0x08, 0x7a, 0xad, 0xa7,
-	0xb9, 0x25, 0x64, 0x36, 0x9c, 0xda, 0xef, 0x09, 0xc2, 0xf8, 0x5b, 0xb8, 0xd0, 0x8e, 0x37, 0xb6,
-	0xe7, 0xbb, 0x74, 0x1e, 0x5c, 0xbb, 0x34, 0xb8, 0x25, 0x73, 0x74, 0x61, 0xfe, 0x01, 0xcd, 0xd9,
-	0x4e, 0x79, 0x2a, 0x54, 0xcc, 0xde, 0x3e, 0x72, 0x8c, 0xa0, 0xf2, 0x0f, 0x4b, 0xb3, 0x87, 0x56,
-	0xa7, 0xfa, 0x17, 0xbf, 0x81, 0x93, 0x8f, 0xe1, 0x7a, 0xc7, 0xb2, 0x47, 0xd4, 0xa4, 0xb9, 0x30,
-	0x09, 0xb4, 0x27, 0x2c, 0xaf, 0xbb, 0x4a, 0x69, 0xb8, 0x8d, 0x18, 0xee, 0x40, 0x4d, 0xaa, 0x50,
-	0xa8, 0xdb, 0x43, 0xfd, 0x41, 0xe3, 0x4b, 0x38, 0x65, 0xdb, 0xa5, 0xce, 0x94, 0xb3, 0xcc, 0x5e,
-	0x99, 0x3f, 0x41, 0x6b, 0xc2, 0xd4, 0xbb, 0x1d, 0x13, 0x29, 0x65, 0x72, 0xb7, 0x56, 0xba, 0xdd,
-	0xbf, 0x5a, 0xee, 0x2d, 0x72, 0x61, 0xfe, 0x08, 0x68, 0xc2, 0xd4, 0x4d, 0x2c, 0x15, 0x17, 0xe9,
-	0x35, 0x17, 0xda, 0xf3, 0xab, 0x51, 0xcd, 0x2e, 0xb4, 0x32, 0xab, 0x6c, 0x2c, 0x87, 0x7d, 0x52,
-	0xb8, 0x05, 0xe5, 0x78, 0xb9, 0x47, 0xca, 0xf1, 0xd2, 0xfc, 0x01, 0xda, 0x4f, 0xc4, 0x68, 0xcd,
-	0x25, 0xfb, 0x0a, 0xf9, 0x1d, 0xd0, 0xd1, 0x3c, 0x57, 0xa9, 0x62, 0x12, 0x77, 0xa1, 0x21, 0x9e,
-	0x64, 0x06, 0x37, 0xe9, 0x71, 0xc8, 0xdc, 0xc2, 0x79, 0x51, 0x95, 0xf0, 0xad, 0x64, 0x78, 0x00,
-	0x67, 0x79, 0x5e, 0xe3, 0x95, 0x5e, 0x63, 0x60, 0x14, 0x6f, 0xfb, 0xb9, 0x3b, 0x2d, 0x40, 0xfc,
-	0x16, 0x6a, 0xab, 0x50, 0x06, 0x1b, 0x2e, 0xf2, 0xdb, 0xae, 0xd1, 0xb3, 0x55, 0x28, 0xef, 0xb8,
-	0x28, 0xa6, 0xac, 0x14, 0x53, 0x0e, 0x3e, 0x1c, 0x6d, 0x09, 0x6f, 0x97, 0x24, 0x5c, 0x28, 0x3c,
-	0x86, 0x1a, 0x65, 0x51, 0x2c, 0x15, 0x13, 0xd8, 0x78, 0x69, 0x47, 0x74, 0x5e, 0xcc, 0x98, 0xaf,
-	0x7a, 0xa5, 0x5f, 0x4a, 0x57, 0x2e, 0x98, 0x5c, 0x44, 0xd6, 0x2a, 0x4d, 0x98, 0x58, 0xb3, 0x65,
-	0xc4, 0x84, 0xf5, 0x18, 0x3e, 0x88, 0x78, 0x51, 0xd4, 0xe9, 0xb5, 0xf6, 0xf7, 0xcf, 0x51, 0xac,
-	0x56, 0xbb, 0x07, 0x6b, 0xc1, 0x37, 0xfd, 0x23, 0xb4, 0x9f, 0xa3, 0xf9, 0x7a, 0x93, 0x7d, 0x8d,
-	0x3e, 0xe4, 0xbb, 0xf2, 0xb7, 0xcf, 0x01, 0x00, 0x00, 0xff, 0xff, 0xad, 0x9f, 0x80, 0x68, 0x4f,
-	0x05, 0x00, 0x00,
+	// 978 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x95, 0xdf, 0x6e, 0xe2, 0x46,
+	0x14, 0xc6, 0x97, 0x7f, 0x09, 0x1c, 0x58, 0x32, 0x3b, 0xd9, 0xa4, 0x2c, 0x55, 0xbb, 0xd4, 0xaa,
+	0x2a, 0xaa, 0x4a, 0xd0, 0xd2, 0x6a, 0xd5, 0xbb, 0x8a, 0xc0, 0x84, 0x58, 0x21, 0xb6, 0x77, 0xec,
+	0xec, 0x6e, 0x7a, 0x63, 0x0d, 0x78, 0x62, 0xdc, 0x80, 0xc7, 0xb5, 0x87, 0xd5, 0xb2, 0x8f, 0xd0,
+	0xe7, 0xa8, 0xd4, 0xc7, 0x6c, 0x35, 0x36, 0x26, 0x21, 0xab, 0xbd, 0xe9, 0x15, 0x7c, 0xe7, 0xfb,
+	0xcd, 0xf1, 0x39, 0xc7, 0x33, 0x1e, 0x78, 0x11, 0x71, 0x1e, 0xf7, 0xe7, 0x0b, 0x16, 0x84, 0x73,
+	0xe1, 0x71, 0x37, 0x59, 0x04, 0xab, 0x5e, 0x14, 0x0b, 0x29, 0xf0, 0x41, 0xfa, 0x93, 0xb4, 0xdb,
+	0x8f, 0x10, 0xfe, 0x9e, 0x87, 0x32, 0x63, 0xda, 0xc7, 0xa9, 0x17, 0xc5, 0x22, 0x12, 0x09, 0x5b,
+	0x6e, 0x83, 0x2f, 0x7d, 0x21, 0xfc, 0x25, 0xef, 0xa7, 0x6a, 0xb6, 0xbe, 0xed, 0xcb, 0x60, 0xc5,
+	0x13, 0xc9, 0x56, 0x51, 0x06, 0x68, 0x7f, 0x57, 0x00, 0x8d, 0xf2, 0x7c, 0x57, 0x3c, 0x49, 0x98,
+	0xcf, 0xf1, 0x4f, 0x50, 0x96, 0x9b, 0x88, 0xb7, 0x0a, 0x9d, 0x42, 0xb7, 0x39, 0xf8, 0x2a, 0x43,
+	0x93, 0xde, 0x63, 0xae, 0xe7, 0x6c, 0x22, 0x4e, 0x53, 0x14, 0xff, 0x0a, 0xb5, 0x5d, 0xea, 0x56,
+	0xb1, 0x53, 0xe8, 0xd6, 0x07, 0xed, 0x5e, 0xf6, 0xf0, 0x5e, 0xfe, 0xf0, 0x9e, 0x93, 0x13, 0xf4,
+	0x1e, 0xc6, 0x2d, 0x38, 0x8c, 0xd8, 0x66, 0x29, 0x98, 0xd7, 0x2a, 0x75, 0x0a, 0xdd, 0x06, 0xcd,
+	0x25, 0xc6, 0x50, 0x96, 0x1f, 0x02, 0xaf, 0x55, 0xee, 0x14, 0xba, 0x35, 0x9a, 0xfe, 0xc7, 0x03,
+	0xa8, 0xe6, 0x2d, 0xb6, 0x2a, 0xe9, 0x63, 0x4e, 0xf3, 0xf2, 0xec, 0xc0, 0x0f, 0xb9, 0x67, 0x6d,
+	0x5d, 0xba, 0xe3, 0xf0, 0x6f, 0x70, 0xf4, 0x68, 0x64, 0xad, 0x83, 0xfd, 0xa5, 0xbb, 0xce, 0x88,
+	0x72, 0x69, 0x73, 0xbe, 0xa7, 0xb5, 0x7f, 0x8b, 0x50, 0x56, 0xbd, 0xe2, 0xa7, 0x50, 0xbb, 0x36,
+	0xc6, 0xe4, 0x5c, 0x37, 0xc8, 0x18, 0x3d, 0xc1, 0x0d, 0xa8, 0x52, 0x32, 0xd1, 0x6d, 0x87, 0x50,
+	0x54, 0xc0, 0x4d, 0x80, 0x5c, 0x91, 0x31, 0x2a, 0xe2, 0x2a, 0x94, 0x75, 0x43, 0x77, 0x50, 0x09,
+	0xd7, 0xa0, 0x42, 0xc9, 0x70, 0x7c, 0x83, 0xca, 0xf8, 0x08, 0xea, 0x0e, 0x1d, 0x1a, 0xf6, 0x70,
+	0xe4, 0xe8, 0xa6, 0x81, 0x2a, 0x2a, 0xe5, 0xc8, 0xbc, 0xb2, 0xa6, 0xc4, 0x21, 0x63, 0x74, 0xa0,
+	0x50, 0x42, 0xa9, 0x49, 0xd1, 0xa1, 0x72, 0x26, 0xc4, 0x71, 0x6d, 0x67, 0xe8, 0x10, 0x54, 0x55,
+	0xd2, 0xba, 0xce, 0x65, 0x4d, 0xc9, 0x31, 0x99, 0x6e, 0x25, 0xe0, 0xe7, 0x80, 0x74, 0xe3, 0x8d,
+	0x79, 0x49, 0xdc, 0xd1, 0xc5, 0x50, 0x37, 0x46, 0xe6, 0x98, 0xa0, 0x7a, 0x56, 0xa0, 0x6d, 0x99,
+	0x86, 0x4d, 0xd0, 0x53, 0x7c, 0x0a, 0x78, 0x97, 0xd0, 0x3d, 0xbb, 0x71, 0xe9, 0xd0, 0x98, 0x10,
+	0xd4, 0x54, 0x6b, 0x55, 0xfc, 0xf5, 0x35, 0xa1, 0x37, 0x2e, 0x25, 0xf6, 0xf5, 0xd4, 0x41, 0x47,
+	0x2a, 0x9a, 0x45, 0x32, 0xde, 0x20, 0xef, 0x1c, 0x84, 0xf0, 0x09, 0x3c, 0x7b, 0x18, 0x1d, 0x4d,
+	0x4d, 0x9b, 0xa0, 0x67, 0xaa, 0x9a, 0x4b, 0x42, 0xac, 0xe1, 0x54, 0x7f, 0x43, 0x10, 0xc6, 0x5f,
+	0xc0, 0xb1, 0xca, 0x78, 0xa1, 0xdb, 0x8e, 0x49, 0x6f, 0xdc, 0x73, 0x93, 0xba, 0x97, 0xe4, 0x06,
+	0x1d, 0xe3, 0x57, 0x30, 0xd8, 0x2b, 0xc1, 0x1a, 0x52, 0x47, 0x1f, 0x4e, 0x5d, 0x35, 0x02, 0xd3,
+	0xd6, 0x1d, 0xa2, 0x38, 0xf7, 0xad, 0xee, 0x5c, 0xb8, 0xd6, 0x70, 0xa2, 0x1b, 0xc3, 0x74, 0x4a,
+	0xcf, 0xb5, 0x57, 0xd0, 0xb0, 0xd6, 0xd2, 0x96, 0x4c, 0x72, 0x3d, 0xbc, 0x15, 0x18, 0x41, 0xe9,
+	0x8e, 0x6f, 0xd2, 0x0d, 0x5a, 0xa3, 0xea, 0x2f, 0x7e, 0x0e, 0x95, 0xf7, 0x6c, 0xb9, 0xe6, 0xe9,
+	0xe6, 0x6b, 0xd0, 0x4c, 0x68, 0x04, 0x8e, 0x26, 0x3c, 0x5b, 0x77, 0xb6, 0xa1, 0x2c, 0xf4, 0x39,
+	0x6e, 0x43, 0x35, 0x91, 0x2c, 0x96, 0x97, 0xbb, 0xf5, 0x3b, 0x8d, 0x4f, 0xe1, 0x80, 0x87, 0x9e,
+	0x72, 0x8a, 0xa9, 0xb3, 0x55, 0xda, 0x3f, 0x05, 0xf8, 0xe1, 0x3e, 0x8f, 0xc5, 0x62, 0x19, 0xb0,
+	0xe5, 0x48, 0xac, 0x22, 0x91, 0x04, 0x92, 0x5f, 0xf2, 0xcd, 0xdb, 0x40, 0x2e, 0x2c, 0xe6, 0x07,
+	0x21, 0x93, 0x81, 0x08, 0xf1, 0x4b, 0xa8, 0x8b, 0xd9, 0x1f, 0x7c, 0x2e, 0xdd, 0xdd, 0x39, 0xaa,
+	0x51, 0xc8, 0x42, 0xe9, 0x46, 0xfa, 0x1a, 0x80, 0x49, 0x19, 0x07, 0xb3, 0xb5, 0xe4, 0x49, 0xab,
+	0xd8, 0x29, 0x29, 0xff, 0x3e, 0x82, 0xbf, 0x84, 0x5a, 0xc4, 0x7c, 0xee, 0x26, 0xc1, 0x47, 0x9e,
+	0x1e, 0x8b, 0x0a, 0xad, 0xaa, 0x80, 0x1d, 0x7c, 0x4c, 0x3b, 0x98, 0x09, 0x71, 0xb7, 0x62, 0xf1,
+	0xdd, 0xf6, 0x6c, 0xec, 0xb4, 0xf6, 0x1d, 0x34, 0x27, 0x5c, 0xbe, 0x5e, 0xf3, 0x78, 0x43, 0x79,
+	0xb2, 0x5e, 0x4a, 0x35, 0x98, 0x3f, 0x95, 0xdc, 0x56, 0x91, 0x09, 0xed, 0x5b, 0x40, 0x13, 0x2e,
+	0x2f, 0x82, 0x44, 0x8a, 0x78, 0x73, 0x2e, 0x62, 0xd5, 0xfd, 0x27, 0x43, 0xd5, 0x3a, 0xd0, 0x4c,
+	0x53, 0xa5, 0x8d, 0x1b, 0xfc, 0x83, 0xc4, 0x4d, 0x28, 0x06, 0xde, 0x16, 0x29, 0x06, 0x9e, 0xf6,
+	0x0d, 0x1c, 0xdd, 0x13, 0xa3, 0xa5, 0x48, 0xf8, 0x27, 0xc8, 0x2f, 0x80, 0x1e, 0xd4, 0x73, 0xb6,
+	0x51, 0xfd, 0x75, 0xa0, 0x1e, 0xdf, 0xcb, 0x14, 0x6e, 0xd0, 0x87, 0x21, 0xed, 0xaf, 0x02, 0x3c,
+	0xcd, 0x97, 0x45, 0x22, 0x4c, 0x38, 0x1e, 0xc0, 0x61, 0x06, 0x28, 0xbe, 0xd4, 0xad, 0x0f, 0x5a,
+	0xf9, 0xf1, 0x7d, 0x9c, 0x9e, 0xe6, 0x20, 0x7e, 0x01, 0xd5, 0x05, 0x4b, 0xdc, 0x95, 0x88, 0xb3,
+	0x8d, 0x51, 0xa5, 0x87, 0x0b, 0x96, 0x5c, 0x89, 0x38, 0x2f, 0xb3, 0x94, 0x97, 0xa9, 0xa6, 0xba,
+	0xe2, 0x92, 0x79, 0x4c, 0xb2, 0x74, 0xaa, 0x0d, 0xba, 0xd3, 0x9a, 0x0f, 0x27, 0x7b, 0xb5, 0x5c,
+	0x6d, 0x0d, 0x3c, 0x80, 0x93, 0x5b, 0x2e, 0xe7, 0x0b, 0xee, 0xb9, 0x31, 0x9f, 0x8b, 0xd8, 0x4b,
+	0xdc, 0xb9, 0x58, 0x87, 0x32, 0xed, 0xa8, 0x42, 0x8f, 0xb7, 0x26, 0xcd, 0xbc, 0x91, 0xb2, 0xf6,
+	0x5e, 0x5f, 0x71, 0xff, 0xf5, 0x0d, 0xde, 0x3d, 0xf8, 0x1a, 0xdb, 0xeb, 0x28, 0x12, 0xb1, 0xc4,
+	0x63, 0xa8, 0x52, 0xee, 0x07, 0x89, 0xe4, 0x31, 0x6e, 0x7d, 0xee, 0x5b, 0xdc, 0xfe, 0xac, 0xa3,
+	0x3d, 0xe9, 0x16, 0x7e, 0x2c, 0x0c, 0x2c, 0xa8, 0xed, 0x1c, 0x3c, 0x82, 0xc3, 0x91, 0x08, 0x43,
+	0x3e, 0x97, 0xff, 0x3f, 0xe3, 0x99, 0x09, 0x9a, 0x88, 0xfd, 0xde, 0x62, 0x13, 0xf1, 0x78, 0xc9,
+	0x3d, 0x9f, 0xc7, 0xbd, 0x5b, 0x36, 0x8b, 0x83, 0x79, 0xbe, 0x4e, 0x5d, 0x48, 0xbf, 0x7f, 0xef,
+	0x07, 0x72, 0xb1, 0x9e, 0xf5, 0xe6, 0x62, 0xd5, 0x7f, 0x80, 0xf6, 0x33, 0x34, 0xbb, 0x98, 0x92,
+	0xbe, 0x42, 0x67, 0xd9, 0x2d, 0xf7, 0xf3, 0x7f, 0x03, 0x00, 0x4a, 0xd5, 0x8e, 0x66, 0x09, 0x07,
+	0x00, 0x00,
 }
@@ -99,6 +99,10 @@ func (ccv *DynamicApplicationConfigValue) VariablyOpaqueFieldProto(name string)
 		return nil, fmt.Errorf("Not a marshaled field: %s", name)
 	}
 	switch ccv.name {
+	case "GossipConfig":
+		return &GossipConfig{}, nil
+	case "Capabilities":
+		return &common.Capabilities{}, nil
 	default:
 		return nil, fmt.Errorf("Unknown Application ConfigValue name: %s", ccv.name)
 	}
@@ -12,6 +12,38 @@ var _ = proto.Marshal
 var _ = fmt.Errorf
 var _ = math.Inf
 
+// LeaderElection defines how the peers of an organization decide which
+// of them pull the blocks of the channel from the ordering service
+type GossipConfig_LeaderElection int32
+
+const (
+	// The peer.gossip.useLeaderElection and peer.gossip.orgLeader
+	// settings of the peer are in effect
+	GossipConfig_LOCAL GossipConfig_LeaderElection = 0
+	// The peers of the organization elect a leader dynamically
+	GossipConfig_DYNAMIC GossipConfig_LeaderElection = 1
+	// The peers whose peer.gossip.orgLeader setting is true pull the blocks
+	GossipConfig_STATIC GossipConfig_LeaderElection = 2
+)
+
+var GossipConfig_LeaderElection_name = map[int32]string{
+	0: "LOCAL",
+	1: "DYNAMIC",
+	2: "STATIC",
+}
+var GossipConfig_LeaderElection_value = map[string]int32{
+	"LOCAL":   0,
+	"DYNAMIC": 1,
+	"STATIC":  2,
+}
+
+func (x GossipConfig_LeaderElection) String() string {
+	return proto.EnumName(GossipConfig_LeaderElection_name, int32(x))
+}
+func (GossipConfig_LeaderElection) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor4, []int{2, 0}
+}
+
 // AnchorPeers simply represents list of anchor peers which is used in ConfigurationItem
 type AnchorPeers struct {
 	AnchorPeers []*AnchorPeer `protobuf:"bytes,1,rep,name=anchor_peers,json=anchorPeers" json:"anchor_peers,omitempty"`
@@ -57,25 +89,97 @@ func (m *AnchorPeer) GetPort() int32 {
 	return 0
 }
 
+// GossipConfig defines the gossip parameters of a channel which are applied
+// to the running gossip instances of the channel's peers. A zero value of
+// a field, or the removal of the GossipConfig, leaves the corresponding
+// local setting of the peer in effect.
+type GossipConfig struct {
+	// Interval between consecutive publications of the state info message, in milliseconds
+	PublishStateInfoIntervalMs uint32 `protobuf:"varint,1,opt,name=publish_state_info_interval_ms,json=publishStateInfoIntervalMs" json:"publish_state_info_interval_ms,omitempty"`
+	// Interval between consecutive pulls of state info messages from peers, in milliseconds
+	RequestStateInfoIntervalMs uint32 `protobuf:"varint,2,opt,name=request_state_info_interval_ms,json=requestStateInfoIntervalMs" json:"request_state_info_interval_ms,omitempty"`
+	// Number of peers to pull state info messages from
+	PullPeerNum uint32 `protobuf:"varint,3,opt,name=pull_peer_num,json=pullPeerNum" json:"pull_peer_num,omitempty"`
+	// Maximum count of blocks of the channel stored in memory to be gossiped
+	MaxBlockCountToStore uint32 `protobuf:"varint,4,opt,name=max_block_count_to_store,json=maxBlockCountToStore" json:"max_block_count_to_store,omitempty"`
+	// How the peers pulling the blocks from the ordering service are decided
+	LeaderElection GossipConfig_LeaderElection `protobuf:"varint,5,opt,name=leader_election,json=leaderElection,enum=protos.GossipConfig_LeaderElection" json:"leader_election,omitempty"`
+}
+
+func (m *GossipConfig) Reset()                    { *m = GossipConfig{} }
+func (m *GossipConfig) String() string            { return proto.CompactTextString(m) }
+func (*GossipConfig) ProtoMessage()               {}
+func (*GossipConfig) Descriptor() ([]byte, []int) { return fileDescriptor4, []int{2} }
+
+func (m *GossipConfig) GetPublishStateInfoIntervalMs() uint32 {
+	if m != nil {
+		return m.PublishStateInfoIntervalMs
+	}
+	return 0
+}
+
+func (m *GossipConfig) GetRequestStateInfoIntervalMs() uint32 {
+	if m != nil {
+		return m.RequestStateInfoIntervalMs
+	}
+	return 0
+}
+
+func (m *GossipConfig) GetPullPeerNum() uint32 {
+	if m != nil {
+		return m.PullPeerNum
+	}
+	return 0
+}
+
+func (m *GossipConfig) GetMaxBlockCountToStore() uint32 {
+	if m != nil {
+		return m.MaxBlockCountToStore
+	}
+	return 0
+}
+
+func (m *GossipConfig) GetLeaderElection() GossipConfig_LeaderElection {
+	if m != nil {
+		return m.LeaderElection
+	}
+	return GossipConfig_LOCAL
+}
+
 func init() {
 	proto.RegisterType((*AnchorPeers)(nil), "protos.AnchorPeers")
 	proto.RegisterType((*AnchorPeer)(nil), "protos.AnchorPeer")
+	proto.RegisterType((*GossipConfig)(nil), "protos.GossipConfig")
+	proto.RegisterEnum("protos.GossipConfig_LeaderElection", GossipConfig_LeaderElection_name, GossipConfig_LeaderElection_value)
 }
 
 func init() { proto.RegisterFile("peer/configuration.proto", fileDescriptor4) }
 
 var fileDescriptor4 = []byte{
-	// 192 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x4c, 0x8f, 0x3d, 0x8f, 0xc3, 0x20,
-	0x0c, 0x86, 0xc5, 0x7d, 0x49, 0x47, 0x6e, 0x62, 0x62, 0x8c, 0x32, 0xe5, 0x16, 0x90, 0xee, 0xe3,
-	0x07, 0xb4, 0xea, 0xde, 0x2a, 0x63, 0x97, 0x8a, 0x50, 0x02, 0x48, 0x6d, 0x8c, 0x0c, 0x19, 0xfa,
-	0xef, 0x2b, 0x40, 0x55, 0x3a, 0xf1, 0x62, 0x3f, 0x8f, 0x6c, 0x53, 0x1e, 0x8c, 0x41, 0xa9, 0x61,
-	0x9e, 0xbc, 0x5d, 0x50, 0x25, 0x0f, 0xb3, 0x08, 0x08, 0x09, 0xd8, 0x47, 0x79, 0x62, 0xb7, 0xa3,
-	0xcd, 0x66, 0xd6, 0x0e, 0xf0, 0x60, 0x0c, 0x46, 0xf6, 0x4f, 0xbf, 0x54, 0xf9, 0x9e, 0xb2, 0x19,
-	0x39, 0x69, 0x5f, 0xfb, 0xe6, 0x87, 0x55, 0x29, 0x8a, 0x15, 0x1d, 0x1a, 0xb5, 0x6a, 0xdd, 0x1f,
-	0xa5, 0x6b, 0x8b, 0x31, 0xfa, 0xe6, 0x20, 0x26, 0x4e, 0x5a, 0xd2, 0x7f, 0x0e, 0x25, 0xe7, 0x5a,
-	0x00, 0x4c, 0xfc, 0xa5, 0x25, 0xfd, 0xfb, 0x50, 0xf2, 0x76, 0x4f, 0x3b, 0x40, 0x2b, 0xdc, 0x2d,
-	0x18, 0xbc, 0x98, 0xb3, 0x35, 0x28, 0x26, 0x35, 0xa2, 0xd7, 0x8f, 0x71, 0x79, 0x87, 0xe3, 0xb7,
-	0xf5, 0xc9, 0x2d, 0xa3, 0xd0, 0x70, 0x95, 0x4f, 0xa8, 0xac, 0xa8, 0xac, 0xa8, 0xcc, 0xe8, 0x58,
-	0x8f, 0xfa, 0xbd, 0x07, 0x00, 0x00, 0xff, 0xff, 0x6d, 0xf2, 0x7b, 0x6f, 0xf7, 0x00, 0x00, 0x00,
+	// 399 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x74, 0x92, 0x4f, 0x6f, 0xd3, 0x40,
+	0x10, 0xc5, 0x71, 0xda, 0x14, 0x75, 0xdc, 0x86, 0x68, 0xc5, 0xc1, 0xe2, 0x80, 0x22, 0x73, 0x09,
+	0x17, 0x5b, 0x2a, 0x85, 0x7b, 0xe2, 0x22, 0x14, 0x29, 0x6d, 0x91, 0x93, 0x0b, 0x5c, 0x56, 0x6b,
+	0x77, 0x12, 0x5b, 0xac, 0x3d, 0x66, 0xff, 0xa0, 0xf2, 0xc5, 0x39, 0xa3, 0x5d, 0xbb, 0x4a, 0x23,
+	0xc1, 0xc9, 0xbb, 0xf3, 0x7e, 0xef, 0x69, 0x66, 0xbc, 0x10, 0x75, 0x88, 0x2a, 0x2d, 0xa9, 0xdd,
+	0xd5, 0x7b, 0xab, 0x84, 0xa9, 0xa9, 0x4d, 0x3a, 0x45, 0x86, 0xd8, 0x99, 0xff, 0xe8, 0xf8, 0x06,
+	0xc2, 0x45, 0x5b, 0x56, 0xa4, 0xbe, 0x22, 0x2a, 0xcd, 0x3e, 0xc2, 0x85, 0xf0, 0x57, 0xee, 0x9c,
+	0x3a, 0x0a, 0x66, 0x27, 0xf3, 0xf0, 0x8a, 0xf5, 0x26, 0x9d, 0x1c, 0xd0, 0x3c, 0x14, 0x07, 0x5b,
+	0x7c, 0x0d, 0x70, 0x90, 0x18, 0x83, 0xd3, 0x8a, 0xb4, 0x89, 0x82, 0x59, 0x30, 0x3f, 0xcf, 0xfd,
+	0xd9, 0xd5, 0x3a, 0x52, 0x26, 0x1a, 0xcd, 0x82, 0xf9, 0x38, 0xf7, 0xe7, 0xf8, 0xcf, 0x08, 0x2e,
+	0xbe, 0x90, 0xd6, 0x75, 0x97, 0xf9, 0x0e, 0xd9, 0x12, 0xde, 0x76, 0xb6, 0x90, 0xb5, 0xae, 0xb8,
+	0x36, 0xc2, 0x20, 0xaf, 0xdb, 0x1d, 0xf1, 0xba, 0x35, 0xa8, 0x7e, 0x09, 0xc9, 0x1b, 0xed, 0x23,
+	0x2f, 0xf3, 0x37, 0x03, 0xb5, 0x71, 0xd0, 0xaa, 0xdd, 0xd1, 0x6a, 0x40, 0x6e, 0xb5, 0xcb, 0x50,
+	0xf8, 0xd3, 0xa2, 0x36, 0xff, 0xcb, 0x18, 0xf5, 0x19, 0x03, 0xf5, 0xaf, 0x8c, 0x18, 0x2e, 0x3b,
+	0x2b, 0xa5, 0xdf, 0x01, 0x6f, 0x6d, 0x13, 0x9d, 0x78, 0x4b, 0xe8, 0x8a, 0x6e, 0xc2, 0x3b, 0xdb,
+	0xb0, 0x4f, 0x10, 0x35, 0xe2, 0x91, 0x17, 0x92, 0xca, 0x1f, 0xbc, 0x24, 0xdb, 0x1a, 0x6e, 0x88,
+	0x6b, 0x43, 0x0a, 0xa3, 0x53, 0x8f, 0xbf, 0x6e, 0xc4, 0xe3, 0xd2, 0xc9, 0x99, 0x53, 0xb7, 0xb4,
+	0x71, 0x1a, 0x5b, 0xc3, 0x2b, 0x89, 0xe2, 0x01, 0x15, 0x47, 0x89, 0xa5, 0xfb, 0x23, 0xd1, 0x78,
+	0x16, 0xcc, 0x27, 0x57, 0xef, 0x9e, 0x96, 0xfc, 0x7c, 0x25, 0xc9, 0xda, 0xb3, 0x9f, 0x07, 0x34,
+	0x9f, 0xc8, 0xa3, 0x7b, 0x7c, 0x0d, 0x93, 0x63, 0x82, 0x9d, 0xc3, 0x78, 0x7d, 0x9f, 0x2d, 0xd6,
+	0xd3, 0x17, 0x2c, 0x84, 0x97, 0x37, 0xdf, 0xee, 0x16, 0xb7, 0xab, 0x6c, 0x1a, 0x30, 0x80, 0xb3,
+	0xcd, 0x76, 0xb1, 0x5d, 0x65, 0xd3, 0xd1, 0xf2, 0x1e, 0x62, 0x52, 0xfb, 0xa4, 0xfa, 0xdd, 0xa1,
+	0x92, 0xf8, 0xb0, 0x47, 0x95, 0xec, 0x44, 0xa1, 0xea, 0xf2, 0xa9, 0x05, 0x37, 0xf8, 0xf7, 0xf7,
+	0xfb, 0xda, 0x54, 0xb6, 0x48, 0x4a, 0x6a, 0xd2, 0x67, 0x68, 0xda, 0xa3, 0x69, 0x8f, 0xa6, 0x0e,
+	0x2d, 0xfa, 0xd7, 0xf4, 0xe1, 0xef, 0x00, 0x00, 0x80, 0xab, 0x13, 0x70, 0x02, 0x00, 0x00,
 }
@@ -7,6 +7,7 @@ import proto "github.com/golang/protobuf/proto"
 import fmt "fmt"
 import math "math"
 import common "github.com/hyperledger/fabric/protos/common"
+import orderer "github.com/hyperledger/fabric/protos/orderer"
 
 import (
 	context "golang.org/x/net/context"
@@ -89,9 +90,7 @@ func (m *Interest) String() string            { return proto.CompactTextString(m
 func (*Interest) ProtoMessage()               {}
 func (*Interest) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{1} }
 
-type isInterest_RegInfo interface {
-	isInterest_RegInfo()
-}
+type isInterest_RegInfo interface{ isInterest_RegInfo() }
 
 type Interest_ChaincodeRegInfo struct {
 	ChaincodeRegInfo *ChaincodeReg `protobuf:"bytes,2,opt,name=chaincode_reg_info,json=chaincodeRegInfo,oneof"`
@@ -272,8 +271,8 @@ func (m *SignedEvent) GetEventBytes() []byte {
 }
 
 // Event is used by
-//  - consumers (adapters) to send Register
-//  - producer to advertise supported types and events
+//   - consumers (adapters) to send Register
+//   - producer to advertise supported types and events
 type Event struct {
 	// Types that are valid to be assigned to Event:
 	//	*Event_Register
@@ -291,9 +290,7 @@ func (m *Event) String() string            { return proto.CompactTextString(m) }
 func (*Event) ProtoMessage()               {}
 func (*Event) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{6} }
 
-type isEvent_Event interface {
-	isEvent_Event()
-}
+type isEvent_Event interface{ isEvent_Event() }
 
 type Event_Register struct {
 	Register *Register `protobuf:"bytes,1,opt,name=register,oneof"`
@@ -497,6 +494,237 @@ func _Event_OneofSizer(msg proto.Message) (n int) {
 	return n
 }
 
+// ChaincodeEventsRequest is the data of the envelope a client sends to the ChaincodeEvents
+// service, to receive the events of a chaincode on the channel of the envelope
+type ChaincodeEventsRequest struct {
+	ChaincodeId string `protobuf:"bytes,1,opt,name=chaincode_id,json=chaincodeId" json:"chaincode_id,omitempty"`
+	// event_name selects the events with this name, all the events of the chaincode are
+	// delivered if it is empty
+	EventName string `protobuf:"bytes,2,opt,name=event_name,json=eventName" json:"event_name,omitempty"`
+	// start_block is the number of the first block whose events are delivered, so that
+	// a client resuming after a disconnection replays the events it missed
+	StartBlock uint64 `protobuf:"varint,3,opt,name=start_block,json=startBlock" json:"start_block,omitempty"`
+}
+
+func (m *ChaincodeEventsRequest) Reset()                    { *m = ChaincodeEventsRequest{} }
+func (m *ChaincodeEventsRequest) String() string            { return proto.CompactTextString(m) }
+func (*ChaincodeEventsRequest) ProtoMessage()               {}
+func (*ChaincodeEventsRequest) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{7} }
+
+func (m *ChaincodeEventsRequest) GetChaincodeId() string {
+	if m != nil {
+		return m.ChaincodeId
+	}
+	return ""
+}
+
+func (m *ChaincodeEventsRequest) GetEventName() string {
+	if m != nil {
+		return m.EventName
+	}
+	return ""
+}
+
+func (m *ChaincodeEventsRequest) GetStartBlock() uint64 {
+	if m != nil {
+		return m.StartBlock
+	}
+	return 0
+}
+
+// ChaincodeBlockEvents lists the chaincode events emitted by the valid transactions of a block
+type ChaincodeBlockEvents struct {
+	BlockNumber uint64            `protobuf:"varint,1,opt,name=block_number,json=blockNumber" json:"block_number,omitempty"`
+	Events      []*ChaincodeEvent `protobuf:"bytes,2,rep,name=events" json:"events,omitempty"`
+}
+
+func (m *ChaincodeBlockEvents) Reset()                    { *m = ChaincodeBlockEvents{} }
+func (m *ChaincodeBlockEvents) String() string            { return proto.CompactTextString(m) }
+func (*ChaincodeBlockEvents) ProtoMessage()               {}
+func (*ChaincodeBlockEvents) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{8} }
+
+func (m *ChaincodeBlockEvents) GetBlockNumber() uint64 {
+	if m != nil {
+		return m.BlockNumber
+	}
+	return 0
+}
+
+func (m *ChaincodeBlockEvents) GetEvents() []*ChaincodeEvent {
+	if m != nil {
+		return m.Events
+	}
+	return nil
+}
+
+// ChaincodeEventsResponse carries either the matching events of a block, or the status
+// ending the delivery
+type ChaincodeEventsResponse struct {
+	// Types that are valid to be assigned to Type:
+	//	*ChaincodeEventsResponse_Status
+	//	*ChaincodeEventsResponse_BlockEvents
+	Type isChaincodeEventsResponse_Type `protobuf_oneof:"Type"`
+}
+
+func (m *ChaincodeEventsResponse) Reset()                    { *m = ChaincodeEventsResponse{} }
+func (m *ChaincodeEventsResponse) String() string            { return proto.CompactTextString(m) }
+func (*ChaincodeEventsResponse) ProtoMessage()               {}
+func (*ChaincodeEventsResponse) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{9} }
+
+type isChaincodeEventsResponse_Type interface{ isChaincodeEventsResponse_Type() }
+
+type ChaincodeEventsResponse_Status struct {
+	Status common.Status `protobuf:"varint,1,opt,name=status,enum=common.Status,oneof"`
+}
+type ChaincodeEventsResponse_BlockEvents struct {
+	BlockEvents *ChaincodeBlockEvents `protobuf:"bytes,2,opt,name=block_events,json=blockEvents,oneof"`
+}
+
+func (*ChaincodeEventsResponse_Status) isChaincodeEventsResponse_Type()      {}
+func (*ChaincodeEventsResponse_BlockEvents) isChaincodeEventsResponse_Type() {}
+
+func (m *ChaincodeEventsResponse) GetType() isChaincodeEventsResponse_Type {
+	if m != nil {
+		return m.Type
+	}
+	return nil
+}
+
+func (m *ChaincodeEventsResponse) GetStatus() common.Status {
+	if x, ok := m.GetType().(*ChaincodeEventsResponse_Status); ok {
+		return x.Status
+	}
+	return common.Status_UNKNOWN
+}
+
+func (m *ChaincodeEventsResponse) GetBlockEvents() *ChaincodeBlockEvents {
+	if x, ok := m.GetType().(*ChaincodeEventsResponse_BlockEvents); ok {
+		return x.BlockEvents
+	}
+	return nil
+}
+
+// XXX_OneofFuncs is for the internal use of the proto package.
+func (*ChaincodeEventsResponse) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) error, func(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error), func(msg proto.Message) (n int), []interface{}) {
+	return _ChaincodeEventsResponse_OneofMarshaler, _ChaincodeEventsResponse_OneofUnmarshaler, _ChaincodeEventsResponse_OneofSizer, []interface{}{
+		(*ChaincodeEventsResponse_Status)(nil),
+		(*ChaincodeEventsResponse_BlockEvents)(nil),
+	}
+}
+
+func _ChaincodeEventsResponse_OneofMarshaler(msg proto.Message, b *proto.Buffer) error {
+	m := msg.(*ChaincodeEventsResponse)
+	// Type
+	switch x := m.Type.(type) {
+	case *ChaincodeEventsResponse_Status:
+		b.EncodeVarint(1<<3 | proto.WireVarint)
+		b.EncodeVarint(uint64(x.Status))
+	case *ChaincodeEventsResponse_BlockEvents:
+		b.EncodeVarint(2<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.BlockEvents); err != nil {
+			return err
+		}
+	case nil:
+	default:
+		return fmt.Errorf("ChaincodeEventsResponse.Type has unexpected type %T", x)
+	}
+	return nil
+}
+
+func _ChaincodeEventsResponse_OneofUnmarshaler(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error) {
+	m := msg.(*ChaincodeEventsResponse)
+	switch tag {
+	case 1: // Type.status
+		if wire != proto.WireVarint {
+			return true, proto.ErrInternalBadWireType
+		}
+		x, err := b.DecodeVarint()
+		m.Type = &ChaincodeEventsResponse_Status{common.Status(x)}
+		return true, err
+	case 2: // Type.block_events
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(ChaincodeBlockEvents)
+		err := b.DecodeMessage(msg)
+		m.Type = &ChaincodeEventsResponse_BlockEvents{msg}
+		return true, err
+	default:
+		return false, nil
+	}
+}
+
+func _ChaincodeEventsResponse_OneofSizer(msg proto.Message) (n int) {
+	m := msg.(*ChaincodeEventsResponse)
+	// Type
+	switch x := m.Type.(type) {
+	case *ChaincodeEventsResponse_Status:
+		n += proto.SizeVarint(1<<3 | proto.WireVarint)
+		n += proto.SizeVarint(uint64(x.Status))
+	case *ChaincodeEventsResponse_BlockEvents:
+		s := proto.Size(x.BlockEvents)
+		n += proto.SizeVarint(2<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case nil:
+	default:
+		panic(fmt.Sprintf("proto: unexpected type %T in oneof", x))
+	}
+	return n
+}
+
+// TxStatusRequest is the data of the envelope a client sends to the TxStatus service, to
+// wait for the commit of a transaction on the channel of the envelope
+type TxStatusRequest struct {
+	TxId string `protobuf:"bytes,1,opt,name=tx_id,json=txId" json:"tx_id,omitempty"`
+}
+
+func (m *TxStatusRequest) Reset()                    { *m = TxStatusRequest{} }
+func (m *TxStatusRequest) String() string            { return proto.CompactTextString(m) }
+func (*TxStatusRequest) ProtoMessage()               {}
+func (*TxStatusRequest) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{10} }
+
+func (m *TxStatusRequest) GetTxId() string {
+	if m != nil {
+		return m.TxId
+	}
+	return ""
+}
+
+// TxStatusResponse carries the status of the request and, if it succeeded, the block the
+// transaction was committed in and the code it was validated with
+type TxStatusResponse struct {
+	Status         common.Status    `protobuf:"varint,1,opt,name=status,enum=common.Status" json:"status,omitempty"`
+	BlockNumber    uint64           `protobuf:"varint,2,opt,name=block_number,json=blockNumber" json:"block_number,omitempty"`
+	ValidationCode TxValidationCode `protobuf:"varint,3,opt,name=validation_code,json=validationCode,enum=protos.TxValidationCode" json:"validation_code,omitempty"`
+}
+
+func (m *TxStatusResponse) Reset()                    { *m = TxStatusResponse{} }
+func (m *TxStatusResponse) String() string            { return proto.CompactTextString(m) }
+func (*TxStatusResponse) ProtoMessage()               {}
+func (*TxStatusResponse) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{11} }
+
+func (m *TxStatusResponse) GetStatus() common.Status {
+	if m != nil {
+		return m.Status
+	}
+	return common.Status_UNKNOWN
+}
+
+func (m *TxStatusResponse) GetBlockNumber() uint64 {
+	if m != nil {
+		return m.BlockNumber
+	}
+	return 0
+}
+
+func (m *TxStatusResponse) GetValidationCode() TxValidationCode {
+	if m != nil {
+		return m.ValidationCode
+	}
+	return TxValidationCode_VALID
+}
+
 func init() {
 	proto.RegisterType((*ChaincodeReg)(nil), "protos.ChaincodeReg")
 	proto.RegisterType((*Interest)(nil), "protos.Interest")
@@ -505,6 +733,11 @@ func init() {
 	proto.RegisterType((*Unregister)(nil), "protos.Unregister")
 	proto.RegisterType((*SignedEvent)(nil), "protos.SignedEvent")
 	proto.RegisterType((*Event)(nil), "protos.Event")
+	proto.RegisterType((*ChaincodeEventsRequest)(nil), "protos.ChaincodeEventsRequest")
+	proto.RegisterType((*ChaincodeBlockEvents)(nil), "protos.ChaincodeBlockEvents")
+	proto.RegisterType((*ChaincodeEventsResponse)(nil), "protos.ChaincodeEventsResponse")
+	proto.RegisterType((*TxStatusRequest)(nil), "protos.TxStatusRequest")
+	proto.RegisterType((*TxStatusResponse)(nil), "protos.TxStatusResponse")
 	proto.RegisterEnum("protos.EventType", EventType_name, EventType_value)
 }
 
@@ -614,47 +847,332 @@ var _Events_serviceDesc = grpc.ServiceDesc{
 	Metadata: "peer/events.proto",
 }
 
+// Client API for ChaincodeEvents service
+
+type ChaincodeEventsClient interface {
+	// Deliver sends the events of the committed blocks starting at the requested block,
+	// then the events of the blocks as they are committed
+	Deliver(ctx context.Context, in *common.Envelope, opts ...grpc.CallOption) (ChaincodeEvents_DeliverClient, error)
+}
+
+type chaincodeEventsClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewChaincodeEventsClient(cc *grpc.ClientConn) ChaincodeEventsClient {
+	return &chaincodeEventsClient{cc}
+}
+
+func (c *chaincodeEventsClient) Deliver(ctx context.Context, in *common.Envelope, opts ...grpc.CallOption) (ChaincodeEvents_DeliverClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_ChaincodeEvents_serviceDesc.Streams[0], c.cc, "/protos.ChaincodeEvents/Deliver", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &chaincodeEventsDeliverClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type ChaincodeEvents_DeliverClient interface {
+	Recv() (*ChaincodeEventsResponse, error)
+	grpc.ClientStream
+}
+
+type chaincodeEventsDeliverClient struct {
+	grpc.ClientStream
+}
+
+func (x *chaincodeEventsDeliverClient) Recv() (*ChaincodeEventsResponse, error) {
+	m := new(ChaincodeEventsResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Server API for ChaincodeEvents service
+
+type ChaincodeEventsServer interface {
+	// Deliver sends the events of the committed blocks starting at the requested block,
+	// then the events of the blocks as they are committed
+	Deliver(*common.Envelope, ChaincodeEvents_DeliverServer) error
+}
+
+func RegisterChaincodeEventsServer(s *grpc.Server, srv ChaincodeEventsServer) {
+	s.RegisterService(&_ChaincodeEvents_serviceDesc, srv)
+}
+
+func _ChaincodeEvents_Deliver_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(common.Envelope)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ChaincodeEventsServer).Deliver(m, &chaincodeEventsDeliverServer{stream})
+}
+
+type ChaincodeEvents_DeliverServer interface {
+	Send(*ChaincodeEventsResponse) error
+	grpc.ServerStream
+}
+
+type chaincodeEventsDeliverServer struct {
+	grpc.ServerStream
+}
+
+func (x *chaincodeEventsDeliverServer) Send(m *ChaincodeEventsResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+var _ChaincodeEvents_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "protos.ChaincodeEvents",
+	HandlerType: (*ChaincodeEventsServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Deliver",
+			Handler:       _ChaincodeEvents_Deliver_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "peer/events.proto",
+}
+
+// Client API for TxStatus service
+
+type TxStatusClient interface {
+	// WaitForCommit returns the status of the transaction once it is committed, or right
+	// away if it is already committed
+	WaitForCommit(ctx context.Context, in *common.Envelope, opts ...grpc.CallOption) (*TxStatusResponse, error)
+}
+
+type txStatusClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewTxStatusClient(cc *grpc.ClientConn) TxStatusClient {
+	return &txStatusClient{cc}
+}
+
+func (c *txStatusClient) WaitForCommit(ctx context.Context, in *common.Envelope, opts ...grpc.CallOption) (*TxStatusResponse, error) {
+	out := new(TxStatusResponse)
+	err := grpc.Invoke(ctx, "/protos.TxStatus/WaitForCommit", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Server API for TxStatus service
+
+type TxStatusServer interface {
+	// WaitForCommit returns the status of the transaction once it is committed, or right
+	// away if it is already committed
+	WaitForCommit(context.Context, *common.Envelope) (*TxStatusResponse, error)
+}
+
+func RegisterTxStatusServer(s *grpc.Server, srv TxStatusServer) {
+	s.RegisterService(&_TxStatus_serviceDesc, srv)
+}
+
+func _TxStatus_WaitForCommit_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(common.Envelope)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TxStatusServer).WaitForCommit(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protos.TxStatus/WaitForCommit",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TxStatusServer).WaitForCommit(ctx, req.(*common.Envelope))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _TxStatus_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "protos.TxStatus",
+	HandlerType: (*TxStatusServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "WaitForCommit",
+			Handler:    _TxStatus_WaitForCommit_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "peer/events.proto",
+}
+
+// Client API for Deliver service
+
+type DeliverClient interface {
+	// Deliver first requires an Envelope of type DELIVER_SEEK_INFO with Payload data as a
+	// marshaled orderer.SeekInfo message, then a stream of block replies is received, read
+	// from the ledger of the peer. A FILTERED content type only carries the headers and
+	// metadata of the blocks, except for the config blocks which are sent in full
+	Deliver(ctx context.Context, opts ...grpc.CallOption) (Deliver_DeliverClient, error)
+}
+
+type deliverClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewDeliverClient(cc *grpc.ClientConn) DeliverClient {
+	return &deliverClient{cc}
+}
+
+func (c *deliverClient) Deliver(ctx context.Context, opts ...grpc.CallOption) (Deliver_DeliverClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_Deliver_serviceDesc.Streams[0], c.cc, "/protos.Deliver/Deliver", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &deliverDeliverClient{stream}
+	return x, nil
+}
+
+type Deliver_DeliverClient interface {
+	Send(*common.Envelope) error
+	Recv() (*orderer.DeliverResponse, error)
+	grpc.ClientStream
+}
+
+type deliverDeliverClient struct {
+	grpc.ClientStream
+}
+
+func (x *deliverDeliverClient) Send(m *common.Envelope) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *deliverDeliverClient) Recv() (*orderer.DeliverResponse, error) {
+	m := new(orderer.DeliverResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Server API for Deliver service
+
+type DeliverServer interface {
+	// Deliver first requires an Envelope of type DELIVER_SEEK_INFO with Payload data as a
+	// marshaled orderer.SeekInfo message, then a stream of block replies is received, read
+	// from the ledger of the peer. A FILTERED content type only carries the headers and
+	// metadata of the blocks, except for the config blocks which are sent in full
+	Deliver(Deliver_DeliverServer) error
+}
+
+func RegisterDeliverServer(s *grpc.Server, srv DeliverServer) {
+	s.RegisterService(&_Deliver_serviceDesc, srv)
+}
+
+func _Deliver_Deliver_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(DeliverServer).Deliver(&deliverDeliverServer{stream})
+}
+
+type Deliver_DeliverServer interface {
+	Send(*orderer.DeliverResponse) error
+	Recv() (*common.Envelope, error)
+	grpc.ServerStream
+}
+
+type deliverDeliverServer struct {
+	grpc.ServerStream
+}
+
+func (x *deliverDeliverServer) Send(m *orderer.DeliverResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *deliverDeliverServer) Recv() (*common.Envelope, error) {
+	m := new(common.Envelope)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+var _Deliver_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "protos.Deliver",
+	HandlerType: (*DeliverServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Deliver",
+			Handler:       _Deliver_Deliver_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "peer/events.proto",
+}
+
 func init() { proto.RegisterFile("peer/events.proto", fileDescriptor5) }
 
 var fileDescriptor5 = []byte{
-	// 611 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x94, 0x54, 0x4f, 0x6f, 0x9b, 0x4e,
-	0x10, 0x05, 0x27, 0x76, 0xcc, 0xd8, 0xce, 0xcf, 0xd9, 0xfc, 0x14, 0x21, 0xf7, 0x8f, 0x52, 0xaa,
-	0x4a, 0x6e, 0x0f, 0x26, 0xa5, 0x51, 0x0f, 0xbd, 0x05, 0x82, 0x0a, 0x4d, 0xf3, 0x47, 0x1b, 0xf7,
-	0xd2, 0x43, 0x2d, 0x8c, 0x27, 0x98, 0x26, 0x06, 0x6b, 0xd9, 0x54, 0xf1, 0x27, 0xea, 0xa9, 0xdf,
-	0xb1, 0x62, 0x61, 0x81, 0xb4, 0xa7, 0x9e, 0xf0, 0xcc, 0xbc, 0x37, 0xfb, 0xf6, 0xcd, 0xac, 0x61,
-	0x6f, 0x8d, 0xc8, 0x4c, 0xfc, 0x81, 0x09, 0xcf, 0x26, 0x6b, 0x96, 0xf2, 0x94, 0x74, 0xc4, 0x27,
-	0x1b, 0xed, 0x87, 0xe9, 0x6a, 0x95, 0x26, 0x66, 0xf1, 0x29, 0x8a, 0xa3, 0x91, 0xc0, 0x87, 0xcb,
-	0x20, 0x4e, 0xc2, 0x74, 0x81, 0x33, 0xc1, 0x2c, 0x6b, 0x07, 0xa2, 0xc6, 0x59, 0x90, 0x64, 0x41,
-	0xc8, 0x63, 0xc9, 0x31, 0xae, 0xa0, 0xef, 0x48, 0x02, 0xc5, 0x88, 0xbc, 0x80, 0x7e, 0xdd, 0x20,
-	0x5e, 0xe8, 0xea, 0xa1, 0x3a, 0xd6, 0x68, 0xaf, 0xca, 0xf9, 0x0b, 0xf2, 0x0c, 0x40, 0x74, 0x9e,
-	0x25, 0xc1, 0x0a, 0xf5, 0x96, 0x00, 0x68, 0x22, 0x73, 0x11, 0xac, 0xd0, 0xf8, 0xa9, 0x42, 0xd7,
-	0x4f, 0x38, 0x32, 0xcc, 0x38, 0x39, 0x92, 0x58, 0xbe, 0x59, 0xa3, 0x68, 0xb6, 0x6b, 0xed, 0x15,
-	0x47, 0x67, 0x13, 0x37, 0xaf, 0x4c, 0x37, 0x6b, 0x2c, 0xe9, 0xf9, 0x4f, 0x72, 0x0a, 0xa4, 0x16,
-	0xc0, 0x30, 0x9a, 0xc5, 0xc9, 0x4d, 0x2a, 0x4e, 0xe9, 0x59, 0xff, 0x4b, 0x66, 0x53, 0xb2, 0xa7,
-	0xd0, 0x61, 0xd8, 0x88, 0xfd, 0xe4, 0x26, 0x25, 0x3a, 0xec, 0x88, 0x9c, 0x7f, 0xaa, 0x6f, 0x09,
-	0x81, 0x32, 0xb4, 0x35, 0xd8, 0x29, 0x41, 0xc6, 0x31, 0x74, 0x29, 0x46, 0x71, 0xc6, 0x91, 0x91,
-	0x31, 0x74, 0x0a, 0xa3, 0x75, 0xf5, 0x70, 0x6b, 0xdc, 0xb3, 0x86, 0xf2, 0x28, 0x79, 0x15, 0x5a,
-	0xd6, 0x8d, 0x73, 0xd0, 0x28, 0x7e, 0x47, 0x61, 0x22, 0x79, 0x09, 0x2d, 0xfe, 0x20, 0xee, 0xd5,
-	0xb3, 0xf6, 0x25, 0x65, 0x5a, 0xbb, 0x4c, 0x5b, 0xfc, 0x81, 0x3c, 0x01, 0x0d, 0x19, 0x4b, 0xd9,
-	0x6c, 0x95, 0x45, 0xa5, 0x5f, 0x5d, 0x91, 0x38, 0xcf, 0x22, 0xe3, 0x3d, 0xc0, 0x97, 0x84, 0xfd,
-	0xbb, 0x8c, 0x33, 0xe8, 0x5d, 0xc7, 0x51, 0x82, 0x0b, 0xe1, 0x22, 0x79, 0x0a, 0x5a, 0x16, 0x47,
-	0x49, 0xc0, 0xef, 0x59, 0xe1, 0x73, 0x9f, 0xd6, 0x09, 0xf2, 0xbc, 0x1c, 0x83, 0xbd, 0xe1, 0x98,
-	0x09, 0x09, 0x7d, 0xda, 0xc8, 0x18, 0xbf, 0x5a, 0xd0, 0x2e, 0xfa, 0x4c, 0xa0, 0x2b, 0xc5, 0x94,
-	0xd7, 0xaa, 0x24, 0x48, 0xaf, 0x3c, 0x85, 0x56, 0x18, 0xf2, 0x0a, 0xda, 0xf3, 0xbb, 0x34, 0xbc,
-	0x2d, 0x27, 0x34, 0x98, 0x94, 0x1b, 0x69, 0xe7, 0x49, 0x4f, 0xa1, 0x45, 0x95, 0x9c, 0xc0, 0x7f,
-	0x7f, 0xec, 0xa5, 0x98, 0x4b, 0xcf, 0x3a, 0xf8, 0x6b, 0xa4, 0x42, 0x87, 0xa7, 0xd0, 0xdd, 0xf0,
-	0x51, 0x86, 0xbc, 0x05, 0x8d, 0x49, 0xdf, 0xf5, 0x6d, 0x41, 0xde, 0xab, 0xa5, 0x95, 0x05, 0x4f,
-	0xa1, 0x35, 0x8a, 0x1c, 0x03, 0xdc, 0x57, 0xde, 0xea, 0x6d, 0xc1, 0x21, 0x92, 0x53, 0xbb, 0xee,
-	0x29, 0xb4, 0x81, 0x13, 0xbb, 0xc3, 0x30, 0xe0, 0x29, 0xd3, 0x3b, 0xc2, 0x29, 0x19, 0xda, 0x3b,
-	0xa5, 0x4b, 0x6f, 0x6c, 0xd0, 0xaa, 0xe5, 0x25, 0x7d, 0xe8, 0x52, 0xf7, 0xa3, 0x7f, 0x3d, 0x75,
-	0xe9, 0x50, 0x21, 0x1a, 0xb4, 0xed, 0xcf, 0x97, 0xce, 0xd9, 0x50, 0x25, 0x03, 0xd0, 0x1c, 0xef,
-	0xc4, 0xbf, 0x70, 0x2e, 0x4f, 0xdd, 0x61, 0x2b, 0x0f, 0xa9, 0xfb, 0xc9, 0x75, 0xa6, 0xfe, 0xe5,
-	0xc5, 0x70, 0xcb, 0xfa, 0x00, 0x1d, 0xd1, 0x23, 0x23, 0x47, 0xb0, 0xed, 0x2c, 0x03, 0x4e, 0xaa,
-	0x05, 0x6a, 0x0c, 0x76, 0x34, 0x78, 0xf4, 0x5a, 0x0c, 0x65, 0xac, 0x1e, 0xa9, 0xf6, 0x37, 0x30,
-	0x52, 0x16, 0x4d, 0x96, 0x9b, 0x35, 0xb2, 0x3b, 0x5c, 0x44, 0xc8, 0x26, 0x37, 0xc1, 0x9c, 0xc5,
-	0xa1, 0x04, 0xe7, 0xaf, 0xdd, 0x1e, 0x14, 0xfd, 0xaf, 0x82, 0xf0, 0x36, 0x88, 0xf0, 0xeb, 0xeb,
-	0x28, 0xe6, 0xcb, 0xfb, 0x79, 0x3e, 0x21, 0xb3, 0xc1, 0x34, 0x0b, 0xa6, 0x59, 0x30, 0xcd, 0x9c,
-	0x39, 0x2f, 0xfe, 0x66, 0xde, 0xfd, 0x0e, 0x00, 0x00, 0xff, 0xff, 0x19, 0x83, 0x34, 0xd3, 0x82,
-	0x04, 0x00, 0x00,
+	// 898 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xa4, 0x55, 0xdd, 0x6e, 0xe3, 0x44,
+	0x14, 0xb6, 0xd3, 0x24, 0x8d, 0x4f, 0x7e, 0xea, 0x4e, 0x57, 0xc5, 0x0a, 0x0b, 0x5b, 0x8c, 0x58,
+	0x15, 0x2e, 0x9c, 0x62, 0x56, 0x5c, 0x80, 0x90, 0xa8, 0xd3, 0x2c, 0x36, 0xcb, 0xb6, 0xab, 0x69,
+	0x00, 0x89, 0x0b, 0x22, 0xc7, 0x99, 0xba, 0x66, 0x13, 0x3b, 0x8c, 0x27, 0x51, 0x2a, 0xde, 0x81,
+	0x67, 0xe0, 0x8e, 0x2b, 0xde, 0x11, 0xcd, 0x78, 0xc6, 0x4e, 0xda, 0x65, 0x25, 0xb4, 0x57, 0xc9,
+	0x7c, 0xe7, 0xef, 0x9b, 0xef, 0x1c, 0x9f, 0x81, 0xc3, 0x25, 0x21, 0x74, 0x40, 0xd6, 0x24, 0x65,
+	0xb9, 0xb3, 0xa4, 0x19, 0xcb, 0x50, 0x53, 0xfc, 0xe4, 0xfd, 0xa3, 0x28, 0x5b, 0x2c, 0xb2, 0x74,
+	0x50, 0xfc, 0x14, 0xc6, 0xbe, 0x99, 0xd1, 0x19, 0xa1, 0x84, 0x0e, 0xc2, 0xa9, 0x44, 0xfa, 0x22,
+	0x43, 0x74, 0x1b, 0x26, 0x69, 0x94, 0xcd, 0xc8, 0x44, 0xe4, 0x92, 0xb6, 0x63, 0x61, 0x63, 0x34,
+	0x4c, 0xf3, 0x30, 0x62, 0x89, 0xca, 0x62, 0xbf, 0x82, 0xce, 0x50, 0x05, 0x60, 0x12, 0xa3, 0x8f,
+	0xa0, 0x53, 0x25, 0x48, 0x66, 0x96, 0x7e, 0xa2, 0x9f, 0x1a, 0xb8, 0x5d, 0x62, 0xc1, 0x0c, 0x7d,
+	0x00, 0x20, 0x32, 0x4f, 0xd2, 0x70, 0x41, 0xac, 0x9a, 0x70, 0x30, 0x04, 0x72, 0x19, 0x2e, 0x88,
+	0xfd, 0xb7, 0x0e, 0xad, 0x20, 0x65, 0x84, 0x92, 0x9c, 0xa1, 0x33, 0xe5, 0xcb, 0xee, 0x96, 0x44,
+	0x24, 0xeb, 0xb9, 0x87, 0x45, 0xe9, 0xdc, 0x19, 0x71, 0xcb, 0xf8, 0x6e, 0x49, 0x64, 0x38, 0xff,
+	0x8b, 0x2e, 0x00, 0x55, 0x04, 0x28, 0x89, 0x27, 0x49, 0x7a, 0x93, 0x89, 0x2a, 0x6d, 0xf7, 0x91,
+	0x8a, 0xdc, 0xa6, 0xec, 0x6b, 0xd8, 0x8c, 0xb6, 0xce, 0x41, 0x7a, 0x93, 0x21, 0x0b, 0xf6, 0x05,
+	0x16, 0x5c, 0x58, 0x7b, 0x82, 0xa0, 0x3a, 0x7a, 0x06, 0xec, 0x4b, 0x27, 0xfb, 0x19, 0xb4, 0x30,
+	0x89, 0x93, 0x9c, 0x11, 0x8a, 0x4e, 0xa1, 0x59, 0x48, 0x6f, 0xe9, 0x27, 0x7b, 0xa7, 0x6d, 0xd7,
+	0x54, 0xa5, 0xd4, 0x55, 0xb0, 0xb4, 0xdb, 0x2f, 0xc1, 0xc0, 0xe4, 0x37, 0x22, 0x44, 0x44, 0x1f,
+	0x43, 0x8d, 0x6d, 0xc4, 0xbd, 0xda, 0xee, 0x91, 0x0a, 0x19, 0x57, 0x2a, 0xe3, 0x1a, 0xdb, 0xa0,
+	0xf7, 0xc1, 0x20, 0x94, 0x66, 0x74, 0xb2, 0xc8, 0x63, 0xa9, 0x57, 0x4b, 0x00, 0x2f, 0xf3, 0xd8,
+	0xfe, 0x12, 0xe0, 0xc7, 0x94, 0xfe, 0x7f, 0x1a, 0x2f, 0xa0, 0x7d, 0x9d, 0xc4, 0x29, 0x99, 0x09,
+	0x15, 0xd1, 0x63, 0x30, 0xf2, 0x24, 0x4e, 0x43, 0xb6, 0xa2, 0x85, 0xce, 0x1d, 0x5c, 0x01, 0xe8,
+	0x43, 0xd9, 0x06, 0xef, 0x8e, 0x91, 0x5c, 0x50, 0xe8, 0xe0, 0x2d, 0xc4, 0xfe, 0xa7, 0x06, 0x8d,
+	0x22, 0x8f, 0x03, 0x2d, 0x45, 0x46, 0x5e, 0xab, 0xa4, 0xa0, 0xb4, 0xf2, 0x35, 0x5c, 0xfa, 0xa0,
+	0x4f, 0xa0, 0x31, 0x9d, 0x67, 0xd1, 0x6b, 0xd9, 0xa1, 0xae, 0x23, 0x67, 0xd4, 0xe3, 0xa0, 0xaf,
+	0xe1, 0xc2, 0x8a, 0xce, 0xe1, 0xe0, 0xde, 0x5c, 0x8a, 0xbe, 0xb4, 0xdd, 0xe3, 0x07, 0x2d, 0x15,
+	0x3c, 0x7c, 0x0d, 0xf7, 0xa2, 0x1d, 0x04, 0x7d, 0x0e, 0x06, 0x55, 0xba, 0x5b, 0x75, 0x11, 0x7c,
+	0x58, 0x51, 0x93, 0x06, 0x5f, 0xc3, 0x95, 0x17, 0x7a, 0x06, 0xb0, 0x2a, 0xb5, 0xb5, 0x1a, 0x22,
+	0x06, 0xa9, 0x98, 0x4a, 0x75, 0x5f, 0xc3, 0x5b, 0x7e, 0x62, 0x76, 0x28, 0x09, 0x59, 0x46, 0xad,
+	0xa6, 0x50, 0x4a, 0x1d, 0xbd, 0x7d, 0xa9, 0x92, 0xfd, 0x07, 0x1c, 0xef, 0xf2, 0xcd, 0x31, 0xf9,
+	0x7d, 0xc5, 0x07, 0xfe, 0x9d, 0xbf, 0x1f, 0xf4, 0x04, 0xda, 0x39, 0x0b, 0x29, 0x9b, 0x14, 0xba,
+	0x72, 0x99, 0xea, 0x18, 0x04, 0x24, 0x44, 0xb5, 0x13, 0x78, 0x54, 0x16, 0x17, 0x48, 0xc1, 0x80,
+	0x97, 0x16, 0x21, 0x93, 0x74, 0xb5, 0x98, 0xca, 0xf6, 0xd5, 0x71, 0x5b, 0x60, 0x97, 0x02, 0x42,
+	0x4e, 0x39, 0x5e, 0xb5, 0x93, 0xbd, 0xff, 0x56, 0xbf, 0x1c, 0xb2, 0x3f, 0x75, 0x78, 0xef, 0xc1,
+	0x45, 0xf3, 0x65, 0x96, 0xe6, 0x84, 0x8f, 0x6a, 0xce, 0x42, 0xb6, 0xca, 0xe5, 0x67, 0xdd, 0x53,
+	0xad, 0xbf, 0x16, 0xa8, 0xaf, 0x61, 0x69, 0x47, 0xe7, 0x8a, 0x58, 0x59, 0x9b, 0x37, 0xe2, 0xf1,
+	0x83, 0xda, 0x5b, 0x97, 0xf1, 0x35, 0x49, 0xbc, 0x38, 0x7a, 0x4d, 0xa8, 0xf3, 0xed, 0x60, 0x3f,
+	0x85, 0x83, 0xf1, 0xa6, 0x28, 0xa0, 0x14, 0x3f, 0x82, 0x06, 0xdb, 0x54, 0x52, 0xd7, 0xd9, 0x26,
+	0x98, 0xd9, 0x7f, 0xe9, 0x60, 0x56, 0x8e, 0x92, 0xf1, 0xd3, 0xb7, 0x33, 0x2e, 0xf9, 0xde, 0x17,
+	0xb2, 0xf6, 0x50, 0xc8, 0x73, 0x38, 0x58, 0x87, 0xf3, 0x64, 0x16, 0xf2, 0x39, 0x9b, 0x70, 0xf2,
+	0xa2, 0x51, 0x3d, 0xd7, 0x2a, 0x97, 0xc0, 0xe6, 0xa7, 0xd2, 0x61, 0xc8, 0x37, 0x53, 0x6f, 0xbd,
+	0x73, 0xfe, 0xcc, 0x03, 0xa3, 0x5c, 0x80, 0xa8, 0x03, 0x2d, 0x3c, 0xfa, 0x2e, 0xb8, 0x1e, 0x8f,
+	0xb0, 0xa9, 0x21, 0x03, 0x1a, 0xde, 0x0f, 0x57, 0xc3, 0x17, 0xa6, 0x8e, 0xba, 0x60, 0x0c, 0xfd,
+	0xf3, 0xe0, 0x72, 0x78, 0x75, 0x31, 0x32, 0x6b, 0xfc, 0x88, 0x47, 0xdf, 0x8f, 0x86, 0xe3, 0xe0,
+	0xea, 0xd2, 0xdc, 0x73, 0xbf, 0x82, 0xa6, 0x6c, 0xfe, 0x19, 0xd4, 0x87, 0xb7, 0x21, 0x43, 0xe5,
+	0x12, 0xda, 0x5a, 0x0e, 0xfd, 0xee, 0xce, 0xc6, 0xb5, 0xb5, 0x53, 0xfd, 0x4c, 0x77, 0xaf, 0xe1,
+	0xe0, 0x5e, 0x6b, 0xd1, 0xb7, 0xb0, 0x7f, 0x41, 0xe6, 0xc9, 0x9a, 0x50, 0x64, 0x2a, 0x6d, 0x46,
+	0xe9, 0x9a, 0xcc, 0xb3, 0x25, 0xe9, 0x3f, 0x79, 0xf3, 0xac, 0x94, 0xf2, 0xda, 0xda, 0x99, 0xee,
+	0x06, 0xd0, 0x52, 0xb2, 0xa3, 0x6f, 0xa0, 0xfb, 0x73, 0x98, 0xb0, 0xe7, 0x19, 0x1d, 0x66, 0x8b,
+	0x45, 0xc2, 0xde, 0x90, 0x73, 0x4b, 0xad, 0xdd, 0x5e, 0xd9, 0x9a, 0xfb, 0xbc, 0x22, 0xf3, 0xf5,
+	0xdb, 0x78, 0x59, 0x8e, 0x7c, 0x08, 0x1d, 0xe9, 0x53, 0xe5, 0xe0, 0xf7, 0xf4, 0x7e, 0x05, 0x3b,
+	0xa3, 0xb1, 0x73, 0x7b, 0xb7, 0x24, 0x74, 0x4e, 0x66, 0x31, 0xa1, 0xce, 0x4d, 0x38, 0xa5, 0x49,
+	0xa4, 0x6a, 0xf3, 0x97, 0xd1, 0xeb, 0x16, 0x97, 0x79, 0x15, 0x46, 0xaf, 0xc3, 0x98, 0xfc, 0xf2,
+	0x69, 0x9c, 0xb0, 0xdb, 0xd5, 0x94, 0x17, 0x1b, 0x6c, 0x45, 0x0e, 0x8a, 0xc8, 0x41, 0x11, 0x39,
+	0xe0, 0x91, 0xd3, 0xe2, 0x91, 0xfe, 0xe2, 0xdf, 0x01, 0x00, 0x17, 0x18, 0xe4, 0x8c, 0xc0, 0x07,
+	0x00, 0x00,
 }
@@ -0,0 +1,434 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: peer/introspection.proto
+
+package peer
+
+import proto "github.com/golang/protobuf/proto"
+import fmt "fmt"
+import math "math"
+import common "github.com/hyperledger/fabric/protos/common"
+
+import (
+	context "golang.org/x/net/context"
+	grpc "google.golang.org/grpc"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+type ChaincodeContainerStatus_State int32
+
+const (
+	ChaincodeContainerStatus_UNKNOWN ChaincodeContainerStatus_State = 0
+	// LAUNCHING chaincodes are starting and not registered yet
+	ChaincodeContainerStatus_LAUNCHING ChaincodeContainerStatus_State = 1
+	// RUNNING chaincodes are registered with the peer
+	ChaincodeContainerStatus_RUNNING ChaincodeContainerStatus_State = 2
+	// RESTARTING chaincodes terminated unexpectedly and are being restarted
+	ChaincodeContainerStatus_RESTARTING ChaincodeContainerStatus_State = 3
+)
+
+var ChaincodeContainerStatus_State_name = map[int32]string{
+	0: "UNKNOWN",
+	1: "LAUNCHING",
+	2: "RUNNING",
+	3: "RESTARTING",
+}
+var ChaincodeContainerStatus_State_value = map[string]int32{
+	"UNKNOWN":    0,
+	"LAUNCHING":  1,
+	"RUNNING":    2,
+	"RESTARTING": 3,
+}
+
+func (x ChaincodeContainerStatus_State) String() string {
+	return proto.EnumName(ChaincodeContainerStatus_State_name, int32(x))
+}
+func (ChaincodeContainerStatus_State) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor13, []int{5, 0}
+}
+
+// ChannelLedgerInfo is the height of the ledger of a channel joined by the peer
+type ChannelLedgerInfo struct {
+	ChannelId        string `protobuf:"bytes,1,opt,name=channel_id,json=channelId" json:"channel_id,omitempty"`
+	Height           uint64 `protobuf:"varint,2,opt,name=height" json:"height,omitempty"`
+	CurrentBlockHash []byte `protobuf:"bytes,3,opt,name=current_block_hash,json=currentBlockHash,proto3" json:"current_block_hash,omitempty"`
+}
+
+func (m *ChannelLedgerInfo) Reset()                    { *m = ChannelLedgerInfo{} }
+func (m *ChannelLedgerInfo) String() string            { return proto.CompactTextString(m) }
+func (*ChannelLedgerInfo) ProtoMessage()               {}
+func (*ChannelLedgerInfo) Descriptor() ([]byte, []int) { return fileDescriptor13, []int{0} }
+
+func (m *ChannelLedgerInfo) GetChannelId() string {
+	if m != nil {
+		return m.ChannelId
+	}
+	return ""
+}
+
+func (m *ChannelLedgerInfo) GetHeight() uint64 {
+	if m != nil {
+		return m.Height
+	}
+	return 0
+}
+
+func (m *ChannelLedgerInfo) GetCurrentBlockHash() []byte {
+	if m != nil {
+		return m.CurrentBlockHash
+	}
+	return nil
+}
+
+type ChannelsIntrospection struct {
+	Channels []*ChannelLedgerInfo `protobuf:"bytes,1,rep,name=channels" json:"channels,omitempty"`
+}
+
+func (m *ChannelsIntrospection) Reset()                    { *m = ChannelsIntrospection{} }
+func (m *ChannelsIntrospection) String() string            { return proto.CompactTextString(m) }
+func (*ChannelsIntrospection) ProtoMessage()               {}
+func (*ChannelsIntrospection) Descriptor() ([]byte, []int) { return fileDescriptor13, []int{1} }
+
+func (m *ChannelsIntrospection) GetChannels() []*ChannelLedgerInfo {
+	if m != nil {
+		return m.Channels
+	}
+	return nil
+}
+
+// GossipMember is a peer known alive by the gossip of the peer
+type GossipMember struct {
+	Endpoint string `protobuf:"bytes,1,opt,name=endpoint" json:"endpoint,omitempty"`
+	PkiId    []byte `protobuf:"bytes,2,opt,name=pki_id,json=pkiId,proto3" json:"pki_id,omitempty"`
+	// ledger_height is the height of the ledger of the channel as advertised by
+	// the peer, 0 in the membership not scoped to a channel
+	LedgerHeight uint64 `protobuf:"varint,3,opt,name=ledger_height,json=ledgerHeight" json:"ledger_height,omitempty"`
+}
+
+func (m *GossipMember) Reset()                    { *m = GossipMember{} }
+func (m *GossipMember) String() string            { return proto.CompactTextString(m) }
+func (*GossipMember) ProtoMessage()               {}
+func (*GossipMember) Descriptor() ([]byte, []int) { return fileDescriptor13, []int{2} }
+
+func (m *GossipMember) GetEndpoint() string {
+	if m != nil {
+		return m.Endpoint
+	}
+	return ""
+}
+
+func (m *GossipMember) GetPkiId() []byte {
+	if m != nil {
+		return m.PkiId
+	}
+	return nil
+}
+
+func (m *GossipMember) GetLedgerHeight() uint64 {
+	if m != nil {
+		return m.LedgerHeight
+	}
+	return 0
+}
+
+type GossipChannelMembership struct {
+	ChannelId string          `protobuf:"bytes,1,opt,name=channel_id,json=channelId" json:"channel_id,omitempty"`
+	Peers     []*GossipMember `protobuf:"bytes,2,rep,name=peers" json:"peers,omitempty"`
+}
+
+func (m *GossipChannelMembership) Reset()                    { *m = GossipChannelMembership{} }
+func (m *GossipChannelMembership) String() string            { return proto.CompactTextString(m) }
+func (*GossipChannelMembership) ProtoMessage()               {}
+func (*GossipChannelMembership) Descriptor() ([]byte, []int) { return fileDescriptor13, []int{3} }
+
+func (m *GossipChannelMembership) GetChannelId() string {
+	if m != nil {
+		return m.ChannelId
+	}
+	return ""
+}
+
+func (m *GossipChannelMembership) GetPeers() []*GossipMember {
+	if m != nil {
+		return m.Peers
+	}
+	return nil
+}
+
+type GossipMembership struct {
+	Peers    []*GossipMember            `protobuf:"bytes,1,rep,name=peers" json:"peers,omitempty"`
+	Channels []*GossipChannelMembership `protobuf:"bytes,2,rep,name=channels" json:"channels,omitempty"`
+}
+
+func (m *GossipMembership) Reset()                    { *m = GossipMembership{} }
+func (m *GossipMembership) String() string            { return proto.CompactTextString(m) }
+func (*GossipMembership) ProtoMessage()               {}
+func (*GossipMembership) Descriptor() ([]byte, []int) { return fileDescriptor13, []int{4} }
+
+func (m *GossipMembership) GetPeers() []*GossipMember {
+	if m != nil {
+		return m.Peers
+	}
+	return nil
+}
+
+func (m *GossipMembership) GetChannels() []*GossipChannelMembership {
+	if m != nil {
+		return m.Channels
+	}
+	return nil
+}
+
+// ChaincodeContainerStatus is the status of a chaincode known to the peer, identified
+// by its canonical name, that is name:version
+type ChaincodeContainerStatus struct {
+	CanonicalName string                         `protobuf:"bytes,1,opt,name=canonical_name,json=canonicalName" json:"canonical_name,omitempty"`
+	State         ChaincodeContainerStatus_State `protobuf:"varint,2,opt,name=state,enum=protos.ChaincodeContainerStatus_State" json:"state,omitempty"`
+	// container is true once the peer launched a container for the chaincode, which
+	// it monitors. The system chaincodes and the chaincodes run by the user in
+	// development mode have none
+	Container bool `protobuf:"varint,3,opt,name=container" json:"container,omitempty"`
+}
+
+func (m *ChaincodeContainerStatus) Reset()                    { *m = ChaincodeContainerStatus{} }
+func (m *ChaincodeContainerStatus) String() string            { return proto.CompactTextString(m) }
+func (*ChaincodeContainerStatus) ProtoMessage()               {}
+func (*ChaincodeContainerStatus) Descriptor() ([]byte, []int) { return fileDescriptor13, []int{5} }
+
+func (m *ChaincodeContainerStatus) GetCanonicalName() string {
+	if m != nil {
+		return m.CanonicalName
+	}
+	return ""
+}
+
+func (m *ChaincodeContainerStatus) GetState() ChaincodeContainerStatus_State {
+	if m != nil {
+		return m.State
+	}
+	return ChaincodeContainerStatus_UNKNOWN
+}
+
+func (m *ChaincodeContainerStatus) GetContainer() bool {
+	if m != nil {
+		return m.Container
+	}
+	return false
+}
+
+type ChaincodeContainers struct {
+	Containers []*ChaincodeContainerStatus `protobuf:"bytes,1,rep,name=containers" json:"containers,omitempty"`
+}
+
+func (m *ChaincodeContainers) Reset()                    { *m = ChaincodeContainers{} }
+func (m *ChaincodeContainers) String() string            { return proto.CompactTextString(m) }
+func (*ChaincodeContainers) ProtoMessage()               {}
+func (*ChaincodeContainers) Descriptor() ([]byte, []int) { return fileDescriptor13, []int{6} }
+
+func (m *ChaincodeContainers) GetContainers() []*ChaincodeContainerStatus {
+	if m != nil {
+		return m.Containers
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*ChannelLedgerInfo)(nil), "protos.ChannelLedgerInfo")
+	proto.RegisterType((*ChannelsIntrospection)(nil), "protos.ChannelsIntrospection")
+	proto.RegisterType((*GossipMember)(nil), "protos.GossipMember")
+	proto.RegisterType((*GossipChannelMembership)(nil), "protos.GossipChannelMembership")
+	proto.RegisterType((*GossipMembership)(nil), "protos.GossipMembership")
+	proto.RegisterType((*ChaincodeContainerStatus)(nil), "protos.ChaincodeContainerStatus")
+	proto.RegisterType((*ChaincodeContainers)(nil), "protos.ChaincodeContainers")
+	proto.RegisterEnum("protos.ChaincodeContainerStatus_State", ChaincodeContainerStatus_State_name, ChaincodeContainerStatus_State_value)
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// Client API for Introspection service
+
+type IntrospectionClient interface {
+	// GetChannels returns the channels joined by the peer and the heights of their ledgers
+	GetChannels(ctx context.Context, in *common.Envelope, opts ...grpc.CallOption) (*ChannelsIntrospection, error)
+	// GetGossipMembership returns the peers known alive by the gossip of the peer
+	GetGossipMembership(ctx context.Context, in *common.Envelope, opts ...grpc.CallOption) (*GossipMembership, error)
+	// GetChaincodeContainers returns the status of the chaincode containers of the peer
+	GetChaincodeContainers(ctx context.Context, in *common.Envelope, opts ...grpc.CallOption) (*ChaincodeContainers, error)
+}
+
+type introspectionClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewIntrospectionClient(cc *grpc.ClientConn) IntrospectionClient {
+	return &introspectionClient{cc}
+}
+
+func (c *introspectionClient) GetChannels(ctx context.Context, in *common.Envelope, opts ...grpc.CallOption) (*ChannelsIntrospection, error) {
+	out := new(ChannelsIntrospection)
+	err := grpc.Invoke(ctx, "/protos.Introspection/GetChannels", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *introspectionClient) GetGossipMembership(ctx context.Context, in *common.Envelope, opts ...grpc.CallOption) (*GossipMembership, error) {
+	out := new(GossipMembership)
+	err := grpc.Invoke(ctx, "/protos.Introspection/GetGossipMembership", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *introspectionClient) GetChaincodeContainers(ctx context.Context, in *common.Envelope, opts ...grpc.CallOption) (*ChaincodeContainers, error) {
+	out := new(ChaincodeContainers)
+	err := grpc.Invoke(ctx, "/protos.Introspection/GetChaincodeContainers", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Server API for Introspection service
+
+type IntrospectionServer interface {
+	// GetChannels returns the channels joined by the peer and the heights of their ledgers
+	GetChannels(context.Context, *common.Envelope) (*ChannelsIntrospection, error)
+	// GetGossipMembership returns the peers known alive by the gossip of the peer
+	GetGossipMembership(context.Context, *common.Envelope) (*GossipMembership, error)
+	// GetChaincodeContainers returns the status of the chaincode containers of the peer
+	GetChaincodeContainers(context.Context, *common.Envelope) (*ChaincodeContainers, error)
+}
+
+func RegisterIntrospectionServer(s *grpc.Server, srv IntrospectionServer) {
+	s.RegisterService(&_Introspection_serviceDesc, srv)
+}
+
+func _Introspection_GetChannels_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(common.Envelope)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IntrospectionServer).GetChannels(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protos.Introspection/GetChannels",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IntrospectionServer).GetChannels(ctx, req.(*common.Envelope))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Introspection_GetGossipMembership_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(common.Envelope)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IntrospectionServer).GetGossipMembership(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protos.Introspection/GetGossipMembership",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IntrospectionServer).GetGossipMembership(ctx, req.(*common.Envelope))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Introspection_GetChaincodeContainers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(common.Envelope)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IntrospectionServer).GetChaincodeContainers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protos.Introspection/GetChaincodeContainers",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IntrospectionServer).GetChaincodeContainers(ctx, req.(*common.Envelope))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _Introspection_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "protos.Introspection",
+	HandlerType: (*IntrospectionServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetChannels",
+			Handler:    _Introspection_GetChannels_Handler,
+		},
+		{
+			MethodName: "GetGossipMembership",
+			Handler:    _Introspection_GetGossipMembership_Handler,
+		},
+		{
+			MethodName: "GetChaincodeContainers",
+			Handler:    _Introspection_GetChaincodeContainers_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "peer/introspection.proto",
+}
+
+func init() { proto.RegisterFile("peer/introspection.proto", fileDescriptor13) }
+
+var fileDescriptor13 = []byte{
+	// 591 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x84, 0x54, 0x5d, 0x6f, 0xd3, 0x3c,
+	0x14, 0x6e, 0xda, 0xb7, 0x7b, 0xd7, 0xb3, 0x75, 0x2a, 0xde, 0x07, 0x61, 0x30, 0x51, 0x05, 0x81,
+	0x0a, 0x42, 0x89, 0x34, 0xc4, 0x15, 0x5c, 0x6c, 0xad, 0xa6, 0x36, 0x62, 0x04, 0x94, 0xad, 0x9a,
+	0xc4, 0x4d, 0xe5, 0x26, 0xa7, 0x8d, 0xd5, 0xd6, 0x8e, 0x62, 0x17, 0x81, 0xf8, 0xb1, 0x88, 0x7f,
+	0x82, 0x12, 0xa7, 0x25, 0x25, 0x43, 0xbd, 0xb2, 0xce, 0xd7, 0x73, 0x9e, 0xe7, 0xf8, 0xd8, 0x60,
+	0xc6, 0x88, 0x89, 0xc3, 0xb8, 0x4a, 0x84, 0x8c, 0x31, 0x50, 0x4c, 0x70, 0x3b, 0x4e, 0x84, 0x12,
+	0x64, 0x27, 0x3b, 0xe4, 0xe9, 0x61, 0x20, 0x16, 0x0b, 0xc1, 0x1d, 0x7d, 0xe8, 0xa0, 0xf5, 0x0d,
+	0x1e, 0xf4, 0x22, 0xca, 0x39, 0xce, 0xaf, 0x31, 0x9c, 0x62, 0xe2, 0xf2, 0x89, 0x20, 0x67, 0x00,
+	0x81, 0x76, 0x8e, 0x58, 0x68, 0x1a, 0x6d, 0xa3, 0xd3, 0xf0, 0x1b, 0xb9, 0xc7, 0x0d, 0xc9, 0x09,
+	0xec, 0x44, 0xc8, 0xa6, 0x91, 0x32, 0xab, 0x6d, 0xa3, 0xf3, 0x9f, 0x9f, 0x5b, 0xe4, 0x35, 0x90,
+	0x60, 0x99, 0x24, 0xc8, 0xd5, 0x68, 0x3c, 0x17, 0xc1, 0x6c, 0x14, 0x51, 0x19, 0x99, 0xb5, 0xb6,
+	0xd1, 0xd9, 0xf7, 0x5b, 0x79, 0xa4, 0x9b, 0x06, 0x06, 0x54, 0x46, 0x96, 0x07, 0xc7, 0x79, 0x67,
+	0xe9, 0x16, 0x59, 0x93, 0xb7, 0xb0, 0x9b, 0xf7, 0x92, 0xa6, 0xd1, 0xae, 0x75, 0xf6, 0xce, 0x1f,
+	0x69, 0xb2, 0xd2, 0x2e, 0x51, 0xf5, 0xd7, 0xa9, 0xd6, 0x04, 0xf6, 0xfb, 0x42, 0x4a, 0x16, 0x7f,
+	0xc4, 0xc5, 0x18, 0x13, 0x72, 0x0a, 0xbb, 0xc8, 0xc3, 0x58, 0x30, 0xae, 0x72, 0x09, 0x6b, 0x9b,
+	0x1c, 0xc3, 0x4e, 0x3c, 0x63, 0xa9, 0xb8, 0x6a, 0xc6, 0xae, 0x1e, 0xcf, 0x98, 0x1b, 0x92, 0x67,
+	0xd0, 0x9c, 0x67, 0xd0, 0xa3, 0x5c, 0x5f, 0x2d, 0xd3, 0xb7, 0xaf, 0x9d, 0x83, 0xcc, 0x67, 0x85,
+	0xf0, 0x50, 0xf7, 0xc9, 0xc9, 0xe8, 0x76, 0x32, 0x62, 0xf1, 0xb6, 0xb9, 0xbd, 0x82, 0x7a, 0x7a,
+	0x49, 0xd2, 0xac, 0x66, 0xaa, 0x8e, 0x56, 0xaa, 0x8a, 0xb4, 0x7d, 0x9d, 0x62, 0xfd, 0x80, 0x56,
+	0xd1, 0x9d, 0xc1, 0xaf, 0xeb, 0x8d, 0xad, 0xf5, 0xe4, 0x5d, 0x61, 0x88, 0xba, 0xdd, 0xd3, 0xcd,
+	0xf4, 0x12, 0xfb, 0xc2, 0x28, 0x7f, 0x1a, 0x60, 0xf6, 0x22, 0xca, 0x78, 0x20, 0x42, 0xec, 0x09,
+	0xae, 0x28, 0xe3, 0x98, 0xdc, 0x28, 0xaa, 0x96, 0x92, 0x3c, 0x87, 0x83, 0x80, 0x72, 0xc1, 0x59,
+	0x40, 0xe7, 0x23, 0x4e, 0x17, 0x98, 0x0b, 0x6d, 0xae, 0xbd, 0x1e, 0x5d, 0x20, 0x79, 0x0f, 0x75,
+	0xa9, 0xa8, 0xc2, 0x6c, 0xc2, 0x07, 0xe7, 0x2f, 0x0a, 0x57, 0x78, 0x2f, 0xae, 0x9d, 0x1e, 0xe8,
+	0xeb, 0x22, 0xf2, 0x04, 0x1a, 0xc1, 0x2a, 0x9e, 0xdd, 0xc2, 0xae, 0xff, 0xc7, 0x61, 0x5d, 0x40,
+	0x3d, 0xcb, 0x26, 0x7b, 0xf0, 0xff, 0xd0, 0xfb, 0xe0, 0x7d, 0xba, 0xf3, 0x5a, 0x15, 0xd2, 0x84,
+	0xc6, 0xf5, 0xe5, 0xd0, 0xeb, 0x0d, 0x5c, 0xaf, 0xdf, 0x32, 0xd2, 0x98, 0x3f, 0xf4, 0xbc, 0xd4,
+	0xa8, 0x92, 0x03, 0x00, 0xff, 0xea, 0xe6, 0xf6, 0xd2, 0xbf, 0x4d, 0xed, 0x9a, 0x75, 0x07, 0x87,
+	0x65, 0x22, 0x92, 0x5c, 0x00, 0xac, 0xbb, 0xac, 0xc6, 0xdc, 0xde, 0xc6, 0xdc, 0x2f, 0xd4, 0x9c,
+	0xff, 0x32, 0xa0, 0xb9, 0xb9, 0xce, 0x17, 0xb0, 0xd7, 0x47, 0xb5, 0x5a, 0x75, 0xd2, 0xb2, 0xf3,
+	0xf7, 0x77, 0xc5, 0xbf, 0xe2, 0x5c, 0xc4, 0x78, 0x7a, 0xf6, 0xd7, 0x76, 0x6f, 0x3e, 0x07, 0xab,
+	0x42, 0x7a, 0x70, 0xd8, 0x47, 0x55, 0x5a, 0x87, 0x32, 0x92, 0x79, 0xdf, 0x46, 0xa4, 0xb9, 0x56,
+	0x85, 0xb8, 0x70, 0xa2, 0x69, 0x94, 0x44, 0x97, 0x71, 0x1e, 0xff, 0x5b, 0xb2, 0xb4, 0x2a, 0x5d,
+	0x04, 0x4b, 0x24, 0x53, 0x3b, 0xfa, 0x1e, 0x63, 0xa2, 0x9f, 0x86, 0x3d, 0xa1, 0xe3, 0x84, 0x05,
+	0xab, 0xb2, 0x74, 0x05, 0xbb, 0x47, 0x1b, 0x32, 0x3e, 0xd3, 0x60, 0x46, 0xa7, 0xf8, 0xe5, 0xe5,
+	0x94, 0xa9, 0x68, 0x39, 0x4e, 0x5b, 0x3a, 0x05, 0x00, 0x47, 0x03, 0x38, 0x1a, 0xc0, 0x49, 0x01,
+	0xc6, 0xfa, 0xdf, 0x7a, 0xf3, 0x7b, 0x00, 0xf4, 0xcd, 0x90, 0xd3, 0xda, 0x04, 0x00, 0x00,
+}
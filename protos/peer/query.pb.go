@@ -6,6 +6,7 @@ package peer
 import proto "github.com/golang/protobuf/proto"
 import fmt "fmt"
 import math "math"
+import common "github.com/hyperledger/fabric/protos/common"
 
 // Reference imports to suppress errors if they are not otherwise used.
 var _ = proto.Marshal
@@ -133,33 +134,95 @@ func (m *ChannelInfo) GetChannelId() string {
 	return ""
 }
 
+// BlocksQueryResponse returns a page of the blocks of a range queried in
+// qscc.go by GetBlocksByRange. The bookmark is the number of the block the
+// next page starts at, and is blank when there are no more blocks in the range
+type BlocksQueryResponse struct {
+	Blocks   []*common.Block `protobuf:"bytes,1,rep,name=blocks" json:"blocks,omitempty"`
+	Bookmark string          `protobuf:"bytes,2,opt,name=bookmark" json:"bookmark,omitempty"`
+}
+
+func (m *BlocksQueryResponse) Reset()                    { *m = BlocksQueryResponse{} }
+func (m *BlocksQueryResponse) String() string            { return proto.CompactTextString(m) }
+func (*BlocksQueryResponse) ProtoMessage()               {}
+func (*BlocksQueryResponse) Descriptor() ([]byte, []int) { return fileDescriptor9, []int{4} }
+
+func (m *BlocksQueryResponse) GetBlocks() []*common.Block {
+	if m != nil {
+		return m.Blocks
+	}
+	return nil
+}
+
+func (m *BlocksQueryResponse) GetBookmark() string {
+	if m != nil {
+		return m.Bookmark
+	}
+	return ""
+}
+
+// TransactionsQueryResponse returns a page of the transactions queried in
+// qscc.go by GetTransactionsByCreator. The bookmark locates the transaction
+// the next page starts at, and is blank when there are no more transactions
+type TransactionsQueryResponse struct {
+	Transactions []*ProcessedTransaction `protobuf:"bytes,1,rep,name=transactions" json:"transactions,omitempty"`
+	Bookmark     string                  `protobuf:"bytes,2,opt,name=bookmark" json:"bookmark,omitempty"`
+}
+
+func (m *TransactionsQueryResponse) Reset()                    { *m = TransactionsQueryResponse{} }
+func (m *TransactionsQueryResponse) String() string            { return proto.CompactTextString(m) }
+func (*TransactionsQueryResponse) ProtoMessage()               {}
+func (*TransactionsQueryResponse) Descriptor() ([]byte, []int) { return fileDescriptor9, []int{5} }
+
+func (m *TransactionsQueryResponse) GetTransactions() []*ProcessedTransaction {
+	if m != nil {
+		return m.Transactions
+	}
+	return nil
+}
+
+func (m *TransactionsQueryResponse) GetBookmark() string {
+	if m != nil {
+		return m.Bookmark
+	}
+	return ""
+}
+
 func init() {
 	proto.RegisterType((*ChaincodeQueryResponse)(nil), "protos.ChaincodeQueryResponse")
 	proto.RegisterType((*ChaincodeInfo)(nil), "protos.ChaincodeInfo")
 	proto.RegisterType((*ChannelQueryResponse)(nil), "protos.ChannelQueryResponse")
 	proto.RegisterType((*ChannelInfo)(nil), "protos.ChannelInfo")
+	proto.RegisterType((*BlocksQueryResponse)(nil), "protos.BlocksQueryResponse")
+	proto.RegisterType((*TransactionsQueryResponse)(nil), "protos.TransactionsQueryResponse")
 }
 
 func init() { proto.RegisterFile("peer/query.proto", fileDescriptor9) }
 
 var fileDescriptor9 = []byte{
-	// 284 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x54, 0x91, 0xdf, 0x4a, 0xc3, 0x30,
-	0x14, 0xc6, 0xa9, 0xfb, 0xa3, 0x3b, 0x43, 0x90, 0x38, 0x25, 0x37, 0xc2, 0xe8, 0xd5, 0x04, 0x69,
-	0x40, 0xf1, 0x05, 0xdc, 0x85, 0xec, 0x6a, 0xb8, 0x4b, 0x6f, 0xa4, 0x4d, 0xcf, 0xda, 0xc0, 0x96,
-	0xc4, 0xa4, 0x1d, 0xec, 0x29, 0x7c, 0x65, 0x39, 0xc9, 0x3a, 0xba, 0xab, 0x9e, 0xf3, 0xfb, 0x7e,
-	0xa1, 0x7c, 0x09, 0xdc, 0x59, 0x44, 0x27, 0x7e, 0x5b, 0x74, 0xc7, 0xcc, 0x3a, 0xd3, 0x18, 0x36,
-	0x0e, 0x1f, 0x9f, 0xae, 0xe1, 0x71, 0x59, 0xe7, 0x4a, 0x4b, 0x53, 0xe2, 0x17, 0xe5, 0x1b, 0xf4,
-	0xd6, 0x68, 0x8f, 0xec, 0x1d, 0x40, 0x76, 0x89, 0xe7, 0xc9, 0x7c, 0xb0, 0x98, 0xbe, 0x3e, 0xc4,
-	0xd3, 0x3e, 0x3b, 0x9f, 0x59, 0xe9, 0xad, 0xd9, 0xf4, 0xc4, 0xf4, 0x2f, 0x81, 0xdb, 0x8b, 0x94,
-	0x31, 0x18, 0xea, 0x7c, 0x8f, 0x3c, 0x99, 0x27, 0x8b, 0xc9, 0x26, 0xcc, 0x8c, 0xc3, 0xf5, 0x01,
-	0x9d, 0x57, 0x46, 0xf3, 0xab, 0x80, 0xbb, 0x95, 0x6c, 0x9b, 0x37, 0x35, 0x1f, 0x44, 0x9b, 0x66,
-	0x36, 0x83, 0x91, 0xd2, 0xb6, 0x6d, 0xf8, 0x30, 0xc0, 0xb8, 0x90, 0x89, 0x5e, 0x4a, 0x3e, 0x8a,
-	0x26, 0xcd, 0xc4, 0x0e, 0xc4, 0xc6, 0x91, 0xd1, 0x9c, 0x7e, 0xc2, 0x6c, 0x59, 0xe7, 0x5a, 0xe3,
-	0xee, 0xb2, 0xa0, 0x80, 0x1b, 0x19, 0x79, 0x57, 0xef, 0xbe, 0x57, 0x8f, 0x78, 0x28, 0x77, 0x96,
-	0xd2, 0x17, 0x98, 0xf6, 0x02, 0xf6, 0x14, 0x2e, 0x88, 0xd6, 0x1f, 0x55, 0x9e, 0xda, 0x4d, 0x4e,
-	0x64, 0x55, 0x7e, 0xac, 0x21, 0x35, 0xae, 0xca, 0xea, 0xa3, 0x45, 0xb7, 0xc3, 0xb2, 0x42, 0x97,
-	0x6d, 0xf3, 0xc2, 0x29, 0xd9, 0xfd, 0x84, 0xde, 0xe4, 0xfb, 0xb9, 0x52, 0x4d, 0xdd, 0x16, 0x99,
-	0x34, 0x7b, 0xd1, 0x53, 0x45, 0x54, 0x45, 0x54, 0x05, 0xa9, 0x45, 0x7c, 0xb2, 0xb7, 0xff, 0x00,
-	0x00, 0x00, 0xff, 0xff, 0x56, 0xd1, 0xfe, 0x74, 0xcd, 0x01, 0x00, 0x00,
+	// 379 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x7c, 0x92, 0xcf, 0x6a, 0xe3, 0x30,
+	0x10, 0xc6, 0xf1, 0xe6, 0xcf, 0x26, 0x93, 0x0d, 0x2c, 0x4a, 0x36, 0x78, 0xc3, 0x2e, 0x04, 0x43,
+	0x21, 0x85, 0x62, 0x43, 0x4b, 0xef, 0x25, 0x39, 0x94, 0x9c, 0xd2, 0x9a, 0x1e, 0x4a, 0x2f, 0x45,
+	0x96, 0x27, 0xb1, 0x49, 0x2c, 0xb9, 0x92, 0x13, 0xc8, 0x53, 0xf4, 0x95, 0x8b, 0x24, 0x3b, 0xb5,
+	0x7b, 0xe8, 0x49, 0x33, 0xdf, 0xfc, 0x34, 0xc3, 0xa7, 0x11, 0xfc, 0xce, 0x11, 0x65, 0xf0, 0x76,
+	0x40, 0x79, 0xf2, 0x73, 0x29, 0x0a, 0x41, 0xba, 0xe6, 0x50, 0xd3, 0x11, 0x13, 0x59, 0x26, 0x78,
+	0x60, 0x0f, 0x5b, 0x9c, 0x4e, 0x0c, 0x5e, 0x48, 0xca, 0x15, 0x65, 0x45, 0x5a, 0xe9, 0xde, 0x1a,
+	0x26, 0xcb, 0x84, 0xa6, 0x9c, 0x89, 0x18, 0x1f, 0x75, 0xb3, 0x10, 0x55, 0x2e, 0xb8, 0x42, 0x72,
+	0x0b, 0xc0, 0xaa, 0x8a, 0x72, 0x9d, 0x59, 0x6b, 0x3e, 0xb8, 0xfe, 0x63, 0x6f, 0x29, 0xff, 0x7c,
+	0x67, 0xc5, 0x37, 0x22, 0xac, 0x81, 0xde, 0xbb, 0x03, 0xc3, 0x46, 0x95, 0x10, 0x68, 0x73, 0x9a,
+	0xa1, 0xeb, 0xcc, 0x9c, 0x79, 0x3f, 0x34, 0x31, 0x71, 0xe1, 0xe7, 0x11, 0xa5, 0x4a, 0x05, 0x77,
+	0x7f, 0x18, 0xb9, 0x4a, 0x35, 0x9d, 0xd3, 0x22, 0x71, 0x5b, 0x96, 0xd6, 0x31, 0x19, 0x43, 0x27,
+	0xe5, 0xf9, 0xa1, 0x70, 0xdb, 0x46, 0xb4, 0x89, 0x26, 0x51, 0x31, 0xe6, 0x76, 0x2c, 0xa9, 0x63,
+	0xad, 0x1d, 0xb5, 0xd6, 0xb5, 0x9a, 0x8e, 0xbd, 0x7b, 0x18, 0x2f, 0x13, 0xca, 0x39, 0xee, 0x9b,
+	0x06, 0x03, 0xe8, 0x31, 0xab, 0x57, 0xf6, 0x46, 0x35, 0x7b, 0x5a, 0x37, 0xe6, 0xce, 0x90, 0x77,
+	0x05, 0x83, 0x5a, 0x81, 0xfc, 0x37, 0x0f, 0xa4, 0xd3, 0xd7, 0x34, 0x2e, 0xdd, 0xf5, 0x4b, 0x65,
+	0x15, 0x7b, 0xcf, 0x30, 0x5a, 0xec, 0x05, 0xdb, 0xa9, 0xe6, 0xd4, 0x0b, 0xe8, 0x46, 0x46, 0x2e,
+	0x67, 0x0e, 0xfd, 0x72, 0x4f, 0x06, 0x0e, 0xcb, 0x22, 0x99, 0x42, 0x2f, 0x12, 0x62, 0x97, 0x51,
+	0xb9, 0x2b, 0x5f, 0xe8, 0x9c, 0x7b, 0x27, 0xf8, 0xfb, 0xf4, 0xb9, 0xc8, 0x2f, 0xfd, 0xef, 0xe0,
+	0x57, 0x6d, 0xcb, 0xd5, 0x94, 0x7f, 0x95, 0xb3, 0x07, 0x29, 0x18, 0x2a, 0x85, 0x71, 0xad, 0x43,
+	0xd8, 0xb8, 0xf1, 0xdd, 0xe8, 0xc5, 0x1a, 0x3c, 0x21, 0xb7, 0x7e, 0x72, 0xca, 0x51, 0xee, 0x31,
+	0xde, 0xa2, 0xf4, 0x37, 0x34, 0x92, 0x29, 0xab, 0xfa, 0xeb, 0x6f, 0xf6, 0x72, 0xb9, 0x4d, 0x8b,
+	0xe4, 0x10, 0x69, 0x67, 0x41, 0x0d, 0x0d, 0x2c, 0x1a, 0x58, 0x34, 0xd0, 0x68, 0x64, 0x3f, 0xed,
+	0xcd, 0xc7, 0x00, 0x36, 0x80, 0xd8, 0xa8, 0xcf, 0x02, 0x00, 0x00,
 }
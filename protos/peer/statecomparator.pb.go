@@ -0,0 +1,349 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: peer/statecomparator.proto
+
+package peer
+
+import proto "github.com/golang/protobuf/proto"
+import fmt "fmt"
+import math "math"
+import common "github.com/hyperledger/fabric/protos/common"
+
+import (
+	context "golang.org/x/net/context"
+	grpc "google.golang.org/grpc"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// StateCommitmentsRequest requests the state commitments of the blocks start_block to
+// end_block of a channel, both included. Fewer commitments are returned if the ledger
+// of the peer is not as high, or if the range exceeds the limit of a response
+type StateCommitmentsRequest struct {
+	ChannelId  string `protobuf:"bytes,1,opt,name=channel_id,json=channelId" json:"channel_id,omitempty"`
+	StartBlock uint64 `protobuf:"varint,2,opt,name=start_block,json=startBlock" json:"start_block,omitempty"`
+	EndBlock   uint64 `protobuf:"varint,3,opt,name=end_block,json=endBlock" json:"end_block,omitempty"`
+}
+
+func (m *StateCommitmentsRequest) Reset()                    { *m = StateCommitmentsRequest{} }
+func (m *StateCommitmentsRequest) String() string            { return proto.CompactTextString(m) }
+func (*StateCommitmentsRequest) ProtoMessage()               {}
+func (*StateCommitmentsRequest) Descriptor() ([]byte, []int) { return fileDescriptor14, []int{0} }
+
+func (m *StateCommitmentsRequest) GetChannelId() string {
+	if m != nil {
+		return m.ChannelId
+	}
+	return ""
+}
+
+func (m *StateCommitmentsRequest) GetStartBlock() uint64 {
+	if m != nil {
+		return m.StartBlock
+	}
+	return 0
+}
+
+func (m *StateCommitmentsRequest) GetEndBlock() uint64 {
+	if m != nil {
+		return m.EndBlock
+	}
+	return 0
+}
+
+// BlockStateCommitment is the hash of the validation codes of the transactions of a block and
+// of the state updates applied by its valid transactions
+type BlockStateCommitment struct {
+	BlockNum   uint64 `protobuf:"varint,1,opt,name=block_num,json=blockNum" json:"block_num,omitempty"`
+	Commitment []byte `protobuf:"bytes,2,opt,name=commitment,proto3" json:"commitment,omitempty"`
+}
+
+func (m *BlockStateCommitment) Reset()                    { *m = BlockStateCommitment{} }
+func (m *BlockStateCommitment) String() string            { return proto.CompactTextString(m) }
+func (*BlockStateCommitment) ProtoMessage()               {}
+func (*BlockStateCommitment) Descriptor() ([]byte, []int) { return fileDescriptor14, []int{1} }
+
+func (m *BlockStateCommitment) GetBlockNum() uint64 {
+	if m != nil {
+		return m.BlockNum
+	}
+	return 0
+}
+
+func (m *BlockStateCommitment) GetCommitment() []byte {
+	if m != nil {
+		return m.Commitment
+	}
+	return nil
+}
+
+type StateCommitmentsResponse struct {
+	Commitments []*BlockStateCommitment `protobuf:"bytes,1,rep,name=commitments" json:"commitments,omitempty"`
+}
+
+func (m *StateCommitmentsResponse) Reset()                    { *m = StateCommitmentsResponse{} }
+func (m *StateCommitmentsResponse) String() string            { return proto.CompactTextString(m) }
+func (*StateCommitmentsResponse) ProtoMessage()               {}
+func (*StateCommitmentsResponse) Descriptor() ([]byte, []int) { return fileDescriptor14, []int{2} }
+
+func (m *StateCommitmentsResponse) GetCommitments() []*BlockStateCommitment {
+	if m != nil {
+		return m.Commitments
+	}
+	return nil
+}
+
+// CompareStateRequest requests the comparison of the state commitments of the blocks start_block
+// to end_block of a channel with the peer at peer_endpoint. An end_block of 0 compares up to the
+// last block committed by both peers
+type CompareStateRequest struct {
+	ChannelId    string `protobuf:"bytes,1,opt,name=channel_id,json=channelId" json:"channel_id,omitempty"`
+	PeerEndpoint string `protobuf:"bytes,2,opt,name=peer_endpoint,json=peerEndpoint" json:"peer_endpoint,omitempty"`
+	StartBlock   uint64 `protobuf:"varint,3,opt,name=start_block,json=startBlock" json:"start_block,omitempty"`
+	EndBlock     uint64 `protobuf:"varint,4,opt,name=end_block,json=endBlock" json:"end_block,omitempty"`
+}
+
+func (m *CompareStateRequest) Reset()                    { *m = CompareStateRequest{} }
+func (m *CompareStateRequest) String() string            { return proto.CompactTextString(m) }
+func (*CompareStateRequest) ProtoMessage()               {}
+func (*CompareStateRequest) Descriptor() ([]byte, []int) { return fileDescriptor14, []int{3} }
+
+func (m *CompareStateRequest) GetChannelId() string {
+	if m != nil {
+		return m.ChannelId
+	}
+	return ""
+}
+
+func (m *CompareStateRequest) GetPeerEndpoint() string {
+	if m != nil {
+		return m.PeerEndpoint
+	}
+	return ""
+}
+
+func (m *CompareStateRequest) GetStartBlock() uint64 {
+	if m != nil {
+		return m.StartBlock
+	}
+	return 0
+}
+
+func (m *CompareStateRequest) GetEndBlock() uint64 {
+	if m != nil {
+		return m.EndBlock
+	}
+	return 0
+}
+
+type CompareStateResponse struct {
+	// diverged is true if the commitments of a block differ, first_divergent_block is then
+	// the lowest such block, and local and remote its commitments on both peers
+	Diverged            bool                  `protobuf:"varint,1,opt,name=diverged" json:"diverged,omitempty"`
+	FirstDivergentBlock uint64                `protobuf:"varint,2,opt,name=first_divergent_block,json=firstDivergentBlock" json:"first_divergent_block,omitempty"`
+	Local               *BlockStateCommitment `protobuf:"bytes,3,opt,name=local" json:"local,omitempty"`
+	Remote              *BlockStateCommitment `protobuf:"bytes,4,opt,name=remote" json:"remote,omitempty"`
+	// compared_blocks is the number of blocks whose commitments were compared
+	ComparedBlocks uint64 `protobuf:"varint,5,opt,name=compared_blocks,json=comparedBlocks" json:"compared_blocks,omitempty"`
+}
+
+func (m *CompareStateResponse) Reset()                    { *m = CompareStateResponse{} }
+func (m *CompareStateResponse) String() string            { return proto.CompactTextString(m) }
+func (*CompareStateResponse) ProtoMessage()               {}
+func (*CompareStateResponse) Descriptor() ([]byte, []int) { return fileDescriptor14, []int{4} }
+
+func (m *CompareStateResponse) GetDiverged() bool {
+	if m != nil {
+		return m.Diverged
+	}
+	return false
+}
+
+func (m *CompareStateResponse) GetFirstDivergentBlock() uint64 {
+	if m != nil {
+		return m.FirstDivergentBlock
+	}
+	return 0
+}
+
+func (m *CompareStateResponse) GetLocal() *BlockStateCommitment {
+	if m != nil {
+		return m.Local
+	}
+	return nil
+}
+
+func (m *CompareStateResponse) GetRemote() *BlockStateCommitment {
+	if m != nil {
+		return m.Remote
+	}
+	return nil
+}
+
+func (m *CompareStateResponse) GetComparedBlocks() uint64 {
+	if m != nil {
+		return m.ComparedBlocks
+	}
+	return 0
+}
+
+func init() {
+	proto.RegisterType((*StateCommitmentsRequest)(nil), "protos.StateCommitmentsRequest")
+	proto.RegisterType((*BlockStateCommitment)(nil), "protos.BlockStateCommitment")
+	proto.RegisterType((*StateCommitmentsResponse)(nil), "protos.StateCommitmentsResponse")
+	proto.RegisterType((*CompareStateRequest)(nil), "protos.CompareStateRequest")
+	proto.RegisterType((*CompareStateResponse)(nil), "protos.CompareStateResponse")
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// Client API for StateComparator service
+
+type StateComparatorClient interface {
+	// GetStateCommitments returns the state commitments of a range of blocks of a channel.
+	// The payload data is a StateCommitmentsRequest
+	GetStateCommitments(ctx context.Context, in *common.Envelope, opts ...grpc.CallOption) (*StateCommitmentsResponse, error)
+	// CompareState compares the state commitments of a channel with another peer and reports
+	// the first divergent block. It must be signed by an admin of the local MSP, and its
+	// payload data is a CompareStateRequest
+	CompareState(ctx context.Context, in *common.Envelope, opts ...grpc.CallOption) (*CompareStateResponse, error)
+}
+
+type stateComparatorClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewStateComparatorClient(cc *grpc.ClientConn) StateComparatorClient {
+	return &stateComparatorClient{cc}
+}
+
+func (c *stateComparatorClient) GetStateCommitments(ctx context.Context, in *common.Envelope, opts ...grpc.CallOption) (*StateCommitmentsResponse, error) {
+	out := new(StateCommitmentsResponse)
+	err := grpc.Invoke(ctx, "/protos.StateComparator/GetStateCommitments", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *stateComparatorClient) CompareState(ctx context.Context, in *common.Envelope, opts ...grpc.CallOption) (*CompareStateResponse, error) {
+	out := new(CompareStateResponse)
+	err := grpc.Invoke(ctx, "/protos.StateComparator/CompareState", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Server API for StateComparator service
+
+type StateComparatorServer interface {
+	// GetStateCommitments returns the state commitments of a range of blocks of a channel.
+	// The payload data is a StateCommitmentsRequest
+	GetStateCommitments(context.Context, *common.Envelope) (*StateCommitmentsResponse, error)
+	// CompareState compares the state commitments of a channel with another peer and reports
+	// the first divergent block. It must be signed by an admin of the local MSP, and its
+	// payload data is a CompareStateRequest
+	CompareState(context.Context, *common.Envelope) (*CompareStateResponse, error)
+}
+
+func RegisterStateComparatorServer(s *grpc.Server, srv StateComparatorServer) {
+	s.RegisterService(&_StateComparator_serviceDesc, srv)
+}
+
+func _StateComparator_GetStateCommitments_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(common.Envelope)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StateComparatorServer).GetStateCommitments(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protos.StateComparator/GetStateCommitments",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StateComparatorServer).GetStateCommitments(ctx, req.(*common.Envelope))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StateComparator_CompareState_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(common.Envelope)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StateComparatorServer).CompareState(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protos.StateComparator/CompareState",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StateComparatorServer).CompareState(ctx, req.(*common.Envelope))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _StateComparator_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "protos.StateComparator",
+	HandlerType: (*StateComparatorServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetStateCommitments",
+			Handler:    _StateComparator_GetStateCommitments_Handler,
+		},
+		{
+			MethodName: "CompareState",
+			Handler:    _StateComparator_CompareState_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "peer/statecomparator.proto",
+}
+
+func init() { proto.RegisterFile("peer/statecomparator.proto", fileDescriptor14) }
+
+var fileDescriptor14 = []byte{
+	// 473 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x53, 0x4d, 0x6f, 0xd3, 0x40,
+	0x10, 0xad, 0x49, 0x5a, 0x25, 0x93, 0x40, 0xd1, 0xa6, 0x80, 0x15, 0xbe, 0x22, 0x73, 0x20, 0x5c,
+	0x6c, 0x29, 0x70, 0x46, 0x28, 0xa5, 0x42, 0x08, 0x09, 0x21, 0xf7, 0xd6, 0x8b, 0xb5, 0xb1, 0xa7,
+	0x8e, 0x55, 0xef, 0xae, 0xd9, 0xdd, 0x44, 0xe2, 0x97, 0x70, 0xe5, 0x6f, 0x72, 0x43, 0xde, 0x5d,
+	0x07, 0xd7, 0x04, 0xc8, 0x69, 0x93, 0x37, 0xf3, 0x66, 0xde, 0xbc, 0x19, 0xc3, 0xb4, 0x42, 0x94,
+	0x91, 0xd2, 0x54, 0x63, 0x2a, 0x58, 0x45, 0x25, 0xd5, 0x42, 0x86, 0x95, 0x14, 0x5a, 0x90, 0x13,
+	0xf3, 0xa8, 0xe9, 0x24, 0x15, 0x8c, 0x09, 0x1e, 0xd9, 0xc7, 0x06, 0x83, 0x2d, 0x3c, 0xba, 0xac,
+	0x59, 0xe7, 0x82, 0xb1, 0x42, 0x33, 0xe4, 0x5a, 0xc5, 0xf8, 0x75, 0x83, 0x4a, 0x93, 0xa7, 0x00,
+	0xe9, 0x9a, 0x72, 0x8e, 0x65, 0x52, 0x64, 0xbe, 0x37, 0xf3, 0xe6, 0xc3, 0x78, 0xe8, 0x90, 0x8f,
+	0x19, 0x79, 0x0e, 0x23, 0xa5, 0xa9, 0xd4, 0xc9, 0xaa, 0x14, 0xe9, 0x8d, 0x7f, 0x67, 0xe6, 0xcd,
+	0xfb, 0x31, 0x18, 0x68, 0x59, 0x23, 0xe4, 0x31, 0x0c, 0x91, 0x67, 0x2e, 0xdc, 0x33, 0xe1, 0x01,
+	0xf2, 0xcc, 0x04, 0x83, 0x4b, 0x38, 0x33, 0x3f, 0x3a, 0xcd, 0x6b, 0x92, 0x21, 0x24, 0x7c, 0xc3,
+	0x4c, 0xcf, 0x7e, 0x3c, 0x30, 0xc0, 0xe7, 0x0d, 0x23, 0xcf, 0x00, 0xd2, 0x5d, 0xaa, 0xe9, 0x38,
+	0x8e, 0x5b, 0x48, 0x70, 0x05, 0xfe, 0x9f, 0xc3, 0xa8, 0x4a, 0x70, 0x85, 0xe4, 0x2d, 0x8c, 0x7e,
+	0x67, 0x2a, 0xdf, 0x9b, 0xf5, 0xe6, 0xa3, 0xc5, 0x13, 0xeb, 0x82, 0x0a, 0xf7, 0x69, 0x89, 0xdb,
+	0x84, 0xe0, 0xbb, 0x07, 0x93, 0x73, 0x63, 0x2d, 0x9a, 0xbc, 0x03, 0x5d, 0x7a, 0x01, 0x77, 0xeb,
+	0xd5, 0x24, 0xc8, 0xb3, 0x4a, 0x14, 0x4e, 0xf5, 0x30, 0x1e, 0xd7, 0xe0, 0x85, 0xc3, 0xba, 0x56,
+	0xf6, 0xfe, 0x6d, 0x65, 0xbf, 0x63, 0xe5, 0x4f, 0x0f, 0xce, 0x6e, 0x2b, 0x73, 0x23, 0x4f, 0x61,
+	0x90, 0x15, 0x5b, 0x94, 0x39, 0x5a, 0x61, 0x83, 0x78, 0xf7, 0x9f, 0x2c, 0xe0, 0xc1, 0x75, 0x21,
+	0x95, 0x4e, 0x1c, 0xc2, 0x6f, 0xef, 0x71, 0x62, 0x82, 0xef, 0x9b, 0x98, 0x55, 0xb1, 0x80, 0xe3,
+	0x52, 0xa4, 0xb4, 0x34, 0x02, 0xff, 0x67, 0x9e, 0x4d, 0x25, 0x6f, 0xe0, 0x44, 0x22, 0x13, 0x1a,
+	0xfd, 0xfe, 0x01, 0x24, 0x97, 0x4b, 0x5e, 0xc2, 0xa9, 0x3d, 0x63, 0x74, 0x43, 0x2b, 0xff, 0xd8,
+	0xe8, 0xba, 0xd7, 0xc0, 0x86, 0xae, 0x16, 0x3f, 0x3c, 0x38, 0x6d, 0x8a, 0xb8, 0xab, 0x27, 0x9f,
+	0x60, 0xf2, 0x01, 0x75, 0xf7, 0x10, 0xc8, 0xfd, 0xd0, 0x1d, 0xfe, 0x05, 0xdf, 0x62, 0x29, 0x2a,
+	0x9c, 0xce, 0x1a, 0x2d, 0x7f, 0x3b, 0x9a, 0xe0, 0x88, 0xbc, 0x83, 0x71, 0xdb, 0xdb, 0x3d, 0x55,
+	0x76, 0x13, 0xed, 0xdb, 0x41, 0x70, 0xb4, 0xcc, 0x21, 0x10, 0x32, 0x0f, 0xd7, 0xdf, 0x2a, 0x94,
+	0x25, 0x66, 0x39, 0xca, 0xf0, 0x9a, 0xae, 0x64, 0x91, 0x36, 0xbc, 0xfa, 0x14, 0x96, 0x0f, 0x3b,
+	0x53, 0x7c, 0xa1, 0xe9, 0x0d, 0xcd, 0xf1, 0xea, 0x55, 0x5e, 0xe8, 0xf5, 0x66, 0x55, 0x77, 0x8d,
+	0x5a, 0x25, 0x22, 0x5b, 0x22, 0xb2, 0x25, 0xa2, 0xba, 0xc4, 0xca, 0x7e, 0xe7, 0xaf, 0x7f, 0x0d,
+	0x00, 0x30, 0xc4, 0xff, 0x52, 0x0c, 0x04, 0x00, 0x00,
+}
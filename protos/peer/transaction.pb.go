@@ -17,31 +17,33 @@ var _ = math.Inf
 type TxValidationCode int32
 
 const (
-	TxValidationCode_VALID                        TxValidationCode = 0
-	TxValidationCode_NIL_ENVELOPE                 TxValidationCode = 1
-	TxValidationCode_BAD_PAYLOAD                  TxValidationCode = 2
-	TxValidationCode_BAD_COMMON_HEADER            TxValidationCode = 3
-	TxValidationCode_BAD_CREATOR_SIGNATURE        TxValidationCode = 4
-	TxValidationCode_INVALID_ENDORSER_TRANSACTION TxValidationCode = 5
-	TxValidationCode_INVALID_CONFIG_TRANSACTION   TxValidationCode = 6
-	TxValidationCode_UNSUPPORTED_TX_PAYLOAD       TxValidationCode = 7
-	TxValidationCode_BAD_PROPOSAL_TXID            TxValidationCode = 8
-	TxValidationCode_DUPLICATE_TXID               TxValidationCode = 9
-	TxValidationCode_ENDORSEMENT_POLICY_FAILURE   TxValidationCode = 10
-	TxValidationCode_MVCC_READ_CONFLICT           TxValidationCode = 11
-	TxValidationCode_PHANTOM_READ_CONFLICT        TxValidationCode = 12
-	TxValidationCode_UNKNOWN_TX_TYPE              TxValidationCode = 13
-	TxValidationCode_TARGET_CHAIN_NOT_FOUND       TxValidationCode = 14
-	TxValidationCode_MARSHAL_TX_ERROR             TxValidationCode = 15
-	TxValidationCode_NIL_TXACTION                 TxValidationCode = 16
-	TxValidationCode_EXPIRED_CHAINCODE            TxValidationCode = 17
-	TxValidationCode_CHAINCODE_VERSION_CONFLICT   TxValidationCode = 18
-	TxValidationCode_BAD_HEADER_EXTENSION         TxValidationCode = 19
-	TxValidationCode_BAD_CHANNEL_HEADER           TxValidationCode = 20
-	TxValidationCode_BAD_RESPONSE_PAYLOAD         TxValidationCode = 21
-	TxValidationCode_BAD_RWSET                    TxValidationCode = 22
-	TxValidationCode_ILLEGAL_WRITESET             TxValidationCode = 23
-	TxValidationCode_INVALID_OTHER_REASON         TxValidationCode = 255
+	TxValidationCode_VALID                         TxValidationCode = 0
+	TxValidationCode_NIL_ENVELOPE                  TxValidationCode = 1
+	TxValidationCode_BAD_PAYLOAD                   TxValidationCode = 2
+	TxValidationCode_BAD_COMMON_HEADER             TxValidationCode = 3
+	TxValidationCode_BAD_CREATOR_SIGNATURE         TxValidationCode = 4
+	TxValidationCode_INVALID_ENDORSER_TRANSACTION  TxValidationCode = 5
+	TxValidationCode_INVALID_CONFIG_TRANSACTION    TxValidationCode = 6
+	TxValidationCode_UNSUPPORTED_TX_PAYLOAD        TxValidationCode = 7
+	TxValidationCode_BAD_PROPOSAL_TXID             TxValidationCode = 8
+	TxValidationCode_DUPLICATE_TXID                TxValidationCode = 9
+	TxValidationCode_ENDORSEMENT_POLICY_FAILURE    TxValidationCode = 10
+	TxValidationCode_MVCC_READ_CONFLICT            TxValidationCode = 11
+	TxValidationCode_PHANTOM_READ_CONFLICT         TxValidationCode = 12
+	TxValidationCode_UNKNOWN_TX_TYPE               TxValidationCode = 13
+	TxValidationCode_TARGET_CHAIN_NOT_FOUND        TxValidationCode = 14
+	TxValidationCode_MARSHAL_TX_ERROR              TxValidationCode = 15
+	TxValidationCode_NIL_TXACTION                  TxValidationCode = 16
+	TxValidationCode_EXPIRED_CHAINCODE             TxValidationCode = 17
+	TxValidationCode_CHAINCODE_VERSION_CONFLICT    TxValidationCode = 18
+	TxValidationCode_BAD_HEADER_EXTENSION          TxValidationCode = 19
+	TxValidationCode_BAD_CHANNEL_HEADER            TxValidationCode = 20
+	TxValidationCode_BAD_RESPONSE_PAYLOAD          TxValidationCode = 21
+	TxValidationCode_BAD_RWSET                     TxValidationCode = 22
+	TxValidationCode_ILLEGAL_WRITESET              TxValidationCode = 23
+	TxValidationCode_INSTANTIATION_POLICY_FAILURE  TxValidationCode = 24
+	TxValidationCode_CHAINCODE_DEFINITION_MISMATCH TxValidationCode = 25
+	TxValidationCode_INVALID_OTHER_REASON          TxValidationCode = 255
 )
 
 var TxValidationCode_name = map[int32]string{
@@ -69,34 +71,38 @@ var TxValidationCode_name = map[int32]string{
 	21:  "BAD_RESPONSE_PAYLOAD",
 	22:  "BAD_RWSET",
 	23:  "ILLEGAL_WRITESET",
+	24:  "INSTANTIATION_POLICY_FAILURE",
+	25:  "CHAINCODE_DEFINITION_MISMATCH",
 	255: "INVALID_OTHER_REASON",
 }
 var TxValidationCode_value = map[string]int32{
-	"VALID":                        0,
-	"NIL_ENVELOPE":                 1,
-	"BAD_PAYLOAD":                  2,
-	"BAD_COMMON_HEADER":            3,
-	"BAD_CREATOR_SIGNATURE":        4,
-	"INVALID_ENDORSER_TRANSACTION": 5,
-	"INVALID_CONFIG_TRANSACTION":   6,
-	"UNSUPPORTED_TX_PAYLOAD":       7,
-	"BAD_PROPOSAL_TXID":            8,
-	"DUPLICATE_TXID":               9,
-	"ENDORSEMENT_POLICY_FAILURE":   10,
-	"MVCC_READ_CONFLICT":           11,
-	"PHANTOM_READ_CONFLICT":        12,
-	"UNKNOWN_TX_TYPE":              13,
-	"TARGET_CHAIN_NOT_FOUND":       14,
-	"MARSHAL_TX_ERROR":             15,
-	"NIL_TXACTION":                 16,
-	"EXPIRED_CHAINCODE":            17,
-	"CHAINCODE_VERSION_CONFLICT":   18,
-	"BAD_HEADER_EXTENSION":         19,
-	"BAD_CHANNEL_HEADER":           20,
-	"BAD_RESPONSE_PAYLOAD":         21,
-	"BAD_RWSET":                    22,
-	"ILLEGAL_WRITESET":             23,
-	"INVALID_OTHER_REASON":         255,
+	"VALID":                         0,
+	"NIL_ENVELOPE":                  1,
+	"BAD_PAYLOAD":                   2,
+	"BAD_COMMON_HEADER":             3,
+	"BAD_CREATOR_SIGNATURE":         4,
+	"INVALID_ENDORSER_TRANSACTION":  5,
+	"INVALID_CONFIG_TRANSACTION":    6,
+	"UNSUPPORTED_TX_PAYLOAD":        7,
+	"BAD_PROPOSAL_TXID":             8,
+	"DUPLICATE_TXID":                9,
+	"ENDORSEMENT_POLICY_FAILURE":    10,
+	"MVCC_READ_CONFLICT":            11,
+	"PHANTOM_READ_CONFLICT":         12,
+	"UNKNOWN_TX_TYPE":               13,
+	"TARGET_CHAIN_NOT_FOUND":        14,
+	"MARSHAL_TX_ERROR":              15,
+	"NIL_TXACTION":                  16,
+	"EXPIRED_CHAINCODE":             17,
+	"CHAINCODE_VERSION_CONFLICT":    18,
+	"BAD_HEADER_EXTENSION":          19,
+	"BAD_CHANNEL_HEADER":            20,
+	"BAD_RESPONSE_PAYLOAD":          21,
+	"BAD_RWSET":                     22,
+	"ILLEGAL_WRITESET":              23,
+	"INSTANTIATION_POLICY_FAILURE":  24,
+	"CHAINCODE_DEFINITION_MISMATCH": 25,
+	"INVALID_OTHER_REASON":          255,
 }
 
 func (x TxValidationCode) String() string {
@@ -295,6 +301,90 @@ func (m *ChaincodeEndorsedAction) GetEndorsements() []*Endorsement {
 	return nil
 }
 
+// TxValidationReasons is the message stored in the TRANSACTIONS_VALIDATION_REASONS
+// block metadata by the committing peer. It holds the reasons the invalid transactions
+// of the block were given their validation codes, when these are known
+type TxValidationReasons struct {
+	Reasons []*TxValidationReason `protobuf:"bytes,1,rep,name=reasons" json:"reasons,omitempty"`
+}
+
+func (m *TxValidationReasons) Reset()                    { *m = TxValidationReasons{} }
+func (m *TxValidationReasons) String() string            { return proto.CompactTextString(m) }
+func (*TxValidationReasons) ProtoMessage()               {}
+func (*TxValidationReasons) Descriptor() ([]byte, []int) { return fileDescriptor12, []int{6} }
+
+func (m *TxValidationReasons) GetReasons() []*TxValidationReason {
+	if m != nil {
+		return m.Reasons
+	}
+	return nil
+}
+
+// TxValidationReason is a machine-readable reason of the validation code of a transaction
+type TxValidationReason struct {
+	TxIndex    uint64           `protobuf:"varint,1,opt,name=tx_index,json=txIndex" json:"tx_index,omitempty"`
+	Code       TxValidationCode `protobuf:"varint,2,opt,name=code,enum=protos.TxValidationCode" json:"code,omitempty"`
+	Namespace  string           `protobuf:"bytes,3,opt,name=namespace" json:"namespace,omitempty"`
+	Collection string           `protobuf:"bytes,4,opt,name=collection" json:"collection,omitempty"`
+	Key        string           `protobuf:"bytes,5,opt,name=key" json:"key,omitempty"`
+	Policy     string           `protobuf:"bytes,6,opt,name=policy" json:"policy,omitempty"`
+	Message    string           `protobuf:"bytes,7,opt,name=message" json:"message,omitempty"`
+}
+
+func (m *TxValidationReason) Reset()                    { *m = TxValidationReason{} }
+func (m *TxValidationReason) String() string            { return proto.CompactTextString(m) }
+func (*TxValidationReason) ProtoMessage()               {}
+func (*TxValidationReason) Descriptor() ([]byte, []int) { return fileDescriptor12, []int{7} }
+
+func (m *TxValidationReason) GetTxIndex() uint64 {
+	if m != nil {
+		return m.TxIndex
+	}
+	return 0
+}
+
+func (m *TxValidationReason) GetCode() TxValidationCode {
+	if m != nil {
+		return m.Code
+	}
+	return TxValidationCode_VALID
+}
+
+func (m *TxValidationReason) GetNamespace() string {
+	if m != nil {
+		return m.Namespace
+	}
+	return ""
+}
+
+func (m *TxValidationReason) GetCollection() string {
+	if m != nil {
+		return m.Collection
+	}
+	return ""
+}
+
+func (m *TxValidationReason) GetKey() string {
+	if m != nil {
+		return m.Key
+	}
+	return ""
+}
+
+func (m *TxValidationReason) GetPolicy() string {
+	if m != nil {
+		return m.Policy
+	}
+	return ""
+}
+
+func (m *TxValidationReason) GetMessage() string {
+	if m != nil {
+		return m.Message
+	}
+	return ""
+}
+
 func init() {
 	proto.RegisterType((*SignedTransaction)(nil), "protos.SignedTransaction")
 	proto.RegisterType((*ProcessedTransaction)(nil), "protos.ProcessedTransaction")
@@ -302,64 +392,76 @@ func init() {
 	proto.RegisterType((*TransactionAction)(nil), "protos.TransactionAction")
 	proto.RegisterType((*ChaincodeActionPayload)(nil), "protos.ChaincodeActionPayload")
 	proto.RegisterType((*ChaincodeEndorsedAction)(nil), "protos.ChaincodeEndorsedAction")
+	proto.RegisterType((*TxValidationReasons)(nil), "protos.TxValidationReasons")
+	proto.RegisterType((*TxValidationReason)(nil), "protos.TxValidationReason")
 	proto.RegisterEnum("protos.TxValidationCode", TxValidationCode_name, TxValidationCode_value)
 }
 
 func init() { proto.RegisterFile("peer/transaction.proto", fileDescriptor12) }
 
 var fileDescriptor12 = []byte{
-	// 833 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x74, 0x54, 0xd1, 0x6e, 0xe2, 0x46,
-	0x14, 0x2d, 0xd9, 0x4d, 0xd2, 0x0c, 0xd9, 0x64, 0x32, 0x10, 0x42, 0x50, 0xd4, 0x5d, 0xf1, 0x50,
-	0x6d, 0x5b, 0x09, 0xa4, 0xec, 0x43, 0xa5, 0xaa, 0x2f, 0x83, 0x3d, 0x09, 0x56, 0xcd, 0x8c, 0x35,
-	0x1e, 0x08, 0xe9, 0x43, 0x47, 0x06, 0xcf, 0x12, 0x54, 0xb0, 0x2d, 0xdb, 0x59, 0x35, 0xaf, 0xfd,
-	0x80, 0xf6, 0x3f, 0xfb, 0x13, 0xad, 0xc6, 0x63, 0x03, 0xc9, 0x76, 0x5f, 0x30, 0x73, 0xcf, 0xb9,
-	0xf7, 0x9c, 0x7b, 0xaf, 0x3d, 0xa0, 0x95, 0x28, 0x95, 0xf6, 0xf3, 0x34, 0x88, 0xb2, 0x60, 0x9e,
-	0x2f, 0xe3, 0xa8, 0x97, 0xa4, 0x71, 0x1e, 0xa3, 0x83, 0xe2, 0x91, 0x75, 0xde, 0x2e, 0xe2, 0x78,
-	0xb1, 0x52, 0xfd, 0xe2, 0x38, 0x7b, 0xfc, 0xd8, 0xcf, 0x97, 0x6b, 0x95, 0xe5, 0xc1, 0x3a, 0x31,
-	0xc4, 0xce, 0x55, 0x51, 0x20, 0x49, 0xe3, 0x24, 0xce, 0x82, 0x95, 0x4c, 0x55, 0x96, 0xc4, 0x51,
-	0xa6, 0x4a, 0xb4, 0x31, 0x8f, 0xd7, 0xeb, 0x38, 0xea, 0x9b, 0x87, 0x09, 0x76, 0x7f, 0x03, 0x67,
-	0xfe, 0x72, 0x11, 0xa9, 0x50, 0x6c, 0x65, 0xd1, 0x0f, 0xe0, 0x6c, 0xc7, 0x85, 0x9c, 0x3d, 0xe5,
-	0x2a, 0x6b, 0xd7, 0xde, 0xd5, 0xde, 0x1f, 0x73, 0xb8, 0x03, 0x0c, 0x74, 0x1c, 0x5d, 0x81, 0xa3,
-	0x6c, 0xb9, 0x88, 0x82, 0xfc, 0x31, 0x55, 0xed, 0xbd, 0x82, 0xb4, 0x0d, 0x74, 0xff, 0xac, 0x81,
-	0xa6, 0x97, 0xc6, 0x73, 0x95, 0x65, 0xcf, 0x35, 0x06, 0xa0, 0xb1, 0x53, 0x8a, 0x44, 0x9f, 0xd4,
-	0x2a, 0x4e, 0x54, 0xa1, 0x52, 0xbf, 0x86, 0xbd, 0xd2, 0x64, 0x15, 0xe7, 0xff, 0x47, 0x46, 0xdf,
-	0x82, 0x93, 0x4f, 0xc1, 0x6a, 0x19, 0x06, 0x3a, 0x6a, 0xc5, 0xa1, 0xd1, 0xdf, 0xe7, 0x2f, 0xa2,
-	0xdd, 0x01, 0xa8, 0xef, 0x4a, 0x7f, 0x00, 0x87, 0xe6, 0x9f, 0x6e, 0xea, 0xd5, 0xfb, 0xfa, 0xf5,
-	0xa5, 0x19, 0x46, 0xd6, 0xdb, 0x61, 0xe1, 0xe2, 0x97, 0x57, 0xcc, 0x2e, 0x01, 0x67, 0x9f, 0xa1,
-	0xa8, 0x05, 0x0e, 0x1e, 0x54, 0x10, 0xaa, 0xb4, 0x9c, 0x4e, 0x79, 0x42, 0x6d, 0x70, 0x98, 0x04,
-	0x4f, 0xab, 0x38, 0x08, 0xcb, 0x89, 0x54, 0xc7, 0xee, 0xdf, 0x35, 0xd0, 0xb2, 0x1e, 0x82, 0x65,
-	0x34, 0x8f, 0x43, 0x65, 0xaa, 0x78, 0x06, 0x42, 0x3f, 0x83, 0xce, 0xbc, 0x42, 0xe4, 0x66, 0x89,
-	0x55, 0x1d, 0x23, 0xd0, 0xde, 0x30, 0xbc, 0x92, 0x50, 0x65, 0xff, 0x08, 0x0e, 0x8c, 0xb5, 0x42,
-	0xb1, 0x7e, 0xfd, 0xb6, 0xea, 0x69, 0xa3, 0x46, 0xa2, 0x30, 0x4e, 0x33, 0x15, 0x96, 0x9d, 0x95,
-	0xf4, 0xee, 0x5f, 0x35, 0x70, 0xf1, 0x05, 0x0e, 0xfa, 0x09, 0x5c, 0x7e, 0xf6, 0x36, 0xbd, 0x70,
-	0x74, 0x51, 0x11, 0x78, 0x89, 0x6f, 0x0d, 0x1d, 0x2b, 0x53, 0x6d, 0xad, 0xa2, 0x3c, 0x6b, 0xef,
-	0x15, 0xa3, 0x6e, 0x54, 0xb6, 0xc8, 0x16, 0xe3, 0xcf, 0x88, 0xdf, 0xff, 0xf3, 0x1a, 0x40, 0xf1,
-	0xc7, 0xe4, 0xd9, 0x0a, 0xd1, 0x11, 0xd8, 0x9f, 0x60, 0xd7, 0xb1, 0xe1, 0x57, 0x08, 0x82, 0x63,
-	0xea, 0xb8, 0x92, 0xd0, 0x09, 0x71, 0x99, 0x47, 0x60, 0x0d, 0x9d, 0x82, 0xfa, 0x00, 0xdb, 0xd2,
-	0xc3, 0xf7, 0x2e, 0xc3, 0x36, 0xdc, 0x43, 0xe7, 0xe0, 0x4c, 0x07, 0x2c, 0x36, 0x1a, 0x31, 0x2a,
-	0x87, 0x04, 0xdb, 0x84, 0xc3, 0x57, 0xe8, 0x12, 0x9c, 0x17, 0x61, 0x4e, 0xb0, 0x60, 0x5c, 0xfa,
-	0xce, 0x2d, 0xc5, 0x62, 0xcc, 0x09, 0x7c, 0x8d, 0xde, 0x81, 0x2b, 0x87, 0x16, 0x0a, 0x92, 0x50,
-	0x9b, 0x71, 0x9f, 0x70, 0x29, 0x38, 0xa6, 0x3e, 0xb6, 0x84, 0xc3, 0x28, 0xdc, 0x47, 0xdf, 0x80,
-	0x4e, 0xc5, 0xb0, 0x18, 0xbd, 0x71, 0x6e, 0x9f, 0xe1, 0x07, 0xa8, 0x03, 0x5a, 0x63, 0xea, 0x8f,
-	0x3d, 0x8f, 0x71, 0x41, 0x6c, 0x29, 0xa6, 0x1b, 0x3f, 0x87, 0x95, 0x1f, 0x8f, 0x33, 0x8f, 0xf9,
-	0xd8, 0x95, 0x62, 0xea, 0xd8, 0xf0, 0x6b, 0x84, 0xc0, 0x89, 0x3d, 0xf6, 0x5c, 0xc7, 0xc2, 0x82,
-	0x98, 0xd8, 0x91, 0x96, 0x29, 0x0d, 0x8c, 0x08, 0x15, 0xd2, 0x63, 0xae, 0x63, 0xdd, 0xcb, 0x1b,
-	0xec, 0xb8, 0xda, 0x28, 0x40, 0x2d, 0x80, 0x46, 0x13, 0xcb, 0x92, 0x9c, 0x60, 0x63, 0xc4, 0x75,
-	0x2c, 0x01, 0xeb, 0xba, 0x37, 0x6f, 0x88, 0xa9, 0x60, 0xa3, 0x17, 0xd0, 0x31, 0x6a, 0x80, 0xd3,
-	0x31, 0xfd, 0x85, 0xb2, 0x3b, 0xaa, 0x5d, 0x89, 0x7b, 0x8f, 0xc0, 0x37, 0xda, 0xae, 0xc0, 0xfc,
-	0x96, 0x08, 0x69, 0x0d, 0xb1, 0x43, 0x25, 0x65, 0x42, 0xde, 0xb0, 0x31, 0xb5, 0xe1, 0x09, 0x6a,
-	0x02, 0x38, 0xc2, 0xdc, 0x1f, 0x16, 0x4e, 0x25, 0xe1, 0x9c, 0x71, 0x78, 0x5a, 0xcd, 0x5d, 0x4c,
-	0xcb, 0x96, 0xa1, 0x6e, 0x8b, 0x4c, 0x3d, 0x87, 0x13, 0xdb, 0x14, 0xb1, 0x98, 0x4d, 0xe0, 0x99,
-	0x6e, 0x61, 0x73, 0x94, 0x13, 0xc2, 0x7d, 0x87, 0xd1, 0xad, 0x1f, 0x84, 0xda, 0xa0, 0xa9, 0xa7,
-	0x61, 0xd6, 0x22, 0xc9, 0x54, 0x10, 0xaa, 0x29, 0xb0, 0xa1, 0x9b, 0x2b, 0x16, 0x34, 0xc4, 0x94,
-	0x12, 0xb7, 0x5a, 0x5c, 0xb3, 0xca, 0xe0, 0xc4, 0xf7, 0x18, 0xf5, 0xc9, 0x66, 0xb2, 0xe7, 0xe8,
-	0x0d, 0x38, 0x2a, 0x90, 0x3b, 0x9f, 0x08, 0xd8, 0xd2, 0xce, 0x1d, 0xd7, 0x25, 0xb7, 0xd8, 0x95,
-	0x77, 0xdc, 0x11, 0x44, 0x47, 0x2f, 0xd0, 0x25, 0x68, 0x56, 0xab, 0x63, 0x62, 0x48, 0xb8, 0x9e,
-	0x90, 0xcf, 0x28, 0xfc, 0xb7, 0x36, 0x98, 0x83, 0x6e, 0x9c, 0x2e, 0x7a, 0x0f, 0x4f, 0x89, 0x4a,
-	0x57, 0x2a, 0x5c, 0xa8, 0xb4, 0xf7, 0x31, 0x98, 0xa5, 0xcb, 0x79, 0xf5, 0x9e, 0xea, 0x2b, 0x75,
-	0x80, 0x76, 0x3e, 0x7d, 0x2f, 0x98, 0xff, 0x1e, 0x2c, 0xd4, 0xaf, 0xdf, 0x2d, 0x96, 0xf9, 0xc3,
-	0xe3, 0x4c, 0xdf, 0x54, 0xfd, 0x9d, 0xf4, 0xbe, 0x49, 0x37, 0x97, 0x74, 0xd6, 0xd7, 0xe9, 0x33,
-	0x73, 0x81, 0x7f, 0xf8, 0x2f, 0x00, 0x00, 0xff, 0xff, 0x19, 0x1c, 0xb2, 0xe4, 0xe1, 0x05, 0x00,
-	0x00,
+	// 998 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x74, 0x55, 0xdb, 0x6e, 0xe3, 0x36,
+	0x10, 0xad, 0x37, 0x17, 0x6f, 0xc6, 0xd9, 0x84, 0xa1, 0x13, 0x47, 0x36, 0xd2, 0xdd, 0xd4, 0x0f,
+	0x45, 0x7a, 0x41, 0x0c, 0x64, 0x0b, 0x14, 0x28, 0xfa, 0x42, 0x4b, 0x4c, 0x2c, 0xac, 0x4c, 0x09,
+	0x14, 0x9d, 0x4b, 0x1f, 0x4a, 0x28, 0x32, 0xd7, 0x31, 0xd6, 0x96, 0x0c, 0xc9, 0x59, 0x24, 0xaf,
+	0xfd, 0x80, 0xf6, 0x53, 0xfa, 0x49, 0xfd, 0x94, 0x16, 0xd4, 0xc5, 0x76, 0x92, 0xed, 0x4b, 0xc4,
+	0x39, 0xe7, 0x90, 0x73, 0x66, 0x86, 0x31, 0xa1, 0x31, 0x53, 0x2a, 0xe9, 0xcc, 0x93, 0x20, 0x4a,
+	0x83, 0x70, 0x3e, 0x8e, 0xa3, 0xd3, 0x59, 0x12, 0xcf, 0x63, 0xbc, 0x99, 0x7d, 0xd2, 0xd6, 0xbb,
+	0x51, 0x1c, 0x8f, 0x26, 0xaa, 0x93, 0x85, 0xb7, 0xf7, 0x1f, 0x3b, 0xf3, 0xf1, 0x54, 0xa5, 0xf3,
+	0x60, 0x3a, 0xcb, 0x85, 0xad, 0xa3, 0xec, 0x80, 0x59, 0x12, 0xcf, 0xe2, 0x34, 0x98, 0xc8, 0x44,
+	0xa5, 0xb3, 0x38, 0x4a, 0x55, 0xc1, 0xd6, 0xc3, 0x78, 0x3a, 0x8d, 0xa3, 0x4e, 0xfe, 0xc9, 0xc1,
+	0xf6, 0xef, 0xb0, 0xe7, 0x8f, 0x47, 0x91, 0x1a, 0x8a, 0x65, 0x5a, 0xfc, 0x03, 0xec, 0xad, 0xb8,
+	0x90, 0xb7, 0x8f, 0x73, 0x95, 0x1a, 0x95, 0xe3, 0xca, 0xc9, 0x36, 0x47, 0x2b, 0x44, 0x57, 0xe3,
+	0xf8, 0x08, 0xb6, 0xd2, 0xf1, 0x28, 0x0a, 0xe6, 0xf7, 0x89, 0x32, 0x5e, 0x65, 0xa2, 0x25, 0xd0,
+	0xfe, 0xa3, 0x02, 0xfb, 0x5e, 0x12, 0x87, 0x2a, 0x4d, 0x9f, 0xe6, 0xe8, 0x42, 0x7d, 0xe5, 0x28,
+	0x1a, 0x7d, 0x56, 0x93, 0x78, 0xa6, 0xb2, 0x2c, 0xb5, 0x33, 0x74, 0x5a, 0x98, 0x2c, 0x71, 0xfe,
+	0x25, 0x31, 0xfe, 0x16, 0x76, 0x3e, 0x07, 0x93, 0xf1, 0x30, 0xd0, 0xa8, 0x19, 0x0f, 0xf3, 0xfc,
+	0x1b, 0xfc, 0x19, 0xda, 0xee, 0x42, 0x6d, 0x35, 0xf5, 0x7b, 0xa8, 0xe6, 0x2b, 0x5d, 0xd4, 0xda,
+	0x49, 0xed, 0xac, 0x99, 0x37, 0x23, 0x3d, 0x5d, 0x51, 0x91, 0xec, 0x2f, 0x2f, 0x95, 0x6d, 0x0a,
+	0x7b, 0x2f, 0x58, 0xdc, 0x80, 0xcd, 0x3b, 0x15, 0x0c, 0x55, 0x52, 0x74, 0xa7, 0x88, 0xb0, 0x01,
+	0xd5, 0x59, 0xf0, 0x38, 0x89, 0x83, 0x61, 0xd1, 0x91, 0x32, 0x6c, 0xff, 0x55, 0x81, 0x86, 0x79,
+	0x17, 0x8c, 0xa3, 0x30, 0x1e, 0xaa, 0xfc, 0x14, 0x2f, 0xa7, 0xf0, 0xaf, 0xd0, 0x0a, 0x4b, 0x46,
+	0x2e, 0x86, 0x58, 0x9e, 0x93, 0x27, 0x30, 0x16, 0x0a, 0xaf, 0x10, 0x94, 0xbb, 0x7f, 0x86, 0xcd,
+	0xdc, 0x5a, 0x96, 0xb1, 0x76, 0xf6, 0xae, 0xac, 0x69, 0x91, 0x8d, 0x46, 0xc3, 0x38, 0x49, 0xd5,
+	0xb0, 0xa8, 0xac, 0x90, 0xb7, 0xff, 0xac, 0xc0, 0xe1, 0xff, 0x68, 0xf0, 0x2f, 0xd0, 0x7c, 0x71,
+	0x9b, 0x9e, 0x39, 0x3a, 0x2c, 0x05, 0xbc, 0xe0, 0x97, 0x86, 0xb6, 0x55, 0x7e, 0xda, 0x54, 0x45,
+	0xf3, 0xd4, 0x78, 0x95, 0xb5, 0xba, 0x5e, 0xda, 0xa2, 0x4b, 0x8e, 0x3f, 0x11, 0xb6, 0x3f, 0x40,
+	0x5d, 0x3c, 0x5c, 0x2e, 0x26, 0xc8, 0x55, 0x90, 0xc6, 0x51, 0x8a, 0x7f, 0x82, 0x6a, 0x92, 0x2f,
+	0x8b, 0xa9, 0xb5, 0x16, 0x53, 0x7b, 0xa1, 0xe6, 0xa5, 0xb4, 0xfd, 0x4f, 0x05, 0xf0, 0x4b, 0x1e,
+	0x37, 0xe1, 0xf5, 0xfc, 0x41, 0x8e, 0xa3, 0xa1, 0x7a, 0xc8, 0xea, 0x58, 0xe7, 0xd5, 0xf9, 0x83,
+	0xad, 0x43, 0xfc, 0x23, 0xac, 0x87, 0xe5, 0x55, 0xda, 0x39, 0x33, 0xbe, 0x94, 0x44, 0x5f, 0x2a,
+	0x9e, 0xa9, 0xf4, 0xed, 0x8f, 0x82, 0xa9, 0x4a, 0x67, 0x41, 0xa8, 0x8c, 0xb5, 0xe3, 0xca, 0xc9,
+	0x16, 0x5f, 0x02, 0xf8, 0x2d, 0x40, 0x18, 0x4f, 0x26, 0x2a, 0x1f, 0xcc, 0x7a, 0x46, 0xaf, 0x20,
+	0x18, 0xc1, 0xda, 0x27, 0xf5, 0x68, 0x6c, 0x64, 0x84, 0x5e, 0xea, 0x1b, 0x35, 0x8b, 0x27, 0xe3,
+	0xf0, 0xd1, 0xd8, 0xcc, 0xc0, 0x22, 0xd2, 0x37, 0x6a, 0xaa, 0xd2, 0x34, 0x18, 0x29, 0xa3, 0x9a,
+	0x11, 0x65, 0xf8, 0xfd, 0xdf, 0x1b, 0x80, 0x9e, 0x9b, 0xc3, 0x5b, 0xb0, 0x71, 0x49, 0x1c, 0xdb,
+	0x42, 0x5f, 0x61, 0x04, 0xdb, 0xcc, 0x76, 0x24, 0x65, 0x97, 0xd4, 0x71, 0x3d, 0x8a, 0x2a, 0x78,
+	0x17, 0x6a, 0x5d, 0x62, 0x49, 0x8f, 0xdc, 0x38, 0x2e, 0xb1, 0xd0, 0x2b, 0x7c, 0x00, 0x7b, 0x1a,
+	0x30, 0xdd, 0x7e, 0xdf, 0x65, 0xb2, 0x47, 0x89, 0x45, 0x39, 0x5a, 0xc3, 0x4d, 0x38, 0xc8, 0x60,
+	0x4e, 0x89, 0x70, 0xb9, 0xf4, 0xed, 0x0b, 0x46, 0xc4, 0x80, 0x53, 0xb4, 0x8e, 0x8f, 0xe1, 0xc8,
+	0x66, 0x59, 0x06, 0x49, 0x99, 0xe5, 0x72, 0x9f, 0x72, 0x29, 0x38, 0x61, 0x3e, 0x31, 0x85, 0xed,
+	0x32, 0xb4, 0x81, 0xdf, 0x42, 0xab, 0x54, 0x98, 0x2e, 0x3b, 0xb7, 0x2f, 0x9e, 0xf0, 0x9b, 0xb8,
+	0x05, 0x8d, 0x01, 0xf3, 0x07, 0x9e, 0xe7, 0x72, 0x41, 0x2d, 0x29, 0xae, 0x17, 0x7e, 0xaa, 0xa5,
+	0x1f, 0x8f, 0xbb, 0x9e, 0xeb, 0x13, 0x47, 0x8a, 0x6b, 0xdb, 0x42, 0xaf, 0x31, 0x86, 0x1d, 0x6b,
+	0xe0, 0x39, 0xb6, 0x49, 0x04, 0xcd, 0xb1, 0x2d, 0x9d, 0xa6, 0x30, 0xd0, 0xa7, 0x4c, 0x48, 0xcf,
+	0x75, 0x6c, 0xf3, 0x46, 0x9e, 0x13, 0xdb, 0xd1, 0x46, 0x01, 0x37, 0x00, 0xf7, 0x2f, 0x4d, 0x53,
+	0x72, 0x4a, 0x72, 0x23, 0x8e, 0x6d, 0x0a, 0x54, 0xd3, 0xb5, 0x79, 0x3d, 0xc2, 0x84, 0xdb, 0x7f,
+	0x46, 0x6d, 0xe3, 0x3a, 0xec, 0x0e, 0xd8, 0x07, 0xe6, 0x5e, 0x31, 0xed, 0x4a, 0xdc, 0x78, 0x14,
+	0xbd, 0xd1, 0x76, 0x05, 0xe1, 0x17, 0x54, 0x48, 0xb3, 0x47, 0x6c, 0x26, 0x99, 0x2b, 0xe4, 0xb9,
+	0x3b, 0x60, 0x16, 0xda, 0xc1, 0xfb, 0x80, 0xfa, 0x84, 0xfb, 0xbd, 0xcc, 0xa9, 0xa4, 0x9c, 0xbb,
+	0x1c, 0xed, 0x96, 0x7d, 0x17, 0xd7, 0x45, 0xc9, 0x48, 0x97, 0x45, 0xaf, 0x3d, 0x9b, 0x53, 0x2b,
+	0x3f, 0xc4, 0x74, 0x2d, 0x8a, 0xf6, 0x74, 0x09, 0x8b, 0x50, 0x5e, 0x52, 0xee, 0xdb, 0x2e, 0x5b,
+	0xfa, 0xc1, 0xd8, 0x80, 0x7d, 0xdd, 0x8d, 0x7c, 0x2c, 0x92, 0x5e, 0x0b, 0xca, 0xb4, 0x04, 0xd5,
+	0x75, 0x71, 0xd9, 0x80, 0x7a, 0x84, 0x31, 0xea, 0x94, 0x83, 0xdb, 0x2f, 0x77, 0x70, 0xea, 0x7b,
+	0x2e, 0xf3, 0xe9, 0xa2, 0xb3, 0x07, 0xf8, 0x0d, 0x6c, 0x65, 0xcc, 0x95, 0x4f, 0x05, 0x6a, 0x68,
+	0xe7, 0xb6, 0xe3, 0xd0, 0x0b, 0xe2, 0xc8, 0x2b, 0x6e, 0x0b, 0xaa, 0xd1, 0xc3, 0x7c, 0xb8, 0xbe,
+	0x20, 0x4c, 0xd8, 0x44, 0x5b, 0x7f, 0xde, 0x55, 0x03, 0x7f, 0x03, 0x5f, 0x2f, 0x2d, 0x5b, 0xf4,
+	0xdc, 0x66, 0x76, 0x26, 0xec, 0xdb, 0x7e, 0x9f, 0x08, 0xb3, 0x87, 0x9a, 0xb8, 0x09, 0xfb, 0xe5,
+	0xfc, 0x5d, 0xd1, 0xa3, 0x5c, 0xb7, 0xd9, 0x77, 0x19, 0xfa, 0xb7, 0xd2, 0x0d, 0xa1, 0x1d, 0x27,
+	0xa3, 0xd3, 0xbb, 0xc7, 0x99, 0x4a, 0x26, 0x6a, 0x38, 0x52, 0xc9, 0xe9, 0xc7, 0xe0, 0x36, 0x19,
+	0x87, 0xe5, 0xff, 0x9a, 0x7e, 0xc6, 0xba, 0x78, 0xe5, 0xe7, 0xd6, 0x0b, 0xc2, 0x4f, 0xc1, 0x48,
+	0xfd, 0xf6, 0xdd, 0x68, 0x3c, 0xbf, 0xbb, 0xbf, 0xd5, 0xaf, 0x43, 0x67, 0x65, 0x7b, 0x27, 0xdf,
+	0x9e, 0x3f, 0x8c, 0x69, 0x47, 0x6f, 0xbf, 0xcd, 0x1f, 0xcd, 0xf7, 0xff, 0x0d, 0x00, 0xc3, 0x51,
+	0x1a, 0xc6, 0x55, 0x07, 0x00, 0x00,
 }